@@ -0,0 +1,49 @@
+package api
+
+import "encoding/json"
+
+// BatchRequest runs tool calls in order in one request
+type BatchRequest struct {
+	Calls       []BatchCall `json:"calls" jsonschema:"required,minItems=1" description:"Tool calls to run, in order"`
+	StopOnError bool        `json:"stop_on_error,omitempty" description:"Skip the calls after the first that fails"`
+}
+
+// BatchCall is one tool call of a batch
+type BatchCall struct {
+	Tool      string                 `json:"tool" jsonschema:"required" description:"Name of the tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty" description:"Arguments of the tool"`
+}
+
+// Batch call statuses
+const (
+	BatchStatusSuccess = "success"
+	BatchStatusError   = "error"
+	BatchStatusSkipped = "skipped"
+)
+
+// BatchResponse reports the result of each call of a batch, in the order of
+// the calls
+type BatchResponse struct {
+	Status    string        `json:"status"`
+	Results   []BatchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Skipped   int           `json:"skipped"`
+}
+
+// BatchResult is the outcome of one call of a batch. Result holds the tool's
+// JSON result, or its text as a JSON string when it returns other text.
+type BatchResult struct {
+	Index  int             `json:"index"`
+	Tool   string          `json:"tool"`
+	Status string          `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Error is a failure as GoThink reports it: a machine-readable code, such as
+// SESSION_NOT_FOUND, and a message
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
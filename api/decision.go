@@ -0,0 +1,986 @@
+package api
+
+import "time"
+
+// DecisionFrameworkRequest frames a decision between options
+type DecisionFrameworkRequest struct {
+	SessionID         string              `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID        string              `json:"decision_id,omitempty" description:"Recorded decision to revise as its next iteration, keeping the current one in its history; omit to record a new decision"`
+	DecisionStatement string              `json:"decision_statement" jsonschema:"required" description:"Statement of the decision to be made"`
+	Options           []DecisionOption    `json:"options" description:"Available decision options"`
+	Criteria          []DecisionCriterion `json:"criteria,omitempty" description:"Decision criteria and weights"`
+	Stakeholders      []string            `json:"stakeholders,omitempty" description:"People affected by the decision"`
+	Constraints       []string            `json:"constraints,omitempty" description:"Constraints the decision must respect"`
+	TimeHorizon       string              `json:"time_horizon,omitempty" description:"Time horizon of the decision"`
+	RiskTolerance     string              `json:"risk_tolerance,omitempty" description:"Tolerance for risk"`
+	AnalysisType      string              `json:"analysis_type" description:"Type of analysis to perform; topsis ranks the options by TOPSIS and needs scores"`
+	Stage             string              `json:"stage" description:"Stage of the decision process"`
+	Scores            []DecisionScore     `json:"scores,omitempty" description:"Score of every option on every criterion; with them the options are ranked"`
+	ScoringMethod     string              `json:"scoring_method,omitempty" jsonschema:"enum=weighted_sum|weighted_product|topsis" description:"How weighted, normalized scores combine: their sum, the product of each raised to its weight, or their closeness to the best on every criterion against the worst (default topsis for a topsis analysis, otherwise weighted_sum)"`
+	Normalization     string              `json:"normalization,omitempty" jsonschema:"enum=none|max|minmax|sum|vector" description:"How each criterion's scores are put on a common scale: as they are, divided by the highest, from lowest to highest, divided by their total or by their Euclidean norm (default vector for topsis, otherwise max)"`
+	Risks             []DecisionRisk      `json:"risks,omitempty" description:"Register of risks to the options; with them the risks are assessed"`
+	// StakeholderProfiles model the stakeholders named in Stakeholders
+	StakeholderProfiles []DecisionStakeholder `json:"stakeholder_profiles,omitempty" description:"Influence, interest and preferences of the stakeholders; with them the stakeholders are analyzed"`
+}
+
+// DecisionOption is an option of a decision. ExpectedValue and
+// ProbabilityOfSuccess are nil when not given, so that 0 can be given.
+type DecisionOption struct {
+	ID                   string   `json:"id,omitempty"`
+	Name                 string   `json:"name" jsonschema:"required"`
+	Description          string   `json:"description"`
+	ExpectedValue        *float64 `json:"expected_value,omitempty"`
+	RiskLevel            string   `json:"risk_level,omitempty"`
+	ProbabilityOfSuccess *float64 `json:"probability_of_success,omitempty" jsonschema:"minimum=0,maximum=1"`
+	// Outcome is the distribution the option's outcome is simulated from
+	Outcome *OutcomeDistribution `json:"outcome,omitempty" description:"Distribution of the option's outcome, for simulating the decision"`
+	// CashFlows are the option's time-phased costs and benefits
+	CashFlows []CashFlow `json:"cash_flows,omitempty" description:"Costs and benefits of the option by period, for its cost-benefit analysis"`
+}
+
+// CashFlow is a cost or benefit, or both, of an option falling in a period
+type CashFlow struct {
+	Period  int     `json:"period" jsonschema:"minimum=0,maximum=1000" description:"Period the flow falls in, 0 being now"`
+	Cost    float64 `json:"cost,omitempty" jsonschema:"minimum=0" description:"Cost falling in the period"`
+	Benefit float64 `json:"benefit,omitempty" jsonschema:"minimum=0" description:"Benefit falling in the period"`
+}
+
+// OutcomeDistribution is the distribution of an option's outcome, given as a
+// Monte Carlo variable's
+type OutcomeDistribution struct {
+	Distribution  string    `json:"distribution" jsonschema:"required,enum=normal|lognormal|triangular|beta|discrete" description:"Distribution of the outcome"`
+	Mean          float64   `json:"mean,omitempty" description:"Mean of a normal or lognormal outcome"`
+	StdDev        float64   `json:"std_dev,omitempty" jsonschema:"minimum=0" description:"Standard deviation of a normal or lognormal outcome"`
+	Min           float64   `json:"min,omitempty" description:"Lowest value of a triangular or beta outcome (beta default 0)"`
+	Mode          float64   `json:"mode,omitempty" description:"Most likely value of a triangular outcome"`
+	Max           float64   `json:"max,omitempty" description:"Highest value of a triangular or beta outcome (beta default 1)"`
+	Alpha         float64   `json:"alpha,omitempty" jsonschema:"minimum=0" description:"First shape of a beta outcome"`
+	Beta          float64   `json:"beta,omitempty" jsonschema:"minimum=0" description:"Second shape of a beta outcome"`
+	Values        []float64 `json:"values,omitempty" description:"Values of a discrete outcome"`
+	Probabilities []float64 `json:"probabilities,omitempty" description:"Relative chances of a discrete outcome's values (default equal)"`
+}
+
+// DecisionCriterion is a criterion options are evaluated by
+type DecisionCriterion struct {
+	ID               string  `json:"id,omitempty"`
+	Name             string  `json:"name" jsonschema:"required"`
+	Description      string  `json:"description"`
+	Weight           float64 `json:"weight" jsonschema:"minimum=0,maximum=1"`
+	EvaluationMethod string  `json:"evaluation_method"`
+	Direction        string  `json:"direction,omitempty" jsonschema:"enum=benefit|cost" description:"Whether higher scores on the criterion are better (benefit, the default) or worse (cost)"`
+}
+
+// DecisionScore is how an option scores on a criterion
+type DecisionScore struct {
+	Option    string  `json:"option" jsonschema:"required" description:"Name of the option"`
+	Criterion string  `json:"criterion" jsonschema:"required" description:"Name of the criterion"`
+	Score     float64 `json:"score" description:"The option's score on the criterion"`
+	Rationale string  `json:"rationale,omitempty" description:"Why the option scores so"`
+}
+
+// RankedOption is an option's place in a decision's ranking: its combined
+// score and what each criterion added to it in a weighted sum, multiplied it
+// by in a weighted product, or its weighted normalized score in TOPSIS, which
+// scores the option's closeness coefficient and also reports its distances
+// from the ideal and anti-ideal options
+type RankedOption struct {
+	Rank              int                `json:"rank"`
+	Option            string             `json:"option"`
+	Score             float64            `json:"score"`
+	Contributions     map[string]float64 `json:"contributions"`
+	IdealDistance     float64            `json:"ideal_distance,omitempty"`
+	AntiIdealDistance float64            `json:"anti_ideal_distance,omitempty"`
+}
+
+// DecisionFrameworkResponse reports a recorded decision
+type DecisionFrameworkResponse struct {
+	DecisionID   string `json:"decision_id"`
+	Status       string `json:"status"`
+	Iteration    int    `json:"iteration"`
+	HasOptions   bool   `json:"has_options"`
+	HasCriteria  bool   `json:"has_criteria"`
+	AnalysisType string `json:"analysis_type"`
+	Stage        string `json:"stage"`
+	// Ranking and Recommendation are set when the decision was scored
+	Ranking        []RankedOption `json:"ranking,omitempty"`
+	Recommendation string         `json:"recommendation,omitempty"`
+	// RiskAssessment is set when the decision has risks
+	RiskAssessment *RiskAssessment `json:"risk_assessment,omitempty"`
+	// StakeholderAnalysis is set when the decision has stakeholder profiles
+	StakeholderAnalysis *StakeholderAnalysis `json:"stakeholder_analysis,omitempty"`
+}
+
+// MultiCriteriaRequest ranks the options of a recorded decision by their
+// weighted scores on its criteria
+type MultiCriteriaRequest struct {
+	SessionID     string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID    string          `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision to score"`
+	Scores        []DecisionScore `json:"scores,omitempty" description:"Score of every option on every criterion, replacing those recorded (default the recorded scores)"`
+	ScoringMethod string          `json:"scoring_method,omitempty" jsonschema:"enum=weighted_sum|weighted_product|topsis" description:"How weighted, normalized scores combine (default the recorded method, or as the analysis type says)"`
+	Normalization string          `json:"normalization,omitempty" jsonschema:"enum=none|max|minmax|sum|vector" description:"How each criterion's scores are put on a common scale (default the recorded normalization, or vector for topsis and max otherwise)"`
+}
+
+// MultiCriteriaResponse reports the ranking stored on a decision
+type MultiCriteriaResponse struct {
+	DecisionID     string         `json:"decision_id"`
+	Status         string         `json:"status"`
+	ScoringMethod  string         `json:"scoring_method"`
+	Normalization  string         `json:"normalization"`
+	Ranking        []RankedOption `json:"ranking"`
+	Recommendation string         `json:"recommendation"`
+}
+
+// ScoreDecisionOptionRequest records the score of an option of a recorded
+// decision on one of its criteria, numerically or as a qualitative rating
+type ScoreDecisionOptionRequest struct {
+	SessionID  string   `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID string   `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision"`
+	Option     string   `json:"option" jsonschema:"required" description:"Name of the option"`
+	Criterion  string   `json:"criterion" jsonschema:"required" description:"Name of the criterion"`
+	Score      *float64 `json:"score,omitempty" description:"Numeric score of the option on the criterion"`
+	Rating     string   `json:"rating,omitempty" jsonschema:"enum=very_poor|poor|fair|good|excellent" description:"Qualitative rating of the option on the criterion instead of a score, scored 1 to 5, or 5 to 1 on a cost criterion"`
+	Rationale  string   `json:"rationale,omitempty" description:"Why the option scores so"`
+}
+
+// DecisionMatrixScore is a score in a decision matrix, with the rating it
+// was recorded from
+type DecisionMatrixScore struct {
+	Option    string  `json:"option"`
+	Criterion string  `json:"criterion"`
+	Score     float64 `json:"score"`
+	Rating    string  `json:"rating,omitempty"`
+	Rationale string  `json:"rationale,omitempty"`
+}
+
+// ScoreDecisionOptionResponse reports the decision matrix of a decision after
+// a score was recorded. Once every option is scored on every criterion the
+// options are ranked.
+type ScoreDecisionOptionResponse struct {
+	DecisionID string                `json:"decision_id"`
+	Status     string                `json:"status"`
+	Matrix     []DecisionMatrixScore `json:"matrix"`
+	// Missing lists the option and criterion pairs yet to be scored
+	Missing        []string       `json:"missing,omitempty"`
+	Complete       bool           `json:"complete"`
+	Ranking        []RankedOption `json:"ranking,omitempty"`
+	Recommendation string         `json:"recommendation,omitempty"`
+}
+
+// AHPRequest derives the criteria weights, and optionally the option scores,
+// of a recorded decision from pairwise comparisons by the Analytic Hierarchy
+// Process
+type AHPRequest struct {
+	SessionID      string            `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID     string            `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision whose criteria are weighted"`
+	CriteriaMatrix [][]float64       `json:"criteria_matrix" jsonschema:"required,minItems=1" description:"Pairwise comparisons of the decision's criteria in their order: row i, column j holds how many times more important criterion i is than criterion j, from 1/9 to 9; an entry of 0 takes the reciprocal of the one mirroring it, so one triangle suffices"`
+	OptionMatrices []AHPOptionMatrix `json:"option_matrices,omitempty" description:"Pairwise comparisons of the decision's options on each criterion; given for every criterion, the options' priorities become their scores and the options are ranked"`
+}
+
+// AHPOptionMatrix compares the options of a decision on a criterion
+type AHPOptionMatrix struct {
+	Criterion string      `json:"criterion" jsonschema:"required" description:"Name of the criterion"`
+	Matrix    [][]float64 `json:"matrix" jsonschema:"required,minItems=1" description:"Pairwise comparisons of the decision's options in their order: row i, column j holds how many times more option i is preferred than option j on the criterion"`
+}
+
+// AHPResponse reports the priorities derived from each comparison matrix,
+// stored on the decision as its criteria weights and option scores, and the
+// ranking they give
+type AHPResponse struct {
+	DecisionID string          `json:"decision_id"`
+	Status     string          `json:"status"`
+	Criteria   AHPPriorities   `json:"criteria"`
+	Options    []AHPPriorities `json:"options,omitempty"`
+	// Consistent reports whether every matrix is consistent enough to rely
+	// on
+	Consistent     bool           `json:"consistent"`
+	Ranking        []RankedOption `json:"ranking,omitempty"`
+	Recommendation string         `json:"recommendation,omitempty"`
+}
+
+// AHPPriorities are the priorities a comparison matrix derives, with its
+// principal eigenvalue and consistency; a consistency ratio above 0.1 is
+// too inconsistent to rely on
+type AHPPriorities struct {
+	Criterion        string             `json:"criterion,omitempty"`
+	Priorities       map[string]float64 `json:"priorities"`
+	LambdaMax        float64            `json:"lambda_max"`
+	ConsistencyIndex float64            `json:"consistency_index"`
+	ConsistencyRatio float64            `json:"consistency_ratio"`
+	Consistent       bool               `json:"consistent"`
+}
+
+// DecisionRisk is a risk to the options of a decision
+type DecisionRisk struct {
+	Name        string           `json:"name" jsonschema:"required" description:"Name of the risk"`
+	Option      string           `json:"option,omitempty" description:"Name of the option the risk threatens (default every option)"`
+	Description string           `json:"description,omitempty" description:"What could go wrong"`
+	Probability float64          `json:"probability" jsonschema:"minimum=0,maximum=1" description:"Probability that the risk strikes"`
+	Impact      float64          `json:"impact" jsonschema:"minimum=0" description:"Loss if the risk strikes, in the units of the options' expected values"`
+	Mitigations []RiskMitigation `json:"mitigations,omitempty" description:"Measures against the risk"`
+}
+
+// RiskMitigation is a measure against a risk
+type RiskMitigation struct {
+	Name                 string  `json:"name" jsonschema:"required" description:"Name of the measure"`
+	ProbabilityReduction float64 `json:"probability_reduction,omitempty" jsonschema:"minimum=0,maximum=1" description:"Fraction by which the measure cuts the risk's probability"`
+	ImpactReduction      float64 `json:"impact_reduction,omitempty" jsonschema:"minimum=0,maximum=1" description:"Fraction by which the measure cuts the risk's impact"`
+	Cost                 float64 `json:"cost,omitempty" jsonschema:"minimum=0" description:"Certain cost of the measure"`
+}
+
+// RiskAnalysisRequest assesses the risk register of a recorded decision
+type RiskAnalysisRequest struct {
+	SessionID  string         `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID string         `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision whose risks are assessed"`
+	Risks      []DecisionRisk `json:"risks,omitempty" description:"Register of risks to the options, replacing the recorded one (default the recorded register)"`
+}
+
+// RiskAnalysisResponse reports the risk assessment stored on a decision
+type RiskAnalysisResponse struct {
+	DecisionID string         `json:"decision_id"`
+	Status     string         `json:"status"`
+	Assessment RiskAssessment `json:"assessment"`
+}
+
+// RiskCell is a risk's place on a probability-impact matrix: its probability
+// and impact levels from 1 to 5, and its severity, their product, rated low
+// up to 4, medium up to 9, high up to 16 and critical above
+type RiskCell struct {
+	ProbabilityLevel int    `json:"probability_level"`
+	ImpactLevel      int    `json:"impact_level"`
+	Severity         int    `json:"severity"`
+	Rating           string `json:"rating"`
+}
+
+// AssessedRisk is a risk's assessment before and after mitigation
+type AssessedRisk struct {
+	Name                string   `json:"name"`
+	Option              string   `json:"option,omitempty"`
+	Inherent            RiskCell `json:"inherent"`
+	Residual            RiskCell `json:"residual"`
+	ResidualProbability float64  `json:"residual_probability"`
+	ResidualImpact      float64  `json:"residual_impact"`
+	ExpectedLoss        float64  `json:"expected_loss"`
+	MitigationCost      float64  `json:"mitigation_cost"`
+}
+
+// OptionRisk is the risk an option runs: the expected loss and mitigation
+// cost of its risks, its expected value less both, and its worst residual
+// rating
+type OptionRisk struct {
+	Option            string  `json:"option"`
+	Risks             int     `json:"risks"`
+	ExpectedValue     float64 `json:"expected_value"`
+	ExpectedLoss      float64 `json:"expected_loss"`
+	MitigationCost    float64 `json:"mitigation_cost"`
+	RiskAdjustedValue float64 `json:"risk_adjusted_value"`
+	Rating            string  `json:"rating"`
+}
+
+// RiskAssessment is the assessment of a decision's risk register. Its
+// matrices count the risks before and after mitigation by probability level,
+// the rows, and impact level, the columns; its options are ordered by
+// risk-adjusted value, highest first.
+type RiskAssessment struct {
+	Risks          []AssessedRisk `json:"risks"`
+	InherentMatrix [][]int        `json:"inherent_matrix"`
+	ResidualMatrix [][]int        `json:"residual_matrix"`
+	Options        []OptionRisk   `json:"options"`
+	Recommendation string         `json:"recommendation"`
+}
+
+// DecisionStakeholder is a stakeholder of a decision: its influence over the
+// decision, its interest in it and its support for the options
+type DecisionStakeholder struct {
+	Name        string                  `json:"name" jsonschema:"required" description:"Name of the stakeholder"`
+	Role        string                  `json:"role,omitempty" description:"The stakeholder's role"`
+	Influence   float64                 `json:"influence" jsonschema:"minimum=0,maximum=1" description:"Power of the stakeholder over the decision, from 0 to 1"`
+	Interest    float64                 `json:"interest" jsonschema:"minimum=0,maximum=1" description:"Stake of the stakeholder in the decision, from 0 to 1"`
+	Preferences []StakeholderPreference `json:"preferences,omitempty" description:"Support of the stakeholder for options; it is neutral on the others"`
+}
+
+// StakeholderPreference is a stakeholder's support for an option
+type StakeholderPreference struct {
+	Option  string  `json:"option" jsonschema:"required" description:"Name of the option"`
+	Support float64 `json:"support" jsonschema:"minimum=-1,maximum=1" description:"Support for the option, from -1 (opposes) to 1 (backs)"`
+}
+
+// StakeholderAnalysisRequest analyzes the stakeholders of a recorded decision
+type StakeholderAnalysisRequest struct {
+	SessionID    string                `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID   string                `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision whose stakeholders are analyzed"`
+	Stakeholders []DecisionStakeholder `json:"stakeholders,omitempty" description:"Stakeholder profiles, replacing the recorded ones (default the recorded profiles)"`
+}
+
+// StakeholderAnalysisResponse reports the stakeholder analysis stored on a
+// decision
+type StakeholderAnalysisResponse struct {
+	DecisionID string              `json:"decision_id"`
+	Status     string              `json:"status"`
+	Analysis   StakeholderAnalysis `json:"analysis"`
+}
+
+// PositionedStakeholder is a stakeholder's place on the influence/interest
+// grid and its weight, its share of the product of influence and interest
+// over all stakeholders
+type PositionedStakeholder struct {
+	Name      string  `json:"name"`
+	Influence float64 `json:"influence"`
+	Interest  float64 `json:"interest"`
+	Quadrant  string  `json:"quadrant"`
+	Weight    float64 `json:"weight"`
+}
+
+// StakeholderGrid lists the stakeholders of each quadrant of the
+// influence/interest grid, influence and interest counting as high from 0.5,
+// most weighty first
+type StakeholderGrid struct {
+	ManageClosely []string `json:"manage_closely"`
+	KeepSatisfied []string `json:"keep_satisfied"`
+	KeepInformed  []string `json:"keep_informed"`
+	Monitor       []string `json:"monitor"`
+}
+
+// StakeholderScore is an option's stakeholder-weighted score, the weighted
+// mean of the stakeholders' support for it, with the stakeholders backing and
+// opposing it
+type StakeholderScore struct {
+	Rank       int      `json:"rank"`
+	Option     string   `json:"option"`
+	Score      float64  `json:"score"`
+	Supporters []string `json:"supporters,omitempty"`
+	Opponents  []string `json:"opponents,omitempty"`
+}
+
+// StakeholderAnalysis is the analysis of a decision's stakeholders; its
+// options are ordered by score, highest first
+type StakeholderAnalysis struct {
+	Stakeholders   []PositionedStakeholder `json:"stakeholders"`
+	Grid           StakeholderGrid         `json:"grid"`
+	Options        []StakeholderScore      `json:"options"`
+	Recommendation string                  `json:"recommendation"`
+}
+
+// DecisionEvaluation is an evaluator's scores of the options of a decision
+type DecisionEvaluation struct {
+	Evaluator string           `json:"evaluator" jsonschema:"required" description:"Name of the evaluator"`
+	Scores    []EvaluatorScore `json:"scores" jsonschema:"required,minItems=1" description:"The evaluator's score of every option, higher being better"`
+}
+
+// EvaluatorScore is an evaluator's score of an option
+type EvaluatorScore struct {
+	Option string  `json:"option" jsonschema:"required" description:"Name of the option"`
+	Score  float64 `json:"score" description:"The evaluator's score of the option"`
+}
+
+// GroupDecisionRequest aggregates the evaluations of a recorded decision's
+// options by a group of evaluators
+type GroupDecisionRequest struct {
+	SessionID   string               `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID  string               `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision"`
+	Evaluations []DecisionEvaluation `json:"evaluations,omitempty" description:"Evaluations to record, replacing those recorded of the same evaluators"`
+	Method      string               `json:"method,omitempty" jsonschema:"enum=borda|condorcet|range" description:"Aggregation the ranking follows: Borda count, pairwise majorities ranked by Copeland score, or mean score (default borda)"`
+}
+
+// GroupDecisionResponse reports the group aggregation stored on a decision
+type GroupDecisionResponse struct {
+	DecisionID  string           `json:"decision_id"`
+	Status      string           `json:"status"`
+	Aggregation GroupAggregation `json:"aggregation"`
+}
+
+// GroupStanding is an option's place in a group ranking by the score of its
+// method: Borda points, Copeland score or mean score
+type GroupStanding struct {
+	Rank   int     `json:"rank"`
+	Option string  `json:"option"`
+	Score  float64 `json:"score"`
+}
+
+// GroupOption is an option's standing under every aggregation method, its
+// mean rank, and the standard deviations of its ranks and scores, which show
+// how far the evaluators differ over it
+type GroupOption struct {
+	Option        string  `json:"option"`
+	BordaPoints   float64 `json:"borda_points"`
+	CopelandScore int     `json:"copeland_score"`
+	RangeScore    float64 `json:"range_score"`
+	MeanRank      float64 `json:"mean_rank"`
+	RankSpread    float64 `json:"rank_spread"`
+	ScoreSpread   float64 `json:"score_spread"`
+}
+
+// EvaluatorConflict is the Spearman correlation of two evaluators' rankings,
+// from -1, opposite, to 1, alike
+type EvaluatorConflict struct {
+	EvaluatorA  string  `json:"evaluator_a"`
+	EvaluatorB  string  `json:"evaluator_b"`
+	Correlation float64 `json:"correlation"`
+}
+
+// GroupAggregation is the aggregation of a decision's evaluations. Its
+// options and pairwise counts, of the evaluators preferring the row option to
+// the column one, follow the decision's order; its disagreement is one less
+// Kendall's W of the evaluators' rankings, and its conflicts list every pair
+// of evaluators, least correlated first.
+type GroupAggregation struct {
+	Method          string              `json:"method"`
+	Evaluators      int                 `json:"evaluators"`
+	Ranking         []GroupStanding     `json:"ranking"`
+	Options         []GroupOption       `json:"options"`
+	Pairwise        [][]int             `json:"pairwise"`
+	CondorcetWinner string              `json:"condorcet_winner,omitempty"`
+	Disagreement    float64             `json:"disagreement"`
+	Conflicts       []EvaluatorConflict `json:"conflicts,omitempty"`
+	Recommendation  string              `json:"recommendation"`
+}
+
+// OptionCashFlows are the time-phased costs and benefits of an option
+type OptionCashFlows struct {
+	Option    string     `json:"option" jsonschema:"required" description:"Name of the option"`
+	CashFlows []CashFlow `json:"cash_flows" jsonschema:"required,minItems=1" description:"Costs and benefits of the option by period"`
+}
+
+// CostBenefitRequest appraises the options of a recorded decision by their
+// time-phased costs and benefits
+type CostBenefitRequest struct {
+	SessionID    string            `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID   string            `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision"`
+	CashFlows    []OptionCashFlows `json:"cash_flows,omitempty" description:"Costs and benefits of options, replacing those recorded (default as recorded)"`
+	DiscountRate float64           `json:"discount_rate,omitempty" jsonschema:"minimum=0" description:"Discount rate per period, such as 0.08 (default 0, undiscounted)"`
+}
+
+// CostBenefitResponse reports the cost-benefit analysis stored on a decision
+type CostBenefitResponse struct {
+	DecisionID string              `json:"decision_id"`
+	Status     string              `json:"status"`
+	Analysis   CostBenefitAnalysis `json:"analysis"`
+}
+
+// OptionCostBenefit is an option's appraisal: the present values of its
+// benefits and costs and their difference, the net present value; their
+// ratio, absent without costs; its internal rate of return, absent when the
+// net present value keeps its sign from -99% to 1000%; and the periods after
+// which its cumulative net flows, undiscounted and discounted, climb back to
+// zero, absent when they stay negative
+type OptionCostBenefit struct {
+	Rank                    int      `json:"rank"`
+	Option                  string   `json:"option"`
+	NPV                     float64  `json:"npv"`
+	PresentBenefits         float64  `json:"present_benefits"`
+	PresentCosts            float64  `json:"present_costs"`
+	BenefitCostRatio        *float64 `json:"benefit_cost_ratio,omitempty"`
+	IRR                     *float64 `json:"irr,omitempty"`
+	PaybackPeriod           *float64 `json:"payback_period,omitempty"`
+	DiscountedPaybackPeriod *float64 `json:"discounted_payback_period,omitempty"`
+}
+
+// CostBenefitAnalysis is the appraisal of a decision's options, by net
+// present value, highest first
+type CostBenefitAnalysis struct {
+	DiscountRate   float64             `json:"discount_rate"`
+	Options        []OptionCostBenefit `json:"options"`
+	Recommendation string              `json:"recommendation"`
+}
+
+// KepnerTregoeRequest analyzes the options of a recorded decision by
+// Kepner-Tregoe decision analysis
+type KepnerTregoeRequest struct {
+	SessionID   string             `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID  string             `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision"`
+	Musts       []MustObjective    `json:"musts,omitempty" description:"Mandatory objectives; an option failing any is eliminated"`
+	Wants       []WantObjective    `json:"wants" jsonschema:"required,minItems=1" description:"Desirable objectives, weighted by importance"`
+	Assessments []OptionAssessment `json:"assessments" jsonschema:"required,minItems=1" description:"Assessment of every option of the decision against the objectives and of its adverse consequences"`
+}
+
+// MustObjective is a mandatory objective of a Kepner-Tregoe analysis
+type MustObjective struct {
+	Name        string `json:"name" jsonschema:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// WantObjective is a desirable objective of a Kepner-Tregoe analysis
+type WantObjective struct {
+	Name        string `json:"name" jsonschema:"required"`
+	Weight      int    `json:"weight" jsonschema:"required,minimum=1,maximum=10" description:"Importance, 10 for the most important"`
+	Description string `json:"description,omitempty"`
+}
+
+// OptionAssessment is an option's assessment against the objectives of a
+// Kepner-Tregoe analysis
+type OptionAssessment struct {
+	Option              string               `json:"option" jsonschema:"required"`
+	Musts               []MustResult         `json:"musts,omitempty" description:"Whether the option meets each MUST"`
+	Wants               []WantScore          `json:"wants,omitempty" description:"Score of the option against each WANT; an option failing a MUST needs none"`
+	AdverseConsequences []AdverseConsequence `json:"adverse_consequences,omitempty" description:"What could go wrong if the option is chosen"`
+}
+
+// MustResult is whether an option meets a MUST objective
+type MustResult struct {
+	Must     string `json:"must" jsonschema:"required"`
+	Met      bool   `json:"met"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// WantScore is an option's score against a WANT objective
+type WantScore struct {
+	Want      string  `json:"want" jsonschema:"required"`
+	Score     float64 `json:"score" jsonschema:"minimum=0,maximum=10" description:"Score, 10 for the option that best satisfies the WANT"`
+	Rationale string  `json:"rationale,omitempty"`
+}
+
+// AdverseConsequence is a risk of choosing an option; its threat is its
+// probability times its seriousness
+type AdverseConsequence struct {
+	Description string `json:"description" jsonschema:"required"`
+	Probability int    `json:"probability" jsonschema:"required,minimum=1,maximum=10" description:"How likely the consequence is, from 1 to 10"`
+	Seriousness int    `json:"seriousness" jsonschema:"required,minimum=1,maximum=10" description:"How serious the consequence would be, from 1 to 10"`
+	Threat      int    `json:"threat,omitempty"`
+}
+
+// KepnerTregoeResponse reports the Kepner-Tregoe analysis stored on a
+// decision
+type KepnerTregoeResponse struct {
+	DecisionID string               `json:"decision_id"`
+	Status     string               `json:"status"`
+	Analysis   KepnerTregoeAnalysis `json:"analysis"`
+}
+
+// KepnerTregoeOption is an option's Kepner-Tregoe assessment: the MUSTs it
+// fails, its weighted score against the WANTs and that score's share of the
+// highest possible, and the total threat of its adverse consequences, with
+// those rated high on both probability and seriousness. Only options meeting
+// every MUST are ranked.
+type KepnerTregoeOption struct {
+	Rank                int                  `json:"rank,omitempty"`
+	Option              string               `json:"option"`
+	Qualified           bool                 `json:"qualified"`
+	Musts               []MustResult         `json:"musts,omitempty"`
+	FailedMusts         []string             `json:"failed_musts,omitempty"`
+	Wants               []WantScore          `json:"wants,omitempty"`
+	WeightedScore       float64              `json:"weighted_score"`
+	Share               float64              `json:"share"`
+	AdverseConsequences []AdverseConsequence `json:"adverse_consequences,omitempty"`
+	Threat              int                  `json:"threat"`
+	SeriousRisks        []string             `json:"serious_risks,omitempty"`
+}
+
+// KepnerTregoeAnalysis is the Kepner-Tregoe analysis of a decision's
+// options, the qualified ones first by weighted score
+type KepnerTregoeAnalysis struct {
+	Musts          []MustObjective      `json:"musts,omitempty"`
+	Wants          []WantObjective      `json:"wants"`
+	Options        []KepnerTregoeOption `json:"options"`
+	BalancedChoice string               `json:"balanced_choice,omitempty"`
+	Recommendation string               `json:"recommendation"`
+}
+
+// Scenario is a named future of a scenario analysis
+type Scenario struct {
+	Name        string  `json:"name" jsonschema:"required" description:"Name of the scenario"`
+	Description string  `json:"description,omitempty" description:"What the future holds"`
+	Probability float64 `json:"probability" jsonschema:"minimum=0,maximum=1" description:"Probability of the scenario; the probabilities sum to 1"`
+}
+
+// ScenarioOutcome is the outcome of an option in a scenario
+type ScenarioOutcome struct {
+	Option   string  `json:"option" jsonschema:"required" description:"Name of the option"`
+	Scenario string  `json:"scenario" jsonschema:"required" description:"Name of the scenario"`
+	Value    float64 `json:"value" description:"Outcome of the option in the scenario, higher being better"`
+}
+
+// ScenarioAnalysisRequest evaluates options across named future scenarios
+type ScenarioAnalysisRequest struct {
+	SessionID string            `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Title     string            `json:"title,omitempty" description:"Question the analysis informs"`
+	Scenarios []Scenario        `json:"scenarios" jsonschema:"required,minItems=1" description:"Named futures with their probabilities"`
+	Options   []string          `json:"options" jsonschema:"required,minItems=2" description:"Names of the options"`
+	Outcomes  []ScenarioOutcome `json:"outcomes" jsonschema:"required,minItems=2" description:"Outcome of every option in every scenario"`
+	Tolerance *float64          `json:"tolerance,omitempty" jsonschema:"minimum=0,maximum=1" description:"Shortfall from a scenario's best outcome, as a share of the spread of its outcomes, a robust option may reach in any scenario (default 0.5)"`
+}
+
+// ScenarioAnalysisResponse reports a scenario analysis and the record
+// storing it
+type ScenarioAnalysisResponse struct {
+	ScenarioID string           `json:"scenario_id"`
+	Status     string           `json:"status"`
+	Analysis   ScenarioAnalysis `json:"analysis"`
+}
+
+// ScenarioEvaluation is an option's evaluation across the scenarios: its
+// probability-weighted mean outcome; its least and greatest outcomes; its
+// greatest and mean regret, the shortfall from the best outcome of a
+// scenario; the scenarios in which it does best; and the scenario of its
+// greatest shortfall relative to the spread of outcomes there. Robust options
+// stay within the tolerance in every scenario; bets do best in some scenario
+// but fall beyond it in another; weak options are neither.
+type ScenarioEvaluation struct {
+	Rank           int      `json:"rank"`
+	Option         string   `json:"option"`
+	Class          string   `json:"class"`
+	ExpectedValue  float64  `json:"expected_value"`
+	WorstCase      float64  `json:"worst_case"`
+	BestCase       float64  `json:"best_case"`
+	MaxRegret      float64  `json:"max_regret"`
+	ExpectedRegret float64  `json:"expected_regret"`
+	BestIn         []string `json:"best_in,omitempty"`
+	WeakestIn      string   `json:"weakest_in,omitempty"`
+}
+
+// ScenarioAnalysis is the evaluation of options across scenarios, by
+// expected value, highest first
+type ScenarioAnalysis struct {
+	Title          string               `json:"title,omitempty"`
+	Scenarios      []Scenario           `json:"scenarios"`
+	Tolerance      float64              `json:"tolerance"`
+	Evaluations    []ScenarioEvaluation `json:"evaluations"`
+	RobustOptions  []string             `json:"robust_options,omitempty"`
+	Bets           []string             `json:"bets,omitempty"`
+	Recommendation string               `json:"recommendation"`
+}
+
+// DecisionHistoryRequest reviews the iterations of a recorded decision
+type DecisionHistoryRequest struct {
+	SessionID  string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID string `json:"decision_id" jsonschema:"required" description:"Identifier of the recorded decision"`
+}
+
+// DecisionHistoryResponse reports the iterations of a decision, oldest first,
+// the last being its current one
+type DecisionHistoryResponse struct {
+	DecisionID string              `json:"decision_id"`
+	Status     string              `json:"status"`
+	Iterations []DecisionIteration `json:"iterations"`
+	Summary    string              `json:"summary"`
+}
+
+// DecisionIteration is an iteration of a decision and what changed in it
+// since the one before
+type DecisionIteration struct {
+	Iteration         int                   `json:"iteration"`
+	DecisionStatement string                `json:"decision_statement"`
+	AnalysisType      string                `json:"analysis_type"`
+	Stage             string                `json:"stage"`
+	Options           []string              `json:"options"`
+	Criteria          []DecisionCriterion   `json:"criteria,omitempty"`
+	Scores            []DecisionMatrixScore `json:"scores,omitempty"`
+	ScoringMethod     string                `json:"scoring_method,omitempty"`
+	Normalization     string                `json:"normalization,omitempty"`
+	Ranking           []RankedOption        `json:"ranking,omitempty"`
+	TopOption         string                `json:"top_option,omitempty"`
+	Recommendation    string                `json:"recommendation,omitempty"`
+	RecordedAt        time.Time             `json:"recorded_at"`
+	Changes           []string              `json:"changes"`
+}
+
+// RecordDecisionOutcomeRequest records what came of a decision
+type RecordDecisionOutcomeRequest struct {
+	SessionID     string   `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID    string   `json:"decision_id" jsonschema:"required" description:"Identifier of the recorded decision"`
+	ChosenOption  string   `json:"chosen_option" jsonschema:"required" description:"Option that was taken"`
+	Succeeded     *bool    `json:"succeeded,omitempty" description:"Whether the option succeeded, checked against its probability_of_success"`
+	RealizedValue *float64 `json:"realized_value,omitempty" description:"Value the option realized, checked against its expected value"`
+	Notes         string   `json:"notes,omitempty" description:"What happened"`
+}
+
+// RecordDecisionOutcomeResponse reports a recorded outcome
+type RecordDecisionOutcomeResponse struct {
+	DecisionID string          `json:"decision_id"`
+	Status     string          `json:"status"`
+	Outcome    DecisionOutcome `json:"outcome"`
+	Summary    string          `json:"summary"`
+}
+
+// DecisionOutcome is what came of a decision, with what was forecast for the
+// option taken
+type DecisionOutcome struct {
+	ChosenOption        string    `json:"chosen_option"`
+	Succeeded           *bool     `json:"succeeded,omitempty"`
+	RealizedValue       *float64  `json:"realized_value,omitempty"`
+	ForecastProbability *float64  `json:"forecast_probability,omitempty"`
+	ForecastValue       *float64  `json:"forecast_value,omitempty"`
+	Notes               string    `json:"notes,omitempty"`
+	RecordedAt          time.Time `json:"recorded_at"`
+}
+
+// CalibrationReportRequest compares the forecasts of decisions with their
+// recorded outcomes
+type CalibrationReportRequest struct {
+	SessionID string `json:"session_id,omitempty" description:"Session to report on; omit for every session of the tenant"`
+	Bins      int    `json:"bins,omitempty" jsonschema:"minimum=1,maximum=20" description:"Bins of equal width the forecast probabilities are grouped into (default 5)"`
+}
+
+// CalibrationReportResponse reports how well the forecasts of decisions with
+// recorded outcomes held up
+type CalibrationReportResponse struct {
+	Status    string              `json:"status"`
+	SessionID string              `json:"session_id,omitempty"`
+	Outcomes  []CalibratedOutcome `json:"outcomes"`
+	// Probability is set when some outcome has a forecast probability and a
+	// success or failure, and Value when some has a forecast and realized
+	// value
+	Probability *ProbabilityCalibration `json:"probability,omitempty"`
+	Value       *ValueCalibration       `json:"value,omitempty"`
+	Summary     string                  `json:"summary"`
+}
+
+// CalibratedOutcome is the recorded outcome of a decision
+type CalibratedOutcome struct {
+	SessionID         string          `json:"session_id"`
+	DecisionID        string          `json:"decision_id"`
+	DecisionStatement string          `json:"decision_statement"`
+	Outcome           DecisionOutcome `json:"outcome"`
+}
+
+// ProbabilityCalibration is how forecast probabilities of success held up
+type ProbabilityCalibration struct {
+	Count        int              `json:"count"`
+	MeanForecast float64          `json:"mean_forecast"`
+	ObservedRate float64          `json:"observed_rate"`
+	BrierScore   float64          `json:"brier_score"`
+	Leaning      string           `json:"leaning"`
+	Bins         []CalibrationBin `json:"bins"`
+}
+
+// CalibrationBin is a bin of forecast probabilities and how often they came
+// true
+type CalibrationBin struct {
+	Lower        float64 `json:"lower"`
+	Upper        float64 `json:"upper"`
+	Count        int     `json:"count"`
+	MeanForecast float64 `json:"mean_forecast"`
+	ObservedRate float64 `json:"observed_rate"`
+}
+
+// ValueCalibration is how forecast values held up
+type ValueCalibration struct {
+	Count             int      `json:"count"`
+	MeanForecast      float64  `json:"mean_forecast"`
+	MeanRealized      float64  `json:"mean_realized"`
+	MeanError         float64  `json:"mean_error"`
+	MeanAbsoluteError float64  `json:"mean_absolute_error"`
+	Ratio             *float64 `json:"ratio,omitempty"`
+}
+
+// DecisionReference names a recorded decision and its session
+type DecisionReference struct {
+	SessionID  string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID string `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision"`
+}
+
+// CompareDecisionsRequest compares recorded decisions, possibly of different
+// sessions, side by side
+type CompareDecisionsRequest struct {
+	Decisions []DecisionReference `json:"decisions" jsonschema:"required,minItems=2" description:"Decisions to compare, in the order they are shown, such as oldest first"`
+}
+
+// CompareDecisionsResponse reports a side-by-side comparison of decisions.
+// The entries of its options and scores follow the order of its decisions.
+type CompareDecisionsResponse struct {
+	Status         string             `json:"status"`
+	Decisions      []ComparedDecision `json:"decisions"`
+	SharedOptions  []string           `json:"shared_options"`
+	SharedCriteria []string           `json:"shared_criteria"`
+	Options        []ComparedOption   `json:"options"`
+	Scores         []ComparedScore    `json:"scores"`
+	Summary        string             `json:"summary"`
+}
+
+// ComparedDecision is a decision as compared, with the option its ranking
+// puts first, if it is ranked
+type ComparedDecision struct {
+	SessionID         string    `json:"session_id"`
+	DecisionID        string    `json:"decision_id"`
+	DecisionStatement string    `json:"decision_statement"`
+	AnalysisType      string    `json:"analysis_type"`
+	Stage             string    `json:"stage"`
+	Options           []string  `json:"options"`
+	Criteria          []string  `json:"criteria"`
+	TopOption         string    `json:"top_option,omitempty"`
+	Recommendation    string    `json:"recommendation,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ComparedOption is an option across the compared decisions: whether each
+// has it, and its rank and ranking score in each, null where a decision has
+// not ranked it
+type ComparedOption struct {
+	Option string     `json:"option"`
+	In     []bool     `json:"in"`
+	Ranks  []*int     `json:"ranks"`
+	Scores []*float64 `json:"scores"`
+}
+
+// ComparedScore is an option's score on a criterion across the compared
+// decisions, null where a decision has not scored it, with the gap between
+// the highest and lowest of the scores given
+type ComparedScore struct {
+	Option    string     `json:"option"`
+	Criterion string     `json:"criterion"`
+	Scores    []*float64 `json:"scores"`
+	Gap       float64    `json:"gap"`
+}
+
+// DecisionTreeRequest evaluates a tree of decision and chance nodes by
+// expected-value rollback
+type DecisionTreeRequest struct {
+	SessionID string             `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Title     string             `json:"title,omitempty" description:"Question the tree decides, noted on its diagram"`
+	Objective string             `json:"objective,omitempty" jsonschema:"enum=maximize|minimize" description:"Whether payoffs are gains to maximize or costs to minimize (default maximize)"`
+	Nodes     []DecisionTreeNode `json:"nodes" jsonschema:"required,minItems=1" description:"Nodes of the tree, each naming its parent; exactly one, the root, names none"`
+}
+
+// DecisionTreeNode is a node of a decision tree
+type DecisionTreeNode struct {
+	ID          string  `json:"id" jsonschema:"required" description:"Node identifier"`
+	Parent      string  `json:"parent,omitempty" description:"ID of the parent node; empty for the root"`
+	Type        string  `json:"type" jsonschema:"required,enum=decision|chance|terminal" description:"decision nodes choose a branch, chance nodes draw one by probability, terminal nodes end the tree"`
+	Label       string  `json:"label,omitempty" description:"Choice or outcome the branch to the node stands for"`
+	Probability float64 `json:"probability,omitempty" jsonschema:"minimum=0,maximum=1" description:"Probability of the branch to the node from its chance parent; a chance node's branches sum to 1"`
+	Payoff      float64 `json:"payoff,omitempty" description:"Payoff collected on reaching the node, negative for a cost"`
+}
+
+// DecisionTreeResponse reports the optimal strategy of a decision tree and
+// the diagram recording it
+type DecisionTreeResponse struct {
+	Status string `json:"status"`
+	// ExpectedValue is the root's rolled-back value, the expected payoff of
+	// the optimal strategy
+	ExpectedValue float64 `json:"expected_value"`
+	// Path holds the nodes from the root the optimal strategy follows until
+	// chance first takes over
+	Path []string `json:"path"`
+	// Strategy holds the choice of every decision node the optimal strategy
+	// can reach, depth first
+	Strategy []DecisionTreeChoice `json:"strategy"`
+	// Outcomes holds the terminal nodes the optimal strategy can reach, most
+	// probable first
+	Outcomes []DecisionTreeOutcome   `json:"outcomes"`
+	Nodes    []EvaluatedDecisionNode `json:"nodes"`
+	Summary  string                  `json:"summary"`
+	VisualID string                  `json:"visual_id"`
+}
+
+// DecisionTreeChoice is the optimal choice at a decision node
+type DecisionTreeChoice struct {
+	Node        string  `json:"node"`
+	Label       string  `json:"label,omitempty"`
+	Choice      string  `json:"choice"`
+	ChoiceLabel string  `json:"choice_label,omitempty"`
+	Value       float64 `json:"value"`
+	// ReachProbability is the chance that the optimal strategy faces the
+	// decision
+	ReachProbability float64 `json:"reach_probability"`
+}
+
+// DecisionTreeOutcome is a terminal node the optimal strategy can reach,
+// with the total payoff on the way from the root
+type DecisionTreeOutcome struct {
+	Node        string  `json:"node"`
+	Label       string  `json:"label,omitempty"`
+	Probability float64 `json:"probability"`
+	Payoff      float64 `json:"payoff"`
+}
+
+// EvaluatedDecisionNode is a node of a decision tree with its rolled-back
+// value
+type EvaluatedDecisionNode struct {
+	ID               string  `json:"id"`
+	Type             string  `json:"type"`
+	Value            float64 `json:"value"`
+	Choice           string  `json:"choice,omitempty"`
+	Optimal          bool    `json:"optimal"`
+	ReachProbability float64 `json:"reach_probability"`
+}
+
+// SimulateDecisionRequest simulates the outcomes of the options of a
+// recorded decision over many futures
+type SimulateDecisionRequest struct {
+	SessionID  string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID string          `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision whose options are simulated"`
+	Outcomes   []OptionOutcome `json:"outcomes,omitempty" description:"Outcome distributions of options, replacing those recorded"`
+	Futures    int             `json:"futures,omitempty" jsonschema:"minimum=1,maximum=1000000" description:"Futures to simulate, each drawing every option's outcome (default 10000)"`
+	Objective  string          `json:"objective,omitempty" jsonschema:"enum=maximize|minimize" description:"Whether outcomes are gains to maximize or costs to minimize (default maximize)"`
+	Alpha      float64         `json:"alpha,omitempty" jsonschema:"minimum=0,maximum=1" description:"Share of the worst futures the CVaR averages (default 0.05)"`
+	Seed       int64           `json:"seed,omitempty" description:"Seed of the simulation's randomness, for reproducible runs (default random)"`
+}
+
+// OptionOutcome sets the outcome distribution of an option
+type OptionOutcome struct {
+	Option  string              `json:"option" jsonschema:"required" description:"Name of the option"`
+	Outcome OutcomeDistribution `json:"outcome" jsonschema:"required" description:"Distribution of the option's outcome"`
+}
+
+// SimulateDecisionResponse reports the simulation stored on a decision
+type SimulateDecisionResponse struct {
+	DecisionID string             `json:"decision_id"`
+	Status     string             `json:"status"`
+	Simulation DecisionSimulation `json:"simulation"`
+}
+
+// DecisionSimulation is a simulation of the outcomes of a decision's options
+// over many futures, its options ordered by their chance of being best
+type DecisionSimulation struct {
+	Futures        int               `json:"futures"`
+	Objective      string            `json:"objective"`
+	Alpha          float64           `json:"alpha"`
+	Seed           int64             `json:"seed"`
+	Options        []SimulatedOption `json:"options"`
+	Recommendation string            `json:"recommendation"`
+}
+
+// SimulatedOption is the simulated outcome of an option: its chance of being
+// the best option in a future, sharing ties, its expected value and spread,
+// its 5th, 50th and 95th percentiles, and its value at risk and CVaR, the
+// mean of its worst alpha of futures
+type SimulatedOption struct {
+	Option          string  `json:"option"`
+	ProbabilityBest float64 `json:"probability_best"`
+	ExpectedValue   float64 `json:"expected_value"`
+	StdDev          float64 `json:"std_dev"`
+	P5              float64 `json:"p5"`
+	P50             float64 `json:"p50"`
+	P95             float64 `json:"p95"`
+	VaR             float64 `json:"var"`
+	CVaR            float64 `json:"cvar"`
+}
+
+// HybridDecisionRequest runs a stochastic algorithm on the options of a
+// recorded decision, framed as the arms of a bandit or the actions of an MDP
+type HybridDecisionRequest struct {
+	SessionID  string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID string `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision"`
+	Algorithm  string `json:"algorithm,omitempty" jsonschema:"enum=bandit|mdp" description:"How the options are framed: bandit, as arms paying out their outcome distribution or, without one, 1 with their probability of success; or mdp, as actions succeeding with their probability of success (default 1) for their score in the decision's ranking or, unranked, their expected value (default bandit)"`
+	Strategy   string `json:"strategy,omitempty" jsonschema:"enum=epsilon_greedy|ucb1|thompson" description:"Arm selection strategy of the bandit (default epsilon_greedy)"`
+	Steps      int    `json:"steps,omitempty" jsonschema:"minimum=1" description:"Pulls the bandit plays (default 1000)"`
+	Samples    int    `json:"samples,omitempty" jsonschema:"minimum=1,maximum=100000" description:"Outcomes drawn from an option's outcome distribution for its arm to resample, unless normal (default 1000)"`
+	Seed       int64  `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+}
+
+// HybridDecisionResponse reports the statistical result of the run, recorded
+// like a run of the bandit or MDP tool, and the framework-style ranking and
+// recommendation stored on the decision
+type HybridDecisionResponse struct {
+	DecisionID string          `json:"decision_id"`
+	Status     string          `json:"status"`
+	Bandit     *BanditResponse `json:"bandit,omitempty"`
+	MDP        *MDPResponse    `json:"mdp,omitempty"`
+	Decision   HybridDecision  `json:"decision"`
+}
+
+// HybridDecision is a decision's options ranked by the estimates of a
+// stochastic algorithm, recorded under AlgorithmID
+type HybridDecision struct {
+	Algorithm      string         `json:"algorithm"`
+	AlgorithmID    string         `json:"algorithm_id"`
+	Options        []HybridOption `json:"options"`
+	Recommendation string         `json:"recommendation"`
+}
+
+// HybridOption is an option's estimate: its average reward over its pulls of
+// the bandit, or its Q-value in the MDP
+type HybridOption struct {
+	Rank     int     `json:"rank"`
+	Option   string  `json:"option"`
+	Estimate float64 `json:"estimate"`
+	Pulls    int     `json:"pulls,omitempty"`
+}
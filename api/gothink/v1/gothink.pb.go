@@ -0,0 +1,4872 @@
+// GoThink gRPC API. The services serve a subset of the HTTP API under
+// /api/v1, listed in the README, and work on the same storage; requests are
+// scoped to the tenant named by the x-tenant-id metadata key, like the
+// X-Tenant-ID header of the HTTP API.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.3
+// 	protoc        (unknown)
+// source: api/gothink/v1/gothink.proto
+
+package gothinkv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SequentialThinkingRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	SessionId         string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Thought           string                 `protobuf:"bytes,2,opt,name=thought,proto3" json:"thought,omitempty"`
+	ThoughtNumber     int32                  `protobuf:"varint,3,opt,name=thought_number,json=thoughtNumber,proto3" json:"thought_number,omitempty"`
+	TotalThoughts     int32                  `protobuf:"varint,4,opt,name=total_thoughts,json=totalThoughts,proto3" json:"total_thoughts,omitempty"`
+	NextThoughtNeeded bool                   `protobuf:"varint,5,opt,name=next_thought_needed,json=nextThoughtNeeded,proto3" json:"next_thought_needed,omitempty"`
+	IsRevision        bool                   `protobuf:"varint,6,opt,name=is_revision,json=isRevision,proto3" json:"is_revision,omitempty"`
+	RevisesThought    *int32                 `protobuf:"varint,7,opt,name=revises_thought,json=revisesThought,proto3,oneof" json:"revises_thought,omitempty"`
+	BranchFromThought *int32                 `protobuf:"varint,8,opt,name=branch_from_thought,json=branchFromThought,proto3,oneof" json:"branch_from_thought,omitempty"`
+	BranchId          string                 `protobuf:"bytes,9,opt,name=branch_id,json=branchId,proto3" json:"branch_id,omitempty"`
+	NeedsMoreThoughts bool                   `protobuf:"varint,10,opt,name=needs_more_thoughts,json=needsMoreThoughts,proto3" json:"needs_more_thoughts,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SequentialThinkingRequest) Reset() {
+	*x = SequentialThinkingRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SequentialThinkingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SequentialThinkingRequest) ProtoMessage() {}
+
+func (x *SequentialThinkingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SequentialThinkingRequest.ProtoReflect.Descriptor instead.
+func (*SequentialThinkingRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SequentialThinkingRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SequentialThinkingRequest) GetThought() string {
+	if x != nil {
+		return x.Thought
+	}
+	return ""
+}
+
+func (x *SequentialThinkingRequest) GetThoughtNumber() int32 {
+	if x != nil {
+		return x.ThoughtNumber
+	}
+	return 0
+}
+
+func (x *SequentialThinkingRequest) GetTotalThoughts() int32 {
+	if x != nil {
+		return x.TotalThoughts
+	}
+	return 0
+}
+
+func (x *SequentialThinkingRequest) GetNextThoughtNeeded() bool {
+	if x != nil {
+		return x.NextThoughtNeeded
+	}
+	return false
+}
+
+func (x *SequentialThinkingRequest) GetIsRevision() bool {
+	if x != nil {
+		return x.IsRevision
+	}
+	return false
+}
+
+func (x *SequentialThinkingRequest) GetRevisesThought() int32 {
+	if x != nil && x.RevisesThought != nil {
+		return *x.RevisesThought
+	}
+	return 0
+}
+
+func (x *SequentialThinkingRequest) GetBranchFromThought() int32 {
+	if x != nil && x.BranchFromThought != nil {
+		return *x.BranchFromThought
+	}
+	return 0
+}
+
+func (x *SequentialThinkingRequest) GetBranchId() string {
+	if x != nil {
+		return x.BranchId
+	}
+	return ""
+}
+
+func (x *SequentialThinkingRequest) GetNeedsMoreThoughts() bool {
+	if x != nil {
+		return x.NeedsMoreThoughts
+	}
+	return false
+}
+
+type SequentialThinkingResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ThoughtId         string                 `protobuf:"bytes,1,opt,name=thought_id,json=thoughtId,proto3" json:"thought_id,omitempty"`
+	Status            string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	SessionId         string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TotalThoughts     int32                  `protobuf:"varint,4,opt,name=total_thoughts,json=totalThoughts,proto3" json:"total_thoughts,omitempty"`
+	RemainingThoughts int32                  `protobuf:"varint,5,opt,name=remaining_thoughts,json=remainingThoughts,proto3" json:"remaining_thoughts,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SequentialThinkingResponse) Reset() {
+	*x = SequentialThinkingResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SequentialThinkingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SequentialThinkingResponse) ProtoMessage() {}
+
+func (x *SequentialThinkingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SequentialThinkingResponse.ProtoReflect.Descriptor instead.
+func (*SequentialThinkingResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SequentialThinkingResponse) GetThoughtId() string {
+	if x != nil {
+		return x.ThoughtId
+	}
+	return ""
+}
+
+func (x *SequentialThinkingResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SequentialThinkingResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SequentialThinkingResponse) GetTotalThoughts() int32 {
+	if x != nil {
+		return x.TotalThoughts
+	}
+	return 0
+}
+
+func (x *SequentialThinkingResponse) GetRemainingThoughts() int32 {
+	if x != nil {
+		return x.RemainingThoughts
+	}
+	return 0
+}
+
+type MentalModelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	ModelName     string                 `protobuf:"bytes,2,opt,name=model_name,json=modelName,proto3" json:"model_name,omitempty"`
+	Problem       string                 `protobuf:"bytes,3,opt,name=problem,proto3" json:"problem,omitempty"`
+	Steps         []string               `protobuf:"bytes,4,rep,name=steps,proto3" json:"steps,omitempty"`
+	Reasoning     string                 `protobuf:"bytes,5,opt,name=reasoning,proto3" json:"reasoning,omitempty"`
+	Conclusion    string                 `protobuf:"bytes,6,opt,name=conclusion,proto3" json:"conclusion,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,7,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MentalModelRequest) Reset() {
+	*x = MentalModelRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MentalModelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MentalModelRequest) ProtoMessage() {}
+
+func (x *MentalModelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MentalModelRequest.ProtoReflect.Descriptor instead.
+func (*MentalModelRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MentalModelRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *MentalModelRequest) GetModelName() string {
+	if x != nil {
+		return x.ModelName
+	}
+	return ""
+}
+
+func (x *MentalModelRequest) GetProblem() string {
+	if x != nil {
+		return x.Problem
+	}
+	return ""
+}
+
+func (x *MentalModelRequest) GetSteps() []string {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *MentalModelRequest) GetReasoning() string {
+	if x != nil {
+		return x.Reasoning
+	}
+	return ""
+}
+
+func (x *MentalModelRequest) GetConclusion() string {
+	if x != nil {
+		return x.Conclusion
+	}
+	return ""
+}
+
+func (x *MentalModelRequest) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+type MentalModelResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ModelId           string                 `protobuf:"bytes,1,opt,name=model_id,json=modelId,proto3" json:"model_id,omitempty"`
+	Status            string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	HasSteps          bool                   `protobuf:"varint,3,opt,name=has_steps,json=hasSteps,proto3" json:"has_steps,omitempty"`
+	HasConclusion     bool                   `protobuf:"varint,4,opt,name=has_conclusion,json=hasConclusion,proto3" json:"has_conclusion,omitempty"`
+	SessionId         string                 `protobuf:"bytes,5,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TotalMentalModels int32                  `protobuf:"varint,6,opt,name=total_mental_models,json=totalMentalModels,proto3" json:"total_mental_models,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *MentalModelResponse) Reset() {
+	*x = MentalModelResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MentalModelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MentalModelResponse) ProtoMessage() {}
+
+func (x *MentalModelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MentalModelResponse.ProtoReflect.Descriptor instead.
+func (*MentalModelResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MentalModelResponse) GetModelId() string {
+	if x != nil {
+		return x.ModelId
+	}
+	return ""
+}
+
+func (x *MentalModelResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MentalModelResponse) GetHasSteps() bool {
+	if x != nil {
+		return x.HasSteps
+	}
+	return false
+}
+
+func (x *MentalModelResponse) GetHasConclusion() bool {
+	if x != nil {
+		return x.HasConclusion
+	}
+	return false
+}
+
+func (x *MentalModelResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *MentalModelResponse) GetTotalMentalModels() int32 {
+	if x != nil {
+		return x.TotalMentalModels
+	}
+	return 0
+}
+
+type DebuggingApproachRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	ApproachName  string                 `protobuf:"bytes,2,opt,name=approach_name,json=approachName,proto3" json:"approach_name,omitempty"`
+	Issue         string                 `protobuf:"bytes,3,opt,name=issue,proto3" json:"issue,omitempty"`
+	Steps         []string               `protobuf:"bytes,4,rep,name=steps,proto3" json:"steps,omitempty"`
+	Findings      string                 `protobuf:"bytes,5,opt,name=findings,proto3" json:"findings,omitempty"`
+	Resolution    string                 `protobuf:"bytes,6,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DebuggingApproachRequest) Reset() {
+	*x = DebuggingApproachRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DebuggingApproachRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebuggingApproachRequest) ProtoMessage() {}
+
+func (x *DebuggingApproachRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebuggingApproachRequest.ProtoReflect.Descriptor instead.
+func (*DebuggingApproachRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DebuggingApproachRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *DebuggingApproachRequest) GetApproachName() string {
+	if x != nil {
+		return x.ApproachName
+	}
+	return ""
+}
+
+func (x *DebuggingApproachRequest) GetIssue() string {
+	if x != nil {
+		return x.Issue
+	}
+	return ""
+}
+
+func (x *DebuggingApproachRequest) GetSteps() []string {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *DebuggingApproachRequest) GetFindings() string {
+	if x != nil {
+		return x.Findings
+	}
+	return ""
+}
+
+func (x *DebuggingApproachRequest) GetResolution() string {
+	if x != nil {
+		return x.Resolution
+	}
+	return ""
+}
+
+type DebuggingApproachResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApproachId    string                 `protobuf:"bytes,1,opt,name=approach_id,json=approachId,proto3" json:"approach_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	HasFindings   bool                   `protobuf:"varint,3,opt,name=has_findings,json=hasFindings,proto3" json:"has_findings,omitempty"`
+	HasResolution bool                   `protobuf:"varint,4,opt,name=has_resolution,json=hasResolution,proto3" json:"has_resolution,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DebuggingApproachResponse) Reset() {
+	*x = DebuggingApproachResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DebuggingApproachResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebuggingApproachResponse) ProtoMessage() {}
+
+func (x *DebuggingApproachResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebuggingApproachResponse.ProtoReflect.Descriptor instead.
+func (*DebuggingApproachResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DebuggingApproachResponse) GetApproachId() string {
+	if x != nil {
+		return x.ApproachId
+	}
+	return ""
+}
+
+func (x *DebuggingApproachResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DebuggingApproachResponse) GetHasFindings() bool {
+	if x != nil {
+		return x.HasFindings
+	}
+	return false
+}
+
+func (x *DebuggingApproachResponse) GetHasResolution() bool {
+	if x != nil {
+		return x.HasResolution
+	}
+	return false
+}
+
+type MDPRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Problem       string                 `protobuf:"bytes,2,opt,name=problem,proto3" json:"problem,omitempty"`
+	States        int32                  `protobuf:"varint,3,opt,name=states,proto3" json:"states,omitempty"`
+	Actions       []string               `protobuf:"bytes,4,rep,name=actions,proto3" json:"actions,omitempty"`
+	Gamma         float64                `protobuf:"fixed64,5,opt,name=gamma,proto3" json:"gamma,omitempty"`
+	MaxIterations int32                  `protobuf:"varint,8,opt,name=max_iterations,json=maxIterations,proto3" json:"max_iterations,omitempty"`
+	// transitions are the model: the outcomes of each action in each state
+	Transitions []*MDPTransition `protobuf:"bytes,9,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	// method is value_iteration (the default) or policy_iteration
+	Method        string  `protobuf:"bytes,10,opt,name=method,proto3" json:"method,omitempty"`
+	Tolerance     float64 `protobuf:"fixed64,11,opt,name=tolerance,proto3" json:"tolerance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MDPRequest) Reset() {
+	*x = MDPRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MDPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MDPRequest) ProtoMessage() {}
+
+func (x *MDPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MDPRequest.ProtoReflect.Descriptor instead.
+func (*MDPRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MDPRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *MDPRequest) GetProblem() string {
+	if x != nil {
+		return x.Problem
+	}
+	return ""
+}
+
+func (x *MDPRequest) GetStates() int32 {
+	if x != nil {
+		return x.States
+	}
+	return 0
+}
+
+func (x *MDPRequest) GetActions() []string {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *MDPRequest) GetGamma() float64 {
+	if x != nil {
+		return x.Gamma
+	}
+	return 0
+}
+
+func (x *MDPRequest) GetMaxIterations() int32 {
+	if x != nil {
+		return x.MaxIterations
+	}
+	return 0
+}
+
+func (x *MDPRequest) GetTransitions() []*MDPTransition {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+func (x *MDPRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *MDPRequest) GetTolerance() float64 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+type MDPTransition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	NextState     string                 `protobuf:"bytes,3,opt,name=next_state,json=nextState,proto3" json:"next_state,omitempty"`
+	Probability   float64                `protobuf:"fixed64,4,opt,name=probability,proto3" json:"probability,omitempty"`
+	Reward        float64                `protobuf:"fixed64,5,opt,name=reward,proto3" json:"reward,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MDPTransition) Reset() {
+	*x = MDPTransition{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MDPTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MDPTransition) ProtoMessage() {}
+
+func (x *MDPTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MDPTransition.ProtoReflect.Descriptor instead.
+func (*MDPTransition) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MDPTransition) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *MDPTransition) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *MDPTransition) GetNextState() string {
+	if x != nil {
+		return x.NextState
+	}
+	return ""
+}
+
+func (x *MDPTransition) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+func (x *MDPTransition) GetReward() float64 {
+	if x != nil {
+		return x.Reward
+	}
+	return 0
+}
+
+type MDPResponse struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	AlgorithmId   string                      `protobuf:"bytes,1,opt,name=algorithm_id,json=algorithmId,proto3" json:"algorithm_id,omitempty"`
+	Status        string                      `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Summary       string                      `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	HasResult     bool                        `protobuf:"varint,4,opt,name=has_result,json=hasResult,proto3" json:"has_result,omitempty"`
+	Converged     bool                        `protobuf:"varint,5,opt,name=converged,proto3" json:"converged,omitempty"`
+	Iterations    int32                       `protobuf:"varint,6,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	Policy        map[string]string           `protobuf:"bytes,7,rep,name=policy,proto3" json:"policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ValueFunction map[string]float64          `protobuf:"bytes,8,rep,name=value_function,json=valueFunction,proto3" json:"value_function,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	QValues       map[string]*MDPActionValues `protobuf:"bytes,9,rep,name=q_values,json=qValues,proto3" json:"q_values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Convergence   *Convergence                `protobuf:"bytes,10,opt,name=convergence,proto3" json:"convergence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MDPResponse) Reset() {
+	*x = MDPResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MDPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MDPResponse) ProtoMessage() {}
+
+func (x *MDPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MDPResponse.ProtoReflect.Descriptor instead.
+func (*MDPResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MDPResponse) GetAlgorithmId() string {
+	if x != nil {
+		return x.AlgorithmId
+	}
+	return ""
+}
+
+func (x *MDPResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MDPResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *MDPResponse) GetHasResult() bool {
+	if x != nil {
+		return x.HasResult
+	}
+	return false
+}
+
+func (x *MDPResponse) GetConverged() bool {
+	if x != nil {
+		return x.Converged
+	}
+	return false
+}
+
+func (x *MDPResponse) GetIterations() int32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *MDPResponse) GetPolicy() map[string]string {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+func (x *MDPResponse) GetValueFunction() map[string]float64 {
+	if x != nil {
+		return x.ValueFunction
+	}
+	return nil
+}
+
+func (x *MDPResponse) GetQValues() map[string]*MDPActionValues {
+	if x != nil {
+		return x.QValues
+	}
+	return nil
+}
+
+func (x *MDPResponse) GetConvergence() *Convergence {
+	if x != nil {
+		return x.Convergence
+	}
+	return nil
+}
+
+// MDPActionValues holds the Q-value of each action of a state
+type MDPActionValues struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        map[string]float64     `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MDPActionValues) Reset() {
+	*x = MDPActionValues{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MDPActionValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MDPActionValues) ProtoMessage() {}
+
+func (x *MDPActionValues) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MDPActionValues.ProtoReflect.Descriptor instead.
+func (*MDPActionValues) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MDPActionValues) GetValues() map[string]float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// Convergence reports how a run ended: whether it converged, why it stopped
+// and the residual of each iteration or checkpoint
+type Convergence struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Method     string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Iterations int32                  `protobuf:"varint,2,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	Converged  bool                   `protobuf:"varint,3,opt,name=converged,proto3" json:"converged,omitempty"`
+	Tolerance  float64                `protobuf:"fixed64,4,opt,name=tolerance,proto3" json:"tolerance,omitempty"`
+	Residual   float64                `protobuf:"fixed64,5,opt,name=residual,proto3" json:"residual,omitempty"`
+	// stopping_reason is tolerance, policy_stable, plateau, max_iterations,
+	// time_limit or exact
+	StoppingReason string    `protobuf:"bytes,6,opt,name=stopping_reason,json=stoppingReason,proto3" json:"stopping_reason,omitempty"`
+	Residuals      []float64 `protobuf:"fixed64,7,rep,packed,name=residuals,proto3" json:"residuals,omitempty"`
+	RHat           float64   `protobuf:"fixed64,8,opt,name=r_hat,json=rHat,proto3" json:"r_hat,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Convergence) Reset() {
+	*x = Convergence{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Convergence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Convergence) ProtoMessage() {}
+
+func (x *Convergence) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Convergence.ProtoReflect.Descriptor instead.
+func (*Convergence) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Convergence) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Convergence) GetIterations() int32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *Convergence) GetConverged() bool {
+	if x != nil {
+		return x.Converged
+	}
+	return false
+}
+
+func (x *Convergence) GetTolerance() float64 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+func (x *Convergence) GetResidual() float64 {
+	if x != nil {
+		return x.Residual
+	}
+	return 0
+}
+
+func (x *Convergence) GetStoppingReason() string {
+	if x != nil {
+		return x.StoppingReason
+	}
+	return ""
+}
+
+func (x *Convergence) GetResiduals() []float64 {
+	if x != nil {
+		return x.Residuals
+	}
+	return nil
+}
+
+func (x *Convergence) GetRHat() float64 {
+	if x != nil {
+		return x.RHat
+	}
+	return 0
+}
+
+type MCTSRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	SessionId           string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Problem             string                 `protobuf:"bytes,2,opt,name=problem,proto3" json:"problem,omitempty"`
+	Simulations         int32                  `protobuf:"varint,3,opt,name=simulations,proto3" json:"simulations,omitempty"`
+	ExplorationConstant float64                `protobuf:"fixed64,4,opt,name=exploration_constant,json=explorationConstant,proto3" json:"exploration_constant,omitempty"`
+	MaxDepth            int32                  `protobuf:"varint,5,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	TimeLimit           int32                  `protobuf:"varint,6,opt,name=time_limit,json=timeLimit,proto3" json:"time_limit,omitempty"`
+	// root_state and states are the game: its states with their legal moves
+	RootState     string       `protobuf:"bytes,7,opt,name=root_state,json=rootState,proto3" json:"root_state,omitempty"`
+	States        []*MCTSState `protobuf:"bytes,8,rep,name=states,proto3" json:"states,omitempty"`
+	Seed          int64        `protobuf:"varint,9,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCTSRequest) Reset() {
+	*x = MCTSRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCTSRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCTSRequest) ProtoMessage() {}
+
+func (x *MCTSRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCTSRequest.ProtoReflect.Descriptor instead.
+func (*MCTSRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MCTSRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *MCTSRequest) GetProblem() string {
+	if x != nil {
+		return x.Problem
+	}
+	return ""
+}
+
+func (x *MCTSRequest) GetSimulations() int32 {
+	if x != nil {
+		return x.Simulations
+	}
+	return 0
+}
+
+func (x *MCTSRequest) GetExplorationConstant() float64 {
+	if x != nil {
+		return x.ExplorationConstant
+	}
+	return 0
+}
+
+func (x *MCTSRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *MCTSRequest) GetTimeLimit() int32 {
+	if x != nil {
+		return x.TimeLimit
+	}
+	return 0
+}
+
+func (x *MCTSRequest) GetRootState() string {
+	if x != nil {
+		return x.RootState
+	}
+	return ""
+}
+
+func (x *MCTSRequest) GetStates() []*MCTSState {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *MCTSRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// MCTSState is a state of a game; rewards are from player 1's point of view
+type MCTSState struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// player is the player to move: 1 (the default) maximizes, 2 minimizes
+	Player        int32       `protobuf:"varint,2,opt,name=player,proto3" json:"player,omitempty"`
+	Reward        float64     `protobuf:"fixed64,3,opt,name=reward,proto3" json:"reward,omitempty"`
+	Moves         []*MCTSMove `protobuf:"bytes,4,rep,name=moves,proto3" json:"moves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCTSState) Reset() {
+	*x = MCTSState{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCTSState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCTSState) ProtoMessage() {}
+
+func (x *MCTSState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCTSState.ProtoReflect.Descriptor instead.
+func (*MCTSState) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MCTSState) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MCTSState) GetPlayer() int32 {
+	if x != nil {
+		return x.Player
+	}
+	return 0
+}
+
+func (x *MCTSState) GetReward() float64 {
+	if x != nil {
+		return x.Reward
+	}
+	return 0
+}
+
+func (x *MCTSState) GetMoves() []*MCTSMove {
+	if x != nil {
+		return x.Moves
+	}
+	return nil
+}
+
+type MCTSMove struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Move          string                 `protobuf:"bytes,1,opt,name=move,proto3" json:"move,omitempty"`
+	NextState     string                 `protobuf:"bytes,2,opt,name=next_state,json=nextState,proto3" json:"next_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCTSMove) Reset() {
+	*x = MCTSMove{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCTSMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCTSMove) ProtoMessage() {}
+
+func (x *MCTSMove) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCTSMove.ProtoReflect.Descriptor instead.
+func (*MCTSMove) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MCTSMove) GetMove() string {
+	if x != nil {
+		return x.Move
+	}
+	return ""
+}
+
+func (x *MCTSMove) GetNextState() string {
+	if x != nil {
+		return x.NextState
+	}
+	return ""
+}
+
+type MCTSResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AlgorithmId        string                 `protobuf:"bytes,1,opt,name=algorithm_id,json=algorithmId,proto3" json:"algorithm_id,omitempty"`
+	Status             string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Summary            string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	HasResult          bool                   `protobuf:"varint,4,opt,name=has_result,json=hasResult,proto3" json:"has_result,omitempty"`
+	BestAction         string                 `protobuf:"bytes,5,opt,name=best_action,json=bestAction,proto3" json:"best_action,omitempty"`
+	TreeStats          *structpb.Struct       `protobuf:"bytes,6,opt,name=tree_stats,json=treeStats,proto3" json:"tree_stats,omitempty"`
+	Actions            []*MCTSActionStats     `protobuf:"bytes,7,rep,name=actions,proto3" json:"actions,omitempty"`
+	PrincipalVariation []string               `protobuf:"bytes,8,rep,name=principal_variation,json=principalVariation,proto3" json:"principal_variation,omitempty"`
+	Simulations        int32                  `protobuf:"varint,9,opt,name=simulations,proto3" json:"simulations,omitempty"`
+	Convergence        *Convergence           `protobuf:"bytes,10,opt,name=convergence,proto3" json:"convergence,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MCTSResponse) Reset() {
+	*x = MCTSResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCTSResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCTSResponse) ProtoMessage() {}
+
+func (x *MCTSResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCTSResponse.ProtoReflect.Descriptor instead.
+func (*MCTSResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MCTSResponse) GetAlgorithmId() string {
+	if x != nil {
+		return x.AlgorithmId
+	}
+	return ""
+}
+
+func (x *MCTSResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MCTSResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *MCTSResponse) GetHasResult() bool {
+	if x != nil {
+		return x.HasResult
+	}
+	return false
+}
+
+func (x *MCTSResponse) GetBestAction() string {
+	if x != nil {
+		return x.BestAction
+	}
+	return ""
+}
+
+func (x *MCTSResponse) GetTreeStats() *structpb.Struct {
+	if x != nil {
+		return x.TreeStats
+	}
+	return nil
+}
+
+func (x *MCTSResponse) GetActions() []*MCTSActionStats {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *MCTSResponse) GetPrincipalVariation() []string {
+	if x != nil {
+		return x.PrincipalVariation
+	}
+	return nil
+}
+
+func (x *MCTSResponse) GetSimulations() int32 {
+	if x != nil {
+		return x.Simulations
+	}
+	return 0
+}
+
+func (x *MCTSResponse) GetConvergence() *Convergence {
+	if x != nil {
+		return x.Convergence
+	}
+	return nil
+}
+
+type MCTSActionStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Move          string                 `protobuf:"bytes,1,opt,name=move,proto3" json:"move,omitempty"`
+	Visits        int32                  `protobuf:"varint,2,opt,name=visits,proto3" json:"visits,omitempty"`
+	Q             float64                `protobuf:"fixed64,3,opt,name=q,proto3" json:"q,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCTSActionStats) Reset() {
+	*x = MCTSActionStats{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCTSActionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCTSActionStats) ProtoMessage() {}
+
+func (x *MCTSActionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCTSActionStats.ProtoReflect.Descriptor instead.
+func (*MCTSActionStats) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MCTSActionStats) GetMove() string {
+	if x != nil {
+		return x.Move
+	}
+	return ""
+}
+
+func (x *MCTSActionStats) GetVisits() int32 {
+	if x != nil {
+		return x.Visits
+	}
+	return 0
+}
+
+func (x *MCTSActionStats) GetQ() float64 {
+	if x != nil {
+		return x.Q
+	}
+	return 0
+}
+
+type BanditRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Problem   string                 `protobuf:"bytes,2,opt,name=problem,proto3" json:"problem,omitempty"`
+	// strategy is epsilon_greedy (the default), ucb1 or thompson
+	Strategy      string       `protobuf:"bytes,4,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Epsilon       float64      `protobuf:"fixed64,5,opt,name=epsilon,proto3" json:"epsilon,omitempty"`
+	Alpha         float64      `protobuf:"fixed64,6,opt,name=alpha,proto3" json:"alpha,omitempty"`
+	Beta          float64      `protobuf:"fixed64,7,opt,name=beta,proto3" json:"beta,omitempty"`
+	Arms          []*BanditArm `protobuf:"bytes,8,rep,name=arms,proto3" json:"arms,omitempty"`
+	Steps         int32        `protobuf:"varint,9,opt,name=steps,proto3" json:"steps,omitempty"`
+	Seed          int64        `protobuf:"varint,10,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BanditRequest) Reset() {
+	*x = BanditRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanditRequest) ProtoMessage() {}
+
+func (x *BanditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanditRequest.ProtoReflect.Descriptor instead.
+func (*BanditRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BanditRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *BanditRequest) GetProblem() string {
+	if x != nil {
+		return x.Problem
+	}
+	return ""
+}
+
+func (x *BanditRequest) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *BanditRequest) GetEpsilon() float64 {
+	if x != nil {
+		return x.Epsilon
+	}
+	return 0
+}
+
+func (x *BanditRequest) GetAlpha() float64 {
+	if x != nil {
+		return x.Alpha
+	}
+	return 0
+}
+
+func (x *BanditRequest) GetBeta() float64 {
+	if x != nil {
+		return x.Beta
+	}
+	return 0
+}
+
+func (x *BanditRequest) GetArms() []*BanditArm {
+	if x != nil {
+		return x.Arms
+	}
+	return nil
+}
+
+func (x *BanditRequest) GetSteps() int32 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *BanditRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// BanditArm is an arm given by its reward distribution or observed rewards
+type BanditArm struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// distribution is bernoulli, gaussian or empirical
+	Distribution    string    `protobuf:"bytes,2,opt,name=distribution,proto3" json:"distribution,omitempty"`
+	Mean            float64   `protobuf:"fixed64,3,opt,name=mean,proto3" json:"mean,omitempty"`
+	StdDev          float64   `protobuf:"fixed64,4,opt,name=std_dev,json=stdDev,proto3" json:"std_dev,omitempty"`
+	ObservedRewards []float64 `protobuf:"fixed64,5,rep,packed,name=observed_rewards,json=observedRewards,proto3" json:"observed_rewards,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BanditArm) Reset() {
+	*x = BanditArm{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanditArm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanditArm) ProtoMessage() {}
+
+func (x *BanditArm) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanditArm.ProtoReflect.Descriptor instead.
+func (*BanditArm) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BanditArm) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BanditArm) GetDistribution() string {
+	if x != nil {
+		return x.Distribution
+	}
+	return ""
+}
+
+func (x *BanditArm) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *BanditArm) GetStdDev() float64 {
+	if x != nil {
+		return x.StdDev
+	}
+	return 0
+}
+
+func (x *BanditArm) GetObservedRewards() []float64 {
+	if x != nil {
+		return x.ObservedRewards
+	}
+	return nil
+}
+
+type ArmStatistics struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Arm            int32                  `protobuf:"varint,1,opt,name=arm,proto3" json:"arm,omitempty"`
+	Pulls          int32                  `protobuf:"varint,2,opt,name=pulls,proto3" json:"pulls,omitempty"`
+	Rewards        float64                `protobuf:"fixed64,3,opt,name=rewards,proto3" json:"rewards,omitempty"`
+	AverageReward  float64                `protobuf:"fixed64,4,opt,name=average_reward,json=averageReward,proto3" json:"average_reward,omitempty"`
+	Name           string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	ExpectedReward float64                `protobuf:"fixed64,6,opt,name=expected_reward,json=expectedReward,proto3" json:"expected_reward,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ArmStatistics) Reset() {
+	*x = ArmStatistics{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArmStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArmStatistics) ProtoMessage() {}
+
+func (x *ArmStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArmStatistics.ProtoReflect.Descriptor instead.
+func (*ArmStatistics) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ArmStatistics) GetArm() int32 {
+	if x != nil {
+		return x.Arm
+	}
+	return 0
+}
+
+func (x *ArmStatistics) GetPulls() int32 {
+	if x != nil {
+		return x.Pulls
+	}
+	return 0
+}
+
+func (x *ArmStatistics) GetRewards() float64 {
+	if x != nil {
+		return x.Rewards
+	}
+	return 0
+}
+
+func (x *ArmStatistics) GetAverageReward() float64 {
+	if x != nil {
+		return x.AverageReward
+	}
+	return 0
+}
+
+func (x *ArmStatistics) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArmStatistics) GetExpectedReward() float64 {
+	if x != nil {
+		return x.ExpectedReward
+	}
+	return 0
+}
+
+// RegretPoint is the cumulative regret after step pulls
+type RegretPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Step          int32                  `protobuf:"varint,1,opt,name=step,proto3" json:"step,omitempty"`
+	Regret        float64                `protobuf:"fixed64,2,opt,name=regret,proto3" json:"regret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegretPoint) Reset() {
+	*x = RegretPoint{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegretPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegretPoint) ProtoMessage() {}
+
+func (x *RegretPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegretPoint.ProtoReflect.Descriptor instead.
+func (*RegretPoint) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RegretPoint) GetStep() int32 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *RegretPoint) GetRegret() float64 {
+	if x != nil {
+		return x.Regret
+	}
+	return 0
+}
+
+type BanditResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AlgorithmId   string                 `protobuf:"bytes,1,opt,name=algorithm_id,json=algorithmId,proto3" json:"algorithm_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Summary       string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	HasResult     bool                   `protobuf:"varint,4,opt,name=has_result,json=hasResult,proto3" json:"has_result,omitempty"`
+	SelectedArm   int32                  `protobuf:"varint,5,opt,name=selected_arm,json=selectedArm,proto3" json:"selected_arm,omitempty"`
+	ArmStats      []*ArmStatistics       `protobuf:"bytes,6,rep,name=arm_stats,json=armStats,proto3" json:"arm_stats,omitempty"`
+	OptimalArm    int32                  `protobuf:"varint,7,opt,name=optimal_arm,json=optimalArm,proto3" json:"optimal_arm,omitempty"`
+	TotalReward   float64                `protobuf:"fixed64,8,opt,name=total_reward,json=totalReward,proto3" json:"total_reward,omitempty"`
+	Regret        float64                `protobuf:"fixed64,9,opt,name=regret,proto3" json:"regret,omitempty"`
+	RegretCurve   []*RegretPoint         `protobuf:"bytes,10,rep,name=regret_curve,json=regretCurve,proto3" json:"regret_curve,omitempty"`
+	Convergence   *Convergence           `protobuf:"bytes,11,opt,name=convergence,proto3" json:"convergence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BanditResponse) Reset() {
+	*x = BanditResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanditResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanditResponse) ProtoMessage() {}
+
+func (x *BanditResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanditResponse.ProtoReflect.Descriptor instead.
+func (*BanditResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BanditResponse) GetAlgorithmId() string {
+	if x != nil {
+		return x.AlgorithmId
+	}
+	return ""
+}
+
+func (x *BanditResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BanditResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *BanditResponse) GetHasResult() bool {
+	if x != nil {
+		return x.HasResult
+	}
+	return false
+}
+
+func (x *BanditResponse) GetSelectedArm() int32 {
+	if x != nil {
+		return x.SelectedArm
+	}
+	return 0
+}
+
+func (x *BanditResponse) GetArmStats() []*ArmStatistics {
+	if x != nil {
+		return x.ArmStats
+	}
+	return nil
+}
+
+func (x *BanditResponse) GetOptimalArm() int32 {
+	if x != nil {
+		return x.OptimalArm
+	}
+	return 0
+}
+
+func (x *BanditResponse) GetTotalReward() float64 {
+	if x != nil {
+		return x.TotalReward
+	}
+	return 0
+}
+
+func (x *BanditResponse) GetRegret() float64 {
+	if x != nil {
+		return x.Regret
+	}
+	return 0
+}
+
+func (x *BanditResponse) GetRegretCurve() []*RegretPoint {
+	if x != nil {
+		return x.RegretCurve
+	}
+	return nil
+}
+
+func (x *BanditResponse) GetConvergence() *Convergence {
+	if x != nil {
+		return x.Convergence
+	}
+	return nil
+}
+
+type BayesianOptimizationRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Problem   string                 `protobuf:"bytes,2,opt,name=problem,proto3" json:"problem,omitempty"`
+	// acquisition_function is ei (the default), ucb or pi
+	AcquisitionFunction string `protobuf:"bytes,3,opt,name=acquisition_function,json=acquisitionFunction,proto3" json:"acquisition_function,omitempty"`
+	// kernel is rbf, matern32 or matern52 (the default)
+	Kernel            string               `protobuf:"bytes,4,opt,name=kernel,proto3" json:"kernel,omitempty"`
+	Iterations        int32                `protobuf:"varint,5,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	ExplorationWeight float64              `protobuf:"fixed64,6,opt,name=exploration_weight,json=explorationWeight,proto3" json:"exploration_weight,omitempty"`
+	Parameters        []*BayesianParameter `protobuf:"bytes,7,rep,name=parameters,proto3" json:"parameters,omitempty"`
+	// objective is an arithmetic expression over the parameters; without one,
+	// only the history is fitted
+	Objective string                 `protobuf:"bytes,8,opt,name=objective,proto3" json:"objective,omitempty"`
+	History   []*BayesianObservation `protobuf:"bytes,9,rep,name=history,proto3" json:"history,omitempty"`
+	// goal is maximize (the default) or minimize
+	Goal          string  `protobuf:"bytes,10,opt,name=goal,proto3" json:"goal,omitempty"`
+	InitialPoints int32   `protobuf:"varint,11,opt,name=initial_points,json=initialPoints,proto3" json:"initial_points,omitempty"`
+	LengthScale   float64 `protobuf:"fixed64,12,opt,name=length_scale,json=lengthScale,proto3" json:"length_scale,omitempty"`
+	Noise         float64 `protobuf:"fixed64,13,opt,name=noise,proto3" json:"noise,omitempty"`
+	Seed          int64   `protobuf:"varint,14,opt,name=seed,proto3" json:"seed,omitempty"`
+	// The run has converged once the best value improves by at most tolerance
+	// over patience evaluations; stop_at_plateau stops it there
+	Tolerance     float64 `protobuf:"fixed64,15,opt,name=tolerance,proto3" json:"tolerance,omitempty"`
+	Patience      int32   `protobuf:"varint,16,opt,name=patience,proto3" json:"patience,omitempty"`
+	StopAtPlateau bool    `protobuf:"varint,17,opt,name=stop_at_plateau,json=stopAtPlateau,proto3" json:"stop_at_plateau,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BayesianOptimizationRequest) Reset() {
+	*x = BayesianOptimizationRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BayesianOptimizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BayesianOptimizationRequest) ProtoMessage() {}
+
+func (x *BayesianOptimizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BayesianOptimizationRequest.ProtoReflect.Descriptor instead.
+func (*BayesianOptimizationRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BayesianOptimizationRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationRequest) GetProblem() string {
+	if x != nil {
+		return x.Problem
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationRequest) GetAcquisitionFunction() string {
+	if x != nil {
+		return x.AcquisitionFunction
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationRequest) GetKernel() string {
+	if x != nil {
+		return x.Kernel
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationRequest) GetIterations() int32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetExplorationWeight() float64 {
+	if x != nil {
+		return x.ExplorationWeight
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetParameters() []*BayesianParameter {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *BayesianOptimizationRequest) GetObjective() string {
+	if x != nil {
+		return x.Objective
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationRequest) GetHistory() []*BayesianObservation {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *BayesianOptimizationRequest) GetGoal() string {
+	if x != nil {
+		return x.Goal
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationRequest) GetInitialPoints() int32 {
+	if x != nil {
+		return x.InitialPoints
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetLengthScale() float64 {
+	if x != nil {
+		return x.LengthScale
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetNoise() float64 {
+	if x != nil {
+		return x.Noise
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetTolerance() float64 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetPatience() int32 {
+	if x != nil {
+		return x.Patience
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationRequest) GetStopAtPlateau() bool {
+	if x != nil {
+		return x.StopAtPlateau
+	}
+	return false
+}
+
+// BayesianParameter is a bounded dimension of the search space
+type BayesianParameter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Min           float64                `protobuf:"fixed64,2,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,3,opt,name=max,proto3" json:"max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BayesianParameter) Reset() {
+	*x = BayesianParameter{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BayesianParameter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BayesianParameter) ProtoMessage() {}
+
+func (x *BayesianParameter) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BayesianParameter.ProtoReflect.Descriptor instead.
+func (*BayesianParameter) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *BayesianParameter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BayesianParameter) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *BayesianParameter) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+// BayesianObservation is an observed evaluation of the objective
+type BayesianObservation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parameters    map[string]float64     `protobuf:"bytes,1,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BayesianObservation) Reset() {
+	*x = BayesianObservation{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BayesianObservation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BayesianObservation) ProtoMessage() {}
+
+func (x *BayesianObservation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BayesianObservation.ProtoReflect.Descriptor instead.
+func (*BayesianObservation) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BayesianObservation) GetParameters() map[string]float64 {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *BayesianObservation) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// GPPosterior is the Gaussian process's mean and variance at a point
+type GPPosterior struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mean          float64                `protobuf:"fixed64,1,opt,name=mean,proto3" json:"mean,omitempty"`
+	Variance      float64                `protobuf:"fixed64,2,opt,name=variance,proto3" json:"variance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPPosterior) Reset() {
+	*x = GPPosterior{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPPosterior) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPPosterior) ProtoMessage() {}
+
+func (x *GPPosterior) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPPosterior.ProtoReflect.Descriptor instead.
+func (*GPPosterior) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GPPosterior) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *GPPosterior) GetVariance() float64 {
+	if x != nil {
+		return x.Variance
+	}
+	return 0
+}
+
+type OptimizationStep struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Iteration     int32                  `protobuf:"varint,1,opt,name=iteration,proto3" json:"iteration,omitempty"`
+	Parameters    map[string]float64     `protobuf:"bytes,2,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	Random        bool                   `protobuf:"varint,4,opt,name=random,proto3" json:"random,omitempty"`
+	Acquisition   float64                `protobuf:"fixed64,5,opt,name=acquisition,proto3" json:"acquisition,omitempty"`
+	Predicted     *GPPosterior           `protobuf:"bytes,6,opt,name=predicted,proto3" json:"predicted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptimizationStep) Reset() {
+	*x = OptimizationStep{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptimizationStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizationStep) ProtoMessage() {}
+
+func (x *OptimizationStep) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizationStep.ProtoReflect.Descriptor instead.
+func (*OptimizationStep) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *OptimizationStep) GetIteration() int32 {
+	if x != nil {
+		return x.Iteration
+	}
+	return 0
+}
+
+func (x *OptimizationStep) GetParameters() map[string]float64 {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *OptimizationStep) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *OptimizationStep) GetRandom() bool {
+	if x != nil {
+		return x.Random
+	}
+	return false
+}
+
+func (x *OptimizationStep) GetAcquisition() float64 {
+	if x != nil {
+		return x.Acquisition
+	}
+	return 0
+}
+
+func (x *OptimizationStep) GetPredicted() *GPPosterior {
+	if x != nil {
+		return x.Predicted
+	}
+	return nil
+}
+
+type BayesianOptimizationResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AlgorithmId     string                 `protobuf:"bytes,1,opt,name=algorithm_id,json=algorithmId,proto3" json:"algorithm_id,omitempty"`
+	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Summary         string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	HasResult       bool                   `protobuf:"varint,4,opt,name=has_result,json=hasResult,proto3" json:"has_result,omitempty"`
+	BestParameters  map[string]float64     `protobuf:"bytes,5,rep,name=best_parameters,json=bestParameters,proto3" json:"best_parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	BestValue       float64                `protobuf:"fixed64,6,opt,name=best_value,json=bestValue,proto3" json:"best_value,omitempty"`
+	Iterations      int32                  `protobuf:"varint,7,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	BestPosterior   *GPPosterior           `protobuf:"bytes,8,opt,name=best_posterior,json=bestPosterior,proto3" json:"best_posterior,omitempty"`
+	History         []*OptimizationStep    `protobuf:"bytes,9,rep,name=history,proto3" json:"history,omitempty"`
+	NextParameters  map[string]float64     `protobuf:"bytes,10,rep,name=next_parameters,json=nextParameters,proto3" json:"next_parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	NextAcquisition float64                `protobuf:"fixed64,11,opt,name=next_acquisition,json=nextAcquisition,proto3" json:"next_acquisition,omitempty"`
+	NextPosterior   *GPPosterior           `protobuf:"bytes,12,opt,name=next_posterior,json=nextPosterior,proto3" json:"next_posterior,omitempty"`
+	Convergence     *Convergence           `protobuf:"bytes,13,opt,name=convergence,proto3" json:"convergence,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BayesianOptimizationResponse) Reset() {
+	*x = BayesianOptimizationResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BayesianOptimizationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BayesianOptimizationResponse) ProtoMessage() {}
+
+func (x *BayesianOptimizationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BayesianOptimizationResponse.ProtoReflect.Descriptor instead.
+func (*BayesianOptimizationResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *BayesianOptimizationResponse) GetAlgorithmId() string {
+	if x != nil {
+		return x.AlgorithmId
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *BayesianOptimizationResponse) GetHasResult() bool {
+	if x != nil {
+		return x.HasResult
+	}
+	return false
+}
+
+func (x *BayesianOptimizationResponse) GetBestParameters() map[string]float64 {
+	if x != nil {
+		return x.BestParameters
+	}
+	return nil
+}
+
+func (x *BayesianOptimizationResponse) GetBestValue() float64 {
+	if x != nil {
+		return x.BestValue
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationResponse) GetIterations() int32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationResponse) GetBestPosterior() *GPPosterior {
+	if x != nil {
+		return x.BestPosterior
+	}
+	return nil
+}
+
+func (x *BayesianOptimizationResponse) GetHistory() []*OptimizationStep {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *BayesianOptimizationResponse) GetNextParameters() map[string]float64 {
+	if x != nil {
+		return x.NextParameters
+	}
+	return nil
+}
+
+func (x *BayesianOptimizationResponse) GetNextAcquisition() float64 {
+	if x != nil {
+		return x.NextAcquisition
+	}
+	return 0
+}
+
+func (x *BayesianOptimizationResponse) GetNextPosterior() *GPPosterior {
+	if x != nil {
+		return x.NextPosterior
+	}
+	return nil
+}
+
+func (x *BayesianOptimizationResponse) GetConvergence() *Convergence {
+	if x != nil {
+		return x.Convergence
+	}
+	return nil
+}
+
+type HMMRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Problem   string                 `protobuf:"bytes,2,opt,name=problem,proto3" json:"problem,omitempty"`
+	States    int32                  `protobuf:"varint,3,opt,name=states,proto3" json:"states,omitempty"`
+	// algorithm is viterbi, decoding with the given parameters, or baum_welch,
+	// fitting them first
+	Algorithm     string `protobuf:"bytes,5,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	MaxIterations int32  `protobuf:"varint,6,opt,name=max_iterations,json=maxIterations,proto3" json:"max_iterations,omitempty"`
+	// observations is the observation sequence, as symbols
+	Observations []string  `protobuf:"bytes,7,rep,name=observations,proto3" json:"observations,omitempty"`
+	Symbols      []string  `protobuf:"bytes,8,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	StateNames   []string  `protobuf:"bytes,9,rep,name=state_names,json=stateNames,proto3" json:"state_names,omitempty"`
+	Initial      []float64 `protobuf:"fixed64,10,rep,packed,name=initial,proto3" json:"initial,omitempty"`
+	// transitions and emissions hold one row per state
+	Transitions   []*HMMRow `protobuf:"bytes,11,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	Emissions     []*HMMRow `protobuf:"bytes,12,rep,name=emissions,proto3" json:"emissions,omitempty"`
+	Tolerance     float64   `protobuf:"fixed64,13,opt,name=tolerance,proto3" json:"tolerance,omitempty"`
+	Seed          int64     `protobuf:"varint,14,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HMMRequest) Reset() {
+	*x = HMMRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HMMRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HMMRequest) ProtoMessage() {}
+
+func (x *HMMRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HMMRequest.ProtoReflect.Descriptor instead.
+func (*HMMRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *HMMRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *HMMRequest) GetProblem() string {
+	if x != nil {
+		return x.Problem
+	}
+	return ""
+}
+
+func (x *HMMRequest) GetStates() int32 {
+	if x != nil {
+		return x.States
+	}
+	return 0
+}
+
+func (x *HMMRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *HMMRequest) GetMaxIterations() int32 {
+	if x != nil {
+		return x.MaxIterations
+	}
+	return 0
+}
+
+func (x *HMMRequest) GetObservations() []string {
+	if x != nil {
+		return x.Observations
+	}
+	return nil
+}
+
+func (x *HMMRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *HMMRequest) GetStateNames() []string {
+	if x != nil {
+		return x.StateNames
+	}
+	return nil
+}
+
+func (x *HMMRequest) GetInitial() []float64 {
+	if x != nil {
+		return x.Initial
+	}
+	return nil
+}
+
+func (x *HMMRequest) GetTransitions() []*HMMRow {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+func (x *HMMRequest) GetEmissions() []*HMMRow {
+	if x != nil {
+		return x.Emissions
+	}
+	return nil
+}
+
+func (x *HMMRequest) GetTolerance() float64 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+func (x *HMMRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// HMMRow is a row of probabilities of an HMM
+type HMMRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Probabilities []float64              `protobuf:"fixed64,1,rep,packed,name=probabilities,proto3" json:"probabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HMMRow) Reset() {
+	*x = HMMRow{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HMMRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HMMRow) ProtoMessage() {}
+
+func (x *HMMRow) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HMMRow.ProtoReflect.Descriptor instead.
+func (*HMMRow) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *HMMRow) GetProbabilities() []float64 {
+	if x != nil {
+		return x.Probabilities
+	}
+	return nil
+}
+
+type HMMResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	AlgorithmId  string                 `protobuf:"bytes,1,opt,name=algorithm_id,json=algorithmId,proto3" json:"algorithm_id,omitempty"`
+	Status       string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Summary      string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	HasResult    bool                   `protobuf:"varint,4,opt,name=has_result,json=hasResult,proto3" json:"has_result,omitempty"`
+	States       int32                  `protobuf:"varint,5,opt,name=states,proto3" json:"states,omitempty"`
+	Observations int32                  `protobuf:"varint,6,opt,name=observations,proto3" json:"observations,omitempty"`
+	StateNames   []string               `protobuf:"bytes,7,rep,name=state_names,json=stateNames,proto3" json:"state_names,omitempty"`
+	Symbols      []string               `protobuf:"bytes,8,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	// state_path is the Viterbi decoding of the observations
+	StatePath          []string `protobuf:"bytes,9,rep,name=state_path,json=statePath,proto3" json:"state_path,omitempty"`
+	PathLogProbability float64  `protobuf:"fixed64,10,opt,name=path_log_probability,json=pathLogProbability,proto3" json:"path_log_probability,omitempty"`
+	LogLikelihood      float64  `protobuf:"fixed64,11,opt,name=log_likelihood,json=logLikelihood,proto3" json:"log_likelihood,omitempty"`
+	// state_posteriors hold the probability of each state at each step
+	StatePosteriors []*HMMRow    `protobuf:"bytes,12,rep,name=state_posteriors,json=statePosteriors,proto3" json:"state_posteriors,omitempty"`
+	Initial         []float64    `protobuf:"fixed64,13,rep,packed,name=initial,proto3" json:"initial,omitempty"`
+	Transitions     []*HMMRow    `protobuf:"bytes,14,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	Emissions       []*HMMRow    `protobuf:"bytes,15,rep,name=emissions,proto3" json:"emissions,omitempty"`
+	Iterations      int32        `protobuf:"varint,16,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	Converged       bool         `protobuf:"varint,17,opt,name=converged,proto3" json:"converged,omitempty"`
+	Convergence     *Convergence `protobuf:"bytes,18,opt,name=convergence,proto3" json:"convergence,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HMMResponse) Reset() {
+	*x = HMMResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HMMResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HMMResponse) ProtoMessage() {}
+
+func (x *HMMResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HMMResponse.ProtoReflect.Descriptor instead.
+func (*HMMResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *HMMResponse) GetAlgorithmId() string {
+	if x != nil {
+		return x.AlgorithmId
+	}
+	return ""
+}
+
+func (x *HMMResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HMMResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *HMMResponse) GetHasResult() bool {
+	if x != nil {
+		return x.HasResult
+	}
+	return false
+}
+
+func (x *HMMResponse) GetStates() int32 {
+	if x != nil {
+		return x.States
+	}
+	return 0
+}
+
+func (x *HMMResponse) GetObservations() int32 {
+	if x != nil {
+		return x.Observations
+	}
+	return 0
+}
+
+func (x *HMMResponse) GetStateNames() []string {
+	if x != nil {
+		return x.StateNames
+	}
+	return nil
+}
+
+func (x *HMMResponse) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *HMMResponse) GetStatePath() []string {
+	if x != nil {
+		return x.StatePath
+	}
+	return nil
+}
+
+func (x *HMMResponse) GetPathLogProbability() float64 {
+	if x != nil {
+		return x.PathLogProbability
+	}
+	return 0
+}
+
+func (x *HMMResponse) GetLogLikelihood() float64 {
+	if x != nil {
+		return x.LogLikelihood
+	}
+	return 0
+}
+
+func (x *HMMResponse) GetStatePosteriors() []*HMMRow {
+	if x != nil {
+		return x.StatePosteriors
+	}
+	return nil
+}
+
+func (x *HMMResponse) GetInitial() []float64 {
+	if x != nil {
+		return x.Initial
+	}
+	return nil
+}
+
+func (x *HMMResponse) GetTransitions() []*HMMRow {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+func (x *HMMResponse) GetEmissions() []*HMMRow {
+	if x != nil {
+		return x.Emissions
+	}
+	return nil
+}
+
+func (x *HMMResponse) GetIterations() int32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+func (x *HMMResponse) GetConverged() bool {
+	if x != nil {
+		return x.Converged
+	}
+	return false
+}
+
+func (x *HMMResponse) GetConvergence() *Convergence {
+	if x != nil {
+		return x.Convergence
+	}
+	return nil
+}
+
+type DecisionOption struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description          string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	ExpectedValue        *float64               `protobuf:"fixed64,4,opt,name=expected_value,json=expectedValue,proto3,oneof" json:"expected_value,omitempty"`
+	RiskLevel            string                 `protobuf:"bytes,5,opt,name=risk_level,json=riskLevel,proto3" json:"risk_level,omitempty"`
+	ProbabilityOfSuccess *float64               `protobuf:"fixed64,6,opt,name=probability_of_success,json=probabilityOfSuccess,proto3,oneof" json:"probability_of_success,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DecisionOption) Reset() {
+	*x = DecisionOption{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionOption) ProtoMessage() {}
+
+func (x *DecisionOption) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionOption.ProtoReflect.Descriptor instead.
+func (*DecisionOption) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *DecisionOption) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DecisionOption) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DecisionOption) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DecisionOption) GetExpectedValue() float64 {
+	if x != nil && x.ExpectedValue != nil {
+		return *x.ExpectedValue
+	}
+	return 0
+}
+
+func (x *DecisionOption) GetRiskLevel() string {
+	if x != nil {
+		return x.RiskLevel
+	}
+	return ""
+}
+
+func (x *DecisionOption) GetProbabilityOfSuccess() float64 {
+	if x != nil && x.ProbabilityOfSuccess != nil {
+		return *x.ProbabilityOfSuccess
+	}
+	return 0
+}
+
+type DecisionCriterion struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description      string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Weight           float64                `protobuf:"fixed64,4,opt,name=weight,proto3" json:"weight,omitempty"`
+	EvaluationMethod string                 `protobuf:"bytes,5,opt,name=evaluation_method,json=evaluationMethod,proto3" json:"evaluation_method,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DecisionCriterion) Reset() {
+	*x = DecisionCriterion{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionCriterion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionCriterion) ProtoMessage() {}
+
+func (x *DecisionCriterion) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionCriterion.ProtoReflect.Descriptor instead.
+func (*DecisionCriterion) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DecisionCriterion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DecisionCriterion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DecisionCriterion) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DecisionCriterion) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *DecisionCriterion) GetEvaluationMethod() string {
+	if x != nil {
+		return x.EvaluationMethod
+	}
+	return ""
+}
+
+type DecisionFrameworkRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	SessionId         string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	DecisionStatement string                 `protobuf:"bytes,2,opt,name=decision_statement,json=decisionStatement,proto3" json:"decision_statement,omitempty"`
+	Options           []*DecisionOption      `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty"`
+	Criteria          []*DecisionCriterion   `protobuf:"bytes,4,rep,name=criteria,proto3" json:"criteria,omitempty"`
+	Stakeholders      []string               `protobuf:"bytes,5,rep,name=stakeholders,proto3" json:"stakeholders,omitempty"`
+	Constraints       []string               `protobuf:"bytes,6,rep,name=constraints,proto3" json:"constraints,omitempty"`
+	TimeHorizon       string                 `protobuf:"bytes,7,opt,name=time_horizon,json=timeHorizon,proto3" json:"time_horizon,omitempty"`
+	RiskTolerance     string                 `protobuf:"bytes,8,opt,name=risk_tolerance,json=riskTolerance,proto3" json:"risk_tolerance,omitempty"`
+	AnalysisType      string                 `protobuf:"bytes,9,opt,name=analysis_type,json=analysisType,proto3" json:"analysis_type,omitempty"`
+	Stage             string                 `protobuf:"bytes,10,opt,name=stage,proto3" json:"stage,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *DecisionFrameworkRequest) Reset() {
+	*x = DecisionFrameworkRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionFrameworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionFrameworkRequest) ProtoMessage() {}
+
+func (x *DecisionFrameworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionFrameworkRequest.ProtoReflect.Descriptor instead.
+func (*DecisionFrameworkRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DecisionFrameworkRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkRequest) GetDecisionStatement() string {
+	if x != nil {
+		return x.DecisionStatement
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkRequest) GetOptions() []*DecisionOption {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *DecisionFrameworkRequest) GetCriteria() []*DecisionCriterion {
+	if x != nil {
+		return x.Criteria
+	}
+	return nil
+}
+
+func (x *DecisionFrameworkRequest) GetStakeholders() []string {
+	if x != nil {
+		return x.Stakeholders
+	}
+	return nil
+}
+
+func (x *DecisionFrameworkRequest) GetConstraints() []string {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+func (x *DecisionFrameworkRequest) GetTimeHorizon() string {
+	if x != nil {
+		return x.TimeHorizon
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkRequest) GetRiskTolerance() string {
+	if x != nil {
+		return x.RiskTolerance
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkRequest) GetAnalysisType() string {
+	if x != nil {
+		return x.AnalysisType
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkRequest) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+type DecisionFrameworkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DecisionId    string                 `protobuf:"bytes,1,opt,name=decision_id,json=decisionId,proto3" json:"decision_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	HasOptions    bool                   `protobuf:"varint,3,opt,name=has_options,json=hasOptions,proto3" json:"has_options,omitempty"`
+	HasCriteria   bool                   `protobuf:"varint,4,opt,name=has_criteria,json=hasCriteria,proto3" json:"has_criteria,omitempty"`
+	AnalysisType  string                 `protobuf:"bytes,5,opt,name=analysis_type,json=analysisType,proto3" json:"analysis_type,omitempty"`
+	Stage         string                 `protobuf:"bytes,6,opt,name=stage,proto3" json:"stage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecisionFrameworkResponse) Reset() {
+	*x = DecisionFrameworkResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionFrameworkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionFrameworkResponse) ProtoMessage() {}
+
+func (x *DecisionFrameworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionFrameworkResponse.ProtoReflect.Descriptor instead.
+func (*DecisionFrameworkResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DecisionFrameworkResponse) GetDecisionId() string {
+	if x != nil {
+		return x.DecisionId
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkResponse) GetHasOptions() bool {
+	if x != nil {
+		return x.HasOptions
+	}
+	return false
+}
+
+func (x *DecisionFrameworkResponse) GetHasCriteria() bool {
+	if x != nil {
+		return x.HasCriteria
+	}
+	return false
+}
+
+func (x *DecisionFrameworkResponse) GetAnalysisType() string {
+	if x != nil {
+		return x.AnalysisType
+	}
+	return ""
+}
+
+func (x *DecisionFrameworkResponse) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+type SessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionRequest) Reset() {
+	*x = SessionRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionRequest) ProtoMessage() {}
+
+func (x *SessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionRequest.ProtoReflect.Descriptor instead.
+func (*SessionRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type SessionStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// stats holds the session statistics as the HTTP API reports them
+	Stats         *structpb.Struct `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionStatsResponse) Reset() {
+	*x = SessionStatsResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionStatsResponse) ProtoMessage() {}
+
+func (x *SessionStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionStatsResponse.ProtoReflect.Descriptor instead.
+func (*SessionStatsResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SessionStatsResponse) GetStats() *structpb.Struct {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type ListRecordsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// type is the record type, such as thoughts or decisions
+	Type   string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Limit  int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	Since  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`
+	Until  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=until,proto3" json:"until,omitempty"`
+	// order is asc (the default) or desc by creation time
+	Order         string `protobuf:"bytes,7,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecordsRequest) Reset() {
+	*x = ListRecordsRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecordsRequest) ProtoMessage() {}
+
+func (x *ListRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecordsRequest.ProtoReflect.Descriptor instead.
+func (*ListRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ListRecordsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ListRecordsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListRecordsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRecordsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListRecordsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListRecordsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *ListRecordsRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+type ListRecordsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Kind      string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Records   []*structpb.Struct     `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
+	Count     int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	// total is the number of records matching the time range before pagination
+	Total         int32 `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecordsResponse) Reset() {
+	*x = ListRecordsResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecordsResponse) ProtoMessage() {}
+
+func (x *ListRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecordsResponse.ProtoReflect.Descriptor instead.
+func (*ListRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListRecordsResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ListRecordsResponse) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ListRecordsResponse) GetRecords() []*structpb.Struct {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *ListRecordsResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ListRecordsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type SearchSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchSessionRequest) Reset() {
+	*x = SearchSessionRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchSessionRequest) ProtoMessage() {}
+
+func (x *SearchSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchSessionRequest.ProtoReflect.Descriptor instead.
+func (*SearchSessionRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SearchSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SearchSessionRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchSessionRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchHit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Field         string                 `protobuf:"bytes,3,opt,name=field,proto3" json:"field,omitempty"`
+	Score         float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	Snippet       string                 `protobuf:"bytes,5,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SearchHit) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *SearchHit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SearchHit) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *SearchHit) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchHit) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type SearchSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Hits          []*SearchHit           `protobuf:"bytes,3,rep,name=hits,proto3" json:"hits,omitempty"`
+	Count         int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchSessionResponse) Reset() {
+	*x = SearchSessionResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchSessionResponse) ProtoMessage() {}
+
+func (x *SearchSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchSessionResponse.ProtoReflect.Descriptor instead.
+func (*SearchSessionResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *SearchSessionResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SearchSessionResponse) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchSessionResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *SearchSessionResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type SessionStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionStatusResponse) Reset() {
+	*x = SessionStatusResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionStatusResponse) ProtoMessage() {}
+
+func (x *SessionStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionStatusResponse.ProtoReflect.Descriptor instead.
+func (*SessionStatusResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SessionStatusResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type StorageStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// limit caps the number of sessions listed by size; zero lists them all
+	Limit         int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageStatsRequest) Reset() {
+	*x = StorageStatsRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageStatsRequest) ProtoMessage() {}
+
+func (x *StorageStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageStatsRequest.ProtoReflect.Descriptor instead.
+func (*StorageStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *StorageStatsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type StorageStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// stats holds the storage statistics as the HTTP API reports them
+	Stats         *structpb.Struct `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageStatsResponse) Reset() {
+	*x = StorageStatsResponse{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageStatsResponse) ProtoMessage() {}
+
+func (x *StorageStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageStatsResponse.ProtoReflect.Descriptor instead.
+func (*StorageStatsResponse) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *StorageStatsResponse) GetStats() *structpb.Struct {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// session_id limits the stream to one session when set
+	SessionId     string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *WatchEventsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	RecordId      string                 `protobuf:"bytes,4,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Record        *structpb.Struct       `protobuf:"bytes,5,opt,name=record,proto3" json:"record,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_api_gothink_v1_gothink_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_api_gothink_v1_gothink_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *Event) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Event) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *Event) GetRecord() *structpb.Struct {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_api_gothink_v1_gothink_proto protoreflect.FileDescriptor
+
+var file_api_gothink_v1_gothink_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2f, 0x76, 0x31,
+	0x2f, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75,
+	0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xcf, 0x03, 0x0a, 0x19, 0x53, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74,
+	0x12, 0x25, 0x0a, 0x0e, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x5f, 0x6e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68,
+	0x74, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x12, 0x2e,
+	0x0a, 0x13, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x5f, 0x6e,
+	0x65, 0x65, 0x64, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x6e, 0x65, 0x78,
+	0x74, 0x54, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x4e, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x1f,
+	0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x2c, 0x0a, 0x0f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x65, 0x73, 0x5f, 0x74, 0x68, 0x6f, 0x75, 0x67,
+	0x68, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x0e, 0x72, 0x65, 0x76, 0x69,
+	0x73, 0x65, 0x73, 0x54, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a,
+	0x13, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x74, 0x68, 0x6f,
+	0x75, 0x67, 0x68, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x11, 0x62, 0x72,
+	0x61, 0x6e, 0x63, 0x68, 0x46, 0x72, 0x6f, 0x6d, 0x54, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x5f, 0x69, 0x64, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x49, 0x64, 0x12,
+	0x2e, 0x0a, 0x13, 0x6e, 0x65, 0x65, 0x64, 0x73, 0x5f, 0x6d, 0x6f, 0x72, 0x65, 0x5f, 0x74, 0x68,
+	0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x6e, 0x65,
+	0x65, 0x64, 0x73, 0x4d, 0x6f, 0x72, 0x65, 0x54, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x42,
+	0x12, 0x0a, 0x10, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x65, 0x73, 0x5f, 0x74, 0x68, 0x6f, 0x75,
+	0x67, 0x68, 0x74, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x62, 0x72, 0x61, 0x6e, 0x63, 0x68, 0x5f, 0x66,
+	0x72, 0x6f, 0x6d, 0x5f, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x22, 0xc8, 0x01, 0x0a, 0x1a,
+	0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69,
+	0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x68,
+	0x6f, 0x75, 0x67, 0x68, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x74, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64,
+	0x12, 0x25, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68,
+	0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x54,
+	0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x72, 0x65, 0x6d, 0x61, 0x69,
+	0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x11, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x54, 0x68,
+	0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x22, 0xe0, 0x01, 0x0a, 0x12, 0x4d, 0x65, 0x6e, 0x74, 0x61,
+	0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72,
+	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e,
+	0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63,
+	0x6f, 0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xdb, 0x01, 0x0a, 0x13, 0x4d, 0x65,
+	0x6e, 0x74, 0x61, 0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x73, 0x5f, 0x73, 0x74, 0x65, 0x70,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x68, 0x61, 0x73, 0x53, 0x74, 0x65, 0x70,
+	0x73, 0x12, 0x25, 0x0a, 0x0e, 0x68, 0x61, 0x73, 0x5f, 0x63, 0x6f, 0x6e, 0x63, 0x6c, 0x75, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x68, 0x61, 0x73, 0x43, 0x6f,
+	0x6e, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x2e, 0x0a, 0x13, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x11, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x4d, 0x65, 0x6e, 0x74, 0x61,
+	0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x22, 0xc6, 0x01, 0x0a, 0x18, 0x44, 0x65, 0x62, 0x75,
+	0x67, 0x67, 0x69, 0x6e, 0x67, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x61, 0x63, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x61, 0x63, 0x68, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x70, 0x70, 0x72,
+	0x6f, 0x61, 0x63, 0x68, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x73, 0x73, 0x75,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x69, 0x73, 0x73, 0x75, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x73,
+	0x74, 0x65, 0x70, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73,
+	0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x22, 0x9e, 0x01, 0x0a, 0x19, 0x44, 0x65, 0x62, 0x75, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x41, 0x70,
+	0x70, 0x72, 0x6f, 0x61, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f,
+	0x0a, 0x0b, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x61, 0x63, 0x68, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x61, 0x63, 0x68, 0x49, 0x64, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x61, 0x73, 0x5f, 0x66,
+	0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x68,
+	0x61, 0x73, 0x46, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x68, 0x61,
+	0x73, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0d, 0x68, 0x61, 0x73, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0xcb, 0x02, 0x0a, 0x0a, 0x4d, 0x44, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x67,
+	0x61, 0x6d, 0x6d, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x67, 0x61, 0x6d, 0x6d,
+	0x61, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x49, 0x74,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3b, 0x0a, 0x0b, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x44, 0x50, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x1c, 0x0a,
+	0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x4a, 0x04, 0x08, 0x06, 0x10,
+	0x07, 0x4a, 0x04, 0x08, 0x07, 0x10, 0x08, 0x52, 0x0d, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e,
+	0x67, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x52, 0x07, 0x65, 0x70, 0x73, 0x69, 0x6c, 0x6f, 0x6e, 0x22,
+	0x96, 0x01, 0x0a, 0x0d, 0x4d, 0x44, 0x50, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x22, 0xa1, 0x05, 0x0a, 0x0b, 0x4d, 0x44, 0x50,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c, 0x67, 0x6f,
+	0x72, 0x69, 0x74, 0x68, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1d, 0x0a,
+	0x0a, 0x68, 0x61, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x68, 0x61, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x74,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3b, 0x0a, 0x06, 0x70, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67, 0x6f, 0x74,
+	0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x44, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x51, 0x0a, 0x0e, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x2a, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x44, 0x50,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x46, 0x75,
+	0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3f, 0x0a, 0x08, 0x71, 0x5f,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x67,
+	0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x44, 0x50, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x51, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x07, 0x71, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x0b, 0x63,
+	0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
+	0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x76, 0x65,
+	0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x1a, 0x39, 0x0a, 0x0b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x1a, 0x40, 0x0a, 0x12, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x1a, 0x57, 0x0a, 0x0c, 0x51, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x44, 0x50, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x8d, 0x01, 0x0a,
+	0x0f, 0x4d, 0x44, 0x50, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x12, 0x3f, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x27, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x44,
+	0x50, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x2e, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xf9, 0x01, 0x0a,
+	0x0b, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67,
+	0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x69, 0x64, 0x75, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x08, 0x72, 0x65, 0x73, 0x69, 0x64, 0x75, 0x61, 0x6c, 0x12, 0x27, 0x0a, 0x0f,
+	0x73, 0x74, 0x6f, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x74, 0x6f, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x52,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x69, 0x64, 0x75, 0x61,
+	0x6c, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x01, 0x52, 0x09, 0x72, 0x65, 0x73, 0x69, 0x64, 0x75,
+	0x61, 0x6c, 0x73, 0x12, 0x13, 0x0a, 0x05, 0x72, 0x5f, 0x68, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x04, 0x72, 0x48, 0x61, 0x74, 0x22, 0xb9, 0x02, 0x0a, 0x0b, 0x4d, 0x43, 0x54,
+	0x53, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c,
+	0x65, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65,
+	0x6d, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x31, 0x0a, 0x14, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x13, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65,
+	0x70, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x44, 0x65,
+	0x70, 0x74, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x4c, 0x69, 0x6d,
+	0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x2d, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x43, 0x54, 0x53, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x73, 0x65, 0x65, 0x64, 0x22, 0x7b, 0x0a, 0x09, 0x4d, 0x43, 0x54, 0x53, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x72,
+	0x65, 0x77, 0x61, 0x72, 0x64, 0x12, 0x2a, 0x0a, 0x05, 0x6d, 0x6f, 0x76, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x43, 0x54, 0x53, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x05, 0x6d, 0x6f, 0x76, 0x65,
+	0x73, 0x22, 0x3d, 0x0a, 0x08, 0x4d, 0x43, 0x54, 0x53, 0x4d, 0x6f, 0x76, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f, 0x76,
+	0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x22, 0xa0, 0x03, 0x0a, 0x0c, 0x4d, 0x43, 0x54, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74,
+	0x68, 0x6d, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73,
+	0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x68, 0x61, 0x73, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x65, 0x73, 0x74,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x0a, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72,
+	0x75, 0x63, 0x74, 0x52, 0x09, 0x74, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x35,
+	0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x43, 0x54,
+	0x53, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x07, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2f, 0x0a, 0x13, 0x70, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70,
+	0x61, 0x6c, 0x5f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x12, 0x70, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x56, 0x61, 0x72,
+	0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x69, 0x6d,
+	0x75, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x76,
+	0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65,
+	0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65,
+	0x6e, 0x63, 0x65, 0x22, 0x4b, 0x0a, 0x0f, 0x4d, 0x43, 0x54, 0x53, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x69,
+	0x73, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x76, 0x69, 0x73, 0x69,
+	0x74, 0x73, 0x12, 0x0c, 0x0a, 0x01, 0x71, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x71,
+	0x22, 0x83, 0x02, 0x0a, 0x0d, 0x42, 0x61, 0x6e, 0x64, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x73,
+	0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73,
+	0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x70, 0x73, 0x69, 0x6c,
+	0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x65, 0x70, 0x73, 0x69, 0x6c, 0x6f,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x65, 0x74, 0x61, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x62, 0x65, 0x74, 0x61, 0x12, 0x29, 0x0a, 0x04, 0x61,
+	0x72, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x6f, 0x74, 0x68,
+	0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x69, 0x74, 0x41, 0x72, 0x6d,
+	0x52, 0x04, 0x61, 0x72, 0x6d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x65, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64,
+	0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x22, 0x9b, 0x01, 0x0a, 0x09, 0x42, 0x61, 0x6e, 0x64, 0x69,
+	0x74, 0x41, 0x72, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x6d, 0x65, 0x61, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6d, 0x65, 0x61, 0x6e,
+	0x12, 0x17, 0x0a, 0x07, 0x73, 0x74, 0x64, 0x5f, 0x64, 0x65, 0x76, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x06, 0x73, 0x74, 0x64, 0x44, 0x65, 0x76, 0x12, 0x29, 0x0a, 0x10, 0x6f, 0x62, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x64, 0x5f, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x01, 0x52, 0x0f, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x52, 0x65, 0x77,
+	0x61, 0x72, 0x64, 0x73, 0x22, 0xb5, 0x01, 0x0a, 0x0d, 0x41, 0x72, 0x6d, 0x53, 0x74, 0x61, 0x74,
+	0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x72, 0x6d, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x03, 0x61, 0x72, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x75, 0x6c, 0x6c,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x70, 0x75, 0x6c, 0x6c, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x07, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x76, 0x65, 0x72,
+	0x61, 0x67, 0x65, 0x5f, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0d, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x65, 0x77, 0x61, 0x72, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f,
+	0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x65, 0x78,
+	0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x52, 0x65, 0x77, 0x61, 0x72, 0x64, 0x22, 0x39, 0x0a, 0x0b,
+	0x52, 0x65, 0x67, 0x72, 0x65, 0x74, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x74, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x74, 0x65, 0x70, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x72, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x72, 0x65, 0x67, 0x72, 0x65, 0x74, 0x22, 0xb2, 0x03, 0x0a, 0x0e, 0x42, 0x61, 0x6e, 0x64,
+	0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c,
+	0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x49, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12,
+	0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x68, 0x61, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x21,
+	0x0a, 0x0c, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x72, 0x6d, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x41, 0x72,
+	0x6d, 0x12, 0x36, 0x0a, 0x09, 0x61, 0x72, 0x6d, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x41, 0x72, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x69, 0x73, 0x74, 0x69, 0x63, 0x73, 0x52,
+	0x08, 0x61, 0x72, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x70, 0x74,
+	0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x61, 0x72, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x6f, 0x70, 0x74, 0x69, 0x6d, 0x61, 0x6c, 0x41, 0x72, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x52, 0x65, 0x77, 0x61, 0x72, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x67, 0x72, 0x65, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x72,
+	0x65, 0x67, 0x72, 0x65, 0x74, 0x12, 0x3a, 0x0a, 0x0c, 0x72, 0x65, 0x67, 0x72, 0x65, 0x74, 0x5f,
+	0x63, 0x75, 0x72, 0x76, 0x65, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f,
+	0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x67, 0x72, 0x65, 0x74, 0x50,
+	0x6f, 0x69, 0x6e, 0x74, 0x52, 0x0b, 0x72, 0x65, 0x67, 0x72, 0x65, 0x74, 0x43, 0x75, 0x72, 0x76,
+	0x65, 0x12, 0x39, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x52,
+	0x0b, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xf2, 0x04, 0x0a,
+	0x1b, 0x42, 0x61, 0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72,
+	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x31, 0x0a, 0x14, 0x61, 0x63, 0x71, 0x75, 0x69, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x13, 0x61, 0x63, 0x71, 0x75, 0x69, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6b, 0x65, 0x72, 0x6e,
+	0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c,
+	0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x2d, 0x0a, 0x12, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11, 0x65, 0x78,
+	0x70, 0x6c, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12,
+	0x3d, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x61, 0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74,
+	0x65, 0x72, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x39, 0x0a, 0x07,
+	0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x79, 0x65, 0x73,
+	0x69, 0x61, 0x6e, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07,
+	0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x67, 0x6f, 0x61, 0x6c, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x67, 0x6f, 0x61, 0x6c, 0x12, 0x25, 0x0a, 0x0e, 0x69,
+	0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x50, 0x6f, 0x69, 0x6e,
+	0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x5f, 0x73, 0x63, 0x61,
+	0x6c, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x53, 0x63, 0x61, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x69, 0x73, 0x65, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x6e, 0x6f, 0x69, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x65, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x0f, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x70, 0x61, 0x74, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x70, 0x61, 0x74, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x74, 0x6f,
+	0x70, 0x5f, 0x61, 0x74, 0x5f, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x61, 0x75, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0d, 0x73, 0x74, 0x6f, 0x70, 0x41, 0x74, 0x50, 0x6c, 0x61, 0x74, 0x65, 0x61,
+	0x75, 0x22, 0x4b, 0x0a, 0x11, 0x42, 0x61, 0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x69,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6d, 0x69, 0x6e, 0x12, 0x10, 0x0a, 0x03,
+	0x6d, 0x61, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6d, 0x61, 0x78, 0x22, 0xbb,
+	0x01, 0x0a, 0x13, 0x42, 0x61, 0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x4f, 0x62, 0x73, 0x65, 0x72,
+	0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x4f, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x67, 0x6f, 0x74,
+	0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x79, 0x65, 0x73, 0x69, 0x61, 0x6e,
+	0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x70, 0x61, 0x72,
+	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x1a, 0x3d, 0x0a,
+	0x0f, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x3d, 0x0a, 0x0b,
+	0x47, 0x50, 0x50, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6d,
+	0x65, 0x61, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6d, 0x65, 0x61, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xc4, 0x02, 0x0a, 0x10,
+	0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x65, 0x70,
+	0x12, 0x1c, 0x0a, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x4c,
+	0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e,
+	0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x65, 0x70,
+	0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x63,
+	0x71, 0x75, 0x69, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0b, 0x61, 0x63, 0x71, 0x75, 0x69, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x35, 0x0a, 0x09,
+	0x70, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x50, 0x50,
+	0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x52, 0x09, 0x70, 0x72, 0x65, 0x64, 0x69, 0x63,
+	0x74, 0x65, 0x64, 0x1a, 0x3d, 0x0a, 0x0f, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xc3, 0x06, 0x0a, 0x1c, 0x42, 0x61, 0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x4f,
+	0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x6c, 0x67, 0x6f, 0x72,
+	0x69, 0x74, 0x68, 0x6d, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x68, 0x61,
+	0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x65, 0x0a, 0x0f, 0x62, 0x65, 0x73, 0x74, 0x5f,
+	0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x3c, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61,
+	0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x65, 0x73, 0x74, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e,
+	0x62, 0x65, 0x73, 0x74, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x1d,
+	0x0a, 0x0a, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x09, 0x62, 0x65, 0x73, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1e, 0x0a,
+	0x0a, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0a, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3e, 0x0a,
+	0x0e, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x70, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x50, 0x50, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x52, 0x0d,
+	0x62, 0x65, 0x73, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x12, 0x36, 0x0a,
+	0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x74, 0x69,
+	0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x65, 0x70, 0x52, 0x07, 0x68, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x65, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3c,
+	0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x79, 0x65,
+	0x73, 0x69, 0x61, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e, 0x6e, 0x65,
+	0x78, 0x74, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x29, 0x0a, 0x10,
+	0x6e, 0x65, 0x78, 0x74, 0x5f, 0x61, 0x63, 0x71, 0x75, 0x69, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x41, 0x63, 0x71, 0x75,
+	0x69, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3e, 0x0a, 0x0e, 0x6e, 0x65, 0x78, 0x74, 0x5f,
+	0x70, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x50, 0x50,
+	0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x6f,
+	0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x12, 0x39, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x76, 0x65,
+	0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67,
+	0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65, 0x72,
+	0x67, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e,
+	0x63, 0x65, 0x1a, 0x41, 0x0a, 0x13, 0x42, 0x65, 0x73, 0x74, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x41, 0x0a, 0x13, 0x4e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xbb, 0x03, 0x0a, 0x0a, 0x48, 0x4d, 0x4d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65,
+	0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x67, 0x6f,
+	0x72, 0x69, 0x74, 0x68, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x6c, 0x67,
+	0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x74,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d,
+	0x6d, 0x61, 0x78, 0x49, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x22, 0x0a,
+	0x0c, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x18, 0x08, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x01, 0x52, 0x07, 0x69,
+	0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x34, 0x0a, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f,
+	0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x4d, 0x4d, 0x52, 0x6f, 0x77, 0x52,
+	0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x30, 0x0a, 0x09,
+	0x65, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x4d, 0x4d,
+	0x52, 0x6f, 0x77, 0x52, 0x09, 0x65, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x09, 0x74, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x65, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64,
+	0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x22, 0x2e, 0x0a, 0x06, 0x48, 0x4d, 0x4d, 0x52, 0x6f, 0x77,
+	0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x01, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x22, 0xaa, 0x05, 0x0a, 0x0b, 0x48, 0x4d, 0x4d, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69,
+	0x74, 0x68, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x6c,
+	0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x68,
+	0x61, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x68, 0x61, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x65, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f,
+	0x6c, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68,
+	0x12, 0x30, 0x0a, 0x14, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x70, 0x72, 0x6f,
+	0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12,
+	0x70, 0x61, 0x74, 0x68, 0x4c, 0x6f, 0x67, 0x50, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x5f, 0x6c, 0x69, 0x6b, 0x65, 0x6c, 0x69,
+	0x68, 0x6f, 0x6f, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6c, 0x6f, 0x67, 0x4c,
+	0x69, 0x6b, 0x65, 0x6c, 0x69, 0x68, 0x6f, 0x6f, 0x64, 0x12, 0x3d, 0x0a, 0x10, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x5f, 0x70, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x73, 0x18, 0x0c, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31,
+	0x2e, 0x48, 0x4d, 0x4d, 0x52, 0x6f, 0x77, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x50, 0x6f,
+	0x73, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e, 0x69, 0x74,
+	0x69, 0x61, 0x6c, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x01, 0x52, 0x07, 0x69, 0x6e, 0x69, 0x74, 0x69,
+	0x61, 0x6c, 0x12, 0x34, 0x0a, 0x0b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e,
+	0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x4d, 0x4d, 0x52, 0x6f, 0x77, 0x52, 0x0b, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x30, 0x0a, 0x09, 0x65, 0x6d, 0x69, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f,
+	0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x4d, 0x4d, 0x52, 0x6f, 0x77, 0x52,
+	0x09, 0x65, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x74,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f,
+	0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x64, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63,
+	0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65, 0x64, 0x12, 0x39, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x76,
+	0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x76, 0x65,
+	0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67, 0x65,
+	0x6e, 0x63, 0x65, 0x22, 0x8a, 0x02, 0x0a, 0x0e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x0e,
+	0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0d, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x69, 0x73, 0x6b,
+	0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x69,
+	0x73, 0x6b, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x39, 0x0a, 0x16, 0x70, 0x72, 0x6f, 0x62, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x6f, 0x66, 0x5f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x14, 0x70, 0x72, 0x6f, 0x62, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x4f, 0x66, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x88,
+	0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x19, 0x0a, 0x17, 0x5f, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62,
+	0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x6f, 0x66, 0x5f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x22, 0x9e, 0x01, 0x0a, 0x11, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x72, 0x69,
+	0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06,
+	0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x77, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x65, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x65, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x22, 0xa4, 0x03, 0x0a, 0x18, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x46, 0x72,
+	0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x2d, 0x0a,
+	0x12, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x64, 0x65, 0x63, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x34, 0x0a, 0x07,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x39, 0x0a, 0x08, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72,
+	0x69, 0x6f, 0x6e, 0x52, 0x08, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61, 0x12, 0x22, 0x0a,
+	0x0c, 0x73, 0x74, 0x61, 0x6b, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x6b, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x74, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69,
+	0x6e, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x68, 0x6f, 0x72, 0x69,
+	0x7a, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x48,
+	0x6f, 0x72, 0x69, 0x7a, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x69, 0x73, 0x6b, 0x5f, 0x74,
+	0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x72, 0x69, 0x73, 0x6b, 0x54, 0x6f, 0x6c, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x23, 0x0a,
+	0x0d, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x22, 0xd3, 0x01, 0x0a, 0x19, 0x44, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x1f, 0x0a, 0x0b, 0x68, 0x61, 0x73, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x68, 0x61, 0x73, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x68, 0x61, 0x73, 0x5f, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x68, 0x61, 0x73, 0x43, 0x72, 0x69, 0x74, 0x65,
+	0x72, 0x69, 0x61, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x73, 0x69, 0x73, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x6e, 0x61, 0x6c,
+	0x79, 0x73, 0x69, 0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x67,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x22, 0x2f,
+	0x0a, 0x0e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22,
+	0x45, 0x0a, 0x14, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x22, 0xef, 0x01, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x30,
+	0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65,
+	0x12, 0x30, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x75, 0x6e, 0x74,
+	0x69, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x22, 0xa7, 0x01, 0x0a, 0x13, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x07, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x22, 0x61, 0x0a, 0x14, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x75, 0x0a, 0x09, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x48,
+	0x69, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f,
+	0x72, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x22, 0x8d, 0x01, 0x0a,
+	0x15, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x29, 0x0a, 0x04, 0x68,
+	0x69, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x6f, 0x74, 0x68,
+	0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x48, 0x69, 0x74,
+	0x52, 0x04, 0x68, 0x69, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x4e, 0x0a, 0x15,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x2b, 0x0a, 0x13,
+	0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x45, 0x0a, 0x14, 0x53, 0x74, 0x6f,
+	0x72, 0x61, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x2d, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x22, 0x33, 0x0a, 0x12, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0xca, 0x01, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65,
+	0x71, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x5f, 0x69,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49,
+	0x64, 0x12, 0x2f, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x06, 0x72, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69,
+	0x6d, 0x65, 0x32, 0x8d, 0x03, 0x0a, 0x0f, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x63, 0x0a, 0x12, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e,
+	0x74, 0x69, 0x61, 0x6c, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x12, 0x25, 0x2e, 0x67,
+	0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e,
+	0x74, 0x69, 0x61, 0x6c, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x68, 0x69, 0x6e, 0x6b,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x63, 0x0a, 0x0e, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x54, 0x68, 0x6f, 0x75, 0x67, 0x68, 0x74, 0x73, 0x12, 0x25, 0x2e,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x68, 0x69, 0x6e, 0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x68, 0x69, 0x6e,
+	0x6b, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01,
+	0x12, 0x4e, 0x0a, 0x0b, 0x4d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12,
+	0x1e, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x6e,
+	0x74, 0x61, 0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1f, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x6e,
+	0x74, 0x61, 0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x60, 0x0a, 0x11, 0x44, 0x65, 0x62, 0x75, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x41, 0x70, 0x70,
+	0x72, 0x6f, 0x61, 0x63, 0x68, 0x12, 0x24, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x62, 0x75, 0x67, 0x67, 0x69, 0x6e, 0x67, 0x41, 0x70, 0x70, 0x72,
+	0x6f, 0x61, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x67, 0x6f,
+	0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x62, 0x75, 0x67, 0x67, 0x69,
+	0x6e, 0x67, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x61, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x32, 0xa4, 0x03, 0x0a, 0x11, 0x53, 0x74, 0x6f, 0x63, 0x68, 0x61, 0x73, 0x74, 0x69,
+	0x63, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x48, 0x0a, 0x15, 0x4d, 0x61, 0x72, 0x6b,
+	0x6f, 0x76, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x44, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x74, 0x68,
+	0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x44, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x49, 0x0a, 0x14, 0x4d, 0x6f, 0x6e, 0x74, 0x65, 0x43, 0x61, 0x72, 0x6c, 0x6f,
+	0x54, 0x72, 0x65, 0x65, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x17, 0x2e, 0x67, 0x6f, 0x74,
+	0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x43, 0x54, 0x53, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31,
+	0x2e, 0x4d, 0x43, 0x54, 0x53, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a,
+	0x10, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x41, 0x72, 0x6d, 0x65, 0x64, 0x42, 0x61, 0x6e, 0x64, 0x69,
+	0x74, 0x12, 0x19, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x61, 0x6e, 0x64, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x67,
+	0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x69, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x69, 0x0a, 0x14, 0x42, 0x61, 0x79, 0x65,
+	0x73, 0x69, 0x61, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x27, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61,
+	0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x67, 0x6f, 0x74, 0x68,
+	0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x79, 0x65, 0x73, 0x69, 0x61, 0x6e, 0x4f,
+	0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a, 0x11, 0x48, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x4d, 0x61, 0x72,
+	0x6b, 0x6f, 0x76, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69,
+	0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x4d, 0x4d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x4d,
+	0x4d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x73, 0x0a, 0x0f, 0x44, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x60, 0x0a, 0x11,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72,
+	0x6b, 0x12, 0x24, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e,
+	0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x46, 0x72, 0x61,
+	0x6d, 0x65, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x92,
+	0x05, 0x0a, 0x0e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x4f, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x1a, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x20, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x73, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x54, 0x0a, 0x0d, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x20, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4d, 0x0a, 0x0c, 0x43, 0x6c, 0x65, 0x61,
+	0x72, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69,
+	0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x0e, 0x41, 0x72, 0x63, 0x68, 0x69,
+	0x76, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x67, 0x6f, 0x74, 0x68,
+	0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x67, 0x6f, 0x74,
+	0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x0f, 0x47, 0x65, 0x74,
+	0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x67,
+	0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e,
+	0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x42, 0x0a, 0x0b, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1e,
+	0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11,
+	0x2e, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x30, 0x01, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x72, 0x61, 0x69, 0x6e, 0x6d, 0x61, 0x6e, 0x61, 0x2f, 0x67, 0x6f, 0x74, 0x68, 0x69,
+	0x6e, 0x6b, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x2f, 0x76,
+	0x31, 0x3b, 0x67, 0x6f, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_gothink_v1_gothink_proto_rawDescOnce sync.Once
+	file_api_gothink_v1_gothink_proto_rawDescData = file_api_gothink_v1_gothink_proto_rawDesc
+)
+
+func file_api_gothink_v1_gothink_proto_rawDescGZIP() []byte {
+	file_api_gothink_v1_gothink_proto_rawDescOnce.Do(func() {
+		file_api_gothink_v1_gothink_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_gothink_v1_gothink_proto_rawDescData)
+	})
+	return file_api_gothink_v1_gothink_proto_rawDescData
+}
+
+var file_api_gothink_v1_gothink_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
+var file_api_gothink_v1_gothink_proto_goTypes = []any{
+	(*SequentialThinkingRequest)(nil),    // 0: gothink.v1.SequentialThinkingRequest
+	(*SequentialThinkingResponse)(nil),   // 1: gothink.v1.SequentialThinkingResponse
+	(*MentalModelRequest)(nil),           // 2: gothink.v1.MentalModelRequest
+	(*MentalModelResponse)(nil),          // 3: gothink.v1.MentalModelResponse
+	(*DebuggingApproachRequest)(nil),     // 4: gothink.v1.DebuggingApproachRequest
+	(*DebuggingApproachResponse)(nil),    // 5: gothink.v1.DebuggingApproachResponse
+	(*MDPRequest)(nil),                   // 6: gothink.v1.MDPRequest
+	(*MDPTransition)(nil),                // 7: gothink.v1.MDPTransition
+	(*MDPResponse)(nil),                  // 8: gothink.v1.MDPResponse
+	(*MDPActionValues)(nil),              // 9: gothink.v1.MDPActionValues
+	(*Convergence)(nil),                  // 10: gothink.v1.Convergence
+	(*MCTSRequest)(nil),                  // 11: gothink.v1.MCTSRequest
+	(*MCTSState)(nil),                    // 12: gothink.v1.MCTSState
+	(*MCTSMove)(nil),                     // 13: gothink.v1.MCTSMove
+	(*MCTSResponse)(nil),                 // 14: gothink.v1.MCTSResponse
+	(*MCTSActionStats)(nil),              // 15: gothink.v1.MCTSActionStats
+	(*BanditRequest)(nil),                // 16: gothink.v1.BanditRequest
+	(*BanditArm)(nil),                    // 17: gothink.v1.BanditArm
+	(*ArmStatistics)(nil),                // 18: gothink.v1.ArmStatistics
+	(*RegretPoint)(nil),                  // 19: gothink.v1.RegretPoint
+	(*BanditResponse)(nil),               // 20: gothink.v1.BanditResponse
+	(*BayesianOptimizationRequest)(nil),  // 21: gothink.v1.BayesianOptimizationRequest
+	(*BayesianParameter)(nil),            // 22: gothink.v1.BayesianParameter
+	(*BayesianObservation)(nil),          // 23: gothink.v1.BayesianObservation
+	(*GPPosterior)(nil),                  // 24: gothink.v1.GPPosterior
+	(*OptimizationStep)(nil),             // 25: gothink.v1.OptimizationStep
+	(*BayesianOptimizationResponse)(nil), // 26: gothink.v1.BayesianOptimizationResponse
+	(*HMMRequest)(nil),                   // 27: gothink.v1.HMMRequest
+	(*HMMRow)(nil),                       // 28: gothink.v1.HMMRow
+	(*HMMResponse)(nil),                  // 29: gothink.v1.HMMResponse
+	(*DecisionOption)(nil),               // 30: gothink.v1.DecisionOption
+	(*DecisionCriterion)(nil),            // 31: gothink.v1.DecisionCriterion
+	(*DecisionFrameworkRequest)(nil),     // 32: gothink.v1.DecisionFrameworkRequest
+	(*DecisionFrameworkResponse)(nil),    // 33: gothink.v1.DecisionFrameworkResponse
+	(*SessionRequest)(nil),               // 34: gothink.v1.SessionRequest
+	(*SessionStatsResponse)(nil),         // 35: gothink.v1.SessionStatsResponse
+	(*ListRecordsRequest)(nil),           // 36: gothink.v1.ListRecordsRequest
+	(*ListRecordsResponse)(nil),          // 37: gothink.v1.ListRecordsResponse
+	(*SearchSessionRequest)(nil),         // 38: gothink.v1.SearchSessionRequest
+	(*SearchHit)(nil),                    // 39: gothink.v1.SearchHit
+	(*SearchSessionResponse)(nil),        // 40: gothink.v1.SearchSessionResponse
+	(*SessionStatusResponse)(nil),        // 41: gothink.v1.SessionStatusResponse
+	(*StorageStatsRequest)(nil),          // 42: gothink.v1.StorageStatsRequest
+	(*StorageStatsResponse)(nil),         // 43: gothink.v1.StorageStatsResponse
+	(*WatchEventsRequest)(nil),           // 44: gothink.v1.WatchEventsRequest
+	(*Event)(nil),                        // 45: gothink.v1.Event
+	nil,                                  // 46: gothink.v1.MDPResponse.PolicyEntry
+	nil,                                  // 47: gothink.v1.MDPResponse.ValueFunctionEntry
+	nil,                                  // 48: gothink.v1.MDPResponse.QValuesEntry
+	nil,                                  // 49: gothink.v1.MDPActionValues.ValuesEntry
+	nil,                                  // 50: gothink.v1.BayesianObservation.ParametersEntry
+	nil,                                  // 51: gothink.v1.OptimizationStep.ParametersEntry
+	nil,                                  // 52: gothink.v1.BayesianOptimizationResponse.BestParametersEntry
+	nil,                                  // 53: gothink.v1.BayesianOptimizationResponse.NextParametersEntry
+	(*structpb.Struct)(nil),              // 54: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),        // 55: google.protobuf.Timestamp
+}
+var file_api_gothink_v1_gothink_proto_depIdxs = []int32{
+	7,  // 0: gothink.v1.MDPRequest.transitions:type_name -> gothink.v1.MDPTransition
+	46, // 1: gothink.v1.MDPResponse.policy:type_name -> gothink.v1.MDPResponse.PolicyEntry
+	47, // 2: gothink.v1.MDPResponse.value_function:type_name -> gothink.v1.MDPResponse.ValueFunctionEntry
+	48, // 3: gothink.v1.MDPResponse.q_values:type_name -> gothink.v1.MDPResponse.QValuesEntry
+	10, // 4: gothink.v1.MDPResponse.convergence:type_name -> gothink.v1.Convergence
+	49, // 5: gothink.v1.MDPActionValues.values:type_name -> gothink.v1.MDPActionValues.ValuesEntry
+	12, // 6: gothink.v1.MCTSRequest.states:type_name -> gothink.v1.MCTSState
+	13, // 7: gothink.v1.MCTSState.moves:type_name -> gothink.v1.MCTSMove
+	54, // 8: gothink.v1.MCTSResponse.tree_stats:type_name -> google.protobuf.Struct
+	15, // 9: gothink.v1.MCTSResponse.actions:type_name -> gothink.v1.MCTSActionStats
+	10, // 10: gothink.v1.MCTSResponse.convergence:type_name -> gothink.v1.Convergence
+	17, // 11: gothink.v1.BanditRequest.arms:type_name -> gothink.v1.BanditArm
+	18, // 12: gothink.v1.BanditResponse.arm_stats:type_name -> gothink.v1.ArmStatistics
+	19, // 13: gothink.v1.BanditResponse.regret_curve:type_name -> gothink.v1.RegretPoint
+	10, // 14: gothink.v1.BanditResponse.convergence:type_name -> gothink.v1.Convergence
+	22, // 15: gothink.v1.BayesianOptimizationRequest.parameters:type_name -> gothink.v1.BayesianParameter
+	23, // 16: gothink.v1.BayesianOptimizationRequest.history:type_name -> gothink.v1.BayesianObservation
+	50, // 17: gothink.v1.BayesianObservation.parameters:type_name -> gothink.v1.BayesianObservation.ParametersEntry
+	51, // 18: gothink.v1.OptimizationStep.parameters:type_name -> gothink.v1.OptimizationStep.ParametersEntry
+	24, // 19: gothink.v1.OptimizationStep.predicted:type_name -> gothink.v1.GPPosterior
+	52, // 20: gothink.v1.BayesianOptimizationResponse.best_parameters:type_name -> gothink.v1.BayesianOptimizationResponse.BestParametersEntry
+	24, // 21: gothink.v1.BayesianOptimizationResponse.best_posterior:type_name -> gothink.v1.GPPosterior
+	25, // 22: gothink.v1.BayesianOptimizationResponse.history:type_name -> gothink.v1.OptimizationStep
+	53, // 23: gothink.v1.BayesianOptimizationResponse.next_parameters:type_name -> gothink.v1.BayesianOptimizationResponse.NextParametersEntry
+	24, // 24: gothink.v1.BayesianOptimizationResponse.next_posterior:type_name -> gothink.v1.GPPosterior
+	10, // 25: gothink.v1.BayesianOptimizationResponse.convergence:type_name -> gothink.v1.Convergence
+	28, // 26: gothink.v1.HMMRequest.transitions:type_name -> gothink.v1.HMMRow
+	28, // 27: gothink.v1.HMMRequest.emissions:type_name -> gothink.v1.HMMRow
+	28, // 28: gothink.v1.HMMResponse.state_posteriors:type_name -> gothink.v1.HMMRow
+	28, // 29: gothink.v1.HMMResponse.transitions:type_name -> gothink.v1.HMMRow
+	28, // 30: gothink.v1.HMMResponse.emissions:type_name -> gothink.v1.HMMRow
+	10, // 31: gothink.v1.HMMResponse.convergence:type_name -> gothink.v1.Convergence
+	30, // 32: gothink.v1.DecisionFrameworkRequest.options:type_name -> gothink.v1.DecisionOption
+	31, // 33: gothink.v1.DecisionFrameworkRequest.criteria:type_name -> gothink.v1.DecisionCriterion
+	54, // 34: gothink.v1.SessionStatsResponse.stats:type_name -> google.protobuf.Struct
+	55, // 35: gothink.v1.ListRecordsRequest.since:type_name -> google.protobuf.Timestamp
+	55, // 36: gothink.v1.ListRecordsRequest.until:type_name -> google.protobuf.Timestamp
+	54, // 37: gothink.v1.ListRecordsResponse.records:type_name -> google.protobuf.Struct
+	39, // 38: gothink.v1.SearchSessionResponse.hits:type_name -> gothink.v1.SearchHit
+	54, // 39: gothink.v1.StorageStatsResponse.stats:type_name -> google.protobuf.Struct
+	54, // 40: gothink.v1.Event.record:type_name -> google.protobuf.Struct
+	55, // 41: gothink.v1.Event.time:type_name -> google.protobuf.Timestamp
+	9,  // 42: gothink.v1.MDPResponse.QValuesEntry.value:type_name -> gothink.v1.MDPActionValues
+	0,  // 43: gothink.v1.ThinkingService.SequentialThinking:input_type -> gothink.v1.SequentialThinkingRequest
+	0,  // 44: gothink.v1.ThinkingService.StreamThoughts:input_type -> gothink.v1.SequentialThinkingRequest
+	2,  // 45: gothink.v1.ThinkingService.MentalModel:input_type -> gothink.v1.MentalModelRequest
+	4,  // 46: gothink.v1.ThinkingService.DebuggingApproach:input_type -> gothink.v1.DebuggingApproachRequest
+	6,  // 47: gothink.v1.StochasticService.MarkovDecisionProcess:input_type -> gothink.v1.MDPRequest
+	11, // 48: gothink.v1.StochasticService.MonteCarloTreeSearch:input_type -> gothink.v1.MCTSRequest
+	16, // 49: gothink.v1.StochasticService.MultiArmedBandit:input_type -> gothink.v1.BanditRequest
+	21, // 50: gothink.v1.StochasticService.BayesianOptimization:input_type -> gothink.v1.BayesianOptimizationRequest
+	27, // 51: gothink.v1.StochasticService.HiddenMarkovModel:input_type -> gothink.v1.HMMRequest
+	32, // 52: gothink.v1.DecisionService.DecisionFramework:input_type -> gothink.v1.DecisionFrameworkRequest
+	34, // 53: gothink.v1.SessionService.GetSessionStats:input_type -> gothink.v1.SessionRequest
+	36, // 54: gothink.v1.SessionService.ListRecords:input_type -> gothink.v1.ListRecordsRequest
+	38, // 55: gothink.v1.SessionService.SearchSession:input_type -> gothink.v1.SearchSessionRequest
+	34, // 56: gothink.v1.SessionService.ClearSession:input_type -> gothink.v1.SessionRequest
+	34, // 57: gothink.v1.SessionService.ArchiveSession:input_type -> gothink.v1.SessionRequest
+	34, // 58: gothink.v1.SessionService.RestoreSession:input_type -> gothink.v1.SessionRequest
+	42, // 59: gothink.v1.SessionService.GetStorageStats:input_type -> gothink.v1.StorageStatsRequest
+	44, // 60: gothink.v1.SessionService.WatchEvents:input_type -> gothink.v1.WatchEventsRequest
+	1,  // 61: gothink.v1.ThinkingService.SequentialThinking:output_type -> gothink.v1.SequentialThinkingResponse
+	1,  // 62: gothink.v1.ThinkingService.StreamThoughts:output_type -> gothink.v1.SequentialThinkingResponse
+	3,  // 63: gothink.v1.ThinkingService.MentalModel:output_type -> gothink.v1.MentalModelResponse
+	5,  // 64: gothink.v1.ThinkingService.DebuggingApproach:output_type -> gothink.v1.DebuggingApproachResponse
+	8,  // 65: gothink.v1.StochasticService.MarkovDecisionProcess:output_type -> gothink.v1.MDPResponse
+	14, // 66: gothink.v1.StochasticService.MonteCarloTreeSearch:output_type -> gothink.v1.MCTSResponse
+	20, // 67: gothink.v1.StochasticService.MultiArmedBandit:output_type -> gothink.v1.BanditResponse
+	26, // 68: gothink.v1.StochasticService.BayesianOptimization:output_type -> gothink.v1.BayesianOptimizationResponse
+	29, // 69: gothink.v1.StochasticService.HiddenMarkovModel:output_type -> gothink.v1.HMMResponse
+	33, // 70: gothink.v1.DecisionService.DecisionFramework:output_type -> gothink.v1.DecisionFrameworkResponse
+	35, // 71: gothink.v1.SessionService.GetSessionStats:output_type -> gothink.v1.SessionStatsResponse
+	37, // 72: gothink.v1.SessionService.ListRecords:output_type -> gothink.v1.ListRecordsResponse
+	40, // 73: gothink.v1.SessionService.SearchSession:output_type -> gothink.v1.SearchSessionResponse
+	41, // 74: gothink.v1.SessionService.ClearSession:output_type -> gothink.v1.SessionStatusResponse
+	41, // 75: gothink.v1.SessionService.ArchiveSession:output_type -> gothink.v1.SessionStatusResponse
+	41, // 76: gothink.v1.SessionService.RestoreSession:output_type -> gothink.v1.SessionStatusResponse
+	43, // 77: gothink.v1.SessionService.GetStorageStats:output_type -> gothink.v1.StorageStatsResponse
+	45, // 78: gothink.v1.SessionService.WatchEvents:output_type -> gothink.v1.Event
+	61, // [61:79] is the sub-list for method output_type
+	43, // [43:61] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
+}
+
+func init() { file_api_gothink_v1_gothink_proto_init() }
+func file_api_gothink_v1_gothink_proto_init() {
+	if File_api_gothink_v1_gothink_proto != nil {
+		return
+	}
+	file_api_gothink_v1_gothink_proto_msgTypes[0].OneofWrappers = []any{}
+	file_api_gothink_v1_gothink_proto_msgTypes[30].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_gothink_v1_gothink_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   54,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_api_gothink_v1_gothink_proto_goTypes,
+		DependencyIndexes: file_api_gothink_v1_gothink_proto_depIdxs,
+		MessageInfos:      file_api_gothink_v1_gothink_proto_msgTypes,
+	}.Build()
+	File_api_gothink_v1_gothink_proto = out.File
+	file_api_gothink_v1_gothink_proto_rawDesc = nil
+	file_api_gothink_v1_gothink_proto_goTypes = nil
+	file_api_gothink_v1_gothink_proto_depIdxs = nil
+}
@@ -0,0 +1,1021 @@
+// GoThink gRPC API. The services serve a subset of the HTTP API under
+// /api/v1, listed in the README, and work on the same storage; requests are
+// scoped to the tenant named by the x-tenant-id metadata key, like the
+// X-Tenant-ID header of the HTTP API.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/gothink/v1/gothink.proto
+
+package gothinkv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ThinkingService_SequentialThinking_FullMethodName = "/gothink.v1.ThinkingService/SequentialThinking"
+	ThinkingService_StreamThoughts_FullMethodName     = "/gothink.v1.ThinkingService/StreamThoughts"
+	ThinkingService_MentalModel_FullMethodName        = "/gothink.v1.ThinkingService/MentalModel"
+	ThinkingService_DebuggingApproach_FullMethodName  = "/gothink.v1.ThinkingService/DebuggingApproach"
+)
+
+// ThinkingServiceClient is the client API for ThinkingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ThinkingService records systematic thinking steps
+type ThinkingServiceClient interface {
+	// SequentialThinking records one thought (POST /api/v1/thinking/sequential)
+	SequentialThinking(ctx context.Context, in *SequentialThinkingRequest, opts ...grpc.CallOption) (*SequentialThinkingResponse, error)
+	// StreamThoughts records each thought the client sends, answering each in
+	// turn, until the client closes its side of the stream
+	StreamThoughts(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SequentialThinkingRequest, SequentialThinkingResponse], error)
+	// MentalModel applies a mental model (POST /api/v1/thinking/mental-model)
+	MentalModel(ctx context.Context, in *MentalModelRequest, opts ...grpc.CallOption) (*MentalModelResponse, error)
+	// DebuggingApproach records a debugging approach (POST /api/v1/thinking/debugging)
+	DebuggingApproach(ctx context.Context, in *DebuggingApproachRequest, opts ...grpc.CallOption) (*DebuggingApproachResponse, error)
+}
+
+type thinkingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewThinkingServiceClient(cc grpc.ClientConnInterface) ThinkingServiceClient {
+	return &thinkingServiceClient{cc}
+}
+
+func (c *thinkingServiceClient) SequentialThinking(ctx context.Context, in *SequentialThinkingRequest, opts ...grpc.CallOption) (*SequentialThinkingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SequentialThinkingResponse)
+	err := c.cc.Invoke(ctx, ThinkingService_SequentialThinking_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thinkingServiceClient) StreamThoughts(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SequentialThinkingRequest, SequentialThinkingResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ThinkingService_ServiceDesc.Streams[0], ThinkingService_StreamThoughts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SequentialThinkingRequest, SequentialThinkingResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ThinkingService_StreamThoughtsClient = grpc.BidiStreamingClient[SequentialThinkingRequest, SequentialThinkingResponse]
+
+func (c *thinkingServiceClient) MentalModel(ctx context.Context, in *MentalModelRequest, opts ...grpc.CallOption) (*MentalModelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MentalModelResponse)
+	err := c.cc.Invoke(ctx, ThinkingService_MentalModel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thinkingServiceClient) DebuggingApproach(ctx context.Context, in *DebuggingApproachRequest, opts ...grpc.CallOption) (*DebuggingApproachResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DebuggingApproachResponse)
+	err := c.cc.Invoke(ctx, ThinkingService_DebuggingApproach_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ThinkingServiceServer is the server API for ThinkingService service.
+// All implementations must embed UnimplementedThinkingServiceServer
+// for forward compatibility.
+//
+// ThinkingService records systematic thinking steps
+type ThinkingServiceServer interface {
+	// SequentialThinking records one thought (POST /api/v1/thinking/sequential)
+	SequentialThinking(context.Context, *SequentialThinkingRequest) (*SequentialThinkingResponse, error)
+	// StreamThoughts records each thought the client sends, answering each in
+	// turn, until the client closes its side of the stream
+	StreamThoughts(grpc.BidiStreamingServer[SequentialThinkingRequest, SequentialThinkingResponse]) error
+	// MentalModel applies a mental model (POST /api/v1/thinking/mental-model)
+	MentalModel(context.Context, *MentalModelRequest) (*MentalModelResponse, error)
+	// DebuggingApproach records a debugging approach (POST /api/v1/thinking/debugging)
+	DebuggingApproach(context.Context, *DebuggingApproachRequest) (*DebuggingApproachResponse, error)
+	mustEmbedUnimplementedThinkingServiceServer()
+}
+
+// UnimplementedThinkingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedThinkingServiceServer struct{}
+
+func (UnimplementedThinkingServiceServer) SequentialThinking(context.Context, *SequentialThinkingRequest) (*SequentialThinkingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SequentialThinking not implemented")
+}
+func (UnimplementedThinkingServiceServer) StreamThoughts(grpc.BidiStreamingServer[SequentialThinkingRequest, SequentialThinkingResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamThoughts not implemented")
+}
+func (UnimplementedThinkingServiceServer) MentalModel(context.Context, *MentalModelRequest) (*MentalModelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MentalModel not implemented")
+}
+func (UnimplementedThinkingServiceServer) DebuggingApproach(context.Context, *DebuggingApproachRequest) (*DebuggingApproachResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebuggingApproach not implemented")
+}
+func (UnimplementedThinkingServiceServer) mustEmbedUnimplementedThinkingServiceServer() {}
+func (UnimplementedThinkingServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeThinkingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ThinkingServiceServer will
+// result in compilation errors.
+type UnsafeThinkingServiceServer interface {
+	mustEmbedUnimplementedThinkingServiceServer()
+}
+
+func RegisterThinkingServiceServer(s grpc.ServiceRegistrar, srv ThinkingServiceServer) {
+	// If the following call pancis, it indicates UnimplementedThinkingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ThinkingService_ServiceDesc, srv)
+}
+
+func _ThinkingService_SequentialThinking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SequentialThinkingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThinkingServiceServer).SequentialThinking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThinkingService_SequentialThinking_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThinkingServiceServer).SequentialThinking(ctx, req.(*SequentialThinkingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThinkingService_StreamThoughts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ThinkingServiceServer).StreamThoughts(&grpc.GenericServerStream[SequentialThinkingRequest, SequentialThinkingResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ThinkingService_StreamThoughtsServer = grpc.BidiStreamingServer[SequentialThinkingRequest, SequentialThinkingResponse]
+
+func _ThinkingService_MentalModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MentalModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThinkingServiceServer).MentalModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThinkingService_MentalModel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThinkingServiceServer).MentalModel(ctx, req.(*MentalModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThinkingService_DebuggingApproach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebuggingApproachRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThinkingServiceServer).DebuggingApproach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThinkingService_DebuggingApproach_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThinkingServiceServer).DebuggingApproach(ctx, req.(*DebuggingApproachRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ThinkingService_ServiceDesc is the grpc.ServiceDesc for ThinkingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ThinkingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gothink.v1.ThinkingService",
+	HandlerType: (*ThinkingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SequentialThinking",
+			Handler:    _ThinkingService_SequentialThinking_Handler,
+		},
+		{
+			MethodName: "MentalModel",
+			Handler:    _ThinkingService_MentalModel_Handler,
+		},
+		{
+			MethodName: "DebuggingApproach",
+			Handler:    _ThinkingService_DebuggingApproach_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamThoughts",
+			Handler:       _ThinkingService_StreamThoughts_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/gothink/v1/gothink.proto",
+}
+
+const (
+	StochasticService_MarkovDecisionProcess_FullMethodName = "/gothink.v1.StochasticService/MarkovDecisionProcess"
+	StochasticService_MonteCarloTreeSearch_FullMethodName  = "/gothink.v1.StochasticService/MonteCarloTreeSearch"
+	StochasticService_MultiArmedBandit_FullMethodName      = "/gothink.v1.StochasticService/MultiArmedBandit"
+	StochasticService_BayesianOptimization_FullMethodName  = "/gothink.v1.StochasticService/BayesianOptimization"
+	StochasticService_HiddenMarkovModel_FullMethodName     = "/gothink.v1.StochasticService/HiddenMarkovModel"
+)
+
+// StochasticServiceClient is the client API for StochasticService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// StochasticService runs stochastic algorithms and records their results
+type StochasticServiceClient interface {
+	// MarkovDecisionProcess runs an MDP (POST /api/v1/stochastic/mdp)
+	MarkovDecisionProcess(ctx context.Context, in *MDPRequest, opts ...grpc.CallOption) (*MDPResponse, error)
+	// MonteCarloTreeSearch runs a tree search (POST /api/v1/stochastic/mcts)
+	MonteCarloTreeSearch(ctx context.Context, in *MCTSRequest, opts ...grpc.CallOption) (*MCTSResponse, error)
+	// MultiArmedBandit runs a bandit (POST /api/v1/stochastic/bandit)
+	MultiArmedBandit(ctx context.Context, in *BanditRequest, opts ...grpc.CallOption) (*BanditResponse, error)
+	// BayesianOptimization runs an optimization (POST /api/v1/stochastic/bayesian)
+	BayesianOptimization(ctx context.Context, in *BayesianOptimizationRequest, opts ...grpc.CallOption) (*BayesianOptimizationResponse, error)
+	// HiddenMarkovModel fits an HMM (POST /api/v1/stochastic/hmm)
+	HiddenMarkovModel(ctx context.Context, in *HMMRequest, opts ...grpc.CallOption) (*HMMResponse, error)
+}
+
+type stochasticServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStochasticServiceClient(cc grpc.ClientConnInterface) StochasticServiceClient {
+	return &stochasticServiceClient{cc}
+}
+
+func (c *stochasticServiceClient) MarkovDecisionProcess(ctx context.Context, in *MDPRequest, opts ...grpc.CallOption) (*MDPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MDPResponse)
+	err := c.cc.Invoke(ctx, StochasticService_MarkovDecisionProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stochasticServiceClient) MonteCarloTreeSearch(ctx context.Context, in *MCTSRequest, opts ...grpc.CallOption) (*MCTSResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MCTSResponse)
+	err := c.cc.Invoke(ctx, StochasticService_MonteCarloTreeSearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stochasticServiceClient) MultiArmedBandit(ctx context.Context, in *BanditRequest, opts ...grpc.CallOption) (*BanditResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BanditResponse)
+	err := c.cc.Invoke(ctx, StochasticService_MultiArmedBandit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stochasticServiceClient) BayesianOptimization(ctx context.Context, in *BayesianOptimizationRequest, opts ...grpc.CallOption) (*BayesianOptimizationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BayesianOptimizationResponse)
+	err := c.cc.Invoke(ctx, StochasticService_BayesianOptimization_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stochasticServiceClient) HiddenMarkovModel(ctx context.Context, in *HMMRequest, opts ...grpc.CallOption) (*HMMResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HMMResponse)
+	err := c.cc.Invoke(ctx, StochasticService_HiddenMarkovModel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StochasticServiceServer is the server API for StochasticService service.
+// All implementations must embed UnimplementedStochasticServiceServer
+// for forward compatibility.
+//
+// StochasticService runs stochastic algorithms and records their results
+type StochasticServiceServer interface {
+	// MarkovDecisionProcess runs an MDP (POST /api/v1/stochastic/mdp)
+	MarkovDecisionProcess(context.Context, *MDPRequest) (*MDPResponse, error)
+	// MonteCarloTreeSearch runs a tree search (POST /api/v1/stochastic/mcts)
+	MonteCarloTreeSearch(context.Context, *MCTSRequest) (*MCTSResponse, error)
+	// MultiArmedBandit runs a bandit (POST /api/v1/stochastic/bandit)
+	MultiArmedBandit(context.Context, *BanditRequest) (*BanditResponse, error)
+	// BayesianOptimization runs an optimization (POST /api/v1/stochastic/bayesian)
+	BayesianOptimization(context.Context, *BayesianOptimizationRequest) (*BayesianOptimizationResponse, error)
+	// HiddenMarkovModel fits an HMM (POST /api/v1/stochastic/hmm)
+	HiddenMarkovModel(context.Context, *HMMRequest) (*HMMResponse, error)
+	mustEmbedUnimplementedStochasticServiceServer()
+}
+
+// UnimplementedStochasticServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStochasticServiceServer struct{}
+
+func (UnimplementedStochasticServiceServer) MarkovDecisionProcess(context.Context, *MDPRequest) (*MDPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkovDecisionProcess not implemented")
+}
+func (UnimplementedStochasticServiceServer) MonteCarloTreeSearch(context.Context, *MCTSRequest) (*MCTSResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MonteCarloTreeSearch not implemented")
+}
+func (UnimplementedStochasticServiceServer) MultiArmedBandit(context.Context, *BanditRequest) (*BanditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MultiArmedBandit not implemented")
+}
+func (UnimplementedStochasticServiceServer) BayesianOptimization(context.Context, *BayesianOptimizationRequest) (*BayesianOptimizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BayesianOptimization not implemented")
+}
+func (UnimplementedStochasticServiceServer) HiddenMarkovModel(context.Context, *HMMRequest) (*HMMResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HiddenMarkovModel not implemented")
+}
+func (UnimplementedStochasticServiceServer) mustEmbedUnimplementedStochasticServiceServer() {}
+func (UnimplementedStochasticServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeStochasticServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StochasticServiceServer will
+// result in compilation errors.
+type UnsafeStochasticServiceServer interface {
+	mustEmbedUnimplementedStochasticServiceServer()
+}
+
+func RegisterStochasticServiceServer(s grpc.ServiceRegistrar, srv StochasticServiceServer) {
+	// If the following call pancis, it indicates UnimplementedStochasticServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StochasticService_ServiceDesc, srv)
+}
+
+func _StochasticService_MarkovDecisionProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MDPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StochasticServiceServer).MarkovDecisionProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StochasticService_MarkovDecisionProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StochasticServiceServer).MarkovDecisionProcess(ctx, req.(*MDPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StochasticService_MonteCarloTreeSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MCTSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StochasticServiceServer).MonteCarloTreeSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StochasticService_MonteCarloTreeSearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StochasticServiceServer).MonteCarloTreeSearch(ctx, req.(*MCTSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StochasticService_MultiArmedBandit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StochasticServiceServer).MultiArmedBandit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StochasticService_MultiArmedBandit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StochasticServiceServer).MultiArmedBandit(ctx, req.(*BanditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StochasticService_BayesianOptimization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BayesianOptimizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StochasticServiceServer).BayesianOptimization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StochasticService_BayesianOptimization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StochasticServiceServer).BayesianOptimization(ctx, req.(*BayesianOptimizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StochasticService_HiddenMarkovModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HMMRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StochasticServiceServer).HiddenMarkovModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StochasticService_HiddenMarkovModel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StochasticServiceServer).HiddenMarkovModel(ctx, req.(*HMMRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// StochasticService_ServiceDesc is the grpc.ServiceDesc for StochasticService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StochasticService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gothink.v1.StochasticService",
+	HandlerType: (*StochasticServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MarkovDecisionProcess",
+			Handler:    _StochasticService_MarkovDecisionProcess_Handler,
+		},
+		{
+			MethodName: "MonteCarloTreeSearch",
+			Handler:    _StochasticService_MonteCarloTreeSearch_Handler,
+		},
+		{
+			MethodName: "MultiArmedBandit",
+			Handler:    _StochasticService_MultiArmedBandit_Handler,
+		},
+		{
+			MethodName: "BayesianOptimization",
+			Handler:    _StochasticService_BayesianOptimization_Handler,
+		},
+		{
+			MethodName: "HiddenMarkovModel",
+			Handler:    _StochasticService_HiddenMarkovModel_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/gothink/v1/gothink.proto",
+}
+
+const (
+	DecisionService_DecisionFramework_FullMethodName = "/gothink.v1.DecisionService/DecisionFramework"
+)
+
+// DecisionServiceClient is the client API for DecisionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DecisionService records decisions
+type DecisionServiceClient interface {
+	// DecisionFramework frames a decision (POST /api/v1/decision/framework)
+	DecisionFramework(ctx context.Context, in *DecisionFrameworkRequest, opts ...grpc.CallOption) (*DecisionFrameworkResponse, error)
+}
+
+type decisionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDecisionServiceClient(cc grpc.ClientConnInterface) DecisionServiceClient {
+	return &decisionServiceClient{cc}
+}
+
+func (c *decisionServiceClient) DecisionFramework(ctx context.Context, in *DecisionFrameworkRequest, opts ...grpc.CallOption) (*DecisionFrameworkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DecisionFrameworkResponse)
+	err := c.cc.Invoke(ctx, DecisionService_DecisionFramework_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecisionServiceServer is the server API for DecisionService service.
+// All implementations must embed UnimplementedDecisionServiceServer
+// for forward compatibility.
+//
+// DecisionService records decisions
+type DecisionServiceServer interface {
+	// DecisionFramework frames a decision (POST /api/v1/decision/framework)
+	DecisionFramework(context.Context, *DecisionFrameworkRequest) (*DecisionFrameworkResponse, error)
+	mustEmbedUnimplementedDecisionServiceServer()
+}
+
+// UnimplementedDecisionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDecisionServiceServer struct{}
+
+func (UnimplementedDecisionServiceServer) DecisionFramework(context.Context, *DecisionFrameworkRequest) (*DecisionFrameworkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecisionFramework not implemented")
+}
+func (UnimplementedDecisionServiceServer) mustEmbedUnimplementedDecisionServiceServer() {}
+func (UnimplementedDecisionServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeDecisionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DecisionServiceServer will
+// result in compilation errors.
+type UnsafeDecisionServiceServer interface {
+	mustEmbedUnimplementedDecisionServiceServer()
+}
+
+func RegisterDecisionServiceServer(s grpc.ServiceRegistrar, srv DecisionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDecisionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DecisionService_ServiceDesc, srv)
+}
+
+func _DecisionService_DecisionFramework_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecisionFrameworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DecisionServiceServer).DecisionFramework(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DecisionService_DecisionFramework_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DecisionServiceServer).DecisionFramework(ctx, req.(*DecisionFrameworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DecisionService_ServiceDesc is the grpc.ServiceDesc for DecisionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DecisionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gothink.v1.DecisionService",
+	HandlerType: (*DecisionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DecisionFramework",
+			Handler:    _DecisionService_DecisionFramework_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/gothink/v1/gothink.proto",
+}
+
+const (
+	SessionService_GetSessionStats_FullMethodName = "/gothink.v1.SessionService/GetSessionStats"
+	SessionService_ListRecords_FullMethodName     = "/gothink.v1.SessionService/ListRecords"
+	SessionService_SearchSession_FullMethodName   = "/gothink.v1.SessionService/SearchSession"
+	SessionService_ClearSession_FullMethodName    = "/gothink.v1.SessionService/ClearSession"
+	SessionService_ArchiveSession_FullMethodName  = "/gothink.v1.SessionService/ArchiveSession"
+	SessionService_RestoreSession_FullMethodName  = "/gothink.v1.SessionService/RestoreSession"
+	SessionService_GetStorageStats_FullMethodName = "/gothink.v1.SessionService/GetStorageStats"
+	SessionService_WatchEvents_FullMethodName     = "/gothink.v1.SessionService/WatchEvents"
+)
+
+// SessionServiceClient is the client API for SessionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SessionService inspects and manages sessions
+type SessionServiceClient interface {
+	// GetSessionStats returns a session's statistics (GET /api/v1/session/{id}/stats)
+	GetSessionStats(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatsResponse, error)
+	// ListRecords returns a page of a session's records (GET /api/v1/session/{id}/records)
+	ListRecords(ctx context.Context, in *ListRecordsRequest, opts ...grpc.CallOption) (*ListRecordsResponse, error)
+	// SearchSession searches a session's text (GET /api/v1/session/{id}/search)
+	SearchSession(ctx context.Context, in *SearchSessionRequest, opts ...grpc.CallOption) (*SearchSessionResponse, error)
+	// ClearSession deletes a session and its records (POST /api/v1/session/{id}/clear)
+	ClearSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatusResponse, error)
+	// ArchiveSession archives a session (POST /api/v1/session/{id}/archive)
+	ArchiveSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatusResponse, error)
+	// RestoreSession restores an archived session (POST /api/v1/session/{id}/restore)
+	RestoreSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatusResponse, error)
+	// GetStorageStats returns storage statistics (GET /api/v1/storage/stats)
+	GetStorageStats(ctx context.Context, in *StorageStatsRequest, opts ...grpc.CallOption) (*StorageStatsResponse, error)
+	// WatchEvents streams the storage writes of the tenant, or of one session,
+	// until the client cancels (GET /api/v1/events)
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type sessionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSessionServiceClient(cc grpc.ClientConnInterface) SessionServiceClient {
+	return &sessionServiceClient{cc}
+}
+
+func (c *sessionServiceClient) GetSessionStats(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionStatsResponse)
+	err := c.cc.Invoke(ctx, SessionService_GetSessionStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) ListRecords(ctx context.Context, in *ListRecordsRequest, opts ...grpc.CallOption) (*ListRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRecordsResponse)
+	err := c.cc.Invoke(ctx, SessionService_ListRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) SearchSession(ctx context.Context, in *SearchSessionRequest, opts ...grpc.CallOption) (*SearchSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchSessionResponse)
+	err := c.cc.Invoke(ctx, SessionService_SearchSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) ClearSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionStatusResponse)
+	err := c.cc.Invoke(ctx, SessionService_ClearSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) ArchiveSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionStatusResponse)
+	err := c.cc.Invoke(ctx, SessionService_ArchiveSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) RestoreSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionStatusResponse)
+	err := c.cc.Invoke(ctx, SessionService_RestoreSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) GetStorageStats(ctx context.Context, in *StorageStatsRequest, opts ...grpc.CallOption) (*StorageStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StorageStatsResponse)
+	err := c.cc.Invoke(ctx, SessionService_GetStorageStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SessionService_ServiceDesc.Streams[0], SessionService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SessionService_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+// SessionServiceServer is the server API for SessionService service.
+// All implementations must embed UnimplementedSessionServiceServer
+// for forward compatibility.
+//
+// SessionService inspects and manages sessions
+type SessionServiceServer interface {
+	// GetSessionStats returns a session's statistics (GET /api/v1/session/{id}/stats)
+	GetSessionStats(context.Context, *SessionRequest) (*SessionStatsResponse, error)
+	// ListRecords returns a page of a session's records (GET /api/v1/session/{id}/records)
+	ListRecords(context.Context, *ListRecordsRequest) (*ListRecordsResponse, error)
+	// SearchSession searches a session's text (GET /api/v1/session/{id}/search)
+	SearchSession(context.Context, *SearchSessionRequest) (*SearchSessionResponse, error)
+	// ClearSession deletes a session and its records (POST /api/v1/session/{id}/clear)
+	ClearSession(context.Context, *SessionRequest) (*SessionStatusResponse, error)
+	// ArchiveSession archives a session (POST /api/v1/session/{id}/archive)
+	ArchiveSession(context.Context, *SessionRequest) (*SessionStatusResponse, error)
+	// RestoreSession restores an archived session (POST /api/v1/session/{id}/restore)
+	RestoreSession(context.Context, *SessionRequest) (*SessionStatusResponse, error)
+	// GetStorageStats returns storage statistics (GET /api/v1/storage/stats)
+	GetStorageStats(context.Context, *StorageStatsRequest) (*StorageStatsResponse, error)
+	// WatchEvents streams the storage writes of the tenant, or of one session,
+	// until the client cancels (GET /api/v1/events)
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedSessionServiceServer()
+}
+
+// UnimplementedSessionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSessionServiceServer struct{}
+
+func (UnimplementedSessionServiceServer) GetSessionStats(context.Context, *SessionRequest) (*SessionStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSessionStats not implemented")
+}
+func (UnimplementedSessionServiceServer) ListRecords(context.Context, *ListRecordsRequest) (*ListRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecords not implemented")
+}
+func (UnimplementedSessionServiceServer) SearchSession(context.Context, *SearchSessionRequest) (*SearchSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSession not implemented")
+}
+func (UnimplementedSessionServiceServer) ClearSession(context.Context, *SessionRequest) (*SessionStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearSession not implemented")
+}
+func (UnimplementedSessionServiceServer) ArchiveSession(context.Context, *SessionRequest) (*SessionStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveSession not implemented")
+}
+func (UnimplementedSessionServiceServer) RestoreSession(context.Context, *SessionRequest) (*SessionStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSession not implemented")
+}
+func (UnimplementedSessionServiceServer) GetStorageStats(context.Context, *StorageStatsRequest) (*StorageStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorageStats not implemented")
+}
+func (UnimplementedSessionServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedSessionServiceServer) mustEmbedUnimplementedSessionServiceServer() {}
+func (UnimplementedSessionServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeSessionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SessionServiceServer will
+// result in compilation errors.
+type UnsafeSessionServiceServer interface {
+	mustEmbedUnimplementedSessionServiceServer()
+}
+
+func RegisterSessionServiceServer(s grpc.ServiceRegistrar, srv SessionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSessionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SessionService_ServiceDesc, srv)
+}
+
+func _SessionService_GetSessionStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).GetSessionStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_GetSessionStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).GetSessionStats(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_ListRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).ListRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_ListRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).ListRecords(ctx, req.(*ListRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_SearchSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).SearchSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_SearchSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).SearchSession(ctx, req.(*SearchSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_ClearSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).ClearSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_ClearSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).ClearSession(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_ArchiveSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).ArchiveSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_ArchiveSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).ArchiveSession(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_RestoreSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).RestoreSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_RestoreSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).RestoreSession(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_GetStorageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).GetStorageStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SessionService_GetStorageStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).GetStorageStats(ctx, req.(*StorageStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SessionServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SessionService_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+// SessionService_ServiceDesc is the grpc.ServiceDesc for SessionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SessionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gothink.v1.SessionService",
+	HandlerType: (*SessionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSessionStats",
+			Handler:    _SessionService_GetSessionStats_Handler,
+		},
+		{
+			MethodName: "ListRecords",
+			Handler:    _SessionService_ListRecords_Handler,
+		},
+		{
+			MethodName: "SearchSession",
+			Handler:    _SessionService_SearchSession_Handler,
+		},
+		{
+			MethodName: "ClearSession",
+			Handler:    _SessionService_ClearSession_Handler,
+		},
+		{
+			MethodName: "ArchiveSession",
+			Handler:    _SessionService_ArchiveSession_Handler,
+		},
+		{
+			MethodName: "RestoreSession",
+			Handler:    _SessionService_RestoreSession_Handler,
+		},
+		{
+			MethodName: "GetStorageStats",
+			Handler:    _SessionService_GetStorageStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _SessionService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/gothink/v1/gothink.proto",
+}
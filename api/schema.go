@@ -0,0 +1,139 @@
+// Package api defines the typed requests and responses of GoThink's tools.
+// The HTTP API decodes its request bodies into them, the MCP server binds tool
+// arguments to them and declares their JSON schemas as the tools' input
+// schemas, so both transports accept the same fields.
+//
+// Fields are described to the schema generator with struct tags: json names
+// the property, description documents it, and jsonschema holds comma
+// separated constraints:
+//
+//	Thought       string `json:"thought" jsonschema:"required" description:"Current thought content"`
+//	ThoughtNumber int    `json:"thought_number" jsonschema:"required,minimum=1"`
+//	Operation     string `json:"operation" jsonschema:"enum=create|update|delete"`
+//
+// The constraints are required, minimum, maximum, minLength, minItems and
+// enum, whose values are separated by |.
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema returns the JSON schema of the object v encodes as. v must be a
+// struct or a pointer to one.
+func Schema(v interface{}) map[string]any {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("api: schema of non-struct type %s", t))
+	}
+	return typeSchema(t)
+}
+
+// Properties returns the property schemas and the required property names of
+// the object v encodes as, as Schema does
+func Properties(v interface{}) (map[string]any, []string) {
+	schema := Schema(v)
+	properties, _ := schema["properties"].(map[string]any)
+	required, _ := schema["required"].([]string)
+	return properties, required
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeSchema returns the JSON schema of the values of t
+func typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object"}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	// Interfaces accept any value
+	return map[string]any{}
+}
+
+// structSchema returns the object schema of the exported, JSON-encoded fields
+// of t
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := typeSchema(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+		if applyConstraints(schema, field.Tag.Get("jsonschema")) {
+			required = append(required, name)
+		}
+		properties[name] = schema
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyConstraints adds the constraints of a jsonschema tag to schema,
+// reporting whether the tag marks the property required
+func applyConstraints(schema map[string]any, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	var required bool
+	for _, constraint := range strings.Split(tag, ",") {
+		keyword, value, _ := strings.Cut(constraint, "=")
+		switch keyword {
+		case "required":
+			required = true
+		case "minimum", "maximum", "minLength", "minItems":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				panic(fmt.Sprintf("api: invalid jsonschema constraint %q", constraint))
+			}
+			schema[keyword] = number
+		case "enum":
+			schema["enum"] = strings.Split(value, "|")
+		default:
+			panic(fmt.Sprintf("api: unknown jsonschema constraint %q", constraint))
+		}
+	}
+	return required
+}
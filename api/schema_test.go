@@ -0,0 +1,63 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_DescribesTaggedFields(t *testing.T) {
+	type item struct {
+		Name   string  `json:"name" jsonschema:"required"`
+		Weight float64 `json:"weight" jsonschema:"minimum=0,maximum=1"`
+	}
+	type request struct {
+		SessionID string                 `json:"session_id" jsonschema:"required" description:"Session identifier"`
+		Count     int                    `json:"count,omitempty" jsonschema:"minimum=1"`
+		Mode      string                 `json:"mode" jsonschema:"enum=fast|slow"`
+		Limit     *int                   `json:"limit,omitempty"`
+		Items     []item                 `json:"items" jsonschema:"minItems=1"`
+		Extra     map[string]interface{} `json:"extra"`
+		Since     time.Time              `json:"since"`
+		Ignored   string                 `json:"-"`
+		internal  string
+	}
+
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"session_id": map[string]any{"type": "string", "description": "Session identifier"},
+			"count":      map[string]any{"type": "integer", "minimum": 1.0},
+			"mode":       map[string]any{"type": "string", "enum": []string{"fast", "slow"}},
+			"limit":      map[string]any{"type": "integer"},
+			"items": map[string]any{
+				"type":     "array",
+				"minItems": 1.0,
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"name":   map[string]any{"type": "string"},
+						"weight": map[string]any{"type": "number", "minimum": 0.0, "maximum": 1.0},
+					},
+					"required": []string{"name"},
+				},
+			},
+			"extra": map[string]any{"type": "object"},
+			"since": map[string]any{"type": "string", "format": "date-time"},
+		},
+		"required": []string{"session_id"},
+	}, Schema(&request{}))
+
+	properties, required := Properties(SequentialThinkingRequest{})
+	assert.Equal(t, []string{"session_id", "thought", "thought_number", "total_thoughts", "next_thought_needed"}, required)
+	assert.Contains(t, properties, "revises_thought")
+}
+
+func TestSchema_RejectsUnknownConstraints(t *testing.T) {
+	type request struct {
+		Name string `json:"name" jsonschema:"pattern=^a"`
+	}
+	assert.Panics(t, func() { Schema(request{}) })
+	assert.Panics(t, func() { Schema("not a struct") })
+}
@@ -0,0 +1,1032 @@
+package api
+
+// StreamUpdate is a best-so-far result of a streamed run after Iteration of
+// at most Total iterations. Which of the best fields are set depends on the
+// algorithm: an MDP's policy, the best move of a tree search or the best
+// parameters of an optimization.
+type StreamUpdate struct {
+	Algorithm      string             `json:"algorithm"`
+	Iteration      int                `json:"iteration"`
+	Total          int                `json:"total"`
+	Summary        string             `json:"summary"`
+	Policy         map[string]string  `json:"policy,omitempty"`
+	BestAction     string             `json:"best_action,omitempty"`
+	BestParameters map[string]float64 `json:"best_parameters,omitempty"`
+	// BestValue is the value of the best parameters, or the mean reward of
+	// the best move
+	BestValue *float64 `json:"best_value,omitempty"`
+	Residual  float64  `json:"residual"`
+}
+
+// MDPRequest solves a Markov decision process given by its transition and
+// reward model
+type MDPRequest struct {
+	SessionID      string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem        string          `json:"problem" jsonschema:"required" description:"Problem description for MDP"`
+	Transitions    []MDPTransition `json:"transitions" jsonschema:"required,minItems=1" description:"Outcomes of taking each action in each state; states without transitions are terminal"`
+	States         int             `json:"states,omitempty" jsonschema:"minimum=1" description:"Number of states, checked against the states the transitions name"`
+	Actions        []string        `json:"actions,omitempty" description:"Actions the transitions may take (default any)"`
+	Gamma          float64         `json:"gamma" jsonschema:"minimum=0,maximum=1" description:"Discount factor"`
+	Method         string          `json:"method,omitempty" jsonschema:"enum=value_iteration|policy_iteration" description:"Solver (default value_iteration)"`
+	Tolerance      float64         `json:"tolerance,omitempty" jsonschema:"minimum=0" description:"Largest change of a state's value at convergence (default 1e-6)"`
+	MaxIterations  int             `json:"max_iterations,omitempty" jsonschema:"minimum=1" description:"Most sweeps or policy improvements to run (default 1000)"`
+	TimeLimit      int             `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which to stop with the policy so far, not converged (default none)"`
+	Stream         bool            `json:"stream,omitempty" description:"Send best-so-far results every stream_interval iterations: as server-sent events over HTTP, or as progress notifications over MCP"`
+	StreamInterval int             `json:"stream_interval,omitempty" jsonschema:"minimum=1" description:"Iterations between streamed results (default 10)"`
+	Trace          bool            `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the residual of each iteration as a convergence plot; its ID is returned as trace_id"`
+}
+
+// MDPTransition is one outcome of taking an action in a state
+type MDPTransition struct {
+	State       string  `json:"state" jsonschema:"required" description:"State the action is taken in"`
+	Action      string  `json:"action" jsonschema:"required" description:"Action taken"`
+	NextState   string  `json:"next_state" jsonschema:"required" description:"State reached"`
+	Probability float64 `json:"probability" jsonschema:"minimum=0,maximum=1" description:"Probability of reaching next_state; the outcomes of an action sum to 1"`
+	Reward      float64 `json:"reward" description:"Reward received on reaching next_state"`
+}
+
+// MDPResponse reports a recorded MDP solution: the optimal policy, the value
+// of each state under it and the Q-value of each action
+type MDPResponse struct {
+	AlgorithmID   string                        `json:"algorithm_id"`
+	Status        string                        `json:"status"`
+	Summary       string                        `json:"summary"`
+	HasResult     bool                          `json:"has_result"`
+	Converged     bool                          `json:"converged"`
+	Iterations    int                           `json:"iterations"`
+	Policy        map[string]string             `json:"policy"`
+	ValueFunction map[string]float64            `json:"value_function"`
+	QValues       map[string]map[string]float64 `json:"q_values"`
+	Convergence   Convergence                   `json:"convergence"`
+	Confidence    *Confidence                   `json:"confidence,omitempty"`
+	TraceID       string                        `json:"trace_id,omitempty"`
+}
+
+// Convergence reports how a run ended: whether it converged, why it stopped
+// (tolerance, policy_stable, plateau, max_iterations, time_limit or exact)
+// and the residual of each iteration or checkpoint, whose meaning depends on
+// the algorithm
+type Convergence struct {
+	Method         string    `json:"method,omitempty"`
+	Iterations     int       `json:"iterations"`
+	Converged      bool      `json:"converged"`
+	StoppingReason string    `json:"stopping_reason"`
+	Tolerance      float64   `json:"tolerance,omitempty"`
+	Residual       float64   `json:"residual"`
+	Residuals      []float64 `json:"residuals"`
+	// RHat is the Gelman-Rubin statistic of a sampler's chains
+	RHat float64 `json:"r_hat,omitempty"`
+	// ElapsedSeconds is how long the run took
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+// Confidence is how sure a run is of its answer, computed from the run
+// itself: the chance, by Method, that the answer is right, with Basis saying
+// what that method takes as right
+type Confidence struct {
+	Value  float64 `json:"value"`
+	Method string  `json:"method"`
+	Basis  string  `json:"basis"`
+}
+
+// MCTSRequest runs a Monte Carlo tree search with UCT over a game given by
+// its states
+type MCTSRequest struct {
+	SessionID           string      `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem             string      `json:"problem" jsonschema:"required" description:"Problem description for MCTS"`
+	RootState           string      `json:"root_state" jsonschema:"required" description:"State to search from"`
+	States              []MCTSState `json:"states" jsonschema:"required,minItems=1" description:"States of the game with their legal moves; states without moves are terminal"`
+	Simulations         int         `json:"simulations,omitempty" jsonschema:"minimum=1" description:"Simulations to run (default 1000)"`
+	ExplorationConstant float64     `json:"exploration_constant,omitempty" jsonschema:"minimum=0" description:"UCT exploration constant (default 1.41)"`
+	MaxDepth            int         `json:"max_depth,omitempty" jsonschema:"minimum=1" description:"Maximum depth of the tree and playouts (default 10)"`
+	TimeLimit           int         `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Time limit in seconds (default 30)"`
+	Seed                int64       `json:"seed,omitempty" description:"Seed of the playouts' randomness, for reproducible runs (default random)"`
+	Parallelism         int         `json:"parallelism,omitempty" jsonschema:"minimum=1,maximum=64" description:"Trees searched at once, each with its share of the simulations, merged into one; unlike the simulations, the result depends on it (default 1)"`
+	Stream              bool        `json:"stream,omitempty" description:"Send best-so-far results every stream_interval simulations: as server-sent events over HTTP, or as progress notifications over MCP"`
+	StreamInterval      int         `json:"stream_interval,omitempty" jsonschema:"minimum=1" description:"Simulations between streamed results (default a tenth of the simulations)"`
+	Trace               bool        `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the 100 most visited nodes of the search tree; its ID is returned as trace_id"`
+}
+
+// MCTSState is one state of a game searched by MCTS. Rewards are from the
+// first player's point of view.
+type MCTSState struct {
+	Name   string     `json:"name" jsonschema:"required" description:"State name"`
+	Player int        `json:"player,omitempty" jsonschema:"minimum=1,maximum=2" description:"Player to move: 1 maximizes rewards, 2 minimizes them (default 1)"`
+	Reward float64    `json:"reward,omitempty" description:"Reward of a terminal state, or estimated value of a state a playout stops in at max_depth"`
+	Moves  []MCTSMove `json:"moves,omitempty" description:"Legal moves"`
+}
+
+// MCTSMove is a legal move and the state it leads to
+type MCTSMove struct {
+	Move      string `json:"move" jsonschema:"required" description:"Move name"`
+	NextState string `json:"next_state" jsonschema:"required" description:"State the move leads to"`
+}
+
+// MCTSResponse reports a recorded MCTS run: the best move from the root,
+// the statistics of each move and the principal variation
+type MCTSResponse struct {
+	AlgorithmID        string                 `json:"algorithm_id"`
+	Status             string                 `json:"status"`
+	Summary            string                 `json:"summary"`
+	HasResult          bool                   `json:"has_result"`
+	BestAction         string                 `json:"best_action"`
+	Actions            []MCTSActionStats      `json:"actions"`
+	PrincipalVariation []string               `json:"principal_variation"`
+	Simulations        int                    `json:"simulations"`
+	TreeStats          map[string]interface{} `json:"tree_stats"`
+	Convergence        Convergence            `json:"convergence"`
+	Confidence         *Confidence            `json:"confidence,omitempty"`
+	TraceID            string                 `json:"trace_id,omitempty"`
+}
+
+// MCTSActionStats are the visits and mean reward of one move from the root
+type MCTSActionStats struct {
+	Move   string  `json:"move"`
+	Visits int     `json:"visits"`
+	Q      float64 `json:"q"`
+}
+
+// BanditRequest plays a multi-armed bandit over arms given by their reward
+// distributions or observed rewards
+type BanditRequest struct {
+	SessionID       string      `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem         string      `json:"problem" jsonschema:"required" description:"Problem description for bandit"`
+	Arms            []BanditArm `json:"arms" jsonschema:"required,minItems=1" description:"Arms of the bandit"`
+	Strategy        string      `json:"strategy,omitempty" jsonschema:"enum=epsilon_greedy|ucb1|thompson" description:"Arm selection strategy (default epsilon_greedy)"`
+	Steps           int         `json:"steps,omitempty" jsonschema:"minimum=1" description:"Pulls to play (default 1000)"`
+	TimeLimit       int         `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which to stop with the pulls so far, not converged (default none)"`
+	Epsilon         float64     `json:"epsilon,omitempty" jsonschema:"minimum=0,maximum=1" description:"Exploration rate of epsilon_greedy (default 0.1)"`
+	Alpha           float64     `json:"alpha,omitempty" jsonschema:"minimum=0" description:"Prior alpha of thompson (default 1)"`
+	Beta            float64     `json:"beta,omitempty" jsonschema:"minimum=0" description:"Prior beta of thompson (default 1)"`
+	DriftMode       string      `json:"drift_mode,omitempty" jsonschema:"enum=none|discounted|sliding_window" description:"How the strategy follows arms whose rewards change: by every reward, by rewards discounted for their age, or by the rewards of a sliding window; with ucb1, discounted or sliding-window UCB (default none)"`
+	Discount        float64     `json:"discount,omitempty" jsonschema:"minimum=0,maximum=1" description:"Weight a reward keeps for every later pull under discounted drift (default 0.99)"`
+	Window          int         `json:"window,omitempty" jsonschema:"minimum=1" description:"Pulls the strategy goes by under sliding_window drift (default 100)"`
+	ChangeThreshold float64     `json:"change_threshold,omitempty" jsonschema:"minimum=0" description:"CUSUM threshold, in standard deviations of an arm's rewards, past which a change of its mean is reported (default 12)"`
+	Seed            int64       `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Trace           bool        `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the regret curve as a convergence plot; its ID is returned as trace_id"`
+}
+
+// BanditArm is one arm of a bandit: a reward distribution or the rewards
+// observed from it
+type BanditArm struct {
+	Name            string         `json:"name,omitempty" description:"Arm name"`
+	Distribution    string         `json:"distribution,omitempty" jsonschema:"enum=bernoulli|gaussian|empirical" description:"Reward distribution (default empirical with observed_rewards, bernoulli otherwise)"`
+	Mean            float64        `json:"mean,omitempty" description:"Success probability of a bernoulli arm or mean of a gaussian arm"`
+	StdDev          float64        `json:"std_dev,omitempty" jsonschema:"minimum=0" description:"Standard deviation of a gaussian arm"`
+	ObservedRewards []float64      `json:"observed_rewards,omitempty" description:"Rewards observed from an empirical arm, which pulls resample"`
+	Changes         []BanditChange `json:"changes,omitempty" description:"Changes of a bernoulli or gaussian arm's mean during the run, by step in increasing order"`
+}
+
+// BanditChange changes the mean of a bandit arm from a pull on
+type BanditChange struct {
+	Step int     `json:"step" jsonschema:"required,minimum=1" description:"Pull from which the arm has the new mean"`
+	Mean float64 `json:"mean" description:"New success probability or mean of the arm"`
+}
+
+// ArmStatistics summarizes the pulls of one bandit arm
+type ArmStatistics struct {
+	Arm            int     `json:"arm"`
+	Name           string  `json:"name,omitempty"`
+	Pulls          int     `json:"pulls"`
+	Rewards        float64 `json:"rewards"`
+	AverageReward  float64 `json:"average_reward"`
+	ExpectedReward float64 `json:"expected_reward"`
+}
+
+// ChangePoint is a change of a bandit arm's mean detected at a step, after the
+// arm had paid out Before on average since its previous change point
+type ChangePoint struct {
+	Arm       int     `json:"arm"`
+	Name      string  `json:"name,omitempty"`
+	Step      int     `json:"step"`
+	Direction string  `json:"direction"`
+	Before    float64 `json:"before"`
+}
+
+// RegretPoint is the cumulative regret of a bandit run after a number of pulls
+type RegretPoint struct {
+	Step   int     `json:"step"`
+	Regret float64 `json:"regret"`
+}
+
+// BanditResponse reports a recorded bandit run: the pulls of each arm and the
+// regret against always pulling the optimal arm
+type BanditResponse struct {
+	AlgorithmID string          `json:"algorithm_id"`
+	Status      string          `json:"status"`
+	Summary     string          `json:"summary"`
+	HasResult   bool            `json:"has_result"`
+	SelectedArm int             `json:"selected_arm"`
+	OptimalArm  int             `json:"optimal_arm"`
+	ArmStats    []ArmStatistics `json:"arm_stats"`
+	TotalReward float64         `json:"total_reward"`
+	Regret      float64         `json:"regret"`
+	RegretCurve []RegretPoint   `json:"regret_curve"`
+	// ChangePoints are the changes of the arms' means detected, by step
+	ChangePoints []ChangePoint `json:"change_points"`
+	Convergence  Convergence   `json:"convergence"`
+	Confidence   *Confidence   `json:"confidence,omitempty"`
+	TraceID      string        `json:"trace_id,omitempty"`
+}
+
+// BayesianOptimizationRequest runs a Bayesian optimization with a
+// Gaussian-process surrogate over a box of parameters, evaluating an objective
+// expression or fitting observed evaluations
+type BayesianOptimizationRequest struct {
+	SessionID           string                `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem             string                `json:"problem" jsonschema:"required" description:"Problem description for the optimization"`
+	Parameters          []BayesianParameter   `json:"parameters" jsonschema:"required,minItems=1" description:"Parameters of the search space with their bounds"`
+	Objective           string                `json:"objective,omitempty" description:"Arithmetic expression over the parameters to optimize, e.g. -pow(x-1, 2) + sin(y); without one, only the history is fitted"`
+	History             []BayesianObservation `json:"history,omitempty" description:"Evaluations already observed"`
+	Goal                string                `json:"goal,omitempty" jsonschema:"enum=maximize|minimize" description:"Whether to seek the highest or lowest value (default maximize)"`
+	AcquisitionFunction string                `json:"acquisition_function,omitempty" jsonschema:"enum=ei|ucb|pi" description:"Acquisition function: expected improvement, upper confidence bound or probability of improvement (default ei)"`
+	Kernel              string                `json:"kernel,omitempty" jsonschema:"enum=rbf|matern32|matern52" description:"Gaussian process kernel (default matern52)"`
+	Iterations          int                   `json:"iterations,omitempty" jsonschema:"minimum=1" description:"Evaluations of the objective to run (default 20)"`
+	TimeLimit           int                   `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which to stop with the best point so far, not converged (default none)"`
+	InitialPoints       int                   `json:"initial_points,omitempty" jsonschema:"minimum=1" description:"Observations to gather at random points before the surrogate takes over (default 3)"`
+	ExplorationWeight   float64               `json:"exploration_weight,omitempty" jsonschema:"minimum=0" description:"Improvement margin of ei and pi, or weight of uncertainty in ucb, in standard deviations of the values (default 0.01, or 2 for ucb)"`
+	LengthScale         float64               `json:"length_scale,omitempty" jsonschema:"minimum=0" description:"Kernel length scale as a fraction of each parameter's range (default 0.2)"`
+	Noise               float64               `json:"noise,omitempty" jsonschema:"minimum=0" description:"Observation noise variance relative to the values' variance (default 1e-6)"`
+	Tolerance           float64               `json:"tolerance,omitempty" jsonschema:"minimum=0" description:"Smallest improvement of the best value that counts as progress (default 1e-6)"`
+	Patience            int                   `json:"patience,omitempty" jsonschema:"minimum=1" description:"Evaluations without progress after which the run has converged (default 5)"`
+	StopAtPlateau       bool                  `json:"stop_at_plateau,omitempty" description:"Stop once the run has converged rather than running every iteration"`
+	Seed                int64                 `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Stream              bool                  `json:"stream,omitempty" description:"Send best-so-far results every stream_interval evaluations: as server-sent events over HTTP, or as progress notifications over MCP"`
+	StreamInterval      int                   `json:"stream_interval,omitempty" jsonschema:"minimum=1" description:"Evaluations between streamed results (default 1)"`
+	Trace               bool                  `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the value and best value of each evaluation as a convergence plot; its ID is returned as trace_id"`
+}
+
+// BayesianParameter is one bounded dimension of a search space
+type BayesianParameter struct {
+	Name string  `json:"name" jsonschema:"required" description:"Parameter name, usable in the objective"`
+	Min  float64 `json:"min" description:"Lower bound"`
+	Max  float64 `json:"max" description:"Upper bound"`
+}
+
+// BayesianObservation is an observed evaluation of the objective
+type BayesianObservation struct {
+	Parameters map[string]float64 `json:"parameters" jsonschema:"required" description:"Value of every parameter"`
+	Value      float64            `json:"value" description:"Objective value observed"`
+}
+
+// BayesianOptimizationResponse reports a recorded Bayesian optimization: the
+// best point with the posterior there, the evaluations run and the point to
+// evaluate next
+type BayesianOptimizationResponse struct {
+	AlgorithmID     string             `json:"algorithm_id"`
+	Status          string             `json:"status"`
+	Summary         string             `json:"summary"`
+	HasResult       bool               `json:"has_result"`
+	BestParameters  map[string]float64 `json:"best_parameters"`
+	BestValue       float64            `json:"best_value"`
+	BestPosterior   GPPosterior        `json:"best_posterior"`
+	Iterations      int                `json:"iterations"`
+	History         []OptimizationStep `json:"history"`
+	NextParameters  map[string]float64 `json:"next_parameters"`
+	NextAcquisition float64            `json:"next_acquisition"`
+	NextPosterior   GPPosterior        `json:"next_posterior"`
+	Convergence     Convergence        `json:"convergence"`
+	Confidence      *Confidence        `json:"confidence,omitempty"`
+	TraceID         string             `json:"trace_id,omitempty"`
+}
+
+// GPPosterior is the mean and variance of the Gaussian process at a point
+type GPPosterior struct {
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+}
+
+// OptimizationStep is one evaluation of the objective: a random initial point,
+// or the point maximizing the acquisition with the posterior predicted there
+type OptimizationStep struct {
+	Iteration   int                `json:"iteration"`
+	Parameters  map[string]float64 `json:"parameters"`
+	Value       float64            `json:"value"`
+	Random      bool               `json:"random,omitempty"`
+	Acquisition float64            `json:"acquisition,omitempty"`
+	Predicted   GPPosterior        `json:"predicted"`
+}
+
+// HMMRequest decodes an observation sequence with a hidden Markov model,
+// given its parameters or fitting them to the sequence
+type HMMRequest struct {
+	SessionID     string      `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem       string      `json:"problem" jsonschema:"required" description:"Problem description for the HMM"`
+	Observations  []string    `json:"observations" jsonschema:"required,minItems=1" description:"Observation sequence, as symbols"`
+	Symbols       []string    `json:"symbols,omitempty" description:"Observation symbols, ordering the emission columns (default the symbols of the sequence in order of appearance)"`
+	States        int         `json:"states,omitempty" jsonschema:"minimum=1" description:"Number of hidden states (default the number of state_names or of initial probabilities, or 2)"`
+	StateNames    []string    `json:"state_names,omitempty" description:"Names of the hidden states (default state_1, state_2, ...)"`
+	Initial       []float64   `json:"initial,omitempty" description:"Probability of starting in each state"`
+	Transitions   [][]float64 `json:"transitions,omitempty" description:"Probability of moving from each state (row) to each state (column)"`
+	Emissions     [][]float64 `json:"emissions,omitempty" description:"Probability of each state (row) emitting each symbol (column)"`
+	Algorithm     string      `json:"algorithm,omitempty" jsonschema:"enum=viterbi|baum_welch" description:"viterbi decodes with the given parameters; baum_welch fits them first, starting from the given parameters or random ones (default viterbi when parameters are given, baum_welch otherwise)"`
+	MaxIterations int         `json:"max_iterations,omitempty" jsonschema:"minimum=1" description:"Baum-Welch iterations to run at most (default 100)"`
+	Tolerance     float64     `json:"tolerance,omitempty" jsonschema:"minimum=0" description:"Smallest log-likelihood gain for Baum-Welch to go on (default 1e-6)"`
+	TimeLimit     int         `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which Baum-Welch stops with the model so far, not converged (default none)"`
+	Seed          int64       `json:"seed,omitempty" description:"Seed of the random starting parameters, for reproducible runs (default random)"`
+	Trace         bool        `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the residual of each Baum-Welch iteration as a convergence plot; its ID is returned as trace_id"`
+}
+
+// HMMResponse reports a recorded HMM run: the decoded state path, the
+// sequence's likelihood and the model's parameters
+type HMMResponse struct {
+	AlgorithmID        string      `json:"algorithm_id"`
+	Status             string      `json:"status"`
+	Summary            string      `json:"summary"`
+	HasResult          bool        `json:"has_result"`
+	States             int         `json:"states"`
+	Observations       int         `json:"observations"`
+	StateNames         []string    `json:"state_names"`
+	Symbols            []string    `json:"symbols"`
+	StatePath          []string    `json:"state_path"`
+	PathLogProbability float64     `json:"path_log_probability"`
+	LogLikelihood      float64     `json:"log_likelihood"`
+	StatePosteriors    [][]float64 `json:"state_posteriors"`
+	Initial            []float64   `json:"initial"`
+	Transitions        [][]float64 `json:"transitions"`
+	Emissions          [][]float64 `json:"emissions"`
+	Iterations         int         `json:"iterations"`
+	Converged          bool        `json:"converged"`
+	Convergence        Convergence `json:"convergence"`
+	Confidence         *Confidence `json:"confidence,omitempty"`
+	TraceID            string      `json:"trace_id,omitempty"`
+}
+
+// QLearningRequest learns a policy by tabular Q-learning, SARSA or expected
+// SARSA over episodes in an environment given by its transition model, by
+// observed transitions or as a grid world
+type QLearningRequest struct {
+	SessionID    string            `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem      string            `json:"problem" jsonschema:"required" description:"Problem description for Q-learning"`
+	Method       string            `json:"method,omitempty" jsonschema:"enum=q_learning|sarsa|expected_sarsa" description:"Learning method (default q_learning)"`
+	Transitions  []MDPTransition   `json:"transitions,omitempty" description:"Transition and reward model of the environment; states without transitions are terminal"`
+	Samples      []QLearningSample `json:"samples,omitempty" description:"Observed transitions to estimate the environment from, when no transitions are given"`
+	Grid         *GridWorld        `json:"grid,omitempty" description:"Grid world to learn in, when neither transitions nor samples are given"`
+	States       int               `json:"states,omitempty" jsonschema:"minimum=1" description:"Number of states, checked against the states of the environment"`
+	Actions      []string          `json:"actions,omitempty" description:"Actions the environment may take (default any)"`
+	StartState   string            `json:"start_state,omitempty" description:"State each episode starts in (default a random non-terminal state)"`
+	Gamma        float64           `json:"gamma" jsonschema:"minimum=0,maximum=1" description:"Discount factor"`
+	LearningRate float64           `json:"learning_rate,omitempty" jsonschema:"minimum=0,maximum=1" description:"Weight of each Q-value update (default 0.1)"`
+	Epsilon      float64           `json:"epsilon,omitempty" jsonschema:"minimum=0,maximum=1" description:"Exploration rate of the first episode (default 1)"`
+	EpsilonDecay float64           `json:"epsilon_decay,omitempty" jsonschema:"minimum=0,maximum=1" description:"Factor applied to the exploration rate after each episode (default 0.99)"`
+	MinEpsilon   float64           `json:"min_epsilon,omitempty" jsonschema:"minimum=0,maximum=1" description:"Lowest exploration rate (default 0.01)"`
+	Episodes     int               `json:"episodes,omitempty" jsonschema:"minimum=1" description:"Episodes to run (default 500)"`
+	TimeLimit    int               `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which to stop with the Q-values learned so far, not converged (default none)"`
+	MaxSteps     int               `json:"max_steps,omitempty" jsonschema:"minimum=1" description:"Most steps per episode (default 100)"`
+	Seed         int64             `json:"seed,omitempty" description:"Seed of the episodes' randomness, for reproducible runs (default random)"`
+	Trace        bool              `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the reward of each episode as a convergence plot; its ID is returned as trace_id"`
+}
+
+// GridWorld is a grid-world environment. Its states are named row,column
+// from 0,0 at the top left, and its actions are up, down, left and right.
+type GridWorld struct {
+	Layout     []string `json:"layout" jsonschema:"required,minItems=1" description:"One string per row, one cell per character: . open, # wall, S start, G goal, X pit; goals and pits end an episode"`
+	StepReward *float64 `json:"step_reward,omitempty" description:"Reward of a step into an open cell (default -1)"`
+	GoalReward *float64 `json:"goal_reward,omitempty" description:"Reward of a step into a goal (default 10)"`
+	PitReward  *float64 `json:"pit_reward,omitempty" description:"Reward of a step into a pit (default -10)"`
+	Slip       float64  `json:"slip,omitempty" jsonschema:"minimum=0,maximum=1" description:"Chance a move goes to either side of the direction chosen instead"`
+}
+
+// QLearningSample is one observed transition of an environment
+type QLearningSample struct {
+	State     string  `json:"state" jsonschema:"required" description:"State the action was taken in"`
+	Action    string  `json:"action" jsonschema:"required" description:"Action taken"`
+	NextState string  `json:"next_state" jsonschema:"required" description:"State reached"`
+	Reward    float64 `json:"reward" description:"Reward received"`
+}
+
+// QLearningResponse reports a recorded learning run: the learned policy,
+// drawn over the grid for grid worlds, the value of each state under it, the
+// Q-value of each action and the learning curve
+type QLearningResponse struct {
+	AlgorithmID   string                        `json:"algorithm_id"`
+	Status        string                        `json:"status"`
+	Summary       string                        `json:"summary"`
+	HasResult     bool                          `json:"has_result"`
+	Method        string                        `json:"method"`
+	Episodes      int                           `json:"episodes"`
+	Policy        map[string]string             `json:"policy"`
+	PolicyGrid    []string                      `json:"policy_grid,omitempty"`
+	ValueFunction map[string]float64            `json:"value_function"`
+	QValues       map[string]map[string]float64 `json:"q_values"`
+	LearningCurve []QLearningEpisode            `json:"learning_curve"`
+	Convergence   Convergence                   `json:"convergence"`
+	TraceID       string                        `json:"trace_id,omitempty"`
+}
+
+// QLearningEpisode reports one episode of a learning run
+type QLearningEpisode struct {
+	Episode  int     `json:"episode"`
+	Reward   float64 `json:"reward"`
+	Steps    int     `json:"steps"`
+	Epsilon  float64 `json:"epsilon"`
+	Residual float64 `json:"residual"`
+}
+
+// AnnealingRequest minimizes an objective expression over a box of variables
+// by simulated annealing
+type AnnealingRequest struct {
+	SessionID          string              `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem            string              `json:"problem" jsonschema:"required" description:"Problem description for the annealing run"`
+	Variables          []AnnealingVariable `json:"variables" jsonschema:"required,minItems=1" description:"Variables of the search space with their bounds"`
+	Objective          string              `json:"objective" jsonschema:"required" description:"Arithmetic expression over the variables to minimize, e.g. pow(x-1, 2) + abs(y)"`
+	Schedule           string              `json:"schedule,omitempty" jsonschema:"enum=exponential|linear|logarithmic|fast" description:"Cooling schedule from the initial to the final temperature (default exponential)"`
+	InitialTemperature float64             `json:"initial_temperature,omitempty" jsonschema:"minimum=0" description:"Temperature of the first iteration, in the objective's units (default 1)"`
+	FinalTemperature   float64             `json:"final_temperature,omitempty" jsonschema:"minimum=0" description:"Temperature of the last iteration (default 0.001)"`
+	Iterations         int                 `json:"iterations,omitempty" jsonschema:"minimum=1" description:"Moves to propose (default 1000)"`
+	TimeLimit          int                 `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which to stop with the best point so far, not converged (default none)"`
+	StepSize           float64             `json:"step_size,omitempty" jsonschema:"minimum=0" description:"Standard deviation of a move as a fraction of each variable's range (default 0.1)"`
+	Start              map[string]float64  `json:"start,omitempty" description:"Value of every variable to start from (default a random point)"`
+	Tolerance          float64             `json:"tolerance,omitempty" jsonschema:"minimum=0" description:"Smallest fall of the best value that counts as progress (default 1e-6)"`
+	Patience           int                 `json:"patience,omitempty" jsonschema:"minimum=1" description:"Iterations without progress after which the run has converged (default a tenth of the iterations)"`
+	StopAtPlateau      bool                `json:"stop_at_plateau,omitempty" description:"Stop once the run has converged rather than running every iteration"`
+	Seed               int64               `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Trace              bool                `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the value, best value and temperature of each trajectory point as a convergence plot; its ID is returned as trace_id"`
+}
+
+// AnnealingVariable is one bounded dimension of a search space
+type AnnealingVariable struct {
+	Name string  `json:"name" jsonschema:"required" description:"Variable name, usable in the objective"`
+	Min  float64 `json:"min" description:"Lower bound"`
+	Max  float64 `json:"max" description:"Upper bound"`
+}
+
+// AnnealingResponse reports a recorded annealing run: the best point found,
+// where the search ended and its trajectory
+type AnnealingResponse struct {
+	AlgorithmID    string             `json:"algorithm_id"`
+	Status         string             `json:"status"`
+	Summary        string             `json:"summary"`
+	HasResult      bool               `json:"has_result"`
+	BestPoint      map[string]float64 `json:"best_point"`
+	BestValue      float64            `json:"best_value"`
+	FinalPoint     map[string]float64 `json:"final_point"`
+	FinalValue     float64            `json:"final_value"`
+	StartValue     float64            `json:"start_value"`
+	Iterations     int                `json:"iterations"`
+	AcceptanceRate float64            `json:"acceptance_rate"`
+	UphillMoves    int                `json:"uphill_moves"`
+	Trajectory     []AnnealingStep    `json:"trajectory"`
+	Convergence    Convergence        `json:"convergence"`
+	TraceID        string             `json:"trace_id,omitempty"`
+}
+
+// AnnealingStep is the state of an annealing run after an iteration: its
+// temperature, current point and value, the best value so far and the share
+// of moves accepted since the previous step
+type AnnealingStep struct {
+	Iteration      int                `json:"iteration"`
+	Temperature    float64            `json:"temperature"`
+	Point          map[string]float64 `json:"point"`
+	Value          float64            `json:"value"`
+	BestValue      float64            `json:"best_value"`
+	AcceptanceRate float64            `json:"acceptance_rate"`
+}
+
+// MonteCarloRequest simulates an output expression over random variables
+type MonteCarloRequest struct {
+	SessionID   string               `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem     string               `json:"problem" jsonschema:"required" description:"Problem description for the simulation"`
+	Variables   []MonteCarloVariable `json:"variables" jsonschema:"required,minItems=1" description:"Random variables drawn in each trial"`
+	Output      string               `json:"output" jsonschema:"required" description:"Arithmetic expression over the variables to simulate, e.g. revenue - cost * (1 + overrun)"`
+	Trials      int                  `json:"trials,omitempty" jsonschema:"minimum=1,maximum=1000000" description:"Trials to run (default 10000)"`
+	TimeLimit   int                  `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which to stop and summarize the trials so far, not converged (default none)"`
+	Percentiles []float64            `json:"percentiles,omitempty" description:"Percentiles of the output to report, from 0 to 100 (default 5, 25, 50, 75 and 95)"`
+	Buckets     int                  `json:"buckets,omitempty" jsonschema:"minimum=1,maximum=1000" description:"Histogram buckets between the lowest and highest output (default 20)"`
+	Thresholds  []float64            `json:"thresholds,omitempty" description:"Values whose probability of being exceeded by the output to report"`
+	Seed        int64                `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Parallelism int                  `json:"parallelism,omitempty" jsonschema:"minimum=1,maximum=64" description:"Goroutines running trials at once; the result does not depend on it (default the number of CPUs)"`
+	Trace       bool                 `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the residual of each checkpoint as a convergence plot; its ID is returned as trace_id"`
+}
+
+// MonteCarloVariable is a random variable of a simulation and its
+// distribution
+type MonteCarloVariable struct {
+	Name          string    `json:"name" jsonschema:"required" description:"Variable name, usable in the output"`
+	Distribution  string    `json:"distribution" jsonschema:"required,enum=normal|lognormal|triangular|beta|discrete" description:"Distribution of the variable"`
+	Mean          float64   `json:"mean,omitempty" description:"Mean of a normal or lognormal variable"`
+	StdDev        float64   `json:"std_dev,omitempty" jsonschema:"minimum=0" description:"Standard deviation of a normal or lognormal variable"`
+	Min           float64   `json:"min,omitempty" description:"Lowest value of a triangular or beta variable (beta default 0)"`
+	Mode          float64   `json:"mode,omitempty" description:"Most likely value of a triangular variable"`
+	Max           float64   `json:"max,omitempty" description:"Highest value of a triangular or beta variable (beta default 1)"`
+	Alpha         float64   `json:"alpha,omitempty" jsonschema:"minimum=0" description:"First shape of a beta variable"`
+	Beta          float64   `json:"beta,omitempty" jsonschema:"minimum=0" description:"Second shape of a beta variable"`
+	Values        []float64 `json:"values,omitempty" description:"Outcomes of a discrete variable"`
+	Probabilities []float64 `json:"probabilities,omitempty" description:"Relative chances of a discrete variable's outcomes (default equal)"`
+}
+
+// MonteCarloResponse reports a recorded simulation: the output's mean and
+// spread, its percentiles, a histogram and the probability of exceeding each
+// threshold
+type MonteCarloResponse struct {
+	AlgorithmID string                 `json:"algorithm_id"`
+	Status      string                 `json:"status"`
+	Summary     string                 `json:"summary"`
+	HasResult   bool                   `json:"has_result"`
+	Trials      int                    `json:"trials"`
+	Mean        float64                `json:"mean"`
+	StdDev      float64                `json:"std_dev"`
+	StdError    float64                `json:"std_error"`
+	Min         float64                `json:"min"`
+	Max         float64                `json:"max"`
+	Percentiles []MonteCarloPercentile `json:"percentiles"`
+	Histogram   []HistogramBucket      `json:"histogram"`
+	Exceedances []Exceedance           `json:"exceedances"`
+	Convergence Convergence            `json:"convergence"`
+	TraceID     string                 `json:"trace_id,omitempty"`
+}
+
+// MonteCarloPercentile is the value below which a percentage of the outputs
+// fall
+type MonteCarloPercentile struct {
+	Percentile float64 `json:"percentile"`
+	Value      float64 `json:"value"`
+}
+
+// HistogramBucket counts the outputs from Low up to High
+type HistogramBucket struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Count int     `json:"count"`
+}
+
+// Exceedance is the probability of the output exceeding a threshold
+type Exceedance struct {
+	Threshold   float64 `json:"threshold"`
+	Probability float64 `json:"probability"`
+}
+
+// ParticleFilterRequest tracks latent state variables through a sequence of
+// observations with a bootstrap particle filter
+type ParticleFilterRequest struct {
+	SessionID         string             `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem           string             `json:"problem" jsonschema:"required" description:"Problem description for the filter"`
+	Variables         []ParticleVariable `json:"variables" jsonschema:"required,minItems=1" description:"Latent state variables and how they move between steps"`
+	Observation       string             `json:"observation" jsonschema:"required" description:"Arithmetic expression over the state variables and the step t giving the expected observation, e.g. level + trend"`
+	ObservationNoise  float64            `json:"observation_noise" jsonschema:"required,minimum=0" description:"Standard deviation of the observations around the expected observation"`
+	Observations      []float64          `json:"observations" jsonschema:"required,minItems=1" description:"Observation sequence, one per step"`
+	Particles         int                `json:"particles,omitempty" jsonschema:"minimum=2,maximum=100000" description:"Particles to track (default 1000)"`
+	ResampleThreshold float64            `json:"resample_threshold,omitempty" jsonschema:"minimum=0,maximum=1" description:"Share of the particles below which the effective sample size triggers resampling (default 0.5)"`
+	Band              float64            `json:"band,omitempty" jsonschema:"minimum=0,maximum=1" description:"Central probability of each estimate's uncertainty band (default 0.9)"`
+	Seed              int64              `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Trace             bool               `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: 50 particles of each step as particle clouds; its ID is returned as trace_id"`
+}
+
+// ParticleVariable is a latent state variable: its initial belief and its
+// dynamics
+type ParticleVariable struct {
+	Name          string  `json:"name" jsonschema:"required" description:"Variable name, usable in the dynamics and observation"`
+	InitialMean   float64 `json:"initial_mean,omitempty" description:"Mean of the Gaussian belief before the first step"`
+	InitialStdDev float64 `json:"initial_std_dev,omitempty" jsonschema:"minimum=0" description:"Standard deviation of the belief before the first step"`
+	Dynamics      string  `json:"dynamics,omitempty" description:"Arithmetic expression over the previous state and the step t giving the next value (default the previous value)"`
+	ProcessNoise  float64 `json:"process_noise,omitempty" jsonschema:"minimum=0" description:"Standard deviation of the Gaussian noise added to each step's value"`
+}
+
+// ParticleFilterResponse reports a recorded filter run: the filtered
+// estimate of each variable at each step
+type ParticleFilterResponse struct {
+	AlgorithmID   string                   `json:"algorithm_id"`
+	Status        string                   `json:"status"`
+	Summary       string                   `json:"summary"`
+	HasResult     bool                     `json:"has_result"`
+	Steps         []FilterStep             `json:"steps"`
+	Final         map[string]StateEstimate `json:"final"`
+	LogLikelihood float64                  `json:"log_likelihood"`
+	Resamples     int                      `json:"resamples"`
+	TraceID       string                   `json:"trace_id,omitempty"`
+}
+
+// FilterStep is the filter's belief after an observation: the observation
+// predicted before seeing it and the estimate of each variable
+type FilterStep struct {
+	Step                int                      `json:"step"`
+	Observation         float64                  `json:"observation"`
+	Predicted           float64                  `json:"predicted"`
+	Estimates           map[string]StateEstimate `json:"estimates"`
+	EffectiveSampleSize float64                  `json:"effective_sample_size"`
+	Resampled           bool                     `json:"resampled"`
+}
+
+// StateEstimate is the filtered mean and spread of a variable, with the
+// bounds of its uncertainty band
+type StateEstimate struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Lower  float64 `json:"lower"`
+	Upper  float64 `json:"upper"`
+}
+
+// BootstrapRequest quantifies the uncertainty of a statistic of a dataset by
+// bootstrap resampling
+type BootstrapRequest struct {
+	SessionID   string    `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem     string    `json:"problem" jsonschema:"required" description:"Problem description for the analysis"`
+	Data        []float64 `json:"data" jsonschema:"required,minItems=2" description:"Numeric sample to resample"`
+	Statistic   string    `json:"statistic,omitempty" jsonschema:"enum=mean|median|mean_difference" description:"Statistic of the data to analyze (default mean); mean_difference is the mean of data less that of comparison"`
+	Comparison  []float64 `json:"comparison,omitempty" description:"Second sample, required by mean_difference"`
+	Resamples   int       `json:"resamples,omitempty" jsonschema:"minimum=2,maximum=100000" description:"Bootstrap resamples to draw (default 2000)"`
+	Confidence  float64   `json:"confidence,omitempty" jsonschema:"minimum=0,maximum=1" description:"Coverage of the confidence intervals (default 0.95)"`
+	Seed        int64     `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Parallelism int       `json:"parallelism,omitempty" jsonschema:"minimum=1,maximum=64" description:"Goroutines drawing resamples at once; the result does not depend on it (default the number of CPUs)"`
+}
+
+// BootstrapResponse reports a recorded bootstrap analysis: the statistic of
+// the data, its standard error and bias over the resamples and its
+// confidence intervals
+type BootstrapResponse struct {
+	AlgorithmID        string             `json:"algorithm_id"`
+	Status             string             `json:"status"`
+	Summary            string             `json:"summary"`
+	HasResult          bool               `json:"has_result"`
+	Statistic          string             `json:"statistic"`
+	Estimate           float64            `json:"estimate"`
+	StdError           float64            `json:"std_error"`
+	Bias               float64            `json:"bias"`
+	Confidence         float64            `json:"confidence"`
+	PercentileInterval ConfidenceInterval `json:"percentile_interval"`
+	BasicInterval      ConfidenceInterval `json:"basic_interval"`
+	NormalInterval     ConfidenceInterval `json:"normal_interval"`
+	Resamples          int                `json:"resamples"`
+}
+
+// ConfidenceInterval bounds a statistic at the analysis's confidence
+type ConfidenceInterval struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// QueueingRequest simulates an M/M/1 or M/M/c queue to reason about the
+// capacity and throughput of a service
+type QueueingRequest struct {
+	SessionID   string    `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem     string    `json:"problem" jsonschema:"required" description:"Problem description for the simulation"`
+	Model       string    `json:"model,omitempty" jsonschema:"enum=mm1|mmc" description:"Queue to simulate: mm1 for a single server, mmc for servers sharing one line (default mm1)"`
+	Servers     int       `json:"servers,omitempty" jsonschema:"minimum=1,maximum=1000" description:"Servers of an mmc queue (default 1)"`
+	ArrivalRate float64   `json:"arrival_rate" jsonschema:"required,minimum=0" description:"Mean customers arriving per unit of time, in a Poisson stream"`
+	ServiceRate float64   `json:"service_rate" jsonschema:"required,minimum=0" description:"Mean customers a busy server serves per unit of time, in exponentially distributed times"`
+	Customers   int       `json:"customers,omitempty" jsonschema:"minimum=1,maximum=1000000" description:"Customers to measure (default 10000)"`
+	WarmUp      int       `json:"warm_up,omitempty" jsonschema:"minimum=0,maximum=1000000" description:"Customers served before measuring, to bring the queue from empty to its usual state (default a tenth of customers)"`
+	Percentiles []float64 `json:"percentiles,omitempty" description:"Percentiles of the wait in line to report, from 0 to 100 (default 50, 90, 95 and 99)"`
+	TimeLimit   int       `json:"time_limit,omitempty" jsonschema:"minimum=0" description:"Seconds after which to stop and summarize the customers so far, not converged (default none)"`
+	Seed        int64     `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Trace       bool      `json:"trace,omitempty" description:"Record a trace of the run as visual data that the visual tools render: the residual of each checkpoint as a convergence plot; its ID is returned as trace_id"`
+}
+
+// QueueingResponse reports a recorded queueing simulation: the servers'
+// utilization, the waits and the distributions of the line and the
+// customers in the system, beside the steady state of a stable queue
+type QueueingResponse struct {
+	AlgorithmID     string                `json:"algorithm_id"`
+	Status          string                `json:"status"`
+	Summary         string                `json:"summary"`
+	HasResult       bool                  `json:"has_result"`
+	Model           string                `json:"model"`
+	Servers         int                   `json:"servers"`
+	Stable          bool                  `json:"stable"`
+	Customers       int                   `json:"customers"`
+	Duration        float64               `json:"duration"`
+	Utilization     float64               `json:"utilization"`
+	MeanWait        float64               `json:"mean_wait"`
+	MeanSojourn     float64               `json:"mean_sojourn"`
+	WaitProbability float64               `json:"wait_probability"`
+	WaitPercentiles []QueueWaitPercentile `json:"wait_percentiles"`
+	MeanQueueLength float64               `json:"mean_queue_length"`
+	MeanInSystem    float64               `json:"mean_in_system"`
+	MaxQueueLength  int                   `json:"max_queue_length"`
+	QueueLengths    []QueueLengthShare    `json:"queue_lengths"`
+	InSystem        []QueueLengthShare    `json:"in_system"`
+	Theory          *QueueingTheory       `json:"theory,omitempty"`
+	Convergence     Convergence           `json:"convergence"`
+	TraceID         string                `json:"trace_id,omitempty"`
+}
+
+// QueueWaitPercentile is the wait below which a percentage of the customers
+// waited, with the steady-state wait of a stable queue
+type QueueWaitPercentile struct {
+	Percentile float64 `json:"percentile"`
+	Value      float64 `json:"value"`
+	Theory     float64 `json:"theory,omitempty"`
+}
+
+// QueueLengthShare is the share of the time that Length customers were
+// waiting, or in the system, with the steady-state probability of a stable
+// queue. Shares run up to the longest length seen; a length of 100 counts
+// 100 customers or more.
+type QueueLengthShare struct {
+	Length      int     `json:"length"`
+	Probability float64 `json:"probability"`
+	Theory      float64 `json:"theory,omitempty"`
+}
+
+// QueueingTheory is the steady state of a stable queue by Erlang's C
+// formula
+type QueueingTheory struct {
+	Utilization     float64 `json:"utilization"`
+	WaitProbability float64 `json:"wait_probability"`
+	MeanWait        float64 `json:"mean_wait"`
+	MeanSojourn     float64 `json:"mean_sojourn"`
+	MeanQueueLength float64 `json:"mean_queue_length"`
+	MeanInSystem    float64 `json:"mean_in_system"`
+}
+
+// ABTestRequest compares the conversion rates of the variants of an A/B test
+type ABTestRequest struct {
+	SessionID   string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem     string          `json:"problem" jsonschema:"required" description:"Problem description for the analysis"`
+	Variants    []ABTestVariant `json:"variants" jsonschema:"required,minItems=2" description:"Variants of the test with their visitors and conversions"`
+	Control     string          `json:"control,omitempty" description:"Name of the variant the others are compared with (default the first)"`
+	Confidence  float64         `json:"confidence,omitempty" jsonschema:"minimum=0,maximum=1" description:"Coverage of the intervals; one less it is the significance level of the tests, shared among the variants compared with the control (default 0.95)"`
+	PriorAlpha  float64         `json:"prior_alpha,omitempty" jsonschema:"minimum=0" description:"First shape of the Beta prior of every conversion rate (default 1, with prior_beta a uniform prior)"`
+	PriorBeta   float64         `json:"prior_beta,omitempty" jsonschema:"minimum=0" description:"Second shape of the Beta prior of every conversion rate (default 1)"`
+	Samples     int             `json:"samples,omitempty" jsonschema:"minimum=1,maximum=1000000" description:"Draws from the posteriors (default 100000)"`
+	Seed        int64           `json:"seed,omitempty" description:"Seed of the run's randomness, for reproducible runs (default random)"`
+	Parallelism int             `json:"parallelism,omitempty" jsonschema:"minimum=1,maximum=64" description:"Goroutines drawing samples at once; the result does not depend on it (default the number of CPUs)"`
+}
+
+// ABTestVariant is a variant of a test: the visitors it was shown to and how
+// many of them converted
+type ABTestVariant struct {
+	Name        string `json:"name" jsonschema:"required" description:"Variant name"`
+	Visitors    int    `json:"visitors" jsonschema:"required,minimum=1" description:"Visitors shown the variant"`
+	Conversions int    `json:"conversions" jsonschema:"minimum=0" description:"Visitors who converted"`
+}
+
+// ABTestResponse reports a recorded A/B test analysis: each variant's rate,
+// its frequentist and Bayesian comparison with the control, and the variant
+// with the least expected loss
+type ABTestResponse struct {
+	AlgorithmID     string                `json:"algorithm_id"`
+	Status          string                `json:"status"`
+	Summary         string                `json:"summary"`
+	HasResult       bool                  `json:"has_result"`
+	Control         string                `json:"control"`
+	Best            string                `json:"best"`
+	Variants        []ABTestVariantResult `json:"variants"`
+	Samples         int                   `json:"samples"`
+	MonteCarloError float64               `json:"monte_carlo_error"`
+	Confidence      *Confidence           `json:"confidence,omitempty"`
+}
+
+// ABTestVariantResult is the analysis of a variant: its observed rate with
+// its Wilson interval, its posterior with its credible interval, the chance
+// that it is best and the rate expected to be lost by choosing it
+type ABTestVariantResult struct {
+	Name             string             `json:"name"`
+	Visitors         int                `json:"visitors"`
+	Conversions      int                `json:"conversions"`
+	Rate             float64            `json:"rate"`
+	RateInterval     ConfidenceInterval `json:"rate_interval"`
+	PosteriorMean    float64            `json:"posterior_mean"`
+	CredibleInterval ConfidenceInterval `json:"credible_interval"`
+	ProbabilityBest  float64            `json:"probability_best"`
+	ExpectedLoss     float64            `json:"expected_loss"`
+	VsControl        *ABTestComparison  `json:"vs_control,omitempty"`
+}
+
+// ABTestComparison is how a variant fares against the control: the
+// difference of their rates, its z-test and the posterior chance that the
+// variant beats the control
+type ABTestComparison struct {
+	Difference         float64            `json:"difference"`
+	RelativeLift       float64            `json:"relative_lift"`
+	DifferenceInterval ConfidenceInterval `json:"difference_interval"`
+	ZScore             float64            `json:"z_score"`
+	PValue             float64            `json:"p_value"`
+	Significant        bool               `json:"significant"`
+	ProbabilityToBeat  float64            `json:"probability_to_beat"`
+}
+
+// StochasticResultRequest retrieves a stochastic algorithm run recorded in a
+// session with its full data
+type StochasticResultRequest struct {
+	SessionID   string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	AlgorithmID string `json:"algorithm_id" jsonschema:"required" description:"ID of the recorded run, as its response returned it"`
+}
+
+// StochasticResultResponse reports a recorded run as the typed data of its
+// algorithm, named by Type after its route (mdp, mcts, bandit, ...), or run
+// for runs of algorithms with no typed data
+type StochasticResultResponse struct {
+	AlgorithmID string `json:"algorithm_id"`
+	Algorithm   string `json:"algorithm"`
+	Type        string `json:"type"`
+	// Complete reports whether the run kept its full data; runs recorded
+	// before runs kept it, and those of the tools that only record a
+	// problem, hold only the fields every run has
+	Complete bool        `json:"complete"`
+	Result   interface{} `json:"result"`
+}
+
+// CompareRunsRequest compares stochastic algorithm runs recorded in a
+// session
+type CompareRunsRequest struct {
+	SessionID    string   `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	AlgorithmIDs []string `json:"algorithm_ids" jsonschema:"required,minItems=2" description:"IDs of the recorded runs to compare"`
+	Goal         string   `json:"goal,omitempty" jsonschema:"enum=maximize|minimize" description:"Whether a higher or a lower value is better (default maximize)"`
+	Title        string   `json:"title,omitempty" description:"Title of the comparison table (default Stochastic run comparison)"`
+}
+
+// CompareRunsResponse reports a recorded comparison: a row for each run, the
+// runs that stand out and the comparison rendered as a Markdown table
+type CompareRunsResponse struct {
+	VisualID      string          `json:"visual_id"`
+	Status        string          `json:"status"`
+	Summary       string          `json:"summary"`
+	Runs          []RunComparison `json:"runs"`
+	BestValue     string          `json:"best_value,omitempty"`
+	Fastest       string          `json:"fastest,omitempty"`
+	MostConfident string          `json:"most_confident,omitempty"`
+	Table         string          `json:"table"`
+}
+
+// RunComparison is a compared run: its headline value, how it stopped and
+// the confidence recorded for it
+type RunComparison struct {
+	AlgorithmID    string   `json:"algorithm_id"`
+	Algorithm      string   `json:"algorithm"`
+	Problem        string   `json:"problem"`
+	Value          *float64 `json:"value,omitempty"`
+	Iterations     int      `json:"iterations"`
+	Converged      bool     `json:"converged"`
+	StoppingReason string   `json:"stopping_reason,omitempty"`
+	Confidence     float64  `json:"confidence,omitempty"`
+	// ConfidenceMethod is how the run computed its confidence, so runs
+	// whose confidences mean different things can be told apart
+	ConfidenceMethod string `json:"confidence_method,omitempty"`
+}
+
+// ParameterSweepRequest runs a stochastic algorithm across a grid or random
+// sample of its hyperparameters
+type ParameterSweepRequest struct {
+	SessionID   string                 `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem     string                 `json:"problem" jsonschema:"required" description:"Problem description for the sweep"`
+	Algorithm   string                 `json:"algorithm" jsonschema:"required" description:"Algorithm to run, named as in its /api/v1/stochastic route, such as mdp, mcts, bandit or annealing; list_algorithms lists them"`
+	Request     map[string]interface{} `json:"request" jsonschema:"required" description:"Request of the algorithm, as its own tool takes it, whose fields each configuration overrides"`
+	Parameters  []SweepParameter       `json:"parameters" jsonschema:"required,minItems=1" description:"Request fields to sweep"`
+	Mode        string                 `json:"mode,omitempty" jsonschema:"enum=grid|random" description:"Try every combination of the parameters' values (grid, the default) or random samples of them"`
+	Samples     int                    `json:"samples,omitempty" jsonschema:"minimum=1,maximum=1000" description:"Configurations to sample in random mode (default 20)"`
+	Metric      string                 `json:"metric,omitempty" description:"Numeric response field to rank configurations by, with dots for nested fields such as convergence.iterations (default the run's recorded value)"`
+	Goal        string                 `json:"goal,omitempty" jsonschema:"enum=maximize|minimize" description:"Whether a higher or a lower metric is better (default minimize for annealing, maximize otherwise)"`
+	Parallelism int                    `json:"parallelism,omitempty" jsonschema:"minimum=1,maximum=64" description:"Configurations to run at once (default the number of CPUs)"`
+	Seed        int64                  `json:"seed,omitempty" description:"Seed of random sampling, also given to every run whose request sets none (default random)"`
+}
+
+// SweepParameter is a request field a sweep varies: over its values, or
+// between Min and Max
+type SweepParameter struct {
+	Name    string        `json:"name" jsonschema:"required" description:"Request field, such as gamma, epsilon or exploration_constant"`
+	Values  []interface{} `json:"values,omitempty" description:"Values to try, numbers or strings such as strategies"`
+	Min     float64       `json:"min,omitempty" description:"Lowest value, without values"`
+	Max     float64       `json:"max,omitempty" description:"Highest value, without values"`
+	Steps   int           `json:"steps,omitempty" jsonschema:"minimum=1,maximum=100" description:"Evenly spaced grid values from min to max (default 5)"`
+	Integer bool          `json:"integer,omitempty" description:"Round values to integers"`
+	Log     bool          `json:"log,omitempty" description:"Space or sample values on a log scale, for a positive min"`
+}
+
+// ParameterSweepResponse reports a recorded sweep: the result of every
+// configuration, a row of the results matrix each, and the best of them
+type ParameterSweepResponse struct {
+	AlgorithmID    string        `json:"algorithm_id"`
+	Status         string        `json:"status"`
+	Summary        string        `json:"summary"`
+	HasResult      bool          `json:"has_result"`
+	Algorithm      string        `json:"algorithm"`
+	Metric         string        `json:"metric"`
+	Goal           string        `json:"goal"`
+	Configurations int           `json:"configurations"`
+	Best           *SweepResult  `json:"best,omitempty"`
+	Results        []SweepResult `json:"results"`
+}
+
+// SweepResult is the outcome of a configuration: its metric, how its run
+// stopped, or why it failed
+type SweepResult struct {
+	Configuration map[string]interface{} `json:"configuration"`
+	Value         *float64               `json:"value,omitempty"`
+	Converged     bool                   `json:"converged"`
+	Iterations    int                    `json:"iterations"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// SensitivityRequest re-runs a recorded stochastic algorithm run with each of
+// its parameters perturbed in turn
+type SensitivityRequest struct {
+	SessionID    string                 `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	AlgorithmID  string                 `json:"algorithm_id" jsonschema:"required" description:"ID of the recorded run to analyze"`
+	Parameters   []SensitivityParameter `json:"parameters" jsonschema:"required,minItems=1" description:"Request fields of the run to perturb, one at a time"`
+	Perturbation float64                `json:"perturbation,omitempty" jsonschema:"minimum=0" description:"Relative change of numeric parameters down and up, such as 0.1 for 10% (default 0.1)"`
+	Metric       string                 `json:"metric,omitempty" description:"Numeric response field to measure, with dots for nested fields such as convergence.iterations (default the run's recorded value)"`
+	Parallelism  int                    `json:"parallelism,omitempty" jsonschema:"minimum=1,maximum=64" description:"Runs to run at once (default the number of CPUs)"`
+}
+
+// SensitivityParameter is a request field a sensitivity analysis perturbs:
+// to its values, or down and up by the perturbation
+type SensitivityParameter struct {
+	Name         string        `json:"name" jsonschema:"required" description:"Request field, such as gamma, epsilon or exploration_constant"`
+	Values       []interface{} `json:"values,omitempty" description:"Values to try instead of perturbing the run's value, numbers or strings such as strategies"`
+	Perturbation float64       `json:"perturbation,omitempty" jsonschema:"minimum=0" description:"Relative change of this parameter (default the analysis's)"`
+}
+
+// SensitivityResponse reports a recorded sensitivity analysis: the run's
+// value re-run as recorded, and how far each parameter moves it, largest
+// swing first as in a tornado chart
+type SensitivityResponse struct {
+	AlgorithmID   string                 `json:"algorithm_id"`
+	Status        string                 `json:"status"`
+	Summary       string                 `json:"summary"`
+	HasResult     bool                   `json:"has_result"`
+	Run           string                 `json:"run"`
+	Algorithm     string                 `json:"algorithm"`
+	Metric        string                 `json:"metric"`
+	Base          SweepResult            `json:"base"`
+	Sensitivities []ParameterSensitivity `json:"sensitivities"`
+	Chart         string                 `json:"chart"`
+}
+
+// ParameterSensitivity is how a perturbed parameter moved a run: the result
+// of each value tried, and the lowest and highest values reached, counting
+// the base run's, with the swing between them
+type ParameterSensitivity struct {
+	Name    string        `json:"name"`
+	Base    interface{}   `json:"base"`
+	Results []SweepResult `json:"results"`
+	Low     *float64      `json:"low,omitempty"`
+	High    *float64      `json:"high,omitempty"`
+	Swing   float64       `json:"swing"`
+}
+
+// DominanceRequest compares two distributions of outcomes, each simulated by
+// a recorded Monte Carlo run or given as samples, by stochastic dominance and
+// by their tails
+type DominanceRequest struct {
+	SessionID  string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Problem    string          `json:"problem" jsonschema:"required" description:"Problem description for the comparison"`
+	A          DominanceSource `json:"a" jsonschema:"required" description:"First distribution"`
+	B          DominanceSource `json:"b" jsonschema:"required" description:"Second distribution"`
+	Alpha      float64         `json:"alpha,omitempty" jsonschema:"minimum=0,maximum=1" description:"Share of the worst outcomes the value at risk and the CVaR cover (default 0.05)"`
+	Goal       string          `json:"goal,omitempty" jsonschema:"enum=maximize|minimize" description:"Whether higher outcomes, such as profits, or lower ones, such as costs, are better (default maximize)"`
+	DecisionID string          `json:"decision_id,omitempty" description:"ID of a decision recorded in the session with options named like the distributions; each receives its distribution's mean as expected value and a risk level from the CVaRs, and the decision the comparison as recommendation"`
+}
+
+// DominanceSource is a compared distribution: the trial outputs of a recorded
+// Monte Carlo run, or samples of it
+type DominanceSource struct {
+	Name        string    `json:"name,omitempty" description:"Name of the distribution, such as the decision option it is the outcome of (default the run's ID, or a or b)"`
+	AlgorithmID string    `json:"algorithm_id,omitempty" description:"ID of a recorded monte_carlo_simulation run, whose trials are run again with their recorded seed"`
+	Samples     []float64 `json:"samples,omitempty" description:"Outcomes drawn from the distribution, at most 1000000, without algorithm_id"`
+}
+
+// DominanceResponse reports a recorded comparison: each distribution's
+// summary and tail, which of them dominates at first and second order, and
+// how close the one with the better mean comes to dominating
+type DominanceResponse struct {
+	AlgorithmID        string           `json:"algorithm_id"`
+	Status             string           `json:"status"`
+	Summary            string           `json:"summary"`
+	HasResult          bool             `json:"has_result"`
+	Goal               string           `json:"goal"`
+	Alpha              float64          `json:"alpha"`
+	A                  DominanceSummary `json:"a"`
+	B                  DominanceSummary `json:"b"`
+	FirstOrder         string           `json:"first_order,omitempty"`
+	SecondOrder        string           `json:"second_order,omitempty"`
+	Leader             string           `json:"leader,omitempty"`
+	Epsilon            float64          `json:"epsilon"`
+	KolmogorovSmirnov  float64          `json:"kolmogorov_smirnov"`
+	ProbabilityABetter float64          `json:"probability_a_better"`
+	DecisionID         string           `json:"decision_id,omitempty"`
+	Recommendation     string           `json:"recommendation"`
+}
+
+// DominanceSummary describes a compared distribution: its mean, spread and
+// range, the value at risk the worst alpha of its outcomes are no better
+// than, and their mean, the CVaR
+type DominanceSummary struct {
+	Name        string  `json:"name"`
+	AlgorithmID string  `json:"algorithm_id,omitempty"`
+	Samples     int     `json:"samples"`
+	Mean        float64 `json:"mean"`
+	StdDev      float64 `json:"std_dev"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	VaR         float64 `json:"var"`
+	CVaR        float64 `json:"cvar"`
+}
+
+// AlgorithmInfo describes a registered stochastic algorithm
+type AlgorithmInfo struct {
+	Name        string         `json:"name"`
+	Tool        string         `json:"tool"`
+	Route       string         `json:"route"`
+	Description string         `json:"description"`
+	Streams     bool           `json:"streams"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+// ListAlgorithmsResponse lists the registered stochastic algorithms with the
+// JSON schemas of their requests
+type ListAlgorithmsResponse struct {
+	Status     string          `json:"status"`
+	Count      int             `json:"count"`
+	Algorithms []AlgorithmInfo `json:"algorithms"`
+}
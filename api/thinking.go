@@ -0,0 +1,145 @@
+package api
+
+// SequentialThinkingRequest is one step of a sequential thinking session
+type SequentialThinkingRequest struct {
+	SessionID         string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	Thought           string `json:"thought" jsonschema:"required" description:"Current thought content"`
+	ThoughtNumber     int    `json:"thought_number" jsonschema:"required,minimum=1" description:"Current thought number in sequence"`
+	TotalThoughts     int    `json:"total_thoughts" jsonschema:"required,minimum=1" description:"Total number of thoughts planned"`
+	NextThoughtNeeded bool   `json:"next_thought_needed" jsonschema:"required" description:"Whether another thought is needed"`
+	IsRevision        bool   `json:"is_revision,omitempty" description:"Whether the thought revises an earlier one"`
+	RevisesThought    *int   `json:"revises_thought,omitempty" jsonschema:"minimum=1" description:"Number of the thought being revised"`
+	BranchFromThought *int   `json:"branch_from_thought,omitempty" jsonschema:"minimum=1" description:"Number of the thought this one branches from"`
+	BranchID          string `json:"branch_id,omitempty" description:"Identifier of the branch"`
+	NeedsMoreThoughts bool   `json:"needs_more_thoughts,omitempty" description:"Whether more thoughts than planned are needed"`
+}
+
+// SequentialThinkingResponse reports a recorded thought
+type SequentialThinkingResponse struct {
+	ThoughtID      string                `json:"thought_id"`
+	Status         string                `json:"status"`
+	SessionContext ThoughtSessionContext `json:"session_context"`
+}
+
+// ThoughtSessionContext is the progress of the session a thought was added to
+type ThoughtSessionContext struct {
+	SessionID         string `json:"session_id"`
+	TotalThoughts     int    `json:"total_thoughts"`
+	RemainingThoughts int    `json:"remaining_thoughts"`
+}
+
+// UpdateThoughtRequest revises a recorded thought
+type UpdateThoughtRequest struct {
+	SessionID         string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	ThoughtID         string `json:"thought_id" jsonschema:"required" description:"ID of the thought to revise"`
+	Thought           string `json:"thought" jsonschema:"required" description:"Revised thought content"`
+	NextThoughtNeeded *bool  `json:"next_thought_needed,omitempty" description:"Whether another thought is needed"`
+}
+
+// UpdateThoughtResponse reports a revised thought
+type UpdateThoughtResponse struct {
+	Status         string         `json:"status"`
+	ThoughtID      string         `json:"thought_id"`
+	SessionContext SessionContext `json:"session_context"`
+}
+
+// MentalModelRequest applies a mental model to a problem
+type MentalModelRequest struct {
+	SessionID  string   `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	ModelName  string   `json:"model_name" jsonschema:"required" description:"Name of the mental model to apply"`
+	Problem    string   `json:"problem" jsonschema:"required" description:"Problem statement to analyze"`
+	Steps      []string `json:"steps" description:"Steps to follow for the mental model"`
+	Reasoning  string   `json:"reasoning" description:"Reasoning followed while applying the model"`
+	Conclusion string   `json:"conclusion" description:"Conclusion reached by applying the model"`
+	Confidence float64  `json:"confidence,omitempty" jsonschema:"minimum=0,maximum=1" description:"Confidence in the conclusion (0-1)"`
+}
+
+// MentalModelResponse reports a recorded mental model application
+type MentalModelResponse struct {
+	ModelID        string                    `json:"model_id"`
+	Status         string                    `json:"status"`
+	ModelInfo      *MentalModelInfo          `json:"model_info,omitempty"`
+	StepsUsed      []string                  `json:"steps_used,omitempty"`
+	HasSteps       bool                      `json:"has_steps"`
+	HasConclusion  bool                      `json:"has_conclusion"`
+	SessionContext MentalModelSessionContext `json:"session_context"`
+}
+
+// MentalModelInfo describes the mental model of the catalog that was applied
+type MentalModelInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Priority    int    `json:"priority"`
+}
+
+// MentalModelSessionContext counts the mental models of a session
+type MentalModelSessionContext struct {
+	SessionID         string `json:"session_id"`
+	TotalMentalModels int    `json:"total_mental_models"`
+}
+
+// MentalModelConclusionRequest attaches a conclusion to an applied mental
+// model. Reasoning and Confidence are kept when not given.
+type MentalModelConclusionRequest struct {
+	SessionID  string   `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	ModelID    string   `json:"model_id" jsonschema:"required" description:"ID returned by mental_model"`
+	Conclusion string   `json:"conclusion" jsonschema:"required" description:"Conclusion reached by applying the model"`
+	Reasoning  *string  `json:"reasoning,omitempty" description:"Reasoning that led to the conclusion"`
+	Confidence *float64 `json:"confidence,omitempty" jsonschema:"minimum=0,maximum=1" description:"Confidence in the conclusion (0-1)"`
+}
+
+// MentalModelConclusionResponse reports an updated mental model
+type MentalModelConclusionResponse struct {
+	Status         string         `json:"status"`
+	ModelID        string         `json:"model_id"`
+	ModelName      string         `json:"model_name"`
+	HasReasoning   bool           `json:"has_reasoning"`
+	HasConclusion  bool           `json:"has_conclusion"`
+	Confidence     float64        `json:"confidence"`
+	SessionContext SessionContext `json:"session_context"`
+}
+
+// DebuggingApproachRequest records a debugging approach applied to an issue
+type DebuggingApproachRequest struct {
+	SessionID    string   `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	ApproachName string   `json:"approach_name" jsonschema:"required" description:"Name of the debugging approach"`
+	Issue        string   `json:"issue" jsonschema:"required" description:"Issue description to debug"`
+	Steps        []string `json:"steps" description:"Debugging steps to follow"`
+	Findings     string   `json:"findings" description:"What the investigation found"`
+	Resolution   string   `json:"resolution" description:"How the issue was resolved"`
+}
+
+// DebuggingApproachResponse reports a recorded debugging approach
+type DebuggingApproachResponse struct {
+	ApproachID     string         `json:"approach_id"`
+	Status         string         `json:"status"`
+	HasSteps       bool           `json:"has_steps"`
+	HasFindings    bool           `json:"has_findings"`
+	HasResolution  bool           `json:"has_resolution"`
+	SessionContext SessionContext `json:"session_context"`
+}
+
+// DebuggingFindingsRequest records the findings or resolution of a debugging
+// approach; at least one of them must be given
+type DebuggingFindingsRequest struct {
+	SessionID  string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	ApproachID string `json:"approach_id" jsonschema:"required" description:"ID returned by debugging_approach"`
+	Findings   string `json:"findings,omitempty" description:"What the investigation found"`
+	Resolution string `json:"resolution,omitempty" description:"How the issue was resolved"`
+}
+
+// DebuggingFindingsResponse reports an updated debugging approach
+type DebuggingFindingsResponse struct {
+	Status         string         `json:"status"`
+	ApproachID     string         `json:"approach_id"`
+	ApproachName   string         `json:"approach_name"`
+	HasFindings    bool           `json:"has_findings"`
+	HasResolution  bool           `json:"has_resolution"`
+	SessionContext SessionContext `json:"session_context"`
+}
+
+// SessionContext names the session a record belongs to
+type SessionContext struct {
+	SessionID string `json:"session_id"`
+}
@@ -0,0 +1,185 @@
+package api
+
+// ConceptMapRequest performs an operation on a concept map
+type ConceptMapRequest struct {
+	SessionID           string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DiagramID           string          `json:"diagram_id" description:"Unique identifier for the diagram (default default-diagram); operations apply to its current state"`
+	DiagramType         string          `json:"diagram_type,omitempty" description:"Type of diagram (conceptMap, mindMap, etc.)"`
+	Operation           string          `json:"operation" jsonschema:"required,enum=create|update|delete|transform" description:"Operation to perform: create replaces the diagram's elements, update adds elements or replaces those of the same ID, delete removes the elements named or, naming none, the whole diagram, and transform applies the transformation_type"`
+	Elements            []VisualElement `json:"elements,omitempty" description:"Visual elements (nodes, edges, etc.)"`
+	TransformationType  string          `json:"transformation_type,omitempty" jsonschema:"enum=merge|collapse|extract|relabel" description:"Transformation a transform operation applies: merge node_ids into the first, collapse them into a cluster node, extract the subgraph around them into a new diagram, or relabel elements by pattern"`
+	NodeIDs             []string        `json:"node_ids,omitempty" description:"Nodes to merge, the first surviving, to collapse, or to extract the neighborhood of; for relabel, the only elements to relabel"`
+	TargetID            string          `json:"target_id,omitempty" description:"ID of the cluster node a collapse leaves (default cluster: and the first node's ID), or of the diagram an extract creates (default the diagram's ID, a colon and the first node's ID)"`
+	Label               string          `json:"label,omitempty" description:"Label of the merged or cluster node"`
+	Depth               int             `json:"depth,omitempty" jsonschema:"minimum=0" description:"Number of edges an extract follows out from its nodes, in either direction (default 1)"`
+	Pattern             string          `json:"pattern,omitempty" description:"Regular expression a relabel replaces in labels"`
+	Replacement         string          `json:"replacement,omitempty" description:"Replacement for the pattern, which may refer to its groups as $1 and so on"`
+	Iteration           int             `json:"iteration,omitempty" jsonschema:"minimum=0" description:"Iteration of the diagram"`
+	Observation         string          `json:"observation,omitempty" description:"What the diagram shows"`
+	Insight             string          `json:"insight,omitempty" description:"Insight drawn from the diagram"`
+	Hypothesis          string          `json:"hypothesis,omitempty" description:"Hypothesis the diagram suggests"`
+	NextOperationNeeded bool            `json:"next_operation_needed,omitempty" description:"Whether another operation is needed"`
+}
+
+// VisualElement is a node or edge of a diagram
+type VisualElement struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Label       string                 `json:"label,omitempty"`
+	Properties  map[string]interface{} `json:"properties"`
+	Source      string                 `json:"source,omitempty"`
+	Target      string                 `json:"target,omitempty"`
+	Contains    []string               `json:"contains,omitempty"`
+	Probability float64                `json:"probability,omitempty" jsonschema:"minimum=0,maximum=1"`
+}
+
+// ConceptMapResponse reports a recorded concept map operation with the
+// diagram it left and what it changed
+type ConceptMapResponse struct {
+	VisualID           string          `json:"visual_id"`
+	Status             string          `json:"status"`
+	DiagramID          string          `json:"diagram_id"`
+	DiagramType        string          `json:"diagram_type"`
+	Operation          string          `json:"operation"`
+	TransformationType string          `json:"transformation_type,omitempty"`
+	Elements           int             `json:"elements"`
+	Diagram            []VisualElement `json:"diagram"`
+	Changes            DiagramChanges  `json:"changes"`
+	Summary            string          `json:"summary"`
+}
+
+// DiagramChanges lists by ID the elements an operation added, changed and
+// removed
+type DiagramChanges struct {
+	Added   []string `json:"added"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+}
+
+// FlowchartRequest records a flowchart of typed nodes and the connections
+// between them
+type FlowchartRequest struct {
+	SessionID   string                `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DiagramID   string                `json:"diagram_id,omitempty" description:"Unique identifier for the diagram (default flowchart)"`
+	Title       string                `json:"title,omitempty" description:"What the flowchart shows"`
+	Nodes       []FlowchartNode       `json:"nodes" jsonschema:"required,minItems=2" description:"Steps of the flowchart, at least one start and one end"`
+	Connections []FlowchartConnection `json:"connections" jsonschema:"required,minItems=1" description:"Connections between the steps; each branch of a decision is labeled"`
+}
+
+// FlowchartNode is a typed step of a flowchart
+type FlowchartNode struct {
+	ID    string `json:"id" jsonschema:"required" description:"Node identifier"`
+	Type  string `json:"type" jsonschema:"required,enum=start|end|process|decision|io" description:"Node type: start and end bound the flow, process does work, io reads input or writes output, and decision branches"`
+	Label string `json:"label,omitempty" description:"Text of the node (default its ID)"`
+}
+
+// FlowchartConnection leads from one flowchart node to another
+type FlowchartConnection struct {
+	From  string `json:"from" jsonschema:"required" description:"ID of the node the connection leaves"`
+	To    string `json:"to" jsonschema:"required" description:"ID of the node the connection enters"`
+	Label string `json:"label,omitempty" description:"Answer that takes the connection; required on each branch of a decision, unique among its branches"`
+}
+
+// FlowchartResponse reports a recorded flowchart with its Mermaid, Graphviz
+// DOT and PlantUML renderings
+type FlowchartResponse struct {
+	VisualID    string `json:"visual_id"`
+	Status      string `json:"status"`
+	DiagramID   string `json:"diagram_id"`
+	Nodes       int    `json:"nodes"`
+	Connections int    `json:"connections"`
+	Mermaid     string `json:"mermaid"`
+	DOT         string `json:"dot"`
+	PlantUML    string `json:"plantuml"`
+}
+
+// DecisionTreeDiagramRequest seeds a decision tree diagram from a recorded
+// decision, or edits the diagram seeded from it
+type DecisionTreeDiagramRequest struct {
+	SessionID  string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DecisionID string          `json:"decision_id" jsonschema:"required" description:"ID of the recorded decision the diagram is linked to"`
+	Operation  string          `json:"operation,omitempty" jsonschema:"enum=seed|update|delete" description:"seed draws the diagram from the decision, its options branching from it and each option's criteria from the option, replacing any drawn before; update adds elements or replaces those of the same ID; delete removes elements or, naming none, the diagram and its link (default seed)"`
+	DiagramID  string          `json:"diagram_id,omitempty" description:"Diagram to seed (default the decision's diagram, or decision: and the decision's ID)"`
+	Elements   []VisualElement `json:"elements,omitempty" description:"Elements to add, replace or remove"`
+}
+
+// DecisionTreeDiagramResponse reports a decision's diagram as the operation
+// left it, with its Graphviz DOT rendering
+type DecisionTreeDiagramResponse struct {
+	VisualID   string          `json:"visual_id"`
+	Status     string          `json:"status"`
+	DiagramID  string          `json:"diagram_id"`
+	DecisionID string          `json:"decision_id"`
+	Operation  string          `json:"operation"`
+	Elements   []VisualElement `json:"elements"`
+	DOT        string          `json:"dot,omitempty"`
+}
+
+// ProbabilityTreeRequest records a probability tree, given as the nodes and
+// edges of a diagram, with its probabilities propagated from the root
+type ProbabilityTreeRequest struct {
+	SessionID string          `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DiagramID string          `json:"diagram_id,omitempty" description:"Unique identifier for the diagram (default probability-tree)"`
+	Title     string          `json:"title,omitempty" description:"What the tree shows"`
+	Elements  []VisualElement `json:"elements" jsonschema:"required,minItems=1" description:"Nodes, and edges from each node to its branches with their probabilities; the probabilities leaving a node sum to 1, and a node's numeric value property is collected on reaching it"`
+}
+
+// ProbabilityTreeResponse reports a recorded probability tree: its expected
+// value, each leaf's joint probability and payoff, and its elements with the
+// propagated probabilities and values written back onto them
+type ProbabilityTreeResponse struct {
+	VisualID      string            `json:"visual_id"`
+	Status        string            `json:"status"`
+	DiagramID     string            `json:"diagram_id"`
+	ExpectedValue float64           `json:"expected_value"`
+	Leaves        []ProbabilityLeaf `json:"leaves"`
+	Elements      []VisualElement   `json:"elements"`
+	Summary       string            `json:"summary"`
+}
+
+// ProbabilityLeaf is a leaf of a probability tree: the labels of the path
+// from the root to it, the chance of following that path, the total of the
+// values on the way and that total's contribution to the expected value
+type ProbabilityLeaf struct {
+	Node             string   `json:"node"`
+	Label            string   `json:"label,omitempty"`
+	Path             []string `json:"path"`
+	JointProbability float64  `json:"joint_probability"`
+	Payoff           float64  `json:"payoff"`
+	Contribution     float64  `json:"contribution"`
+}
+
+// MapSessionRequest drafts a concept map of a session's reasoning
+type MapSessionRequest struct {
+	SessionID       string `json:"session_id" jsonschema:"required" description:"Session identifier"`
+	DiagramID       string `json:"diagram_id,omitempty" description:"Diagram to record the map as (default session-map); an existing map is replaced"`
+	MaxConcepts     int    `json:"max_concepts,omitempty" jsonschema:"minimum=0,maximum=100" description:"Most concepts to map (default 15)"`
+	MinDocuments    int    `json:"min_documents,omitempty" jsonschema:"minimum=0" description:"Fewest thoughts, mental models and decisions a keyword must occur in to become a concept (default 2)"`
+	MinCooccurrence int    `json:"min_cooccurrence,omitempty" jsonschema:"minimum=0" description:"Fewest artifacts two concepts must share to be related (default 2)"`
+}
+
+// MapSessionResponse reports the concept map drafted from a session
+type MapSessionResponse struct {
+	VisualID  string            `json:"visual_id"`
+	Status    string            `json:"status"`
+	DiagramID string            `json:"diagram_id"`
+	Artifacts int               `json:"artifacts"`
+	Concepts  []MappedConcept   `json:"concepts"`
+	Relations []ConceptRelation `json:"relations"`
+	Elements  []VisualElement   `json:"elements"`
+	Summary   string            `json:"summary"`
+}
+
+// MappedConcept is a keyword of a session with the artifacts mentioning it
+type MappedConcept struct {
+	Term      string   `json:"term"`
+	Mentions  int      `json:"mentions"`
+	Artifacts []string `json:"artifacts"`
+}
+
+// ConceptRelation relates two concepts through the artifacts mentioning both
+type ConceptRelation struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Artifacts []string `json:"artifacts"`
+}
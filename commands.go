@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+)
+
+// commands are the subcommands named by the first argument; serve mcp runs
+// when none is given
+var commands = map[string]func(args []string) error{
+	"serve":   runServe,
+	"backup":  runBackup,
+	"restore": runRestore,
+}
+
+// runBackup writes every session in the configured storage to a backup archive
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	out := flags.String("out", "", "backup archive to write (.tar.gz), or - for stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("usage: gothink backup --out file.tar.gz")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := openBackupSource(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	backend := cfg.StorageBackend
+	if backend == "" {
+		backend = "memory"
+	}
+
+	if *out == "-" {
+		_, err := storage.WriteBackup(store, backend, os.Stdout)
+		return err
+	}
+
+	// Write beside the destination and rename so a failed backup never
+	// replaces a good one
+	tmp, err := os.CreateTemp(filepath.Dir(*out), filepath.Base(*out)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := storage.WriteBackup(store, backend, tmp)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Backed up %d sessions to %s\n", manifest.Sessions, *out)
+	return nil
+}
+
+// runRestore loads the sessions of a backup archive into the configured storage
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	in := flags.String("in", "", "backup archive to read (.tar.gz), or - for stdin")
+	replace := flags.Bool("replace", false, "replace sessions that already exist instead of skipping them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" && flags.NArg() == 1 {
+		*in = flags.Arg(0)
+	}
+	if *in == "" {
+		return fmt.Errorf("usage: gothink restore [--replace] --in file.tar.gz")
+	}
+
+	var archive io.Reader = os.Stdin
+	if *in != "-" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return fmt.Errorf("failed to open backup: %w", err)
+		}
+		defer file.Close()
+		archive = file
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	result, restoreErr := storage.ReadBackup(store, archive, *replace)
+	if err := store.Close(); err != nil && restoreErr == nil {
+		restoreErr = fmt.Errorf("failed to close storage: %w", err)
+	}
+	if result != nil {
+		fmt.Fprintf(os.Stderr, "Restored %d sessions (%d records), skipped %d existing sessions\n",
+			len(result.Restored), result.Records, len(result.Skipped))
+	}
+
+	return restoreErr
+}
+
+// openBackupSource opens the configured storage for reading. The memory
+// backend keeps its sessions inside the server process, so they are read
+// from the snapshot and journal it writes, leaving both untouched; durable
+// backends are opened directly without their journal, which only repeats
+// what they already hold.
+func openBackupSource(cfg *config.Config) (storage.Store, error) {
+	if cfg.StorageBackend != "" && cfg.StorageBackend != "memory" {
+		source := *cfg
+		source.EnableJournal = false
+		store, err := storage.New(&source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create storage: %w", err)
+		}
+		return store, nil
+	}
+
+	persisted := cfg.EnablePersistence && cfg.PersistencePath != ""
+	if !persisted && !cfg.EnableJournal {
+		return nil, fmt.Errorf("the memory backend keeps sessions only inside the server process; enable persistence or the journal to back them up")
+	}
+
+	store := storage.NewMemoryStore(cfg)
+	if persisted {
+		if err := store.LoadSnapshot(storage.SnapshotPath(cfg)); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.EnableJournal {
+		if err := storage.ReplayJournal(store, storage.JournalPath(cfg)); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
@@ -0,0 +1,327 @@
+// Package abtest compares the conversion rates of the variants of an A/B
+// test two ways. The frequentist comparison tests each variant against the
+// control with a two-proportion z-test. The Bayesian one gives each rate a
+// Beta posterior, from a Beta prior and the variant's conversions, and draws
+// from the posteriors to estimate the chance that each variant beats the
+// control and that it is the best, and the conversion rate expected to be
+// lost by choosing it.
+package abtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/rainmana/gothink/internal/parallel"
+	"github.com/rainmana/gothink/internal/randdist"
+)
+
+// Variant is an arm of a test: the visitors it was shown to and how many of
+// them converted
+type Variant struct {
+	Name        string
+	Visitors    int
+	Conversions int
+}
+
+// Options control a run
+type Options struct {
+	// Control is the index of the variant the others are compared with
+	Control int
+	// Confidence is the coverage of the intervals, such as 0.95; one less
+	// it is the significance level of the z-tests
+	Confidence float64
+	// PriorAlpha and PriorBeta are the shapes of the Beta prior of every
+	// rate, 1 and 1 for a uniform prior
+	PriorAlpha float64
+	PriorBeta  float64
+	// Samples is the number of draws from the posteriors
+	Samples int
+	// Parallelism is the number of goroutines drawing samples, 1 when zero.
+	// The draws are made in chunks seeded from Rand, so the result does not
+	// depend on it.
+	Parallelism int
+	Rand        *rand.Rand
+}
+
+// Interval bounds a rate or a difference of rates
+type Interval struct {
+	Lower float64
+	Upper float64
+}
+
+// Comparison is how a variant fares against the control
+type Comparison struct {
+	// Difference is the variant's rate less the control's, and
+	// RelativeLift the difference as a share of the control's rate, 0 when
+	// the control converted no one; DifferenceInterval bounds the difference
+	// by the normal approximation
+	Difference         float64
+	RelativeLift       float64
+	DifferenceInterval Interval
+	// ZScore and PValue are those of the two-sided pooled z-test of equal
+	// rates. The difference is Significant when PValue is below the
+	// significance level divided among the variants compared with the
+	// control, by Bonferroni's correction.
+	ZScore      float64
+	PValue      float64
+	Significant bool
+	// ProbabilityToBeat is the posterior chance that the variant's rate
+	// exceeds the control's
+	ProbabilityToBeat float64
+}
+
+// VariantResult is the analysis of a variant
+type VariantResult struct {
+	Variant
+	// Rate is the share of visitors who converted, and RateInterval its
+	// Wilson score interval
+	Rate         float64
+	RateInterval Interval
+	// PosteriorMean is the mean of the rate's Beta posterior, and
+	// CredibleInterval its central interval
+	PosteriorMean    float64
+	CredibleInterval Interval
+	// ProbabilityBest is the posterior chance that the variant has the
+	// highest rate, and ExpectedLoss the posterior mean of how far the
+	// highest rate exceeds the variant's: the rate given up by choosing it
+	ProbabilityBest float64
+	ExpectedLoss    float64
+	// VsControl compares the variant with the control; it is nil for the
+	// control itself
+	VsControl *Comparison
+}
+
+// Result is the outcome of a run
+type Result struct {
+	Variants []VariantResult
+	// Best is the index of the variant with the least expected loss
+	Best int
+	// MonteCarloError is the largest standard error of the probabilities
+	// estimated from the draws
+	MonteCarloError float64
+}
+
+// Analyze compares the variants of a test. It returns ctx's error if ctx
+// ends first.
+func Analyze(ctx context.Context, variants []Variant, opts Options) (*Result, error) {
+	switch {
+	case len(variants) < 2:
+		return nil, errors.New("a test needs at least 2 variants")
+	case opts.Control < 0 || opts.Control >= len(variants):
+		return nil, fmt.Errorf("there is no control variant %d", opts.Control)
+	case !(opts.Confidence > 0 && opts.Confidence < 1):
+		return nil, errors.New("the confidence must be within (0, 1)")
+	case !(opts.PriorAlpha > 0 && opts.PriorBeta > 0):
+		return nil, errors.New("the prior's shapes must be positive")
+	case opts.Samples < 1:
+		return nil, errors.New("there must be at least 1 sample")
+	case opts.Parallelism < 0:
+		return nil, errors.New("parallelism must not be negative")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+	names := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		switch {
+		case v.Name == "":
+			return nil, errors.New("every variant needs a name")
+		case names[v.Name]:
+			return nil, fmt.Errorf("variant %q is named twice", v.Name)
+		case v.Visitors < 1:
+			return nil, fmt.Errorf("variant %q needs at least 1 visitor", v.Name)
+		case v.Conversions < 0 || v.Conversions > v.Visitors:
+			return nil, fmt.Errorf("variant %q must have between 0 and its %d visitors' conversions", v.Name, v.Visitors)
+		}
+		names[v.Name] = true
+	}
+
+	z := math.Sqrt2 * math.Erfinv(opts.Confidence)
+	tail := (1 - opts.Confidence) / 2
+	result := &Result{Variants: make([]VariantResult, len(variants))}
+	alphas := make([]float64, len(variants))
+	betas := make([]float64, len(variants))
+	for i, v := range variants {
+		alphas[i] = opts.PriorAlpha + float64(v.Conversions)
+		betas[i] = opts.PriorBeta + float64(v.Visitors-v.Conversions)
+		result.Variants[i] = VariantResult{
+			Variant:       v,
+			Rate:          float64(v.Conversions) / float64(v.Visitors),
+			RateInterval:  wilson(v, z),
+			PosteriorMean: alphas[i] / (alphas[i] + betas[i]),
+			CredibleInterval: Interval{
+				Lower: betaQuantile(tail, alphas[i], betas[i]),
+				Upper: betaQuantile(1-tail, alphas[i], betas[i]),
+			},
+		}
+	}
+
+	// Test each variant against the control, sharing the significance
+	// level among them
+	control := variants[opts.Control]
+	level := (1 - opts.Confidence) / float64(len(variants)-1)
+	for i, v := range variants {
+		if i != opts.Control {
+			result.Variants[i].VsControl = compare(control, v, z, level)
+		}
+	}
+
+	// Draw from the posteriors, each chunk counting into its own tallies
+	chunks := (opts.Samples + parallel.ChunkSize - 1) / parallel.ChunkSize
+	type tally struct {
+		best, beat []int
+		loss       []float64
+	}
+	tallies := make([]tally, chunks)
+	err := parallel.Chunks(ctx, opts.Samples, parallel.ChunkSize, opts.Parallelism, opts.Rand, func(start, end int, r *rand.Rand) error {
+		t := tally{best: make([]int, len(variants)), beat: make([]int, len(variants)), loss: make([]float64, len(variants))}
+		draws := make([]float64, len(variants))
+		for s := start; s < end; s++ {
+			best := 0
+			for i := range variants {
+				draws[i] = randdist.Beta(r, alphas[i], betas[i])
+				if draws[i] > draws[best] {
+					best = i
+				}
+			}
+			t.best[best]++
+			for i := range variants {
+				if draws[i] > draws[opts.Control] {
+					t.beat[i]++
+				}
+				t.loss[i] += draws[best] - draws[i]
+			}
+		}
+		tallies[start/parallel.ChunkSize] = t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	samples := float64(opts.Samples)
+	for i := range result.Variants {
+		best, beat, loss := 0, 0, 0.0
+		for _, t := range tallies {
+			best += t.best[i]
+			beat += t.beat[i]
+			loss += t.loss[i]
+		}
+		v := &result.Variants[i]
+		v.ProbabilityBest = float64(best) / samples
+		v.ExpectedLoss = loss / samples
+		if v.VsControl != nil {
+			v.VsControl.ProbabilityToBeat = float64(beat) / samples
+		}
+		for _, p := range []float64{v.ProbabilityBest, float64(beat) / samples} {
+			result.MonteCarloError = math.Max(result.MonteCarloError, math.Sqrt(p*(1-p)/samples))
+		}
+		if v.ExpectedLoss < result.Variants[result.Best].ExpectedLoss {
+			result.Best = i
+		}
+	}
+	return result, nil
+}
+
+// wilson returns the Wilson score interval of v's rate for the normal
+// quantile z
+func wilson(v Variant, z float64) Interval {
+	n := float64(v.Visitors)
+	p := float64(v.Conversions) / n
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	spread := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return Interval{Lower: math.Max(0, center-spread), Upper: math.Min(1, center+spread)}
+}
+
+// compare tests v against control at the significance level, with z the
+// normal quantile of the difference's interval
+func compare(control, v Variant, z, level float64) *Comparison {
+	n1, n2 := float64(control.Visitors), float64(v.Visitors)
+	p1, p2 := float64(control.Conversions)/n1, float64(v.Conversions)/n2
+	c := &Comparison{Difference: p2 - p1}
+	if p1 > 0 {
+		c.RelativeLift = c.Difference / p1
+	}
+	spread := z * math.Sqrt(p1*(1-p1)/n1+p2*(1-p2)/n2)
+	c.DifferenceInterval = Interval{Lower: c.Difference - spread, Upper: c.Difference + spread}
+
+	pooled := float64(control.Conversions+v.Conversions) / (n1 + n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	c.PValue = 1
+	if se > 0 {
+		c.ZScore = c.Difference / se
+		c.PValue = math.Erfc(math.Abs(c.ZScore) / math.Sqrt2)
+	}
+	c.Significant = c.PValue < level
+	return c
+}
+
+// betaQuantile returns the q-th quantile of Beta(a, b) by bisection of its
+// distribution function
+func betaQuantile(q, a, b float64) float64 {
+	low, high := 0.0, 1.0
+	for i := 0; i < 60; i++ {
+		mid := (low + high) / 2
+		if regularizedBeta(mid, a, b) < q {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// regularizedBeta returns the distribution function of Beta(a, b) at x, the
+// regularized incomplete beta function, by its continued fraction
+func regularizedBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	// The fraction converges quickly below the mean; above it, use the
+	// symmetry I_x(a, b) = 1 - I_{1-x}(b, a)
+	if x > (a+1)/(a+b+2) {
+		return 1 - regularizedBeta(1-x, b, a)
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	return front * betaFraction(x, a, b) / a
+}
+
+// betaFraction evaluates the continued fraction of the incomplete beta
+// function by Lentz's method
+func betaFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+		// Even step
+		numerator := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 / clamp(1+numerator*d)
+		c = clamp(1 + numerator/c)
+		h *= d * c
+		// Odd step
+		numerator = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 / clamp(1+numerator*d)
+		c = clamp(1 + numerator/c)
+		step := d * c
+		h *= step
+		if math.Abs(step-1) < 1e-14 {
+			break
+		}
+	}
+	return h
+}
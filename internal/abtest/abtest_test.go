@@ -0,0 +1,152 @@
+package abtest
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func options(parallelism int) Options {
+	return Options{
+		Confidence:  0.95,
+		PriorAlpha:  1,
+		PriorBeta:   1,
+		Samples:     50000,
+		Parallelism: parallelism,
+		Rand:        rand.New(rand.NewSource(1)),
+	}
+}
+
+func TestAnalyze_ComparesAVariantWithTheControl(t *testing.T) {
+	result, err := Analyze(context.Background(), []Variant{
+		{Name: "control", Visitors: 1000, Conversions: 100},
+		{Name: "treatment", Visitors: 1000, Conversions: 130},
+	}, options(1))
+	require.NoError(t, err)
+	require.Len(t, result.Variants, 2)
+
+	control, treatment := result.Variants[0], result.Variants[1]
+	assert.Nil(t, control.VsControl)
+	assert.Equal(t, 0.1, control.Rate)
+	assert.InDelta(t, 101.0/1002, control.PosteriorMean, 1e-12)
+
+	// The pooled rate is 0.115, so z = 0.03 / sqrt(0.115·0.885·2/1000)
+	vs := treatment.VsControl
+	require.NotNil(t, vs)
+	assert.InDelta(t, 0.03, vs.Difference, 1e-12)
+	assert.InDelta(t, 0.3, vs.RelativeLift, 1e-12)
+	assert.InDelta(t, 2.1027, vs.ZScore, 1e-4)
+	assert.InDelta(t, 0.0355, vs.PValue, 1e-4)
+	assert.True(t, vs.Significant)
+	assert.Greater(t, vs.DifferenceInterval.Lower, 0.0)
+
+	// The normal approximation of the posteriors puts the treatment ahead
+	// with chance 0.98
+	assert.InDelta(t, 0.982, vs.ProbabilityToBeat, 0.005)
+	assert.Equal(t, vs.ProbabilityToBeat, treatment.ProbabilityBest)
+	assert.Equal(t, 1, result.Best)
+	assert.Less(t, treatment.ExpectedLoss, 0.001)
+	assert.InDelta(t, 0.03, control.ExpectedLoss, 0.002)
+	assert.Less(t, result.MonteCarloError, 0.005)
+}
+
+func TestAnalyze_SharesTheSignificanceLevelAmongVariants(t *testing.T) {
+	variants := []Variant{
+		{Name: "a", Visitors: 1000, Conversions: 100},
+		{Name: "b", Visitors: 1000, Conversions: 100},
+		{Name: "c", Visitors: 1000, Conversions: 100},
+		{Name: "d", Visitors: 1000, Conversions: 130},
+	}
+	result, err := Analyze(context.Background(), variants, options(1))
+	require.NoError(t, err)
+
+	// Significant alone at 0.05, but not at 0.05 shared among three
+	assert.InDelta(t, 0.0355, result.Variants[3].VsControl.PValue, 1e-4)
+	assert.False(t, result.Variants[3].VsControl.Significant)
+
+	// Identical variants are equally likely to be best
+	for _, v := range result.Variants[:3] {
+		assert.InDelta(t, result.Variants[0].ProbabilityBest, v.ProbabilityBest, 0.01)
+	}
+	total := 0.0
+	for _, v := range result.Variants {
+		total += v.ProbabilityBest
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+	assert.Equal(t, 3, result.Best)
+}
+
+func TestAnalyze_DoesNotDependOnParallelism(t *testing.T) {
+	variants := []Variant{
+		{Name: "a", Visitors: 500, Conversions: 40},
+		{Name: "b", Visitors: 480, Conversions: 45},
+		{Name: "c", Visitors: 510, Conversions: 38},
+	}
+	serial, err := Analyze(context.Background(), variants, options(1))
+	require.NoError(t, err)
+	parallel, err := Analyze(context.Background(), variants, options(4))
+	require.NoError(t, err)
+	assert.Equal(t, serial, parallel)
+}
+
+func TestAnalyze_BoundsRates(t *testing.T) {
+	result, err := Analyze(context.Background(), []Variant{
+		{Name: "none", Visitors: 10},
+		{Name: "all", Visitors: 10, Conversions: 10},
+	}, options(1))
+	require.NoError(t, err)
+
+	// Wilson's interval stays within [0, 1] where the normal one collapses
+	none, all := result.Variants[0], result.Variants[1]
+	assert.Equal(t, 0.0, none.RateInterval.Lower)
+	assert.InDelta(t, 3.8415/13.8415, none.RateInterval.Upper, 1e-4)
+	assert.InDelta(t, 1-none.RateInterval.Upper, all.RateInterval.Lower, 1e-9)
+	assert.Zero(t, all.VsControl.RelativeLift, "no lift over a rate of 0")
+
+	// Beta(1, 11) puts 2.5% below 1 - 0.975^(1/11)
+	assert.InDelta(t, 1-math.Pow(0.975, 1.0/11), none.CredibleInterval.Lower, 1e-9)
+	assert.InDelta(t, 1-math.Pow(0.025, 1.0/11), none.CredibleInterval.Upper, 1e-9)
+}
+
+func TestBetaQuantile_InvertsTheDistribution(t *testing.T) {
+	assert.InDelta(t, 0.5, betaQuantile(0.5, 2, 2), 1e-12)
+	assert.InDelta(t, 0.3, betaQuantile(0.3, 1, 1), 1e-12)
+	assert.InDelta(t, math.Sqrt(0.7), betaQuantile(0.7, 2, 1), 1e-12)
+	assert.InDelta(t, 0.9, regularizedBeta(betaQuantile(0.9, 130.5, 870.5), 130.5, 870.5), 1e-9)
+}
+
+func TestAnalyze_RejectsInvalidTests(t *testing.T) {
+	valid := []Variant{{Name: "a", Visitors: 10, Conversions: 1}, {Name: "b", Visitors: 10, Conversions: 2}}
+	for name, variants := range map[string][]Variant{
+		"one variant":    valid[:1],
+		"unnamed":        {{Visitors: 10}, valid[1]},
+		"named twice":    {valid[0], valid[0]},
+		"no visitors":    {{Name: "a"}, valid[1]},
+		"over-converted": {{Name: "a", Visitors: 10, Conversions: 11}, valid[1]},
+		"negative":       {{Name: "a", Visitors: 10, Conversions: -1}, valid[1]},
+	} {
+		_, err := Analyze(context.Background(), variants, options(1))
+		assert.Error(t, err, name)
+	}
+	for name, change := range map[string]func(*Options){
+		"no control":    func(o *Options) { o.Control = 2 },
+		"confidence":    func(o *Options) { o.Confidence = 1 },
+		"prior":         func(o *Options) { o.PriorBeta = 0 },
+		"no samples":    func(o *Options) { o.Samples = 0 },
+		"no randomness": func(o *Options) { o.Rand = nil },
+	} {
+		opts := options(1)
+		change(&opts)
+		_, err := Analyze(context.Background(), valid, opts)
+		assert.Error(t, err, name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Analyze(ctx, valid, options(1))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,130 @@
+// Package ahp derives priorities from pairwise comparisons by the Analytic
+// Hierarchy Process. A comparison matrix holds in row i, column j how many
+// times more important, or preferred, item i is than item j, on Saaty's
+// scale from 1 (equally) to 9 (extremely), with the reciprocal in row j,
+// column i. The priorities are the matrix's principal eigenvector, scaled to
+// sum to 1, and its consistency ratio measures how far the judgments
+// contradict each other against random judgments; a ratio above 0.1 is
+// conventionally too inconsistent to rely on.
+package ahp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ConsistencyThreshold is the highest consistency ratio of acceptably
+// consistent judgments
+const ConsistencyThreshold = 0.1
+
+// MaxItems is the most items a matrix compares, the most Saaty's random
+// indices cover
+const MaxItems = 15
+
+// randomIndex holds Saaty's random consistency index for 1 to MaxItems items:
+// the mean consistency index of random reciprocal matrices
+var randomIndex = [MaxItems + 1]float64{0, 0, 0, 0.58, 0.90, 1.12, 1.24, 1.32, 1.41, 1.45, 1.49, 1.51, 1.48, 1.56, 1.57, 1.59}
+
+// Result holds the priorities a comparison matrix derives
+type Result struct {
+	// Priorities holds each item's priority; they sum to 1
+	Priorities []float64
+	// LambdaMax is the principal eigenvalue, the number of items for
+	// perfectly consistent judgments and more the less consistent they are
+	LambdaMax        float64
+	ConsistencyIndex float64
+	// ConsistencyRatio is the consistency index over the random index of
+	// as many items, 0 for up to 2 items, which cannot be inconsistent
+	ConsistencyRatio float64
+	Consistent       bool
+}
+
+// Priorities derives the priorities of the items matrix compares. An entry
+// of 0 takes the reciprocal of the entry mirroring it across the diagonal,
+// so that only one triangle need be given; the diagonal may be 0 or 1.
+func Priorities(matrix [][]float64) (*Result, error) {
+	n := len(matrix)
+	switch {
+	case n == 0:
+		return nil, errors.New("there is nothing to compare")
+	case n > MaxItems:
+		return nil, fmt.Errorf("at most %d items can be compared", MaxItems)
+	}
+	for i, row := range matrix {
+		if len(row) != n {
+			return nil, fmt.Errorf("row %d has %d entries for %d items", i+1, len(row), n)
+		}
+	}
+
+	// Complete the matrix and check that it is reciprocal
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n)
+		for j := range a[i] {
+			x, mirror := matrix[i][j], matrix[j][i]
+			switch {
+			case i == j:
+				if x != 0 && x != 1 {
+					return nil, fmt.Errorf("entry (%d, %d) compares an item with itself, so must be 1", i+1, j+1)
+				}
+				x = 1
+			case math.IsNaN(x) || math.IsInf(x, 0) || x < 0:
+				return nil, fmt.Errorf("entry (%d, %d) must be positive", i+1, j+1)
+			case x == 0 && mirror == 0:
+				return nil, fmt.Errorf("entries (%d, %d) and (%d, %d) are both missing", i+1, j+1, j+1, i+1)
+			case x == 0:
+				x = 1 / mirror
+			case mirror != 0 && math.Abs(x*mirror-1) > 1e-3:
+				return nil, fmt.Errorf("entries (%d, %d) and (%d, %d) are not reciprocal", i+1, j+1, j+1, i+1)
+			}
+			a[i][j] = x
+		}
+	}
+
+	// Power iteration converges to the principal eigenvector of a positive
+	// matrix from any positive start
+	v := make([]float64, n)
+	for i := range v {
+		v[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for iteration := 0; iteration < 1000; iteration++ {
+		total := 0.0
+		for i := range next {
+			next[i] = 0
+			for j := range v {
+				next[i] += a[i][j] * v[j]
+			}
+			total += next[i]
+		}
+		change := 0.0
+		for i := range next {
+			next[i] /= total
+			change = math.Max(change, math.Abs(next[i]-v[i]))
+		}
+		v, next = next, v
+		if change < 1e-14 {
+			break
+		}
+	}
+
+	lambda := 0.0
+	for i := range v {
+		product := 0.0
+		for j := range v {
+			product += a[i][j] * v[j]
+		}
+		lambda += product / v[i]
+	}
+	lambda /= float64(n)
+
+	result := &Result{Priorities: v, LambdaMax: lambda}
+	if n > 2 {
+		// Rounding can leave a consistent matrix's eigenvalue a hair below n
+		result.ConsistencyIndex = math.Max(0, (lambda-float64(n))/float64(n-1))
+		result.ConsistencyRatio = result.ConsistencyIndex / randomIndex[n]
+	}
+	result.Consistent = result.ConsistencyRatio <= ConsistencyThreshold
+	return result, nil
+}
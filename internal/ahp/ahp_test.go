@@ -0,0 +1,74 @@
+package ahp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorities_RecoverConsistentWeights(t *testing.T) {
+	// Judgments from the weights 0.6, 0.3 and 0.1 are perfectly consistent
+	result, err := Priorities([][]float64{
+		{1, 2, 6},
+		{0.5, 1, 3},
+		{1.0 / 6, 1.0 / 3, 1},
+	})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6, 0.3, 0.1}, result.Priorities, 1e-12)
+	assert.InDelta(t, 3, result.LambdaMax, 1e-9)
+	assert.InDelta(t, 0, result.ConsistencyRatio, 1e-9)
+	assert.True(t, result.Consistent)
+}
+
+func TestPriorities_MeasureInconsistency(t *testing.T) {
+	// Saaty's example: A is 3 times B and B 3 times C, but A only 5 times C
+	result, err := Priorities([][]float64{
+		{1, 3, 5},
+		{0, 1, 3},
+		{0, 0, 1},
+	})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6370, 0.2583, 0.1047}, result.Priorities, 1e-4)
+	assert.InDelta(t, 3.0385, result.LambdaMax, 1e-4)
+	assert.InDelta(t, 0.0193, result.ConsistencyIndex, 1e-4)
+	assert.InDelta(t, 0.0332, result.ConsistencyRatio, 1e-4)
+	assert.True(t, result.Consistent)
+
+	// A circle of preferences is as inconsistent as judgments get
+	result, err = Priorities([][]float64{
+		{1, 9, 1.0 / 9},
+		{0, 1, 9},
+		{0, 0, 1},
+	})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, result.Priorities, 1e-9)
+	assert.Greater(t, result.ConsistencyRatio, 1.0)
+	assert.False(t, result.Consistent)
+}
+
+func TestPriorities_ComparesFewItems(t *testing.T) {
+	result, err := Priorities([][]float64{{1}})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1}, result.Priorities)
+
+	result, err = Priorities([][]float64{{1, 4}, {0.25, 1}})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.8, 0.2}, result.Priorities, 1e-12)
+	assert.Zero(t, result.ConsistencyRatio)
+}
+
+func TestPriorities_RejectsInvalidMatrices(t *testing.T) {
+	for name, matrix := range map[string][][]float64{
+		"empty":         nil,
+		"ragged":        {{1, 2}, {0.5}},
+		"negative":      {{1, -2}, {0, 1}},
+		"diagonal":      {{2, 1}, {1, 1}},
+		"missing":       {{1, 0}, {0, 1}},
+		"nonreciprocal": {{1, 2}, {2, 1}},
+		"too many":      make([][]float64, MaxItems+1),
+	} {
+		_, err := Priorities(matrix)
+		assert.Error(t, err, name)
+	}
+}
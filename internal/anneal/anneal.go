@@ -0,0 +1,258 @@
+// Package anneal minimizes objectives by simulated annealing. The search
+// space is a box of bounded variables; each iteration proposes a Gaussian
+// move from the current point and accepts it by the Metropolis rule at the
+// current temperature, which a cooling schedule lowers from the initial to
+// the final temperature over the run. A run reports the best point found and
+// the trajectory of the search.
+package anneal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+)
+
+// Cooling schedules
+const (
+	// Exponential multiplies the temperature by a constant factor each
+	// iteration
+	Exponential = "exponential"
+	// Linear lowers the temperature by a constant amount each iteration
+	Linear = "linear"
+	// Logarithmic divides the initial temperature by 1 + c·ln(1+k), cooling
+	// fast at first and slowly later
+	Logarithmic = "logarithmic"
+	// Fast divides the initial temperature by 1 + c·k, as in Cauchy
+	// annealing
+	Fast = "fast"
+)
+
+// maxTrajectoryPoints bounds the points of a trajectory
+const maxTrajectoryPoints = 100
+
+// Variable is one dimension of the search space
+type Variable struct {
+	Name string
+	Min  float64
+	Max  float64
+}
+
+// Objective evaluates the objective at a point of the search space
+type Objective func(point map[string]float64) (float64, error)
+
+// Options control a run
+type Options struct {
+	Schedule string
+	// InitialTemperature and FinalTemperature are the temperatures of the
+	// first and last iterations, in the objective's units
+	InitialTemperature float64
+	FinalTemperature   float64
+	Iterations         int
+	// StepSize is the standard deviation of a move relative to each
+	// variable's range
+	StepSize float64
+	// Start is the point the search starts from; a random point when nil
+	Start map[string]float64
+	// The run has converged once the best value falls by at most Tolerance
+	// over Patience iterations; StopAtPlateau stops it there rather than
+	// running every iteration
+	Tolerance     float64
+	Patience      int
+	StopAtPlateau bool
+	// TimeLimit, when positive, stops the run early with the best point
+	// found so far
+	TimeLimit time.Duration
+	// Rand is the source of randomness of the moves and their acceptance
+	Rand *rand.Rand
+}
+
+// Step is a point of a run's trajectory: the state of the search after an
+// iteration
+type Step struct {
+	Iteration   int
+	Temperature float64
+	Point       map[string]float64
+	Value       float64
+	BestValue   float64
+	// AcceptanceRate is the share of moves accepted since the previous step
+	AcceptanceRate float64
+}
+
+// Result is the outcome of a run
+type Result struct {
+	BestPoint  map[string]float64
+	BestValue  float64
+	FinalPoint map[string]float64
+	FinalValue float64
+	// StartValue is the value of the starting point
+	StartValue float64
+	// Accepted counts the moves accepted, and Uphill those of them that
+	// raised the value
+	Accepted int
+	Uphill   int
+	// Trajectory samples the search at most 100 times, always including the
+	// last iteration
+	Trajectory []Step
+	// Iterations counts the iterations run
+	Iterations int
+	// Converged reports whether the best value reached a plateau, in a run
+	// that did not run out of time, and Residuals holds how much it fell
+	// between the steps of the trajectory
+	Converged      bool
+	StoppingReason string
+	Residuals      []float64
+}
+
+// Temperature returns the temperature of iteration k of n under schedule,
+// falling from initial at the first iteration to final at the last
+func Temperature(schedule string, initial, final float64, k, n int) (float64, error) {
+	if n <= 1 {
+		return initial, nil
+	}
+	ratio := initial / final
+	switch schedule {
+	case Exponential:
+		return initial * math.Pow(final/initial, float64(k)/float64(n-1)), nil
+	case Linear:
+		return initial - (initial-final)*float64(k)/float64(n-1), nil
+	case Logarithmic:
+		c := (ratio - 1) / math.Log(float64(n))
+		return initial / (1 + c*math.Log(float64(k+1))), nil
+	case Fast:
+		c := (ratio - 1) / float64(n-1)
+		return initial / (1 + c*float64(k)), nil
+	}
+	return 0, fmt.Errorf("unknown cooling schedule %q", schedule)
+}
+
+// Minimize runs opts.Iterations moves of simulated annealing over variables.
+// It returns ctx's error if ctx ends first.
+func Minimize(ctx context.Context, variables []Variable, objective Objective, opts Options) (*Result, error) {
+	switch {
+	case opts.Schedule != Exponential && opts.Schedule != Linear && opts.Schedule != Logarithmic && opts.Schedule != Fast:
+		return nil, fmt.Errorf("unknown cooling schedule %q", opts.Schedule)
+	case len(variables) == 0:
+		return nil, errors.New("the search space has no variables")
+	case objective == nil:
+		return nil, errors.New("no objective")
+	case !(opts.InitialTemperature > 0) || !(opts.FinalTemperature > 0):
+		return nil, errors.New("the temperatures must be positive")
+	case opts.FinalTemperature > opts.InitialTemperature:
+		return nil, errors.New("the final temperature must not exceed the initial one")
+	case opts.Iterations <= 0:
+		return nil, errors.New("iterations must be positive")
+	case !(opts.StepSize > 0):
+		return nil, errors.New("the step size must be positive")
+	case opts.Tolerance < 0 || opts.Patience < 0:
+		return nil, errors.New("the tolerance and patience must not be negative")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+
+	seen := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		switch {
+		case v.Name == "" || seen[v.Name]:
+			return nil, errors.New("variables must have distinct names")
+		case math.IsInf(v.Min, 0) || math.IsInf(v.Max, 0) || !(v.Min < v.Max):
+			return nil, fmt.Errorf("variable %s needs finite bounds with min below max", v.Name)
+		}
+		seen[v.Name] = true
+	}
+
+	current := make(map[string]float64, len(variables))
+	for _, v := range variables {
+		if opts.Start == nil {
+			current[v.Name] = v.Min + opts.Rand.Float64()*(v.Max-v.Min)
+			continue
+		}
+		x, ok := opts.Start[v.Name]
+		if !ok || x < v.Min || x > v.Max {
+			return nil, fmt.Errorf("the start needs %s within [%v, %v]", v.Name, v.Min, v.Max)
+		}
+		current[v.Name] = x
+	}
+	if len(opts.Start) > len(variables) {
+		return nil, errors.New("the start sets variables outside the search space")
+	}
+
+	value, err := objective(current)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{BestPoint: current, BestValue: value, StartValue: value, StoppingReason: convergence.MaxIterations}
+	best := []float64{value}
+
+	interval := max(1, (opts.Iterations+maxTrajectoryPoints-1)/maxTrajectoryPoints)
+	accepted := 0
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+	for k := 0; k < opts.Iterations; k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		temperature, _ := Temperature(opts.Schedule, opts.InitialTemperature, opts.FinalTemperature, k, opts.Iterations)
+
+		candidate := make(map[string]float64, len(variables))
+		for _, v := range variables {
+			x := current[v.Name] + opts.StepSize*(v.Max-v.Min)*opts.Rand.NormFloat64()
+			candidate[v.Name] = math.Min(v.Max, math.Max(v.Min, x))
+		}
+		next, err := objective(candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		// The Metropolis rule: always move downhill, and uphill with a
+		// chance that shrinks as the temperature falls
+		if delta := next - value; delta <= 0 || opts.Rand.Float64() < math.Exp(-delta/temperature) {
+			if delta > 0 {
+				result.Uphill++
+			}
+			current, value = candidate, next
+			result.Accepted++
+			accepted++
+			if value < result.BestValue {
+				result.BestPoint, result.BestValue = current, value
+			}
+		}
+
+		best = append(best, result.BestValue)
+		result.Iterations++
+		result.Converged = convergence.Plateaued(best, opts.Patience, opts.Tolerance)
+		stop := result.Converged && opts.StopAtPlateau
+		timeUp := !stop && k+1 < opts.Iterations && deadline.Passed()
+
+		if iteration := k + 1; iteration%interval == 0 || iteration == opts.Iterations || stop || timeUp {
+			moves := iteration - (len(result.Trajectory) * interval)
+			if n := len(result.Trajectory); n > 0 {
+				result.Residuals = append(result.Residuals, result.Trajectory[n-1].BestValue-result.BestValue)
+			}
+			result.Trajectory = append(result.Trajectory, Step{
+				Iteration:      iteration,
+				Temperature:    temperature,
+				Point:          current,
+				Value:          value,
+				BestValue:      result.BestValue,
+				AcceptanceRate: float64(accepted) / float64(moves),
+			})
+			accepted = 0
+		}
+		if timeUp {
+			result.Converged = false
+			result.StoppingReason = convergence.TimeLimit
+			break
+		}
+		if stop {
+			result.StoppingReason = convergence.Plateau
+			break
+		}
+	}
+
+	result.FinalPoint, result.FinalValue = current, value
+	return result, nil
+}
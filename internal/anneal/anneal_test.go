@@ -0,0 +1,148 @@
+package anneal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var plane = []Variable{{Name: "x", Min: -5, Max: 5}, {Name: "y", Min: -5, Max: 5}}
+
+// bowl has its minimum of 1 at (1, -2)
+func bowl(point map[string]float64) (float64, error) {
+	return math.Pow(point["x"]-1, 2) + math.Pow(point["y"]+2, 2) + 1, nil
+}
+
+func options(schedule string) Options {
+	return Options{
+		Schedule:           schedule,
+		InitialTemperature: 10,
+		FinalTemperature:   1e-3,
+		Iterations:         3000,
+		StepSize:           0.05,
+		Rand:               rand.New(rand.NewSource(1)),
+	}
+}
+
+func TestMinimize_FindsTheMinimum(t *testing.T) {
+	for _, schedule := range []string{Exponential, Linear, Logarithmic, Fast} {
+		t.Run(schedule, func(t *testing.T) {
+			result, err := Minimize(context.Background(), plane, bowl, options(schedule))
+			require.NoError(t, err)
+
+			assert.InDelta(t, 1, result.BestValue, 0.05)
+			assert.InDelta(t, 1, result.BestPoint["x"], 0.25)
+			assert.InDelta(t, -2, result.BestPoint["y"], 0.25)
+			assert.LessOrEqual(t, result.BestValue, result.FinalValue)
+			assert.LessOrEqual(t, result.BestValue, result.StartValue)
+			assert.Greater(t, result.Uphill, 0)
+			assert.LessOrEqual(t, result.Uphill, result.Accepted)
+
+			require.Len(t, result.Trajectory, 100)
+			last := result.Trajectory[99]
+			assert.Equal(t, 3000, last.Iteration)
+			assert.InDelta(t, 1e-3, last.Temperature, 1e-9)
+			assert.Equal(t, result.BestValue, last.BestValue)
+			assert.Equal(t, result.FinalValue, last.Value)
+			// The search accepts more readily while hot
+			assert.Greater(t, result.Trajectory[0].AcceptanceRate, last.AcceptanceRate)
+		})
+	}
+}
+
+func TestMinimize_StartsFromAPoint(t *testing.T) {
+	opts := options(Exponential)
+	opts.Iterations = 10
+	opts.Start = map[string]float64{"x": 4, "y": 4}
+	result, err := Minimize(context.Background(), plane, bowl, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 46.0, result.StartValue)
+	assert.Len(t, result.Trajectory, 10)
+
+	opts.Start = map[string]float64{"x": 6, "y": 0}
+	_, err = Minimize(context.Background(), plane, bowl, opts)
+	assert.Error(t, err, "start out of bounds")
+	opts.Start = map[string]float64{"x": 0, "y": 0, "z": 0}
+	_, err = Minimize(context.Background(), plane, bowl, opts)
+	assert.Error(t, err, "start outside the space")
+}
+
+func TestMinimize_StopsAtPlateau(t *testing.T) {
+	opts := options(Exponential)
+	opts.Tolerance, opts.Patience = 1e-6, 300
+	result, err := Minimize(context.Background(), plane, bowl, opts)
+	require.NoError(t, err)
+	assert.True(t, result.Converged)
+	assert.Equal(t, "max_iterations", result.StoppingReason)
+	assert.Equal(t, 3000, result.Iterations)
+	require.Len(t, result.Residuals, 99)
+	for _, residual := range result.Residuals {
+		assert.GreaterOrEqual(t, residual, 0.0)
+	}
+
+	opts = options(Exponential)
+	opts.Tolerance, opts.Patience, opts.StopAtPlateau = 1e-6, 300, true
+	result, err = Minimize(context.Background(), plane, bowl, opts)
+	require.NoError(t, err)
+	assert.True(t, result.Converged)
+	assert.Equal(t, "plateau", result.StoppingReason)
+	assert.Less(t, result.Iterations, 3000)
+	assert.Equal(t, result.Iterations, result.Trajectory[len(result.Trajectory)-1].Iteration)
+}
+
+func TestMinimize_StopsAtTheTimeLimit(t *testing.T) {
+	opts := options(Exponential)
+	opts.Iterations, opts.TimeLimit = 100000000, time.Nanosecond
+	opts.Tolerance, opts.Patience = 1e-6, 1
+	result, err := Minimize(context.Background(), plane, bowl, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Converged)
+	assert.Equal(t, "time_limit", result.StoppingReason)
+	assert.Equal(t, 1, result.Iterations)
+	assert.Equal(t, result.Iterations, result.Trajectory[len(result.Trajectory)-1].Iteration)
+	assert.LessOrEqual(t, result.BestValue, result.StartValue)
+}
+
+func TestTemperature_FallsFromInitialToFinal(t *testing.T) {
+	for _, schedule := range []string{Exponential, Linear, Logarithmic, Fast} {
+		previous := math.Inf(1)
+		for k := 0; k < 50; k++ {
+			temperature, err := Temperature(schedule, 100, 0.1, k, 50)
+			require.NoError(t, err)
+			assert.Less(t, temperature, previous, schedule)
+			previous = temperature
+		}
+		first, _ := Temperature(schedule, 100, 0.1, 0, 50)
+		assert.InDelta(t, 100, first, 1e-9, schedule)
+		assert.InDelta(t, 0.1, previous, 1e-9, schedule)
+	}
+
+	_, err := Temperature("quadratic", 100, 0.1, 0, 50)
+	assert.Error(t, err)
+}
+
+func TestMinimize_RejectsInvalidRuns(t *testing.T) {
+	_, err := Minimize(context.Background(), nil, bowl, options(Exponential))
+	assert.Error(t, err, "no variables")
+	_, err = Minimize(context.Background(), []Variable{{Name: "x", Min: 1, Max: 1}}, bowl, options(Exponential))
+	assert.Error(t, err, "empty range")
+	_, err = Minimize(context.Background(), plane, nil, options(Exponential))
+	assert.Error(t, err, "no objective")
+	_, err = Minimize(context.Background(), plane, bowl, options("quadratic"))
+	assert.Error(t, err, "unknown schedule")
+
+	opts := options(Exponential)
+	opts.FinalTemperature = 20
+	_, err = Minimize(context.Background(), plane, bowl, opts)
+	assert.Error(t, err, "warming")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Minimize(ctx, plane, bowl, options(Exponential))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,217 @@
+// Package apierror defines the machine-readable error codes GoThink returns
+// from MCP tools, HTTP endpoints and gRPC methods. Failures are reported as a
+// JSON object of the form {"error": {"code": "SESSION_NOT_FOUND", "message": "..."}},
+// or over gRPC as a status carrying the code as the reason of its ErrorInfo
+// detail, so clients can branch on the code rather than parse the message.
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/workerpool"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Code identifies the kind of failure
+type Code string
+
+// Error codes
+const (
+	CodeInvalidParameters   Code = "INVALID_PARAMETERS"
+	CodeSessionNotFound     Code = "SESSION_NOT_FOUND"
+	CodeRecordNotFound      Code = "RECORD_NOT_FOUND"
+	CodeModelNotFound       Code = "MODEL_NOT_FOUND"
+	CodeAlreadyExists       Code = "ALREADY_EXISTS"
+	CodeSessionArchived     Code = "SESSION_ARCHIVED"
+	CodeSessionNotArchived  Code = "SESSION_NOT_ARCHIVED"
+	CodeSessionLimitReached Code = "SESSION_LIMIT_REACHED"
+	CodeQuotaExceeded       Code = "QUOTA_EXCEEDED"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeCancelled           Code = "CANCELLED"
+	CodeServerBusy          Code = "SERVER_BUSY"
+	CodeTimeout             Code = "TIMEOUT"
+	CodeUpstreamError       Code = "UPSTREAM_ERROR"
+	CodeInternal            Code = "INTERNAL_ERROR"
+)
+
+// HTTPStatus returns the HTTP status code reported with c
+func (c Code) HTTPStatus() int {
+	switch c {
+	case CodeInvalidParameters:
+		return http.StatusBadRequest
+	case CodeSessionNotFound, CodeRecordNotFound, CodeModelNotFound:
+		return http.StatusNotFound
+	case CodeAlreadyExists, CodeSessionArchived, CodeSessionNotArchived, CodeSessionLimitReached, CodeQuotaExceeded:
+		return http.StatusConflict
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	case CodeCancelled:
+		return http.StatusRequestTimeout
+	case CodeServerBusy:
+		return http.StatusServiceUnavailable
+	case CodeTimeout:
+		return http.StatusGatewayTimeout
+	case CodeUpstreamError:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode returns the gRPC status code reported with c
+func (c Code) GRPCCode() codes.Code {
+	switch c {
+	case CodeInvalidParameters:
+		return codes.InvalidArgument
+	case CodeSessionNotFound, CodeRecordNotFound, CodeModelNotFound:
+		return codes.NotFound
+	case CodeAlreadyExists:
+		return codes.AlreadyExists
+	case CodeSessionArchived, CodeSessionNotArchived:
+		return codes.FailedPrecondition
+	case CodeSessionLimitReached, CodeQuotaExceeded, CodeRateLimited:
+		return codes.ResourceExhausted
+	case CodeCancelled:
+		return codes.Canceled
+	case CodeServerBusy, CodeUpstreamError:
+		return codes.Unavailable
+	case CodeTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// Error is a failure with its code and a human-readable message
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errorf returns an error with the given code and formatted message
+func Errorf(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// CodeOf returns the code describing err: the code of an *Error it wraps, or
+// the code matching the storage or worker pool error it wraps, or
+// CodeInternal
+func CodeOf(err error) Code {
+	var apiErr *Error
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.Code
+	case errors.Is(err, storage.ErrInvalidArgument):
+		return CodeInvalidParameters
+	case errors.Is(err, storage.ErrSessionNotFound):
+		return CodeSessionNotFound
+	case errors.Is(err, storage.ErrRecordNotFound), errors.Is(err, storage.ErrNotFound):
+		return CodeRecordNotFound
+	case errors.Is(err, storage.ErrSessionExists), errors.Is(err, storage.ErrDuplicateID):
+		return CodeAlreadyExists
+	case errors.Is(err, storage.ErrSessionArchived):
+		return CodeSessionArchived
+	case errors.Is(err, storage.ErrSessionNotArchived):
+		return CodeSessionNotArchived
+	case errors.Is(err, storage.ErrThoughtLimit):
+		return CodeSessionLimitReached
+	case errors.Is(err, storage.ErrQuotaExceeded):
+		return CodeQuotaExceeded
+	case errors.Is(err, workerpool.ErrQueueFull):
+		return CodeServerBusy
+	case errors.Is(err, workerpool.ErrTimeout):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return CodeCancelled
+	default:
+		return CodeInternal
+	}
+}
+
+// CodeOr returns the code describing err, or fallback when nothing more
+// specific than CodeInternal is known, such as for the failure of an
+// external service
+func CodeOr(err error, fallback Code) Code {
+	if code := CodeOf(err); code != CodeInternal {
+		return code
+	}
+	return fallback
+}
+
+// body is the JSON document reporting an error
+type body struct {
+	Error *Error `json:"error"`
+}
+
+// ToolError returns an MCP tool error result with the given code. The
+// error object is both the text content and the structured content.
+func ToolError(code Code, format string, args ...interface{}) *mcp.CallToolResult {
+	report := body{Error: Errorf(code, format, args...)}
+	text, _ := json.Marshal(report)
+
+	result := mcp.NewToolResultError(string(text))
+	result.StructuredContent = report
+	return result
+}
+
+// ToolFailure returns an MCP tool error result coded by the cause of err
+func ToolFailure(err error, format string, args ...interface{}) *mcp.CallToolResult {
+	return ToolError(CodeOf(err), format, args...)
+}
+
+// FromToolResult returns the error reported by a tool error result, or nil
+// for a successful result. Errors not built by ToolError are CodeInternal.
+func FromToolResult(result *mcp.CallToolResult) *Error {
+	if result == nil || !result.IsError {
+		return nil
+	}
+	if report, ok := result.StructuredContent.(body); ok && report.Error != nil {
+		return report.Error
+	}
+
+	var message string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			message += text.Text
+		}
+	}
+	return &Error{Code: CodeInternal, Message: message}
+}
+
+// Write responds to an HTTP request with the error object and the status
+// matching code
+func Write(w http.ResponseWriter, code Code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code.HTTPStatus())
+	json.NewEncoder(w).Encode(body{Error: &Error{Code: code, Message: message}})
+}
+
+// ErrorInfoDomain is the domain of the ErrorInfo detail of gRPC errors
+const ErrorInfoDomain = "gothink"
+
+// GRPCStatus returns a gRPC status error coded by the cause of err, with the
+// code as the reason of its ErrorInfo detail, or nil when err is nil
+func GRPCStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code := CodeOf(err)
+	st := status.New(code.GRPCCode(), err.Error())
+	if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: string(code), Domain: ErrorInfoDomain}); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,142 @@
+// Package audit records every MCP tool call in a structured audit log: one
+// JSON line per call with the tool, session, tenant, arguments, duration and
+// outcome. Arguments holding thought content can be redacted for
+// privacy-sensitive deployments.
+package audit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces the value of a redacted argument
+const Redacted = "[REDACTED]"
+
+// ContentArguments are the tool arguments that carry thought content and
+// other free text, redacted when AuditRedactContent is set
+var ContentArguments = []string{
+	"thought", "problem", "issue", "context", "steps", "conclusion", "reasoning",
+	"findings", "resolution", "decision_statement", "options", "criteria",
+	"elements", "parameters", "query", "export", "thoughts", "mental_models",
+	"stochastic_algorithms", "decisions", "visual_data", "critiques", "scenarios",
+	// The calls of a batch are each audited on their own, redacted as above
+	"calls",
+}
+
+// Outcomes of a tool call
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Call describes one tool call
+type Call struct {
+	Tool      string
+	SessionID string
+	TenantID  string
+	Arguments map[string]interface{}
+	Duration  time.Duration
+	Outcome   string
+	// ErrorCode and Error describe a failed call
+	ErrorCode string
+	Error     string
+}
+
+// Logger writes tool calls to an audit log
+type Logger struct {
+	logger *logrus.Logger
+	redact map[string]bool
+	closer io.Closer
+}
+
+// New returns a logger writing to w that replaces the values of the redact
+// arguments
+func New(w io.Writer, redact []string) *Logger {
+	logger := logrus.New()
+	logger.SetOutput(w)
+	logger.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+
+	l := &Logger{logger: logger, redact: make(map[string]bool, len(redact))}
+	for _, name := range redact {
+		l.redact[name] = true
+	}
+	return l
+}
+
+// Open returns the audit logger configured by cfg, or nil when audit logging
+// is disabled. An AuditLogPath of "-" logs to stderr; any other path is a file
+// that is appended to.
+func Open(cfg *config.Config) (*Logger, error) {
+	switch cfg.AuditLogPath {
+	case "":
+		return nil, nil
+	case "-":
+		return New(os.Stderr, RedactedArguments(cfg)), nil
+	}
+
+	file, err := os.OpenFile(cfg.AuditLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l := New(file, RedactedArguments(cfg))
+	l.closer = file
+	return l, nil
+}
+
+// RedactedArguments returns the arguments cfg asks to redact
+func RedactedArguments(cfg *config.Config) []string {
+	redact := append([]string(nil), cfg.AuditRedactFields...)
+	if cfg.AuditRedactContent {
+		redact = append(redact, ContentArguments...)
+	}
+	return redact
+}
+
+// Close closes the audit log file, if any
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Record logs a tool call
+func (l *Logger) Record(call Call) {
+	if l == nil {
+		return
+	}
+
+	fields := logrus.Fields{
+		"tool":        call.Tool,
+		"session_id":  call.SessionID,
+		"arguments":   l.redacted(call.Arguments),
+		"duration_ms": float64(call.Duration.Microseconds()) / 1000,
+		"outcome":     call.Outcome,
+	}
+	if call.TenantID != "" {
+		fields["tenant_id"] = call.TenantID
+	}
+	if call.Outcome == OutcomeError {
+		fields["error_code"] = call.ErrorCode
+		fields["error"] = call.Error
+	}
+
+	l.logger.WithFields(fields).Info("tool call")
+}
+
+// redacted returns a copy of args with the redacted arguments replaced
+func (l *Logger) redacted(args map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if l.redact[name] {
+			value = Redacted
+		}
+		copied[name] = value
+	}
+	return copied
+}
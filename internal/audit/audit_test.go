@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_AppendsToConfiguredFile(t *testing.T) {
+	cfg := config.DefaultConfig()
+	logger, err := Open(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, logger, "audit logging is off by default")
+	logger.Record(Call{Tool: "ignored"})
+
+	cfg.AuditLogPath = filepath.Join(t.TempDir(), "audit.log")
+	cfg.AuditRedactFields = []string{"session_notes"}
+	for i := 0; i < 2; i++ {
+		logger, err = Open(cfg)
+		require.NoError(t, err)
+		logger.Record(Call{
+			Tool:      "sequential_thinking",
+			SessionID: "s1",
+			Arguments: map[string]interface{}{"thought": "visible", "session_notes": "secret"},
+			Duration:  1500 * time.Microsecond,
+			Outcome:   OutcomeSuccess,
+		})
+		require.NoError(t, logger.Close())
+	}
+
+	data, err := os.ReadFile(cfg.AuditLogPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"duration_ms":1.5`)
+	assert.Contains(t, lines[1], `"thought":"visible"`)
+	assert.Contains(t, lines[1], `"session_notes":"[REDACTED]"`)
+	assert.NotContains(t, lines[1], "error_code")
+}
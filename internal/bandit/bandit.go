@@ -0,0 +1,594 @@
+// Package bandit plays multi-armed bandits with the epsilon-greedy, UCB1 and
+// Thompson sampling strategies. Each arm is given as its reward distribution,
+// Bernoulli or Gaussian, or as an observed reward history that pulls resample
+// from. A run reports the pulls and rewards of every arm and the regret
+// curve: how much expected reward the strategy has lost to always pulling the
+// best arm.
+//
+// Arms whose means change during a run make the bandit non-stationary. Under
+// a drift mode the strategies go by discounted or recent rewards rather than
+// all of them, which makes UCB1 discounted UCB or sliding-window UCB, and a
+// CUSUM test on each arm's rewards reports where its mean changed.
+package bandit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+	"github.com/rainmana/gothink/internal/randdist"
+)
+
+// Strategies
+const (
+	EpsilonGreedy = "epsilon_greedy"
+	UCB1          = "ucb1"
+	Thompson      = "thompson"
+)
+
+// Reward distributions
+const (
+	Bernoulli = "bernoulli"
+	Gaussian  = "gaussian"
+	// Empirical resamples the observed rewards of an arm
+	Empirical = "empirical"
+)
+
+// Drift modes
+const (
+	// Stationary strategies go by every reward
+	Stationary = "none"
+	// Discounted strategies weigh each reward down by the discount for every
+	// pull since
+	Discounted = "discounted"
+	// SlidingWindow strategies go by the rewards of the last window pulls
+	SlidingWindow = "sliding_window"
+)
+
+// Change point directions
+const (
+	Increase = "increase"
+	Decrease = "decrease"
+)
+
+// maxCurvePoints bounds the points of a regret curve
+const maxCurvePoints = 100
+
+// The CUSUM test of change points tests an arm once it has paid out
+// changeWarmup rewards since its last change point, against its rewards
+// standardized by their mean and deviation so far. It allows a drift of
+// changeSlack deviations per reward before counting it towards a change,
+// and takes rewards within [0, 1] to deviate by at least minBoundedDeviation,
+// so a run of identical rewards does not make the next one a change.
+const (
+	changeWarmup        = 20
+	changeSlack         = 0.5
+	minBoundedDeviation = 0.1
+)
+
+// Arm is one arm of a bandit
+type Arm struct {
+	Name string
+	// Distribution is Bernoulli, Gaussian or Empirical; when empty it is
+	// Empirical if Observed holds rewards and Bernoulli otherwise
+	Distribution string
+	// Mean is the success probability of a Bernoulli arm or the mean of a
+	// Gaussian one, and StdDev the standard deviation of a Gaussian arm
+	Mean   float64
+	StdDev float64
+	// Observed holds the rewards an Empirical arm has paid out
+	Observed []float64
+	// Changes are the changes of a Bernoulli or Gaussian arm's mean during
+	// a run, by their steps in order
+	Changes []Change
+}
+
+// Change changes the mean of an arm from the pull of Step on
+type Change struct {
+	Step int
+	Mean float64
+}
+
+// Options control a run
+type Options struct {
+	Strategy string
+	// Steps is the number of pulls
+	Steps int
+	// Epsilon is the chance epsilon-greedy pulls a random arm
+	Epsilon float64
+	// Alpha and Beta are the Beta prior of Thompson sampling on rewards
+	// within [0, 1]
+	Alpha float64
+	Beta  float64
+	// DriftMode is Stationary, Discounted or SlidingWindow, Stationary when
+	// empty. Discount, within (0, 1), is the weight Discounted strategies
+	// keep of a reward for each pull after it, and Window the pulls that
+	// SlidingWindow strategies go by.
+	DriftMode string
+	Discount  float64
+	Window    int
+	// ChangeThreshold, when positive, detects change points: where the CUSUM
+	// of an arm's standardized rewards exceeds it
+	ChangeThreshold float64
+	// TimeLimit, when positive, stops the run early with the pulls so far
+	TimeLimit time.Duration
+	// Rand is the source of randomness of the strategy and the rewards
+	Rand *rand.Rand
+}
+
+// ArmStats are the pulls of one arm over a run
+type ArmStats struct {
+	Arm   int
+	Name  string
+	Pulls int
+	// Rewards is the total reward the arm paid out
+	Rewards       float64
+	AverageReward float64
+	// ExpectedReward is the mean of the arm's distribution, at the end of
+	// the run if it changed
+	ExpectedReward float64
+}
+
+// ChangePoint is a change of an arm's mean detected at Step, after it had
+// paid out Before on average since its previous change point
+type ChangePoint struct {
+	Arm       int
+	Name      string
+	Step      int
+	Direction string
+	Before    float64
+}
+
+// RegretPoint is the cumulative regret after Step pulls
+type RegretPoint struct {
+	Step   int
+	Regret float64
+}
+
+// Result is the outcome of a run
+type Result struct {
+	// Steps is the number of pulls played, fewer than Options.Steps when
+	// the run ran out of time
+	Steps int
+	Arms  []ArmStats
+	// SelectedArm is the arm with the highest average reward, the one the
+	// strategy would exploit next; under drift, the average it goes by
+	SelectedArm int
+	// OptimalArm is the arm with the highest expected reward, at the end of
+	// the run if the arms changed
+	OptimalArm  int
+	TotalReward float64
+	// Regret is the expected reward lost to not always pulling the optimal
+	// arm of the time, and RegretCurve its growth over at most 100 evenly
+	// spaced steps
+	Regret      float64
+	RegretCurve []RegretPoint
+	// ChangePoints are the changes of the arms' means detected, by step
+	ChangePoints []ChangePoint
+	// Confidence bounds from below the chance that the selected arm is the
+	// best, going by the statistics it was selected by: one less the sum,
+	// over the other arms, of a bound on the chance that an arm's mean is
+	// at least the selected arm's given the gap between their averages.
+	// When Bounded, every reward is within [0, 1] and the bound is
+	// Hoeffding's; otherwise it is the Gaussian tail bound with the arms'
+	// sample variances. An arm pulled too little to bound leaves it 0.
+	Confidence float64
+	Bounded    bool
+	// Converged reports whether the selected arm held over the last quarter
+	// of the regret curve's steps, in a run that did not run out of time.
+	// Residuals holds the regret per pull
+	// between each step of the curve and the next, which falls towards 0 as
+	// the strategy settles on the optimal arm.
+	Converged      bool
+	StoppingReason string
+	Residuals      []float64
+}
+
+// arm is an arm as a run plays it
+type arm struct {
+	distribution string
+	mean, stdDev float64
+	observed     []float64
+	changes      []Change
+	bounded      bool
+}
+
+// draw pulls a
+func (a *arm) draw(r *rand.Rand) float64 {
+	switch a.distribution {
+	case Bernoulli:
+		if r.Float64() < a.mean {
+			return 1
+		}
+		return 0
+	case Gaussian:
+		return a.mean + a.stdDev*r.NormFloat64()
+	}
+	return a.observed[r.Intn(len(a.observed))]
+}
+
+// newArm validates a and returns it as a run plays it
+func newArm(i int, a Arm) (*arm, error) {
+	played := &arm{distribution: a.Distribution, mean: a.Mean, stdDev: a.StdDev, observed: a.Observed, changes: a.Changes}
+	if played.distribution == "" {
+		played.distribution = Bernoulli
+		if len(a.Observed) > 0 {
+			played.distribution = Empirical
+		}
+	}
+
+	switch played.distribution {
+	case Bernoulli:
+		if a.Mean < 0 || a.Mean > 1 || math.IsNaN(a.Mean) {
+			return nil, fmt.Errorf("arm %d has success probability %v outside [0, 1]", i, a.Mean)
+		}
+		played.bounded = true
+	case Gaussian:
+		if math.IsNaN(a.Mean) || math.IsInf(a.Mean, 0) || a.StdDev < 0 || math.IsNaN(a.StdDev) || math.IsInf(a.StdDev, 0) {
+			return nil, fmt.Errorf("arm %d needs a finite mean and a non-negative standard deviation", i)
+		}
+	case Empirical:
+		if len(a.Observed) == 0 {
+			return nil, fmt.Errorf("arm %d has no observed rewards", i)
+		}
+		total := 0.0
+		played.bounded = true
+		for _, reward := range a.Observed {
+			if math.IsNaN(reward) || math.IsInf(reward, 0) {
+				return nil, fmt.Errorf("arm %d has an observed reward that is not finite", i)
+			}
+			total += reward
+			played.bounded = played.bounded && reward >= 0 && reward <= 1
+		}
+		played.mean = total / float64(len(a.Observed))
+	default:
+		return nil, fmt.Errorf("arm %d has unknown distribution %q", i, a.Distribution)
+	}
+
+	for j, change := range a.Changes {
+		switch {
+		case played.distribution == Empirical:
+			return nil, fmt.Errorf("arm %d resamples its observed rewards, whose mean cannot change", i)
+		case change.Step < 1 || j > 0 && change.Step <= a.Changes[j-1].Step:
+			return nil, fmt.Errorf("arm %d needs changes at positive steps in increasing order", i)
+		case played.distribution == Bernoulli && (change.Mean < 0 || change.Mean > 1 || math.IsNaN(change.Mean)):
+			return nil, fmt.Errorf("arm %d changes to success probability %v outside [0, 1]", i, change.Mean)
+		case math.IsNaN(change.Mean) || math.IsInf(change.Mean, 0):
+			return nil, fmt.Errorf("arm %d changes to a mean that is not finite", i)
+		}
+	}
+	return played, nil
+}
+
+// statistics are the pulls, rewards, squared rewards and successes of each
+// arm that a strategy goes by: all of them, or under drift the discounted or
+// recent ones
+type statistics struct {
+	pulls, rewards, squares, successes []float64
+	// total is the sum of the pulls
+	total float64
+	// recent are the pulls within a sliding window, oldest first
+	recent []pull
+}
+
+// pull is a pull of an arm and what it paid out
+type pull struct {
+	arm             int
+	reward, success float64
+}
+
+// add counts a pull of arm under opts' drift mode
+func (s *statistics) add(opts Options, arm int, reward, success float64) {
+	if opts.DriftMode == Discounted {
+		for i := range s.pulls {
+			s.pulls[i] *= opts.Discount
+			s.rewards[i] *= opts.Discount
+			s.squares[i] *= opts.Discount
+			s.successes[i] *= opts.Discount
+		}
+		s.total *= opts.Discount
+	}
+	s.pulls[arm]++
+	s.rewards[arm] += reward
+	s.squares[arm] += reward * reward
+	s.successes[arm] += success
+	s.total++
+
+	if opts.DriftMode == SlidingWindow {
+		s.recent = append(s.recent, pull{arm: arm, reward: reward, success: success})
+		if len(s.recent) > opts.Window {
+			oldest := s.recent[0]
+			s.recent = s.recent[1:]
+			s.pulls[oldest.arm]--
+			s.rewards[oldest.arm] -= oldest.reward
+			s.squares[oldest.arm] -= oldest.reward * oldest.reward
+			s.successes[oldest.arm] -= oldest.success
+			s.total--
+		}
+	}
+}
+
+// detector is the CUSUM test of change points of one arm, over its rewards
+// since its last change point
+type detector struct {
+	n                  int
+	mean, m2           float64
+	increase, decrease float64
+}
+
+// observe adds a reward, returning the direction of the change it reveals,
+// if any, and the average reward before it. After a change the test starts
+// over from the reward.
+func (d *detector) observe(reward, threshold float64, bounded bool) (string, float64) {
+	direction := ""
+	if d.n >= changeWarmup {
+		deviation := math.Sqrt(d.m2 / float64(d.n-1))
+		if bounded {
+			deviation = math.Max(deviation, minBoundedDeviation)
+		}
+		deviation = math.Max(deviation, 1e-9*math.Max(1, math.Abs(d.mean)))
+		z := (reward - d.mean) / deviation
+		d.increase = math.Max(0, d.increase+z-changeSlack)
+		d.decrease = math.Max(0, d.decrease-z-changeSlack)
+		switch {
+		case d.increase > threshold:
+			direction = Increase
+		case d.decrease > threshold:
+			direction = Decrease
+		}
+	}
+	before := d.mean
+	if direction != "" {
+		*d = detector{}
+	}
+
+	// Welford's update of the mean and the sum of squared deviations
+	d.n++
+	delta := reward - d.mean
+	d.mean += delta / float64(d.n)
+	d.m2 += delta * (reward - d.mean)
+	return direction, before
+}
+
+// Run plays the bandit of arms for opts.Steps pulls with opts.Strategy. It
+// returns ctx's error if ctx ends first.
+func Run(ctx context.Context, arms []Arm, opts Options) (*Result, error) {
+	switch {
+	case len(arms) == 0:
+		return nil, errors.New("the bandit has no arms")
+	case opts.Steps <= 0:
+		return nil, errors.New("steps must be positive")
+	case opts.Epsilon < 0 || opts.Epsilon > 1 || math.IsNaN(opts.Epsilon):
+		return nil, fmt.Errorf("epsilon %v is outside [0, 1]", opts.Epsilon)
+	case opts.Alpha <= 0 || opts.Beta <= 0:
+		return nil, errors.New("the Beta prior must be positive")
+	case opts.DriftMode == Discounted && !(opts.Discount > 0 && opts.Discount < 1):
+		return nil, fmt.Errorf("discount %v is outside (0, 1)", opts.Discount)
+	case opts.DriftMode == SlidingWindow && opts.Window <= 0:
+		return nil, errors.New("the window must be positive")
+	case opts.DriftMode != "" && opts.DriftMode != Stationary && opts.DriftMode != Discounted && opts.DriftMode != SlidingWindow:
+		return nil, fmt.Errorf("unknown drift mode %q", opts.DriftMode)
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+
+	played := make([]*arm, len(arms))
+	bounded := true
+	for i, a := range arms {
+		var err error
+		if played[i], err = newArm(i, a); err != nil {
+			return nil, err
+		}
+		bounded = bounded && played[i].bounded
+	}
+
+	// Strategies choose by the statistics of the drift mode, so UCB1 under
+	// drift is discounted or sliding-window UCB, its confidence growing with
+	// the log of the pulls it goes by
+	var choose func(s *statistics) int
+	switch opts.Strategy {
+	case EpsilonGreedy:
+		choose = func(s *statistics) int {
+			if opts.Rand.Float64() < opts.Epsilon {
+				return opts.Rand.Intn(len(s.pulls))
+			}
+			return highest(len(s.pulls), func(i int) float64 { return average(s.pulls[i], s.rewards[i]) })
+		}
+	case UCB1:
+		choose = func(s *statistics) int {
+			return highest(len(s.pulls), func(i int) float64 {
+				if s.pulls[i] == 0 {
+					return math.Inf(1)
+				}
+				return s.rewards[i]/s.pulls[i] + math.Sqrt(2*math.Log(s.total+1)/s.pulls[i])
+			})
+		}
+	case Thompson:
+		choose = func(s *statistics) int {
+			return highest(len(s.pulls), func(i int) float64 {
+				if bounded {
+					return randdist.Beta(opts.Rand, opts.Alpha+s.successes[i], opts.Beta+s.pulls[i]-s.successes[i])
+				}
+				// Unbounded rewards get a Gaussian posterior instead
+				return average(s.pulls[i], s.rewards[i]) + opts.Rand.NormFloat64()/math.Sqrt(s.pulls[i]+1)
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", opts.Strategy)
+	}
+
+	result := &Result{Arms: make([]ArmStats, len(arms)), StoppingReason: convergence.MaxIterations}
+	optimal := func() {
+		result.OptimalArm = highest(len(played), func(i int) float64 { return played[i].mean })
+	}
+	optimal()
+
+	pulls := make([]int, len(arms))
+	rewards := make([]float64, len(arms))
+	stats := &statistics{
+		pulls:     make([]float64, len(arms)),
+		rewards:   make([]float64, len(arms)),
+		squares:   make([]float64, len(arms)),
+		successes: make([]float64, len(arms)),
+	}
+	detectors := make([]detector, len(arms))
+	selected := func() int {
+		return highest(len(arms), func(i int) float64 {
+			if stats.pulls[i] == 0 {
+				return math.Inf(-1)
+			}
+			return average(stats.pulls[i], stats.rewards[i])
+		})
+	}
+	interval := max(1, (opts.Steps+maxCurvePoints-1)/maxCurvePoints)
+	held := 0
+	checkpoint := func(step int) {
+		if n := len(result.RegretCurve); n > 0 {
+			previous := result.RegretCurve[n-1]
+			result.Residuals = append(result.Residuals, (result.Regret-previous.Regret)/float64(step-previous.Step))
+			if selected() == result.SelectedArm {
+				held++
+			} else {
+				held = 0
+			}
+		}
+		result.SelectedArm = selected()
+		result.RegretCurve = append(result.RegretCurve, RegretPoint{Step: step, Regret: result.Regret})
+	}
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+	for step := 1; step <= opts.Steps; step++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// A run pulls at least once, so that it has an arm to select
+		if step > 1 && deadline.Passed() {
+			result.StoppingReason = convergence.TimeLimit
+			break
+		}
+
+		// Arms change their means from the pull of a change's step on
+		changed := false
+		for _, a := range played {
+			for len(a.changes) > 0 && a.changes[0].Step <= step {
+				a.mean, a.changes = a.changes[0].Mean, a.changes[1:]
+				changed = true
+			}
+		}
+		if changed {
+			optimal()
+		}
+
+		i := choose(stats)
+		reward := played[i].draw(opts.Rand)
+		pulls[i]++
+		rewards[i] += reward
+		success := 0.0
+		if opts.Strategy == Thompson && bounded {
+			// Rewards within [0, 1] count as a success with that chance, so
+			// the Beta posterior applies to any of them
+			if opts.Rand.Float64() < reward {
+				success = 1
+			}
+		}
+		stats.add(opts, i, reward, success)
+		if opts.ChangeThreshold > 0 {
+			if direction, before := detectors[i].observe(reward, opts.ChangeThreshold, played[i].bounded); direction != "" {
+				result.ChangePoints = append(result.ChangePoints, ChangePoint{Arm: i, Name: arms[i].Name, Step: step, Direction: direction, Before: before})
+			}
+		}
+		result.TotalReward += reward
+		result.Regret += played[result.OptimalArm].mean - played[i].mean
+		result.Steps = step
+
+		if step%interval == 0 || step == opts.Steps {
+			checkpoint(step)
+		}
+	}
+	if n := len(result.RegretCurve); n == 0 || result.RegretCurve[n-1].Step < result.Steps {
+		// The run ran out of time between checkpoints
+		checkpoint(result.Steps)
+	}
+
+	for i, a := range arms {
+		result.Arms[i] = ArmStats{
+			Arm:            i,
+			Name:           a.Name,
+			Pulls:          pulls[i],
+			Rewards:        rewards[i],
+			AverageReward:  average(float64(pulls[i]), rewards[i]),
+			ExpectedReward: played[i].mean,
+		}
+	}
+	result.Bounded = bounded
+	result.Confidence = stats.confidence(result.SelectedArm, bounded)
+	points := len(result.RegretCurve)
+	result.Converged = result.StoppingReason != convergence.TimeLimit && points >= 4 && held >= points/4
+	return result, nil
+}
+
+// confidence returns one less the sum, over the arms other than selected, of
+// a bound on the chance that the arm's mean is at least selected's given the
+// gap between their averages: Hoeffding's for bounded rewards, and otherwise
+// the Gaussian tail bound with the arms' sample variances
+func (s *statistics) confidence(selected int, bounded bool) float64 {
+	variance := func(i int) float64 {
+		if bounded {
+			// The variance of a reward within [0, 1] is at most 1/4
+			return 0.25 / s.pulls[i]
+		}
+		mean := s.rewards[i] / s.pulls[i]
+		return math.Max(0, s.squares[i]/s.pulls[i]-mean*mean) / (s.pulls[i] - 1)
+	}
+	enough := func(i int) bool {
+		if bounded {
+			return s.pulls[i] > 0
+		}
+		return s.pulls[i] > 1
+	}
+	if !enough(selected) {
+		return 0
+	}
+
+	doubt := 0.0
+	for i := range s.pulls {
+		if i == selected {
+			continue
+		}
+		if !enough(i) {
+			return 0
+		}
+		gap := average(s.pulls[selected], s.rewards[selected]) - average(s.pulls[i], s.rewards[i])
+		spread := variance(selected) + variance(i)
+		switch {
+		case gap <= 0:
+			doubt++
+		case spread > 0:
+			doubt += math.Exp(-gap * gap / (2 * spread))
+		}
+	}
+	return math.Max(0, 1-doubt)
+}
+
+// average returns the average reward of an arm, or 0 if it was never pulled
+func average(pulls, rewards float64) float64 {
+	if pulls == 0 {
+		return 0
+	}
+	return rewards / pulls
+}
+
+// highest returns the index of the highest of n scores, preferring the first
+// on ties
+func highest(n int, score func(int) float64) int {
+	best, bestScore := 0, math.Inf(-1)
+	for i := 0; i < n; i++ {
+		if s := score(i); s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return best
+}
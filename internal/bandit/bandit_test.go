@@ -0,0 +1,206 @@
+package bandit
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ads are three Bernoulli arms, the last clicked most often
+var ads = []Arm{
+	{Name: "banner", Mean: 0.1},
+	{Name: "video", Mean: 0.3},
+	{Name: "native", Mean: 0.6},
+}
+
+func run(t *testing.T, arms []Arm, strategy string) *Result {
+	t.Helper()
+	result, err := Run(context.Background(), arms, Options{
+		Strategy: strategy,
+		Steps:    2000,
+		Epsilon:  0.1,
+		Alpha:    1,
+		Beta:     1,
+		Rand:     rand.New(rand.NewSource(1)),
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestRun_FindsTheBestArm(t *testing.T) {
+	for _, strategy := range []string{EpsilonGreedy, UCB1, Thompson} {
+		t.Run(strategy, func(t *testing.T) {
+			result := run(t, ads, strategy)
+			assert.Equal(t, 2, result.OptimalArm)
+			assert.Equal(t, 2, result.SelectedArm)
+			assert.Greater(t, result.Arms[2].Pulls, 1000)
+			assert.Equal(t, 0.6, result.Arms[2].ExpectedReward)
+			assert.InDelta(t, 0.6, result.Arms[2].AverageReward, 0.05)
+
+			pulls, rewards := 0, 0.0
+			for _, arm := range result.Arms {
+				pulls += arm.Pulls
+				rewards += arm.Rewards
+			}
+			assert.Equal(t, 2000, pulls)
+			assert.Equal(t, rewards, result.TotalReward)
+
+			// Regret grows ever slower as the best arm takes over
+			require.Len(t, result.RegretCurve, 100)
+			assert.Equal(t, RegretPoint{Step: 2000, Regret: result.Regret}, result.RegretCurve[99])
+			assert.Greater(t, result.Regret, 0.0)
+			assert.Less(t, result.Regret, 0.1*2000)
+			early := result.RegretCurve[9].Regret
+			late := result.Regret - result.RegretCurve[89].Regret
+			assert.Greater(t, early, late)
+
+			// The gap to the other arms is too wide to be chance
+			assert.True(t, result.Bounded)
+			assert.Greater(t, result.Confidence, 0.95)
+		})
+	}
+}
+
+func TestRun_BoundsItsConfidenceInTheSelectedArm(t *testing.T) {
+	// Arms a hundredth apart cannot be told apart in 2000 pulls
+	near := run(t, []Arm{{Name: "a", Mean: 0.5}, {Name: "b", Mean: 0.51}}, UCB1)
+	assert.Less(t, near.Confidence, 0.5)
+
+	// Unbounded rewards are bounded by their sample variances
+	gaussian := run(t, []Arm{
+		{Name: "low", Distribution: Gaussian, Mean: 0, StdDev: 1},
+		{Name: "high", Distribution: Gaussian, Mean: 2, StdDev: 1},
+	}, EpsilonGreedy)
+	assert.False(t, gaussian.Bounded)
+	assert.Equal(t, 1, gaussian.SelectedArm)
+	assert.Greater(t, gaussian.Confidence, 0.95)
+
+	// An arm never pulled cannot be ruled out
+	once, err := Run(context.Background(), ads, Options{Strategy: UCB1, Steps: 2, Alpha: 1, Beta: 1, Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+	assert.Zero(t, once.Confidence)
+}
+
+func TestRun_PlaysObservedAndGaussianArms(t *testing.T) {
+	// Unbounded rewards switch Thompson sampling to a Gaussian posterior
+	result := run(t, []Arm{
+		{Name: "observed", Observed: []float64{2, 4, 3}},
+		{Name: "gaussian", Distribution: Gaussian, Mean: 5, StdDev: 1},
+	}, Thompson)
+	assert.Equal(t, 3.0, result.Arms[0].ExpectedReward)
+	assert.Equal(t, 1, result.OptimalArm)
+	assert.Equal(t, 1, result.SelectedArm)
+	assert.Greater(t, result.Arms[1].Pulls, 1900)
+}
+
+func TestRun_TracksArmsThatChange(t *testing.T) {
+	// The best arm turns worst halfway through
+	arms := []Arm{
+		{Name: "incumbent", Mean: 0.8, Changes: []Change{{Step: 5000, Mean: 0.2}}},
+		{Name: "challenger", Mean: 0.4, Changes: []Change{{Step: 5000, Mean: 0.6}}},
+	}
+	play := func(driftMode string) *Result {
+		result, err := Run(context.Background(), arms, Options{
+			Strategy:        UCB1,
+			Steps:           10000,
+			DriftMode:       driftMode,
+			Discount:        0.995,
+			Window:          400,
+			Alpha:           1,
+			Beta:            1,
+			ChangeThreshold: 12,
+			Rand:            rand.New(rand.NewSource(1)),
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	// Plain UCB1 trusts the incumbent's long record
+	stationary := play(Stationary)
+	assert.Equal(t, 1, stationary.OptimalArm)
+	assert.Equal(t, 0.2, stationary.Arms[0].ExpectedReward)
+	assert.Equal(t, 0, stationary.SelectedArm)
+	for _, driftMode := range []string{Discounted, SlidingWindow} {
+		t.Run(driftMode, func(t *testing.T) {
+			result := play(driftMode)
+			assert.Equal(t, 1, result.SelectedArm)
+			assert.Less(t, result.Regret, stationary.Regret)
+		})
+	}
+
+	// The incumbent's fall is detected soon after it happens, and nothing
+	// else is
+	require.Len(t, stationary.ChangePoints, 1)
+	change := stationary.ChangePoints[0]
+	assert.Equal(t, 0, change.Arm)
+	assert.Equal(t, "incumbent", change.Name)
+	assert.Equal(t, Decrease, change.Direction)
+	assert.InDelta(t, 0.8, change.Before, 0.02)
+	assert.GreaterOrEqual(t, change.Step, 5000)
+	assert.Less(t, change.Step, 5100)
+}
+
+func TestRun_StopsAtTheTimeLimit(t *testing.T) {
+	result, err := Run(context.Background(), ads, Options{
+		Strategy:  UCB1,
+		Steps:     100000000,
+		Alpha:     1,
+		Beta:      1,
+		TimeLimit: time.Millisecond,
+		Rand:      rand.New(rand.NewSource(1)),
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Converged)
+	assert.Equal(t, "time_limit", result.StoppingReason)
+	assert.Less(t, result.Steps, 100000000)
+
+	pulls := 0
+	for _, arm := range result.Arms {
+		pulls += arm.Pulls
+	}
+	assert.Equal(t, result.Steps, pulls)
+	// The curve ends at the last pull played
+	assert.Equal(t, result.Steps, result.RegretCurve[len(result.RegretCurve)-1].Step)
+}
+
+func TestRun_RejectsInvalidBandits(t *testing.T) {
+	opts := Options{Strategy: UCB1, Steps: 10, Alpha: 1, Beta: 1, Rand: rand.New(rand.NewSource(1))}
+	for name, arms := range map[string][]Arm{
+		"no arms":             nil,
+		"probability":         {{Mean: 1.5}},
+		"no observed reward":  {{Distribution: Empirical}},
+		"negative deviation":  {{Distribution: Gaussian, StdDev: -1}},
+		"unknown":             {{Distribution: "poisson"}},
+		"changing observed":   {{Observed: []float64{1}, Changes: []Change{{Step: 5, Mean: 1}}}},
+		"unordered changes":   {{Mean: 0.5, Changes: []Change{{Step: 5, Mean: 1}, {Step: 5, Mean: 0}}}},
+		"changed probability": {{Mean: 0.5, Changes: []Change{{Step: 5, Mean: 2}}}},
+	} {
+		_, err := Run(context.Background(), arms, opts)
+		assert.Error(t, err, name)
+	}
+
+	for _, drift := range []Options{
+		{DriftMode: Discounted, Discount: 1},
+		{DriftMode: SlidingWindow},
+		{DriftMode: "forgetful"},
+	} {
+		drifting := opts
+		drifting.DriftMode, drifting.Discount, drifting.Window = drift.DriftMode, drift.Discount, drift.Window
+		_, err := Run(context.Background(), ads, drifting)
+		assert.Error(t, err, drift.DriftMode)
+	}
+
+	opts.Strategy = "softmax"
+	_, err := Run(context.Background(), ads, opts)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts.Strategy = Thompson
+	_, err = Run(ctx, ads, opts)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,380 @@
+// Package bayesopt optimizes objectives by Bayesian optimization with a
+// Gaussian-process surrogate. The search space is a box of bounded
+// parameters; the objective is a function of them, such as a compiled
+// expression, or is known only through observed evaluations. Each iteration fits the Gaussian
+// process to the evaluations so far and evaluates the objective where an
+// acquisition function (expected improvement, probability of improvement or
+// upper confidence bound) is highest. A run reports the best point with the
+// posterior mean and variance there, and suggests the next point to evaluate.
+package bayesopt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+)
+
+// Acquisition functions
+const (
+	ExpectedImprovement      = "ei"
+	UpperConfidenceBound     = "ucb"
+	ProbabilityOfImprovement = "pi"
+)
+
+// perturbation is the standard deviation, relative to each parameter's
+// range, of the candidates drawn around the best point
+const perturbation = 0.1
+
+// significantImprovement is the improvement on the best value, in standard
+// deviations of the evaluations, that a run's confidence rules out
+const significantImprovement = 0.1
+
+// Parameter is one dimension of the search space
+type Parameter struct {
+	Name string
+	Min  float64
+	Max  float64
+}
+
+// Objective evaluates the objective at a point of the search space
+type Objective func(point map[string]float64) (float64, error)
+
+// Observation is an evaluation of the objective
+type Observation struct {
+	Parameters map[string]float64
+	Value      float64
+}
+
+// Options control a run
+type Options struct {
+	Kernel      string
+	Acquisition string
+	// ExplorationWeight is the improvement margin of expected improvement and
+	// probability of improvement, or the weight of the standard deviation in
+	// the upper confidence bound, in units of the observed values' standard
+	// deviation
+	ExplorationWeight float64
+	// LengthScale is the kernel's length scale relative to each parameter's
+	// range, and Noise the variance of the observations relative to theirs
+	LengthScale float64
+	Noise       float64
+	// Minimize seeks the lowest value rather than the highest
+	Minimize bool
+	// Iterations is the number of evaluations of the objective. The first
+	// evaluations sample random points until there are InitialPoints
+	// observations.
+	Iterations    int
+	InitialPoints int
+	// Candidates is the number of random points the acquisition function is
+	// maximized over
+	Candidates int
+	// The run has converged once the best value improves by at most
+	// Tolerance over Patience evaluations of the objective; StopAtPlateau
+	// stops it there rather than running every iteration
+	Tolerance     float64
+	Patience      int
+	StopAtPlateau bool
+	// TimeLimit, when positive, stops the run early with the best point
+	// evaluated so far
+	TimeLimit time.Duration
+	// Progress, when set, receives the result so far every ProgressInterval
+	// evaluations, without a posterior or a next point
+	ProgressInterval int
+	Progress         func(*Result)
+	// Rand is the source of randomness of the points sampled
+	Rand *rand.Rand
+}
+
+// Posterior is the Gaussian process's belief about the objective at a point
+type Posterior struct {
+	Mean     float64
+	Variance float64
+}
+
+// Step is one evaluation of the objective
+type Step struct {
+	Iteration  int
+	Parameters map[string]float64
+	Value      float64
+	// Random reports a point sampled before the Gaussian process took over;
+	// other points maximize the acquisition function, with the posterior
+	// predicted there before evaluating
+	Random      bool
+	Acquisition float64
+	Predicted   Posterior
+}
+
+// Result is the outcome of a run
+type Result struct {
+	// BestParameters is the best point evaluated, counting the observed
+	// evaluations, and BestValue its value
+	BestParameters map[string]float64
+	BestValue      float64
+	// BestPosterior is the posterior at the best point given every evaluation
+	BestPosterior Posterior
+	History       []Step
+	// Next is the point the acquisition function would evaluate next
+	Next            map[string]float64
+	NextAcquisition float64
+	NextPosterior   Posterior
+	// Confidence is one less the highest chance, under the posterior given
+	// every evaluation, that a candidate point improves on the best value by
+	// a tenth of the evaluations' standard deviation
+	Confidence float64
+	// Converged reports whether the best value reached a plateau, in a run
+	// that did not run out of time, and Residuals holds how much each
+	// evaluation improved it. A run that only fits observed evaluations stops
+	// as Exact.
+	Converged      bool
+	StoppingReason string
+	Residuals      []float64
+}
+
+// space maps points between the search space and the unit cube
+type space []Parameter
+
+// toUnit returns point scaled into the unit cube
+func (s space) toUnit(point map[string]float64) []float64 {
+	x := make([]float64, len(s))
+	for i, p := range s {
+		x[i] = (point[p.Name] - p.Min) / (p.Max - p.Min)
+	}
+	return x
+}
+
+// fromUnit returns the point of the search space at x
+func (s space) fromUnit(x []float64) map[string]float64 {
+	point := make(map[string]float64, len(s))
+	for i, p := range s {
+		point[p.Name] = p.Min + x[i]*(p.Max-p.Min)
+	}
+	return point
+}
+
+// Optimize runs opts.Iterations evaluations of objective over parameters,
+// starting from the evaluations observed. With a nil objective it only fits
+// the observed evaluations and suggests the next point. It returns ctx's
+// error if ctx ends first.
+func Optimize(ctx context.Context, parameters []Parameter, observed []Observation, objective Objective, opts Options) (*Result, error) {
+	k, err := kernelByName(opts.Kernel)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(parameters) == 0:
+		return nil, errors.New("the search space has no parameters")
+	case opts.Acquisition != ExpectedImprovement && opts.Acquisition != UpperConfidenceBound && opts.Acquisition != ProbabilityOfImprovement:
+		return nil, fmt.Errorf("unknown acquisition function %q", opts.Acquisition)
+	case opts.ExplorationWeight < 0 || math.IsNaN(opts.ExplorationWeight):
+		return nil, errors.New("the exploration weight must not be negative")
+	case opts.LengthScale <= 0 || opts.Noise <= 0:
+		return nil, errors.New("the length scale and noise must be positive")
+	case opts.Iterations < 0 || opts.InitialPoints < 0 || opts.Candidates <= 0:
+		return nil, errors.New("iterations and initial points must not be negative, and candidates must be positive")
+	case opts.Tolerance < 0 || opts.Patience < 0:
+		return nil, errors.New("the tolerance and patience must not be negative")
+	case len(observed) == 0 && (objective == nil || opts.Iterations == 0):
+		return nil, errors.New("there are neither observed evaluations nor evaluations of an objective to run")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+
+	s := space(parameters)
+	seen := make(map[string]bool, len(parameters))
+	for _, p := range parameters {
+		switch {
+		case p.Name == "" || seen[p.Name]:
+			return nil, errors.New("parameters must have distinct names")
+		case math.IsInf(p.Min, 0) || math.IsInf(p.Max, 0) || !(p.Min < p.Max):
+			return nil, fmt.Errorf("parameter %s needs finite bounds with min below max", p.Name)
+		}
+		seen[p.Name] = true
+	}
+
+	// The Gaussian process always maximizes, so minimizing flips the values
+	sign := 1.0
+	if opts.Minimize {
+		sign = -1
+	}
+
+	var x [][]float64
+	var y []float64
+	result := &Result{}
+	record := func(point map[string]float64, value float64) {
+		x = append(x, s.toUnit(point))
+		y = append(y, sign*value)
+		if len(y) == 1 || sign*value > sign*result.BestValue {
+			result.BestParameters, result.BestValue = point, value
+		}
+	}
+	for i, o := range observed {
+		if math.IsNaN(o.Value) || math.IsInf(o.Value, 0) {
+			return nil, fmt.Errorf("observation %d has no finite value", i)
+		}
+		if len(o.Parameters) != len(parameters) {
+			return nil, fmt.Errorf("observation %d must set exactly the parameters of the search space", i)
+		}
+		for _, p := range parameters {
+			v, ok := o.Parameters[p.Name]
+			if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("observation %d has no finite %s", i, p.Name)
+			}
+		}
+		record(o.Parameters, o.Value)
+	}
+
+	// best holds the best value, as the Gaussian process sees it, after each
+	// evaluation of the objective
+	var best []float64
+	if len(y) > 0 {
+		best = append(best, sign*result.BestValue)
+	}
+	result.StoppingReason = convergence.Exact
+	if objective != nil && opts.Iterations > 0 {
+		result.StoppingReason = convergence.MaxIterations
+	}
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+	for iteration := 1; objective != nil && iteration <= opts.Iterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// A run evaluates at least once, so that it has a best point
+		if len(y) > 0 && deadline.Passed() {
+			result.Converged = false
+			result.StoppingReason = convergence.TimeLimit
+			break
+		}
+
+		step := Step{Iteration: iteration}
+		var point []float64
+		if len(y) < max(1, opts.InitialPoints) {
+			step.Random = true
+			point = make([]float64, len(parameters))
+			for i := range point {
+				point[i] = opts.Rand.Float64()
+			}
+		} else {
+			g, err := fit(k, opts.LengthScale, opts.Noise, x, y)
+			if err != nil {
+				return nil, err
+			}
+			var mean, variance float64
+			point, step.Acquisition, mean, variance, _ = g.maximize(s.toUnit(result.BestParameters), y, opts)
+			step.Predicted = g.posterior(mean, variance, sign)
+		}
+
+		step.Parameters = s.fromUnit(point)
+		if step.Value, err = objective(step.Parameters); err != nil {
+			return nil, err
+		}
+		record(step.Parameters, step.Value)
+		result.History = append(result.History, step)
+
+		if len(best) > 0 {
+			result.Residuals = append(result.Residuals, sign*result.BestValue-best[len(best)-1])
+		}
+		best = append(best, sign*result.BestValue)
+		// Random points do not count towards a plateau
+		if !step.Random && convergence.Plateaued(best, opts.Patience, opts.Tolerance) {
+			result.Converged = true
+			if opts.StopAtPlateau {
+				result.StoppingReason = convergence.Plateau
+				break
+			}
+		} else {
+			result.Converged = false
+		}
+		if opts.Progress != nil && opts.ProgressInterval > 0 && iteration%opts.ProgressInterval == 0 && iteration < opts.Iterations {
+			snapshot := *result
+			snapshot.History = append([]Step(nil), result.History...)
+			snapshot.Residuals = append([]float64(nil), result.Residuals...)
+			opts.Progress(&snapshot)
+		}
+	}
+	if result.StoppingReason == convergence.Exact {
+		result.Converged = true
+	}
+
+	g, err := fit(k, opts.LengthScale, opts.Noise, x, y)
+	if err != nil {
+		return nil, err
+	}
+	mean, variance := g.predict(s.toUnit(result.BestParameters))
+	result.BestPosterior = g.posterior(mean, variance, sign)
+
+	next, acquisition, mean, variance, improvement := g.maximize(s.toUnit(result.BestParameters), y, opts)
+	result.Next = s.fromUnit(next)
+	result.NextAcquisition = acquisition
+	result.NextPosterior = g.posterior(mean, variance, sign)
+	result.Confidence = 1 - improvement
+	return result, nil
+}
+
+// maximize returns the candidate point with the highest acquisition, its
+// acquisition and the standardized posterior there, and the highest chance
+// of a candidate improving significantly on the best value. Half of the
+// candidates are uniform over the unit cube and half are drawn around best.
+func (g *gp) maximize(best []float64, y []float64, opts Options) ([]float64, float64, float64, float64, float64) {
+	incumbent := math.Inf(-1)
+	for _, v := range y {
+		incumbent = math.Max(incumbent, (v-g.mean)/g.scale)
+	}
+
+	var bestPoint []float64
+	bestAcquisition, bestMean, bestVariance, improvement := math.Inf(-1), 0.0, 0.0, 0.0
+	for c := 0; c < opts.Candidates; c++ {
+		point := make([]float64, len(best))
+		for i := range point {
+			if c%2 == 0 {
+				point[i] = opts.Rand.Float64()
+			} else {
+				point[i] = math.Min(1, math.Max(0, best[i]+perturbation*opts.Rand.NormFloat64()))
+			}
+		}
+
+		mean, variance := g.predict(point)
+		improvement = math.Max(improvement, acquire(ProbabilityOfImprovement, mean, variance, incumbent, significantImprovement))
+		if a := acquire(opts.Acquisition, mean, variance, incumbent, opts.ExplorationWeight); a > bestAcquisition {
+			bestPoint, bestAcquisition, bestMean, bestVariance = point, a, mean, variance
+		}
+	}
+	return bestPoint, bestAcquisition, bestMean, bestVariance, improvement
+}
+
+// posterior returns the standardized posterior mean and variance in the
+// objective's units
+func (g *gp) posterior(mean, variance, sign float64) Posterior {
+	return Posterior{Mean: sign * (mean*g.scale + g.mean), Variance: variance * g.scale * g.scale}
+}
+
+// acquire returns the acquisition at a point with posterior mean and
+// variance, given the best value so far
+func acquire(acquisition string, mean, variance, incumbent, weight float64) float64 {
+	sd := math.Sqrt(variance)
+	if acquisition == UpperConfidenceBound {
+		return mean + weight*sd
+	}
+
+	improvement := mean - incumbent - weight
+	if sd == 0 {
+		if acquisition == ProbabilityOfImprovement {
+			if improvement > 0 {
+				return 1
+			}
+			return 0
+		}
+		return math.Max(improvement, 0)
+	}
+	z := improvement / sd
+	cdf := 0.5 * math.Erfc(-z/math.Sqrt2)
+	if acquisition == ProbabilityOfImprovement {
+		return cdf
+	}
+	pdf := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+	return improvement*cdf + sd*pdf
+}
@@ -0,0 +1,188 @@
+package bayesopt
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var unitSquare = []Parameter{{Name: "x", Min: -2, Max: 2}, {Name: "y", Min: -2, Max: 2}}
+
+// parse compiles an objective over the unit square's parameters
+func parse(t *testing.T, expression string) Objective {
+	compiled, err := expr.Compile(expression, []string{"x", "y"})
+	require.NoError(t, err)
+	return Objective(compiled)
+}
+
+func options(kernel, acquisition string, weight float64) Options {
+	return Options{
+		Kernel:            kernel,
+		Acquisition:       acquisition,
+		ExplorationWeight: weight,
+		LengthScale:       0.2,
+		Noise:             1e-6,
+		Iterations:        25,
+		InitialPoints:     4,
+		Candidates:        500,
+		Rand:              rand.New(rand.NewSource(1)),
+	}
+}
+
+func TestOptimize_FindsTheMaximum(t *testing.T) {
+	objective := parse(t, "-(pow(x - 0.5, 2) + pow(y + 1, 2))")
+
+	for _, tc := range []struct {
+		kernel, acquisition string
+		weight              float64
+	}{
+		{Matern52, ExpectedImprovement, 0.01},
+		{RBF, UpperConfidenceBound, 2},
+		{Matern32, ProbabilityOfImprovement, 0.01},
+	} {
+		t.Run(tc.kernel+"/"+tc.acquisition, func(t *testing.T) {
+			result, err := Optimize(context.Background(), unitSquare, nil, objective, options(tc.kernel, tc.acquisition, tc.weight))
+			require.NoError(t, err)
+			require.Len(t, result.History, 25)
+			assert.True(t, result.History[3].Random)
+			assert.False(t, result.History[4].Random)
+
+			assert.Greater(t, result.BestValue, -0.05)
+			assert.InDelta(t, 0.5, result.BestParameters["x"], 0.25)
+			assert.InDelta(t, -1, result.BestParameters["y"], 0.25)
+			// The posterior all but interpolates the evaluations
+			assert.InDelta(t, result.BestValue, result.BestPosterior.Mean, 1e-2)
+			assert.Less(t, result.BestPosterior.Variance, 1e-3)
+			assert.Contains(t, result.Next, "x")
+		})
+	}
+}
+
+func TestOptimize_GrowsConfidentAsEvaluationsPinDownTheMaximum(t *testing.T) {
+	objective := parse(t, "-(pow(x - 0.5, 2) + pow(y + 1, 2))")
+	confidence := func(iterations int) float64 {
+		opts := options(Matern52, ExpectedImprovement, 0.01)
+		opts.Iterations = iterations
+		result, err := Optimize(context.Background(), unitSquare, nil, objective, opts)
+		require.NoError(t, err)
+		return result.Confidence
+	}
+
+	// A few evaluations leave much of the square able to beat the best
+	early, late := confidence(8), confidence(60)
+	assert.Less(t, early, 0.8)
+	assert.Greater(t, late, early)
+	assert.Greater(t, late, 0.95)
+}
+
+func TestOptimize_MinimizesObservedHistory(t *testing.T) {
+	parameters := []Parameter{{Name: "rate", Min: 0, Max: 1}}
+	observed := []Observation{
+		{Parameters: map[string]float64{"rate": 0.1}, Value: 5},
+		{Parameters: map[string]float64{"rate": 0.4}, Value: 2},
+		{Parameters: map[string]float64{"rate": 0.9}, Value: 7},
+	}
+	opts := options(Matern52, ExpectedImprovement, 0.01)
+	opts.Minimize = true
+	result, err := Optimize(context.Background(), parameters, observed, nil, opts)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.History)
+	assert.True(t, result.Converged)
+	assert.Equal(t, "exact", result.StoppingReason)
+	assert.Equal(t, 2.0, result.BestValue)
+	assert.Equal(t, 0.4, result.BestParameters["rate"])
+	assert.InDelta(t, 2, result.BestPosterior.Mean, 1e-2)
+	// The suggestion explores near the lowest observation, where the
+	// posterior is uncertain
+	assert.InDelta(t, 0.4, result.Next["rate"], 0.3)
+	assert.Greater(t, result.NextPosterior.Variance, 0.0)
+	assert.Greater(t, result.NextAcquisition, 0.0)
+}
+
+func TestOptimize_StopsAtPlateau(t *testing.T) {
+	objective := parse(t, "min(x, 0.5)")
+
+	opts := options(Matern52, ExpectedImprovement, 0.01)
+	opts.Tolerance, opts.Patience, opts.StopAtPlateau = 1e-9, 4, true
+	result, err := Optimize(context.Background(), unitSquare, nil, objective, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.5, result.BestValue)
+	assert.True(t, result.Converged)
+	assert.Equal(t, "plateau", result.StoppingReason)
+	assert.Less(t, len(result.History), 25)
+	assert.Len(t, result.Residuals, len(result.History)-1)
+
+	// Without a patience the run goes on and cannot tell it converged
+	opts = options(Matern52, ExpectedImprovement, 0.01)
+	result, err = Optimize(context.Background(), unitSquare, nil, objective, opts)
+	require.NoError(t, err)
+	assert.Len(t, result.History, 25)
+	assert.False(t, result.Converged)
+	assert.Equal(t, "max_iterations", result.StoppingReason)
+}
+
+func TestOptimize_StopsAtTheTimeLimit(t *testing.T) {
+	objective := parse(t, "-(pow(x - 0.5, 2) + pow(y + 1, 2))")
+
+	opts := options(Matern52, ExpectedImprovement, 0.01)
+	opts.Iterations, opts.TimeLimit = 100000, time.Nanosecond
+	result, err := Optimize(context.Background(), unitSquare, nil, objective, opts)
+	require.NoError(t, err)
+	assert.False(t, result.Converged)
+	assert.Equal(t, "time_limit", result.StoppingReason)
+	require.Len(t, result.History, 1)
+	assert.Equal(t, result.History[0].Value, result.BestValue)
+	assert.Contains(t, result.Next, "x")
+}
+
+func TestOptimize_ReportsProgress(t *testing.T) {
+	objective := parse(t, "-(pow(x - 0.5, 2) + pow(y + 1, 2))")
+
+	var snapshots []*Result
+	opts := options(Matern52, ExpectedImprovement, 0.01)
+	opts.ProgressInterval = 5
+	opts.Progress = func(r *Result) { snapshots = append(snapshots, r) }
+	result, err := Optimize(context.Background(), unitSquare, nil, objective, opts)
+	require.NoError(t, err)
+
+	require.Len(t, snapshots, 4)
+	for i, snapshot := range snapshots {
+		assert.Len(t, snapshot.History, 5*(i+1))
+		assert.Nil(t, snapshot.Next)
+		if i > 0 {
+			// The best value never gets worse
+			assert.GreaterOrEqual(t, snapshot.BestValue, snapshots[i-1].BestValue)
+		}
+	}
+	assert.GreaterOrEqual(t, result.BestValue, snapshots[3].BestValue)
+}
+
+func TestOptimize_RejectsInvalidRuns(t *testing.T) {
+	objective := parse(t, "x")
+
+	opts := options(Matern52, ExpectedImprovement, 0.01)
+	_, err := Optimize(context.Background(), nil, nil, objective, opts)
+	assert.Error(t, err, "no parameters")
+	_, err = Optimize(context.Background(), []Parameter{{Name: "x", Min: 1, Max: 1}}, nil, objective, opts)
+	assert.Error(t, err, "empty range")
+	_, err = Optimize(context.Background(), unitSquare, nil, nil, opts)
+	assert.Error(t, err, "nothing to fit")
+	_, err = Optimize(context.Background(), unitSquare, []Observation{{Parameters: map[string]float64{"x": 1}}}, nil, opts)
+	assert.Error(t, err, "missing parameter")
+
+	opts.Kernel = "periodic"
+	_, err = Optimize(context.Background(), unitSquare, nil, objective, opts)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Optimize(ctx, unitSquare, nil, objective, options(RBF, ExpectedImprovement, 0.01))
+	assert.ErrorIs(t, err, context.Canceled)
+}
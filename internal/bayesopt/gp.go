@@ -0,0 +1,170 @@
+package bayesopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Kernels
+const (
+	RBF      = "rbf"
+	Matern32 = "matern32"
+	Matern52 = "matern52"
+)
+
+// maxJitterRetries bounds how often fitting raises the noise to make the
+// covariance matrix positive definite
+const maxJitterRetries = 6
+
+// kernel returns the correlation of two points at scaled distance r
+type kernel func(r float64) float64
+
+// kernelByName returns the kernel name selects
+func kernelByName(name string) (kernel, error) {
+	switch name {
+	case RBF:
+		return func(r float64) float64 { return math.Exp(-r * r / 2) }, nil
+	case Matern32:
+		return func(r float64) float64 {
+			s := math.Sqrt(3) * r
+			return (1 + s) * math.Exp(-s)
+		}, nil
+	case Matern52:
+		return func(r float64) float64 {
+			s := math.Sqrt(5) * r
+			return (1 + s + s*s/3) * math.Exp(-s)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown kernel %q", name)
+}
+
+// gp is a Gaussian process fitted to observations in the unit cube. Its
+// targets are standardized to zero mean and unit variance.
+type gp struct {
+	kernel      kernel
+	lengthScale float64
+	x           [][]float64
+	// chol is the lower Cholesky factor of the covariance of the
+	// observations and alpha solves chol cholᵀ alpha = y
+	chol  [][]float64
+	alpha []float64
+	// mean and scale undo the standardization of the targets
+	mean, scale float64
+}
+
+// fit returns the Gaussian process with kernel k conditioned on y at x
+func fit(k kernel, lengthScale, noise float64, x [][]float64, y []float64) (*gp, error) {
+	g := &gp{kernel: k, lengthScale: lengthScale, x: x, scale: 1}
+	for _, v := range y {
+		g.mean += v
+	}
+	g.mean /= float64(len(y))
+	variance := 0.0
+	for _, v := range y {
+		variance += (v - g.mean) * (v - g.mean)
+	}
+	if variance > 0 {
+		g.scale = math.Sqrt(variance / float64(len(y)))
+	}
+	standardized := make([]float64, len(y))
+	for i, v := range y {
+		standardized[i] = (v - g.mean) / g.scale
+	}
+
+	n := len(x)
+	for retry := 0; retry < maxJitterRetries; retry++ {
+		cov := make([][]float64, n)
+		for i := range cov {
+			cov[i] = make([]float64, n)
+			for j := range cov[i] {
+				cov[i][j] = g.covariance(x[i], x[j])
+			}
+			cov[i][i] += noise
+		}
+		if chol, ok := cholesky(cov); ok {
+			g.chol = chol
+			g.alpha = backSubstitute(chol, forwardSubstitute(chol, standardized))
+			return g, nil
+		}
+		// Repeated or nearby points leave the matrix singular
+		noise = math.Max(noise*10, 1e-10)
+	}
+	return nil, errors.New("the observations' covariance is not positive definite")
+}
+
+// covariance returns the kernel of a and b
+func (g *gp) covariance(a, b []float64) float64 {
+	distance := 0.0
+	for i := range a {
+		distance += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return g.kernel(math.Sqrt(distance) / g.lengthScale)
+}
+
+// predict returns the posterior mean and variance at x, standardized
+func (g *gp) predict(x []float64) (float64, float64) {
+	k := make([]float64, len(g.x))
+	mean := 0.0
+	for i, xi := range g.x {
+		k[i] = g.covariance(x, xi)
+		mean += k[i] * g.alpha[i]
+	}
+	v := forwardSubstitute(g.chol, k)
+	variance := 1.0
+	for _, vi := range v {
+		variance -= vi * vi
+	}
+	return mean, math.Max(variance, 0)
+}
+
+// cholesky returns the lower triangular L with L Lᵀ = a, or false if a is
+// not positive definite
+func cholesky(a [][]float64) ([][]float64, bool) {
+	n := len(a)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			sum := a[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				if sum <= 0 {
+					return nil, false
+				}
+				l[i][i] = math.Sqrt(sum)
+			} else {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+	return l, true
+}
+
+// forwardSubstitute solves l x = b for lower triangular l
+func forwardSubstitute(l [][]float64, b []float64) []float64 {
+	x := make([]float64, len(b))
+	for i := range b {
+		sum := b[i]
+		for k := 0; k < i; k++ {
+			sum -= l[i][k] * x[k]
+		}
+		x[i] = sum / l[i][i]
+	}
+	return x
+}
+
+// backSubstitute solves lᵀ x = b for lower triangular l
+func backSubstitute(l [][]float64, b []float64) []float64 {
+	x := make([]float64, len(b))
+	for i := len(b) - 1; i >= 0; i-- {
+		sum := b[i]
+		for k := i + 1; k < len(b); k++ {
+			sum -= l[k][i] * x[k]
+		}
+		x[i] = sum / l[i][i]
+	}
+	return x
+}
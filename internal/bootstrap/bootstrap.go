@@ -0,0 +1,156 @@
+// Package bootstrap quantifies the uncertainty of a statistic of a small
+// sample by resampling it. Each resample draws as many values as the sample
+// holds, with replacement, and the spread of the statistic over the resamples
+// stands in for its sampling distribution, giving a standard error, a bias
+// and confidence intervals.
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/rainmana/gothink/internal/parallel"
+	"github.com/rainmana/gothink/internal/stats"
+)
+
+// Statistics
+const (
+	Mean   = "mean"
+	Median = "median"
+	// MeanDifference is the mean of the sample less the mean of the
+	// comparison sample, each resampled on its own
+	MeanDifference = "mean_difference"
+)
+
+// Options control a run
+type Options struct {
+	Statistic string
+	Resamples int
+	// Confidence is the coverage of the intervals, such as 0.95
+	Confidence float64
+	// Parallelism is the number of goroutines drawing resamples, 1 when
+	// zero. The resamples are drawn in chunks seeded from Rand, so the
+	// result does not depend on it.
+	Parallelism int
+	Rand        *rand.Rand
+}
+
+// Interval is a confidence interval
+type Interval struct {
+	Lower float64
+	Upper float64
+}
+
+// Result is the outcome of a run
+type Result struct {
+	// Estimate is the statistic of the sample itself
+	Estimate float64
+	// StdError is the standard deviation of the statistic over the
+	// resamples, and Bias how far their mean lies from Estimate
+	StdError float64
+	Bias     float64
+	// Percentile bounds the central Confidence of the resampled statistics;
+	// Basic reflects those bounds about Estimate, correcting for bias; and
+	// Normal is Estimate plus or minus the normal quantile times StdError
+	Percentile Interval
+	Basic      Interval
+	Normal     Interval
+	// Resamples holds the statistic of each resample, sorted
+	Resamples []float64
+}
+
+// Analyze bootstraps opts.Statistic of sample, and of comparison for
+// MeanDifference. It returns ctx's error if ctx ends first.
+func Analyze(ctx context.Context, sample, comparison []float64, opts Options) (*Result, error) {
+	statistic, err := statisticOf(opts.Statistic)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(sample) < 2:
+		return nil, errors.New("the sample needs at least 2 values")
+	case opts.Statistic == MeanDifference && len(comparison) < 2:
+		return nil, errors.New("the comparison sample needs at least 2 values")
+	case opts.Statistic != MeanDifference && len(comparison) > 0:
+		return nil, fmt.Errorf("only %s takes a comparison sample", MeanDifference)
+	case opts.Resamples < 2:
+		return nil, errors.New("there must be at least 2 resamples")
+	case !(opts.Confidence > 0 && opts.Confidence < 1):
+		return nil, errors.New("the confidence must be within (0, 1)")
+	case opts.Parallelism < 0:
+		return nil, errors.New("parallelism must not be negative")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+	for _, values := range [][]float64{sample, comparison} {
+		for _, x := range values {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return nil, errors.New("the samples must hold finite values")
+			}
+		}
+	}
+
+	estimate := statistic(sample)
+	if opts.Statistic == MeanDifference {
+		estimate -= statistic(comparison)
+	}
+
+	resamples := make([]float64, opts.Resamples)
+	err = parallel.Chunks(ctx, opts.Resamples, parallel.ChunkSize, opts.Parallelism, opts.Rand, func(start, end int, r *rand.Rand) error {
+		draw := make([]float64, len(sample))
+		other := make([]float64, len(comparison))
+		for b := start; b < end; b++ {
+			resample(draw, sample, r)
+			resamples[b] = statistic(draw)
+			if opts.Statistic == MeanDifference {
+				resample(other, comparison, r)
+				resamples[b] -= statistic(other)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Float64s(resamples)
+
+	result := &Result{Estimate: estimate, Resamples: resamples}
+	moments := stats.Describe(resamples)
+	result.StdError = moments.StdDev
+	result.Bias = moments.Mean - estimate
+
+	low, high := stats.Interval(resamples, (1-opts.Confidence)/2)
+	result.Percentile = Interval{Lower: low, Upper: high}
+	result.Basic = Interval{Lower: 2*estimate - high, Upper: 2*estimate - low}
+	z := math.Sqrt2 * math.Erfinv(opts.Confidence)
+	result.Normal = Interval{Lower: estimate - z*result.StdError, Upper: estimate + z*result.StdError}
+	return result, nil
+}
+
+// statisticOf returns the function computing statistic of a sample
+func statisticOf(statistic string) (func([]float64) float64, error) {
+	switch statistic {
+	case Mean, MeanDifference:
+		return stats.Mean, nil
+	case Median:
+		return median, nil
+	}
+	return nil, fmt.Errorf("unknown statistic %q", statistic)
+}
+
+// resample fills draw with values drawn from sample with replacement
+func resample(draw, sample []float64, r *rand.Rand) {
+	for i := range draw {
+		draw[i] = sample[r.Intn(len(sample))]
+	}
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return stats.Quantile(sorted, 0.5)
+}
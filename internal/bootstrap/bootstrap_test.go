@@ -0,0 +1,90 @@
+package bootstrap
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func options(statistic string) Options {
+	return Options{Statistic: statistic, Resamples: 5000, Confidence: 0.95, Rand: rand.New(rand.NewSource(1))}
+}
+
+func TestAnalyze_Mean(t *testing.T) {
+	sample := []float64{12, 15, 9, 11, 14, 10, 13, 16, 8, 12}
+	result, err := Analyze(context.Background(), sample, nil, options(Mean))
+	require.NoError(t, err)
+	assert.InDelta(t, 12, result.Estimate, 1e-9)
+
+	// The standard error approaches the population formula s/√n scaled by
+	// √((n-1)/n)
+	squares := 0.0
+	for _, x := range sample {
+		squares += (x - 12) * (x - 12)
+	}
+	expected := math.Sqrt(squares/float64(len(sample))) / math.Sqrt(float64(len(sample)))
+	assert.InDelta(t, expected, result.StdError, 0.05)
+	assert.InDelta(t, 0, result.Bias, 0.05)
+
+	for _, interval := range []Interval{result.Percentile, result.Basic, result.Normal} {
+		assert.Less(t, interval.Lower, 12.0)
+		assert.Greater(t, interval.Upper, 12.0)
+		assert.InDelta(t, 2*1.96*expected, interval.Upper-interval.Lower, 0.4)
+	}
+	assert.Len(t, result.Resamples, 5000)
+	assert.IsNonDecreasing(t, result.Resamples)
+}
+
+func TestAnalyze_MedianAndMeanDifference(t *testing.T) {
+	result, err := Analyze(context.Background(), []float64{1, 2, 3, 100, 4, 5, 6, 7, 8}, nil, options(Median))
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, result.Estimate)
+	// The outlier barely moves the median
+	assert.Less(t, result.Percentile.Upper, 10.0)
+
+	treated := []float64{14, 15, 16, 15, 17, 14, 16}
+	control := []float64{10, 11, 9, 10, 12, 10}
+	result, err = Analyze(context.Background(), treated, control, options(MeanDifference))
+	require.NoError(t, err)
+	assert.InDelta(t, 15.2857-10.3333, result.Estimate, 1e-3)
+	assert.Greater(t, result.Percentile.Lower, 0.0, "the difference is clear")
+}
+
+func TestAnalyze_DoesNotDependOnParallelism(t *testing.T) {
+	sample := []float64{3, 8, 1, 9, 4, 7, 2}
+	serial, err := Analyze(context.Background(), sample, nil, options(Median))
+	require.NoError(t, err)
+
+	opts := options(Median)
+	opts.Parallelism = 8
+	parallel, err := Analyze(context.Background(), sample, nil, opts)
+	require.NoError(t, err)
+	assert.Equal(t, serial, parallel)
+}
+
+func TestAnalyze_RejectsInvalidRuns(t *testing.T) {
+	_, err := Analyze(context.Background(), []float64{1, 2}, nil, options("mode"))
+	assert.Error(t, err, "unknown statistic")
+	_, err = Analyze(context.Background(), []float64{1}, nil, options(Mean))
+	assert.Error(t, err, "too small")
+	_, err = Analyze(context.Background(), []float64{1, 2}, nil, options(MeanDifference))
+	assert.Error(t, err, "no comparison")
+	_, err = Analyze(context.Background(), []float64{1, 2}, []float64{1, 2}, options(Mean))
+	assert.Error(t, err, "stray comparison")
+	_, err = Analyze(context.Background(), []float64{1, math.NaN()}, nil, options(Mean))
+	assert.Error(t, err, "not finite")
+
+	opts := options(Mean)
+	opts.Confidence = 1
+	_, err = Analyze(context.Background(), []float64{1, 2}, nil, opts)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Analyze(ctx, []float64{1, 2}, nil, options(Mean))
+	assert.ErrorIs(t, err, context.Canceled)
+}
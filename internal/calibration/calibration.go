@@ -0,0 +1,156 @@
+// Package calibration compares forecasts with what came to pass. Forecast
+// probabilities of success are scored against the successes and failures
+// that followed by the Brier score, the mean squared difference between each
+// probability and its outcome, 1 for a success and 0 for a failure, and by a
+// reliability table that bins the forecasts by probability and sets the mean
+// forecast of each bin against the share of its outcomes that succeeded. A
+// well-calibrated forecaster's 70% forecasts come true 70% of the time.
+// Forecast values are compared with the values realized by their mean error
+// and the ratio of the total realized to the total forecast.
+package calibration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DefaultBins is the number of equal-width bins of the reliability table
+const DefaultBins = 5
+
+// Tolerance is how far, in probability, the mean forecast may be from the
+// observed rate of success for the forecasts to count as calibrated
+const Tolerance = 0.05
+
+// Leanings of the forecasts: optimistic forecasts expect more successes than
+// come, pessimistic ones fewer
+const (
+	Calibrated  = "calibrated"
+	Optimistic  = "optimistic"
+	Pessimistic = "pessimistic"
+)
+
+// Bin is a bin of the reliability table, holding the forecasts of at least
+// Lower and below Upper, or up to 1 for the last bin
+type Bin struct {
+	Lower        float64
+	Upper        float64
+	Count        int
+	MeanForecast float64
+	ObservedRate float64
+}
+
+// Probability is the calibration of forecast probabilities of success
+type Probability struct {
+	Count        int
+	MeanForecast float64
+	ObservedRate float64
+	BrierScore   float64
+	// Leaning says whether the forecasts expected more successes than came,
+	// fewer, or about as many
+	Leaning string
+	// Bins is the reliability table, holding only bins with forecasts
+	Bins []Bin
+}
+
+// Probabilities scores forecast probabilities of success against the
+// outcomes that followed them, binning them into bins of equal width
+func Probabilities(forecasts []float64, succeeded []bool, bins int) (*Probability, error) {
+	if len(forecasts) != len(succeeded) {
+		return nil, fmt.Errorf("%d forecasts but %d outcomes", len(forecasts), len(succeeded))
+	}
+	if len(forecasts) == 0 {
+		return nil, errors.New("there are no forecasts")
+	}
+	if bins < 1 {
+		return nil, fmt.Errorf("there must be at least one bin, not %d", bins)
+	}
+
+	table := make([]Bin, bins)
+	for b := range table {
+		table[b].Lower, table[b].Upper = float64(b)/float64(bins), float64(b+1)/float64(bins)
+	}
+	result := &Probability{Count: len(forecasts)}
+	for i, p := range forecasts {
+		if p < 0 || p > 1 || math.IsNaN(p) {
+			return nil, fmt.Errorf("forecast %d has probability %g outside [0, 1]", i+1, p)
+		}
+		outcome := 0.0
+		if succeeded[i] {
+			outcome = 1
+		}
+		result.MeanForecast += p
+		result.ObservedRate += outcome
+		result.BrierScore += (p - outcome) * (p - outcome)
+
+		b := int(p * float64(bins))
+		if b == bins {
+			b--
+		}
+		table[b].Count++
+		table[b].MeanForecast += p
+		table[b].ObservedRate += outcome
+	}
+	n := float64(len(forecasts))
+	result.MeanForecast /= n
+	result.ObservedRate /= n
+	result.BrierScore /= n
+	switch gap := result.MeanForecast - result.ObservedRate; {
+	case gap > Tolerance:
+		result.Leaning = Optimistic
+	case gap < -Tolerance:
+		result.Leaning = Pessimistic
+	default:
+		result.Leaning = Calibrated
+	}
+
+	for _, bin := range table {
+		if bin.Count > 0 {
+			bin.MeanForecast /= float64(bin.Count)
+			bin.ObservedRate /= float64(bin.Count)
+			result.Bins = append(result.Bins, bin)
+		}
+	}
+	return result, nil
+}
+
+// Value is the calibration of forecast values
+type Value struct {
+	Count        int
+	MeanForecast float64
+	MeanRealized float64
+	// MeanError is the mean of the realized values less the forecast ones,
+	// negative when the forecasts were too high
+	MeanError         float64
+	MeanAbsoluteError float64
+	// Ratio is the total realized over the total forecast, nil when the
+	// forecasts total 0
+	Ratio *float64
+}
+
+// Values compares forecast values with the values realized
+func Values(forecasts, realized []float64) (*Value, error) {
+	if len(forecasts) != len(realized) {
+		return nil, fmt.Errorf("%d forecasts but %d realized values", len(forecasts), len(realized))
+	}
+	if len(forecasts) == 0 {
+		return nil, errors.New("there are no forecasts")
+	}
+
+	result := &Value{Count: len(forecasts)}
+	for i, forecast := range forecasts {
+		result.MeanForecast += forecast
+		result.MeanRealized += realized[i]
+		result.MeanAbsoluteError += math.Abs(realized[i] - forecast)
+	}
+	if result.MeanForecast != 0 {
+		ratio := result.MeanRealized / result.MeanForecast
+		result.Ratio = &ratio
+	}
+	n := float64(len(forecasts))
+	result.MeanForecast /= n
+	result.MeanRealized /= n
+	result.MeanError = result.MeanRealized - result.MeanForecast
+	result.MeanAbsoluteError /= n
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package calibration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbabilities_ScoresAndBinsForecasts(t *testing.T) {
+	result, err := Probabilities([]float64{0.9, 0.8, 0.9, 0.3, 1}, []bool{true, false, false, false, true}, DefaultBins)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, result.Count)
+	assert.InDelta(t, 0.78, result.MeanForecast, 1e-12)
+	assert.InDelta(t, 0.4, result.ObservedRate, 1e-12)
+	// (0.01 + 0.64 + 0.81 + 0.09 + 0) / 5
+	assert.InDelta(t, 0.31, result.BrierScore, 1e-12)
+	assert.Equal(t, Optimistic, result.Leaning)
+
+	require.Len(t, result.Bins, 2)
+	assert.Equal(t, 1, result.Bins[0].Count)
+	assert.InDelta(t, 0.2, result.Bins[0].Lower, 1e-12)
+	assert.InDelta(t, 0.4, result.Bins[0].Upper, 1e-12)
+	assert.Equal(t, 0.0, result.Bins[0].ObservedRate)
+	// A forecast of 1 falls in the last bin
+	assert.Equal(t, 4, result.Bins[1].Count)
+	assert.InDelta(t, 0.9, result.Bins[1].MeanForecast, 1e-12)
+	assert.InDelta(t, 0.5, result.Bins[1].ObservedRate, 1e-12)
+}
+
+func TestProbabilities_LeaningFollowsTheGap(t *testing.T) {
+	result, err := Probabilities([]float64{0.5, 0.5}, []bool{true, false}, DefaultBins)
+	require.NoError(t, err)
+	assert.Equal(t, Calibrated, result.Leaning)
+	assert.InDelta(t, 0.25, result.BrierScore, 1e-12)
+
+	result, err = Probabilities([]float64{0.2, 0.4}, []bool{true, true}, DefaultBins)
+	require.NoError(t, err)
+	assert.Equal(t, Pessimistic, result.Leaning)
+}
+
+func TestProbabilities_RejectsBadForecasts(t *testing.T) {
+	_, err := Probabilities(nil, nil, DefaultBins)
+	assert.Error(t, err)
+	_, err = Probabilities([]float64{1.2}, []bool{true}, DefaultBins)
+	assert.Error(t, err)
+	_, err = Probabilities([]float64{0.5}, []bool{true, false}, DefaultBins)
+	assert.Error(t, err)
+	_, err = Probabilities([]float64{0.5}, []bool{true}, 0)
+	assert.Error(t, err)
+}
+
+func TestValues_ComparesForecastAndRealizedValues(t *testing.T) {
+	result, err := Values([]float64{100, 50}, []float64{80, 70})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Count)
+	assert.InDelta(t, 75, result.MeanForecast, 1e-12)
+	assert.InDelta(t, 75, result.MeanRealized, 1e-12)
+	assert.InDelta(t, 0, result.MeanError, 1e-12)
+	assert.InDelta(t, 20, result.MeanAbsoluteError, 1e-12)
+	require.NotNil(t, result.Ratio)
+	assert.InDelta(t, 1, *result.Ratio, 1e-12)
+
+	result, err = Values([]float64{10, -10}, []float64{5, 0})
+	require.NoError(t, err)
+	assert.Nil(t, result.Ratio)
+	assert.InDelta(t, 2.5, result.MeanError, 1e-12)
+}
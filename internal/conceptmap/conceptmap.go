@@ -0,0 +1,184 @@
+// Package conceptmap drafts concept maps from text. The concepts of a set of
+// documents are its keywords: the words, other than common English ones,
+// that recur across the documents, ranked by how many documents use them,
+// then by how often, then by first use. Two concepts are related when they
+// occur in the same documents, the more documents they share the more
+// strongly.
+package conceptmap
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Defaults of Options
+const (
+	DefaultMaxConcepts     = 15
+	DefaultMinDocuments    = 2
+	DefaultMinCooccurrence = 2
+)
+
+// Document is a text to draw concepts from
+type Document struct {
+	ID   string
+	Text string
+}
+
+// Options control the drafting of a map
+type Options struct {
+	// MaxConcepts is the most concepts the map holds
+	MaxConcepts int
+	// MinDocuments is the fewest documents a word must occur in to be a
+	// concept; when there are fewer documents, every document is needed
+	MinDocuments int
+	// MinCooccurrence is the fewest documents two concepts must share to be
+	// related, capped likewise
+	MinCooccurrence int
+}
+
+// Concept is a keyword of the documents
+type Concept struct {
+	Term string
+	// Mentions counts the occurrences of the term
+	Mentions int
+	// Documents are the IDs of the documents using the term, in order
+	Documents []string
+}
+
+// Relation relates two concepts, From ranking above To
+type Relation struct {
+	From string
+	To   string
+	// Documents are the IDs of the documents using both, in order
+	Documents []string
+}
+
+// Map is a drafted concept map: its concepts best first, and their
+// relations strongest first
+type Map struct {
+	Concepts  []Concept
+	Relations []Relation
+}
+
+// Draft drafts the concept map of documents
+func Draft(documents []Document, opts Options) Map {
+	if opts.MaxConcepts <= 0 {
+		opts.MaxConcepts = DefaultMaxConcepts
+	}
+	if opts.MinDocuments <= 0 {
+		opts.MinDocuments = DefaultMinDocuments
+	}
+	if opts.MinCooccurrence <= 0 {
+		opts.MinCooccurrence = DefaultMinCooccurrence
+	}
+	if opts.MinDocuments > len(documents) {
+		opts.MinDocuments = len(documents)
+	}
+	if opts.MinCooccurrence > len(documents) {
+		opts.MinCooccurrence = len(documents)
+	}
+
+	candidates := make(map[string]*Concept)
+	var order []*Concept
+	uses := make([]map[string]bool, len(documents))
+	for d, document := range documents {
+		uses[d] = make(map[string]bool)
+		for _, term := range Keywords(document.Text) {
+			c := candidates[term]
+			if c == nil {
+				c = &Concept{Term: term}
+				candidates[term] = c
+				order = append(order, c)
+			}
+			c.Mentions++
+			if !uses[d][term] {
+				uses[d][term] = true
+				c.Documents = append(c.Documents, document.ID)
+			}
+		}
+	}
+
+	var kept []*Concept
+	for _, c := range order {
+		if len(c.Documents) >= opts.MinDocuments {
+			kept = append(kept, c)
+		}
+	}
+	sort.SliceStable(kept, func(a, b int) bool {
+		if len(kept[a].Documents) != len(kept[b].Documents) {
+			return len(kept[a].Documents) > len(kept[b].Documents)
+		}
+		return kept[a].Mentions > kept[b].Mentions
+	})
+	if len(kept) > opts.MaxConcepts {
+		kept = kept[:opts.MaxConcepts]
+	}
+
+	m := Map{Concepts: make([]Concept, len(kept)), Relations: []Relation{}}
+	for i, c := range kept {
+		m.Concepts[i] = *c
+	}
+	for i, a := range m.Concepts {
+		for _, b := range m.Concepts[i+1:] {
+			var shared []string
+			for d, document := range documents {
+				if uses[d][a.Term] && uses[d][b.Term] {
+					shared = append(shared, document.ID)
+				}
+			}
+			if len(shared) > 0 && len(shared) >= opts.MinCooccurrence {
+				m.Relations = append(m.Relations, Relation{From: a.Term, To: b.Term, Documents: shared})
+			}
+		}
+	}
+	sort.SliceStable(m.Relations, func(a, b int) bool {
+		return len(m.Relations[a].Documents) > len(m.Relations[b].Documents)
+	})
+	return m
+}
+
+// Keywords returns the words of text that may be concepts, lower-cased and
+// in order: those of three or more letters, not all digits, that are not
+// common English words
+func Keywords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	var keywords []string
+	for _, word := range words {
+		word = strings.Trim(word, "-")
+		if len([]rune(word)) < 3 || stopWords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
+
+// stopWords are common English words that carry no concept
+var stopWords = func() map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(`
+		about above after again against all also although among and any are
+		because been before being below between both but can cannot
+		could did does doing done down during each either else enough even
+		ever every few for from further get gets getting give given goes going
+		had has have having her here hers herself him himself his how however
+		into its itself just let like made make makes many may maybe might
+		more most much must need needs neither nor not now off often once
+		one only onto other others otherwise our ours ourselves out over own
+		per perhaps quite rather really same see seem seems several shall she
+		should since some still such than that the their theirs them
+		themselves then there therefore these they this those though through
+		thus too under until upon use used uses using very was way ways well
+		were what whatever when where whether which while who whom whose why
+		will with within without would yet you your yours yourself
+		first second third next last new old good better best bad worse worst
+		thing things something anything nothing everything lot lots think
+		thought know want take takes took yes
+	`) {
+		words[word] = true
+	}
+	return words
+}()
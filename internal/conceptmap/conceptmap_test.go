@@ -0,0 +1,46 @@
+package conceptmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeywords_DropsCommonWordsAndNumbers(t *testing.T) {
+	assert.Equal(t,
+		[]string{"database", "latency", "trade-off", "p99", "batch", "writes"},
+		Keywords("The database latency is a trade-off: at 250 ms p99, should we batch? No - writes"))
+}
+
+func TestDraft_KeepsRecurringTermsAndRelatesThoseSharingDocuments(t *testing.T) {
+	m := Draft([]Document{
+		{ID: "t1", Text: "Database latency spikes under load"},
+		{ID: "t2", Text: "Caching could cut database latency"},
+		{ID: "t3", Text: "Caching adds invalidation cost, and database cost"},
+		{ID: "t4", Text: "Load tests show the latency budget"},
+	}, Options{})
+
+	terms := make([]string, len(m.Concepts))
+	for i, c := range m.Concepts {
+		terms[i] = c.Term
+	}
+	// cost recurs, but in one document only
+	assert.Equal(t, []string{"database", "latency", "load", "caching"}, terms)
+	assert.Equal(t, []string{"t1", "t2", "t3"}, m.Concepts[0].Documents)
+	assert.Equal(t, 2, m.Concepts[3].Mentions)
+
+	assert.Equal(t, []Relation{
+		{From: "database", To: "latency", Documents: []string{"t1", "t2"}},
+		{From: "database", To: "caching", Documents: []string{"t2", "t3"}},
+		{From: "latency", To: "load", Documents: []string{"t1", "t4"}},
+	}, m.Relations)
+}
+
+func TestDraft_CapsThresholdsAtTheDocumentsGiven(t *testing.T) {
+	m := Draft([]Document{{ID: "only", Text: "Pricing strategy for pricing tiers"}}, Options{MaxConcepts: 2})
+
+	require.Len(t, m.Concepts, 2)
+	assert.Equal(t, Concept{Term: "pricing", Mentions: 2, Documents: []string{"only"}}, m.Concepts[0])
+	assert.Equal(t, []Relation{{From: "pricing", To: "strategy", Documents: []string{"only"}}}, m.Relations)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,34 +16,104 @@ type Config struct {
 	Host         string        `json:"host" yaml:"host"`
 	ReadTimeout  time.Duration `json:"read_timeout" yaml:"read_timeout"`
 	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"`
+	// EnableWebSocket serves the MCP server to WebSocket clients at /mcp/ws
+	// of the HTTP API
+	EnableWebSocket bool `json:"enable_websocket" yaml:"enable_websocket"`
+	// GRPCPort serves the gRPC API on Host alongside the other servers when set
+	GRPCPort string `json:"grpc_port" yaml:"grpc_port"`
 
 	// Session settings
 	SessionTimeout        time.Duration `json:"session_timeout" yaml:"session_timeout"`
 	MaxThoughtsPerSession int           `json:"max_thoughts_per_session" yaml:"max_thoughts_per_session"`
+	// Idle sessions are marked inactive after SessionTimeout and evicted
+	// SessionGracePeriod later; expiry is checked every SessionSweepInterval
+	SessionGracePeriod   time.Duration `json:"session_grace_period" yaml:"session_grace_period"`
+	SessionSweepInterval time.Duration `json:"session_sweep_interval" yaml:"session_sweep_interval"`
+	// Memory backend quotas; zero disables a quota. Writes beyond a per-session
+	// quota are rejected, while exceeding a global quota evicts the least
+	// recently used sessions. Sizes are approximate JSON-encoded bytes.
+	MaxRecordsPerSession int   `json:"max_records_per_session" yaml:"max_records_per_session"`
+	MaxBytesPerSession   int64 `json:"max_bytes_per_session" yaml:"max_bytes_per_session"`
+	MaxTotalRecords      int   `json:"max_total_records" yaml:"max_total_records"`
+	MaxTotalBytes        int64 `json:"max_total_bytes" yaml:"max_total_bytes"`
+
+	// Rate limiting; zero disables it. HTTP requests are limited per client IP
+	// and MCP tool calls per session, each allowing RateLimitPerSecond
+	// requests on average and bursts of up to RateLimitBurst.
+	RateLimitPerSecond float64 `json:"rate_limit_per_second" yaml:"rate_limit_per_second"`
+	RateLimitBurst     int     `json:"rate_limit_burst" yaml:"rate_limit_burst"`
+
+	// Worker pool for the expensive MCP tools (stochastic algorithms and
+	// intelligence): up to WorkerPoolSize calls run at once, WorkerQueueSize
+	// more wait and further calls are rejected. ToolTimeout bounds each call,
+	// including its wait. A WorkerPoolSize of zero disables the pool.
+	WorkerPoolSize  int           `json:"worker_pool_size" yaml:"worker_pool_size"`
+	WorkerQueueSize int           `json:"worker_queue_size" yaml:"worker_queue_size"`
+	ToolTimeout     time.Duration `json:"tool_timeout" yaml:"tool_timeout"`
+
+	// Results of MCP tool calls made with a request_id are kept for
+	// ResultRetention, up to MaxRetainedResults of them, and returned to
+	// retries of the call. A ResultRetention of zero disables retention.
+	ResultRetention    time.Duration `json:"result_retention" yaml:"result_retention"`
+	MaxRetainedResults int           `json:"max_retained_results" yaml:"max_retained_results"`
 
 	// Feature flags
 	EnableStochasticAlgorithms bool `json:"enable_stochastic_algorithms" yaml:"enable_stochastic_algorithms"`
 	EnableSystematicThinking   bool `json:"enable_systematic_thinking" yaml:"enable_systematic_thinking"`
 	EnableVisualization        bool `json:"enable_visualization" yaml:"enable_visualization"`
 	EnableHybridThinking       bool `json:"enable_hybrid_thinking" yaml:"enable_hybrid_thinking"`
+	// Per-tool overrides of the MCP tools registered for enabled features: when
+	// EnabledTools is set only the tools it names are kept, and DisabledTools
+	// are always dropped
+	EnabledTools  []string `json:"enabled_tools" yaml:"enabled_tools"`
+	DisabledTools []string `json:"disabled_tools" yaml:"disabled_tools"`
 
 	// Algorithm settings
 	MaxStochasticIterations    int     `json:"max_stochastic_iterations" yaml:"max_stochastic_iterations"`
 	DefaultConfidenceThreshold float64 `json:"default_confidence_threshold" yaml:"default_confidence_threshold"`
 
 	// Persistence settings
+	StorageBackend    string `json:"storage_backend" yaml:"storage_backend"`
 	EnablePersistence bool   `json:"enable_persistence" yaml:"enable_persistence"`
 	PersistencePath   string `json:"persistence_path" yaml:"persistence_path"`
+	// SnapshotInterval controls how often the memory backend writes a snapshot when persistence is enabled
+	SnapshotInterval time.Duration `json:"snapshot_interval" yaml:"snapshot_interval"`
+	// EnableJournal records every write in an append-only journal that is replayed on startup
+	EnableJournal bool   `json:"enable_journal" yaml:"enable_journal"`
+	JournalPath   string `json:"journal_path" yaml:"journal_path"`
+
+	// Redis settings (used by the redis storage backend)
+	RedisAddress   string `json:"redis_address" yaml:"redis_address"`
+	RedisPassword  string `json:"redis_password" yaml:"redis_password"`
+	RedisDB        int    `json:"redis_db" yaml:"redis_db"`
+	RedisKeyPrefix string `json:"redis_key_prefix" yaml:"redis_key_prefix"`
 
 	// Logging settings
 	EnableDetailedLogging bool   `json:"enable_detailed_logging" yaml:"enable_detailed_logging"`
 	LogLevel              string `json:"log_level" yaml:"log_level"`
+	// Audit log of MCP tool calls: empty disables it, "-" writes to stderr and
+	// any other value names a file to append to. AuditRedactContent replaces
+	// thought content and other free-text arguments in the log, and
+	// AuditRedactFields names further arguments to replace.
+	AuditLogPath       string   `json:"audit_log_path" yaml:"audit_log_path"`
+	AuditRedactContent bool     `json:"audit_redact_content" yaml:"audit_redact_content"`
+	AuditRedactFields  []string `json:"audit_redact_fields" yaml:"audit_redact_fields"`
 
 	// Mental models settings
 	MentalModelsPath string `json:"mental_models_path" yaml:"mental_models_path"`
 
 	// Algorithm defaults
 	AlgorithmDefaults map[string]interface{} `json:"algorithm_defaults" yaml:"algorithm_defaults"`
+
+	// IntelligenceMaxAge is how old intelligence data may get before the HTTP
+	// readiness check fails; zero leaves freshness unchecked
+	IntelligenceMaxAge time.Duration `json:"intelligence_max_age" yaml:"intelligence_max_age"`
+
+	// Critic settings (the critic is disabled when no endpoint is configured)
+	CriticEndpoint string        `json:"critic_endpoint" yaml:"critic_endpoint"`
+	CriticModel    string        `json:"critic_model" yaml:"critic_model"`
+	CriticAPIKey   string        `json:"critic_api_key" yaml:"critic_api_key"`
+	CriticTimeout  time.Duration `json:"critic_timeout" yaml:"critic_timeout"`
 }
 
 // DefaultConfig returns the default configuration
@@ -53,16 +125,32 @@ func DefaultConfig() *Config {
 		WriteTimeout:               30 * time.Second,
 		SessionTimeout:             30 * time.Minute,
 		MaxThoughtsPerSession:      100,
+		SessionGracePeriod:         5 * time.Minute,
+		SessionSweepInterval:       time.Minute,
+		MaxTotalBytes:              256 << 20,
+		RateLimitPerSecond:         20,
+		RateLimitBurst:             50,
+		WorkerPoolSize:             4,
+		WorkerQueueSize:            32,
+		ToolTimeout:                30 * time.Second,
+		ResultRetention:            10 * time.Minute,
+		MaxRetainedResults:         1000,
 		EnableStochasticAlgorithms: true,
 		EnableSystematicThinking:   true,
 		EnableVisualization:        true,
 		EnableHybridThinking:       true,
 		MaxStochasticIterations:    1000,
 		DefaultConfidenceThreshold: 0.8,
+		StorageBackend:             "memory",
 		EnablePersistence:          false,
+		SnapshotInterval:           time.Minute,
+		RedisAddress:               "localhost:6379",
+		RedisKeyPrefix:             "gothink:",
 		EnableDetailedLogging:      false,
 		LogLevel:                   "info",
 		AlgorithmDefaults:          make(map[string]interface{}),
+		CriticModel:                "gpt-4o-mini",
+		CriticTimeout:              60 * time.Second,
 	}
 }
 
@@ -101,6 +189,12 @@ func loadFromEnv(cfg *Config) {
 	if host := os.Getenv("GOTHINK_HOST"); host != "" {
 		cfg.Host = host
 	}
+	if grpcPort := os.Getenv("GOTHINK_GRPC_PORT"); grpcPort != "" {
+		cfg.GRPCPort = grpcPort
+	}
+	if enableWebSocket := os.Getenv("GOTHINK_ENABLE_WEBSOCKET"); enableWebSocket == "true" {
+		cfg.EnableWebSocket = true
+	}
 	if enableStochastic := os.Getenv("GOTHINK_ENABLE_STOCHASTIC"); enableStochastic == "false" {
 		cfg.EnableStochasticAlgorithms = false
 	}
@@ -113,10 +207,84 @@ func loadFromEnv(cfg *Config) {
 	if enableHybrid := os.Getenv("GOTHINK_ENABLE_HYBRID"); enableHybrid == "false" {
 		cfg.EnableHybridThinking = false
 	}
+	if enabledTools := os.Getenv("GOTHINK_ENABLED_TOOLS"); enabledTools != "" {
+		cfg.EnabledTools = splitList(enabledTools)
+	}
+	if disabledTools := os.Getenv("GOTHINK_DISABLED_TOOLS"); disabledTools != "" {
+		cfg.DisabledTools = splitList(disabledTools)
+	}
+	if enableJournal := os.Getenv("GOTHINK_ENABLE_JOURNAL"); enableJournal == "true" {
+		cfg.EnableJournal = true
+	}
+	if rateLimit, err := strconv.ParseFloat(os.Getenv("GOTHINK_RATE_LIMIT_PER_SECOND"), 64); err == nil {
+		cfg.RateLimitPerSecond = rateLimit
+	}
+	if rateLimitBurst, err := strconv.Atoi(os.Getenv("GOTHINK_RATE_LIMIT_BURST")); err == nil {
+		cfg.RateLimitBurst = rateLimitBurst
+	}
+	if workerPoolSize, err := strconv.Atoi(os.Getenv("GOTHINK_WORKER_POOL_SIZE")); err == nil {
+		cfg.WorkerPoolSize = workerPoolSize
+	}
+	if workerQueueSize, err := strconv.Atoi(os.Getenv("GOTHINK_WORKER_QUEUE_SIZE")); err == nil {
+		cfg.WorkerQueueSize = workerQueueSize
+	}
+	if toolTimeout, err := time.ParseDuration(os.Getenv("GOTHINK_TOOL_TIMEOUT")); err == nil {
+		cfg.ToolTimeout = toolTimeout
+	}
+	if resultRetention, err := time.ParseDuration(os.Getenv("GOTHINK_RESULT_RETENTION")); err == nil {
+		cfg.ResultRetention = resultRetention
+	}
+	if maxRetainedResults, err := strconv.Atoi(os.Getenv("GOTHINK_MAX_RETAINED_RESULTS")); err == nil {
+		cfg.MaxRetainedResults = maxRetainedResults
+	}
+	if storageBackend := os.Getenv("GOTHINK_STORAGE_BACKEND"); storageBackend != "" {
+		cfg.StorageBackend = storageBackend
+	}
+	if redisAddress := os.Getenv("GOTHINK_REDIS_ADDRESS"); redisAddress != "" {
+		cfg.RedisAddress = redisAddress
+	}
+	if redisPassword := os.Getenv("GOTHINK_REDIS_PASSWORD"); redisPassword != "" {
+		cfg.RedisPassword = redisPassword
+	}
+	if redisKeyPrefix := os.Getenv("GOTHINK_REDIS_KEY_PREFIX"); redisKeyPrefix != "" {
+		cfg.RedisKeyPrefix = redisKeyPrefix
+	}
 	if logLevel := os.Getenv("GOTHINK_LOG_LEVEL"); logLevel != "" {
 		cfg.LogLevel = logLevel
 	}
+	if auditLogPath := os.Getenv("GOTHINK_AUDIT_LOG"); auditLogPath != "" {
+		cfg.AuditLogPath = auditLogPath
+	}
+	if redactContent := os.Getenv("GOTHINK_AUDIT_REDACT_CONTENT"); redactContent == "true" {
+		cfg.AuditRedactContent = true
+	}
+	if redactFields := os.Getenv("GOTHINK_AUDIT_REDACT_FIELDS"); redactFields != "" {
+		cfg.AuditRedactFields = splitList(redactFields)
+	}
 	if mentalModelsPath := os.Getenv("GOTHINK_MENTAL_MODELS_PATH"); mentalModelsPath != "" {
 		cfg.MentalModelsPath = mentalModelsPath
 	}
+	if intelligenceMaxAge, err := time.ParseDuration(os.Getenv("GOTHINK_INTELLIGENCE_MAX_AGE")); err == nil {
+		cfg.IntelligenceMaxAge = intelligenceMaxAge
+	}
+	if criticEndpoint := os.Getenv("GOTHINK_CRITIC_ENDPOINT"); criticEndpoint != "" {
+		cfg.CriticEndpoint = criticEndpoint
+	}
+	if criticModel := os.Getenv("GOTHINK_CRITIC_MODEL"); criticModel != "" {
+		cfg.CriticModel = criticModel
+	}
+	if criticAPIKey := os.Getenv("GOTHINK_CRITIC_API_KEY"); criticAPIKey != "" {
+		cfg.CriticAPIKey = criticAPIKey
+	}
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,151 @@
+// Package convergence holds what the stochastic algorithms share to report
+// how a run ended: the reasons a run stops, the deadline of a run's time
+// limit and the diagnostics that decide whether it converged, a plateau of
+// the best value found for optimizers and the Gelman-Rubin statistic for
+// samplers.
+package convergence
+
+import (
+	"math"
+	"time"
+)
+
+// Stopping reasons
+const (
+	// Tolerance stops a run whose residual fell within its tolerance
+	Tolerance = "tolerance"
+	// PolicyStable stops a policy iteration whose policy no longer changes
+	PolicyStable = "policy_stable"
+	// Plateau stops a run whose best value stopped improving
+	Plateau = "plateau"
+	// MaxIterations stops a run that used up its iterations, episodes,
+	// simulations, steps or trials
+	MaxIterations = "max_iterations"
+	// TimeLimit stops a run that used up its time
+	TimeLimit = "time_limit"
+	// Exact ends a computation that does not iterate
+	Exact = "exact"
+)
+
+// Deadline is when a run's time limit runs out. Runs check it between
+// iterations and, once it has passed, stop with the best result so far, not
+// converged, for the reason TimeLimit.
+type Deadline struct {
+	at time.Time
+}
+
+// DeadlineAfter returns the deadline of a time limit starting now, which
+// never passes when the limit is not positive
+func DeadlineAfter(limit time.Duration) Deadline {
+	if limit <= 0 {
+		return Deadline{}
+	}
+	return Deadline{at: time.Now().Add(limit)}
+}
+
+// Passed reports whether the deadline has passed
+func (d Deadline) Passed() bool {
+	return !d.at.IsZero() && time.Now().After(d.at)
+}
+
+// RHatThreshold is the Gelman-Rubin statistic below which chains are taken
+// to have converged
+const RHatThreshold = 1.01
+
+// splitChains is the number of consecutive chains SplitRHat splits a run's
+// draws into, and rHatCheckpoints the most times it computes the statistic
+const (
+	splitChains     = 4
+	rHatCheckpoints = 20
+)
+
+// Plateaued reports whether best, the best value found after each
+// iteration, improved by at most tolerance over the last window iterations.
+// Values may be rising or falling but not both.
+func Plateaued(best []float64, window int, tolerance float64) bool {
+	if window <= 0 || len(best) <= window {
+		return false
+	}
+	return math.Abs(best[len(best)-1]-best[len(best)-1-window]) <= tolerance
+}
+
+// GelmanRubin returns the potential scale reduction factor of chains of
+// equal length: how much wider the spread of all draws is than the spread
+// within a chain. It approaches 1 as the chains agree, and is NaN without at
+// least two chains of two draws.
+func GelmanRubin(chains [][]float64) float64 {
+	m := len(chains)
+	if m < 2 || len(chains[0]) < 2 {
+		return math.NaN()
+	}
+	n := len(chains[0])
+
+	means := make([]float64, m)
+	overall, within := 0.0, 0.0
+	for j, chain := range chains {
+		for _, x := range chain[:n] {
+			means[j] += x
+		}
+		means[j] /= float64(n)
+		overall += means[j]
+
+		variance := 0.0
+		for _, x := range chain[:n] {
+			variance += (x - means[j]) * (x - means[j])
+		}
+		within += variance / float64(n-1)
+	}
+	overall /= float64(m)
+	within /= float64(m)
+
+	between := 0.0
+	for _, mean := range means {
+		between += (mean - overall) * (mean - overall)
+	}
+	between *= float64(n) / float64(m-1)
+
+	if within == 0 {
+		if between == 0 {
+			// Every draw is the same
+			return 1
+		}
+		return math.Inf(1)
+	}
+	pooled := float64(n-1)/float64(n)*within + between/float64(n)
+	return math.Sqrt(pooled / within)
+}
+
+// SplitRHat checks the convergence of a sampler's draws, in the order they
+// were drawn: at evenly spaced checkpoints it splits the draws so far into
+// consecutive chains and computes their Gelman-Rubin statistic. It returns
+// the statistic at the last checkpoint, 0 when there are too few draws or
+// when chains without spread disagree, and the residual |R̂ - 1| of each
+// checkpoint with a finite statistic.
+func SplitRHat(draws []float64) (rHat float64, residuals []float64) {
+	interval := max(2*splitChains, (len(draws)+rHatCheckpoints-1)/rHatCheckpoints)
+	for n := interval; n < len(draws)+interval; n += interval {
+		checkpoint := GelmanRubin(split(draws[:min(n, len(draws))]))
+		if math.IsNaN(checkpoint) {
+			continue
+		}
+		if math.IsInf(checkpoint, 0) {
+			// Chains without spread that disagree have not converged
+			rHat = 0
+			continue
+		}
+		residuals = append(residuals, math.Abs(checkpoint-1))
+		rHat = checkpoint
+	}
+	return rHat, residuals
+}
+
+// split splits draws into consecutive chains of equal length, leaving out
+// the draws left over
+func split(draws []float64) [][]float64 {
+	n := len(draws) / splitChains
+	chains := make([][]float64, splitChains)
+	for j := range chains {
+		chains[j] = draws[j*n : (j+1)*n]
+	}
+	return chains
+}
@@ -0,0 +1,70 @@
+package convergence
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlateaued(t *testing.T) {
+	best := []float64{1, 3, 4, 4.0000001, 4.0000001, 4.0000001}
+	assert.True(t, Plateaued(best, 3, 1e-6))
+	assert.False(t, Plateaued(best, 4, 1e-6))
+	assert.False(t, Plateaued(best, 6, 1e-6), "window longer than the run")
+	assert.True(t, Plateaued([]float64{5, 2, 2, 2}, 2, 0), "minimizing")
+}
+
+func TestGelmanRubin(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	draw := func(mean float64) []float64 {
+		chain := make([]float64, 2000)
+		for i := range chain {
+			chain[i] = mean + r.NormFloat64()
+		}
+		return chain
+	}
+
+	assert.Less(t, GelmanRubin([][]float64{draw(0), draw(0), draw(0), draw(0)}), RHatThreshold)
+	assert.Greater(t, GelmanRubin([][]float64{draw(0), draw(0), draw(0), draw(3)}), 1.2)
+	assert.Equal(t, 1.0, GelmanRubin([][]float64{{2, 2}, {2, 2}}))
+	assert.True(t, math.IsInf(GelmanRubin([][]float64{{1, 1}, {2, 2}}), 1))
+	assert.True(t, math.IsNaN(GelmanRubin([][]float64{draw(0)})))
+}
+
+func TestSplitRHat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	draws := make([]float64, 4000)
+	for i := range draws {
+		draws[i] = r.NormFloat64()
+	}
+	rHat, residuals := SplitRHat(draws)
+	assert.Less(t, rHat, RHatThreshold)
+	assert.Len(t, residuals, 20)
+	assert.InDelta(t, math.Abs(rHat-1), residuals[len(residuals)-1], 1e-12)
+
+	// A run that drifts does not converge
+	for i := range draws {
+		draws[i] += float64(i) / 100
+	}
+	rHat, _ = SplitRHat(draws)
+	assert.Greater(t, rHat, 1.2)
+
+	// Too few draws to split, and chains without spread that disagree
+	rHat, residuals = SplitRHat([]float64{1, 2, 3})
+	assert.Zero(t, rHat)
+	assert.Empty(t, residuals)
+	rHat, residuals = SplitRHat([]float64{1, 1, 2, 2, 3, 3, 4, 4})
+	assert.Zero(t, rHat)
+	assert.Empty(t, residuals)
+}
+
+func TestDeadline(t *testing.T) {
+	assert.False(t, DeadlineAfter(0).Passed(), "no limit")
+	assert.False(t, DeadlineAfter(time.Hour).Passed())
+	deadline := DeadlineAfter(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	assert.True(t, deadline.Passed())
+}
@@ -0,0 +1,148 @@
+// Package costbenefit appraises an option by its time-phased costs and
+// benefits. Each period's net flow, its benefits less its costs, is
+// discounted to period 0 at a rate per period; the net present value is the
+// sum of the discounted flows, and the benefit-cost ratio the present value
+// of the benefits over that of the costs. The internal rate of return is
+// approximated as the lowest rate at which the net present value changes
+// sign, and the payback periods are the times at which the cumulative net
+// flows, undiscounted or discounted, first climb back to zero, each period's
+// flow after the first taken to accrue evenly over it.
+package costbenefit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxPeriod is the latest period a flow can fall in
+const MaxPeriod = 1000
+
+// Flow is a cost or benefit, or both, falling in a period
+type Flow struct {
+	// Period counts from 0, now
+	Period  int
+	Cost    float64
+	Benefit float64
+}
+
+// Result is the appraisal of an option's flows
+type Result struct {
+	NPV        float64
+	PVBenefits float64
+	PVCosts    float64
+	// BenefitCostRatio is undefined, and HasRatio false, without costs
+	BenefitCostRatio float64
+	HasRatio         bool
+	// IRR is undefined, and HasIRR false, when the net present value keeps
+	// its sign at every rate from -99% to 1000%
+	IRR    float64
+	HasIRR bool
+	// Payback and DiscountedPayback are undefined, and PaysBack and
+	// PaysBackDiscounted false, when the cumulative flows stay negative
+	Payback            float64
+	PaysBack           bool
+	DiscountedPayback  float64
+	PaysBackDiscounted bool
+}
+
+// Analyze appraises flows at rate
+func Analyze(flows []Flow, rate float64) (*Result, error) {
+	switch {
+	case len(flows) == 0:
+		return nil, errors.New("there are no costs or benefits")
+	case !(rate > -1) || math.IsInf(rate, 0):
+		return nil, errors.New("the discount rate must be finite and above -100%")
+	}
+	last := 0
+	for _, f := range flows {
+		switch {
+		case f.Period < 0 || f.Period > MaxPeriod:
+			return nil, fmt.Errorf("period %d is not from 0 to %d", f.Period, MaxPeriod)
+		case !(f.Cost >= 0) || math.IsInf(f.Cost, 0) || !(f.Benefit >= 0) || math.IsInf(f.Benefit, 0):
+			return nil, fmt.Errorf("the costs and benefits of period %d must be finite and not negative", f.Period)
+		}
+		if f.Period > last {
+			last = f.Period
+		}
+	}
+	costs := make([]float64, last+1)
+	benefits := make([]float64, last+1)
+	for _, f := range flows {
+		costs[f.Period] += f.Cost
+		benefits[f.Period] += f.Benefit
+	}
+	net := make([]float64, last+1)
+	discounted := make([]float64, last+1)
+	result := &Result{}
+	for t := range net {
+		factor := math.Pow(1+rate, -float64(t))
+		net[t] = benefits[t] - costs[t]
+		discounted[t] = net[t] * factor
+		result.PVBenefits += benefits[t] * factor
+		result.PVCosts += costs[t] * factor
+	}
+	result.NPV = result.PVBenefits - result.PVCosts
+	if result.PVCosts > 0 {
+		result.BenefitCostRatio, result.HasRatio = result.PVBenefits/result.PVCosts, true
+	}
+	result.IRR, result.HasIRR = irr(net)
+	result.Payback, result.PaysBack = payback(net)
+	result.DiscountedPayback, result.PaysBackDiscounted = payback(discounted)
+	return result, nil
+}
+
+// npv returns the net present value of net at rate
+func npv(net []float64, rate float64) float64 {
+	value := 0.0
+	for t, flow := range net {
+		value += flow * math.Pow(1+rate, -float64(t))
+	}
+	return value
+}
+
+// irr returns the lowest rate from -99% to 1000% at which the net present
+// value of net changes sign, found by scanning for a change and bisecting it
+func irr(net []float64) (float64, bool) {
+	const low, high, steps = -0.99, 10.0, 2000
+	previous, before := low, npv(net, low)
+	if before == 0 {
+		return 0, false
+	}
+	for k := 1; k <= steps; k++ {
+		rate := low + (high-low)*float64(k)/steps
+		value := npv(net, rate)
+		if value == 0 {
+			return rate, true
+		}
+		if before*value < 0 {
+			a, b := previous, rate
+			for i := 0; i < 100; i++ {
+				mid := (a + b) / 2
+				if npv(net, mid)*before > 0 {
+					a = mid
+				} else {
+					b = mid
+				}
+			}
+			return (a + b) / 2, true
+		}
+		previous, before = rate, value
+	}
+	return 0, false
+}
+
+// payback returns the time at which the cumulative sum of flows, once
+// negative, first climbs back to zero, interpolating within the period it
+// does so; flows that never turn negative pay back at once
+func payback(flows []float64) (float64, bool) {
+	cumulative, negative := 0.0, false
+	for t, flow := range flows {
+		if cumulative < 0 && cumulative+flow >= 0 {
+			return float64(t) - 1 - cumulative/flow, true
+		}
+		cumulative += flow
+		negative = negative || cumulative < 0
+	}
+	return 0, !negative
+}
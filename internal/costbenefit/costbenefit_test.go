@@ -0,0 +1,77 @@
+package costbenefit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze_DiscountsFlows(t *testing.T) {
+	// 1000 now returns 400 a year for three years, with 50 of upkeep
+	result, err := Analyze([]Flow{
+		{Period: 0, Cost: 1000},
+		{Period: 1, Benefit: 400, Cost: 50},
+		{Period: 2, Benefit: 400, Cost: 50},
+		{Period: 3, Benefit: 400, Cost: 50},
+	}, 0.1)
+	require.NoError(t, err)
+
+	annuity := 1/1.1 + 1/1.21 + 1/1.331
+	assert.InDelta(t, 400*annuity, result.PVBenefits, 1e-9)
+	assert.InDelta(t, 1000+50*annuity, result.PVCosts, 1e-9)
+	assert.InDelta(t, 350*annuity-1000, result.NPV, 1e-9)
+	require.True(t, result.HasRatio)
+	assert.InDelta(t, 400*annuity/(1000+50*annuity), result.BenefitCostRatio, 1e-12)
+
+	// 350 a year recovers 1000 two thirds into the third year
+	require.True(t, result.PaysBack)
+	assert.InDelta(t, 2+300.0/350, result.Payback, 1e-12)
+	assert.False(t, result.PaysBackDiscounted)
+
+	// The IRR zeroes the net present value
+	require.True(t, result.HasIRR)
+	assert.InDelta(t, 0.0248, result.IRR, 1e-4)
+	assert.InDelta(t, 0, npv([]float64{-1000, 350, 350, 350}, result.IRR), 1e-6)
+}
+
+func TestAnalyze_PaysBackDiscountedFlows(t *testing.T) {
+	result, err := Analyze([]Flow{{Period: 0, Cost: 100}, {Period: 1, Benefit: 110}, {Period: 2, Benefit: 121}}, 0.1)
+	require.NoError(t, err)
+	assert.InDelta(t, 100, result.NPV, 1e-9)
+	require.True(t, result.PaysBackDiscounted)
+	assert.InDelta(t, 1, result.DiscountedPayback, 1e-12)
+	assert.InDelta(t, 1-10.0/110, result.Payback, 1e-12)
+
+	// Costs that start later still need paying back
+	result, err = Analyze([]Flow{{Period: 1, Cost: 100}, {Period: 3, Benefit: 300}}, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 2+1.0/3, result.Payback, 1e-12)
+
+	// Benefits without costs pay back at once, with no ratio or IRR
+	result, err = Analyze([]Flow{{Period: 2, Benefit: 10}}, 0.05)
+	require.NoError(t, err)
+	assert.True(t, result.PaysBack)
+	assert.Equal(t, 0.0, result.Payback)
+	assert.False(t, result.HasRatio)
+	assert.False(t, result.HasIRR)
+}
+
+func TestAnalyze_RejectsInvalidFlows(t *testing.T) {
+	for name, c := range map[string]struct {
+		flows []Flow
+		rate  float64
+	}{
+		"no flows":        {nil, 0.1},
+		"rate":            {[]Flow{{Cost: 1}}, -1},
+		"NaN rate":        {[]Flow{{Cost: 1}}, math.NaN()},
+		"negative period": {[]Flow{{Period: -1, Cost: 1}}, 0.1},
+		"late period":     {[]Flow{{Period: MaxPeriod + 1, Cost: 1}}, 0.1},
+		"negative cost":   {[]Flow{{Cost: -1}}, 0.1},
+		"infinite":        {[]Flow{{Benefit: math.Inf(1)}}, 0.1},
+	} {
+		_, err := Analyze(c.flows, c.rate)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,143 @@
+package critic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// systemPrompt instructs the reviewing model how to critique a reasoning trace
+const systemPrompt = `You are a rigorous reviewer of reasoning traces.
+Review the reasoning you are given and respond with a JSON object containing:
+  "summary": a short overall assessment,
+  "logical_gaps": a list of unsupported leaps, contradictions or missing steps,
+  "missing_alternatives": a list of options, hypotheses or perspectives that were not considered.
+Respond with the JSON object only.`
+
+// Client sends reasoning content to an OpenAI-compatible chat completions endpoint for review
+type Client struct {
+	client   *http.Client
+	endpoint string
+	model    string
+	apiKey   string
+}
+
+// Review represents the structured critique returned by the reviewing model
+type Review struct {
+	Summary             string   `json:"summary"`
+	LogicalGaps         []string `json:"logical_gaps"`
+	MissingAlternatives []string `json:"missing_alternatives"`
+	Raw                 string   `json:"-"`
+}
+
+// chatRequest represents a chat completions request body
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+// chatMessage represents a single chat message
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatResponse represents the subset of the chat completions response we use
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// NewClient creates a new critic client
+func NewClient(endpoint, model, apiKey string, timeout time.Duration) *Client {
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+
+	return &Client{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		endpoint: endpoint,
+		model:    model,
+		apiKey:   apiKey,
+	}
+}
+
+// Model returns the name of the reviewing model
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Critique sends the given reasoning content for review
+func (c *Client) Critique(ctx context.Context, content string) (*Review, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: content},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoThink-Critic/1.0")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("critic endpoint returned status %d", resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var chatResp chatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("failed to parse critic response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("critic response contained no choices")
+	}
+
+	return parseReview(chatResp.Choices[0].Message.Content), nil
+}
+
+// parseReview extracts a structured review from the model output, falling back
+// to the raw text as the summary when the model did not return JSON
+func parseReview(text string) *Review {
+	review := &Review{Raw: text}
+
+	trimmed := strings.TrimSpace(text)
+	trimmed = strings.TrimPrefix(trimmed, "```json")
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	trimmed = strings.TrimSuffix(trimmed, "```")
+
+	if err := json.Unmarshal([]byte(strings.TrimSpace(trimmed)), review); err != nil {
+		review.Summary = strings.TrimSpace(text)
+	}
+
+	return review
+}
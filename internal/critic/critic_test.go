@@ -0,0 +1,99 @@
+package critic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endpoint serves a chat completions endpoint that answers every request with
+// status and body, recording the last request it received
+func endpoint(t *testing.T, status int, body string) (*httptest.Server, *chatRequest, *http.Header) {
+	t.Helper()
+
+	var request chatRequest
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		header = r.Header.Clone()
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &request, &header
+}
+
+// reply returns a chat completions response whose only choice says content
+func reply(t *testing.T, content string) string {
+	t.Helper()
+
+	var response chatResponse
+	response.Choices = append(response.Choices, struct {
+		Message chatMessage `json:"message"`
+	}{Message: chatMessage{Role: "assistant", Content: content}})
+	body, err := json.Marshal(response)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestCritique_ParsesAJSONReview(t *testing.T) {
+	content := `{"summary": "Mostly sound", "logical_gaps": ["Step 3 assumes the cache is warm"], "missing_alternatives": ["Batching the writes"]}`
+	server, request, header := endpoint(t, http.StatusOK, reply(t, content))
+
+	review, err := NewClient(server.URL, "reviewer", "secret", time.Second).Critique(context.Background(), "1. Profile\n2. Cache\n3. Ship")
+	require.NoError(t, err)
+	assert.Equal(t, "Mostly sound", review.Summary)
+	assert.Equal(t, []string{"Step 3 assumes the cache is warm"}, review.LogicalGaps)
+	assert.Equal(t, []string{"Batching the writes"}, review.MissingAlternatives)
+	assert.Equal(t, content, review.Raw)
+
+	assert.Equal(t, "reviewer", request.Model)
+	require.Len(t, request.Messages, 2)
+	assert.Equal(t, "system", request.Messages[0].Role)
+	assert.Equal(t, chatMessage{Role: "user", Content: "1. Profile\n2. Cache\n3. Ship"}, request.Messages[1])
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+}
+
+func TestCritique_ParsesAFencedReview(t *testing.T) {
+	content := "```json\n{\"summary\": \"Thin\", \"logical_gaps\": [\"No baseline\"]}\n```"
+	server, _, header := endpoint(t, http.StatusOK, reply(t, content))
+
+	review, err := NewClient(server.URL, "reviewer", "", time.Second).Critique(context.Background(), "Ship it")
+	require.NoError(t, err)
+	assert.Equal(t, "Thin", review.Summary)
+	assert.Equal(t, []string{"No baseline"}, review.LogicalGaps)
+	assert.Empty(t, review.MissingAlternatives)
+	assert.Empty(t, header.Get("Authorization"), "no API key, no authorization")
+}
+
+func TestCritique_KeepsAPlainTextReviewAsTheSummary(t *testing.T) {
+	server, _, _ := endpoint(t, http.StatusOK, reply(t, "  The reasoning skips the rollback plan.\n"))
+
+	review, err := NewClient(server.URL, "reviewer", "", time.Second).Critique(context.Background(), "Ship it")
+	require.NoError(t, err)
+	assert.Equal(t, "The reasoning skips the rollback plan.", review.Summary)
+	assert.Empty(t, review.LogicalGaps)
+	assert.Equal(t, "  The reasoning skips the rollback plan.\n", review.Raw)
+}
+
+func TestCritique_FailsOnABadResponse(t *testing.T) {
+	server, _, _ := endpoint(t, http.StatusBadGateway, `{"error": "upstream down"}`)
+	_, err := NewClient(server.URL, "reviewer", "", time.Second).Critique(context.Background(), "Ship it")
+	assert.EqualError(t, err, "critic endpoint returned status 502")
+
+	server, _, _ = endpoint(t, http.StatusOK, `{"choices": []}`)
+	_, err = NewClient(server.URL, "reviewer", "", time.Second).Critique(context.Background(), "Ship it")
+	assert.EqualError(t, err, "critic response contained no choices")
+
+	server, _, _ = endpoint(t, http.StatusOK, `not json`)
+	_, err = NewClient(server.URL, "reviewer", "", time.Second).Critique(context.Background(), "Ship it")
+	assert.ErrorContains(t, err, "failed to parse critic response")
+}
@@ -0,0 +1,212 @@
+// Package decisiontree evaluates decision trees by expected-value rollback.
+// A tree is made of decision nodes, whose branches are the choices open to
+// the decision maker, chance nodes, whose branches are outcomes with
+// probabilities, and terminal nodes. Every node may carry a payoff, collected
+// on reaching it, so that a branch's cost sits on the node it leads to and
+// an outcome's value on its terminal node. Rolling back from the leaves, a
+// chance node is worth its payoff plus the probability-weighted value of its
+// branches and a decision node its payoff plus the value of its best branch.
+package decisiontree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Node types
+const (
+	Decision = "decision"
+	Chance   = "chance"
+	Terminal = "terminal"
+)
+
+// MaxNodes is the most nodes a tree can have
+const MaxNodes = 10000
+
+// Node is a node of a tree, given by its parent
+type Node struct {
+	ID string
+	// Parent is the ID of the node's parent, empty for the root
+	Parent string
+	Type   string
+	Label  string
+	// Probability is the chance of reaching the node from its parent, which
+	// must be a chance node
+	Probability float64
+	Payoff      float64
+}
+
+// Options control an evaluation
+type Options struct {
+	// Minimize makes payoffs costs, so that decision nodes choose their
+	// branch of least value
+	Minimize bool
+}
+
+// Evaluated is a node with its rolled-back value
+type Evaluated struct {
+	Node
+	// Value is the expected payoff from reaching the node on, the node's own
+	// included
+	Value float64
+	// Choice is the ID of a decision node's best branch
+	Choice string
+	// Optimal reports whether the optimal strategy can reach the node
+	Optimal bool
+	// ReachProbability is the chance that the optimal strategy reaches the
+	// node, 0 for nodes it never reaches
+	ReachProbability float64
+}
+
+// Outcome is a terminal node the optimal strategy can reach
+type Outcome struct {
+	Node string
+	// Probability is the chance of reaching the node
+	Probability float64
+	// Payoff is the total of the payoffs on the way from the root
+	Payoff float64
+}
+
+// Result is an evaluated tree
+type Result struct {
+	// Nodes holds the nodes in their given order
+	Nodes []Evaluated
+	// Value is the root's value, the optimal strategy's expected payoff
+	Value float64
+	// Strategy holds the decision nodes the optimal strategy can reach,
+	// depth first, each with its choice
+	Strategy []Evaluated
+	// Outcomes holds the terminal nodes the optimal strategy can reach, most
+	// probable first: its risk profile
+	Outcomes []Outcome
+}
+
+// Evaluate rolls back the tree of nodes
+func Evaluate(nodes []Node, opts Options) (*Result, error) {
+	switch {
+	case len(nodes) == 0:
+		return nil, errors.New("the tree has no nodes")
+	case len(nodes) > MaxNodes:
+		return nil, fmt.Errorf("the tree has more than %d nodes", MaxNodes)
+	}
+
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		switch {
+		case n.ID == "":
+			return nil, fmt.Errorf("node %d has no ID", i+1)
+		case n.Type != Decision && n.Type != Chance && n.Type != Terminal:
+			return nil, fmt.Errorf("node %s has unknown type %q", n.ID, n.Type)
+		case math.IsNaN(n.Payoff) || math.IsInf(n.Payoff, 0):
+			return nil, fmt.Errorf("node %s has a non-finite payoff", n.ID)
+		}
+		if _, ok := index[n.ID]; ok {
+			return nil, fmt.Errorf("node %s is listed twice", n.ID)
+		}
+		index[n.ID] = i
+	}
+
+	root := -1
+	children := make([][]int, len(nodes))
+	for i, n := range nodes {
+		if n.Parent == "" {
+			if root >= 0 {
+				return nil, fmt.Errorf("nodes %s and %s are both roots", nodes[root].ID, n.ID)
+			}
+			root = i
+			continue
+		}
+		parent, ok := index[n.Parent]
+		if !ok {
+			return nil, fmt.Errorf("node %s has unknown parent %s", n.ID, n.Parent)
+		}
+		if nodes[parent].Type == Terminal {
+			return nil, fmt.Errorf("node %s has terminal parent %s", n.ID, n.Parent)
+		}
+		if nodes[parent].Type == Chance && !(n.Probability >= 0 && n.Probability <= 1) {
+			return nil, fmt.Errorf("node %s needs a probability between 0 and 1", n.ID)
+		}
+		children[parent] = append(children[parent], i)
+	}
+	if root < 0 {
+		return nil, errors.New("the tree has no root, a node without a parent")
+	}
+	for i, n := range nodes {
+		if n.Type == Terminal {
+			continue
+		}
+		if len(children[i]) == 0 {
+			return nil, fmt.Errorf("%s node %s has no branches", n.Type, n.ID)
+		}
+		if n.Type == Chance {
+			total := 0.0
+			for _, c := range children[i] {
+				total += nodes[c].Probability
+			}
+			if math.Abs(total-1) > 1e-6 {
+				return nil, fmt.Errorf("the probabilities of the branches of chance node %s sum to %.6g, not 1", n.ID, total)
+			}
+		}
+	}
+
+	result := &Result{Nodes: make([]Evaluated, len(nodes))}
+	for i, n := range nodes {
+		result.Nodes[i] = Evaluated{Node: n}
+	}
+
+	// Roll back depth first from the root; nodes it never visits lie on a
+	// cycle, detached from the root
+	visited := 0
+	var rollback func(i int) float64
+	rollback = func(i int) float64 {
+		visited++
+		e := &result.Nodes[i]
+		value := 0.0
+		switch e.Type {
+		case Chance:
+			for _, c := range children[i] {
+				value += nodes[c].Probability * rollback(c)
+			}
+		case Decision:
+			best := -1
+			for _, c := range children[i] {
+				v := rollback(c)
+				if best < 0 || (opts.Minimize && v < value) || (!opts.Minimize && v > value) {
+					best, value = c, v
+				}
+			}
+			e.Choice = nodes[best].ID
+		}
+		e.Value = e.Payoff + value
+		return e.Value
+	}
+	result.Value = rollback(root)
+	if visited != len(nodes) {
+		return nil, errors.New("some nodes are not connected to the root")
+	}
+
+	// Walk the optimal strategy from the root
+	var walk func(i int, probability, payoff float64)
+	walk = func(i int, probability, payoff float64) {
+		e := &result.Nodes[i]
+		e.Optimal = true
+		e.ReachProbability = probability
+		payoff += e.Payoff
+		switch e.Type {
+		case Terminal:
+			result.Outcomes = append(result.Outcomes, Outcome{Node: e.ID, Probability: probability, Payoff: payoff})
+		case Decision:
+			result.Strategy = append(result.Strategy, *e)
+			walk(index[e.Choice], probability, payoff)
+		case Chance:
+			for _, c := range children[i] {
+				walk(c, probability*nodes[c].Probability, payoff)
+			}
+		}
+	}
+	walk(root, 1, 0)
+	sort.SliceStable(result.Outcomes, func(a, b int) bool { return result.Outcomes[a].Probability > result.Outcomes[b].Probability })
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package decisiontree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// oilTree asks whether to test for oil, at 10, before drilling, at 70, for a
+// strike worth 200. Oil is there with probability 0.42; a test is positive
+// with probability 0.4, after which oil is there with probability 0.75, and
+// 0.2 after a negative test.
+var oilTree = []Node{
+	{ID: "root", Type: Decision},
+	{ID: "test", Parent: "root", Type: Chance, Payoff: -10},
+	{ID: "positive", Parent: "test", Type: Decision, Probability: 0.4},
+	{ID: "drill+", Parent: "positive", Type: Chance, Payoff: -70},
+	{ID: "oil+", Parent: "drill+", Type: Terminal, Probability: 0.75, Payoff: 200},
+	{ID: "dry+", Parent: "drill+", Type: Terminal, Probability: 0.25},
+	{ID: "abandon+", Parent: "positive", Type: Terminal},
+	{ID: "negative", Parent: "test", Type: Decision, Probability: 0.6},
+	{ID: "drill-", Parent: "negative", Type: Chance, Payoff: -70},
+	{ID: "oil-", Parent: "drill-", Type: Terminal, Probability: 0.2, Payoff: 200},
+	{ID: "dry-", Parent: "drill-", Type: Terminal, Probability: 0.8},
+	{ID: "abandon-", Parent: "negative", Type: Terminal},
+	{ID: "drill", Parent: "root", Type: Chance, Payoff: -70},
+	{ID: "oil", Parent: "drill", Type: Terminal, Probability: 0.42, Payoff: 200},
+	{ID: "dry", Parent: "drill", Type: Terminal, Probability: 0.58},
+	{ID: "skip", Parent: "root", Type: Terminal},
+}
+
+func TestEvaluate_RollsBackExpectedValues(t *testing.T) {
+	result, err := Evaluate(oilTree, Options{})
+	require.NoError(t, err)
+
+	// Drilling after a positive test is worth 0.75·200 - 70, after a
+	// negative one 0.2·200 - 70, so it is abandoned; testing is worth
+	// 0.4·80 - 10, and drilling untested 0.42·200 - 70
+	values := make(map[string]float64)
+	for _, n := range result.Nodes {
+		values[n.ID] = n.Value
+	}
+	assert.InDelta(t, 80, values["drill+"], 1e-9)
+	assert.InDelta(t, -30, values["drill-"], 1e-9)
+	assert.InDelta(t, 0, values["negative"], 1e-9)
+	assert.InDelta(t, 22, values["test"], 1e-9)
+	assert.InDelta(t, 14, values["drill"], 1e-9)
+	assert.InDelta(t, 22, result.Value, 1e-9)
+
+	require.Len(t, result.Strategy, 3)
+	for i, want := range [][2]string{{"root", "test"}, {"positive", "drill+"}, {"negative", "abandon-"}} {
+		assert.Equal(t, want[0], result.Strategy[i].ID)
+		assert.Equal(t, want[1], result.Strategy[i].Choice)
+	}
+	assert.InDelta(t, 0.6, result.Strategy[2].ReachProbability, 1e-12)
+}
+
+func TestEvaluate_ProfilesTheOptimalStrategy(t *testing.T) {
+	result, err := Evaluate(oilTree, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, result.Outcomes, 3)
+	for i, want := range []Outcome{
+		{Node: "abandon-", Probability: 0.6, Payoff: -10},
+		{Node: "oil+", Probability: 0.3, Payoff: 120},
+		{Node: "dry+", Probability: 0.1, Payoff: -80},
+	} {
+		assert.Equal(t, want.Node, result.Outcomes[i].Node)
+		assert.InDelta(t, want.Probability, result.Outcomes[i].Probability, 1e-12)
+		assert.InDelta(t, want.Payoff, result.Outcomes[i].Payoff, 1e-12)
+	}
+
+	optimal := make(map[string]bool)
+	for _, n := range result.Nodes {
+		optimal[n.ID] = n.Optimal
+	}
+	assert.True(t, optimal["dry+"])
+	assert.False(t, optimal["drill"])
+	assert.False(t, optimal["oil-"])
+}
+
+func TestEvaluate_MinimizesCosts(t *testing.T) {
+	result, err := Evaluate([]Node{
+		{ID: "route", Type: Decision},
+		{ID: "highway", Parent: "route", Type: Chance},
+		{ID: "clear", Parent: "highway", Type: Terminal, Probability: 0.7, Payoff: 30},
+		{ID: "jam", Parent: "highway", Type: Terminal, Probability: 0.3, Payoff: 90},
+		{ID: "backroads", Parent: "route", Type: Terminal, Payoff: 45},
+	}, Options{Minimize: true})
+	require.NoError(t, err)
+	assert.InDelta(t, 45, result.Value, 1e-12)
+	assert.Equal(t, "backroads", result.Nodes[0].Choice)
+
+	result, err = Evaluate([]Node{{ID: "only", Type: Terminal, Payoff: 5}}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, result.Value)
+	assert.Empty(t, result.Strategy)
+	assert.Equal(t, []Outcome{{Node: "only", Probability: 1, Payoff: 5}}, result.Outcomes)
+}
+
+func TestEvaluate_RejectsInvalidTrees(t *testing.T) {
+	for name, nodes := range map[string][]Node{
+		"empty":          nil,
+		"no ID":          {{Type: Terminal}},
+		"unknown type":   {{ID: "a", Type: "maybe"}},
+		"duplicate":      {{ID: "a", Type: Terminal}, {ID: "a", Type: Terminal}},
+		"two roots":      {{ID: "a", Type: Terminal}, {ID: "b", Type: Terminal}},
+		"no root":        {{ID: "a", Parent: "b", Type: Decision}, {ID: "b", Parent: "a", Type: Decision}},
+		"unknown parent": {{ID: "a", Type: Decision}, {ID: "b", Parent: "c", Type: Terminal}},
+		"terminal parent": {
+			{ID: "a", Type: Terminal}, {ID: "b", Parent: "a", Type: Terminal},
+		},
+		"no branches": {{ID: "a", Type: Chance}},
+		"probabilities": {
+			{ID: "a", Type: Chance}, {ID: "b", Parent: "a", Type: Terminal, Probability: 0.5}, {ID: "c", Parent: "a", Type: Terminal, Probability: 0.4},
+		},
+		"detached cycle": {
+			{ID: "a", Type: Decision}, {ID: "b", Parent: "a", Type: Terminal},
+			{ID: "c", Parent: "d", Type: Decision}, {ID: "d", Parent: "c", Type: Decision},
+		},
+	} {
+		_, err := Evaluate(nodes, Options{})
+		assert.Error(t, err, name)
+	}
+}
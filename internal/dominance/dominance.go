@@ -0,0 +1,237 @@
+// Package dominance compares two distributions of outcomes, each given by
+// samples, by stochastic dominance and by their tails. A dominates B at
+// first order when A's distribution function lies nowhere above B's, so that
+// every decision maker who prefers better outcomes prefers A; at second
+// order when the integral of A's lies nowhere above B's, so that every
+// risk-averse one does. The tails are measured by the value at risk and the
+// conditional value at risk (CVaR), the mean of the worst outcomes.
+package dominance
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/rainmana/gothink/internal/stats"
+)
+
+// Distributions a result names
+const (
+	A = "a"
+	B = "b"
+)
+
+// Options control a comparison
+type Options struct {
+	// Alpha is the share of the worst outcomes the tail measures cover,
+	// such as 0.05
+	Alpha float64
+	// Minimize says lower outcomes are better, as for costs or losses;
+	// otherwise higher ones are
+	Minimize bool
+}
+
+// Summary describes a distribution: its mean, spread and range, and its
+// tail at Alpha. VaR is the outcome the worst Alpha of outcomes are no
+// better than, and CVaR their mean.
+type Summary struct {
+	Samples int
+	Mean    float64
+	StdDev  float64
+	Min     float64
+	Max     float64
+	VaR     float64
+	CVaR    float64
+}
+
+// Result is the outcome of a comparison
+type Result struct {
+	A Summary
+	B Summary
+	// FirstOrder and SecondOrder name the distribution, A or B, that
+	// dominates the other at that order, and are empty when neither does.
+	// First-order dominance implies second-order dominance.
+	FirstOrder  string
+	SecondOrder string
+	// Leader is the distribution with the better mean, empty when the means
+	// are equal, and Epsilon the share of the area between the distribution
+	// functions where Leader's is the worse: 0 when Leader dominates at
+	// first order, and small when it almost does in the sense of Leshno and
+	// Levy
+	Leader  string
+	Epsilon float64
+	// KolmogorovSmirnov is the largest gap between the distribution
+	// functions
+	KolmogorovSmirnov float64
+	// ProbabilityABetter is the chance that a draw of A is better than an
+	// independent draw of B, counting ties as half
+	ProbabilityABetter float64
+}
+
+// Compare compares the distributions of samples a and b
+func Compare(a, b []float64, opts Options) (*Result, error) {
+	switch {
+	case len(a) == 0 || len(b) == 0:
+		return nil, errors.New("each distribution needs at least 1 sample")
+	case !(opts.Alpha > 0 && opts.Alpha < 1):
+		return nil, errors.New("alpha must be within (0, 1)")
+	}
+	for _, samples := range [][]float64{a, b} {
+		for _, x := range samples {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return nil, errors.New("the samples must be finite")
+			}
+		}
+	}
+
+	// Compare as if higher were better, negating outcomes to be minimized
+	sign := 1.0
+	if opts.Minimize {
+		sign = -1
+	}
+	x, y := oriented(a, sign), oriented(b, sign)
+	result := &Result{A: summarize(x, sign, opts.Alpha), B: summarize(y, sign, opts.Alpha)}
+
+	// Walk the merged support, where the distribution functions step, keeping
+	// their integrals up to each point
+	var gapA, gapB, integralA, integralB float64
+	firstA, firstB, secondA, secondB := true, true, true, true
+	strictFirst, strictSecond := false, false
+	i, j := 0, 0
+	span := math.Max(x[len(x)-1], y[len(y)-1]) - math.Min(x[0], y[0])
+	tolerance := 1e-12 * math.Max(1, span)
+	var previous float64
+	for i < len(x) || j < len(y) {
+		point := math.Inf(1)
+		if i < len(x) {
+			point = x[i]
+		}
+		if j < len(y) {
+			point = math.Min(point, y[j])
+		}
+		if i > 0 || j > 0 {
+			// The distribution functions hold their values since the last point
+			fa, fb := float64(i)/float64(len(x)), float64(j)/float64(len(y))
+			width := point - previous
+			integralA += fa * width
+			integralB += fb * width
+			gapA += math.Max(0, fa-fb) * width
+			gapB += math.Max(0, fb-fa) * width
+			secondA = secondA && integralA <= integralB+tolerance
+			secondB = secondB && integralB <= integralA+tolerance
+			strictSecond = strictSecond || math.Abs(integralA-integralB) > tolerance
+		}
+		for i < len(x) && x[i] == point {
+			i++
+		}
+		for j < len(y) && y[j] == point {
+			j++
+		}
+		fa, fb := float64(i)/float64(len(x)), float64(j)/float64(len(y))
+		firstA = firstA && fa <= fb
+		firstB = firstB && fb <= fa
+		strictFirst = strictFirst || fa != fb
+		result.KolmogorovSmirnov = math.Max(result.KolmogorovSmirnov, math.Abs(fa-fb))
+		previous = point
+	}
+
+	switch {
+	case strictFirst && firstA:
+		result.FirstOrder, result.SecondOrder = A, A
+	case strictFirst && firstB:
+		result.FirstOrder, result.SecondOrder = B, B
+	case strictSecond && secondA:
+		result.SecondOrder = A
+	case strictSecond && secondB:
+		result.SecondOrder = B
+	}
+
+	meanX, meanY := sign*result.A.Mean, sign*result.B.Mean
+	switch {
+	case meanX > meanY:
+		result.Leader = A
+		if gapA+gapB > 0 {
+			result.Epsilon = gapA / (gapA + gapB)
+		}
+	case meanY > meanX:
+		result.Leader = B
+		if gapA+gapB > 0 {
+			result.Epsilon = gapB / (gapA + gapB)
+		}
+	}
+
+	result.ProbabilityABetter = probabilityBetter(x, y)
+	return result, nil
+}
+
+// Describe summarizes the distribution of samples, its tail at opts.Alpha
+func Describe(samples []float64, opts Options) (Summary, error) {
+	switch {
+	case len(samples) == 0:
+		return Summary{}, errors.New("the distribution needs at least 1 sample")
+	case !(opts.Alpha > 0 && opts.Alpha < 1):
+		return Summary{}, errors.New("alpha must be within (0, 1)")
+	}
+	for _, x := range samples {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return Summary{}, errors.New("the samples must be finite")
+		}
+	}
+	sign := 1.0
+	if opts.Minimize {
+		sign = -1
+	}
+	return summarize(oriented(samples, sign), sign, opts.Alpha), nil
+}
+
+// oriented returns samples times sign, sorted
+func oriented(samples []float64, sign float64) []float64 {
+	sorted := make([]float64, len(samples))
+	for i, v := range samples {
+		sorted[i] = sign * v
+	}
+	sort.Float64s(sorted)
+	return sorted
+}
+
+// summarize describes sorted, samples oriented by sign so that higher is
+// better, in the samples' own units
+func summarize(sorted []float64, sign, alpha float64) Summary {
+	n := float64(len(sorted))
+	moments := stats.Describe(sorted)
+	s := Summary{Samples: len(sorted), StdDev: moments.StdDev}
+
+	// The worst alpha of the outcomes, counting in part the outcome the share
+	// ends within; alpha < 1 leaves it within the samples
+	tail := alpha * n
+	whole := int(math.Floor(tail))
+	worst := (tail - float64(whole)) * sorted[whole]
+	for _, v := range sorted[:whole] {
+		worst += v
+	}
+	at := sorted[max(int(math.Ceil(tail))-1, 0)]
+
+	s.Mean, s.VaR, s.CVaR = sign*moments.Mean, sign*at, sign*worst/tail
+	s.Min, s.Max = sign*sorted[0], sign*sorted[len(sorted)-1]
+	if sign < 0 {
+		s.Min, s.Max = s.Max, s.Min
+	}
+	return s
+}
+
+// probabilityBetter returns the chance that a draw of sorted x exceeds a draw
+// of sorted y, counting ties as half
+func probabilityBetter(x, y []float64) float64 {
+	wins := 0.0
+	below, through := 0, 0
+	for _, v := range x {
+		for below < len(y) && y[below] < v {
+			below++
+		}
+		for through < len(y) && y[through] <= v {
+			through++
+		}
+		wins += float64(below) + float64(through-below)/2
+	}
+	return wins / (float64(len(x)) * float64(len(y)))
+}
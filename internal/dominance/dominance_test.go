@@ -0,0 +1,123 @@
+package dominance
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare_FindsFirstOrderDominanceOfAShift(t *testing.T) {
+	result, err := Compare([]float64{4, 2, 3, 1}, []float64{2, 3, 4, 5}, Options{Alpha: 0.25})
+	require.NoError(t, err)
+
+	assert.Equal(t, B, result.FirstOrder)
+	assert.Equal(t, B, result.SecondOrder)
+	assert.Equal(t, B, result.Leader)
+	assert.Zero(t, result.Epsilon)
+	assert.InDelta(t, 0.25, result.KolmogorovSmirnov, 1e-12)
+
+	// a beats b in 3 of the 16 pairs and ties in 3
+	assert.InDelta(t, 4.5/16, result.ProbabilityABetter, 1e-12)
+
+	assert.Equal(t, 4, result.A.Samples)
+	assert.InDelta(t, 2.5, result.A.Mean, 1e-12)
+	assert.InDelta(t, math.Sqrt(5.0/3), result.A.StdDev, 1e-12)
+	assert.Equal(t, 1.0, result.A.Min)
+	assert.Equal(t, 4.0, result.A.Max)
+	assert.Equal(t, 1.0, result.A.VaR)
+	assert.Equal(t, 1.0, result.A.CVaR)
+}
+
+func TestCompare_PrefersLessSpreadAtSecondOrder(t *testing.T) {
+	result, err := Compare([]float64{0, 10}, []float64{5, 5}, Options{Alpha: 0.5})
+	require.NoError(t, err)
+
+	// A sure 5 beats an even chance of 0 or 10 for the risk-averse only
+	assert.Empty(t, result.FirstOrder)
+	assert.Equal(t, B, result.SecondOrder)
+	assert.Empty(t, result.Leader)
+	assert.Equal(t, 0.0, result.A.CVaR)
+	assert.Equal(t, 5.0, result.B.CVaR)
+	assert.InDelta(t, 0.5, result.ProbabilityABetter, 1e-12)
+}
+
+func TestCompare_MeasuresAlmostDominance(t *testing.T) {
+	result, err := Compare([]float64{0, 10, 10, 10}, []float64{5, 5, 5, 5}, Options{Alpha: 0.05})
+	require.NoError(t, err)
+
+	// a's distribution function is the higher over [0, 5), an area of 1.25,
+	// and b's over [5, 10), an area of 3.75
+	assert.Empty(t, result.FirstOrder)
+	assert.Empty(t, result.SecondOrder)
+	assert.Equal(t, A, result.Leader)
+	assert.InDelta(t, 0.25, result.Epsilon, 1e-12)
+	assert.InDelta(t, 0.75, result.KolmogorovSmirnov, 1e-12)
+}
+
+func TestCompare_AveragesAFractionalTail(t *testing.T) {
+	samples := make([]float64, 100)
+	for i := range samples {
+		samples[i] = float64(100 - i)
+	}
+	result, err := Compare(samples, samples, Options{Alpha: 0.05})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, result.A.VaR)
+	assert.InDelta(t, 3, result.A.CVaR, 1e-12)
+	assert.Empty(t, result.SecondOrder, "a distribution does not dominate itself")
+	assert.Zero(t, result.KolmogorovSmirnov)
+
+	// The worst 2.5 outcomes are 1, 2 and half of 3
+	result, err = Compare(samples, samples, Options{Alpha: 0.025})
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, result.A.VaR)
+	assert.InDelta(t, 4.5/2.5, result.A.CVaR, 1e-12)
+}
+
+func TestCompare_MinimizesCosts(t *testing.T) {
+	result, err := Compare([]float64{1, 2, 3, 4}, []float64{2, 3, 4, 5}, Options{Alpha: 0.25, Minimize: true})
+	require.NoError(t, err)
+
+	// Lower costs are better, so the worst outcomes are the highest
+	assert.Equal(t, A, result.FirstOrder)
+	assert.Equal(t, A, result.Leader)
+	assert.Equal(t, 1.0, result.A.Min)
+	assert.Equal(t, 4.0, result.A.Max)
+	assert.InDelta(t, 2.5, result.A.Mean, 1e-12)
+	assert.Equal(t, 4.0, result.A.CVaR)
+	assert.Equal(t, 5.0, result.B.CVaR)
+	assert.InDelta(t, 1-4.5/16, result.ProbabilityABetter, 1e-12)
+}
+
+func TestDescribe_SummarizesOneDistribution(t *testing.T) {
+	summary, err := Describe([]float64{4, 2, 3, 1}, Options{Alpha: 0.25})
+	require.NoError(t, err)
+	compared, err := Compare([]float64{4, 2, 3, 1}, []float64{0}, Options{Alpha: 0.25})
+	require.NoError(t, err)
+	assert.Equal(t, compared.A, summary)
+
+	summary, err = Describe([]float64{1, 2, 3, 4}, Options{Alpha: 0.5, Minimize: true})
+	require.NoError(t, err)
+	assert.InDelta(t, 3.5, summary.CVaR, 1e-12)
+
+	_, err = Describe(nil, Options{Alpha: 0.25})
+	assert.Error(t, err)
+}
+
+func TestCompare_RejectsInvalidComparisons(t *testing.T) {
+	valid := []float64{1, 2}
+	for name, c := range map[string]struct {
+		a, b  []float64
+		alpha float64
+	}{
+		"no samples": {nil, valid, 0.05},
+		"alpha 0":    {valid, valid, 0},
+		"alpha 1":    {valid, valid, 1},
+		"NaN":        {[]float64{math.NaN()}, valid, 0.05},
+		"infinite":   {valid, []float64{math.Inf(-1)}, 0.05},
+	} {
+		_, err := Compare(c.a, c.b, Options{Alpha: c.alpha})
+		assert.Error(t, err, name)
+	}
+}
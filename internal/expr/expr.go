@@ -0,0 +1,269 @@
+// Package expr compiles the small expression language callers use to define
+// objectives, outputs, dynamics and rewards textually. An expression combines
+// numbers, variables and the constants pi and e with arithmetic, comparison
+// and logical operators, parentheses and a fixed set of functions. It has no
+// access to anything but the variables it is given, so expressions from
+// clients are safe to evaluate.
+//
+// Comparisons and logical operators yield 1 for true and 0 for false, and
+// treat any nonzero operand as true, so ifelse(x > 0 && y > 0, x*y, 0) is a
+// valid expression.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Expression evaluates a compiled expression at an assignment of its
+// variables
+type Expression func(values map[string]float64) (float64, error)
+
+// evaluator computes the value of a compiled node
+type evaluator func(values map[string]float64) float64
+
+// function is a function expressions may call
+type function struct {
+	// arity is the number of arguments, or the least number when variadic
+	arity    int
+	variadic bool
+	call     func(args []float64) float64
+}
+
+// functions are the functions expressions may call, apart from ifelse, which
+// evaluates only the branch it picks
+var functions = map[string]function{
+	"sin":   {arity: 1, call: func(a []float64) float64 { return math.Sin(a[0]) }},
+	"cos":   {arity: 1, call: func(a []float64) float64 { return math.Cos(a[0]) }},
+	"tan":   {arity: 1, call: func(a []float64) float64 { return math.Tan(a[0]) }},
+	"exp":   {arity: 1, call: func(a []float64) float64 { return math.Exp(a[0]) }},
+	"log":   {arity: 1, call: func(a []float64) float64 { return math.Log(a[0]) }},
+	"log10": {arity: 1, call: func(a []float64) float64 { return math.Log10(a[0]) }},
+	"sqrt":  {arity: 1, call: func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"abs":   {arity: 1, call: func(a []float64) float64 { return math.Abs(a[0]) }},
+	"tanh":  {arity: 1, call: func(a []float64) float64 { return math.Tanh(a[0]) }},
+	"floor": {arity: 1, call: func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {arity: 1, call: func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"round": {arity: 1, call: func(a []float64) float64 { return math.Round(a[0]) }},
+	"sign": {arity: 1, call: func(a []float64) float64 {
+		if a[0] == 0 {
+			return 0
+		}
+		return math.Copysign(1, a[0])
+	}},
+	"pow":   {arity: 2, call: func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"hypot": {arity: 2, call: func(a []float64) float64 { return math.Hypot(a[0], a[1]) }},
+	"clamp": {arity: 3, call: func(a []float64) float64 { return math.Min(math.Max(a[0], a[1]), a[2]) }},
+	"min": {arity: 2, variadic: true, call: func(a []float64) float64 {
+		m := a[0]
+		for _, x := range a[1:] {
+			m = math.Min(m, x)
+		}
+		return m
+	}},
+	"max": {arity: 2, variadic: true, call: func(a []float64) float64 {
+		m := a[0]
+		for _, x := range a[1:] {
+			m = math.Max(m, x)
+		}
+		return m
+	}},
+}
+
+// constants are the named constants expressions may use
+var constants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+// Functions returns the names of the functions expressions may call, sorted
+func Functions() []string {
+	names := []string{"ifelse"}
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Compile compiles expression over variables. Evaluating the expression
+// fails when its value is not finite.
+func Compile(expression string, variables []string) (Expression, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, errors.New("the expression is empty")
+	}
+	tree, err := parser.ParseExpr(expression)
+	if err != nil {
+		return nil, fmt.Errorf("expression does not parse: %v", err)
+	}
+
+	names := make(map[string]bool, len(variables))
+	for _, name := range variables {
+		names[name] = true
+	}
+	eval, err := compile(tree, names)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(values map[string]float64) (float64, error) {
+		value := eval(values)
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return 0, fmt.Errorf("expression is not finite at %v", values)
+		}
+		return value, nil
+	}, nil
+}
+
+// truth returns 1 for true and 0 for false
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// compile turns a node into an evaluator
+func compile(node ast.Expr, names map[string]bool) (evaluator, error) {
+	switch e := node.(type) {
+	case *ast.ParenExpr:
+		return compile(e.X, names)
+
+	case *ast.BasicLit:
+		if e.Kind != token.INT && e.Kind != token.FLOAT {
+			return nil, fmt.Errorf("expression has unsupported literal %s", e.Value)
+		}
+		value, err := strconv.ParseFloat(e.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expression has invalid number %s", e.Value)
+		}
+		return func(map[string]float64) float64 { return value }, nil
+
+	case *ast.Ident:
+		if names[e.Name] {
+			name := e.Name
+			return func(values map[string]float64) float64 { return values[name] }, nil
+		}
+		if value, ok := constants[e.Name]; ok {
+			return func(map[string]float64) float64 { return value }, nil
+		}
+		return nil, fmt.Errorf("expression names unknown variable %s", e.Name)
+
+	case *ast.UnaryExpr:
+		x, err := compile(e.X, names)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.SUB:
+			return func(values map[string]float64) float64 { return -x(values) }, nil
+		case token.ADD:
+			return x, nil
+		case token.NOT:
+			return func(values map[string]float64) float64 { return truth(x(values) == 0) }, nil
+		}
+		return nil, fmt.Errorf("expression has unsupported operator %s", e.Op)
+
+	case *ast.BinaryExpr:
+		return compileBinary(e, names)
+
+	case *ast.CallExpr:
+		return compileCall(e, names)
+	}
+	return nil, errors.New("expression has an unsupported construct")
+}
+
+// compileBinary compiles an arithmetic, comparison or logical operation
+func compileBinary(e *ast.BinaryExpr, names map[string]bool) (evaluator, error) {
+	x, err := compile(e.X, names)
+	if err != nil {
+		return nil, err
+	}
+	y, err := compile(e.Y, names)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case token.ADD:
+		return func(v map[string]float64) float64 { return x(v) + y(v) }, nil
+	case token.SUB:
+		return func(v map[string]float64) float64 { return x(v) - y(v) }, nil
+	case token.MUL:
+		return func(v map[string]float64) float64 { return x(v) * y(v) }, nil
+	case token.QUO:
+		return func(v map[string]float64) float64 { return x(v) / y(v) }, nil
+	case token.REM:
+		return func(v map[string]float64) float64 { return math.Mod(x(v), y(v)) }, nil
+	case token.LSS:
+		return func(v map[string]float64) float64 { return truth(x(v) < y(v)) }, nil
+	case token.LEQ:
+		return func(v map[string]float64) float64 { return truth(x(v) <= y(v)) }, nil
+	case token.GTR:
+		return func(v map[string]float64) float64 { return truth(x(v) > y(v)) }, nil
+	case token.GEQ:
+		return func(v map[string]float64) float64 { return truth(x(v) >= y(v)) }, nil
+	case token.EQL:
+		return func(v map[string]float64) float64 { return truth(x(v) == y(v)) }, nil
+	case token.NEQ:
+		return func(v map[string]float64) float64 { return truth(x(v) != y(v)) }, nil
+	case token.LAND:
+		return func(v map[string]float64) float64 { return truth(x(v) != 0 && y(v) != 0) }, nil
+	case token.LOR:
+		return func(v map[string]float64) float64 { return truth(x(v) != 0 || y(v) != 0) }, nil
+	case token.XOR:
+		return nil, errors.New("expression has unsupported operator ^; use pow for powers")
+	}
+	return nil, fmt.Errorf("expression has unsupported operator %s", e.Op)
+}
+
+// compileCall compiles a function call
+func compileCall(e *ast.CallExpr, names map[string]bool) (evaluator, error) {
+	ident, ok := e.Fun.(*ast.Ident)
+	if !ok {
+		return nil, errors.New("expression calls something that is not a function")
+	}
+	if e.Ellipsis.IsValid() {
+		return nil, fmt.Errorf("%s does not take a spread argument", ident.Name)
+	}
+	args := make([]evaluator, len(e.Args))
+	for i, arg := range e.Args {
+		var err error
+		if args[i], err = compile(arg, names); err != nil {
+			return nil, err
+		}
+	}
+
+	if ident.Name == "ifelse" {
+		if len(args) != 3 {
+			return nil, errors.New("ifelse takes 3 arguments")
+		}
+		condition, then, otherwise := args[0], args[1], args[2]
+		return func(v map[string]float64) float64 {
+			if condition(v) != 0 {
+				return then(v)
+			}
+			return otherwise(v)
+		}, nil
+	}
+
+	fn, ok := functions[ident.Name]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("expression calls unknown function %s; the functions are %s", ident.Name, strings.Join(Functions(), ", "))
+	case fn.variadic && len(args) < fn.arity:
+		return nil, fmt.Errorf("%s takes at least %d arguments", ident.Name, fn.arity)
+	case !fn.variadic && len(args) != fn.arity:
+		return nil, fmt.Errorf("%s takes %d arguments", ident.Name, fn.arity)
+	}
+	return func(v map[string]float64) float64 {
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			values[i] = arg(v)
+		}
+		return fn.call(values)
+	}, nil
+}
@@ -0,0 +1,62 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var xy = []string{"x", "y"}
+
+func TestCompile(t *testing.T) {
+	cases := []struct {
+		expression string
+		want       float64
+	}{
+		{"2*sin(pi/2) + pow(x, 2) - abs(y)/4", 10.5},
+		{"x % 2 + floor(-0.5) + ceil(0.2) + round(2.5)", 4},
+		{"min(x, y, 1) + max(x, y, 1)", 1},
+		{"clamp(x, 0, 2) * sign(y)", -2},
+		{"ifelse(x > 2 && y < 0, x, y)", 3},
+		{"ifelse(x == 3 || 1/0 > 0, 1, 0)", 1},
+		{"(x >= 3) + (x <= 3) + (x != y) + !(x < y)", 4},
+		{"log10(100) + hypot(3, 4) - e*0", 7},
+	}
+	values := map[string]float64{"x": 3, "y": -2}
+	for _, tc := range cases {
+		expression, err := Compile(tc.expression, xy)
+		require.NoError(t, err, tc.expression)
+		value, err := expression(values)
+		require.NoError(t, err, tc.expression)
+		assert.InDelta(t, tc.want, value, 1e-12, tc.expression)
+	}
+}
+
+func TestCompile_IfelseEvaluatesOnlyItsBranch(t *testing.T) {
+	expression, err := Compile("ifelse(x > 0, sqrt(x), 0)", xy)
+	require.NoError(t, err)
+	value, err := expression(map[string]float64{"x": -4})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, value)
+}
+
+func TestCompile_RejectsInvalidExpressions(t *testing.T) {
+	expression, err := Compile("1/x", xy)
+	require.NoError(t, err)
+	_, err = expression(map[string]float64{"x": 0})
+	assert.Error(t, err, "not finite")
+
+	for _, source := range []string{"", "x +", "z * 2", "x ^ 2", "x << 2", "fmt.Println(x)", "pow(x)", "max(x)",
+		"ifelse(x, 1)", `"x"`, "x[0]", "os.Exit(1)"} {
+		_, err := Compile(source, xy)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	names := Functions()
+	assert.Contains(t, names, "ifelse")
+	assert.Contains(t, names, "pow")
+	assert.IsIncreasing(t, names)
+}
@@ -0,0 +1,222 @@
+// Package flowchart checks and renders flowcharts. A flowchart's nodes are
+// typed: start and end nodes bound the flow, process nodes do work, IO nodes
+// read input or write output, and decision nodes branch. Only decisions
+// branch, so every start, process and IO node has exactly one outgoing
+// connection, while a decision has two or more, each labeled with the answer
+// that takes it. Flowcharts render as Mermaid, with the conventional shape of
+// each node type, and as PlantUML activity diagrams.
+package flowchart
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DiagramType is the diagram type flowcharts are recorded under
+const DiagramType = "flowchart"
+
+// Node types
+const (
+	Start    = "start"
+	End      = "end"
+	Process  = "process"
+	Decision = "decision"
+	IO       = "io"
+)
+
+// Shapes are the Graphviz shapes of the node types
+var Shapes = map[string]string{
+	Start:    "oval",
+	End:      "oval",
+	Process:  "box",
+	Decision: "diamond",
+	IO:       "parallelogram",
+}
+
+// Node is a step of a flowchart
+type Node struct {
+	ID    string
+	Type  string
+	Label string
+}
+
+// Connection leads from one node to another, labeled with the answer that
+// takes it when it leaves a decision
+type Connection struct {
+	From  string
+	To    string
+	Label string
+}
+
+// Chart is a flowchart
+type Chart struct {
+	Nodes       []Node
+	Connections []Connection
+}
+
+// Validate checks that chart is well formed, reporting every problem found
+func Validate(chart Chart) error {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	types := make(map[string]string, len(chart.Nodes))
+	starts, ends := 0, 0
+	for _, node := range chart.Nodes {
+		switch {
+		case node.ID == "":
+			problem("a %s node has no ID", node.Type)
+			continue
+		case types[node.ID] != "":
+			problem("node %s is defined twice", node.ID)
+			continue
+		}
+		switch node.Type {
+		case Start:
+			starts++
+		case End:
+			ends++
+		case Process, Decision, IO:
+		default:
+			problem("node %s has unknown type %q", node.ID, node.Type)
+			continue
+		}
+		types[node.ID] = node.Type
+	}
+	if starts == 0 {
+		problem("there is no start node")
+	}
+	if ends == 0 {
+		problem("there is no end node")
+	}
+
+	outgoing := make(map[string][]Connection, len(types))
+	incoming := make(map[string]int, len(types))
+	for _, c := range chart.Connections {
+		known := true
+		for _, id := range []string{c.From, c.To} {
+			if types[id] == "" {
+				problem("connection %s -> %s names unknown node %q", c.From, c.To, id)
+				known = false
+			}
+		}
+		if known {
+			outgoing[c.From] = append(outgoing[c.From], c)
+			incoming[c.To]++
+		}
+	}
+
+	checked := make(map[string]bool, len(types))
+	for _, node := range chart.Nodes {
+		nodeType, out := types[node.ID], outgoing[node.ID]
+		if nodeType == "" || checked[node.ID] {
+			continue
+		}
+		checked[node.ID] = true
+		switch nodeType {
+		case Start:
+			if incoming[node.ID] > 0 {
+				problem("start node %s has incoming connections", node.ID)
+			}
+		case End:
+			if len(out) > 0 {
+				problem("end node %s has outgoing connections", node.ID)
+			}
+		}
+		switch nodeType {
+		case Decision:
+			if len(out) < 2 {
+				problem("decision node %s has %d outgoing connections, not two or more", node.ID, len(out))
+			}
+			labels := make(map[string]bool, len(out))
+			for _, c := range out {
+				switch {
+				case c.Label == "":
+					problem("branch %s -> %s of decision node %s has no label", c.From, c.To, node.ID)
+				case labels[c.Label]:
+					problem("decision node %s has two branches labeled %q", node.ID, c.Label)
+				}
+				labels[c.Label] = true
+			}
+		case Start, Process, IO:
+			if len(out) != 1 {
+				problem("%s node %s has %d outgoing connections, not one; only decisions branch", nodeType, node.ID, len(out))
+			}
+		}
+	}
+
+	// Every node must be reached from a start node
+	if len(problems) == 0 {
+		reached := make(map[string]bool, len(types))
+		var queue []string
+		for _, node := range chart.Nodes {
+			if node.Type == Start {
+				reached[node.ID] = true
+				queue = append(queue, node.ID)
+			}
+		}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, c := range outgoing[id] {
+				if !reached[c.To] {
+					reached[c.To] = true
+					queue = append(queue, c.To)
+				}
+			}
+		}
+		for _, node := range chart.Nodes {
+			if !reached[node.ID] {
+				problem("node %s cannot be reached from a start node", node.ID)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// mermaidShapes are the opening and closing brackets of each node type's
+// Mermaid shape
+var mermaidShapes = map[string][2]string{
+	Start:    {"([", "])"},
+	End:      {"([", "])"},
+	Process:  {"[", "]"},
+	Decision: {"{", "}"},
+	IO:       {"[/", "/]"},
+}
+
+// Mermaid renders chart as a top-down Mermaid flowchart. Nodes are named n1,
+// n2 and so on in order, since Mermaid reserves some words, such as end, that
+// make poor IDs; a node's label defaults to its ID.
+func Mermaid(chart Chart) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	names := make(map[string]string, len(chart.Nodes))
+	for i, node := range chart.Nodes {
+		names[node.ID] = fmt.Sprintf("n%d", i+1)
+		label := node.Label
+		if label == "" {
+			label = node.ID
+		}
+		shape := mermaidShapes[node.Type]
+		fmt.Fprintf(&b, "    %s%s%s%s\n", names[node.ID], shape[0], mermaidQuote(label), shape[1])
+	}
+	for _, c := range chart.Connections {
+		if c.Label == "" {
+			fmt.Fprintf(&b, "    %s --> %s\n", names[c.From], names[c.To])
+		} else {
+			fmt.Fprintf(&b, "    %s -->|%s| %s\n", names[c.From], mermaidQuote(c.Label), names[c.To])
+		}
+	}
+	return b.String()
+}
+
+// mermaidQuote quotes s as Mermaid text
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", "<br>").Replace(s) + `"`
+}
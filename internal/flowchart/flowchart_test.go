@@ -0,0 +1,142 @@
+package flowchart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loginChart() Chart {
+	return Chart{
+		Nodes: []Node{
+			{ID: "begin", Type: Start, Label: "Start"},
+			{ID: "read", Type: IO, Label: "Read credentials"},
+			{ID: "check", Type: Decision, Label: `Valid "user"?`},
+			{ID: "greet", Type: Process, Label: "Greet user"},
+			{ID: "end", Type: End},
+		},
+		Connections: []Connection{
+			{From: "begin", To: "read"},
+			{From: "read", To: "check"},
+			{From: "check", To: "greet", Label: "yes"},
+			{From: "check", To: "read", Label: "no"},
+			{From: "greet", To: "end"},
+		},
+	}
+}
+
+func TestValidate_AcceptsAWellFormedChart(t *testing.T) {
+	assert.NoError(t, Validate(loginChart()))
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	chart := loginChart()
+	chart.Connections[3].Label = ""
+	chart.Connections = append(chart.Connections, Connection{From: "greet", To: "begin"}, Connection{From: "end", To: "ghost"})
+	err := Validate(chart)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `connection end -> ghost names unknown node "ghost"`)
+	assert.Contains(t, err.Error(), "start node begin has incoming connections")
+	assert.Contains(t, err.Error(), "branch check -> read of decision node check has no label")
+	assert.Contains(t, err.Error(), "process node greet has 2 outgoing connections, not one; only decisions branch")
+}
+
+func TestValidate_DecisionsNeedDistinctlyLabeledBranches(t *testing.T) {
+	chart := loginChart()
+	chart.Connections[3].Label = "yes"
+	assert.EqualError(t, Validate(chart), `decision node check has two branches labeled "yes"`)
+
+	chart = loginChart()
+	chart.Connections = append(chart.Connections[:3], chart.Connections[4])
+	assert.EqualError(t, Validate(chart), "decision node check has 1 outgoing connections, not two or more")
+}
+
+func TestValidate_RequiresBoundsAndReachability(t *testing.T) {
+	err := Validate(Chart{Nodes: []Node{{ID: "a", Type: "loop"}, {ID: "a", Type: Process}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `node a has unknown type "loop"`)
+	assert.Contains(t, err.Error(), "there is no start node")
+	assert.Contains(t, err.Error(), "there is no end node")
+
+	chart := loginChart()
+	chart.Nodes = append(chart.Nodes, Node{ID: "orphan", Type: End})
+	assert.EqualError(t, Validate(chart), "node orphan cannot be reached from a start node")
+}
+
+func TestMermaid_RendersShapesAndBranches(t *testing.T) {
+	assert.Equal(t, `flowchart TD
+    n1(["Start"])
+    n2[/"Read credentials"/]
+    n3{"Valid #quot;user#quot;?"}
+    n4["Greet user"]
+    n5(["end"])
+    n1 --> n2
+    n2 --> n3
+    n3 -->|"yes"| n4
+    n3 -->|"no"| n2
+    n4 --> n5
+`, Mermaid(loginChart()))
+}
+
+func TestPlantUML_DrawsLoopsWithConnectors(t *testing.T) {
+	assert.Equal(t, `@startuml
+start
+(A)
+:Read credentials/
+if (Valid "user"?) then (yes)
+  :Greet user;
+  stop
+else (no)
+  (A)
+  detach
+endif
+@enduml
+`, PlantUML(loginChart()))
+}
+
+func TestPlantUML_RejoinsBranches(t *testing.T) {
+	chart := Chart{
+		Nodes: []Node{
+			{ID: "begin", Type: Start},
+			{ID: "size", Type: Decision, Label: "Size?"},
+			{ID: "small", Type: Process, Label: "Pack\nsmall"},
+			{ID: "medium", Type: Process, Label: "Pack medium"},
+			{ID: "fragile", Type: Decision, Label: "Fragile?"},
+			{ID: "wrap", Type: Process, Label: "Wrap"},
+			{ID: "ship", Type: IO, Label: "Ship"},
+			{ID: "done", Type: End},
+		},
+		Connections: []Connection{
+			{From: "begin", To: "size"},
+			{From: "size", To: "small", Label: "S"},
+			{From: "size", To: "medium", Label: "M"},
+			{From: "size", To: "fragile", Label: "L"},
+			{From: "small", To: "ship"},
+			{From: "medium", To: "ship", Label: "boxed"},
+			{From: "fragile", To: "wrap", Label: "yes"},
+			{From: "fragile", To: "ship", Label: "no"},
+			{From: "wrap", To: "ship"},
+			{From: "ship", To: "done"},
+		},
+	}
+	require.NoError(t, Validate(chart))
+	assert.Equal(t, `@startuml
+start
+switch (Size?)
+case (S)
+  :Pack\nsmall;
+case (M)
+  :Pack medium;
+  -> boxed;
+case (L)
+  if (Fragile?) then (yes)
+    :Wrap;
+  else (no)
+  endif
+endswitch
+:Ship/
+stop
+@enduml
+`, PlantUML(chart))
+}
@@ -0,0 +1,218 @@
+package flowchart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PlantUML renders chart, which must be valid, as a PlantUML activity
+// diagram. Start and end nodes become PlantUML's start and stop, process
+// nodes actions and IO nodes slanted actions; a decision with two branches
+// becomes an if and one with more a switch, its branches rejoining where
+// they first meet again. Activity diagrams are structured, so a flow that
+// returns to a node already drawn, as a loop does, or leaves a decision's
+// branch for a point outside it, ends in a lettered connector, drawn again
+// just before the node the flow continues at.
+func PlantUML(chart Chart) string {
+	w := &activityWriter{
+		nodes:    make(map[string]Node, len(chart.Nodes)),
+		outgoing: make(map[string][]Connection, len(chart.Nodes)),
+		order:    make(map[string]int, len(chart.Nodes)),
+	}
+	for i, node := range chart.Nodes {
+		w.nodes[node.ID] = node
+		w.order[node.ID] = i
+	}
+	for _, c := range chart.Connections {
+		w.outgoing[c.From] = append(w.outgoing[c.From], c)
+	}
+
+	// The first pass finds the nodes flows jump to, so that the second can
+	// mark them with their connectors
+	w.render(chart, nil)
+	return w.render(chart, w.jumps)
+}
+
+// activityWriter writes a flowchart as an activity diagram
+type activityWriter struct {
+	nodes    map[string]Node
+	outgoing map[string][]Connection
+	order    map[string]int
+
+	b          strings.Builder
+	drawn      map[string]bool
+	connectors map[string]string
+	jumps      map[string]string
+}
+
+// render writes chart with connectors marking the nodes flows jump to,
+// recording the jumps made in w.jumps
+func (w *activityWriter) render(chart Chart, connectors map[string]string) string {
+	w.b.Reset()
+	w.drawn = make(map[string]bool, len(chart.Nodes))
+	w.connectors = connectors
+	w.jumps = make(map[string]string)
+	w.b.WriteString("@startuml\n")
+	for _, node := range chart.Nodes {
+		if node.Type == Start {
+			w.flow(node.ID, "", nil, "")
+		}
+	}
+	w.b.WriteString("@enduml\n")
+	return w.b.String()
+}
+
+// flow writes the flow from node id until it reaches merge, where the
+// decision it is a branch of rejoins, or ends. Reaching a node already
+// drawn, or the merge point of an enclosing decision, in stops, it jumps
+// there.
+func (w *activityWriter) flow(id, merge string, stops []string, indent string) {
+	for id != merge {
+		node := w.nodes[id]
+		if node.Type != End && (w.drawn[id] || containsID(stops, id)) {
+			name, ok := w.jumps[id]
+			if !ok {
+				name = connectorName(len(w.jumps))
+				w.jumps[id] = name
+			}
+			fmt.Fprintf(&w.b, "%s(%s)\n%sdetach\n", indent, name, indent)
+			return
+		}
+		if name, ok := w.connectors[id]; ok {
+			fmt.Fprintf(&w.b, "%s(%s)\n", indent, name)
+		}
+
+		switch node.Type {
+		case End:
+			fmt.Fprintf(&w.b, "%sstop\n", indent)
+			return
+		case Decision:
+			w.drawn[id] = true
+			if id = w.decision(node, merge, stops, indent); id == "" {
+				return
+			}
+			continue
+		case Start:
+			fmt.Fprintf(&w.b, "%sstart\n", indent)
+		case IO:
+			fmt.Fprintf(&w.b, "%s:%s/\n", indent, plantUMLText(nodeLabel(node)))
+		default:
+			fmt.Fprintf(&w.b, "%s:%s;\n", indent, plantUMLText(nodeLabel(node)))
+		}
+		w.drawn[id] = true
+		next := w.outgoing[id][0]
+		if next.Label != "" {
+			fmt.Fprintf(&w.b, "%s-> %s;\n", indent, plantUMLText(next.Label))
+		}
+		id = next.To
+	}
+}
+
+// decision writes decision node with its branches, returning the node they
+// rejoin at, if they do
+func (w *activityWriter) decision(node Node, merge string, stops []string, indent string) string {
+	branches := w.outgoing[node.ID]
+	rejoin := w.rejoin(node.ID)
+	if merge != "" {
+		stops = append(append([]string{}, stops...), merge)
+	}
+	inner := indent + "  "
+	if len(branches) == 2 {
+		fmt.Fprintf(&w.b, "%sif (%s) then (%s)\n", indent, plantUMLText(nodeLabel(node)), plantUMLText(branches[0].Label))
+		w.flow(branches[0].To, rejoin, stops, inner)
+		fmt.Fprintf(&w.b, "%selse (%s)\n", indent, plantUMLText(branches[1].Label))
+		w.flow(branches[1].To, rejoin, stops, inner)
+		fmt.Fprintf(&w.b, "%sendif\n", indent)
+		return rejoin
+	}
+	fmt.Fprintf(&w.b, "%sswitch (%s)\n", indent, plantUMLText(nodeLabel(node)))
+	for _, branch := range branches {
+		fmt.Fprintf(&w.b, "%scase (%s)\n", indent, plantUMLText(branch.Label))
+		w.flow(branch.To, rejoin, stops, inner)
+	}
+	fmt.Fprintf(&w.b, "%sendswitch\n", indent)
+	return rejoin
+}
+
+// rejoin returns the node where the branches of decision first meet again,
+// none when they never do: of the nodes not yet drawn that every branch
+// reaches without passing through decision, the one the farthest branch
+// reaches soonest, ties going to the one reached soonest in all, then to the
+// one defined first
+func (w *activityWriter) rejoin(decision string) string {
+	var common map[string][]int
+	for i, branch := range w.outgoing[decision] {
+		distances := map[string]int{}
+		if !w.drawn[branch.To] && branch.To != decision {
+			distances[branch.To] = 0
+		}
+		queue := []string{branch.To}
+		for len(queue) > 0 && len(distances) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, c := range w.outgoing[id] {
+				if _, seen := distances[c.To]; !seen && c.To != decision && !w.drawn[c.To] {
+					distances[c.To] = distances[id] + 1
+					queue = append(queue, c.To)
+				}
+			}
+		}
+		if i == 0 {
+			common = make(map[string][]int, len(distances))
+			for id, d := range distances {
+				common[id] = []int{d}
+			}
+			continue
+		}
+		for id := range common {
+			if d, ok := distances[id]; ok {
+				common[id] = append(common[id], d)
+			} else {
+				delete(common, id)
+			}
+		}
+	}
+
+	best, bestFarthest, bestTotal := "", 0, 0
+	for id, distances := range common {
+		farthest, total := 0, 0
+		for _, d := range distances {
+			farthest, total = max(farthest, d), total+d
+		}
+		if best == "" || farthest < bestFarthest || farthest == bestFarthest && (total < bestTotal || total == bestTotal && w.order[id] < w.order[best]) {
+			best, bestFarthest, bestTotal = id, farthest, total
+		}
+	}
+	return best
+}
+
+// connectorName names the nth connector A to Z, then A1 to Z1 and so on
+func connectorName(n int) string {
+	name := string(rune('A' + n%26))
+	if n >= 26 {
+		name += fmt.Sprint(n / 26)
+	}
+	return name
+}
+
+// nodeLabel returns the label of node, its ID when it has none
+func nodeLabel(node Node) string {
+	if node.Label == "" {
+		return node.ID
+	}
+	return node.Label
+}
+
+// plantUMLText writes s on a single line, as PlantUML text
+func plantUMLText(s string) string {
+	return strings.NewReplacer("\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+// Package grpcserver serves the GoThink gRPC API over the same storage as the
+// MCP server and the HTTP API.
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	gothinkv1 "github.com/rainmana/gothink/api/gothink/v1"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/handlers"
+	"github.com/rainmana/gothink/internal/middleware"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/telemetry"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// TenantMetadataKey names the request metadata that selects a tenant namespace
+const TenantMetadataKey = "x-tenant-id"
+
+// New returns the gRPC API: the thinking, stochastic, decision and session
+// services, each feature-flagged service only when enabled, and server
+// reflection. Calls are traced, rate limited per client IP and scoped to the
+// tenant of their x-tenant-id metadata.
+func New(cfg *config.Config, store storage.Store, logger *logrus.Logger) *grpc.Server {
+	limiter := middleware.NewRateLimiter(cfg.RateLimitPerSecond, cfg.RateLimitBurst)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(tracingUnary, rateLimitUnary(limiter), tenantUnary),
+		grpc.ChainStreamInterceptor(tracingStream, rateLimitStream(limiter), tenantStream),
+	)
+
+	if cfg.EnableSystematicThinking {
+		gothinkv1.RegisterThinkingServiceServer(s, &thinkingService{handler: handlers.NewThinkingHandler(store, logger)})
+	}
+	if cfg.EnableStochasticAlgorithms {
+		gothinkv1.RegisterStochasticServiceServer(s, &stochasticService{handler: handlers.NewStochasticHandler(store, logger)})
+	}
+	gothinkv1.RegisterDecisionServiceServer(s, &decisionService{handler: handlers.NewDecisionHandler(store, logger)})
+	gothinkv1.RegisterSessionServiceServer(s, &sessionService{store: store, logger: logger})
+	reflection.Register(s)
+
+	return s
+}
+
+// tracingUnary records a span for each call, continuing the trace the client
+// passes as W3C trace context in the request metadata
+func tracingUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startSpan(ctx, info.FullMethod)
+	resp, err := next(ctx, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+// tracingStream records a span for each stream, like tracingUnary
+func tracingStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+	ctx, span := startSpan(ss.Context(), info.FullMethod)
+	err := next(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	endSpan(span, err)
+	return err
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return telemetry.Tracer().Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(status.Code(err))))
+	telemetry.End(span, err)
+}
+
+// rateLimitUnary limits the calls of each client IP, failing those beyond its
+// bucket with ResourceExhausted
+func rateLimitUnary(limiter *middleware.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		if err := allow(ctx, limiter); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// rateLimitStream limits the streams opened by each client IP, like
+// rateLimitUnary
+func rateLimitStream(limiter *middleware.RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if err := allow(ss.Context(), limiter); err != nil {
+			return err
+		}
+		return next(srv, ss)
+	}
+}
+
+func allow(ctx context.Context, limiter *middleware.RateLimiter) error {
+	if limiter == nil {
+		return nil
+	}
+	if allowed, wait := limiter.Allow(clientIP(ctx)); !allowed {
+		return apierror.GRPCStatus(apierror.Errorf(apierror.CodeRateLimited, "rate limit exceeded; retry in %s", wait.Round(time.Millisecond)))
+	}
+	return nil
+}
+
+// tenantUnary scopes a call to the tenant named by its x-tenant-id metadata.
+// Calls without it use the shared namespace.
+func tenantUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := withTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return next(ctx, req)
+}
+
+// tenantStream scopes a stream to its tenant, like tenantUnary
+func tenantStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+	ctx, err := withTenant(ss.Context())
+	if err != nil {
+		return err
+	}
+	return next(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func withTenant(ctx context.Context) (context.Context, error) {
+	var tenantID string
+	if values := metadata.ValueFromIncomingContext(ctx, TenantMetadataKey); len(values) > 0 {
+		tenantID = values[0]
+	}
+	if err := storage.ValidateTenantID(tenantID); err != nil {
+		return nil, apierror.GRPCStatus(apierror.Errorf(apierror.CodeInvalidParameters, "%v", err))
+	}
+	return storage.WithTenant(ctx, tenantID), nil
+}
+
+// clientIP returns the IP address a call came from
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// contextStream is a server stream whose handlers see ctx
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier adapts gRPC metadata for trace context propagation
+type metadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = metadataCarrier(nil)
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// tenantStore scopes the store to the tenant set on ctx by the tenant
+// interceptors, tracing its calls under the call span
+func tenantStore(ctx context.Context, store storage.Store) storage.Store {
+	return storage.WithTracing(ctx, storage.ForTenant(store, storage.TenantFromContext(ctx)))
+}
+
+// sessionRequired fails calls that name no session
+func sessionRequired(sessionID string) error {
+	if sessionID == "" {
+		return apierror.GRPCStatus(apierror.Errorf(apierror.CodeInvalidParameters, "Session ID required"))
+	}
+	return nil
+}
+
+// unsupported reports a method the server cannot offer with its store
+func unsupported(format string, args ...interface{}) error {
+	return status.Errorf(codes.Unimplemented, format, args...)
+}
@@ -0,0 +1,128 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	gothinkv1 "github.com/rainmana/gothink/api/gothink/v1"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves the gRPC API over store in memory and returns a client connection
+func dial(t *testing.T, cfg *config.Config, store storage.Store) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	srv := New(cfg, store, logrus.New())
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServices_MirrorHTTPOverSharedStore(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.EnableStochasticAlgorithms = false
+	store := storage.NewMemoryStore(cfg)
+	conn := dial(t, cfg, store)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), TenantMetadataKey, "acme")
+
+	thinking := gothinkv1.NewThinkingServiceClient(conn)
+	response, err := thinking.SequentialThinking(ctx, &gothinkv1.SequentialThinkingRequest{
+		SessionId: "grpc", Thought: "first", ThoughtNumber: 1, TotalThoughts: 3, NextThoughtNeeded: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", response.GetStatus())
+	assert.Equal(t, int32(1), response.GetTotalThoughts())
+
+	// Thoughts streamed in are each recorded and answered in turn
+	stream, err := thinking.StreamThoughts(ctx)
+	require.NoError(t, err)
+	for i := int32(2); i <= 3; i++ {
+		require.NoError(t, stream.Send(&gothinkv1.SequentialThinkingRequest{
+			SessionId: "grpc", Thought: "next", ThoughtNumber: i, TotalThoughts: 3, NextThoughtNeeded: i < 3,
+		}))
+		reply, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, i, reply.GetTotalThoughts())
+	}
+	require.NoError(t, stream.CloseSend())
+
+	// The thoughts are in the tenant's namespace of the shared store
+	thoughts, err := storage.ForTenant(store, "acme").GetThoughts("grpc", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 3)
+
+	sessions := gothinkv1.NewSessionServiceClient(conn)
+	stats, err := sessions.GetSessionStats(ctx, &gothinkv1.SessionRequest{SessionId: "grpc"})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), stats.GetStats().GetFields()["thought_count"].GetNumberValue())
+
+	records, err := sessions.ListRecords(ctx, &gothinkv1.ListRecordsRequest{SessionId: "grpc", Type: storage.KindThoughts, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), records.GetCount())
+	assert.Equal(t, int32(3), records.GetTotal())
+	assert.Equal(t, "first", records.GetRecords()[0].GetFields()["thought"].GetStringValue())
+
+	// Failures carry the machine-readable code
+	_, err = sessions.ArchiveSession(ctx, &gothinkv1.SessionRequest{SessionId: "missing"})
+	st := status.Convert(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	require.Len(t, st.Details(), 1)
+	assert.Equal(t, "SESSION_NOT_FOUND", st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+
+	_, err = thinking.SequentialThinking(metadata.AppendToOutgoingContext(context.Background(), TenantMetadataKey, "not a tenant!"),
+		&gothinkv1.SequentialThinkingRequest{SessionId: "grpc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Disabled features are not served
+	_, err = gothinkv1.NewStochasticServiceClient(conn).MarkovDecisionProcess(ctx, &gothinkv1.MDPRequest{SessionId: "grpc"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestWatchEvents_StreamsWrites(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewEventStore(storage.NewMemoryStore(cfg), storage.NewEventBus())
+	conn := dial(t, cfg, store)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := gothinkv1.NewSessionServiceClient(conn).WatchEvents(ctx, &gothinkv1.WatchEventsRequest{SessionId: "watched"})
+	require.NoError(t, err)
+	// The headers arrive once the server has subscribed
+	_, err = events.Header()
+	require.NoError(t, err)
+
+	_, err = gothinkv1.NewStochasticServiceClient(conn).MultiArmedBandit(ctx, &gothinkv1.BanditRequest{
+		SessionId: "watched", Problem: "Pick an ad", Strategy: "epsilon_greedy",
+		Arms: []*gothinkv1.BanditArm{{Name: "banner", Mean: 0.1}, {Name: "video", Mean: 0.3}},
+	})
+	require.NoError(t, err)
+
+	event, err := events.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, storage.OpAddStochasticAlgorithm, event.GetType())
+	assert.Equal(t, "watched", event.GetSessionId())
+	assert.Equal(t, "Pick an ad", event.GetRecord().GetFields()["problem"].GetStringValue())
+}
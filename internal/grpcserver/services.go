@@ -0,0 +1,702 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/rainmana/gothink/api"
+	gothinkv1 "github.com/rainmana/gothink/api/gothink/v1"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/handlers"
+	"github.com/rainmana/gothink/internal/search"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// thinkingService records thinking steps through the HTTP API's handler
+type thinkingService struct {
+	gothinkv1.UnimplementedThinkingServiceServer
+	handler *handlers.ThinkingHandler
+}
+
+func (s *thinkingService) SequentialThinking(ctx context.Context, req *gothinkv1.SequentialThinkingRequest) (*gothinkv1.SequentialThinkingResponse, error) {
+	response, err := s.handler.RecordThought(ctx, sequentialThinkingRequest(req))
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return sequentialThinkingResponse(response), nil
+}
+
+func (s *thinkingService) StreamThoughts(stream gothinkv1.ThinkingService_StreamThoughtsServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		response, err := s.handler.RecordThought(stream.Context(), sequentialThinkingRequest(req))
+		if err != nil {
+			return apierror.GRPCStatus(err)
+		}
+		if err := stream.Send(sequentialThinkingResponse(response)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *thinkingService) MentalModel(ctx context.Context, req *gothinkv1.MentalModelRequest) (*gothinkv1.MentalModelResponse, error) {
+	response, err := s.handler.ApplyMentalModel(ctx, api.MentalModelRequest{
+		SessionID:  req.GetSessionId(),
+		ModelName:  req.GetModelName(),
+		Problem:    req.GetProblem(),
+		Steps:      req.GetSteps(),
+		Reasoning:  req.GetReasoning(),
+		Conclusion: req.GetConclusion(),
+		Confidence: req.GetConfidence(),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.MentalModelResponse{
+		ModelId:           response.ModelID,
+		Status:            response.Status,
+		HasSteps:          response.HasSteps,
+		HasConclusion:     response.HasConclusion,
+		SessionId:         response.SessionContext.SessionID,
+		TotalMentalModels: int32(response.SessionContext.TotalMentalModels),
+	}, nil
+}
+
+func (s *thinkingService) DebuggingApproach(ctx context.Context, req *gothinkv1.DebuggingApproachRequest) (*gothinkv1.DebuggingApproachResponse, error) {
+	response, err := s.handler.RecordDebuggingApproach(ctx, api.DebuggingApproachRequest{
+		SessionID:    req.GetSessionId(),
+		ApproachName: req.GetApproachName(),
+		Issue:        req.GetIssue(),
+		Steps:        req.GetSteps(),
+		Findings:     req.GetFindings(),
+		Resolution:   req.GetResolution(),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.DebuggingApproachResponse{
+		ApproachId:    response.ApproachID,
+		Status:        response.Status,
+		HasFindings:   response.HasFindings,
+		HasResolution: response.HasResolution,
+	}, nil
+}
+
+func sequentialThinkingRequest(req *gothinkv1.SequentialThinkingRequest) api.SequentialThinkingRequest {
+	return api.SequentialThinkingRequest{
+		SessionID:         req.GetSessionId(),
+		Thought:           req.GetThought(),
+		ThoughtNumber:     int(req.GetThoughtNumber()),
+		TotalThoughts:     int(req.GetTotalThoughts()),
+		NextThoughtNeeded: req.GetNextThoughtNeeded(),
+		IsRevision:        req.GetIsRevision(),
+		RevisesThought:    intPtr(req.RevisesThought),
+		BranchFromThought: intPtr(req.BranchFromThought),
+		BranchID:          req.GetBranchId(),
+		NeedsMoreThoughts: req.GetNeedsMoreThoughts(),
+	}
+}
+
+func sequentialThinkingResponse(response *api.SequentialThinkingResponse) *gothinkv1.SequentialThinkingResponse {
+	return &gothinkv1.SequentialThinkingResponse{
+		ThoughtId:         response.ThoughtID,
+		Status:            response.Status,
+		SessionId:         response.SessionContext.SessionID,
+		TotalThoughts:     int32(response.SessionContext.TotalThoughts),
+		RemainingThoughts: int32(response.SessionContext.RemainingThoughts),
+	}
+}
+
+// stochasticService runs stochastic algorithms through the HTTP API's handler
+type stochasticService struct {
+	gothinkv1.UnimplementedStochasticServiceServer
+	handler *handlers.StochasticHandler
+}
+
+func (s *stochasticService) MarkovDecisionProcess(ctx context.Context, req *gothinkv1.MDPRequest) (*gothinkv1.MDPResponse, error) {
+	transitions := make([]api.MDPTransition, len(req.GetTransitions()))
+	for i, t := range req.GetTransitions() {
+		transitions[i] = api.MDPTransition{
+			State:       t.GetState(),
+			Action:      t.GetAction(),
+			NextState:   t.GetNextState(),
+			Probability: t.GetProbability(),
+			Reward:      t.GetReward(),
+		}
+	}
+	response, err := s.handler.RunMDP(ctx, api.MDPRequest{
+		SessionID:     req.GetSessionId(),
+		Problem:       req.GetProblem(),
+		Transitions:   transitions,
+		States:        int(req.GetStates()),
+		Actions:       req.GetActions(),
+		Gamma:         req.GetGamma(),
+		Method:        req.GetMethod(),
+		Tolerance:     req.GetTolerance(),
+		MaxIterations: int(req.GetMaxIterations()),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+
+	qValues := make(map[string]*gothinkv1.MDPActionValues, len(response.QValues))
+	for state, values := range response.QValues {
+		qValues[state] = &gothinkv1.MDPActionValues{Values: values}
+	}
+	return &gothinkv1.MDPResponse{
+		AlgorithmId:   response.AlgorithmID,
+		Status:        response.Status,
+		Summary:       response.Summary,
+		HasResult:     response.HasResult,
+		Converged:     response.Converged,
+		Iterations:    int32(response.Iterations),
+		Policy:        response.Policy,
+		ValueFunction: response.ValueFunction,
+		QValues:       qValues,
+		Convergence:   convergence(response.Convergence),
+	}, nil
+}
+
+// convergence converts how a run ended to its message
+func convergence(c api.Convergence) *gothinkv1.Convergence {
+	return &gothinkv1.Convergence{
+		Method:         c.Method,
+		Iterations:     int32(c.Iterations),
+		Converged:      c.Converged,
+		Tolerance:      c.Tolerance,
+		Residual:       c.Residual,
+		StoppingReason: c.StoppingReason,
+		Residuals:      c.Residuals,
+		RHat:           c.RHat,
+	}
+}
+
+func (s *stochasticService) MonteCarloTreeSearch(ctx context.Context, req *gothinkv1.MCTSRequest) (*gothinkv1.MCTSResponse, error) {
+	states := make([]api.MCTSState, len(req.GetStates()))
+	for i, state := range req.GetStates() {
+		states[i] = api.MCTSState{Name: state.GetName(), Player: int(state.GetPlayer()), Reward: state.GetReward()}
+		for _, move := range state.GetMoves() {
+			states[i].Moves = append(states[i].Moves, api.MCTSMove{Move: move.GetMove(), NextState: move.GetNextState()})
+		}
+	}
+	response, err := s.handler.RunMCTS(ctx, api.MCTSRequest{
+		SessionID:           req.GetSessionId(),
+		Problem:             req.GetProblem(),
+		RootState:           req.GetRootState(),
+		States:              states,
+		Simulations:         int(req.GetSimulations()),
+		ExplorationConstant: req.GetExplorationConstant(),
+		MaxDepth:            int(req.GetMaxDepth()),
+		TimeLimit:           int(req.GetTimeLimit()),
+		Seed:                req.GetSeed(),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	treeStats, err := toStruct(response.TreeStats)
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+
+	actions := make([]*gothinkv1.MCTSActionStats, len(response.Actions))
+	for i, action := range response.Actions {
+		actions[i] = &gothinkv1.MCTSActionStats{Move: action.Move, Visits: int32(action.Visits), Q: action.Q}
+	}
+	return &gothinkv1.MCTSResponse{
+		AlgorithmId:        response.AlgorithmID,
+		Status:             response.Status,
+		Summary:            response.Summary,
+		HasResult:          response.HasResult,
+		BestAction:         response.BestAction,
+		TreeStats:          treeStats,
+		Actions:            actions,
+		PrincipalVariation: response.PrincipalVariation,
+		Simulations:        int32(response.Simulations),
+		Convergence:        convergence(response.Convergence),
+	}, nil
+}
+
+func (s *stochasticService) MultiArmedBandit(ctx context.Context, req *gothinkv1.BanditRequest) (*gothinkv1.BanditResponse, error) {
+	arms := make([]api.BanditArm, len(req.GetArms()))
+	for i, arm := range req.GetArms() {
+		arms[i] = api.BanditArm{
+			Name:            arm.GetName(),
+			Distribution:    arm.GetDistribution(),
+			Mean:            arm.GetMean(),
+			StdDev:          arm.GetStdDev(),
+			ObservedRewards: arm.GetObservedRewards(),
+		}
+	}
+	response, err := s.handler.RunBandit(ctx, api.BanditRequest{
+		SessionID: req.GetSessionId(),
+		Problem:   req.GetProblem(),
+		Arms:      arms,
+		Strategy:  req.GetStrategy(),
+		Steps:     int(req.GetSteps()),
+		Epsilon:   req.GetEpsilon(),
+		Alpha:     req.GetAlpha(),
+		Beta:      req.GetBeta(),
+		Seed:      req.GetSeed(),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	armStats := make([]*gothinkv1.ArmStatistics, len(response.ArmStats))
+	for i, stat := range response.ArmStats {
+		armStats[i] = &gothinkv1.ArmStatistics{
+			Arm:            int32(stat.Arm),
+			Name:           stat.Name,
+			Pulls:          int32(stat.Pulls),
+			Rewards:        stat.Rewards,
+			AverageReward:  stat.AverageReward,
+			ExpectedReward: stat.ExpectedReward,
+		}
+	}
+	curve := make([]*gothinkv1.RegretPoint, len(response.RegretCurve))
+	for i, point := range response.RegretCurve {
+		curve[i] = &gothinkv1.RegretPoint{Step: int32(point.Step), Regret: point.Regret}
+	}
+	return &gothinkv1.BanditResponse{
+		AlgorithmId: response.AlgorithmID,
+		Status:      response.Status,
+		Summary:     response.Summary,
+		HasResult:   response.HasResult,
+		SelectedArm: int32(response.SelectedArm),
+		OptimalArm:  int32(response.OptimalArm),
+		ArmStats:    armStats,
+		TotalReward: response.TotalReward,
+		Regret:      response.Regret,
+		RegretCurve: curve,
+		Convergence: convergence(response.Convergence),
+	}, nil
+}
+
+func (s *stochasticService) BayesianOptimization(ctx context.Context, req *gothinkv1.BayesianOptimizationRequest) (*gothinkv1.BayesianOptimizationResponse, error) {
+	parameters := make([]api.BayesianParameter, len(req.GetParameters()))
+	for i, p := range req.GetParameters() {
+		parameters[i] = api.BayesianParameter{Name: p.GetName(), Min: p.GetMin(), Max: p.GetMax()}
+	}
+	history := make([]api.BayesianObservation, len(req.GetHistory()))
+	for i, o := range req.GetHistory() {
+		history[i] = api.BayesianObservation{Parameters: o.GetParameters(), Value: o.GetValue()}
+	}
+	response, err := s.handler.RunBayesianOptimization(ctx, api.BayesianOptimizationRequest{
+		SessionID:           req.GetSessionId(),
+		Problem:             req.GetProblem(),
+		Parameters:          parameters,
+		Objective:           req.GetObjective(),
+		History:             history,
+		Goal:                req.GetGoal(),
+		AcquisitionFunction: req.GetAcquisitionFunction(),
+		Kernel:              req.GetKernel(),
+		Iterations:          int(req.GetIterations()),
+		InitialPoints:       int(req.GetInitialPoints()),
+		ExplorationWeight:   req.GetExplorationWeight(),
+		LengthScale:         req.GetLengthScale(),
+		Noise:               req.GetNoise(),
+		Tolerance:           req.GetTolerance(),
+		Patience:            int(req.GetPatience()),
+		StopAtPlateau:       req.GetStopAtPlateau(),
+		Seed:                req.GetSeed(),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	steps := make([]*gothinkv1.OptimizationStep, len(response.History))
+	for i, step := range response.History {
+		steps[i] = &gothinkv1.OptimizationStep{
+			Iteration:   int32(step.Iteration),
+			Parameters:  step.Parameters,
+			Value:       step.Value,
+			Random:      step.Random,
+			Acquisition: step.Acquisition,
+			Predicted:   gpPosterior(step.Predicted),
+		}
+	}
+	return &gothinkv1.BayesianOptimizationResponse{
+		AlgorithmId:     response.AlgorithmID,
+		Status:          response.Status,
+		Summary:         response.Summary,
+		HasResult:       response.HasResult,
+		BestParameters:  response.BestParameters,
+		BestValue:       response.BestValue,
+		Iterations:      int32(response.Iterations),
+		BestPosterior:   gpPosterior(response.BestPosterior),
+		History:         steps,
+		NextParameters:  response.NextParameters,
+		NextAcquisition: response.NextAcquisition,
+		NextPosterior:   gpPosterior(response.NextPosterior),
+		Convergence:     convergence(response.Convergence),
+	}, nil
+}
+
+// gpPosterior converts a Gaussian-process posterior to its message
+func gpPosterior(posterior api.GPPosterior) *gothinkv1.GPPosterior {
+	return &gothinkv1.GPPosterior{Mean: posterior.Mean, Variance: posterior.Variance}
+}
+
+func (s *stochasticService) HiddenMarkovModel(ctx context.Context, req *gothinkv1.HMMRequest) (*gothinkv1.HMMResponse, error) {
+	response, err := s.handler.RunHMM(ctx, api.HMMRequest{
+		SessionID:     req.GetSessionId(),
+		Problem:       req.GetProblem(),
+		Observations:  req.GetObservations(),
+		Symbols:       req.GetSymbols(),
+		States:        int(req.GetStates()),
+		StateNames:    req.GetStateNames(),
+		Initial:       req.GetInitial(),
+		Transitions:   fromHMMRows(req.GetTransitions()),
+		Emissions:     fromHMMRows(req.GetEmissions()),
+		Algorithm:     req.GetAlgorithm(),
+		MaxIterations: int(req.GetMaxIterations()),
+		Tolerance:     req.GetTolerance(),
+		Seed:          req.GetSeed(),
+	})
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.HMMResponse{
+		AlgorithmId:        response.AlgorithmID,
+		Status:             response.Status,
+		Summary:            response.Summary,
+		HasResult:          response.HasResult,
+		States:             int32(response.States),
+		Observations:       int32(response.Observations),
+		StateNames:         response.StateNames,
+		Symbols:            response.Symbols,
+		StatePath:          response.StatePath,
+		PathLogProbability: response.PathLogProbability,
+		LogLikelihood:      response.LogLikelihood,
+		StatePosteriors:    toHMMRows(response.StatePosteriors),
+		Initial:            response.Initial,
+		Transitions:        toHMMRows(response.Transitions),
+		Emissions:          toHMMRows(response.Emissions),
+		Iterations:         int32(response.Iterations),
+		Converged:          response.Converged,
+		Convergence:        convergence(response.Convergence),
+	}, nil
+}
+
+// fromHMMRows converts HMM rows to a matrix, keeping nil for no rows so the
+// handler can tell given parameters from missing ones
+func fromHMMRows(rows []*gothinkv1.HMMRow) [][]float64 {
+	if len(rows) == 0 {
+		return nil
+	}
+	matrix := make([][]float64, len(rows))
+	for i, row := range rows {
+		matrix[i] = row.GetProbabilities()
+	}
+	return matrix
+}
+
+// toHMMRows converts a matrix to HMM rows
+func toHMMRows(matrix [][]float64) []*gothinkv1.HMMRow {
+	rows := make([]*gothinkv1.HMMRow, len(matrix))
+	for i, row := range matrix {
+		rows[i] = &gothinkv1.HMMRow{Probabilities: row}
+	}
+	return rows
+}
+
+// decisionService records decisions through the HTTP API's handler
+type decisionService struct {
+	gothinkv1.UnimplementedDecisionServiceServer
+	handler *handlers.DecisionHandler
+}
+
+func (s *decisionService) DecisionFramework(ctx context.Context, req *gothinkv1.DecisionFrameworkRequest) (*gothinkv1.DecisionFrameworkResponse, error) {
+	request := api.DecisionFrameworkRequest{
+		SessionID:         req.GetSessionId(),
+		DecisionStatement: req.GetDecisionStatement(),
+		Stakeholders:      req.GetStakeholders(),
+		Constraints:       req.GetConstraints(),
+		TimeHorizon:       req.GetTimeHorizon(),
+		RiskTolerance:     req.GetRiskTolerance(),
+		AnalysisType:      req.GetAnalysisType(),
+		Stage:             req.GetStage(),
+	}
+	for _, option := range req.GetOptions() {
+		request.Options = append(request.Options, api.DecisionOption{
+			ID:                   option.GetId(),
+			Name:                 option.GetName(),
+			Description:          option.GetDescription(),
+			ExpectedValue:        option.ExpectedValue,
+			RiskLevel:            option.GetRiskLevel(),
+			ProbabilityOfSuccess: option.ProbabilityOfSuccess,
+		})
+	}
+	for _, criterion := range req.GetCriteria() {
+		request.Criteria = append(request.Criteria, api.DecisionCriterion{
+			ID:               criterion.GetId(),
+			Name:             criterion.GetName(),
+			Description:      criterion.GetDescription(),
+			Weight:           criterion.GetWeight(),
+			EvaluationMethod: criterion.GetEvaluationMethod(),
+		})
+	}
+
+	response, err := s.handler.RecordDecision(ctx, request)
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.DecisionFrameworkResponse{
+		DecisionId:   response.DecisionID,
+		Status:       response.Status,
+		HasOptions:   response.HasOptions,
+		HasCriteria:  response.HasCriteria,
+		AnalysisType: response.AnalysisType,
+		Stage:        response.Stage,
+	}, nil
+}
+
+// sessionService inspects and manages the sessions of the store
+type sessionService struct {
+	gothinkv1.UnimplementedSessionServiceServer
+	store  storage.Store
+	logger *logrus.Logger
+}
+
+func (s *sessionService) GetSessionStats(ctx context.Context, req *gothinkv1.SessionRequest) (*gothinkv1.SessionStatsResponse, error) {
+	if err := sessionRequired(req.GetSessionId()); err != nil {
+		return nil, err
+	}
+
+	stats, err := tenantStore(ctx, s.store).GetSessionStats(req.GetSessionId())
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get session stats")
+		return nil, apierror.GRPCStatus(apierror.Errorf(apierror.CodeOf(err), "Failed to get session stats"))
+	}
+
+	encoded, err := toStruct(stats)
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.SessionStatsResponse{Stats: encoded}, nil
+}
+
+func (s *sessionService) ListRecords(ctx context.Context, req *gothinkv1.ListRecordsRequest) (*gothinkv1.ListRecordsResponse, error) {
+	if err := sessionRequired(req.GetSessionId()); err != nil {
+		return nil, err
+	}
+	if req.GetType() == "" {
+		return nil, apierror.GRPCStatus(apierror.Errorf(apierror.CodeInvalidParameters, "Record type required"))
+	}
+
+	query := &storage.Query{
+		Limit:  int(req.GetLimit()),
+		Offset: int(req.GetOffset()),
+		Order:  req.GetOrder(),
+	}
+	if req.Since != nil {
+		query.Since = req.GetSince().AsTime()
+	}
+	if req.Until != nil {
+		query.Until = req.GetUntil().AsTime()
+	}
+
+	page, err := storage.QueryRecords(tenantStore(ctx, s.store), req.GetSessionId(), req.GetType(), query)
+	if err != nil {
+		return nil, apierror.GRPCStatus(apierror.Errorf(apierror.CodeInvalidParameters, "%v", err))
+	}
+
+	records, err := toStructs(page.Records)
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.ListRecordsResponse{
+		SessionId: page.SessionID,
+		Kind:      page.Kind,
+		Records:   records,
+		Count:     int32(page.Count),
+		Total:     int32(page.Total),
+	}, nil
+}
+
+func (s *sessionService) SearchSession(ctx context.Context, req *gothinkv1.SearchSessionRequest) (*gothinkv1.SearchSessionResponse, error) {
+	if err := sessionRequired(req.GetSessionId()); err != nil {
+		return nil, err
+	}
+
+	hits, err := search.Session(tenantStore(ctx, s.store), req.GetSessionId(), req.GetQuery(), int(req.GetLimit()))
+	if err != nil {
+		return nil, apierror.GRPCStatus(apierror.Errorf(apierror.CodeInvalidParameters, "%v", err))
+	}
+
+	response := &gothinkv1.SearchSessionResponse{
+		SessionId: req.GetSessionId(),
+		Query:     req.GetQuery(),
+		Hits:      make([]*gothinkv1.SearchHit, len(hits)),
+		Count:     int32(len(hits)),
+	}
+	for i, hit := range hits {
+		response.Hits[i] = &gothinkv1.SearchHit{
+			Kind:    hit.Kind,
+			Id:      hit.ID,
+			Field:   hit.Field,
+			Score:   hit.Score,
+			Snippet: hit.Snippet,
+		}
+	}
+	return response, nil
+}
+
+func (s *sessionService) ClearSession(ctx context.Context, req *gothinkv1.SessionRequest) (*gothinkv1.SessionStatusResponse, error) {
+	if err := sessionRequired(req.GetSessionId()); err != nil {
+		return nil, err
+	}
+
+	if err := tenantStore(ctx, s.store).ClearSession(req.GetSessionId()); err != nil {
+		s.logger.WithError(err).Error("Failed to clear session")
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.SessionStatusResponse{SessionId: req.GetSessionId(), Status: "cleared"}, nil
+}
+
+func (s *sessionService) ArchiveSession(ctx context.Context, req *gothinkv1.SessionRequest) (*gothinkv1.SessionStatusResponse, error) {
+	return s.setArchived(ctx, req.GetSessionId(), true)
+}
+
+func (s *sessionService) RestoreSession(ctx context.Context, req *gothinkv1.SessionRequest) (*gothinkv1.SessionStatusResponse, error) {
+	return s.setArchived(ctx, req.GetSessionId(), false)
+}
+
+func (s *sessionService) setArchived(ctx context.Context, sessionID string, archived bool) (*gothinkv1.SessionStatusResponse, error) {
+	if err := sessionRequired(sessionID); err != nil {
+		return nil, err
+	}
+
+	store := tenantStore(ctx, s.store)
+	if _, err := store.GetSession(sessionID); err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+
+	change, status := store.RestoreSession, "active"
+	if archived {
+		change, status = store.ArchiveSession, "archived"
+	}
+	if err := change(sessionID); err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.SessionStatusResponse{SessionId: sessionID, Status: status}, nil
+}
+
+func (s *sessionService) GetStorageStats(ctx context.Context, req *gothinkv1.StorageStatsRequest) (*gothinkv1.StorageStatsResponse, error) {
+	if req.GetLimit() < 0 {
+		return nil, apierror.GRPCStatus(apierror.Errorf(apierror.CodeInvalidParameters, "limit must be a non-negative integer"))
+	}
+
+	stats, err := tenantStore(ctx, s.store).StorageStats()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get storage stats")
+		return nil, apierror.GRPCStatus(apierror.Errorf(apierror.CodeOf(err), "Failed to get storage stats"))
+	}
+	if limit := int(req.GetLimit()); limit > 0 && limit < len(stats.SessionSizes) {
+		stats.SessionSizes = stats.SessionSizes[:limit]
+	}
+
+	encoded, err := toStruct(stats)
+	if err != nil {
+		return nil, apierror.GRPCStatus(err)
+	}
+	return &gothinkv1.StorageStatsResponse{Stats: encoded}, nil
+}
+
+func (s *sessionService) WatchEvents(req *gothinkv1.WatchEventsRequest, stream gothinkv1.SessionService_WatchEventsServer) error {
+	publisher, ok := s.store.(interface{ Events() *storage.EventBus })
+	if !ok {
+		return unsupported("the %T store does not publish events", s.store)
+	}
+
+	ctx := stream.Context()
+	sub := publisher.Events().Subscribe(storage.TenantFromContext(ctx), req.GetSessionId(), 0)
+	defer sub.Close()
+	// The headers tell the client that writes from now on reach it
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, open := <-sub.Events():
+			if !open {
+				// The store is shutting down
+				return nil
+			}
+
+			message := &gothinkv1.Event{
+				Seq:       event.Seq,
+				Type:      event.Type,
+				SessionId: event.SessionID,
+				RecordId:  event.RecordID,
+				Time:      timestamppb.New(event.Time),
+			}
+			if len(event.Record) > 0 {
+				message.Record = &structpb.Struct{}
+				if err := protojson.Unmarshal(event.Record, message.Record); err != nil {
+					s.logger.WithError(err).Error("Failed to encode event")
+					continue
+				}
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStruct encodes v, which must encode as a JSON object, as a Struct
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	encoded := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, encoded); err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+// toStructs encodes v, which must encode as a JSON array of objects, as Structs
+func toStructs(v interface{}) ([]*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, err
+	}
+
+	encoded := make([]*structpb.Struct, len(elements))
+	for i, element := range elements {
+		encoded[i] = &structpb.Struct{}
+		if err := protojson.Unmarshal(element, encoded[i]); err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
+
+func intPtr(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
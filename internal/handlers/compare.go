@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// CompareDecisions handles decision comparison requests
+func (h *DecisionHandler) CompareDecisions(w http.ResponseWriter, r *http.Request) {
+	var request api.CompareDecisionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunCompareDecisions(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunCompareDecisions compares the decisions request names, each in its own
+// session in the tenant of ctx, side by side: their statements, options,
+// scores, rankings and recommendations
+func (h *DecisionHandler) RunCompareDecisions(ctx context.Context, request api.CompareDecisionsRequest) (*api.CompareDecisionsResponse, error) {
+	if len(request.Decisions) < 2 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: at least two decisions are required")
+	}
+
+	store := tenantStore(ctx, h.storage)
+	decisions := make([]*types.DecisionData, len(request.Decisions))
+	for k, ref := range request.Decisions {
+		if ref.SessionID == "" || ref.DecisionID == "" {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: every decision needs a session_id and decision_id")
+		}
+		recorded, err := store.GetDecisions(ref.SessionID, nil)
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get decisions: %v", err)
+		}
+		for _, decision := range recorded {
+			if decision.ID == ref.DecisionID {
+				decisions[k] = decision
+			}
+		}
+		if decisions[k] == nil {
+			return nil, apierror.Errorf(apierror.CodeRecordNotFound, "Decision %s not found in session %s", ref.DecisionID, ref.SessionID)
+		}
+	}
+
+	return compareDecisions(decisions), nil
+}
+
+// compareDecisions lays decisions side by side. Options, criteria and scores
+// are listed in the order the decisions first name them.
+func compareDecisions(decisions []*types.DecisionData) *api.CompareDecisionsResponse {
+	n := len(decisions)
+	response := &api.CompareDecisionsResponse{
+		Status:         "success",
+		Decisions:      make([]api.ComparedDecision, n),
+		SharedOptions:  []string{},
+		SharedCriteria: []string{},
+		Options:        []api.ComparedOption{},
+		Scores:         []api.ComparedScore{},
+	}
+
+	options := make(map[string]int)
+	optionCounts := make(map[string]int)
+	criterionCounts := make(map[string]int)
+	var criteria []string
+	scores := make(map[[2]string]int)
+	for k, decision := range decisions {
+		compared := api.ComparedDecision{
+			SessionID:         decision.SessionID,
+			DecisionID:        decision.ID,
+			DecisionStatement: decision.DecisionStatement,
+			AnalysisType:      decision.AnalysisType,
+			Stage:             decision.Stage,
+			Options:           make([]string, len(decision.Options)),
+			Criteria:          make([]string, len(decision.Criteria)),
+			Recommendation:    decision.Recommendation,
+			CreatedAt:         decision.CreatedAt,
+		}
+		if len(decision.Ranking) > 0 {
+			compared.TopOption = decision.Ranking[0].Option
+		}
+		for i, option := range decision.Options {
+			compared.Options[i] = option.Name
+			if _, seen := options[option.Name]; !seen {
+				options[option.Name] = len(response.Options)
+				response.Options = append(response.Options, api.ComparedOption{
+					Option: option.Name,
+					In:     make([]bool, n),
+					Ranks:  make([]*int, n),
+					Scores: make([]*float64, n),
+				})
+			}
+			response.Options[options[option.Name]].In[k] = true
+			optionCounts[option.Name]++
+		}
+		for i, criterion := range decision.Criteria {
+			compared.Criteria[i] = criterion.Name
+			if criterionCounts[criterion.Name] == 0 {
+				criteria = append(criteria, criterion.Name)
+			}
+			criterionCounts[criterion.Name]++
+		}
+		for _, ranked := range decision.Ranking {
+			if i, ok := options[ranked.Option]; ok {
+				rank, score := ranked.Rank, ranked.Score
+				response.Options[i].Ranks[k], response.Options[i].Scores[k] = &rank, &score
+			}
+		}
+		for _, s := range decision.Scores {
+			key := [2]string{s.Option, s.Criterion}
+			if _, seen := scores[key]; !seen {
+				scores[key] = len(response.Scores)
+				response.Scores = append(response.Scores, api.ComparedScore{Option: s.Option, Criterion: s.Criterion, Scores: make([]*float64, n)})
+			}
+			score := s.Score
+			response.Scores[scores[key]].Scores[k] = &score
+		}
+		response.Decisions[k] = compared
+	}
+
+	for _, o := range response.Options {
+		if optionCounts[o.Option] == n {
+			response.SharedOptions = append(response.SharedOptions, o.Option)
+		}
+	}
+	for _, criterion := range criteria {
+		if criterionCounts[criterion] == n {
+			response.SharedCriteria = append(response.SharedCriteria, criterion)
+		}
+	}
+	widest := -1
+	for i := range response.Scores {
+		low, high := math.Inf(1), math.Inf(-1)
+		for _, score := range response.Scores[i].Scores {
+			if score != nil {
+				low, high = math.Min(low, *score), math.Max(high, *score)
+			}
+		}
+		response.Scores[i].Gap = high - low
+		if response.Scores[i].Gap > 0 && (widest < 0 || response.Scores[i].Gap > response.Scores[widest].Gap) {
+			widest = i
+		}
+	}
+
+	response.Summary = fmt.Sprintf("The %d decisions share %d of %d options", n, len(response.SharedOptions), len(response.Options))
+	if len(criteria) > 0 {
+		response.Summary += fmt.Sprintf(" and %d of %d criteria", len(response.SharedCriteria), len(criteria))
+	}
+	var firsts []string
+	agree := true
+	for k, d := range response.Decisions {
+		if d.TopOption == "" {
+			firsts = append(firsts, fmt.Sprintf("decision %d is unranked", k+1))
+			agree = false
+			continue
+		}
+		firsts = append(firsts, fmt.Sprintf("decision %d ranks %s first", k+1, d.TopOption))
+		agree = agree && d.TopOption == response.Decisions[0].TopOption
+	}
+	if agree {
+		response.Summary += fmt.Sprintf("; all rank %s first", response.Decisions[0].TopOption)
+	} else {
+		response.Summary += "; " + strings.Join(firsts, ", ")
+	}
+	if widest >= 0 {
+		s := response.Scores[widest]
+		response.Summary += fmt.Sprintf("; the widest score gap is %s on %s, %.4g", s.Option, s.Criterion, s.Gap)
+	}
+	return response
+}
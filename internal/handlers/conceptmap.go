@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Transformations of a concept map
+const (
+	transformMerge    = "merge"
+	transformCollapse = "collapse"
+	transformExtract  = "extract"
+	transformRelabel  = "relabel"
+)
+
+// isEdgeElement reports whether element is an edge rather than a node or
+// group
+func isEdgeElement(element types.VisualElement) bool {
+	return element.Source != "" || element.Target != ""
+}
+
+// transformConceptMap applies the transformation of request to elements, the
+// current state of its diagram, returning the elements it leaves
+func transformConceptMap(elements []types.VisualElement, request api.ConceptMapRequest) ([]types.VisualElement, error) {
+	kinds := make(map[string]bool, len(elements))
+	for _, element := range elements {
+		kinds[element.ID] = !isEdgeElement(element) && len(element.Contains) == 0
+	}
+	listed := make(map[string]bool, len(request.NodeIDs))
+	for _, id := range request.NodeIDs {
+		node, ok := kinds[id]
+		switch {
+		case listed[id]:
+			return nil, fmt.Errorf("%s is listed twice", id)
+		case !ok:
+			return nil, fmt.Errorf("the diagram has no element %s", id)
+		case !node && request.TransformationType != transformRelabel:
+			return nil, fmt.Errorf("%s is not a node", id)
+		}
+		listed[id] = true
+	}
+
+	switch request.TransformationType {
+	case transformMerge:
+		if len(request.NodeIDs) < 2 {
+			return nil, errors.New("a merge needs two or more node_ids")
+		}
+		return mergeNodes(elements, request.NodeIDs, request.Label), nil
+	case transformCollapse:
+		if len(request.NodeIDs) < 2 {
+			return nil, errors.New("a collapse needs two or more node_ids")
+		}
+		clusterID := request.TargetID
+		if clusterID == "" {
+			clusterID = "cluster:" + request.NodeIDs[0]
+		}
+		if _, exists := kinds[clusterID]; exists && !listed[clusterID] {
+			return nil, fmt.Errorf("the diagram already has an element %s", clusterID)
+		}
+		return collapseNodes(elements, request.NodeIDs, clusterID, request.Label), nil
+	case transformExtract:
+		if len(request.NodeIDs) == 0 {
+			return nil, errors.New("an extract needs node_ids")
+		}
+		if request.Depth < 0 {
+			return nil, errors.New("depth must not be negative")
+		}
+		depth := request.Depth
+		if depth == 0 {
+			depth = 1
+		}
+		return extractSubgraph(elements, request.NodeIDs, depth), nil
+	case transformRelabel:
+		if request.Pattern == "" {
+			return nil, errors.New("a relabel needs a pattern")
+		}
+		pattern, err := regexp.Compile(request.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: %v", err)
+		}
+		return relabelElements(elements, listed, pattern, request.Replacement), nil
+	default:
+		return nil, fmt.Errorf("transformation_type must be merge, collapse, extract or relabel, not %q", request.TransformationType)
+	}
+}
+
+// mergeNodes merges the nodes of ids into the first, which keeps its ID and
+// properties and takes those of the others it lacks, along with their edges.
+// Edges among the merged nodes are dropped, and so are edges the merge makes
+// copies of others, running between the same nodes with the same label.
+func mergeNodes(elements []types.VisualElement, ids []string, label string) []types.VisualElement {
+	var survivor types.VisualElement
+	properties := make(map[string]interface{})
+	for _, element := range elements {
+		if element.ID == ids[0] {
+			survivor = element
+		}
+	}
+	merged := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		merged[id] = true
+	}
+	for i := len(elements) - 1; i >= 0; i-- {
+		if merged[elements[i].ID] && !isEdgeElement(elements[i]) {
+			for key, value := range elements[i].Properties {
+				properties[key] = value
+			}
+		}
+	}
+	for key, value := range survivor.Properties {
+		properties[key] = value
+	}
+	properties["merged_from"] = append([]string{}, ids[1:]...)
+	survivor.Properties = properties
+	if label != "" {
+		survivor.Label = label
+	}
+	return contractNodes(elements, merged, survivor)
+}
+
+// collapseNodes replaces the nodes of ids with a cluster node of clusterID,
+// listing them as its members and labeled label or, failing that, with their
+// labels. Edges among the members are dropped and those reaching them lead
+// to the cluster instead.
+func collapseNodes(elements []types.VisualElement, ids []string, clusterID, label string) []types.VisualElement {
+	members := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		members[id] = true
+	}
+	if label == "" {
+		var labels []string
+		for _, element := range elements {
+			if members[element.ID] {
+				name := element.Label
+				if name == "" {
+					name = element.ID
+				}
+				labels = append(labels, name)
+			}
+		}
+		label = strings.Join(labels, ", ")
+	}
+	cluster := types.VisualElement{
+		ID:         clusterID,
+		Type:       "cluster",
+		Label:      label,
+		Properties: map[string]interface{}{"members": append([]string{}, ids...)},
+	}
+	return contractNodes(elements, members, cluster)
+}
+
+// contractNodes replaces the nodes of members with node, placed where the
+// first of them was, redirecting the edges and group memberships of members
+// to it. Edges between members are dropped, as are edges that become copies
+// of others, running between the same nodes with the same label.
+func contractNodes(elements []types.VisualElement, members map[string]bool, node types.VisualElement) []types.VisualElement {
+	rename := func(id string) string {
+		if members[id] {
+			return node.ID
+		}
+		return id
+	}
+	edgeKey := func(e types.VisualElement) [3]string { return [3]string{e.Source, e.Target, e.Label} }
+	kept := make(map[[3]string]bool)
+	for _, element := range elements {
+		if isEdgeElement(element) && !members[element.Source] && !members[element.Target] {
+			kept[edgeKey(element)] = true
+		}
+	}
+
+	result := make([]types.VisualElement, 0, len(elements))
+	placed := false
+	for _, element := range elements {
+		switch {
+		case isEdgeElement(element):
+			if !members[element.Source] && !members[element.Target] {
+				break
+			}
+			if members[element.Source] && members[element.Target] {
+				continue
+			}
+			element.Source, element.Target = rename(element.Source), rename(element.Target)
+			if kept[edgeKey(element)] {
+				continue
+			}
+			kept[edgeKey(element)] = true
+		case members[element.ID]:
+			if !placed {
+				result, placed = append(result, node), true
+			}
+			continue
+		case len(element.Contains) > 0:
+			contains := make([]string, 0, len(element.Contains))
+			seen := make(map[string]bool, len(element.Contains))
+			for _, id := range element.Contains {
+				if id = rename(id); !seen[id] {
+					contains, seen[id] = append(contains, id), true
+				}
+			}
+			element.Contains = contains
+		}
+		result = append(result, element)
+	}
+	return result
+}
+
+// extractSubgraph returns the nodes within depth edges of the nodes of ids,
+// following edges either way, with the edges between them and the groups
+// containing any of them, which keep only those they contain
+func extractSubgraph(elements []types.VisualElement, ids []string, depth int) []types.VisualElement {
+	neighbors := make(map[string][]string)
+	for _, element := range elements {
+		if isEdgeElement(element) {
+			neighbors[element.Source] = append(neighbors[element.Source], element.Target)
+			neighbors[element.Target] = append(neighbors[element.Target], element.Source)
+		}
+	}
+	reached := make(map[string]bool, len(ids))
+	frontier := append([]string{}, ids...)
+	for _, id := range ids {
+		reached[id] = true
+	}
+	for step := 0; step < depth && len(frontier) > 0; step++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbor := range neighbors[id] {
+				if !reached[neighbor] {
+					reached[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	var result []types.VisualElement
+	for _, element := range elements {
+		switch {
+		case isEdgeElement(element):
+			if !reached[element.Source] || !reached[element.Target] {
+				continue
+			}
+		case len(element.Contains) > 0:
+			var contains []string
+			for _, id := range element.Contains {
+				if reached[id] {
+					contains = append(contains, id)
+				}
+			}
+			if len(contains) == 0 {
+				continue
+			}
+			element.Contains = contains
+		case !reached[element.ID]:
+			continue
+		}
+		result = append(result, element)
+	}
+	return result
+}
+
+// relabelElements replaces pattern in the labels of elements with
+// replacement; when only names any elements, in their labels alone
+func relabelElements(elements []types.VisualElement, only map[string]bool, pattern *regexp.Regexp, replacement string) []types.VisualElement {
+	result := make([]types.VisualElement, len(elements))
+	for i, element := range elements {
+		if len(only) == 0 || only[element.ID] {
+			element.Label = pattern.ReplaceAllString(element.Label, replacement)
+		}
+		result[i] = element
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"math"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/mdp"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Methods by which runs compute their confidence from the run itself
+const (
+	confidenceExact                = "exact"
+	confidenceActionGap            = "action_gap"
+	confidenceRolloutBootstrap     = "rollout_bootstrap"
+	confidenceHoeffding            = "hoeffding_bound"
+	confidenceGaussianTail         = "gaussian_tail_bound"
+	confidencePosteriorImprovement = "posterior_improvement"
+	confidencePathPosterior        = "path_posterior"
+	confidenceProbabilityBest      = "probability_best"
+)
+
+// confidenceBases says what the confidence of each method is the chance of
+var confidenceBases = map[string]string{
+	confidenceExact:                "Dynamic programming converged within its tolerance, so the policy is optimal up to it",
+	confidenceActionGap:            "Share of states whose action beats every other by more than twice the error bound the Bellman residual puts on the Q-values",
+	confidenceRolloutBootstrap:     "Share of bootstrap resamples of the rollouts through each move from the root in which the best move keeps the best mean reward",
+	confidenceHoeffding:            "Lower bound, by Hoeffding's inequality on rewards within [0, 1], on the chance that the selected arm has the highest mean",
+	confidenceGaussianTail:         "Lower bound, by the Gaussian tail with the arms' sample variances, on the chance that the selected arm has the highest mean",
+	confidencePosteriorImprovement: "One less the highest chance, under the Gaussian-process posterior, that a candidate point beats the best value by a tenth of the evaluations' standard deviation",
+	confidencePathPosterior:        "Posterior probability of the decoded state path given the observations under the model",
+	confidenceProbabilityBest:      "Share of draws from the variants' Beta posteriors in which the variant with the least expected loss has the highest conversion rate",
+}
+
+// setConfidence records value, computed by method, as the confidence of run
+// and returns it as responses report it
+func setConfidence(run *types.StochasticAlgorithmData, value float64, method string) *api.Confidence {
+	run.Confidence, run.ConfidenceMethod = value, method
+	return &api.Confidence{Value: value, Method: method, Basis: confidenceBases[method]}
+}
+
+// mdpConfidence returns the confidence in the policy of solution, solved
+// with discount gamma, and its method. A policy that has not converged is
+// only sure of the actions whose Q-values lead every other action's by more
+// than twice the error the Bellman residual bounds them by: the values are
+// within residual/(1-gamma) of the optimal ones, and the Q-values within
+// gamma times that.
+func mdpConfidence(solution *mdp.Solution, gamma float64) (float64, string) {
+	if solution.Converged {
+		return 1, confidenceExact
+	}
+	if len(solution.Policy) == 0 {
+		return 1, confidenceActionGap
+	}
+
+	residual := 0.0
+	for state, q := range solution.QValues {
+		best := math.Inf(-1)
+		for _, value := range q {
+			best = math.Max(best, value)
+		}
+		residual = math.Max(residual, math.Abs(best-solution.Values[state]))
+	}
+	bound := math.Inf(1)
+	if gamma < 1 {
+		bound = gamma * residual / (1 - gamma)
+	}
+	sure := 0
+	for state, action := range solution.Policy {
+		q := solution.QValues[state]
+		certain := true
+		for other, value := range q {
+			certain = certain && (other == action || q[action]-value > 2*bound)
+		}
+		if certain {
+			sure++
+		}
+	}
+	return float64(sure) / float64(len(solution.Policy)), confidenceActionGap
+}
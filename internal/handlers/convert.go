@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// DecisionOptions converts the options of a request to their stored form
+func DecisionOptions(options []api.DecisionOption) []types.DecisionOption {
+	if options == nil {
+		return nil
+	}
+	converted := make([]types.DecisionOption, len(options))
+	for i, option := range options {
+		converted[i] = types.DecisionOption{
+			ID:                   option.ID,
+			Name:                 option.Name,
+			Description:          option.Description,
+			ExpectedValue:        option.ExpectedValue,
+			RiskLevel:            option.RiskLevel,
+			ProbabilityOfSuccess: option.ProbabilityOfSuccess,
+		}
+		if option.Outcome != nil {
+			outcome := types.OutcomeDistribution(*option.Outcome)
+			converted[i].Outcome = &outcome
+		}
+		for _, flow := range option.CashFlows {
+			converted[i].CashFlows = append(converted[i].CashFlows, types.CashFlow(flow))
+		}
+	}
+	return converted
+}
+
+// DecisionCriteria converts the criteria of a request to their stored form
+func DecisionCriteria(criteria []api.DecisionCriterion) []types.DecisionCriterion {
+	if criteria == nil {
+		return nil
+	}
+	converted := make([]types.DecisionCriterion, len(criteria))
+	for i, criterion := range criteria {
+		converted[i] = types.DecisionCriterion(criterion)
+	}
+	return converted
+}
+
+// DecisionScores converts the scores of a request to their stored form
+func DecisionScores(scores []api.DecisionScore) []types.DecisionScore {
+	if scores == nil {
+		return nil
+	}
+	converted := make([]types.DecisionScore, len(scores))
+	for i, score := range scores {
+		converted[i] = types.DecisionScore{
+			Option:    score.Option,
+			Criterion: score.Criterion,
+			Score:     score.Score,
+			Rationale: score.Rationale,
+		}
+	}
+	return converted
+}
+
+// DecisionMatrix converts stored scores to their response form
+func DecisionMatrix(scores []types.DecisionScore) []api.DecisionMatrixScore {
+	converted := make([]api.DecisionMatrixScore, len(scores))
+	for i, score := range scores {
+		converted[i] = api.DecisionMatrixScore(score)
+	}
+	return converted
+}
+
+// RankedOptions converts a stored ranking to its response form
+func RankedOptions(ranking []types.RankedOption) []api.RankedOption {
+	if ranking == nil {
+		return nil
+	}
+	converted := make([]api.RankedOption, len(ranking))
+	for i, ranked := range ranking {
+		converted[i] = api.RankedOption(ranked)
+	}
+	return converted
+}
+
+// DecisionRisks converts the risk register of a request to its stored form
+func DecisionRisks(risks []api.DecisionRisk) []types.DecisionRisk {
+	if risks == nil {
+		return nil
+	}
+	converted := make([]types.DecisionRisk, len(risks))
+	for i, r := range risks {
+		converted[i] = types.DecisionRisk{
+			Name:        r.Name,
+			Option:      r.Option,
+			Description: r.Description,
+			Probability: r.Probability,
+			Impact:      r.Impact,
+		}
+		for _, m := range r.Mitigations {
+			converted[i].Mitigations = append(converted[i].Mitigations, types.RiskMitigation(m))
+		}
+	}
+	return converted
+}
+
+// RiskAssessment converts a stored risk assessment to its response form
+func RiskAssessment(assessment *types.RiskAssessment) *api.RiskAssessment {
+	if assessment == nil {
+		return nil
+	}
+	converted := &api.RiskAssessment{
+		Risks:          make([]api.AssessedRisk, len(assessment.Risks)),
+		InherentMatrix: assessment.InherentMatrix,
+		ResidualMatrix: assessment.ResidualMatrix,
+		Options:        make([]api.OptionRisk, len(assessment.Options)),
+		Recommendation: assessment.Recommendation,
+	}
+	for i, r := range assessment.Risks {
+		converted.Risks[i] = api.AssessedRisk{
+			Name:                r.Name,
+			Option:              r.Option,
+			Inherent:            api.RiskCell(r.Inherent),
+			Residual:            api.RiskCell(r.Residual),
+			ResidualProbability: r.ResidualProbability,
+			ResidualImpact:      r.ResidualImpact,
+			ExpectedLoss:        r.ExpectedLoss,
+			MitigationCost:      r.MitigationCost,
+		}
+	}
+	for i, option := range assessment.Options {
+		converted.Options[i] = api.OptionRisk(option)
+	}
+	return converted
+}
+
+// DecisionStakeholders converts the stakeholder profiles of a request to
+// their stored form
+func DecisionStakeholders(stakeholders []api.DecisionStakeholder) []types.DecisionStakeholder {
+	if stakeholders == nil {
+		return nil
+	}
+	converted := make([]types.DecisionStakeholder, len(stakeholders))
+	for i, s := range stakeholders {
+		converted[i] = types.DecisionStakeholder{
+			Name:      s.Name,
+			Role:      s.Role,
+			Influence: s.Influence,
+			Interest:  s.Interest,
+		}
+		for _, p := range s.Preferences {
+			converted[i].Preferences = append(converted[i].Preferences, types.StakeholderPreference(p))
+		}
+	}
+	return converted
+}
+
+// StakeholderAnalysis converts a stored stakeholder analysis to its response
+// form
+func StakeholderAnalysis(analysis *types.StakeholderAnalysis) *api.StakeholderAnalysis {
+	if analysis == nil {
+		return nil
+	}
+	converted := &api.StakeholderAnalysis{
+		Stakeholders:   make([]api.PositionedStakeholder, len(analysis.Stakeholders)),
+		Grid:           api.StakeholderGrid(analysis.Grid),
+		Options:        make([]api.StakeholderScore, len(analysis.Options)),
+		Recommendation: analysis.Recommendation,
+	}
+	for i, s := range analysis.Stakeholders {
+		converted.Stakeholders[i] = api.PositionedStakeholder(s)
+	}
+	for i, option := range analysis.Options {
+		converted.Options[i] = api.StakeholderScore(option)
+	}
+	return converted
+}
+
+// GroupAggregation converts a stored group aggregation to its response form
+func GroupAggregation(aggregation *types.GroupAggregation) *api.GroupAggregation {
+	if aggregation == nil {
+		return nil
+	}
+	converted := &api.GroupAggregation{
+		Method:          aggregation.Method,
+		Evaluators:      aggregation.Evaluators,
+		Ranking:         make([]api.GroupStanding, len(aggregation.Ranking)),
+		Options:         make([]api.GroupOption, len(aggregation.Options)),
+		Pairwise:        aggregation.Pairwise,
+		CondorcetWinner: aggregation.CondorcetWinner,
+		Disagreement:    aggregation.Disagreement,
+		Recommendation:  aggregation.Recommendation,
+	}
+	for i, standing := range aggregation.Ranking {
+		converted.Ranking[i] = api.GroupStanding(standing)
+	}
+	for i, option := range aggregation.Options {
+		converted.Options[i] = api.GroupOption(option)
+	}
+	for _, conflict := range aggregation.Conflicts {
+		converted.Conflicts = append(converted.Conflicts, api.EvaluatorConflict(conflict))
+	}
+	return converted
+}
+
+// CostBenefitAnalysis converts a stored cost-benefit analysis to its
+// response form
+func CostBenefitAnalysis(analysis *types.CostBenefitAnalysis) *api.CostBenefitAnalysis {
+	if analysis == nil {
+		return nil
+	}
+	converted := &api.CostBenefitAnalysis{
+		DiscountRate:   analysis.DiscountRate,
+		Options:        make([]api.OptionCostBenefit, len(analysis.Options)),
+		Recommendation: analysis.Recommendation,
+	}
+	for i, option := range analysis.Options {
+		converted.Options[i] = api.OptionCostBenefit(option)
+	}
+	return converted
+}
+
+// KepnerTregoeAnalysis converts a stored Kepner-Tregoe analysis to its
+// response form
+func KepnerTregoeAnalysis(analysis *types.KepnerTregoeAnalysis) *api.KepnerTregoeAnalysis {
+	if analysis == nil {
+		return nil
+	}
+	converted := &api.KepnerTregoeAnalysis{
+		Wants:          make([]api.WantObjective, len(analysis.Wants)),
+		Options:        make([]api.KepnerTregoeOption, len(analysis.Options)),
+		BalancedChoice: analysis.BalancedChoice,
+		Recommendation: analysis.Recommendation,
+	}
+	for _, must := range analysis.Musts {
+		converted.Musts = append(converted.Musts, api.MustObjective(must))
+	}
+	for k, want := range analysis.Wants {
+		converted.Wants[k] = api.WantObjective(want)
+	}
+	for i, option := range analysis.Options {
+		converted.Options[i] = api.KepnerTregoeOption{
+			Rank:          option.Rank,
+			Option:        option.Option,
+			Qualified:     option.Qualified,
+			FailedMusts:   option.FailedMusts,
+			WeightedScore: option.WeightedScore,
+			Share:         option.Share,
+			Threat:        option.Threat,
+			SeriousRisks:  option.SeriousRisks,
+		}
+		for _, result := range option.Musts {
+			converted.Options[i].Musts = append(converted.Options[i].Musts, api.MustResult(result))
+		}
+		for _, score := range option.Wants {
+			converted.Options[i].Wants = append(converted.Options[i].Wants, api.WantScore(score))
+		}
+		for _, c := range option.AdverseConsequences {
+			converted.Options[i].AdverseConsequences = append(converted.Options[i].AdverseConsequences, api.AdverseConsequence(c))
+		}
+	}
+	return converted
+}
+
+// ScenarioAnalysis converts a stored scenario analysis to its response form
+func ScenarioAnalysis(analysis *types.ScenarioData) *api.ScenarioAnalysis {
+	if analysis == nil {
+		return nil
+	}
+	converted := &api.ScenarioAnalysis{
+		Title:          analysis.Title,
+		Scenarios:      make([]api.Scenario, len(analysis.Scenarios)),
+		Tolerance:      analysis.Tolerance,
+		Evaluations:    make([]api.ScenarioEvaluation, len(analysis.Evaluations)),
+		RobustOptions:  analysis.RobustOptions,
+		Bets:           analysis.Bets,
+		Recommendation: analysis.Recommendation,
+	}
+	for i, scenario := range analysis.Scenarios {
+		converted.Scenarios[i] = api.Scenario(scenario)
+	}
+	for i, evaluation := range analysis.Evaluations {
+		converted.Evaluations[i] = api.ScenarioEvaluation(evaluation)
+	}
+	return converted
+}
+
+// VisualElements converts the elements of a request to their stored form
+func VisualElements(elements []api.VisualElement) []types.VisualElement {
+	if elements == nil {
+		return nil
+	}
+	converted := make([]types.VisualElement, len(elements))
+	for i, element := range elements {
+		converted[i] = types.VisualElement(element)
+	}
+	return converted
+}
+
+// armStatistics converts the arm statistics of a bandit run to their reported
+// form
+func armStatistics(stats []types.ArmStatistics) []api.ArmStatistics {
+	converted := make([]api.ArmStatistics, len(stats))
+	for i, arm := range stats {
+		converted[i] = api.ArmStatistics(arm)
+	}
+	return converted
+}
+
+// valueOr returns the value v points to, or fallback when v is nil
+func valueOr(v *float64, fallback float64) float64 {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
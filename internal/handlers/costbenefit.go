@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/costbenefit"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// CostBenefit handles cost-benefit analysis requests
+func (h *DecisionHandler) CostBenefit(w http.ResponseWriter, r *http.Request) {
+	var request api.CostBenefitRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunCostBenefit(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunCostBenefit appraises the options of the decision request names, in its
+// session in the tenant of ctx, by their costs and benefits of request or as
+// recorded, discounted at request's rate. The analysis is stored on the
+// decision, and each option's expected value set to its net present value.
+func (h *DecisionHandler) RunCostBenefit(ctx context.Context, request api.CostBenefitRequest) (*api.CostBenefitResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid cost-benefit analysis: session_id and decision_id are required")
+	}
+	if !(request.DiscountRate >= 0) || math.IsInf(request.DiscountRate, 0) {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid cost-benefit analysis: the discount rate must be finite and not negative")
+	}
+
+	var analyzed types.DecisionData
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		for _, o := range request.CashFlows {
+			found := false
+			for i := range decision.Options {
+				if decision.Options[i].Name == o.Option {
+					decision.Options[i].CashFlows, found = make([]types.CashFlow, len(o.CashFlows)), true
+					for k, flow := range o.CashFlows {
+						decision.Options[i].CashFlows[k] = types.CashFlow(flow)
+					}
+				}
+			}
+			if !found {
+				return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid cost-benefit analysis: there is no option %s", o.Option)
+			}
+		}
+		if err := appraiseOptions(decision, request.DiscountRate); err != nil {
+			return err
+		}
+		analyzed = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to analyze costs and benefits: %v", err)
+	}
+
+	return &api.CostBenefitResponse{
+		DecisionID: request.DecisionID,
+		Status:     "success",
+		Analysis:   *CostBenefitAnalysis(analyzed.CostBenefit),
+	}, nil
+}
+
+// appraiseOptions appraises the options of decision by their cash flows at
+// rate and stores the analysis on it. The recommendation names the option of
+// highest net present value unless the decision is ranked by its scores.
+func appraiseOptions(decision *types.DecisionData, rate float64) error {
+	if len(decision.Options) == 0 {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid cost-benefit analysis: the decision has no options")
+	}
+	analysis := &types.CostBenefitAnalysis{DiscountRate: rate, Options: make([]types.OptionCostBenefit, len(decision.Options))}
+	for i := range decision.Options {
+		option := &decision.Options[i]
+		if len(option.CashFlows) == 0 {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid cost-benefit analysis: option %s has no costs or benefits", option.Name)
+		}
+		flows := make([]costbenefit.Flow, len(option.CashFlows))
+		for k, flow := range option.CashFlows {
+			flows[k] = costbenefit.Flow(flow)
+		}
+		result, err := costbenefit.Analyze(flows, rate)
+		if err != nil {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid cost-benefit analysis: option %s: %v", option.Name, err)
+		}
+		appraised := types.OptionCostBenefit{
+			Option:          option.Name,
+			NPV:             result.NPV,
+			PresentBenefits: result.PVBenefits,
+			PresentCosts:    result.PVCosts,
+		}
+		if result.HasRatio {
+			appraised.BenefitCostRatio = &result.BenefitCostRatio
+		}
+		if result.HasIRR {
+			appraised.IRR = &result.IRR
+		}
+		if result.PaysBack {
+			appraised.PaybackPeriod = &result.Payback
+		}
+		if result.PaysBackDiscounted {
+			appraised.DiscountedPaybackPeriod = &result.DiscountedPayback
+		}
+		analysis.Options[i] = appraised
+		option.ExpectedValue = &result.NPV
+	}
+
+	sort.SliceStable(analysis.Options, func(a, b int) bool { return analysis.Options[a].NPV > analysis.Options[b].NPV })
+	for i := range analysis.Options {
+		analysis.Options[i].Rank = i + 1
+		if i > 0 && math.Abs(analysis.Options[i].NPV-analysis.Options[i-1].NPV) < 1e-9 {
+			analysis.Options[i].Rank = analysis.Options[i-1].Rank
+		}
+	}
+
+	best := analysis.Options[0]
+	analysis.Recommendation = fmt.Sprintf("%s has the highest NPV at a discount rate of %g%%, %.4g", best.Option, 100*rate, best.NPV)
+	if best.BenefitCostRatio != nil {
+		analysis.Recommendation += fmt.Sprintf(", with a benefit-cost ratio of %.3g", *best.BenefitCostRatio)
+	}
+	if best.IRR != nil {
+		analysis.Recommendation += fmt.Sprintf(", an IRR of about %.3g%%", 100*(*best.IRR))
+	}
+	if best.PaybackPeriod != nil {
+		analysis.Recommendation += fmt.Sprintf(", paying back in %.3g periods", *best.PaybackPeriod)
+	} else {
+		analysis.Recommendation += ", never paying back"
+	}
+	decision.CostBenefit = analysis
+	if len(decision.Ranking) == 0 {
+		decision.Recommendation = analysis.Recommendation
+	}
+	return nil
+}
@@ -1,23 +1,31 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/ahp"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/mcda"
+	"github.com/rainmana/gothink/internal/risk"
 	"github.com/rainmana/gothink/internal/storage"
 	"github.com/rainmana/gothink/internal/types"
 )
 
 // DecisionHandler handles decision framework operations
 type DecisionHandler struct {
-	storage *storage.Storage
+	storage storage.Store
 	logger  *logrus.Logger
 }
 
 // NewDecisionHandler creates a new decision handler
-func NewDecisionHandler(storage *storage.Storage, logger *logrus.Logger) *DecisionHandler {
+func NewDecisionHandler(storage storage.Store, logger *logrus.Logger) *DecisionHandler {
 	return &DecisionHandler{
 		storage: storage,
 		logger:  logger,
@@ -26,58 +34,88 @@ func NewDecisionHandler(storage *storage.Storage, logger *logrus.Logger) *Decisi
 
 // DecisionFramework handles decision framework requests
 func (h *DecisionHandler) DecisionFramework(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID         string                    `json:"session_id"`
-		DecisionStatement string                    `json:"decision_statement"`
-		Options           []types.DecisionOption    `json:"options"`
-		Criteria          []types.DecisionCriterion `json:"criteria,omitempty"`
-		Stakeholders      []string                  `json:"stakeholders,omitempty"`
-		Constraints       []string                  `json:"constraints,omitempty"`
-		TimeHorizon       string                    `json:"time_horizon,omitempty"`
-		RiskTolerance     string                    `json:"risk_tolerance,omitempty"`
-		AnalysisType      string                    `json:"analysis_type"`
-		Stage             string                    `json:"stage"`
+	var request api.DecisionFrameworkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
+	response, err := h.RecordDecision(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
 		return
 	}
 
+	h.respondWithJSON(w, response)
+}
+
+// RecordDecision adds the decision of request to its session in the tenant
+// of ctx, or revises the decision it names, ranking its options first when it
+// has scores, assessing its risks when it has a risk register and analyzing
+// its stakeholders when they are profiled
+func (h *DecisionHandler) RecordDecision(ctx context.Context, request api.DecisionFrameworkRequest) (*api.DecisionFrameworkResponse, error) {
 	// Create decision data
 	decision := &types.DecisionData{
-		ID:                "",
-		DecisionStatement: request.DecisionStatement,
-		Options:           request.Options,
-		Criteria:          request.Criteria,
-		Stakeholders:      request.Stakeholders,
-		Constraints:       request.Constraints,
-		TimeHorizon:       request.TimeHorizon,
-		RiskTolerance:     request.RiskTolerance,
-		AnalysisType:      request.AnalysisType,
-		Stage:             request.Stage,
-		Iteration:         1,
-		NextStageNeeded:   true,
-		CreatedAt:         time.Now(),
-	}
-
-	// Add to storage
-	if err := h.storage.AddDecision(request.SessionID, decision); err != nil {
-		h.logger.WithError(err).Error("Failed to add decision")
-		h.respondWithError(w, "Failed to add decision", http.StatusInternalServerError)
-		return
+		ID:                  "",
+		DecisionStatement:   request.DecisionStatement,
+		Options:             DecisionOptions(request.Options),
+		Criteria:            DecisionCriteria(request.Criteria),
+		Stakeholders:        request.Stakeholders,
+		Constraints:         request.Constraints,
+		TimeHorizon:         request.TimeHorizon,
+		RiskTolerance:       request.RiskTolerance,
+		AnalysisType:        request.AnalysisType,
+		Stage:               request.Stage,
+		Scores:              DecisionScores(request.Scores),
+		ScoringMethod:       request.ScoringMethod,
+		Normalization:       request.Normalization,
+		Risks:               DecisionRisks(request.Risks),
+		StakeholderProfiles: DecisionStakeholders(request.StakeholderProfiles),
+		Iteration:           1,
+		NextStageNeeded:     true,
+		CreatedAt:           time.Now(),
+	}
+	if err := ScoreDecision(decision); err != nil {
+		return nil, err
+	}
+	if err := AssessRisks(decision); err != nil {
+		return nil, err
+	}
+	if err := AnalyzeStakeholders(decision); err != nil {
+		return nil, err
 	}
 
-	response := map[string]interface{}{
-		"decision_id":   decision.ID,
-		"status":        "success",
-		"has_options":   len(request.Options) > 0,
-		"has_criteria":  len(request.Criteria) > 0,
-		"analysis_type": request.AnalysisType,
-		"stage":         request.Stage,
+	// Add to storage, or revise the recorded decision
+	store := tenantStore(ctx, h.storage)
+	if request.DecisionID != "" {
+		var revised types.DecisionData
+		err := store.UpdateDecision(request.SessionID, request.DecisionID, func(current *types.DecisionData) error {
+			reviseDecision(current, decision)
+			revised = *current
+			return nil
+		})
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to revise decision: %v", err)
+		}
+		decision = &revised
+	} else if err := store.AddDecision(request.SessionID, decision); err != nil {
+		h.logger.WithError(err).Error("Failed to add decision")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add decision")
 	}
 
-	h.respondWithJSON(w, response)
+	return &api.DecisionFrameworkResponse{
+		DecisionID:          decision.ID,
+		Status:              "success",
+		Iteration:           decision.Iteration,
+		HasOptions:          len(request.Options) > 0,
+		HasCriteria:         len(request.Criteria) > 0,
+		AnalysisType:        request.AnalysisType,
+		Stage:               request.Stage,
+		Ranking:             RankedOptions(decision.Ranking),
+		Recommendation:      decision.Recommendation,
+		RiskAssessment:      RiskAssessment(decision.RiskAssessment),
+		StakeholderAnalysis: StakeholderAnalysis(decision.StakeholderAnalysis),
+	}, nil
 }
 
 // ExpectedUtility handles expected utility analysis requests
@@ -92,24 +130,458 @@ func (h *DecisionHandler) ExpectedUtility(w http.ResponseWriter, r *http.Request
 
 // MultiCriteria handles multi-criteria analysis requests
 func (h *DecisionHandler) MultiCriteria(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Multi-criteria analysis not yet implemented",
-		"status":  "coming_soon",
+	var request api.MultiCriteriaRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunMultiCriteria(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
 	}
+
 	h.respondWithJSON(w, response)
 }
 
+// RunMultiCriteria ranks the options of the decision request names, in its
+// session in the tenant of ctx, by the scores, method and normalization of
+// request or as recorded, and stores the ranking on the decision
+func (h *DecisionHandler) RunMultiCriteria(ctx context.Context, request api.MultiCriteriaRequest) (*api.MultiCriteriaResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: session_id and decision_id are required")
+	}
+
+	var scored types.DecisionData
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		if len(request.Scores) > 0 {
+			decision.Scores = DecisionScores(request.Scores)
+		}
+		if request.ScoringMethod != "" {
+			decision.ScoringMethod = request.ScoringMethod
+		}
+		if request.Normalization != "" {
+			decision.Normalization = request.Normalization
+		}
+		if len(decision.Scores) == 0 {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: decision %s has no scores", decision.ID)
+		}
+		if err := ScoreDecision(decision); err != nil {
+			return err
+		}
+		scored = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to score decision: %v", err)
+	}
+
+	return &api.MultiCriteriaResponse{
+		DecisionID:     request.DecisionID,
+		Status:         "success",
+		ScoringMethod:  scored.ScoringMethod,
+		Normalization:  scored.Normalization,
+		Ranking:        RankedOptions(scored.Ranking),
+		Recommendation: scored.Recommendation,
+	}, nil
+}
+
 // RiskAnalysis handles risk analysis requests
 func (h *DecisionHandler) RiskAnalysis(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Risk analysis not yet implemented",
-		"status":  "coming_soon",
+	var request api.RiskAnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunRiskAnalysis(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
 	}
+
 	h.respondWithJSON(w, response)
 }
 
+// RunRiskAnalysis assesses the risk register of request, or as recorded, for
+// the decision request names, in its session in the tenant of ctx, and
+// stores the assessment on the decision
+func (h *DecisionHandler) RunRiskAnalysis(ctx context.Context, request api.RiskAnalysisRequest) (*api.RiskAnalysisResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid risk analysis: session_id and decision_id are required")
+	}
+
+	var assessed types.DecisionData
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		if request.Risks != nil {
+			decision.Risks = DecisionRisks(request.Risks)
+		}
+		if len(decision.Risks) == 0 {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid risk analysis: the decision has no risks")
+		}
+		if err := AssessRisks(decision); err != nil {
+			return err
+		}
+		assessed = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to analyze risks: %v", err)
+	}
+
+	return &api.RiskAnalysisResponse{
+		DecisionID: request.DecisionID,
+		Status:     "success",
+		Assessment: *RiskAssessment(assessed.RiskAssessment),
+	}, nil
+}
+
+// AHP handles Analytic Hierarchy Process requests
+func (h *DecisionHandler) AHP(w http.ResponseWriter, r *http.Request) {
+	var request api.AHPRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunAHP(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunAHP derives the weights of the criteria of the decision request names,
+// in its session in the tenant of ctx, from the pairwise comparisons of
+// request, and stores them on the decision, re-ranking any scores it has.
+// Given comparisons of the options on every criterion, it instead stores the
+// options' priorities as their scores and ranks the options by their weighted
+// sum, the AHP's synthesis.
+func (h *DecisionHandler) RunAHP(ctx context.Context, request api.AHPRequest) (*api.AHPResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid AHP analysis: session_id and decision_id are required")
+	}
+
+	var response *api.AHPResponse
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		var err error
+		response, err = weighDecision(decision, request)
+		return err
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to analyze decision: %v", err)
+	}
+	return response, nil
+}
+
+// weighDecision stores on decision the criteria weights, and any option
+// scores and ranking, that the comparisons of request derive
+func weighDecision(decision *types.DecisionData, request api.AHPRequest) (*api.AHPResponse, error) {
+	criteria := make([]string, len(decision.Criteria))
+	for j, criterion := range decision.Criteria {
+		criteria[j] = criterion.Name
+	}
+	weights, err := comparePairs(request.CriteriaMatrix, criteria, "criteria")
+	if err != nil {
+		return nil, err
+	}
+	response := &api.AHPResponse{
+		DecisionID: decision.ID,
+		Status:     "success",
+		Criteria:   *weights,
+		Consistent: weights.Consistent,
+	}
+	for j := range decision.Criteria {
+		decision.Criteria[j].Weight = weights.Priorities[criteria[j]]
+	}
+	var inconsistent []string
+	if !weights.Consistent {
+		inconsistent = append(inconsistent, fmt.Sprintf("the criteria (consistency ratio %.3g)", weights.ConsistencyRatio))
+	}
+	if len(request.OptionMatrices) == 0 {
+		// Scores the decision already has are re-ranked by the new weights
+		if err := ScoreDecision(decision); err != nil {
+			return nil, err
+		}
+		response.Ranking = RankedOptions(decision.Ranking)
+		response.Recommendation = decision.Recommendation
+		return response, nil
+	}
+
+	options := make([]string, len(decision.Options))
+	for i, option := range decision.Options {
+		options[i] = option.Name
+	}
+	matrices := make(map[string][][]float64, len(request.OptionMatrices))
+	for _, m := range request.OptionMatrices {
+		if _, ok := weights.Priorities[m.Criterion]; !ok {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid AHP analysis: there is no criterion %s", m.Criterion)
+		}
+		if _, ok := matrices[m.Criterion]; ok {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid AHP analysis: the options are compared twice on %s", m.Criterion)
+		}
+		matrices[m.Criterion] = m.Matrix
+	}
+	var scores []types.DecisionScore
+	for _, criterion := range criteria {
+		matrix, ok := matrices[criterion]
+		if !ok {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid AHP analysis: the options are not compared on %s", criterion)
+		}
+		priorities, err := comparePairs(matrix, options, "options on "+criterion)
+		if err != nil {
+			return nil, err
+		}
+		priorities.Criterion = criterion
+		response.Options = append(response.Options, *priorities)
+		response.Consistent = response.Consistent && priorities.Consistent
+		if !priorities.Consistent {
+			inconsistent = append(inconsistent, fmt.Sprintf("the options on %s (%.3g)", criterion, priorities.ConsistencyRatio))
+		}
+		for _, option := range options {
+			scores = append(scores, types.DecisionScore{Option: option, Criterion: criterion, Score: priorities.Priorities[option]})
+		}
+	}
+
+	// Priorities are preferences, whatever a criterion's direction, already
+	// on a common scale
+	decision.Scores, decision.ScoringMethod, decision.Normalization = scores, mcda.WeightedSum, mcda.None
+	for j := range decision.Criteria {
+		decision.Criteria[j].Direction = ""
+	}
+	if err := ScoreDecision(decision); err != nil {
+		return nil, err
+	}
+	if len(inconsistent) > 0 {
+		decision.Recommendation += "; the judgments of " + strings.Join(inconsistent, " and ") + " are too inconsistent to rely on"
+	}
+	response.Ranking = RankedOptions(decision.Ranking)
+	response.Recommendation = decision.Recommendation
+	return response, nil
+}
+
+// comparePairs returns the priorities matrix derives for the items names, in
+// their order, named what in errors
+func comparePairs(matrix [][]float64, names []string, what string) (*api.AHPPriorities, error) {
+	if len(matrix) != len(names) {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid AHP analysis: the matrix of %s compares %d items, but there are %d", what, len(matrix), len(names))
+	}
+	result, err := ahp.Priorities(matrix)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid AHP analysis: %s: %v", what, err)
+	}
+	priorities := &api.AHPPriorities{
+		Priorities:       make(map[string]float64, len(names)),
+		LambdaMax:        result.LambdaMax,
+		ConsistencyIndex: result.ConsistencyIndex,
+		ConsistencyRatio: result.ConsistencyRatio,
+		Consistent:       result.Consistent,
+	}
+	for i, name := range names {
+		if _, ok := priorities.Priorities[name]; ok {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid AHP analysis: %s is listed twice", name)
+		}
+		priorities.Priorities[name] = result.Priorities[i]
+	}
+	return priorities, nil
+}
+
+// ScoreDecision ranks the options of decision by their scores on its
+// criteria, with its scoring method and normalization, which default to a
+// weighted sum of max-normalized scores, or TOPSIS on vector-normalized ones
+// for a topsis analysis, and stores the ranking and a recommendation on it.
+// Every option must be scored once on every criterion. A decision without
+// scores is left as it is, unless it is a topsis analysis, which needs them.
+func ScoreDecision(decision *types.DecisionData) error {
+	topsis := decision.AnalysisType == mcda.TOPSIS
+	if len(decision.Scores) == 0 {
+		if topsis {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: a topsis analysis needs the scores of the options on the criteria")
+		}
+		return nil
+	}
+	switch {
+	case decision.ScoringMethod == "" && topsis:
+		decision.ScoringMethod = mcda.TOPSIS
+	case decision.ScoringMethod == "":
+		decision.ScoringMethod = mcda.WeightedSum
+	}
+	switch {
+	case decision.Normalization == "" && decision.ScoringMethod == mcda.TOPSIS:
+		decision.Normalization = mcda.Vector
+	case decision.Normalization == "":
+		decision.Normalization = mcda.Max
+	}
+
+	criteria := make([]mcda.Criterion, len(decision.Criteria))
+	criterionIndex := make(map[string]int, len(decision.Criteria))
+	for j, criterion := range decision.Criteria {
+		if _, ok := criterionIndex[criterion.Name]; ok {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: criterion %s is listed twice", criterion.Name)
+		}
+		if criterion.Direction != "" && criterion.Direction != "benefit" && criterion.Direction != "cost" {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: criterion %s has unknown direction %q", criterion.Name, criterion.Direction)
+		}
+		criterionIndex[criterion.Name] = j
+		criteria[j] = mcda.Criterion{Name: criterion.Name, Weight: criterion.Weight, Cost: criterion.Direction == "cost"}
+	}
+	optionIndex := make(map[string]int, len(decision.Options))
+	scores := make([][]float64, len(decision.Options))
+	scored := make([][]bool, len(decision.Options))
+	for i, option := range decision.Options {
+		if _, ok := optionIndex[option.Name]; ok {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: option %s is listed twice", option.Name)
+		}
+		optionIndex[option.Name] = i
+		scores[i] = make([]float64, len(criteria))
+		scored[i] = make([]bool, len(criteria))
+	}
+	for _, score := range decision.Scores {
+		i, ok := optionIndex[score.Option]
+		if !ok {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: there is no option %s", score.Option)
+		}
+		j, ok := criterionIndex[score.Criterion]
+		if !ok {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: there is no criterion %s", score.Criterion)
+		}
+		if scored[i][j] {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: option %s is scored twice on %s", score.Option, score.Criterion)
+		}
+		scores[i][j], scored[i][j] = score.Score, true
+	}
+	for i, option := range decision.Options {
+		for j, criterion := range decision.Criteria {
+			if !scored[i][j] {
+				return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: option %s has no score on %s", option.Name, criterion.Name)
+			}
+		}
+	}
+
+	result, err := mcda.Rank(criteria, scores, mcda.Options{Method: decision.ScoringMethod, Normalization: decision.Normalization})
+	if err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: %v", err)
+	}
+	decision.Ranking = make([]types.RankedOption, len(result.Ranking))
+	var first []string
+	for k, ranked := range result.Ranking {
+		option := decision.Options[ranked.Option].Name
+		contributions := make(map[string]float64, len(criteria))
+		for j, contribution := range ranked.Contributions {
+			contributions[criteria[j].Name] = contribution
+		}
+		decision.Ranking[k] = types.RankedOption{
+			Rank:              ranked.Rank,
+			Option:            option,
+			Score:             ranked.Score,
+			Contributions:     contributions,
+			IdealDistance:     ranked.IdealDistance,
+			AntiIdealDistance: ranked.AntiIdealDistance,
+		}
+		if ranked.Rank == 1 {
+			first = append(first, option)
+		}
+	}
+	verb := "ranks"
+	if len(first) > 1 {
+		verb = "tie"
+	}
+	score := strings.ReplaceAll(decision.ScoringMethod, "_", " ")
+	if decision.ScoringMethod == mcda.TOPSIS {
+		score = "TOPSIS closeness coefficient"
+	}
+	decision.Recommendation = fmt.Sprintf("%s %s first with a %s of %.4g over %d criteria (%s normalization)",
+		strings.Join(first, " and "), verb, score, result.Ranking[0].Score, len(criteria), decision.Normalization)
+	return nil
+}
+
+// AssessRisks assesses the risk register of decision and stores the
+// assessment on it, rating each option by its worst residual risk. The
+// recommendation names the option of highest risk-adjusted value unless the
+// decision is ranked by its scores. A decision without risks is left as it
+// is.
+func AssessRisks(decision *types.DecisionData) error {
+	if len(decision.Risks) == 0 {
+		return nil
+	}
+	risks := make([]risk.Risk, len(decision.Risks))
+	for k, r := range decision.Risks {
+		risks[k] = risk.Risk{Name: r.Name, Option: r.Option, Probability: r.Probability, Impact: r.Impact}
+		for _, m := range r.Mitigations {
+			risks[k].Mitigations = append(risks[k].Mitigations, risk.Mitigation(m))
+		}
+	}
+	options := make([]risk.Option, len(decision.Options))
+	for i, option := range decision.Options {
+		options[i] = risk.Option{Name: option.Name, ExpectedValue: valueOr(option.ExpectedValue, 0)}
+	}
+	result, err := risk.Assess(risks, options)
+	if err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid risk analysis: %v", err)
+	}
+
+	assessment := &types.RiskAssessment{
+		Risks:          make([]types.AssessedRisk, len(result.Risks)),
+		InherentMatrix: riskMatrix(result.Inherent),
+		ResidualMatrix: riskMatrix(result.Residual),
+		Options:        make([]types.OptionRisk, len(result.Options)),
+	}
+	for k, r := range result.Risks {
+		assessment.Risks[k] = types.AssessedRisk{
+			Name:                r.Name,
+			Option:              r.Option,
+			Inherent:            riskCell(r.Inherent),
+			Residual:            riskCell(r.Residual),
+			ResidualProbability: r.ResidualProbability,
+			ResidualImpact:      r.ResidualImpact,
+			ExpectedLoss:        r.ExpectedLoss,
+			MitigationCost:      r.MitigationCost,
+		}
+	}
+	ratings := make(map[string]string, len(result.Options))
+	for i, option := range result.Options {
+		assessment.Options[i] = types.OptionRisk{
+			Option:            option.Name,
+			Risks:             option.Risks,
+			ExpectedValue:     option.ExpectedValue,
+			ExpectedLoss:      option.ExpectedLoss,
+			MitigationCost:    option.MitigationCost,
+			RiskAdjustedValue: option.RiskAdjustedValue,
+			Rating:            option.Rating,
+		}
+		ratings[option.Name] = option.Rating
+	}
+	for i := range decision.Options {
+		decision.Options[i].RiskLevel = ratings[decision.Options[i].Name]
+	}
+
+	best := result.Options[0]
+	assessment.Recommendation = fmt.Sprintf("%s has the highest risk-adjusted value, %.4g: an expected value of %.4g less an expected loss of %.4g and mitigation costs of %.4g over %d risks (%s risk)",
+		best.Name, best.RiskAdjustedValue, best.ExpectedValue, best.ExpectedLoss, best.MitigationCost, best.Risks, best.Rating)
+	decision.RiskAssessment = assessment
+	if len(decision.Ranking) == 0 {
+		decision.Recommendation = assessment.Recommendation
+	}
+	return nil
+}
+
+// riskMatrix returns matrix as rows of probability levels
+func riskMatrix(matrix risk.Matrix) [][]int {
+	rows := make([][]int, len(matrix))
+	for i := range matrix {
+		rows[i] = append([]int(nil), matrix[i][:]...)
+	}
+	return rows
+}
+
+// riskCell returns the stored form of a risk's level
+func riskCell(level risk.Level) types.RiskCell {
+	return types.RiskCell{ProbabilityLevel: level.Probability, ImpactLevel: level.Impact, Severity: level.Severity, Rating: level.Rating}
+}
+
 // Helper methods
 
 func (h *DecisionHandler) respondWithJSON(w http.ResponseWriter, data interface{}) {
@@ -117,8 +589,6 @@ func (h *DecisionHandler) respondWithJSON(w http.ResponseWriter, data interface{
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *DecisionHandler) respondWithError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+func (h *DecisionHandler) respondWithError(w http.ResponseWriter, code apierror.Code, message string) {
+	apierror.Write(w, code, message)
 }
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/decisiontree"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Operations on a decision's diagram
+const (
+	diagramSeed   = "seed"
+	diagramUpdate = "update"
+	diagramDelete = "delete"
+)
+
+// DecisionTree handles decision tree diagram requests
+func (h *VisualHandler) DecisionTree(w http.ResponseWriter, r *http.Request) {
+	var request api.DecisionTreeDiagramRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunDecisionTreeDiagram(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunDecisionTreeDiagram seeds a decision tree diagram from the decision
+// request names, in its session in the tenant of ctx, or edits the diagram
+// seeded from it. The diagram records the decision's ID and the decision the
+// diagram's, so each leads to the other; deleting the whole diagram drops the
+// decision's link.
+func (h *VisualHandler) RunDecisionTreeDiagram(ctx context.Context, request api.DecisionTreeDiagramRequest) (*api.DecisionTreeDiagramResponse, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision tree diagram: "+format, args...)
+	}
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, invalid("session_id and decision_id are required")
+	}
+	if request.Operation == "" {
+		request.Operation = diagramSeed
+	}
+
+	store := tenantStore(ctx, h.storage)
+	decisions, err := store.GetDecisions(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get decisions: %v", err)
+	}
+	var decision *types.DecisionData
+	for _, d := range decisions {
+		if d.ID == request.DecisionID {
+			decision = d
+			break
+		}
+	}
+	if decision == nil {
+		return nil, apierror.Errorf(apierror.CodeRecordNotFound, "Decision %s not found in session %s", request.DecisionID, request.SessionID)
+	}
+
+	visual := &types.VisualData{
+		DiagramID:   request.DiagramID,
+		DiagramType: "decision-tree",
+		DecisionID:  decision.ID,
+		Observation: decision.DecisionStatement,
+		CreatedAt:   time.Now(),
+	}
+	switch request.Operation {
+	case diagramSeed:
+		if visual.DiagramID == "" {
+			visual.DiagramID = decision.DiagramID
+		}
+		if visual.DiagramID == "" {
+			visual.DiagramID = "decision:" + decision.ID
+		}
+		visual.Operation, visual.Elements = "create", seedDecisionTree(decision)
+	case diagramUpdate, diagramDelete:
+		switch {
+		case decision.DiagramID == "":
+			return nil, invalid("decision %s has no diagram; seed one first", decision.ID)
+		case visual.DiagramID != "" && visual.DiagramID != decision.DiagramID:
+			return nil, invalid("decision %s is linked to diagram %s, not %s", decision.ID, decision.DiagramID, visual.DiagramID)
+		case request.Operation == diagramUpdate && len(request.Elements) == 0:
+			return nil, invalid("an update needs elements")
+		}
+		visual.DiagramID, visual.Operation, visual.Elements = decision.DiagramID, request.Operation, VisualElements(request.Elements)
+	default:
+		return nil, invalid("unknown operation %q", request.Operation)
+	}
+	for _, element := range visual.Elements {
+		if element.ID == "" {
+			return nil, invalid("every element needs an ID")
+		}
+	}
+
+	// Check the diagram the operation would leave before recording it
+	visuals, err := store.GetVisualData(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get visual data: %v", err)
+	}
+	elements := storage.DiagramElements(append(visuals, visual), visual.DiagramID)
+	nodes := make(map[string]bool, len(elements))
+	for _, element := range elements {
+		if element.Source == "" && element.Target == "" {
+			nodes[element.ID] = true
+		}
+	}
+	for _, element := range elements {
+		if (element.Source != "" || element.Target != "") && (!nodes[element.Source] || !nodes[element.Target]) {
+			return nil, invalid("edge %s must join two nodes of the diagram", element.ID)
+		}
+	}
+
+	elements = layoutOperation(visual, elements)
+	if err := store.AddVisualData(request.SessionID, visual); err != nil {
+		h.logger.WithError(err).Error("Failed to add visual data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add visual data")
+	}
+	linked := visual.DiagramID
+	if request.Operation == diagramDelete && len(elements) == 0 {
+		linked = ""
+	}
+	if linked != decision.DiagramID {
+		err := store.UpdateDecision(request.SessionID, decision.ID, func(decision *types.DecisionData) error {
+			decision.DiagramID = linked
+			return nil
+		})
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to link decision to its diagram: %v", err)
+		}
+	}
+
+	response := &api.DecisionTreeDiagramResponse{
+		VisualID:   visual.ID,
+		Status:     "success",
+		DiagramID:  visual.DiagramID,
+		DecisionID: decision.ID,
+		Operation:  request.Operation,
+		Elements:   make([]api.VisualElement, len(elements)),
+	}
+	for i, element := range elements {
+		response.Elements[i] = api.VisualElement(element)
+	}
+	if len(elements) > 0 {
+		response.DOT = storage.DiagramDOT(visual.DiagramID, visual.DiagramType, elements)
+	}
+	return response, nil
+}
+
+// seedDecisionTree draws decision as a tree: the decision at the root, a
+// branch to each option and, from each option, a branch to each criterion
+// holding the option's score on it. The branch to the top-ranked option is
+// optimal.
+func seedDecisionTree(decision *types.DecisionData) []types.VisualElement {
+	ranks := make(map[string]types.RankedOption, len(decision.Ranking))
+	for _, ranked := range decision.Ranking {
+		ranks[ranked.Option] = ranked
+	}
+	scores := make(map[[2]string]float64, len(decision.Scores))
+	for _, score := range decision.Scores {
+		scores[[2]string{score.Option, score.Criterion}] = score.Score
+	}
+
+	elements := []types.VisualElement{{
+		ID:         "decision",
+		Type:       "node",
+		Label:      decision.DecisionStatement,
+		Properties: map[string]interface{}{"node_type": decisiontree.Decision, "decision_id": decision.ID},
+	}}
+	for _, option := range decision.Options {
+		optionID := "option:" + option.Name
+		properties := map[string]interface{}{"node_type": decisiontree.Chance}
+		if len(decision.Criteria) == 0 {
+			properties["node_type"] = decisiontree.Terminal
+		}
+		if option.Description != "" {
+			properties["description"] = option.Description
+		}
+		if option.ExpectedValue != nil {
+			properties["expected_value"] = *option.ExpectedValue
+		}
+		if option.ProbabilityOfSuccess != nil {
+			properties["probability_of_success"] = *option.ProbabilityOfSuccess
+		}
+		ranked, isRanked := ranks[option.Name]
+		if isRanked {
+			properties["rank"], properties["score"] = ranked.Rank, ranked.Score
+		}
+		elements = append(elements,
+			types.VisualElement{ID: optionID, Type: "node", Label: option.Name, Properties: properties},
+			types.VisualElement{
+				ID:         "decision->" + optionID,
+				Type:       "edge",
+				Label:      option.Name,
+				Source:     "decision",
+				Target:     optionID,
+				Properties: map[string]interface{}{"optimal": isRanked && ranked.Rank == 1},
+			})
+
+		for _, criterion := range decision.Criteria {
+			criterionID := optionID + "/criterion:" + criterion.Name
+			properties := map[string]interface{}{"node_type": decisiontree.Terminal, "criterion": criterion.Name, "weight": criterion.Weight}
+			label := criterion.Name
+			if score, ok := scores[[2]string{option.Name, criterion.Name}]; ok {
+				properties["score"] = score
+				label += fmt.Sprintf(": %g", score)
+			}
+			elements = append(elements,
+				types.VisualElement{ID: criterionID, Type: "node", Label: label, Properties: properties},
+				types.VisualElement{
+					ID:         optionID + "->" + criterionID,
+					Type:       "edge",
+					Label:      criterion.Name,
+					Source:     optionID,
+					Target:     criterionID,
+					Properties: map[string]interface{}{"criterion_weight": criterion.Weight},
+				})
+		}
+	}
+	return elements
+}
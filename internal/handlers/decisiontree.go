@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/decisiontree"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// DecisionTree handles decision tree analysis requests
+func (h *DecisionHandler) DecisionTree(w http.ResponseWriter, r *http.Request) {
+	var request api.DecisionTreeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunDecisionTree(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunDecisionTree evaluates the decision tree of request by expected-value
+// rollback and records the evaluated tree as a decision-tree diagram in its
+// session in the tenant of ctx
+func (h *DecisionHandler) RunDecisionTree(ctx context.Context, request api.DecisionTreeRequest) (*api.DecisionTreeResponse, error) {
+	if request.SessionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision tree: session_id is required")
+	}
+	if request.Objective != "" && request.Objective != "maximize" && request.Objective != "minimize" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision tree: unknown objective %q", request.Objective)
+	}
+	nodes := make([]decisiontree.Node, len(request.Nodes))
+	for i, n := range request.Nodes {
+		nodes[i] = decisiontree.Node(n)
+	}
+	result, err := decisiontree.Evaluate(nodes, decisiontree.Options{Minimize: request.Objective == "minimize"})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision tree: %v", err)
+	}
+
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.ID] = i
+	}
+	name := func(id string) string {
+		if label := nodes[index[id]].Label; label != "" {
+			return label
+		}
+		return id
+	}
+
+	response := &api.DecisionTreeResponse{
+		Status:        "success",
+		ExpectedValue: result.Value,
+		Strategy:      make([]api.DecisionTreeChoice, len(result.Strategy)),
+		Outcomes:      make([]api.DecisionTreeOutcome, len(result.Outcomes)),
+		Nodes:         make([]api.EvaluatedDecisionNode, len(result.Nodes)),
+	}
+	for i, n := range result.Nodes {
+		response.Nodes[i] = api.EvaluatedDecisionNode{
+			ID:               n.ID,
+			Type:             n.Type,
+			Value:            n.Value,
+			Choice:           n.Choice,
+			Optimal:          n.Optimal,
+			ReachProbability: n.ReachProbability,
+		}
+		if n.Parent == "" {
+			for id := n.ID; ; {
+				response.Path = append(response.Path, id)
+				e := result.Nodes[index[id]]
+				if e.Type != decisiontree.Decision {
+					break
+				}
+				id = e.Choice
+			}
+		}
+	}
+	choices := make([]string, len(result.Strategy))
+	for k, e := range result.Strategy {
+		response.Strategy[k] = api.DecisionTreeChoice{
+			Node:             e.ID,
+			Label:            e.Label,
+			Choice:           e.Choice,
+			ChoiceLabel:      nodes[index[e.Choice]].Label,
+			Value:            e.Value,
+			ReachProbability: e.ReachProbability,
+		}
+		choices[k] = fmt.Sprintf("%s at %s", name(e.Choice), name(e.ID))
+	}
+	for k, o := range result.Outcomes {
+		response.Outcomes[k] = api.DecisionTreeOutcome{Node: o.Node, Label: nodes[index[o.Node]].Label, Probability: o.Probability, Payoff: o.Payoff}
+	}
+	if len(choices) == 0 {
+		response.Summary = fmt.Sprintf("The tree leaves nothing to decide; its expected value is %.4g", result.Value)
+	} else {
+		response.Summary = fmt.Sprintf("Choose %s, for an expected value of %.4g over %d outcomes", strings.Join(choices, ", then "), result.Value, len(result.Outcomes))
+	}
+
+	visual := &types.VisualData{
+		Operation:   "create",
+		Elements:    treeElements(result),
+		DiagramID:   "decision-tree:" + response.Path[0],
+		DiagramType: "decision-tree",
+		Observation: request.Title,
+		Insight:     response.Summary,
+		CreatedAt:   time.Now(),
+	}
+	layoutOperation(visual, visual.Elements)
+	if err := tenantStore(ctx, h.storage).AddVisualData(request.SessionID, visual); err != nil {
+		h.logger.WithError(err).Error("Failed to add decision tree data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add decision tree data")
+	}
+	response.VisualID = visual.ID
+	return response, nil
+}
+
+// treeElements returns the nodes of an evaluated tree and the edges of its
+// branches, marking those the optimal strategy can take
+func treeElements(result *decisiontree.Result) []types.VisualElement {
+	elements := make([]types.VisualElement, 0, 2*len(result.Nodes))
+	for _, n := range result.Nodes {
+		properties := map[string]interface{}{
+			"node_type":         n.Type,
+			"payoff":            n.Payoff,
+			"value":             n.Value,
+			"optimal":           n.Optimal,
+			"reach_probability": n.ReachProbability,
+		}
+		if n.Choice != "" {
+			properties["choice"] = n.Choice
+		}
+		elements = append(elements, types.VisualElement{ID: n.ID, Type: "node", Label: n.Label, Properties: properties})
+	}
+	for _, n := range result.Nodes {
+		if n.Parent == "" {
+			continue
+		}
+		elements = append(elements, types.VisualElement{
+			ID:          n.Parent + "->" + n.ID,
+			Type:        "edge",
+			Label:       n.Label,
+			Source:      n.Parent,
+			Target:      n.ID,
+			Probability: n.Probability,
+			Properties:  map[string]interface{}{"optimal": n.Optimal},
+		})
+	}
+	return elements
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/dominance"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// maxDominanceSamples is the most samples a compared distribution may give,
+// as many as a Monte Carlo run's trials
+const maxDominanceSamples = 1000000
+
+// StochasticDominance handles stochastic dominance requests
+func (h *StochasticHandler) StochasticDominance(w http.ResponseWriter, r *http.Request) {
+	var request api.DominanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunStochasticDominance(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunStochasticDominance compares the two distributions of request by
+// stochastic dominance and by their tails, and records the comparison in its
+// session in the tenant of ctx. Given a decision, it also sets the expected
+// value and risk level of the decision's options named like the
+// distributions, and its recommendation, in the same transaction.
+func (h *StochasticHandler) RunStochasticDominance(ctx context.Context, request api.DominanceRequest) (*api.DominanceResponse, error) {
+	// Set defaults
+	if request.Alpha == 0 {
+		request.Alpha = 0.05
+	}
+	if request.Goal == "" {
+		request.Goal = "maximize"
+	}
+	if request.Goal != "maximize" && request.Goal != "minimize" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: unknown goal %q", request.Goal)
+	}
+
+	store := tenantStore(ctx, h.storage)
+	nameA, a, err := outcomes(ctx, store, request.SessionID, request.A, dominance.A)
+	if err != nil {
+		return nil, err
+	}
+	nameB, b, err := outcomes(ctx, store, request.SessionID, request.B, dominance.B)
+	if err != nil {
+		return nil, err
+	}
+	if nameA == nameB {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: both distributions are named %s", nameA)
+	}
+
+	result, err := dominance.Compare(a, b, dominance.Options{Alpha: request.Alpha, Minimize: request.Goal == "minimize"})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: %v", err)
+	}
+	named := func(d string) string {
+		switch d {
+		case dominance.A:
+			return nameA
+		case dominance.B:
+			return nameB
+		}
+		return ""
+	}
+	summaryA := dominanceSummary(nameA, request.A.AlgorithmID, result.A)
+	summaryB := dominanceSummary(nameB, request.B.AlgorithmID, result.B)
+	summary := dominanceVerdict(result, nameA, nameB, request.Goal) +
+		fmt.Sprintf(". The worst %g%% of outcomes average %.4g for %s and %.4g for %s",
+			100*request.Alpha, result.A.CVaR, nameA, result.B.CVaR, nameB)
+
+	// Create dominance data
+	dominanceData := &types.DominanceData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "stochastic_dominance",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"a":           nameA,
+				"b":           nameB,
+				"alpha":       request.Alpha,
+				"goal":        request.Goal,
+				"decision_id": request.DecisionID,
+			},
+			Result:     summary,
+			Iterations: len(a) + len(b),
+			Value:      &result.ProbabilityABetter,
+			CreatedAt:  time.Now(),
+		},
+		A:                  summaryA,
+		B:                  summaryB,
+		FirstOrder:         named(result.FirstOrder),
+		SecondOrder:        named(result.SecondOrder),
+		Leader:             named(result.Leader),
+		Epsilon:            result.Epsilon,
+		KolmogorovSmirnov:  result.KolmogorovSmirnov,
+		ProbabilityABetter: result.ProbabilityABetter,
+		DecisionID:         request.DecisionID,
+	}
+
+	// Add to storage, feeding the decision along with the run so that
+	// neither is recorded without the other
+	err = store.Transaction(request.SessionID, func(tx *storage.Tx) error {
+		if err := keepPayload(&dominanceData.StochasticAlgorithmData, dominanceData); err != nil {
+			return err
+		}
+		tx.AddStochasticAlgorithm(&dominanceData.StochasticAlgorithmData)
+		if request.DecisionID != "" {
+			tx.UpdateDecision(request.DecisionID, func(decision *types.DecisionData) error {
+				return feedDecision(decision, summaryA, summaryB, request.Goal, summary)
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to add dominance data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add dominance data: %v", err)
+	}
+
+	return &api.DominanceResponse{
+		AlgorithmID:        dominanceData.ID,
+		Status:             "success",
+		Summary:            summary,
+		HasResult:          true,
+		Goal:               request.Goal,
+		Alpha:              request.Alpha,
+		A:                  api.DominanceSummary(summaryA),
+		B:                  api.DominanceSummary(summaryB),
+		FirstOrder:         dominanceData.FirstOrder,
+		SecondOrder:        dominanceData.SecondOrder,
+		Leader:             dominanceData.Leader,
+		Epsilon:            result.Epsilon,
+		KolmogorovSmirnov:  result.KolmogorovSmirnov,
+		ProbabilityABetter: result.ProbabilityABetter,
+		DecisionID:         request.DecisionID,
+		Recommendation:     summary,
+	}, nil
+}
+
+// outcomes returns the name of source, fallback unless it names itself or a
+// run, and its samples: those it gives, or the trial outputs of the Monte
+// Carlo run it names in the session in store, simulated again with the run's
+// recorded seed. A run that ran out of time is simulated for the trials it
+// ran, which the seed draws alike.
+func outcomes(ctx context.Context, store storage.Store, sessionID string, source api.DominanceSource, fallback string) (string, []float64, error) {
+	switch {
+	case source.AlgorithmID != "" && len(source.Samples) > 0:
+		return "", nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: distribution %s gives both algorithm_id and samples", fallback)
+	case source.AlgorithmID == "" && len(source.Samples) == 0:
+		return "", nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: distribution %s needs algorithm_id or samples", fallback)
+	case len(source.Samples) > maxDominanceSamples:
+		return "", nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: at most %d samples", maxDominanceSamples)
+	case len(source.Samples) > 0:
+		if source.Name == "" {
+			source.Name = fallback
+		}
+		return source.Name, source.Samples, nil
+	}
+
+	record, err := findRun(store, sessionID, source.AlgorithmID)
+	if err != nil {
+		return "", nil, err
+	}
+	if record.Algorithm != "monte_carlo" || record.Rerun == nil {
+		return "", nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: run %s (%s) is not a Monte Carlo simulation that can be run again", record.ID, record.Algorithm)
+	}
+	raw, err := json.Marshal(record.Rerun.Request)
+	if err != nil {
+		return "", nil, apierror.Errorf(apierror.CodeInternal, "Failed to encode the request of run %s", record.ID)
+	}
+	var request api.MonteCarloRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return "", nil, apierror.Errorf(apierror.CodeInternal, "Failed to decode the request of run %s", record.ID)
+	}
+	request.Trials, request.TimeLimit = record.Iterations, 0
+	result, err := simulateMonteCarlo(ctx, &request)
+	if err != nil {
+		return "", nil, err
+	}
+	if source.Name == "" {
+		source.Name = record.ID
+	}
+	return source.Name, result.Outputs, nil
+}
+
+// dominanceSummary returns the summary of the distribution name, from the
+// run algorithmID if any
+func dominanceSummary(name, algorithmID string, s dominance.Summary) types.DominanceSummary {
+	return types.DominanceSummary{
+		Name:        name,
+		AlgorithmID: algorithmID,
+		Samples:     s.Samples,
+		Mean:        s.Mean,
+		StdDev:      s.StdDev,
+		Min:         s.Min,
+		Max:         s.Max,
+		VaR:         s.VaR,
+		CVaR:        s.CVaR,
+	}
+}
+
+// dominanceVerdict states which of the distributions nameA and nameB result
+// prefers and to whom
+func dominanceVerdict(result *dominance.Result, nameA, nameB, goal string) string {
+	better := "higher"
+	if goal == "minimize" {
+		better = "lower"
+	}
+	first, second := nameA, nameB
+	if result.FirstOrder == dominance.B || result.SecondOrder == dominance.B || result.Leader == dominance.B {
+		first, second = nameB, nameA
+	}
+	switch {
+	case result.FirstOrder != "":
+		return fmt.Sprintf("%s dominates %s at first order: anyone who prefers %s outcomes should prefer %s, whatever their attitude to risk",
+			first, second, better, first)
+	case result.SecondOrder != "":
+		return fmt.Sprintf("%s dominates %s at second order: anyone averse to risk should prefer %s, though one who seeks risk might not",
+			first, second, first)
+	case result.Leader != "":
+		return fmt.Sprintf("Neither %s nor %s dominates the other: %s has the better mean but the worse outcomes over %.1f%% of the area between their distributions, so the choice depends on the attitude to risk",
+			nameA, nameB, first, 100*result.Epsilon)
+	}
+	return fmt.Sprintf("Neither %s nor %s dominates the other and their means are equal, so the choice depends on the attitude to risk", nameA, nameB)
+}
+
+// feedDecision sets the expected value of the options of decision named like
+// the distributions a and b to their means, and their risk levels by their
+// CVaRs under goal: high for the worse tail, low for the better and medium
+// for both when they are equal. The decision's recommendation becomes
+// recommendation.
+func feedDecision(decision *types.DecisionData, a, b types.DominanceSummary, goal, recommendation string) error {
+	riskA, riskB := "medium", "medium"
+	switch {
+	case a.CVaR == b.CVaR:
+	case (a.CVaR < b.CVaR) == (goal == "maximize"):
+		// a's worst outcomes are the worse
+		riskA, riskB = "high", "low"
+	default:
+		riskA, riskB = "low", "high"
+	}
+
+	for _, fed := range []struct {
+		summary types.DominanceSummary
+		risk    string
+	}{{a, riskA}, {b, riskB}} {
+		found := false
+		for i := range decision.Options {
+			option := &decision.Options[i]
+			if option.Name == fed.summary.Name {
+				option.ExpectedValue = &fed.summary.Mean
+				option.RiskLevel = fed.risk
+				found = true
+			}
+		}
+		if !found {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "decision %s has no option named %s", decision.ID, fed.summary.Name)
+		}
+	}
+	decision.Recommendation = recommendation
+	return nil
+}
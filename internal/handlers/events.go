@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment so
+// proxies keep the connection open
+const eventKeepAlive = 15 * time.Second
+
+// EventsHandler streams storage events to clients as server-sent events
+type EventsHandler struct {
+	bus    *storage.EventBus
+	logger *logrus.Logger
+}
+
+// NewEventsHandler creates a new events handler
+func NewEventsHandler(bus *storage.EventBus, logger *logrus.Logger) *EventsHandler {
+	return &EventsHandler{
+		bus:    bus,
+		logger: logger,
+	}
+}
+
+// Stream handles event stream requests (GET /api/v1/events). Each storage
+// write of the request's tenant is sent as an event named after its type with
+// the event as JSON data; the optional session_id query parameter limits the
+// stream to one session. The stream ends when the client disconnects.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.respondWithError(w, apierror.CodeInternal, "Streaming not supported")
+		return
+	}
+
+	sub := h.bus.Subscribe(storage.TenantFromContext(r.Context()), sessionIDFromRequest(r), 0)
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, open := <-sub.Events():
+			if !open {
+				// The store is shutting down
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.WithError(err).Error("Failed to encode event")
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", strconv.FormatUint(event.Seq, 10), event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *EventsHandler) respondWithError(w http.ResponseWriter, code apierror.Code, message string) {
+	apierror.Write(w, code, message)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/rainmana/gothink/internal/voting"
+)
+
+// GroupDecision handles group decision requests
+func (h *DecisionHandler) GroupDecision(w http.ResponseWriter, r *http.Request) {
+	var request api.GroupDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunGroupDecision(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunGroupDecision records the evaluations of request on the decision it
+// names, in its session in the tenant of ctx, replacing those of the same
+// evaluators, and aggregates all the decision's evaluations, storing the
+// aggregation on the decision
+func (h *DecisionHandler) RunGroupDecision(ctx context.Context, request api.GroupDecisionRequest) (*api.GroupDecisionResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: session_id and decision_id are required")
+	}
+	if request.Method == "" {
+		request.Method = voting.Borda
+	}
+	if request.Method != voting.Borda && request.Method != voting.Condorcet && request.Method != voting.Range {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: unknown method %q", request.Method)
+	}
+
+	var aggregated types.DecisionData
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		for _, e := range request.Evaluations {
+			evaluation := types.DecisionEvaluation{Evaluator: e.Evaluator, Scores: make([]types.EvaluatorScore, len(e.Scores))}
+			for k, score := range e.Scores {
+				evaluation.Scores[k] = types.EvaluatorScore(score)
+			}
+			replaced := false
+			for k := range decision.Evaluations {
+				if decision.Evaluations[k].Evaluator == e.Evaluator {
+					decision.Evaluations[k], replaced = evaluation, true
+				}
+			}
+			if !replaced {
+				decision.Evaluations = append(decision.Evaluations, evaluation)
+			}
+		}
+		if len(decision.Evaluations) == 0 {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: the decision has no evaluations")
+		}
+		if err := aggregateEvaluations(decision, request.Method); err != nil {
+			return err
+		}
+		aggregated = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to aggregate evaluations: %v", err)
+	}
+
+	return &api.GroupDecisionResponse{
+		DecisionID:  request.DecisionID,
+		Status:      "success",
+		Aggregation: *GroupAggregation(aggregated.GroupAggregation),
+	}, nil
+}
+
+// aggregateEvaluations aggregates the evaluations of decision and stores the
+// aggregation on it, ranked by method. The recommendation names the options
+// ranked first unless the decision is ranked by its scores.
+func aggregateEvaluations(decision *types.DecisionData, method string) error {
+	options := make([]string, len(decision.Options))
+	index := make(map[string]int, len(decision.Options))
+	for i, option := range decision.Options {
+		options[i], index[option.Name] = option.Name, i
+	}
+	ballots := make([]voting.Ballot, len(decision.Evaluations))
+	for k, e := range decision.Evaluations {
+		ballots[k] = voting.Ballot{Evaluator: e.Evaluator, Scores: make([]float64, len(options))}
+		scored := make([]bool, len(options))
+		for _, score := range e.Scores {
+			i, ok := index[score.Option]
+			switch {
+			case !ok:
+				return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: evaluator %s scores unknown option %s", e.Evaluator, score.Option)
+			case scored[i]:
+				return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: evaluator %s scores %s twice", e.Evaluator, score.Option)
+			}
+			ballots[k].Scores[i], scored[i] = score.Score, true
+		}
+		for i, ok := range scored {
+			if !ok {
+				return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: evaluator %s has not scored %s", e.Evaluator, options[i])
+			}
+		}
+	}
+	result, err := voting.Aggregate(options, ballots)
+	if err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: %v", err)
+	}
+	ranking, err := result.Ranking(method)
+	if err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid group decision: %v", err)
+	}
+
+	aggregation := &types.GroupAggregation{
+		Method:          method,
+		Evaluators:      len(ballots),
+		Ranking:         make([]types.GroupStanding, len(ranking)),
+		Options:         make([]types.GroupOption, len(result.Options)),
+		Pairwise:        result.Pairwise,
+		CondorcetWinner: result.CondorcetWinner,
+		Disagreement:    result.Disagreement,
+	}
+	var first []string
+	for k, standing := range ranking {
+		aggregation.Ranking[k] = types.GroupStanding(standing)
+		if standing.Rank == 1 {
+			first = append(first, standing.Option)
+		}
+	}
+	for i, o := range result.Options {
+		aggregation.Options[i] = types.GroupOption{
+			Option:        o.Name,
+			BordaPoints:   o.Borda,
+			CopelandScore: o.Copeland,
+			RangeScore:    o.Range,
+			MeanRank:      o.MeanRank,
+			RankSpread:    o.RankSpread,
+			ScoreSpread:   o.ScoreSpread,
+		}
+	}
+	for _, c := range result.Conflicts {
+		aggregation.Conflicts = append(aggregation.Conflicts, types.EvaluatorConflict{EvaluatorA: c.A, EvaluatorB: c.B, Correlation: c.Correlation})
+	}
+
+	verb := "ranks"
+	if len(first) > 1 {
+		verb = "tie"
+	}
+	by, score := "Borda count", fmt.Sprintf("%.4g points", ranking[0].Score)
+	switch method {
+	case voting.Condorcet:
+		by, score = "pairwise majorities", fmt.Sprintf("a Copeland score of %.0f", ranking[0].Score)
+	case voting.Range:
+		by, score = "range voting", fmt.Sprintf("a mean score of %.4g", ranking[0].Score)
+	}
+	aggregation.Recommendation = fmt.Sprintf("By %s, %s %s first with %s from %d evaluators", by, strings.Join(first, " and "), verb, score, len(ballots))
+	if result.CondorcetWinner != "" {
+		aggregation.Recommendation += fmt.Sprintf("; %s is the Condorcet winner", result.CondorcetWinner)
+	} else {
+		aggregation.Recommendation += "; there is no Condorcet winner"
+	}
+	aggregation.Recommendation += fmt.Sprintf("; the evaluators' disagreement is %.2f", result.Disagreement)
+	if len(result.Conflicts) > 0 && result.Conflicts[0].Correlation < 0 {
+		c := result.Conflicts[0]
+		aggregation.Recommendation += fmt.Sprintf(", %s and %s conflicting most (correlation %.2f)", c.A, c.B, c.Correlation)
+	}
+	decision.GroupAggregation = aggregation
+	if len(decision.Ranking) == 0 {
+		decision.Recommendation = aggregation.Recommendation
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// DecisionHistory handles decision history requests
+func (h *DecisionHandler) DecisionHistory(w http.ResponseWriter, r *http.Request) {
+	var request api.DecisionHistoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunDecisionHistory(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunDecisionHistory lists the iterations of the decision request names, in
+// its session in the tenant of ctx, with what changed in each
+func (h *DecisionHandler) RunDecisionHistory(ctx context.Context, request api.DecisionHistoryRequest) (*api.DecisionHistoryResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid history: session_id and decision_id are required")
+	}
+
+	decisions, err := tenantStore(ctx, h.storage).GetDecisions(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get decisions: %v", err)
+	}
+	for _, decision := range decisions {
+		if decision.ID == request.DecisionID {
+			return decisionHistory(decision), nil
+		}
+	}
+	return nil, apierror.Errorf(apierror.CodeRecordNotFound, "Decision %s not found in session %s", request.DecisionID, request.SessionID)
+}
+
+// reviseDecision makes next the current iteration of decision, keeping its
+// identity, its link to its diagram and the iteration next replaces in its
+// history. Analyses of the replaced iteration that next does not redo, such
+// as simulations, group aggregations and cost-benefit analyses, are dropped
+// with it.
+func reviseDecision(decision, next *types.DecisionData) {
+	id, sessionID, createdAt, diagramID := decision.ID, decision.SessionID, decision.CreatedAt, decision.DiagramID
+	history := append(append([]types.DecisionIteration{}, decision.History...), decisionIteration(decision))
+	iteration := decision.Iteration + 1
+	revisedAt := next.CreatedAt
+	*decision = *next
+	decision.ID, decision.SessionID, decision.CreatedAt, decision.DiagramID = id, sessionID, createdAt, diagramID
+	decision.History, decision.Iteration, decision.RevisedAt = history, iteration, &revisedAt
+}
+
+// decisionIteration captures the current iteration of decision
+func decisionIteration(decision *types.DecisionData) types.DecisionIteration {
+	recordedAt := decision.CreatedAt
+	if decision.RevisedAt != nil {
+		recordedAt = *decision.RevisedAt
+	}
+	return types.DecisionIteration{
+		Iteration:         decision.Iteration,
+		DecisionStatement: decision.DecisionStatement,
+		AnalysisType:      decision.AnalysisType,
+		Stage:             decision.Stage,
+		Options:           decision.Options,
+		Criteria:          decision.Criteria,
+		Scores:            decision.Scores,
+		ScoringMethod:     decision.ScoringMethod,
+		Normalization:     decision.Normalization,
+		Ranking:           decision.Ranking,
+		Recommendation:    decision.Recommendation,
+		RecordedAt:        recordedAt,
+	}
+}
+
+// decisionHistory lists the iterations of decision, oldest first, each with
+// its changes from the one before
+func decisionHistory(decision *types.DecisionData) *api.DecisionHistoryResponse {
+	stored := append(append([]types.DecisionIteration{}, decision.History...), decisionIteration(decision))
+	response := &api.DecisionHistoryResponse{
+		DecisionID: decision.ID,
+		Status:     "success",
+		Iterations: make([]api.DecisionIteration, len(stored)),
+	}
+
+	var tops []string
+	changes := 0
+	for k, s := range stored {
+		iteration := api.DecisionIteration{
+			Iteration:         s.Iteration,
+			DecisionStatement: s.DecisionStatement,
+			AnalysisType:      s.AnalysisType,
+			Stage:             s.Stage,
+			Options:           make([]string, len(s.Options)),
+			Scores:            DecisionMatrix(s.Scores),
+			ScoringMethod:     s.ScoringMethod,
+			Normalization:     s.Normalization,
+			Ranking:           RankedOptions(s.Ranking),
+			Recommendation:    s.Recommendation,
+			RecordedAt:        s.RecordedAt,
+			Changes:           []string{},
+		}
+		for i, option := range s.Options {
+			iteration.Options[i] = option.Name
+		}
+		for _, criterion := range s.Criteria {
+			iteration.Criteria = append(iteration.Criteria, api.DecisionCriterion(criterion))
+		}
+		if len(s.Ranking) > 0 {
+			iteration.TopOption = s.Ranking[0].Option
+			if len(tops) == 0 || tops[len(tops)-1] != iteration.TopOption {
+				tops = append(tops, iteration.TopOption)
+			}
+		}
+		if k > 0 {
+			iteration.Changes = iterationChanges(&response.Iterations[k-1], &iteration)
+			changes += len(iteration.Changes)
+		}
+		response.Iterations[k] = iteration
+	}
+
+	if len(stored) == 1 {
+		response.Summary = fmt.Sprintf("Decision %s has not been revised", decision.ID)
+	} else {
+		response.Summary = fmt.Sprintf("Decision %s has %d iterations with %d changes", decision.ID, len(stored), changes)
+	}
+	switch len(tops) {
+	case 0:
+	case 1:
+		response.Summary += fmt.Sprintf("; its top option has stayed %s", tops[0])
+	default:
+		response.Summary += fmt.Sprintf("; its top option has gone from %s", strings.Join(tops, " to "))
+	}
+	return response
+}
+
+// iterationChanges describes what changed from iteration previous to next:
+// the statement, stage and scoring, the options and criteria added and
+// removed, the criteria reweighted, the scores given and changed, and the top
+// option
+func iterationChanges(previous, next *api.DecisionIteration) []string {
+	changes := []string{}
+	if next.DecisionStatement != previous.DecisionStatement {
+		changes = append(changes, fmt.Sprintf("restated the decision as %q", next.DecisionStatement))
+	}
+	if next.Stage != previous.Stage {
+		changes = append(changes, fmt.Sprintf("moved from stage %s to %s", previous.Stage, next.Stage))
+	}
+	if previous.ScoringMethod != "" && next.ScoringMethod != "" && next.ScoringMethod != previous.ScoringMethod {
+		changes = append(changes, fmt.Sprintf("switched scoring method from %s to %s", previous.ScoringMethod, next.ScoringMethod))
+	}
+	if previous.Normalization != "" && next.Normalization != "" && next.Normalization != previous.Normalization {
+		changes = append(changes, fmt.Sprintf("switched normalization from %s to %s", previous.Normalization, next.Normalization))
+	}
+
+	options := make(map[string]bool, len(previous.Options))
+	for _, option := range previous.Options {
+		options[option] = true
+	}
+	for _, option := range next.Options {
+		if !options[option] {
+			changes = append(changes, "added option "+option)
+		}
+		delete(options, option)
+	}
+	for _, option := range previous.Options {
+		if options[option] {
+			changes = append(changes, "removed option "+option)
+		}
+	}
+
+	weights := make(map[string]float64, len(previous.Criteria))
+	for _, criterion := range previous.Criteria {
+		weights[criterion.Name] = criterion.Weight
+	}
+	for _, criterion := range next.Criteria {
+		weight, ok := weights[criterion.Name]
+		switch {
+		case !ok:
+			changes = append(changes, "added criterion "+criterion.Name)
+		case weight != criterion.Weight:
+			changes = append(changes, fmt.Sprintf("reweighted criterion %s from %g to %g", criterion.Name, weight, criterion.Weight))
+		}
+		delete(weights, criterion.Name)
+	}
+	for _, criterion := range previous.Criteria {
+		if _, ok := weights[criterion.Name]; ok {
+			changes = append(changes, "removed criterion "+criterion.Name)
+		}
+	}
+
+	// Scores of options and criteria new to next come with them and are not
+	// listed apart
+	options, criteria := make(map[string]bool), make(map[string]bool)
+	for _, option := range previous.Options {
+		options[option] = true
+	}
+	for _, criterion := range previous.Criteria {
+		criteria[criterion.Name] = true
+	}
+	scores := make(map[[2]string]float64, len(previous.Scores))
+	for _, s := range previous.Scores {
+		scores[[2]string{s.Option, s.Criterion}] = s.Score
+	}
+	for _, s := range next.Scores {
+		score, ok := scores[[2]string{s.Option, s.Criterion}]
+		switch {
+		case ok && score != s.Score:
+			changes = append(changes, fmt.Sprintf("rescored %s on %s from %g to %g", s.Option, s.Criterion, score, s.Score))
+		case !ok && options[s.Option] && criteria[s.Criterion]:
+			changes = append(changes, fmt.Sprintf("scored %s on %s %g", s.Option, s.Criterion, s.Score))
+		}
+	}
+
+	switch {
+	case next.TopOption == previous.TopOption:
+	case previous.TopOption == "":
+		changes = append(changes, "ranked "+next.TopOption+" first")
+	case next.TopOption == "":
+		changes = append(changes, "no longer ranks the options")
+	default:
+		changes = append(changes, fmt.Sprintf("changed the top option from %s to %s", previous.TopOption, next.TopOption))
+	}
+	return changes
+}
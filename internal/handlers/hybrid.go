@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/bandit"
+	"github.com/rainmana/gothink/internal/montecarlo"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Algorithms a hybrid decision frames a decision's options for
+const (
+	hybridBandit = "bandit"
+	hybridMDP    = "mdp"
+)
+
+// hybridState is the state of the MDP a hybrid decision frames, in which an
+// option is taken
+const hybridState = "decide"
+
+// HybridProbabilisticDecision handles hybrid probabilistic decision requests
+func (h *DecisionHandler) HybridProbabilisticDecision(w http.ResponseWriter, r *http.Request) {
+	var request api.HybridDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunHybridProbabilisticDecision(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunHybridProbabilisticDecision frames the options of the decision request
+// names, in its session in the tenant of ctx, as the arms of a bandit or the
+// actions of an MDP and runs it, recording the run in the session as the
+// bandit and MDP tools do. The options, ranked by the run's estimates, are
+// stored on the decision with a recommendation drawn from them.
+func (h *DecisionHandler) RunHybridProbabilisticDecision(ctx context.Context, request api.HybridDecisionRequest) (*api.HybridDecisionResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid hybrid decision: session_id and decision_id are required")
+	}
+
+	// Set defaults
+	if request.Algorithm == "" {
+		request.Algorithm = hybridBandit
+	}
+	if request.Samples == 0 {
+		request.Samples = 1000
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	switch {
+	case request.Algorithm != hybridBandit && request.Algorithm != hybridMDP:
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid hybrid decision: unknown algorithm %q", request.Algorithm)
+	case request.Samples < 0 || request.Samples > 100000:
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid hybrid decision: samples must be from 1 to 100000")
+	}
+
+	store := tenantStore(ctx, h.storage)
+	decisions, err := store.GetDecisions(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get decisions: %v", err)
+	}
+	var decision *types.DecisionData
+	for _, d := range decisions {
+		if d.ID == request.DecisionID {
+			decision = d
+			break
+		}
+	}
+	if decision == nil {
+		return nil, apierror.Errorf(apierror.CodeRecordNotFound, "Decision %s not found in session %s", request.DecisionID, request.SessionID)
+	}
+	if len(decision.Options) == 0 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid hybrid decision: the decision has no options")
+	}
+
+	stochastic := NewStochasticHandler(h.storage, h.logger)
+	response := &api.HybridDecisionResponse{DecisionID: request.DecisionID, Status: "success"}
+	hybrid := &types.HybridDecision{Algorithm: request.Algorithm}
+	switch request.Algorithm {
+	case hybridBandit:
+		arms, err := optionArms(ctx, decision, request)
+		if err != nil {
+			return nil, err
+		}
+		result, err := stochastic.RunBandit(ctx, api.BanditRequest{
+			SessionID: request.SessionID,
+			Problem:   decision.DecisionStatement,
+			Arms:      arms,
+			Strategy:  request.Strategy,
+			Steps:     request.Steps,
+			Seed:      request.Seed,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.Bandit, hybrid.AlgorithmID = result, result.AlgorithmID
+		for _, arm := range result.ArmStats {
+			hybrid.Options = append(hybrid.Options, types.HybridOption{Option: arm.Name, Estimate: arm.AverageReward, Pulls: arm.Pulls})
+		}
+	case hybridMDP:
+		transitions, err := optionTransitions(decision)
+		if err != nil {
+			return nil, err
+		}
+		result, err := stochastic.RunMDP(ctx, api.MDPRequest{
+			SessionID:   request.SessionID,
+			Problem:     decision.DecisionStatement,
+			Transitions: transitions,
+			Gamma:       1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.MDP, hybrid.AlgorithmID = result, result.AlgorithmID
+		for _, option := range decision.Options {
+			hybrid.Options = append(hybrid.Options, types.HybridOption{Option: option.Name, Estimate: result.QValues[hybridState][option.Name]})
+		}
+	}
+
+	sort.SliceStable(hybrid.Options, func(a, b int) bool {
+		return hybrid.Options[a].Estimate > hybrid.Options[b].Estimate
+	})
+	for i := range hybrid.Options {
+		hybrid.Options[i].Rank = i + 1
+		if i > 0 && hybrid.Options[i].Estimate == hybrid.Options[i-1].Estimate {
+			hybrid.Options[i].Rank = hybrid.Options[i-1].Rank
+		}
+	}
+	hybrid.Recommendation = hybridRecommendation(decision, hybrid)
+
+	err = store.UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		decision.Hybrid = hybrid
+		if len(decision.Ranking) == 0 {
+			decision.Recommendation = hybrid.Recommendation
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to store hybrid decision: %v", err)
+	}
+
+	response.Decision = api.HybridDecision{
+		Algorithm:      hybrid.Algorithm,
+		AlgorithmID:    hybrid.AlgorithmID,
+		Options:        make([]api.HybridOption, len(hybrid.Options)),
+		Recommendation: hybrid.Recommendation,
+	}
+	for i, option := range hybrid.Options {
+		response.Decision.Options[i] = api.HybridOption(option)
+	}
+	return response, nil
+}
+
+// optionArms frames the options of decision as bandit arms: a gaussian arm
+// for a normal outcome, an empirical arm resampling draws from any other
+// outcome distribution, and otherwise a bernoulli arm paying 1 with the
+// option's probability of success, which may be 0 but must be given
+func optionArms(ctx context.Context, decision *types.DecisionData, request api.HybridDecisionRequest) ([]api.BanditArm, error) {
+	seeds := rand.New(rand.NewSource(request.Seed))
+	arms := make([]api.BanditArm, len(decision.Options))
+	for i, option := range decision.Options {
+		arm := api.BanditArm{Name: option.Name}
+		switch {
+		case option.Outcome != nil && option.Outcome.Distribution == montecarlo.Normal:
+			arm.Distribution, arm.Mean, arm.StdDev = bandit.Gaussian, option.Outcome.Mean, option.Outcome.StdDev
+		case option.Outcome != nil:
+			result, err := montecarlo.Simulate(ctx, []montecarlo.Variable{outcomeVariable(option.Outcome)}, func(draws map[string]float64) (float64, error) {
+				return draws["outcome"], nil
+			}, montecarlo.Options{
+				Trials:      request.Samples,
+				Buckets:     1,
+				Parallelism: runtime.GOMAXPROCS(0),
+				Rand:        rand.New(rand.NewSource(seeds.Int63())),
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, apierror.Errorf(apierror.CodeOf(err), "Hybrid decision cancelled")
+				}
+				return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid hybrid decision: option %s: %v", option.Name, err)
+			}
+			arm.Distribution, arm.ObservedRewards = bandit.Empirical, result.Outputs
+		case option.ProbabilityOfSuccess != nil:
+			arm.Distribution, arm.Mean = bandit.Bernoulli, *option.ProbabilityOfSuccess
+		default:
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid hybrid decision: option %s has neither an outcome distribution nor a probability of success", option.Name)
+		}
+		arms[i] = arm
+	}
+	return arms, nil
+}
+
+// optionTransitions frames the options of decision as the actions of a
+// one-step MDP: each succeeds with its probability of success, 1 when not
+// given, paying its score in the decision's ranking or, unranked, its
+// expected value, and otherwise fails paying nothing. A probability or value
+// of 0 counts as given, as it does for the bandit's arms.
+func optionTransitions(decision *types.DecisionData) ([]api.MDPTransition, error) {
+	scores := make(map[string]float64, len(decision.Ranking))
+	for _, ranked := range decision.Ranking {
+		scores[ranked.Option] = ranked.Score
+	}
+
+	var transitions []api.MDPTransition
+	for _, option := range decision.Options {
+		payoff, ranked := scores[option.Name]
+		if !ranked {
+			if len(decision.Ranking) > 0 || option.ExpectedValue == nil {
+				return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid hybrid decision: option %s has neither a score in the decision's ranking nor an expected value", option.Name)
+			}
+			payoff = *option.ExpectedValue
+		}
+		p := valueOr(option.ProbabilityOfSuccess, 1)
+		if p > 0 {
+			transitions = append(transitions, api.MDPTransition{State: hybridState, Action: option.Name, NextState: option.Name + " succeeds", Probability: p, Reward: payoff})
+		}
+		if p < 1 {
+			transitions = append(transitions, api.MDPTransition{State: hybridState, Action: option.Name, NextState: option.Name + " fails", Probability: 1 - p})
+		}
+	}
+	return transitions, nil
+}
+
+// hybridRecommendation names the option of highest estimate, against the
+// runner-up, and whether the decision's ranking agrees
+func hybridRecommendation(decision *types.DecisionData, hybrid *types.HybridDecision) string {
+	best := hybrid.Options[0]
+	var recommendation string
+	if hybrid.Algorithm == hybridBandit {
+		recommendation = fmt.Sprintf("%s pays out most, averaging %.4g over %d pulls", best.Option, best.Estimate, best.Pulls)
+	} else {
+		recommendation = fmt.Sprintf("%s has the highest expected payoff, %.4g", best.Option, best.Estimate)
+	}
+	if len(hybrid.Options) > 1 {
+		runnerUp := hybrid.Options[1]
+		recommendation += fmt.Sprintf(", against %.4g for %s", runnerUp.Estimate, runnerUp.Option)
+	}
+	if len(decision.Ranking) > 0 {
+		if top := decision.Ranking[0].Option; top == best.Option {
+			recommendation += "; the decision's ranking agrees"
+		} else {
+			recommendation += fmt.Sprintf("; the decision's ranking puts %s first", top)
+		}
+	}
+	return recommendation
+}
@@ -3,13 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/apierror"
 	"github.com/rainmana/gothink/internal/intelligence"
 	"github.com/rainmana/gothink/internal/models"
+	"github.com/rainmana/gothink/internal/progress"
 )
 
 // IntelligenceHandler handles intelligence-related MCP requests
@@ -36,8 +37,8 @@ func (h *IntelligenceHandler) AddIntelligenceTools(s *server.MCPServer) {
 		mcp.NewTool("query_nvd",
 			mcp.WithDescription("Query NVD CVE data for security vulnerabilities"),
 			mcp.WithString("query", mcp.Required(), mcp.Description("Search query for CVEs")),
-			mcp.WithNumber("limit", mcp.Description("Maximum number of results to return")),
-			mcp.WithNumber("offset", mcp.Description("Number of results to skip")),
+			mcp.WithNumber("limit", mcp.Min(0), mcp.Description("Maximum number of results to return")),
+			mcp.WithNumber("offset", mcp.Min(0), mcp.Description("Number of results to skip")),
 		),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			query, _ := req.RequireString("query")
@@ -56,7 +57,7 @@ func (h *IntelligenceHandler) AddIntelligenceTools(s *server.MCPServer) {
 			// Query NVD data
 			response, err := h.intelligenceService.QueryNVDData(ctx, intelQuery)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to query NVD data: %v", err)), nil
+				return apierror.ToolFailure(err, "Failed to query NVD data: %v", err), nil
 			}
 
 			// Create response
@@ -81,8 +82,8 @@ func (h *IntelligenceHandler) AddIntelligenceTools(s *server.MCPServer) {
 		mcp.NewTool("query_attack",
 			mcp.WithDescription("Query MITRE ATT&CK techniques and tactics"),
 			mcp.WithString("query", mcp.Required(), mcp.Description("Search query for ATT&CK techniques")),
-			mcp.WithNumber("limit", mcp.Description("Maximum number of results to return")),
-			mcp.WithNumber("offset", mcp.Description("Number of results to skip")),
+			mcp.WithNumber("limit", mcp.Min(0), mcp.Description("Maximum number of results to return")),
+			mcp.WithNumber("offset", mcp.Min(0), mcp.Description("Number of results to skip")),
 		),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			query, _ := req.RequireString("query")
@@ -101,7 +102,7 @@ func (h *IntelligenceHandler) AddIntelligenceTools(s *server.MCPServer) {
 			// Query MITRE data
 			response, err := h.intelligenceService.QueryMITREData(ctx, intelQuery)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to query MITRE data: %v", err)), nil
+				return apierror.ToolFailure(err, "Failed to query MITRE data: %v", err), nil
 			}
 
 			// Create response
@@ -126,8 +127,8 @@ func (h *IntelligenceHandler) AddIntelligenceTools(s *server.MCPServer) {
 		mcp.NewTool("query_owasp",
 			mcp.WithDescription("Query OWASP testing procedures and guidelines"),
 			mcp.WithString("query", mcp.Required(), mcp.Description("Search query for OWASP procedures")),
-			mcp.WithNumber("limit", mcp.Description("Maximum number of results to return")),
-			mcp.WithNumber("offset", mcp.Description("Number of results to skip")),
+			mcp.WithNumber("limit", mcp.Min(0), mcp.Description("Maximum number of results to return")),
+			mcp.WithNumber("offset", mcp.Min(0), mcp.Description("Number of results to skip")),
 		),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			query, _ := req.RequireString("query")
@@ -146,7 +147,7 @@ func (h *IntelligenceHandler) AddIntelligenceTools(s *server.MCPServer) {
 			// Query OWASP data
 			response, err := h.intelligenceService.QueryOWASPData(ctx, intelQuery)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to query OWASP data: %v", err)), nil
+				return apierror.ToolFailure(err, "Failed to query OWASP data: %v", err), nil
 			}
 
 			// Create response
@@ -172,9 +173,14 @@ func (h *IntelligenceHandler) AddIntelligenceTools(s *server.MCPServer) {
 			mcp.WithDescription("Refresh all intelligence data from external sources"),
 		),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			// Refresh intelligence data
-			if err := h.intelligenceService.RefreshIntelligenceData(ctx); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to refresh intelligence data: %v", err)), nil
+			// Refresh intelligence data, reporting each source as it is stored
+			reporter := progress.FromRequest(ctx, req, 0)
+			err := h.intelligenceService.RefreshIntelligenceDataWithProgress(ctx, func(done, total int, message string) {
+				reporter.SetTotal(float64(total))
+				reporter.Report(float64(done), message)
+			})
+			if err != nil {
+				return apierror.ToolError(apierror.CodeOr(err, apierror.CodeUpstreamError), "Failed to refresh intelligence data: %v", err), nil
 			}
 
 			// Get updated stats
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/kepnertregoe"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// KepnerTregoe handles Kepner-Tregoe analysis requests
+func (h *DecisionHandler) KepnerTregoe(w http.ResponseWriter, r *http.Request) {
+	var request api.KepnerTregoeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunKepnerTregoe(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunKepnerTregoe analyzes the options of the decision request names, in its
+// session in the tenant of ctx, against the MUST and WANT objectives of
+// request, weighing their adverse consequences, and stores the analysis on
+// the decision
+func (h *DecisionHandler) RunKepnerTregoe(ctx context.Context, request api.KepnerTregoeRequest) (*api.KepnerTregoeResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid Kepner-Tregoe analysis: session_id and decision_id are required")
+	}
+
+	var analyzed types.DecisionData
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		if err := analyzeKepnerTregoe(decision, request); err != nil {
+			return err
+		}
+		analyzed = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to run Kepner-Tregoe analysis: %v", err)
+	}
+
+	return &api.KepnerTregoeResponse{
+		DecisionID: request.DecisionID,
+		Status:     "success",
+		Analysis:   *KepnerTregoeAnalysis(analyzed.KepnerTregoe),
+	}, nil
+}
+
+// analyzeKepnerTregoe assesses every option of decision against the
+// objectives of request and stores the analysis on it. The qualified options
+// are ranked by weighted score, and the recommendation names the best
+// balanced choice unless the decision is ranked by its scores.
+func analyzeKepnerTregoe(decision *types.DecisionData, request api.KepnerTregoeRequest) error {
+	invalid := func(format string, args ...interface{}) error {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid Kepner-Tregoe analysis: "+format, args...)
+	}
+	if len(decision.Options) == 0 {
+		return invalid("the decision has no options")
+	}
+
+	analysis := &types.KepnerTregoeAnalysis{
+		Musts: make([]types.MustObjective, len(request.Musts)),
+		Wants: make([]types.WantObjective, len(request.Wants)),
+	}
+	musts := make(map[string]bool, len(request.Musts))
+	for k, must := range request.Musts {
+		if musts[must.Name] {
+			return invalid("MUST %s is listed twice", must.Name)
+		}
+		musts[must.Name] = true
+		analysis.Musts[k] = types.MustObjective(must)
+	}
+	wants := make(map[string]int, len(request.Wants))
+	weights := make([]int, len(request.Wants))
+	for k, want := range request.Wants {
+		if _, listed := wants[want.Name]; listed {
+			return invalid("WANT %s is listed twice", want.Name)
+		}
+		wants[want.Name], weights[k] = k, want.Weight
+		analysis.Wants[k] = types.WantObjective(want)
+	}
+
+	assessments := make(map[string]api.OptionAssessment, len(request.Assessments))
+	for _, a := range request.Assessments {
+		if _, listed := assessments[a.Option]; listed {
+			return invalid("option %s is assessed twice", a.Option)
+		}
+		assessments[a.Option] = a
+	}
+	options := make(map[string]bool, len(decision.Options))
+	for _, option := range decision.Options {
+		options[option.Name] = true
+	}
+	for _, a := range request.Assessments {
+		if !options[a.Option] {
+			return invalid("there is no option %s", a.Option)
+		}
+	}
+
+	alternatives := make([]kepnertregoe.Alternative, len(decision.Options))
+	analysis.Options = make([]types.KepnerTregoeOption, len(decision.Options))
+	for i, option := range decision.Options {
+		a, ok := assessments[option.Name]
+		if !ok {
+			return invalid("option %s is not assessed", option.Name)
+		}
+		assessed := types.KepnerTregoeOption{Option: option.Name}
+		alternative := kepnertregoe.Alternative{Name: option.Name}
+
+		met := make(map[string]bool, len(a.Musts))
+		for _, result := range a.Musts {
+			if !musts[result.Must] {
+				return invalid("option %s is assessed against unknown MUST %s", option.Name, result.Must)
+			}
+			if _, seen := met[result.Must]; seen {
+				return invalid("option %s is assessed twice against MUST %s", option.Name, result.Must)
+			}
+			met[result.Must] = result.Met
+			assessed.Musts = append(assessed.Musts, types.MustResult(result))
+		}
+		for _, must := range request.Musts {
+			ok, seen := met[must.Name]
+			if !seen {
+				return invalid("option %s is not assessed against MUST %s", option.Name, must.Name)
+			}
+			if !ok {
+				alternative.Failed = append(alternative.Failed, must.Name)
+			}
+		}
+
+		scores := make([]*float64, len(request.Wants))
+		for _, s := range a.Wants {
+			k, ok := wants[s.Want]
+			switch {
+			case !ok:
+				return invalid("option %s is scored against unknown WANT %s", option.Name, s.Want)
+			case scores[k] != nil:
+				return invalid("option %s is scored twice against WANT %s", option.Name, s.Want)
+			}
+			score := s.Score
+			scores[k] = &score
+			assessed.Wants = append(assessed.Wants, types.WantScore(s))
+		}
+		if len(alternative.Failed) == 0 {
+			alternative.Scores = make([]float64, len(scores))
+			for k, score := range scores {
+				if score == nil {
+					return invalid("option %s has no score against WANT %s", option.Name, request.Wants[k].Name)
+				}
+				alternative.Scores[k] = *score
+			}
+		}
+
+		for _, c := range a.AdverseConsequences {
+			alternative.Consequences = append(alternative.Consequences, kepnertregoe.Consequence{Description: c.Description, Probability: c.Probability, Seriousness: c.Seriousness})
+			assessed.AdverseConsequences = append(assessed.AdverseConsequences, types.AdverseConsequence{
+				Description: c.Description,
+				Probability: c.Probability,
+				Seriousness: c.Seriousness,
+				Threat:      c.Probability * c.Seriousness,
+			})
+		}
+		alternatives[i], analysis.Options[i] = alternative, assessed
+	}
+
+	results, err := kepnertregoe.Analyze(weights, alternatives)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	for i, result := range results {
+		option := &analysis.Options[i]
+		option.Qualified = result.Qualified
+		option.FailedMusts = alternatives[i].Failed
+		option.WeightedScore, option.Share = result.WeightedScore, result.Share
+		option.Threat, option.SeriousRisks = result.Threat, result.SeriousRisks
+	}
+	if balanced := kepnertregoe.BalancedChoice(results); balanced >= 0 {
+		analysis.BalancedChoice = results[balanced].Name
+	}
+
+	ranked := analysis.Options
+	sort.SliceStable(ranked, func(a, b int) bool {
+		if ranked[a].Qualified != ranked[b].Qualified {
+			return ranked[a].Qualified
+		}
+		return ranked[a].WeightedScore > ranked[b].WeightedScore
+	})
+	for i := range ranked {
+		if !ranked[i].Qualified {
+			break
+		}
+		ranked[i].Rank = i + 1
+		if i > 0 && math.Abs(ranked[i].WeightedScore-ranked[i-1].WeightedScore) < 1e-9 {
+			ranked[i].Rank = ranked[i-1].Rank
+		}
+	}
+
+	analysis.Recommendation = kepnerTregoeRecommendation(analysis, weights)
+	decision.KepnerTregoe = analysis
+	if len(decision.Ranking) == 0 {
+		decision.Recommendation = analysis.Recommendation
+	}
+	return nil
+}
+
+// kepnerTregoeRecommendation names the option of highest weighted score and,
+// when it differs, the best balanced choice, then the options eliminated
+func kepnerTregoeRecommendation(analysis *types.KepnerTregoeAnalysis, weights []int) string {
+	var eliminated []string
+	var balanced *types.KepnerTregoeOption
+	for i, option := range analysis.Options {
+		if !option.Qualified {
+			eliminated = append(eliminated, fmt.Sprintf("%s fails %s", option.Option, strings.Join(option.FailedMusts, " and ")))
+		}
+		if option.Option == analysis.BalancedChoice {
+			balanced = &analysis.Options[i]
+		}
+	}
+	if balanced == nil {
+		return "No option meets every MUST: " + strings.Join(eliminated, "; ")
+	}
+
+	possible := 0
+	for _, weight := range weights {
+		possible += kepnertregoe.MaxRating * weight
+	}
+	top := analysis.Options[0]
+	recommendation := fmt.Sprintf("%s meets every MUST and scores highest on the WANTs, %.4g of %d (%.0f%%)", top.Option, top.WeightedScore, possible, 100*top.Share)
+	risks := strings.Join(top.SeriousRisks, " and ")
+	switch {
+	case balanced.Option == top.Option && len(top.SeriousRisks) == 0:
+		recommendation += ", with no serious adverse consequence"
+	case balanced.Option == top.Option:
+		recommendation += fmt.Sprintf("; it risks %s, but every qualifying option runs a serious risk and it threatens least", risks)
+	case len(balanced.SeriousRisks) == 0:
+		recommendation += fmt.Sprintf("; but it risks %s, so %s, scoring %.4g (%.0f%%) with no serious risk, is the best balanced choice", risks, balanced.Option, balanced.WeightedScore, 100*balanced.Share)
+	default:
+		recommendation += fmt.Sprintf("; every qualifying option runs a serious risk, so %s, of least threat, %d, is the best balanced choice", balanced.Option, balanced.Threat)
+	}
+	if len(eliminated) > 0 {
+		recommendation += "; eliminated: " + strings.Join(eliminated, ", ")
+	}
+	return recommendation
+}
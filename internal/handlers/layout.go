@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"github.com/rainmana/gothink/internal/flowchart"
+	"github.com/rainmana/gothink/internal/layout"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// layeredDiagrams are the diagram types laid out in layers; any other
+// diagram of nodes and edges is laid out by force
+var layeredDiagrams = map[string]bool{
+	flowchart.DiagramType: true,
+	"decision-tree":       true,
+	"probability-tree":    true,
+}
+
+// layoutElements positions the nodes of elements, a diagram of diagramType,
+// storing each position in the x and y properties of its node. Layered
+// diagrams are placed by their structure alone; in others, nodes already
+// positioned keep their place. Elements with a source or target are edges,
+// and those containing others groups; neither is positioned. The elements
+// are returned with the IDs of the nodes whose positions changed, elements
+// itself left untouched.
+func layoutElements(diagramType string, elements []types.VisualElement) ([]types.VisualElement, map[string]bool) {
+	g := layout.Graph{Fixed: make(map[string]layout.Point)}
+	for _, element := range elements {
+		switch {
+		case element.Source != "" || element.Target != "":
+			g.Edges = append(g.Edges, [2]string{element.Source, element.Target})
+		case len(element.Contains) == 0:
+			g.Nodes = append(g.Nodes, element.ID)
+			if x, ok := numberProperty(element, "x"); ok {
+				if y, ok := numberProperty(element, "y"); ok {
+					g.Fixed[element.ID] = layout.Point{X: x, Y: y}
+				}
+			}
+		}
+	}
+
+	var positions map[string]layout.Point
+	if layeredDiagrams[diagramType] {
+		positions = layout.Layered(g, layout.Options{})
+	} else {
+		positions = layout.ForceDirected(g, layout.Options{})
+	}
+
+	laid := make([]types.VisualElement, len(elements))
+	moved := make(map[string]bool)
+	for i, element := range elements {
+		laid[i] = element
+		p, ok := positions[element.ID]
+		if !ok || element.Source != "" || element.Target != "" || len(element.Contains) > 0 {
+			continue
+		}
+		if fixed, ok := g.Fixed[element.ID]; ok && fixed == p {
+			continue
+		}
+		properties := make(map[string]interface{}, len(element.Properties)+2)
+		for key, value := range element.Properties {
+			properties[key] = value
+		}
+		properties["x"], properties["y"] = p.X, p.Y
+		laid[i].Properties = properties
+		moved[element.ID] = true
+	}
+	return laid, moved
+}
+
+// layoutOperation lays out elements, the diagram the operation of visual
+// leaves, and carries the positions on visual so that they are stored with
+// it: a create takes the laid out elements, and an update also replaces the
+// nodes the layout moved. A delete moves nothing.
+func layoutOperation(visual *types.VisualData, elements []types.VisualElement) []types.VisualElement {
+	switch visual.Operation {
+	case "delete":
+		return elements
+	case "update":
+		laid, moved := layoutElements(visual.DiagramType, elements)
+		updated := make(map[string]bool, len(visual.Elements))
+		for _, element := range visual.Elements {
+			updated[element.ID] = true
+		}
+		carried := make([]types.VisualElement, 0, len(visual.Elements)+len(moved))
+		for _, element := range laid {
+			if updated[element.ID] || moved[element.ID] {
+				carried = append(carried, element)
+			}
+		}
+		visual.Elements = carried
+		return laid
+	default:
+		visual.Elements, _ = layoutElements(visual.DiagramType, elements)
+		return visual.Elements
+	}
+}
+
+// numberProperty returns the numeric property key of element
+func numberProperty(element types.VisualElement, key string) (float64, bool) {
+	switch v := element.Properties[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
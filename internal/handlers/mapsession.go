@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/conceptmap"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// artifactLabelLength is the most characters of its text an artifact's label
+// shows
+const artifactLabelLength = 48
+
+// MapSession handles session mapping requests
+func (h *VisualHandler) MapSession(w http.ResponseWriter, r *http.Request) {
+	var request api.MapSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunMapSession(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunMapSession drafts a concept map of the thoughts, mental models and
+// decisions of the session request names, in the tenant of ctx, and records
+// it as a concept map diagram. Each artifact is a node, joined to the
+// concepts it mentions, the keywords recurring across the artifacts; related
+// concepts, mentioned by the same artifacts, are joined to each other, and
+// thoughts to the thoughts they follow, revise or branch from.
+func (h *VisualHandler) RunMapSession(ctx context.Context, request api.MapSessionRequest) (*api.MapSessionResponse, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid session map: "+format, args...)
+	}
+	if request.SessionID == "" {
+		return nil, invalid("session_id is required")
+	}
+	if request.MaxConcepts < 0 || request.MaxConcepts > 100 {
+		return nil, invalid("max_concepts must be from 1 to 100")
+	}
+	if request.MinDocuments < 0 || request.MinCooccurrence < 0 {
+		return nil, invalid("min_documents and min_cooccurrence must not be negative")
+	}
+	if request.DiagramID == "" {
+		request.DiagramID = "session-map"
+	}
+
+	store := tenantStore(ctx, h.storage)
+	thoughts, err := store.GetThoughts(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get thoughts: %v", err)
+	}
+	models, err := store.GetMentalModels(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get mental models: %v", err)
+	}
+	decisions, err := store.GetDecisions(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get decisions: %v", err)
+	}
+	if len(thoughts)+len(models)+len(decisions) == 0 {
+		return nil, invalid("session %s has no thoughts, mental models or decisions to map", request.SessionID)
+	}
+
+	var elements []types.VisualElement
+	var documents []conceptmap.Document
+	addArtifact := func(id, kind, label, recordID, text string) {
+		elements = append(elements, types.VisualElement{
+			ID:         id,
+			Type:       kind,
+			Label:      label,
+			Properties: map[string]interface{}{"record_id": recordID},
+		})
+		documents = append(documents, conceptmap.Document{ID: id, Text: text})
+	}
+	link := func(kind, source, target string) {
+		elements = append(elements, types.VisualElement{ID: source + "->" + target, Type: kind, Label: strings.ReplaceAll(kind, "_", " "), Source: source, Target: target, Properties: map[string]interface{}{}})
+	}
+
+	// Thoughts follow the one numbered before them on their branch
+	sort.SliceStable(thoughts, func(a, b int) bool { return thoughts[a].ThoughtNumber < thoughts[b].ThoughtNumber })
+	numbered := make(map[int]string)
+	for _, thought := range thoughts {
+		id := "thought:" + thought.ID
+		label := fmt.Sprintf("Thought %d: %s", thought.ThoughtNumber, abbreviate(thought.Thought, artifactLabelLength))
+		addArtifact(id, "thought", label, thought.ID, thought.Thought)
+		if _, ok := numbered[thought.ThoughtNumber]; !ok && thought.BranchID == "" {
+			numbered[thought.ThoughtNumber] = id
+		}
+	}
+	previous := make(map[string]string)
+	for _, thought := range thoughts {
+		id := "thought:" + thought.ID
+		switch {
+		case thought.IsRevision && thought.RevisesThought != nil && numbered[*thought.RevisesThought] != "":
+			link("revises", id, numbered[*thought.RevisesThought])
+		case previous[thought.BranchID] != "":
+			link("follows", id, previous[thought.BranchID])
+		case thought.BranchFromThought != nil && numbered[*thought.BranchFromThought] != "":
+			link("branches_from", id, numbered[*thought.BranchFromThought])
+		}
+		if !thought.IsRevision {
+			previous[thought.BranchID] = id
+		}
+	}
+
+	for _, model := range models {
+		text := strings.Join(append([]string{model.Problem, model.Reasoning, model.Conclusion}, model.Steps...), "\n")
+		addArtifact("mental_model:"+model.ID, "mental_model", model.ModelName, model.ID, text)
+	}
+	for _, decision := range decisions {
+		parts := []string{decision.DecisionStatement, decision.Recommendation}
+		for _, option := range decision.Options {
+			parts = append(parts, option.Name, option.Description)
+		}
+		for _, criterion := range decision.Criteria {
+			parts = append(parts, criterion.Name)
+		}
+		addArtifact("decision:"+decision.ID, "decision", abbreviate(decision.DecisionStatement, artifactLabelLength), decision.ID, strings.Join(parts, "\n"))
+	}
+
+	drafted := conceptmap.Draft(documents, conceptmap.Options{
+		MaxConcepts:     request.MaxConcepts,
+		MinDocuments:    request.MinDocuments,
+		MinCooccurrence: request.MinCooccurrence,
+	})
+	response := &api.MapSessionResponse{
+		Status:    "success",
+		DiagramID: request.DiagramID,
+		Artifacts: len(documents),
+		Concepts:  make([]api.MappedConcept, len(drafted.Concepts)),
+		Relations: make([]api.ConceptRelation, len(drafted.Relations)),
+	}
+	for i, concept := range drafted.Concepts {
+		id := "concept:" + concept.Term
+		elements = append(elements, types.VisualElement{
+			ID:         id,
+			Type:       "concept",
+			Label:      concept.Term,
+			Properties: map[string]interface{}{"mentions": concept.Mentions, "artifacts": len(concept.Documents)},
+		})
+		for _, artifact := range concept.Documents {
+			link("mentions", artifact, id)
+		}
+		response.Concepts[i] = api.MappedConcept{Term: concept.Term, Mentions: concept.Mentions, Artifacts: concept.Documents}
+	}
+	for i, relation := range drafted.Relations {
+		elements = append(elements, types.VisualElement{
+			ID:         "concept:" + relation.From + "--concept:" + relation.To,
+			Type:       "related",
+			Source:     "concept:" + relation.From,
+			Target:     "concept:" + relation.To,
+			Properties: map[string]interface{}{"shared_artifacts": len(relation.Documents)},
+		})
+		response.Relations[i] = api.ConceptRelation{From: relation.From, To: relation.To, Artifacts: relation.Documents}
+	}
+
+	switch {
+	case len(drafted.Concepts) == 0:
+		response.Summary = fmt.Sprintf("Mapped %d artifacts; no keyword recurs across them", len(documents))
+	default:
+		var terms []string
+		for i := 0; i < len(drafted.Concepts) && i < 3; i++ {
+			terms = append(terms, drafted.Concepts[i].Term)
+		}
+		response.Summary = fmt.Sprintf("Mapped %d artifacts to %d concepts and %d relations between them; the most widespread are %s",
+			len(documents), len(drafted.Concepts), len(drafted.Relations), strings.Join(terms, ", "))
+	}
+
+	visual := &types.VisualData{
+		Operation:   "create",
+		Elements:    elements,
+		DiagramID:   request.DiagramID,
+		DiagramType: "conceptMap",
+		Observation: fmt.Sprintf("Concept map of session %s", request.SessionID),
+		Insight:     response.Summary,
+		CreatedAt:   time.Now(),
+	}
+	elements = layoutOperation(visual, elements)
+	if err := store.AddVisualData(request.SessionID, visual); err != nil {
+		h.logger.WithError(err).Error("Failed to add visual data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add visual data")
+	}
+
+	response.VisualID = visual.ID
+	response.Elements = make([]api.VisualElement, len(elements))
+	for i, element := range elements {
+		response.Elements[i] = api.VisualElement(element)
+	}
+	return response, nil
+}
+
+// abbreviate shortens text to its first line and at most n characters,
+// marking what it cut with an ellipsis
+func abbreviate(text string, n int) string {
+	text = strings.TrimSpace(text)
+	cut := false
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text, cut = strings.TrimSpace(text[:i]), true
+	}
+	if runes := []rune(text); len(runes) > n {
+		text, cut = strings.TrimSpace(string(runes[:n])), true
+	}
+	if cut {
+		text += "…"
+	}
+	return text
+}
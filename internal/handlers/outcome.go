@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/calibration"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// RecordDecisionOutcome handles decision outcome requests
+func (h *DecisionHandler) RecordDecisionOutcome(w http.ResponseWriter, r *http.Request) {
+	var request api.RecordDecisionOutcomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunRecordDecisionOutcome(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunRecordDecisionOutcome stores what came of the decision request names, in
+// its session in the tenant of ctx, on the decision along with the forecasts
+// of the option taken, replacing any outcome recorded before
+func (h *DecisionHandler) RunRecordDecisionOutcome(ctx context.Context, request api.RecordDecisionOutcomeRequest) (*api.RecordDecisionOutcomeResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" || request.ChosenOption == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid outcome: session_id, decision_id and chosen_option are required")
+	}
+	if request.Succeeded == nil && request.RealizedValue == nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid outcome: succeeded, realized_value or both are required")
+	}
+
+	var outcome types.DecisionOutcome
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		var chosen *types.DecisionOption
+		for i := range decision.Options {
+			if decision.Options[i].Name == request.ChosenOption {
+				chosen = &decision.Options[i]
+				break
+			}
+		}
+		if chosen == nil {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid outcome: decision %s has no option %s", decision.ID, request.ChosenOption)
+		}
+
+		outcome = types.DecisionOutcome{
+			ChosenOption:  chosen.Name,
+			Succeeded:     request.Succeeded,
+			RealizedValue: request.RealizedValue,
+			Notes:         request.Notes,
+			RecordedAt:    time.Now(),
+		}
+		if chosen.ProbabilityOfSuccess != nil {
+			probability := *chosen.ProbabilityOfSuccess
+			outcome.ForecastProbability = &probability
+		}
+		if value, ok := forecastValue(decision, chosen); ok {
+			outcome.ForecastValue = &value
+		}
+		recorded := outcome
+		decision.Outcome = &recorded
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to record outcome: %v", err)
+	}
+
+	return &api.RecordDecisionOutcomeResponse{
+		DecisionID: request.DecisionID,
+		Status:     "success",
+		Outcome:    api.DecisionOutcome(outcome),
+		Summary:    outcomeSummary(&outcome),
+	}, nil
+}
+
+// forecastValue returns the value forecast for option of decision: its
+// expected value when given, even if 0, or failing that the mean of its
+// simulated outcomes
+func forecastValue(decision *types.DecisionData, option *types.DecisionOption) (float64, bool) {
+	if option.ExpectedValue != nil {
+		return *option.ExpectedValue, true
+	}
+	if decision.Simulation != nil {
+		for _, simulated := range decision.Simulation.Options {
+			if simulated.Option == option.Name {
+				return simulated.ExpectedValue, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// outcomeSummary says how outcome compares with its forecasts
+func outcomeSummary(outcome *types.DecisionOutcome) string {
+	var parts []string
+	if outcome.Succeeded != nil {
+		result := "failed"
+		if *outcome.Succeeded {
+			result = "succeeded"
+		}
+		part := fmt.Sprintf("%s %s", outcome.ChosenOption, result)
+		if outcome.ForecastProbability != nil {
+			part += fmt.Sprintf(", forecast to succeed with probability %.2g", *outcome.ForecastProbability)
+		}
+		parts = append(parts, part)
+	}
+	if outcome.RealizedValue != nil {
+		part := fmt.Sprintf("%s realized %.4g", outcome.ChosenOption, *outcome.RealizedValue)
+		if outcome.ForecastValue != nil {
+			part += fmt.Sprintf(" against a forecast of %.4g", *outcome.ForecastValue)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// CalibrationReport handles calibration report requests
+func (h *DecisionHandler) CalibrationReport(w http.ResponseWriter, r *http.Request) {
+	var request api.CalibrationReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunCalibrationReport(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunCalibrationReport compares the forecasts of the decisions with recorded
+// outcomes, in the session request names or, without one, in every session
+// of the tenant of ctx, with what came of them
+func (h *DecisionHandler) RunCalibrationReport(ctx context.Context, request api.CalibrationReportRequest) (*api.CalibrationReportResponse, error) {
+	bins := calibration.DefaultBins
+	if request.Bins != 0 {
+		bins = request.Bins
+	}
+	if bins < 1 || bins > 20 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid calibration report: bins must be between 1 and 20, not %d", request.Bins)
+	}
+
+	store := tenantStore(ctx, h.storage)
+	sessions := []string{request.SessionID}
+	if request.SessionID == "" {
+		listed, err := store.ListSessions()
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to list sessions: %v", err)
+		}
+		sessions = listed
+		sort.Strings(sessions)
+	}
+
+	response := &api.CalibrationReportResponse{
+		Status:    "success",
+		SessionID: request.SessionID,
+		Outcomes:  []api.CalibratedOutcome{},
+	}
+	var probabilities, values, realized []float64
+	var succeeded []bool
+	for _, sessionID := range sessions {
+		decisions, err := store.GetDecisions(sessionID, nil)
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get decisions: %v", err)
+		}
+		for _, decision := range decisions {
+			outcome := decision.Outcome
+			if outcome == nil {
+				continue
+			}
+			response.Outcomes = append(response.Outcomes, api.CalibratedOutcome{
+				SessionID:         sessionID,
+				DecisionID:        decision.ID,
+				DecisionStatement: decision.DecisionStatement,
+				Outcome:           api.DecisionOutcome(*outcome),
+			})
+			if outcome.ForecastProbability != nil && outcome.Succeeded != nil {
+				probabilities = append(probabilities, *outcome.ForecastProbability)
+				succeeded = append(succeeded, *outcome.Succeeded)
+			}
+			if outcome.ForecastValue != nil && outcome.RealizedValue != nil {
+				values = append(values, *outcome.ForecastValue)
+				realized = append(realized, *outcome.RealizedValue)
+			}
+		}
+	}
+
+	if len(probabilities) > 0 {
+		result, err := calibration.Probabilities(probabilities, succeeded, bins)
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid calibration report: %v", err)
+		}
+		response.Probability = &api.ProbabilityCalibration{
+			Count:        result.Count,
+			MeanForecast: result.MeanForecast,
+			ObservedRate: result.ObservedRate,
+			BrierScore:   result.BrierScore,
+			Leaning:      result.Leaning,
+			Bins:         make([]api.CalibrationBin, len(result.Bins)),
+		}
+		for i, bin := range result.Bins {
+			response.Probability.Bins[i] = api.CalibrationBin(bin)
+		}
+	}
+	if len(values) > 0 {
+		result, err := calibration.Values(values, realized)
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid calibration report: %v", err)
+		}
+		response.Value = &api.ValueCalibration{
+			Count:             result.Count,
+			MeanForecast:      result.MeanForecast,
+			MeanRealized:      result.MeanRealized,
+			MeanError:         result.MeanError,
+			MeanAbsoluteError: result.MeanAbsoluteError,
+			Ratio:             result.Ratio,
+		}
+	}
+	response.Summary = calibrationSummary(response)
+	return response, nil
+}
+
+// calibrationSummary says how many decisions have outcomes and how their
+// forecast probabilities and values held up
+func calibrationSummary(response *api.CalibrationReportResponse) string {
+	if len(response.Outcomes) == 0 {
+		return "No decision has a recorded outcome"
+	}
+	summary := fmt.Sprintf("%d decisions have recorded outcomes", len(response.Outcomes))
+	if p := response.Probability; p != nil {
+		leaning := p.Leaning
+		if leaning == calibration.Calibrated {
+			leaning = "well calibrated"
+		}
+		summary += fmt.Sprintf("; %d forecast probabilities averaged %.0f%% against %.0f%% of successes, a Brier score of %.3f: %s",
+			p.Count, 100*p.MeanForecast, 100*p.ObservedRate, p.BrierScore, leaning)
+	}
+	if v := response.Value; v != nil {
+		summary += fmt.Sprintf("; %d forecast values averaged %.4g against %.4g realized", v.Count, v.MeanForecast, v.MeanRealized)
+		if v.Ratio != nil {
+			summary += fmt.Sprintf(", %.0f%% of the forecast", 100*(*v.Ratio))
+		}
+	}
+	if response.Probability == nil && response.Value == nil {
+		summary += "; none has a forecast to check"
+	}
+	return summary
+}
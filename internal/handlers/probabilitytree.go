@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/decisiontree"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// ProbabilityTree handles probability tree requests
+func (h *VisualHandler) ProbabilityTree(w http.ResponseWriter, r *http.Request) {
+	var request api.ProbabilityTreeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunProbabilityTree(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunProbabilityTree checks the probability tree of request, propagates its
+// probabilities from the root and rolls its values back, and records the
+// tree, annotated, as a probability-tree diagram in its session in the
+// tenant of ctx. Every node is annotated with its path_probability, the
+// chance of reaching it, and its expected_value from there on; every leaf
+// also with its joint_probability, path, payoff and contribution to the
+// expected value.
+func (h *VisualHandler) RunProbabilityTree(ctx context.Context, request api.ProbabilityTreeRequest) (*api.ProbabilityTreeResponse, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid probability tree: "+format, args...)
+	}
+	if request.SessionID == "" {
+		return nil, invalid("session_id is required")
+	}
+	if request.DiagramID == "" {
+		request.DiagramID = "probability-tree"
+	}
+
+	// Nodes are the elements that join none, each with the edge leading to it
+	elements := VisualElements(request.Elements)
+	index := make(map[string]int, len(elements))
+	for i, element := range elements {
+		if element.ID == "" {
+			return nil, invalid("element %d has no ID", i+1)
+		}
+		if _, listed := index[element.ID]; listed {
+			return nil, invalid("element %s is listed twice", element.ID)
+		}
+		index[element.ID] = i
+	}
+	incoming := make(map[string]int)
+	branches := make(map[string]int)
+	for i, element := range elements {
+		if element.Source == "" && element.Target == "" {
+			continue
+		}
+		for _, end := range []string{element.Source, element.Target} {
+			if k, ok := index[end]; !ok || elements[k].Source != "" || elements[k].Target != "" {
+				return nil, invalid("edge %s must join two nodes", element.ID)
+			}
+		}
+		if k, ok := incoming[element.Target]; ok {
+			return nil, invalid("node %s is reached by both edge %s and edge %s", element.Target, elements[k].ID, element.ID)
+		}
+		incoming[element.Target] = i
+		branches[element.Source]++
+	}
+
+	var nodes []decisiontree.Node
+	for _, element := range elements {
+		if element.Source != "" || element.Target != "" {
+			continue
+		}
+		node := decisiontree.Node{ID: element.ID, Type: decisiontree.Chance, Label: element.Label}
+		if branches[element.ID] == 0 {
+			node.Type = decisiontree.Terminal
+		}
+		if k, ok := incoming[element.ID]; ok {
+			node.Parent, node.Probability = elements[k].Source, elements[k].Probability
+		}
+		if value, ok := element.Properties["value"]; ok {
+			payoff, ok := value.(float64)
+			if !ok {
+				if n, isInt := value.(int); isInt {
+					payoff, ok = float64(n), true
+				}
+			}
+			if !ok {
+				return nil, invalid("node %s has a value that is not a number", element.ID)
+			}
+			node.Payoff = payoff
+		}
+		nodes = append(nodes, node)
+	}
+	result, err := decisiontree.Evaluate(nodes, decisiontree.Options{})
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	// The path to a node lists the labels of the edges taken to reach it,
+	// or failing those of the nodes reached
+	var path func(id string) []string
+	path = func(id string) []string {
+		k, ok := incoming[id]
+		if !ok {
+			return []string{}
+		}
+		edge, step := elements[k], id
+		switch {
+		case edge.Label != "":
+			step = edge.Label
+		case elements[index[id]].Label != "":
+			step = elements[index[id]].Label
+		}
+		return append(path(edge.Source), step)
+	}
+
+	response := &api.ProbabilityTreeResponse{
+		Status:        "success",
+		DiagramID:     request.DiagramID,
+		ExpectedValue: result.Value,
+		Leaves:        []api.ProbabilityLeaf{},
+	}
+	payoffs := make(map[string]float64, len(result.Nodes))
+	for _, n := range result.Nodes {
+		payoffs[n.ID] = n.Payoff
+	}
+	// A leaf pays the payoffs on the way from the root, its own included
+	var payoff func(id string) float64
+	payoff = func(id string) float64 {
+		if k, ok := incoming[id]; ok {
+			return payoffs[id] + payoff(elements[k].Source)
+		}
+		return payoffs[id]
+	}
+	for _, n := range result.Nodes {
+		element := &elements[index[n.ID]]
+		properties := make(map[string]interface{}, len(element.Properties)+6)
+		for key, value := range element.Properties {
+			properties[key] = value
+		}
+		properties["path_probability"] = n.ReachProbability
+		properties["expected_value"] = n.Value
+		if n.Type == decisiontree.Terminal {
+			leaf := api.ProbabilityLeaf{
+				Node:             n.ID,
+				Label:            n.Label,
+				Path:             path(n.ID),
+				JointProbability: n.ReachProbability,
+				Payoff:           payoff(n.ID),
+			}
+			leaf.Contribution = leaf.JointProbability * leaf.Payoff
+			properties["joint_probability"] = leaf.JointProbability
+			properties["path"] = leaf.Path
+			properties["payoff"] = leaf.Payoff
+			properties["contribution"] = leaf.Contribution
+			response.Leaves = append(response.Leaves, leaf)
+		}
+		element.Properties = properties
+	}
+
+	likeliest := response.Leaves[0]
+	for _, leaf := range response.Leaves[1:] {
+		if leaf.JointProbability > likeliest.JointProbability {
+			likeliest = leaf
+		}
+	}
+	response.Summary = fmt.Sprintf("The tree has %d outcomes and an expected value of %.4g; the likeliest, %s, has probability %.4g",
+		len(response.Leaves), result.Value, pathName(likeliest), likeliest.JointProbability)
+
+	visual := &types.VisualData{
+		Operation:   "create",
+		Elements:    elements,
+		DiagramID:   request.DiagramID,
+		DiagramType: "probability-tree",
+		Observation: request.Title,
+		Insight:     response.Summary,
+		CreatedAt:   time.Now(),
+	}
+	elements = layoutOperation(visual, elements)
+	if err := tenantStore(ctx, h.storage).AddVisualData(request.SessionID, visual); err != nil {
+		h.logger.WithError(err).Error("Failed to add visual data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add visual data")
+	}
+
+	response.VisualID = visual.ID
+	response.Elements = make([]api.VisualElement, len(elements))
+	for i, element := range elements {
+		response.Elements[i] = api.VisualElement(element)
+	}
+	return response, nil
+}
+
+// pathName names a leaf by its path, or by its label or ID at the root
+func pathName(leaf api.ProbabilityLeaf) string {
+	if len(leaf.Path) == 0 {
+		if leaf.Label != "" {
+			return leaf.Label
+		}
+		return leaf.Node
+	}
+	name := leaf.Path[0]
+	for _, step := range leaf.Path[1:] {
+		name += " then " + step
+	}
+	return name
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// StochasticAlgorithm is a stochastic method served alike by its HTTP route,
+// POST /api/v1/stochastic/{name}, its MCP tool, parameter sweeps and the
+// algorithm listing. Algorithms register once with
+// RegisterStochasticAlgorithm, and every transport serves what the registry
+// holds.
+type StochasticAlgorithm interface {
+	// Name names the algorithm in its route and in parameter sweeps
+	Name() string
+	// Tool names the algorithm's MCP tool
+	Tool() string
+	Description() string
+	// Request returns a pointer to a new zero request of the algorithm,
+	// whose type declares its parameters
+	Request() interface{}
+	// Validate checks that params, a request as JSON, decodes and sets the
+	// required parameters, without running it
+	Validate(params []byte) error
+	// Run runs params and records the run in its session in the tenant of
+	// ctx, sending progress best-so-far results when the algorithm streams,
+	// the request sets stream and progress is not nil
+	Run(ctx context.Context, params []byte, progress ProgressFunc) (interface{}, error)
+}
+
+// registration is a registered algorithm, bound to a handler to run it
+type registration struct {
+	name        string
+	tool        string
+	description string
+	request     func() interface{}
+	run         func(h *StochasticHandler, ctx context.Context, params []byte, progress ProgressFunc) (interface{}, error)
+}
+
+// registry holds the registered algorithms in the order they registered
+var registry []registration
+
+// RegisterStochasticAlgorithm registers the algorithm name, served as the
+// MCP tool tool. run runs a request on a handler, sending progress
+// best-so-far results when the request streams; run methods that do not
+// stream can be adapted with WithoutProgress. It panics if the name or the
+// tool is taken, and is meant to be called from init functions.
+func RegisterStochasticAlgorithm[Request, Response any](name, tool, description string, run func(h *StochasticHandler, ctx context.Context, request Request, progress ProgressFunc) (Response, error)) {
+	for _, r := range registry {
+		if r.name == name || r.tool == tool {
+			panic(fmt.Sprintf("handlers: stochastic algorithm %s or tool %s registered twice", name, tool))
+		}
+	}
+
+	registry = append(registry, registration{
+		name:        name,
+		tool:        tool,
+		description: description,
+		request:     func() interface{} { return new(Request) },
+		run: func(h *StochasticHandler, ctx context.Context, params []byte, progress ProgressFunc) (interface{}, error) {
+			var request Request
+			if err := json.Unmarshal(params, &request); err != nil {
+				return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid request: %v", err)
+			}
+			return run(h, ctx, request, progress)
+		},
+	})
+}
+
+// WithoutProgress adapts a run method that does not stream for
+// RegisterStochasticAlgorithm
+func WithoutProgress[Request, Response any](run func(*StochasticHandler, context.Context, Request) (Response, error)) func(*StochasticHandler, context.Context, Request, ProgressFunc) (Response, error) {
+	return func(h *StochasticHandler, ctx context.Context, request Request, _ ProgressFunc) (Response, error) {
+		return run(h, ctx, request)
+	}
+}
+
+func init() {
+	RegisterStochasticAlgorithm("mdp", "solve_mdp",
+		"Solve a Markov decision process given by its transitions by value or policy iteration, reporting the optimal policy, its values and how the solver converged; with stream set, the policy reached is sent as progress",
+		(*StochasticHandler).RunMDPWithProgress)
+	RegisterStochasticAlgorithm("mcts", "search_game_tree",
+		"Search a game given by its states and moves with Monte Carlo tree search (UCT), reporting the best move, the statistics of each move and the principal variation; with stream set, the best move so far is sent as progress",
+		(*StochasticHandler).RunMCTSWithProgress)
+	RegisterStochasticAlgorithm("bandit", "play_bandit",
+		"Play a multi-armed bandit of Bernoulli, Gaussian or empirical arms with epsilon-greedy, UCB1 or Thompson sampling, discounted or over a sliding window when arms drift, reporting the pulls and rewards of each arm, the arm to select, the regret curve and detected change points",
+		WithoutProgress((*StochasticHandler).RunBandit))
+	RegisterStochasticAlgorithm("bayesian", "bayesian_optimization",
+		"Optimize an arithmetic objective over bounded parameters, or fit observed evaluations, with a Gaussian-process surrogate and EI, UCB or PI acquisition, reporting the best point and the next to evaluate; with stream set, the best evaluation so far is sent as progress",
+		(*StochasticHandler).RunBayesianOptimizationWithProgress)
+	RegisterStochasticAlgorithm("hmm", "hidden_markov_model",
+		"Decode, smooth or fit a hidden Markov model over a sequence of observed symbols with Viterbi, forward-backward and Baum-Welch, reporting the most likely state path, the state posteriors and the model",
+		WithoutProgress((*StochasticHandler).RunHMM))
+	RegisterStochasticAlgorithm("reinforcement", "reinforcement_learning",
+		"Learn a policy by tabular Q-learning, SARSA or expected SARSA over episodes in a grid world, or in an environment given by its transitions or by observed samples, reporting the learned policy and learning curve",
+		WithoutProgress((*StochasticHandler).RunQLearning))
+	RegisterStochasticAlgorithm("annealing", "simulated_annealing",
+		"Minimize an arithmetic objective over bounded variables by simulated annealing with an exponential, linear, logarithmic or fast cooling schedule, reporting the best point found and the search trajectory",
+		WithoutProgress((*StochasticHandler).RunSimulatedAnnealing))
+	RegisterStochasticAlgorithm("montecarlo", "monte_carlo_simulation",
+		"Simulate an arithmetic output over normal, lognormal, triangular, beta and discrete random variables, reporting its mean, percentiles, histogram and the probability of exceeding thresholds",
+		WithoutProgress((*StochasticHandler).RunMonteCarloSimulation))
+	RegisterStochasticAlgorithm("particle", "particle_filter",
+		"Track a latent state through a sequence of observations with a particle filter, given expressions for how each state variable moves and what the state is observed as, returning filtered estimates with uncertainty bands",
+		WithoutProgress((*StochasticHandler).RunParticleFilter))
+	RegisterStochasticAlgorithm("bootstrap", "bootstrap_analysis",
+		"Quantify the uncertainty of the mean, median or difference of means of a small numeric dataset by bootstrap resampling, returning its standard error and confidence intervals",
+		WithoutProgress((*StochasticHandler).RunBootstrapAnalysis))
+	RegisterStochasticAlgorithm("queueing", "queueing_simulation",
+		"Simulate an M/M/1 or M/M/c queue from its arrival and service rates to reason about capacity and throughput, returning the servers' utilization, the expected wait and the distribution of the queue length beside the steady state by Erlang's C formula",
+		WithoutProgress((*StochasticHandler).RunQueueingSimulation))
+	RegisterStochasticAlgorithm("abtest", "ab_test_analysis",
+		"Analyze an A/B test from each variant's visitors and conversions, comparing each with the control by a two-proportion z-test and by Beta-Binomial posteriors, returning p-values, the probability to beat the control and to be best, and the conversion rate expected to be lost by choosing each variant",
+		WithoutProgress((*StochasticHandler).RunABTestAnalysis))
+}
+
+// rerunOf returns what repeats a run of the registered algorithm name from
+// its request, with the defaults and seed it ran with
+func rerunOf(name string, request interface{}) *types.Rerun {
+	raw, err := json.Marshal(request)
+	if err != nil {
+		return nil
+	}
+	rerun := &types.Rerun{Algorithm: name}
+	if err := json.Unmarshal(raw, &rerun.Request); err != nil {
+		return nil
+	}
+	return rerun
+}
+
+// boundAlgorithm is a registered algorithm run by a handler
+type boundAlgorithm struct {
+	registration
+	h *StochasticHandler
+}
+
+func (a boundAlgorithm) Name() string         { return a.name }
+func (a boundAlgorithm) Tool() string         { return a.tool }
+func (a boundAlgorithm) Description() string  { return a.description }
+func (a boundAlgorithm) Request() interface{} { return a.request() }
+
+func (a boundAlgorithm) Validate(params []byte) error {
+	request := a.request()
+	if err := json.Unmarshal(params, request); err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid request: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(params, &fields); err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid request: %v", err)
+	}
+	_, required := api.Properties(request)
+	for _, name := range required {
+		if value, ok := fields[name]; !ok || value == nil || value == "" {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid request: %s is required", name)
+		}
+	}
+	return nil
+}
+
+func (a boundAlgorithm) Run(ctx context.Context, params []byte, progress ProgressFunc) (interface{}, error) {
+	return a.run(a.h, ctx, params, progress)
+}
+
+// Algorithms returns the registered algorithms, run by h, in the order they
+// registered
+func (h *StochasticHandler) Algorithms() []StochasticAlgorithm {
+	algorithms := make([]StochasticAlgorithm, len(registry))
+	for i, r := range registry {
+		algorithms[i] = boundAlgorithm{registration: r, h: h}
+	}
+	return algorithms
+}
+
+// Algorithm returns the registered algorithm of a name, run by h
+func (h *StochasticHandler) Algorithm(name string) (StochasticAlgorithm, bool) {
+	for _, r := range registry {
+		if r.name == name {
+			return boundAlgorithm{registration: r, h: h}, true
+		}
+	}
+	return nil, false
+}
+
+// Streams reports whether algorithm streams best-so-far results, which its
+// requests declare with a stream parameter
+func Streams(algorithm StochasticAlgorithm) bool {
+	properties, _ := api.Properties(algorithm.Request())
+	_, ok := properties["stream"]
+	return ok
+}
+
+// ServeAlgorithm returns the handler of algorithm's route, which runs the
+// request body, as server-sent events when the algorithm streams and the
+// request sets stream
+func (h *StochasticHandler) ServeAlgorithm(algorithm StochasticAlgorithm) http.HandlerFunc {
+	streams := Streams(algorithm)
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		var options struct {
+			Stream bool `json:"stream"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || json.Unmarshal(body, &options) != nil {
+			h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+			return
+		}
+		if streams && options.Stream {
+			h.stream(w, r, func(ctx context.Context, progress ProgressFunc) (interface{}, error) {
+				return algorithm.Run(ctx, body, progress)
+			})
+			return
+		}
+
+		response, err := algorithm.Run(r.Context(), body, nil)
+		if err != nil {
+			h.respondWithError(w, apierror.CodeOf(err), err.Error())
+			return
+		}
+
+		h.respondWithJSON(w, response)
+	}
+}
+
+// ListAlgorithms handles algorithm listing requests
+func (h *StochasticHandler) ListAlgorithms(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, h.ListStochasticAlgorithms())
+}
+
+// ListStochasticAlgorithms describes the registered algorithms with the
+// schemas of their requests
+func (h *StochasticHandler) ListStochasticAlgorithms() *api.ListAlgorithmsResponse {
+	response := &api.ListAlgorithmsResponse{Status: "success"}
+	for _, algorithm := range h.Algorithms() {
+		response.Algorithms = append(response.Algorithms, api.AlgorithmInfo{
+			Name:        algorithm.Name(),
+			Tool:        algorithm.Tool(),
+			Route:       "/api/v1/stochastic/" + algorithm.Name(),
+			Description: algorithm.Description(),
+			Streams:     Streams(algorithm),
+			Parameters:  api.Schema(algorithm.Request()),
+		})
+	}
+	response.Count = len(response.Algorithms)
+	return response
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// resultTypes gives, for the algorithm a run records, the name of its typed
+// data and a constructor of it
+var resultTypes = map[string]struct {
+	name string
+	data func() interface{}
+}{
+	"mdp":                  {"mdp", func() interface{} { return &types.MDPData{} }},
+	"mcts":                 {"mcts", func() interface{} { return &types.MCTSData{} }},
+	"bandit":               {"bandit", func() interface{} { return &types.BanditData{} }},
+	"bayesian":             {"bayesian", func() interface{} { return &types.BayesianOptimizationData{} }},
+	"hmm":                  {"hmm", func() interface{} { return &types.HMMData{} }},
+	"q_learning":           {"reinforcement", func() interface{} { return &types.QLearningData{} }},
+	"sarsa":                {"reinforcement", func() interface{} { return &types.QLearningData{} }},
+	"expected_sarsa":       {"reinforcement", func() interface{} { return &types.QLearningData{} }},
+	"simulated_annealing":  {"annealing", func() interface{} { return &types.AnnealingData{} }},
+	"monte_carlo":          {"montecarlo", func() interface{} { return &types.MonteCarloData{} }},
+	"particle_filter":      {"particle", func() interface{} { return &types.ParticleFilterData{} }},
+	"bootstrap":            {"bootstrap", func() interface{} { return &types.BootstrapData{} }},
+	"queueing":             {"queueing", func() interface{} { return &types.QueueingData{} }},
+	"ab_test":              {"abtest", func() interface{} { return &types.ABTestData{} }},
+	"stochastic_dominance": {"dominance", func() interface{} { return &types.DominanceData{} }},
+	"parameter_sweep":      {"sweep", func() interface{} { return &types.ParameterSweepData{} }},
+	"sensitivity":          {"sensitivity", func() interface{} { return &types.SensitivityData{} }},
+}
+
+// addRun adds run to the session in store, keeping its payload from data, the
+// typed data run is embedded in
+func addRun(store storage.Store, sessionID string, run *types.StochasticAlgorithmData, data interface{}) error {
+	if err := keepPayload(run, data); err != nil {
+		return err
+	}
+	return store.AddStochasticAlgorithm(sessionID, run)
+}
+
+// keepPayload keeps as run's payload the fields data, the typed data run is
+// embedded in, has beyond run's own
+func keepPayload(run *types.StochasticAlgorithmData, data interface{}) error {
+	full, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(full, &fields); err != nil {
+		return err
+	}
+	common, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	var shared map[string]json.RawMessage
+	if err := json.Unmarshal(common, &shared); err != nil {
+		return err
+	}
+	for name := range shared {
+		delete(fields, name)
+	}
+	if len(fields) > 0 {
+		if run.Payload, err = json.Marshal(fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findRun returns the run id of the session in store
+func findRun(store storage.Store, sessionID, id string) (*types.StochasticAlgorithmData, error) {
+	records, err := store.GetStochasticAlgorithms(sessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get stochastic algorithm data")
+	}
+	for _, record := range records {
+		if record.ID == id {
+			return record, nil
+		}
+	}
+	return nil, apierror.Errorf(apierror.CodeRecordNotFound, "Run %s not found in session %s", id, sessionID)
+}
+
+// GetResult handles stochastic result requests
+func (h *StochasticHandler) GetResult(w http.ResponseWriter, r *http.Request) {
+	request := api.StochasticResultRequest{
+		SessionID:   r.URL.Query().Get("session_id"),
+		AlgorithmID: mux.Vars(r)["id"],
+	}
+
+	response, err := h.RunGetResult(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunGetResult returns the run request names, from its session in the tenant
+// of ctx, as the typed data of its algorithm: its common fields together with
+// the fields its payload keeps
+func (h *StochasticHandler) RunGetResult(ctx context.Context, request api.StochasticResultRequest) (*api.StochasticResultResponse, error) {
+	if request.SessionID == "" || request.AlgorithmID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid result request: session_id and algorithm_id are required")
+	}
+	record, err := findRun(tenantStore(ctx, h.storage), request.SessionID, request.AlgorithmID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the common fields, then the payload over them, into the
+	// algorithm's typed data
+	common := *record
+	common.Payload = nil
+	raw, err := json.Marshal(common)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInternal, "Failed to encode run %s", record.ID)
+	}
+	response := &api.StochasticResultResponse{
+		AlgorithmID: record.ID,
+		Algorithm:   record.Algorithm,
+		Type:        "run",
+		Complete:    record.Payload != nil,
+	}
+	var data interface{} = &types.StochasticAlgorithmData{}
+	if typed, ok := resultTypes[record.Algorithm]; ok {
+		response.Type, data = typed.name, typed.data()
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, apierror.Errorf(apierror.CodeInternal, "Failed to decode run %s", record.ID)
+	}
+	if record.Payload != nil {
+		if err := json.Unmarshal(record.Payload, data); err != nil {
+			return nil, apierror.Errorf(apierror.CodeInternal, "Failed to decode the payload of run %s: %v", record.ID, err)
+		}
+	}
+	response.Result = data
+	return response, nil
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/scenario"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// ScenarioAnalysis handles scenario analysis requests
+func (h *DecisionHandler) ScenarioAnalysis(w http.ResponseWriter, r *http.Request) {
+	var request api.ScenarioAnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunScenarioAnalysis(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunScenarioAnalysis evaluates the options of request across its scenarios,
+// separating the robust options from the bets, and records the analysis in
+// its session in the tenant of ctx
+func (h *DecisionHandler) RunScenarioAnalysis(ctx context.Context, request api.ScenarioAnalysisRequest) (*api.ScenarioAnalysisResponse, error) {
+	if request.SessionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: session_id is required")
+	}
+	tolerance := scenario.DefaultTolerance
+	if request.Tolerance != nil {
+		tolerance = *request.Tolerance
+	}
+
+	analysis := &types.ScenarioData{
+		Title:     request.Title,
+		Scenarios: make([]types.Scenario, len(request.Scenarios)),
+		Options:   request.Options,
+		Outcomes:  make([]types.ScenarioOutcome, len(request.Outcomes)),
+		Tolerance: tolerance,
+		CreatedAt: time.Now(),
+	}
+	for j, s := range request.Scenarios {
+		analysis.Scenarios[j] = types.Scenario(s)
+	}
+	for k, o := range request.Outcomes {
+		analysis.Outcomes[k] = types.ScenarioOutcome(o)
+	}
+	if err := evaluateScenarios(analysis); err != nil {
+		return nil, err
+	}
+
+	if err := tenantStore(ctx, h.storage).AddScenario(request.SessionID, analysis); err != nil {
+		h.logger.WithError(err).Error("Failed to add scenario analysis")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add scenario analysis")
+	}
+
+	return &api.ScenarioAnalysisResponse{
+		ScenarioID: analysis.ID,
+		Status:     "success",
+		Analysis:   *ScenarioAnalysis(analysis),
+	}, nil
+}
+
+// evaluateScenarios evaluates the options of analysis from its outcomes and
+// stores the evaluations on it, ranked by expected value. The recommendation
+// names the robust option of highest expected value, or, when no option is
+// robust, those of least maximum regret and highest expected value, and the
+// scenarios each bet is on.
+func evaluateScenarios(analysis *types.ScenarioData) error {
+	options := make(map[string]int, len(analysis.Options))
+	for i, option := range analysis.Options {
+		if _, listed := options[option]; listed {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: option %s is listed twice", option)
+		}
+		options[option] = i
+	}
+	scenarios := make(map[string]int, len(analysis.Scenarios))
+	futures := make([]scenario.Scenario, len(analysis.Scenarios))
+	for j, s := range analysis.Scenarios {
+		if _, listed := scenarios[s.Name]; listed {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: scenario %s is listed twice", s.Name)
+		}
+		scenarios[s.Name] = j
+		futures[j] = scenario.Scenario{Name: s.Name, Probability: s.Probability}
+	}
+	outcomes := make([][]float64, len(analysis.Options))
+	given := make([][]bool, len(analysis.Options))
+	for i := range outcomes {
+		outcomes[i], given[i] = make([]float64, len(futures)), make([]bool, len(futures))
+	}
+	for _, o := range analysis.Outcomes {
+		i, ok := options[o.Option]
+		if !ok {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: there is no option %s", o.Option)
+		}
+		j, ok := scenarios[o.Scenario]
+		switch {
+		case !ok:
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: there is no scenario %s", o.Scenario)
+		case given[i][j]:
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: the outcome of %s in %s is given twice", o.Option, o.Scenario)
+		}
+		outcomes[i][j], given[i][j] = o.Value, true
+	}
+	for i, row := range given {
+		for j, ok := range row {
+			if !ok {
+				return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: the outcome of %s in %s is missing", analysis.Options[i], futures[j].Name)
+			}
+		}
+	}
+	result, err := scenario.Analyze(futures, analysis.Options, outcomes, analysis.Tolerance)
+	if err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scenario analysis: %v", err)
+	}
+
+	analysis.Evaluations = make([]types.ScenarioEvaluation, len(result.Options))
+	for i, o := range result.Options {
+		analysis.Evaluations[i] = types.ScenarioEvaluation{
+			Option:         o.Name,
+			Class:          o.Class,
+			ExpectedValue:  o.ExpectedValue,
+			WorstCase:      o.Worst,
+			BestCase:       o.Best,
+			MaxRegret:      o.MaxRegret,
+			ExpectedRegret: o.ExpectedRegret,
+			BestIn:         o.BestIn,
+			WeakestIn:      o.WeakestIn,
+		}
+	}
+	evaluations := analysis.Evaluations
+	sort.SliceStable(evaluations, func(a, b int) bool { return evaluations[a].ExpectedValue > evaluations[b].ExpectedValue })
+	least := 0
+	var bets []string
+	for i := range evaluations {
+		evaluations[i].Rank = i + 1
+		if i > 0 && math.Abs(evaluations[i].ExpectedValue-evaluations[i-1].ExpectedValue) < 1e-9 {
+			evaluations[i].Rank = evaluations[i-1].Rank
+		}
+		if evaluations[i].MaxRegret < evaluations[least].MaxRegret {
+			least = i
+		}
+		switch evaluations[i].Class {
+		case scenario.Robust:
+			analysis.RobustOptions = append(analysis.RobustOptions, evaluations[i].Option)
+		case scenario.Bet:
+			analysis.Bets = append(analysis.Bets, evaluations[i].Option)
+			bets = append(bets, fmt.Sprintf("%s is a bet on %s, weakest under %s", evaluations[i].Option, strings.Join(evaluations[i].BestIn, " and "), evaluations[i].WeakestIn))
+		}
+	}
+
+	best := evaluations[0]
+	if len(analysis.RobustOptions) > 0 {
+		var robust types.ScenarioEvaluation
+		for _, e := range evaluations {
+			if e.Class == scenario.Robust {
+				robust = e
+				break
+			}
+		}
+		analysis.Recommendation = fmt.Sprintf("%s is robust, never more than %g%% of the way from a scenario's best outcome to its worst, with an expected value of %.4g", robust.Option, 100*analysis.Tolerance, robust.ExpectedValue)
+		if robust.Option != best.Option {
+			analysis.Recommendation += fmt.Sprintf("; %s has the highest expected value, %.4g", best.Option, best.ExpectedValue)
+		}
+	} else if least == 0 {
+		analysis.Recommendation = fmt.Sprintf("No option is robust at a tolerance of %g%%; %s has both the least maximum regret, %.4g, and the highest expected value, %.4g", 100*analysis.Tolerance, best.Option, best.MaxRegret, best.ExpectedValue)
+	} else {
+		analysis.Recommendation = fmt.Sprintf("No option is robust at a tolerance of %g%%; %s has the least maximum regret, %.4g, and %s the highest expected value, %.4g", 100*analysis.Tolerance, evaluations[least].Option, evaluations[least].MaxRegret, best.Option, best.ExpectedValue)
+	}
+	if len(bets) > 0 {
+		analysis.Recommendation += "; " + strings.Join(bets, "; ")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// ratingScores are the scores qualitative ratings stand for on a benefit
+// criterion; on a cost criterion they count down from 5, so that a better
+// rating stays better once the criterion's scores are inverted
+var ratingScores = map[string]float64{"very_poor": 1, "poor": 2, "fair": 3, "good": 4, "excellent": 5}
+
+// ScoreOption handles option scoring requests
+func (h *DecisionHandler) ScoreOption(w http.ResponseWriter, r *http.Request) {
+	var request api.ScoreDecisionOptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunScoreOption(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunScoreOption records the score of request's option on its criterion in
+// the decision matrix of the decision request names, in its session in the
+// tenant of ctx, replacing any earlier score of the pair. Once the matrix is
+// complete the decision's options are ranked by it.
+func (h *DecisionHandler) RunScoreOption(ctx context.Context, request api.ScoreDecisionOptionRequest) (*api.ScoreDecisionOptionResponse, error) {
+	switch {
+	case request.SessionID == "" || request.DecisionID == "":
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: session_id and decision_id are required")
+	case request.Option == "" || request.Criterion == "":
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: option and criterion are required")
+	case (request.Score == nil) == (request.Rating == ""):
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: give either a score or a rating")
+	}
+	if _, ok := ratingScores[request.Rating]; request.Rating != "" && !ok {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: unknown rating %q", request.Rating)
+	}
+
+	var scored types.DecisionData
+	var missing []string
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		var criterion *types.DecisionCriterion
+		for j := range decision.Criteria {
+			if decision.Criteria[j].Name == request.Criterion {
+				criterion = &decision.Criteria[j]
+			}
+		}
+		if criterion == nil {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: there is no criterion %s", request.Criterion)
+		}
+		found := false
+		for _, option := range decision.Options {
+			found = found || option.Name == request.Option
+		}
+		if !found {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid scoring: there is no option %s", request.Option)
+		}
+
+		score := types.DecisionScore{Option: request.Option, Criterion: request.Criterion, Rating: request.Rating, Rationale: request.Rationale}
+		if request.Score != nil {
+			score.Score = *request.Score
+		} else if score.Score = ratingScores[request.Rating]; criterion.Direction == "cost" {
+			score.Score = 6 - score.Score
+		}
+		replaced := false
+		for k := range decision.Scores {
+			if decision.Scores[k].Option == score.Option && decision.Scores[k].Criterion == score.Criterion {
+				decision.Scores[k], replaced = score, true
+			}
+		}
+		if !replaced {
+			decision.Scores = append(decision.Scores, score)
+		}
+
+		missing = missingScores(decision)
+		if len(missing) == 0 {
+			if err := ScoreDecision(decision); err != nil {
+				return err
+			}
+		}
+		scored = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to score option: %v", err)
+	}
+
+	response := &api.ScoreDecisionOptionResponse{
+		DecisionID: request.DecisionID,
+		Status:     "success",
+		Matrix:     DecisionMatrix(scored.Scores),
+		Missing:    missing,
+		Complete:   len(missing) == 0,
+	}
+	if response.Complete {
+		response.Ranking = RankedOptions(scored.Ranking)
+		response.Recommendation = scored.Recommendation
+	}
+	return response, nil
+}
+
+// missingScores returns the option and criterion pairs of decision without a
+// score, option by option
+func missingScores(decision *types.DecisionData) []string {
+	scored := make(map[[2]string]bool, len(decision.Scores))
+	for _, score := range decision.Scores {
+		scored[[2]string{score.Option, score.Criterion}] = true
+	}
+	var missing []string
+	for _, option := range decision.Options {
+		for _, criterion := range decision.Criteria {
+			if !scored[[2]string{option.Name, criterion.Name}] {
+				missing = append(missing, fmt.Sprintf("%s on %s", option.Name, criterion.Name))
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// tornadoWidth is the length of the bar of the largest swing in a
+// sensitivity chart
+const tornadoWidth = 20
+
+// SensitivityAnalysis handles sensitivity analysis requests
+func (h *StochasticHandler) SensitivityAnalysis(w http.ResponseWriter, r *http.Request) {
+	var request api.SensitivityRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunSensitivityAnalysis(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunSensitivityAnalysis re-runs the run request names, as recorded in its
+// session in the tenant of ctx, with each parameter perturbed in turn, and
+// records how far each moved the run's value there. The re-runs keep the
+// recorded seed, so a parameter's swing is its own effect rather than a
+// different draw. Like a sweep's, they are kept in a scratch store.
+func (h *StochasticHandler) RunSensitivityAnalysis(ctx context.Context, request api.SensitivityRequest) (*api.SensitivityResponse, error) {
+	// Set defaults
+	if request.Perturbation == 0 {
+		request.Perturbation = 0.1
+	}
+	if request.Parallelism == 0 {
+		request.Parallelism = runtime.GOMAXPROCS(0)
+	}
+	if request.Parallelism > 64 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sensitivity analysis: at most a parallelism of 64")
+	}
+
+	store := tenantStore(ctx, h.storage)
+	record, err := findRun(store, request.SessionID, request.AlgorithmID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Rerun == nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sensitivity analysis: run %s (%s) records no request to run again", record.ID, record.Algorithm)
+	}
+	algorithm, ok := h.Algorithm(record.Rerun.Algorithm)
+	if !ok {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sensitivity analysis: run %s was run by %s, which is no longer registered", record.ID, record.Rerun.Algorithm)
+	}
+	values, err := perturbations(request, record.Rerun.Request, algorithm)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sensitivity analysis: %v", err)
+	}
+
+	// The first configuration is the run as recorded, then each value of
+	// each parameter in turn
+	configurations := []map[string]interface{}{{}}
+	for i, p := range request.Parameters {
+		for _, value := range values[i] {
+			configurations = append(configurations, map[string]interface{}{p.Name: value})
+		}
+	}
+
+	// Run the configurations, stopping if the client goes away
+	sweep := api.ParameterSweepRequest{Problem: record.Problem, Request: record.Rerun.Request, Metric: request.Metric}
+	results := h.runConfigurations(ctx, algorithm.Name(), sweep, configurations, request.Parallelism)
+	if err := ctx.Err(); err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Sensitivity analysis cancelled")
+	}
+	base := results[0]
+	if base.Value == nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sensitivity analysis: the run as recorded produced no value: %s", base.Error)
+	}
+
+	sensitivities := make([]api.ParameterSensitivity, len(request.Parameters))
+	next := 1
+	for i, p := range request.Parameters {
+		sensitivity := api.ParameterSensitivity{
+			Name:    p.Name,
+			Base:    record.Rerun.Request[p.Name],
+			Results: results[next : next+len(values[i])],
+			Low:     base.Value,
+			High:    base.Value,
+		}
+		next += len(values[i])
+		for _, result := range sensitivity.Results {
+			if result.Value == nil {
+				continue
+			}
+			if *result.Value < *sensitivity.Low {
+				sensitivity.Low = result.Value
+			}
+			if *result.Value > *sensitivity.High {
+				sensitivity.High = result.Value
+			}
+		}
+		sensitivity.Swing = *sensitivity.High - *sensitivity.Low
+		sensitivities[i] = sensitivity
+	}
+	sort.SliceStable(sensitivities, func(i, j int) bool { return sensitivities[i].Swing > sensitivities[j].Swing })
+
+	metric := request.Metric
+	if metric == "" {
+		metric = "value"
+	}
+	top := sensitivities[0]
+	summary := fmt.Sprintf("Run %s (%s) is most sensitive to %s, which moves its %s from %.4g to %.4g around %.4g",
+		record.ID, algorithm.Name(), top.Name, metric, *top.Low, *top.High, *base.Value)
+	if top.Swing == 0 {
+		summary = fmt.Sprintf("Run %s (%s) keeps its %s at %.4g under every perturbation", record.ID, algorithm.Name(), metric, *base.Value)
+	}
+
+	stored := make([]types.ParameterSensitivity, len(sensitivities))
+	for i, sensitivity := range sensitivities {
+		stored[i] = types.ParameterSensitivity{
+			Name:    sensitivity.Name,
+			Base:    sensitivity.Base,
+			Results: make([]types.SweepResult, len(sensitivity.Results)),
+			Low:     sensitivity.Low,
+			High:    sensitivity.High,
+			Swing:   sensitivity.Swing,
+		}
+		for j, result := range sensitivity.Results {
+			stored[i].Results[j] = types.SweepResult(result)
+		}
+	}
+
+	// Create sensitivity data
+	sensitivityData := &types.SensitivityData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "sensitivity",
+			Problem:   record.Problem,
+			Parameters: map[string]interface{}{
+				"run":          record.ID,
+				"algorithm":    algorithm.Name(),
+				"parameters":   len(request.Parameters),
+				"perturbation": request.Perturbation,
+				"metric":       metric,
+				"parallelism":  request.Parallelism,
+			},
+			Result:     summary,
+			Iterations: len(results),
+			Value:      &top.Swing,
+			CreatedAt:  time.Now(),
+		},
+		Sensitivities: stored,
+	}
+
+	// Add to storage
+	if err := addRun(store, request.SessionID, &sensitivityData.StochasticAlgorithmData, sensitivityData); err != nil {
+		h.logger.WithError(err).Error("Failed to add sensitivity data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add sensitivity data")
+	}
+
+	return &api.SensitivityResponse{
+		AlgorithmID:   sensitivityData.ID,
+		Status:        "success",
+		Summary:       summary,
+		HasResult:     true,
+		Run:           record.ID,
+		Algorithm:     algorithm.Name(),
+		Metric:        metric,
+		Base:          base,
+		Sensitivities: sensitivities,
+		Chart:         tornadoChart(metric, record.ID, *base.Value, sensitivities),
+	}, nil
+}
+
+// perturbations returns the values to try for each parameter of request: its
+// values, or the run's value of it moved down and up by its perturbation,
+// within the bounds of algorithm's request
+func perturbations(request api.SensitivityRequest, recorded map[string]interface{}, algorithm StochasticAlgorithm) ([][]interface{}, error) {
+	if len(request.Parameters) == 0 {
+		return nil, fmt.Errorf("there are no parameters to perturb")
+	}
+	properties, _ := api.Properties(algorithm.Request())
+	seen := map[string]bool{"session_id": true, "problem": true}
+	values := make([][]interface{}, len(request.Parameters))
+	for i, p := range request.Parameters {
+		property, ok := properties[p.Name].(map[string]any)
+		switch {
+		case p.Name == "" || seen[p.Name]:
+			return nil, fmt.Errorf("parameters need distinct names other than session_id and problem")
+		case !ok:
+			return nil, fmt.Errorf("%s takes no parameter %s", algorithm.Name(), p.Name)
+		case p.Perturbation < 0:
+			return nil, fmt.Errorf("parameter %s needs a positive perturbation", p.Name)
+		}
+		seen[p.Name] = true
+		if len(p.Values) > 0 {
+			values[i] = p.Values
+			continue
+		}
+
+		x, ok := recorded[p.Name].(float64)
+		if !ok {
+			return nil, fmt.Errorf("the run has no numeric %s to perturb; give values to try", p.Name)
+		}
+		perturbation := p.Perturbation
+		if perturbation == 0 {
+			perturbation = request.Perturbation
+		}
+		low, high := x-perturbation*math.Abs(x), x+perturbation*math.Abs(x)
+		if x == 0 {
+			low, high = -perturbation, perturbation
+		}
+		if property["type"] == "integer" {
+			low, high = math.Min(math.Round(low), x-1), math.Max(math.Round(high), x+1)
+		}
+		if minimum, ok := property["minimum"].(float64); ok {
+			low = math.Max(low, minimum)
+		}
+		if maximum, ok := property["maximum"].(float64); ok {
+			high = math.Min(high, maximum)
+		}
+		for _, value := range []float64{low, high} {
+			if value != x {
+				values[i] = append(values[i], value)
+			}
+		}
+		if len(values[i]) == 0 {
+			return nil, fmt.Errorf("parameter %s cannot move from %v within its bounds", p.Name, x)
+		}
+	}
+	return values, nil
+}
+
+// tornadoChart renders sensitivities, largest swing first, as a Markdown
+// table with a bar for each swing
+func tornadoChart(metric, run string, base float64, sensitivities []api.ParameterSensitivity) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Sensitivity of %s of run %s\n\n", metric, run)
+	fmt.Fprintf(&b, "Base %s %.4g\n\n", metric, base)
+	b.WriteString("| Parameter | Base | Tried | Low | High | Swing | |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	widest := sensitivities[0].Swing
+	for _, s := range sensitivities {
+		tried := make([]string, len(s.Results))
+		for i, result := range s.Results {
+			tried[i] = fmt.Sprintf("%v", result.Configuration[s.Name])
+			if result.Value == nil {
+				tried[i] += " (failed)"
+			}
+		}
+		bar := ""
+		if widest > 0 {
+			bar = strings.Repeat("█", int(math.Round(tornadoWidth*s.Swing/widest)))
+		}
+		fmt.Fprintf(&b, "| %s | %v | %s | %.4g | %.4g | %.4g | %s |\n", s.Name, s.Base, strings.Join(tried, ", "),
+			*s.Low, *s.High, s.Swing, bar)
+	}
+	return b.String()
+}
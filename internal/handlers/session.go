@@ -1,21 +1,32 @@
 package handlers
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/search"
 	"github.com/rainmana/gothink/internal/storage"
 )
 
 // SessionHandler handles session management operations
 type SessionHandler struct {
-	storage *storage.Storage
+	storage storage.Store
 	logger  *logrus.Logger
 }
 
 // NewSessionHandler creates a new session handler
-func NewSessionHandler(storage *storage.Storage, logger *logrus.Logger) *SessionHandler {
+func NewSessionHandler(storage storage.Store, logger *logrus.Logger) *SessionHandler {
 	return &SessionHandler{
 		storage: storage,
 		logger:  logger,
@@ -24,69 +35,307 @@ func NewSessionHandler(storage *storage.Storage, logger *logrus.Logger) *Session
 
 // GetStats handles session statistics requests
 func (h *SessionHandler) GetStats(w http.ResponseWriter, r *http.Request) {
-	sessionID := r.URL.Query().Get("session_id")
+	sessionID := sessionIDFromRequest(r)
 	if sessionID == "" {
-		h.respondWithError(w, "Session ID required", http.StatusBadRequest)
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Session ID required")
 		return
 	}
 
-	stats, err := h.storage.GetSessionStats(sessionID)
+	stats, err := tenantStore(r.Context(), h.storage).GetSessionStats(sessionID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get session stats")
-		h.respondWithError(w, "Failed to get session stats", http.StatusInternalServerError)
+		h.respondWithError(w, apierror.CodeOf(err), "Failed to get session stats")
 		return
 	}
 
 	h.respondWithJSON(w, stats)
 }
 
-// Export handles session export requests
+// StorageStats handles storage statistics requests (GET /api/v1/storage/stats).
+// The optional limit query parameter caps the number of sessions listed by size.
+func (h *SessionHandler) StorageStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := tenantStore(r.Context(), h.storage).StorageStats()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get storage stats")
+		h.respondWithError(w, apierror.CodeOf(err), "Failed to get storage stats")
+		return
+	}
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			h.respondWithError(w, apierror.CodeInvalidParameters, "limit must be a non-negative integer")
+			return
+		}
+		if limit < len(stats.SessionSizes) {
+			stats.SessionSizes = stats.SessionSizes[:limit]
+		}
+	}
+
+	h.respondWithJSON(w, stats)
+}
+
+// Export handles session export requests. The optional format query parameter
+// selects json (default), markdown, csv, which is returned as a zip archive
+// holding one file per store, or dot, excalidraw or drawio, which hold one
+// file per diagram and are zipped when there are several.
 func (h *SessionHandler) Export(w http.ResponseWriter, r *http.Request) {
-	sessionID := r.URL.Query().Get("session_id")
+	sessionID := sessionIDFromRequest(r)
 	if sessionID == "" {
-		h.respondWithError(w, "Session ID required", http.StatusBadRequest)
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Session ID required")
 		return
 	}
 
-	export, err := h.storage.ExportSession(sessionID)
+	export, err := tenantStore(r.Context(), h.storage).ExportSession(sessionID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to export session")
-		h.respondWithError(w, "Failed to export session", http.StatusInternalServerError)
+		h.respondWithError(w, apierror.CodeOf(err), "Failed to export session")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" || format == storage.FormatJSON {
+		h.respondWithJSON(w, export)
 		return
 	}
 
-	h.respondWithJSON(w, export)
+	files, err := storage.RenderExport(export, format)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, err.Error())
+		return
+	}
+
+	if len(files) == 1 {
+		w.Header().Set("Content-Type", files[0].ContentType+"; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", files[0].Name))
+		io.WriteString(w, files[0].Content)
+		return
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for _, file := range files {
+		fw, err := zw.Create(file.Name)
+		if err == nil {
+			_, err = io.WriteString(fw, file.Content)
+		}
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to build export archive")
+			h.respondWithError(w, apierror.CodeInternal, "Failed to export session")
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		h.logger.WithError(err).Error("Failed to build export archive")
+		h.respondWithError(w, apierror.CodeInternal, "Failed to export session")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "session-"+format+".zip"))
+	w.Write(archive.Bytes())
 }
 
-// Import handles session import requests
+// Import handles session import requests. The body is a session export; the
+// optional session_id query parameter overrides the session it is restored to.
 func (h *SessionHandler) Import(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Session import not yet implemented",
-		"status":  "coming_soon",
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Failed to read request body")
+		return
 	}
-	h.respondWithJSON(w, response)
+
+	export, err := storage.DecodeSessionExport(body)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, err.Error())
+		return
+	}
+
+	result, err := storage.ImportSession(tenantStore(r.Context(), h.storage), export, r.URL.Query().Get("session_id"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to import session")
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, result)
 }
 
-// Clear handles session clear requests
+// Clear handles session clear requests, deleting the session and all of its records
 func (h *SessionHandler) Clear(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Session clear not yet implemented",
-		"status":  "coming_soon",
+	sessionID := sessionIDFromRequest(r)
+	if sessionID == "" {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Session ID required")
+		return
+	}
+
+	if err := tenantStore(r.Context(), h.storage).ClearSession(sessionID); err != nil {
+		h.logger.WithError(err).Error("Failed to clear session")
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, map[string]interface{}{
+		"session_id": sessionID,
+		"status":     "cleared",
+	})
+}
+
+// Archive handles session archive requests
+func (h *SessionHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// Restore handles requests to restore an archived session
+func (h *SessionHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *SessionHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	sessionID := sessionIDFromRequest(r)
+	if sessionID == "" {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Session ID required")
+		return
+	}
+
+	store := tenantStore(r.Context(), h.storage)
+	if _, err := store.GetSession(sessionID); err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	change, status := store.RestoreSession, "active"
+	if archived {
+		change, status = store.ArchiveSession, "archived"
+	}
+
+	if err := change(sessionID); err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, map[string]interface{}{
+		"session_id": sessionID,
+		"status":     status,
+	})
+}
+
+// GetRecords handles paginated record listing requests. The record type is
+// given by the type query parameter; limit, offset, since, until (RFC 3339)
+// and order narrow the result.
+func (h *SessionHandler) GetRecords(w http.ResponseWriter, r *http.Request) {
+	sessionID := sessionIDFromRequest(r)
+	if sessionID == "" {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Session ID required")
+		return
+	}
+
+	values := r.URL.Query()
+	kind := values.Get("type")
+	if kind == "" {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Record type required")
+		return
+	}
+
+	query, err := parseRecordQuery(values)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, err.Error())
+		return
+	}
+
+	page, err := storage.QueryRecords(tenantStore(r.Context(), h.storage), sessionID, kind, query)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, page)
+}
+
+// Search handles full-text search requests over a session's thoughts, mental
+// model conclusions and decision statements. The q parameter holds the search
+// text and limit caps the number of hits.
+func (h *SessionHandler) Search(w http.ResponseWriter, r *http.Request) {
+	sessionID := sessionIDFromRequest(r)
+	if sessionID == "" {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Session ID required")
+		return
+	}
+
+	values := r.URL.Query()
+	limit := 0
+	if raw := values.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid limit")
+			return
+		}
+		limit = n
 	}
-	h.respondWithJSON(w, response)
+
+	hits, err := search.Session(tenantStore(r.Context(), h.storage), sessionID, values.Get("q"), limit)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, map[string]interface{}{
+		"session_id": sessionID,
+		"query":      values.Get("q"),
+		"hits":       hits,
+		"count":      len(hits),
+	})
 }
 
 // Helper methods
 
+// sessionIDFromRequest returns the session ID from the {id} route variable or
+// the session_id query parameter
+func sessionIDFromRequest(r *http.Request) string {
+	if id := mux.Vars(r)["id"]; id != "" {
+		return id
+	}
+	return r.URL.Query().Get("session_id")
+}
+
+// tenantStore scopes the store to the tenant set on the request context by
+// middleware.Tenant, tracing its calls under the request span
+func tenantStore(ctx context.Context, store storage.Store) storage.Store {
+	return storage.WithTracing(ctx, storage.ForTenant(store, storage.TenantFromContext(ctx)))
+}
+
+// parseRecordQuery reads record query options from URL parameters
+func parseRecordQuery(values url.Values) (*storage.Query, error) {
+	query := &storage.Query{Order: values.Get("order")}
+
+	for name, target := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		if raw := values.Get(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %q", name, raw)
+			}
+			*target = n
+		}
+	}
+
+	for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if raw := values.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: expected RFC 3339 time", name)
+			}
+			*target = t
+		}
+	}
+
+	return query, query.Validate()
+}
+
 func (h *SessionHandler) respondWithJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *SessionHandler) respondWithError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+func (h *SessionHandler) respondWithError(w http.ResponseWriter, code apierror.Code, message string) {
+	apierror.Write(w, code, message)
 }
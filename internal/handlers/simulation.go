@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/dominance"
+	"github.com/rainmana/gothink/internal/montecarlo"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// SimulateDecision handles decision simulation requests
+func (h *DecisionHandler) SimulateDecision(w http.ResponseWriter, r *http.Request) {
+	var request api.SimulateDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunDecisionSimulation(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunDecisionSimulation simulates the outcomes of the options of the decision
+// request names, in its session in the tenant of ctx, drawing every option's
+// outcome in each future from the distributions of request or as recorded.
+// The simulation is stored on the decision, and each option's expected value
+// set to its simulated mean. The simulation stops once ctx is done.
+func (h *DecisionHandler) RunDecisionSimulation(ctx context.Context, request api.SimulateDecisionRequest) (*api.SimulateDecisionResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: session_id and decision_id are required")
+	}
+
+	// Set defaults
+	if request.Futures == 0 {
+		request.Futures = 10000
+	}
+	if request.Objective == "" {
+		request.Objective = "maximize"
+	}
+	if request.Alpha == 0 {
+		request.Alpha = 0.05
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	switch {
+	case request.Futures < 0 || request.Futures > 1000000:
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: futures must be from 1 to 1000000")
+	case request.Objective != "maximize" && request.Objective != "minimize":
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: unknown objective %q", request.Objective)
+	case !(request.Alpha > 0 && request.Alpha < 1):
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: alpha must be within (0, 1)")
+	}
+
+	var simulated types.DecisionData
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		for _, o := range request.Outcomes {
+			found := false
+			for i := range decision.Options {
+				if decision.Options[i].Name == o.Option {
+					outcome := types.OutcomeDistribution(o.Outcome)
+					decision.Options[i].Outcome, found = &outcome, true
+				}
+			}
+			if !found {
+				return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: there is no option %s", o.Option)
+			}
+		}
+		if err := simulateDecision(ctx, decision, request); err != nil {
+			return err
+		}
+		simulated = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to simulate decision: %v", err)
+	}
+
+	simulation := simulated.Simulation
+	response := &api.SimulateDecisionResponse{
+		DecisionID: request.DecisionID,
+		Status:     "success",
+		Simulation: api.DecisionSimulation{
+			Futures:        simulation.Futures,
+			Objective:      simulation.Objective,
+			Alpha:          simulation.Alpha,
+			Seed:           simulation.Seed,
+			Options:        make([]api.SimulatedOption, len(simulation.Options)),
+			Recommendation: simulation.Recommendation,
+		},
+	}
+	for i, option := range simulation.Options {
+		response.Simulation.Options[i] = api.SimulatedOption(option)
+	}
+	return response, nil
+}
+
+// outcomeVariable returns the Monte Carlo variable, named outcome, an
+// option's outcome is drawn from
+func outcomeVariable(outcome *types.OutcomeDistribution) montecarlo.Variable {
+	variable := montecarlo.Variable{
+		Name:          "outcome",
+		Distribution:  outcome.Distribution,
+		Mean:          outcome.Mean,
+		StdDev:        outcome.StdDev,
+		Min:           outcome.Min,
+		Mode:          outcome.Mode,
+		Max:           outcome.Max,
+		Alpha:         outcome.Alpha,
+		Beta:          outcome.Beta,
+		Values:        outcome.Values,
+		Probabilities: outcome.Probabilities,
+	}
+	if variable.Distribution == montecarlo.Beta && variable.Min == 0 && variable.Max == 0 {
+		variable.Max = 1
+	}
+	return variable
+}
+
+// simulateDecision simulates the futures of request for the options of
+// decision and stores the simulation on it
+func simulateDecision(ctx context.Context, decision *types.DecisionData, request api.SimulateDecisionRequest) error {
+	if len(decision.Options) == 0 {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: the decision has no options")
+	}
+
+	// Each option's outcomes are drawn from a seed of their own, so that
+	// the options' futures are independent
+	seeds := rand.New(rand.NewSource(request.Seed))
+	outcomes := make([][]float64, len(decision.Options))
+	simulation := &types.DecisionSimulation{
+		Futures:   request.Futures,
+		Objective: request.Objective,
+		Alpha:     request.Alpha,
+		Seed:      request.Seed,
+		Options:   make([]types.SimulatedOption, len(decision.Options)),
+	}
+	for i, option := range decision.Options {
+		if option.Outcome == nil {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: option %s has no outcome distribution", option.Name)
+		}
+		result, err := montecarlo.Simulate(ctx, []montecarlo.Variable{outcomeVariable(option.Outcome)}, func(draws map[string]float64) (float64, error) {
+			return draws["outcome"], nil
+		}, montecarlo.Options{
+			Trials:      request.Futures,
+			Percentiles: []float64{5, 50, 95},
+			Buckets:     1,
+			Parallelism: runtime.GOMAXPROCS(0),
+			Rand:        rand.New(rand.NewSource(seeds.Int63())),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return apierror.Errorf(apierror.CodeOf(err), "Decision simulation cancelled")
+			}
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: option %s: %v", option.Name, err)
+		}
+		tail, err := dominance.Describe(result.Outputs, dominance.Options{Alpha: request.Alpha, Minimize: request.Objective == "minimize"})
+		if err != nil {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid decision simulation: option %s: %v", option.Name, err)
+		}
+		outcomes[i] = result.Outputs
+		simulation.Options[i] = types.SimulatedOption{
+			Option:        option.Name,
+			ExpectedValue: result.Mean,
+			StdDev:        result.StdDev,
+			P5:            result.Percentiles[0].Value,
+			P50:           result.Percentiles[1].Value,
+			P95:           result.Percentiles[2].Value,
+			VaR:           tail.VaR,
+			CVaR:          tail.CVaR,
+		}
+	}
+
+	// In each future the best options share the win
+	better := func(a, b float64) bool { return a > b }
+	if request.Objective == "minimize" {
+		better = func(a, b float64) bool { return a < b }
+	}
+	best := make([]int, 0, len(outcomes))
+	for t := 0; t < request.Futures; t++ {
+		best = best[:0]
+		for i := range outcomes {
+			switch {
+			case len(best) == 0 || better(outcomes[i][t], outcomes[best[0]][t]):
+				best = append(best[:0], i)
+			case outcomes[i][t] == outcomes[best[0]][t]:
+				best = append(best, i)
+			}
+		}
+		for _, i := range best {
+			simulation.Options[i].ProbabilityBest += 1 / float64(len(best)) / float64(request.Futures)
+		}
+	}
+
+	for i := range decision.Options {
+		value := simulation.Options[i].ExpectedValue
+		decision.Options[i].ExpectedValue = &value
+	}
+	sort.SliceStable(simulation.Options, func(a, b int) bool {
+		return simulation.Options[a].ProbabilityBest > simulation.Options[b].ProbabilityBest
+	})
+	top := simulation.Options[0]
+	simulation.Recommendation = fmt.Sprintf("%s is best in %.1f%% of %d simulated futures, with an expected value of %.4g, a 90%% range of %.4g to %.4g and a CVaR at %g%% of %.4g",
+		top.Option, 100*top.ProbabilityBest, request.Futures, top.ExpectedValue, top.P5, top.P95, 100*request.Alpha, top.CVaR)
+	if len(simulation.Options) > 1 && simulation.Options[1].ProbabilityBest > 0 {
+		runnerUp := simulation.Options[1]
+		simulation.Recommendation += fmt.Sprintf("; %s is best in %.1f%%", runnerUp.Option, 100*runnerUp.ProbabilityBest)
+	}
+	decision.Simulation = simulation
+	if len(decision.Ranking) == 0 {
+		decision.Recommendation = simulation.Recommendation
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/stakeholder"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// StakeholderAnalysis handles stakeholder analysis requests
+func (h *DecisionHandler) StakeholderAnalysis(w http.ResponseWriter, r *http.Request) {
+	var request api.StakeholderAnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunStakeholderAnalysis(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunStakeholderAnalysis analyzes the stakeholder profiles of request, or as
+// recorded, for the decision request names, in its session in the tenant of
+// ctx, and stores the analysis on the decision
+func (h *DecisionHandler) RunStakeholderAnalysis(ctx context.Context, request api.StakeholderAnalysisRequest) (*api.StakeholderAnalysisResponse, error) {
+	if request.SessionID == "" || request.DecisionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid stakeholder analysis: session_id and decision_id are required")
+	}
+
+	var analyzed types.DecisionData
+	err := tenantStore(ctx, h.storage).UpdateDecision(request.SessionID, request.DecisionID, func(decision *types.DecisionData) error {
+		if request.Stakeholders != nil {
+			decision.StakeholderProfiles = DecisionStakeholders(request.Stakeholders)
+		}
+		if len(decision.StakeholderProfiles) == 0 {
+			return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid stakeholder analysis: the decision has no stakeholder profiles")
+		}
+		if err := AnalyzeStakeholders(decision); err != nil {
+			return err
+		}
+		analyzed = *decision
+		return nil
+	})
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to analyze stakeholders: %v", err)
+	}
+
+	return &api.StakeholderAnalysisResponse{
+		DecisionID: request.DecisionID,
+		Status:     "success",
+		Analysis:   *StakeholderAnalysis(analyzed.StakeholderAnalysis),
+	}, nil
+}
+
+// AnalyzeStakeholders places the profiled stakeholders of decision on the
+// influence/interest grid, scores its options by their weighted support and
+// stores the analysis on it, adding the profiles' names to its stakeholders.
+// The recommendation names the best supported option unless the decision is
+// ranked by its scores or has a risk assessment. A decision without
+// stakeholder profiles is left as it is.
+func AnalyzeStakeholders(decision *types.DecisionData) error {
+	if len(decision.StakeholderProfiles) == 0 {
+		return nil
+	}
+	stakeholders := make([]stakeholder.Stakeholder, len(decision.StakeholderProfiles))
+	for k, s := range decision.StakeholderProfiles {
+		stakeholders[k] = stakeholder.Stakeholder{Name: s.Name, Influence: s.Influence, Interest: s.Interest}
+		for _, p := range s.Preferences {
+			stakeholders[k].Preferences = append(stakeholders[k].Preferences, stakeholder.Preference(p))
+		}
+	}
+	options := make([]string, len(decision.Options))
+	for i, option := range decision.Options {
+		options[i] = option.Name
+	}
+	result, err := stakeholder.Analyze(stakeholders, options)
+	if err != nil {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid stakeholder analysis: %v", err)
+	}
+
+	analysis := &types.StakeholderAnalysis{
+		Stakeholders: make([]types.PositionedStakeholder, len(result.Stakeholders)),
+		Grid:         types.StakeholderGrid(result.Grid),
+		Options:      make([]types.StakeholderScore, len(result.Options)),
+	}
+	for k, s := range result.Stakeholders {
+		analysis.Stakeholders[k] = types.PositionedStakeholder{
+			Name:      s.Name,
+			Influence: s.Influence,
+			Interest:  s.Interest,
+			Quadrant:  s.Quadrant,
+			Weight:    s.Weight,
+		}
+		named := false
+		for _, name := range decision.Stakeholders {
+			named = named || name == s.Name
+		}
+		if !named {
+			decision.Stakeholders = append(decision.Stakeholders, s.Name)
+		}
+	}
+	for i, option := range result.Options {
+		analysis.Options[i] = types.StakeholderScore(option)
+	}
+
+	best := result.Options[0]
+	analysis.Recommendation = fmt.Sprintf("%s has the most stakeholder support, a weighted score of %.3g from -1 to 1, backed by %s and opposed by %s",
+		best.Option, best.Score, listOrNone(best.Supporters), listOrNone(best.Opponents))
+	if len(result.Grid.ManageClosely) > 0 {
+		analysis.Recommendation += fmt.Sprintf("; manage %s closely", strings.Join(result.Grid.ManageClosely, ", "))
+	}
+	decision.StakeholderAnalysis = analysis
+	if len(decision.Ranking) == 0 && decision.RiskAssessment == nil {
+		decision.Recommendation = analysis.Recommendation
+	}
+	return nil
+}
+
+// listOrNone joins names, or says there are none
+func listOrNone(names []string) string {
+	if len(names) == 0 {
+		return "no one"
+	}
+	return strings.Join(names, ", ")
+}
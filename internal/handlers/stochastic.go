@@ -1,63 +1,112 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
 	"net/http"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/abtest"
+	"github.com/rainmana/gothink/internal/anneal"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/bandit"
+	"github.com/rainmana/gothink/internal/bayesopt"
+	"github.com/rainmana/gothink/internal/bootstrap"
+	"github.com/rainmana/gothink/internal/convergence"
+	"github.com/rainmana/gothink/internal/expr"
+	"github.com/rainmana/gothink/internal/hmm"
+	"github.com/rainmana/gothink/internal/mcts"
+	"github.com/rainmana/gothink/internal/mdp"
+	"github.com/rainmana/gothink/internal/montecarlo"
+	"github.com/rainmana/gothink/internal/particle"
+	"github.com/rainmana/gothink/internal/queueing"
 	"github.com/rainmana/gothink/internal/storage"
 	"github.com/rainmana/gothink/internal/types"
 )
 
 // StochasticHandler handles stochastic algorithm operations
 type StochasticHandler struct {
-	storage *storage.Storage
+	storage storage.Store
 	logger  *logrus.Logger
 }
 
 // NewStochasticHandler creates a new stochastic handler
-func NewStochasticHandler(storage *storage.Storage, logger *logrus.Logger) *StochasticHandler {
+func NewStochasticHandler(storage storage.Store, logger *logrus.Logger) *StochasticHandler {
 	return &StochasticHandler{
 		storage: storage,
 		logger:  logger,
 	}
 }
 
-// MarkovDecisionProcess handles MDP requests
-func (h *StochasticHandler) MarkovDecisionProcess(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID     string   `json:"session_id"`
-		Problem       string   `json:"problem"`
-		States        int      `json:"states"`
-		Actions       []string `json:"actions"`
-		Gamma         float64  `json:"gamma"`
-		LearningRate  float64  `json:"learning_rate,omitempty"`
-		Epsilon       float64  `json:"epsilon,omitempty"`
-		MaxIterations int      `json:"max_iterations,omitempty"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
+// RunMDP solves the MDP of request and records it in its session in the
+// tenant of ctx. The solver stops once ctx is done.
+func (h *StochasticHandler) RunMDP(ctx context.Context, request api.MDPRequest) (*api.MDPResponse, error) {
+	return h.RunMDPWithProgress(ctx, request, nil)
+}
 
+// RunMDPWithProgress runs RunMDP, sending progress the greedy policy reached
+// every stream_interval iterations when request streams
+func (h *StochasticHandler) RunMDPWithProgress(ctx context.Context, request api.MDPRequest, progress ProgressFunc) (*api.MDPResponse, error) {
 	// Set defaults
-	if request.LearningRate == 0 {
-		request.LearningRate = 0.1
+	if request.Method == "" {
+		request.Method = mdp.ValueIteration
 	}
-	if request.Epsilon == 0 {
-		request.Epsilon = 0.1
+	if request.Tolerance == 0 {
+		request.Tolerance = 1e-6
 	}
 	if request.MaxIterations == 0 {
 		request.MaxIterations = 1000
 	}
+	if request.StreamInterval == 0 {
+		request.StreamInterval = 10
+	}
+
+	model, err := mdpModel(request.Transitions, nil, request.States, request.Actions)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid MDP: %v", err)
+	}
+
+	opts := mdp.Options{
+		Gamma:         request.Gamma,
+		Tolerance:     request.Tolerance,
+		MaxIterations: request.MaxIterations,
+		TimeLimit:     time.Duration(request.TimeLimit) * time.Second,
+	}
+	if request.Stream && progress != nil {
+		opts.ProgressInterval = request.StreamInterval
+		opts.Progress = func(solution *mdp.Solution) {
+			progress(api.StreamUpdate{
+				Algorithm: "mdp",
+				Iteration: solution.Iterations,
+				Total:     request.MaxIterations,
+				Summary:   fmt.Sprintf("Policy after %d iterations by %s, residual %.4g", solution.Iterations, solution.Method, solution.Residual),
+				Policy:    solution.Policy,
+				Residual:  solution.Residual,
+			})
+		}
+	}
+
+	// Solve the MDP, stopping if the client goes away
+	start := time.Now()
+	solution, err := mdp.Solve(ctx, model, request.Method, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "MDP solver cancelled")
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid MDP: %v", err)
+	}
 
-	// Simulate MDP algorithm (simplified implementation)
-	policy, valueFunction, qValues := h.simulateMDP(request.States, request.Actions, request.Gamma, request.LearningRate, request.Epsilon, request.MaxIterations)
+	summary := fmt.Sprintf("Optimal policy over %d states with discount factor %.2f by %s", len(model.States()), request.Gamma, solution.Method)
+	if !solution.Converged {
+		summary = fmt.Sprintf("Best policy over %d states with discount factor %.2f by %s; not converged after %d iterations", len(model.States()), request.Gamma, solution.Method, solution.Iterations)
+	}
 
 	// Create MDP data
 	mdpData := &types.MDPData{
@@ -66,69 +115,232 @@ func (h *StochasticHandler) MarkovDecisionProcess(w http.ResponseWriter, r *http
 			Algorithm: "mdp",
 			Problem:   request.Problem,
 			Parameters: map[string]interface{}{
-				"states":         request.States,
-				"actions":        request.Actions,
+				"states":         model.States(),
+				"actions":        model.Actions(),
+				"transitions":    len(request.Transitions),
 				"gamma":          request.Gamma,
-				"learning_rate":  request.LearningRate,
-				"epsilon":        request.Epsilon,
+				"method":         request.Method,
+				"tolerance":      request.Tolerance,
 				"max_iterations": request.MaxIterations,
+				"time_limit":     request.TimeLimit,
 			},
-			Result:     fmt.Sprintf("Optimized policy over %d states", request.States),
-			Confidence: 0.85,
-			Iterations: request.MaxIterations,
-			Converged:  true,
-			CreatedAt:  time.Now(),
+			Result:         summary,
+			Iterations:     solution.Iterations,
+			Converged:      solution.Converged,
+			StoppingReason: solution.StoppingReason,
+			Rerun:          rerunOf("mdp", request),
+			CreatedAt:      time.Now(),
 		},
-		Policy:        policy,
-		ValueFunction: valueFunction,
-		QValues:       qValues,
+		Policy:        solution.Policy,
+		ValueFunction: solution.Values,
+		QValues:       solution.QValues,
+		Convergence:   convergenceOf(solution.Iterations, solution.Converged, solution.StoppingReason, solution.Residuals, time.Since(start)),
 	}
+	mdpData.Convergence.Method = solution.Method
+	mdpData.Convergence.Tolerance = request.Tolerance
+	value, method := mdpConfidence(solution, request.Gamma)
+	confidence := setConfidence(&mdpData.StochasticAlgorithmData, value, method)
 
 	// Add to storage
-	if err := h.storage.AddStochasticAlgorithm(request.SessionID, &mdpData.StochasticAlgorithmData); err != nil {
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &mdpData.StochasticAlgorithmData, mdpData); err != nil {
 		h.logger.WithError(err).Error("Failed to add MDP data")
-		h.respondWithError(w, "Failed to add MDP data", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add MDP data")
 	}
 
-	response := map[string]interface{}{
-		"algorithm_id": mdpData.ID,
-		"status":       "success",
-		"summary":      fmt.Sprintf("Optimized policy over %d states with discount factor %.2f", request.States, request.Gamma),
-		"has_result":   true,
-		"converged":    mdpData.Converged,
-		"iterations":   mdpData.Iterations,
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &mdpData.StochasticAlgorithmData, traceConvergencePlot, residualPlot(solution.Residuals)); err != nil {
+			return nil, err
+		}
 	}
 
-	h.respondWithJSON(w, response)
+	return &api.MDPResponse{
+		AlgorithmID:   mdpData.ID,
+		Status:        "success",
+		Summary:       summary,
+		HasResult:     true,
+		Converged:     mdpData.Converged,
+		Iterations:    mdpData.Iterations,
+		Policy:        mdpData.Policy,
+		ValueFunction: mdpData.ValueFunction,
+		QValues:       mdpData.QValues,
+		Convergence:   api.Convergence(*mdpData.Convergence),
+		Confidence:    confidence,
+		TraceID:       traceID,
+	}, nil
 }
 
-// MonteCarloTreeSearch handles MCTS requests
-func (h *StochasticHandler) MonteCarloTreeSearch(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID           string  `json:"session_id"`
-		Problem             string  `json:"problem"`
-		Simulations         int     `json:"simulations"`
-		ExplorationConstant float64 `json:"exploration_constant"`
-		MaxDepth            int     `json:"max_depth,omitempty"`
-		TimeLimit           int     `json:"time_limit,omitempty"`
+// convergenceOf reports how a run ended from its iterations, whether it
+// converged, why it stopped, its residuals, the last being its residual, and
+// how long it took
+func convergenceOf(iterations int, converged bool, reason string, residuals []float64, elapsed time.Duration) *types.Convergence {
+	c := &types.Convergence{
+		Iterations:     iterations,
+		Converged:      converged,
+		StoppingReason: reason,
+		Residuals:      append([]float64{}, residuals...),
+		ElapsedSeconds: elapsed.Seconds(),
+	}
+	if len(residuals) > 0 {
+		c.Residual = residuals[len(residuals)-1]
 	}
+	return c
+}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
-		return
+// mdpModel returns the model of transitions, or, without transitions, the
+// model estimated from samples, checking it against the state count and
+// actions a request declares
+func mdpModel(transitions []api.MDPTransition, samples []api.QLearningSample, states int, actions []string) (*mdp.Model, error) {
+	allowed := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		allowed[action] = true
+	}
+	checkAction := func(kind string, i int, action string) error {
+		if len(allowed) > 0 && !allowed[action] {
+			return fmt.Errorf("%s %d takes action %s, which is not one of actions", kind, i, action)
+		}
+		return nil
 	}
 
+	var model *mdp.Model
+	var err error
+	if len(transitions) > 0 || len(samples) == 0 {
+		modelTransitions := make([]mdp.Transition, len(transitions))
+		for i, t := range transitions {
+			if err := checkAction("transition", i, t.Action); err != nil {
+				return nil, err
+			}
+			modelTransitions[i] = mdp.Transition(t)
+		}
+		model, err = mdp.NewModel(modelTransitions)
+	} else {
+		modelSamples := make([]mdp.Sample, len(samples))
+		for i, s := range samples {
+			if err := checkAction("sample", i, s.Action); err != nil {
+				return nil, err
+			}
+			modelSamples[i] = mdp.Sample(s)
+		}
+		model, err = mdp.ModelFromSamples(modelSamples)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if states > 0 && states != len(model.States()) {
+		return nil, fmt.Errorf("states is %d but the environment has %d states", states, len(model.States()))
+	}
+	return model, nil
+}
+
+// RunMCTS searches the game of request with UCT and records the search in
+// its session in the tenant of ctx. The search stops once ctx is done.
+func (h *StochasticHandler) RunMCTS(ctx context.Context, request api.MCTSRequest) (*api.MCTSResponse, error) {
+	return h.RunMCTSWithProgress(ctx, request, nil)
+}
+
+// RunMCTSWithProgress runs RunMCTS, sending progress the most visited move
+// every stream_interval simulations when request streams
+func (h *StochasticHandler) RunMCTSWithProgress(ctx context.Context, request api.MCTSRequest, progress ProgressFunc) (*api.MCTSResponse, error) {
 	// Set defaults
+	if request.Simulations == 0 {
+		request.Simulations = 1000
+	}
+	if request.ExplorationConstant == 0 {
+		request.ExplorationConstant = math.Sqrt2
+	}
 	if request.MaxDepth == 0 {
 		request.MaxDepth = 10
 	}
 	if request.TimeLimit == 0 {
 		request.TimeLimit = 30
 	}
+	if request.StreamInterval == 0 {
+		request.StreamInterval = max(1, request.Simulations/10)
+	}
+	if request.Parallelism == 0 {
+		request.Parallelism = 1
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	if request.Parallelism > 64 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid search: at most 64 trees at once")
+	}
 
-	// Simulate MCTS algorithm
-	bestAction, treeStats := h.simulateMCTS(request.Simulations, request.ExplorationConstant, request.MaxDepth)
+	states := make([]mcts.State, len(request.States))
+	for i, state := range request.States {
+		states[i] = mcts.State{Name: state.Name, Player: state.Player, Reward: state.Reward}
+		for _, move := range state.Moves {
+			states[i].Moves = append(states[i].Moves, mcts.Move{Name: move.Move, NextState: move.NextState})
+		}
+	}
+	game, err := mcts.NewGame(states)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid game: %v", err)
+	}
+
+	opts := mcts.Options{
+		Root:                request.RootState,
+		Simulations:         request.Simulations,
+		ExplorationConstant: request.ExplorationConstant,
+		MaxDepth:            request.MaxDepth,
+		TimeLimit:           time.Duration(request.TimeLimit) * time.Second,
+		Parallelism:         request.Parallelism,
+		Rand:                rand.New(rand.NewSource(request.Seed)),
+	}
+	if request.Trace {
+		opts.TreeNodes = traceTreeNodes
+	}
+	if request.Stream && progress != nil {
+		opts.ProgressInterval = request.StreamInterval
+		opts.Progress = func(result *mcts.Result) {
+			update := api.StreamUpdate{
+				Algorithm:  "mcts",
+				Iteration:  result.Simulations,
+				Total:      request.Simulations,
+				Summary:    fmt.Sprintf("Best move %s after %d simulations", result.BestMove, result.Simulations),
+				BestAction: result.BestMove,
+			}
+			for _, action := range result.Actions {
+				if action.Move == result.BestMove {
+					update.BestValue = &action.Q
+				}
+			}
+			if n := len(result.Residuals); n > 0 {
+				update.Residual = result.Residuals[n-1]
+			}
+			progress(update)
+		}
+	}
+
+	// Search the game, stopping if the client goes away
+	start := time.Now()
+	result, err := mcts.Search(ctx, game, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "MCTS cancelled")
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid search: %v", err)
+	}
+
+	summary := fmt.Sprintf("Best move %s after %d simulations with exploration constant %.2f", result.BestMove, result.Simulations, request.ExplorationConstant)
+	treeStats := map[string]interface{}{
+		"nodes": result.Nodes,
+		"depth": result.Depth,
+		"visits": map[string]int{
+			"root": result.Simulations,
+		},
+	}
+	actionStats := make([]types.MCTSActionStats, len(result.Actions))
+	var bestQ *float64
+	for i, action := range result.Actions {
+		actionStats[i] = types.MCTSActionStats(action)
+		if action.Move == result.BestMove {
+			bestQ = &actionStats[i].Q
+		}
+	}
 
 	// Create MCTS data
 	mctsData := &types.MCTSData{
@@ -137,58 +349,75 @@ func (h *StochasticHandler) MonteCarloTreeSearch(w http.ResponseWriter, r *http.
 			Algorithm: "mcts",
 			Problem:   request.Problem,
 			Parameters: map[string]interface{}{
+				"root_state":           request.RootState,
+				"states":               len(request.States),
 				"simulations":          request.Simulations,
 				"exploration_constant": request.ExplorationConstant,
 				"max_depth":            request.MaxDepth,
 				"time_limit":           request.TimeLimit,
+				"parallelism":          request.Parallelism,
+				"seed":                 request.Seed,
 			},
-			Result:     fmt.Sprintf("Explored %d paths with exploration constant %.2f", request.Simulations, request.ExplorationConstant),
-			Confidence: 0.80,
-			Iterations: request.Simulations,
-			Converged:  true,
-			CreatedAt:  time.Now(),
+			Result:         summary,
+			Iterations:     result.Simulations,
+			Converged:      result.Converged,
+			StoppingReason: result.StoppingReason,
+			Value:          bestQ,
+			Rerun:          rerunOf("mcts", request),
+			CreatedAt:      time.Now(),
 		},
-		BestAction: bestAction,
-		TreeStats:  treeStats,
+		BestAction:         result.BestMove,
+		ActionStats:        actionStats,
+		PrincipalVariation: result.PrincipalVariation,
+		TreeStats:          treeStats,
+		Convergence:        convergenceOf(result.Simulations, result.Converged, result.StoppingReason, result.Residuals, time.Since(start)),
 	}
+	confidence := setConfidence(&mctsData.StochasticAlgorithmData, result.Confidence, confidenceRolloutBootstrap)
 
 	// Add to storage
-	if err := h.storage.AddStochasticAlgorithm(request.SessionID, &mctsData.StochasticAlgorithmData); err != nil {
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &mctsData.StochasticAlgorithmData, mctsData); err != nil {
 		h.logger.WithError(err).Error("Failed to add MCTS data")
-		h.respondWithError(w, "Failed to add MCTS data", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add MCTS data")
 	}
 
-	response := map[string]interface{}{
-		"algorithm_id": mctsData.ID,
-		"status":       "success",
-		"summary":      fmt.Sprintf("Explored %d paths with exploration constant %.2f", request.Simulations, request.ExplorationConstant),
-		"has_result":   true,
-		"best_action":  bestAction,
-		"tree_stats":   treeStats,
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &mctsData.StochasticAlgorithmData, traceSearchTree, searchTree(result.Tree)); err != nil {
+			return nil, err
+		}
 	}
 
-	h.respondWithJSON(w, response)
-}
-
-// MultiArmedBandit handles multi-armed bandit requests
-func (h *StochasticHandler) MultiArmedBandit(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID string  `json:"session_id"`
-		Problem   string  `json:"problem"`
-		Arms      int     `json:"arms"`
-		Strategy  string  `json:"strategy"`
-		Epsilon   float64 `json:"epsilon,omitempty"`
-		Alpha     float64 `json:"alpha,omitempty"`
-		Beta      float64 `json:"beta,omitempty"`
+	response := &api.MCTSResponse{
+		AlgorithmID:        mctsData.ID,
+		Status:             "success",
+		Summary:            summary,
+		HasResult:          true,
+		BestAction:         result.BestMove,
+		Actions:            make([]api.MCTSActionStats, len(actionStats)),
+		PrincipalVariation: result.PrincipalVariation,
+		Simulations:        result.Simulations,
+		TreeStats:          treeStats,
+		Convergence:        api.Convergence(*mctsData.Convergence),
+		Confidence:         confidence,
+		TraceID:            traceID,
 	}
-
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
-		return
+	for i, action := range actionStats {
+		response.Actions[i] = api.MCTSActionStats(action)
 	}
+	return response, nil
+}
 
+// RunBandit runs the bandit of request and records it in its session in the
+// tenant of ctx
+func (h *StochasticHandler) RunBandit(ctx context.Context, request api.BanditRequest) (*api.BanditResponse, error) {
 	// Set defaults
+	if request.Strategy == "" {
+		request.Strategy = bandit.EpsilonGreedy
+	}
+	if request.Steps == 0 {
+		request.Steps = 1000
+	}
 	if request.Epsilon == 0 {
 		request.Epsilon = 0.1
 	}
@@ -198,9 +427,68 @@ func (h *StochasticHandler) MultiArmedBandit(w http.ResponseWriter, r *http.Requ
 	if request.Beta == 0 {
 		request.Beta = 1.0
 	}
+	if request.DriftMode == "" {
+		request.DriftMode = bandit.Stationary
+	}
+	if request.Discount == 0 {
+		request.Discount = 0.99
+	}
+	if request.Window == 0 {
+		request.Window = 100
+	}
+	if request.ChangeThreshold == 0 {
+		request.ChangeThreshold = 12
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
 
-	// Simulate bandit algorithm
-	armStats, selectedArm := h.simulateBandit(request.Arms, request.Strategy, request.Epsilon, request.Alpha, request.Beta)
+	arms := make([]bandit.Arm, len(request.Arms))
+	for i, arm := range request.Arms {
+		arms[i] = bandit.Arm{Name: arm.Name, Distribution: arm.Distribution, Mean: arm.Mean, StdDev: arm.StdDev, Observed: arm.ObservedRewards}
+		for _, change := range arm.Changes {
+			arms[i].Changes = append(arms[i].Changes, bandit.Change(change))
+		}
+	}
+
+	// Play the bandit, stopping if the client goes away
+	start := time.Now()
+	result, err := bandit.Run(ctx, arms, bandit.Options{
+		Strategy:        request.Strategy,
+		Steps:           request.Steps,
+		Epsilon:         request.Epsilon,
+		Alpha:           request.Alpha,
+		Beta:            request.Beta,
+		DriftMode:       request.DriftMode,
+		Discount:        request.Discount,
+		Window:          request.Window,
+		ChangeThreshold: request.ChangeThreshold,
+		TimeLimit:       time.Duration(request.TimeLimit) * time.Second,
+		Rand:            rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Bandit cancelled")
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid bandit: %v", err)
+	}
+
+	armStats := make([]types.ArmStatistics, len(result.Arms))
+	for i, arm := range result.Arms {
+		armStats[i] = types.ArmStatistics(arm)
+	}
+	curve := make([]types.RegretPoint, len(result.RegretCurve))
+	for i, point := range result.RegretCurve {
+		curve[i] = types.RegretPoint(point)
+	}
+	changes := make([]types.ChangePoint, len(result.ChangePoints))
+	for i, change := range result.ChangePoints {
+		changes[i] = types.ChangePoint(change)
+	}
+	summary := fmt.Sprintf("Selected arm %d after %d pulls with %s strategy, regret %.2f", result.SelectedArm, result.Steps, request.Strategy, result.Regret)
+	if len(changes) > 0 {
+		summary += fmt.Sprintf(", %d change points detected", len(changes))
+	}
 
 	// Create bandit data
 	banditData := &types.BanditData{
@@ -209,64 +497,209 @@ func (h *StochasticHandler) MultiArmedBandit(w http.ResponseWriter, r *http.Requ
 			Algorithm: "bandit",
 			Problem:   request.Problem,
 			Parameters: map[string]interface{}{
-				"arms":     request.Arms,
-				"strategy": request.Strategy,
-				"epsilon":  request.Epsilon,
-				"alpha":    request.Alpha,
-				"beta":     request.Beta,
+				"arms":             len(request.Arms),
+				"strategy":         request.Strategy,
+				"steps":            request.Steps,
+				"epsilon":          request.Epsilon,
+				"alpha":            request.Alpha,
+				"beta":             request.Beta,
+				"drift_mode":       request.DriftMode,
+				"discount":         request.Discount,
+				"window":           request.Window,
+				"change_threshold": request.ChangeThreshold,
+				"time_limit":       request.TimeLimit,
+				"seed":             request.Seed,
 			},
-			Result:     fmt.Sprintf("Selected optimal arm with %s strategy (ε=%.2f)", request.Strategy, request.Epsilon),
-			Confidence: 0.75,
-			Iterations: 1000,
-			Converged:  true,
-			CreatedAt:  time.Now(),
+			Result:         summary,
+			Iterations:     result.Steps,
+			Converged:      result.Converged,
+			StoppingReason: result.StoppingReason,
+			Value:          &armStats[result.SelectedArm].AverageReward,
+			Rerun:          rerunOf("bandit", request),
+			CreatedAt:      time.Now(),
 		},
-		ArmStats:    armStats,
-		SelectedArm: selectedArm,
+		ArmStats:     armStats,
+		SelectedArm:  result.SelectedArm,
+		OptimalArm:   result.OptimalArm,
+		Regret:       result.Regret,
+		RegretCurve:  curve,
+		ChangePoints: changes,
+		Convergence:  convergenceOf(result.Steps, result.Converged, result.StoppingReason, result.Residuals, time.Since(start)),
 	}
+	method := confidenceGaussianTail
+	if result.Bounded {
+		method = confidenceHoeffding
+	}
+	confidence := setConfidence(&banditData.StochasticAlgorithmData, result.Confidence, method)
 
 	// Add to storage
-	if err := h.storage.AddStochasticAlgorithm(request.SessionID, &banditData.StochasticAlgorithmData); err != nil {
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &banditData.StochasticAlgorithmData, banditData); err != nil {
 		h.logger.WithError(err).Error("Failed to add bandit data")
-		h.respondWithError(w, "Failed to add bandit data", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add bandit data")
 	}
 
-	response := map[string]interface{}{
-		"algorithm_id": banditData.ID,
-		"status":       "success",
-		"summary":      fmt.Sprintf("Selected optimal arm with %s strategy (ε=%.2f)", request.Strategy, request.Epsilon),
-		"has_result":   true,
-		"selected_arm": selectedArm,
-		"arm_stats":    armStats,
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &banditData.StochasticAlgorithmData, traceConvergencePlot, regretPlot(curve)); err != nil {
+			return nil, err
+		}
 	}
 
-	h.respondWithJSON(w, response)
+	response := &api.BanditResponse{
+		AlgorithmID:  banditData.ID,
+		Status:       "success",
+		Summary:      summary,
+		HasResult:    true,
+		SelectedArm:  result.SelectedArm,
+		OptimalArm:   result.OptimalArm,
+		ArmStats:     armStatistics(armStats),
+		TotalReward:  result.TotalReward,
+		Regret:       result.Regret,
+		RegretCurve:  make([]api.RegretPoint, len(curve)),
+		ChangePoints: make([]api.ChangePoint, len(changes)),
+		Convergence:  api.Convergence(*banditData.Convergence),
+		Confidence:   confidence,
+		TraceID:      traceID,
+	}
+	for i, point := range curve {
+		response.RegretCurve[i] = api.RegretPoint(point)
+	}
+	for i, change := range changes {
+		response.ChangePoints[i] = api.ChangePoint(change)
+	}
+	return response, nil
+}
+
+// RunBayesianOptimization runs the optimization of request and records it in
+// its session in the tenant of ctx. The optimization stops once ctx is done.
+func (h *StochasticHandler) RunBayesianOptimization(ctx context.Context, request api.BayesianOptimizationRequest) (*api.BayesianOptimizationResponse, error) {
+	return h.RunBayesianOptimizationWithProgress(ctx, request, nil)
 }
 
-// BayesianOptimization handles Bayesian optimization requests
-func (h *StochasticHandler) BayesianOptimization(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID           string  `json:"session_id"`
-		Problem             string  `json:"problem"`
-		AcquisitionFunction string  `json:"acquisition_function"`
-		Kernel              string  `json:"kernel"`
-		Iterations          int     `json:"iterations"`
-		ExplorationWeight   float64 `json:"exploration_weight,omitempty"`
+// RunBayesianOptimizationWithProgress runs RunBayesianOptimization, sending
+// progress the best evaluation so far every stream_interval evaluations when
+// request streams
+func (h *StochasticHandler) RunBayesianOptimizationWithProgress(ctx context.Context, request api.BayesianOptimizationRequest, progress ProgressFunc) (*api.BayesianOptimizationResponse, error) {
+	// Set defaults
+	if request.Goal == "" {
+		request.Goal = "maximize"
+	}
+	if request.AcquisitionFunction == "" {
+		request.AcquisitionFunction = bayesopt.ExpectedImprovement
+	}
+	if request.Kernel == "" {
+		request.Kernel = bayesopt.Matern52
+	}
+	if request.Iterations == 0 {
+		request.Iterations = 20
+	}
+	if request.InitialPoints == 0 {
+		request.InitialPoints = 3
+	}
+	if request.ExplorationWeight == 0 {
+		request.ExplorationWeight = 0.01
+		if request.AcquisitionFunction == bayesopt.UpperConfidenceBound {
+			request.ExplorationWeight = 2
+		}
+	}
+	if request.LengthScale == 0 {
+		request.LengthScale = 0.2
+	}
+	if request.Noise == 0 {
+		request.Noise = 1e-6
+	}
+	if request.Tolerance == 0 {
+		request.Tolerance = 1e-6
+	}
+	if request.Patience == 0 {
+		request.Patience = 5
+	}
+	if request.StreamInterval == 0 {
+		request.StreamInterval = 1
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	if request.Goal != "maximize" && request.Goal != "minimize" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid goal %q", request.Goal)
+	}
+
+	parameters := make([]bayesopt.Parameter, len(request.Parameters))
+	names := make([]string, len(request.Parameters))
+	for i, p := range request.Parameters {
+		parameters[i] = bayesopt.Parameter(p)
+		names[i] = p.Name
+	}
+	observed := make([]bayesopt.Observation, len(request.History))
+	for i, o := range request.History {
+		observed[i] = bayesopt.Observation(o)
+	}
+	var objective bayesopt.Objective
+	if request.Objective != "" {
+		compiled, err := expr.Compile(request.Objective, names)
+		if err != nil {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid objective: %v", err)
+		}
+		objective = bayesopt.Objective(compiled)
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
-		return
+	opts := bayesopt.Options{
+		Kernel:            request.Kernel,
+		Acquisition:       request.AcquisitionFunction,
+		ExplorationWeight: request.ExplorationWeight,
+		LengthScale:       request.LengthScale,
+		Noise:             request.Noise,
+		Minimize:          request.Goal == "minimize",
+		Iterations:        request.Iterations,
+		InitialPoints:     request.InitialPoints,
+		Candidates:        1000,
+		Tolerance:         request.Tolerance,
+		Patience:          request.Patience,
+		StopAtPlateau:     request.StopAtPlateau,
+		TimeLimit:         time.Duration(request.TimeLimit) * time.Second,
+		Rand:              rand.New(rand.NewSource(request.Seed)),
+	}
+	if request.Stream && progress != nil {
+		opts.ProgressInterval = request.StreamInterval
+		opts.Progress = func(result *bayesopt.Result) {
+			update := api.StreamUpdate{
+				Algorithm:      "bayesian",
+				Iteration:      len(result.History),
+				Total:          request.Iterations,
+				Summary:        fmt.Sprintf("Best value %.4g after %d evaluations", result.BestValue, len(result.History)),
+				BestParameters: result.BestParameters,
+				BestValue:      &result.BestValue,
+			}
+			if n := len(result.Residuals); n > 0 {
+				update.Residual = result.Residuals[n-1]
+			}
+			progress(update)
+		}
 	}
 
-	// Set defaults
-	if request.ExplorationWeight == 0 {
-		request.ExplorationWeight = 0.1
+	// Optimize, stopping if the client goes away
+	start := time.Now()
+	result, err := bayesopt.Optimize(ctx, parameters, observed, objective, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Bayesian optimization cancelled")
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid optimization: %v", err)
 	}
 
-	// Simulate Bayesian optimization
-	optimizationHistory, bestParameters, bestValue := h.simulateBayesianOptimization(request.Iterations, request.AcquisitionFunction, request.Kernel, request.ExplorationWeight)
+	history := make([]types.OptimizationStep, len(result.History))
+	for i, step := range result.History {
+		history[i] = types.OptimizationStep{
+			Iteration:   step.Iteration,
+			Parameters:  step.Parameters,
+			Value:       step.Value,
+			Random:      step.Random,
+			Acquisition: step.Acquisition,
+			Predicted:   types.GPPosterior(step.Predicted),
+		}
+	}
+	summary := fmt.Sprintf("Best value %.4g after %d evaluations with %s acquisition and %s kernel", result.BestValue, len(history), request.AcquisitionFunction, request.Kernel)
 
 	// Create Bayesian optimization data
 	bayesianData := &types.BayesianOptimizationData{
@@ -275,65 +708,199 @@ func (h *StochasticHandler) BayesianOptimization(w http.ResponseWriter, r *http.
 			Algorithm: "bayesian",
 			Problem:   request.Problem,
 			Parameters: map[string]interface{}{
+				"parameters":           len(request.Parameters),
+				"objective":            request.Objective,
+				"observed":             len(request.History),
+				"goal":                 request.Goal,
 				"acquisition_function": request.AcquisitionFunction,
 				"kernel":               request.Kernel,
 				"iterations":           request.Iterations,
+				"initial_points":       request.InitialPoints,
 				"exploration_weight":   request.ExplorationWeight,
+				"length_scale":         request.LengthScale,
+				"noise":                request.Noise,
+				"tolerance":            request.Tolerance,
+				"patience":             request.Patience,
+				"stop_at_plateau":      request.StopAtPlateau,
+				"time_limit":           request.TimeLimit,
+				"seed":                 request.Seed,
 			},
-			Result:     fmt.Sprintf("Optimized objective with %s acquisition", request.AcquisitionFunction),
-			Confidence: 0.90,
-			Iterations: request.Iterations,
-			Converged:  true,
-			CreatedAt:  time.Now(),
+			Result:         summary,
+			Iterations:     len(history),
+			Converged:      result.Converged,
+			StoppingReason: result.StoppingReason,
+			Value:          &result.BestValue,
+			Rerun:          rerunOf("bayesian", request),
+			CreatedAt:      time.Now(),
 		},
-		OptimizationHistory: optimizationHistory,
-		BestParameters:      bestParameters,
-		BestValue:           bestValue,
+		OptimizationHistory: history,
+		BestParameters:      result.BestParameters,
+		BestValue:           result.BestValue,
+		BestPosterior:       types.GPPosterior(result.BestPosterior),
+		NextParameters:      result.Next,
+		Convergence:         convergenceOf(len(history), result.Converged, result.StoppingReason, result.Residuals, time.Since(start)),
 	}
+	bayesianData.Convergence.Tolerance = request.Tolerance
+	confidence := setConfidence(&bayesianData.StochasticAlgorithmData, result.Confidence, confidencePosteriorImprovement)
 
 	// Add to storage
-	if err := h.storage.AddStochasticAlgorithm(request.SessionID, &bayesianData.StochasticAlgorithmData); err != nil {
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &bayesianData.StochasticAlgorithmData, bayesianData); err != nil {
 		h.logger.WithError(err).Error("Failed to add Bayesian optimization data")
-		h.respondWithError(w, "Failed to add Bayesian optimization data", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add Bayesian optimization data")
 	}
 
-	response := map[string]interface{}{
-		"algorithm_id":    bayesianData.ID,
-		"status":          "success",
-		"summary":         fmt.Sprintf("Optimized objective with %s acquisition", request.AcquisitionFunction),
-		"has_result":      true,
-		"best_parameters": bestParameters,
-		"best_value":      bestValue,
-		"iterations":      request.Iterations,
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &bayesianData.StochasticAlgorithmData, traceConvergencePlot, optimizationPlot(history, request.Goal)); err != nil {
+			return nil, err
+		}
 	}
 
-	h.respondWithJSON(w, response)
-}
-
-// HiddenMarkovModel handles HMM requests
-func (h *StochasticHandler) HiddenMarkovModel(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID     string `json:"session_id"`
-		Problem       string `json:"problem"`
-		States        int    `json:"states"`
-		Observations  int    `json:"observations"`
-		Algorithm     string `json:"algorithm"`
-		MaxIterations int    `json:"max_iterations,omitempty"`
+	response := &api.BayesianOptimizationResponse{
+		AlgorithmID:     bayesianData.ID,
+		Status:          "success",
+		Summary:         summary,
+		HasResult:       true,
+		BestParameters:  result.BestParameters,
+		BestValue:       result.BestValue,
+		BestPosterior:   api.GPPosterior(result.BestPosterior),
+		Iterations:      len(history),
+		History:         make([]api.OptimizationStep, len(history)),
+		NextParameters:  result.Next,
+		NextAcquisition: result.NextAcquisition,
+		NextPosterior:   api.GPPosterior(result.NextPosterior),
+		Convergence:     api.Convergence(*bayesianData.Convergence),
+		Confidence:      confidence,
+		TraceID:         traceID,
 	}
-
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
-		return
+	for i, step := range history {
+		response.History[i] = api.OptimizationStep{
+			Iteration:   step.Iteration,
+			Parameters:  step.Parameters,
+			Value:       step.Value,
+			Random:      step.Random,
+			Acquisition: step.Acquisition,
+			Predicted:   api.GPPosterior(step.Predicted),
+		}
 	}
+	return response, nil
+}
+
+// RunHMM fits the model of request and records it in its session in the
+// tenant of ctx
+func (h *StochasticHandler) RunHMM(ctx context.Context, request api.HMMRequest) (*api.HMMResponse, error) {
+	known := request.Initial != nil || request.Transitions != nil || request.Emissions != nil
 
 	// Set defaults
+	if request.Algorithm == "" {
+		request.Algorithm = "baum_welch"
+		if known {
+			request.Algorithm = "viterbi"
+		}
+	}
+	if request.States == 0 {
+		switch {
+		case request.StateNames != nil:
+			request.States = len(request.StateNames)
+		case request.Initial != nil:
+			request.States = len(request.Initial)
+		default:
+			request.States = 2
+		}
+	}
+	if request.StateNames == nil {
+		for i := 1; i <= request.States; i++ {
+			request.StateNames = append(request.StateNames, fmt.Sprintf("state_%d", i))
+		}
+	}
 	if request.MaxIterations == 0 {
 		request.MaxIterations = 100
 	}
+	if request.Tolerance == 0 {
+		request.Tolerance = 1e-6
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	if request.Symbols == nil {
+		seen := make(map[string]bool)
+		for _, o := range request.Observations {
+			if !seen[o] {
+				seen[o] = true
+				request.Symbols = append(request.Symbols, o)
+			}
+		}
+	}
+
+	symbolIndex := make(map[string]int, len(request.Symbols))
+	for i, symbol := range request.Symbols {
+		symbolIndex[symbol] = i
+	}
+	observations := make([]int, len(request.Observations))
+	for t, o := range request.Observations {
+		i, ok := symbolIndex[o]
+		if !ok {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Observation %d is %q, which is not one of the symbols", t, o)
+		}
+		observations[t] = i
+	}
+	if len(observations) == 0 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "There are no observations")
+	}
+	if len(request.StateNames) != request.States {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "There are %d state names for %d states", len(request.StateNames), request.States)
+	}
 
-	// Simulate HMM algorithm
-	stateSequence, transitionProbs, emissionProbs, initialProbs := h.simulateHMM(request.States, request.Observations, request.Algorithm, request.MaxIterations)
+	model := hmm.RandomModel(request.States, len(request.Symbols), rand.New(rand.NewSource(request.Seed)))
+	if known {
+		model = &hmm.Model{Initial: request.Initial, Transition: request.Transitions, Emission: request.Emissions}
+		if err := model.Validate(len(request.Symbols)); err != nil {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid model: %v", err)
+		}
+		if model.States() != request.States {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "The model has %d states, not %d", model.States(), request.States)
+		}
+	}
+
+	// Decoding a known model is exact
+	start := time.Now()
+	fit := &hmm.Fit{Converged: true, StoppingReason: convergence.Exact}
+	switch request.Algorithm {
+	case "viterbi":
+		if !known {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Viterbi decoding needs initial, transitions and emissions")
+		}
+	case "baum_welch":
+		// Fit the model, stopping if the client goes away
+		var err error
+		if model, fit, err = hmm.BaumWelch(ctx, model, observations, hmm.FitOptions{
+			MaxIterations: request.MaxIterations,
+			Tolerance:     request.Tolerance,
+			TimeLimit:     time.Duration(request.TimeLimit) * time.Second,
+		}); err != nil {
+			if ctx.Err() != nil {
+				return nil, apierror.Errorf(apierror.CodeOf(err), "HMM fitting cancelled")
+			}
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid HMM: %v", err)
+		}
+	default:
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Unknown algorithm %q", request.Algorithm)
+	}
+
+	path, pathLogProbability, err := hmm.Viterbi(model, observations)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid HMM: %v", err)
+	}
+	posteriors, logLikelihood, err := hmm.ForwardBackward(model, observations)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid HMM: %v", err)
+	}
+	statePath := make([]string, len(path))
+	for t, state := range path {
+		statePath[t] = request.StateNames[state]
+	}
+	summary := fmt.Sprintf("Decoded %d observations with %s, log-likelihood %.4f", len(observations), request.Algorithm, logLikelihood)
 
 	// Create HMM data
 	hmmData := &types.HMMData{
@@ -343,222 +910,1324 @@ func (h *StochasticHandler) HiddenMarkovModel(w http.ResponseWriter, r *http.Req
 			Problem:   request.Problem,
 			Parameters: map[string]interface{}{
 				"states":         request.States,
-				"observations":   request.Observations,
+				"symbols":        len(request.Symbols),
+				"observations":   len(request.Observations),
 				"algorithm":      request.Algorithm,
 				"max_iterations": request.MaxIterations,
+				"tolerance":      request.Tolerance,
+				"time_limit":     request.TimeLimit,
+				"seed":           request.Seed,
 			},
-			Result:     fmt.Sprintf("Inferred hidden states using %s algorithm", request.Algorithm),
-			Confidence: 0.80,
-			Iterations: request.MaxIterations,
-			Converged:  true,
-			CreatedAt:  time.Now(),
+			Result:         summary,
+			Iterations:     fit.Iterations,
+			Converged:      fit.Converged,
+			StoppingReason: fit.StoppingReason,
+			Value:          &logLikelihood,
+			Rerun:          rerunOf("hmm", request),
+			CreatedAt:      time.Now(),
 		},
-		StateSequence:           stateSequence,
-		TransitionProbabilities: transitionProbs,
-		EmissionProbabilities:   emissionProbs,
-		InitialProbabilities:    initialProbs,
+		StateSequence:           path,
+		TransitionProbabilities: model.Transition,
+		EmissionProbabilities:   model.Emission,
+		InitialProbabilities:    model.Initial,
+		StatePath:               statePath,
+		LogLikelihood:           logLikelihood,
+		Convergence:             convergenceOf(fit.Iterations, fit.Converged, fit.StoppingReason, fit.Residuals, time.Since(start)),
+	}
+	if request.Algorithm == "baum_welch" {
+		hmmData.Convergence.Tolerance = request.Tolerance
 	}
+	confidence := setConfidence(&hmmData.StochasticAlgorithmData, math.Exp(pathLogProbability-logLikelihood), confidencePathPosterior)
 
 	// Add to storage
-	if err := h.storage.AddStochasticAlgorithm(request.SessionID, &hmmData.StochasticAlgorithmData); err != nil {
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &hmmData.StochasticAlgorithmData, hmmData); err != nil {
 		h.logger.WithError(err).Error("Failed to add HMM data")
-		h.respondWithError(w, "Failed to add HMM data", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add HMM data")
 	}
 
-	response := map[string]interface{}{
-		"algorithm_id": hmmData.ID,
-		"status":       "success",
-		"summary":      fmt.Sprintf("Inferred hidden states using %s algorithm", request.Algorithm),
-		"has_result":   true,
-		"states":       request.States,
-		"observations": request.Observations,
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &hmmData.StochasticAlgorithmData, traceConvergencePlot, residualPlot(fit.Residuals)); err != nil {
+			return nil, err
+		}
 	}
 
-	h.respondWithJSON(w, response)
+	return &api.HMMResponse{
+		AlgorithmID:        hmmData.ID,
+		Status:             "success",
+		Summary:            summary,
+		HasResult:          true,
+		States:             request.States,
+		Observations:       len(observations),
+		StateNames:         request.StateNames,
+		Symbols:            request.Symbols,
+		StatePath:          statePath,
+		PathLogProbability: pathLogProbability,
+		LogLikelihood:      logLikelihood,
+		StatePosteriors:    posteriors,
+		Initial:            model.Initial,
+		Transitions:        model.Transition,
+		Emissions:          model.Emission,
+		Iterations:         fit.Iterations,
+		Converged:          hmmData.Converged,
+		Convergence:        api.Convergence(*hmmData.Convergence),
+		Confidence:         confidence,
+		TraceID:            traceID,
+	}, nil
 }
 
-// ReinforcementLearning handles reinforcement learning requests
-func (h *StochasticHandler) ReinforcementLearning(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Reinforcement learning not yet implemented",
-		"status":  "coming_soon",
+// RunQLearning learns a policy for the environment of request by tabular
+// Q-learning, SARSA or expected SARSA and records it in its session in the
+// tenant of ctx. Learning stops once ctx is done.
+func (h *StochasticHandler) RunQLearning(ctx context.Context, request api.QLearningRequest) (*api.QLearningResponse, error) {
+	// Set defaults
+	if request.Method == "" {
+		request.Method = mdp.QLearning
 	}
-	h.respondWithJSON(w, response)
+	if request.LearningRate == 0 {
+		request.LearningRate = 0.1
+	}
+	if request.Epsilon == 0 {
+		request.Epsilon = 1
+	}
+	if request.EpsilonDecay == 0 {
+		request.EpsilonDecay = 0.99
+	}
+	if request.MinEpsilon == 0 {
+		request.MinEpsilon = 0.01
+	}
+	if request.Episodes == 0 {
+		request.Episodes = 500
+	}
+	if request.MaxSteps == 0 {
+		request.MaxSteps = 100
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+
+	var model *mdp.Model
+	var grid mdp.Grid
+	var err error
+	if request.Grid != nil && len(request.Transitions) == 0 && len(request.Samples) == 0 {
+		grid = gridWorld(*request.Grid)
+		var start string
+		if model, start, err = grid.Model(); err == nil && request.StartState == "" {
+			request.StartState = start
+		}
+	} else {
+		model, err = mdpModel(request.Transitions, request.Samples, request.States, request.Actions)
+	}
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid environment: %v", err)
+	}
+
+	// Run the episodes, stopping if the client goes away
+	start := time.Now()
+	learned, err := mdp.Learn(ctx, model, mdp.LearningOptions{
+		Method:       request.Method,
+		Gamma:        request.Gamma,
+		LearningRate: request.LearningRate,
+		Epsilon:      request.Epsilon,
+		EpsilonDecay: request.EpsilonDecay,
+		MinEpsilon:   request.MinEpsilon,
+		Episodes:     request.Episodes,
+		MaxSteps:     request.MaxSteps,
+		StartState:   request.StartState,
+		TimeLimit:    time.Duration(request.TimeLimit) * time.Second,
+		Rand:         rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "%s cancelled", request.Method)
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid %s parameters: %v", request.Method, err)
+	}
+
+	curve := make([]types.LearningEpisode, len(learned.Episodes))
+	residuals := make([]float64, len(learned.Episodes))
+	for i, episode := range learned.Episodes {
+		curve[i] = types.LearningEpisode{Episode: i + 1, Reward: episode.Reward, Steps: episode.Steps, Epsilon: episode.Epsilon, Residual: episode.Residual}
+		residuals[i] = episode.Residual
+	}
+	summary := fmt.Sprintf("Learned a policy over %d states by %s in %d episodes; the last episode collected a reward of %.2f",
+		len(model.States()), request.Method, len(curve), curve[len(curve)-1].Reward)
+
+	// Create Q-learning data
+	qData := &types.QLearningData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: request.Method,
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"method":        request.Method,
+				"states":        model.States(),
+				"actions":       model.Actions(),
+				"start_state":   request.StartState,
+				"gamma":         request.Gamma,
+				"learning_rate": request.LearningRate,
+				"epsilon":       request.Epsilon,
+				"epsilon_decay": request.EpsilonDecay,
+				"min_epsilon":   request.MinEpsilon,
+				"episodes":      request.Episodes,
+				"max_steps":     request.MaxSteps,
+				"time_limit":    request.TimeLimit,
+				"seed":          request.Seed,
+			},
+			Result:         summary,
+			Iterations:     len(curve),
+			Converged:      learned.Converged,
+			StoppingReason: learned.StoppingReason,
+			Value:          &curve[len(curve)-1].Reward,
+			Rerun:          rerunOf("reinforcement", request),
+			CreatedAt:      time.Now(),
+		},
+		Policy:        learned.Policy,
+		ValueFunction: learned.Values,
+		QValues:       learned.QValues,
+		LearningCurve: curve,
+		Convergence:   convergenceOf(len(curve), learned.Converged, learned.StoppingReason, residuals, time.Since(start)),
+	}
+	if request.Grid != nil && grid.Layout != nil {
+		qData.PolicyGrid = grid.Render(learned.Policy)
+	}
+
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &qData.StochasticAlgorithmData, qData); err != nil {
+		h.logger.WithError(err).Error("Failed to add Q-learning data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add Q-learning data")
+	}
+
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &qData.StochasticAlgorithmData, traceConvergencePlot, learningPlot(curve)); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &api.QLearningResponse{
+		AlgorithmID:   qData.ID,
+		Status:        "success",
+		Summary:       summary,
+		HasResult:     true,
+		Method:        request.Method,
+		Episodes:      len(curve),
+		Policy:        qData.Policy,
+		PolicyGrid:    qData.PolicyGrid,
+		ValueFunction: qData.ValueFunction,
+		QValues:       qData.QValues,
+		LearningCurve: make([]api.QLearningEpisode, len(curve)),
+		Convergence:   api.Convergence(*qData.Convergence),
+		TraceID:       traceID,
+	}
+	for i, episode := range curve {
+		response.LearningCurve[i] = api.QLearningEpisode(episode)
+	}
+	return response, nil
 }
 
-// Simulation methods (simplified implementations)
-
-func (h *StochasticHandler) simulateMDP(states int, actions []string, gamma, learningRate, epsilon float64, maxIterations int) (map[string]string, map[string]float64, map[string]map[string]float64) {
-	// Simplified MDP simulation
-	policy := make(map[string]string)
-	valueFunction := make(map[string]float64)
-	qValues := make(map[string]map[string]float64)
-
-	// Initialize Q-values
-	for i := 0; i < states; i++ {
-		state := fmt.Sprintf("state_%d", i)
-		qValues[state] = make(map[string]float64)
-		for _, action := range actions {
-			qValues[state][action] = rand.Float64()
-		}
-	}
-
-	// Simple policy iteration
-	for i := 0; i < maxIterations; i++ {
-		// Update Q-values (simplified)
-		for state := range qValues {
-			bestAction := ""
-			bestValue := -math.MaxFloat64
-			for action, value := range qValues[state] {
-				if value > bestValue {
-					bestValue = value
-					bestAction = action
-				}
-			}
-			policy[state] = bestAction
-			valueFunction[state] = bestValue
+// gridWorld returns the grid world of grid, with its default rewards
+func gridWorld(grid api.GridWorld) mdp.Grid {
+	world := mdp.Grid{Layout: grid.Layout, StepReward: -1, GoalReward: 10, PitReward: -10, Slip: grid.Slip}
+	if grid.StepReward != nil {
+		world.StepReward = *grid.StepReward
+	}
+	if grid.GoalReward != nil {
+		world.GoalReward = *grid.GoalReward
+	}
+	if grid.PitReward != nil {
+		world.PitReward = *grid.PitReward
+	}
+	return world
+}
+
+// RunSimulatedAnnealing minimizes the objective of request by simulated
+// annealing and records the run in its session in the tenant of ctx. The
+// search stops once ctx is done.
+func (h *StochasticHandler) RunSimulatedAnnealing(ctx context.Context, request api.AnnealingRequest) (*api.AnnealingResponse, error) {
+	// Set defaults
+	if request.Schedule == "" {
+		request.Schedule = anneal.Exponential
+	}
+	if request.InitialTemperature == 0 {
+		request.InitialTemperature = 1
+	}
+	if request.FinalTemperature == 0 {
+		request.FinalTemperature = 1e-3
+	}
+	if request.Iterations == 0 {
+		request.Iterations = 1000
+	}
+	if request.StepSize == 0 {
+		request.StepSize = 0.1
+	}
+	if request.Tolerance == 0 {
+		request.Tolerance = 1e-6
+	}
+	if request.Patience == 0 {
+		request.Patience = max(1, request.Iterations/10)
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+
+	variables := make([]anneal.Variable, len(request.Variables))
+	names := make([]string, len(request.Variables))
+	for i, v := range request.Variables {
+		variables[i] = anneal.Variable(v)
+		names[i] = v.Name
+	}
+	objective, err := expr.Compile(request.Objective, names)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid objective: %v", err)
+	}
+
+	// Anneal, stopping if the client goes away
+	start := time.Now()
+	result, err := anneal.Minimize(ctx, variables, anneal.Objective(objective), anneal.Options{
+		Schedule:           request.Schedule,
+		InitialTemperature: request.InitialTemperature,
+		FinalTemperature:   request.FinalTemperature,
+		Iterations:         request.Iterations,
+		StepSize:           request.StepSize,
+		Start:              request.Start,
+		Tolerance:          request.Tolerance,
+		Patience:           request.Patience,
+		StopAtPlateau:      request.StopAtPlateau,
+		TimeLimit:          time.Duration(request.TimeLimit) * time.Second,
+		Rand:               rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Simulated annealing cancelled")
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid annealing run: %v", err)
+	}
+
+	trajectory := make([]types.AnnealingStep, len(result.Trajectory))
+	for i, step := range result.Trajectory {
+		trajectory[i] = types.AnnealingStep(step)
+	}
+	acceptance := float64(result.Accepted) / float64(result.Iterations)
+	summary := fmt.Sprintf("Lowest value %.4g after %d iterations of %s cooling from %.4g; %.0f%% of moves accepted",
+		result.BestValue, result.Iterations, request.Schedule, result.StartValue, 100*acceptance)
+
+	// Create annealing data
+	annealingData := &types.AnnealingData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "simulated_annealing",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"variables":           len(request.Variables),
+				"objective":           request.Objective,
+				"schedule":            request.Schedule,
+				"initial_temperature": request.InitialTemperature,
+				"final_temperature":   request.FinalTemperature,
+				"iterations":          request.Iterations,
+				"step_size":           request.StepSize,
+				"tolerance":           request.Tolerance,
+				"patience":            request.Patience,
+				"stop_at_plateau":     request.StopAtPlateau,
+				"time_limit":          request.TimeLimit,
+				"seed":                request.Seed,
+			},
+			Result:         summary,
+			Iterations:     result.Iterations,
+			Converged:      result.Converged,
+			StoppingReason: result.StoppingReason,
+			Value:          &result.BestValue,
+			Rerun:          rerunOf("annealing", request),
+			CreatedAt:      time.Now(),
+		},
+		BestPoint:   result.BestPoint,
+		BestValue:   result.BestValue,
+		FinalPoint:  result.FinalPoint,
+		FinalValue:  result.FinalValue,
+		Trajectory:  trajectory,
+		Convergence: convergenceOf(result.Iterations, result.Converged, result.StoppingReason, result.Residuals, time.Since(start)),
+	}
+	annealingData.Convergence.Tolerance = request.Tolerance
+
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &annealingData.StochasticAlgorithmData, annealingData); err != nil {
+		h.logger.WithError(err).Error("Failed to add annealing data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add annealing data")
+	}
+
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &annealingData.StochasticAlgorithmData, traceConvergencePlot, annealingPlot(trajectory)); err != nil {
+			return nil, err
 		}
 	}
 
-	return policy, valueFunction, qValues
+	response := &api.AnnealingResponse{
+		AlgorithmID:    annealingData.ID,
+		Status:         "success",
+		Summary:        summary,
+		HasResult:      true,
+		BestPoint:      result.BestPoint,
+		BestValue:      result.BestValue,
+		FinalPoint:     result.FinalPoint,
+		FinalValue:     result.FinalValue,
+		StartValue:     result.StartValue,
+		Iterations:     result.Iterations,
+		AcceptanceRate: acceptance,
+		UphillMoves:    result.Uphill,
+		Trajectory:     make([]api.AnnealingStep, len(trajectory)),
+		Convergence:    api.Convergence(*annealingData.Convergence),
+		TraceID:        traceID,
+	}
+	for i, step := range trajectory {
+		response.Trajectory[i] = api.AnnealingStep(step)
+	}
+	return response, nil
 }
 
-func (h *StochasticHandler) simulateMCTS(simulations int, explorationConstant float64, maxDepth int) (string, map[string]interface{}) {
-	// Simplified MCTS simulation
-	actions := []string{"action_1", "action_2", "action_3", "action_4"}
-	bestAction := actions[rand.Intn(len(actions))]
+// RunMonteCarloSimulation simulates the output of request over its random
+// variables and records the run in its session in the tenant of ctx. The
+// simulation stops once ctx is done.
+func (h *StochasticHandler) RunMonteCarloSimulation(ctx context.Context, request api.MonteCarloRequest) (*api.MonteCarloResponse, error) {
+	start := time.Now()
+	result, err := simulateMonteCarlo(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
 
-	treeStats := map[string]interface{}{
-		"nodes": simulations * 2,
-		"depth": maxDepth,
-		"visits": map[string]int{
-			"root": simulations,
+	percentiles := make([]types.MonteCarloPercentile, len(result.Percentiles))
+	for i, p := range result.Percentiles {
+		percentiles[i] = types.MonteCarloPercentile(p)
+	}
+	histogram := make([]types.HistogramBucket, len(result.Histogram))
+	for i, bucket := range result.Histogram {
+		histogram[i] = types.HistogramBucket(bucket)
+	}
+	exceedances := make([]types.Exceedance, len(result.Exceedances))
+	for i, e := range result.Exceedances {
+		exceedances[i] = types.Exceedance(e)
+	}
+	summary := fmt.Sprintf("Output mean %.4g (standard deviation %.4g) over %d trials, ranging from %.4g to %.4g",
+		result.Mean, result.StdDev, result.Trials, result.Min, result.Max)
+
+	// Create Monte Carlo data
+	monteCarloData := &types.MonteCarloData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "monte_carlo",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"variables":   len(request.Variables),
+				"output":      request.Output,
+				"trials":      request.Trials,
+				"percentiles": request.Percentiles,
+				"buckets":     request.Buckets,
+				"thresholds":  request.Thresholds,
+				"parallelism": request.Parallelism,
+				"time_limit":  request.TimeLimit,
+				"seed":        request.Seed,
+			},
+			Result:         summary,
+			Iterations:     result.Trials,
+			Converged:      result.Converged,
+			StoppingReason: result.StoppingReason,
+			Value:          &result.Mean,
+			Rerun:          rerunOf("montecarlo", request),
+			CreatedAt:      time.Now(),
 		},
+		Mean:        result.Mean,
+		StdDev:      result.StdDev,
+		Percentiles: percentiles,
+		Histogram:   histogram,
+		Exceedances: exceedances,
+		Convergence: convergenceOf(result.Trials, result.Converged, result.StoppingReason, result.Residuals, time.Since(start)),
 	}
+	monteCarloData.Convergence.RHat = result.RHat
 
-	return bestAction, treeStats
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &monteCarloData.StochasticAlgorithmData, monteCarloData); err != nil {
+		h.logger.WithError(err).Error("Failed to add Monte Carlo data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add Monte Carlo data")
+	}
+
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &monteCarloData.StochasticAlgorithmData, traceConvergencePlot, residualPlot(result.Residuals)); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &api.MonteCarloResponse{
+		AlgorithmID: monteCarloData.ID,
+		Status:      "success",
+		Summary:     summary,
+		HasResult:   true,
+		Trials:      result.Trials,
+		Mean:        result.Mean,
+		StdDev:      result.StdDev,
+		StdError:    result.StdError,
+		Min:         result.Min,
+		Max:         result.Max,
+		Percentiles: make([]api.MonteCarloPercentile, len(percentiles)),
+		Histogram:   make([]api.HistogramBucket, len(histogram)),
+		Exceedances: make([]api.Exceedance, len(exceedances)),
+		Convergence: api.Convergence(*monteCarloData.Convergence),
+		TraceID:     traceID,
+	}
+	for i, p := range percentiles {
+		response.Percentiles[i] = api.MonteCarloPercentile(p)
+	}
+	for i, bucket := range histogram {
+		response.Histogram[i] = api.HistogramBucket(bucket)
+	}
+	for i, e := range exceedances {
+		response.Exceedances[i] = api.Exceedance(e)
+	}
+	return response, nil
 }
 
-func (h *StochasticHandler) simulateBandit(arms int, strategy string, epsilon, alpha, beta float64) ([]types.ArmStatistics, int) {
-	armStats := make([]types.ArmStatistics, arms)
-	selectedArm := 0
+// simulateMonteCarlo sets the defaults of request and simulates its output
+// over its random variables, stopping once ctx is done
+func simulateMonteCarlo(ctx context.Context, request *api.MonteCarloRequest) (*montecarlo.Result, error) {
+	// Set defaults
+	if request.Trials == 0 {
+		request.Trials = 10000
+	}
+	if len(request.Percentiles) == 0 {
+		request.Percentiles = []float64{5, 25, 50, 75, 95}
+	}
+	if request.Buckets == 0 {
+		request.Buckets = 20
+	}
+	if request.Parallelism == 0 {
+		request.Parallelism = runtime.GOMAXPROCS(0)
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	if request.Trials > 1000000 || request.Buckets > 1000 || request.Parallelism > 64 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid simulation: at most 1000000 trials, 1000 buckets and parallelism 64")
+	}
 
-	for i := 0; i < arms; i++ {
-		pulls := rand.Intn(100) + 10
-		rewards := rand.Float64() * float64(pulls)
+	variables := make([]montecarlo.Variable, len(request.Variables))
+	names := make([]string, len(request.Variables))
+	for i, v := range request.Variables {
+		variables[i] = montecarlo.Variable(v)
+		if v.Distribution == montecarlo.Beta && v.Min == 0 && v.Max == 0 {
+			variables[i].Max = 1
+		}
+		names[i] = v.Name
+	}
+	output, err := expr.Compile(request.Output, names)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid output: %v", err)
+	}
 
-		armStats[i] = types.ArmStatistics{
-			Arm:           i,
-			Pulls:         pulls,
-			Rewards:       rewards,
-			AverageReward: rewards / float64(pulls),
+	// Run the trials, stopping if the client goes away
+	result, err := montecarlo.Simulate(ctx, variables, montecarlo.Output(output), montecarlo.Options{
+		Trials:      request.Trials,
+		Percentiles: request.Percentiles,
+		Buckets:     request.Buckets,
+		Thresholds:  request.Thresholds,
+		Parallelism: request.Parallelism,
+		TimeLimit:   time.Duration(request.TimeLimit) * time.Second,
+		Rand:        rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Monte Carlo simulation cancelled")
 		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid simulation: %v", err)
 	}
+	return result, nil
+}
 
-	// Select best arm
-	bestReward := -1.0
-	for i, stat := range armStats {
-		if stat.AverageReward > bestReward {
-			bestReward = stat.AverageReward
-			selectedArm = i
+// RunParticleFilter tracks the latent state of request through its
+// observations and records the run in its session in the tenant of ctx. The
+// filter stops once ctx is done.
+func (h *StochasticHandler) RunParticleFilter(ctx context.Context, request api.ParticleFilterRequest) (*api.ParticleFilterResponse, error) {
+	// Set defaults
+	if request.Particles == 0 {
+		request.Particles = 1000
+	}
+	if request.ResampleThreshold == 0 {
+		request.ResampleThreshold = 0.5
+	}
+	if request.Band == 0 {
+		request.Band = 0.9
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	if request.Particles > 100000 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid filter: at most 100000 particles")
+	}
+
+	// Expressions see the state variables and the step
+	names := []string{"t"}
+	for _, v := range request.Variables {
+		names = append(names, v.Name)
+	}
+	model := particle.Model{Variables: make([]particle.Variable, len(request.Variables)), ObservationNoise: request.ObservationNoise}
+	for i, v := range request.Variables {
+		model.Variables[i] = particle.Variable{Name: v.Name, InitialMean: v.InitialMean, InitialStdDev: v.InitialStdDev, ProcessNoise: v.ProcessNoise}
+		if v.Dynamics != "" {
+			dynamics, err := expr.Compile(v.Dynamics, names)
+			if err != nil {
+				return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid dynamics of %s: %v", v.Name, err)
+			}
+			model.Variables[i].Dynamics = particle.Function(dynamics)
+		}
+	}
+	observation, err := expr.Compile(request.Observation, names)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid observation: %v", err)
+	}
+	model.Observation = particle.Function(observation)
+
+	// Filter, stopping if the client goes away, keeping particle clouds for
+	// a trace
+	cloud := 0
+	if request.Trace {
+		cloud = traceParticles
+	}
+	result, err := particle.Filter(ctx, model, request.Observations, particle.Options{
+		Particles:         request.Particles,
+		ResampleThreshold: request.ResampleThreshold,
+		Band:              request.Band,
+		Cloud:             cloud,
+		Rand:              rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Particle filter cancelled")
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid filter: %v", err)
+	}
+
+	steps := make([]types.FilterStep, len(result.Steps))
+	for i, step := range result.Steps {
+		steps[i] = types.FilterStep{
+			Step:                step.Step,
+			Observation:         step.Observation,
+			Predicted:           step.Predicted,
+			Estimates:           make(map[string]types.StateEstimate, len(step.Estimates)),
+			EffectiveSampleSize: step.EffectiveSampleSize,
+			Resampled:           step.Resampled,
+		}
+		for name, estimate := range step.Estimates {
+			steps[i].Estimates[name] = types.StateEstimate(estimate)
+		}
+	}
+	final := steps[len(steps)-1].Estimates
+	estimates := make([]string, len(request.Variables))
+	for i, v := range request.Variables {
+		estimate := final[v.Name]
+		estimates[i] = fmt.Sprintf("%s %.4g (%.4g to %.4g)", v.Name, estimate.Mean, estimate.Lower, estimate.Upper)
+	}
+	summary := fmt.Sprintf("Filtered %d observations with %d particles; finally %s", len(steps), request.Particles, strings.Join(estimates, ", "))
+
+	// Create particle filter data
+	filterData := &types.ParticleFilterData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "particle_filter",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"variables":          len(request.Variables),
+				"observation":        request.Observation,
+				"observation_noise":  request.ObservationNoise,
+				"observations":       len(request.Observations),
+				"particles":          request.Particles,
+				"resample_threshold": request.ResampleThreshold,
+				"band":               request.Band,
+				"seed":               request.Seed,
+			},
+			Result:     summary,
+			Iterations: len(steps),
+			Value:      &result.LogLikelihood,
+			Rerun:      rerunOf("particle", request),
+			CreatedAt:  time.Now(),
+		},
+		Steps:         steps,
+		LogLikelihood: result.LogLikelihood,
+	}
+
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &filterData.StochasticAlgorithmData, filterData); err != nil {
+		h.logger.WithError(err).Error("Failed to add particle filter data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add particle filter data")
+	}
+
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &filterData.StochasticAlgorithmData, traceParticleCloud, particleClouds(result.Steps)); err != nil {
+			return nil, err
 		}
 	}
 
-	return armStats, selectedArm
+	response := &api.ParticleFilterResponse{
+		AlgorithmID:   filterData.ID,
+		Status:        "success",
+		Summary:       summary,
+		HasResult:     true,
+		Steps:         make([]api.FilterStep, len(steps)),
+		LogLikelihood: result.LogLikelihood,
+		Resamples:     result.Resamples,
+		TraceID:       traceID,
+	}
+	for i, step := range steps {
+		response.Steps[i] = api.FilterStep{
+			Step:                step.Step,
+			Observation:         step.Observation,
+			Predicted:           step.Predicted,
+			Estimates:           make(map[string]api.StateEstimate, len(step.Estimates)),
+			EffectiveSampleSize: step.EffectiveSampleSize,
+			Resampled:           step.Resampled,
+		}
+		for name, estimate := range step.Estimates {
+			response.Steps[i].Estimates[name] = api.StateEstimate(estimate)
+		}
+	}
+	response.Final = response.Steps[len(steps)-1].Estimates
+	return response, nil
 }
 
-func (h *StochasticHandler) simulateBayesianOptimization(iterations int, acquisitionFunction, kernel string, explorationWeight float64) ([]types.OptimizationStep, map[string]float64, float64) {
-	history := make([]types.OptimizationStep, iterations)
-	bestValue := -math.MaxFloat64
-	bestParameters := make(map[string]float64)
+// RunBootstrapAnalysis bootstraps the statistic of request's data and records
+// the analysis in its session in the tenant of ctx. The resampling stops once
+// ctx is done.
+func (h *StochasticHandler) RunBootstrapAnalysis(ctx context.Context, request api.BootstrapRequest) (*api.BootstrapResponse, error) {
+	// Set defaults
+	if request.Statistic == "" {
+		request.Statistic = bootstrap.Mean
+	}
+	if request.Resamples == 0 {
+		request.Resamples = 2000
+	}
+	if request.Confidence == 0 {
+		request.Confidence = 0.95
+	}
+	if request.Parallelism == 0 {
+		request.Parallelism = runtime.GOMAXPROCS(0)
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	if request.Resamples > 100000 || request.Parallelism > 64 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid analysis: at most 100000 resamples and parallelism 64")
+	}
 
-	for i := 0; i < iterations; i++ {
-		params := map[string]float64{
-			"param_1": rand.Float64() * 10,
-			"param_2": rand.Float64() * 10,
+	// Resample, stopping if the client goes away
+	result, err := bootstrap.Analyze(ctx, request.Data, request.Comparison, bootstrap.Options{
+		Statistic:   request.Statistic,
+		Resamples:   request.Resamples,
+		Confidence:  request.Confidence,
+		Parallelism: request.Parallelism,
+		Rand:        rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Bootstrap analysis cancelled")
 		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid analysis: %v", err)
+	}
 
-		// Simulate objective function
-		value := math.Sin(params["param_1"])*math.Cos(params["param_2"]) + rand.NormFloat64()*0.1
+	summary := fmt.Sprintf("%s %.4g (standard error %.4g), %.4g%% interval %.4g to %.4g over %d resamples",
+		request.Statistic, result.Estimate, result.StdError, 100*request.Confidence, result.Percentile.Lower, result.Percentile.Upper, request.Resamples)
 
-		history[i] = types.OptimizationStep{
-			Iteration:  i + 1,
-			Parameters: params,
-			Value:      value,
+	// Create bootstrap data
+	bootstrapData := &types.BootstrapData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "bootstrap",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"statistic":   request.Statistic,
+				"data":        len(request.Data),
+				"comparison":  len(request.Comparison),
+				"resamples":   request.Resamples,
+				"confidence":  request.Confidence,
+				"parallelism": request.Parallelism,
+				"seed":        request.Seed,
+			},
+			Result:     summary,
+			Iterations: request.Resamples,
+			Value:      &result.Estimate,
+			Rerun:      rerunOf("bootstrap", request),
+			CreatedAt:  time.Now(),
+		},
+		Estimate:           result.Estimate,
+		StdError:           result.StdError,
+		Bias:               result.Bias,
+		PercentileInterval: types.ConfidenceInterval(result.Percentile),
+		BasicInterval:      types.ConfidenceInterval(result.Basic),
+		NormalInterval:     types.ConfidenceInterval(result.Normal),
+	}
+
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &bootstrapData.StochasticAlgorithmData, bootstrapData); err != nil {
+		h.logger.WithError(err).Error("Failed to add bootstrap data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add bootstrap data")
+	}
+
+	return &api.BootstrapResponse{
+		AlgorithmID:        bootstrapData.ID,
+		Status:             "success",
+		Summary:            summary,
+		HasResult:          true,
+		Statistic:          request.Statistic,
+		Estimate:           result.Estimate,
+		StdError:           result.StdError,
+		Bias:               result.Bias,
+		Confidence:         request.Confidence,
+		PercentileInterval: api.ConfidenceInterval(result.Percentile),
+		BasicInterval:      api.ConfidenceInterval(result.Basic),
+		NormalInterval:     api.ConfidenceInterval(result.Normal),
+		Resamples:          request.Resamples,
+	}, nil
+}
+
+// RunQueueingSimulation simulates the queue of request and records the run in
+// its session in the tenant of ctx. The simulation stops once ctx is done.
+func (h *StochasticHandler) RunQueueingSimulation(ctx context.Context, request api.QueueingRequest) (*api.QueueingResponse, error) {
+	// Set defaults
+	if request.Model == "" {
+		request.Model = "mm1"
+	}
+	if request.Servers == 0 {
+		request.Servers = 1
+	}
+	if request.Customers == 0 {
+		request.Customers = 10000
+	}
+	if request.WarmUp == 0 {
+		request.WarmUp = request.Customers / 10
+	}
+	if len(request.Percentiles) == 0 {
+		request.Percentiles = []float64{50, 90, 95, 99}
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	switch {
+	case request.Model != "mm1" && request.Model != "mmc":
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid simulation: unknown model %q", request.Model)
+	case request.Model == "mm1" && request.Servers != 1:
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid simulation: an mm1 queue has a single server; use mmc for %d", request.Servers)
+	case request.Servers > 1000 || request.Customers > 1000000 || request.WarmUp > 1000000:
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid simulation: at most 1000 servers, 1000000 customers and a warm-up of 1000000")
+	}
+
+	// Serve the customers, stopping if the client goes away
+	start := time.Now()
+	result, err := queueing.Simulate(ctx, queueing.Options{
+		Servers:     request.Servers,
+		ArrivalRate: request.ArrivalRate,
+		ServiceRate: request.ServiceRate,
+		Customers:   request.Customers,
+		WarmUp:      request.WarmUp,
+		Percentiles: request.Percentiles,
+		TimeLimit:   time.Duration(request.TimeLimit) * time.Second,
+		Rand:        rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "Queueing simulation cancelled")
 		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid simulation: %v", err)
+	}
+
+	summary := fmt.Sprintf("Servers %.1f%% utilized; customers waited %.4g on average, %.1f%% of them at all, with %.4g in line over %d customers",
+		100*result.Utilization, result.MeanWait, 100*result.WaitProbability, result.MeanQueueLength, result.Customers)
+	if !result.Stable {
+		summary += fmt.Sprintf("; the queue is unstable, arrivals outpacing the %d servers, so the line keeps growing", request.Servers)
+	}
+
+	queueLengths := make([]types.QueueShare, len(result.QueueLengths))
+	for i, share := range result.QueueLengths {
+		queueLengths[i] = types.QueueShare{Length: share.Length, Probability: share.Probability}
+	}
+
+	// Create queueing data
+	queueingData := &types.QueueingData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "queueing",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"model":        request.Model,
+				"servers":      request.Servers,
+				"arrival_rate": request.ArrivalRate,
+				"service_rate": request.ServiceRate,
+				"customers":    request.Customers,
+				"warm_up":      request.WarmUp,
+				"percentiles":  request.Percentiles,
+				"time_limit":   request.TimeLimit,
+				"seed":         request.Seed,
+			},
+			Result:         summary,
+			Iterations:     result.Customers,
+			Converged:      result.Converged,
+			StoppingReason: result.StoppingReason,
+			Value:          &result.MeanWait,
+			Rerun:          rerunOf("queueing", request),
+			CreatedAt:      time.Now(),
+		},
+		Servers:         request.Servers,
+		Stable:          result.Stable,
+		Utilization:     result.Utilization,
+		MeanWait:        result.MeanWait,
+		MeanQueueLength: result.MeanQueueLength,
+		QueueLengths:    queueLengths,
+		Convergence:     convergenceOf(result.Customers, result.Converged, result.StoppingReason, result.Residuals, time.Since(start)),
+	}
+	queueingData.Convergence.RHat = result.RHat
+
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &queueingData.StochasticAlgorithmData, queueingData); err != nil {
+		h.logger.WithError(err).Error("Failed to add queueing data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add queueing data")
+	}
 
-		if value > bestValue {
-			bestValue = value
-			bestParameters = params
+	// Trace the run for the visual tools
+	var traceID string
+	if request.Trace {
+		if traceID, err = h.recordTrace(ctx, request.SessionID, &queueingData.StochasticAlgorithmData, traceConvergencePlot, residualPlot(result.Residuals)); err != nil {
+			return nil, err
 		}
 	}
 
-	return history, bestParameters, bestValue
+	response := &api.QueueingResponse{
+		AlgorithmID:     queueingData.ID,
+		Status:          "success",
+		Summary:         summary,
+		HasResult:       true,
+		Model:           request.Model,
+		Servers:         request.Servers,
+		Stable:          result.Stable,
+		Customers:       result.Customers,
+		Duration:        result.Duration,
+		Utilization:     result.Utilization,
+		MeanWait:        result.MeanWait,
+		MeanSojourn:     result.MeanSojourn,
+		WaitProbability: result.WaitProbability,
+		WaitPercentiles: make([]api.QueueWaitPercentile, len(result.Percentiles)),
+		MeanQueueLength: result.MeanQueueLength,
+		MeanInSystem:    result.MeanInSystem,
+		MaxQueueLength:  result.MaxQueueLength,
+		QueueLengths:    make([]api.QueueLengthShare, len(result.QueueLengths)),
+		InSystem:        make([]api.QueueLengthShare, len(result.InSystem)),
+		Convergence:     api.Convergence(*queueingData.Convergence),
+		TraceID:         traceID,
+	}
+	for i, p := range result.Percentiles {
+		response.WaitPercentiles[i] = api.QueueWaitPercentile(p)
+	}
+	for i, share := range result.QueueLengths {
+		response.QueueLengths[i] = api.QueueLengthShare(share)
+	}
+	for i, share := range result.InSystem {
+		response.InSystem[i] = api.QueueLengthShare(share)
+	}
+	if result.Theory != nil {
+		theory := api.QueueingTheory(*result.Theory)
+		response.Theory = &theory
+	}
+	return response, nil
 }
 
-func (h *StochasticHandler) simulateHMM(states, observations int, algorithm string, maxIterations int) ([]int, [][]float64, [][]float64, []float64) {
-	// Generate random state sequence
-	stateSequence := make([]int, observations)
-	for i := range stateSequence {
-		stateSequence[i] = rand.Intn(states)
+// RunABTestAnalysis compares the variants of request's A/B test and records
+// the analysis in its session in the tenant of ctx. The sampling stops once
+// ctx is done.
+func (h *StochasticHandler) RunABTestAnalysis(ctx context.Context, request api.ABTestRequest) (*api.ABTestResponse, error) {
+	// Set defaults
+	if request.Control == "" && len(request.Variants) > 0 {
+		request.Control = request.Variants[0].Name
+	}
+	if request.Confidence == 0 {
+		request.Confidence = 0.95
+	}
+	if request.PriorAlpha == 0 {
+		request.PriorAlpha = 1
+	}
+	if request.PriorBeta == 0 {
+		request.PriorBeta = 1
+	}
+	if request.Samples == 0 {
+		request.Samples = 100000
+	}
+	if request.Parallelism == 0 {
+		request.Parallelism = runtime.GOMAXPROCS(0)
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+	if request.Samples > 1000000 || request.Parallelism > 64 || len(request.Variants) > 100 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid analysis: at most 100 variants, 1000000 samples and parallelism 64")
+	}
+
+	variants := make([]abtest.Variant, len(request.Variants))
+	control := -1
+	for i, v := range request.Variants {
+		variants[i] = abtest.Variant(v)
+		if v.Name == request.Control && control < 0 {
+			control = i
+		}
+	}
+	if control < 0 && len(variants) > 0 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid analysis: there is no control variant %q", request.Control)
+	}
+
+	// Draw from the posteriors, stopping if the client goes away
+	result, err := abtest.Analyze(ctx, variants, abtest.Options{
+		Control:     control,
+		Confidence:  request.Confidence,
+		PriorAlpha:  request.PriorAlpha,
+		PriorBeta:   request.PriorBeta,
+		Samples:     request.Samples,
+		Parallelism: request.Parallelism,
+		Rand:        rand.New(rand.NewSource(request.Seed)),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apierror.Errorf(apierror.CodeOf(err), "A/B test analysis cancelled")
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid analysis: %v", err)
+	}
+
+	best := result.Variants[result.Best]
+	summary := fmt.Sprintf("%s converts best with probability %.3f at %.4g%%, giving up %.4g%% in expectation",
+		best.Name, best.ProbabilityBest, 100*best.Rate, 100*best.ExpectedLoss)
+	if best.VsControl != nil {
+		summary += fmt.Sprintf("; against %s it lifts the rate %+.4g%% (p = %.3g", request.Control, 100*best.VsControl.RelativeLift, best.VsControl.PValue)
+		if !best.VsControl.Significant {
+			summary += ", not significant"
+		}
+		summary += ")"
+	}
+
+	recorded := make([]types.ABTestVariant, len(result.Variants))
+	for i, v := range result.Variants {
+		recorded[i] = types.ABTestVariant{
+			Name:            v.Name,
+			Visitors:        v.Visitors,
+			Conversions:     v.Conversions,
+			Rate:            v.Rate,
+			PosteriorMean:   v.PosteriorMean,
+			ProbabilityBest: v.ProbabilityBest,
+			ExpectedLoss:    v.ExpectedLoss,
+		}
+		if v.VsControl != nil {
+			recorded[i].PValue = &v.VsControl.PValue
+			recorded[i].ProbabilityToBeat = &v.VsControl.ProbabilityToBeat
+		}
+	}
+
+	// Create A/B test data
+	abTestData := &types.ABTestData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "ab_test",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"variants":    len(request.Variants),
+				"control":     request.Control,
+				"confidence":  request.Confidence,
+				"prior_alpha": request.PriorAlpha,
+				"prior_beta":  request.PriorBeta,
+				"samples":     request.Samples,
+				"parallelism": request.Parallelism,
+				"seed":        request.Seed,
+			},
+			Result:     summary,
+			Iterations: request.Samples,
+			Value:      &best.PosteriorMean,
+			Rerun:      rerunOf("abtest", request),
+			CreatedAt:  time.Now(),
+		},
+		Control:  request.Control,
+		Best:     best.Name,
+		Variants: recorded,
+	}
+	confidence := setConfidence(&abTestData.StochasticAlgorithmData, best.ProbabilityBest, confidenceProbabilityBest)
+
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &abTestData.StochasticAlgorithmData, abTestData); err != nil {
+		h.logger.WithError(err).Error("Failed to add A/B test data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add A/B test data")
 	}
 
-	// Generate random transition probabilities
-	transitionProbs := make([][]float64, states)
-	for i := range transitionProbs {
-		transitionProbs[i] = make([]float64, states)
-		sum := 0.0
-		for j := range transitionProbs[i] {
-			transitionProbs[i][j] = rand.Float64()
-			sum += transitionProbs[i][j]
+	response := &api.ABTestResponse{
+		AlgorithmID:     abTestData.ID,
+		Status:          "success",
+		Summary:         summary,
+		HasResult:       true,
+		Control:         request.Control,
+		Best:            best.Name,
+		Variants:        make([]api.ABTestVariantResult, len(result.Variants)),
+		Samples:         request.Samples,
+		MonteCarloError: result.MonteCarloError,
+		Confidence:      confidence,
+	}
+	for i, v := range result.Variants {
+		response.Variants[i] = api.ABTestVariantResult{
+			Name:             v.Name,
+			Visitors:         v.Visitors,
+			Conversions:      v.Conversions,
+			Rate:             v.Rate,
+			RateInterval:     api.ConfidenceInterval(v.RateInterval),
+			PosteriorMean:    v.PosteriorMean,
+			CredibleInterval: api.ConfidenceInterval(v.CredibleInterval),
+			ProbabilityBest:  v.ProbabilityBest,
+			ExpectedLoss:     v.ExpectedLoss,
 		}
-		// Normalize
-		for j := range transitionProbs[i] {
-			transitionProbs[i][j] /= sum
+		if c := v.VsControl; c != nil {
+			response.Variants[i].VsControl = &api.ABTestComparison{
+				Difference:         c.Difference,
+				RelativeLift:       c.RelativeLift,
+				DifferenceInterval: api.ConfidenceInterval(c.DifferenceInterval),
+				ZScore:             c.ZScore,
+				PValue:             c.PValue,
+				Significant:        c.Significant,
+				ProbabilityToBeat:  c.ProbabilityToBeat,
+			}
 		}
 	}
+	return response, nil
+}
+
+// CompareRuns handles run comparison requests
+func (h *StochasticHandler) CompareRuns(w http.ResponseWriter, r *http.Request) {
+	var request api.CompareRunsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunCompareRuns(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunCompareRuns compares the runs request names from its session in the
+// tenant of ctx and records the comparison there as a table
+func (h *StochasticHandler) RunCompareRuns(ctx context.Context, request api.CompareRunsRequest) (*api.CompareRunsResponse, error) {
+	// Set defaults
+	if request.Goal == "" {
+		request.Goal = "maximize"
+	}
+	if request.Title == "" {
+		request.Title = "Stochastic run comparison"
+	}
+	if request.Goal != "maximize" && request.Goal != "minimize" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: unknown goal %q", request.Goal)
+	}
+	if len(request.AlgorithmIDs) < 2 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: at least 2 runs are needed")
+	}
 
-	// Generate random emission probabilities
-	emissionProbs := make([][]float64, states)
-	for i := range emissionProbs {
-		emissionProbs[i] = make([]float64, observations)
-		sum := 0.0
-		for j := range emissionProbs[i] {
-			emissionProbs[i][j] = rand.Float64()
-			sum += emissionProbs[i][j]
+	store := tenantStore(ctx, h.storage)
+	algorithms, err := store.GetStochasticAlgorithms(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get stochastic algorithm data")
+	}
+	byID := make(map[string]*types.StochasticAlgorithmData, len(algorithms))
+	for _, algorithm := range algorithms {
+		byID[algorithm.ID] = algorithm
+	}
+
+	runs := make([]api.RunComparison, len(request.AlgorithmIDs))
+	seen := make(map[string]bool, len(request.AlgorithmIDs))
+	for i, id := range request.AlgorithmIDs {
+		algorithm, ok := byID[id]
+		if !ok {
+			return nil, apierror.Errorf(apierror.CodeRecordNotFound, "Run %s not found in session %s", id, request.SessionID)
+		}
+		if seen[id] {
+			return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid comparison: run %s is listed twice", id)
 		}
-		// Normalize
-		for j := range emissionProbs[i] {
-			emissionProbs[i][j] /= sum
+		seen[id] = true
+		runs[i] = api.RunComparison{
+			AlgorithmID:      id,
+			Algorithm:        algorithm.Algorithm,
+			Problem:          algorithm.Problem,
+			Value:            algorithm.Value,
+			Iterations:       algorithm.Iterations,
+			Converged:        algorithm.Converged,
+			StoppingReason:   algorithm.StoppingReason,
+			Confidence:       algorithm.Confidence,
+			ConfidenceMethod: algorithm.ConfidenceMethod,
 		}
 	}
 
-	// Generate random initial probabilities
-	initialProbs := make([]float64, states)
-	sum := 0.0
-	for i := range initialProbs {
-		initialProbs[i] = rand.Float64()
-		sum += initialProbs[i]
+	// The runs that stand out: the best value for the goal, the fewest
+	// iterations to converge and the highest confidence
+	response := &api.CompareRunsResponse{Status: "success", Runs: runs}
+	better := func(a, b float64) bool {
+		if request.Goal == "minimize" {
+			return a < b
+		}
+		return a > b
+	}
+	best, fastest, confident := -1, -1, -1
+	for i, run := range runs {
+		if run.Value != nil && (best < 0 || better(*run.Value, *runs[best].Value)) {
+			best = i
+		}
+		if run.Converged && (fastest < 0 || run.Iterations < runs[fastest].Iterations) {
+			fastest = i
+		}
+		if run.Confidence > 0 && (confident < 0 || run.Confidence > runs[confident].Confidence) {
+			confident = i
+		}
+	}
+	var standouts []string
+	if best >= 0 {
+		response.BestValue = runs[best].AlgorithmID
+		standouts = append(standouts, fmt.Sprintf("best value %.4g from %s (%s)", *runs[best].Value, runs[best].AlgorithmID, runs[best].Algorithm))
+	}
+	if fastest >= 0 {
+		response.Fastest = runs[fastest].AlgorithmID
+		standouts = append(standouts, fmt.Sprintf("fastest to converge %s (%s) in %d iterations", runs[fastest].AlgorithmID, runs[fastest].Algorithm, runs[fastest].Iterations))
 	}
-	// Normalize
-	for i := range initialProbs {
-		initialProbs[i] /= sum
+	if confident >= 0 {
+		response.MostConfident = runs[confident].AlgorithmID
+		standouts = append(standouts, fmt.Sprintf("most confident %s (%s) at %.2f", runs[confident].AlgorithmID, runs[confident].Algorithm, runs[confident].Confidence))
+	}
+	response.Summary = fmt.Sprintf("Compared %d runs", len(runs))
+	if len(standouts) > 0 {
+		response.Summary += ": " + strings.Join(standouts, "; ")
+	}
+	response.Table = comparisonTable(request.Title, runs, response)
+
+	// Record the comparison as a table whose rows are the runs
+	elements := make([]types.VisualElement, len(runs))
+	for i, run := range runs {
+		elements[i] = types.VisualElement{
+			ID:    run.AlgorithmID,
+			Type:  "row",
+			Label: run.Algorithm,
+			Properties: map[string]interface{}{
+				"problem":           run.Problem,
+				"iterations":        run.Iterations,
+				"converged":         run.Converged,
+				"stopping_reason":   run.StoppingReason,
+				"confidence":        run.Confidence,
+				"confidence_method": run.ConfidenceMethod,
+			},
+		}
+		if run.Value != nil {
+			elements[i].Properties["value"] = *run.Value
+		}
+	}
+	visual := &types.VisualData{
+		Operation:   "create",
+		Elements:    elements,
+		DiagramID:   "comparison:" + strings.Join(request.AlgorithmIDs, ","),
+		DiagramType: "table",
+		Observation: request.Title,
+		Insight:     response.Summary,
+		CreatedAt:   time.Now(),
 	}
 
-	return stateSequence, transitionProbs, emissionProbs, initialProbs
+	// Add to storage
+	if err := store.AddVisualData(request.SessionID, visual); err != nil {
+		h.logger.WithError(err).Error("Failed to add comparison data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add comparison data")
+	}
+
+	response.VisualID = visual.ID
+	return response, nil
+}
+
+// comparisonTable renders compared runs as a Markdown table, noting the runs
+// that stand out
+func comparisonTable(title string, runs []api.RunComparison, response *api.CompareRunsResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", title)
+	b.WriteString("| Run | Algorithm | Value | Iterations | Converged | Stopping reason | Confidence | Notes |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, run := range runs {
+		value, confidence := "-", "-"
+		if run.Value != nil {
+			value = fmt.Sprintf("%.4g", *run.Value)
+		}
+		if run.Confidence > 0 {
+			confidence = fmt.Sprintf("%.2f", run.Confidence)
+			if run.ConfidenceMethod != "" {
+				confidence += fmt.Sprintf(" (%s)", run.ConfidenceMethod)
+			}
+		}
+		var notes []string
+		if run.AlgorithmID == response.BestValue {
+			notes = append(notes, "best value")
+		}
+		if run.AlgorithmID == response.Fastest {
+			notes = append(notes, "fastest")
+		}
+		if run.AlgorithmID == response.MostConfident {
+			notes = append(notes, "most confident")
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %t | %s | %s | %s |\n", run.AlgorithmID, run.Algorithm, value, run.Iterations,
+			run.Converged, run.StoppingReason, confidence, strings.Join(notes, ", "))
+	}
+	return b.String()
 }
 
 // Helper methods
 
+// ProgressFunc receives the best-so-far results of a streamed run
+type ProgressFunc func(api.StreamUpdate)
+
+// stream responds to a streamed run with server-sent events: a progress
+// event for each best-so-far result run sends, then a result event with the
+// response or an error event. A run failing before its first result responds
+// with the error alone, as unstreamed runs do.
+func (h *StochasticHandler) stream(w http.ResponseWriter, r *http.Request, run func(context.Context, ProgressFunc) (interface{}, error)) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.respondWithError(w, apierror.CodeInternal, "Streaming not supported")
+		return
+	}
+
+	started := false
+	send := func(event string, data interface{}) {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to encode stream event")
+			return
+		}
+		if !started {
+			// The stream outlives the server's write timeout
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		flusher.Flush()
+	}
+
+	response, err := run(r.Context(), func(update api.StreamUpdate) { send("progress", update) })
+	switch {
+	case err == nil:
+		send("result", response)
+	case started:
+		send("error", map[string]*apierror.Error{"error": apierror.Errorf(apierror.CodeOf(err), "%s", err.Error())})
+	default:
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+	}
+}
+
 func (h *StochasticHandler) respondWithJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *StochasticHandler) respondWithError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+func (h *StochasticHandler) respondWithError(w http.ResponseWriter, code apierror.Code, message string) {
+	apierror.Write(w, code, message)
 }
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// maxSweepConfigurations bounds the configurations a sweep runs
+const maxSweepConfigurations = 1000
+
+// ParameterSweep handles parameter sweep requests
+func (h *StochasticHandler) ParameterSweep(w http.ResponseWriter, r *http.Request) {
+	var request api.ParameterSweepRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
+	}
+
+	response, err := h.RunParameterSweep(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, response)
+}
+
+// RunParameterSweep runs request's algorithm on each configuration of its
+// parameters, several at once, and records the sweep in its session in the
+// tenant of ctx. The runs themselves are kept in a scratch store, so only the
+// sweep is recorded. The sweep stops once ctx is done.
+func (h *StochasticHandler) RunParameterSweep(ctx context.Context, request api.ParameterSweepRequest) (*api.ParameterSweepResponse, error) {
+	// Set defaults
+	if request.Mode == "" {
+		request.Mode = "grid"
+	}
+	if request.Samples == 0 {
+		request.Samples = 20
+	}
+	if request.Goal == "" {
+		request.Goal = "maximize"
+		if request.Algorithm == "annealing" {
+			request.Goal = "minimize"
+		}
+	}
+	if request.Parallelism == 0 {
+		request.Parallelism = runtime.GOMAXPROCS(0)
+	}
+	if request.Seed == 0 {
+		request.Seed = time.Now().UnixNano()
+	}
+
+	_, ok := h.Algorithm(request.Algorithm)
+	switch {
+	case !ok:
+		var names []string
+		for _, algorithm := range h.Algorithms() {
+			names = append(names, algorithm.Name())
+		}
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sweep: unknown algorithm %q; the algorithms are %s", request.Algorithm, strings.Join(names, ", "))
+	case request.Goal != "maximize" && request.Goal != "minimize":
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sweep: unknown goal %q", request.Goal)
+	case request.Samples > maxSweepConfigurations || request.Parallelism > 64:
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sweep: at most %d samples and a parallelism of 64", maxSweepConfigurations)
+	}
+	configurations, err := sweepConfigurations(request)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sweep: %v", err)
+	}
+
+	// Run the configurations, stopping if the client goes away
+	results := h.runConfigurations(ctx, request.Algorithm, request, configurations, request.Parallelism)
+	if err := ctx.Err(); err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Parameter sweep cancelled")
+	}
+
+	better := func(a, b float64) bool {
+		if request.Goal == "minimize" {
+			return a < b
+		}
+		return a > b
+	}
+	best := -1
+	for i, result := range results {
+		if result.Value != nil && (best < 0 || better(*result.Value, *results[best].Value)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid sweep: no configuration produced a value; the first failed with: %s", results[0].Error)
+	}
+
+	metric := request.Metric
+	if metric == "" {
+		metric = "value"
+	}
+	summary := fmt.Sprintf("Best %s %.4g of %d %s configurations at %s", metric, *results[best].Value, len(results), request.Algorithm,
+		describeConfiguration(results[best].Configuration, request.Parameters))
+
+	stored := make([]types.SweepResult, len(results))
+	for i, result := range results {
+		stored[i] = types.SweepResult(result)
+	}
+
+	// Create parameter sweep data
+	sweepData := &types.ParameterSweepData{
+		StochasticAlgorithmData: types.StochasticAlgorithmData{
+			Algorithm: "parameter_sweep",
+			Problem:   request.Problem,
+			Parameters: map[string]interface{}{
+				"algorithm":   request.Algorithm,
+				"parameters":  len(request.Parameters),
+				"mode":        request.Mode,
+				"samples":     request.Samples,
+				"metric":      metric,
+				"goal":        request.Goal,
+				"parallelism": request.Parallelism,
+				"seed":        request.Seed,
+			},
+			Result:     summary,
+			Iterations: len(results),
+			Value:      results[best].Value,
+			CreatedAt:  time.Now(),
+		},
+		Results: stored,
+		Best:    &stored[best],
+	}
+
+	// Add to storage
+	if err := addRun(tenantStore(ctx, h.storage), request.SessionID, &sweepData.StochasticAlgorithmData, sweepData); err != nil {
+		h.logger.WithError(err).Error("Failed to add parameter sweep data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add parameter sweep data")
+	}
+
+	return &api.ParameterSweepResponse{
+		AlgorithmID:    sweepData.ID,
+		Status:         "success",
+		Summary:        summary,
+		HasResult:      true,
+		Algorithm:      request.Algorithm,
+		Metric:         metric,
+		Goal:           request.Goal,
+		Configurations: len(results),
+		Best:           &results[best],
+		Results:        results,
+	}, nil
+}
+
+// runConfigurations runs the registered algorithm name on each configuration
+// of a sweep, parallelism at once, until ctx is done. The runs are kept in a
+// scratch store, so none is recorded in the tenant of ctx.
+func (h *StochasticHandler) runConfigurations(ctx context.Context, name string, sweep api.ParameterSweepRequest, configurations []map[string]interface{}, parallelism int) []api.SweepResult {
+	scratch := &StochasticHandler{storage: storage.NewMemoryStore(&config.Config{}), logger: h.logger}
+	algorithm, _ := scratch.Algorithm(name)
+	results := make([]api.SweepResult, len(configurations))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(parallelism, len(configurations)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = scratch.runConfiguration(ctx, algorithm, sweep, i, configurations[i])
+			}
+		}()
+	}
+	for i := range configurations {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// runConfiguration runs configuration i of a sweep in a session of its own
+// and reads its metric: the run's recorded value, or the response field the
+// sweep names
+func (h *StochasticHandler) runConfiguration(ctx context.Context, algorithm StochasticAlgorithm, sweep api.ParameterSweepRequest, i int, configuration map[string]interface{}) api.SweepResult {
+	result := api.SweepResult{Configuration: configuration}
+	// The sweep already runs configurations at once, so their runs default to
+	// a single goroutine each
+	request := map[string]interface{}{"problem": sweep.Problem, "seed": sweep.Seed, "parallelism": 1}
+	for name, value := range sweep.Request {
+		request[name] = value
+	}
+	for name, value := range configuration {
+		request[name] = value
+	}
+	sessionID := fmt.Sprintf("configuration-%d", i+1)
+	request["session_id"] = sessionID
+	raw, err := json.Marshal(request)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	response, err := algorithm.Run(ctx, raw, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	records, err := tenantStore(ctx, h.storage).GetStochasticAlgorithms(sessionID, nil)
+	if err != nil || len(records) == 0 {
+		result.Error = "the run was not recorded"
+		return result
+	}
+	record := records[len(records)-1]
+	result.Converged, result.Iterations = record.Converged, record.Iterations
+
+	if sweep.Metric == "" {
+		if record.Value == nil {
+			result.Error = "the run has no value; name a metric"
+			return result
+		}
+		result.Value = record.Value
+		return result
+	}
+	value, err := responseField(response, sweep.Metric)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Value = &value
+	return result
+}
+
+// responseField reads the numeric field of response at a dotted path
+func responseField(response interface{}, path string) (float64, error) {
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return 0, err
+	}
+	var field interface{}
+	if err := json.Unmarshal(raw, &field); err != nil {
+		return 0, err
+	}
+	for _, name := range strings.Split(path, ".") {
+		object, ok := field.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("the response has no field %s", path)
+		}
+		if field, ok = object[name]; !ok {
+			return 0, fmt.Errorf("the response has no field %s", path)
+		}
+	}
+	value, ok := field.(float64)
+	if !ok {
+		return 0, fmt.Errorf("the response field %s is not a number", path)
+	}
+	return value, nil
+}
+
+// sweepConfigurations returns the configurations of request's parameters:
+// every combination of their values in grid mode, or random samples
+func sweepConfigurations(request api.ParameterSweepRequest) ([]map[string]interface{}, error) {
+	if len(request.Parameters) == 0 {
+		return nil, fmt.Errorf("there are no parameters to sweep")
+	}
+	seen := map[string]bool{"session_id": true, "problem": true}
+	for _, p := range request.Parameters {
+		switch {
+		case p.Name == "" || seen[p.Name]:
+			return nil, fmt.Errorf("parameters need distinct names other than session_id and problem")
+		case len(p.Values) == 0 && !(p.Min < p.Max):
+			return nil, fmt.Errorf("parameter %s needs values or min below max", p.Name)
+		case len(p.Values) == 0 && p.Log && !(p.Min > 0):
+			return nil, fmt.Errorf("parameter %s needs a positive min on a log scale", p.Name)
+		case p.Steps < 0 || p.Steps > 100:
+			return nil, fmt.Errorf("parameter %s needs from 1 to 100 steps", p.Name)
+		}
+		seen[p.Name] = true
+	}
+
+	switch request.Mode {
+	case "grid":
+		configurations := []map[string]interface{}{{}}
+		for _, p := range request.Parameters {
+			values := gridValues(p)
+			if len(configurations)*len(values) > maxSweepConfigurations {
+				return nil, fmt.Errorf("the grid has more than %d configurations", maxSweepConfigurations)
+			}
+			var next []map[string]interface{}
+			for _, configuration := range configurations {
+				for _, value := range values {
+					extended := make(map[string]interface{}, len(configuration)+1)
+					for name, v := range configuration {
+						extended[name] = v
+					}
+					extended[p.Name] = value
+					next = append(next, extended)
+				}
+			}
+			configurations = next
+		}
+		return configurations, nil
+
+	case "random":
+		r := rand.New(rand.NewSource(request.Seed))
+		configurations := make([]map[string]interface{}, request.Samples)
+		for i := range configurations {
+			configurations[i] = make(map[string]interface{}, len(request.Parameters))
+			for _, p := range request.Parameters {
+				if len(p.Values) > 0 {
+					configurations[i][p.Name] = p.Values[r.Intn(len(p.Values))]
+					continue
+				}
+				x := p.Min + r.Float64()*(p.Max-p.Min)
+				if p.Log {
+					x = math.Exp(math.Log(p.Min) + r.Float64()*(math.Log(p.Max)-math.Log(p.Min)))
+				}
+				configurations[i][p.Name] = sweepValue(p, x)
+			}
+		}
+		return configurations, nil
+	}
+	return nil, fmt.Errorf("unknown mode %q", request.Mode)
+}
+
+// gridValues returns the values a grid tries for p: its values, or evenly
+// spaced values from its min to its max without repeats
+func gridValues(p api.SweepParameter) []interface{} {
+	if len(p.Values) > 0 {
+		return p.Values
+	}
+	steps := p.Steps
+	if steps == 0 {
+		steps = 5
+	}
+	var values []interface{}
+	seen := make(map[interface{}]bool, steps)
+	for k := 0; k < steps; k++ {
+		fraction := 0.0
+		if steps > 1 {
+			fraction = float64(k) / float64(steps-1)
+		}
+		x := p.Min + fraction*(p.Max-p.Min)
+		if p.Log {
+			x = math.Exp(math.Log(p.Min) + fraction*(math.Log(p.Max)-math.Log(p.Min)))
+		}
+		if value := sweepValue(p, x); !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// sweepValue returns x as a value of p, rounded for integer parameters
+func sweepValue(p api.SweepParameter, x float64) interface{} {
+	if p.Integer {
+		return int(math.Round(x))
+	}
+	return x
+}
+
+// describeConfiguration lists a configuration's values in the order of the
+// swept parameters
+func describeConfiguration(configuration map[string]interface{}, parameters []api.SweepParameter) string {
+	values := make([]string, len(parameters))
+	for i, p := range parameters {
+		values[i] = fmt.Sprintf("%s=%v", p.Name, configuration[p.Name])
+	}
+	return strings.Join(values, ", ")
+}
@@ -1,23 +1,26 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
 	"github.com/rainmana/gothink/internal/storage"
 	"github.com/rainmana/gothink/internal/types"
 )
 
 // ThinkingHandler handles systematic thinking operations
 type ThinkingHandler struct {
-	storage *storage.Storage
+	storage storage.Store
 	logger  *logrus.Logger
 }
 
 // NewThinkingHandler creates a new thinking handler
-func NewThinkingHandler(storage *storage.Storage, logger *logrus.Logger) *ThinkingHandler {
+func NewThinkingHandler(storage storage.Store, logger *logrus.Logger) *ThinkingHandler {
 	return &ThinkingHandler{
 		storage: storage,
 		logger:  logger,
@@ -26,24 +29,23 @@ func NewThinkingHandler(storage *storage.Storage, logger *logrus.Logger) *Thinki
 
 // SequentialThinking handles sequential thinking requests
 func (h *ThinkingHandler) SequentialThinking(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID         string `json:"session_id"`
-		Thought           string `json:"thought"`
-		ThoughtNumber     int    `json:"thought_number"`
-		TotalThoughts     int    `json:"total_thoughts"`
-		NextThoughtNeeded bool   `json:"next_thought_needed"`
-		IsRevision        bool   `json:"is_revision,omitempty"`
-		RevisesThought    *int   `json:"revises_thought,omitempty"`
-		BranchFromThought *int   `json:"branch_from_thought,omitempty"`
-		BranchID          string `json:"branch_id,omitempty"`
-		NeedsMoreThoughts bool   `json:"needs_more_thoughts,omitempty"`
+	var request api.SequentialThinkingRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
+	response, err := h.RecordThought(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
 		return
 	}
 
+	h.respondWithJSON(w, response)
+}
+
+// RecordThought adds the thought of request to its session in the tenant of ctx
+func (h *ThinkingHandler) RecordThought(ctx context.Context, request api.SequentialThinkingRequest) (*api.SequentialThinkingResponse, error) {
 	// Create thought data
 	thought := &types.ThoughtData{
 		ID:                "",
@@ -60,53 +62,51 @@ func (h *ThinkingHandler) SequentialThinking(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Add to storage
-	if err := h.storage.AddThought(request.SessionID, thought); err != nil {
+	store := tenantStore(ctx, h.storage)
+	if err := store.AddThought(request.SessionID, thought); err != nil {
 		h.logger.WithError(err).Error("Failed to add thought")
-		h.respondWithError(w, "Failed to add thought", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add thought")
 	}
 
-	// Get session context
-	stats, err := h.storage.GetSessionStats(request.SessionID)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get session stats")
+	// Prepare response with the session context
+	response := &api.SequentialThinkingResponse{
+		ThoughtID:      thought.ID,
+		Status:         "success",
+		SessionContext: api.ThoughtSessionContext{SessionID: request.SessionID},
 	}
-
-	// Prepare response
-	response := map[string]interface{}{
-		"thought_id": thought.ID,
-		"status":     "success",
-		"session_context": map[string]interface{}{
-			"session_id":         request.SessionID,
-			"total_thoughts":     stats.ThoughtCount,
-			"remaining_thoughts": stats.RemainingThoughts,
-		},
+	if stats, err := store.GetSessionStats(request.SessionID); err != nil {
+		h.logger.WithError(err).Error("Failed to get session stats")
+	} else {
+		response.SessionContext.TotalThoughts = stats.ThoughtCount
+		response.SessionContext.RemainingThoughts = stats.RemainingThoughts
 	}
 
-	h.respondWithJSON(w, response)
+	return response, nil
 }
 
 // MentalModel handles mental model application requests
 func (h *ThinkingHandler) MentalModel(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID  string   `json:"session_id"`
-		ModelName  string   `json:"model_name"`
-		Problem    string   `json:"problem"`
-		Steps      []string `json:"steps"`
-		Reasoning  string   `json:"reasoning"`
-		Conclusion string   `json:"conclusion"`
-		Confidence float64  `json:"confidence,omitempty"`
+	var request api.MentalModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
+	response, err := h.ApplyMentalModel(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
 		return
 	}
 
+	h.respondWithJSON(w, response)
+}
+
+// ApplyMentalModel adds the mental model application of request to its
+// session in the tenant of ctx
+func (h *ThinkingHandler) ApplyMentalModel(ctx context.Context, request api.MentalModelRequest) (*api.MentalModelResponse, error) {
 	// Validate model name
 	if _, exists := types.MentalModels[request.ModelName]; !exists {
-		h.respondWithError(w, "Invalid mental model", http.StatusBadRequest)
-		return
+		return nil, apierror.Errorf(apierror.CodeModelNotFound, "Invalid mental model")
 	}
 
 	// Create mental model data
@@ -122,53 +122,53 @@ func (h *ThinkingHandler) MentalModel(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Add to storage
-	if err := h.storage.AddMentalModel(request.SessionID, model); err != nil {
+	store := tenantStore(ctx, h.storage)
+	if err := store.AddMentalModel(request.SessionID, model); err != nil {
 		h.logger.WithError(err).Error("Failed to add mental model")
-		h.respondWithError(w, "Failed to add mental model", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add mental model")
 	}
 
-	// Get session context
-	stats, err := h.storage.GetSessionStats(request.SessionID)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get session stats")
+	// Prepare response with the session context
+	response := &api.MentalModelResponse{
+		ModelID:        model.ID,
+		Status:         "success",
+		HasSteps:       len(request.Steps) > 0,
+		HasConclusion:  request.Conclusion != "",
+		SessionContext: api.MentalModelSessionContext{SessionID: request.SessionID},
 	}
-
-	// Prepare response
-	response := map[string]interface{}{
-		"model_id":       model.ID,
-		"status":         "success",
-		"has_steps":      len(request.Steps) > 0,
-		"has_conclusion": request.Conclusion != "",
-		"session_context": map[string]interface{}{
-			"session_id":          request.SessionID,
-			"total_mental_models": stats.Stores["mental_models"].(map[string]int)["count"],
-		},
+	if stats, err := store.GetSessionStats(request.SessionID); err != nil {
+		h.logger.WithError(err).Error("Failed to get session stats")
+	} else if counts, ok := stats.Stores["mental_models"].(map[string]int); ok {
+		response.SessionContext.TotalMentalModels = counts["count"]
 	}
 
-	h.respondWithJSON(w, response)
+	return response, nil
 }
 
 // DebuggingApproach handles debugging approach requests
 func (h *ThinkingHandler) DebuggingApproach(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID    string   `json:"session_id"`
-		ApproachName string   `json:"approach_name"`
-		Issue        string   `json:"issue"`
-		Steps        []string `json:"steps"`
-		Findings     string   `json:"findings"`
-		Resolution   string   `json:"resolution"`
+	var request api.DebuggingApproachRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
+	response, err := h.RecordDebuggingApproach(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
 		return
 	}
 
+	h.respondWithJSON(w, response)
+}
+
+// RecordDebuggingApproach adds the debugging approach of request to its
+// session in the tenant of ctx
+func (h *ThinkingHandler) RecordDebuggingApproach(ctx context.Context, request api.DebuggingApproachRequest) (*api.DebuggingApproachResponse, error) {
 	// For now, we'll store this as a mental model with a special type
 	model := &types.MentalModelData{
 		ID:         "",
-		ModelName:  "debugging_" + request.ApproachName,
+		ModelName:  types.DebuggingModelPrefix + request.ApproachName,
 		Problem:    request.Issue,
 		Steps:      request.Steps,
 		Reasoning:  request.Findings,
@@ -177,20 +177,17 @@ func (h *ThinkingHandler) DebuggingApproach(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Add to storage
-	if err := h.storage.AddMentalModel(request.SessionID, model); err != nil {
+	if err := tenantStore(ctx, h.storage).AddMentalModel(request.SessionID, model); err != nil {
 		h.logger.WithError(err).Error("Failed to add debugging approach")
-		h.respondWithError(w, "Failed to add debugging approach", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add debugging approach")
 	}
 
-	response := map[string]interface{}{
-		"approach_id":    model.ID,
-		"status":         "success",
-		"has_findings":   request.Findings != "",
-		"has_resolution": request.Resolution != "",
-	}
-
-	h.respondWithJSON(w, response)
+	return &api.DebuggingApproachResponse{
+		ApproachID:    model.ID,
+		Status:        "success",
+		HasFindings:   request.Findings != "",
+		HasResolution: request.Resolution != "",
+	}, nil
 }
 
 // CollaborativeReasoning handles collaborative reasoning requests
@@ -250,8 +247,6 @@ func (h *ThinkingHandler) respondWithJSON(w http.ResponseWriter, data interface{
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *ThinkingHandler) respondWithError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+func (h *ThinkingHandler) respondWithError(w http.ResponseWriter, code apierror.Code, message string) {
+	apierror.Write(w, code, message)
 }
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/mcts"
+	"github.com/rainmana/gothink/internal/particle"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Diagram types of the traces runs record
+const (
+	traceConvergencePlot = "convergence-plot"
+	traceSearchTree      = "search-tree"
+	traceParticleCloud   = "particle-cloud"
+)
+
+// A traced search keeps its traceTreeNodes most visited nodes, and a traced
+// particle filter traceParticles particles of each step
+const (
+	traceTreeNodes = 100
+	traceParticles = 50
+)
+
+// recordTrace records elements, a diagram of diagramType tracing run, as
+// visual data in its session in the tenant of ctx, returning its ID. The
+// diagram's ID is trace: and the run's.
+func (h *StochasticHandler) recordTrace(ctx context.Context, sessionID string, run *types.StochasticAlgorithmData, diagramType string, elements []types.VisualElement) (string, error) {
+	visual := &types.VisualData{
+		Operation:   "create",
+		Elements:    elements,
+		DiagramID:   "trace:" + run.ID,
+		DiagramType: diagramType,
+		Iteration:   run.Iterations,
+		Observation: run.Problem,
+		Insight:     run.Result,
+		CreatedAt:   time.Now(),
+	}
+	if err := tenantStore(ctx, h.storage).AddVisualData(sessionID, visual); err != nil {
+		h.logger.WithError(err).Error("Failed to add trace data")
+		return "", apierror.Errorf(apierror.CodeOf(err), "Failed to add trace data")
+	}
+	return visual.ID, nil
+}
+
+// plotPoints returns the points of a convergence plot, in order, each with
+// the properties of its iteration
+func plotPoints(points []map[string]interface{}) []types.VisualElement {
+	elements := make([]types.VisualElement, len(points))
+	for i, properties := range points {
+		elements[i] = types.VisualElement{ID: fmt.Sprintf("point-%d", i+1), Type: "point", Properties: properties}
+	}
+	return elements
+}
+
+// residualPlot returns a convergence plot of the residual of each iteration
+func residualPlot(residuals []float64) []types.VisualElement {
+	points := make([]map[string]interface{}, len(residuals))
+	for i, residual := range residuals {
+		points[i] = map[string]interface{}{"iteration": i + 1, "residual": residual}
+	}
+	return plotPoints(points)
+}
+
+// searchTree returns the nodes of tree and the edges of its moves. An edge's
+// probability is the share of its parent's visits that took the move.
+func searchTree(tree *mcts.TreeNode) []types.VisualElement {
+	var elements []types.VisualElement
+	var walk func(n *mcts.TreeNode, id string)
+	walk = func(n *mcts.TreeNode, id string) {
+		elements = append(elements, types.VisualElement{
+			ID:    id,
+			Type:  "node",
+			Label: n.State,
+			Properties: map[string]interface{}{
+				"depth":    n.Depth,
+				"expanded": n.Expanded,
+				"visits":   n.Visits,
+				"q":        n.Q,
+			},
+		})
+		for i, child := range n.Children {
+			childID := fmt.Sprintf("%s.%d", id, i)
+			edge := types.VisualElement{ID: id + "->" + childID, Type: "edge", Label: child.Move, Source: id, Target: childID, Properties: map[string]interface{}{}}
+			if n.Visits > 0 {
+				edge.Probability = float64(child.Visits) / float64(n.Visits)
+			}
+			elements = append(elements, edge)
+			walk(child, childID)
+		}
+	}
+	if tree != nil {
+		walk(tree, "root")
+	}
+	return elements
+}
+
+// particleClouds returns an element for each step of a filter, containing
+// the particles of its cloud
+func particleClouds(steps []particle.Step) []types.VisualElement {
+	var elements []types.VisualElement
+	for _, step := range steps {
+		stepID := fmt.Sprintf("step-%d", step.Step)
+		estimates := make(map[string]interface{}, len(step.Estimates))
+		for name, estimate := range step.Estimates {
+			estimates[name] = estimate.Mean
+		}
+		cloud := types.VisualElement{
+			ID:   stepID,
+			Type: "step",
+			Properties: map[string]interface{}{
+				"step":        step.Step,
+				"observation": step.Observation,
+				"estimates":   estimates,
+				"resampled":   step.Resampled,
+			},
+		}
+		particles := make([]types.VisualElement, len(step.Cloud))
+		for i, p := range step.Cloud {
+			properties := map[string]interface{}{"step": step.Step, "weight": p.Weight}
+			for name, value := range p.Values {
+				properties[name] = value
+			}
+			particles[i] = types.VisualElement{ID: fmt.Sprintf("%s-particle-%d", stepID, i+1), Type: "particle", Properties: properties}
+			cloud.Contains = append(cloud.Contains, particles[i].ID)
+		}
+		elements = append(append(elements, cloud), particles...)
+	}
+	return elements
+}
+
+// regretPlot returns a convergence plot of a bandit's regret curve
+func regretPlot(curve []types.RegretPoint) []types.VisualElement {
+	points := make([]map[string]interface{}, len(curve))
+	for i, point := range curve {
+		points[i] = map[string]interface{}{"iteration": point.Step, "regret": point.Regret}
+	}
+	return plotPoints(points)
+}
+
+// optimizationPlot returns a convergence plot of the value of each
+// evaluation of an optimization and the best value, for goal, so far
+func optimizationPlot(history []types.OptimizationStep, goal string) []types.VisualElement {
+	points := make([]map[string]interface{}, len(history))
+	for i, step := range history {
+		best := step.Value
+		if i > 0 {
+			previous := points[i-1]["best"].(float64)
+			if goal == "minimize" && previous < best || goal != "minimize" && previous > best {
+				best = previous
+			}
+		}
+		points[i] = map[string]interface{}{"iteration": step.Iteration, "value": step.Value, "best": best, "random": step.Random}
+	}
+	return plotPoints(points)
+}
+
+// learningPlot returns a convergence plot of the reward of each episode of
+// learning
+func learningPlot(curve []types.LearningEpisode) []types.VisualElement {
+	points := make([]map[string]interface{}, len(curve))
+	for i, episode := range curve {
+		points[i] = map[string]interface{}{"iteration": episode.Episode, "reward": episode.Reward, "epsilon": episode.Epsilon, "residual": episode.Residual}
+	}
+	return plotPoints(points)
+}
+
+// annealingPlot returns a convergence plot of the points of an annealing
+// trajectory
+func annealingPlot(trajectory []types.AnnealingStep) []types.VisualElement {
+	points := make([]map[string]interface{}, len(trajectory))
+	for i, step := range trajectory {
+		points[i] = map[string]interface{}{"iteration": step.Iteration, "value": step.Value, "best": step.BestValue, "temperature": step.Temperature}
+	}
+	return plotPoints(points)
+}
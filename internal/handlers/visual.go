@@ -1,23 +1,29 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/flowchart"
 	"github.com/rainmana/gothink/internal/storage"
 	"github.com/rainmana/gothink/internal/types"
 )
 
 // VisualHandler handles visualization operations
 type VisualHandler struct {
-	storage *storage.Storage
+	storage storage.Store
 	logger  *logrus.Logger
 }
 
 // NewVisualHandler creates a new visual handler
-func NewVisualHandler(storage *storage.Storage, logger *logrus.Logger) *VisualHandler {
+func NewVisualHandler(storage storage.Store, logger *logrus.Logger) *VisualHandler {
 	return &VisualHandler{
 		storage: storage,
 		logger:  logger,
@@ -26,30 +32,83 @@ func NewVisualHandler(storage *storage.Storage, logger *logrus.Logger) *VisualHa
 
 // ConceptMap handles concept map requests
 func (h *VisualHandler) ConceptMap(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		SessionID           string                `json:"session_id"`
-		DiagramID           string                `json:"diagram_id"`
-		Operation           string                `json:"operation"`
-		Elements            []types.VisualElement `json:"elements,omitempty"`
-		Iteration           int                   `json:"iteration"`
-		Observation         string                `json:"observation,omitempty"`
-		Insight             string                `json:"insight,omitempty"`
-		Hypothesis          string                `json:"hypothesis,omitempty"`
-		NextOperationNeeded bool                  `json:"next_operation_needed"`
+	var request api.ConceptMapRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.respondWithError(w, "Invalid request body", http.StatusBadRequest)
+	response, err := h.RunConceptMap(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
 		return
 	}
 
-	// Create visual data
+	h.respondWithJSON(w, response)
+}
+
+// RunConceptMap applies the operation of request to the current state of its
+// diagram, in its session in the tenant of ctx, and records it. The diagram's
+// state is that its recorded operations leave, as the session's DOT export
+// draws it; update, delete and transform need a diagram to apply to, and
+// delete elements the diagram holds.
+func (h *VisualHandler) RunConceptMap(ctx context.Context, request api.ConceptMapRequest) (*api.ConceptMapResponse, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return apierror.Errorf(apierror.CodeInvalidParameters, "Invalid concept map operation: "+format, args...)
+	}
+	if request.SessionID == "" {
+		return nil, invalid("session_id is required")
+	}
+	if request.DiagramID == "" {
+		request.DiagramID = "default-diagram"
+	}
+
+	store := tenantStore(ctx, h.storage)
+	visuals, err := store.GetVisualData(request.SessionID, nil)
+	if err != nil {
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to get visual data: %v", err)
+	}
+	current := storage.DiagramElements(visuals, request.DiagramID)
+	if request.DiagramType == "" {
+		request.DiagramType = storage.DiagramType(visuals, request.DiagramID)
+	}
+	if request.DiagramType == "" {
+		request.DiagramType = "conceptMap"
+	}
+
+	switch request.Operation {
+	case "create":
+	case "update", "delete", "transform":
+		if len(current) == 0 {
+			return nil, apierror.Errorf(apierror.CodeRecordNotFound, "Diagram %s not found in session %s", request.DiagramID, request.SessionID)
+		}
+		if request.Operation == "update" && len(request.Elements) == 0 {
+			return nil, invalid("an update needs elements")
+		}
+	default:
+		return nil, invalid("operation must be create, update, delete or transform, not %q", request.Operation)
+	}
+	if request.TransformationType != "" && request.Operation != "transform" {
+		return nil, invalid("transformation_type applies only to the transform operation")
+	}
+	held := make(map[string]bool, len(current))
+	for _, element := range current {
+		held[element.ID] = true
+	}
+	for _, element := range request.Elements {
+		switch {
+		case element.ID == "":
+			return nil, invalid("every element needs an ID")
+		case request.Operation == "delete" && !held[element.ID]:
+			return nil, invalid("diagram %s has no element %s", request.DiagramID, element.ID)
+		}
+	}
+
 	visual := &types.VisualData{
-		ID:                  "",
 		Operation:           request.Operation,
-		Elements:            request.Elements,
+		Elements:            VisualElements(request.Elements),
 		DiagramID:           request.DiagramID,
-		DiagramType:         "concept-map",
+		DiagramType:         request.DiagramType,
 		Iteration:           request.Iteration,
 		Observation:         request.Observation,
 		Insight:             request.Insight,
@@ -57,23 +116,69 @@ func (h *VisualHandler) ConceptMap(w http.ResponseWriter, r *http.Request) {
 		NextOperationNeeded: request.NextOperationNeeded,
 		CreatedAt:           time.Now(),
 	}
+	// A transform records the elements it leaves, in a new diagram for an
+	// extract
+	if request.Operation == "transform" {
+		transformed, err := transformConceptMap(current, request)
+		if err != nil {
+			return nil, invalid("%v", err)
+		}
+		visual.TransformationType, visual.Elements = request.TransformationType, transformed
+		if request.TransformationType == transformExtract {
+			visual.DiagramID = request.TargetID
+			if visual.DiagramID == "" {
+				visual.DiagramID = request.DiagramID + ":" + request.NodeIDs[0]
+			}
+			current = storage.DiagramElements(visuals, visual.DiagramID)
+		}
+	}
+	elements, changes := storage.ApplyDiagramOperation(current, visual)
+	elements = layoutOperation(visual, elements)
 
 	// Add to storage
-	if err := h.storage.AddVisualData(request.SessionID, visual); err != nil {
+	if err := store.AddVisualData(request.SessionID, visual); err != nil {
 		h.logger.WithError(err).Error("Failed to add visual data")
-		h.respondWithError(w, "Failed to add visual data", http.StatusInternalServerError)
-		return
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add visual data")
 	}
 
-	response := map[string]interface{}{
-		"visual_id":    visual.ID,
-		"status":       "success",
-		"diagram_type": "concept-map",
-		"operation":    request.Operation,
-		"elements":     len(request.Elements),
+	response := &api.ConceptMapResponse{
+		VisualID:           visual.ID,
+		Status:             "success",
+		DiagramID:          visual.DiagramID,
+		DiagramType:        request.DiagramType,
+		Operation:          request.Operation,
+		TransformationType: request.TransformationType,
+		Elements:           len(request.Elements),
+		Diagram:            make([]api.VisualElement, len(elements)),
+		Changes:            api.DiagramChanges(changes),
+		Summary:            diagramChangeSummary(visual.DiagramID, changes, len(elements)),
+	}
+	for i, element := range elements {
+		response.Diagram[i] = api.VisualElement(element)
 	}
+	return response, nil
+}
 
-	h.respondWithJSON(w, response)
+// diagramChangeSummary says what an operation changed and what it left of
+// diagram diagramID
+func diagramChangeSummary(diagramID string, changes storage.DiagramChanges, left int) string {
+	var parts []string
+	for _, change := range []struct {
+		verb string
+		ids  []string
+	}{{"added", changes.Added}, {"updated", changes.Updated}, {"removed", changes.Removed}} {
+		if len(change.ids) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", change.verb, strings.Join(change.ids, ", ")))
+		}
+	}
+	summary := fmt.Sprintf("Diagram %s now has %d elements", diagramID, left)
+	if left == 0 {
+		summary = fmt.Sprintf("Diagram %s is now empty", diagramID)
+	}
+	if len(parts) == 0 {
+		return summary + ": nothing changed"
+	}
+	return summary + ": " + strings.Join(parts, "; ")
 }
 
 // MindMap handles mind map requests
@@ -88,32 +193,84 @@ func (h *VisualHandler) MindMap(w http.ResponseWriter, r *http.Request) {
 
 // Flowchart handles flowchart requests
 func (h *VisualHandler) Flowchart(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Flowchart not yet implemented",
-		"status":  "coming_soon",
+	var request api.FlowchartRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, apierror.CodeInvalidParameters, "Invalid request body")
+		return
 	}
-	h.respondWithJSON(w, response)
-}
 
-// DecisionTree handles decision tree requests
-func (h *VisualHandler) DecisionTree(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Decision tree not yet implemented",
-		"status":  "coming_soon",
+	response, err := h.RunFlowchart(r.Context(), request)
+	if err != nil {
+		h.respondWithError(w, apierror.CodeOf(err), err.Error())
+		return
 	}
+
 	h.respondWithJSON(w, response)
 }
 
-// ProbabilityTree handles probability tree requests
-func (h *VisualHandler) ProbabilityTree(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
-	response := map[string]interface{}{
-		"message": "Probability tree not yet implemented",
-		"status":  "coming_soon",
+// RunFlowchart checks the flowchart of request and records it as visual data
+// in its session in the tenant of ctx, rendered as Mermaid, Graphviz DOT and
+// a PlantUML activity diagram
+func (h *VisualHandler) RunFlowchart(ctx context.Context, request api.FlowchartRequest) (*api.FlowchartResponse, error) {
+	if request.SessionID == "" {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid flowchart: session_id is required")
 	}
-	h.respondWithJSON(w, response)
+	if request.DiagramID == "" {
+		request.DiagramID = flowchart.DiagramType
+	}
+
+	chart := flowchart.Chart{
+		Nodes:       make([]flowchart.Node, len(request.Nodes)),
+		Connections: make([]flowchart.Connection, len(request.Connections)),
+	}
+	for i, node := range request.Nodes {
+		chart.Nodes[i] = flowchart.Node(node)
+	}
+	for i, c := range request.Connections {
+		chart.Connections[i] = flowchart.Connection(c)
+	}
+	if err := flowchart.Validate(chart); err != nil {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "Invalid flowchart: %v", err)
+	}
+
+	// Nodes keep their type as the element type, and connections are edges
+	elements := make([]types.VisualElement, 0, len(chart.Nodes)+len(chart.Connections))
+	for _, node := range chart.Nodes {
+		elements = append(elements, types.VisualElement{ID: node.ID, Type: node.Type, Label: node.Label, Properties: map[string]interface{}{}})
+	}
+	edges := make(map[string]int, len(chart.Connections))
+	for _, c := range chart.Connections {
+		id := c.From + "->" + c.To
+		if edges[id]++; edges[id] > 1 {
+			id = fmt.Sprintf("%s#%d", id, edges[id])
+		}
+		elements = append(elements, types.VisualElement{ID: id, Type: "edge", Label: c.Label, Source: c.From, Target: c.To, Properties: map[string]interface{}{}})
+	}
+
+	visual := &types.VisualData{
+		Operation:   "create",
+		Elements:    elements,
+		DiagramID:   request.DiagramID,
+		DiagramType: flowchart.DiagramType,
+		Observation: request.Title,
+		CreatedAt:   time.Now(),
+	}
+	elements = layoutOperation(visual, elements)
+	if err := tenantStore(ctx, h.storage).AddVisualData(request.SessionID, visual); err != nil {
+		h.logger.WithError(err).Error("Failed to add visual data")
+		return nil, apierror.Errorf(apierror.CodeOf(err), "Failed to add visual data")
+	}
+
+	return &api.FlowchartResponse{
+		VisualID:    visual.ID,
+		Status:      "success",
+		DiagramID:   request.DiagramID,
+		Nodes:       len(chart.Nodes),
+		Connections: len(chart.Connections),
+		Mermaid:     flowchart.Mermaid(chart),
+		DOT:         storage.DiagramDOT(request.DiagramID, flowchart.DiagramType, elements),
+		PlantUML:    flowchart.PlantUML(chart),
+	}, nil
 }
 
 // BayesianNetwork handles Bayesian network requests
@@ -133,8 +290,6 @@ func (h *VisualHandler) respondWithJSON(w http.ResponseWriter, data interface{})
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *VisualHandler) respondWithError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+func (h *VisualHandler) respondWithError(w http.ResponseWriter, code apierror.Code, message string) {
+	apierror.Write(w, code, message)
 }
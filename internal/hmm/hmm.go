@@ -0,0 +1,377 @@
+// Package hmm runs inference on discrete hidden Markov models. States and
+// observation symbols are numbered from 0. Given a model and an observation
+// sequence, Viterbi decodes the most likely state path and ForwardBackward
+// computes the sequence's log-likelihood and the posterior probability of
+// each state at each step; BaumWelch fits a model's parameters to a sequence
+// by expectation maximization.
+package hmm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+)
+
+// probabilityTolerance is how far a row of probabilities may sum from 1
+const probabilityTolerance = 1e-6
+
+// Model is a hidden Markov model
+type Model struct {
+	// Initial holds the probability of starting in each state
+	Initial []float64
+	// Transition holds, for each state, the probability of moving to each
+	// state next
+	Transition [][]float64
+	// Emission holds, for each state, the probability of emitting each symbol
+	Emission [][]float64
+}
+
+// RandomModel returns a model of states and symbols with random
+// probabilities, a starting point for BaumWelch that breaks the symmetry
+// between states
+func RandomModel(states, symbols int, r *rand.Rand) *Model {
+	row := func(n int) []float64 {
+		p := make([]float64, n)
+		total := 0.0
+		for i := range p {
+			p[i] = 0.5 + r.Float64()
+			total += p[i]
+		}
+		for i := range p {
+			p[i] /= total
+		}
+		return p
+	}
+
+	m := &Model{Initial: row(states), Transition: make([][]float64, states), Emission: make([][]float64, states)}
+	for i := 0; i < states; i++ {
+		m.Transition[i] = row(states)
+		m.Emission[i] = row(symbols)
+	}
+	return m
+}
+
+// States returns the number of states of m
+func (m *Model) States() int {
+	return len(m.Initial)
+}
+
+// Validate checks that m is a model of symbols symbols whose rows of
+// probabilities each sum to 1
+func (m *Model) Validate(symbols int) error {
+	n := len(m.Initial)
+	switch {
+	case n == 0:
+		return errors.New("the model has no states")
+	case len(m.Transition) != n || len(m.Emission) != n:
+		return fmt.Errorf("the model needs a transition and an emission row for each of its %d states", n)
+	}
+	if err := checkRow("initial probabilities", m.Initial, n); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := checkRow(fmt.Sprintf("transitions of state %d", i), m.Transition[i], n); err != nil {
+			return err
+		}
+		if err := checkRow(fmt.Sprintf("emissions of state %d", i), m.Emission[i], symbols); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRow checks that row holds n probabilities summing to 1
+func checkRow(name string, row []float64, n int) error {
+	if len(row) != n {
+		return fmt.Errorf("the %s have %d entries, not %d", name, len(row), n)
+	}
+	total := 0.0
+	for _, p := range row {
+		if p < 0 || p > 1+probabilityTolerance || math.IsNaN(p) {
+			return fmt.Errorf("the %s hold %v, outside [0, 1]", name, p)
+		}
+		total += p
+	}
+	if math.Abs(total-1) > probabilityTolerance {
+		return fmt.Errorf("the %s sum to %v, not 1", name, total)
+	}
+	return nil
+}
+
+// checkObservations checks that observations is a non-empty sequence of
+// symbols of m
+func checkObservations(m *Model, observations []int) error {
+	if len(observations) == 0 {
+		return errors.New("there are no observations")
+	}
+	for t, o := range observations {
+		if o < 0 || o >= len(m.Emission[0]) {
+			return fmt.Errorf("observation %d is symbol %d, which the model does not emit", t, o)
+		}
+	}
+	return nil
+}
+
+// Viterbi returns the most likely state path of m emitting observations and
+// the log probability of the path and observations together
+func Viterbi(m *Model, observations []int) ([]int, float64, error) {
+	if err := checkObservations(m, observations); err != nil {
+		return nil, 0, err
+	}
+	n, steps := m.States(), len(observations)
+
+	// score[i] is the log probability of the best path ending in state i;
+	// back[t][i] the state before i on it
+	score := make([]float64, n)
+	for i := range score {
+		score[i] = math.Log(m.Initial[i]) + math.Log(m.Emission[i][observations[0]])
+	}
+	back := make([][]int, steps)
+	for t := 1; t < steps; t++ {
+		back[t] = make([]int, n)
+		next := make([]float64, n)
+		for j := 0; j < n; j++ {
+			next[j] = math.Inf(-1)
+			for i := 0; i < n; i++ {
+				if s := score[i] + math.Log(m.Transition[i][j]); s > next[j] {
+					next[j], back[t][j] = s, i
+				}
+			}
+			next[j] += math.Log(m.Emission[j][observations[t]])
+		}
+		score = next
+	}
+
+	last := 0
+	for i := range score {
+		if score[i] > score[last] {
+			last = i
+		}
+	}
+	if math.IsInf(score[last], -1) {
+		return nil, 0, errors.New("the observations are impossible under the model")
+	}
+
+	path := make([]int, steps)
+	path[steps-1] = last
+	for t := steps - 1; t > 0; t-- {
+		path[t-1] = back[t][path[t]]
+	}
+	return path, score[last], nil
+}
+
+// pass holds the scaled forward and backward variables of a sequence
+type pass struct {
+	alpha, beta [][]float64
+	// scale holds the probability of each observation given the ones before
+	scale []float64
+}
+
+// forwardBackward runs the scaled forward and backward recursions
+func forwardBackward(m *Model, observations []int) (*pass, error) {
+	n, steps := m.States(), len(observations)
+	p := &pass{alpha: make([][]float64, steps), beta: make([][]float64, steps), scale: make([]float64, steps)}
+
+	for t, o := range observations {
+		p.alpha[t] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			if t == 0 {
+				p.alpha[t][j] = m.Initial[j]
+			} else {
+				for i := 0; i < n; i++ {
+					p.alpha[t][j] += p.alpha[t-1][i] * m.Transition[i][j]
+				}
+			}
+			p.alpha[t][j] *= m.Emission[j][o]
+			p.scale[t] += p.alpha[t][j]
+		}
+		if p.scale[t] == 0 {
+			return nil, fmt.Errorf("observation %d is impossible under the model", t)
+		}
+		for j := range p.alpha[t] {
+			p.alpha[t][j] /= p.scale[t]
+		}
+	}
+
+	p.beta[steps-1] = make([]float64, n)
+	for i := range p.beta[steps-1] {
+		p.beta[steps-1][i] = 1
+	}
+	for t := steps - 2; t >= 0; t-- {
+		p.beta[t] = make([]float64, n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				p.beta[t][i] += m.Transition[i][j] * m.Emission[j][observations[t+1]] * p.beta[t+1][j]
+			}
+			p.beta[t][i] /= p.scale[t+1]
+		}
+	}
+	return p, nil
+}
+
+// logLikelihood returns the log probability of the sequence of p
+func (p *pass) logLikelihood() float64 {
+	total := 0.0
+	for _, c := range p.scale {
+		total += math.Log(c)
+	}
+	return total
+}
+
+// posterior returns the probability of each state at step t
+func (p *pass) posterior(t int) []float64 {
+	gamma := make([]float64, len(p.alpha[t]))
+	for i := range gamma {
+		gamma[i] = p.alpha[t][i] * p.beta[t][i]
+	}
+	return gamma
+}
+
+// ForwardBackward returns the posterior probability of each state at each
+// step of observations, and their log-likelihood under m
+func ForwardBackward(m *Model, observations []int) ([][]float64, float64, error) {
+	if err := checkObservations(m, observations); err != nil {
+		return nil, 0, err
+	}
+	p, err := forwardBackward(m, observations)
+	if err != nil {
+		return nil, 0, err
+	}
+	posteriors := make([][]float64, len(observations))
+	for t := range posteriors {
+		posteriors[t] = p.posterior(t)
+	}
+	return posteriors, p.logLikelihood(), nil
+}
+
+// FitOptions control BaumWelch
+type FitOptions struct {
+	MaxIterations int
+	// Tolerance is the smallest gain in log-likelihood an iteration must
+	// make for fitting to go on
+	Tolerance float64
+	// TimeLimit, when positive, stops fitting early with the model fitted so
+	// far, which has not converged
+	TimeLimit time.Duration
+}
+
+// Fit reports how BaumWelch fitted a model
+type Fit struct {
+	Iterations int
+	Converged  bool
+	// LogLikelihood is the log-likelihood of the observations under the
+	// fitted model, and LogLikelihoods its value before each iteration
+	LogLikelihood  float64
+	LogLikelihoods []float64
+	// Residuals holds the gain in log-likelihood of each iteration
+	Residuals      []float64
+	StoppingReason string
+}
+
+// BaumWelch fits a model to observations by expectation maximization,
+// starting from m, which it leaves unchanged. It returns ctx's error if ctx
+// ends first.
+func BaumWelch(ctx context.Context, m *Model, observations []int, opts FitOptions) (*Model, *Fit, error) {
+	if err := checkObservations(m, observations); err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case opts.MaxIterations <= 0:
+		return nil, nil, errors.New("max iterations must be positive")
+	case opts.Tolerance <= 0:
+		return nil, nil, errors.New("tolerance must be positive")
+	}
+
+	n, symbols, steps := m.States(), len(m.Emission[0]), len(observations)
+	model := m.copy()
+	fit := &Fit{StoppingReason: convergence.MaxIterations}
+	p, err := forwardBackward(model, observations)
+	if err != nil {
+		return nil, nil, err
+	}
+	fit.LogLikelihood = p.logLikelihood()
+
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+	for fit.Iterations < opts.MaxIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if fit.Iterations > 0 && deadline.Passed() {
+			fit.StoppingReason = convergence.TimeLimit
+			break
+		}
+		fit.LogLikelihoods = append(fit.LogLikelihoods, fit.LogLikelihood)
+
+		// Expected state occupancies, transitions and emissions
+		gammaTotal := make([]float64, n)
+		fromTotal := make([]float64, n)
+		transitions := make([][]float64, n)
+		emissions := make([][]float64, n)
+		for i := range transitions {
+			transitions[i] = make([]float64, n)
+			emissions[i] = make([]float64, symbols)
+		}
+		first := p.posterior(0)
+		for t, o := range observations {
+			gamma := p.posterior(t)
+			for i := 0; i < n; i++ {
+				gammaTotal[i] += gamma[i]
+				emissions[i][o] += gamma[i]
+			}
+			if t == steps-1 {
+				continue
+			}
+			for i := 0; i < n; i++ {
+				fromTotal[i] += gamma[i]
+				for j := 0; j < n; j++ {
+					transitions[i][j] += p.alpha[t][i] * model.Transition[i][j] * model.Emission[j][observations[t+1]] * p.beta[t+1][j] / p.scale[t+1]
+				}
+			}
+		}
+
+		// States never visited keep their rows
+		next := model.copy()
+		next.Initial = first
+		for i := 0; i < n; i++ {
+			if fromTotal[i] > 0 {
+				for j := range transitions[i] {
+					next.Transition[i][j] = transitions[i][j] / fromTotal[i]
+				}
+			}
+			if gammaTotal[i] > 0 {
+				for k := range emissions[i] {
+					next.Emission[i][k] = emissions[i][k] / gammaTotal[i]
+				}
+			}
+		}
+
+		if p, err = forwardBackward(next, observations); err != nil {
+			return nil, nil, err
+		}
+		gain := p.logLikelihood() - fit.LogLikelihood
+		model, fit.LogLikelihood = next, p.logLikelihood()
+		fit.Iterations++
+		fit.Residuals = append(fit.Residuals, gain)
+		if gain < opts.Tolerance {
+			fit.Converged = true
+			fit.StoppingReason = convergence.Tolerance
+			break
+		}
+	}
+	return model, fit, nil
+}
+
+// copy returns a deep copy of m
+func (m *Model) copy() *Model {
+	c := &Model{Initial: append([]float64(nil), m.Initial...)}
+	for i := range m.Transition {
+		c.Transition = append(c.Transition, append([]float64(nil), m.Transition[i]...))
+		c.Emission = append(c.Emission, append([]float64(nil), m.Emission[i]...))
+	}
+	return c
+}
@@ -0,0 +1,125 @@
+package hmm
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fever is the healthy (0) and fever (1) model emitting normal (0), cold (1)
+// and dizzy (2)
+var fever = &Model{
+	Initial:    []float64{0.6, 0.4},
+	Transition: [][]float64{{0.7, 0.3}, {0.4, 0.6}},
+	Emission:   [][]float64{{0.5, 0.4, 0.1}, {0.1, 0.3, 0.6}},
+}
+
+func TestViterbi_DecodesMostLikelyPath(t *testing.T) {
+	require.NoError(t, fever.Validate(3))
+
+	path, logProbability, err := Viterbi(fever, []int{0, 1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 1}, path)
+	assert.InDelta(t, math.Log(0.01512), logProbability, 1e-9)
+
+	_, _, err = Viterbi(fever, []int{0, 3})
+	assert.Error(t, err)
+}
+
+func TestForwardBackward_MatchesEnumeration(t *testing.T) {
+	observations := []int{0, 1, 2, 2}
+	posteriors, logLikelihood, err := ForwardBackward(fever, observations)
+	require.NoError(t, err)
+
+	// Sum the probability of every state path
+	total, firstHealthy := 0.0, 0.0
+	for paths := 0; paths < 1<<len(observations); paths++ {
+		p, previous := 1.0, 0
+		for t, o := range observations {
+			state := paths >> t & 1
+			if t == 0 {
+				p *= fever.Initial[state]
+			} else {
+				p *= fever.Transition[previous][state]
+			}
+			p *= fever.Emission[state][o]
+			previous = state
+		}
+		total += p
+		if paths&1 == 0 {
+			firstHealthy += p
+		}
+	}
+	assert.InDelta(t, math.Log(total), logLikelihood, 1e-9)
+	assert.InDelta(t, firstHealthy/total, posteriors[0][0], 1e-9)
+	for _, row := range posteriors {
+		assert.InDelta(t, 1, row[0]+row[1], 1e-9)
+	}
+}
+
+func TestBaumWelch_FitsSequence(t *testing.T) {
+	// Sample a sequence from a model with sticky, distinct states
+	truth := &Model{
+		Initial:    []float64{1, 0},
+		Transition: [][]float64{{0.95, 0.05}, {0.1, 0.9}},
+		Emission:   [][]float64{{0.9, 0.1}, {0.15, 0.85}},
+	}
+	r := rand.New(rand.NewSource(1))
+	observations := make([]int, 500)
+	state := 0
+	for t := range observations {
+		if t > 0 && r.Float64() > truth.Transition[state][state] {
+			state = 1 - state
+		}
+		observations[t] = 0
+		if r.Float64() > truth.Emission[state][0] {
+			observations[t] = 1
+		}
+	}
+	_, trueLogLikelihood, err := ForwardBackward(truth, observations)
+	require.NoError(t, err)
+
+	start := RandomModel(2, 2, rand.New(rand.NewSource(2)))
+	fitted, fit, err := BaumWelch(context.Background(), start, observations, FitOptions{MaxIterations: 500, Tolerance: 1e-8})
+	require.NoError(t, err)
+	require.NoError(t, fitted.Validate(2))
+	assert.True(t, fit.Converged)
+	assert.Len(t, fit.LogLikelihoods, fit.Iterations)
+
+	// Expectation maximization never lowers the likelihood, and ends at
+	// least as likely as the model that generated the sequence
+	for i := 1; i < len(fit.LogLikelihoods); i++ {
+		assert.GreaterOrEqual(t, fit.LogLikelihoods[i], fit.LogLikelihoods[i-1]-1e-9)
+	}
+	assert.Greater(t, fit.LogLikelihood, trueLogLikelihood-1)
+	assert.NotEqual(t, start, fitted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = BaumWelch(ctx, start, observations, FitOptions{MaxIterations: 10, Tolerance: 1e-8})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Out of time, fitting stops with the model of the iterations run
+	fitted, fit, err = BaumWelch(context.Background(), start, observations, FitOptions{MaxIterations: 500, Tolerance: 1e-8, TimeLimit: time.Nanosecond})
+	require.NoError(t, err)
+	assert.False(t, fit.Converged)
+	assert.Equal(t, "time_limit", fit.StoppingReason)
+	assert.Equal(t, 1, fit.Iterations)
+	assert.NotEqual(t, start, fitted)
+}
+
+func TestModel_Validate(t *testing.T) {
+	for name, m := range map[string]*Model{
+		"no states":      {},
+		"short emission": {Initial: []float64{1}, Transition: [][]float64{{1}}, Emission: [][]float64{{1}}},
+		"not summing":    {Initial: []float64{0.5}, Transition: [][]float64{{1}}, Emission: [][]float64{{0.5, 0.5}}},
+		"missing row":    {Initial: []float64{0.5, 0.5}, Transition: [][]float64{{0.5, 0.5}}, Emission: [][]float64{{0.5, 0.5}}},
+	} {
+		assert.Error(t, m.Validate(2), name)
+	}
+}
@@ -0,0 +1,98 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/intelligence"
+	"github.com/rainmana/gothink/internal/storage"
+)
+
+// checkTimeout bounds each readiness check
+const checkTimeout = 2 * time.Second
+
+// Check statuses
+const (
+	statusOK      = "ok"
+	statusFailing = "failing"
+)
+
+// checkResult is the status of one dependency
+type checkResult struct {
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	LastRefresh *time.Time `json:"last_refresh,omitempty"`
+}
+
+// healthz answers the liveness probe: the process is up and serving
+func healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  statusOK,
+		"version": Version,
+	})
+}
+
+// readyz answers the readiness probe: the storage backend can be reached and,
+// when a maximum age is configured, the intelligence data is fresh. Each
+// dependency's status is reported; any failing dependency makes the response
+// 503 Service Unavailable.
+func readyz(cfg *config.Config, store storage.Store, service *intelligence.IntelligenceService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checks := map[string]checkResult{
+			"storage": checkStorage(r.Context(), store),
+		}
+		if service != nil && cfg.IntelligenceMaxAge > 0 {
+			checks["intelligence"] = checkIntelligence(service, cfg.IntelligenceMaxAge)
+		}
+
+		status, code := "ready", http.StatusOK
+		for _, check := range checks {
+			if check.Status != statusOK {
+				status, code = "not_ready", http.StatusServiceUnavailable
+			}
+		}
+
+		writeJSON(w, code, map[string]interface{}{
+			"status": status,
+			"checks": checks,
+		})
+	}
+}
+
+// checkStorage pings the storage backend
+func checkStorage(ctx context.Context, store storage.Store) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if err := store.Ping(ctx); err != nil {
+		return checkResult{Status: statusFailing, Error: err.Error()}
+	}
+	return checkResult{Status: statusOK}
+}
+
+// checkIntelligence fails when the intelligence data was never refreshed or
+// was last refreshed more than maxAge ago
+func checkIntelligence(service *intelligence.IntelligenceService, maxAge time.Duration) checkResult {
+	refreshedAt := service.RefreshedAt()
+	if refreshedAt.IsZero() {
+		return checkResult{Status: statusFailing, Error: "intelligence data has not been refreshed"}
+	}
+
+	result := checkResult{Status: statusOK, LastRefresh: &refreshedAt}
+	if age := time.Since(refreshedAt); age > maxAge {
+		result.Status = statusFailing
+		result.Error = fmt.Sprintf("intelligence data is %s old, more than %s", age.Round(time.Second), maxAge)
+	}
+	return result
+}
+
+// writeJSON responds with status and body encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,165 @@
+// Package httpserver serves the GoThink REST API over the same storage as the
+// MCP server.
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/handlers"
+	"github.com/rainmana/gothink/internal/intelligence"
+	"github.com/rainmana/gothink/internal/mcpserver"
+	"github.com/rainmana/gothink/internal/middleware"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Version is reported by the health endpoints
+const Version = "1.0.0"
+
+// Option customizes the router built by NewRouter
+type Option func(*options)
+
+type options struct {
+	intelligence *intelligence.IntelligenceService
+	mcp          *server.MCPServer
+	mcpCtx       context.Context
+}
+
+// WithIntelligence checks the freshness of the intelligence data of service
+// when the server is asked whether it is ready
+func WithIntelligence(service *intelligence.IntelligenceService) Option {
+	return func(o *options) {
+		o.intelligence = service
+	}
+}
+
+// WithMCP runs the tool calls of POST /api/v1/batch on s and, when the
+// WebSocket transport is enabled, serves s to WebSocket clients at /mcp/ws,
+// closing their connections once ctx ends
+func WithMCP(ctx context.Context, s *server.MCPServer) Option {
+	return func(o *options) {
+		o.mcp = s
+		o.mcpCtx = ctx
+	}
+}
+
+// NewRouter returns the HTTP API: the health endpoints GET /health, /healthz
+// and /readyz, and the routes of every enabled feature under /api/v1. The
+// change feed is served when store publishes events, and the batch endpoint
+// and the MCP WebSocket transport when an MCP server is given.
+func NewRouter(cfg *config.Config, store storage.Store, logger *logrus.Logger, opts ...Option) http.Handler {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	router := mux.NewRouter()
+	router.Use(
+		middleware.Tracing(),
+		middleware.Logging(logger),
+		middleware.RateLimit(middleware.NewRateLimiter(cfg.RateLimitPerSecond, cfg.RateLimitBurst)),
+		middleware.CORS(),
+	)
+
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "healthy",
+			"version": Version,
+		})
+	}).Methods(http.MethodGet)
+	router.HandleFunc("/healthz", healthz).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", readyz(cfg, store, o.intelligence)).Methods(http.MethodGet)
+	if o.mcp != nil && cfg.EnableWebSocket {
+		router.Handle("/mcp/ws", mcpserver.NewWebSocketHandler(o.mcpCtx, o.mcp, logger)).Methods(http.MethodGet)
+	}
+
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(middleware.Tenant(), middleware.JSON())
+
+	if cfg.EnableSystematicThinking {
+		thinking := handlers.NewThinkingHandler(store, logger)
+		api.HandleFunc("/thinking/sequential", thinking.SequentialThinking).Methods(http.MethodPost)
+		api.HandleFunc("/thinking/mental-model", thinking.MentalModel).Methods(http.MethodPost)
+		api.HandleFunc("/thinking/debugging", thinking.DebuggingApproach).Methods(http.MethodPost)
+		api.HandleFunc("/thinking/collaborative", thinking.CollaborativeReasoning).Methods(http.MethodPost)
+		api.HandleFunc("/thinking/socratic", thinking.SocraticMethod).Methods(http.MethodPost)
+		api.HandleFunc("/thinking/creative", thinking.CreativeThinking).Methods(http.MethodPost)
+		api.HandleFunc("/thinking/systems", thinking.SystemsThinking).Methods(http.MethodPost)
+		api.HandleFunc("/thinking/scientific", thinking.ScientificMethod).Methods(http.MethodPost)
+	}
+
+	if cfg.EnableStochasticAlgorithms {
+		stochastic := handlers.NewStochasticHandler(store, logger)
+		for _, algorithm := range stochastic.Algorithms() {
+			api.HandleFunc("/stochastic/"+algorithm.Name(), stochastic.ServeAlgorithm(algorithm)).Methods(http.MethodPost)
+		}
+		api.HandleFunc("/stochastic/algorithms", stochastic.ListAlgorithms).Methods(http.MethodGet)
+		api.HandleFunc("/stochastic/compare", stochastic.CompareRuns).Methods(http.MethodPost)
+		api.HandleFunc("/stochastic/sweep", stochastic.ParameterSweep).Methods(http.MethodPost)
+		api.HandleFunc("/stochastic/sensitivity", stochastic.SensitivityAnalysis).Methods(http.MethodPost)
+		api.HandleFunc("/stochastic/dominance", stochastic.StochasticDominance).Methods(http.MethodPost)
+		api.HandleFunc("/stochastic/results/{id}", stochastic.GetResult).Methods(http.MethodGet)
+	}
+
+	decision := handlers.NewDecisionHandler(store, logger)
+	api.HandleFunc("/decision/framework", decision.DecisionFramework).Methods(http.MethodPost)
+	api.HandleFunc("/decision/expected-utility", decision.ExpectedUtility).Methods(http.MethodPost)
+	api.HandleFunc("/decision/multi-criteria", decision.MultiCriteria).Methods(http.MethodPost)
+	api.HandleFunc("/decision/score", decision.ScoreOption).Methods(http.MethodPost)
+	api.HandleFunc("/decision/ahp", decision.AHP).Methods(http.MethodPost)
+	api.HandleFunc("/decision/risk-analysis", decision.RiskAnalysis).Methods(http.MethodPost)
+	api.HandleFunc("/decision/tree", decision.DecisionTree).Methods(http.MethodPost)
+	api.HandleFunc("/decision/simulate", decision.SimulateDecision).Methods(http.MethodPost)
+	api.HandleFunc("/decision/stakeholders", decision.StakeholderAnalysis).Methods(http.MethodPost)
+	api.HandleFunc("/decision/group", decision.GroupDecision).Methods(http.MethodPost)
+	api.HandleFunc("/decision/cost-benefit", decision.CostBenefit).Methods(http.MethodPost)
+	api.HandleFunc("/decision/scenarios", decision.ScenarioAnalysis).Methods(http.MethodPost)
+	api.HandleFunc("/decision/compare", decision.CompareDecisions).Methods(http.MethodPost)
+	api.HandleFunc("/decision/kepner-tregoe", decision.KepnerTregoe).Methods(http.MethodPost)
+	api.HandleFunc("/decision/history", decision.DecisionHistory).Methods(http.MethodPost)
+	api.HandleFunc("/decision/outcome", decision.RecordDecisionOutcome).Methods(http.MethodPost)
+	api.HandleFunc("/decision/calibration", decision.CalibrationReport).Methods(http.MethodPost)
+
+	if cfg.EnableHybridThinking && cfg.EnableStochasticAlgorithms {
+		api.HandleFunc("/hybrid/probabilistic-decision", decision.HybridProbabilisticDecision).Methods(http.MethodPost)
+	}
+
+	if cfg.EnableVisualization {
+		visual := handlers.NewVisualHandler(store, logger)
+		api.HandleFunc("/visual/concept-map", visual.ConceptMap).Methods(http.MethodPost)
+		api.HandleFunc("/visual/mind-map", visual.MindMap).Methods(http.MethodPost)
+		api.HandleFunc("/visual/flowchart", visual.Flowchart).Methods(http.MethodPost)
+		api.HandleFunc("/visual/decision-tree", visual.DecisionTree).Methods(http.MethodPost)
+		api.HandleFunc("/visual/probability-tree", visual.ProbabilityTree).Methods(http.MethodPost)
+		api.HandleFunc("/visual/bayesian-network", visual.BayesianNetwork).Methods(http.MethodPost)
+		api.HandleFunc("/visual/map-session", visual.MapSession).Methods(http.MethodPost)
+	}
+
+	session := handlers.NewSessionHandler(store, logger)
+	api.HandleFunc("/session/import", session.Import).Methods(http.MethodPost)
+	api.HandleFunc("/session/{id}/stats", session.GetStats).Methods(http.MethodGet)
+	api.HandleFunc("/session/{id}/export", session.Export).Methods(http.MethodGet)
+	api.HandleFunc("/session/{id}/records", session.GetRecords).Methods(http.MethodGet)
+	api.HandleFunc("/session/{id}/search", session.Search).Methods(http.MethodGet)
+	api.HandleFunc("/session/{id}/clear", session.Clear).Methods(http.MethodPost, http.MethodDelete)
+	api.HandleFunc("/session/{id}/archive", session.Archive).Methods(http.MethodPost)
+	api.HandleFunc("/session/{id}/restore", session.Restore).Methods(http.MethodPost)
+	api.HandleFunc("/storage/stats", session.StorageStats).Methods(http.MethodGet)
+
+	if o.mcp != nil {
+		api.Handle("/batch", mcpserver.NewBatchHandler(o.mcp)).Methods(http.MethodPost)
+	}
+
+	if publisher, ok := store.(interface{ Events() *storage.EventBus }); ok {
+		events := handlers.NewEventsHandler(publisher.Events(), logger)
+		api.HandleFunc("/events", events.Stream).Methods(http.MethodGet)
+	}
+
+	return router
+}
@@ -0,0 +1,694 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/intelligence"
+	"github.com/rainmana/gothink/internal/models"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRouter_ServesEnabledFeaturesOverSharedStore(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.EnableStochasticAlgorithms = false
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/health", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"healthy"`)
+
+	rec = serve(http.MethodPost, "/api/v1/thinking/sequential",
+		`{"session_id":"http","thought":"first","thought_number":1,"total_thoughts":2,"next_thought_needed":true}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The thought is visible to anything else holding the store
+	thoughts, err := store.GetThoughts("http", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "first", thoughts[0].Thought)
+
+	rec = serve(http.MethodGet, "/api/v1/session/http/stats", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stats types.SessionStatistics
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, 1, stats.ThoughtCount)
+
+	// Failures carry a machine-readable code
+	rec = serve(http.MethodPost, "/api/v1/session/missing/archive", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"SESSION_NOT_FOUND","message":"session missing not found"}}`, rec.Body.String())
+
+	// Disabled features are not routed
+	rec = serve(http.MethodPost, "/api/v1/stochastic/mdp", `{}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// unreachableStore is a store whose backend cannot be reached
+type unreachableStore struct {
+	storage.Store
+}
+
+func (unreachableStore) Ping(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// noIntelligence is an intelligence source without any data
+type noIntelligence struct{}
+
+func (noIntelligence) DownloadAllCVEs(context.Context) ([]models.CVE, error) {
+	return nil, nil
+}
+
+func (noIntelligence) DownloadTechniques(context.Context) ([]models.AttackTechnique, error) {
+	return nil, nil
+}
+
+func (noIntelligence) DownloadProcedures(context.Context) ([]models.OWASPProcedure, error) {
+	return nil, nil
+}
+
+func TestReadyz_ReportsEachDependency(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.IntelligenceMaxAge = time.Hour
+	store := storage.NewMemoryStore(cfg)
+	service := intelligence.NewIntelligenceServiceWithSources(noIntelligence{}, noIntelligence{}, noIntelligence{})
+
+	probe := func(store storage.Store, path string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		NewRouter(cfg, store, logrus.New(), WithIntelligence(service)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body
+	}
+
+	// Liveness does not depend on anything
+	code, body := probe(unreachableStore{store}, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+
+	// Intelligence data that was never refreshed is not ready
+	code, body = probe(store, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", body["status"])
+	checks := body["checks"].(map[string]interface{})
+	assert.Equal(t, "ok", checks["storage"].(map[string]interface{})["status"])
+	assert.Equal(t, "failing", checks["intelligence"].(map[string]interface{})["status"])
+
+	require.NoError(t, service.RefreshIntelligenceData(context.Background()))
+	code, body = probe(store, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body["status"])
+	assert.Contains(t, body["checks"].(map[string]interface{})["intelligence"], "last_refresh")
+
+	// An unreachable backend is reported with its error
+	code, body = probe(unreachableStore{store}, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, map[string]interface{}{"status": "failing", "error": "connection refused"},
+		body["checks"].(map[string]interface{})["storage"])
+}
+
+func TestMDP_SolvesTransitionModel(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	solve := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/mdp", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := solve(`{"session_id":"mdp","problem":"Invest or spend","gamma":0.9,"method":"policy_iteration","transitions":[
+		{"state":"idle","action":"spend","next_state":"idle","probability":1,"reward":1},
+		{"state":"idle","action":"invest","next_state":"rich","probability":1},
+		{"state":"rich","action":"cash_out","next_state":"done","probability":1,"reward":20}]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response struct {
+		Converged     bool                          `json:"converged"`
+		Policy        map[string]string             `json:"policy"`
+		ValueFunction map[string]float64            `json:"value_function"`
+		QValues       map[string]map[string]float64 `json:"q_values"`
+		Convergence   struct {
+			Method         string    `json:"method"`
+			StoppingReason string    `json:"stopping_reason"`
+			Residuals      []float64 `json:"residuals"`
+		} `json:"convergence"`
+		Confidence struct {
+			Value  float64 `json:"value"`
+			Method string  `json:"method"`
+		} `json:"confidence"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.Converged)
+	assert.Equal(t, "policy_iteration", response.Convergence.Method)
+	assert.Equal(t, "policy_stable", response.Convergence.StoppingReason)
+	assert.NotEmpty(t, response.Convergence.Residuals)
+	assert.Equal(t, map[string]string{"idle": "invest", "rich": "cash_out"}, response.Policy)
+	assert.InDelta(t, 18, response.ValueFunction["idle"], 1e-6)
+	assert.InDelta(t, 1+0.9*18, response.QValues["idle"]["spend"], 1e-6)
+	assert.Equal(t, 1.0, response.Confidence.Value)
+	assert.Equal(t, "exact", response.Confidence.Method)
+
+	algorithms, err := store.GetStochasticAlgorithms("mdp", nil)
+	require.NoError(t, err)
+	require.Len(t, algorithms, 1)
+	assert.True(t, algorithms[0].Converged)
+	assert.Equal(t, "exact", algorithms[0].ConfidenceMethod)
+
+	// Cut short, value iteration is only sure of the actions far enough
+	// ahead of the others for the Bellman residual's error bound
+	rec = solve(`{"session_id":"mdp","problem":"Invest or spend","gamma":0.9,"max_iterations":1,"transitions":[
+		{"state":"idle","action":"spend","next_state":"idle","probability":1,"reward":1},
+		{"state":"idle","action":"invest","next_state":"rich","probability":1},
+		{"state":"rich","action":"cash_out","next_state":"done","probability":1,"reward":20}]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.False(t, response.Converged)
+	assert.Equal(t, "action_gap", response.Confidence.Method)
+	assert.Equal(t, 0.5, response.Confidence.Value, "only the state with a single action is sure")
+
+	// Outcome probabilities must sum to 1
+	rec = solve(`{"session_id":"mdp","problem":"Broken","gamma":0.9,"transitions":[{"state":"a","action":"x","next_state":"b","probability":0.5}]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "INVALID_PARAMETERS")
+}
+
+func TestMCTS_SearchesGameModel(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/mcts", strings.NewReader(`{
+		"session_id":"mcts","problem":"Escalate or contain","root_state":"incident","simulations":500,"seed":3,
+		"states":[
+			{"name":"incident","moves":[{"move":"contain","next_state":"contained"},{"move":"escalate","next_state":"response"}]},
+			{"name":"contained","reward":0.4},
+			{"name":"response","player":2,"moves":[{"move":"evade","next_state":"breach"},{"move":"yield","next_state":"resolved"}]},
+			{"name":"breach","reward":-1},
+			{"name":"resolved","reward":1}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response struct {
+		BestAction         string   `json:"best_action"`
+		PrincipalVariation []string `json:"principal_variation"`
+		Simulations        int      `json:"simulations"`
+		Actions            []struct {
+			Move   string `json:"move"`
+			Visits int    `json:"visits"`
+		} `json:"actions"`
+		Confidence struct {
+			Value  float64 `json:"value"`
+			Method string  `json:"method"`
+			Basis  string  `json:"basis"`
+		} `json:"confidence"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "contain", response.BestAction)
+	assert.Equal(t, "rollout_bootstrap", response.Confidence.Method)
+	assert.Greater(t, response.Confidence.Value, 0.9)
+	assert.NotEmpty(t, response.Confidence.Basis)
+	assert.Equal(t, []string{"contain"}, response.PrincipalVariation)
+	assert.Equal(t, 500, response.Simulations)
+	require.Len(t, response.Actions, 2)
+	assert.Equal(t, 500, response.Actions[0].Visits+response.Actions[1].Visits)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/mcts",
+		strings.NewReader(`{"session_id":"mcts","problem":"No game","root_state":"start"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMCTS_StreamsBestMoves(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	search := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/mcts", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := search(`{"session_id":"mcts","problem":"Escalate or contain","root_state":"incident","simulations":400,"seed":3,
+		"stream":true,"stream_interval":100,
+		"states":[
+			{"name":"incident","moves":[{"move":"contain","next_state":"contained"},{"move":"escalate","next_state":"resolved"}]},
+			{"name":"contained","reward":0.4},
+			{"name":"resolved","reward":1}]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	var events []string
+	var updates []map[string]interface{}
+	for _, chunk := range strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n") {
+		lines := strings.SplitN(chunk, "\n", 2)
+		require.Len(t, lines, 2, chunk)
+		event := strings.TrimPrefix(lines[0], "event: ")
+		events = append(events, event)
+		if event == "progress" {
+			var update map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &update))
+			updates = append(updates, update)
+		}
+	}
+	assert.Equal(t, []string{"progress", "progress", "progress", "result"}, events)
+	assert.Equal(t, 100.0, updates[0]["iteration"])
+	assert.Equal(t, 400.0, updates[0]["total"])
+	assert.Equal(t, "escalate", updates[2]["best_action"])
+	assert.Contains(t, rec.Body.String(), `"principal_variation":["escalate"]`)
+
+	// Invalid requests fail before streaming
+	rec = search(`{"session_id":"mcts","problem":"No game","root_state":"start","stream":true}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
+
+func TestBandit_PlaysStrategyOverArms(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/bandit", strings.NewReader(`{
+		"session_id":"bandit","problem":"Pick a subject line","strategy":"ucb1","steps":1000,"seed":5,
+		"arms":[
+			{"name":"plain","mean":0.2},
+			{"name":"urgent","observed_rewards":[1,0,1,1,0,1,1,1]}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response struct {
+		SelectedArm int     `json:"selected_arm"`
+		OptimalArm  int     `json:"optimal_arm"`
+		Regret      float64 `json:"regret"`
+		ArmStats    []struct {
+			Name           string  `json:"name"`
+			Pulls          int     `json:"pulls"`
+			ExpectedReward float64 `json:"expected_reward"`
+		} `json:"arm_stats"`
+		RegretCurve []struct {
+			Step   int     `json:"step"`
+			Regret float64 `json:"regret"`
+		} `json:"regret_curve"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.OptimalArm)
+	assert.Equal(t, 1, response.SelectedArm)
+	require.Len(t, response.ArmStats, 2)
+	assert.Equal(t, "urgent", response.ArmStats[1].Name)
+	assert.Equal(t, 0.75, response.ArmStats[1].ExpectedReward)
+	assert.Equal(t, 1000, response.ArmStats[0].Pulls+response.ArmStats[1].Pulls)
+	require.Len(t, response.RegretCurve, 100)
+	assert.Equal(t, 1000, response.RegretCurve[99].Step)
+	assert.Equal(t, response.Regret, response.RegretCurve[99].Regret)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/bandit",
+		strings.NewReader(`{"session_id":"bandit","problem":"No arms","strategy":"softmax","arms":[{"mean":0.5}]}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBandit_FollowsArmsThatChange(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/bandit", strings.NewReader(`{
+		"session_id":"bandit","problem":"Follow an evolving opponent","strategy":"ucb1","steps":6000,"seed":5,
+		"drift_mode":"sliding_window","window":300,
+		"arms":[
+			{"name":"aggressive","mean":0.7,"changes":[{"step":3000,"mean":0.2}]},
+			{"name":"defensive","mean":0.4,"changes":[{"step":3000,"mean":0.6}]}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response struct {
+		SelectedArm  int `json:"selected_arm"`
+		OptimalArm   int `json:"optimal_arm"`
+		ChangePoints []struct {
+			Name      string `json:"name"`
+			Step      int    `json:"step"`
+			Direction string `json:"direction"`
+		} `json:"change_points"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.OptimalArm)
+	assert.Equal(t, 1, response.SelectedArm)
+	require.NotEmpty(t, response.ChangePoints)
+	assert.Equal(t, "aggressive", response.ChangePoints[0].Name)
+	assert.Equal(t, "decrease", response.ChangePoints[0].Direction)
+	assert.GreaterOrEqual(t, response.ChangePoints[0].Step, 3000)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/bandit", strings.NewReader(`{
+		"session_id":"bandit","problem":"Forget everything","drift_mode":"discounted","discount":1,"arms":[{"mean":0.5}]}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBayesianOptimization_OptimizesObjective(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/bayesian", strings.NewReader(`{
+		"session_id":"bo","problem":"Tune the cache size","goal":"minimize","iterations":15,"seed":2,
+		"objective":"pow(size - 3, 2) + 1",
+		"parameters":[{"name":"size","min":0,"max":10}],
+		"history":[{"parameters":{"size":8},"value":26}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response struct {
+		BestParameters map[string]float64 `json:"best_parameters"`
+		BestValue      float64            `json:"best_value"`
+		BestPosterior  struct {
+			Mean     float64 `json:"mean"`
+			Variance float64 `json:"variance"`
+		} `json:"best_posterior"`
+		Iterations     int                `json:"iterations"`
+		NextParameters map[string]float64 `json:"next_parameters"`
+		History        []struct {
+			Random bool `json:"random"`
+		} `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 15, response.Iterations)
+	require.Len(t, response.History, 15)
+	assert.True(t, response.History[1].Random)
+	assert.False(t, response.History[2].Random)
+	assert.InDelta(t, 3, response.BestParameters["size"], 0.5)
+	assert.InDelta(t, 1, response.BestValue, 0.25)
+	assert.InDelta(t, response.BestValue, response.BestPosterior.Mean, 0.05)
+	assert.Contains(t, response.NextParameters, "size")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/bayesian",
+		strings.NewReader(`{"session_id":"bo","problem":"Unknown name","objective":"depth * 2","parameters":[{"name":"size","min":0,"max":10}]}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHMM_DecodesAndFitsSequences(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	type hmmResponse struct {
+		StatePath          []string    `json:"state_path"`
+		PathLogProbability float64     `json:"path_log_probability"`
+		LogLikelihood      float64     `json:"log_likelihood"`
+		StatePosteriors    [][]float64 `json:"state_posteriors"`
+		Transitions        [][]float64 `json:"transitions"`
+		Iterations         int         `json:"iterations"`
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/hmm", strings.NewReader(`{
+		"session_id":"hmm","problem":"Is the host compromised","observations":["normal","scan","exfil"],
+		"state_names":["clean","compromised"],"symbols":["normal","scan","exfil"],
+		"initial":[0.6,0.4],"transitions":[[0.7,0.3],[0.4,0.6]],"emissions":[[0.5,0.4,0.1],[0.1,0.3,0.6]]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var decoded hmmResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.Equal(t, []string{"clean", "clean", "compromised"}, decoded.StatePath)
+	assert.InDelta(t, math.Log(0.01512), decoded.PathLogProbability, 1e-9)
+	assert.Greater(t, decoded.LogLikelihood, decoded.PathLogProbability)
+	require.Len(t, decoded.StatePosteriors, 3)
+	assert.Zero(t, decoded.Iterations)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/hmm", strings.NewReader(`{
+		"session_id":"hmm","problem":"Fit the host","seed":4,
+		"observations":["normal","normal","normal","scan","exfil","exfil","exfil","normal","normal","exfil","exfil","exfil"]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var fitted hmmResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fitted))
+	assert.Len(t, fitted.StatePath, 12)
+	assert.Len(t, fitted.Transitions, 2)
+	assert.Positive(t, fitted.Iterations)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/hmm",
+		strings.NewReader(`{"session_id":"hmm","problem":"Bad model","observations":["a"],"initial":[0.5],"transitions":[[1]],"emissions":[[1]]}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAlgorithms_ListsRegisteredRoutes(t *testing.T) {
+	cfg := config.DefaultConfig()
+	router := NewRouter(cfg, storage.NewMemoryStore(cfg), logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stochastic/algorithms", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response api.ListAlgorithmsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Algorithms)
+
+	// Each algorithm is routed where the listing says
+	for _, algorithm := range response.Algorithms {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, algorithm.Route, strings.NewReader(`{"session_id":"routes"`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, algorithm.Name)
+		assert.Contains(t, rec.Body.String(), "Invalid request body", algorithm.Name)
+	}
+}
+
+func TestStochasticResult_ReturnsTheFullTypedData(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stochastic/bandit", strings.NewReader(`{
+		"session_id":"results","problem":"Pick a subject line","steps":500,"seed":5,
+		"arms":[{"name":"plain","mean":0.2},{"name":"urgent","mean":0.7}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var played struct {
+		AlgorithmID string  `json:"algorithm_id"`
+		Regret      float64 `json:"regret"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &played))
+
+	// The record keeps the arm statistics beside the fields every run has
+	records, err := store.GetStochasticAlgorithms("results", nil)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Contains(t, string(records[0].Payload), `"arm_stats"`)
+	assert.NotContains(t, string(records[0].Payload), `"problem"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stochastic/results/"+played.AlgorithmID+"?session_id=results", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		api.StochasticResultResponse
+		Result types.BanditData `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "bandit", response.Type)
+	assert.True(t, response.Complete)
+	assert.Equal(t, played.AlgorithmID, response.Result.ID)
+	assert.Equal(t, "Pick a subject line", response.Result.Problem)
+	require.Len(t, response.Result.ArmStats, 2)
+	assert.Equal(t, "urgent", response.Result.ArmStats[1].Name)
+	assert.Equal(t, 500, response.Result.ArmStats[0].Pulls+response.Result.ArmStats[1].Pulls)
+	assert.Equal(t, played.Regret, response.Result.Regret)
+	assert.NotEmpty(t, response.Result.RegretCurve)
+	require.NotNil(t, response.Result.Convergence)
+	assert.Nil(t, response.Result.Payload)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stochastic/results/missing?session_id=results", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stochastic/results/"+played.AlgorithmID, nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMultiCriteria_RanksARecordedDecision(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/framework", strings.NewReader(`{
+		"session_id":"mcda","decision_statement":"Pick a vendor","analysis_type":"multi-criteria","stage":"evaluation",
+		"options":[{"name":"acme"},{"name":"globex"}],
+		"criteria":[{"name":"price","weight":0.6,"direction":"cost"},{"name":"quality","weight":0.4}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var recorded api.DecisionFrameworkResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &recorded))
+	assert.Empty(t, recorded.Ranking, "nothing to rank without scores")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/multi-criteria", strings.NewReader(`{
+		"session_id":"mcda","decision_id":"`+recorded.DecisionID+`","scores":[
+			{"option":"acme","criterion":"price","score":100},{"option":"acme","criterion":"quality","score":6},
+			{"option":"globex","criterion":"price","score":80},{"option":"globex","criterion":"quality","score":9}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var scored api.MultiCriteriaResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &scored))
+	assert.Equal(t, "weighted_sum", scored.ScoringMethod)
+	assert.Equal(t, "max", scored.Normalization)
+	require.Len(t, scored.Ranking, 2)
+	assert.Equal(t, "globex", scored.Ranking[0].Option)
+	assert.InDelta(t, 1.0, scored.Ranking[0].Score, 1e-12)
+	assert.InDelta(t, 0.6*0.8+0.4*6/9, scored.Ranking[1].Score, 1e-12)
+	assert.InDelta(t, 0.6*0.8, scored.Ranking[1].Contributions["price"], 1e-12)
+
+	// The ranking is stored on the decision
+	decisions, err := store.GetDecisions("mcda", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Len(t, decisions[0].Scores, 4)
+	assert.Equal(t, "globex", decisions[0].Ranking[0].Option)
+	assert.Equal(t, scored.Recommendation, decisions[0].Recommendation)
+	assert.Contains(t, scored.Recommendation, "globex ranks first")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/multi-criteria", strings.NewReader(`{
+		"session_id":"mcda","decision_id":"missing"}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRiskAnalysis_AssessesARecordedRegister(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/framework", strings.NewReader(`{
+		"session_id":"risk","decision_statement":"Where to host","analysis_type":"risk","stage":"evaluation",
+		"options":[{"name":"cloud","expected_value":200},{"name":"on-prem","expected_value":150}],
+		"risks":[{"name":"outage","option":"cloud","probability":0.9,"impact":100}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var recorded api.DecisionFrameworkResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &recorded))
+	require.NotNil(t, recorded.RiskAssessment)
+	assert.Equal(t, "on-prem", recorded.RiskAssessment.Options[0].Option)
+	assert.Equal(t, recorded.RiskAssessment.Recommendation, recorded.Recommendation)
+
+	// Mitigating the outage, and adding a risk to on-prem, turns the choice
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/risk-analysis", strings.NewReader(`{
+		"session_id":"risk","decision_id":"`+recorded.DecisionID+`","risks":[
+			{"name":"outage","option":"cloud","probability":0.9,"impact":100,
+			 "mitigations":[{"name":"failover","probability_reduction":0.8,"cost":10}]},
+			{"name":"hardware","option":"on-prem","probability":0.5,"impact":60}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var analyzed api.RiskAnalysisResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &analyzed))
+	assessment := analyzed.Assessment
+
+	require.Len(t, assessment.Risks, 2)
+	outage := assessment.Risks[0]
+	assert.Equal(t, api.RiskCell{ProbabilityLevel: 5, ImpactLevel: 5, Severity: 25, Rating: "critical"}, outage.Inherent)
+	assert.Equal(t, api.RiskCell{ProbabilityLevel: 1, ImpactLevel: 5, Severity: 5, Rating: "medium"}, outage.Residual)
+	assert.InDelta(t, 18, outage.ExpectedLoss, 1e-9)
+	assert.Equal(t, 1, assessment.InherentMatrix[4][4])
+	assert.Equal(t, 1, assessment.ResidualMatrix[0][4])
+	assert.Equal(t, 1, assessment.ResidualMatrix[2][2])
+
+	// cloud keeps 200 - 18 - 10 and on-prem 150 - 30
+	require.Len(t, assessment.Options, 2)
+	assert.Equal(t, "cloud", assessment.Options[0].Option)
+	assert.InDelta(t, 172, assessment.Options[0].RiskAdjustedValue, 1e-9)
+	assert.InDelta(t, 120, assessment.Options[1].RiskAdjustedValue, 1e-9)
+	assert.Contains(t, assessment.Recommendation, "cloud has the highest risk-adjusted value, 172")
+
+	decisions, err := store.GetDecisions("risk", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Len(t, decisions[0].Risks, 2)
+	assert.Equal(t, "medium", decisions[0].Options[0].RiskLevel)
+	assert.Equal(t, "medium", decisions[0].Options[1].RiskLevel)
+	assert.Equal(t, assessment.Recommendation, decisions[0].Recommendation)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/risk-analysis", strings.NewReader(`{
+		"session_id":"risk","decision_id":"`+recorded.DecisionID+`","risks":[{"name":"flood","option":"moon","probability":0.1}]}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCostBenefit_AppraisesTimePhasedOptions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store := storage.NewMemoryStore(cfg)
+	router := NewRouter(cfg, store, logrus.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/framework", strings.NewReader(`{
+		"session_id":"cba","decision_statement":"Upgrade the plant","analysis_type":"cost-benefit","stage":"evaluation",
+		"options":[
+			{"name":"retrofit","cash_flows":[{"period":0,"cost":100},{"period":1,"benefit":110},{"period":2,"benefit":121}]},
+			{"name":"replace"}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var recorded api.DecisionFrameworkResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &recorded))
+
+	// replace has no flows yet
+	analyze := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/decision/cost-benefit", strings.NewReader(body)))
+		return rec
+	}
+	rec = analyze(`{"session_id":"cba","decision_id":"` + recorded.DecisionID + `","discount_rate":0.1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = analyze(`{"session_id":"cba","decision_id":"` + recorded.DecisionID + `","discount_rate":0.1,
+		"cash_flows":[{"option":"replace","cash_flows":[{"period":0,"cost":1000},{"period":1,"benefit":400,"cost":50},{"period":2,"benefit":400,"cost":50},{"period":3,"benefit":400,"cost":50}]}]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var analyzed api.CostBenefitResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &analyzed))
+	analysis := analyzed.Analysis
+	assert.Equal(t, 0.1, analysis.DiscountRate)
+
+	// retrofit's benefits discount to 200 against a cost of 100; replace
+	// nets 350 a year, worth less than its 1000 at 10%
+	require.Len(t, analysis.Options, 2)
+	retrofit, replace := analysis.Options[0], analysis.Options[1]
+	assert.Equal(t, "retrofit", retrofit.Option)
+	assert.Equal(t, 1, retrofit.Rank)
+	assert.InDelta(t, 100, retrofit.NPV, 1e-9)
+	require.NotNil(t, retrofit.BenefitCostRatio)
+	assert.InDelta(t, 2, *retrofit.BenefitCostRatio, 1e-12)
+	require.NotNil(t, retrofit.IRR)
+	assert.InDelta(t, 0.7798, *retrofit.IRR, 1e-4)
+	require.NotNil(t, retrofit.DiscountedPaybackPeriod)
+	assert.InDelta(t, 1, *retrofit.DiscountedPaybackPeriod, 1e-12)
+	assert.Equal(t, "replace", replace.Option)
+	assert.Less(t, replace.NPV, 0.0)
+	require.NotNil(t, replace.PaybackPeriod)
+	assert.InDelta(t, 2+300.0/350, *replace.PaybackPeriod, 1e-12)
+	assert.Nil(t, replace.DiscountedPaybackPeriod)
+	assert.Equal(t, "retrofit has the highest NPV at a discount rate of 10%, 100, with a benefit-cost ratio of 2, an IRR of about 78%, paying back in 0.909 periods", analysis.Recommendation)
+
+	decisions, err := store.GetDecisions("cba", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Len(t, decisions[0].Options[1].CashFlows, 4)
+	assert.InDelta(t, 100, *decisions[0].Options[0].ExpectedValue, 1e-9)
+	assert.Equal(t, analysis.Recommendation, decisions[0].Recommendation)
+	require.NotNil(t, decisions[0].CostBenefit)
+}
@@ -3,50 +3,109 @@ package intelligence
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/rainmana/gothink/internal/models"
 	"github.com/rainmana/gothink/internal/repository"
+	"github.com/rainmana/gothink/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// CVESource downloads CVE data
+type CVESource interface {
+	DownloadAllCVEs(ctx context.Context) ([]models.CVE, error)
+}
+
+// TechniqueSource downloads ATT&CK techniques
+type TechniqueSource interface {
+	DownloadTechniques(ctx context.Context) ([]models.AttackTechnique, error)
+}
+
+// ProcedureSource downloads OWASP testing procedures
+type ProcedureSource interface {
+	DownloadProcedures(ctx context.Context) ([]models.OWASPProcedure, error)
+}
+
 // IntelligenceService orchestrates intelligence data downloads and storage
 type IntelligenceService struct {
-	nvdDownloader   *NVDDownloader
-	mitreDownloader *MITREDownloader
-	owaspDownloader *OWASPDownloader
+	nvdDownloader   CVESource
+	mitreDownloader TechniqueSource
+	owaspDownloader ProcedureSource
 	securityRepo    *repository.SecurityRepository
+
+	refreshMutex sync.RWMutex
+	refreshedAt  time.Time
 }
 
 // NewIntelligenceService creates a new intelligence service
 func NewIntelligenceService(apiKey string) *IntelligenceService {
+	return NewIntelligenceServiceWithSources(NewNVDDownloader(apiKey), NewMITREDownloader(), NewOWASPDownloader())
+}
+
+// NewIntelligenceServiceWithSources creates a new intelligence service using the given data sources
+func NewIntelligenceServiceWithSources(nvd CVESource, mitre TechniqueSource, owasp ProcedureSource) *IntelligenceService {
 	return &IntelligenceService{
-		nvdDownloader:   NewNVDDownloader(apiKey),
-		mitreDownloader: NewMITREDownloader(),
-		owaspDownloader: NewOWASPDownloader(),
+		nvdDownloader:   nvd,
+		mitreDownloader: mitre,
+		owaspDownloader: owasp,
 		securityRepo:    repository.NewSecurityRepository(),
 	}
 }
 
+// ProgressFunc receives the number of refresh steps completed out of total,
+// with a description of the step just finished
+type ProgressFunc func(done, total int, message string)
+
 // DownloadAndStoreAllIntelligence downloads and stores all intelligence data
 func (s *IntelligenceService) DownloadAndStoreAllIntelligence(ctx context.Context) error {
-	// Download NVD data
-	if err := s.DownloadAndStoreNVDData(ctx); err != nil {
-		return fmt.Errorf("failed to download NVD data: %w", err)
-	}
+	return s.downloadAndStoreAll(ctx, nil)
+}
 
-	// Download MITRE ATT&CK data
-	if err := s.DownloadAndStoreMITREData(ctx); err != nil {
-		return fmt.Errorf("failed to download MITRE data: %w", err)
+// downloadAndStoreAll downloads and stores each source in turn, reporting
+// every completed source to progress when it is set
+func (s *IntelligenceService) downloadAndStoreAll(ctx context.Context, progress ProgressFunc) error {
+	steps := []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{"NVD", s.DownloadAndStoreNVDData},
+		{"MITRE", s.DownloadAndStoreMITREData},
+		{"OWASP", s.DownloadAndStoreOWASPData},
 	}
 
-	// Download OWASP data
-	if err := s.DownloadAndStoreOWASPData(ctx); err != nil {
-		return fmt.Errorf("failed to download OWASP data: %w", err)
+	for i, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		stepCtx, span := telemetry.Tracer().Start(ctx, "intelligence.download",
+			trace.WithAttributes(attribute.String("gothink.intelligence.source", step.name)))
+		err := step.run(stepCtx)
+		telemetry.End(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to download %s data: %w", step.name, err)
+		}
+		if progress != nil {
+			progress(i+1, len(steps), fmt.Sprintf("%s data stored", step.name))
+		}
 	}
 
+	s.refreshMutex.Lock()
+	s.refreshedAt = time.Now()
+	s.refreshMutex.Unlock()
+
 	return nil
 }
 
+// RefreshedAt returns when every source was last downloaded and stored, or
+// the zero time if that has not happened yet
+func (s *IntelligenceService) RefreshedAt() time.Time {
+	s.refreshMutex.RLock()
+	defer s.refreshMutex.RUnlock()
+	return s.refreshedAt
+}
+
 // DownloadAndStoreNVDData downloads and stores NVD CVE data
 func (s *IntelligenceService) DownloadAndStoreNVDData(ctx context.Context) error {
 	// Download CVEs from NVD with retry logic
@@ -141,12 +200,22 @@ func (s *IntelligenceService) GetIntelligenceStats(ctx context.Context) map[stri
 
 // RefreshIntelligenceData refreshes all intelligence data
 func (s *IntelligenceService) RefreshIntelligenceData(ctx context.Context) error {
+	return s.RefreshIntelligenceDataWithProgress(ctx, nil)
+}
+
+// RefreshIntelligenceDataWithProgress refreshes all intelligence data,
+// reporting each source to progress once it is stored. The refresh stops
+// when ctx is cancelled.
+func (s *IntelligenceService) RefreshIntelligenceDataWithProgress(ctx context.Context, progress ProgressFunc) error {
 	// Set a timeout for the refresh operation
 	refreshCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
+	refreshCtx, span := telemetry.Tracer().Start(refreshCtx, "intelligence.refresh")
 
 	// Download and store all intelligence data
-	if err := s.DownloadAndStoreAllIntelligence(refreshCtx); err != nil {
+	err := s.downloadAndStoreAll(refreshCtx, progress)
+	telemetry.End(span, err)
+	if err != nil {
 		return fmt.Errorf("failed to refresh intelligence data: %w", err)
 	}
 
@@ -0,0 +1,130 @@
+// Package kepnertregoe evaluates alternatives by Kepner-Tregoe decision
+// analysis. The objectives of the decision are split into MUSTs, mandatory
+// and measurable, and WANTs, desirable and weighted from 1 to 10 by their
+// importance. An alternative failing any MUST is eliminated; each remaining
+// one is scored from 0 to 10 against every WANT, and its weighted score is
+// the sum of each score times its WANT's weight. The adverse consequences of
+// each alternative are then rated from 1 to 10 for probability and
+// seriousness, their threat being the product; a consequence rated high on
+// both is a serious risk, and the best balanced choice is the alternative of
+// highest weighted score that runs none.
+package kepnertregoe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Ratings of weights, probabilities and seriousness run from MinRating to
+// MaxRating, and scores from 0 to MaxRating
+const (
+	MinRating = 1
+	MaxRating = 10
+)
+
+// HighRating is the rating from which a probability or seriousness is high
+const HighRating = 7
+
+// Consequence is an adverse consequence of an alternative
+type Consequence struct {
+	Description string
+	Probability int
+	Seriousness int
+}
+
+// Alternative is an alternative assessed against the objectives
+type Alternative struct {
+	Name string
+	// Failed names the MUSTs the alternative does not meet
+	Failed []string
+	// Scores are the alternative's scores against the WANTs, in order; an
+	// eliminated alternative needs none
+	Scores       []float64
+	Consequences []Consequence
+}
+
+// Assessment is an alternative's evaluation
+type Assessment struct {
+	Name      string
+	Qualified bool
+	// WeightedScore is the sum of the scores times the weights, and Share
+	// its fraction of the highest possible, both 0 for an eliminated
+	// alternative
+	WeightedScore float64
+	Share         float64
+	// Threat is the sum of the threats of the adverse consequences, and
+	// SeriousRisks the consequences of high probability and seriousness
+	Threat       int
+	SeriousRisks []string
+}
+
+// Analyze assesses alternatives against WANTs of weights
+func Analyze(weights []int, alternatives []Alternative) ([]Assessment, error) {
+	if len(weights) == 0 {
+		return nil, errors.New("there are no WANT objectives")
+	}
+	if len(alternatives) == 0 {
+		return nil, errors.New("there are no alternatives")
+	}
+	total := 0
+	for k, weight := range weights {
+		if weight < MinRating || weight > MaxRating {
+			return nil, fmt.Errorf("WANT %d has weight %d outside [%d, %d]", k+1, weight, MinRating, MaxRating)
+		}
+		total += weight
+	}
+
+	assessments := make([]Assessment, len(alternatives))
+	for i, alternative := range alternatives {
+		assessment := Assessment{Name: alternative.Name, Qualified: len(alternative.Failed) == 0}
+		for _, c := range alternative.Consequences {
+			if c.Probability < MinRating || c.Probability > MaxRating || c.Seriousness < MinRating || c.Seriousness > MaxRating {
+				return nil, fmt.Errorf("adverse consequence %q of %s must rate probability and seriousness from %d to %d", c.Description, alternative.Name, MinRating, MaxRating)
+			}
+			assessment.Threat += c.Probability * c.Seriousness
+			if c.Probability >= HighRating && c.Seriousness >= HighRating {
+				assessment.SeriousRisks = append(assessment.SeriousRisks, c.Description)
+			}
+		}
+		if assessment.Qualified {
+			if len(alternative.Scores) != len(weights) {
+				return nil, fmt.Errorf("%s has %d scores for %d WANTs", alternative.Name, len(alternative.Scores), len(weights))
+			}
+			for k, score := range alternative.Scores {
+				if !(score >= 0 && score <= MaxRating) {
+					return nil, fmt.Errorf("%s scores %g on WANT %d, outside [0, %d]", alternative.Name, score, k+1, MaxRating)
+				}
+				assessment.WeightedScore += score * float64(weights[k])
+			}
+			assessment.Share = assessment.WeightedScore / float64(MaxRating*total)
+		}
+		assessments[i] = assessment
+	}
+	return assessments, nil
+}
+
+// BalancedChoice returns the index of the best balanced choice among
+// assessments: the qualified alternative of highest weighted score that runs
+// no serious risk or, when each runs one, of least threat; -1 when no
+// alternative qualifies
+func BalancedChoice(assessments []Assessment) int {
+	choice := -1
+	for i, a := range assessments {
+		if a.Qualified && (choice < 0 || balancedBefore(a, assessments[choice])) {
+			choice = i
+		}
+	}
+	return choice
+}
+
+// balancedBefore reports whether a is a better balanced choice than b
+func balancedBefore(a, b Assessment) bool {
+	aSafe, bSafe := len(a.SeriousRisks) == 0, len(b.SeriousRisks) == 0
+	switch {
+	case aSafe != bSafe:
+		return aSafe
+	case !aSafe && a.Threat != b.Threat:
+		return a.Threat < b.Threat
+	}
+	return a.WeightedScore > b.WeightedScore
+}
@@ -0,0 +1,62 @@
+package kepnertregoe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze_EliminatesAndScoresAlternatives(t *testing.T) {
+	assessments, err := Analyze([]int{10, 5}, []Alternative{
+		{Name: "cloud", Scores: []float64{10, 6}, Consequences: []Consequence{
+			{Description: "vendor lock-in", Probability: 8, Seriousness: 7},
+			{Description: "cost overrun", Probability: 3, Seriousness: 5},
+		}},
+		{Name: "on-prem", Scores: []float64{7, 10}, Consequences: []Consequence{{Description: "hiring delay", Probability: 6, Seriousness: 4}}},
+		{Name: "hybrid", Failed: []string{"budget"}},
+	})
+	require.NoError(t, err)
+
+	cloud, onPrem, hybrid := assessments[0], assessments[1], assessments[2]
+	assert.True(t, cloud.Qualified)
+	assert.Equal(t, 130.0, cloud.WeightedScore)
+	assert.InDelta(t, 130.0/150, cloud.Share, 1e-12)
+	assert.Equal(t, 71, cloud.Threat)
+	assert.Equal(t, []string{"vendor lock-in"}, cloud.SeriousRisks)
+
+	assert.Equal(t, 120.0, onPrem.WeightedScore)
+	assert.Equal(t, 24, onPrem.Threat)
+	assert.Empty(t, onPrem.SeriousRisks)
+
+	assert.False(t, hybrid.Qualified)
+	assert.Zero(t, hybrid.WeightedScore)
+
+	// cloud scores highest but runs a serious risk
+	assert.Equal(t, 1, BalancedChoice(assessments))
+}
+
+func TestBalancedChoice_FallsBackToTheLeastThreat(t *testing.T) {
+	assert.Equal(t, 1, BalancedChoice([]Assessment{
+		{Name: "a", Qualified: true, WeightedScore: 90, Threat: 80, SeriousRisks: []string{"x"}},
+		{Name: "b", Qualified: true, WeightedScore: 70, Threat: 60, SeriousRisks: []string{"y"}},
+	}))
+	assert.Equal(t, 0, BalancedChoice([]Assessment{
+		{Name: "a", Qualified: true, WeightedScore: 90},
+		{Name: "b", Qualified: true, WeightedScore: 70},
+	}))
+	assert.Equal(t, -1, BalancedChoice([]Assessment{{Name: "a"}}))
+}
+
+func TestAnalyze_RejectsBadRatings(t *testing.T) {
+	_, err := Analyze(nil, []Alternative{{Name: "a"}})
+	assert.Error(t, err)
+	_, err = Analyze([]int{11}, []Alternative{{Name: "a", Scores: []float64{5}}})
+	assert.Error(t, err)
+	_, err = Analyze([]int{5}, []Alternative{{Name: "a", Scores: []float64{12}}})
+	assert.Error(t, err)
+	_, err = Analyze([]int{5}, []Alternative{{Name: "a"}})
+	assert.Error(t, err)
+	_, err = Analyze([]int{5}, []Alternative{{Name: "a", Scores: []float64{5}, Consequences: []Consequence{{Description: "d", Probability: 0, Seriousness: 3}}}})
+	assert.Error(t, err)
+}
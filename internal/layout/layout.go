@@ -0,0 +1,319 @@
+// Package layout positions the nodes of diagrams. Layered layout suits
+// flows and trees: every node sits a layer below the nodes leading to it,
+// ordered within its layer to keep edges short and uncrossed. Force-directed
+// layout suits maps without a direction: nodes repel one another while edges
+// pull the nodes they join together, and the layout settles where the forces
+// balance. Positions grow rightward in x and downward in y, as in SVG, and
+// are rounded to whole units.
+package layout
+
+import "math"
+
+// DefaultSpacing is the distance between neighboring nodes unless Options
+// set another
+const DefaultSpacing = 100
+
+// DefaultIterations is the number of steps a force-directed layout takes
+// unless Options set another
+const DefaultIterations = 300
+
+// Point is the position of a node
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Graph is a diagram's nodes, in order, and the edges between them, each
+// from its first node to its second. Edges naming nodes not listed are
+// ignored.
+type Graph struct {
+	Nodes []string
+	Edges [][2]string
+	// Fixed holds the positions of nodes a force-directed layout must not
+	// move; layered layout places every node by the graph's structure alone
+	Fixed map[string]Point
+}
+
+// Options control a layout
+type Options struct {
+	// Spacing is the distance between neighboring nodes, and between layers
+	Spacing float64
+	// Iterations is the number of steps of a force-directed layout
+	Iterations int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Spacing <= 0 {
+		o.Spacing = DefaultSpacing
+	}
+	if o.Iterations <= 0 {
+		o.Iterations = DefaultIterations
+	}
+	return o
+}
+
+// adjacency returns, for each node of g by index, the indexes of the nodes
+// its edges lead to and come from, dropping self-loops and edges naming
+// unknown nodes
+func adjacency(g Graph) (index map[string]int, out, in [][]int) {
+	index = make(map[string]int, len(g.Nodes))
+	for i, id := range g.Nodes {
+		if _, seen := index[id]; !seen {
+			index[id] = i
+		}
+	}
+	out, in = make([][]int, len(g.Nodes)), make([][]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		from, ok := index[e[0]]
+		to, ok2 := index[e[1]]
+		if !ok || !ok2 || from == to {
+			continue
+		}
+		out[from] = append(out[from], to)
+		in[to] = append(in[to], from)
+	}
+	return index, out, in
+}
+
+// Layered places g in layers top-down. Cycles are broken by ignoring the
+// edges that lead back to a node on the way to them, searching from the
+// nodes no edge leads to, in order; each node then takes the layer below its
+// deepest predecessor. Nodes are ordered within their layers by sweeping
+// down and up, moving each toward the mean position of its neighbors in the
+// layer before, and each layer is centered on the widest.
+func Layered(g Graph, opts Options) map[string]Point {
+	opts = opts.withDefaults()
+	index, out, _ := adjacency(g)
+	n := len(g.Nodes)
+
+	// Keep the edges of a depth-first search that do not close a cycle
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make([]int, n)
+	forward := make([][]int, n)
+	backward := make([][]int, n)
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = onPath
+		for _, w := range out[v] {
+			switch state[w] {
+			case unvisited:
+				visit(w)
+			case onPath:
+				continue
+			}
+			forward[v] = append(forward[v], w)
+			backward[w] = append(backward[w], v)
+		}
+		state[v] = done
+	}
+	hasIncoming := make([]bool, n)
+	for v := range out {
+		for _, w := range out[v] {
+			hasIncoming[w] = true
+		}
+	}
+	for v := 0; v < n; v++ {
+		if !hasIncoming[v] && state[v] == unvisited {
+			visit(v)
+		}
+	}
+	for v := 0; v < n; v++ {
+		if state[v] == unvisited {
+			visit(v)
+		}
+	}
+
+	// Longest path layering, in topological order
+	layer := make([]int, n)
+	pending := make([]int, n)
+	var queue []int
+	for v := 0; v < n; v++ {
+		pending[v] = len(backward[v])
+		if pending[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+	depth := 0
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range forward[v] {
+			if layer[v]+1 > layer[w] {
+				layer[w] = layer[v] + 1
+			}
+			if pending[w]--; pending[w] == 0 {
+				queue = append(queue, w)
+			}
+		}
+		if layer[v] > depth {
+			depth = layer[v]
+		}
+	}
+
+	layers := make([][]int, depth+1)
+	for v := 0; v < n; v++ {
+		if index[g.Nodes[v]] == v {
+			layers[layer[v]] = append(layers[layer[v]], v)
+		}
+	}
+	order := make([]float64, n)
+	for _, nodes := range layers {
+		for i, v := range nodes {
+			order[v] = float64(i)
+		}
+	}
+	sweep := func(from, to, step int, neighbors [][]int) {
+		for l := from; l != to; l += step {
+			nodes := layers[l]
+			key := make(map[int]float64, len(nodes))
+			for _, v := range nodes {
+				key[v] = order[v]
+				if len(neighbors[v]) > 0 {
+					sum := 0.0
+					for _, u := range neighbors[v] {
+						sum += order[u]
+					}
+					key[v] = sum / float64(len(neighbors[v]))
+				}
+			}
+			stableSortBy(nodes, key)
+			for i, v := range nodes {
+				order[v] = float64(i)
+			}
+		}
+	}
+	for pass := 0; pass < 4; pass++ {
+		sweep(1, len(layers), 1, backward)
+		sweep(len(layers)-2, -1, -1, forward)
+	}
+
+	width := 0
+	for _, nodes := range layers {
+		if len(nodes) > width {
+			width = len(nodes)
+		}
+	}
+	positions := make(map[string]Point, n)
+	for l, nodes := range layers {
+		offset := float64(width-len(nodes)) / 2
+		for i, v := range nodes {
+			positions[g.Nodes[v]] = Point{
+				X: math.Round((offset + float64(i)) * opts.Spacing),
+				Y: math.Round(float64(l) * opts.Spacing),
+			}
+		}
+	}
+	return positions
+}
+
+// stableSortBy sorts nodes by key, keeping the order of nodes of equal key
+func stableSortBy(nodes []int, key map[int]float64) {
+	for i := 1; i < len(nodes); i++ {
+		for j := i; j > 0 && key[nodes[j]] < key[nodes[j-1]]; j-- {
+			nodes[j], nodes[j-1] = nodes[j-1], nodes[j]
+		}
+	}
+}
+
+// ForceDirected places g by the Fruchterman-Reingold method: every pair of
+// nodes repels with a force of k²/d and the nodes an edge joins attract with
+// one of d²/k, k being the spacing and d their distance, while the distance a
+// node may move each step cools from the spacing to nothing. Fixed nodes stay
+// where they are, and nodes joined to them start beside them; the others
+// start on a circle. Without fixed nodes the layout is shifted to start at
+// the origin. The layout is deterministic.
+func ForceDirected(g Graph, opts Options) map[string]Point {
+	opts = opts.withDefaults()
+	index, out, in := adjacency(g)
+	n := len(g.Nodes)
+	k := opts.Spacing
+
+	x, y := make([]float64, n), make([]float64, n)
+	fixed := make([]bool, n)
+	for id, p := range g.Fixed {
+		if v, ok := index[id]; ok {
+			x[v], y[v], fixed[v] = p.X, p.Y, true
+		}
+	}
+	radius := k * float64(n) / (2 * math.Pi)
+	for v := 0; v < n; v++ {
+		if fixed[v] {
+			continue
+		}
+		angle := 2 * math.Pi * float64(v) / float64(n)
+		x[v], y[v] = radius*math.Cos(angle), radius*math.Sin(angle)
+		var sx, sy, count float64
+		for _, u := range append(append([]int{}, out[v]...), in[v]...) {
+			if fixed[u] {
+				sx, sy, count = sx+x[u], sy+y[u], count+1
+			}
+		}
+		if count > 0 {
+			x[v], y[v] = sx/count+k*math.Cos(angle), sy/count+k*math.Sin(angle)
+		}
+	}
+
+	dx, dy := make([]float64, n), make([]float64, n)
+	for step := 0; step < opts.Iterations; step++ {
+		for v := range dx {
+			dx[v], dy[v] = 0, 0
+		}
+		for v := 0; v < n; v++ {
+			for u := v + 1; u < n; u++ {
+				ex, ey := x[v]-x[u], y[v]-y[u]
+				d := math.Hypot(ex, ey)
+				if d < 0.01 {
+					// Part coincident nodes in a direction of their own
+					angle := float64(v*n+u) * 2.399963
+					ex, ey, d = 0.01*math.Cos(angle), 0.01*math.Sin(angle), 0.01
+				}
+				force := k * k / d
+				dx[v], dy[v] = dx[v]+ex/d*force, dy[v]+ey/d*force
+				dx[u], dy[u] = dx[u]-ex/d*force, dy[u]-ey/d*force
+			}
+		}
+		for v := range out {
+			for _, u := range out[v] {
+				ex, ey := x[v]-x[u], y[v]-y[u]
+				d := math.Hypot(ex, ey)
+				if d < 0.01 {
+					continue
+				}
+				force := d * d / k
+				dx[v], dy[v] = dx[v]-ex/d*force, dy[v]-ey/d*force
+				dx[u], dy[u] = dx[u]+ex/d*force, dy[u]+ey/d*force
+			}
+		}
+		limit := k * (1 - float64(step)/float64(opts.Iterations))
+		for v := 0; v < n; v++ {
+			if fixed[v] {
+				continue
+			}
+			d := math.Hypot(dx[v], dy[v])
+			if d < 1e-9 {
+				continue
+			}
+			move := math.Min(d, limit)
+			x[v], y[v] = x[v]+dx[v]/d*move, y[v]+dy[v]/d*move
+		}
+	}
+
+	if len(g.Fixed) == 0 && n > 0 {
+		minX, minY := math.Inf(1), math.Inf(1)
+		for v := 0; v < n; v++ {
+			minX, minY = math.Min(minX, x[v]), math.Min(minY, y[v])
+		}
+		for v := 0; v < n; v++ {
+			x[v], y[v] = x[v]-minX, y[v]-minY
+		}
+	}
+	positions := make(map[string]Point, n)
+	for id, v := range index {
+		positions[id] = Point{X: math.Round(x[v]), Y: math.Round(y[v])}
+	}
+	return positions
+}
@@ -0,0 +1,79 @@
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayered_PlacesEachNodeBelowItsPredecessors(t *testing.T) {
+	g := Graph{
+		Nodes: []string{"root", "a", "b", "a1", "b1", "join"},
+		Edges: [][2]string{{"root", "a"}, {"root", "b"}, {"a", "a1"}, {"b", "b1"}, {"a1", "join"}, {"b1", "join"}, {"root", "join"}},
+	}
+	positions := Layered(g, Options{})
+
+	assert.Equal(t, Point{X: 50, Y: 0}, positions["root"])
+	assert.Equal(t, Point{X: 0, Y: 100}, positions["a"])
+	assert.Equal(t, Point{X: 100, Y: 100}, positions["b"])
+	assert.Equal(t, Point{X: 0, Y: 200}, positions["a1"])
+	assert.Equal(t, Point{X: 100, Y: 200}, positions["b1"])
+	// join sits below its deepest predecessor
+	assert.Equal(t, Point{X: 50, Y: 300}, positions["join"])
+}
+
+func TestLayered_OrdersLayersToUncrossEdges(t *testing.T) {
+	g := Graph{
+		Nodes: []string{"a", "b", "b-child", "a-child"},
+		Edges: [][2]string{{"a", "a-child"}, {"b", "b-child"}},
+	}
+	positions := Layered(g, Options{Spacing: 10})
+
+	assert.Equal(t, positions["a"].X, positions["a-child"].X)
+	assert.Equal(t, positions["b"].X, positions["b-child"].X)
+}
+
+func TestLayered_BreaksCycles(t *testing.T) {
+	g := Graph{
+		Nodes: []string{"start", "read", "check", "done"},
+		Edges: [][2]string{{"start", "read"}, {"read", "check"}, {"check", "read"}, {"check", "done"}},
+	}
+	positions := Layered(g, Options{})
+
+	assert.Len(t, positions, 4)
+	assert.Less(t, positions["start"].Y, positions["read"].Y)
+	assert.Less(t, positions["read"].Y, positions["check"].Y)
+	assert.Less(t, positions["check"].Y, positions["done"].Y)
+}
+
+func TestForceDirected_SpreadsNodesAndKeepsFixedOnes(t *testing.T) {
+	g := Graph{
+		Nodes: []string{"a", "b", "c", "d"},
+		Edges: [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"c", "d"}},
+	}
+	positions := ForceDirected(g, Options{})
+	assert.Len(t, positions, 4)
+	assert.Equal(t, positions, ForceDirected(g, Options{}), "the layout is deterministic")
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	for a, p := range positions {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		for b, q := range positions {
+			if a < b {
+				assert.Greater(t, math.Hypot(p.X-q.X, p.Y-q.Y), 30.0, "%s and %s overlap", a, b)
+			}
+		}
+	}
+	assert.Equal(t, 0.0, minX)
+	assert.Equal(t, 0.0, minY)
+
+	g.Nodes = append(g.Nodes, "e")
+	g.Edges = append(g.Edges, [2]string{"d", "e"})
+	g.Fixed = positions
+	grown := ForceDirected(g, Options{})
+	for id, p := range positions {
+		assert.Equal(t, p, grown[id])
+	}
+	assert.Less(t, math.Hypot(grown["e"].X-grown["d"].X, grown["e"].Y-grown["d"].Y), 300.0)
+}
@@ -0,0 +1,311 @@
+// Package mcda ranks the options of a decision by their scores on weighted
+// criteria. The scores of each criterion are first normalized onto a common
+// scale on which higher is better, then combined by a weighted sum, the sum
+// of each normalized score times its criterion's weight, or a weighted
+// product, the product of each normalized score raised to its criterion's
+// weight. The weighted product compares options by ratios, so it needs
+// positive normalized scores but is unaffected by the units of a criterion.
+// TOPSIS instead weighs the normalized scores and scores each option by its
+// closeness to the ideal option, the best weighted score on every criterion,
+// against its distance from the anti-ideal one, the worst on every criterion.
+package mcda
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Methods of combining normalized scores
+const (
+	WeightedSum     = "weighted_sum"
+	WeightedProduct = "weighted_product"
+	// TOPSIS scores each option by its closeness coefficient, its Euclidean
+	// distance from the anti-ideal option over the sum of its distances from
+	// the ideal and the anti-ideal ones
+	TOPSIS = "topsis"
+)
+
+// Normalizations of a criterion's scores. Each turns a cost criterion's
+// scores around, so that the lowest cost normalizes highest.
+const (
+	// None keeps the scores, negating costs in a weighted sum or TOPSIS and
+	// inverting them in a weighted product
+	None = "none"
+	// Max divides benefits by the highest score and divides the lowest cost
+	// by each cost
+	Max = "max"
+	// MinMax maps the lowest score to 0 and the highest to 1, or the
+	// reverse for costs; a criterion scoring every option alike maps to 1
+	MinMax = "minmax"
+	// Sum divides benefits by their total, and the inverses of costs by
+	// theirs
+	Sum = "sum"
+	// Vector divides the scores by their Euclidean norm, taking costs from 1
+	Vector = "vector"
+)
+
+// Criterion is a criterion options are scored on
+type Criterion struct {
+	Name string
+	// Weight is the criterion's importance; weights are scaled to sum to 1,
+	// and count equally when all are 0
+	Weight float64
+	// Cost says lower scores are better
+	Cost bool
+}
+
+// Options control a ranking
+type Options struct {
+	Method        string
+	Normalization string
+}
+
+// Ranked is a ranked option
+type Ranked struct {
+	// Option is the index of the option
+	Option int
+	// Rank is 1 for the best options, and one more than the number of
+	// options scoring higher otherwise, so that tied options share a rank
+	Rank  int
+	Score float64
+	// Contributions holds what each criterion adds to Score in a weighted
+	// sum, multiplies it by in a weighted product, or the weighted normalized
+	// score of TOPSIS
+	Contributions []float64
+	// IdealDistance and AntiIdealDistance are TOPSIS's Euclidean distances of
+	// the weighted normalized scores from the ideal and anti-ideal options
+	IdealDistance     float64
+	AntiIdealDistance float64
+}
+
+// Result is a ranking of options
+type Result struct {
+	// Weights holds the criteria's weights scaled to sum to 1
+	Weights []float64
+	// Normalized holds each option's normalized score on each criterion
+	Normalized [][]float64
+	// Ranking holds the options, best first
+	Ranking []Ranked
+	// Ideal and AntiIdeal hold TOPSIS's best and worst weighted normalized
+	// score on each criterion
+	Ideal     []float64
+	AntiIdeal []float64
+}
+
+// Rank ranks the options scored by scores, a row of scores on criteria per
+// option
+func Rank(criteria []Criterion, scores [][]float64, opts Options) (*Result, error) {
+	switch {
+	case len(criteria) == 0:
+		return nil, errors.New("there are no criteria")
+	case len(scores) == 0:
+		return nil, errors.New("there are no options")
+	case opts.Method != WeightedSum && opts.Method != WeightedProduct && opts.Method != TOPSIS:
+		return nil, fmt.Errorf("unknown method %q", opts.Method)
+	}
+	for i, row := range scores {
+		if len(row) != len(criteria) {
+			return nil, fmt.Errorf("option %d has %d scores for %d criteria", i+1, len(row), len(criteria))
+		}
+		for _, x := range row {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return nil, errors.New("the scores must be finite")
+			}
+		}
+	}
+
+	weights, err := scaleWeights(criteria)
+	if err != nil {
+		return nil, err
+	}
+	normalized := make([][]float64, len(scores))
+	for i := range normalized {
+		normalized[i] = make([]float64, len(criteria))
+	}
+	column := make([]float64, len(scores))
+	for j, criterion := range criteria {
+		for i, row := range scores {
+			column[i] = row[j]
+		}
+		values, err := normalize(column, criterion.Cost, opts)
+		if err != nil {
+			return nil, fmt.Errorf("criterion %s: %v", criterion.Name, err)
+		}
+		for i, v := range values {
+			normalized[i][j] = v
+		}
+	}
+
+	result := &Result{Weights: weights, Normalized: normalized, Ranking: make([]Ranked, len(scores))}
+	if opts.Method == TOPSIS {
+		result.Ideal, result.AntiIdeal = make([]float64, len(criteria)), make([]float64, len(criteria))
+		for j := range criteria {
+			result.Ideal[j], result.AntiIdeal[j] = math.Inf(-1), math.Inf(1)
+			for _, row := range normalized {
+				result.Ideal[j] = math.Max(result.Ideal[j], weights[j]*row[j])
+				result.AntiIdeal[j] = math.Min(result.AntiIdeal[j], weights[j]*row[j])
+			}
+		}
+	}
+	for i, row := range normalized {
+		ranked := Ranked{Option: i, Contributions: make([]float64, len(criteria))}
+		switch opts.Method {
+		case WeightedSum:
+			for j, v := range row {
+				ranked.Contributions[j] = weights[j] * v
+				ranked.Score += ranked.Contributions[j]
+			}
+		case TOPSIS:
+			for j, v := range row {
+				ranked.Contributions[j] = weights[j] * v
+				ranked.IdealDistance += math.Pow(ranked.Contributions[j]-result.Ideal[j], 2)
+				ranked.AntiIdealDistance += math.Pow(ranked.Contributions[j]-result.AntiIdeal[j], 2)
+			}
+			ranked.IdealDistance, ranked.AntiIdealDistance = math.Sqrt(ranked.IdealDistance), math.Sqrt(ranked.AntiIdealDistance)
+			// Options alike on every criterion are each the ideal
+			ranked.Score = 1
+			if total := ranked.IdealDistance + ranked.AntiIdealDistance; total > 0 {
+				ranked.Score = ranked.AntiIdealDistance / total
+			}
+		default:
+			ranked.Score = 1
+			for j, v := range row {
+				if v <= 0 {
+					return nil, fmt.Errorf("criterion %s: the weighted product needs positive normalized scores, which %s normalization does not give", criteria[j].Name, opts.Normalization)
+				}
+				ranked.Contributions[j] = math.Pow(v, weights[j])
+				ranked.Score *= ranked.Contributions[j]
+			}
+		}
+		result.Ranking[i] = ranked
+	}
+
+	sort.SliceStable(result.Ranking, func(a, b int) bool { return result.Ranking[a].Score > result.Ranking[b].Score })
+	for i := range result.Ranking {
+		result.Ranking[i].Rank = i + 1
+		if i > 0 && tied(result.Ranking[i].Score, result.Ranking[i-1].Score) {
+			result.Ranking[i].Rank = result.Ranking[i-1].Rank
+		}
+	}
+	return result, nil
+}
+
+// scaleWeights returns the weights of criteria scaled to sum to 1
+func scaleWeights(criteria []Criterion) ([]float64, error) {
+	total := 0.0
+	for _, criterion := range criteria {
+		if !(criterion.Weight >= 0) || math.IsInf(criterion.Weight, 0) {
+			return nil, fmt.Errorf("criterion %s has a negative or infinite weight", criterion.Name)
+		}
+		total += criterion.Weight
+	}
+	weights := make([]float64, len(criteria))
+	for j, criterion := range criteria {
+		if total == 0 {
+			weights[j] = 1 / float64(len(criteria))
+		} else {
+			weights[j] = criterion.Weight / total
+		}
+	}
+	return weights, nil
+}
+
+// normalize returns the scores of a criterion normalized as opts says, so
+// that higher is better
+func normalize(scores []float64, cost bool, opts Options) ([]float64, error) {
+	lowest, highest := scores[0], scores[0]
+	for _, x := range scores {
+		lowest, highest = math.Min(lowest, x), math.Max(highest, x)
+	}
+	normalized := make([]float64, len(scores))
+	switch opts.Normalization {
+	case None:
+		for i, x := range scores {
+			switch {
+			case !cost:
+				normalized[i] = x
+			case opts.Method != WeightedProduct:
+				normalized[i] = -x
+			case x == 0:
+				return nil, errors.New("a cost of 0 cannot be inverted")
+			default:
+				normalized[i] = 1 / x
+			}
+		}
+
+	case Max:
+		if cost {
+			if lowest <= 0 {
+				return nil, errors.New("max normalization of costs needs positive scores")
+			}
+			for i, x := range scores {
+				normalized[i] = lowest / x
+			}
+			break
+		}
+		if lowest < 0 || highest == 0 {
+			return nil, errors.New("max normalization needs non-negative scores, not all 0")
+		}
+		for i, x := range scores {
+			normalized[i] = x / highest
+		}
+
+	case MinMax:
+		for i, x := range scores {
+			switch {
+			case highest == lowest:
+				normalized[i] = 1
+			case cost:
+				normalized[i] = (highest - x) / (highest - lowest)
+			default:
+				normalized[i] = (x - lowest) / (highest - lowest)
+			}
+		}
+
+	case Sum:
+		if cost && lowest <= 0 {
+			return nil, errors.New("sum normalization of costs needs positive scores")
+		}
+		if !cost && (lowest < 0 || highest == 0) {
+			return nil, errors.New("sum normalization needs non-negative scores, not all 0")
+		}
+		total := 0.0
+		for i, x := range scores {
+			normalized[i] = x
+			if cost {
+				normalized[i] = 1 / x
+			}
+			total += normalized[i]
+		}
+		for i := range normalized {
+			normalized[i] /= total
+		}
+
+	case Vector:
+		norm := 0.0
+		for _, x := range scores {
+			norm += x * x
+		}
+		if norm == 0 {
+			return nil, errors.New("vector normalization needs a score other than 0")
+		}
+		norm = math.Sqrt(norm)
+		for i, x := range scores {
+			normalized[i] = x / norm
+			if cost {
+				normalized[i] = 1 - normalized[i]
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown normalization %q", opts.Normalization)
+	}
+	return normalized, nil
+}
+
+// tied reports whether scores a and b differ by no more than rounding
+func tied(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
@@ -0,0 +1,168 @@
+package mcda
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vendors are scored on price, a cost, and on speed and support
+var (
+	vendors = []Criterion{
+		{Name: "price", Weight: 0.5, Cost: true},
+		{Name: "speed", Weight: 0.3},
+		{Name: "support", Weight: 0.2},
+	}
+	vendorScores = [][]float64{
+		{100, 8, 6},
+		{150, 9, 9},
+		{120, 5, 7},
+	}
+)
+
+func TestRank_SumsMaxNormalizedScores(t *testing.T) {
+	result, err := Rank(vendors, vendorScores, Options{Method: WeightedSum, Normalization: Max})
+	require.NoError(t, err)
+
+	// The cheapest price and the highest speed and support normalize to 1
+	assert.InDeltaSlice(t, []float64{1, 8.0 / 9, 6.0 / 9}, result.Normalized[0], 1e-12)
+	assert.InDeltaSlice(t, []float64{100.0 / 150, 1, 1}, result.Normalized[1], 1e-12)
+
+	require.Len(t, result.Ranking, 3)
+	for i, want := range []struct {
+		option int
+		score  float64
+	}{
+		{0, 0.5 + 0.3*8/9 + 0.2*6/9},
+		{1, 0.5*100/150 + 0.3 + 0.2},
+		{2, 0.5*100/120 + 0.3*5/9 + 0.2*7/9},
+	} {
+		ranked := result.Ranking[i]
+		assert.Equal(t, want.option, ranked.Option)
+		assert.Equal(t, i+1, ranked.Rank)
+		assert.InDelta(t, want.score, ranked.Score, 1e-12)
+	}
+	assert.InDeltaSlice(t, []float64{0.5, 0.3 * 8 / 9, 0.2 * 6 / 9}, result.Ranking[0].Contributions, 1e-12)
+}
+
+func TestRank_SumsMinMaxNormalizedScores(t *testing.T) {
+	result, err := Rank(vendors, vendorScores, Options{Method: WeightedSum, Normalization: MinMax})
+	require.NoError(t, err)
+
+	assert.InDeltaSlice(t, []float64{1, 0.75, 0}, result.Normalized[0], 1e-12)
+	assert.InDeltaSlice(t, []float64{0.6, 0, 1.0 / 3}, result.Normalized[2], 1e-12)
+	assert.InDelta(t, 0.725, result.Ranking[0].Score, 1e-12)
+	assert.InDelta(t, 0.5, result.Ranking[1].Score, 1e-12)
+	assert.InDelta(t, 0.3+0.2/3, result.Ranking[2].Score, 1e-12)
+}
+
+func TestRank_MultipliesWeightedScores(t *testing.T) {
+	result, err := Rank(vendors, vendorScores, Options{Method: WeightedProduct, Normalization: Max})
+	require.NoError(t, err)
+
+	want := math.Pow(8.0/9, 0.3) * math.Pow(6.0/9, 0.2)
+	assert.Equal(t, 0, result.Ranking[0].Option)
+	assert.InDelta(t, want, result.Ranking[0].Score, 1e-12)
+	assert.InDelta(t, math.Pow(100.0/150, 0.5), result.Ranking[1].Score, 1e-12)
+
+	// Without normalization the ratios, and so the ranking, are the same
+	raw, err := Rank(vendors, vendorScores, Options{Method: WeightedProduct, Normalization: None})
+	require.NoError(t, err)
+	for i := range raw.Ranking {
+		assert.Equal(t, result.Ranking[i].Option, raw.Ranking[i].Option)
+	}
+
+	// Min-max normalization maps the worst score to 0, which no product
+	// can weigh
+	_, err = Rank(vendors, vendorScores, Options{Method: WeightedProduct, Normalization: MinMax})
+	assert.Error(t, err)
+}
+
+func TestRank_MeasuresClosenessToTheIdeal(t *testing.T) {
+	result, err := Rank(vendors, vendorScores, Options{Method: TOPSIS, Normalization: Vector})
+	require.NoError(t, err)
+
+	// Taking vector-normalized costs from 1 moves the ideal and anti-ideal
+	// prices with them, so the distances are those of textbook TOPSIS, which
+	// takes the lowest price as ideal
+	norm := math.Sqrt(100*100 + 150*150 + 120*120)
+	assert.InDelta(t, 0.5*(1-100/norm), result.Ideal[0], 1e-12)
+	assert.InDelta(t, 0.5*(1-150/norm), result.AntiIdeal[0], 1e-12)
+	assert.InDelta(t, 0.3*9/math.Sqrt(170), result.Ideal[1], 1e-12)
+	assert.InDelta(t, 0.2*6/math.Sqrt(166), result.AntiIdeal[2], 1e-12)
+
+	for i, want := range []struct {
+		option           int
+		ideal, antiIdeal float64
+		closeness        float64
+	}{
+		{0, 0.0519431079, 0.1345025349, 0.7214034763},
+		{1, 0.1154392741, 0.1031468028, 0.4718818520},
+		{2, 0.1075483139, 0.0709817245, 0.3975898127},
+	} {
+		ranked := result.Ranking[i]
+		assert.Equal(t, want.option, ranked.Option)
+		assert.Equal(t, i+1, ranked.Rank)
+		assert.InDelta(t, want.ideal, ranked.IdealDistance, 1e-9)
+		assert.InDelta(t, want.antiIdeal, ranked.AntiIdealDistance, 1e-9)
+		assert.InDelta(t, want.closeness, ranked.Score, 1e-9)
+	}
+	assert.InDelta(t, 0.3*8/math.Sqrt(170), result.Ranking[0].Contributions[1], 1e-12)
+
+	// Options alike on every criterion are each the ideal
+	result, err = Rank(vendors, [][]float64{{1, 2, 3}, {1, 2, 3}}, Options{Method: TOPSIS, Normalization: Max})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, result.Ranking[0].Score)
+	assert.Equal(t, 1, result.Ranking[1].Rank)
+}
+
+func TestRank_NormalizesBySumAndVector(t *testing.T) {
+	result, err := Rank(vendors, vendorScores, Options{Method: WeightedSum, Normalization: Sum})
+	require.NoError(t, err)
+	inverses := 1.0/100 + 1.0/150 + 1.0/120
+	assert.InDelta(t, (1.0/100)/inverses, result.Normalized[0][0], 1e-12)
+	assert.InDelta(t, 8.0/22, result.Normalized[0][1], 1e-12)
+
+	result, err = Rank(vendors, vendorScores, Options{Method: WeightedSum, Normalization: Vector})
+	require.NoError(t, err)
+	norm := math.Sqrt(100*100 + 150*150 + 120*120)
+	assert.InDelta(t, 1-100/norm, result.Normalized[0][0], 1e-12)
+	assert.InDelta(t, 8/math.Sqrt(64+81+25), result.Normalized[0][1], 1e-12)
+}
+
+func TestRank_SharesRanksAndWeights(t *testing.T) {
+	criteria := []Criterion{{Name: "x"}, {Name: "y"}}
+	result, err := Rank(criteria, [][]float64{{1, 3}, {3, 1}, {1, 1}}, Options{Method: WeightedSum, Normalization: None})
+	require.NoError(t, err)
+
+	// Criteria without weights count equally, and tied options share a rank
+	assert.Equal(t, []float64{0.5, 0.5}, result.Weights)
+	assert.Equal(t, []int{1, 1, 3}, []int{result.Ranking[0].Rank, result.Ranking[1].Rank, result.Ranking[2].Rank})
+	assert.Equal(t, []int{0, 1, 2}, []int{result.Ranking[0].Option, result.Ranking[1].Option, result.Ranking[2].Option})
+}
+
+func TestRank_RejectsInvalidRankings(t *testing.T) {
+	valid := Options{Method: WeightedSum, Normalization: Max}
+	for name, c := range map[string]struct {
+		criteria []Criterion
+		scores   [][]float64
+		opts     Options
+	}{
+		"no criteria":        {nil, vendorScores, valid},
+		"no options":         {vendors, nil, valid},
+		"short row":          {vendors, [][]float64{{1, 2}}, valid},
+		"NaN":                {vendors, [][]float64{{1, math.NaN(), 2}}, valid},
+		"negative weight":    {[]Criterion{{Name: "x", Weight: -1}}, [][]float64{{1}}, valid},
+		"negative benefit":   {[]Criterion{{Name: "x"}}, [][]float64{{-1}, {2}}, valid},
+		"zero cost":          {[]Criterion{{Name: "x", Cost: true}}, [][]float64{{0}, {2}}, valid},
+		"unknown method":     {vendors, vendorScores, Options{Method: "electre", Normalization: Max}},
+		"unknown scale":      {vendors, vendorScores, Options{Method: WeightedSum, Normalization: "log"}},
+		"all-zero vector":    {[]Criterion{{Name: "x"}}, [][]float64{{0}, {0}}, Options{Method: WeightedSum, Normalization: Vector}},
+		"non-positive ratio": {[]Criterion{{Name: "x"}}, [][]float64{{0}, {2}}, Options{Method: WeightedProduct, Normalization: None}},
+	} {
+		_, err := Rank(c.criteria, c.scores, c.opts)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,57 @@
+package mcpserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/audit"
+)
+
+// Option customizes the MCP server built by New
+type Option func(*options)
+
+type options struct {
+	audit *audit.Logger
+}
+
+// WithAuditLog records every tool call in logger
+func WithAuditLog(logger *audit.Logger) Option {
+	return func(o *options) {
+		o.audit = logger
+	}
+}
+
+// auditMiddleware records each tool call, including calls rejected by the
+// middlewares that follow it, in the audit log
+func auditMiddleware(logger *audit.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if logger == nil {
+			return next
+		}
+
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+
+			call := audit.Call{
+				Tool:      req.Params.Name,
+				SessionID: req.GetString("session_id", ""),
+				TenantID:  req.GetString("tenant_id", ""),
+				Arguments: req.GetArguments(),
+				Duration:  time.Since(start),
+				Outcome:   audit.OutcomeSuccess,
+			}
+			if err != nil {
+				call.Outcome, call.ErrorCode, call.Error = audit.OutcomeError, string(apierror.CodeOf(err)), err.Error()
+			} else if failure := apierror.FromToolResult(result); failure != nil {
+				call.Outcome, call.ErrorCode, call.Error = audit.OutcomeError, string(failure.Code), failure.Message
+			}
+			logger.Record(call)
+
+			return result, err
+		}
+	}
+}
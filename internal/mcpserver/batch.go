@@ -0,0 +1,155 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+)
+
+// batchTool names the tool that runs batches
+const batchTool = "batch_execute"
+
+// MaxBatchCalls is the most tool calls a batch may hold
+const MaxBatchCalls = 100
+
+// ExecuteBatch runs the calls of request on s in order, each through the
+// same validation, tenancy, rate limiting, worker pool and audit log as a
+// call made on its own. A failed call does not stop the batch unless
+// request.StopOnError is set; calls left once ctx ends are skipped. Calls
+// that name no tenant_id run in the tenant of ctx.
+func ExecuteBatch(ctx context.Context, s *server.MCPServer, request api.BatchRequest) (*api.BatchResponse, error) {
+	if len(request.Calls) == 0 {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "calls is required")
+	}
+	if len(request.Calls) > MaxBatchCalls {
+		return nil, apierror.Errorf(apierror.CodeInvalidParameters, "a batch holds at most %d calls, got %d", MaxBatchCalls, len(request.Calls))
+	}
+
+	response := &api.BatchResponse{Status: api.BatchStatusSuccess, Results: make([]api.BatchResult, len(request.Calls))}
+	stopped := false
+	for i, call := range request.Calls {
+		result := &response.Results[i]
+		result.Index = i
+		result.Tool = call.Tool
+
+		switch {
+		case stopped || ctx.Err() != nil:
+			result.Status = api.BatchStatusSkipped
+			response.Skipped++
+			continue
+		case call.Tool == batchTool:
+			result.Error = &api.Error{Code: string(apierror.CodeInvalidParameters), Message: "batches cannot be nested"}
+		default:
+			result.Result, result.Error = callTool(ctx, s, i, call)
+		}
+
+		if result.Error != nil {
+			result.Status = api.BatchStatusError
+			response.Failed++
+			stopped = request.StopOnError
+		} else {
+			result.Status = api.BatchStatusSuccess
+			response.Succeeded++
+		}
+	}
+
+	return response, nil
+}
+
+// callTool makes one call of a batch on s, returning the tool's result or
+// the error it reports
+func callTool(ctx context.Context, s *server.MCPServer, id int, call api.BatchCall) (json.RawMessage, *api.Error) {
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  string(mcp.MethodToolsCall),
+		"params":  map[string]interface{}{"name": call.Tool, "arguments": call.Arguments},
+	})
+	if err != nil {
+		return nil, &api.Error{Code: string(apierror.CodeInvalidParameters), Message: err.Error()}
+	}
+
+	switch response := s.HandleMessage(ctx, message).(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := response.Result.(mcp.CallToolResult)
+		if !ok {
+			return nil, &api.Error{Code: string(apierror.CodeInternal), Message: "unexpected tool result"}
+		}
+		if failure := apierror.FromToolResult(&result); failure != nil {
+			return nil, &api.Error{Code: string(failure.Code), Message: failure.Message}
+		}
+		return resultJSON(&result), nil
+	case mcp.JSONRPCError:
+		// Unknown tools and malformed calls are protocol errors
+		return nil, &api.Error{Code: string(apierror.CodeInvalidParameters), Message: response.Error.Message}
+	default:
+		return nil, &api.Error{Code: string(apierror.CodeInternal), Message: "unexpected response to tool call"}
+	}
+}
+
+// resultJSON returns the text of a tool result as JSON: the text itself when
+// it is JSON, or a JSON string holding it
+func resultJSON(result *mcp.CallToolResult) json.RawMessage {
+	var text string
+	for _, content := range result.Content {
+		if textContent, ok := mcp.AsTextContent(content); ok {
+			text += textContent.Text
+		}
+	}
+	if json.Valid([]byte(text)) {
+		return json.RawMessage(text)
+	}
+	encoded, _ := json.Marshal(text)
+	return encoded
+}
+
+// addBatchTool adds batch_execute, which runs tool calls of s in order
+func addBatchTool(s *server.MCPServer) {
+	s.AddTool(
+		mcp.NewTool(batchTool,
+			mcp.WithDescription("Run an ordered list of tool calls in one request and report the result of each, for playbook-style workflows"),
+			withRequest(api.BatchRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.BatchRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := ExecuteBatch(ctx, s, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+// NewBatchHandler serves POST /api/v1/batch, running the batch of the request
+// body on s in the tenant of the request
+func NewBatchHandler(s *server.MCPServer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request api.BatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			apierror.Write(w, apierror.CodeInvalidParameters, "Invalid request body")
+			return
+		}
+
+		response, err := ExecuteBatch(r.Context(), s, request)
+		if err != nil {
+			apierror.Write(w, apierror.CodeOf(err), err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+}
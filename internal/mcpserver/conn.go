@@ -0,0 +1,130 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// connSession is the MCP session of one WebSocket or stdio connection
+type connSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func newConnSession(id string) *connSession {
+	return &connSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 100)}
+}
+
+func (s *connSession) SessionID() string {
+	return s.id
+}
+
+func (s *connSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *connSession) Initialize() {
+	s.initialized.Store(true)
+}
+
+func (s *connSession) Initialized() bool {
+	return s.initialized.Load()
+}
+
+// connection routes the messages of one client connection to the server,
+// whatever its transport. The transport writes the queued outgoing messages
+// and the session's notifications.
+type connection struct {
+	server  *server.MCPServer
+	session *connSession
+
+	// outgoing carries the responses and errors to write; notifications come
+	// from the session
+	outgoing chan interface{}
+
+	// inFlight cancels the running requests by ID
+	inFlightMutex sync.Mutex
+	inFlight      map[string]context.CancelFunc
+	requests      sync.WaitGroup
+}
+
+func newConnection(s *server.MCPServer, session *connSession) *connection {
+	return &connection{
+		server:   s,
+		session:  session,
+		outgoing: make(chan interface{}),
+		inFlight: make(map[string]context.CancelFunc),
+	}
+}
+
+// connMessage holds the fields of a JSON-RPC message needed to route it
+type connMessage struct {
+	ID     *mcp.RequestId `json:"id"`
+	Method string         `json:"method"`
+	Params struct {
+		RequestID *mcp.RequestId `json:"requestId"`
+	} `json:"params"`
+}
+
+// handle routes one message: requests run concurrently, notifications in
+// order, and responses to server requests are not expected
+func (c *connection) handle(ctx context.Context, data []byte) {
+	var message connMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		c.send(ctx, mcp.NewJSONRPCError(mcp.NewRequestId(nil), mcp.PARSE_ERROR, "Parse error", nil))
+		return
+	}
+
+	switch {
+	case message.Method == "notifications/cancelled":
+		if message.Params.RequestID != nil {
+			c.cancel(*message.Params.RequestID)
+		}
+	case message.Method == "":
+		// A response; GoThink sends no requests to clients
+	case message.ID == nil:
+		HandleMessage(ctx, c.server, data)
+	default:
+		id := message.ID.String()
+		requestCtx, cancel := context.WithCancel(ctx)
+		c.inFlightMutex.Lock()
+		c.inFlight[id] = cancel
+		c.inFlightMutex.Unlock()
+
+		c.requests.Add(1)
+		go func() {
+			defer c.requests.Done()
+			defer c.cancel(*message.ID)
+
+			if response := HandleMessage(requestCtx, c.server, data); response != nil {
+				c.send(ctx, response)
+			}
+		}()
+	}
+}
+
+// cancel cancels the running request with the given ID, if any
+func (c *connection) cancel(id mcp.RequestId) {
+	c.inFlightMutex.Lock()
+	cancel, ok := c.inFlight[id.String()]
+	delete(c.inFlight, id.String())
+	c.inFlightMutex.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// send queues message to be written to the client, dropping it once ctx ends
+func (c *connection) send(ctx context.Context, message interface{}) {
+	select {
+	case c.outgoing <- message:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,102 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/models"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// addPrompts registers a prompt for each mental model of the catalog, named
+// after its key, and one for each debugging approach, named with the
+// debugging prefix. The catalog is read once, when the server is created.
+func addPrompts(s *server.MCPServer, modelsLoader *models.Loader, cfg *config.Config) {
+	availableModels, err := modelsLoader.LoadMentalModels(cfg.MentalModelsPath)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range modelsLoader.GetModelsByPriority(availableModels) {
+		key, model := entry.Key, entry.Model
+		s.AddPrompt(
+			mcp.NewPrompt(key,
+				mcp.WithPromptDescription(fmt.Sprintf("%s: %s", model.Name, model.Description)),
+				mcp.WithArgument("problem", mcp.RequiredArgument(), mcp.ArgumentDescription("Problem to apply the mental model to")),
+				mcp.WithArgument("context", mcp.ArgumentDescription("Background on the problem")),
+			),
+			func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				problem := req.Params.Arguments["problem"]
+				if strings.TrimSpace(problem) == "" {
+					return nil, fmt.Errorf("problem is required")
+				}
+
+				var text strings.Builder
+				fmt.Fprintf(&text, "Apply %s to the following problem. %s.\n\n", model.Name, model.Description)
+				fmt.Fprintf(&text, "Problem: %s\n", problem)
+				if background := req.Params.Arguments["context"]; background != "" {
+					fmt.Fprintf(&text, "Context: %s\n", background)
+				}
+				text.WriteString("\nWork through these steps in order:\n")
+				writeSteps(&text, model.Steps)
+				fmt.Fprintf(&text, "\nRecord the analysis with the mental_model tool (model_name %q), then attach your conclusion with update_mental_model_conclusion.", key)
+
+				return promptResult(model.Name, text.String()), nil
+			},
+		)
+	}
+
+	keys := make([]string, 0, len(types.DebuggingApproaches))
+	for key := range types.DebuggingApproaches {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		key, approach := key, types.DebuggingApproaches[key]
+		s.AddPrompt(
+			mcp.NewPrompt(types.DebuggingModelPrefix+key,
+				mcp.WithPromptDescription(fmt.Sprintf("%s: %s", approach.Name, approach.Description)),
+				mcp.WithArgument("issue", mcp.RequiredArgument(), mcp.ArgumentDescription("Issue to debug")),
+				mcp.WithArgument("context", mcp.ArgumentDescription("What is known about the issue so far")),
+			),
+			func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				issue := req.Params.Arguments["issue"]
+				if strings.TrimSpace(issue) == "" {
+					return nil, fmt.Errorf("issue is required")
+				}
+
+				var text strings.Builder
+				fmt.Fprintf(&text, "Debug the following issue using %s. %s.\n\n", approach.Name, approach.Description)
+				fmt.Fprintf(&text, "Issue: %s\n", issue)
+				if background := req.Params.Arguments["context"]; background != "" {
+					fmt.Fprintf(&text, "Context: %s\n", background)
+				}
+				text.WriteString("\nWork through these steps in order:\n")
+				writeSteps(&text, approach.Steps)
+				fmt.Fprintf(&text, "\nRecord the approach with the debugging_approach tool (approach_name %q), then record what you find with record_debugging_findings.", key)
+
+				return promptResult(approach.Name, text.String()), nil
+			},
+		)
+	}
+}
+
+// writeSteps writes steps as a numbered list
+func writeSteps(text *strings.Builder, steps []string) {
+	for i, step := range steps {
+		fmt.Fprintf(text, "%d. %s\n", i+1, step)
+	}
+}
+
+// promptResult returns a prompt made of a single user message
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	})
+}
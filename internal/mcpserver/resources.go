@@ -0,0 +1,249 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/models"
+	"github.com/rainmana/gothink/internal/storage"
+)
+
+// Resource URIs. Sessions of a tenant namespace live under
+// gothink://tenant/{tenant_id}/ with the same paths as shared sessions.
+const (
+	resourceRoot         = "gothink://"
+	mentalModelsResource = "gothink://mental-models"
+)
+
+// addResources registers sessions, session exports, diagrams and the mental
+// model catalog as MCP resources
+func addResources(s *server.MCPServer, store storage.Store, modelsLoader *models.Loader, cfg *config.Config) {
+	s.AddResource(
+		mcp.NewResource(sessionsURI(""), "Sessions",
+			mcp.WithResourceDescription("Sessions of the shared namespace and their resource URIs"),
+			mcp.WithMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readSessions(store, "", req.Params.URI)
+		},
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(resourceRoot+"tenant/{tenant_id}/sessions", "Tenant sessions",
+			mcp.WithTemplateDescription("Sessions of a tenant namespace and their resource URIs"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			tenantID := resourceArg(req, "tenant_id")
+			if err := storage.ValidateTenantID(tenantID); err != nil {
+				return nil, err
+			}
+			return readSessions(store, tenantID, req.Params.URI)
+		},
+	)
+
+	for _, prefix := range []string{resourceRoot, resourceRoot + "tenant/{tenant_id}/"} {
+		s.AddResourceTemplate(
+			mcp.NewResourceTemplate(prefix+"session/{session_id}", "Session",
+				mcp.WithTemplateDescription("Session metadata and statistics"),
+				mcp.WithTemplateMIMEType("application/json"),
+			),
+			sessionResource(store, func(store storage.Store, session *storage.SessionData) (interface{}, error) {
+				stats, err := store.GetSessionStats(session.ID)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{
+					"session":    session,
+					"statistics": stats,
+				}, nil
+			}),
+		)
+		s.AddResourceTemplate(
+			mcp.NewResourceTemplate(prefix+"session/{session_id}/export", "Session export",
+				mcp.WithTemplateDescription("Every record of a session, as produced by session_export"),
+				mcp.WithTemplateMIMEType("application/json"),
+			),
+			sessionResource(store, func(store storage.Store, session *storage.SessionData) (interface{}, error) {
+				return store.ExportSession(session.ID)
+			}),
+		)
+		s.AddResourceTemplate(
+			mcp.NewResourceTemplate(prefix+"session/{session_id}/diagrams", "Session diagrams",
+				mcp.WithTemplateDescription("Visual reasoning records of a session, oldest first"),
+				mcp.WithTemplateMIMEType("application/json"),
+			),
+			sessionResource(store, func(store storage.Store, session *storage.SessionData) (interface{}, error) {
+				diagrams, err := store.GetVisualData(session.ID, nil)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{
+					"session_id": session.ID,
+					"count":      len(diagrams),
+					"diagrams":   diagrams,
+				}, nil
+			}),
+		)
+	}
+
+	s.AddResource(
+		mcp.NewResource(mentalModelsResource, "Mental models",
+			mcp.WithResourceDescription("Catalog of the available mental models"),
+			mcp.WithMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			availableModels, err := modelsLoader.LoadMentalModels(cfg.MentalModelsPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load mental models: %w", err)
+			}
+			return jsonResource(req.Params.URI, map[string]interface{}{
+				"total_models": len(availableModels),
+				"models":       availableModels,
+			})
+		},
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(mentalModelsResource+"/{name}", "Mental model",
+			mcp.WithTemplateDescription("A single mental model of the catalog"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			availableModels, err := modelsLoader.LoadMentalModels(cfg.MentalModelsPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load mental models: %w", err)
+			}
+			name := resourceArg(req, "name")
+			model, exists := availableModels[name]
+			if !exists {
+				return nil, fmt.Errorf("unknown mental model: %s", name)
+			}
+			return jsonResource(req.Params.URI, model)
+		},
+	)
+}
+
+// notifyResourceUpdates sends notifications/resources/updated for the session
+// resources changed by each store write to the clients subscribed to them,
+// until the store is closed. Stores that publish no events send none.
+func notifyResourceUpdates(s *server.MCPServer, store storage.Store, subs *subscriptions) {
+	publisher, ok := store.(interface{ Events() *storage.EventBus })
+	if !ok {
+		return
+	}
+
+	sub := publisher.Events().SubscribeAll(0)
+	go func() {
+		for event := range sub.Events() {
+			for _, uri := range updatedResources(event) {
+				for _, sessionID := range subs.subscribers(uri) {
+					// A session that ended meanwhile has nothing to notify
+					s.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+				}
+			}
+		}
+	}()
+}
+
+// updatedResources returns the URIs of the resources a store event changes
+func updatedResources(event storage.Event) []string {
+	tenantID, sessionID := storage.SplitTenantSessionID(event.SessionID)
+	session := sessionURI(tenantID, sessionID)
+
+	uris := []string{session, session + "/export"}
+	switch event.Type {
+	case storage.OpAddVisualData, storage.OpUpdateVisualData, storage.OpClearSession:
+		uris = append(uris, session+"/diagrams")
+	}
+	switch event.Type {
+	case storage.OpCreateSession, storage.OpClearSession:
+		uris = append(uris, sessionsURI(tenantID))
+	}
+
+	return uris
+}
+
+// sessionURI returns the resource URI of a session
+func sessionURI(tenantID, sessionID string) string {
+	if tenantID == "" {
+		return resourceRoot + "session/" + sessionID
+	}
+	return resourceRoot + "tenant/" + tenantID + "/session/" + sessionID
+}
+
+// sessionsURI returns the resource URI listing a namespace's sessions
+func sessionsURI(tenantID string) string {
+	if tenantID == "" {
+		return resourceRoot + "sessions"
+	}
+	return resourceRoot + "tenant/" + tenantID + "/sessions"
+}
+
+// readSessions lists the sessions of a namespace with their resource URIs
+func readSessions(store storage.Store, tenantID, uri string) ([]mcp.ResourceContents, error) {
+	ids, err := storage.ForTenant(store, tenantID).ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		sessions = append(sessions, map[string]string{"id": id, "uri": sessionURI(tenantID, id)})
+	}
+
+	return jsonResource(uri, map[string]interface{}{
+		"count":    len(sessions),
+		"sessions": sessions,
+	})
+}
+
+// sessionResource returns a handler for a resource of one session, built by
+// read from the tenant's store once the session is known to exist
+func sessionResource(store storage.Store, read func(store storage.Store, session *storage.SessionData) (interface{}, error)) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		tenantID := resourceArg(req, "tenant_id")
+		if err := storage.ValidateTenantID(tenantID); err != nil {
+			return nil, err
+		}
+		scoped := storage.WithTracing(ctx, storage.ForTenant(store, tenantID))
+
+		session, err := scoped.GetSession(resourceArg(req, "session_id"))
+		if err != nil {
+			return nil, err
+		}
+		content, err := read(scoped, session)
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonResource(req.Params.URI, content)
+	}
+}
+
+// resourceArg returns a variable matched from a resource template
+func resourceArg(req mcp.ReadResourceRequest, name string) string {
+	switch value := req.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// jsonResource encodes content as the JSON text of a resource
+func jsonResource(uri string, content interface{}) ([]mcp.ResourceContents, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource %s: %w", uri, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+	}, nil
+}
@@ -0,0 +1,186 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/storage"
+)
+
+// requestIDArgument names the optional argument that makes a tool call
+// idempotent
+const requestIDArgument = "request_id"
+
+// resultStore retains the results of tool calls made with a request_id, so a
+// client retrying a call gets the result of the first instead of running it
+// again. Results are kept for retention, and at most maxResults of them.
+type resultStore struct {
+	retention  time.Duration
+	maxResults int
+
+	mu      sync.Mutex
+	results map[string]*retainedResult
+	// order holds the keys of results in the order they were stored, which is
+	// also the order they expire in
+	order []string
+}
+
+// retainedResult is the result of a call, or the call still running when
+// done is open
+type retainedResult struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{}
+	result      *mcp.CallToolResult
+	stored      time.Time
+}
+
+func newResultStore(retention time.Duration, maxResults int) *resultStore {
+	return &resultStore{
+		retention:  retention,
+		maxResults: maxResults,
+		results:    make(map[string]*retainedResult),
+	}
+}
+
+// claim returns the result retained under key, waiting for it while its call
+// runs, or claims key for a new call when there is none; the caller of a new
+// call must then store or release key. A retained call whose fingerprint
+// differs was a different call under the same request ID.
+func (r *resultStore) claim(ctx context.Context, key string, fingerprint [sha256.Size]byte) (retained *retainedResult, claimed bool) {
+	for {
+		r.mu.Lock()
+		r.expire(time.Now())
+		retained, ok := r.results[key]
+		if !ok {
+			r.results[key] = &retainedResult{fingerprint: fingerprint, done: make(chan struct{})}
+			r.mu.Unlock()
+			return nil, true
+		}
+		r.mu.Unlock()
+
+		if retained.fingerprint != fingerprint {
+			return retained, false
+		}
+		select {
+		case <-retained.done:
+			if retained.result != nil {
+				return retained, false
+			}
+			// The call failed and was released; try again
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// store retains the result of the call claimed under key
+func (r *resultStore) store(key string, result *mcp.CallToolResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	retained := r.results[key]
+	retained.result, retained.stored = result, time.Now()
+	close(retained.done)
+
+	r.order = append(r.order, key)
+	for len(r.order) > r.maxResults && r.maxResults > 0 {
+		delete(r.results, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// release drops the claim on key of a call that failed, so a retry runs again
+func (r *resultStore) release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	close(r.results[key].done)
+	delete(r.results, key)
+}
+
+// expire drops the results stored before the retention period; the caller
+// holds r.mu
+func (r *resultStore) expire(now time.Time) {
+	for len(r.order) > 0 {
+		retained, ok := r.results[r.order[0]]
+		if ok && now.Sub(retained.stored) < r.retention {
+			return
+		}
+		delete(r.results, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// retentionMiddleware returns the retained result of a tool call that repeats
+// the request_id of an earlier successful call of the same tenant, instead of
+// running it again, so retries do not create duplicate records. Failed calls
+// are not retained, and a request_id reused for a different call is rejected.
+// A nil store retains nothing.
+func retentionMiddleware(results *resultStore) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			requestID := req.GetString(requestIDArgument, "")
+			if requestID == "" {
+				return next(ctx, req)
+			}
+
+			// Handlers never see the request ID
+			args := make(map[string]interface{}, len(req.GetArguments()))
+			for name, value := range req.GetArguments() {
+				if name != requestIDArgument {
+					args[name] = value
+				}
+			}
+			req.Params.Arguments = args
+			if results == nil {
+				return next(ctx, req)
+			}
+
+			encoded, err := json.Marshal(map[string]interface{}{"tool": req.Params.Name, "arguments": args})
+			if err != nil {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "Invalid arguments for %s: %v", req.Params.Name, err), nil
+			}
+			fingerprint := sha256.Sum256(encoded)
+
+			key := storage.TenantSessionID(storage.TenantFromContext(ctx), requestID)
+			retained, claimed := results.claim(ctx, key, fingerprint)
+			switch {
+			case claimed:
+			case retained == nil:
+				return apierror.ToolFailure(ctx.Err(), "%s: %v", req.Params.Name, ctx.Err()), nil
+			case retained.fingerprint != fingerprint:
+				return apierror.ToolError(apierror.CodeInvalidParameters, "request_id %s was already used for a different call", requestID), nil
+			default:
+				return retained.result, nil
+			}
+
+			result, err := next(ctx, req)
+			if err != nil || result == nil || result.IsError {
+				results.release(key)
+			} else {
+				results.store(key, result)
+			}
+			return result, err
+		}
+	}
+}
+
+// withRequestID adds the optional request_id parameter to every tool of s
+func withRequestID(s *server.MCPServer) {
+	for _, tool := range s.ListTools() {
+		if tool.Tool.InputSchema.Properties == nil {
+			continue
+		}
+		tool.Tool.InputSchema.Properties[requestIDArgument] = map[string]any{
+			"type":        "string",
+			"description": "Client-chosen ID of this call; retrying with the same ID returns the first call's result instead of running it again",
+		}
+		s.AddTool(tool.Tool, tool.Handler)
+	}
+}
@@ -0,0 +1,1875 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/apierror"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/critic"
+	"github.com/rainmana/gothink/internal/handlers"
+	"github.com/rainmana/gothink/internal/intelligence"
+	"github.com/rainmana/gothink/internal/middleware"
+	"github.com/rainmana/gothink/internal/models"
+	"github.com/rainmana/gothink/internal/progress"
+	"github.com/rainmana/gothink/internal/search"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/telemetry"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/rainmana/gothink/internal/workerpool"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New creates the GoThink MCP server with every tool, resource and prompt
+// registered; opts enable optional features such as the audit log
+func New(cfg *config.Config, store storage.Store, modelsLoader *models.Loader, intelligenceService *intelligence.IntelligenceService, opts ...Option) *server.MCPServer {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Expensive tools run on the worker pool; pooled names them once added
+	pool := workerpool.New(cfg.WorkerPoolSize, cfg.WorkerQueueSize, cfg.ToolTimeout)
+	pooled := make(map[string]bool)
+
+	// Results of calls made with a request_id are retained for their retries
+	var results *resultStore
+	if cfg.ResultRetention > 0 {
+		results = newResultStore(cfg.ResultRetention, cfg.MaxRetainedResults)
+	}
+
+	// Clients subscribe to the resources they want updates of; a session's
+	// subscriptions end with it
+	subs := newSubscriptions()
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		subs.drop(session.SessionID())
+	})
+
+	var s *server.MCPServer
+	s = server.NewMCPServer(
+		"GoThink MCP Server",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(tracingMiddleware),
+		server.WithToolHandlerMiddleware(auditMiddleware(o.audit)),
+		server.WithToolHandlerMiddleware(validationMiddleware(func(name string) *server.ServerTool { return s.GetTool(name) })),
+		server.WithToolHandlerMiddleware(tenantMiddleware),
+		server.WithToolHandlerMiddleware(retentionMiddleware(results)),
+		server.WithToolHandlerMiddleware(rateLimitMiddleware(middleware.NewRateLimiter(cfg.RateLimitPerSecond, cfg.RateLimitBurst))),
+		server.WithToolHandlerMiddleware(workerPoolMiddleware(pool, func(name string) bool { return pooled[name] })),
+	)
+
+	// Add the tools of each enabled feature, as the HTTP API mounts its routes
+	if cfg.EnableSystematicThinking {
+		addThinkingTools(s, store, modelsLoader, cfg)
+	}
+	if cfg.EnableStochasticAlgorithms {
+		markTools(s, pooled, func() { addStochasticTools(s, store) })
+	}
+	addDecisionTools(s, store)
+	if cfg.EnableHybridThinking && cfg.EnableStochasticAlgorithms {
+		markTools(s, pooled, func() { addHybridTools(s, store) })
+	}
+	if cfg.EnableVisualization {
+		addVisualTools(s, store)
+	}
+	addSessionTools(s, store)
+
+	// Run ordered lists of the other tools' calls
+	addBatchTool(s)
+
+	// Expose sessions and the mental model catalog as resources
+	serverSubscriptions.Store(s, subs)
+	addResources(s, store, modelsLoader, cfg)
+	notifyResourceUpdates(s, store, subs)
+
+	// Offer the mental models and debugging approaches as prompts
+	if cfg.EnableSystematicThinking {
+		addPrompts(s, modelsLoader, cfg)
+	}
+
+	// Add critic tools when a critic endpoint is configured
+	if cfg.CriticEndpoint != "" {
+		addCriticTools(s, store, cfg)
+	}
+
+	// Add intelligence tools
+	markTools(s, pooled, func() { addIntelligenceTools(s, intelligenceService) })
+
+	// Apply the per-tool overrides of the configuration
+	if len(cfg.EnabledTools) > 0 {
+		enabled := make(map[string]bool, len(cfg.EnabledTools))
+		for _, name := range cfg.EnabledTools {
+			enabled[name] = true
+		}
+		for name := range s.ListTools() {
+			if !enabled[name] {
+				s.DeleteTools(name)
+			}
+		}
+	}
+	s.DeleteTools(cfg.DisabledTools...)
+
+	// Any tool call may be retried safely under a request_id
+	withRequestID(s)
+
+	return s
+}
+
+func addThinkingTools(s *server.MCPServer, store storage.Store, modelsLoader *models.Loader, cfg *config.Config) {
+	// Sequential Thinking Tool
+	s.AddTool(
+		mcp.NewTool("sequential_thinking",
+			mcp.WithDescription("Perform sequential thinking operations with structured thought progression"),
+			withRequest(api.SequentialThinkingRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			var request api.SequentialThinkingRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			// Create thought data
+			thoughtData := &types.ThoughtData{
+				Thought:           request.Thought,
+				ThoughtNumber:     request.ThoughtNumber,
+				TotalThoughts:     request.TotalThoughts,
+				NextThoughtNeeded: request.NextThoughtNeeded,
+				IsRevision:        request.IsRevision,
+				RevisesThought:    request.RevisesThought,
+				BranchFromThought: request.BranchFromThought,
+				BranchID:          request.BranchID,
+				NeedsMoreThoughts: request.NeedsMoreThoughts,
+			}
+
+			// Store the thought
+			if err := store.AddThought(request.SessionID, thoughtData); err != nil {
+				return apierror.ToolFailure(err, "Failed to add thought: %v", err), nil
+			}
+
+			// Get session stats
+			stats, _ := store.GetSessionStats(request.SessionID)
+
+			// Create response
+			response := api.SequentialThinkingResponse{
+				Status:    "success",
+				ThoughtID: thoughtData.ID,
+				SessionContext: api.ThoughtSessionContext{
+					SessionID:         request.SessionID,
+					TotalThoughts:     stats.ThoughtCount,
+					RemainingThoughts: stats.RemainingThoughts,
+				},
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Mental Model Tool
+	s.AddTool(
+		mcp.NewTool("mental_model",
+			mcp.WithDescription("Apply mental models to solve problems using structured thinking frameworks"),
+			withRequest(api.MentalModelRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			var request api.MentalModelRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			// Load available mental models
+			availableModels, err := modelsLoader.LoadMentalModels(cfg.MentalModelsPath)
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to load mental models: %v", err), nil
+			}
+
+			// Check if the requested model exists
+			model, exists := availableModels[request.ModelName]
+			if !exists {
+				// Return available models for reference
+				available := modelsLoader.GetAvailableModels(availableModels)
+				return apierror.ToolError(apierror.CodeModelNotFound, "Mental model '%s' not found. Available models: %v", request.ModelName, available), nil
+			}
+
+			// Use model steps if no custom steps provided
+			steps := request.Steps
+			if len(steps) == 0 {
+				steps = model.Steps
+			}
+
+			// Create mental model data
+			modelData := &types.MentalModelData{
+				ModelName:  request.ModelName,
+				Problem:    request.Problem,
+				Steps:      steps,
+				Reasoning:  request.Reasoning,
+				Conclusion: request.Conclusion,
+				Confidence: request.Confidence,
+			}
+
+			// Store the mental model
+			if err := store.AddMentalModel(request.SessionID, modelData); err != nil {
+				return apierror.ToolFailure(err, "Failed to add mental model: %v", err), nil
+			}
+
+			// Get session stats
+			stats, _ := store.GetSessionStats(request.SessionID)
+
+			// Create response
+			response := api.MentalModelResponse{
+				Status:  "success",
+				ModelID: modelData.ID,
+				ModelInfo: &api.MentalModelInfo{
+					Name:        model.Name,
+					Description: model.Description,
+					Category:    model.Category,
+					Priority:    model.Priority,
+				},
+				StepsUsed:     steps,
+				HasSteps:      len(steps) > 0,
+				HasConclusion: request.Conclusion != "",
+				SessionContext: api.MentalModelSessionContext{
+					SessionID:         request.SessionID,
+					TotalMentalModels: stats.Stores["mental_models"].(map[string]int)["count"],
+				},
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Debugging Approach Tool
+	s.AddTool(
+		mcp.NewTool("debugging_approach",
+			mcp.WithDescription("Apply systematic debugging approaches to identify and resolve issues"),
+			withRequest(api.DebuggingApproachRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			var request api.DebuggingApproachRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			// Debugging approaches are stored as mental models so findings can be recorded later
+			approach := &types.MentalModelData{
+				ModelName:  types.DebuggingModelPrefix + request.ApproachName,
+				Problem:    request.Issue,
+				Steps:      request.Steps,
+				Reasoning:  request.Findings,
+				Conclusion: request.Resolution,
+			}
+			if err := store.AddMentalModel(request.SessionID, approach); err != nil {
+				return apierror.ToolFailure(err, "Failed to add debugging approach: %v", err), nil
+			}
+
+			// Create response
+			response := api.DebuggingApproachResponse{
+				Status:         "success",
+				ApproachID:     approach.ID,
+				HasSteps:       len(request.Steps) > 0,
+				HasFindings:    request.Findings != "",
+				HasResolution:  request.Resolution != "",
+				SessionContext: api.SessionContext{SessionID: request.SessionID},
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Update Thought Tool
+	s.AddTool(
+		mcp.NewTool("update_thought",
+			mcp.WithDescription("Revise the content of a previously recorded thought"),
+			withRequest(api.UpdateThoughtRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			var request api.UpdateThoughtRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			err := store.UpdateThought(request.SessionID, request.ThoughtID, func(thought *types.ThoughtData) error {
+				thought.Thought = request.Thought
+				if request.NextThoughtNeeded != nil {
+					thought.NextThoughtNeeded = *request.NextThoughtNeeded
+				}
+				return nil
+			})
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to update thought: %v", err), nil
+			}
+
+			// Create response
+			response := api.UpdateThoughtResponse{
+				Status:         "success",
+				ThoughtID:      request.ThoughtID,
+				SessionContext: api.SessionContext{SessionID: request.SessionID},
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Update Mental Model Conclusion Tool
+	s.AddTool(
+		mcp.NewTool("update_mental_model_conclusion",
+			mcp.WithDescription("Attach a conclusion, and optionally reasoning and confidence, to a previously applied mental model"),
+			withRequest(api.MentalModelConclusionRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			var request api.MentalModelConclusionRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			var updated types.MentalModelData
+			err := store.UpdateMentalModel(request.SessionID, request.ModelID, func(model *types.MentalModelData) error {
+				model.Conclusion = request.Conclusion
+				if request.Reasoning != nil {
+					model.Reasoning = *request.Reasoning
+				}
+				if request.Confidence != nil {
+					model.Confidence = *request.Confidence
+				}
+				updated = *model
+				return nil
+			})
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to update mental model: %v", err), nil
+			}
+
+			// Create response
+			response := api.MentalModelConclusionResponse{
+				Status:         "success",
+				ModelID:        request.ModelID,
+				ModelName:      updated.ModelName,
+				HasReasoning:   updated.Reasoning != "",
+				HasConclusion:  true,
+				Confidence:     updated.Confidence,
+				SessionContext: api.SessionContext{SessionID: request.SessionID},
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Record Debugging Findings Tool
+	s.AddTool(
+		mcp.NewTool("record_debugging_findings",
+			mcp.WithDescription("Record findings and, once known, the resolution of a debugging approach"),
+			withRequest(api.DebuggingFindingsRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			var request api.DebuggingFindingsRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+			if request.Findings == "" && request.Resolution == "" {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "findings or resolution is required"), nil
+			}
+
+			var updated types.MentalModelData
+			err := store.UpdateMentalModel(request.SessionID, request.ApproachID, func(approach *types.MentalModelData) error {
+				if !approach.IsDebuggingApproach() {
+					return apierror.Errorf(apierror.CodeInvalidParameters, "record %s is not a debugging approach", request.ApproachID)
+				}
+				if request.Findings != "" {
+					approach.Reasoning = request.Findings
+				}
+				if request.Resolution != "" {
+					approach.Conclusion = request.Resolution
+				}
+				updated = *approach
+				return nil
+			})
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to record findings: %v", err), nil
+			}
+
+			// Create response
+			response := api.DebuggingFindingsResponse{
+				Status:         "success",
+				ApproachID:     request.ApproachID,
+				ApproachName:   strings.TrimPrefix(updated.ModelName, types.DebuggingModelPrefix),
+				HasFindings:    updated.Reasoning != "",
+				HasResolution:  updated.Conclusion != "",
+				SessionContext: api.SessionContext{SessionID: request.SessionID},
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// List Available Mental Models Tool
+	s.AddTool(
+		mcp.NewTool("list_mental_models",
+			mcp.WithDescription("List all available mental models with their details"),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// Load available mental models
+			availableModels, err := modelsLoader.LoadMentalModels(cfg.MentalModelsPath)
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to load mental models: %v", err), nil
+			}
+
+			// Get models sorted by priority
+			modelsByPriority := modelsLoader.GetModelsByPriority(availableModels)
+			modelsByCategory := modelsLoader.GetModelsByCategory(availableModels)
+
+			// Create response
+			response := map[string]interface{}{
+				"status":             "success",
+				"total_models":       len(availableModels),
+				"models_by_priority": modelsByPriority,
+				"models_by_category": modelsByCategory,
+				"available_models":   modelsLoader.GetAvailableModels(availableModels),
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+func addStochasticTools(s *server.MCPServer, store storage.Store) {
+	stochastic := handlers.NewStochasticHandler(store, logrus.StandardLogger())
+
+	// Registered algorithms, each run by its handler
+	for _, algorithm := range stochastic.Algorithms() {
+		s.AddTool(
+			mcp.NewTool(algorithm.Tool(),
+				mcp.WithDescription(algorithm.Description()),
+				withRequest(algorithm.Request()),
+				withTenant(),
+			),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				params, err := json.Marshal(req.GetArguments())
+				if err != nil {
+					return apierror.ToolError(apierror.CodeInvalidParameters, "Invalid arguments for %s: %v", req.Params.Name, err), nil
+				}
+
+				response, err := algorithm.Run(ctx, params, streamProgress(ctx, req))
+				if err != nil {
+					return apierror.ToolFailure(err, "%v", err), nil
+				}
+
+				result, _ := json.Marshal(response)
+				return mcp.NewToolResultText(string(result)), nil
+			},
+		)
+	}
+
+	// Algorithm Listing Tool
+	s.AddTool(
+		mcp.NewTool("list_algorithms",
+			mcp.WithDescription("List the stochastic algorithms this server runs, with the tool and route of each and the JSON schema of its parameters"),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, _ := json.Marshal(stochastic.ListStochasticAlgorithms())
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("compare_stochastic_runs",
+			mcp.WithDescription("Compare stochastic algorithm runs recorded in a session by their best values, convergence speed and confidence, recording the comparison as a table and returning it rendered as Markdown"),
+			withRequest(api.CompareRunsRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.CompareRunsRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := stochastic.RunCompareRuns(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("parameter_sweep",
+			mcp.WithDescription("Run a stochastic algorithm across a grid or random sample of its hyperparameters, such as gamma, epsilon or the exploration constant, in parallel, and report the best configuration with the result of every configuration"),
+			withRequest(api.ParameterSweepRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.ParameterSweepRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := stochastic.RunParameterSweep(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("stochastic_sensitivity",
+			mcp.WithDescription("Re-run a recorded stochastic algorithm run with each of the given parameters perturbed in turn, keeping its seed, and report how far each moves its value or a response metric, largest swing first, with a tornado chart rendered as Markdown"),
+			withRequest(api.SensitivityRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.SensitivityRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := stochastic.RunSensitivityAnalysis(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("stochastic_dominance",
+			mcp.WithDescription("Compare two distributions of outcomes, the trial outputs of recorded Monte Carlo simulations or given samples, by first- and second-order stochastic dominance and by their value at risk and CVaR, optionally feeding the means, risk levels and verdict into a recorded decision"),
+			withRequest(api.DominanceRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.DominanceRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := stochastic.RunStochasticDominance(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("get_stochastic_result",
+			mcp.WithDescription("Retrieve a stochastic algorithm run recorded in a session with its full typed data, such as an MDP's policy and Q-values, a tree search's move statistics or a bandit's arm statistics and regret curve"),
+			withRequest(api.StochasticResultRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.StochasticResultRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := stochastic.RunGetResult(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+// streamProgress returns the progress function of a streamed run, sending
+// each best-so-far result as a progress notification with the result as its
+// JSON message, to clients that ask for progress
+func streamProgress(ctx context.Context, req mcp.CallToolRequest) handlers.ProgressFunc {
+	reporter := progress.FromRequest(ctx, req, 0)
+	return func(update api.StreamUpdate) {
+		message, _ := json.Marshal(update)
+		reporter.SetTotal(float64(update.Total))
+		reporter.Report(float64(update.Iteration), string(message))
+	}
+}
+
+func addDecisionTools(s *server.MCPServer, store storage.Store) {
+	// Decision Framework Tool
+	decision := handlers.NewDecisionHandler(store, logrus.StandardLogger())
+	s.AddTool(
+		mcp.NewTool("decision_framework",
+			mcp.WithDescription("Apply decision frameworks for structured decision making; given a decision_id, revise that decision as its next iteration"),
+			withRequest(api.DecisionFrameworkRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.DecisionFrameworkRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+			if request.AnalysisType == "" {
+				request.AnalysisType = "multi-criteria"
+			}
+			if request.Stage == "" {
+				request.Stage = "evaluation"
+			}
+
+			response, err := decision.RecordDecision(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Decision History Tool
+	s.AddTool(
+		mcp.NewTool("decision_history",
+			mcp.WithDescription("Review how a recorded decision evolved: each iteration's options, criteria, scores, ranking and recommendation, oldest first, with what changed since the one before"),
+			withRequest(api.DecisionHistoryRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.DecisionHistoryRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunDecisionHistory(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Multi-Criteria Analysis Tool
+	s.AddTool(
+		mcp.NewTool("multi_criteria_analysis",
+			mcp.WithDescription("Rank the options of a recorded decision by a weighted sum or weighted product of their normalized scores on its criteria, storing the ranking and a recommendation on the decision"),
+			withRequest(api.MultiCriteriaRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.MultiCriteriaRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunMultiCriteria(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Option Scoring Tool
+	s.AddTool(
+		mcp.NewTool("score_decision_option",
+			mcp.WithDescription("Record the score of an option of a recorded decision on one of its criteria, as a number or a qualitative rating from very_poor to excellent, with its rationale, building up the decision matrix the analyses rank the options by; once every option is scored on every criterion the options are ranked"),
+			withRequest(api.ScoreDecisionOptionRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.ScoreDecisionOptionRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunScoreOption(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Analytic Hierarchy Process Tool
+	s.AddTool(
+		mcp.NewTool("ahp_analysis",
+			mcp.WithDescription("Derive the criteria weights of a recorded decision, and optionally its options' scores, from pairwise comparison matrices by the Analytic Hierarchy Process, with priority vectors and consistency ratios, storing them on the decision and ranking its options"),
+			withRequest(api.AHPRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.AHPRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunAHP(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Risk Analysis Tool
+	s.AddTool(
+		mcp.NewTool("risk_analysis",
+			mcp.WithDescription("Assess the risk register of a recorded decision: place each risk on a probability-impact matrix before and after its mitigations, and compute each option's expected loss and risk-adjusted value, storing the assessment on the decision"),
+			withRequest(api.RiskAnalysisRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.RiskAnalysisRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunRiskAnalysis(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Decision Tree Analysis Tool
+	s.AddTool(
+		mcp.NewTool("decision_tree_analysis",
+			mcp.WithDescription("Evaluate a tree of decision, chance and terminal nodes with probabilities and payoffs by expected-value rollback, returning the optimal strategy, its risk profile and the evaluated tree, recorded as a decision-tree diagram"),
+			withRequest(api.DecisionTreeRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.DecisionTreeRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunDecisionTree(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Stakeholder Analysis Tool
+	s.AddTool(
+		mcp.NewTool("stakeholder_analysis",
+			mcp.WithDescription("Analyze the stakeholders of a recorded decision by their influence, interest and support for each option: place them on an influence/interest grid and score the options by stakeholder-weighted support, storing the analysis on the decision"),
+			withRequest(api.StakeholderAnalysisRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.StakeholderAnalysisRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunStakeholderAnalysis(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Group Decision Tool
+	s.AddTool(
+		mcp.NewTool("group_decision",
+			mcp.WithDescription("Record several evaluators' scores of a recorded decision's options and aggregate them by Borda count, Condorcet pairwise majorities and range voting, with a disagreement metric and the evaluators whose rankings conflict most, storing the aggregation on the decision"),
+			withRequest(api.GroupDecisionRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.GroupDecisionRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunGroupDecision(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Cost-Benefit Analysis Tool
+	s.AddTool(
+		mcp.NewTool("cost_benefit_analysis",
+			mcp.WithDescription("Appraise the options of a recorded decision by their time-phased costs and benefits at a discount rate: net present value, approximate internal rate of return, payback periods and benefit-cost ratio, storing the analysis on the decision"),
+			withRequest(api.CostBenefitRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.CostBenefitRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunCostBenefit(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Scenario Analysis Tool
+	s.AddTool(
+		mcp.NewTool("scenario_analysis",
+			mcp.WithDescription("Evaluate options across named future scenarios with probabilities, from the outcome of every option in every scenario: expected value, worst case and regret, separating robust options, good in every scenario, from bets that win in some scenarios and do poorly in others, and record the analysis in the session"),
+			withRequest(api.ScenarioAnalysisRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.ScenarioAnalysisRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunScenarioAnalysis(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Decision Comparison Tool
+	s.AddTool(
+		mcp.NewTool("compare_decisions",
+			mcp.WithDescription("Compare recorded decisions, possibly from different sessions, side by side: their statements, options, scores, rankings and recommendations, with the options and criteria they share, for reviewing recurring decisions"),
+			withRequest(api.CompareDecisionsRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.CompareDecisionsRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunCompareDecisions(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Kepner-Tregoe Analysis Tool
+	s.AddTool(
+		mcp.NewTool("kepner_tregoe",
+			mcp.WithDescription("Analyze the options of a recorded decision by Kepner-Tregoe decision analysis: eliminate those failing a MUST objective, score the rest against weighted WANT objectives, weigh their adverse consequences by probability and seriousness and name the best balanced choice, storing the analysis on the decision"),
+			withRequest(api.KepnerTregoeRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.KepnerTregoeRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunKepnerTregoe(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Decision Outcome Tool
+	s.AddTool(
+		mcp.NewTool("record_decision_outcome",
+			mcp.WithDescription("Record what came of a recorded decision: the option taken, whether it succeeded and the value it realized, alongside the probability of success and value forecast for it"),
+			withRequest(api.RecordDecisionOutcomeRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.RecordDecisionOutcomeRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunRecordDecisionOutcome(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Calibration Report Tool
+	s.AddTool(
+		mcp.NewTool("calibration_report",
+			mcp.WithDescription("Compare the forecast probabilities and values of decisions with their recorded outcomes, across a session or every session of the tenant, by Brier score, a reliability table and the mean forecast error"),
+			withRequest(api.CalibrationReportRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.CalibrationReportRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunCalibrationReport(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Decision Simulation Tool
+	s.AddTool(
+		mcp.NewTool("simulate_decision",
+			mcp.WithDescription("Simulate the outcomes of a recorded decision's options, each drawn from its outcome distribution, over many futures, returning each option's probability of being best, expected value, 5th to 95th percentiles and CVaR, storing the simulation on the decision"),
+			withRequest(api.SimulateDecisionRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.SimulateDecisionRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunDecisionSimulation(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+func addHybridTools(s *server.MCPServer, store storage.Store) {
+	// Hybrid Probabilistic Decision Tool
+	decision := handlers.NewDecisionHandler(store, logrus.StandardLogger())
+	s.AddTool(
+		mcp.NewTool("hybrid_probabilistic_decision",
+			mcp.WithDescription("Run a stochastic algorithm on the options of a recorded decision, framed as the arms of a multi-armed bandit or the actions of an MDP, returning the run with the options ranked by its estimates and a recommendation stored on the decision"),
+			withRequest(api.HybridDecisionRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.HybridDecisionRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := decision.RunHybridProbabilisticDecision(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+func addVisualTools(s *server.MCPServer, store storage.Store) {
+	visual := handlers.NewVisualHandler(store, logrus.StandardLogger())
+
+	// Concept Map Tool
+	s.AddTool(
+		mcp.NewTool("concept_map",
+			mcp.WithDescription("Create and manipulate concept maps for visual thinking: each operation applies to the diagram's current state, and the response holds the resulting diagram and what changed"),
+			withRequest(api.ConceptMapRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.ConceptMapRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := visual.RunConceptMap(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Flowchart Tool
+	s.AddTool(
+		mcp.NewTool("flowchart",
+			mcp.WithDescription("Record a flowchart of typed start, end, process, decision and io nodes, checking that only decisions branch and that each branch is labeled, and return it rendered as Mermaid, Graphviz DOT and a PlantUML activity diagram"),
+			withRequest(api.FlowchartRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.FlowchartRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := visual.RunFlowchart(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Decision Tree Diagram Tool
+	s.AddTool(
+		mcp.NewTool("decision_tree_diagram",
+			mcp.WithDescription("Seed a decision tree diagram from a recorded decision, its options branching from it and their criteria from each option, or edit it, keeping the decision and its diagram linked to each other"),
+			withRequest(api.DecisionTreeDiagramRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.DecisionTreeDiagramRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := visual.RunDecisionTreeDiagram(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("probability_tree",
+			mcp.WithDescription("Record a probability tree, checking that the probabilities of the branches from each node sum to 1, and write each outcome's joint probability, payoff and contribution to the expected value back onto its leaf"),
+			withRequest(api.ProbabilityTreeRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.ProbabilityTreeRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := visual.RunProbabilityTree(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("map_session",
+			mcp.WithDescription("Draft a starter concept map of a session: its thoughts, mental models and decisions, the keywords recurring across them, and the links between them"),
+			withRequest(api.MapSessionRequest{}),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var request api.MapSessionRequest
+			if invalid := bindRequest(req, &request); invalid != nil {
+				return invalid, nil
+			}
+
+			response, err := visual.RunMapSession(ctx, request)
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+// defaultSessionSizes is the number of sessions storage_stats lists by default
+const defaultSessionSizes = 20
+
+func addSessionTools(s *server.MCPServer, store storage.Store) {
+	// Session Stats Tool
+	s.AddTool(
+		mcp.NewTool("session_stats",
+			mcp.WithDescription("Get statistics for a session"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+
+			// Get session stats
+			stats, err := store.GetSessionStats(sessionID)
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to get session stats: %v", err), nil
+			}
+
+			// Create response
+			response := map[string]interface{}{
+				"session_id":         sessionID,
+				"created_at":         stats.CreatedAt.Format(time.RFC3339),
+				"last_accessed_at":   stats.LastAccessedAt.Format(time.RFC3339),
+				"thought_count":      stats.ThoughtCount,
+				"tools_used":         stats.ToolsUsed,
+				"tool_counts":        stats.ToolCounts,
+				"total_operations":   stats.TotalOperations,
+				"is_active":          stats.IsActive,
+				"is_archived":        stats.IsArchived,
+				"remaining_thoughts": stats.RemainingThoughts,
+				"stores":             stats.Stores,
+			}
+			if stats.StoragePressure != nil {
+				response["storage_pressure"] = stats.StoragePressure
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Storage Stats Tool
+	s.AddTool(
+		mcp.NewTool("storage_stats",
+			mcp.WithDescription("Report storage usage for capacity planning: record counts per store, the largest sessions, estimated memory footprint and eviction counters"),
+			withTenant(),
+			mcp.WithNumber("limit", integer(), mcp.Min(0), mcp.Description(fmt.Sprintf("Maximum number of sessions listed by size (default: %d)", defaultSessionSizes))),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+
+			stats, err := store.StorageStats()
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to get storage stats: %v", err), nil
+			}
+
+			if limit := req.GetInt("limit", defaultSessionSizes); limit >= 0 && limit < len(stats.SessionSizes) {
+				stats.SessionSizes = stats.SessionSizes[:limit]
+			}
+
+			result, _ := json.Marshal(stats)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Export Tool
+	s.AddTool(
+		mcp.NewTool("session_export",
+			mcp.WithDescription("Export all data for a session as JSON (importable with session_import), a Markdown report, CSV with one file per store, or its diagrams as Graphviz DOT, Excalidraw or draw.io files"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+			mcp.WithString("format", mcp.Description("Export format (default: json)"), mcp.Enum(storage.ExportFormats()...)),
+			mcp.WithBoolean("compress", mcp.Description("Return the export gzipped and base64 encoded")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+			format := req.GetString("format", storage.FormatJSON)
+
+			payload, err := renderSessionExport(store, sessionID, format)
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to export session: %v", err), nil
+			}
+			if !req.GetBool("compress", false) {
+				return mcp.NewToolResultText(payload), nil
+			}
+
+			compressed, err := storage.CompressExport([]byte(payload))
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to export session: %v", err), nil
+			}
+
+			response := map[string]interface{}{
+				"session_id": sessionID,
+				"format":     format,
+				"encoding":   storage.EncodingGzipBase64,
+				"size":       len(payload),
+				"data":       compressed,
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Export Chunk Tool
+	chunker := storage.NewExportChunker(10 * time.Minute)
+	s.AddTool(
+		mcp.NewTool("session_export_chunk",
+			mcp.WithDescription("Read a large session export in chunks. Call without a cursor to start an export, then pass next_cursor until done is true and concatenate the data fields."),
+			mcp.WithString("session_id", mcp.Description("Session identifier (required to start an export)")),
+			withTenant(),
+			mcp.WithString("cursor", mcp.Description("next_cursor from the previous chunk")),
+			mcp.WithString("format", mcp.Description("Export format (default: json)"), mcp.Enum(storage.ExportFormats()...)),
+			mcp.WithBoolean("compress", mcp.Description("Gzip and base64 encode the export before chunking")),
+			mcp.WithNumber("chunk_size", integer(), mcp.Min(1), mcp.Description(fmt.Sprintf("Maximum bytes per chunk (default: %d, max: %d)", storage.DefaultChunkSize, storage.MaxChunkSize))),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			cursor := req.GetString("cursor", "")
+			if cursor == "" {
+				sessionID := req.GetString("session_id", "")
+				if sessionID == "" {
+					return apierror.ToolError(apierror.CodeInvalidParameters, "session_id is required to start an export"), nil
+				}
+				format := req.GetString("format", storage.FormatJSON)
+
+				payload, err := renderSessionExport(store, sessionID, format)
+				if err != nil {
+					return apierror.ToolFailure(err, "Failed to export session: %v", err), nil
+				}
+
+				encoding := storage.EncodingIdentity
+				if req.GetBool("compress", false) {
+					if payload, err = storage.CompressExport([]byte(payload)); err != nil {
+						return apierror.ToolFailure(err, "Failed to export session: %v", err), nil
+					}
+					encoding = storage.EncodingGzipBase64
+				}
+
+				cursor = chunker.Begin(storage.TenantFromContext(ctx), sessionID, format, encoding, payload)
+			}
+
+			chunk, err := chunker.Next(storage.TenantFromContext(ctx), cursor, req.GetInt("chunk_size", storage.DefaultChunkSize))
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+
+			result, _ := json.Marshal(chunk)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Clear Tool
+	s.AddTool(
+		mcp.NewTool("session_clear",
+			mcp.WithDescription("Delete a session and all of its thoughts, mental models, algorithms, decisions, visuals and critiques"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+
+			if err := store.ClearSession(sessionID); err != nil {
+				return apierror.ToolFailure(err, "Failed to clear session: %v", err), nil
+			}
+
+			response := map[string]interface{}{
+				"session_id": sessionID,
+				"status":     "cleared",
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Archive Tools
+	s.AddTool(
+		mcp.NewTool("archive_session",
+			mcp.WithDescription("Archive a session: its records stay retrievable but it accepts no new records and never expires"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+
+			if err := store.ArchiveSession(sessionID); err != nil {
+				return apierror.ToolFailure(err, "Failed to archive session: %v", err), nil
+			}
+
+			response := map[string]interface{}{
+				"session_id": sessionID,
+				"status":     "archived",
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	s.AddTool(
+		mcp.NewTool("restore_session",
+			mcp.WithDescription("Restore an archived session so it accepts new records again"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+
+			if err := store.RestoreSession(sessionID); err != nil {
+				return apierror.ToolFailure(err, "Failed to restore session: %v", err), nil
+			}
+
+			response := map[string]interface{}{
+				"session_id": sessionID,
+				"status":     "active",
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Records Tool
+	s.AddTool(
+		mcp.NewTool("session_records",
+			mcp.WithDescription("List a session's records of one type with pagination, time range filtering and sorting"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+			mcp.WithString("record_type", mcp.Required(), mcp.Description("Record type to list"), mcp.Enum(storage.RecordKinds()...)),
+			mcp.WithNumber("limit", integer(), mcp.Min(0), mcp.Description("Maximum number of records to return (default: all)")),
+			mcp.WithNumber("offset", integer(), mcp.Min(0), mcp.Description("Number of records to skip")),
+			mcp.WithString("since", mcp.Description("Only records created at or after this RFC 3339 time")),
+			mcp.WithString("until", mcp.Description("Only records created at or before this RFC 3339 time")),
+			mcp.WithString("order", mcp.Description("Sort order by creation time"), mcp.Enum(storage.SortAscending, storage.SortDescending)),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+			recordType, _ := req.RequireString("record_type")
+
+			query := &storage.Query{
+				Limit:  req.GetInt("limit", 0),
+				Offset: req.GetInt("offset", 0),
+				Order:  req.GetString("order", ""),
+			}
+			for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+				if raw := req.GetString(name, ""); raw != "" {
+					t, err := time.Parse(time.RFC3339, raw)
+					if err != nil {
+						return apierror.ToolError(apierror.CodeInvalidParameters, "Invalid %s: expected RFC 3339 time", name), nil
+					}
+					*target = t
+				}
+			}
+
+			page, err := storage.QueryRecords(store, sessionID, recordType, query)
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to list records: %v", err), nil
+			}
+
+			result, _ := json.Marshal(page)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Search Tool
+	s.AddTool(
+		mcp.NewTool("search_session",
+			mcp.WithDescription("Full-text search over a session's thoughts, mental model conclusions and decision statements, returning ranked hits"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+			mcp.WithString("query", mcp.Required(), mcp.Description("Search text")),
+			mcp.WithNumber("limit", integer(), mcp.Min(0), mcp.Description(fmt.Sprintf("Maximum number of hits (default: %d)", search.DefaultLimit))),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+			query, _ := req.RequireString("query")
+
+			hits, err := search.Session(store, sessionID, query, req.GetInt("limit", 0))
+			if err != nil {
+				return apierror.ToolFailure(err, "Search failed: %v", err), nil
+			}
+
+			response := map[string]interface{}{
+				"session_id": sessionID,
+				"query":      query,
+				"hits":       hits,
+				"count":      len(hits),
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Import Tool
+	s.AddTool(
+		mcp.NewTool("session_import",
+			mcp.WithDescription("Restore a session from the JSON produced by session_export. Records receive new IDs."),
+			mcp.WithString("export", mcp.Required(), mcp.Description("Session export JSON")),
+			mcp.WithString("session_id", mcp.Description("Session to restore into (defaults to the exported session ID)")),
+			withTenant(),
+			mcp.WithString("encoding", mcp.Description("Encoding of the export (default: identity)"), mcp.Enum(storage.EncodingIdentity, storage.EncodingGzipBase64)),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			payload, err := req.RequireString("export")
+			if err != nil {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "%v", err), nil
+			}
+
+			content := []byte(payload)
+			if req.GetString("encoding", storage.EncodingIdentity) == storage.EncodingGzipBase64 {
+				if content, err = storage.DecompressExport(payload); err != nil {
+					return apierror.ToolError(apierror.CodeInvalidParameters, "%v", err), nil
+				}
+			}
+
+			exportData, err := storage.DecodeSessionExport(content)
+			if err != nil {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "%v", err), nil
+			}
+
+			imported, err := storage.ImportSession(store, exportData, getString(req.GetArguments(), "session_id"))
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to import session: %v", err), nil
+			}
+
+			result, _ := json.Marshal(imported)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Fork Tool
+	s.AddTool(
+		mcp.NewTool("session_fork",
+			mcp.WithDescription("Copy a session into a new session to explore an alternative line of reasoning without changing the original. Copies receive new IDs."),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session to fork")),
+			mcp.WithString("new_session_id", mcp.Required(), mcp.Description("ID of the new session, which must not exist yet")),
+			withTenant(),
+			mcp.WithNumber("up_to_thought", integer(), mcp.Min(0), mcp.Description("Fork the session as it stood before any thought numbered beyond this was recorded (default: copy everything)")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, err := req.RequireString("session_id")
+			if err != nil {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "%v", err), nil
+			}
+
+			targetID, err := req.RequireString("new_session_id")
+			if err != nil {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "%v", err), nil
+			}
+
+			forked, err := storage.ForkSession(store, sessionID, targetID, req.GetInt("up_to_thought", 0))
+			if err != nil {
+				return apierror.ToolFailure(err, "Failed to fork session: %v", err), nil
+			}
+
+			result, _ := json.Marshal(forked)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+
+	// Session Bulk Import Tool
+	records := mcp.Items(map[string]any{"type": "object"})
+	s.AddTool(
+		mcp.NewTool("session_bulk_import",
+			mcp.WithDescription("Add many records to a session in one call, for example to reconstruct a prior session. Records keep any IDs given; critiques are added last so they can reference other records of the call."),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+			mcp.WithArray("thoughts", mcp.Description("Thoughts, in the form returned by session_export"), records),
+			mcp.WithArray("mental_models", mcp.Description("Mental model applications"), records),
+			mcp.WithArray("stochastic_algorithms", mcp.Description("Stochastic algorithm results"), records),
+			mcp.WithArray("decisions", mcp.Description("Decisions"), records),
+			mcp.WithArray("visual_data", mcp.Description("Visual thinking operations"), records),
+			mcp.WithArray("critiques", mcp.Description("Critiques"), records),
+			mcp.WithArray("scenarios", mcp.Description("Scenario analyses"), records),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, err := req.RequireString("session_id")
+			if err != nil {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "%v", err), nil
+			}
+
+			// The record arrays share their names with the batch fields
+			raw, err := json.Marshal(req.GetArguments())
+			if err != nil {
+				return apierror.ToolFailure(err, "%v", err), nil
+			}
+			var batch storage.Batch
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "Invalid records: %v", err), nil
+			}
+			if batch.Len() == 0 {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "No records given"), nil
+			}
+
+			added, err := store.AddBatch(sessionID, &batch)
+			if err != nil {
+				if added != nil {
+					result, _ := json.Marshal(added)
+					return apierror.ToolFailure(err, "Failed to import records: %v (added before the failure: %s)", err, result), nil
+				}
+				return apierror.ToolFailure(err, "Failed to import records: %v", err), nil
+			}
+
+			result, _ := json.Marshal(added)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+func addCriticTools(s *server.MCPServer, store storage.Store, cfg *config.Config) {
+	criticClient := critic.NewClient(cfg.CriticEndpoint, cfg.CriticModel, cfg.CriticAPIKey, cfg.CriticTimeout)
+
+	// Critique Reasoning Tool
+	s.AddTool(
+		mcp.NewTool("critique_reasoning",
+			mcp.WithDescription("Send session reasoning to an external LLM critic for review of logical gaps and missing alternatives"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Session identifier")),
+			withTenant(),
+			mcp.WithArray("include", mcp.Description("Record types to review (thoughts, mental_models, decisions); defaults to all"),
+				mcp.WithStringItems(mcp.Enum(storage.KindThoughts, storage.KindMentalModels, storage.KindDecisions))),
+			mcp.WithArray("record_ids", mcp.Description("Specific record IDs to review; defaults to every record of the included types"), mcp.WithStringItems()),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store := tenantStore(ctx, store)
+			sessionID, _ := req.RequireString("session_id")
+			include := req.GetStringSlice("include", []string{"thoughts", "mental_models", "decisions"})
+			recordIDs := req.GetStringSlice("record_ids", []string{})
+
+			selected := make(map[string]bool)
+			for _, id := range recordIDs {
+				selected[id] = true
+			}
+			wanted := func(id string) bool {
+				return len(selected) == 0 || selected[id]
+			}
+
+			// Collect the selected session content
+			var content strings.Builder
+			var targetIDs []string
+			for _, recordType := range include {
+				switch recordType {
+				case "thoughts":
+					thoughts, _ := store.GetThoughts(sessionID, nil)
+					for _, thought := range thoughts {
+						if wanted(thought.ID) {
+							fmt.Fprintf(&content, "Thought %d/%d: %s\n", thought.ThoughtNumber, thought.TotalThoughts, thought.Thought)
+							targetIDs = append(targetIDs, thought.ID)
+						}
+					}
+				case "mental_models":
+					mentalModels, _ := store.GetMentalModels(sessionID, nil)
+					for _, model := range mentalModels {
+						if wanted(model.ID) {
+							fmt.Fprintf(&content, "Mental model %s applied to: %s\nSteps: %s\nReasoning: %s\nConclusion: %s\n",
+								model.ModelName, model.Problem, strings.Join(model.Steps, "; "), model.Reasoning, model.Conclusion)
+							targetIDs = append(targetIDs, model.ID)
+						}
+					}
+				case "decisions":
+					decisions, _ := store.GetDecisions(sessionID, nil)
+					for _, decision := range decisions {
+						if wanted(decision.ID) {
+							var optionNames []string
+							for _, option := range decision.Options {
+								optionNames = append(optionNames, option.Name)
+							}
+							fmt.Fprintf(&content, "Decision: %s\nOptions: %s\nRecommendation: %s\n",
+								decision.DecisionStatement, strings.Join(optionNames, ", "), decision.Recommendation)
+							targetIDs = append(targetIDs, decision.ID)
+						}
+					}
+				default:
+					return apierror.ToolError(apierror.CodeInvalidParameters, "Unknown record type '%s'. Supported types: thoughts, mental_models, decisions", recordType), nil
+				}
+			}
+
+			if err := ctx.Err(); err != nil {
+				return apierror.ToolFailure(err, "Critique cancelled: %v", err), nil
+			}
+			if len(targetIDs) == 0 {
+				return apierror.ToolError(apierror.CodeRecordNotFound, "No session content matched the requested records"), nil
+			}
+
+			// Request the critique
+			review, err := criticClient.Critique(ctx, content.String())
+			if err != nil {
+				return apierror.ToolError(apierror.CodeOr(err, apierror.CodeUpstreamError), "Failed to critique reasoning: %v", err), nil
+			}
+
+			// Store the critique linked to the reviewed records
+			critiqueData := &types.CritiqueData{
+				TargetType:          strings.Join(include, ","),
+				TargetIDs:           targetIDs,
+				Model:               criticClient.Model(),
+				Summary:             review.Summary,
+				LogicalGaps:         review.LogicalGaps,
+				MissingAlternatives: review.MissingAlternatives,
+				RawCritique:         review.Raw,
+			}
+			if err := store.AddCritique(sessionID, critiqueData); err != nil {
+				return apierror.ToolFailure(err, "Failed to add critique: %v", err), nil
+			}
+
+			// Create response
+			response := map[string]interface{}{
+				"status":               "success",
+				"critique_id":          critiqueData.ID,
+				"target_ids":           targetIDs,
+				"summary":              review.Summary,
+				"logical_gaps":         review.LogicalGaps,
+				"missing_alternatives": review.MissingAlternatives,
+			}
+
+			result, _ := json.Marshal(response)
+			return mcp.NewToolResultText(string(result)), nil
+		},
+	)
+}
+
+// Helper functions
+
+// withRequest declares the fields of the api request type of v as the
+// parameters of a tool, with their JSON schemas
+func withRequest(v interface{}) mcp.ToolOption {
+	properties, required := api.Properties(v)
+	return func(tool *mcp.Tool) {
+		for name, property := range properties {
+			tool.InputSchema.Properties[name] = property
+		}
+		tool.InputSchema.Required = append(tool.InputSchema.Required, required...)
+	}
+}
+
+// describeArgument replaces the description of a declared parameter, for
+// tools that share a request type
+func describeArgument(name, description string) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		if property, ok := tool.InputSchema.Properties[name].(map[string]any); ok {
+			property["description"] = description
+		}
+	}
+}
+
+// bindRequest decodes the arguments of a tool call into the api request
+// request points to. The validation middleware has already checked them
+// against the request's schema, so a failure returns an error result only
+// for arguments no schema can describe.
+func bindRequest(req mcp.CallToolRequest, request interface{}) *mcp.CallToolResult {
+	if err := req.BindArguments(request); err != nil {
+		return apierror.ToolError(apierror.CodeInvalidParameters, "Invalid arguments for %s: %v", req.Params.Name, err)
+	}
+	return nil
+}
+
+// withTenant adds the optional tenant_id parameter to a session-scoped tool
+func withTenant() mcp.ToolOption {
+	return mcp.WithString("tenant_id", mcp.Description("Tenant namespace of the session (default: shared namespace)"))
+}
+
+// tracingMiddleware records a span for each tool call, continuing the trace
+// the client passes as W3C trace context in the request's _meta
+func tracingMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req.Params.Meta != nil {
+			carrier := propagation.MapCarrier{}
+			for key, value := range req.Params.Meta.AdditionalFields {
+				if text, ok := value.(string); ok {
+					carrier[key] = text
+				}
+			}
+			ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+		}
+
+		ctx, span := telemetry.Tracer().Start(ctx, "tools/call "+req.Params.Name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				telemetry.AttrTool.String(req.Params.Name),
+				telemetry.AttrSessionID.String(req.GetString("session_id", "")),
+				telemetry.AttrTenantID.String(req.GetString("tenant_id", "")),
+			),
+		)
+		defer span.End()
+
+		result, err := next(ctx, req)
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case result != nil && result.IsError:
+			span.SetStatus(codes.Error, "tool returned an error")
+		}
+		return result, err
+	}
+}
+
+// tenantMiddleware validates the tenant_id argument of a tool call and
+// carries it to the handler in the context. Calls without one keep the tenant
+// already on the context, such as the calls of a batch.
+func tenantMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tenantID := req.GetString("tenant_id", storage.TenantFromContext(ctx))
+		if err := storage.ValidateTenantID(tenantID); err != nil {
+			return apierror.ToolFailure(err, "%v", err), nil
+		}
+		return next(storage.WithTenant(ctx, tenantID), req)
+	}
+}
+
+// rateLimitMiddleware limits the tool calls made to each session of each
+// tenant. Calls that name no session are not limited.
+func rateLimitMiddleware(limiter *middleware.RateLimiter) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sessionID := req.GetString("session_id", "")
+			if sessionID == "" {
+				return next(ctx, req)
+			}
+
+			key := storage.TenantSessionID(storage.TenantFromContext(ctx), sessionID)
+			if allowed, wait := limiter.Allow(key); !allowed {
+				return apierror.ToolError(apierror.CodeRateLimited, "rate limit exceeded for session %s; retry in %s", sessionID, wait.Round(time.Millisecond)), nil
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// workerPoolMiddleware runs the calls of the tools pooled reports on pool.
+// Calls the pool rejects or times out fail with SERVER_BUSY or TIMEOUT.
+func workerPoolMiddleware(pool *workerpool.Pool, pooled func(name string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if pool == nil {
+			return next
+		}
+
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !pooled(req.Params.Name) {
+				return next(ctx, req)
+			}
+
+			var result *mcp.CallToolResult
+			var err error
+			if poolErr := pool.Run(ctx, func(ctx context.Context) {
+				result, err = next(ctx, req)
+			}); poolErr != nil {
+				return apierror.ToolFailure(poolErr, "%s: %v", req.Params.Name, poolErr), nil
+			}
+			return result, err
+		}
+	}
+}
+
+// markTools calls add and marks the tools it adds to s in marked
+func markTools(s *server.MCPServer, marked map[string]bool, add func()) {
+	existing := s.ListTools()
+	add()
+	for name := range s.ListTools() {
+		if _, ok := existing[name]; !ok {
+			marked[name] = true
+		}
+	}
+}
+
+// tenantStore scopes the store to the tenant of a tool call, tracing its calls
+// under the tool call span
+func tenantStore(ctx context.Context, store storage.Store) storage.Store {
+	return storage.WithTracing(ctx, storage.ForTenant(store, storage.TenantFromContext(ctx)))
+}
+
+// renderSessionExport returns the text session_export produces for a format:
+// the file itself for single-file formats, or a JSON listing of the files
+func renderSessionExport(store storage.Store, sessionID, format string) (string, error) {
+	exportData, err := store.ExportSession(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := storage.RenderExport(exportData, format)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 1 {
+		return files[0].Content, nil
+	}
+
+	result, err := json.Marshal(map[string]interface{}{
+		"session_id": sessionID,
+		"format":     format,
+		"files":      files,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode export files: %w", err)
+	}
+
+	return string(result), nil
+}
+
+func getString(m map[string]interface{}, key string) string {
+	if val, ok := m[key].(string); ok {
+		return val
+	}
+	return ""
+}
+
+func addIntelligenceTools(s *server.MCPServer, intelligenceService *intelligence.IntelligenceService) {
+	// Create intelligence handler
+	intelligenceHandler := handlers.NewIntelligenceHandler("") // No API key for now
+	if intelligenceService != nil {
+		intelligenceHandler.SetIntelligenceService(intelligenceService)
+	}
+
+	// Add intelligence tools
+	intelligenceHandler.AddIntelligenceTools(s)
+}
@@ -0,0 +1,3258 @@
+package mcpserver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rainmana/gothink/api"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/mcpserver"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/rainmana/gothink/servertest"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSessionExportImport_RoundTrip(t *testing.T) {
+	srv := servertest.New(t)
+
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "source",
+		"thought":             "Define the problem",
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+
+	export := servertest.ResultText(srv.CallTool("session_export", map[string]interface{}{
+		"session_id": "source",
+	}))
+
+	result := srv.CallToolJSON("session_import", map[string]interface{}{
+		"export":     export,
+		"session_id": "copy",
+	})
+
+	assert.Equal(t, "copy", result["session_id"])
+	assert.Equal(t, map[string]interface{}{"thoughts": float64(1)}, result["imported"])
+	assert.Equal(t, map[string]interface{}{"test-1": "test-2"}, result["id_map"])
+	srv.AssertRecordCount("source", "thoughts", 1)
+	srv.AssertRecordCount("copy", "thoughts", 1)
+}
+
+func TestSessionImport_RejectsIncompatibleVersion(t *testing.T) {
+	srv := servertest.New(t)
+
+	text := srv.CallToolError("session_import", map[string]interface{}{
+		"export": `{"version": "2.0.0", "session_id": "s1", "data": {}}`,
+	})
+
+	assert.Contains(t, text, "unsupported session export version")
+	srv.AssertRecordCount("s1", "thoughts", 0)
+}
+
+func TestSessionRecords_Paginates(t *testing.T) {
+	srv := servertest.New(t)
+
+	for i := 1; i <= 3; i++ {
+		srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+			"session_id":          "s1",
+			"thought":             "step",
+			"thought_number":      i,
+			"total_thoughts":      3,
+			"next_thought_needed": i < 3,
+		})
+	}
+
+	result := srv.CallToolJSON("session_records", map[string]interface{}{
+		"session_id":  "s1",
+		"record_type": "thoughts",
+		"limit":       1,
+		"order":       "desc",
+	})
+
+	assert.Equal(t, float64(1), result["count"])
+	assert.Equal(t, float64(3), result["total"])
+	records := result["records"].([]interface{})
+	assert.Equal(t, "test-3", records[0].(map[string]interface{})["id"])
+}
+
+func TestSequentialThinking_AcceptsTheHTTPRequestFields(t *testing.T) {
+	srv := servertest.New(t)
+
+	result := srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Reconsider the premise",
+		"thought_number":      2,
+		"total_thoughts":      3,
+		"next_thought_needed": true,
+		"is_revision":         true,
+		"revises_thought":     1,
+		"branch_id":           "alt",
+	})
+	assert.Equal(t, "s1", result["session_context"].(map[string]interface{})["session_id"])
+
+	records := srv.CallToolJSON("session_records", map[string]interface{}{
+		"session_id":  "s1",
+		"record_type": "thoughts",
+	})["records"].([]interface{})
+	require.Len(t, records, 1)
+	thought := records[0].(map[string]interface{})
+	assert.Equal(t, true, thought["is_revision"])
+	assert.Equal(t, float64(1), thought["revises_thought"])
+	assert.Equal(t, "alt", thought["branch_id"])
+}
+
+func TestUpdateMentalModelConclusion(t *testing.T) {
+	srv := servertest.New(t)
+
+	model := srv.CallToolJSON("mental_model", map[string]interface{}{
+		"session_id": "s1",
+		"model_name": "first_principles",
+		"problem":    "Slow builds",
+	})
+
+	result := srv.CallToolJSON("update_mental_model_conclusion", map[string]interface{}{
+		"session_id": "s1",
+		"model_id":   model["model_id"],
+		"conclusion": "Cache dependencies",
+		"confidence": 0.8,
+	})
+
+	assert.Equal(t, true, result["has_conclusion"])
+	assert.Equal(t, 0.8, result["confidence"])
+
+	text := srv.CallToolError("update_mental_model_conclusion", map[string]interface{}{
+		"session_id": "s1",
+		"model_id":   "missing",
+		"conclusion": "Nothing",
+	})
+	assert.Contains(t, text, "not found")
+}
+
+func TestRecordDebuggingFindings(t *testing.T) {
+	srv := servertest.New(t)
+
+	approach := srv.CallToolJSON("debugging_approach", map[string]interface{}{
+		"session_id":    "s1",
+		"approach_name": "binary_search",
+		"issue":         "Flaky test",
+	})
+	assert.Equal(t, "test-1", approach["approach_id"])
+	srv.AssertRecordCount("s1", "mental_models", 1)
+
+	result := srv.CallToolJSON("record_debugging_findings", map[string]interface{}{
+		"session_id":  "s1",
+		"approach_id": approach["approach_id"],
+		"findings":    "Fails when run in parallel",
+	})
+
+	assert.Equal(t, "binary_search", result["approach_name"])
+	assert.Equal(t, true, result["has_findings"])
+	assert.Equal(t, false, result["has_resolution"])
+}
+
+func TestSessionExportChunk_ReassemblesCompressedExport(t *testing.T) {
+	srv := servertest.New(t)
+
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             strings.Repeat("A long thought. ", 50),
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+
+	var encoded strings.Builder
+	args := map[string]interface{}{"session_id": "s1", "compress": true, "chunk_size": 64}
+	for {
+		chunk := srv.CallToolJSON("session_export_chunk", args)
+		assert.Equal(t, "gzip+base64", chunk["encoding"])
+		encoded.WriteString(chunk["data"].(string))
+		if chunk["done"] == true {
+			break
+		}
+		args = map[string]interface{}{"cursor": chunk["next_cursor"], "chunk_size": 64}
+	}
+
+	content, err := storage.DecompressExport(encoded.String())
+	require.NoError(t, err)
+	export, err := storage.DecodeSessionExport(content)
+	require.NoError(t, err)
+	assert.Equal(t, "s1", export.SessionID)
+}
+
+func TestTenantsAreIsolated(t *testing.T) {
+	srv := servertest.New(t)
+
+	for _, tenant := range []string{"acme", "globex"} {
+		srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+			"session_id":          "s1",
+			"tenant_id":           tenant,
+			"thought":             "Plan for " + tenant,
+			"thought_number":      1,
+			"total_thoughts":      1,
+			"next_thought_needed": false,
+		})
+	}
+	srv.AssertRecordCount(storage.TenantSessionID("acme", "s1"), storage.KindThoughts, 1)
+	srv.AssertRecordCount("s1", storage.KindThoughts, 0)
+
+	stats := srv.CallToolJSON("session_stats", map[string]interface{}{"session_id": "s1", "tenant_id": "acme"})
+	assert.Equal(t, "s1", stats["session_id"])
+
+	hits := srv.CallToolJSON("search_session", map[string]interface{}{"session_id": "s1", "tenant_id": "acme", "query": "globex"})
+	assert.Equal(t, float64(0), hits["count"])
+
+	text := servertest.ResultText(srv.CallTool("session_export", map[string]interface{}{"session_id": "s1", "tenant_id": "globex"}))
+	assert.Contains(t, text, "Plan for globex")
+	assert.NotContains(t, text, "Plan for acme")
+	assert.NotContains(t, text, "tenant:")
+
+	// Neither a bad tenant ID nor a session ID naming another tenant's namespace is accepted
+	srv.CallToolError("session_stats", map[string]interface{}{"session_id": "s1", "tenant_id": "acme/s1"})
+	srv.CallToolError("session_export", map[string]interface{}{"session_id": storage.TenantSessionID("acme", "s1"), "tenant_id": "globex"})
+}
+
+func TestStorageStats(t *testing.T) {
+	srv := servertest.New(t)
+
+	for _, session := range []string{"s1", "s2", "s3"} {
+		srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+			"session_id":          session,
+			"thought":             "Thinking in " + session,
+			"thought_number":      1,
+			"total_thoughts":      1,
+			"next_thought_needed": false,
+		})
+	}
+
+	stats := srv.CallToolJSON("storage_stats", map[string]interface{}{"limit": 2})
+	assert.Equal(t, float64(3), stats["sessions"])
+	assert.Equal(t, float64(3), stats["record_counts"].(map[string]interface{})[storage.KindThoughts])
+	assert.Len(t, stats["session_sizes"], 2)
+	assert.Contains(t, stats, "evictions")
+}
+
+func TestSessionBulkImport(t *testing.T) {
+	srv := servertest.New(t)
+
+	result := srv.CallToolJSON("session_bulk_import", map[string]interface{}{
+		"session_id": "s1",
+		"thoughts": []interface{}{
+			map[string]interface{}{"thought": "Define the problem", "thought_number": 1, "total_thoughts": 2},
+			map[string]interface{}{"thought": "Gather options", "thought_number": 2, "total_thoughts": 2},
+		},
+		"decisions": []interface{}{
+			map[string]interface{}{"decision_statement": "Choose a database"},
+		},
+	})
+
+	assert.Equal(t, "s1", result["session_id"])
+	assert.Equal(t, map[string]interface{}{"thoughts": float64(2), "decisions": float64(1)}, result["added"])
+	srv.AssertRecordCount("s1", "thoughts", 2)
+	srv.AssertRecordCount("s1", "decisions", 1)
+
+	text := srv.CallToolError("session_bulk_import", map[string]interface{}{"session_id": "s1"})
+	assert.Contains(t, text, "No records given")
+}
+
+func TestSessionFork(t *testing.T) {
+	srv := servertest.New(t)
+
+	for i := 1; i <= 3; i++ {
+		srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+			"session_id":          "main",
+			"thought":             "step",
+			"thought_number":      i,
+			"total_thoughts":      3,
+			"next_thought_needed": i < 3,
+		})
+	}
+
+	result := srv.CallToolJSON("session_fork", map[string]interface{}{
+		"session_id":     "main",
+		"new_session_id": "branch",
+		"up_to_thought":  2,
+	})
+
+	assert.Equal(t, "main", result["source_session_id"])
+	assert.Equal(t, "branch", result["session_id"])
+	srv.AssertRecordCount("branch", "thoughts", 2)
+	srv.AssertRecordCount("main", "thoughts", 3)
+
+	text := srv.CallToolError("session_fork", map[string]interface{}{
+		"session_id":     "main",
+		"new_session_id": "branch",
+	})
+	assert.Contains(t, text, "already exists")
+}
+
+func TestResources_ReadSessions(t *testing.T) {
+	srv := servertest.New(t)
+
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Define the problem",
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+	srv.CallToolJSON("concept_map", map[string]interface{}{
+		"session_id": "s1",
+		"operation":  "create",
+	})
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s2",
+		"tenant_id":           "acme",
+		"thought":             "Tenant thought",
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+
+	sessions := srv.ReadResourceJSON("gothink://sessions")
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "s1", "uri": "gothink://session/s1"}}, sessions["sessions"])
+
+	session := srv.ReadResourceJSON("gothink://session/s1")
+	assert.Equal(t, "s1", session["session"].(map[string]interface{})["id"])
+
+	export := srv.ReadResourceJSON("gothink://session/s1/export")
+	assert.Equal(t, "s1", export["session_id"])
+
+	diagrams := srv.ReadResourceJSON("gothink://session/s1/diagrams")
+	assert.Equal(t, float64(1), diagrams["count"])
+
+	tenant := srv.ReadResourceJSON("gothink://tenant/acme/session/s2")
+	assert.Equal(t, "s2", tenant["session"].(map[string]interface{})["id"])
+
+	err := srv.ReadResourceError("gothink://session/missing")
+	assert.ErrorContains(t, err, "session missing not found")
+}
+
+func TestResources_MentalModels(t *testing.T) {
+	srv := servertest.New(t)
+
+	catalog := srv.ReadResourceJSON("gothink://mental-models")
+	assert.Positive(t, catalog["total_models"])
+
+	model := srv.ReadResourceJSON("gothink://mental-models/first_principles")
+	assert.Equal(t, "First Principles Thinking", model["name"])
+
+	err := srv.ReadResourceError("gothink://mental-models/no_such_model")
+	assert.ErrorContains(t, err, "unknown mental model")
+}
+
+// watchSession is a client session that collects the notifications the
+// server sends it
+type watchSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (w *watchSession) SessionID() string { return w.id }
+func (w *watchSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return w.notifications
+}
+func (w *watchSession) Initialize()       {}
+func (w *watchSession) Initialized() bool { return true }
+
+// watch registers a client session with the server
+func watch(t *testing.T, srv *servertest.Server, id string) *watchSession {
+	t.Helper()
+
+	watcher := &watchSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 16)}
+	require.NoError(t, srv.MCP.RegisterSession(context.Background(), watcher))
+	t.Cleanup(func() { srv.MCP.UnregisterSession(context.Background(), id) })
+	return watcher
+}
+
+// request sends a JSON-RPC request of watcher's client to the server
+func (w *watchSession) request(srv *servertest.Server, method, uri string) mcp.JSONRPCMessage {
+	message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q,"params":{"uri":%q}}`, method, uri)
+	return mcpserver.HandleMessage(srv.MCP.WithContext(context.Background(), w), srv.MCP, []byte(message))
+}
+
+// subscribe subscribes watcher's client to a resource
+func (w *watchSession) subscribe(t *testing.T, srv *servertest.Server, uri string) {
+	t.Helper()
+
+	require.IsType(t, mcp.JSONRPCResponse{}, w.request(srv, "resources/subscribe", uri))
+}
+
+// updates returns the URIs of the next n resource updates sent to watcher
+func (w *watchSession) updates(t *testing.T, n int) []string {
+	t.Helper()
+
+	var uris []string
+	for len(uris) < n {
+		select {
+		case notification := <-w.notifications:
+			if notification.Method == mcp.MethodNotificationResourceUpdated {
+				uris = append(uris, notification.Params.AdditionalFields["uri"].(string))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d resource updates, got %v", n, uris)
+		}
+	}
+	return uris
+}
+
+func TestResources_NotifyOnWrite(t *testing.T) {
+	srv := servertest.New(t)
+
+	watcher := watch(t, srv, "watcher")
+	watcher.subscribe(t, srv, "gothink://session/s1")
+	watcher.subscribe(t, srv, "gothink://session/s1/export")
+
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Define the problem",
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+	assert.Equal(t, []string{"gothink://session/s1", "gothink://session/s1/export"}, watcher.updates(t, 2))
+
+	// Once unsubscribed, a resource's updates stop
+	require.IsType(t, mcp.JSONRPCResponse{}, watcher.request(srv, "resources/unsubscribe", "gothink://session/s1"))
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Measure it",
+		"thought_number":      2,
+		"total_thoughts":      2,
+		"next_thought_needed": false,
+	})
+	assert.Equal(t, []string{"gothink://session/s1/export"}, watcher.updates(t, 1))
+}
+
+func TestResources_NotifyOnlySubscribers(t *testing.T) {
+	srv := servertest.New(t)
+
+	shared := watch(t, srv, "shared")
+	shared.subscribe(t, srv, "gothink://session/s1")
+	tenant := watch(t, srv, "tenant")
+	tenant.subscribe(t, srv, "gothink://tenant/acme/session/s1")
+	bystander := watch(t, srv, "bystander")
+
+	for _, tenantID := range []string{"", "acme"} {
+		srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+			"tenant_id":           tenantID,
+			"session_id":          "s1",
+			"thought":             "Define the problem",
+			"thought_number":      1,
+			"total_thoughts":      1,
+			"next_thought_needed": false,
+		})
+	}
+
+	// Each client hears of its own session only, and the writes to the
+	// shared session were notified before those to the tenant's
+	assert.Equal(t, []string{"gothink://session/s1"}, shared.updates(t, 1))
+	assert.Equal(t, []string{"gothink://tenant/acme/session/s1"}, tenant.updates(t, 1))
+	assert.Empty(t, shared.notifications)
+	assert.Empty(t, bystander.notifications)
+}
+
+func TestResources_SubscribeRejectsForeignURIs(t *testing.T) {
+	srv := servertest.New(t)
+
+	response := watch(t, srv, "watcher").request(srv, "resources/subscribe", "https://example.com/feed")
+	require.IsType(t, mcp.JSONRPCError{}, response)
+	assert.Equal(t, mcp.INVALID_PARAMS, response.(mcp.JSONRPCError).Error.Code)
+
+	capabilities := srv.Client.GetServerCapabilities()
+	require.NotNil(t, capabilities.Resources)
+	assert.True(t, capabilities.Resources.Subscribe)
+}
+
+func TestPrompts_MentalModelsAndDebuggingApproaches(t *testing.T) {
+	srv := servertest.New(t)
+
+	text := srv.GetPrompt("first_principles", map[string]string{"problem": "Our builds are slow"})
+	assert.Contains(t, text, "First Principles Thinking")
+	assert.Contains(t, text, "Problem: Our builds are slow")
+	assert.Contains(t, text, "1. Identify the problem clearly")
+	assert.Contains(t, text, `model_name "first_principles"`)
+
+	text = srv.GetPrompt("debugging_binary_search", map[string]string{"issue": "Flaky test", "context": "Started last week"})
+	assert.Contains(t, text, "Issue: Flaky test")
+	assert.Contains(t, text, "Context: Started last week")
+	assert.Contains(t, text, `approach_name "binary_search"`)
+
+	assert.ErrorContains(t, srv.GetPromptError("first_principles", nil), "problem is required")
+	srv.GetPromptError("unknown_model", map[string]string{"problem": "x"})
+}
+
+// game is the arguments of search_game_tree for a game of one move, between
+// settling for half the reward and winning it all
+const game = `"root_state":"start","states":[` +
+	`{"name":"start","moves":[{"move":"settle","next_state":"settled"},{"move":"explore","next_state":"won"}]},` +
+	`{"name":"settled","reward":0.5},{"name":"won","reward":1}]`
+
+func TestStochasticTools_ReportProgressAndHonorCancellation(t *testing.T) {
+	srv := servertest.New(t)
+
+	watcher := &watchSession{notifications: make(chan mcp.JSONRPCNotification, 16)}
+	require.NoError(t, srv.MCP.RegisterSession(context.Background(), watcher))
+	call := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search_game_tree",` +
+		`"arguments":{"session_id":"s1","problem":"Next move",` + game + `,"simulations":40,"stream":true,"stream_interval":10,"seed":1},` +
+		`"_meta":{"progressToken":"mcts-1"}}}`)
+
+	srv.MCP.HandleMessage(srv.MCP.WithContext(context.Background(), watcher), call)
+
+	var progress []float64
+	for len(watcher.notifications) > 0 {
+		notification := <-watcher.notifications
+		if notification.Method != "notifications/progress" {
+			continue
+		}
+		assert.Equal(t, "mcts-1", notification.Params.AdditionalFields["progressToken"])
+		assert.Equal(t, float64(40), notification.Params.AdditionalFields["total"])
+		progress = append(progress, notification.Params.AdditionalFields["progress"].(float64))
+	}
+	assert.Equal(t, []float64{10, 20, 30}, progress)
+	assert.Equal(t, 1, srv.RecordCount("s1", "stochastic_algorithms"))
+
+	ctx, cancel := context.WithCancel(srv.MCP.WithContext(context.Background(), watcher))
+	cancel()
+	response := srv.MCP.HandleMessage(ctx, call)
+	result, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.True(t, result.Result.(mcp.CallToolResult).IsError)
+	assert.Equal(t, 1, srv.RecordCount("s1", "stochastic_algorithms"))
+}
+
+func TestBayesianOptimization_StreamsBestEvaluations(t *testing.T) {
+	srv := servertest.New(t)
+
+	watcher := &watchSession{notifications: make(chan mcp.JSONRPCNotification, 16)}
+	require.NoError(t, srv.MCP.RegisterSession(context.Background(), watcher))
+	call := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"bayesian_optimization",` +
+		`"arguments":{"session_id":"s1","problem":"Tune the cache","objective":"-pow(size - 3, 2)",` +
+		`"parameters":[{"name":"size","min":0,"max":10}],"iterations":9,"stream":true,"stream_interval":3,"seed":4},` +
+		`"_meta":{"progressToken":"bo-1"}}}`)
+
+	response := srv.MCP.HandleMessage(srv.MCP.WithContext(context.Background(), watcher), call)
+	result, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	assert.False(t, result.Result.(mcp.CallToolResult).IsError)
+
+	var progress []float64
+	for len(watcher.notifications) > 0 {
+		notification := <-watcher.notifications
+		if notification.Method != "notifications/progress" {
+			continue
+		}
+		assert.Equal(t, float64(9), notification.Params.AdditionalFields["total"])
+		progress = append(progress, notification.Params.AdditionalFields["progress"].(float64))
+
+		var update api.StreamUpdate
+		require.NoError(t, json.Unmarshal([]byte(notification.Params.AdditionalFields["message"].(string)), &update))
+		assert.Equal(t, "bayesian", update.Algorithm)
+		assert.Contains(t, update.BestParameters, "size")
+		assert.NotNil(t, update.BestValue)
+	}
+	// The last evaluations end with the run itself
+	assert.Equal(t, []float64{3, 6}, progress)
+	srv.AssertRecordCount("s1", storage.KindStochasticAlgorithms, 1)
+}
+
+func TestRateLimit_LimitsEachSession(t *testing.T) {
+	srv := servertest.New(t, servertest.WithConfig(func(cfg *config.Config) {
+		cfg.RateLimitPerSecond = 0.001
+		cfg.RateLimitBurst = 2
+	}))
+
+	thought := func(sessionID, tenantID string) map[string]interface{} {
+		return map[string]interface{}{
+			"session_id":          sessionID,
+			"tenant_id":           tenantID,
+			"thought":             "Define the problem",
+			"thought_number":      1,
+			"total_thoughts":      1,
+			"next_thought_needed": false,
+		}
+	}
+
+	srv.CallToolJSON("sequential_thinking", thought("s1", ""))
+	srv.CallToolJSON("sequential_thinking", thought("s1", ""))
+	assert.Contains(t, srv.CallToolError("sequential_thinking", thought("s1", "")), "rate limit exceeded for session s1")
+
+	// Other sessions, and the same session ID of another tenant, are not affected
+	srv.CallToolJSON("sequential_thinking", thought("s2", ""))
+	srv.CallToolJSON("sequential_thinking", thought("s1", "acme"))
+	srv.CallToolJSON("list_mental_models", map[string]interface{}{})
+	assert.Equal(t, 2, srv.RecordCount("s1", "thoughts"))
+}
+
+func TestTracing_SpansToolCallsAndStorage(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	srv := servertest.New(t)
+	call := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"sequential_thinking",` +
+		`"arguments":{"session_id":"s1","thought":"Define the problem","thought_number":1,"total_thoughts":1,"next_thought_needed":false},` +
+		`"_meta":{"traceparent":"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}}}`)
+	srv.MCP.HandleMessage(context.Background(), call)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	tool, ok := spans["tools/call sequential_thinking"]
+	require.True(t, ok, "tool call span recorded")
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", tool.SpanContext().TraceID().String())
+	assert.Equal(t, "b7ad6b7169203331", tool.Parent().SpanID().String())
+
+	add, ok := spans["storage.AddThought"]
+	require.True(t, ok, "storage span recorded")
+	assert.Equal(t, tool.SpanContext().SpanID(), add.Parent().SpanID())
+	assert.Contains(t, add.Attributes(), attribute.String("gothink.session_id", "s1"))
+}
+
+func TestToolErrors_CarryCodes(t *testing.T) {
+	srv := servertest.New(t, servertest.WithConfig(func(cfg *config.Config) {
+		cfg.MaxThoughtsPerSession = 1
+	}))
+
+	thought := map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Define the problem",
+		"thought_number":      1,
+		"total_thoughts":      2,
+		"next_thought_needed": true,
+	}
+	srv.CallToolJSON("sequential_thinking", thought)
+	assert.Equal(t, "SESSION_LIMIT_REACHED", srv.CallToolErrorCode("sequential_thinking", thought))
+
+	assert.Equal(t, "MODEL_NOT_FOUND", srv.CallToolErrorCode("mental_model", map[string]interface{}{
+		"session_id": "s1",
+		"model_name": "does_not_exist",
+		"problem":    "Anything",
+	}))
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("update_thought", map[string]interface{}{
+		"session_id": "s1",
+		"thought_id": "missing",
+		"thought":    "Revised",
+	}))
+	assert.Equal(t, "SESSION_NOT_FOUND", srv.CallToolErrorCode("session_clear", map[string]interface{}{
+		"session_id": "missing",
+	}))
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("session_records", map[string]interface{}{
+		"session_id":  "s1",
+		"record_type": "thoughts",
+		"since":       "yesterday",
+	}))
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("sequential_thinking", map[string]interface{}{
+		"session_id": "s1",
+		"tenant_id":  "not a tenant",
+	}))
+
+	srv.CallToolJSON("archive_session", map[string]interface{}{"session_id": "s1"})
+	assert.Equal(t, "SESSION_ARCHIVED", srv.CallToolErrorCode("archive_session", map[string]interface{}{"session_id": "s1"}))
+
+	// The error object is also returned as structured content
+	result := srv.CallTool("restore_session", map[string]interface{}{"session_id": "missing"})
+	require.True(t, result.IsError)
+	assert.Contains(t, servertest.ResultText(result), `"code":"SESSION_NOT_FOUND"`)
+	assert.NotNil(t, result.StructuredContent)
+}
+
+func TestValidation_RejectsMalformedCalls(t *testing.T) {
+	srv := servertest.New(t)
+
+	thought := func(overrides map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{
+			"session_id":          "s1",
+			"thought":             "Define the problem",
+			"thought_number":      1,
+			"total_thoughts":      3,
+			"next_thought_needed": true,
+		}
+		for name, value := range overrides {
+			if value == nil {
+				delete(args, name)
+			} else {
+				args[name] = value
+			}
+		}
+		return args
+	}
+
+	for _, tc := range []struct {
+		overrides map[string]interface{}
+		problem   string
+	}{
+		{map[string]interface{}{"thought": nil}, "thought is required"},
+		{map[string]interface{}{"thought": ""}, "thought must not be empty"},
+		{map[string]interface{}{"thought_number": 0}, "thought_number must be at least 1"},
+		{map[string]interface{}{"thought_number": 1.5}, "thought_number must be a whole number"},
+		{map[string]interface{}{"total_thoughts": "many"}, "total_thoughts must be a number"},
+		{map[string]interface{}{"next_thought": true}, "next_thought is not a known argument"},
+	} {
+		text := srv.CallToolError("sequential_thinking", thought(tc.overrides))
+		assert.Contains(t, text, "INVALID_PARAMETERS")
+		assert.Contains(t, text, tc.problem)
+	}
+	srv.AssertRecordCount("s1", "thoughts", 0)
+
+	text := srv.CallToolError("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Pick a database",
+		"criteria": []interface{}{
+			map[string]interface{}{"name": "Cost", "weight": 0.4},
+			map[string]interface{}{"name": "Speed", "weight": 1.5},
+			map[string]interface{}{"weight": 0.1},
+		},
+	})
+	assert.Contains(t, text, "criteria[1].weight must be at most 1")
+	assert.Contains(t, text, "criteria[2].name is required")
+	srv.AssertRecordCount("s1", "decisions", 0)
+
+	text = srv.CallToolError("concept_map", map[string]interface{}{
+		"session_id": "s1",
+		"operation":  "explode",
+	})
+	assert.Contains(t, text, "operation must be one of create, update, delete")
+
+	srv.CallToolJSON("sequential_thinking", thought(nil))
+	srv.AssertRecordCount("s1", "thoughts", 1)
+}
+
+func TestToolEnablement_FollowsFeatureFlagsAndOverrides(t *testing.T) {
+	srv := servertest.New(t, servertest.WithConfig(func(cfg *config.Config) {
+		cfg.EnableStochasticAlgorithms = false
+		cfg.DisabledTools = []string{"session_clear"}
+	}))
+
+	names := srv.ToolNames()
+	assert.Contains(t, names, "sequential_thinking")
+	assert.Contains(t, names, "concept_map")
+	assert.NotContains(t, names, "solve_mdp")
+	assert.NotContains(t, names, "play_bandit")
+	assert.NotContains(t, names, "session_clear")
+
+	srv = servertest.New(t, servertest.WithConfig(func(cfg *config.Config) {
+		cfg.EnableSystematicThinking = false
+		cfg.EnabledTools = []string{"session_stats", "session_export", "sequential_thinking"}
+		cfg.DisabledTools = []string{"session_export"}
+	}))
+
+	// Per-tool entries only narrow what the feature flags register
+	assert.ElementsMatch(t, []string{"session_stats"}, srv.ToolNames())
+}
+
+func TestAuditLog_RecordsToolCallsWithRedaction(t *testing.T) {
+	var auditLog bytes.Buffer
+	srv := servertest.New(t, servertest.WithAuditLog(&auditLog), servertest.WithConfig(func(cfg *config.Config) {
+		cfg.AuditRedactContent = true
+	}))
+
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"tenant_id":           "acme",
+		"thought":             "The launch codes are 1234",
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+	srv.CallToolError("mental_model", map[string]interface{}{
+		"session_id": "s1",
+		"model_name": "does_not_exist",
+		"problem":    "Anything",
+	})
+
+	assert.NotContains(t, auditLog.String(), "launch codes")
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(auditLog.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "sequential_thinking", entries[0]["tool"])
+	assert.Equal(t, "s1", entries[0]["session_id"])
+	assert.Equal(t, "acme", entries[0]["tenant_id"])
+	assert.Equal(t, "success", entries[0]["outcome"])
+	assert.Contains(t, entries[0], "duration_ms")
+	arguments := entries[0]["arguments"].(map[string]interface{})
+	assert.Equal(t, "[REDACTED]", arguments["thought"])
+	assert.Equal(t, float64(1), arguments["thought_number"])
+
+	assert.Equal(t, "mental_model", entries[1]["tool"])
+	assert.Equal(t, "error", entries[1]["outcome"])
+	assert.Equal(t, "MODEL_NOT_FOUND", entries[1]["error_code"])
+	assert.Equal(t, "does_not_exist", entries[1]["arguments"].(map[string]interface{})["model_name"])
+}
+
+func TestWorkerPool_BoundsExpensiveTools(t *testing.T) {
+	srv := servertest.New(t, servertest.WithConfig(func(cfg *config.Config) {
+		cfg.WorkerPoolSize = 1
+		cfg.ToolTimeout = time.Nanosecond
+	}))
+
+	// Tools off the pool are unaffected by its timeout
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Unaffected",
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+	srv.AssertRecordCount("s1", storage.KindThoughts, 1)
+
+	// Stochastic tools run on the pool and cannot finish within its timeout
+	assert.Equal(t, "TIMEOUT", srv.CallToolErrorCode("solve_mdp", map[string]interface{}{
+		"session_id": "s1",
+		"problem":    "Route planning",
+		"gamma":      0.9,
+		"transitions": []interface{}{
+			map[string]interface{}{"state": "depot", "action": "drive", "next_state": "depot", "probability": 1, "reward": 1},
+		},
+	}))
+	srv.AssertRecordCount("s1", storage.KindStochasticAlgorithms, 0)
+}
+
+func TestWebSocket_StreamsProgressAndResults(t *testing.T) {
+	srv := servertest.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	httpServer := httptest.NewServer(mcpserver.NewWebSocketHandler(ctx, srv.MCP, logrus.New()))
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// read returns the next message, skipping notifications other than progress
+	read := func() map[string]interface{} {
+		t.Helper()
+		for {
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			var message map[string]interface{}
+			require.NoError(t, conn.ReadJSON(&message))
+			if method, ok := message["method"]; !ok || method == "notifications/progress" {
+				return message
+			}
+		}
+	}
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize",`+
+		`"params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"browser","version":"1.0"}}}`)))
+	response := read()
+	assert.Equal(t, float64(1), response["id"])
+	assert.Contains(t, response, "result")
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_game_tree",`+
+		`"arguments":{"session_id":"ws","problem":"Next move",`+game+`,"simulations":40,"stream":true,"stream_interval":10,"seed":1},`+
+		`"_meta":{"progressToken":"mcts-ws"}}}`)))
+
+	// Progress arrives while the call runs, before its result
+	var progress []interface{}
+	for {
+		message := read()
+		if message["method"] == "notifications/progress" {
+			params := message["params"].(map[string]interface{})
+			assert.Equal(t, "mcts-ws", params["progressToken"])
+			progress = append(progress, params["progress"])
+			continue
+		}
+		assert.Equal(t, float64(2), message["id"])
+		result := message["result"].(map[string]interface{})
+		assert.NotEqual(t, true, result["isError"])
+		break
+	}
+	assert.Equal(t, []interface{}{float64(10), float64(20), float64(30)}, progress)
+	assert.Equal(t, 1, srv.RecordCount("ws", "stochastic_algorithms"))
+
+	// Malformed messages are answered with a JSON-RPC error
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{`)))
+	response = read()
+	assert.Equal(t, float64(mcp.PARSE_ERROR), response["error"].(map[string]interface{})["code"])
+
+	// The connection is closed once the server stops
+	cancel()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error %v", err)
+}
+
+func TestStdio_SubscribesAndNotifies(t *testing.T) {
+	srv := servertest.New(t)
+	inReader, in := io.Pipe()
+	out, outWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- mcpserver.ServeStdio(context.Background(), srv.MCP, inReader, outWriter)
+		outWriter.Close()
+	}()
+
+	decoder := json.NewDecoder(out)
+	send := func(message string) map[string]interface{} {
+		t.Helper()
+		_, err := io.WriteString(in, message+"\n")
+		require.NoError(t, err)
+		var response map[string]interface{}
+		require.NoError(t, decoder.Decode(&response))
+		return response
+	}
+
+	response := send(`{"jsonrpc":"2.0","id":1,"method":"initialize",` +
+		`"params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"cli","version":"1.0"}}}`)
+	assert.Equal(t, true, response["result"].(map[string]interface{})["capabilities"].(map[string]interface{})["resources"].(map[string]interface{})["subscribe"])
+	response = send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"gothink://session/s1/export"}}`)
+	assert.Equal(t, float64(2), response["id"])
+	assert.Contains(t, response, "result")
+
+	// A write from another client reaches the subscriber
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Define the problem",
+		"thought_number":      1,
+		"total_thoughts":      1,
+		"next_thought_needed": false,
+	})
+	var notification map[string]interface{}
+	require.NoError(t, decoder.Decode(&notification))
+	assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification["method"])
+	assert.Equal(t, "gothink://session/s1/export", notification["params"].(map[string]interface{})["uri"])
+
+	// The server stops once stdin ends
+	require.NoError(t, in.Close())
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeStdio did not return once stdin ended")
+	}
+}
+
+func TestBatchExecute_RunsCallsInOrder(t *testing.T) {
+	srv := servertest.New(t)
+
+	thought := func(number int) map[string]interface{} {
+		return map[string]interface{}{
+			"tool": "sequential_thinking",
+			"arguments": map[string]interface{}{
+				"session_id":          "s1",
+				"thought":             "Step",
+				"thought_number":      number,
+				"total_thoughts":      2,
+				"next_thought_needed": number < 2,
+			},
+		}
+	}
+	result := srv.CallToolJSON("batch_execute", map[string]interface{}{
+		"tenant_id": "acme",
+		"calls": []interface{}{
+			thought(1),
+			map[string]interface{}{"tool": "sequential_thinking", "arguments": map[string]interface{}{"session_id": "s1"}},
+			map[string]interface{}{"tool": "batch_execute"},
+			thought(2),
+		},
+	})
+	assert.Equal(t, float64(2), result["succeeded"])
+	assert.Equal(t, float64(2), result["failed"])
+
+	results := result["results"].([]interface{})
+	require.Len(t, results, 4)
+	first := results[0].(map[string]interface{})
+	assert.Equal(t, "success", first["status"])
+	assert.Equal(t, "s1", first["result"].(map[string]interface{})["session_context"].(map[string]interface{})["session_id"])
+	assert.Equal(t, "INVALID_PARAMETERS", results[1].(map[string]interface{})["error"].(map[string]interface{})["code"])
+	assert.Equal(t, "error", results[2].(map[string]interface{})["status"])
+	assert.Equal(t, "success", results[3].(map[string]interface{})["status"])
+
+	// The calls ran in the batch's tenant
+	srv.AssertRecordCount(storage.TenantSessionID("acme", "s1"), storage.KindThoughts, 2)
+	srv.AssertRecordCount("s1", storage.KindThoughts, 0)
+
+	// Once a call fails, stop_on_error skips the rest
+	result = srv.CallToolJSON("batch_execute", map[string]interface{}{
+		"stop_on_error": true,
+		"calls": []interface{}{
+			map[string]interface{}{"tool": "no_such_tool"},
+			thought(1),
+		},
+	})
+	results = result["results"].([]interface{})
+	assert.Equal(t, "error", results[0].(map[string]interface{})["status"])
+	assert.Equal(t, "skipped", results[1].(map[string]interface{})["status"])
+	srv.AssertRecordCount("s1", storage.KindThoughts, 0)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("batch_execute", map[string]interface{}{"calls": []interface{}{}}))
+}
+
+func TestRequestID_RetryReturnsRetainedResult(t *testing.T) {
+	srv := servertest.New(t)
+
+	args := map[string]interface{}{
+		"request_id":          "r1",
+		"session_id":          "s1",
+		"thought":             "Frame the problem",
+		"thought_number":      1,
+		"total_thoughts":      2,
+		"next_thought_needed": true,
+	}
+	first := srv.CallToolJSON("sequential_thinking", args)
+	retry := srv.CallToolJSON("sequential_thinking", args)
+	assert.Equal(t, first["thought_id"], retry["thought_id"])
+	srv.AssertRecordCount("s1", storage.KindThoughts, 1)
+
+	// The request ID is scoped to the tenant
+	args["tenant_id"] = "acme"
+	srv.CallToolJSON("sequential_thinking", args)
+	srv.AssertRecordCount(storage.TenantSessionID("acme", "s1"), storage.KindThoughts, 1)
+	delete(args, "tenant_id")
+
+	// A request ID cannot be reused for a different call
+	args["thought"] = "Something else"
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("sequential_thinking", args))
+
+	// Failed calls are not retained, so their retries run again
+	failing := map[string]interface{}{"request_id": "r2", "session_id": "missing"}
+	assert.Equal(t, "SESSION_NOT_FOUND", srv.CallToolErrorCode("archive_session", failing))
+	srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id": "missing", "thought": "Start", "thought_number": 1, "total_thoughts": 1, "next_thought_needed": false,
+	})
+	srv.CallToolJSON("archive_session", failing)
+}
+
+func TestQLearning_LearnsFromSamples(t *testing.T) {
+	srv := servertest.New(t)
+
+	sample := func(state, action, next string, reward float64) map[string]interface{} {
+		return map[string]interface{}{"state": state, "action": action, "next_state": next, "reward": reward}
+	}
+	result := srv.CallToolJSON("reinforcement_learning", map[string]interface{}{
+		"session_id": "rl",
+		"problem":    "Patch now or later",
+		"gamma":      0.9,
+		"episodes":   300,
+		"seed":       7,
+		"samples": []interface{}{
+			sample("exposed", "patch", "safe", 5),
+			sample("exposed", "wait", "exposed", -1),
+			sample("exposed", "wait", "breached", -20),
+		},
+	})
+	assert.Equal(t, "patch", result["policy"].(map[string]interface{})["exposed"])
+	curve := result["learning_curve"].([]interface{})
+	require.Len(t, curve, 300)
+	assert.Equal(t, float64(1), curve[0].(map[string]interface{})["epsilon"])
+	srv.AssertRecordCount("rl", storage.KindStochasticAlgorithms, 1)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("reinforcement_learning", map[string]interface{}{
+		"session_id": "rl",
+		"problem":    "No environment",
+		"gamma":      0.9,
+	}))
+}
+
+func TestReinforcementLearning_LearnsGridWorld(t *testing.T) {
+	srv := servertest.New(t)
+
+	result := srv.CallToolJSON("reinforcement_learning", map[string]interface{}{
+		"session_id": "grid",
+		"problem":    "Reach the exit",
+		"method":     "expected_sarsa",
+		"gamma":      0.95,
+		"episodes":   400,
+		"seed":       3,
+		"grid": map[string]interface{}{
+			"layout": []interface{}{"S.#", "..G"},
+		},
+	})
+	assert.Equal(t, "expected_sarsa", result["method"])
+	policy := result["policy"].(map[string]interface{})
+	assert.Contains(t, []interface{}{"down", "right"}, policy["0,0"])
+	assert.Equal(t, "right", policy["1,1"])
+	grid := result["policy_grid"].([]interface{})
+	require.Len(t, grid, 2)
+	assert.True(t, strings.HasSuffix(grid[0].(string), "#"))
+	assert.True(t, strings.HasSuffix(grid[1].(string), ">G"))
+	srv.AssertRecordCount("grid", storage.KindStochasticAlgorithms, 1)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("reinforcement_learning", map[string]interface{}{
+		"session_id": "grid",
+		"problem":    "No open cells",
+		"gamma":      0.9,
+		"grid":       map[string]interface{}{"layout": []interface{}{"#G"}},
+	}))
+}
+
+func TestSimulatedAnnealing_MinimizesObjective(t *testing.T) {
+	srv := servertest.New(t)
+
+	result := srv.CallToolJSON("simulated_annealing", map[string]interface{}{
+		"session_id":          "anneal",
+		"problem":             "Tune the cache",
+		"objective":           "pow(size - 3, 2) + abs(ttl + 1)",
+		"variables":           []interface{}{map[string]interface{}{"name": "size", "min": 0, "max": 10}, map[string]interface{}{"name": "ttl", "min": -5, "max": 5}},
+		"schedule":            "logarithmic",
+		"initial_temperature": 5,
+		"iterations":          2000,
+		"step_size":           0.05,
+		"start":               map[string]interface{}{"size": 10, "ttl": 5},
+		"seed":                2,
+	})
+	assert.Equal(t, 55.0, result["start_value"])
+	assert.Less(t, result["best_value"].(float64), 0.1)
+	best := result["best_point"].(map[string]interface{})
+	assert.InDelta(t, 3, best["size"].(float64), 0.3)
+	assert.InDelta(t, -1, best["ttl"].(float64), 0.1)
+	assert.Len(t, result["trajectory"], 100)
+	convergence := result["convergence"].(map[string]interface{})
+	assert.Equal(t, "max_iterations", convergence["stopping_reason"])
+	assert.Len(t, convergence["residuals"], 99)
+	srv.AssertRecordCount("anneal", storage.KindStochasticAlgorithms, 1)
+
+	// Stopping at a plateau ends the run early
+	result = srv.CallToolJSON("simulated_annealing", map[string]interface{}{
+		"session_id":      "anneal",
+		"problem":         "Tune the cache",
+		"objective":       "min(size, 4)",
+		"variables":       []interface{}{map[string]interface{}{"name": "size", "min": 0, "max": 10}},
+		"iterations":      2000,
+		"patience":        50,
+		"stop_at_plateau": true,
+		"seed":            2,
+	})
+	convergence = result["convergence"].(map[string]interface{})
+	assert.Equal(t, true, convergence["converged"])
+	assert.Equal(t, "plateau", convergence["stopping_reason"])
+	assert.Less(t, result["iterations"].(float64), 2000.0)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("simulated_annealing", map[string]interface{}{
+		"session_id": "anneal",
+		"problem":    "Unknown variable",
+		"objective":  "size * rate",
+		"variables":  []interface{}{map[string]interface{}{"name": "size", "min": 0, "max": 10}},
+	}))
+}
+
+func TestMonteCarloSimulation_EstimatesRisk(t *testing.T) {
+	srv := servertest.New(t)
+
+	request := map[string]interface{}{
+		"session_id": "risk",
+		"problem":    "Will the project overrun its budget",
+		"output":     "labor * days + incident",
+		"variables": []interface{}{
+			map[string]interface{}{"name": "labor", "distribution": "lognormal", "mean": 800, "std_dev": 100},
+			map[string]interface{}{"name": "days", "distribution": "triangular", "min": 20, "mode": 25, "max": 40},
+			map[string]interface{}{"name": "incident", "distribution": "discrete", "values": []interface{}{0, 10000}, "probabilities": []interface{}{0.9, 0.1}},
+		},
+		"trials":     20000,
+		"buckets":    10,
+		"thresholds": []interface{}{30000},
+		"seed":       5,
+	}
+	result := srv.CallToolJSON("monte_carlo_simulation", request)
+	// 800 · (20+25+40)/3 + 1000
+	assert.InDelta(t, 23667, result["mean"].(float64), 250)
+	assert.Len(t, result["percentiles"], 5)
+	assert.Len(t, result["histogram"], 10)
+	exceedance := result["exceedances"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 30000.0, exceedance["threshold"])
+	assert.Greater(t, exceedance["probability"].(float64), 0.05)
+	assert.Less(t, exceedance["probability"].(float64), 0.3)
+	convergence := result["convergence"].(map[string]interface{})
+	assert.Equal(t, true, convergence["converged"])
+	assert.Less(t, convergence["r_hat"].(float64), 1.01)
+	srv.AssertRecordCount("risk", storage.KindStochasticAlgorithms, 1)
+
+	// The trials are seeded in chunks, whatever the goroutines running them
+	request["parallelism"] = 1
+	serial := srv.CallToolJSON("monte_carlo_simulation", request)
+	assert.Equal(t, result["mean"], serial["mean"])
+	assert.Equal(t, result["percentiles"], serial["percentiles"])
+	srv.AssertRecordCount("risk", storage.KindStochasticAlgorithms, 2)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("monte_carlo_simulation", map[string]interface{}{
+		"session_id": "risk",
+		"problem":    "Backwards triangle",
+		"output":     "days",
+		"variables":  []interface{}{map[string]interface{}{"name": "days", "distribution": "triangular", "min": 40, "mode": 25, "max": 20}},
+	}))
+}
+
+func TestParticleFilter_TracksLatentState(t *testing.T) {
+	srv := servertest.New(t)
+
+	// A position moving at a hidden constant velocity, observed with noise
+	observations := []interface{}{1.2, 1.9, 3.1, 4.0, 4.8, 6.2, 7.1, 7.9, 9.0, 10.1}
+	result := srv.CallToolJSON("particle_filter", map[string]interface{}{
+		"session_id": "tracking",
+		"problem":    "Where is the vehicle",
+		"variables": []interface{}{
+			map[string]interface{}{"name": "position", "initial_std_dev": 1, "dynamics": "position + velocity", "process_noise": 0.1},
+			map[string]interface{}{"name": "velocity", "initial_mean": 0.5, "initial_std_dev": 1, "process_noise": 0.01},
+		},
+		"observation":       "position",
+		"observation_noise": 0.3,
+		"observations":      observations,
+		"particles":         5000,
+		"seed":              3,
+	})
+	assert.Len(t, result["steps"], 10)
+	final := result["final"].(map[string]interface{})
+	position := final["position"].(map[string]interface{})
+	assert.InDelta(t, 10, position["mean"].(float64), 0.6)
+	assert.Less(t, position["lower"].(float64), position["mean"].(float64))
+	assert.Greater(t, position["upper"].(float64), position["mean"].(float64))
+	assert.InDelta(t, 1, final["velocity"].(map[string]interface{})["mean"].(float64), 0.2)
+	srv.AssertRecordCount("tracking", storage.KindStochasticAlgorithms, 1)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("particle_filter", map[string]interface{}{
+		"session_id":        "tracking",
+		"problem":           "Unknown variable",
+		"variables":         []interface{}{map[string]interface{}{"name": "position"}},
+		"observation":       "speed",
+		"observation_noise": 1,
+		"observations":      observations,
+	}))
+}
+
+func TestBootstrapAnalysis_QuantifiesUncertainty(t *testing.T) {
+	srv := servertest.New(t)
+
+	result := srv.CallToolJSON("bootstrap_analysis", map[string]interface{}{
+		"session_id": "evidence",
+		"problem":    "Did the change cut latency",
+		"statistic":  "mean_difference",
+		"data":       []interface{}{120, 118, 125, 130, 122, 119, 127},
+		"comparison": []interface{}{101, 99, 105, 98, 103, 100},
+		"resamples":  5000,
+		"seed":       2,
+	})
+	assert.InDelta(t, 123-101, result["estimate"].(float64), 1e-9)
+	assert.Greater(t, result["std_error"].(float64), 0.0)
+	assert.Equal(t, 0.95, result["confidence"])
+	for _, name := range []string{"percentile_interval", "basic_interval", "normal_interval"} {
+		interval := result[name].(map[string]interface{})
+		assert.Greater(t, interval["lower"].(float64), 10.0, name)
+		assert.Less(t, interval["upper"].(float64), 30.0, name)
+	}
+	srv.AssertRecordCount("evidence", storage.KindStochasticAlgorithms, 1)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("bootstrap_analysis", map[string]interface{}{
+		"session_id": "evidence",
+		"problem":    "No comparison",
+		"statistic":  "mean_difference",
+		"data":       []interface{}{1, 2, 3},
+	}))
+}
+
+func TestQueueingSimulation_SizesCapacity(t *testing.T) {
+	srv := servertest.New(t)
+
+	// Two servers at 80% load: Erlang's C makes 71% of the customers wait
+	result := srv.CallToolJSON("queueing_simulation", map[string]interface{}{
+		"session_id":   "capacity",
+		"problem":      "How many workers does the job queue need",
+		"model":        "mmc",
+		"servers":      2,
+		"arrival_rate": 8,
+		"service_rate": 5,
+		"customers":    50000,
+		"seed":         4,
+	})
+	assert.Equal(t, true, result["stable"])
+	assert.Equal(t, 2.0, result["servers"])
+	theory := result["theory"].(map[string]interface{})
+	assert.InDelta(t, 0.8, theory["utilization"].(float64), 1e-9)
+	assert.InDelta(t, 0.7111, theory["wait_probability"].(float64), 1e-4)
+	assert.InDelta(t, 0.8, result["utilization"].(float64), 0.02)
+	assert.InEpsilon(t, theory["mean_wait"].(float64), result["mean_wait"].(float64), 0.15)
+	assert.Len(t, result["wait_percentiles"], 4)
+	empty := result["queue_lengths"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 0.0, empty["length"])
+	assert.InDelta(t, empty["theory"].(float64), empty["probability"].(float64), 0.03)
+	convergence := result["convergence"].(map[string]interface{})
+	assert.Equal(t, true, convergence["converged"])
+	srv.AssertRecordCount("capacity", storage.KindStochasticAlgorithms, 1)
+
+	// One worker cannot keep up
+	overloaded := srv.CallToolJSON("queueing_simulation", map[string]interface{}{
+		"session_id":   "capacity",
+		"problem":      "Can one worker keep up",
+		"arrival_rate": 8,
+		"service_rate": 5,
+		"seed":         4,
+	})
+	assert.Equal(t, false, overloaded["stable"])
+	assert.Nil(t, overloaded["theory"])
+	assert.Contains(t, overloaded["summary"], "unstable")
+	srv.AssertRecordCount("capacity", storage.KindStochasticAlgorithms, 2)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("queueing_simulation", map[string]interface{}{
+		"session_id":   "capacity",
+		"problem":      "Two servers on one",
+		"model":        "mm1",
+		"servers":      2,
+		"arrival_rate": 1,
+		"service_rate": 2,
+	}))
+}
+
+func TestABTestAnalysis_ComparesVariants(t *testing.T) {
+	srv := servertest.New(t)
+
+	request := map[string]interface{}{
+		"session_id": "experiment",
+		"problem":    "Does the new checkout convert better",
+		"variants": []interface{}{
+			map[string]interface{}{"name": "old", "visitors": 1000, "conversions": 100},
+			map[string]interface{}{"name": "new", "visitors": 1000, "conversions": 130},
+		},
+		"samples": 20000,
+		"seed":    3,
+	}
+	result := srv.CallToolJSON("ab_test_analysis", request)
+	assert.Equal(t, "old", result["control"])
+	assert.Equal(t, "new", result["best"])
+	variants := result["variants"].([]interface{})
+	require.Len(t, variants, 2)
+	assert.NotContains(t, variants[0], "vs_control")
+	challenger := variants[1].(map[string]interface{})
+	vs := challenger["vs_control"].(map[string]interface{})
+	assert.InDelta(t, 0.0355, vs["p_value"].(float64), 1e-4)
+	assert.Equal(t, true, vs["significant"])
+	assert.InDelta(t, 0.98, vs["probability_to_beat"].(float64), 0.01)
+	assert.Less(t, challenger["expected_loss"].(float64), 0.001)
+	confidence := result["confidence"].(map[string]interface{})
+	assert.Equal(t, "probability_best", confidence["method"])
+	assert.Equal(t, challenger["probability_best"], confidence["value"])
+	srv.AssertRecordCount("experiment", storage.KindStochasticAlgorithms, 1)
+
+	// The draws are seeded in chunks, whatever the goroutines drawing them
+	request["parallelism"] = 1
+	serial := srv.CallToolJSON("ab_test_analysis", request)
+	assert.Equal(t, result["variants"], serial["variants"])
+
+	request["control"] = "missing"
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("ab_test_analysis", request))
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("ab_test_analysis", map[string]interface{}{
+		"session_id": "experiment",
+		"problem":    "More conversions than visitors",
+		"variants": []interface{}{
+			map[string]interface{}{"name": "a", "visitors": 10, "conversions": 11},
+			map[string]interface{}{"name": "b", "visitors": 10, "conversions": 1},
+		},
+	}))
+}
+
+func TestCompareStochasticRuns_TabulatesRuns(t *testing.T) {
+	srv := servertest.New(t)
+
+	anneal := func(iterations int) string {
+		result := srv.CallToolJSON("simulated_annealing", map[string]interface{}{
+			"session_id": "compare",
+			"problem":    "Tune the cache",
+			"objective":  "pow(size - 3, 2)",
+			"variables":  []interface{}{map[string]interface{}{"name": "size", "min": 0, "max": 10}},
+			"iterations": iterations,
+			"start":      map[string]interface{}{"size": 10},
+			"seed":       4,
+		})
+		return result["algorithm_id"].(string)
+	}
+	short, long := anneal(5), anneal(2000)
+	simulation := srv.CallToolJSON("monte_carlo_simulation", map[string]interface{}{
+		"session_id": "compare",
+		"problem":    "Cache hit rate",
+		"output":     "hits",
+		"variables":  []interface{}{map[string]interface{}{"name": "hits", "distribution": "normal", "mean": 0.8, "std_dev": 0.05}},
+		"seed":       4,
+	})["algorithm_id"].(string)
+
+	result := srv.CallToolJSON("compare_stochastic_runs", map[string]interface{}{
+		"session_id":    "compare",
+		"algorithm_ids": []interface{}{short, long, simulation},
+		"goal":          "minimize",
+	})
+	runs := result["runs"].([]interface{})
+	require.Len(t, runs, 3)
+	assert.Equal(t, short, runs[0].(map[string]interface{})["algorithm_id"])
+	assert.Equal(t, "simulated_annealing", runs[1].(map[string]interface{})["algorithm"])
+	assert.Equal(t, long, result["best_value"])
+	assert.Equal(t, short, result["fastest"], "the fewest iterations")
+	table := result["table"].(string)
+	assert.Contains(t, table, "| Run | Algorithm | Value |")
+	assert.Contains(t, table, "best value")
+	assert.NotEmpty(t, result["visual_id"])
+	srv.AssertRecordCount("compare", storage.KindVisualData, 1)
+
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("compare_stochastic_runs", map[string]interface{}{
+		"session_id":    "compare",
+		"algorithm_ids": []interface{}{short, "missing"},
+	}))
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("compare_stochastic_runs", map[string]interface{}{
+		"session_id":    "compare",
+		"algorithm_ids": []interface{}{short, short},
+	}))
+}
+
+func TestParameterSweep_FindsTheBestConfiguration(t *testing.T) {
+	srv := servertest.New(t)
+
+	// Annealing with tiny steps cannot leave the start in few iterations
+	annealing := map[string]interface{}{
+		"objective":  "pow(size - 3, 2)",
+		"variables":  []interface{}{map[string]interface{}{"name": "size", "min": 0, "max": 10}},
+		"iterations": 300,
+		"start":      map[string]interface{}{"size": 10},
+	}
+	result := srv.CallToolJSON("parameter_sweep", map[string]interface{}{
+		"session_id": "sweep",
+		"problem":    "Which step size anneals best",
+		"algorithm":  "annealing",
+		"request":    annealing,
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "step_size", "values": []interface{}{0.0001, 0.1}},
+			map[string]interface{}{"name": "schedule", "values": []interface{}{"exponential", "linear"}},
+		},
+		"parallelism": 2,
+		"seed":        3,
+	})
+	assert.Equal(t, "minimize", result["goal"])
+	assert.Equal(t, 4.0, result["configurations"])
+	assert.Len(t, result["results"], 4)
+	best := result["best"].(map[string]interface{})
+	assert.Equal(t, 0.1, best["configuration"].(map[string]interface{})["step_size"])
+	assert.Less(t, best["value"].(float64), 0.1)
+	// Only the sweep is recorded, not its runs
+	srv.AssertRecordCount("sweep", storage.KindStochasticAlgorithms, 1)
+
+	// Random samples are reproducible whatever the parallelism
+	sweep := func(parallelism int) interface{} {
+		return srv.CallToolJSON("parameter_sweep", map[string]interface{}{
+			"session_id": "sweep",
+			"problem":    "Tune the discount",
+			"algorithm":  "mdp",
+			"request": map[string]interface{}{"states": 2, "actions": []interface{}{"stay", "go"}, "transitions": []interface{}{
+				map[string]interface{}{"state": "0", "action": "go", "next_state": "1", "probability": 1, "reward": 1},
+				map[string]interface{}{"state": "0", "action": "stay", "next_state": "0", "probability": 1},
+				map[string]interface{}{"state": "1", "action": "go", "next_state": "0", "probability": 1},
+				map[string]interface{}{"state": "1", "action": "stay", "next_state": "1", "probability": 1, "reward": 0.5},
+			}},
+			"parameters":  []interface{}{map[string]interface{}{"name": "gamma", "min": 0.5, "max": 0.99}},
+			"mode":        "random",
+			"samples":     6,
+			"metric":      "convergence.iterations",
+			"goal":        "minimize",
+			"parallelism": parallelism,
+			"seed":        9,
+		})["results"]
+	}
+	assert.Equal(t, sweep(1), sweep(4))
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("parameter_sweep", map[string]interface{}{
+		"session_id": "sweep",
+		"problem":    "Unknown algorithm",
+		"algorithm":  "genetic",
+		"request":    map[string]interface{}{},
+		"parameters": []interface{}{map[string]interface{}{"name": "rate", "values": []interface{}{1}}},
+	}))
+}
+
+func TestStochasticSensitivity_RanksParametersBySwing(t *testing.T) {
+	srv := servertest.New(t)
+
+	solved := srv.CallToolJSON("solve_mdp", map[string]interface{}{
+		"session_id": "sensitivity",
+		"problem":    "How much does patience matter",
+		"gamma":      0.9,
+		"transitions": []interface{}{
+			map[string]interface{}{"state": "0", "action": "go", "next_state": "1", "probability": 1, "reward": 1},
+			map[string]interface{}{"state": "0", "action": "stay", "next_state": "0", "probability": 1},
+			map[string]interface{}{"state": "1", "action": "go", "next_state": "0", "probability": 1},
+			map[string]interface{}{"state": "1", "action": "stay", "next_state": "1", "probability": 1, "reward": 0.5},
+		},
+	})
+	run := solved["algorithm_id"].(string)
+
+	result := srv.CallToolJSON("stochastic_sensitivity", map[string]interface{}{
+		"session_id":   "sensitivity",
+		"algorithm_id": run,
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "tolerance"},
+			map[string]interface{}{"name": "gamma"},
+			map[string]interface{}{"name": "method", "values": []interface{}{"policy_iteration"}},
+		},
+		"metric": "value_function.0",
+	})
+	assert.Equal(t, run, result["run"])
+	assert.Equal(t, "mdp", result["algorithm"])
+	// Going then staying is worth 1 + 0.9 * 0.5 / 0.1
+	base := result["base"].(map[string]interface{})["value"].(float64)
+	assert.InDelta(t, 5.5, base, 1e-4)
+
+	sensitivities := result["sensitivities"].([]interface{})
+	require.Len(t, sensitivities, 3)
+	gamma := sensitivities[0].(map[string]interface{})
+	assert.Equal(t, "gamma", gamma["name"])
+	assert.Equal(t, 0.9, gamma["base"])
+	tried := gamma["results"].([]interface{})
+	require.Len(t, tried, 2)
+	assert.InDelta(t, 0.81, tried[0].(map[string]interface{})["configuration"].(map[string]interface{})["gamma"], 1e-9)
+	assert.Less(t, gamma["low"], base)
+	assert.Greater(t, gamma["high"], base)
+	assert.Greater(t, gamma["swing"], sensitivities[1].(map[string]interface{})["swing"])
+	assert.Contains(t, result["chart"], "| gamma | 0.9 |")
+	// Only the analysis is recorded besides the run, not its re-runs
+	srv.AssertRecordCount("sensitivity", storage.KindStochasticAlgorithms, 2)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("stochastic_sensitivity", map[string]interface{}{
+		"session_id":   "sensitivity",
+		"algorithm_id": run,
+		"parameters":   []interface{}{map[string]interface{}{"name": "learning_rate"}},
+	}))
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("stochastic_sensitivity", map[string]interface{}{
+		"session_id":   "sensitivity",
+		"algorithm_id": "missing",
+		"parameters":   []interface{}{map[string]interface{}{"name": "gamma"}},
+	}))
+}
+
+func TestStochasticTrace_RecordsVisualData(t *testing.T) {
+	srv := servertest.New(t)
+
+	solved := srv.CallToolJSON("solve_mdp", map[string]interface{}{
+		"session_id": "trace",
+		"problem":    "Plot the value iteration",
+		"gamma":      0.9,
+		"trace":      true,
+		"transitions": []interface{}{
+			map[string]interface{}{"state": "0", "action": "go", "next_state": "1", "probability": 1, "reward": 1},
+			map[string]interface{}{"state": "1", "action": "stay", "next_state": "1", "probability": 1, "reward": 0.5},
+		},
+	})
+	searched := srv.CallToolJSON("search_game_tree", map[string]interface{}{
+		"session_id": "trace",
+		"problem":    "Draw the search tree",
+		"root_state": "start",
+		"trace":      true,
+		"seed":       1,
+		"states": []interface{}{
+			map[string]interface{}{"name": "start", "moves": []interface{}{
+				map[string]interface{}{"move": "settle", "next_state": "settled"},
+				map[string]interface{}{"move": "explore", "next_state": "won"},
+			}},
+			map[string]interface{}{"name": "settled", "reward": 0.5},
+			map[string]interface{}{"name": "won", "reward": 1},
+		},
+	})
+	filtered := srv.CallToolJSON("particle_filter", map[string]interface{}{
+		"session_id":        "trace",
+		"problem":           "Show the particles",
+		"variables":         []interface{}{map[string]interface{}{"name": "level", "initial_std_dev": 1, "process_noise": 0.1}},
+		"observation":       "level",
+		"observation_noise": 0.5,
+		"observations":      []interface{}{1.0, 1.2, 0.9},
+		"trace":             true,
+		"seed":              2,
+	})
+	// Runs without trace record none
+	srv.CallToolJSON("play_bandit", map[string]interface{}{
+		"session_id": "trace",
+		"problem":    "Untraced",
+		"arms":       []interface{}{map[string]interface{}{"mean": 0.5}},
+		"seed":       3,
+	})
+	srv.AssertRecordCount("trace", storage.KindVisualData, 3)
+
+	visuals, err := srv.Store.GetVisualData("trace", nil)
+	require.NoError(t, err)
+	require.Len(t, visuals, 3)
+	byType := map[string]*types.VisualData{}
+	for _, visual := range visuals {
+		byType[visual.DiagramType] = visual
+	}
+
+	plot := byType["convergence-plot"]
+	require.NotNil(t, plot)
+	assert.Equal(t, solved["trace_id"], plot.ID)
+	assert.Equal(t, "trace:"+solved["algorithm_id"].(string), plot.DiagramID)
+	residuals := solved["convergence"].(map[string]interface{})["residuals"].([]interface{})
+	require.Len(t, plot.Elements, len(residuals))
+	assert.Equal(t, "point", plot.Elements[0].Type)
+	assert.Equal(t, residuals[0], plot.Elements[0].Properties["residual"])
+
+	tree := byType["search-tree"]
+	require.NotNil(t, tree)
+	assert.Equal(t, searched["trace_id"], tree.ID)
+	assert.Equal(t, "root", tree.Elements[0].ID)
+	assert.Equal(t, "start", tree.Elements[0].Label)
+	edges := 0
+	for _, element := range tree.Elements {
+		if element.Type == "edge" {
+			edges++
+			assert.Equal(t, "root", element.Source)
+			assert.Greater(t, element.Probability, 0.0)
+		}
+	}
+	assert.Equal(t, 2, edges)
+
+	clouds := byType["particle-cloud"]
+	require.NotNil(t, clouds)
+	assert.Equal(t, filtered["trace_id"], clouds.ID)
+	assert.Equal(t, "step-1", clouds.Elements[0].ID)
+	assert.Len(t, clouds.Elements[0].Contains, 50)
+	assert.Len(t, clouds.Elements, 3*51)
+	assert.Contains(t, clouds.Elements[1].Properties, "level")
+}
+
+func TestListAlgorithms_DescribesRegisteredAlgorithms(t *testing.T) {
+	srv := servertest.New(t)
+
+	result := srv.CallToolJSON("list_algorithms", map[string]interface{}{})
+	algorithms := result["algorithms"].([]interface{})
+	assert.Equal(t, float64(len(algorithms)), result["count"])
+	tools := map[string]map[string]interface{}{}
+	for _, a := range algorithms {
+		algorithm := a.(map[string]interface{})
+		tools[algorithm["tool"].(string)] = algorithm
+		// Every registered algorithm is served as a tool
+		assert.Contains(t, srv.ToolNames(), algorithm["tool"])
+	}
+
+	mdp := tools["solve_mdp"]
+	require.NotNil(t, mdp)
+	assert.Equal(t, "mdp", mdp["name"])
+	assert.Equal(t, "/api/v1/stochastic/mdp", mdp["route"])
+	assert.Equal(t, true, mdp["streams"])
+	parameters := mdp["parameters"].(map[string]interface{})
+	assert.Contains(t, parameters["properties"], "transitions")
+	assert.Contains(t, parameters["required"], "session_id")
+	assert.Equal(t, false, tools["bootstrap_analysis"]["streams"])
+
+	// Algorithms reach MCP by registering alone
+	played := srv.CallToolJSON("play_bandit", map[string]interface{}{
+		"session_id": "registry",
+		"problem":    "Pick a subject line",
+		"arms":       []interface{}{map[string]interface{}{"mean": 0.1}, map[string]interface{}{"mean": 0.9}},
+		"steps":      500,
+		"seed":       3,
+	})
+	assert.Equal(t, 1.0, played["selected_arm"])
+	srv.AssertRecordCount("registry", storage.KindStochasticAlgorithms, 1)
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("hidden_markov_model", map[string]interface{}{
+		"session_id":   "registry",
+		"problem":      "Unknown symbols",
+		"observations": []interface{}{"a"},
+		"symbols":      []interface{}{"b"},
+	}))
+}
+
+func TestStochasticConfidence_ComesFromTheRun(t *testing.T) {
+	srv := servertest.New(t)
+
+	// A run recorded without its result has no confidence to claim
+	recorded := &types.StochasticAlgorithmData{Algorithm: "bandit", Problem: "Pick a subject line"}
+	require.NoError(t, srv.Store.AddStochasticAlgorithm("confidence", recorded))
+
+	played := srv.CallToolJSON("play_bandit", map[string]interface{}{
+		"session_id": "confidence",
+		"problem":    "Pick a subject line",
+		"arms":       []interface{}{map[string]interface{}{"mean": 0.1}, map[string]interface{}{"mean": 0.9}},
+		"steps":      500,
+		"seed":       3,
+	})
+	confidence := played["confidence"].(map[string]interface{})
+	assert.Equal(t, "hoeffding_bound", confidence["method"])
+	assert.Greater(t, confidence["value"], 0.99)
+	assert.NotEmpty(t, confidence["basis"])
+
+	runs, err := srv.Store.GetStochasticAlgorithms("confidence", nil)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Zero(t, runs[0].Confidence)
+	assert.Empty(t, runs[0].ConfidenceMethod)
+	assert.Equal(t, confidence["value"], runs[1].Confidence)
+	assert.Equal(t, "hoeffding_bound", runs[1].ConfidenceMethod)
+
+	// Comparisons say how each confidence was computed
+	compared := srv.CallToolJSON("compare_stochastic_runs", map[string]interface{}{
+		"session_id":    "confidence",
+		"algorithm_ids": []interface{}{recorded.ID, played["algorithm_id"]},
+	})
+	assert.Equal(t, played["algorithm_id"], compared["most_confident"])
+	assert.Contains(t, compared["table"], "(hoeffding_bound)")
+}
+
+func TestGetStochasticResult_ReturnsTheFullTypedData(t *testing.T) {
+	srv := servertest.New(t)
+
+	solved := srv.CallToolJSON("solve_mdp", map[string]interface{}{
+		"session_id": "results",
+		"problem":    "Invest or spend",
+		"gamma":      0.9,
+		"transitions": []interface{}{
+			map[string]interface{}{"state": "idle", "action": "spend", "next_state": "idle", "probability": 1, "reward": 1},
+			map[string]interface{}{"state": "idle", "action": "invest", "next_state": "rich", "probability": 1},
+			map[string]interface{}{"state": "rich", "action": "cash_out", "next_state": "done", "probability": 1, "reward": 20},
+		},
+	})
+	result := srv.CallToolJSON("get_stochastic_result", map[string]interface{}{
+		"session_id":   "results",
+		"algorithm_id": solved["algorithm_id"],
+	})
+	assert.Equal(t, "mdp", result["type"])
+	assert.Equal(t, true, result["complete"])
+	mdp := result["result"].(map[string]interface{})
+	assert.Equal(t, solved["algorithm_id"], mdp["id"])
+	assert.Equal(t, "Invest or spend", mdp["problem"])
+	assert.Equal(t, solved["policy"], mdp["policy"])
+	assert.Equal(t, solved["value_function"], mdp["value_function"])
+	assert.Equal(t, solved["q_values"], mdp["q_values"])
+	assert.Contains(t, mdp, "convergence")
+	assert.NotContains(t, mdp, "payload")
+
+	// A run recorded without its result has only the common fields
+	recorded := &types.StochasticAlgorithmData{Algorithm: "mdp", Problem: "Invest or spend"}
+	require.NoError(t, srv.Store.AddStochasticAlgorithm("results", recorded))
+	result = srv.CallToolJSON("get_stochastic_result", map[string]interface{}{
+		"session_id":   "results",
+		"algorithm_id": recorded.ID,
+	})
+	assert.Equal(t, "mdp", result["type"])
+	assert.Equal(t, false, result["complete"])
+	assert.NotContains(t, result["result"], "policy")
+
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("get_stochastic_result", map[string]interface{}{
+		"session_id":   "elsewhere",
+		"algorithm_id": solved["algorithm_id"],
+	}))
+}
+
+func TestStochasticDominance_FeedsTheDecision(t *testing.T) {
+	srv := servertest.New(t)
+
+	simulate := func(mean, stdDev float64) map[string]interface{} {
+		return srv.CallToolJSON("monte_carlo_simulation", map[string]interface{}{
+			"session_id": "dominance",
+			"problem":    "Yearly return of a fund",
+			"output":     "r",
+			"variables":  []interface{}{map[string]interface{}{"name": "r", "distribution": "normal", "mean": mean, "std_dev": stdDev}},
+			"trials":     5000,
+			"seed":       3,
+		})
+	}
+	safe, risky := simulate(100, 5), simulate(110, 40)
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "dominance",
+		"decision_statement": "Pick a fund",
+		"options": []interface{}{
+			map[string]interface{}{"name": "safe", "description": "Bonds"},
+			map[string]interface{}{"name": "risky", "description": "Equities"},
+		},
+		"analysis_type": "risk",
+		"stage":         "analysis",
+	})
+
+	result := srv.CallToolJSON("stochastic_dominance", map[string]interface{}{
+		"session_id":  "dominance",
+		"problem":     "Bonds or equities",
+		"a":           map[string]interface{}{"name": "safe", "algorithm_id": safe["algorithm_id"]},
+		"b":           map[string]interface{}{"name": "risky", "algorithm_id": risky["algorithm_id"]},
+		"decision_id": decision["decision_id"],
+	})
+
+	// The runs are simulated again with their seeds
+	a, b := result["a"].(map[string]interface{}), result["b"].(map[string]interface{})
+	assert.InDelta(t, safe["mean"].(float64), a["mean"].(float64), 1e-9)
+	assert.InDelta(t, risky["mean"].(float64), b["mean"].(float64), 1e-9)
+	assert.Equal(t, 5000.0, a["samples"])
+
+	// Equities pay more on average but neither fund dominates: the worst 5%
+	// of years average about 100 - 2.06·5 for bonds and 110 - 2.06·40 for
+	// equities
+	assert.NotContains(t, result, "first_order")
+	assert.NotContains(t, result, "second_order")
+	assert.Equal(t, "risky", result["leader"])
+	assert.Greater(t, result["epsilon"].(float64), 0.0)
+	assert.InDelta(t, 89.7, a["cvar"].(float64), 1)
+	assert.InDelta(t, 27.5, b["cvar"].(float64), 4)
+	assert.Contains(t, result["summary"], "Neither safe nor risky dominates")
+	srv.AssertRecordCount("dominance", storage.KindStochasticAlgorithms, 3)
+
+	decisions, err := srv.Store.GetDecisions("dominance", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	options := decisions[0].Options
+	assert.Equal(t, a["mean"], *options[0].ExpectedValue)
+	assert.Equal(t, "low", options[0].RiskLevel)
+	assert.Equal(t, b["mean"], *options[1].ExpectedValue)
+	assert.Equal(t, "high", options[1].RiskLevel)
+	assert.Equal(t, result["recommendation"], decisions[0].Recommendation)
+
+	// The run is not recorded when the decision cannot take it
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("stochastic_dominance", map[string]interface{}{
+		"session_id":  "dominance",
+		"problem":     "Unknown options",
+		"a":           map[string]interface{}{"name": "cash", "samples": []interface{}{1, 1}},
+		"b":           map[string]interface{}{"name": "gold", "samples": []interface{}{0, 3}},
+		"decision_id": decision["decision_id"],
+	}))
+	srv.AssertRecordCount("dominance", storage.KindStochasticAlgorithms, 3)
+
+	// A sure 1 beats an even chance of 0 or 2 for the risk-averse only
+	result = srv.CallToolJSON("stochastic_dominance", map[string]interface{}{
+		"session_id": "dominance",
+		"problem":    "Cash or a coin flip",
+		"a":          map[string]interface{}{"name": "cash", "samples": []interface{}{1, 1}},
+		"b":          map[string]interface{}{"name": "flip", "samples": []interface{}{0, 2}},
+		"alpha":      0.5,
+	})
+	assert.Equal(t, "cash", result["second_order"])
+	assert.NotContains(t, result, "first_order")
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("stochastic_dominance", map[string]interface{}{
+		"session_id": "dominance",
+		"problem":    "Not a simulation",
+		"a":          map[string]interface{}{"algorithm_id": result["algorithm_id"]},
+		"b":          map[string]interface{}{"samples": []interface{}{1}},
+	}))
+}
+
+func TestDecisionFramework_RanksScoredOptions(t *testing.T) {
+	srv := servertest.New(t)
+
+	scores := func(rows map[string][]float64) []interface{} {
+		var scores []interface{}
+		for option, row := range rows {
+			for j, criterion := range []string{"cost", "latency", "features"} {
+				scores = append(scores, map[string]interface{}{"option": option, "criterion": criterion, "score": row[j]})
+			}
+		}
+		return scores
+	}
+	decision := map[string]interface{}{
+		"session_id":         "mcda",
+		"decision_statement": "Pick a database",
+		"options": []interface{}{
+			map[string]interface{}{"name": "postgres"},
+			map[string]interface{}{"name": "mongo"},
+			map[string]interface{}{"name": "sqlite"},
+		},
+		"criteria": []interface{}{
+			map[string]interface{}{"name": "cost", "weight": 0.2, "direction": "cost"},
+			map[string]interface{}{"name": "latency", "weight": 0.3, "direction": "cost"},
+			map[string]interface{}{"name": "features", "weight": 0.5},
+		},
+		"scores": scores(map[string][]float64{
+			"postgres": {3, 4, 9},
+			"mongo":    {4, 3, 6},
+			"sqlite":   {1, 2, 4},
+		}),
+	}
+	result := srv.CallToolJSON("decision_framework", decision)
+	ranking := result["ranking"].([]interface{})
+	require.Len(t, ranking, 3)
+	first := ranking[0].(map[string]interface{})
+	assert.Equal(t, "sqlite", first["option"])
+	assert.Equal(t, 1.0, first["rank"])
+	// 0.2·1 + 0.3·1 + 0.5·4/9, and 0.2·1/3 + 0.3·2/4 + 0.5·9/9 for postgres
+	assert.InDelta(t, 0.5+0.5*4/9, first["score"].(float64), 1e-12)
+	assert.InDelta(t, 0.2/3+0.15+0.5, ranking[1].(map[string]interface{})["score"].(float64), 1e-12)
+	assert.Contains(t, result["recommendation"], "sqlite ranks first with a weighted sum")
+
+	// Re-scoring by a weighted product keeps the recorded scores
+	rescored := srv.CallToolJSON("multi_criteria_analysis", map[string]interface{}{
+		"session_id":     "mcda",
+		"decision_id":    result["decision_id"],
+		"scoring_method": "weighted_product",
+	})
+	assert.Equal(t, "weighted_product", rescored["scoring_method"])
+	ranking = rescored["ranking"].([]interface{})
+	first, second := ranking[0].(map[string]interface{}), ranking[1].(map[string]interface{})
+	assert.Equal(t, "sqlite", first["option"])
+	assert.InDelta(t, math.Pow(4.0/9, 0.5), first["score"].(float64), 1e-12)
+	assert.Equal(t, "postgres", second["option"])
+	assert.InDelta(t, math.Pow(1.0/3, 0.2)*math.Pow(0.5, 0.3), second["score"].(float64), 1e-12)
+
+	decisions, err := srv.Store.GetDecisions("mcda", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "weighted_product", decisions[0].ScoringMethod)
+	assert.Equal(t, rescored["recommendation"], decisions[0].Recommendation)
+
+	// Every option needs a score on every criterion
+	decision["scores"] = decision["scores"].([]interface{})[1:]
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("decision_framework", decision))
+	srv.AssertRecordCount("mcda", storage.KindDecisions, 1)
+}
+
+func TestAHPAnalysis_WeighsCriteriaAndRanksOptions(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "ahp",
+		"decision_statement": "Pick a laptop",
+		"options": []interface{}{
+			map[string]interface{}{"name": "light"},
+			map[string]interface{}{"name": "fast"},
+		},
+		"criteria": []interface{}{
+			map[string]interface{}{"name": "weight"},
+			map[string]interface{}{"name": "speed"},
+			map[string]interface{}{"name": "price", "direction": "cost"},
+		},
+	})
+
+	// Consistent judgments from the weights 0.6, 0.3 and 0.1, giving only
+	// the upper triangles
+	result := srv.CallToolJSON("ahp_analysis", map[string]interface{}{
+		"session_id":      "ahp",
+		"decision_id":     decision["decision_id"],
+		"criteria_matrix": []interface{}{[]interface{}{1, 2, 6}, []interface{}{0, 1, 3}, []interface{}{0, 0, 1}},
+		"option_matrices": []interface{}{
+			map[string]interface{}{"criterion": "weight", "matrix": []interface{}{[]interface{}{1, 4}, []interface{}{0, 1}}},
+			map[string]interface{}{"criterion": "speed", "matrix": []interface{}{[]interface{}{1, 0.25}, []interface{}{0, 1}}},
+			map[string]interface{}{"criterion": "price", "matrix": []interface{}{[]interface{}{1, 1}, []interface{}{1, 1}}},
+		},
+	})
+	criteria := result["criteria"].(map[string]interface{})
+	priorities := criteria["priorities"].(map[string]interface{})
+	assert.InDelta(t, 0.6, priorities["weight"].(float64), 1e-9)
+	assert.InDelta(t, 0.3, priorities["speed"].(float64), 1e-9)
+	assert.InDelta(t, 0.1, priorities["price"].(float64), 1e-9)
+	assert.InDelta(t, 0, criteria["consistency_ratio"].(float64), 1e-9)
+	assert.Equal(t, true, result["consistent"])
+	assert.Len(t, result["options"], 3)
+
+	// light scores 0.6·0.8 + 0.3·0.2 + 0.1·0.5 by the weighted priorities
+	ranking := result["ranking"].([]interface{})
+	first := ranking[0].(map[string]interface{})
+	assert.Equal(t, "light", first["option"])
+	assert.InDelta(t, 0.59, first["score"].(float64), 1e-9)
+	assert.InDelta(t, 0.41, ranking[1].(map[string]interface{})["score"].(float64), 1e-9)
+
+	decisions, err := srv.Store.GetDecisions("ahp", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.InDelta(t, 0.6, decisions[0].Criteria[0].Weight, 1e-9)
+	assert.Len(t, decisions[0].Scores, 6)
+	assert.Equal(t, result["recommendation"], decisions[0].Recommendation)
+
+	// Inconsistent judgments are stored but flagged
+	result = srv.CallToolJSON("ahp_analysis", map[string]interface{}{
+		"session_id":      "ahp",
+		"decision_id":     decision["decision_id"],
+		"criteria_matrix": []interface{}{[]interface{}{1, 9, 1.0 / 9}, []interface{}{0, 1, 9}, []interface{}{0, 0, 1}},
+	})
+	assert.Equal(t, false, result["consistent"])
+	assert.Greater(t, result["criteria"].(map[string]interface{})["consistency_ratio"].(float64), 0.1)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("ahp_analysis", map[string]interface{}{
+		"session_id":      "ahp",
+		"decision_id":     decision["decision_id"],
+		"criteria_matrix": []interface{}{[]interface{}{1, 2}, []interface{}{0.5, 1}},
+	}))
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("ahp_analysis", map[string]interface{}{
+		"session_id":      "ahp",
+		"decision_id":     decision["decision_id"],
+		"criteria_matrix": []interface{}{[]interface{}{1, 2, 6}, []interface{}{0, 1, 3}, []interface{}{0, 0, 1}},
+		"option_matrices": []interface{}{
+			map[string]interface{}{"criterion": "weight", "matrix": []interface{}{[]interface{}{1, 4}, []interface{}{0, 1}}},
+		},
+	}))
+}
+
+func TestDecisionFramework_RanksByTOPSIS(t *testing.T) {
+	srv := servertest.New(t)
+
+	var scores []interface{}
+	for option, row := range map[string][]float64{"postgres": {3, 4, 9}, "mongo": {4, 3, 6}, "sqlite": {1, 2, 4}} {
+		for j, criterion := range []string{"cost", "latency", "features"} {
+			scores = append(scores, map[string]interface{}{"option": option, "criterion": criterion, "score": row[j]})
+		}
+	}
+	decision := map[string]interface{}{
+		"session_id":         "topsis",
+		"decision_statement": "Pick a database",
+		"analysis_type":      "topsis",
+		"options": []interface{}{
+			map[string]interface{}{"name": "postgres"},
+			map[string]interface{}{"name": "mongo"},
+			map[string]interface{}{"name": "sqlite"},
+		},
+		"criteria": []interface{}{
+			map[string]interface{}{"name": "cost", "weight": 0.2, "direction": "cost"},
+			map[string]interface{}{"name": "latency", "weight": 0.3, "direction": "cost"},
+			map[string]interface{}{"name": "features", "weight": 0.5},
+		},
+		"scores": scores,
+	}
+	result := srv.CallToolJSON("decision_framework", decision)
+
+	// Unlike the weighted sum, which favors sqlite, closeness to the ideal
+	// favors postgres's far richer features
+	ranking := result["ranking"].([]interface{})
+	require.Len(t, ranking, 3)
+	for i, want := range []struct {
+		option                      string
+		ideal, antiIdeal, closeness float64
+	}{
+		{"postgres", 0.1362631251, 0.2202973961, 0.6178401226},
+		{"sqlite", 0.2167774924, 0.1620492115, 0.4277660731},
+		{"mongo", 0.1840296046, 0.1030642774, 0.3589915489},
+	} {
+		ranked := ranking[i].(map[string]interface{})
+		assert.Equal(t, want.option, ranked["option"])
+		assert.InDelta(t, want.ideal, ranked["ideal_distance"].(float64), 1e-9)
+		assert.InDelta(t, want.antiIdeal, ranked["anti_ideal_distance"].(float64), 1e-9)
+		assert.InDelta(t, want.closeness, ranked["score"].(float64), 1e-9)
+	}
+	assert.Contains(t, result["recommendation"], "postgres ranks first with a TOPSIS closeness coefficient of 0.6178")
+	assert.Contains(t, result["recommendation"], "(vector normalization)")
+
+	decisions, err := srv.Store.GetDecisions("topsis", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "topsis", decisions[0].ScoringMethod)
+	assert.Equal(t, "vector", decisions[0].Normalization)
+
+	// A topsis analysis has nothing to rank without scores
+	delete(decision, "scores")
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("decision_framework", decision))
+	srv.AssertRecordCount("topsis", "decisions", 1)
+}
+
+func TestDecisionTreeAnalysis_FindsTheOptimalStrategy(t *testing.T) {
+	srv := servertest.New(t)
+
+	node := func(id, parent, typ, label string, probability, payoff float64) map[string]interface{} {
+		return map[string]interface{}{"id": id, "parent": parent, "type": typ, "label": label, "probability": probability, "payoff": payoff}
+	}
+	// Launch now, or run a pilot first that is promising with probability
+	// 0.5 and after which a launch succeeds with probability 0.8
+	result := srv.CallToolJSON("decision_tree_analysis", map[string]interface{}{
+		"session_id": "tree",
+		"title":      "Launch the product?",
+		"nodes": []interface{}{
+			node("root", "", "decision", "", 0, 0),
+			node("launch", "root", "chance", "Launch now", 0, -50),
+			node("hit", "launch", "terminal", "Hit", 0.5, 150),
+			node("flop", "launch", "terminal", "Flop", 0.5, 0),
+			node("pilot", "root", "chance", "Pilot first", 0, -5),
+			node("promising", "pilot", "decision", "Promising", 0.5, 0),
+			node("launch+", "promising", "chance", "Launch", 0, -50),
+			node("hit+", "launch+", "terminal", "Hit", 0.8, 150),
+			node("flop+", "launch+", "terminal", "Flop", 0.2, 0),
+			node("shelve+", "promising", "terminal", "Shelve", 0, 0),
+			node("poor", "pilot", "terminal", "Poor", 0.5, 0),
+		},
+	})
+
+	// Launching now is worth 0.5·150 - 50; piloting 0.5·(0.8·150 - 50) - 5
+	assert.InDelta(t, 30, result["expected_value"].(float64), 1e-9)
+	assert.Equal(t, []interface{}{"root", "pilot"}, result["path"])
+	strategy := result["strategy"].([]interface{})
+	require.Len(t, strategy, 2)
+	assert.Equal(t, "pilot", strategy[0].(map[string]interface{})["choice"])
+	assert.Equal(t, "launch+", strategy[1].(map[string]interface{})["choice"])
+	assert.InDelta(t, 0.5, strategy[1].(map[string]interface{})["reach_probability"].(float64), 1e-12)
+	outcomes := result["outcomes"].([]interface{})
+	require.Len(t, outcomes, 3)
+	assert.Equal(t, "poor", outcomes[0].(map[string]interface{})["node"])
+	assert.InDelta(t, 95, outcomes[1].(map[string]interface{})["payoff"].(float64), 1e-9)
+	assert.Equal(t, "Choose Pilot first at root, then Launch at Promising, for an expected value of 30 over 3 outcomes", result["summary"])
+
+	// The evaluated tree is recorded for rendering
+	visuals, err := srv.Store.GetVisualData("tree", nil)
+	require.NoError(t, err)
+	require.Len(t, visuals, 1)
+	assert.Equal(t, result["visual_id"], visuals[0].ID)
+	assert.Equal(t, "decision-tree", visuals[0].DiagramType)
+	assert.Equal(t, "Launch the product?", visuals[0].Observation)
+	assert.Len(t, visuals[0].Elements, 21)
+	for _, element := range visuals[0].Elements {
+		if element.ID == "launch+->hit+" {
+			assert.Equal(t, 0.8, element.Probability)
+			assert.Equal(t, true, element.Properties["optimal"])
+		}
+		if element.ID == "root->launch" {
+			assert.Equal(t, false, element.Properties["optimal"])
+		}
+	}
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("decision_tree_analysis", map[string]interface{}{
+		"session_id": "tree",
+		"nodes": []interface{}{
+			node("root", "", "chance", "", 0, 0),
+			node("a", "root", "terminal", "", 0.5, 1),
+			node("b", "root", "terminal", "", 0.4, 1),
+		},
+	}))
+}
+
+func TestSimulateDecision_EstimatesTheChanceOfBeingBest(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "futures",
+		"decision_statement": "Pick a fund",
+		"options": []interface{}{
+			map[string]interface{}{"name": "safe", "description": "Bonds", "outcome": map[string]interface{}{"distribution": "normal", "mean": 100, "std_dev": 5}},
+			map[string]interface{}{"name": "risky", "description": "Equities", "outcome": map[string]interface{}{"distribution": "normal", "mean": 110, "std_dev": 40}},
+			map[string]interface{}{"name": "cash", "description": "Savings"},
+		},
+		"analysis_type": "risk",
+		"stage":         "analysis",
+	})
+	request := map[string]interface{}{
+		"session_id":  "futures",
+		"decision_id": decision["decision_id"],
+		"outcomes": []interface{}{
+			map[string]interface{}{"option": "cash", "outcome": map[string]interface{}{"distribution": "discrete", "values": []float64{90}}},
+		},
+		"futures": 20000,
+		"seed":    11,
+	}
+	result := srv.CallToolJSON("simulate_decision", request)
+
+	// Equities beat bonds when their difference, normal with mean 10 and
+	// standard deviation √1625, is positive; cash at 90 almost never wins
+	simulation := result["simulation"].(map[string]interface{})
+	options := simulation["options"].([]interface{})
+	require.Len(t, options, 3)
+	risky, safe := options[0].(map[string]interface{}), options[1].(map[string]interface{})
+	assert.Equal(t, "risky", risky["option"])
+	assert.InDelta(t, 0.598, risky["probability_best"].(float64), 0.02)
+	assert.InDelta(t, 110, risky["expected_value"].(float64), 1)
+	assert.InDelta(t, 110-1.645*40, risky["p5"].(float64), 3)
+	assert.InDelta(t, 110+1.645*40, risky["p95"].(float64), 3)
+	assert.InDelta(t, 110-2.063*40, risky["cvar"].(float64), 4)
+	assert.Equal(t, "safe", safe["option"])
+	assert.InDelta(t, 100-2.063*5, safe["cvar"].(float64), 1)
+	total := 0.0
+	for _, option := range options {
+		total += option.(map[string]interface{})["probability_best"].(float64)
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+	assert.Contains(t, simulation["recommendation"], "risky is best in")
+
+	// The same seed simulates the same futures
+	assert.Equal(t, result, srv.CallToolJSON("simulate_decision", request))
+
+	// The simulation and the distributions are kept on the decision
+	decisions, err := srv.Store.GetDecisions("futures", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	require.NotNil(t, decisions[0].Simulation)
+	assert.Equal(t, 20000, decisions[0].Simulation.Futures)
+	require.NotNil(t, decisions[0].Options[2].Outcome)
+	assert.Equal(t, "discrete", decisions[0].Options[2].Outcome.Distribution)
+	assert.InDelta(t, 90, *decisions[0].Options[2].ExpectedValue, 1e-9)
+
+	other := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "futures",
+		"decision_statement": "Pick a bank",
+		"options":            []interface{}{map[string]interface{}{"name": "local", "description": "Local bank"}},
+		"analysis_type":      "risk",
+		"stage":              "analysis",
+	})
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("simulate_decision", map[string]interface{}{
+		"session_id":  "futures",
+		"decision_id": other["decision_id"],
+	}))
+}
+
+func TestStakeholderAnalysis_WeighsOptionsBySupport(t *testing.T) {
+	srv := servertest.New(t)
+
+	profile := func(name string, influence, interest float64, support map[string]float64) map[string]interface{} {
+		var preferences []interface{}
+		for option, s := range support {
+			preferences = append(preferences, map[string]interface{}{"option": option, "support": s})
+		}
+		return map[string]interface{}{"name": name, "influence": influence, "interest": interest, "preferences": preferences}
+	}
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "stakeholders",
+		"decision_statement": "Build or buy the billing system",
+		"options": []interface{}{
+			map[string]interface{}{"name": "build", "description": "In house"},
+			map[string]interface{}{"name": "buy", "description": "Vendor"},
+		},
+		"stakeholders": []string{"legal"},
+		"stakeholder_profiles": []interface{}{
+			profile("engineering", 0.8, 1, map[string]float64{"build": 1}),
+		},
+	})
+	assert.Equal(t, "build has the most stakeholder support, a weighted score of 1 from -1 to 1, backed by engineering and opposed by no one; manage engineering closely", decision["recommendation"])
+	require.Contains(t, decision, "stakeholder_analysis")
+
+	result := srv.CallToolJSON("stakeholder_analysis", map[string]interface{}{
+		"session_id":  "stakeholders",
+		"decision_id": decision["decision_id"],
+		"stakeholders": []interface{}{
+			profile("engineering", 0.8, 1, map[string]float64{"build": 1, "buy": -0.5}),
+			profile("finance", 0.5, 0.8, map[string]float64{"buy": 1, "build": -1}),
+			profile("sales", 0.4, 0.5, map[string]float64{"buy": 0.5}),
+		},
+	})
+
+	// Weights are 0.8, 0.4 and 0.2 of 1.4
+	analysis := result["analysis"].(map[string]interface{})
+	grid := analysis["grid"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"engineering", "finance"}, grid["manage_closely"])
+	assert.Equal(t, []interface{}{"sales"}, grid["keep_informed"])
+	options := analysis["options"].([]interface{})
+	require.Len(t, options, 2)
+	build, buy := options[0].(map[string]interface{}), options[1].(map[string]interface{})
+	assert.Equal(t, "build", build["option"])
+	assert.InDelta(t, 0.4/1.4, build["score"].(float64), 1e-12)
+	assert.Equal(t, []interface{}{"finance"}, build["opponents"])
+	assert.InDelta(t, 0.1/1.4, buy["score"].(float64), 1e-12)
+	assert.Equal(t, []interface{}{"finance", "sales"}, buy["supporters"])
+	stakeholders := analysis["stakeholders"].([]interface{})
+	assert.InDelta(t, 0.8/1.4, stakeholders[0].(map[string]interface{})["weight"].(float64), 1e-12)
+
+	// The profiles and the analysis are kept on the decision, and the
+	// profiled stakeholders named among its stakeholders
+	decisions, err := srv.Store.GetDecisions("stakeholders", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, []string{"legal", "engineering", "finance", "sales"}, decisions[0].Stakeholders)
+	assert.Len(t, decisions[0].StakeholderProfiles, 3)
+	require.NotNil(t, decisions[0].StakeholderAnalysis)
+	assert.Equal(t, analysis["recommendation"], decisions[0].Recommendation)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("stakeholder_analysis", map[string]interface{}{
+		"session_id":   "stakeholders",
+		"decision_id":  decision["decision_id"],
+		"stakeholders": []interface{}{profile("legal", 0.5, 0.5, map[string]float64{"lease": 1})},
+	}))
+}
+
+func TestScoreDecisionOption_AccumulatesTheDecisionMatrix(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "matrix",
+		"decision_statement": "Choose a vendor",
+		"options": []interface{}{
+			map[string]interface{}{"name": "acme", "description": "Acme"},
+			map[string]interface{}{"name": "globex", "description": "Globex"},
+		},
+		"criteria": []interface{}{
+			map[string]interface{}{"name": "price", "weight": 0.5, "direction": "cost"},
+			map[string]interface{}{"name": "support", "weight": 0.5},
+		},
+	})
+	score := func(option, criterion string, value interface{}, rating string) map[string]interface{} {
+		request := map[string]interface{}{
+			"session_id":  "matrix",
+			"decision_id": decision["decision_id"],
+			"option":      option,
+			"criterion":   criterion,
+			"rationale":   option + " on " + criterion,
+		}
+		if value != nil {
+			request["score"] = value
+		}
+		if rating != "" {
+			request["rating"] = rating
+		}
+		return srv.CallToolJSON("score_decision_option", request)
+	}
+
+	result := score("acme", "price", 100, "")
+	assert.Equal(t, false, result["complete"])
+	assert.Equal(t, []interface{}{"acme on support", "globex on price", "globex on support"}, result["missing"])
+	assert.NotContains(t, result, "ranking")
+
+	// A rating on a cost criterion counts down, so that good stays good
+	result = score("globex", "price", nil, "good")
+	matrix := result["matrix"].([]interface{})
+	require.Len(t, matrix, 2)
+	assert.Equal(t, 2.0, matrix[1].(map[string]interface{})["score"])
+	assert.Equal(t, "good", matrix[1].(map[string]interface{})["rating"])
+
+	// Scoring a pair again replaces its score
+	score("globex", "price", 80, "")
+	score("acme", "support", nil, "excellent")
+	result = score("globex", "support", 0, "")
+	assert.Equal(t, true, result["complete"])
+	assert.NotContains(t, result, "missing")
+	assert.Len(t, result["matrix"], 4)
+	ranking := result["ranking"].([]interface{})
+	require.Len(t, ranking, 2)
+	assert.Equal(t, "acme", ranking[0].(map[string]interface{})["option"])
+
+	decisions, err := srv.Store.GetDecisions("matrix", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, 80.0, decisions[0].Scores[1].Score)
+	assert.Empty(t, decisions[0].Scores[1].Rating)
+	assert.Equal(t, "acme on support", decisions[0].Scores[2].Rationale)
+	assert.Equal(t, 5.0, decisions[0].Scores[2].Score)
+	assert.Equal(t, result["recommendation"], decisions[0].Recommendation)
+
+	// The analyses rank by the accumulated matrix
+	ranked := srv.CallToolJSON("multi_criteria_analysis", map[string]interface{}{
+		"session_id":     "matrix",
+		"decision_id":    decision["decision_id"],
+		"scoring_method": "topsis",
+	})
+	assert.Equal(t, "acme", ranked["ranking"].([]interface{})[0].(map[string]interface{})["option"])
+
+	for _, request := range []map[string]interface{}{
+		{"option": "acme", "criterion": "price"},
+		{"option": "acme", "criterion": "price", "score": 1, "rating": "fair"},
+		{"option": "initech", "criterion": "price", "score": 1},
+		{"option": "acme", "criterion": "uptime", "score": 1},
+	} {
+		request["session_id"], request["decision_id"] = "matrix", decision["decision_id"]
+		assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("score_decision_option", request))
+	}
+}
+
+func TestGroupDecision_AggregatesEvaluators(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "group",
+		"decision_statement": "Pick the offsite venue",
+		"options": []interface{}{
+			map[string]interface{}{"name": "lake", "description": "Lakeside lodge"},
+			map[string]interface{}{"name": "city", "description": "City hotel"},
+			map[string]interface{}{"name": "farm", "description": "Farm retreat"},
+		},
+	})
+	evaluation := func(evaluator string, lake, city, farm float64) map[string]interface{} {
+		return map[string]interface{}{"evaluator": evaluator, "scores": []interface{}{
+			map[string]interface{}{"option": "lake", "score": lake},
+			map[string]interface{}{"option": "city", "score": city},
+			map[string]interface{}{"option": "farm", "score": farm},
+		}}
+	}
+	aggregate := func(method string, evaluations ...interface{}) map[string]interface{} {
+		request := map[string]interface{}{"session_id": "group", "decision_id": decision["decision_id"]}
+		if method != "" {
+			request["method"] = method
+		}
+		if len(evaluations) > 0 {
+			request["evaluations"] = evaluations
+		}
+		return srv.CallToolJSON("group_decision", request)["aggregation"].(map[string]interface{})
+	}
+
+	aggregate("", evaluation("ann", 9, 5, 1), evaluation("bob", 9, 5, 1), evaluation("cyd", 9, 5, 1), evaluation("dee", 1, 1, 1))
+	// dee's evaluation is replaced; three evaluators rank lake > city > farm
+	// and two city > farm > lake
+	result := aggregate("", evaluation("dee", 0, 10, 8), evaluation("eve", 0, 10, 8))
+	assert.Equal(t, "borda", result["method"])
+	assert.Equal(t, 5.0, result["evaluators"])
+	first := result["ranking"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "city", first["option"])
+	assert.Equal(t, 7.0, first["score"])
+	assert.Equal(t, "lake", result["condorcet_winner"])
+	assert.Equal(t, []interface{}{[]interface{}{0.0, 3.0, 3.0}, []interface{}{2.0, 0.0, 5.0}, []interface{}{2.0, 0.0, 0.0}}, result["pairwise"])
+	lake := result["options"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 2.0, lake["copeland_score"])
+	assert.InDelta(t, 5.4, lake["range_score"].(float64), 1e-12)
+	assert.Greater(t, lake["rank_spread"].(float64), 0.9)
+	conflict := result["conflicts"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "ann", conflict["evaluator_a"])
+	assert.Equal(t, "dee", conflict["evaluator_b"])
+	assert.Less(t, conflict["correlation"].(float64), 0.0)
+	assert.Regexp(t, `^By Borda count, city ranks first with 7 points from 5 evaluators; lake is the Condorcet winner; the evaluators' disagreement is 0\.\d\d, ann and dee conflicting most \(correlation -0\.50\)$`, result["recommendation"])
+
+	result = aggregate("condorcet")
+	assert.Equal(t, "lake", result["ranking"].([]interface{})[0].(map[string]interface{})["option"])
+
+	decisions, err := srv.Store.GetDecisions("group", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Len(t, decisions[0].Evaluations, 5)
+	require.NotNil(t, decisions[0].GroupAggregation)
+	assert.Equal(t, "condorcet", decisions[0].GroupAggregation.Method)
+	assert.Equal(t, result["recommendation"], decisions[0].Recommendation)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("group_decision", map[string]interface{}{
+		"session_id":  "group",
+		"decision_id": decision["decision_id"],
+		"evaluations": []interface{}{map[string]interface{}{"evaluator": "fay", "scores": []interface{}{map[string]interface{}{"option": "lake", "score": 1}}}},
+	}))
+}
+
+func TestScenarioAnalysis_SeparatesRobustOptionsFromBets(t *testing.T) {
+	srv := servertest.New(t)
+
+	outcomes := func(option string, boom, base, bust float64) []interface{} {
+		return []interface{}{
+			map[string]interface{}{"option": option, "scenario": "boom", "value": boom},
+			map[string]interface{}{"option": option, "scenario": "base", "value": base},
+			map[string]interface{}{"option": option, "scenario": "bust", "value": bust},
+		}
+	}
+	var all []interface{}
+	all = append(all, outcomes("expand", 100, 40, -50)...)
+	all = append(all, outcomes("steady", 60, 45, 10)...)
+	all = append(all, outcomes("hold", 20, 20, 20)...)
+	request := map[string]interface{}{
+		"session_id": "futures",
+		"title":      "How hard to invest next year",
+		"scenarios": []interface{}{
+			map[string]interface{}{"name": "boom", "probability": 0.3, "description": "Demand doubles"},
+			map[string]interface{}{"name": "base", "probability": 0.5},
+			map[string]interface{}{"name": "bust", "probability": 0.2},
+		},
+		"options":  []interface{}{"expand", "steady", "hold"},
+		"outcomes": all,
+	}
+
+	response := srv.CallToolJSON("scenario_analysis", request)
+	analysis := response["analysis"].(map[string]interface{})
+	assert.Equal(t, 0.5, analysis["tolerance"])
+	assert.Equal(t, []interface{}{"steady"}, analysis["robust_options"])
+	assert.Equal(t, []interface{}{"expand", "hold"}, analysis["bets"])
+	steady := analysis["evaluations"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 1.0, steady["rank"])
+	assert.Equal(t, "robust", steady["class"])
+	assert.InDelta(t, 42.5, steady["expected_value"].(float64), 1e-9)
+	assert.Equal(t, 40.0, steady["max_regret"])
+	assert.Equal(t, "boom", steady["weakest_in"])
+	assert.Equal(t, "steady is robust, never more than 50% of the way from a scenario's best outcome to its worst, with an expected value of 42.5; "+
+		"expand is a bet on boom, weakest under bust; hold is a bet on bust, weakest under boom", analysis["recommendation"])
+
+	scenarios, err := srv.Store.GetScenarios("futures", nil)
+	require.NoError(t, err)
+	require.Len(t, scenarios, 1)
+	assert.Equal(t, response["scenario_id"], scenarios[0].ID)
+	assert.Equal(t, "Demand doubles", scenarios[0].Scenarios[0].Description)
+	assert.Len(t, scenarios[0].Outcomes, 9)
+
+	// A tighter tolerance leaves no option robust
+	request["tolerance"] = 0.4
+	analysis = srv.CallToolJSON("scenario_analysis", request)["analysis"].(map[string]interface{})
+	assert.Nil(t, analysis["robust_options"])
+	assert.Regexp(t, `^No option is robust at a tolerance of 40%; steady has both the least maximum regret, 40, and the highest expected value, 42\.5; steady is a bet on base`, analysis["recommendation"])
+
+	request["outcomes"] = all[1:]
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("scenario_analysis", request))
+}
+
+func TestCompareDecisions_LaysDecisionsSideBySide(t *testing.T) {
+	srv := servertest.New(t)
+
+	option := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "description": name}
+	}
+	criterion := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "description": name, "weight": 0.5}
+	}
+	score := func(option, criterion string, score float64) map[string]interface{} {
+		return map[string]interface{}{"option": option, "criterion": criterion, "score": score}
+	}
+	first := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "q1",
+		"decision_statement": "Pick a supplier for Q1",
+		"options":            []interface{}{option("acme"), option("globex")},
+		"criteria":           []interface{}{criterion("quality")},
+		"scores":             []interface{}{score("acme", "quality", 8), score("globex", "quality", 6)},
+	})
+	second := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "q2",
+		"decision_statement": "Pick a supplier for Q2",
+		"options":            []interface{}{option("acme"), option("globex"), option("initech")},
+		"criteria":           []interface{}{criterion("quality"), criterion("speed")},
+		"scores": []interface{}{
+			score("acme", "quality", 5), score("acme", "speed", 5),
+			score("globex", "quality", 9), score("globex", "speed", 7),
+			score("initech", "quality", 4), score("initech", "speed", 4),
+		},
+	})
+
+	result := srv.CallToolJSON("compare_decisions", map[string]interface{}{"decisions": []interface{}{
+		map[string]interface{}{"session_id": "q1", "decision_id": first["decision_id"]},
+		map[string]interface{}{"session_id": "q2", "decision_id": second["decision_id"]},
+	}})
+	decisions := result["decisions"].([]interface{})
+	require.Len(t, decisions, 2)
+	assert.Equal(t, "Pick a supplier for Q1", decisions[0].(map[string]interface{})["decision_statement"])
+	assert.Equal(t, "q2", decisions[1].(map[string]interface{})["session_id"])
+	assert.Equal(t, "globex", decisions[1].(map[string]interface{})["top_option"])
+	assert.Equal(t, []interface{}{"acme", "globex"}, result["shared_options"])
+	assert.Equal(t, []interface{}{"quality"}, result["shared_criteria"])
+
+	options := result["options"].([]interface{})
+	require.Len(t, options, 3)
+	initech := options[2].(map[string]interface{})
+	assert.Equal(t, []interface{}{false, true}, initech["in"])
+	assert.Equal(t, []interface{}{nil, 3.0}, initech["ranks"])
+	acme := options[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{1.0, 2.0}, acme["ranks"])
+
+	scores := result["scores"].([]interface{})
+	require.Len(t, scores, 6)
+	assert.Equal(t, map[string]interface{}{"option": "acme", "criterion": "quality", "scores": []interface{}{8.0, 5.0}, "gap": 3.0}, scores[0])
+	assert.Equal(t, []interface{}{nil, 5.0}, scores[2].(map[string]interface{})["scores"])
+	assert.Equal(t, "The 2 decisions share 2 of 3 options and 1 of 2 criteria; decision 1 ranks acme first, decision 2 ranks globex first; "+
+		"the widest score gap is acme on quality, 3", result["summary"])
+
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("compare_decisions", map[string]interface{}{"decisions": []interface{}{
+		map[string]interface{}{"session_id": "q1", "decision_id": first["decision_id"]},
+		map[string]interface{}{"session_id": "q1", "decision_id": second["decision_id"]},
+	}}))
+}
+
+func TestDecisionHistory_TracksIterationsOfARevisedDecision(t *testing.T) {
+	srv := servertest.New(t)
+
+	option := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "description": name}
+	}
+	criterion := func(name string, weight float64) map[string]interface{} {
+		return map[string]interface{}{"name": name, "description": name, "weight": weight}
+	}
+	score := func(option, criterion string, score float64) map[string]interface{} {
+		return map[string]interface{}{"option": option, "criterion": criterion, "score": score}
+	}
+	first := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Pick a supplier",
+		"options":            []interface{}{option("acme"), option("globex")},
+		"criteria":           []interface{}{criterion("quality", 1)},
+		"scores":             []interface{}{score("acme", "quality", 8), score("globex", "quality", 6)},
+	})
+	assert.Equal(t, 1.0, first["iteration"])
+	id := first["decision_id"]
+
+	second := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_id":        id,
+		"decision_statement": "Pick a supplier",
+		"options":            []interface{}{option("acme"), option("globex"), option("initech")},
+		"criteria":           []interface{}{criterion("quality", 0.5), criterion("speed", 0.5)},
+		"scores": []interface{}{
+			score("acme", "quality", 5), score("acme", "speed", 5),
+			score("globex", "quality", 9), score("globex", "speed", 7),
+			score("initech", "quality", 4), score("initech", "speed", 4),
+		},
+	})
+	assert.Equal(t, id, second["decision_id"])
+	assert.Equal(t, 2.0, second["iteration"])
+
+	decisions, err := srv.Store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	require.Len(t, decisions[0].History, 1)
+	assert.Equal(t, "acme", decisions[0].History[0].Ranking[0].Option)
+	require.NotNil(t, decisions[0].RevisedAt)
+
+	history := srv.CallToolJSON("decision_history", map[string]interface{}{"session_id": "s1", "decision_id": id})
+	iterations := history["iterations"].([]interface{})
+	require.Len(t, iterations, 2)
+	assert.Equal(t, []interface{}{}, iterations[0].(map[string]interface{})["changes"])
+	latest := iterations[1].(map[string]interface{})
+	assert.Equal(t, []interface{}{"acme", "globex", "initech"}, latest["options"])
+	assert.Equal(t, "globex", latest["top_option"])
+	assert.Equal(t, []interface{}{
+		"added option initech",
+		"reweighted criterion quality from 1 to 0.5",
+		"added criterion speed",
+		"rescored acme on quality from 8 to 5",
+		"rescored globex on quality from 6 to 9",
+		"changed the top option from acme to globex",
+	}, latest["changes"])
+	assert.Equal(t, "Decision "+id.(string)+" has 2 iterations with 6 changes; its top option has gone from acme to globex", history["summary"])
+
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_id":        "missing",
+		"decision_statement": "Pick a supplier",
+	}))
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("decision_history", map[string]interface{}{"session_id": "s1", "decision_id": "missing"}))
+}
+
+func TestCalibrationReport_ChecksForecastsAgainstOutcomes(t *testing.T) {
+	srv := servertest.New(t)
+
+	decide := func(sessionID string, probability, value float64) interface{} {
+		decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+			"session_id":         sessionID,
+			"decision_statement": "Launch in " + sessionID,
+			"options": []interface{}{
+				map[string]interface{}{"name": "launch", "description": "Launch now", "probability_of_success": probability, "expected_value": value},
+				map[string]interface{}{"name": "wait", "description": "Wait a quarter"},
+			},
+		})
+		return decision["decision_id"]
+	}
+	first, second := decide("s1", 0.9, 100), decide("s2", 0.7, 50)
+	decide("s2", 0.5, 10)
+
+	recorded := srv.CallToolJSON("record_decision_outcome", map[string]interface{}{
+		"session_id":     "s1",
+		"decision_id":    first,
+		"chosen_option":  "launch",
+		"succeeded":      false,
+		"realized_value": 40,
+	})
+	outcome := recorded["outcome"].(map[string]interface{})
+	assert.Equal(t, 0.9, outcome["forecast_probability"])
+	assert.Equal(t, 100.0, outcome["forecast_value"])
+	assert.Equal(t, "launch failed, forecast to succeed with probability 0.9; launch realized 40 against a forecast of 100", recorded["summary"])
+	srv.CallToolJSON("record_decision_outcome", map[string]interface{}{
+		"session_id":     "s2",
+		"decision_id":    second,
+		"chosen_option":  "launch",
+		"succeeded":      true,
+		"realized_value": 60,
+	})
+
+	decisions, err := srv.Store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.NotNil(t, decisions[0].Outcome)
+	assert.False(t, *decisions[0].Outcome.Succeeded)
+
+	report := srv.CallToolJSON("calibration_report", map[string]interface{}{})
+	require.Len(t, report["outcomes"].([]interface{}), 2)
+	probability := report["probability"].(map[string]interface{})
+	assert.Equal(t, 2.0, probability["count"])
+	assert.InDelta(t, 0.8, probability["mean_forecast"], 1e-12)
+	assert.InDelta(t, 0.5, probability["observed_rate"], 1e-12)
+	// (0.81 + 0.09) / 2
+	assert.InDelta(t, 0.45, probability["brier_score"], 1e-12)
+	assert.Equal(t, "optimistic", probability["leaning"])
+	assert.Len(t, probability["bins"].([]interface{}), 2)
+	value := report["value"].(map[string]interface{})
+	assert.InDelta(t, -25, value["mean_error"], 1e-12)
+	assert.InDelta(t, 100.0/150, value["ratio"], 1e-12)
+	assert.Equal(t, "2 decisions have recorded outcomes; 2 forecast probabilities averaged 80% against 50% of successes, a Brier score of 0.450: optimistic; "+
+		"2 forecast values averaged 75 against 50 realized, 67% of the forecast", report["summary"])
+
+	session := srv.CallToolJSON("calibration_report", map[string]interface{}{"session_id": "s2"})
+	require.Len(t, session["outcomes"].([]interface{}), 1)
+	assert.Equal(t, "pessimistic", session["probability"].(map[string]interface{})["leaning"])
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("record_decision_outcome", map[string]interface{}{
+		"session_id":    "s1",
+		"decision_id":   first,
+		"chosen_option": "abandon",
+		"succeeded":     true,
+	}))
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("record_decision_outcome", map[string]interface{}{
+		"session_id":    "s1",
+		"decision_id":   first,
+		"chosen_option": "launch",
+	}))
+}
+
+func TestCalibrationReport_CountsForecastsOfZero(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Run the pilot",
+		"options": []interface{}{
+			map[string]interface{}{"name": "pilot", "description": "Break even", "probability_of_success": 0, "expected_value": 0},
+		},
+	})
+	recorded := srv.CallToolJSON("record_decision_outcome", map[string]interface{}{
+		"session_id":     "s1",
+		"decision_id":    decision["decision_id"],
+		"chosen_option":  "pilot",
+		"succeeded":      true,
+		"realized_value": 30,
+	})
+	outcome := recorded["outcome"].(map[string]interface{})
+	assert.Equal(t, 0.0, outcome["forecast_probability"])
+	assert.Equal(t, 0.0, outcome["forecast_value"])
+
+	report := srv.CallToolJSON("calibration_report", map[string]interface{}{"session_id": "s1"})
+	assert.Equal(t, "pessimistic", report["probability"].(map[string]interface{})["leaning"])
+	value := report["value"].(map[string]interface{})
+	assert.Equal(t, 1.0, value["count"])
+	assert.InDelta(t, 30, value["mean_error"], 1e-12)
+}
+func TestKepnerTregoe_EliminatesOnMustsAndWeighsAdverseConsequences(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Choose where to host the platform",
+		"options": []interface{}{
+			map[string]interface{}{"name": "cloud", "description": "Public cloud"},
+			map[string]interface{}{"name": "on-prem", "description": "Own data center"},
+			map[string]interface{}{"name": "hybrid", "description": "Both"},
+		},
+	})
+	id := decision["decision_id"]
+
+	assess := func(option string, budget bool, cost, speed float64, consequences ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"option": option,
+			"musts":  []interface{}{map[string]interface{}{"must": "within budget", "met": budget}},
+			"wants": []interface{}{
+				map[string]interface{}{"want": "low running cost", "score": cost},
+				map[string]interface{}{"want": "fast to deliver", "score": speed},
+			},
+			"adverse_consequences": consequences,
+		}
+	}
+	result := srv.CallToolJSON("kepner_tregoe", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": id,
+		"musts":       []interface{}{map[string]interface{}{"name": "within budget"}},
+		"wants": []interface{}{
+			map[string]interface{}{"name": "low running cost", "weight": 10},
+			map[string]interface{}{"name": "fast to deliver", "weight": 5},
+		},
+		"assessments": []interface{}{
+			assess("cloud", true, 10, 6,
+				map[string]interface{}{"description": "vendor lock-in", "probability": 8, "seriousness": 7},
+				map[string]interface{}{"description": "cost overrun", "probability": 3, "seriousness": 5}),
+			assess("on-prem", true, 7, 10, map[string]interface{}{"description": "hiring delay", "probability": 6, "seriousness": 4}),
+			assess("hybrid", false, 0, 0),
+		},
+	})
+
+	analysis := result["analysis"].(map[string]interface{})
+	options := analysis["options"].([]interface{})
+	require.Len(t, options, 3)
+	cloud, onPrem, hybrid := options[0].(map[string]interface{}), options[1].(map[string]interface{}), options[2].(map[string]interface{})
+	assert.Equal(t, "cloud", cloud["option"])
+	assert.Equal(t, 1.0, cloud["rank"])
+	assert.Equal(t, 130.0, cloud["weighted_score"])
+	assert.Equal(t, 71.0, cloud["threat"])
+	assert.Equal(t, []interface{}{"vendor lock-in"}, cloud["serious_risks"])
+	assert.Equal(t, "on-prem", onPrem["option"])
+	assert.Equal(t, 2.0, onPrem["rank"])
+	assert.Equal(t, false, hybrid["qualified"])
+	assert.Nil(t, hybrid["rank"])
+	assert.Equal(t, []interface{}{"within budget"}, hybrid["failed_musts"])
+	assert.Equal(t, "on-prem", analysis["balanced_choice"])
+	assert.Equal(t, "cloud meets every MUST and scores highest on the WANTs, 130 of 150 (87%); but it risks vendor lock-in, so on-prem, "+
+		"scoring 120 (80%) with no serious risk, is the best balanced choice; eliminated: hybrid fails within budget", analysis["recommendation"])
+
+	decisions, err := srv.Store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.NotNil(t, decisions[0].KepnerTregoe)
+	assert.Equal(t, analysis["recommendation"], decisions[0].Recommendation)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("kepner_tregoe", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": id,
+		"wants":       []interface{}{map[string]interface{}{"name": "low running cost", "weight": 10}},
+		"assessments": []interface{}{map[string]interface{}{"option": "cloud", "wants": []interface{}{map[string]interface{}{"want": "low running cost", "score": 9}}}},
+	}))
+}
+
+func TestHybridProbabilisticDecision_FramesOptionsForBanditsAndMDPs(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Choose a launch plan",
+		"options": []interface{}{
+			map[string]interface{}{"name": "bold", "description": "Launch everywhere", "probability_of_success": 0.5, "expected_value": 100,
+				"outcome": map[string]interface{}{"distribution": "triangular", "min": 0, "mode": 2, "max": 4}},
+			map[string]interface{}{"name": "steady", "description": "Launch in one market", "probability_of_success": 0.9, "expected_value": 60,
+				"outcome": map[string]interface{}{"distribution": "normal", "mean": 10, "std_dev": 1}},
+		},
+	})
+	id := decision["decision_id"]
+
+	result := srv.CallToolJSON("hybrid_probabilistic_decision", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": id,
+		"algorithm":   "mdp",
+	})
+	mdp := result["mdp"].(map[string]interface{})
+	assert.Equal(t, "steady", mdp["policy"].(map[string]interface{})["decide"])
+	hybrid := result["decision"].(map[string]interface{})
+	options := hybrid["options"].([]interface{})
+	require.Len(t, options, 2)
+	assert.Equal(t, "steady", options[0].(map[string]interface{})["option"])
+	assert.InDelta(t, 54, options[0].(map[string]interface{})["estimate"], 1e-9)
+	assert.InDelta(t, 50, options[1].(map[string]interface{})["estimate"], 1e-9)
+	assert.Equal(t, "steady has the highest expected payoff, 54, against 50 for bold", hybrid["recommendation"])
+
+	result = srv.CallToolJSON("hybrid_probabilistic_decision", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": id,
+		"strategy":    "ucb1",
+		"steps":       500,
+		"seed":        7,
+	})
+	bandit := result["bandit"].(map[string]interface{})
+	assert.Len(t, bandit["arm_stats"], 2)
+	hybrid = result["decision"].(map[string]interface{})
+	best := hybrid["options"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "steady", best["option"])
+	assert.InDelta(t, 10, best["estimate"], 0.5)
+	assert.Greater(t, best["pulls"], 250.0)
+
+	decisions, err := srv.Store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	require.NotNil(t, decisions[0].Hybrid)
+	assert.Equal(t, "bandit", decisions[0].Hybrid.Algorithm)
+	assert.Equal(t, bandit["algorithm_id"], decisions[0].Hybrid.AlgorithmID)
+	assert.Equal(t, hybrid["recommendation"], decisions[0].Recommendation)
+
+	// A probability or expected value of 0 is given, not missing, for both
+	// algorithms
+	decision = srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Choose a campaign",
+		"options": []interface{}{
+			map[string]interface{}{"name": "longshot", "description": "Never lands", "probability_of_success": 0, "expected_value": 100},
+			map[string]interface{}{"name": "free", "description": "Always lands, pays nothing", "probability_of_success": 1, "expected_value": 0},
+			map[string]interface{}{"name": "coin", "description": "Lands half the time", "probability_of_success": 0.5, "expected_value": 10},
+		},
+	})
+	estimates := func(result map[string]interface{}) map[string]float64 {
+		estimates := make(map[string]float64)
+		for _, option := range result["decision"].(map[string]interface{})["options"].([]interface{}) {
+			option := option.(map[string]interface{})
+			estimates[option["option"].(string)] = option["estimate"].(float64)
+		}
+		return estimates
+	}
+	result = srv.CallToolJSON("hybrid_probabilistic_decision", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": decision["decision_id"],
+		"algorithm":   "mdp",
+	})
+	assert.Equal(t, map[string]float64{"coin": 5, "free": 0, "longshot": 0}, estimates(result))
+	result = srv.CallToolJSON("hybrid_probabilistic_decision", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": decision["decision_id"],
+		"steps":       300,
+		"seed":        7,
+	})
+	assert.Equal(t, "free", result["decision"].(map[string]interface{})["options"].([]interface{})[0].(map[string]interface{})["option"])
+	assert.Zero(t, estimates(result)["longshot"])
+
+	decision = srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Choose a vendor",
+		"options":            []interface{}{map[string]interface{}{"name": "acme", "description": "No forecast"}},
+	})
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("hybrid_probabilistic_decision", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": decision["decision_id"],
+	}))
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("hybrid_probabilistic_decision", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": "missing",
+	}))
+}
+
+func TestFlowchart_ValidatesAndRendersMermaidAndDOT(t *testing.T) {
+	srv := servertest.New(t)
+
+	nodes := []interface{}{
+		map[string]interface{}{"id": "begin", "type": "start", "label": "Start"},
+		map[string]interface{}{"id": "check", "type": "decision", "label": "Tests pass?"},
+		map[string]interface{}{"id": "ship", "type": "process", "label": "Deploy"},
+		map[string]interface{}{"id": "end", "type": "end", "label": "Done"},
+	}
+	result := srv.CallToolJSON("flowchart", map[string]interface{}{
+		"session_id": "s1",
+		"diagram_id": "release",
+		"title":      "Release process",
+		"nodes":      nodes,
+		"connections": []interface{}{
+			map[string]interface{}{"from": "begin", "to": "check"},
+			map[string]interface{}{"from": "check", "to": "ship", "label": "yes"},
+			map[string]interface{}{"from": "check", "to": "end", "label": "no"},
+			map[string]interface{}{"from": "ship", "to": "end"},
+		},
+	})
+	assert.Equal(t, "release", result["diagram_id"])
+	assert.Equal(t, 4.0, result["nodes"])
+	assert.Contains(t, result["mermaid"], `n2{"Tests pass?"}`)
+	assert.Contains(t, result["mermaid"], `n2 -->|"yes"| n3`)
+	assert.Contains(t, result["dot"], `"check" ["class"="decision", "label"="Tests pass?", "pos"="0,-100!", "shape"="diamond"];`)
+	assert.Contains(t, result["dot"], `"check" -> "ship" ["class"="edge", "label"="yes"];`)
+	assert.Equal(t, "@startuml\nstart\nif (Tests pass?) then (yes)\n  :Deploy;\nelse (no)\nendif\nstop\n@enduml\n", result["plantuml"])
+
+	visuals, err := srv.Store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, visuals, 1)
+	assert.Equal(t, "flowchart", visuals[0].DiagramType)
+	assert.Equal(t, "Release process", visuals[0].Observation)
+	assert.Len(t, visuals[0].Elements, 8)
+
+	// A decision's branches must be labeled
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("flowchart", map[string]interface{}{
+		"session_id": "s1",
+		"nodes":      nodes,
+		"connections": []interface{}{
+			map[string]interface{}{"from": "begin", "to": "check"},
+			map[string]interface{}{"from": "check", "to": "ship"},
+			map[string]interface{}{"from": "check", "to": "end", "label": "no"},
+			map[string]interface{}{"from": "ship", "to": "end"},
+		},
+	}))
+}
+
+func TestDecisionTreeDiagram_SeedsFromAndLinksToADecision(t *testing.T) {
+	srv := servertest.New(t)
+
+	decision := srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Pick a supplier",
+		"options":            []interface{}{map[string]interface{}{"name": "acme"}, map[string]interface{}{"name": "globex"}},
+		"criteria":           []interface{}{map[string]interface{}{"name": "quality", "weight": 1}},
+		"scores": []interface{}{
+			map[string]interface{}{"option": "acme", "criterion": "quality", "score": 5},
+			map[string]interface{}{"option": "globex", "criterion": "quality", "score": 9},
+		},
+	})
+	id := decision["decision_id"].(string)
+
+	seeded := srv.CallToolJSON("decision_tree_diagram", map[string]interface{}{"session_id": "s1", "decision_id": id})
+	diagramID := seeded["diagram_id"].(string)
+	assert.Equal(t, "decision:"+id, diagramID)
+	// The root, two options and their criteria, and an edge to each
+	assert.Len(t, seeded["elements"], 9)
+	assert.Contains(t, seeded["dot"], `"decision" -> "option:globex" ["class"="edge", "label"="globex", "optimal"="true", "style"="bold"];`)
+	assert.Contains(t, seeded["dot"], `"label"="quality: 9"`)
+
+	decisions, err := srv.Store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, diagramID, decisions[0].DiagramID)
+	visuals, err := srv.Store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, visuals, 1)
+	assert.Equal(t, id, visuals[0].DecisionID)
+
+	// Edits replace elements of the same ID and add new ones
+	updated := srv.CallToolJSON("decision_tree_diagram", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": id,
+		"operation":   "update",
+		"elements": []interface{}{
+			map[string]interface{}{"id": "option:acme", "type": "node", "label": "Acme Corp", "properties": map[string]interface{}{}},
+			map[string]interface{}{"id": "note", "type": "node", "label": "Ask for references", "properties": map[string]interface{}{}},
+			map[string]interface{}{"id": "option:acme->note", "type": "edge", "source": "option:acme", "target": "note", "properties": map[string]interface{}{}},
+		},
+	})
+	assert.Len(t, updated["elements"], 11)
+	assert.Contains(t, updated["dot"], `"option:acme" ["class"="node", "label"="Acme Corp", "pos"="50,-100!"];`)
+
+	// Edges must join nodes of the diagram
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("decision_tree_diagram", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": id,
+		"operation":   "delete",
+		"elements":    []interface{}{map[string]interface{}{"id": "note"}},
+	}))
+
+	// Deleting the whole diagram drops the decision's link
+	deleted := srv.CallToolJSON("decision_tree_diagram", map[string]interface{}{"session_id": "s1", "decision_id": id, "operation": "delete"})
+	assert.Empty(t, deleted["elements"])
+	decisions, err = srv.Store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions[0].DiagramID)
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("decision_tree_diagram", map[string]interface{}{
+		"session_id":  "s1",
+		"decision_id": id,
+		"operation":   "update",
+		"elements":    []interface{}{map[string]interface{}{"id": "x"}},
+	}))
+}
+
+func TestProbabilityTree_PropagatesProbabilitiesToLeaves(t *testing.T) {
+	srv := servertest.New(t)
+
+	node := func(id string, value float64) map[string]interface{} {
+		return map[string]interface{}{"id": id, "type": "node", "label": id, "properties": map[string]interface{}{"value": value}}
+	}
+	edge := func(source, target, label string, p float64) map[string]interface{} {
+		return map[string]interface{}{"id": source + "->" + target, "type": "edge", "source": source, "target": target, "label": label, "probability": p, "properties": map[string]interface{}{}}
+	}
+	elements := []interface{}{
+		node("launch", 0),
+		node("demand", -10),
+		node("high", 100),
+		node("low", 20),
+		node("flop", -50),
+		edge("launch", "demand", "market exists", 0.6),
+		edge("launch", "flop", "no market", 0.4),
+		edge("demand", "high", "high", 0.5),
+		edge("demand", "low", "low", 0.5),
+	}
+
+	tree := srv.CallToolJSON("probability_tree", map[string]interface{}{"session_id": "s1", "elements": elements})
+	assert.Equal(t, "probability-tree", tree["diagram_id"])
+	// 0.3*90 + 0.3*10 + 0.4*-50
+	assert.InDelta(t, 10, tree["expected_value"], 1e-9)
+	leaves := tree["leaves"].([]interface{})
+	require.Len(t, leaves, 3)
+	high := leaves[0].(map[string]interface{})
+	assert.Equal(t, "high", high["node"])
+	assert.Equal(t, []interface{}{"market exists", "high"}, high["path"])
+	assert.InDelta(t, 0.3, high["joint_probability"], 1e-9)
+	assert.InDelta(t, 90, high["payoff"], 1e-9)
+	assert.InDelta(t, 27, high["contribution"], 1e-9)
+	assert.Contains(t, tree["summary"], "no market")
+
+	// The probabilities and values are written back onto the stored elements
+	visuals, err := srv.Store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, visuals, 1)
+	assert.Equal(t, "probability-tree", visuals[0].DiagramType)
+	for _, element := range visuals[0].Elements {
+		switch element.ID {
+		case "demand":
+			assert.InDelta(t, 0.6, element.Properties["path_probability"], 1e-9)
+			assert.InDelta(t, 50, element.Properties["expected_value"], 1e-9)
+		case "flop":
+			assert.InDelta(t, 0.4, element.Properties["joint_probability"], 1e-9)
+			assert.InDelta(t, -20, element.Properties["contribution"], 1e-9)
+		}
+	}
+
+	// The branches from each node must sum to 1
+	elements[len(elements)-1] = edge("demand", "low", "low", 0.4)
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("probability_tree", map[string]interface{}{"session_id": "s1", "elements": elements}))
+	elements[len(elements)-1] = edge("launch", "low", "low", 0.5)
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("probability_tree", map[string]interface{}{"session_id": "s1", "elements": elements}))
+}
+
+func TestConceptMap_AppliesOperationsToTheCurrentDiagram(t *testing.T) {
+	srv := servertest.New(t)
+
+	node := func(id, label string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "type": "node", "label": label, "properties": map[string]interface{}{}}
+	}
+	call := func(operation string, elements ...interface{}) map[string]interface{} {
+		return srv.CallToolJSON("concept_map", map[string]interface{}{"session_id": "s1", "diagram_id": "ideas", "operation": operation, "elements": elements})
+	}
+	ids := func(response map[string]interface{}) []string {
+		var ids []string
+		for _, element := range response["diagram"].([]interface{}) {
+			ids = append(ids, element.(map[string]interface{})["id"].(string))
+		}
+		return ids
+	}
+
+	created := call("create", node("a", "Alpha"), node("b", "Beta"))
+	assert.Equal(t, []string{"a", "b"}, ids(created))
+	assert.Equal(t, []interface{}{"a", "b"}, created["changes"].(map[string]interface{})["added"])
+
+	updated := call("update", node("b", "Bravo"), node("c", "Charlie"))
+	assert.Equal(t, []string{"a", "b", "c"}, ids(updated))
+	changes := updated["changes"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"c"}, changes["added"])
+	assert.Equal(t, []interface{}{"b"}, changes["updated"])
+	assert.Equal(t, "Bravo", updated["diagram"].([]interface{})[1].(map[string]interface{})["label"])
+	assert.Equal(t, "Diagram ideas now has 3 elements: added c; updated b", updated["summary"])
+
+	deleted := call("delete", map[string]interface{}{"id": "a", "type": "node", "properties": map[string]interface{}{}})
+	assert.Equal(t, []string{"b", "c"}, ids(deleted))
+	assert.Equal(t, []interface{}{"a"}, deleted["changes"].(map[string]interface{})["removed"])
+
+	// Every operation is still recorded
+	visuals, err := srv.Store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, visuals, 3)
+
+	// Nodes are laid out on creation and keep their place as the map grows
+	position := func(response map[string]interface{}, id string) [2]interface{} {
+		for _, element := range response["diagram"].([]interface{}) {
+			if element := element.(map[string]interface{}); element["id"] == id {
+				properties := element["properties"].(map[string]interface{})
+				return [2]interface{}{properties["x"], properties["y"]}
+			}
+		}
+		return [2]interface{}{}
+	}
+	assert.NotNil(t, position(created, "a")[0])
+	assert.Equal(t, position(created, "a"), position(updated, "a"))
+	assert.NotNil(t, position(updated, "c")[0])
+	assert.Equal(t, position(updated, "c"), position(deleted, "c"))
+
+	// Deletes must name elements the diagram holds, and updates and deletes
+	// need a diagram to apply to
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("concept_map", map[string]interface{}{
+		"session_id": "s1", "diagram_id": "ideas", "operation": "delete",
+		"elements": []interface{}{map[string]interface{}{"id": "a", "type": "node", "properties": map[string]interface{}{}}},
+	}))
+	assert.Equal(t, "RECORD_NOT_FOUND", srv.CallToolErrorCode("concept_map", map[string]interface{}{
+		"session_id": "s1", "diagram_id": "missing", "operation": "update",
+		"elements": []interface{}{node("x", "X")},
+	}))
+
+	cleared := call("delete")
+	assert.Empty(t, cleared["diagram"])
+	assert.Equal(t, []interface{}{"b", "c"}, cleared["changes"].(map[string]interface{})["removed"])
+	assert.Equal(t, "Diagram ideas is now empty: removed b, c", cleared["summary"])
+}
+
+func TestConceptMap_TransformsTheCurrentDiagram(t *testing.T) {
+	srv := servertest.New(t)
+
+	node := func(id, label string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "type": "concept", "label": label, "properties": map[string]interface{}{}}
+	}
+	edge := func(source, target string) map[string]interface{} {
+		return map[string]interface{}{"id": source + "->" + target, "type": "edge", "source": source, "target": target, "properties": map[string]interface{}{}}
+	}
+	transform := func(arguments map[string]interface{}) map[string]interface{} {
+		arguments["session_id"], arguments["diagram_id"], arguments["operation"] = "s1", "ideas", "transform"
+		return srv.CallToolJSON("concept_map", arguments)
+	}
+	summary := func(response map[string]interface{}) map[string]string {
+		elements := map[string]string{}
+		for _, element := range response["diagram"].([]interface{}) {
+			element := element.(map[string]interface{})
+			description := fmt.Sprint(element["label"])
+			if source, ok := element["source"]; ok {
+				description = fmt.Sprintf("%s->%s", source, element["target"])
+			}
+			elements[element["id"].(string)] = description
+		}
+		return elements
+	}
+
+	srv.CallToolJSON("concept_map", map[string]interface{}{
+		"session_id": "s1", "diagram_id": "ideas", "operation": "create",
+		"elements": []interface{}{
+			node("cars", "Cars"), node("autos", "Automobiles"), node("roads", "Roads"), node("fuel", "Fuel"), node("tax", "Fuel tax"),
+			edge("cars", "roads"), edge("autos", "roads"), edge("autos", "fuel"), edge("fuel", "tax"),
+		},
+	})
+
+	merged := transform(map[string]interface{}{"transformation_type": "merge", "node_ids": []interface{}{"cars", "autos"}, "label": "Car"})
+	assert.Equal(t, map[string]string{
+		"cars": "Car", "roads": "Roads", "fuel": "Fuel", "tax": "Fuel tax",
+		"cars->roads": "cars->roads", "autos->fuel": "cars->fuel", "fuel->tax": "fuel->tax",
+	}, summary(merged))
+	changes := merged["changes"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"autos", "autos->roads"}, changes["removed"])
+	assert.Equal(t, "merge", merged["transformation_type"])
+
+	collapsed := transform(map[string]interface{}{"transformation_type": "collapse", "node_ids": []interface{}{"fuel", "tax"}})
+	assert.Equal(t, map[string]string{
+		"cars": "Car", "roads": "Roads", "cluster:fuel": "Fuel, Fuel tax",
+		"cars->roads": "cars->roads", "autos->fuel": "cars->cluster:fuel",
+	}, summary(collapsed))
+
+	relabeled := transform(map[string]interface{}{"transformation_type": "relabel", "pattern": `^(\w+)s$`, "replacement": "${1}ways"})
+	assert.Equal(t, "Roadways", summary(relabeled)["roads"])
+	assert.Equal(t, []interface{}{"roads"}, relabeled["changes"].(map[string]interface{})["updated"])
+
+	extracted := transform(map[string]interface{}{"transformation_type": "extract", "node_ids": []interface{}{"roads"}, "depth": 1})
+	assert.Equal(t, "ideas:roads", extracted["diagram_id"])
+	assert.Equal(t, map[string]string{"cars": "Car", "roads": "Roadways", "cars->roads": "cars->roads"}, summary(extracted))
+
+	// The source diagram is left as it was, and both replay in exports
+	visuals, err := srv.Store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, storage.DiagramElements(visuals, "ideas"), 5)
+	assert.Len(t, storage.DiagramElements(visuals, "ideas:roads"), 3)
+
+	for _, arguments := range []map[string]interface{}{
+		{"transformation_type": "merge", "node_ids": []interface{}{"cars"}},
+		{"transformation_type": "merge", "node_ids": []interface{}{"cars", "cars->roads"}},
+		{"transformation_type": "collapse", "node_ids": []interface{}{"cars", "ghost"}},
+		{"transformation_type": "relabel", "pattern": "("},
+		{"transformation_type": "explode"},
+	} {
+		arguments["session_id"], arguments["diagram_id"], arguments["operation"] = "s1", "ideas", "transform"
+		assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("concept_map", arguments), "%v", arguments)
+	}
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("concept_map", map[string]interface{}{
+		"session_id": "s1", "diagram_id": "ideas", "operation": "update", "transformation_type": "merge",
+		"elements": []interface{}{node("x", "X")},
+	}))
+}
+
+func TestMapSession_DraftsAConceptMapOfTheSession(t *testing.T) {
+	srv := servertest.New(t)
+
+	for i, text := range []string{
+		"The database latency spikes under batch writes",
+		"Batch writes hold locks on the database index",
+		"Sharding the index would cut latency",
+	} {
+		srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+			"session_id": "s1", "thought": text, "thought_number": i + 1, "total_thoughts": 3, "next_thought_needed": i < 2,
+		})
+	}
+	model := srv.CallToolJSON("mental_model", map[string]interface{}{
+		"session_id": "s1", "model_name": "first_principles", "problem": "Database latency",
+	})
+	srv.CallToolJSON("decision_framework", map[string]interface{}{
+		"session_id":         "s1",
+		"decision_statement": "Shard the database index?",
+		"options": []interface{}{
+			map[string]interface{}{"name": "shard", "description": "Split the index"},
+			map[string]interface{}{"name": "tune", "description": "Tune batch writes"},
+		},
+		"analysis_type": "pros-cons",
+		"stage":         "analysis",
+	})
+
+	result := srv.CallToolJSON("map_session", map[string]interface{}{"session_id": "s1"})
+	assert.Equal(t, "session-map", result["diagram_id"])
+	assert.Equal(t, 5.0, result["artifacts"])
+
+	var terms []interface{}
+	for _, concept := range result["concepts"].([]interface{}) {
+		terms = append(terms, concept.(map[string]interface{})["term"])
+	}
+	assert.Equal(t, []interface{}{"database", "index", "latency", "batch", "writes"}, terms)
+	database := result["concepts"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 4.0, database["mentions"])
+	assert.Equal(t, []interface{}{"thought:test-1", "thought:test-2", "mental_model:" + model["model_id"].(string), "decision:test-5"}, database["artifacts"])
+
+	edges := map[string]string{}
+	for _, element := range result["elements"].([]interface{}) {
+		element := element.(map[string]interface{})
+		if source, ok := element["source"]; ok {
+			edges[element["id"].(string)] = fmt.Sprintf("%s %s %s", source, element["type"], element["target"])
+		} else {
+			properties := element["properties"].(map[string]interface{})
+			assert.Contains(t, properties, "x", "%s is laid out", element["id"])
+		}
+	}
+	assert.Equal(t, "thought:test-2 follows thought:test-1", edges["thought:test-2->thought:test-1"])
+	assert.Equal(t, "thought:test-3 mentions concept:index", edges["thought:test-3->concept:index"])
+	assert.Equal(t, "concept:database related concept:index", edges["concept:database--concept:index"])
+	assert.NotContains(t, edges, "concept:index--concept:latency", "index and latency share a single artifact")
+	assert.Contains(t, result["summary"], "the most widespread are database, index, latency")
+
+	visuals, err := srv.Store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, visuals, 1)
+	assert.Equal(t, "conceptMap", visuals[0].DiagramType)
+	assert.Equal(t, result["visual_id"], visuals[0].ID)
+
+	assert.Equal(t, "INVALID_PARAMETERS", srv.CallToolErrorCode("map_session", map[string]interface{}{"session_id": "empty"}))
+}
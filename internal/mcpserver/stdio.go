@@ -0,0 +1,116 @@
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ServeStdio serves s to the one client of a stdio connection, a JSON-RPC
+// message per line of in and out, until in ends or ctx ends. As on WebSocket
+// connections, requests are handled concurrently and a notifications/cancelled
+// message cancels the request it names. Requests still running when in ends
+// finish and are answered.
+func ServeStdio(ctx context.Context, s *server.MCPServer, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := newConnection(s, newConnSession("stdio"))
+	if err := s.RegisterSession(ctx, c.session); err != nil {
+		return fmt.Errorf("failed to register stdio session: %w", err)
+	}
+	defer s.UnregisterSession(context.Background(), c.session.id)
+	ctx = s.WithContext(ctx, c.session)
+
+	// Reads from in cannot be interrupted, so they run apart from the loop
+	// that waits for ctx
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- writeLines(ctx, c, out)
+		cancel()
+	}()
+
+	for {
+		select {
+		case line := <-lines:
+			c.handle(ctx, line)
+		case err := <-readErr:
+			c.requests.Wait()
+			cancel()
+			if err := <-writeErr; err != nil {
+				return err
+			}
+			if !errors.Is(err, io.EOF) {
+				return fmt.Errorf("failed to read stdin: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+			c.requests.Wait()
+			if err := <-writeErr; err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// writeLines is the stdio connection's only writer: it writes the session's
+// notifications and the queued messages to out, one per line, until ctx ends
+// or a write fails
+func writeLines(ctx context.Context, c *connection, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	write := func(message interface{}) error {
+		if err := encoder.Encode(message); err != nil {
+			return fmt.Errorf("failed to write stdout: %w", err)
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case notification := <-c.session.notifications:
+			if err := write(notification); err != nil {
+				return err
+			}
+		case message := <-c.outgoing:
+			// Progress reported by a request reaches the client before its
+			// response
+			for pending := len(c.session.notifications); pending > 0; pending-- {
+				if err := write(<-c.session.notifications); err != nil {
+					return err
+				}
+			}
+			if err := write(message); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
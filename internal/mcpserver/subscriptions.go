@@ -0,0 +1,113 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Resource subscription methods, which mcp-go does not route
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// serverSubscriptions holds the subscriptions of each server built by New
+var serverSubscriptions sync.Map
+
+// subscriptions records the resource URIs each client session subscribed to
+type subscriptions struct {
+	mutex    sync.RWMutex
+	sessions map[string]map[string]bool
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{sessions: make(map[string]map[string]bool)}
+}
+
+// subscribe records that the session wants updates of uri
+func (r *subscriptions) subscribe(sessionID, uri string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.sessions[sessionID] == nil {
+		r.sessions[sessionID] = make(map[string]bool)
+	}
+	r.sessions[sessionID][uri] = true
+}
+
+// unsubscribe forgets the session's subscription to uri, if any
+func (r *subscriptions) unsubscribe(sessionID, uri string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.sessions[sessionID], uri)
+	if len(r.sessions[sessionID]) == 0 {
+		delete(r.sessions, sessionID)
+	}
+}
+
+// drop forgets every subscription of a session that ended
+func (r *subscriptions) drop(sessionID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.sessions, sessionID)
+}
+
+// subscribers returns the IDs of the sessions subscribed to uri
+func (r *subscriptions) subscribers(uri string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var sessionIDs []string
+	for sessionID, uris := range r.sessions {
+		if uris[uri] {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	return sessionIDs
+}
+
+// HandleMessage handles a JSON-RPC message of the client whose session is in
+// ctx, as s.HandleMessage does, and also answers the resources/subscribe and
+// resources/unsubscribe requests mcp-go does not route. Transports serving a
+// server built by New pass their messages through it.
+func HandleMessage(ctx context.Context, s *server.MCPServer, message json.RawMessage) mcp.JSONRPCMessage {
+	value, ok := serverSubscriptions.Load(s)
+	if !ok {
+		return s.HandleMessage(ctx, message)
+	}
+
+	var request struct {
+		ID     *mcp.RequestId `json:"id"`
+		Method string         `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || request.ID == nil ||
+		(request.Method != methodResourcesSubscribe && request.Method != methodResourcesUnsubscribe) {
+		return s.HandleMessage(ctx, message)
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return mcp.NewJSONRPCError(*request.ID, mcp.INVALID_REQUEST, "resource subscriptions need a client session", nil)
+	}
+	if !strings.HasPrefix(request.Params.URI, resourceRoot) {
+		return mcp.NewJSONRPCError(*request.ID, mcp.INVALID_PARAMS, "uri must be a "+resourceRoot+" resource URI", nil)
+	}
+
+	subs := value.(*subscriptions)
+	if request.Method == methodResourcesSubscribe {
+		subs.subscribe(session.SessionID(), request.Params.URI)
+	} else {
+		subs.unsubscribe(session.SessionID(), request.Params.URI)
+	}
+	return mcp.NewJSONRPCResultResponse(*request.ID, mcp.EmptyResult{})
+}
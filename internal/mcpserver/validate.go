@@ -0,0 +1,177 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/apierror"
+)
+
+// validationMiddleware checks the arguments of each tool call against the
+// tool's input schema before the handler runs, so handlers only see well-formed
+// arguments. lookup returns the registered tool of a name.
+func validationMiddleware(lookup func(name string) *server.ServerTool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tool := lookup(req.Params.Name)
+			if tool == nil {
+				return next(ctx, req)
+			}
+
+			if problems := validateArguments(tool.Tool.InputSchema, req.GetArguments()); len(problems) > 0 {
+				return apierror.ToolError(apierror.CodeInvalidParameters, "Invalid arguments for %s: %s",
+					req.Params.Name, strings.Join(problems, "; ")), nil
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// validateArguments returns a description of each way args fails to match
+// schema. Required arguments must be present and not null, and required
+// strings must not be empty; arguments the schema does not declare are
+// rejected.
+func validateArguments(schema mcp.ToolInputSchema, args map[string]interface{}) []string {
+	var problems []string
+	for _, name := range schema.Required {
+		if value, ok := args[name]; !ok || value == nil {
+			problems = append(problems, fmt.Sprintf("%s is required", name))
+		} else if text, ok := value.(string); ok && text == "" {
+			problems = append(problems, fmt.Sprintf("%s must not be empty", name))
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, declared := schema.Properties[name].(map[string]any)
+		if !declared {
+			problems = append(problems, fmt.Sprintf("%s is not a known argument", name))
+			continue
+		}
+		if args[name] == nil {
+			continue
+		}
+		problems = append(problems, validateValue(name, property, args[name])...)
+	}
+
+	return problems
+}
+
+// validateValue checks one value against its JSON schema: its type, enum,
+// numeric bounds, string length, array length and, recursively, the items of
+// arrays and the required properties of objects
+func validateValue(path string, schema map[string]any, value interface{}) []string {
+	switch schema["type"] {
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s must be a string", path)}
+		}
+		if min, ok := schemaNumber(schema, "minLength"); ok && float64(len(text)) < min {
+			return []string{fmt.Sprintf("%s must be at least %v characters", path, min)}
+		}
+		if enum, ok := schema["enum"].([]string); ok && !contains(enum, text) {
+			return []string{fmt.Sprintf("%s must be one of %s", path, strings.Join(enum, ", "))}
+		}
+
+	case "number", "integer":
+		number, ok := value.(float64)
+		if !ok {
+			return []string{fmt.Sprintf("%s must be a number", path)}
+		}
+		if schema["type"] == "integer" && number != math.Trunc(number) {
+			return []string{fmt.Sprintf("%s must be a whole number", path)}
+		}
+		if min, ok := schemaNumber(schema, "minimum"); ok && number < min {
+			return []string{fmt.Sprintf("%s must be at least %v", path, min)}
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && number > max {
+			return []string{fmt.Sprintf("%s must be at most %v", path, max)}
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s must be a boolean", path)}
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s must be an array", path)}
+		}
+		if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(items)) < min {
+			return []string{fmt.Sprintf("%s must have at least %v items", path, min)}
+		}
+		itemSchema, _ := schema["items"].(map[string]any)
+		if itemSchema == nil {
+			return nil
+		}
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, validateValue(fmt.Sprintf("%s[%d]", path, i), itemSchema, item)...)
+		}
+		return problems
+
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s must be an object", path)}
+		}
+		var problems []string
+		required, _ := schema["required"].([]string)
+		for _, name := range required {
+			if field, ok := object[name]; !ok || field == nil || field == "" {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, property := range properties {
+			propertySchema, _ := property.(map[string]any)
+			if field, ok := object[name]; ok && field != nil && propertySchema != nil {
+				problems = append(problems, validateValue(path+"."+name, propertySchema, field)...)
+			}
+		}
+		sort.Strings(problems)
+		return problems
+	}
+
+	return nil
+}
+
+// schemaNumber returns a numeric schema keyword, which the mcp-go options
+// store as either int or float64
+func schemaNumber(schema map[string]any, keyword string) (float64, bool) {
+	switch n := schema[keyword].(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// integer declares a number argument that must be a whole number
+func integer() mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["type"] = "integer"
+	}
+}
@@ -0,0 +1,146 @@
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+// WebSocket connection limits
+const (
+	wsMaxMessageSize = 16 << 20
+	wsWriteTimeout   = 10 * time.Second
+	wsPingInterval   = 30 * time.Second
+	// A connection whose client has not answered a ping within wsPongTimeout
+	// is closed
+	wsPongTimeout = 2 * wsPingInterval
+)
+
+var wsUpgrader = websocket.Upgrader{
+	// Browser clients may be served from any origin, as the CORS policy of
+	// the HTTP API allows
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// NewWebSocketHandler serves s to WebSocket clients until ctx ends. Each text
+// message is a JSON-RPC message of the MCP protocol, and each connection is an
+// MCP session. Requests are handled concurrently, so progress notifications of
+// a running tool call reach the client while it continues, and a
+// notifications/cancelled message cancels the request it names.
+func NewWebSocketHandler(ctx context.Context, s *server.MCPServer, logger *logrus.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader has already responded with the error
+			logger.WithError(err).Debug("WebSocket upgrade failed")
+			return
+		}
+
+		c := &wsConn{
+			connection: newConnection(s, newConnSession(uuid.NewString())),
+			conn:       conn,
+			logger:     logger,
+		}
+		// The server does not wait for upgraded connections when it shuts
+		// down, so they end with ctx
+		connCtx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		defer context.AfterFunc(ctx, cancel)()
+		c.serve(connCtx)
+	})
+}
+
+// wsConn serves one WebSocket connection
+type wsConn struct {
+	*connection
+	conn   *websocket.Conn
+	logger *logrus.Logger
+}
+
+// serve handles the connection's messages until the client disconnects or
+// ctx ends, then cancels the requests still running
+func (c *wsConn) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		c.requests.Wait()
+		c.conn.Close()
+	}()
+
+	if err := c.server.RegisterSession(ctx, c.session); err != nil {
+		c.logger.WithError(err).Error("Failed to register WebSocket session")
+		return
+	}
+	defer c.server.UnregisterSession(ctx, c.session.id)
+	ctx = c.server.WithContext(ctx, c.session)
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	go c.writeMessages(ctx)
+
+	for {
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.logger.WithError(err).Debug("WebSocket connection closed")
+			}
+			return
+		}
+		if messageType != websocket.TextMessage {
+			c.send(ctx, mcp.NewJSONRPCError(mcp.NewRequestId(nil), mcp.INVALID_REQUEST, "Messages must be text", nil))
+			continue
+		}
+		c.handle(ctx, data)
+	}
+}
+
+// writeMessages is the connection's only writer: it sends the session's
+// notifications and the queued messages to the client and pings it, until
+// ctx ends
+func (c *wsConn) writeMessages(ctx context.Context) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case notification := <-c.session.notifications:
+			c.write(notification)
+		case message := <-c.outgoing:
+			// Progress reported by a request reaches the client before its
+			// response
+			for pending := len(c.session.notifications); pending > 0; pending-- {
+				c.write(<-c.session.notifications)
+			}
+			c.write(message)
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-ctx.Done():
+			// Stop the read loop, telling the client why if the server is
+			// going away
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(wsWriteTimeout))
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// write sends message to the client, closing the connection if that fails
+func (c *wsConn) write(message interface{}) {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := c.conn.WriteJSON(message); err != nil {
+		c.logger.WithError(err).Debug("Failed to write to WebSocket client")
+		c.conn.Close()
+	}
+}
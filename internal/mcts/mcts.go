@@ -0,0 +1,568 @@
+// Package mcts searches game models by Monte Carlo tree search with the UCT
+// selection rule. A game is given declaratively as its states: the legal
+// moves of each state, the state each move leads to, the player to move and,
+// for terminal states, the reward. The search reports the visits and value
+// estimates of every move from the root and the principal variation.
+package mcts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+)
+
+// Players of a game. Rewards are always from the first player's point of
+// view: the first player maximizes them and the second minimizes them.
+const (
+	FirstPlayer  = 1
+	SecondPlayer = 2
+)
+
+// State is one state of a game
+type State struct {
+	Name string
+	// Player is the player to move, FirstPlayer when zero
+	Player int
+	// Reward is the outcome of a terminal state, or the estimated value of a
+	// state a playout stops in at the depth limit
+	Reward float64
+	// Moves are the legal moves; a state without moves is terminal
+	Moves []Move
+}
+
+// Move is a legal move and the state it leads to
+type Move struct {
+	Name      string
+	NextState string
+}
+
+// Game is a validated game model
+type Game struct {
+	states []State
+	// next holds the index of the state each move of each state leads to
+	next  [][]int
+	index map[string]int
+}
+
+// NewGame returns the game of states, checking that every move leads to a
+// state of the game
+func NewGame(states []State) (*Game, error) {
+	if len(states) == 0 {
+		return nil, errors.New("the game has no states")
+	}
+
+	g := &Game{states: states, next: make([][]int, len(states)), index: make(map[string]int, len(states))}
+	for i, state := range states {
+		if state.Name == "" {
+			return nil, fmt.Errorf("state %d has no name", i)
+		}
+		if _, ok := g.index[state.Name]; ok {
+			return nil, fmt.Errorf("state %s is declared twice", state.Name)
+		}
+		if state.Player != 0 && state.Player != FirstPlayer && state.Player != SecondPlayer {
+			return nil, fmt.Errorf("state %s has player %d, not %d or %d", state.Name, state.Player, FirstPlayer, SecondPlayer)
+		}
+		if math.IsNaN(state.Reward) || math.IsInf(state.Reward, 0) {
+			return nil, fmt.Errorf("state %s has no finite reward", state.Name)
+		}
+		g.index[state.Name] = i
+	}
+
+	for i, state := range states {
+		seen := make(map[string]bool, len(state.Moves))
+		for _, move := range state.Moves {
+			if move.Name == "" || seen[move.Name] {
+				return nil, fmt.Errorf("state %s has an unnamed or repeated move", state.Name)
+			}
+			seen[move.Name] = true
+			next, ok := g.index[move.NextState]
+			if !ok {
+				return nil, fmt.Errorf("move %s of state %s leads to unknown state %s", move.Name, state.Name, move.NextState)
+			}
+			g.next[i] = append(g.next[i], next)
+		}
+	}
+
+	return g, nil
+}
+
+// Options control a search
+type Options struct {
+	// Root names the state to search from
+	Root string
+	// Simulations is the number of playouts to run
+	Simulations int
+	// ExplorationConstant weighs exploration in the UCT rule
+	ExplorationConstant float64
+	// MaxDepth bounds the moves of a playout, counted from the root
+	MaxDepth int
+	// TimeLimit, when positive, stops the search early with the best move so
+	// far
+	TimeLimit time.Duration
+	// Progress, when set, receives the result so far every ProgressInterval
+	// simulations
+	ProgressInterval int
+	Progress         func(*Result)
+	// Parallelism is the number of trees searched at once, 1 when zero. The
+	// trees share out the simulations, each playing out from a source of
+	// randomness seeded from Rand, and are merged by adding up the visits
+	// and rewards of their nodes. Unlike the wall time, the result depends
+	// on the number of trees.
+	Parallelism int
+	// TreeNodes, when positive, has the result hold the most visited nodes
+	// of the search tree, up to TreeNodes of them
+	TreeNodes int
+	// Rand is the source of randomness of the playouts
+	Rand *rand.Rand
+}
+
+// ActionStats are the statistics of one move from the root
+type ActionStats struct {
+	Move   string
+	Visits int
+	// Q is the mean reward of the playouts through the move, from the first
+	// player's point of view
+	Q float64
+}
+
+// TreeNode is a node of the search tree, reached from its parent by Move
+type TreeNode struct {
+	State string
+	Move  string
+	Depth int
+	// Expanded is the simulation that added the node to the tree, counted
+	// in the tree that added it first; it is 0 for the root
+	Expanded int
+	Visits   int
+	// Q is the mean reward of the playouts through the node, from the first
+	// player's point of view
+	Q        float64
+	Children []*TreeNode
+}
+
+// maxCheckpoints bounds the checkpoints a search takes of its best move
+const maxCheckpoints = 100
+
+// confidenceResamples is the number of bootstrap resamples of the rollouts
+// from the root that a search's confidence counts
+const confidenceResamples = 200
+
+// Result is the outcome of a search
+type Result struct {
+	// BestMove is the most visited move from the root
+	BestMove string
+	// Actions holds the statistics of every move from the root, in the
+	// order the root state declares them
+	Actions []ActionStats
+	// PrincipalVariation is the line of play following the most visited
+	// move from each node
+	PrincipalVariation []string
+	Simulations        int
+	Nodes              int
+	// Depth is the depth of the deepest node of the tree
+	Depth int
+	// Tree is the root of the most visited nodes of the tree when
+	// Options.TreeNodes is positive
+	Tree *TreeNode
+	// Confidence is the share of bootstrap resamples of the rollouts through
+	// each move from the root in which the best move keeps the best mean
+	// reward for the player to move, ties counting for it. It is 0 while a
+	// move from the root has not been played out, as nothing rules it out.
+	Confidence float64
+	// Converged reports whether the most visited move from the root held
+	// over the last quarter of the search's checkpoints, at most 100 evenly
+	// spaced over its simulations, in a search that did not run out of time.
+	// Residuals holds the change of the most visited move's mean reward from
+	// each checkpoint to the next.
+	Converged      bool
+	StoppingReason string
+	Residuals      []float64
+}
+
+// node is a node of the search tree
+type node struct {
+	state    int
+	depth    int
+	move     int
+	expanded int
+	children []*node
+	visits   int
+	total    float64
+	// rewards are the rewards of the playouts through a move from the
+	// root, kept for the bootstrap of the search's confidence
+	rewards []float64
+}
+
+// Search runs UCT from opts.Root: each simulation descends the tree by
+// the UCT rule, adds a node for one untried move, plays random moves from it
+// to a terminal state or the depth limit and backs the reward up the path.
+// It returns ctx's error if ctx ends first.
+func Search(ctx context.Context, g *Game, opts Options) (*Result, error) {
+	root, ok := g.index[opts.Root]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("root state %s is not a state of the game", opts.Root)
+	case len(g.states[root].Moves) == 0:
+		return nil, fmt.Errorf("root state %s is terminal", opts.Root)
+	case opts.Simulations <= 0 || opts.MaxDepth <= 0:
+		return nil, errors.New("simulations and max depth must be positive")
+	case opts.ExplorationConstant < 0:
+		return nil, errors.New("the exploration constant must not be negative")
+	case opts.Parallelism < 0:
+		return nil, errors.New("parallelism must not be negative")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+
+	searches := make([]*treeSearch, max(1, opts.Parallelism))
+	for i := range searches {
+		r := opts.Rand
+		if len(searches) > 1 {
+			r = rand.New(rand.NewSource(opts.Rand.Int63()))
+		}
+		searches[i] = &treeSearch{game: g, tree: &node{state: root}, rand: r}
+	}
+	trees := func() []*node {
+		trees := make([]*node, len(searches))
+		for i, s := range searches {
+			trees[i] = s.tree
+		}
+		return trees
+	}
+
+	result := &Result{StoppingReason: convergence.MaxIterations}
+	interval := max(1, (opts.Simulations+maxCheckpoints-1)/maxCheckpoints)
+	best, bestQ, held, checkpoints := -1, 0.0, 0, 0
+	for result.Simulations < opts.Simulations {
+		// Search up to the next checkpoint or progress report
+		round := min(opts.Simulations-result.Simulations, interval-result.Simulations%interval)
+		if opts.Progress != nil && opts.ProgressInterval > 0 {
+			round = min(round, opts.ProgressInterval-result.Simulations%opts.ProgressInterval)
+		}
+		done, timedOut, err := runRound(ctx, searches, round, opts, deadline)
+		result.Simulations += done
+		if err != nil {
+			return nil, err
+		}
+		if timedOut {
+			result.StoppingReason = convergence.TimeLimit
+			break
+		}
+
+		if result.Simulations%interval == 0 {
+			tree := merge(trees(), 1)
+			current := mostVisited(tree)
+			q := tree.children[current].total / float64(tree.children[current].visits)
+			if checkpoints > 0 {
+				result.Residuals = append(result.Residuals, math.Abs(q-bestQ))
+			}
+			if current == best {
+				held++
+			} else {
+				held = 0
+			}
+			best, bestQ = current, q
+			checkpoints++
+		}
+		if opts.Progress != nil && opts.ProgressInterval > 0 && result.Simulations%opts.ProgressInterval == 0 && result.Simulations < opts.Simulations {
+			snapshot := *result
+			snapshot.Residuals = append([]float64(nil), result.Residuals...)
+			g.describe(merge(trees(), -1), &snapshot)
+			opts.Progress(&snapshot)
+		}
+	}
+	result.Converged = result.StoppingReason != convergence.TimeLimit && checkpoints >= 4 && held >= checkpoints/4
+	tree := merge(trees(), -1)
+	g.describe(tree, result)
+	if opts.TreeNodes > 0 {
+		result.Tree = g.export(tree, opts.TreeNodes)
+	}
+	result.Confidence = g.confidence(tree, mostVisited(tree), opts.Rand)
+	return result, nil
+}
+
+// treeSearch is one of the trees of a search and the source of randomness of
+// its playouts
+type treeSearch struct {
+	game        *Game
+	tree        *node
+	rand        *rand.Rand
+	simulations int
+}
+
+// runRound shares out simulations among searches and runs them at once,
+// returning the simulations run and whether a search reached the deadline
+func runRound(ctx context.Context, searches []*treeSearch, simulations int, opts Options, deadline convergence.Deadline) (int, bool, error) {
+	done := make([]int, len(searches))
+	timedOut := make([]bool, len(searches))
+	errs := make([]error, len(searches))
+	var wg sync.WaitGroup
+	for i, s := range searches {
+		share := simulations / len(searches)
+		if i < simulations%len(searches) {
+			share++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done[i], timedOut[i], errs[i] = s.run(ctx, share, opts, deadline)
+		}()
+	}
+	wg.Wait()
+
+	total, stopped := 0, false
+	for i := range searches {
+		if errs[i] != nil {
+			return total, false, errs[i]
+		}
+		total += done[i]
+		stopped = stopped || timedOut[i]
+	}
+	return total, stopped, nil
+}
+
+// run runs simulations on the tree of s, returning the simulations run and
+// whether it stopped at the deadline
+func (s *treeSearch) run(ctx context.Context, simulations int, opts Options, deadline convergence.Deadline) (int, bool, error) {
+	g := s.game
+	for done := 0; done < simulations; done++ {
+		if err := ctx.Err(); err != nil {
+			return done, false, err
+		}
+		if deadline.Passed() {
+			return done, true, nil
+		}
+		s.simulations++
+
+		// Select
+		path := []*node{s.tree}
+		current := s.tree
+		for len(current.children) == len(g.next[current.state]) && len(current.children) > 0 && current.depth < opts.MaxDepth {
+			current = g.selectChild(current, opts.ExplorationConstant)
+			path = append(path, current)
+		}
+
+		// Expand
+		if len(current.children) < len(g.next[current.state]) && current.depth < opts.MaxDepth {
+			move := len(current.children)
+			child := &node{state: g.next[current.state][move], depth: current.depth + 1, move: move, expanded: s.simulations}
+			current.children = append(current.children, child)
+			current = child
+			path = append(path, current)
+		}
+
+		// Play out and back up
+		reward := g.playout(current.state, opts.MaxDepth-current.depth, s.rand)
+		for _, n := range path {
+			n.visits++
+			n.total += reward
+		}
+		if len(path) > 1 {
+			path[1].rewards = append(path[1].rewards, reward)
+		}
+	}
+	return simulations, false, nil
+}
+
+// merge returns the tree adding up the visits and rewards of trees searched
+// from the same root, down to levels below the root, or all the way when
+// levels is negative. Trees expand the moves of a state in the same order,
+// so their children line up.
+func merge(trees []*node, levels int) *node {
+	if len(trees) == 1 {
+		return trees[0]
+	}
+
+	merged := &node{state: trees[0].state, depth: trees[0].depth, move: trees[0].move, expanded: trees[0].expanded}
+	var children [][]*node
+	for _, tree := range trees {
+		merged.expanded = min(merged.expanded, tree.expanded)
+		merged.visits += tree.visits
+		merged.total += tree.total
+		merged.rewards = append(merged.rewards, tree.rewards...)
+		for i, child := range tree.children {
+			if i == len(children) {
+				children = append(children, nil)
+			}
+			children[i] = append(children[i], child)
+		}
+	}
+	if levels != 0 {
+		for _, group := range children {
+			merged.children = append(merged.children, merge(group, levels-1))
+		}
+	}
+	return merged
+}
+
+// measure returns the number of nodes of tree and the depth of its deepest
+// node
+func measure(tree *node) (int, int) {
+	nodes, depth := 1, tree.depth
+	for _, child := range tree.children {
+		n, d := measure(child)
+		nodes, depth = nodes+n, max(depth, d)
+	}
+	return nodes, depth
+}
+
+// describe sets the size of tree and the statistics of the moves from its
+// root, the principal variation and the best move of result
+func (g *Game) describe(tree *node, result *Result) {
+	result.Nodes, result.Depth = measure(tree)
+	moves := g.states[tree.state].Moves
+	result.Actions = make([]ActionStats, len(moves))
+	for i, move := range moves {
+		result.Actions[i].Move = move.Name
+		if i < len(tree.children) && tree.children[i].visits > 0 {
+			result.Actions[i].Visits = tree.children[i].visits
+			result.Actions[i].Q = tree.children[i].total / float64(tree.children[i].visits)
+		}
+	}
+
+	for current := tree; len(current.children) > 0; {
+		best := mostVisited(current)
+		if current.children[best].visits == 0 {
+			break
+		}
+		result.PrincipalVariation = append(result.PrincipalVariation, g.states[current.state].Moves[best].Name)
+		current = current.children[best]
+	}
+	if len(result.PrincipalVariation) > 0 {
+		result.BestMove = result.PrincipalVariation[0]
+	}
+}
+
+// export returns the most visited nodes of tree, up to limit of them. A node
+// has at least the visits of each of its children, so the nodes kept, taken
+// by visits and then depth, hang together from the root.
+func (g *Game) export(tree *node, limit int) *TreeNode {
+	var nodes []*node
+	var collect func(n *node)
+	collect = func(n *node) {
+		nodes = append(nodes, n)
+		for _, child := range n.children {
+			if child.visits > 0 {
+				collect(child)
+			}
+		}
+	}
+	collect(tree)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].visits != nodes[j].visits {
+			return nodes[i].visits > nodes[j].visits
+		}
+		return nodes[i].depth < nodes[j].depth
+	})
+	kept := make(map[*node]bool, limit)
+	for _, n := range nodes[:min(limit, len(nodes))] {
+		kept[n] = true
+	}
+
+	var build func(n *node, move string) *TreeNode
+	build = func(n *node, move string) *TreeNode {
+		exported := &TreeNode{State: g.states[n.state].Name, Move: move, Depth: n.depth, Expanded: n.expanded, Visits: n.visits}
+		if n.visits > 0 {
+			exported.Q = n.total / float64(n.visits)
+		}
+		for _, child := range n.children {
+			if kept[child] {
+				exported.Children = append(exported.Children, build(child, g.states[n.state].Moves[child.move].Name))
+			}
+		}
+		return exported
+	}
+	return build(tree, "")
+}
+
+// confidence returns the share of confidenceResamples bootstrap resamples
+// of the rollouts through each move from the root of tree in which the move
+// at index best has the best mean reward for the player to move, or 0 while
+// a move has no rollouts
+func (g *Game) confidence(tree *node, best int, r *rand.Rand) float64 {
+	if len(tree.children) < len(g.next[tree.state]) {
+		return 0
+	}
+	for _, child := range tree.children {
+		if len(child.rewards) == 0 {
+			return 0
+		}
+	}
+	if len(tree.children) == 1 {
+		return 1
+	}
+	sign := 1.0
+	if g.states[tree.state].Player == SecondPlayer {
+		sign = -1
+	}
+
+	means := make([]float64, len(tree.children))
+	held := 0
+	for resample := 0; resample < confidenceResamples; resample++ {
+		for i, child := range tree.children {
+			total := 0.0
+			for range child.rewards {
+				total += child.rewards[r.Intn(len(child.rewards))]
+			}
+			means[i] = sign * total / float64(len(child.rewards))
+		}
+		beaten := false
+		for i, mean := range means {
+			beaten = beaten || i != best && mean > means[best]
+		}
+		if !beaten {
+			held++
+		}
+	}
+	return float64(held) / confidenceResamples
+}
+
+// selectChild returns the child of n with the highest UCT score for the
+// player to move in n
+func (g *Game) selectChild(n *node, explorationConstant float64) *node {
+	sign := 1.0
+	if g.states[n.state].Player == SecondPlayer {
+		sign = -1
+	}
+
+	var best *node
+	bestScore := math.Inf(-1)
+	logVisits := math.Log(float64(n.visits))
+	for _, child := range n.children {
+		score := sign*child.total/float64(child.visits) + explorationConstant*math.Sqrt(logVisits/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
+
+// playout plays random moves from state until a terminal state or for at
+// most depth moves, returning the reward of the state it stops in
+func (g *Game) playout(state, depth int, r *rand.Rand) float64 {
+	for ; depth > 0 && len(g.next[state]) > 0; depth-- {
+		state = g.next[state][r.Intn(len(g.next[state]))]
+	}
+	return g.states[state].Reward
+}
+
+// mostVisited returns the index of the most visited child of n, preferring
+// the first on ties
+func mostVisited(n *node) int {
+	best := 0
+	for i, child := range n.children {
+		if child.visits > n.children[best].visits {
+			best = i
+		}
+	}
+	return best
+}
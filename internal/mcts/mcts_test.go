@@ -0,0 +1,216 @@
+package mcts
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func search(t *testing.T, states []State, root string) *Result {
+	t.Helper()
+	game, err := NewGame(states)
+	require.NoError(t, err)
+	result, err := Search(context.Background(), game, Options{
+		Root:                root,
+		Simulations:         2000,
+		ExplorationConstant: 1.4,
+		MaxDepth:            10,
+		Rand:                rand.New(rand.NewSource(1)),
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestSearch_FindsPrincipalVariation(t *testing.T) {
+	result := search(t, []State{
+		{Name: "start", Moves: []Move{{Name: "settle", NextState: "settled"}, {Name: "explore", NextState: "fork"}}},
+		{Name: "settled", Reward: 0.5},
+		{Name: "fork", Moves: []Move{{Name: "left", NextState: "lost"}, {Name: "right", NextState: "won"}}},
+		{Name: "lost", Reward: 0},
+		{Name: "won", Reward: 1},
+	}, "start")
+
+	assert.Equal(t, "explore", result.BestMove)
+	assert.Equal(t, []string{"explore", "right"}, result.PrincipalVariation)
+	assert.Equal(t, 2000, result.Simulations)
+	assert.Equal(t, 5, result.Nodes)
+	assert.Equal(t, 2, result.Depth)
+
+	require.Len(t, result.Actions, 2)
+	assert.Equal(t, "settle", result.Actions[0].Move)
+	assert.InDelta(t, 0.5, result.Actions[0].Q, 1e-9)
+	assert.Greater(t, result.Actions[1].Visits, result.Actions[0].Visits)
+	assert.Greater(t, result.Actions[1].Q, 0.9)
+}
+
+func TestSearch_ExportsTheMostVisitedNodes(t *testing.T) {
+	game, err := NewGame([]State{
+		{Name: "start", Moves: []Move{{Name: "settle", NextState: "settled"}, {Name: "explore", NextState: "fork"}}},
+		{Name: "settled", Reward: 0.5},
+		{Name: "fork", Moves: []Move{{Name: "left", NextState: "lost"}, {Name: "right", NextState: "won"}}},
+		{Name: "lost", Reward: 0},
+		{Name: "won", Reward: 1},
+	})
+	require.NoError(t, err)
+	export := func(nodes int) *TreeNode {
+		result, err := Search(context.Background(), game, Options{
+			Root:                "start",
+			Simulations:         2000,
+			ExplorationConstant: 1.4,
+			MaxDepth:            10,
+			TreeNodes:           nodes,
+			Rand:                rand.New(rand.NewSource(1)),
+		})
+		require.NoError(t, err)
+		return result.Tree
+	}
+
+	// The principal variation is what three nodes keep
+	tree := export(3)
+	require.NotNil(t, tree)
+	assert.Equal(t, "start", tree.State)
+	assert.Equal(t, 0, tree.Expanded)
+	assert.Equal(t, 2000, tree.Visits)
+	require.Len(t, tree.Children, 1)
+	explore := tree.Children[0]
+	assert.Equal(t, "explore", explore.Move)
+	assert.Equal(t, "fork", explore.State)
+	require.Len(t, explore.Children, 1)
+	right := explore.Children[0]
+	assert.Equal(t, "right", right.Move)
+	assert.Equal(t, 2, right.Depth)
+	assert.Greater(t, right.Expanded, explore.Expanded)
+	assert.InDelta(t, 1, right.Q, 1e-9)
+
+	tree = export(100)
+	require.Len(t, tree.Children, 2)
+	assert.Equal(t, "settle", tree.Children[0].Move)
+	assert.Len(t, tree.Children[1].Children, 2)
+}
+
+func TestSearch_AssumesTheOpponentMinimizes(t *testing.T) {
+	result := search(t, []State{
+		{Name: "start", Moves: []Move{{Name: "draw", NextState: "drawn"}, {Name: "gamble", NextState: "reply"}}},
+		{Name: "drawn"},
+		{Name: "reply", Player: SecondPlayer, Moves: []Move{{Name: "punish", NextState: "lost"}, {Name: "blunder", NextState: "won"}}},
+		{Name: "lost", Reward: -1},
+		{Name: "won", Reward: 1},
+	}, "start")
+
+	// Random playouts rate the gamble even, but the opponent punishes it
+	assert.Equal(t, "draw", result.BestMove)
+	assert.Less(t, result.Actions[1].Q, -0.5)
+}
+
+func TestSearch_BootstrapsItsConfidence(t *testing.T) {
+	// Exploring wins for sure, so no resample of the rollouts overturns it
+	clear := search(t, []State{
+		{Name: "start", Moves: []Move{{Name: "settle", NextState: "settled"}, {Name: "explore", NextState: "fork"}}},
+		{Name: "settled", Reward: 0.5},
+		{Name: "fork", Moves: []Move{{Name: "left", NextState: "lost"}, {Name: "right", NextState: "won"}}},
+		{Name: "lost", Reward: 0},
+		{Name: "won", Reward: 1},
+	}, "start")
+	assert.Greater(t, clear.Confidence, 0.95)
+
+	// Two identical coin flips leave either move best in many resamples
+	coins := []State{
+		{Name: "start", Moves: []Move{{Name: "heads", NextState: "flip"}, {Name: "tails", NextState: "flip"}}},
+		{Name: "flip", Moves: []Move{{Name: "lose", NextState: "lost"}, {Name: "win", NextState: "won"}}},
+		{Name: "lost", Reward: 0},
+		{Name: "won", Reward: 1},
+	}
+	tossed := search(t, coins, "start")
+	assert.Greater(t, tossed.Confidence, 0.05)
+	assert.Less(t, tossed.Confidence, 0.95)
+
+	// A move never played out cannot be ruled out
+	game, err := NewGame(coins)
+	require.NoError(t, err)
+	result, err := Search(context.Background(), game, Options{Root: "start", Simulations: 1, ExplorationConstant: 1.4, MaxDepth: 10, Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+	assert.Zero(t, result.Confidence)
+}
+
+func TestSearch_ReportsProgress(t *testing.T) {
+	game, err := NewGame([]State{
+		{Name: "start", Moves: []Move{{Name: "settle", NextState: "settled"}, {Name: "explore", NextState: "won"}}},
+		{Name: "settled", Reward: 0.5},
+		{Name: "won", Reward: 1},
+	})
+	require.NoError(t, err)
+
+	var snapshots []*Result
+	result, err := Search(context.Background(), game, Options{
+		Root:                "start",
+		Simulations:         1000,
+		ExplorationConstant: 1.4,
+		MaxDepth:            10,
+		ProgressInterval:    250,
+		Progress:            func(r *Result) { snapshots = append(snapshots, r) },
+		Rand:                rand.New(rand.NewSource(1)),
+	})
+	require.NoError(t, err)
+
+	// The last interval ends with the search itself
+	require.Len(t, snapshots, 3)
+	for i, snapshot := range snapshots {
+		assert.Equal(t, 250*(i+1), snapshot.Simulations)
+		assert.Equal(t, "explore", snapshot.BestMove)
+		assert.Len(t, snapshot.Actions, 2)
+	}
+	assert.Equal(t, 1000, result.Simulations)
+}
+
+func TestSearch_MergesParallelTrees(t *testing.T) {
+	game, err := NewGame([]State{
+		{Name: "start", Moves: []Move{{Name: "settle", NextState: "settled"}, {Name: "explore", NextState: "fork"}}},
+		{Name: "settled", Reward: 0.5},
+		{Name: "fork", Moves: []Move{{Name: "left", NextState: "lost"}, {Name: "right", NextState: "won"}}},
+		{Name: "lost", Reward: 0},
+		{Name: "won", Reward: 1},
+	})
+	require.NoError(t, err)
+	run := func() *Result {
+		result, err := Search(context.Background(), game, Options{
+			Root:                "start",
+			Simulations:         2001,
+			ExplorationConstant: 1.4,
+			MaxDepth:            10,
+			Parallelism:         4,
+			Rand:                rand.New(rand.NewSource(1)),
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := run()
+	assert.Equal(t, []string{"explore", "right"}, result.PrincipalVariation)
+	assert.Equal(t, 2001, result.Simulations)
+	assert.Equal(t, 2001, result.Actions[0].Visits+result.Actions[1].Visits)
+	assert.Equal(t, 5, result.Nodes)
+	assert.True(t, result.Converged)
+	assert.Equal(t, result, run(), "the same seed searches the same trees")
+}
+
+func TestSearch_RejectsInvalidGames(t *testing.T) {
+	_, err := NewGame([]State{{Name: "a", Moves: []Move{{Name: "x", NextState: "nowhere"}}}})
+	assert.Error(t, err)
+	_, err = NewGame([]State{{Name: "a"}, {Name: "a"}})
+	assert.Error(t, err)
+
+	game, err := NewGame([]State{{Name: "a"}})
+	require.NoError(t, err)
+	_, err = Search(context.Background(), game, Options{Root: "a", Simulations: 1, MaxDepth: 1, Rand: rand.New(rand.NewSource(1))})
+	assert.Error(t, err, "terminal root")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	game, err = NewGame([]State{{Name: "a", Moves: []Move{{Name: "x", NextState: "a"}}}})
+	require.NoError(t, err)
+	_, err = Search(ctx, game, Options{Root: "a", Simulations: 1, MaxDepth: 1, Rand: rand.New(rand.NewSource(1))})
+	assert.ErrorIs(t, err, context.Canceled)
+}
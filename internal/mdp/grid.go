@@ -0,0 +1,154 @@
+package mdp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Grid cells
+const (
+	cellOpen  = '.'
+	cellWall  = '#'
+	cellStart = 'S'
+	cellGoal  = 'G'
+	cellPit   = 'X'
+)
+
+// gridMoves are the actions of a grid world with their row and column steps
+var gridMoves = []struct {
+	action, arrow string
+	dr, dc        int
+}{
+	{"up", "^", -1, 0},
+	{"down", "v", 1, 0},
+	{"left", "<", 0, -1},
+	{"right", ">", 0, 1},
+}
+
+// Grid is a grid world. Layout holds one string per row, one cell per
+// character: '.' is open, '#' a wall, 'S' the open start cell, 'G' a goal and
+// 'X' a pit; goals and pits end an episode. Each step moves up, down, left or
+// right, staying put against a wall or the edge.
+type Grid struct {
+	Layout []string
+	// StepReward is the reward of a step into an open cell, GoalReward of
+	// one into a goal and PitReward of one into a pit
+	StepReward float64
+	GoalReward float64
+	PitReward  float64
+	// Slip is the chance a move goes to either side of the direction chosen
+	// instead, split evenly between the two
+	Slip float64
+}
+
+// GridState returns the name of the state of the cell at row and column
+func GridState(row, column int) string {
+	return fmt.Sprintf("%d,%d", row, column)
+}
+
+// Model returns the model of g and the state of its start cell, empty if it
+// has none
+func (g Grid) Model() (*Model, string, error) {
+	if len(g.Layout) == 0 {
+		return nil, "", errors.New("the grid has no rows")
+	}
+	if g.Slip < 0 || g.Slip > 1 {
+		return nil, "", fmt.Errorf("slip %v is outside [0, 1]", g.Slip)
+	}
+
+	cell := func(row, column int) byte {
+		if row < 0 || row >= len(g.Layout) || column < 0 || column >= len(g.Layout[row]) {
+			return cellWall
+		}
+		return g.Layout[row][column]
+	}
+
+	var transitions []Transition
+	start := ""
+	for row, line := range g.Layout {
+		for column := 0; column < len(line); column++ {
+			switch line[column] {
+			case cellStart:
+				if start != "" {
+					return nil, "", errors.New("the grid has more than one start cell")
+				}
+				start = GridState(row, column)
+			case cellOpen:
+			case cellWall, cellGoal, cellPit:
+				continue
+			default:
+				return nil, "", fmt.Errorf("cell %s is %q, not one of .#SGX", GridState(row, column), line[column])
+			}
+
+			for i, move := range gridMoves {
+				// Slipping moves to either side of the direction chosen
+				sides := [2]int{2, 3}
+				if i >= 2 {
+					sides = [2]int{0, 1}
+				}
+				outcomes := []struct {
+					move        int
+					probability float64
+				}{{i, 1 - g.Slip}, {sides[0], g.Slip / 2}, {sides[1], g.Slip / 2}}
+
+				for _, outcome := range outcomes {
+					if outcome.probability == 0 {
+						continue
+					}
+					m := gridMoves[outcome.move]
+					nextRow, nextColumn := row+m.dr, column+m.dc
+					if cell(nextRow, nextColumn) == cellWall {
+						nextRow, nextColumn = row, column
+					}
+					reward := g.StepReward
+					switch cell(nextRow, nextColumn) {
+					case cellGoal:
+						reward = g.GoalReward
+					case cellPit:
+						reward = g.PitReward
+					}
+					transitions = append(transitions, Transition{
+						State:       GridState(row, column),
+						Action:      move.action,
+						NextState:   GridState(nextRow, nextColumn),
+						Probability: outcome.probability,
+						Reward:      reward,
+					})
+				}
+			}
+		}
+	}
+	if len(transitions) == 0 {
+		return nil, "", errors.New("the grid has no open cells")
+	}
+
+	m, err := NewModel(transitions)
+	if err != nil {
+		return nil, "", err
+	}
+	return m, start, nil
+}
+
+// Render draws policy over g: the arrow of each open cell's action, with
+// walls, goals and pits as in the layout
+func (g Grid) Render(policy map[string]string) []string {
+	arrows := make(map[string]string, len(gridMoves))
+	for _, move := range gridMoves {
+		arrows[move.action] = move.arrow
+	}
+
+	rendered := make([]string, len(g.Layout))
+	for row, line := range g.Layout {
+		var b strings.Builder
+		for column := 0; column < len(line); column++ {
+			if arrow, ok := arrows[policy[GridState(row, column)]]; ok {
+				b.WriteString(arrow)
+			} else {
+				b.WriteByte(line[column])
+			}
+		}
+		rendered[row] = b.String()
+	}
+	return rendered
+}
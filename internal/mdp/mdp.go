@@ -0,0 +1,397 @@
+// Package mdp solves finite Markov decision processes by dynamic programming
+// and learns them by tabular Q-learning. A model is given as its transitions:
+// the probability and reward of reaching each next state when taking an
+// action in a state, or is estimated from observed transitions. Value
+// iteration and policy iteration both return the optimal policy, its value
+// function and the Q-values of every action, with a report of how the solver
+// converged; Q-learning returns the policy learned over simulated episodes
+// with its learning curve.
+package mdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+)
+
+// Solver methods
+const (
+	ValueIteration  = "value_iteration"
+	PolicyIteration = "policy_iteration"
+)
+
+// probabilityTolerance is how far the outcome probabilities of an action may
+// sum from 1
+const probabilityTolerance = 1e-6
+
+// Transition is one outcome of taking Action in State: reaching NextState
+// with Probability, for Reward
+type Transition struct {
+	State       string
+	Action      string
+	NextState   string
+	Probability float64
+	Reward      float64
+}
+
+// outcome is a transition as the solvers use it
+type outcome struct {
+	next        int
+	probability float64
+	reward      float64
+}
+
+// Model is a finite MDP. States without transitions are terminal: they are
+// worth nothing and have no action.
+type Model struct {
+	states []string
+	index  map[string]int
+	// actions holds the names of the actions of each state, sorted, and
+	// outcomes their outcomes in the same order
+	actions  [][]string
+	outcomes [][][]outcome
+}
+
+// NewModel returns the model of transitions. The outcome probabilities of
+// each action of a state must sum to 1.
+func NewModel(transitions []Transition) (*Model, error) {
+	if len(transitions) == 0 {
+		return nil, errors.New("the model has no transitions")
+	}
+
+	m := &Model{index: make(map[string]int)}
+	stateIndex := func(name string) int {
+		i, ok := m.index[name]
+		if !ok {
+			i = len(m.states)
+			m.index[name] = i
+			m.states = append(m.states, name)
+		}
+		return i
+	}
+
+	byAction := make(map[int]map[string][]outcome)
+	for _, t := range transitions {
+		switch {
+		case t.State == "" || t.NextState == "":
+			return nil, errors.New("transitions must name their state and next state")
+		case t.Action == "":
+			return nil, fmt.Errorf("a transition from %s names no action", t.State)
+		case t.Probability < 0 || t.Probability > 1 || math.IsNaN(t.Probability):
+			return nil, fmt.Errorf("transition %s -%s-> %s has probability %v outside [0, 1]", t.State, t.Action, t.NextState, t.Probability)
+		case math.IsNaN(t.Reward) || math.IsInf(t.Reward, 0):
+			return nil, fmt.Errorf("transition %s -%s-> %s has no finite reward", t.State, t.Action, t.NextState)
+		}
+
+		state, next := stateIndex(t.State), stateIndex(t.NextState)
+		if byAction[state] == nil {
+			byAction[state] = make(map[string][]outcome)
+		}
+		byAction[state][t.Action] = append(byAction[state][t.Action], outcome{next: next, probability: t.Probability, reward: t.Reward})
+	}
+
+	m.actions = make([][]string, len(m.states))
+	m.outcomes = make([][][]outcome, len(m.states))
+	for state, actions := range byAction {
+		for action := range actions {
+			m.actions[state] = append(m.actions[state], action)
+		}
+		sort.Strings(m.actions[state])
+
+		for _, action := range m.actions[state] {
+			total := 0.0
+			for _, o := range actions[action] {
+				total += o.probability
+			}
+			if math.Abs(total-1) > probabilityTolerance {
+				return nil, fmt.Errorf("the outcomes of %s in %s have probabilities summing to %v, not 1", action, m.states[state], total)
+			}
+			m.outcomes[state] = append(m.outcomes[state], actions[action])
+		}
+	}
+
+	return m, nil
+}
+
+// States returns the names of the model's states, in the order the
+// transitions first name them
+func (m *Model) States() []string {
+	return append([]string(nil), m.states...)
+}
+
+// Actions returns the names of every action of the model, sorted
+func (m *Model) Actions() []string {
+	seen := make(map[string]bool)
+	var actions []string
+	for _, stateActions := range m.actions {
+		for _, action := range stateActions {
+			if !seen[action] {
+				seen[action] = true
+				actions = append(actions, action)
+			}
+		}
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Options control a solver
+type Options struct {
+	// Gamma discounts future rewards, from 0 to 1
+	Gamma float64
+	// Tolerance is the largest change in any state's value at which the
+	// values have converged
+	Tolerance float64
+	// MaxIterations bounds the sweeps of value iteration, or the policy
+	// improvements of policy iteration and the sweeps of each evaluation
+	MaxIterations int
+	// TimeLimit, when positive, stops the solver early with the greedy
+	// policy of the values reached
+	TimeLimit time.Duration
+	// Progress, when set, receives the solution so far every
+	// ProgressInterval iterations: the greedy policy of the values reached
+	ProgressInterval int
+	Progress         func(*Solution)
+}
+
+// Solution is the optimal policy of a model and how the solver reached it
+type Solution struct {
+	// Policy maps each non-terminal state to its best action
+	Policy map[string]string
+	// Values maps each state to its value under the policy
+	Values map[string]float64
+	// QValues maps each non-terminal state to the value of each of its actions
+	QValues map[string]map[string]float64
+
+	Method     string
+	Iterations int
+	// Converged reports whether the solver stopped because the values (or,
+	// for policy iteration, the policy) stopped changing rather than at
+	// MaxIterations or TimeLimit
+	Converged bool
+	// StoppingReason is why the solver stopped, one of the convergence
+	// package's reasons
+	StoppingReason string
+	// Residual is the largest change in any state's value in the last sweep,
+	// and Residuals its value after each iteration
+	Residual  float64
+	Residuals []float64
+}
+
+// Solve solves m with method, either ValueIteration or PolicyIteration. It
+// returns ctx's error if ctx ends first.
+func Solve(ctx context.Context, m *Model, method string, opts Options) (*Solution, error) {
+	if opts.Gamma < 0 || opts.Gamma > 1 || math.IsNaN(opts.Gamma) {
+		return nil, fmt.Errorf("gamma %v is outside [0, 1]", opts.Gamma)
+	}
+	if opts.Tolerance <= 0 {
+		return nil, errors.New("tolerance must be positive")
+	}
+	if opts.MaxIterations <= 0 {
+		return nil, errors.New("max iterations must be positive")
+	}
+
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+	switch method {
+	case ValueIteration:
+		return m.valueIteration(ctx, opts, deadline)
+	case PolicyIteration:
+		return m.policyIteration(ctx, opts, deadline)
+	}
+	return nil, fmt.Errorf("unknown method %q", method)
+}
+
+// valueIteration applies the Bellman optimality update to every state until
+// no value changes by more than the tolerance
+func (m *Model) valueIteration(ctx context.Context, opts Options, deadline convergence.Deadline) (*Solution, error) {
+	values := make([]float64, len(m.states))
+	solution := &Solution{Method: ValueIteration, StoppingReason: convergence.MaxIterations}
+
+	for solution.Iterations < opts.MaxIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if solution.Iterations > 0 && deadline.Passed() {
+			solution.StoppingReason = convergence.TimeLimit
+			break
+		}
+
+		updated := make([]float64, len(values))
+		residual := 0.0
+		for state := range m.states {
+			if len(m.actions[state]) > 0 {
+				_, updated[state] = m.best(state, values, opts.Gamma)
+			}
+			residual = math.Max(residual, math.Abs(updated[state]-values[state]))
+		}
+		values = updated
+		solution.Iterations++
+		solution.Residual = residual
+		solution.Residuals = append(solution.Residuals, residual)
+
+		if residual <= opts.Tolerance {
+			solution.Converged = true
+			break
+		}
+		if opts.progressDue(solution.Iterations) {
+			m.reportProgress(solution, m.greedy(values, opts.Gamma), values, opts)
+		}
+	}
+	if solution.Converged {
+		solution.StoppingReason = convergence.Tolerance
+	}
+
+	m.report(solution, m.greedy(values, opts.Gamma), values, opts.Gamma)
+	return solution, nil
+}
+
+// greedy returns the best action of every non-terminal state under values
+func (m *Model) greedy(values []float64, gamma float64) []int {
+	policy := make([]int, len(m.states))
+	for state := range m.states {
+		if len(m.actions[state]) > 0 {
+			policy[state], _ = m.best(state, values, gamma)
+		}
+	}
+	return policy
+}
+
+// progressDue reports whether the solution after iteration is due to opts'
+// progress function
+func (opts Options) progressDue(iteration int) bool {
+	return opts.Progress != nil && opts.ProgressInterval > 0 && iteration%opts.ProgressInterval == 0
+}
+
+// reportProgress sends opts' progress function a copy of solution with
+// policy and values
+func (m *Model) reportProgress(solution *Solution, policy []int, values []float64, opts Options) {
+	snapshot := *solution
+	snapshot.Residuals = append([]float64(nil), solution.Residuals...)
+	m.report(&snapshot, policy, values, opts.Gamma)
+	opts.Progress(&snapshot)
+}
+
+// policyIteration alternates evaluating the current policy and improving it
+// greedily until the policy no longer changes
+func (m *Model) policyIteration(ctx context.Context, opts Options, deadline convergence.Deadline) (*Solution, error) {
+	values := make([]float64, len(m.states))
+	policy := make([]int, len(m.states))
+	solution := &Solution{Method: PolicyIteration, StoppingReason: convergence.MaxIterations}
+
+	for solution.Iterations < opts.MaxIterations {
+		if solution.Iterations > 0 && deadline.Passed() {
+			solution.StoppingReason = convergence.TimeLimit
+			break
+		}
+		residual, err := m.evaluate(ctx, policy, values, opts, deadline)
+		if err != nil {
+			return nil, err
+		}
+		solution.Iterations++
+		solution.Residual = residual
+		solution.Residuals = append(solution.Residuals, residual)
+
+		stable := true
+		for state := range m.states {
+			if len(m.actions[state]) == 0 {
+				continue
+			}
+			// Only switch to an action that is strictly better, so ties
+			// cannot make the policy cycle
+			action, value := m.best(state, values, opts.Gamma)
+			if action != policy[state] && value > m.q(state, policy[state], values, opts.Gamma)+opts.Tolerance {
+				policy[state] = action
+				stable = false
+			}
+		}
+		if stable {
+			solution.Converged = residual <= opts.Tolerance
+			if !solution.Converged && deadline.Passed() {
+				// The evaluation ran out of time
+				solution.StoppingReason = convergence.TimeLimit
+			}
+			break
+		}
+		if opts.progressDue(solution.Iterations) {
+			m.reportProgress(solution, policy, values, opts)
+		}
+	}
+	if solution.Converged {
+		solution.StoppingReason = convergence.PolicyStable
+	}
+
+	m.report(solution, policy, values, opts.Gamma)
+	return solution, nil
+}
+
+// evaluate updates values in place towards the values of policy, sweeping
+// until no value changes by more than the tolerance, and returns the largest
+// change of the last sweep. It stops early once deadline passes.
+func (m *Model) evaluate(ctx context.Context, policy []int, values []float64, opts Options, deadline convergence.Deadline) (float64, error) {
+	residual := math.Inf(1)
+	for sweep := 0; sweep < opts.MaxIterations && residual > opts.Tolerance; sweep++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if sweep > 0 && deadline.Passed() {
+			break
+		}
+
+		residual = 0
+		for state := range m.states {
+			if len(m.actions[state]) == 0 {
+				continue
+			}
+			value := m.q(state, policy[state], values, opts.Gamma)
+			residual = math.Max(residual, math.Abs(value-values[state]))
+			values[state] = value
+		}
+	}
+	return residual, nil
+}
+
+// q returns the expected return of taking the action at index action in
+// state and then following values
+func (m *Model) q(state, action int, values []float64, gamma float64) float64 {
+	total := 0.0
+	for _, o := range m.outcomes[state][action] {
+		total += o.probability * (o.reward + gamma*values[o.next])
+	}
+	return total
+}
+
+// best returns the index and value of the action of state with the highest
+// Q-value, preferring the first in name order on ties
+func (m *Model) best(state int, values []float64, gamma float64) (int, float64) {
+	bestAction, bestValue := 0, math.Inf(-1)
+	for action := range m.actions[state] {
+		if value := m.q(state, action, values, gamma); value > bestValue {
+			bestAction, bestValue = action, value
+		}
+	}
+	return bestAction, bestValue
+}
+
+// report fills in the policy, values and Q-values of solution by name
+func (m *Model) report(solution *Solution, policy []int, values []float64, gamma float64) {
+	solution.Policy = make(map[string]string)
+	solution.Values = make(map[string]float64, len(m.states))
+	solution.QValues = make(map[string]map[string]float64)
+	for state, name := range m.states {
+		solution.Values[name] = values[state]
+		if len(m.actions[state]) == 0 {
+			continue
+		}
+
+		solution.Policy[name] = m.actions[state][policy[state]]
+		solution.QValues[name] = make(map[string]float64, len(m.actions[state]))
+		for action, actionName := range m.actions[state] {
+			solution.QValues[name][actionName] = m.q(state, action, values, gamma)
+		}
+	}
+}
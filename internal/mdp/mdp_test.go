@@ -0,0 +1,252 @@
+package mdp
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cashOut is a model where staying in start earns 1 each step, while going
+// on earns nothing at first but 10 once cashed out
+var cashOut = []Transition{
+	{State: "start", Action: "stay", NextState: "start", Probability: 1, Reward: 1},
+	{State: "start", Action: "go", NextState: "ready", Probability: 0.8},
+	{State: "start", Action: "go", NextState: "start", Probability: 0.2},
+	{State: "ready", Action: "cash", NextState: "done", Probability: 1, Reward: 10},
+}
+
+func TestSolve_FindsOptimalPolicy(t *testing.T) {
+	model, err := NewModel(cashOut)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start", "ready", "done"}, model.States())
+	assert.Equal(t, []string{"cash", "go", "stay"}, model.Actions())
+
+	for _, method := range []string{ValueIteration, PolicyIteration} {
+		t.Run(method, func(t *testing.T) {
+			// Patient: staying forever is worth 1/(1-0.95) = 20
+			solution, err := Solve(context.Background(), model, method, Options{Gamma: 0.95, Tolerance: 1e-9, MaxIterations: 10000})
+			require.NoError(t, err)
+			assert.True(t, solution.Converged)
+			assert.Equal(t, method, solution.Method)
+			assert.Equal(t, map[string]string{"start": "stay", "ready": "cash"}, solution.Policy)
+			assert.InDelta(t, 20, solution.Values["start"], 1e-6)
+			assert.InDelta(t, 10, solution.Values["ready"], 1e-6)
+			assert.Equal(t, 0.0, solution.Values["done"])
+			// Going: 0.8 * 0.95 * 10 + 0.2 * 0.95 * 20
+			assert.InDelta(t, 11.4, solution.QValues["start"]["go"], 1e-6)
+			assert.NotContains(t, solution.QValues, "done")
+
+			// Impatient: staying is worth 2, going 4/0.9
+			solution, err = Solve(context.Background(), model, method, Options{Gamma: 0.5, Tolerance: 1e-9, MaxIterations: 10000})
+			require.NoError(t, err)
+			assert.Equal(t, "go", solution.Policy["start"])
+			assert.InDelta(t, 4/0.9, solution.Values["start"], 1e-6)
+		})
+	}
+}
+
+func TestSolve_ReportsUnconvergedAndCancelled(t *testing.T) {
+	model, err := NewModel(cashOut)
+	require.NoError(t, err)
+
+	solution, err := Solve(context.Background(), model, ValueIteration, Options{Gamma: 0.95, Tolerance: 1e-9, MaxIterations: 3})
+	require.NoError(t, err)
+	assert.False(t, solution.Converged)
+	assert.Equal(t, 3, solution.Iterations)
+	assert.Greater(t, solution.Residual, 1e-9)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Solve(ctx, model, PolicyIteration, Options{Gamma: 0.95, Tolerance: 1e-9, MaxIterations: 10})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = Solve(context.Background(), model, "q_learning", Options{Gamma: 0.95, Tolerance: 1e-9, MaxIterations: 10})
+	assert.Error(t, err)
+	_, err = Solve(context.Background(), model, ValueIteration, Options{Gamma: 1.5, Tolerance: 1e-9, MaxIterations: 10})
+	assert.Error(t, err)
+}
+
+func TestSolve_StopsAtTheTimeLimit(t *testing.T) {
+	model, err := NewModel(cashOut)
+	require.NoError(t, err)
+
+	for _, method := range []string{ValueIteration, PolicyIteration} {
+		solution, err := Solve(context.Background(), model, method, Options{Gamma: 0.999999, Tolerance: 1e-12, MaxIterations: 1000000, TimeLimit: time.Nanosecond})
+		require.NoError(t, err)
+		assert.False(t, solution.Converged, method)
+		assert.Equal(t, "time_limit", solution.StoppingReason, method)
+		assert.Equal(t, 1, solution.Iterations, method)
+		assert.Contains(t, solution.Policy, "start", method)
+	}
+
+	learned, err := Learn(context.Background(), model, LearningOptions{
+		Gamma:        0.5,
+		LearningRate: 0.2,
+		Epsilon:      1,
+		EpsilonDecay: 0.99,
+		MinEpsilon:   0.05,
+		Episodes:     1000000,
+		MaxSteps:     50,
+		TimeLimit:    time.Nanosecond,
+		Rand:         rand.New(rand.NewSource(1)),
+	})
+	require.NoError(t, err)
+	assert.False(t, learned.Converged)
+	assert.Equal(t, "time_limit", learned.StoppingReason)
+	assert.Len(t, learned.Episodes, 1)
+	assert.Contains(t, learned.Policy, "start")
+}
+
+func TestSolve_ReportsProgress(t *testing.T) {
+	model, err := NewModel(cashOut)
+	require.NoError(t, err)
+
+	var snapshots []*Solution
+	solution, err := Solve(context.Background(), model, ValueIteration, Options{
+		Gamma:            0.95,
+		Tolerance:        1e-9,
+		MaxIterations:    10000,
+		ProgressInterval: 100,
+		Progress:         func(s *Solution) { snapshots = append(snapshots, s) },
+	})
+	require.NoError(t, err)
+
+	require.Len(t, snapshots, solution.Iterations/100)
+	assert.Equal(t, 100, snapshots[0].Iterations)
+	assert.Len(t, snapshots[0].Residuals, 100)
+	assert.Equal(t, "stay", snapshots[0].Policy["start"])
+	// The values climb towards their fixed point
+	assert.Less(t, snapshots[0].Values["start"], snapshots[1].Values["start"])
+	assert.Less(t, snapshots[1].Values["start"], solution.Values["start"])
+}
+
+func TestNewModel_RejectsInvalidTransitions(t *testing.T) {
+	for name, transitions := range map[string][]Transition{
+		"empty":               nil,
+		"missing next state":  {{State: "a", Action: "x", Probability: 1}},
+		"missing action":      {{State: "a", NextState: "b", Probability: 1}},
+		"negative":            {{State: "a", Action: "x", NextState: "b", Probability: -0.5}},
+		"probabilities short": {{State: "a", Action: "x", NextState: "b", Probability: 0.5}},
+	} {
+		_, err := NewModel(transitions)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLearn_LearnsOptimalPolicy(t *testing.T) {
+	model, err := NewModel(cashOut)
+	require.NoError(t, err)
+
+	opts := LearningOptions{
+		Gamma:        0.5,
+		LearningRate: 0.2,
+		Epsilon:      1,
+		EpsilonDecay: 0.99,
+		MinEpsilon:   0.05,
+		Episodes:     500,
+		MaxSteps:     50,
+		StartState:   "start",
+		Rand:         rand.New(rand.NewSource(1)),
+	}
+	learned, err := Learn(context.Background(), model, opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"start": "go", "ready": "cash"}, learned.Policy)
+	assert.InDelta(t, 4/0.9, learned.Values["start"], 0.5)
+	assert.InDelta(t, 10, learned.QValues["ready"]["cash"], 1e-3)
+
+	// The learning curve records each episode as exploration decays
+	require.Len(t, learned.Episodes, 500)
+	assert.Equal(t, 1.0, learned.Episodes[0].Epsilon)
+	assert.Equal(t, 0.05, learned.Episodes[499].Epsilon)
+	assert.Equal(t, 10.0, learned.Episodes[499].Reward)
+
+	opts.StartState = "done"
+	_, err = Learn(context.Background(), model, opts)
+	assert.Error(t, err)
+}
+
+func TestModelFromSamples_EstimatesOutcomes(t *testing.T) {
+	model, err := ModelFromSamples([]Sample{
+		{State: "a", Action: "x", NextState: "b", Reward: 1},
+		{State: "a", Action: "x", NextState: "b", Reward: 3},
+		{State: "a", Action: "x", NextState: "a", Reward: 0},
+		{State: "a", Action: "x", NextState: "a", Reward: 0},
+	})
+	require.NoError(t, err)
+
+	// Half of the samples reach b, for a mean reward of 2
+	solution, err := Solve(context.Background(), model, ValueIteration, Options{Gamma: 0, Tolerance: 1e-9, MaxIterations: 10})
+	require.NoError(t, err)
+	assert.InDelta(t, 1, solution.Values["a"], 1e-9)
+
+	_, err = ModelFromSamples(nil)
+	assert.Error(t, err)
+}
+
+func TestLearn_SARSAAvoidsTheCliff(t *testing.T) {
+	// The shortest path runs along the pits; exploring next to them is risky
+	grid := Grid{
+		Layout: []string{
+			"....",
+			"....",
+			"SXXG",
+		},
+		StepReward: -1,
+		GoalReward: 10,
+		PitReward:  -100,
+	}
+	model, start, err := grid.Model()
+	require.NoError(t, err)
+	assert.Equal(t, "2,0", start)
+
+	for _, method := range []string{SARSA, ExpectedSARSA} {
+		t.Run(method, func(t *testing.T) {
+			learned, err := Learn(context.Background(), model, LearningOptions{
+				Method:       method,
+				Gamma:        1,
+				LearningRate: 0.5,
+				Epsilon:      0.1,
+				EpsilonDecay: 1,
+				MinEpsilon:   0.1,
+				Episodes:     1000,
+				MaxSteps:     100,
+				StartState:   start,
+				Rand:         rand.New(rand.NewSource(1)),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "up", learned.Policy[start])
+			assert.Equal(t, "down", learned.Policy["1,3"])
+			assert.Equal(t, "^XXG", grid.Render(learned.Policy)[2])
+		})
+	}
+
+	_, err = Learn(context.Background(), model, LearningOptions{Method: "td_lambda", Gamma: 1, LearningRate: 0.5, EpsilonDecay: 1, Episodes: 1, MaxSteps: 1, Rand: rand.New(rand.NewSource(1))})
+	assert.Error(t, err)
+}
+
+func TestGrid_ModelsSlipsAndWalls(t *testing.T) {
+	model, start, err := Grid{Layout: []string{"S#G"}, StepReward: -1, GoalReward: 1, Slip: 0.2}.Model()
+	require.NoError(t, err)
+	assert.Equal(t, "0,0", start)
+	assert.Equal(t, []string{"0,0"}, model.States())
+
+	// Every move bumps into a wall or the edge
+	solution, err := Solve(context.Background(), model, ValueIteration, Options{Gamma: 0.5, Tolerance: 1e-9, MaxIterations: 100})
+	require.NoError(t, err)
+	assert.InDelta(t, -2, solution.Values["0,0"], 1e-6)
+
+	for name, grid := range map[string]Grid{
+		"empty":      {},
+		"two starts": {Layout: []string{"SS"}},
+		"unknown":    {Layout: []string{"S?"}},
+		"slip":       {Layout: []string{"S."}, Slip: 2},
+		"walls":      {Layout: []string{"#G"}},
+	} {
+		_, _, err := grid.Model()
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,301 @@
+package mdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+)
+
+// Learning methods. Q-learning is off-policy: it updates towards the best
+// action of the state reached. SARSA updates towards the action the
+// epsilon-greedy policy takes next, and expected SARSA towards the policy's
+// expected value over all actions.
+const (
+	QLearning     = "q_learning"
+	SARSA         = "sarsa"
+	ExpectedSARSA = "expected_sarsa"
+)
+
+// Sample is one observed transition: taking Action in State reached
+// NextState for Reward
+type Sample struct {
+	State     string
+	Action    string
+	NextState string
+	Reward    float64
+}
+
+// outcomeKey identifies the outcome of an action in a state
+type outcomeKey struct {
+	state, action, next string
+}
+
+// ModelFromSamples returns the model estimated from observed transitions:
+// the probability of an outcome is the share of the samples of its state and
+// action that reached it, and its reward their mean reward
+func ModelFromSamples(samples []Sample) (*Model, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("there are no samples")
+	}
+
+	type tally struct {
+		count  int
+		reward float64
+	}
+	var order []outcomeKey
+	outcomes := make(map[outcomeKey]*tally)
+	actionCounts := make(map[[2]string]int)
+	for _, s := range samples {
+		if math.IsNaN(s.Reward) || math.IsInf(s.Reward, 0) {
+			return nil, fmt.Errorf("sample %s -%s-> %s has no finite reward", s.State, s.Action, s.NextState)
+		}
+		key := outcomeKey{s.State, s.Action, s.NextState}
+		if outcomes[key] == nil {
+			outcomes[key] = &tally{}
+			order = append(order, key)
+		}
+		outcomes[key].count++
+		outcomes[key].reward += s.Reward
+		actionCounts[[2]string{s.State, s.Action}]++
+	}
+
+	transitions := make([]Transition, len(order))
+	for i, key := range order {
+		t := outcomes[key]
+		transitions[i] = Transition{
+			State:       key.state,
+			Action:      key.action,
+			NextState:   key.next,
+			Probability: float64(t.count) / float64(actionCounts[[2]string{key.state, key.action}]),
+			Reward:      t.reward / float64(t.count),
+		}
+	}
+	return NewModel(transitions)
+}
+
+// LearningOptions control learning
+type LearningOptions struct {
+	// Method is QLearning, SARSA or ExpectedSARSA; when empty it is QLearning
+	Method string
+	// Gamma discounts future rewards, from 0 to 1
+	Gamma float64
+	// LearningRate weighs each update of a Q-value, from 0 (excluded) to 1
+	LearningRate float64
+	// Epsilon is the chance of exploring a random action in the first
+	// episode; after each episode it is multiplied by EpsilonDecay, down to
+	// MinEpsilon
+	Epsilon      float64
+	EpsilonDecay float64
+	MinEpsilon   float64
+	// Episodes is the number of episodes to run, each ending in a terminal
+	// state or after MaxSteps steps
+	Episodes int
+	MaxSteps int
+	// StartState is the state every episode starts in; when empty, each
+	// episode starts in a random non-terminal state
+	StartState string
+	// TimeLimit, when positive, stops learning early with the Q-values
+	// learned so far
+	TimeLimit time.Duration
+	// Rand is the source of randomness of the episodes
+	Rand *rand.Rand
+}
+
+// Episode reports one episode of learning
+type Episode struct {
+	// Reward is the undiscounted reward collected over the episode
+	Reward  float64
+	Steps   int
+	Epsilon float64
+	// Residual is the largest change of a Q-value over the episode
+	Residual float64
+}
+
+// Learned is the policy learning learned and how it got there
+type Learned struct {
+	// Policy maps each non-terminal state to its greedy action
+	Policy map[string]string
+	// Values maps each state to the Q-value of its greedy action
+	Values map[string]float64
+	// QValues maps each non-terminal state to the learned value of each of
+	// its actions
+	QValues map[string]map[string]float64
+	// Episodes is the learning curve, one entry per episode run
+	Episodes []Episode
+	// Converged reports whether the greedy policy held over the last tenth
+	// of the episodes; learning runs every episode unless it runs out of
+	// time, when it has not converged
+	Converged      bool
+	StoppingReason string
+}
+
+// Learn runs opts.Method on episodes simulated from m: each step takes an
+// epsilon-greedy action, samples its outcome from the model and moves the
+// action's Q-value towards the reward plus the discounted value of the state
+// reached, as the method estimates it. It returns ctx's error if ctx ends
+// first.
+func Learn(ctx context.Context, m *Model, opts LearningOptions) (*Learned, error) {
+	if opts.Method == "" {
+		opts.Method = QLearning
+	}
+	switch {
+	case opts.Method != QLearning && opts.Method != SARSA && opts.Method != ExpectedSARSA:
+		return nil, fmt.Errorf("unknown method %q", opts.Method)
+	case opts.Gamma < 0 || opts.Gamma > 1 || math.IsNaN(opts.Gamma):
+		return nil, fmt.Errorf("gamma %v is outside [0, 1]", opts.Gamma)
+	case opts.LearningRate <= 0 || opts.LearningRate > 1:
+		return nil, fmt.Errorf("learning rate %v is outside (0, 1]", opts.LearningRate)
+	case opts.Epsilon < 0 || opts.Epsilon > 1 || opts.MinEpsilon < 0 || opts.MinEpsilon > 1:
+		return nil, errors.New("epsilon and min epsilon must be within [0, 1]")
+	case opts.EpsilonDecay <= 0 || opts.EpsilonDecay > 1:
+		return nil, fmt.Errorf("epsilon decay %v is outside (0, 1]", opts.EpsilonDecay)
+	case opts.Episodes <= 0 || opts.MaxSteps <= 0:
+		return nil, errors.New("episodes and max steps must be positive")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+
+	var starts []int
+	if opts.StartState != "" {
+		start, ok := m.index[opts.StartState]
+		if !ok {
+			return nil, fmt.Errorf("start state %s is not a state of the model", opts.StartState)
+		}
+		starts = []int{start}
+	} else {
+		for state := range m.states {
+			if len(m.actions[state]) > 0 {
+				starts = append(starts, state)
+			}
+		}
+	}
+	if len(m.actions[starts[0]]) == 0 {
+		return nil, fmt.Errorf("start state %s is terminal", opts.StartState)
+	}
+
+	q := make([][]float64, len(m.states))
+	for state := range m.states {
+		q[state] = make([]float64, len(m.actions[state]))
+	}
+
+	learned := &Learned{Episodes: make([]Episode, opts.Episodes), StoppingReason: convergence.MaxIterations}
+	epsilon := opts.Epsilon
+	policy := make([]int, len(m.states))
+	lastChange := 0
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+	for episode := range learned.Episodes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Learning runs at least one episode, so that it has a curve
+		if episode > 0 && deadline.Passed() {
+			learned.Episodes = learned.Episodes[:episode]
+			learned.StoppingReason = convergence.TimeLimit
+			break
+		}
+
+		report := &learned.Episodes[episode]
+		report.Epsilon = epsilon
+		state := starts[opts.Rand.Intn(len(starts))]
+		action := explore(q[state], epsilon, opts.Rand)
+		for report.Steps < opts.MaxSteps && len(m.actions[state]) > 0 {
+			o := m.sample(state, action, opts.Rand)
+
+			target, next := o.reward, 0
+			if len(q[o.next]) > 0 {
+				next = explore(q[o.next], epsilon, opts.Rand)
+				switch opts.Method {
+				case QLearning:
+					target += opts.Gamma * q[o.next][greedy(q[o.next])]
+				case SARSA:
+					target += opts.Gamma * q[o.next][next]
+				case ExpectedSARSA:
+					target += opts.Gamma * expected(q[o.next], epsilon)
+				}
+			}
+			change := opts.LearningRate * (target - q[state][action])
+			q[state][action] += change
+			report.Residual = math.Max(report.Residual, math.Abs(change))
+
+			report.Reward += o.reward
+			report.Steps++
+			state, action = o.next, next
+		}
+
+		epsilon = math.Max(opts.MinEpsilon, epsilon*opts.EpsilonDecay)
+		for state := range q {
+			if len(q[state]) > 0 && greedy(q[state]) != policy[state] {
+				policy[state] = greedy(q[state])
+				lastChange = episode
+			}
+		}
+	}
+	learned.Converged = learned.StoppingReason != convergence.TimeLimit && opts.Episodes-1-lastChange >= max(1, opts.Episodes/10)
+
+	learned.Policy = make(map[string]string)
+	learned.Values = make(map[string]float64, len(m.states))
+	learned.QValues = make(map[string]map[string]float64)
+	for state, name := range m.states {
+		if len(q[state]) == 0 {
+			learned.Values[name] = 0
+			continue
+		}
+		best := greedy(q[state])
+		learned.Policy[name] = m.actions[state][best]
+		learned.Values[name] = q[state][best]
+		learned.QValues[name] = make(map[string]float64, len(q[state]))
+		for action, value := range q[state] {
+			learned.QValues[name][m.actions[state][action]] = value
+		}
+	}
+	return learned, nil
+}
+
+// explore returns the index of an epsilon-greedy action over values
+func explore(values []float64, epsilon float64, r *rand.Rand) int {
+	if r.Float64() < epsilon {
+		return r.Intn(len(values))
+	}
+	return greedy(values)
+}
+
+// expected returns the expected value of values under the epsilon-greedy
+// policy
+func expected(values []float64, epsilon float64) float64 {
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+	return (1-epsilon)*values[greedy(values)] + epsilon*mean
+}
+
+// greedy returns the index of the highest value, preferring the first on ties
+func greedy(values []float64) int {
+	best := 0
+	for i, value := range values {
+		if value > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// sample draws the outcome of the action at index action in state
+func (m *Model) sample(state, action int, r *rand.Rand) outcome {
+	outcomes := m.outcomes[state][action]
+	draw := r.Float64()
+	for _, o := range outcomes {
+		if draw < o.probability {
+			return o
+		}
+		draw -= o.probability
+	}
+	// Rounding can leave the draw just past the last outcome
+	return outcomes[len(outcomes)-1]
+}
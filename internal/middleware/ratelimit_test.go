@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_RefillsPerKey(t *testing.T) {
+	limiter := NewRateLimiter(2, 3)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.SetClock(func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow("a")
+		assert.True(t, allowed, "request %d within the burst", i+1)
+	}
+	allowed, wait := limiter.Allow("a")
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other keys have their own bucket
+	allowed, _ = limiter.Allow("b")
+	assert.True(t, allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _ = limiter.Allow("a")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("a")
+	assert.False(t, allowed)
+
+	assert.Nil(t, NewRateLimiter(0, 10))
+	allowed, _ = (*RateLimiter)(nil).Allow("a")
+	assert.True(t, allowed)
+}
+
+func TestRateLimit_RejectsWithRetryAfter(t *testing.T) {
+	handler := RateLimit(NewRateLimiter(1, 1))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/storage/stats", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNoContent, request("10.0.0.1:1234").Code)
+	rec := request("10.0.0.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusNoContent, request("10.0.0.2:1234").Code)
+}
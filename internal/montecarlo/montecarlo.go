@@ -0,0 +1,312 @@
+// Package montecarlo runs Monte Carlo simulations: each trial draws every
+// random variable from its distribution and evaluates an output over the
+// draws. A run summarizes the outputs by their mean, spread and percentiles,
+// a histogram and the probability of exceeding thresholds.
+package montecarlo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+	"github.com/rainmana/gothink/internal/parallel"
+	"github.com/rainmana/gothink/internal/randdist"
+	"github.com/rainmana/gothink/internal/stats"
+)
+
+// Distributions
+const (
+	Normal = "normal"
+	// LogNormal is given by its own mean and standard deviation, not those
+	// of its logarithm
+	LogNormal  = "lognormal"
+	Triangular = "triangular"
+	// Beta is scaled from [0, 1] to [Min, Max]
+	Beta     = "beta"
+	Discrete = "discrete"
+)
+
+// Variable is a random variable of a simulation
+type Variable struct {
+	Name         string
+	Distribution string
+	// Mean and StdDev parameterize Normal and LogNormal variables
+	Mean   float64
+	StdDev float64
+	// Min, Mode and Max parameterize Triangular variables; Min and Max also
+	// bound Beta variables
+	Min  float64
+	Mode float64
+	Max  float64
+	// Alpha and Beta are the shapes of Beta variables
+	Alpha float64
+	Beta  float64
+	// Values are the outcomes of a Discrete variable and Probabilities their
+	// chances, equal when empty
+	Values        []float64
+	Probabilities []float64
+}
+
+// Output evaluates the simulated quantity for the draws of a trial
+type Output func(draws map[string]float64) (float64, error)
+
+// Options control a run
+type Options struct {
+	Trials int
+	// Percentiles are reported from 0 to 100
+	Percentiles []float64
+	// Buckets is the number of histogram buckets
+	Buckets int
+	// Thresholds are the values whose chance of being exceeded is reported
+	Thresholds []float64
+	// Parallelism is the number of goroutines running trials, 1 when zero.
+	// The trials are drawn in chunks seeded from Rand, so the result does not
+	// depend on it, but Output must then be safe for concurrent use.
+	Parallelism int
+	// TimeLimit, when positive, stops the run early, summarizing the trials
+	// run so far
+	TimeLimit time.Duration
+	Rand      *rand.Rand
+}
+
+// Bucket is a histogram bucket: the outputs in [Low, High), or [Low, High]
+// for the last bucket
+type Bucket struct {
+	Low   float64
+	High  float64
+	Count int
+}
+
+// Percentile is the value below which a share of the outputs fall
+type Percentile struct {
+	Percentile float64
+	Value      float64
+}
+
+// Exceedance is the chance of the output exceeding a threshold
+type Exceedance struct {
+	Threshold   float64
+	Probability float64
+}
+
+// Result summarizes a run
+type Result struct {
+	Trials int
+	Mean   float64
+	StdDev float64
+	// StdError is the standard error of Mean
+	StdError    float64
+	Min         float64
+	Max         float64
+	Percentiles []Percentile
+	Histogram   []Bucket
+	Exceedances []Exceedance
+	// RHat is the Gelman-Rubin statistic of the trials split into 4
+	// consecutive chains, 0 when there are too few trials for it. The run
+	// has converged when it is below convergence.RHatThreshold; Residuals
+	// holds its distance from 1 as the trials accumulated. A run that ran
+	// out of time has not converged.
+	RHat           float64
+	Converged      bool
+	StoppingReason string
+	Residuals      []float64
+	// Outputs holds the output of each trial run, in trial order
+	Outputs []float64
+}
+
+// sampler draws values of a variable
+type sampler func(r *rand.Rand) float64
+
+// newSampler checks v and returns its sampler
+func newSampler(v Variable) (sampler, error) {
+	switch v.Distribution {
+	case Normal:
+		if v.StdDev < 0 {
+			return nil, errors.New("the standard deviation must not be negative")
+		}
+		return func(r *rand.Rand) float64 { return v.Mean + v.StdDev*r.NormFloat64() }, nil
+
+	case LogNormal:
+		if v.Mean <= 0 || v.StdDev < 0 {
+			return nil, errors.New("a lognormal needs a positive mean and a non-negative standard deviation")
+		}
+		variance := math.Log(1 + v.StdDev*v.StdDev/(v.Mean*v.Mean))
+		mu, sigma := math.Log(v.Mean)-variance/2, math.Sqrt(variance)
+		return func(r *rand.Rand) float64 { return math.Exp(mu + sigma*r.NormFloat64()) }, nil
+
+	case Triangular:
+		if !(v.Min <= v.Mode && v.Mode <= v.Max && v.Min < v.Max) {
+			return nil, errors.New("a triangular needs min <= mode <= max with min below max")
+		}
+		split := (v.Mode - v.Min) / (v.Max - v.Min)
+		return func(r *rand.Rand) float64 {
+			u := r.Float64()
+			if u < split {
+				return v.Min + math.Sqrt(u*(v.Max-v.Min)*(v.Mode-v.Min))
+			}
+			return v.Max - math.Sqrt((1-u)*(v.Max-v.Min)*(v.Max-v.Mode))
+		}, nil
+
+	case Beta:
+		if !(v.Alpha > 0 && v.Beta > 0) {
+			return nil, errors.New("a beta needs positive alpha and beta")
+		}
+		if !(v.Min < v.Max) {
+			return nil, errors.New("a beta needs min below max")
+		}
+		return func(r *rand.Rand) float64 {
+			return v.Min + (v.Max-v.Min)*randdist.Beta(r, v.Alpha, v.Beta)
+		}, nil
+
+	case Discrete:
+		if len(v.Values) == 0 {
+			return nil, errors.New("a discrete variable needs values")
+		}
+		cumulative := make([]float64, len(v.Values))
+		total := 0.0
+		for i := range v.Values {
+			p := 1.0
+			if len(v.Probabilities) > 0 {
+				if len(v.Probabilities) != len(v.Values) {
+					return nil, errors.New("a discrete variable needs a probability for each value")
+				}
+				if p = v.Probabilities[i]; p < 0 || math.IsNaN(p) {
+					return nil, errors.New("probabilities must not be negative")
+				}
+			}
+			total += p
+			cumulative[i] = total
+		}
+		if total == 0 {
+			return nil, errors.New("the probabilities sum to 0")
+		}
+		return func(r *rand.Rand) float64 {
+			u := r.Float64() * total
+			return v.Values[min(sort.SearchFloat64s(cumulative, u), len(v.Values)-1)]
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown distribution %q", v.Distribution)
+}
+
+// Simulate runs opts.Trials trials of output over variables. It returns
+// ctx's error if ctx ends first.
+func Simulate(ctx context.Context, variables []Variable, output Output, opts Options) (*Result, error) {
+	switch {
+	case len(variables) == 0:
+		return nil, errors.New("the simulation has no variables")
+	case output == nil:
+		return nil, errors.New("no output")
+	case opts.Trials <= 0:
+		return nil, errors.New("trials must be positive")
+	case opts.Buckets <= 0:
+		return nil, errors.New("buckets must be positive")
+	case opts.Parallelism < 0:
+		return nil, errors.New("parallelism must not be negative")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+	for _, p := range opts.Percentiles {
+		if !(p >= 0 && p <= 100) {
+			return nil, fmt.Errorf("percentile %v is outside [0, 100]", p)
+		}
+	}
+
+	samplers := make([]sampler, len(variables))
+	seen := make(map[string]bool, len(variables))
+	for i, v := range variables {
+		if v.Name == "" || seen[v.Name] {
+			return nil, errors.New("variables must have distinct names")
+		}
+		seen[v.Name] = true
+		var err error
+		if samplers[i], err = newSampler(v); err != nil {
+			return nil, fmt.Errorf("variable %s: %v", v.Name, err)
+		}
+	}
+
+	outputs := make([]float64, opts.Trials)
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+	err := parallel.Chunks(ctx, opts.Trials, parallel.ChunkSize, opts.Parallelism, opts.Rand, func(start, end int, r *rand.Rand) error {
+		// The first chunk always runs, so that there are trials to summarize
+		if start > 0 && deadline.Passed() {
+			return timeUp{trials: start}
+		}
+		draws := make(map[string]float64, len(variables))
+		for t := start; t < end; t++ {
+			for i, v := range variables {
+				draws[v.Name] = samplers[i](r)
+			}
+			value, err := output(draws)
+			if err != nil {
+				return fmt.Errorf("trial %d: %v", t+1, err)
+			}
+			outputs[t] = value
+		}
+		return nil
+	})
+	stoppingReason := convergence.MaxIterations
+	var stopped timeUp
+	if errors.As(err, &stopped) {
+		// Every chunk before the first to run out of time has run
+		outputs, err = outputs[:stopped.trials], nil
+		stoppingReason = convergence.TimeLimit
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := summarize(outputs, opts)
+	result.StoppingReason = stoppingReason
+	result.Outputs = outputs
+	result.RHat, result.Residuals = convergence.SplitRHat(outputs)
+	result.Converged = stoppingReason != convergence.TimeLimit && result.RHat > 0 && result.RHat < convergence.RHatThreshold
+	return result, nil
+}
+
+// timeUp stops the chunks of a run from the first that found the time limit
+// passed, the first of which is trials
+type timeUp struct {
+	trials int
+}
+
+func (timeUp) Error() string { return "the time limit has passed" }
+
+// summarize describes the outputs of a run
+func summarize(outputs []float64, opts Options) *Result {
+	n := float64(len(outputs))
+	result := &Result{Trials: len(outputs)}
+	moments := stats.Describe(outputs)
+	result.Mean, result.StdDev, result.StdError = moments.Mean, moments.StdDev, moments.StdError
+
+	sorted := append([]float64(nil), outputs...)
+	sort.Float64s(sorted)
+	result.Min, result.Max = sorted[0], sorted[len(sorted)-1]
+	for _, p := range opts.Percentiles {
+		result.Percentiles = append(result.Percentiles, Percentile{Percentile: p, Value: stats.Quantile(sorted, p/100)})
+	}
+
+	width := (result.Max - result.Min) / float64(opts.Buckets)
+	if width == 0 {
+		result.Histogram = []Bucket{{Low: result.Min, High: result.Max, Count: len(outputs)}}
+	} else {
+		result.Histogram = make([]Bucket, opts.Buckets)
+		for i := range result.Histogram {
+			result.Histogram[i].Low = result.Min + float64(i)*width
+			result.Histogram[i].High = result.Min + float64(i+1)*width
+		}
+		result.Histogram[opts.Buckets-1].High = result.Max
+		for _, x := range sorted {
+			result.Histogram[min(int((x-result.Min)/width), opts.Buckets-1)].Count++
+		}
+	}
+
+	for _, threshold := range opts.Thresholds {
+		above := len(sorted) - sort.Search(len(sorted), func(i int) bool { return sorted[i] > threshold })
+		result.Exceedances = append(result.Exceedances, Exceedance{Threshold: threshold, Probability: float64(above) / n})
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+package montecarlo
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func options(trials int) Options {
+	return Options{
+		Trials:      trials,
+		Percentiles: []float64{5, 50, 95},
+		Buckets:     10,
+		Rand:        rand.New(rand.NewSource(1)),
+	}
+}
+
+func TestSimulate_MatchesDistributionMoments(t *testing.T) {
+	for _, tc := range []struct {
+		variable   Variable
+		mean, sd   float64
+		min, max   float64
+		meanWithin float64
+	}{
+		{Variable{Distribution: Normal, Mean: 10, StdDev: 2}, 10, 2, math.Inf(-1), math.Inf(1), 0.05},
+		{Variable{Distribution: LogNormal, Mean: 5, StdDev: 3}, 5, 3, 0, math.Inf(1), 0.1},
+		{Variable{Distribution: Triangular, Min: 0, Mode: 3, Max: 9}, 4, math.Sqrt(13.5 / 4), 0, 9, 0.05},
+		{Variable{Distribution: Beta, Alpha: 2, Beta: 6, Min: 0, Max: 100}, 25, 100 * math.Sqrt(12.0/(64*9)), 0, 100, 0.5},
+		{Variable{Distribution: Beta, Alpha: 0.5, Beta: 0.5, Min: 0, Max: 1}, 0.5, math.Sqrt(0.125), 0, 1, 0.01},
+		{Variable{Distribution: Discrete, Values: []float64{0, 10}, Probabilities: []float64{3, 1}}, 2.5, math.Sqrt(18.75), 0, 10, 0.1},
+	} {
+		t.Run(tc.variable.Distribution, func(t *testing.T) {
+			tc.variable.Name = "x"
+			result, err := Simulate(context.Background(), []Variable{tc.variable},
+				func(draws map[string]float64) (float64, error) { return draws["x"], nil }, options(50000))
+			require.NoError(t, err)
+
+			assert.InDelta(t, tc.mean, result.Mean, tc.meanWithin)
+			assert.InDelta(t, tc.sd, result.StdDev, tc.sd*0.05)
+			assert.GreaterOrEqual(t, result.Min, tc.min)
+			assert.LessOrEqual(t, result.Max, tc.max)
+		})
+	}
+}
+
+func TestSimulate_SummarizesOutputs(t *testing.T) {
+	variables := []Variable{
+		{Name: "cost", Distribution: Normal, Mean: 100, StdDev: 10},
+		{Name: "overrun", Distribution: Discrete, Values: []float64{0, 50}, Probabilities: []float64{0.8, 0.2}},
+	}
+	opts := options(20000)
+	opts.Thresholds = []float64{100, 140, 1000}
+	result, err := Simulate(context.Background(), variables, func(draws map[string]float64) (float64, error) {
+		return draws["cost"] + draws["overrun"], nil
+	}, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, 20000, result.Trials)
+	assert.InDelta(t, 1, result.RHat, 0.01)
+	assert.True(t, result.Converged)
+	assert.Equal(t, "max_iterations", result.StoppingReason)
+	assert.Len(t, result.Residuals, 20)
+	assert.InDelta(t, 110, result.Mean, 0.5)
+	assert.InDelta(t, result.StdDev/math.Sqrt(20000), result.StdError, 1e-12)
+
+	require.Len(t, result.Percentiles, 3)
+	assert.Equal(t, 50.0, result.Percentiles[1].Percentile)
+	assert.Less(t, result.Percentiles[0].Value, result.Percentiles[1].Value)
+	assert.Less(t, result.Percentiles[1].Value, result.Percentiles[2].Value)
+
+	require.Len(t, result.Histogram, 10)
+	assert.Equal(t, result.Min, result.Histogram[0].Low)
+	assert.Equal(t, result.Max, result.Histogram[9].High)
+	total := 0
+	for _, bucket := range result.Histogram {
+		total += bucket.Count
+	}
+	assert.Equal(t, 20000, total)
+
+	require.Len(t, result.Exceedances, 3)
+	// Half the trials without an overrun and nearly all with one exceed 100
+	assert.InDelta(t, 0.8*0.5+0.2, result.Exceedances[0].Probability, 0.02)
+	// Only overruns reach 140 bar a 4-sigma cost
+	assert.InDelta(t, 0.2*0.84, result.Exceedances[1].Probability, 0.02)
+	assert.Zero(t, result.Exceedances[2].Probability)
+}
+
+func TestSimulate_DoesNotDependOnParallelism(t *testing.T) {
+	variables := []Variable{{Name: "x", Distribution: LogNormal, Mean: 5, StdDev: 3}}
+	square := func(draws map[string]float64) (float64, error) { return draws["x"] * draws["x"], nil }
+	serial, err := Simulate(context.Background(), variables, square, options(10000))
+	require.NoError(t, err)
+
+	opts := options(10000)
+	opts.Parallelism = 8
+	parallel, err := Simulate(context.Background(), variables, square, opts)
+	require.NoError(t, err)
+	assert.Equal(t, serial, parallel)
+}
+
+func TestSimulate_StopsAtTheTimeLimit(t *testing.T) {
+	variables := []Variable{{Name: "x", Distribution: Normal, Mean: 5, StdDev: 1}}
+	identity := func(draws map[string]float64) (float64, error) { return draws["x"], nil }
+	for _, parallelism := range []int{1, 8} {
+		opts := options(1000000)
+		opts.Parallelism, opts.TimeLimit = parallelism, time.Nanosecond
+		result, err := Simulate(context.Background(), variables, identity, opts)
+		require.NoError(t, err)
+		assert.False(t, result.Converged)
+		assert.Equal(t, "time_limit", result.StoppingReason)
+		// The trials run are whole chunks from the first
+		assert.Less(t, result.Trials, 1000000)
+		assert.Zero(t, result.Trials%1024)
+		assert.InDelta(t, 5, result.Mean, 0.2)
+	}
+}
+
+func TestSimulate_RejectsInvalidRuns(t *testing.T) {
+	identity := func(draws map[string]float64) (float64, error) { return draws["x"], nil }
+	for name, v := range map[string]Variable{
+		"negative sd":          {Name: "x", Distribution: Normal, StdDev: -1},
+		"lognormal mean":       {Name: "x", Distribution: LogNormal, Mean: 0, StdDev: 1},
+		"triangular mode":      {Name: "x", Distribution: Triangular, Min: 0, Mode: 5, Max: 4},
+		"beta shape":           {Name: "x", Distribution: Beta, Alpha: 0, Beta: 1, Max: 1},
+		"discrete values":      {Name: "x", Distribution: Discrete},
+		"discrete odds":        {Name: "x", Distribution: Discrete, Values: []float64{1, 2}, Probabilities: []float64{1}},
+		"unknown distribution": {Name: "x", Distribution: "cauchy"},
+	} {
+		_, err := Simulate(context.Background(), []Variable{v}, identity, options(10))
+		assert.Error(t, err, name)
+	}
+
+	normal := Variable{Name: "x", Distribution: Normal, StdDev: 1}
+	_, err := Simulate(context.Background(), []Variable{normal, normal}, identity, options(10))
+	assert.Error(t, err, "duplicate names")
+	opts := options(10)
+	opts.Percentiles = []float64{101}
+	_, err = Simulate(context.Background(), []Variable{normal}, identity, opts)
+	assert.Error(t, err, "percentile")
+
+	// Too few trials to split into chains
+	result, err := Simulate(context.Background(), []Variable{normal}, identity, options(5))
+	require.NoError(t, err)
+	assert.Zero(t, result.RHat)
+	assert.False(t, result.Converged)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Simulate(ctx, []Variable{normal}, identity, options(10))
+	assert.ErrorIs(t, err, context.Canceled)
+}
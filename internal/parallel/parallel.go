@@ -0,0 +1,86 @@
+// Package parallel spreads randomized work over goroutines without giving up
+// reproducibility. The work is cut into chunks of a fixed size, and each chunk
+// draws from a source of randomness of its own, seeded in order from the
+// caller's source before any chunk runs. A run's results therefore depend on
+// its seed but not on how many goroutines share the work.
+package parallel
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// ChunkSize is the number of items of a chunk callers use unless they have a
+// reason to differ: large enough that seeding a chunk's source is cheap
+// beside its work, small enough to share out small runs
+const ChunkSize = 1024
+
+// Chunks runs fn over the items [0, n) in consecutive chunks of size items,
+// on up to workers goroutines at once. Each call of fn is given the bounds of
+// its chunk and a source of randomness of its own; calls must share no state
+// beyond writing the items of their chunks. Chunks returns
+// the error of the first chunk that fails, or ctx's error if ctx ends first.
+func Chunks(ctx context.Context, n, size, workers int, r *rand.Rand, fn func(start, end int, r *rand.Rand) error) error {
+	switch {
+	case n < 0 || size <= 0:
+		return errors.New("the items must not be negative and the chunk size must be positive")
+	case r == nil:
+		return errors.New("no source of randomness")
+	}
+
+	seeds := make([]int64, (n+size-1)/size)
+	for i := range seeds {
+		seeds[i] = r.Int63()
+	}
+	errs := make([]error, len(seeds))
+
+	// Chunks are claimed in order, and a chunk after one that failed is
+	// skipped, so the chunks before it all run and the error returned does
+	// not depend on scheduling
+	var mu sync.Mutex
+	next, failed := 0, len(seeds)
+	claim := func() (int, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if next >= failed {
+			return 0, false
+		}
+		next++
+		return next - 1, true
+	}
+	fail := func(chunk int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs[chunk] = err
+		failed = min(failed, chunk)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(1, workers), len(seeds)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk, ok := claim(); ok; chunk, ok = claim() {
+				if err := ctx.Err(); err != nil {
+					fail(chunk, err)
+					return
+				}
+				start := chunk * size
+				if err := fn(start, min(start+size, n), rand.New(rand.NewSource(seeds[chunk]))); err != nil {
+					fail(chunk, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
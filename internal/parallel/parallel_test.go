@@ -0,0 +1,57 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// draw fills n items with random draws on workers goroutines
+func draw(t *testing.T, n, workers int) []float64 {
+	t.Helper()
+	items := make([]float64, n)
+	err := Chunks(context.Background(), n, 100, workers, rand.New(rand.NewSource(1)), func(start, end int, r *rand.Rand) error {
+		for i := start; i < end; i++ {
+			items[i] = r.Float64()
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return items
+}
+
+func TestChunks_DoesNotDependOnWorkers(t *testing.T) {
+	serial := draw(t, 1050, 1)
+	for _, workers := range []int{0, 2, 7, 64} {
+		assert.Equal(t, serial, draw(t, 1050, workers), "%d workers", workers)
+	}
+	assert.NotContains(t, serial, 0.0, "every item is drawn")
+	assert.NotEqual(t, serial[:100], serial[100:200], "chunks draw differently")
+}
+
+func TestChunks_ReturnsTheFirstFailure(t *testing.T) {
+	for _, workers := range []int{1, 8} {
+		err := Chunks(context.Background(), 1000, 10, workers, rand.New(rand.NewSource(1)), func(start, end int, r *rand.Rand) error {
+			if start >= 300 {
+				return fmt.Errorf("chunk at %d", start)
+			}
+			return nil
+		})
+		assert.EqualError(t, err, "chunk at 300")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Chunks(ctx, 10, 5, 2, rand.New(rand.NewSource(1)), func(int, int, *rand.Rand) error {
+		return errors.New("ran")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Error(t, Chunks(context.Background(), 10, 0, 1, rand.New(rand.NewSource(1)), nil))
+	assert.Error(t, Chunks(context.Background(), 10, 5, 1, nil, nil))
+}
@@ -0,0 +1,296 @@
+// Package particle tracks latent states with a bootstrap particle filter
+// (sequential Monte Carlo). A model describes how each state variable moves
+// from one step to the next, with Gaussian process noise, and the observation
+// it produces, with Gaussian observation noise. The filter propagates a cloud
+// of weighted particles through the steps, weighs them by the likelihood of
+// each observation and resamples them when too few carry the weight.
+package particle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Function evaluates an expression over the state variables and the step,
+// named t and counted from 1
+type Function func(values map[string]float64) (float64, error)
+
+// Variable is a latent state variable
+type Variable struct {
+	Name string
+	// InitialMean and InitialStdDev describe the Gaussian belief about the
+	// variable before the first step
+	InitialMean   float64
+	InitialStdDev float64
+	// Dynamics returns the variable's next value from the previous state,
+	// before ProcessNoise, the standard deviation of the Gaussian noise added
+	// to it; a nil Dynamics keeps the value
+	Dynamics     Function
+	ProcessNoise float64
+}
+
+// Model is a state-space model
+type Model struct {
+	Variables []Variable
+	// Observation returns the observation a state is expected to produce,
+	// and ObservationNoise is the standard deviation of the Gaussian noise
+	// around it
+	Observation      Function
+	ObservationNoise float64
+}
+
+// Options control a run
+type Options struct {
+	Particles int
+	// ResampleThreshold is the share of the particles below which the
+	// effective sample size triggers resampling, from 0 (never) to 1
+	// (always)
+	ResampleThreshold float64
+	// Band is the central probability of the uncertainty band of each
+	// estimate, such as 0.9 for the 5th to 95th percentiles
+	Band float64
+	// Cloud, when positive, has each step hold up to Cloud of its particles
+	Cloud int
+	Rand  *rand.Rand
+}
+
+// Estimate is the filtered belief about a variable at a step
+type Estimate struct {
+	Mean   float64
+	StdDev float64
+	// Lower and Upper bound the central Band of the weighted particles
+	Lower float64
+	Upper float64
+}
+
+// Step is the filter's state after an observation
+type Step struct {
+	Step        int
+	Observation float64
+	// Predicted is the observation expected before seeing it
+	Predicted float64
+	Estimates map[string]Estimate
+	// EffectiveSampleSize measures how many particles carry the weight
+	// after the observation, before any resampling
+	EffectiveSampleSize float64
+	Resampled           bool
+	// Cloud holds the first Options.Cloud particles after the observation,
+	// before any resampling; the particles are exchangeable, so they are a
+	// sample of the whole cloud
+	Cloud []Particle
+}
+
+// Particle is a particle of a step's cloud: its state and normalized weight
+type Particle struct {
+	Values map[string]float64
+	Weight float64
+}
+
+// Result is the outcome of a run
+type Result struct {
+	Steps []Step
+	// LogLikelihood is the estimated log-likelihood of the observations
+	// under the model
+	LogLikelihood float64
+	Resamples     int
+}
+
+// stepVariable is the variable naming the step in expressions
+const stepVariable = "t"
+
+// Filter runs a bootstrap particle filter of model over observations. It
+// returns ctx's error if ctx ends first.
+func Filter(ctx context.Context, model Model, observations []float64, opts Options) (*Result, error) {
+	switch {
+	case len(model.Variables) == 0:
+		return nil, errors.New("the model has no state variables")
+	case model.Observation == nil:
+		return nil, errors.New("the model has no observation")
+	case !(model.ObservationNoise > 0):
+		return nil, errors.New("the observation noise must be positive")
+	case len(observations) == 0:
+		return nil, errors.New("there are no observations")
+	case opts.Particles < 2:
+		return nil, errors.New("there must be at least 2 particles")
+	case opts.ResampleThreshold < 0 || opts.ResampleThreshold > 1:
+		return nil, errors.New("the resample threshold must be within [0, 1]")
+	case !(opts.Band > 0 && opts.Band < 1):
+		return nil, errors.New("the band must be within (0, 1)")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+	seen := map[string]bool{stepVariable: true}
+	for _, v := range model.Variables {
+		switch {
+		case v.Name == "" || seen[v.Name]:
+			return nil, fmt.Errorf("state variables need distinct names other than %s", stepVariable)
+		case v.InitialStdDev < 0 || v.ProcessNoise < 0:
+			return nil, fmt.Errorf("variable %s: standard deviations must not be negative", v.Name)
+		}
+		seen[v.Name] = true
+	}
+	for t, o := range observations {
+		if math.IsNaN(o) || math.IsInf(o, 0) {
+			return nil, fmt.Errorf("observation %d is not finite", t+1)
+		}
+	}
+
+	n, dims := opts.Particles, len(model.Variables)
+	particles := make([][]float64, n)
+	for i := range particles {
+		particles[i] = make([]float64, dims)
+		for j, v := range model.Variables {
+			particles[i][j] = v.InitialMean + v.InitialStdDev*opts.Rand.NormFloat64()
+		}
+	}
+	logWeights := make([]float64, n)
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1 / float64(n)
+	}
+
+	result := &Result{Steps: make([]Step, len(observations))}
+	values := make(map[string]float64, dims+1)
+	for t, observation := range observations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		values[stepVariable] = float64(t + 1)
+
+		// Propagate each particle and weigh it by the observation
+		predicted := 0.0
+		for i, particle := range particles {
+			for j, v := range model.Variables {
+				values[v.Name] = particle[j]
+			}
+			next := make([]float64, dims)
+			for j, v := range model.Variables {
+				next[j] = particle[j]
+				if v.Dynamics != nil {
+					x, err := v.Dynamics(values)
+					if err != nil {
+						return nil, fmt.Errorf("step %d: dynamics of %s: %v", t+1, v.Name, err)
+					}
+					next[j] = x
+				}
+				next[j] += v.ProcessNoise * opts.Rand.NormFloat64()
+			}
+			particles[i] = next
+
+			for j, v := range model.Variables {
+				values[v.Name] = next[j]
+			}
+			expected, err := model.Observation(values)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: observation: %v", t+1, err)
+			}
+			predicted += weights[i] * expected
+			z := (observation - expected) / model.ObservationNoise
+			logWeights[i] = math.Log(weights[i]) - z*z/2
+		}
+
+		// Normalize the weights in log space so tiny likelihoods do not
+		// underflow
+		top := math.Inf(-1)
+		for _, w := range logWeights {
+			top = math.Max(top, w)
+		}
+		total := 0.0
+		for i, w := range logWeights {
+			weights[i] = math.Exp(w - top)
+			total += weights[i]
+		}
+		result.LogLikelihood += top + math.Log(total) - math.Log(model.ObservationNoise*math.Sqrt(2*math.Pi))
+		squares := 0.0
+		for i := range weights {
+			weights[i] /= total
+			squares += weights[i] * weights[i]
+		}
+
+		step := Step{
+			Step:                t + 1,
+			Observation:         observation,
+			Predicted:           predicted,
+			Estimates:           make(map[string]Estimate, dims),
+			EffectiveSampleSize: 1 / squares,
+		}
+		for j, v := range model.Variables {
+			step.Estimates[v.Name] = estimate(particles, weights, j, opts.Band)
+		}
+		for i := range particles[:min(opts.Cloud, n)] {
+			p := Particle{Values: make(map[string]float64, dims), Weight: weights[i]}
+			for j, v := range model.Variables {
+				p.Values[v.Name] = particles[i][j]
+			}
+			step.Cloud = append(step.Cloud, p)
+		}
+		if step.EffectiveSampleSize < opts.ResampleThreshold*float64(n) {
+			particles = resample(particles, weights, opts.Rand)
+			for i := range weights {
+				weights[i] = 1 / float64(n)
+			}
+			step.Resampled = true
+			result.Resamples++
+		}
+		result.Steps[t] = step
+	}
+	return result, nil
+}
+
+// estimate returns the weighted mean, spread and central band of dimension j
+// of particles
+func estimate(particles [][]float64, weights []float64, j int, band float64) Estimate {
+	mean := 0.0
+	for i, p := range particles {
+		mean += weights[i] * p[j]
+	}
+	variance := 0.0
+	for i, p := range particles {
+		variance += weights[i] * (p[j] - mean) * (p[j] - mean)
+	}
+
+	order := make([]int, len(particles))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return particles[order[a]][j] < particles[order[b]][j] })
+	quantile := func(q float64) float64 {
+		cumulative := 0.0
+		for _, i := range order {
+			cumulative += weights[i]
+			if cumulative >= q {
+				return particles[i][j]
+			}
+		}
+		return particles[order[len(order)-1]][j]
+	}
+
+	return Estimate{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance),
+		Lower:  quantile((1 - band) / 2),
+		Upper:  quantile((1 + band) / 2),
+	}
+}
+
+// resample draws len(particles) particles in proportion to weights by
+// systematic resampling, which keeps the draws' spread low
+func resample(particles [][]float64, weights []float64, r *rand.Rand) [][]float64 {
+	n := len(particles)
+	resampled := make([][]float64, n)
+	u := r.Float64() / float64(n)
+	cumulative, i := weights[0], 0
+	for k := range resampled {
+		for u > cumulative && i < n-1 {
+			i++
+			cumulative += weights[i]
+		}
+		resampled[k] = append([]float64(nil), particles[i]...)
+		u += 1 / float64(n)
+	}
+	return resampled
+}
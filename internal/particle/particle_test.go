@@ -0,0 +1,97 @@
+package particle
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func options() Options {
+	return Options{Particles: 2000, ResampleThreshold: 0.5, Band: 0.9, Rand: rand.New(rand.NewSource(1))}
+}
+
+func level(values map[string]float64) (float64, error) { return values["level"], nil }
+
+func TestFilter_TracksDriftingLevel(t *testing.T) {
+	// A level drifting up by 1 a step, observed with noise
+	r := rand.New(rand.NewSource(7))
+	observations := make([]float64, 30)
+	for i := range observations {
+		observations[i] = float64(i+1) + 0.5*r.NormFloat64()
+	}
+
+	model := Model{
+		Variables: []Variable{{
+			Name:          "level",
+			InitialStdDev: 2,
+			Dynamics:      func(values map[string]float64) (float64, error) { return values["level"] + 1, nil },
+			ProcessNoise:  0.1,
+		}},
+		Observation:      level,
+		ObservationNoise: 0.5,
+	}
+	opts := options()
+	opts.Cloud = 50
+	result, err := Filter(context.Background(), model, observations, opts)
+	require.NoError(t, err)
+	require.Len(t, result.Steps, 30)
+
+	last := result.Steps[29]
+	assert.Equal(t, 30, last.Step)
+	estimate := last.Estimates["level"]
+	assert.InDelta(t, 30, estimate.Mean, 0.5)
+	assert.Less(t, estimate.Lower, estimate.Mean)
+	assert.Greater(t, estimate.Upper, estimate.Mean)
+	// The filter grows surer than the first step's prior
+	assert.Less(t, estimate.StdDev, result.Steps[0].Estimates["level"].StdDev)
+	assert.InDelta(t, 30, last.Predicted, 1)
+	assert.Greater(t, result.Resamples, 0)
+	assert.False(t, math.IsNaN(result.LogLikelihood))
+
+	// The cloud is a sample of the particles around the estimate
+	require.Len(t, last.Cloud, 50)
+	for _, p := range last.Cloud {
+		assert.InDelta(t, estimate.Mean, p.Values["level"], 6*estimate.StdDev)
+		assert.Greater(t, p.Weight, 0.0)
+	}
+}
+
+func TestFilter_UsesTheStepAndHiddenVariables(t *testing.T) {
+	// The observation is the product of a hidden constant rate and the step
+	observations := []float64{2, 4, 6, 8, 10, 12}
+	model := Model{
+		Variables:        []Variable{{Name: "rate", InitialMean: 1, InitialStdDev: 2, ProcessNoise: 0.01}},
+		Observation:      func(values map[string]float64) (float64, error) { return values["rate"] * values["t"], nil },
+		ObservationNoise: 0.2,
+	}
+	result, err := Filter(context.Background(), model, observations, options())
+	require.NoError(t, err)
+	assert.InDelta(t, 2, result.Steps[5].Estimates["rate"].Mean, 0.05)
+	assert.Empty(t, result.Steps[5].Cloud)
+}
+
+func TestFilter_RejectsInvalidRuns(t *testing.T) {
+	model := Model{Variables: []Variable{{Name: "level"}}, Observation: level, ObservationNoise: 1}
+	_, err := Filter(context.Background(), model, nil, options())
+	assert.Error(t, err, "no observations")
+	_, err = Filter(context.Background(), Model{Observation: level, ObservationNoise: 1}, []float64{1}, options())
+	assert.Error(t, err, "no variables")
+	_, err = Filter(context.Background(), Model{Variables: []Variable{{Name: "t"}}, Observation: level, ObservationNoise: 1}, []float64{1}, options())
+	assert.Error(t, err, "reserved name")
+	_, err = Filter(context.Background(), Model{Variables: []Variable{{Name: "level"}}, Observation: level}, []float64{1}, options())
+	assert.Error(t, err, "no observation noise")
+
+	opts := options()
+	opts.Band = 1
+	_, err = Filter(context.Background(), model, []float64{1}, opts)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Filter(ctx, model, []float64{1}, options())
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,66 @@
+// Package progress reports the progress of long-running MCP tool calls to the
+// client that made them, as notifications/progress messages.
+package progress
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MethodNotificationProgress is the MCP method of progress notifications
+const MethodNotificationProgress = "notifications/progress"
+
+// Reporter sends progress notifications for one tool call. Clients only
+// receive them when the call carries a progress token; otherwise, and for a
+// nil Reporter, reporting does nothing.
+type Reporter struct {
+	ctx    context.Context
+	server *server.MCPServer
+	token  mcp.ProgressToken
+	total  float64
+}
+
+// FromRequest returns a Reporter for a tool call expected to take total
+// units of work, or 0 when the total is unknown
+func FromRequest(ctx context.Context, req mcp.CallToolRequest, total float64) *Reporter {
+	reporter := &Reporter{ctx: ctx, server: server.ServerFromContext(ctx), total: total}
+	if req.Params.Meta != nil {
+		reporter.token = req.Params.Meta.ProgressToken
+	}
+	return reporter
+}
+
+// Enabled reports whether the client asked for progress notifications
+func (r *Reporter) Enabled() bool {
+	return r != nil && r.server != nil && r.token != nil
+}
+
+// SetTotal changes the units of work expected, once they are known
+func (r *Reporter) SetTotal(total float64) {
+	if r != nil {
+		r.total = total
+	}
+}
+
+// Report sends the units of work done so far with a short description, such
+// as the best result found. Notifications the client cannot receive are
+// dropped: progress never fails a tool call.
+func (r *Reporter) Report(done float64, message string) {
+	if !r.Enabled() {
+		return
+	}
+
+	params := map[string]any{
+		"progressToken": r.token,
+		"progress":      done,
+	}
+	if r.total > 0 {
+		params["total"] = r.total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	_ = r.server.SendNotificationToClient(r.ctx, MethodNotificationProgress, params)
+}
@@ -0,0 +1,354 @@
+// Package queueing simulates M/M/c queues: customers arrive in a Poisson
+// stream, wait in a single first-come first-served line and are served by
+// the first free of c servers in exponentially distributed times. M/M/1 is
+// the queue of a single server. A run reports the servers' utilization, how
+// long customers waited and how long the line was, and, for a stable queue,
+// the steady-state values Erlang's C formula gives for the same measures.
+package queueing
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/internal/convergence"
+	"github.com/rainmana/gothink/internal/stats"
+)
+
+// maxLength is the longest line, or the most customers in the system, a
+// length distribution tells apart; longer ones count towards it
+const maxLength = 100
+
+// Options control a run
+type Options struct {
+	// Servers is the number of servers, c
+	Servers int
+	// ArrivalRate is the customers arriving per unit of time, and
+	// ServiceRate the customers a busy server serves per unit of time
+	ArrivalRate float64
+	ServiceRate float64
+	// Customers is the number of customers measured, after WarmUp customers
+	// have been served to bring the queue from empty to its usual state
+	Customers int
+	WarmUp    int
+	// Percentiles of the waits are reported from 0 to 100
+	Percentiles []float64
+	// TimeLimit, when positive, stops the run early, summarizing the
+	// customers measured so far
+	TimeLimit time.Duration
+	Rand      *rand.Rand
+}
+
+// Percentile is the wait below which a share of the customers waited, with
+// the steady-state wait of the same share when the queue is stable
+type Percentile struct {
+	Percentile float64
+	Value      float64
+	Theory     float64
+}
+
+// Share is the share of the time that Length customers were waiting, or in
+// the system, with its steady-state probability when the queue is stable.
+// A distribution runs up to the longest length seen, and a share of
+// maxLength counts that many customers or more.
+type Share struct {
+	Length      int
+	Probability float64
+	Theory      float64
+}
+
+// Theory holds the steady-state measures of a stable queue: the utilization
+// of its servers, the chance that a customer waits (Erlang's C), the mean
+// wait in line and time in the system, and the mean customers waiting and in
+// the system
+type Theory struct {
+	Utilization     float64
+	WaitProbability float64
+	MeanWait        float64
+	MeanSojourn     float64
+	MeanQueueLength float64
+	MeanInSystem    float64
+}
+
+// Result summarizes a run over the customers measured
+type Result struct {
+	// Customers is the number measured and Duration the time they spanned,
+	// from the end of the warm-up to the last of them starting service
+	Customers int
+	Duration  float64
+	// Stable reports whether the servers can keep up, the arrival rate below
+	// their combined service rate; an unstable queue's line keeps growing
+	Stable bool
+	// Utilization is the share of the servers' time spent serving
+	Utilization float64
+	// MeanWait is the mean time in line and MeanSojourn the mean time in the
+	// system, waiting and being served; WaitProbability is the share of
+	// customers who waited at all
+	MeanWait        float64
+	MeanSojourn     float64
+	WaitProbability float64
+	Percentiles     []Percentile
+	// MeanQueueLength and MeanInSystem are the time averages of the
+	// customers waiting and in the system, QueueLengths and InSystem their
+	// distributions over time and MaxQueueLength the longest line
+	MeanQueueLength float64
+	MeanInSystem    float64
+	MaxQueueLength  int
+	QueueLengths    []Share
+	InSystem        []Share
+	// Theory is nil for an unstable queue
+	Theory *Theory
+	// RHat is the Gelman-Rubin statistic of the waits split into 4
+	// consecutive chains, which stays far above 1 while the line drifts. The
+	// run has converged when it is below convergence.RHatThreshold;
+	// Residuals holds its distance from 1 as customers accumulated. A run
+	// that ran out of time has not converged.
+	RHat           float64
+	Converged      bool
+	StoppingReason string
+	Residuals      []float64
+}
+
+// departures are the times busy servers finish, soonest first
+type departures []float64
+
+func (d departures) Len() int            { return len(d) }
+func (d departures) Less(i, j int) bool  { return d[i] < d[j] }
+func (d departures) Swap(i, j int)       { d[i], d[j] = d[j], d[i] }
+func (d *departures) Push(x interface{}) { *d = append(*d, x.(float64)) }
+func (d *departures) Pop() interface{} {
+	old := *d
+	x := old[len(old)-1]
+	*d = old[:len(old)-1]
+	return x
+}
+
+// Simulate runs the queue of opts until opts.Customers customers have been
+// served after the warm-up. It returns ctx's error if ctx ends first.
+func Simulate(ctx context.Context, opts Options) (*Result, error) {
+	switch {
+	case opts.Servers <= 0:
+		return nil, errors.New("the queue needs at least one server")
+	case !(opts.ArrivalRate > 0) || math.IsInf(opts.ArrivalRate, 0):
+		return nil, errors.New("the arrival rate must be positive")
+	case !(opts.ServiceRate > 0) || math.IsInf(opts.ServiceRate, 0):
+		return nil, errors.New("the service rate must be positive")
+	case opts.Customers <= 0 || opts.WarmUp < 0:
+		return nil, errors.New("customers must be positive and the warm-up must not be negative")
+	case opts.Rand == nil:
+		return nil, errors.New("no source of randomness")
+	}
+	for _, p := range opts.Percentiles {
+		if !(p >= 0 && p <= 100) {
+			return nil, fmt.Errorf("percentile %v is outside [0, 100]", p)
+		}
+	}
+
+	r := opts.Rand
+	busy := &departures{}
+	var line []float64 // arrival times of the customers waiting, in order
+	waits := make([]float64, 0, opts.Customers)
+	queueTime := make([]float64, maxLength+1)
+	systemTime := make([]float64, maxLength+1)
+	stoppingReason := convergence.MaxIterations
+	deadline := convergence.DeadlineAfter(opts.TimeLimit)
+
+	now, nextArrival := 0.0, r.ExpFloat64()/opts.ArrivalRate
+	served, start, sojourns, busyTime, maxQueue := 0, 0.0, 0.0, 0.0, 0
+	measuring := opts.WarmUp == 0
+	for events := 0; len(waits) < opts.Customers; events++ {
+		if events%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// A run measures at least one customer, so that it has waits
+			// to summarize
+			if len(waits) > 0 && deadline.Passed() {
+				stoppingReason = convergence.TimeLimit
+				break
+			}
+		}
+
+		// Advance to the next arrival or departure, counting the time the
+		// line and the system spent at their lengths
+		next := nextArrival
+		departing := busy.Len() > 0 && (*busy)[0] <= nextArrival
+		if departing {
+			next = (*busy)[0]
+		}
+		if measuring {
+			elapsed := next - now
+			queueTime[min(len(line), maxLength)] += elapsed
+			systemTime[min(len(line)+busy.Len(), maxLength)] += elapsed
+			busyTime += elapsed * float64(busy.Len())
+		}
+		now = next
+
+		// A customer arriving to a free server starts right away, and a
+		// server finishing takes the first customer in line
+		var arrived float64
+		if departing {
+			heap.Pop(busy)
+			served++
+			if !measuring && served >= opts.WarmUp {
+				measuring, start = true, now
+			}
+			if len(line) == 0 {
+				continue
+			}
+			arrived, line = line[0], line[1:]
+		} else {
+			nextArrival = now + r.ExpFloat64()/opts.ArrivalRate
+			if busy.Len() == opts.Servers {
+				line = append(line, now)
+				if measuring {
+					maxQueue = max(maxQueue, len(line))
+				}
+				continue
+			}
+			arrived = now
+		}
+		service := r.ExpFloat64() / opts.ServiceRate
+		heap.Push(busy, now+service)
+		if measuring {
+			waits = append(waits, now-arrived)
+			sojourns += now - arrived + service
+		}
+	}
+
+	result := summarize(waits, opts)
+	result.Duration = now - start
+	result.StoppingReason = stoppingReason
+	result.Stable = opts.ArrivalRate < float64(opts.Servers)*opts.ServiceRate
+	result.MaxQueueLength = maxQueue
+	result.MeanSojourn = sojourns / float64(len(waits))
+	if result.Duration > 0 {
+		result.Utilization = busyTime / (float64(opts.Servers) * result.Duration)
+		result.QueueLengths, result.MeanQueueLength = distribution(queueTime, result.Duration)
+		result.InSystem, result.MeanInSystem = distribution(systemTime, result.Duration)
+	}
+	if result.Stable {
+		result.Theory = theorize(opts, result)
+	}
+
+	result.RHat, result.Residuals = convergence.SplitRHat(waits)
+	result.Converged = stoppingReason != convergence.TimeLimit && result.RHat > 0 && result.RHat < convergence.RHatThreshold
+	return result, nil
+}
+
+// summarize describes the waits of the customers measured
+func summarize(waits []float64, opts Options) *Result {
+	result := &Result{Customers: len(waits), MeanWait: stats.Mean(waits)}
+	waited := 0
+	for _, wait := range waits {
+		if wait > 0 {
+			waited++
+		}
+	}
+	result.WaitProbability = float64(waited) / float64(len(waits))
+
+	sorted := append([]float64(nil), waits...)
+	sort.Float64s(sorted)
+	for _, p := range opts.Percentiles {
+		result.Percentiles = append(result.Percentiles, Percentile{Percentile: p, Value: stats.Quantile(sorted, p/100)})
+	}
+	return result
+}
+
+// distribution returns the shares of duration spent at each length, up to
+// the longest reached, and the mean length over time
+func distribution(times []float64, duration float64) ([]Share, float64) {
+	longest := 0
+	for length, t := range times {
+		if t > 0 {
+			longest = length
+		}
+	}
+	shares := make([]Share, longest+1)
+	mean := 0.0
+	for length := range shares {
+		shares[length] = Share{Length: length, Probability: times[length] / duration}
+		mean += float64(length) * shares[length].Probability
+	}
+	return shares, mean
+}
+
+// theorize returns the steady-state measures of the stable queue of opts by
+// Erlang's C formula, filling in the steady-state values of result's
+// percentiles and distributions
+func theorize(opts Options, result *Result) *Theory {
+	c := opts.Servers
+	offered := opts.ArrivalRate / opts.ServiceRate
+	rho := offered / float64(c)
+
+	// The probability of n customers in the system is p0 a^n/n! below c
+	// and p0 a^c/c! rho^(n-c) from c on; its terms are taken in logs, as a^c
+	// and c! overflow for many servers
+	logTerm := func(n int) float64 {
+		lgamma, _ := math.Lgamma(float64(n + 1))
+		return float64(n)*math.Log(offered) - lgamma
+	}
+	logs := make([]float64, c+1)
+	largest := math.Inf(-1)
+	for n := 0; n <= c; n++ {
+		logs[n] = logTerm(n)
+		if n == c {
+			logs[n] -= math.Log(1 - rho)
+		}
+		largest = math.Max(largest, logs[n])
+	}
+	total := 0.0
+	for _, l := range logs {
+		total += math.Exp(l - largest)
+	}
+	logP0 := -largest - math.Log(total)
+	inSystem := func(n int) float64 {
+		if n < c {
+			return math.Exp(logP0 + logTerm(n))
+		}
+		return math.Exp(logP0 + logTerm(c) + float64(n-c)*math.Log(rho))
+	}
+
+	theory := &Theory{Utilization: rho, WaitProbability: math.Exp(logP0 + logs[c])}
+	drain := float64(c)*opts.ServiceRate - opts.ArrivalRate
+	theory.MeanWait = theory.WaitProbability / drain
+	theory.MeanSojourn = theory.MeanWait + 1/opts.ServiceRate
+	theory.MeanQueueLength = opts.ArrivalRate * theory.MeanWait
+	theory.MeanInSystem = opts.ArrivalRate * theory.MeanSojourn
+
+	// A customer waits longer than t with chance C e^(-(c mu - lambda) t)
+	for i, p := range result.Percentiles {
+		if tail := 1 - p.Percentile/100; tail < theory.WaitProbability {
+			result.Percentiles[i].Theory = math.Log(theory.WaitProbability/tail) / drain
+		}
+	}
+	theoryOf := func(shares []Share, probability func(length int) float64) {
+		below := 0.0
+		for i := range shares {
+			if shares[i].Length == maxLength {
+				shares[i].Theory = 1 - below
+				break
+			}
+			shares[i].Theory = probability(shares[i].Length)
+			below += shares[i].Theory
+		}
+	}
+	theoryOf(result.InSystem, inSystem)
+	theoryOf(result.QueueLengths, func(length int) float64 {
+		if length > 0 {
+			return inSystem(c + length)
+		}
+		// No one waits while at most c customers are in the system
+		none := 0.0
+		for n := 0; n <= c; n++ {
+			none += inSystem(n)
+		}
+		return none
+	})
+	return theory
+}
@@ -0,0 +1,113 @@
+package queueing
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func simulate(t *testing.T, servers int, arrivalRate, serviceRate float64) *Result {
+	t.Helper()
+	result, err := Simulate(context.Background(), Options{
+		Servers:     servers,
+		ArrivalRate: arrivalRate,
+		ServiceRate: serviceRate,
+		Customers:   100000,
+		WarmUp:      10000,
+		Percentiles: []float64{50, 90, 99},
+		Rand:        rand.New(rand.NewSource(1)),
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestSimulate_MatchesTheSingleServerQueue(t *testing.T) {
+	result := simulate(t, 1, 0.8, 1)
+	require.True(t, result.Stable)
+	require.NotNil(t, result.Theory)
+
+	// M/M/1 at 80% load: customers wait 4 on average, with 3.2 in line
+	assert.InDelta(t, 0.8, result.Theory.Utilization, 1e-9)
+	assert.InDelta(t, 0.8, result.Theory.WaitProbability, 1e-9)
+	assert.InDelta(t, 4, result.Theory.MeanWait, 1e-9)
+	assert.InDelta(t, 5, result.Theory.MeanSojourn, 1e-9)
+	assert.InDelta(t, 3.2, result.Theory.MeanQueueLength, 1e-9)
+	assert.InDelta(t, 4, result.Theory.MeanInSystem, 1e-9)
+
+	assert.Equal(t, 100000, result.Customers)
+	assert.InDelta(t, 0.8, result.Utilization, 0.01)
+	assert.InDelta(t, 0.8, result.WaitProbability, 0.01)
+	assert.InDelta(t, 4, result.MeanWait, 0.4)
+	assert.InDelta(t, 5, result.MeanSojourn, 0.4)
+	assert.InDelta(t, 3.2, result.MeanQueueLength, 0.4)
+	assert.True(t, result.Converged)
+
+	// The system is empty a fifth of the time, and each further customer
+	// is 0.8 times as likely
+	assert.InDelta(t, 0.2, result.InSystem[0].Theory, 1e-9)
+	assert.InDelta(t, 0.16, result.InSystem[1].Theory, 1e-9)
+	assert.InDelta(t, 0.2, result.InSystem[0].Probability, 0.01)
+	assert.InDelta(t, 0.36, result.QueueLengths[0].Theory, 1e-9)
+	assert.InDelta(t, 0.36, result.QueueLengths[0].Probability, 0.01)
+	for _, p := range result.Percentiles {
+		assert.InEpsilon(t, p.Theory, p.Value, 0.1, "percentile %v", p.Percentile)
+	}
+}
+
+func TestSimulate_MatchesErlangC(t *testing.T) {
+	result := simulate(t, 3, 2.4, 1)
+	require.NotNil(t, result.Theory)
+
+	// Three servers at 80% load make a customer wait with chance 0.647
+	assert.InDelta(t, 0.6472, result.Theory.WaitProbability, 1e-4)
+	assert.InDelta(t, 1.0787, result.Theory.MeanWait, 1e-4)
+	assert.InDelta(t, 0.6472, result.WaitProbability, 0.02)
+	assert.InDelta(t, 1.0787, result.MeanWait, 0.1)
+	assert.InDelta(t, 0.8, result.Utilization, 0.01)
+
+	probability := 0.0
+	for _, share := range result.InSystem {
+		probability += share.Probability
+	}
+	assert.InDelta(t, 1, probability, 1e-9)
+
+	// Many servers keep Erlang's C formula finite
+	many := simulate(t, 500, 480, 1)
+	require.NotNil(t, many.Theory)
+	assert.Greater(t, many.Theory.WaitProbability, 0.0)
+	assert.Less(t, many.Theory.WaitProbability, 1.0)
+}
+
+func TestSimulate_ReportsUnstableQueues(t *testing.T) {
+	result := simulate(t, 2, 2.2, 1)
+
+	// The line only grows, so the waits never settle
+	assert.False(t, result.Stable)
+	assert.Nil(t, result.Theory)
+	assert.False(t, result.Converged)
+	assert.Greater(t, result.RHat, 1.5)
+	assert.Greater(t, result.MaxQueueLength, 1000)
+	assert.InDelta(t, 1, result.Utilization, 1e-6)
+	require.Len(t, result.QueueLengths, maxLength+1)
+}
+
+func TestSimulate_RejectsInvalidQueues(t *testing.T) {
+	valid := Options{Servers: 1, ArrivalRate: 1, ServiceRate: 2, Customers: 10, Rand: rand.New(rand.NewSource(1))}
+	for name, change := range map[string]func(*Options){
+		"no servers":      func(o *Options) { o.Servers = 0 },
+		"no arrivals":     func(o *Options) { o.ArrivalRate = 0 },
+		"no service":      func(o *Options) { o.ServiceRate = -1 },
+		"no customers":    func(o *Options) { o.Customers = 0 },
+		"bad percentile":  func(o *Options) { o.Percentiles = []float64{101} },
+		"no randomness":   func(o *Options) { o.Rand = nil },
+		"negative warmup": func(o *Options) { o.WarmUp = -1 },
+	} {
+		opts := valid
+		change(&opts)
+		_, err := Simulate(context.Background(), opts)
+		assert.Error(t, err, name)
+	}
+}
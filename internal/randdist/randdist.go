@@ -0,0 +1,42 @@
+// Package randdist draws from the continuous distributions the stochastic
+// algorithms sample beyond what math/rand offers: Gamma, and Beta as a ratio
+// of Gamma draws.
+package randdist
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Beta draws from Beta(alpha, beta) as the ratio of two Gamma draws
+func Beta(r *rand.Rand, alpha, beta float64) float64 {
+	x, y := Gamma(r, alpha), Gamma(r, beta)
+	if x+y == 0 {
+		// Both draws underflowed, which tiny shapes allow
+		return 0.5
+	}
+	return x / (x + y)
+}
+
+// Gamma draws from Gamma(shape, 1) by Marsaglia and Tsang's method
+func Gamma(r *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// Boost the shape above 1 and scale the draw back down
+		return Gamma(r, shape+1) * math.Pow(r.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := r.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
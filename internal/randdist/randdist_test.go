@@ -0,0 +1,42 @@
+package randdist
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// moments returns the mean and variance of n draws
+func moments(n int, draw func() float64) (mean, variance float64) {
+	draws := make([]float64, n)
+	for i := range draws {
+		draws[i] = draw()
+		mean += draws[i]
+	}
+	mean /= float64(n)
+	for _, x := range draws {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(n-1)
+}
+
+func TestGamma(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, shape := range []float64{0.3, 1, 4.5} {
+		mean, variance := moments(100000, func() float64 { return Gamma(r, shape) })
+		assert.InDelta(t, shape, mean, 0.05*shape, "shape %v", shape)
+		assert.InDelta(t, shape, variance, 0.1*shape, "shape %v", shape)
+	}
+}
+
+func TestBeta(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	mean, variance := moments(100000, func() float64 { return Beta(r, 2, 6) })
+	assert.InDelta(t, 0.25, mean, 0.005)
+	assert.InDelta(t, 2*6/(8*8*9.0), variance, 0.001)
+
+	// Shapes so small both draws underflow
+	x := Beta(r, 1e-300, 1e-300)
+	assert.True(t, x >= 0 && x <= 1)
+}
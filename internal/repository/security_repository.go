@@ -34,9 +34,12 @@ func (r *SecurityRepository) StoreCVE(ctx context.Context, cve models.CVE) error
 	return nil
 }
 
-// StoreCVEs stores multiple CVEs in the repository
+// StoreCVEs stores multiple CVEs in the repository, stopping if ctx is done
 func (r *SecurityRepository) StoreCVEs(ctx context.Context, cves []models.CVE) error {
 	for _, cve := range cves {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := r.StoreCVE(ctx, cve); err != nil {
 			return fmt.Errorf("failed to store CVE %s: %w", cve.ID, err)
 		}
@@ -53,11 +56,15 @@ func (r *SecurityRepository) GetCVE(ctx context.Context, id string) (*models.CVE
 	return &cve, nil
 }
 
-// QueryCVEs searches for CVEs based on query parameters
+// QueryCVEs searches for CVEs based on query parameters, stopping if ctx
+// is done
 func (r *SecurityRepository) QueryCVEs(ctx context.Context, query models.IntelligenceQuery) (*models.IntelligenceResponse, error) {
 	var results []interface{}
 
 	for _, cve := range r.cves {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		// Simple text search in description
 		if query.Query == "" || contains(cve.Description, query.Query) || contains(cve.ID, query.Query) {
 			results = append(results, cve)
@@ -96,9 +103,13 @@ func (r *SecurityRepository) StoreTechnique(ctx context.Context, technique model
 	return nil
 }
 
-// StoreTechniques stores multiple attack techniques in the repository
+// StoreTechniques stores multiple attack techniques in the repository,
+// stopping if ctx is done
 func (r *SecurityRepository) StoreTechniques(ctx context.Context, techniques []models.AttackTechnique) error {
 	for _, technique := range techniques {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := r.StoreTechnique(ctx, technique); err != nil {
 			return fmt.Errorf("failed to store technique %s: %w", technique.ID, err)
 		}
@@ -115,11 +126,15 @@ func (r *SecurityRepository) GetTechnique(ctx context.Context, id string) (*mode
 	return &technique, nil
 }
 
-// QueryTechniques searches for attack techniques based on query parameters
+// QueryTechniques searches for attack techniques based on query parameters,
+// stopping if ctx is done
 func (r *SecurityRepository) QueryTechniques(ctx context.Context, query models.IntelligenceQuery) (*models.IntelligenceResponse, error) {
 	var results []interface{}
 
 	for _, technique := range r.techniques {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		// Simple text search in name, description, and tactics
 		if query.Query == "" ||
 			contains(technique.Name, query.Query) ||
@@ -161,9 +176,13 @@ func (r *SecurityRepository) StoreProcedure(ctx context.Context, procedure model
 	return nil
 }
 
-// StoreProcedures stores multiple OWASP procedures in the repository
+// StoreProcedures stores multiple OWASP procedures in the repository,
+// stopping if ctx is done
 func (r *SecurityRepository) StoreProcedures(ctx context.Context, procedures []models.OWASPProcedure) error {
 	for _, procedure := range procedures {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := r.StoreProcedure(ctx, procedure); err != nil {
 			return fmt.Errorf("failed to store procedure %s: %w", procedure.ID, err)
 		}
@@ -180,11 +199,15 @@ func (r *SecurityRepository) GetProcedure(ctx context.Context, id string) (*mode
 	return &procedure, nil
 }
 
-// QueryProcedures searches for OWASP procedures based on query parameters
+// QueryProcedures searches for OWASP procedures based on query parameters,
+// stopping if ctx is done
 func (r *SecurityRepository) QueryProcedures(ctx context.Context, query models.IntelligenceQuery) (*models.IntelligenceResponse, error) {
 	var results []interface{}
 
 	for _, procedure := range r.procedures {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		// Simple text search in title, description, and category
 		if query.Query == "" ||
 			contains(procedure.Title, query.Query) ||
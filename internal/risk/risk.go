@@ -0,0 +1,225 @@
+// Package risk assesses a register of risks to the options of a decision.
+// Each risk strikes with a probability and costs an impact, a loss in the
+// units of the options' expected values, and mitigations reduce either by a
+// fraction at a certain cost. Risks are placed on a probability-impact matrix
+// of Levels by Levels cells, probability in equal bands of [0, 1] and impact
+// in equal bands up to the largest impact in the register, and rated by the
+// product of their levels. An option's expected loss is the sum of the
+// probability times the impact of the risks it runs, after mitigation, and
+// its risk-adjusted value is its expected value less its expected loss and
+// the cost of its mitigations.
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Levels is the number of probability and impact levels of the matrix
+const Levels = 5
+
+// Ratings of a risk by its severity, the product of its probability and
+// impact levels
+const (
+	Low      = "low"      // severity up to 4
+	Medium   = "medium"   // up to 9
+	High     = "high"     // up to 16
+	Critical = "critical" // above 16
+)
+
+// Risk is a risk in the register
+type Risk struct {
+	Name string
+	// Option names the option the risk threatens; a risk naming none
+	// threatens every option
+	Option      string
+	Probability float64
+	Impact      float64
+	Mitigations []Mitigation
+}
+
+// Mitigation is a measure against a risk
+type Mitigation struct {
+	Name string
+	// ProbabilityReduction and ImpactReduction are the fractions by which
+	// the mitigation cuts the risk's probability and impact; the cuts of a
+	// risk's mitigations compound
+	ProbabilityReduction float64
+	ImpactReduction      float64
+	Cost                 float64
+}
+
+// Option is an option the risks threaten
+type Option struct {
+	Name          string
+	ExpectedValue float64
+}
+
+// Level places a risk on the matrix
+type Level struct {
+	Probability int
+	Impact      int
+	Severity    int
+	Rating      string
+}
+
+// Assessed is an assessed risk
+type Assessed struct {
+	Risk
+	// Inherent places the risk before mitigation and Residual after it
+	Inherent            Level
+	Residual            Level
+	ResidualProbability float64
+	ResidualImpact      float64
+	// ExpectedLoss is the residual probability times the residual impact
+	ExpectedLoss   float64
+	MitigationCost float64
+}
+
+// OptionRisk is the risk an option runs
+type OptionRisk struct {
+	Option
+	Risks          int
+	ExpectedLoss   float64
+	MitigationCost float64
+	// RiskAdjustedValue is the expected value less the expected loss and
+	// the mitigation cost
+	RiskAdjustedValue float64
+	// Rating is the worst residual rating of the option's risks, Low when
+	// it runs none
+	Rating string
+}
+
+// Matrix counts risks by probability level, the rows, and impact level, the
+// columns, both from 1 at index 0
+type Matrix [Levels][Levels]int
+
+// Assessment is the assessment of a register
+type Assessment struct {
+	Risks []Assessed
+	// Inherent and Residual count the risks before and after mitigation
+	Inherent Matrix
+	Residual Matrix
+	// Options holds the options by risk-adjusted value, highest first
+	Options []OptionRisk
+}
+
+// Assess assesses the risks to options
+func Assess(risks []Risk, options []Option) (*Assessment, error) {
+	if len(options) == 0 {
+		return nil, errors.New("there are no options")
+	}
+	index := make(map[string]int, len(options))
+	for i, option := range options {
+		if _, ok := index[option.Name]; ok {
+			return nil, fmt.Errorf("option %s is listed twice", option.Name)
+		}
+		index[option.Name] = i
+	}
+
+	maxImpact := 0.0
+	for _, r := range risks {
+		switch {
+		case r.Name == "":
+			return nil, errors.New("every risk needs a name")
+		case !(r.Probability >= 0 && r.Probability <= 1):
+			return nil, fmt.Errorf("risk %s needs a probability between 0 and 1", r.Name)
+		case !(r.Impact >= 0) || math.IsInf(r.Impact, 0):
+			return nil, fmt.Errorf("risk %s needs a finite, non-negative impact", r.Name)
+		}
+		if _, ok := index[r.Option]; r.Option != "" && !ok {
+			return nil, fmt.Errorf("risk %s threatens unknown option %s", r.Name, r.Option)
+		}
+		for _, m := range r.Mitigations {
+			switch {
+			case !(m.ProbabilityReduction >= 0 && m.ProbabilityReduction <= 1), !(m.ImpactReduction >= 0 && m.ImpactReduction <= 1):
+				return nil, fmt.Errorf("mitigation %s of risk %s needs reductions between 0 and 1", m.Name, r.Name)
+			case !(m.Cost >= 0) || math.IsInf(m.Cost, 0):
+				return nil, fmt.Errorf("mitigation %s of risk %s needs a finite, non-negative cost", m.Name, r.Name)
+			}
+		}
+		maxImpact = math.Max(maxImpact, r.Impact)
+	}
+
+	assessment := &Assessment{Risks: make([]Assessed, len(risks)), Options: make([]OptionRisk, len(options))}
+	for i, option := range options {
+		assessment.Options[i] = OptionRisk{Option: option, Rating: Low}
+	}
+	for k, r := range risks {
+		a := Assessed{Risk: r, ResidualProbability: r.Probability, ResidualImpact: r.Impact}
+		for _, m := range r.Mitigations {
+			a.ResidualProbability *= 1 - m.ProbabilityReduction
+			a.ResidualImpact *= 1 - m.ImpactReduction
+			a.MitigationCost += m.Cost
+		}
+		a.ExpectedLoss = a.ResidualProbability * a.ResidualImpact
+		a.Inherent = place(r.Probability, r.Impact, maxImpact)
+		a.Residual = place(a.ResidualProbability, a.ResidualImpact, maxImpact)
+		assessment.Inherent[a.Inherent.Probability-1][a.Inherent.Impact-1]++
+		assessment.Residual[a.Residual.Probability-1][a.Residual.Impact-1]++
+		assessment.Risks[k] = a
+
+		for i := range assessment.Options {
+			option := &assessment.Options[i]
+			if r.Option != "" && r.Option != option.Name {
+				continue
+			}
+			option.Risks++
+			option.ExpectedLoss += a.ExpectedLoss
+			option.MitigationCost += a.MitigationCost
+			if severity(option.Rating) < a.Residual.Severity {
+				option.Rating = a.Residual.Rating
+			}
+		}
+	}
+	for i := range assessment.Options {
+		option := &assessment.Options[i]
+		option.RiskAdjustedValue = option.ExpectedValue - option.ExpectedLoss - option.MitigationCost
+	}
+	sort.SliceStable(assessment.Options, func(a, b int) bool {
+		return assessment.Options[a].RiskAdjustedValue > assessment.Options[b].RiskAdjustedValue
+	})
+	return assessment, nil
+}
+
+// place places a risk of probability p and impact x on the matrix, impact
+// banded up to maxImpact
+func place(p, x, maxImpact float64) Level {
+	level := Level{Probability: band(p), Impact: 1}
+	if maxImpact > 0 {
+		level.Impact = band(x / maxImpact)
+	}
+	level.Severity = level.Probability * level.Impact
+	switch {
+	case level.Severity <= 4:
+		level.Rating = Low
+	case level.Severity <= 9:
+		level.Rating = Medium
+	case level.Severity <= 16:
+		level.Rating = High
+	default:
+		level.Rating = Critical
+	}
+	return level
+}
+
+// band returns the level, from 1 to Levels, of a fraction in [0, 1]; each
+// level's band includes its upper bound
+func band(fraction float64) int {
+	return int(math.Max(1, math.Min(Levels, math.Ceil(fraction*Levels-1e-9))))
+}
+
+// severity returns the least severity of a rating
+func severity(rating string) int {
+	switch rating {
+	case Medium:
+		return 5
+	case High:
+		return 10
+	case Critical:
+		return 17
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package risk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssess_PlacesRisksOnTheMatrix(t *testing.T) {
+	result, err := Assess([]Risk{
+		{Name: "outage", Option: "cloud", Probability: 0.9, Impact: 100},
+		{Name: "breach", Option: "cloud", Probability: 0.1, Impact: 50},
+		{Name: "hardware", Option: "on-prem", Probability: 0.5, Impact: 60},
+	}, []Option{{Name: "cloud", ExpectedValue: 200}, {Name: "on-prem", ExpectedValue: 150}})
+	require.NoError(t, err)
+
+	// Impact is banded up to the largest, 100
+	assert.Equal(t, Level{Probability: 5, Impact: 5, Severity: 25, Rating: Critical}, result.Risks[0].Inherent)
+	assert.Equal(t, Level{Probability: 1, Impact: 3, Severity: 3, Rating: Low}, result.Risks[1].Inherent)
+	assert.Equal(t, Level{Probability: 3, Impact: 3, Severity: 9, Rating: Medium}, result.Risks[2].Inherent)
+	assert.Equal(t, 1, result.Inherent[4][4])
+	assert.Equal(t, 1, result.Inherent[0][2])
+	assert.Equal(t, 1, result.Inherent[2][2])
+	assert.Equal(t, result.Inherent, result.Residual)
+
+	// cloud loses 0.9·100 + 0.1·50 and on-prem 0.5·60
+	require.Len(t, result.Options, 2)
+	cloud, onPrem := result.Options[1], result.Options[0]
+	assert.Equal(t, "cloud", cloud.Name)
+	assert.Equal(t, 2, cloud.Risks)
+	assert.InDelta(t, 95, cloud.ExpectedLoss, 1e-12)
+	assert.InDelta(t, 105, cloud.RiskAdjustedValue, 1e-12)
+	assert.Equal(t, Critical, cloud.Rating)
+	assert.Equal(t, "on-prem", onPrem.Name)
+	assert.InDelta(t, 120, onPrem.RiskAdjustedValue, 1e-12)
+	assert.Equal(t, Medium, onPrem.Rating)
+}
+
+func TestAssess_CompoundsMitigations(t *testing.T) {
+	result, err := Assess([]Risk{
+		{Name: "outage", Probability: 0.8, Impact: 100, Mitigations: []Mitigation{
+			{Name: "failover", ProbabilityReduction: 0.5, Cost: 5},
+			{Name: "monitoring", ProbabilityReduction: 0.5, ImpactReduction: 0.2, Cost: 2},
+		}},
+	}, []Option{{Name: "a", ExpectedValue: 100}, {Name: "b"}})
+	require.NoError(t, err)
+
+	outage := result.Risks[0]
+	assert.InDelta(t, 0.2, outage.ResidualProbability, 1e-12)
+	assert.InDelta(t, 80, outage.ResidualImpact, 1e-12)
+	assert.InDelta(t, 16, outage.ExpectedLoss, 1e-12)
+	assert.InDelta(t, 7, outage.MitigationCost, 1e-12)
+	assert.Equal(t, Level{Probability: 4, Impact: 5, Severity: 20, Rating: Critical}, outage.Inherent)
+	assert.Equal(t, Level{Probability: 1, Impact: 4, Severity: 4, Rating: Low}, outage.Residual)
+	assert.Equal(t, 1, result.Residual[0][3])
+
+	// A risk naming no option threatens them all
+	for _, option := range result.Options {
+		assert.Equal(t, 1, option.Risks)
+		assert.InDelta(t, option.ExpectedValue-23, option.RiskAdjustedValue, 1e-12)
+	}
+}
+
+func TestAssess_RatesOptionsWithoutRisksLow(t *testing.T) {
+	result, err := Assess(nil, []Option{{Name: "a", ExpectedValue: 3}})
+	require.NoError(t, err)
+	assert.Equal(t, Low, result.Options[0].Rating)
+	assert.Equal(t, 3.0, result.Options[0].RiskAdjustedValue)
+	assert.Equal(t, Matrix{}, result.Inherent)
+
+	// Risks without impact sit in the lowest impact band
+	result, err = Assess([]Risk{{Name: "harmless", Probability: 1}}, []Option{{Name: "a"}})
+	require.NoError(t, err)
+	assert.Equal(t, Level{Probability: 5, Impact: 1, Severity: 5, Rating: Medium}, result.Risks[0].Inherent)
+}
+
+func TestAssess_RejectsInvalidRegisters(t *testing.T) {
+	options := []Option{{Name: "a"}}
+	for name, c := range map[string]struct {
+		risks   []Risk
+		options []Option
+	}{
+		"no options":       {nil, nil},
+		"duplicate option": {nil, []Option{{Name: "a"}, {Name: "a"}}},
+		"unnamed":          {[]Risk{{Probability: 0.5}}, options},
+		"probability":      {[]Risk{{Name: "r", Probability: 1.5}}, options},
+		"NaN probability":  {[]Risk{{Name: "r", Probability: math.NaN()}}, options},
+		"negative impact":  {[]Risk{{Name: "r", Impact: -1}}, options},
+		"unknown option":   {[]Risk{{Name: "r", Option: "b"}}, options},
+		"reduction":        {[]Risk{{Name: "r", Mitigations: []Mitigation{{ImpactReduction: 2}}}}, options},
+		"negative cost":    {[]Risk{{Name: "r", Mitigations: []Mitigation{{Cost: -1}}}}, options},
+		"infinite impact":  {[]Risk{{Name: "r", Impact: math.Inf(1)}}, options},
+	} {
+		_, err := Assess(c.risks, c.options)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,159 @@
+// Package scenario evaluates options against named futures, each with a
+// probability, from the outcome of every option in every scenario, higher
+// being better. An option's regret in a scenario is how far its outcome falls
+// short of the best option's there, and its relative regret that shortfall as
+// a share of the scenario's spread, the best outcome less the worst. An option
+// whose relative regret stays within a tolerance in every scenario is robust,
+// good whatever the future; one that does best in some scenario but falls
+// beyond the tolerance in another is a bet on the scenarios it wins; and one
+// that is neither is weak.
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Classes of option
+const (
+	Robust = "robust"
+	Bet    = "bet"
+	Weak   = "weak"
+)
+
+// DefaultTolerance is the relative regret a robust option may reach in a
+// scenario: it stays in the better half of the outcomes of every scenario
+const DefaultTolerance = 0.5
+
+// Scenario is a named future
+type Scenario struct {
+	Name        string
+	Probability float64
+}
+
+// Option is an option's evaluation across the scenarios
+type Option struct {
+	Name  string
+	Class string
+	// ExpectedValue is the probability-weighted mean outcome, and Worst and
+	// Best the least and greatest outcomes
+	ExpectedValue float64
+	Worst         float64
+	Best          float64
+	// MaxRegret is the greatest regret in any scenario and ExpectedRegret
+	// the probability-weighted mean regret
+	MaxRegret      float64
+	ExpectedRegret float64
+	// BestIn names the scenarios in which the option does best, sharing ties
+	BestIn []string
+	// WeakestIn names the scenario of the greatest relative regret, empty
+	// when the option does best in every scenario
+	WeakestIn string
+}
+
+// Result is the evaluation of the options
+type Result struct {
+	// Options holds the options in their given order
+	Options []Option
+	// Probabilities are those of the scenarios, normalized to sum to 1
+	Probabilities []float64
+}
+
+// Analyze evaluates options in scenarios from outcomes, holding the outcome
+// of each option in each scenario, in their orders. The probabilities must
+// sum to 1 within 0.01 and are normalized; tolerance is from 0 to 1.
+func Analyze(scenarios []Scenario, options []string, outcomes [][]float64, tolerance float64) (*Result, error) {
+	n, m := len(options), len(scenarios)
+	switch {
+	case n < 2:
+		return nil, errors.New("there must be at least two options")
+	case m == 0:
+		return nil, errors.New("there are no scenarios")
+	case !(tolerance >= 0 && tolerance <= 1):
+		return nil, errors.New("the tolerance must be from 0 to 1")
+	case len(outcomes) != n:
+		return nil, fmt.Errorf("there are outcomes for %d options, not %d", len(outcomes), n)
+	}
+	names := make(map[string]bool, n)
+	for _, option := range options {
+		if names[option] {
+			return nil, fmt.Errorf("option %s is listed twice", option)
+		}
+		names[option] = true
+	}
+	names = make(map[string]bool, m)
+	total := 0.0
+	for k, s := range scenarios {
+		switch {
+		case s.Name == "":
+			return nil, fmt.Errorf("scenario %d has no name", k+1)
+		case names[s.Name]:
+			return nil, fmt.Errorf("scenario %s is listed twice", s.Name)
+		case !(s.Probability >= 0 && s.Probability <= 1):
+			return nil, fmt.Errorf("the probability of scenario %s must be from 0 to 1", s.Name)
+		}
+		names[s.Name] = true
+		total += s.Probability
+	}
+	if math.Abs(total-1) > 0.01+1e-9 {
+		return nil, fmt.Errorf("the scenario probabilities sum to %.4g, not 1", total)
+	}
+	for i, row := range outcomes {
+		if len(row) != m {
+			return nil, fmt.Errorf("option %s has %d outcomes, not %d", options[i], len(row), m)
+		}
+		for j, value := range row {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("the outcome of option %s in scenario %s is not finite", options[i], scenarios[j].Name)
+			}
+		}
+	}
+
+	result := &Result{Options: make([]Option, n), Probabilities: make([]float64, m)}
+	for j, s := range scenarios {
+		result.Probabilities[j] = s.Probability / total
+	}
+	best := make([]float64, m)
+	spread := make([]float64, m)
+	for j := range scenarios {
+		best[j], spread[j] = outcomes[0][j], outcomes[0][j]
+		for i := range options {
+			best[j] = math.Max(best[j], outcomes[i][j])
+			spread[j] = math.Min(spread[j], outcomes[i][j])
+		}
+		spread[j] = best[j] - spread[j]
+	}
+
+	for i, name := range options {
+		o := Option{Name: name, Worst: outcomes[i][0], Best: outcomes[i][0]}
+		weakest, within := 0.0, true
+		for j, s := range scenarios {
+			value, p := outcomes[i][j], result.Probabilities[j]
+			regret := best[j] - value
+			o.ExpectedValue += p * value
+			o.ExpectedRegret += p * regret
+			o.Worst, o.Best = math.Min(o.Worst, value), math.Max(o.Best, value)
+			o.MaxRegret = math.Max(o.MaxRegret, regret)
+			if regret == 0 {
+				o.BestIn = append(o.BestIn, s.Name)
+				continue
+			}
+			relative := regret / spread[j]
+			if relative > weakest {
+				weakest, o.WeakestIn = relative, s.Name
+			}
+			within = within && relative <= tolerance
+		}
+		switch {
+		case within:
+			o.Class = Robust
+		case len(o.BestIn) > 0:
+			o.Class = Bet
+		default:
+			o.Class = Weak
+		}
+		result.Options[i] = o
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package scenario
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var futures = []Scenario{{Name: "boom", Probability: 0.3}, {Name: "base", Probability: 0.5}, {Name: "bust", Probability: 0.2}}
+
+func TestAnalyze_SeparatesRobustOptionsFromBets(t *testing.T) {
+	// The best outcomes are 100, 45 and 20, the spreads 90, 30 and 70
+	result, err := Analyze(futures, []string{"expand", "steady", "hold", "lag"}, [][]float64{
+		{100, 40, -50},
+		{60, 45, 10},
+		{20, 20, 20},
+		{10, 15, 0},
+	}, DefaultTolerance)
+	require.NoError(t, err)
+
+	expand, steady, hold, lag := result.Options[0], result.Options[1], result.Options[2], result.Options[3]
+	assert.Equal(t, Bet, expand.Class)
+	assert.Equal(t, []string{"boom"}, expand.BestIn)
+	assert.Equal(t, "bust", expand.WeakestIn)
+	assert.InDelta(t, 40, expand.ExpectedValue, 1e-12)
+	assert.InDelta(t, 16.5, expand.ExpectedRegret, 1e-12)
+	assert.Equal(t, 70.0, expand.MaxRegret)
+	assert.Equal(t, -50.0, expand.Worst)
+	assert.Equal(t, 100.0, expand.Best)
+
+	// 40 short of the best in a boom is 4/9 of its spread
+	assert.Equal(t, Robust, steady.Class)
+	assert.Equal(t, []string{"base"}, steady.BestIn)
+	assert.Equal(t, "boom", steady.WeakestIn)
+	assert.InDelta(t, 42.5, steady.ExpectedValue, 1e-12)
+	assert.InDelta(t, 14, steady.ExpectedRegret, 1e-12)
+
+	assert.Equal(t, Bet, hold.Class)
+	assert.Equal(t, []string{"bust"}, hold.BestIn)
+	assert.Equal(t, Weak, lag.Class)
+	assert.Empty(t, lag.BestIn)
+
+	// A tighter tolerance makes a bet of the robust option
+	result, err = Analyze(futures, []string{"expand", "steady"}, [][]float64{{100, 40, -50}, {60, 45, 10}}, 0.4)
+	require.NoError(t, err)
+	assert.Equal(t, Bet, result.Options[1].Class)
+}
+
+func TestAnalyze_NormalizesProbabilitiesAndSharesTies(t *testing.T) {
+	thirds := []Scenario{{Name: "a", Probability: 0.33}, {Name: "b", Probability: 0.33}, {Name: "c", Probability: 0.33}}
+	result, err := Analyze(thirds, []string{"x", "y"}, [][]float64{{1, 2, 3}, {1, 2, 3}}, DefaultTolerance)
+	require.NoError(t, err)
+	for _, p := range result.Probabilities {
+		assert.InDelta(t, 1.0/3, p, 1e-12)
+	}
+	for _, o := range result.Options {
+		assert.Equal(t, Robust, o.Class)
+		assert.Equal(t, []string{"a", "b", "c"}, o.BestIn)
+		assert.Empty(t, o.WeakestIn)
+		assert.InDelta(t, 2, o.ExpectedValue, 1e-12)
+		assert.Equal(t, 0.0, o.MaxRegret)
+	}
+}
+
+func TestAnalyze_RejectsInvalidInput(t *testing.T) {
+	options := []string{"x", "y"}
+	outcomes := [][]float64{{1, 2, 3}, {3, 2, 1}}
+	for name, c := range map[string]struct {
+		scenarios []Scenario
+		options   []string
+		outcomes  [][]float64
+		tolerance float64
+	}{
+		"one option":        {futures, []string{"x"}, outcomes[:1], 0.5},
+		"duplicate option":  {futures, []string{"x", "x"}, outcomes, 0.5},
+		"no scenarios":      {nil, options, [][]float64{{}, {}}, 0.5},
+		"unnamed scenario":  {[]Scenario{{Probability: 0.5}, {Name: "b", Probability: 0.5}}, options, [][]float64{{1, 2}, {2, 1}}, 0.5},
+		"duplicate":         {[]Scenario{{Name: "a", Probability: 0.5}, {Name: "a", Probability: 0.5}}, options, [][]float64{{1, 2}, {2, 1}}, 0.5},
+		"negative":          {[]Scenario{{Name: "a", Probability: 1.5}, {Name: "b", Probability: -0.5}}, options, [][]float64{{1, 2}, {2, 1}}, 0.5},
+		"not summing to 1":  {[]Scenario{{Name: "a", Probability: 0.5}, {Name: "b", Probability: 0.4}}, options, [][]float64{{1, 2}, {2, 1}}, 0.5},
+		"missing outcome":   {futures, options, [][]float64{{1, 2, 3}, {3, 2}}, 0.5},
+		"missing option":    {futures, options, outcomes[:1], 0.5},
+		"NaN outcome":       {futures, options, [][]float64{{1, math.NaN(), 3}, {3, 2, 1}}, 0.5},
+		"tolerance above 1": {futures, options, outcomes, 1.5},
+	} {
+		_, err := Analyze(c.scenarios, c.options, c.outcomes, c.tolerance)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,204 @@
+// Package search provides full-text search over the content of a session.
+//
+// Thought text, mental model conclusions and decision statements are indexed
+// per request and ranked with TF-IDF, so rare terms weigh more than common ones
+// and an exact phrase match ranks above scattered terms.
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/rainmana/gothink/internal/storage"
+)
+
+// DefaultLimit is the number of hits returned when no limit is given
+const DefaultLimit = 20
+
+// snippetRadius is the number of characters shown either side of a match
+const snippetRadius = 60
+
+// Hit is a record matching a search
+type Hit struct {
+	Kind    string  `json:"kind"`
+	ID      string  `json:"id"`
+	Field   string  `json:"field"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+// document is a searchable field of a record
+type document struct {
+	kind   string
+	id     string
+	field  string
+	text   string
+	terms  map[string]int
+	length int
+}
+
+// Session searches the session's records and returns up to limit hits, best first
+func Session(store storage.Store, sessionID, query string, limit int) ([]Hit, error) {
+	queryTerms := uniqueTerms(tokenize(query))
+	if len(queryTerms) == 0 {
+		return nil, fmt.Errorf("search query must contain at least one word")
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	docs, err := collect(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Document frequency of each query term
+	df := make(map[string]int)
+	for _, doc := range docs {
+		for _, term := range queryTerms {
+			if doc.terms[term] > 0 {
+				df[term]++
+			}
+		}
+	}
+
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	hits := []Hit{}
+	for _, doc := range docs {
+		score := 0.0
+		for _, term := range queryTerms {
+			tf := doc.terms[term]
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + float64(len(docs))/float64(df[term]))
+			score += float64(tf) / float64(doc.length) * idf
+		}
+		if score == 0 {
+			continue
+		}
+
+		lower := strings.ToLower(doc.text)
+		if len(queryTerms) > 1 && strings.Contains(lower, phrase) {
+			score *= 2
+		}
+
+		hits = append(hits, Hit{
+			Kind:    doc.kind,
+			ID:      doc.id,
+			Field:   doc.field,
+			Score:   math.Round(score*1e4) / 1e4,
+			Snippet: snippet(doc.text, queryTerms),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
+
+// collect gathers the searchable fields of the session's records
+func collect(store storage.Store, sessionID string) ([]*document, error) {
+	var docs []*document
+	add := func(kind, id, field, text string) {
+		terms := tokenize(text)
+		if len(terms) == 0 {
+			return
+		}
+		counts := make(map[string]int, len(terms))
+		for _, term := range terms {
+			counts[term]++
+		}
+		docs = append(docs, &document{kind: kind, id: id, field: field, text: text, terms: counts, length: len(terms)})
+	}
+
+	thoughts, err := store.GetThoughts(sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, thought := range thoughts {
+		add(storage.KindThoughts, thought.ID, "thought", thought.Thought)
+	}
+
+	mentalModels, err := store.GetMentalModels(sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, model := range mentalModels {
+		add(storage.KindMentalModels, model.ID, "conclusion", model.Conclusion)
+	}
+
+	decisions, err := store.GetDecisions(sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, decision := range decisions {
+		add(storage.KindDecisions, decision.ID, "decision_statement", decision.DecisionStatement)
+	}
+
+	return docs, nil
+}
+
+// tokenize splits text into lower-case words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// uniqueTerms removes repeated terms, keeping the first occurrence
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	var unique []string
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// snippet returns the text surrounding the first matching term
+func snippet(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	start := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); i >= 0 && (start < 0 || i < start) {
+			start = i
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	// Lower-casing preserves the rune count, so the rune offset carries over to text
+	runes := []rune(text)
+	pos := len([]rune(lower[:start]))
+	from := pos - snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	to := pos + snippetRadius
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	result := strings.TrimSpace(string(runes[from:to]))
+	if from > 0 {
+		result = "..." + result
+	}
+	if to < len(runes) {
+		result += "..."
+	}
+
+	return result
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_RanksMatchesAcrossRecordTypes(t *testing.T) {
+	store := storage.NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "The cache layer hides database latency"}))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t2", Thought: "Users complain about slow pages"}))
+	require.NoError(t, store.AddMentalModel("s1", &types.MentalModelData{ID: "m1", Conclusion: "Database latency is the cause"}))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{ID: "d1", DecisionStatement: "Which database should we use?"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{ID: "other", Thought: "database latency elsewhere"}))
+
+	hits, err := Session(store, "s1", "database latency", 0)
+	require.NoError(t, err)
+	require.Len(t, hits, 3)
+
+	// The conclusion contains the exact phrase and is shorter than the thought
+	assert.Equal(t, "m1", hits[0].ID)
+	assert.Equal(t, storage.KindMentalModels, hits[0].Kind)
+	assert.Equal(t, "conclusion", hits[0].Field)
+	assert.Equal(t, "t1", hits[1].ID)
+	assert.Equal(t, "d1", hits[2].ID)
+
+	hits, err = Session(store, "s1", "database", 1)
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+}
+
+func TestSession_RejectsEmptyQuery(t *testing.T) {
+	store := storage.NewMemoryStore(config.DefaultConfig())
+
+	_, err := Session(store, "s1", "  ?! ", 0)
+	assert.Error(t, err)
+}
+
+func TestSnippet_TrimsLongText(t *testing.T) {
+	text := "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Needle here. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip"
+
+	result := snippet(text, []string{"needle"})
+	assert.Contains(t, result, "Needle here")
+	assert.True(t, len(result) < len(text))
+	assert.Contains(t, result, "...")
+}
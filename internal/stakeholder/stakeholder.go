@@ -0,0 +1,196 @@
+// Package stakeholder analyzes the stakeholders of a decision. Each
+// stakeholder has an influence over the decision and an interest in it, both
+// from 0 to 1, which place it on Mendelow's influence/interest grid, and a
+// support for each option from -1, opposing it, to 1, backing it. A
+// stakeholder's weight is its salience, the product of its influence and
+// interest, over that of all the stakeholders, and an option's score is the
+// weighted mean of the stakeholders' support for it, a stakeholder without a
+// preference counting as neutral.
+package stakeholder
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Quadrants of the grid, by whether influence and interest are high, at
+// least Threshold
+const (
+	ManageClosely = "manage_closely" // high influence and interest
+	KeepSatisfied = "keep_satisfied" // high influence, low interest
+	KeepInformed  = "keep_informed"  // low influence, high interest
+	Monitor       = "monitor"        // low influence and interest
+)
+
+// Threshold is the influence or interest from which it counts as high
+const Threshold = 0.5
+
+// Stakeholder is a party to the decision
+type Stakeholder struct {
+	Name        string
+	Influence   float64
+	Interest    float64
+	Preferences []Preference
+}
+
+// Preference is a stakeholder's support for an option
+type Preference struct {
+	Option  string
+	Support float64
+}
+
+// Positioned is a stakeholder placed on the grid
+type Positioned struct {
+	Stakeholder
+	Quadrant string
+	// Weight is the stakeholder's share of the salience of all stakeholders
+	Weight float64
+}
+
+// Grid lists the stakeholders of each quadrant, most salient first
+type Grid struct {
+	ManageClosely []string
+	KeepSatisfied []string
+	KeepInformed  []string
+	Monitor       []string
+}
+
+// Scored is an option's stakeholder-weighted score
+type Scored struct {
+	// Rank is the option's place by score, shared by tied options
+	Rank   int
+	Option string
+	Score  float64
+	// Supporters and Opponents name the stakeholders backing and opposing
+	// the option, most salient first
+	Supporters []string
+	Opponents  []string
+}
+
+// Analysis is the analysis of a decision's stakeholders
+type Analysis struct {
+	// Stakeholders holds the stakeholders in their given order
+	Stakeholders []Positioned
+	Grid         Grid
+	// Options holds the options by score, highest first
+	Options []Scored
+}
+
+// Analyze places stakeholders on the grid and scores options by their
+// weighted support
+func Analyze(stakeholders []Stakeholder, options []string) (*Analysis, error) {
+	if len(stakeholders) == 0 {
+		return nil, errors.New("there are no stakeholders")
+	}
+	if len(options) == 0 {
+		return nil, errors.New("there are no options")
+	}
+	index := make(map[string]int, len(options))
+	for i, option := range options {
+		if _, ok := index[option]; ok {
+			return nil, fmt.Errorf("option %s is listed twice", option)
+		}
+		index[option] = i
+	}
+
+	names := make(map[string]bool, len(stakeholders))
+	total := 0.0
+	for i, s := range stakeholders {
+		switch {
+		case s.Name == "":
+			return nil, fmt.Errorf("stakeholder %d has no name", i+1)
+		case names[s.Name]:
+			return nil, fmt.Errorf("stakeholder %s is listed twice", s.Name)
+		case !(s.Influence >= 0 && s.Influence <= 1):
+			return nil, fmt.Errorf("stakeholder %s needs an influence between 0 and 1", s.Name)
+		case !(s.Interest >= 0 && s.Interest <= 1):
+			return nil, fmt.Errorf("stakeholder %s needs an interest between 0 and 1", s.Name)
+		}
+		names[s.Name] = true
+		stated := make(map[string]bool, len(s.Preferences))
+		for _, p := range s.Preferences {
+			switch {
+			case !hasOption(index, p.Option):
+				return nil, fmt.Errorf("stakeholder %s prefers unknown option %s", s.Name, p.Option)
+			case stated[p.Option]:
+				return nil, fmt.Errorf("stakeholder %s states its support for %s twice", s.Name, p.Option)
+			case !(p.Support >= -1 && p.Support <= 1):
+				return nil, fmt.Errorf("stakeholder %s needs a support for %s between -1 and 1", s.Name, p.Option)
+			}
+			stated[p.Option] = true
+		}
+		total += s.Influence * s.Interest
+	}
+	if total == 0 {
+		return nil, errors.New("no stakeholder has both influence and interest")
+	}
+
+	analysis := &Analysis{Stakeholders: make([]Positioned, len(stakeholders)), Options: make([]Scored, len(options))}
+	for i, option := range options {
+		analysis.Options[i] = Scored{Option: option}
+	}
+	for i, s := range stakeholders {
+		p := Positioned{Stakeholder: s, Quadrant: quadrant(s), Weight: s.Influence * s.Interest / total}
+		analysis.Stakeholders[i] = p
+		for _, preference := range s.Preferences {
+			analysis.Options[index[preference.Option]].Score += p.Weight * preference.Support
+		}
+	}
+
+	// List stakeholders most salient first, in their given order on ties
+	bySalience := make([]Positioned, len(analysis.Stakeholders))
+	copy(bySalience, analysis.Stakeholders)
+	sort.SliceStable(bySalience, func(a, b int) bool { return bySalience[a].Weight > bySalience[b].Weight })
+	for _, p := range bySalience {
+		switch p.Quadrant {
+		case ManageClosely:
+			analysis.Grid.ManageClosely = append(analysis.Grid.ManageClosely, p.Name)
+		case KeepSatisfied:
+			analysis.Grid.KeepSatisfied = append(analysis.Grid.KeepSatisfied, p.Name)
+		case KeepInformed:
+			analysis.Grid.KeepInformed = append(analysis.Grid.KeepInformed, p.Name)
+		default:
+			analysis.Grid.Monitor = append(analysis.Grid.Monitor, p.Name)
+		}
+		for _, preference := range p.Preferences {
+			scored := &analysis.Options[index[preference.Option]]
+			switch {
+			case preference.Support > 0:
+				scored.Supporters = append(scored.Supporters, p.Name)
+			case preference.Support < 0:
+				scored.Opponents = append(scored.Opponents, p.Name)
+			}
+		}
+	}
+
+	sort.SliceStable(analysis.Options, func(a, b int) bool { return analysis.Options[a].Score > analysis.Options[b].Score })
+	for i := range analysis.Options {
+		analysis.Options[i].Rank = i + 1
+		if i > 0 && math.Abs(analysis.Options[i].Score-analysis.Options[i-1].Score) < 1e-12 {
+			analysis.Options[i].Rank = analysis.Options[i-1].Rank
+		}
+	}
+	return analysis, nil
+}
+
+// hasOption reports whether option is one of the options of index
+func hasOption(index map[string]int, option string) bool {
+	_, ok := index[option]
+	return ok
+}
+
+// quadrant returns the quadrant of the grid s falls in
+func quadrant(s Stakeholder) string {
+	switch influential, interested := s.Influence >= Threshold, s.Interest >= Threshold; {
+	case influential && interested:
+		return ManageClosely
+	case influential:
+		return KeepSatisfied
+	case interested:
+		return KeepInformed
+	default:
+		return Monitor
+	}
+}
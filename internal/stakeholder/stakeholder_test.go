@@ -0,0 +1,85 @@
+package stakeholder
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze_PlacesStakeholdersOnTheGrid(t *testing.T) {
+	result, err := Analyze([]Stakeholder{
+		{Name: "board", Influence: 0.9, Interest: 0.2},
+		{Name: "engineering", Influence: 0.6, Interest: 0.9},
+		{Name: "customers", Influence: 0.3, Interest: 0.8},
+		{Name: "press", Influence: 0.1, Interest: 0.1},
+		{Name: "finance", Influence: 0.8, Interest: 0.5},
+	}, []string{"build"})
+	require.NoError(t, err)
+
+	assert.Equal(t, KeepSatisfied, result.Stakeholders[0].Quadrant)
+	assert.Equal(t, ManageClosely, result.Stakeholders[1].Quadrant)
+	assert.Equal(t, KeepInformed, result.Stakeholders[2].Quadrant)
+	assert.Equal(t, Monitor, result.Stakeholders[3].Quadrant)
+
+	// Saliences are 0.18, 0.54, 0.24, 0.01 and 0.4, of 1.37
+	assert.Equal(t, []string{"engineering", "finance"}, result.Grid.ManageClosely)
+	assert.Equal(t, []string{"board"}, result.Grid.KeepSatisfied)
+	assert.Equal(t, []string{"customers"}, result.Grid.KeepInformed)
+	assert.Equal(t, []string{"press"}, result.Grid.Monitor)
+	assert.InDelta(t, 0.54/1.37, result.Stakeholders[1].Weight, 1e-12)
+}
+
+func TestAnalyze_WeighsSupportBySalience(t *testing.T) {
+	result, err := Analyze([]Stakeholder{
+		{Name: "engineering", Influence: 0.8, Interest: 1, Preferences: []Preference{{Option: "build", Support: 1}, {Option: "buy", Support: -0.5}}},
+		{Name: "finance", Influence: 0.5, Interest: 0.8, Preferences: []Preference{{Option: "buy", Support: 1}, {Option: "build", Support: -1}}},
+		{Name: "sales", Influence: 0.4, Interest: 0.5, Preferences: []Preference{{Option: "buy", Support: 0.5}}},
+	}, []string{"build", "buy", "defer", "delay"})
+	require.NoError(t, err)
+
+	// Weights are 0.8, 0.4 and 0.2 of 1.4; sales is neutral on building
+	require.Len(t, result.Options, 4)
+	build, buy := result.Options[0], result.Options[1]
+	assert.Equal(t, "build", build.Option)
+	assert.InDelta(t, (0.8-0.4)/1.4, build.Score, 1e-12)
+	assert.Equal(t, []string{"engineering"}, build.Supporters)
+	assert.Equal(t, []string{"finance"}, build.Opponents)
+	assert.Equal(t, 1, build.Rank)
+	assert.Equal(t, "buy", buy.Option)
+	assert.InDelta(t, (-0.4+0.4+0.1)/1.4, buy.Score, 1e-12)
+	assert.Equal(t, []string{"finance", "sales"}, buy.Supporters)
+	assert.Equal(t, []string{"engineering"}, buy.Opponents)
+	assert.Equal(t, 2, buy.Rank)
+
+	// Options nobody prefers tie at 0
+	for _, option := range result.Options[2:] {
+		assert.Equal(t, 0.0, option.Score)
+		assert.Equal(t, 3, option.Rank)
+		assert.Empty(t, option.Supporters)
+	}
+}
+
+func TestAnalyze_RejectsInvalidStakeholders(t *testing.T) {
+	options := []string{"a"}
+	for name, c := range map[string]struct {
+		stakeholders []Stakeholder
+		options      []string
+	}{
+		"no stakeholders":  {nil, options},
+		"no options":       {[]Stakeholder{{Name: "s", Influence: 1, Interest: 1}}, nil},
+		"duplicate option": {[]Stakeholder{{Name: "s", Influence: 1, Interest: 1}}, []string{"a", "a"}},
+		"unnamed":          {[]Stakeholder{{Influence: 1, Interest: 1}}, options},
+		"duplicate":        {[]Stakeholder{{Name: "s", Influence: 1, Interest: 1}, {Name: "s", Influence: 1, Interest: 1}}, options},
+		"influence":        {[]Stakeholder{{Name: "s", Influence: 2, Interest: 1}}, options},
+		"NaN interest":     {[]Stakeholder{{Name: "s", Influence: 1, Interest: math.NaN()}}, options},
+		"unknown option":   {[]Stakeholder{{Name: "s", Influence: 1, Interest: 1, Preferences: []Preference{{Option: "b"}}}}, options},
+		"twice":            {[]Stakeholder{{Name: "s", Influence: 1, Interest: 1, Preferences: []Preference{{Option: "a"}, {Option: "a"}}}}, options},
+		"support":          {[]Stakeholder{{Name: "s", Influence: 1, Interest: 1, Preferences: []Preference{{Option: "a", Support: -2}}}}, options},
+		"no salience":      {[]Stakeholder{{Name: "s", Influence: 1}}, options},
+	} {
+		_, err := Analyze(c.stakeholders, c.options)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,56 @@
+// Package stats summarizes the samples the stochastic algorithms draw: the
+// mean and spread of a sample, and the quantiles and central intervals of a
+// sorted one.
+package stats
+
+import "math"
+
+// Moments describes the center and spread of a sample
+type Moments struct {
+	Mean float64
+	// StdDev is the sample standard deviation, 0 for a single value
+	StdDev float64
+	// StdError is the standard error of the mean
+	StdError float64
+}
+
+// Describe returns the moments of values, which must not be empty
+func Describe(values []float64) Moments {
+	n := float64(len(values))
+	m := Moments{Mean: Mean(values)}
+	if len(values) > 1 {
+		squares := 0.0
+		for _, x := range values {
+			squares += (x - m.Mean) * (x - m.Mean)
+		}
+		m.StdDev = math.Sqrt(squares / (n - 1))
+	}
+	m.StdError = m.StdDev / math.Sqrt(n)
+	return m
+}
+
+// Mean returns the mean of values, which must not be empty
+func Mean(values []float64) float64 {
+	sum := 0.0
+	for _, x := range values {
+		sum += x
+	}
+	return sum / float64(len(values))
+}
+
+// Quantile returns the q-th quantile of sorted, interpolating between
+// neighbouring values
+func Quantile(sorted []float64, q float64) float64 {
+	rank := q * float64(len(sorted)-1)
+	low := int(math.Floor(rank))
+	if low >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[low] + (rank-float64(low))*(sorted[low+1]-sorted[low])
+}
+
+// Interval returns the central interval of sorted that leaves out alpha of
+// it in each tail
+func Interval(sorted []float64, alpha float64) (low, high float64) {
+	return Quantile(sorted, alpha), Quantile(sorted, 1-alpha)
+}
@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	m := Describe([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	assert.Equal(t, 5.0, m.Mean)
+	assert.InDelta(t, math.Sqrt(32.0/7), m.StdDev, 1e-12)
+	assert.InDelta(t, m.StdDev/math.Sqrt(8), m.StdError, 1e-12)
+
+	assert.Equal(t, Moments{Mean: 3}, Describe([]float64{3}), "a single value has no spread")
+}
+
+func TestQuantileAndInterval(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	assert.Equal(t, 1.0, Quantile(sorted, 0))
+	assert.Equal(t, 3.0, Quantile(sorted, 0.5))
+	assert.Equal(t, 3.4, Quantile(sorted, 0.6))
+	assert.Equal(t, 5.0, Quantile(sorted, 1))
+	assert.Equal(t, 7.0, Quantile([]float64{7}, 0.3))
+
+	low, high := Interval(sorted, 0.25)
+	assert.Equal(t, 2.0, low)
+	assert.Equal(t, 4.0, high)
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Backup archives are gzipped tarballs holding manifest.json followed by one
+// sessions/<n>.json file per session
+const (
+	backupFormat       = "gothink-backup"
+	backupManifestName = "manifest.json"
+	backupSessionsDir  = "sessions"
+)
+
+// BackupVersion is the archive version written by WriteBackup. Restores
+// accept archives up to this version.
+const BackupVersion = 1
+
+// BackupManifest describes a backup archive
+type BackupManifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Backend   string    `json:"backend"`
+	Sessions  int       `json:"sessions"`
+}
+
+// backupSession is one session of a backup archive: its metadata and an
+// export of its records with their original IDs
+type backupSession struct {
+	Session *SessionData         `json:"session"`
+	Export  *types.SessionExport `json:"export"`
+}
+
+// RestoreResult describes the outcome of restoring a backup
+type RestoreResult struct {
+	Manifest *BackupManifest `json:"manifest"`
+	Restored []string        `json:"restored"`
+	// Skipped lists sessions left untouched because they already exist
+	Skipped []string `json:"skipped"`
+	Records int      `json:"records"`
+}
+
+// WriteBackup writes every session of a store to w as a backup archive. Each
+// session is read separately, so sessions written to during the backup are
+// captured as they were when reached.
+func WriteBackup(s Store, backend string, w io.Writer) (*BackupManifest, error) {
+	ids, err := s.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+
+	manifest := &BackupManifest{
+		Format:    backupFormat,
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC(),
+		Backend:   backend,
+	}
+
+	var entries [][]byte
+	for _, id := range ids {
+		session, err := s.GetSession(id)
+		if err != nil {
+			// The session was removed after it was listed
+			continue
+		}
+		export, err := s.ExportSession(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export session %s: %w", id, err)
+		}
+
+		data, err := json.Marshal(&backupSession{Session: session, Export: export})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode session %s: %w", id, err)
+		}
+		entries = append(entries, data)
+	}
+	manifest.Sessions = len(entries)
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+	if err := writeTarFile(tw, backupManifestName, data, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		name := path.Join(backupSessionsDir, fmt.Sprintf("%06d.json", i+1))
+		if err := writeTarFile(tw, name, entry, manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// ReadBackup restores the sessions of a backup archive into a store, keeping
+// their record IDs. Sessions that already exist are skipped, or cleared and
+// replaced when replace is set. Archived sessions are archived again once
+// their records are restored.
+func ReadBackup(s Store, r io.Reader, replace bool) (*RestoreResult, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != backupManifestName {
+		return nil, fmt.Errorf("invalid backup archive: %s must come first", backupManifestName)
+	}
+	var manifest BackupManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if manifest.Format != backupFormat {
+		return nil, fmt.Errorf("not a GoThink backup (format %q)", manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d (supported: up to %d)", manifest.Version, BackupVersion)
+	}
+
+	result := &RestoreResult{Manifest: &manifest, Restored: []string{}, Skipped: []string{}}
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid backup archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasPrefix(header.Name, backupSessionsDir+"/") {
+			continue
+		}
+
+		var entry backupSession
+		if err := json.NewDecoder(tr).Decode(&entry); err != nil {
+			return result, fmt.Errorf("invalid backup entry %s: %w", header.Name, err)
+		}
+		if entry.Session == nil || entry.Session.ID == "" || entry.Export == nil {
+			return result, fmt.Errorf("invalid backup entry %s: missing session", header.Name)
+		}
+
+		sessionID := entry.Session.ID
+		if _, err := s.GetSession(sessionID); err == nil {
+			if !replace {
+				result.Skipped = append(result.Skipped, sessionID)
+				continue
+			}
+			if err := s.ClearSession(sessionID); err != nil {
+				return result, fmt.Errorf("failed to replace session %s: %w", sessionID, err)
+			}
+		}
+
+		records, err := restoreRecords(s, sessionID, entry.Export)
+		result.Records += records
+		if err != nil {
+			return result, fmt.Errorf("failed to restore session %s: %w", sessionID, err)
+		}
+		if entry.Session.Archived {
+			if err := s.ArchiveSession(sessionID); err != nil {
+				return result, fmt.Errorf("failed to archive session %s: %w", sessionID, err)
+			}
+		}
+		result.Restored = append(result.Restored, sessionID)
+	}
+
+	return result, nil
+}
+
+// restoreRecords adds the records of an export to a session as they were
+// exported, IDs and timestamps included, and returns how many were added
+func restoreRecords(s Store, sessionID string, export *types.SessionExport) (int, error) {
+	export, err := MigrateExport(export)
+	if err != nil {
+		return 0, err
+	}
+	data, err := decodeExportData(export.Data)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.CreateSession(sessionID); err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	add := func(err error) error {
+		if err == nil {
+			restored++
+		}
+		return err
+	}
+
+	for _, thought := range data.Thoughts {
+		if err := add(s.AddThought(sessionID, thought)); err != nil {
+			return restored, err
+		}
+	}
+	for _, model := range data.MentalModels {
+		if err := add(s.AddMentalModel(sessionID, model)); err != nil {
+			return restored, err
+		}
+	}
+	for _, algorithm := range data.StochasticAlgorithms {
+		if err := add(s.AddStochasticAlgorithm(sessionID, algorithm)); err != nil {
+			return restored, err
+		}
+	}
+	for _, decision := range data.Decisions {
+		if err := add(s.AddDecision(sessionID, decision)); err != nil {
+			return restored, err
+		}
+	}
+	for _, visual := range data.VisualData {
+		if err := add(s.AddVisualData(sessionID, visual)); err != nil {
+			return restored, err
+		}
+	}
+	for _, scenario := range data.Scenarios {
+		if err := add(s.AddScenario(sessionID, scenario)); err != nil {
+			return restored, err
+		}
+	}
+	for _, critique := range data.Critiques {
+		if err := add(s.AddCritique(sessionID, critique)); err != nil {
+			return restored, err
+		}
+	}
+
+	return restored, nil
+}
+
+// writeTarFile adds a regular file to a tar archive
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:     name,
+		Mode:     0o600,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write backup entry %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write backup entry %s: %w", name, err)
+	}
+	return nil
+}
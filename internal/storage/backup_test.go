@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup_RoundTrip(t *testing.T) {
+	source := NewMemoryStore(config.DefaultConfig())
+	thought := &types.ThoughtData{Thought: "first", ThoughtNumber: 1, TotalThoughts: 1}
+	require.NoError(t, source.AddThought("s1", thought))
+	critique := &types.CritiqueData{TargetType: "thoughts", TargetIDs: []string{thought.ID}, Summary: "Thin"}
+	require.NoError(t, source.AddCritique("s1", critique))
+	require.NoError(t, source.AddDecision("s2", &types.DecisionData{DecisionStatement: "Ship it"}))
+	require.NoError(t, source.ArchiveSession("s2"))
+
+	var archive bytes.Buffer
+	manifest, err := WriteBackup(source, "memory", &archive)
+	require.NoError(t, err)
+	assert.Equal(t, 2, manifest.Sessions)
+	assert.Equal(t, BackupVersion, manifest.Version)
+
+	target := NewMemoryStore(config.DefaultConfig())
+	result, err := ReadBackup(target, bytes.NewReader(archive.Bytes()), false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s2"}, result.Restored)
+	assert.Equal(t, 3, result.Records)
+
+	// Records keep their IDs, so references between them still resolve
+	thoughts, err := target.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, thought.ID, thoughts[0].ID)
+	critiques, err := target.GetCritiques("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, critiques, 1)
+	assert.Equal(t, []string{thought.ID}, critiques[0].TargetIDs)
+
+	session, err := target.GetSession("s2")
+	require.NoError(t, err)
+	assert.True(t, session.Archived)
+
+	// Existing sessions are skipped unless replaced
+	result, err = ReadBackup(target, bytes.NewReader(archive.Bytes()), false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Restored)
+	assert.Equal(t, []string{"s1", "s2"}, result.Skipped)
+
+	result, err = ReadBackup(target, bytes.NewReader(archive.Bytes()), true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s2"}, result.Restored)
+	thoughts, err = target.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
+
+func TestReadBackup_RejectsOtherArchives(t *testing.T) {
+	_, err := ReadBackup(NewMemoryStore(config.DefaultConfig()), bytes.NewReader([]byte("not gzip")), false)
+	assert.Error(t, err)
+}
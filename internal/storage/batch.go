@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Batch holds records of any type to be added to a session in one call
+type Batch struct {
+	Thoughts             []*types.ThoughtData             `json:"thoughts,omitempty"`
+	MentalModels         []*types.MentalModelData         `json:"mental_models,omitempty"`
+	StochasticAlgorithms []*types.StochasticAlgorithmData `json:"stochastic_algorithms,omitempty"`
+	Decisions            []*types.DecisionData            `json:"decisions,omitempty"`
+	VisualData           []*types.VisualData              `json:"visual_data,omitempty"`
+	Critiques            []*types.CritiqueData            `json:"critiques,omitempty"`
+	Scenarios            []*types.ScenarioData            `json:"scenarios,omitempty"`
+}
+
+// Len returns the number of records in the batch
+func (b *Batch) Len() int {
+	return len(b.Thoughts) + len(b.MentalModels) + len(b.StochasticAlgorithms) +
+		len(b.Decisions) + len(b.VisualData) + len(b.Critiques) + len(b.Scenarios)
+}
+
+// BatchResult describes the records added by AddBatch
+type BatchResult struct {
+	SessionID string `json:"session_id"`
+	// Added counts the records added per store
+	Added map[string]int `json:"added"`
+	// IDs lists the IDs of the added records per store, in batch order
+	IDs map[string][]string `json:"ids"`
+}
+
+// addBatch adds the records of a batch to a session through the store's own
+// Add methods, so wrappers journal, publish and scope each record as usual.
+// The batch is checked against the thought limit and for repeated IDs before
+// anything is written; a record rejected by the store stops the batch, and
+// the result reports the records added before it. Critiques are added last
+// so they may reference other records of the batch by ID.
+func addBatch(s Store, sessionID string, batch *Batch) (*BatchResult, error) {
+	if err := checkBatchIDs(batch); err != nil {
+		return nil, err
+	}
+
+	if len(batch.Thoughts) > 0 {
+		stats, err := s.GetSessionStats(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch.Thoughts) > stats.RemainingThoughts {
+			return nil, errorf(ErrThoughtLimit, "batch of %d thoughts exceeds the %d remaining for session %s",
+				len(batch.Thoughts), stats.RemainingThoughts, sessionID)
+		}
+	}
+
+	result := &BatchResult{
+		SessionID: sessionID,
+		Added:     make(map[string]int),
+		IDs:       make(map[string][]string),
+	}
+	added := func(kind, id string) {
+		result.Added[kind]++
+		result.IDs[kind] = append(result.IDs[kind], id)
+	}
+
+	for _, thought := range batch.Thoughts {
+		if err := s.AddThought(sessionID, thought); err != nil {
+			return result, fmt.Errorf("failed to add thought: %w", err)
+		}
+		added(KindThoughts, thought.ID)
+	}
+	for _, model := range batch.MentalModels {
+		if err := s.AddMentalModel(sessionID, model); err != nil {
+			return result, fmt.Errorf("failed to add mental model: %w", err)
+		}
+		added(KindMentalModels, model.ID)
+	}
+	for _, algorithm := range batch.StochasticAlgorithms {
+		if err := s.AddStochasticAlgorithm(sessionID, algorithm); err != nil {
+			return result, fmt.Errorf("failed to add stochastic algorithm: %w", err)
+		}
+		added(KindStochasticAlgorithms, algorithm.ID)
+	}
+	for _, decision := range batch.Decisions {
+		if err := s.AddDecision(sessionID, decision); err != nil {
+			return result, fmt.Errorf("failed to add decision: %w", err)
+		}
+		added(KindDecisions, decision.ID)
+	}
+	for _, visual := range batch.VisualData {
+		if err := s.AddVisualData(sessionID, visual); err != nil {
+			return result, fmt.Errorf("failed to add visual data: %w", err)
+		}
+		added(KindVisualData, visual.ID)
+	}
+	for _, scenario := range batch.Scenarios {
+		if err := s.AddScenario(sessionID, scenario); err != nil {
+			return result, fmt.Errorf("failed to add scenario analysis: %w", err)
+		}
+		added(KindScenarios, scenario.ID)
+	}
+	for _, critique := range batch.Critiques {
+		if err := s.AddCritique(sessionID, critique); err != nil {
+			return result, fmt.Errorf("failed to add critique: %w", err)
+		}
+		added(KindCritiques, critique.ID)
+	}
+
+	return result, nil
+}
+
+// checkBatchIDs rejects a batch holding empty records or giving two records
+// the same ID
+func checkBatchIDs(batch *Batch) error {
+	seen := make(map[string]bool)
+	for _, err := range []error{
+		checkIDs(seen, KindThoughts, batch.Thoughts, thoughtIdentity),
+		checkIDs(seen, KindMentalModels, batch.MentalModels, mentalModelIdentity),
+		checkIDs(seen, KindStochasticAlgorithms, batch.StochasticAlgorithms, algorithmIdentity),
+		checkIDs(seen, KindDecisions, batch.Decisions, decisionIdentity),
+		checkIDs(seen, KindVisualData, batch.VisualData, visualIdentity),
+		checkIDs(seen, KindCritiques, batch.Critiques, critiqueIdentity),
+		checkIDs(seen, KindScenarios, batch.Scenarios, scenarioIdentity),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIDs marks the IDs of records as seen, rejecting repeats
+func checkIDs[T any](seen map[string]bool, kind string, records []*T, identity identityFunc[T]) error {
+	for _, record := range records {
+		if record == nil {
+			return errorf(ErrInvalidArgument, "batch contains an empty %s record", kind)
+		}
+		id, _, _ := identity(record)
+		if *id == "" {
+			continue
+		}
+		if seen[*id] {
+			return errorf(ErrInvalidArgument, "batch repeats %s ID %s", kind, *id)
+		}
+		seen[*id] = true
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddBatch_AddsAllRecords(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+
+	batch := &Batch{
+		Thoughts: []*types.ThoughtData{
+			{ID: "t1", Thought: "first", ThoughtNumber: 1, TotalThoughts: 2},
+			{Thought: "second", ThoughtNumber: 2, TotalThoughts: 2},
+		},
+		Decisions: []*types.DecisionData{{DecisionStatement: "Pick one"}},
+		Critiques: []*types.CritiqueData{{TargetType: "thoughts", TargetIDs: []string{"t1"}, Summary: "Thin"}},
+	}
+
+	result, err := store.AddBatch("s1", batch)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{KindThoughts: 2, KindDecisions: 1, KindCritiques: 1}, result.Added)
+	require.Len(t, result.IDs[KindThoughts], 2)
+	assert.Equal(t, "t1", result.IDs[KindThoughts][0])
+	assert.Equal(t, batch.Thoughts[1].ID, result.IDs[KindThoughts][1])
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 2)
+	critiques, err := store.GetCritiques("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, critiques, 1)
+	assert.Equal(t, []string{"t1"}, critiques[0].TargetIDs)
+}
+
+func TestAddBatch_ChecksBeforeWriting(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxThoughtsPerSession = 1
+	store := NewMemoryStore(cfg)
+
+	_, err := store.AddBatch("s1", &Batch{Thoughts: []*types.ThoughtData{{Thought: "a"}, {Thought: "b"}}})
+	assert.ErrorContains(t, err, "exceeds the 1 remaining")
+
+	_, err = store.AddBatch("s1", &Batch{
+		Decisions: []*types.DecisionData{{ID: "same", DecisionStatement: "a"}},
+		Critiques: []*types.CritiqueData{{ID: "same", Summary: "b"}},
+	})
+	assert.ErrorContains(t, err, "batch repeats critiques ID same")
+
+	_, err = store.AddBatch("s1", &Batch{Decisions: []*types.DecisionData{nil}})
+	assert.ErrorContains(t, err, "empty decisions record")
+
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+}
+
+func TestAddBatch_ReportsRecordsAddedBeforeFailure(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{ID: "taken", DecisionStatement: "earlier"}))
+
+	result, err := store.AddBatch("s1", &Batch{
+		Thoughts:  []*types.ThoughtData{{Thought: "first"}},
+		Decisions: []*types.DecisionData{{ID: "taken", DecisionStatement: "again"}},
+	})
+	assert.ErrorIs(t, err, ErrDuplicateID)
+	require.NotNil(t, result)
+	assert.Equal(t, map[string]int{KindThoughts: 1}, result.Added)
+}
+
+func TestAddBatch_JournalsEachRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gothink.journal")
+
+	store, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	_, err = ForTenant(store, "acme").AddBatch("s1", &Batch{
+		Thoughts:  []*types.ThoughtData{{Thought: "first"}},
+		Decisions: []*types.DecisionData{{DecisionStatement: "Pick one"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	replayed, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	defer replayed.Close()
+
+	tenant := ForTenant(replayed, "acme")
+	thoughts, err := tenant.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+	decisions, err := tenant.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 1)
+}
@@ -0,0 +1,279 @@
+// Package bolt provides an embedded key-value storage backend built on bbolt.
+//
+// Importing the package registers the "bolt" backend with the storage
+// package. It needs neither CGO nor SQL. Each session gets its own bucket in
+// which records are keyed by kind and insertion sequence, so listing a
+// session's thoughts is a single prefix scan.
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// sessionsBucket holds session metadata keyed by session ID
+	sessionsBucket = []byte("sessions")
+	// recordsBucket holds one nested bucket of records per session
+	recordsBucket = []byte("records")
+	// idsBucket maps <kind>/<record id> to the owning session, so record IDs
+	// are unique across sessions
+	idsBucket = []byte("ids")
+)
+
+// Key layout inside a session bucket:
+//
+//	r/<kind>/<seq>       record data, seq is a big-endian uint64 so keys sort by insertion
+//	i/<kind>/<record id> the sequence key of the record, used to replace records in place
+const (
+	recordPrefix = "r/"
+	indexPrefix  = "i/"
+)
+
+func init() {
+	storage.Register("bolt", func(cfg *config.Config) (storage.Store, error) {
+		backend, err := Open(DatabasePath(cfg), !cfg.EnablePersistence)
+		if err != nil {
+			return nil, err
+		}
+		return storage.NewRecordStore(cfg, backend), nil
+	})
+}
+
+// Backend stores records and sessions in a bbolt database
+type Backend struct {
+	db        *bolt.DB
+	temporary bool
+}
+
+// DatabasePath returns the database location for the configuration. Without
+// persistence a temporary file is used and removed on Close.
+func DatabasePath(cfg *config.Config) string {
+	if !cfg.EnablePersistence || cfg.PersistencePath == "" {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("gothink-%d.bolt", time.Now().UnixNano()))
+	}
+
+	if filepath.Ext(cfg.PersistencePath) == "" {
+		return filepath.Join(cfg.PersistencePath, "gothink.bolt")
+	}
+
+	return cfg.PersistencePath
+}
+
+// Open opens (and if necessary creates) the database at path. Temporary
+// databases are deleted when closed.
+func Open(path string, temporary bool) (*Backend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{sessionsBucket, recordsBucket, idsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize buckets: %w", err)
+	}
+
+	return &Backend{db: db, temporary: temporary}, nil
+}
+
+// InsertRecord adds a new record, returning storage.ErrDuplicateID if a record
+// of the same kind already has the ID in any session
+func (b *Backend) InsertRecord(kind, sessionID, id string, data []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return insertRecord(tx, kind, sessionID, id, data)
+	})
+}
+
+// insertRecord writes a new record unless its ID is already in use
+func insertRecord(tx *bolt.Tx, kind, sessionID, id string, data []byte) error {
+	if tx.Bucket(idsBucket).Get([]byte(kind+"/"+id)) != nil {
+		return storage.ErrDuplicateID
+	}
+	if bucket := tx.Bucket(recordsBucket).Bucket([]byte(sessionID)); bucket != nil && bucket.Get([]byte(indexPrefix+kind+"/"+id)) != nil {
+		return storage.ErrDuplicateID
+	}
+	return putRecord(tx, kind, sessionID, id, data)
+}
+
+// PutRecord inserts or replaces a record
+func (b *Backend) PutRecord(kind, sessionID, id string, data []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return putRecord(tx, kind, sessionID, id, data)
+	})
+}
+
+// putRecord writes a record into its session bucket and indexes its ID
+func putRecord(tx *bolt.Tx, kind, sessionID, id string, data []byte) error {
+	bucket, err := tx.Bucket(recordsBucket).CreateBucketIfNotExists([]byte(sessionID))
+	if err != nil {
+		return err
+	}
+
+	indexKey := []byte(indexPrefix + kind + "/" + id)
+	recordKey := bucket.Get(indexKey)
+	if recordKey == nil {
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		recordKey = make([]byte, 0, len(recordPrefix)+len(kind)+9)
+		recordKey = append(recordKey, recordPrefix+kind+"/"...)
+		recordKey = binary.BigEndian.AppendUint64(recordKey, seq)
+
+		if err := bucket.Put(indexKey, recordKey); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Bucket(idsBucket).Put([]byte(kind+"/"+id), []byte(sessionID)); err != nil {
+		return err
+	}
+	return bucket.Put(recordKey, data)
+}
+
+// ListRecords returns all records of a kind for a session in insertion order
+func (b *Backend) ListRecords(kind, sessionID string) ([][]byte, error) {
+	var records [][]byte
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(recordsBucket).Bucket([]byte(sessionID))
+		if bucket == nil {
+			return nil
+		}
+
+		prefix := []byte(recordPrefix + kind + "/")
+		cursor := bucket.Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			// Values are only valid for the life of the transaction
+			records = append(records, append([]byte(nil), value...))
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// PutSession inserts or replaces session metadata
+func (b *Backend) PutSession(sessionID string, data []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(sessionID), data)
+	})
+}
+
+// GetSession returns session metadata or storage.ErrNotFound
+func (b *Backend) GetSession(sessionID string) ([]byte, error) {
+	var data []byte
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(sessionsBucket).Get([]byte(sessionID))
+		if value == nil {
+			return storage.ErrNotFound
+		}
+		data = append([]byte(nil), value...)
+		return nil
+	})
+
+	return data, err
+}
+
+// ListSessions returns the IDs of all sessions
+func (b *Backend) ListSessions() ([]string, error) {
+	var ids []string
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(key, _ []byte) error {
+			ids = append(ids, string(key))
+			return nil
+		})
+	})
+
+	return ids, err
+}
+
+// DeleteSession removes session metadata, the session's record bucket and its
+// record IDs in one transaction
+func (b *Backend) DeleteSession(sessionID string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(recordsBucket).Bucket([]byte(sessionID)); bucket != nil {
+			ids := tx.Bucket(idsBucket)
+			prefix := []byte(indexPrefix)
+			cursor := bucket.Cursor()
+			for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+				if err := ids.Delete(key[len(prefix):]); err != nil {
+					return err
+				}
+			}
+		}
+
+		err := tx.Bucket(recordsBucket).DeleteBucket([]byte(sessionID))
+		if err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		return tx.Bucket(sessionsBucket).Delete([]byte(sessionID))
+	})
+}
+
+// ApplyWrites applies writes in order in one transaction
+func (b *Backend) ApplyWrites(writes []storage.RecordWrite) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, write := range writes {
+			var err error
+			switch write.Type {
+			case storage.WriteInsertRecord:
+				err = insertRecord(tx, write.Kind, write.SessionID, write.ID, write.Data)
+			case storage.WritePutRecord:
+				err = putRecord(tx, write.Kind, write.SessionID, write.ID, write.Data)
+			case storage.WritePutSession:
+				err = tx.Bucket(sessionsBucket).Put([]byte(write.SessionID), write.Data)
+			default:
+				err = fmt.Errorf("unknown write type %q", write.Type)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Ping checks that the database is open
+func (b *Backend) Ping(ctx context.Context) error {
+	return b.db.View(func(*bolt.Tx) error { return nil })
+}
+
+// Close closes the database, removing it if it was temporary
+func (b *Backend) Close() error {
+	path := b.db.Path()
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+
+	if b.temporary {
+		return os.Remove(path)
+	}
+
+	return nil
+}
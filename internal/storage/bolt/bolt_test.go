@@ -0,0 +1,170 @@
+package bolt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionsSurviveRestart(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.StorageBackend = "bolt"
+	cfg.EnablePersistence = true
+	cfg.PersistencePath = filepath.Join(t.TempDir(), "data")
+
+	store, err := storage.New(cfg)
+	require.NoError(t, err)
+
+	for i, text := range []string{"first", "second", "third"} {
+		require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: text, ThoughtNumber: i + 1, TotalThoughts: 3}))
+	}
+	require.NoError(t, store.AddMentalModel("s1", &types.MentalModelData{ModelName: "first_principles"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "other session"}))
+	require.NoError(t, store.Close())
+
+	reopened, err := storage.New(cfg)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	thoughts, err := reopened.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 3)
+	for i, text := range []string{"first", "second", "third"} {
+		assert.Equal(t, text, thoughts[i].Thought)
+	}
+
+	models, err := reopened.GetMentalModels("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, models, 1)
+
+	session, err := reopened.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, session.ThoughtCount)
+}
+
+func TestPutRecordReplacesInPlace(t *testing.T) {
+	backend, err := Open(filepath.Join(t.TempDir(), "test.bolt"), false)
+	require.NoError(t, err)
+	defer backend.Close()
+
+	require.NoError(t, backend.PutRecord(storage.KindThoughts, "s1", "a", []byte(`"a1"`)))
+	require.NoError(t, backend.PutRecord(storage.KindThoughts, "s1", "b", []byte(`"b1"`)))
+	require.NoError(t, backend.PutRecord(storage.KindThoughts, "s1", "a", []byte(`"a2"`)))
+
+	records, err := backend.ListRecords(storage.KindThoughts, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`"a2"`), []byte(`"b1"`)}, records)
+}
+
+func TestTemporaryDatabaseRemovedOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "temp.bolt")
+	backend, err := Open(path, true)
+	require.NoError(t, err)
+	require.NoError(t, backend.Close())
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestClearSession(t *testing.T) {
+	backend, err := Open(filepath.Join(t.TempDir(), "test.bolt"), false)
+	require.NoError(t, err)
+	store := storage.NewRecordStore(config.DefaultConfig(), backend)
+	defer store.Close()
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "gone"}))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "gone"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "kept"}))
+
+	require.NoError(t, store.ClearSession("s1"))
+	assert.Error(t, store.ClearSession("s1"))
+
+	_, err = store.GetSession("s1")
+	assert.Error(t, err)
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+
+	thoughts, err = store.GetThoughts("s2", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
+
+func TestInsertRecordRejectsDuplicateIDs(t *testing.T) {
+	backend, err := Open(filepath.Join(t.TempDir(), "test.bolt"), false)
+	require.NoError(t, err)
+	defer backend.Close()
+
+	require.NoError(t, backend.InsertRecord(storage.KindThoughts, "s1", "a", []byte(`"a1"`)))
+	assert.ErrorIs(t, backend.InsertRecord(storage.KindThoughts, "s1", "a", []byte(`"a2"`)), storage.ErrDuplicateID)
+	assert.ErrorIs(t, backend.InsertRecord(storage.KindThoughts, "s2", "a", []byte(`"a3"`)), storage.ErrDuplicateID)
+	require.NoError(t, backend.InsertRecord(storage.KindDecisions, "s1", "a", []byte(`"d1"`)))
+
+	// Deleting the session releases its IDs
+	require.NoError(t, backend.DeleteSession("s1"))
+	require.NoError(t, backend.InsertRecord(storage.KindThoughts, "s2", "a", []byte(`"a4"`)))
+}
+
+func TestTransactionCommitsOrRollsBack(t *testing.T) {
+	backend, err := Open(filepath.Join(t.TempDir(), "test.bolt"), false)
+	require.NoError(t, err)
+	store := storage.NewRecordStore(config.DefaultConfig(), backend)
+	defer store.Close()
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "first"}))
+	require.NoError(t, store.AddDecision("s2", &types.DecisionData{ID: "taken", DecisionStatement: "elsewhere"}))
+
+	stage := func(clash bool) func(tx *storage.Tx) error {
+		return func(tx *storage.Tx) error {
+			tx.UpdateThought("t1", func(thought *types.ThoughtData) error {
+				thought.Thought = "revised"
+				return nil
+			})
+			tx.AddDecision(&types.DecisionData{DecisionStatement: "Pick a database"})
+			tx.AddVisualData(&types.VisualData{DiagramType: "decisionTree"})
+			if clash {
+				tx.AddDecision(&types.DecisionData{ID: "taken", DecisionStatement: "clash"})
+			}
+			return nil
+		}
+	}
+
+	// The clash is only found by the backend, after every write was staged
+	assert.ErrorIs(t, store.Transaction("s1", stage(true)), storage.ErrDuplicateID)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "first", thoughts[0].Thought)
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, session.TotalOperations)
+
+	require.NoError(t, store.Transaction("s1", stage(false)))
+
+	thoughts, err = store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "revised", thoughts[0].Thought)
+	decisions, err = store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 1)
+	visuals, err := store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, visuals, 1)
+	session, err = store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, session.TotalOperations)
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Export payload encodings
+const (
+	EncodingIdentity   = "identity"
+	EncodingGzipBase64 = "gzip+base64"
+)
+
+// Export chunk sizes in bytes of the encoded payload
+const (
+	DefaultChunkSize = 256 << 10
+	MaxChunkSize     = 1 << 20
+)
+
+// CompressExport gzips a rendered export and encodes it as base64 text
+func CompressExport(content []byte) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return "", fmt.Errorf("failed to compress export: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress export: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecompressExport reverses CompressExport
+func DecompressExport(encoded string) ([]byte, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed export: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed export: %w", err)
+	}
+	defer zr.Close()
+
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed export: %w", err)
+	}
+
+	return content, nil
+}
+
+// ExportChunk is one piece of an export read through an ExportChunker
+type ExportChunk struct {
+	SessionID string `json:"session_id"`
+	Format    string `json:"format"`
+	Encoding  string `json:"encoding"`
+	Data      string `json:"data"`
+	// Offset is the position of Data within the complete payload
+	Offset    int `json:"offset"`
+	TotalSize int `json:"total_size"`
+	// SHA256 is the checksum of the complete payload, for verification after reassembly
+	SHA256     string `json:"sha256"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Done       bool   `json:"done"`
+}
+
+// pendingExport is a rendered export being read in chunks
+type pendingExport struct {
+	tenantID  string
+	sessionID string
+	format    string
+	encoding  string
+	payload   string
+	checksum  string
+	expires   time.Time
+}
+
+// ExportChunker holds rendered exports so they can be read in chunks. Each
+// export is rendered once, so every chunk comes from the same snapshot of the
+// session even if it changes between reads. Exports are dropped once fully
+// read or after ttl without a read. Each export belongs to the tenant that
+// began it and cannot be read by any other.
+type ExportChunker struct {
+	ttl     time.Duration
+	now     func() time.Time
+	exports map[string]*pendingExport
+	mu      sync.Mutex
+}
+
+// NewExportChunker creates a chunker that keeps unread exports for ttl
+func NewExportChunker(ttl time.Duration) *ExportChunker {
+	return &ExportChunker{
+		ttl:     ttl,
+		now:     time.Now,
+		exports: make(map[string]*pendingExport),
+	}
+}
+
+// Begin registers a rendered export and returns the cursor of its first chunk
+func (c *ExportChunker) Begin(tenantID, sessionID, format, encoding, payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for id, pending := range c.exports {
+		if now.After(pending.expires) {
+			delete(c.exports, id)
+		}
+	}
+
+	id := generateID()
+	c.exports[id] = &pendingExport{
+		tenantID:  tenantID,
+		sessionID: sessionID,
+		format:    format,
+		encoding:  encoding,
+		payload:   payload,
+		checksum:  hex.EncodeToString(sum[:]),
+		expires:   now.Add(c.ttl),
+	}
+
+	return encodeCursor(id, 0)
+}
+
+// Next returns up to size bytes of the tenant's export at cursor
+func (c *ExportChunker) Next(tenantID, cursor string, size int) (*ExportChunk, error) {
+	id, offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if size > MaxChunkSize {
+		size = MaxChunkSize
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, exists := c.exports[id]
+	if exists && pending.tenantID != tenantID {
+		exists = false
+	} else if exists && c.now().After(pending.expires) {
+		delete(c.exports, id)
+		exists = false
+	}
+	if !exists {
+		return nil, errorf(ErrInvalidArgument, "export cursor expired or unknown; start a new export")
+	}
+	if offset > len(pending.payload) {
+		return nil, errorf(ErrInvalidArgument, "export cursor offset %d is beyond the end of the export", offset)
+	}
+
+	end := offset + size
+	if end >= len(pending.payload) {
+		end = len(pending.payload)
+	} else {
+		// Keep multi-byte characters whole so each chunk is valid text
+		for end > offset && !utf8.RuneStart(pending.payload[end]) {
+			end--
+		}
+		if end == offset {
+			return nil, errorf(ErrInvalidArgument, "chunk size %d is too small", size)
+		}
+	}
+
+	chunk := &ExportChunk{
+		SessionID: pending.sessionID,
+		Format:    pending.format,
+		Encoding:  pending.encoding,
+		Data:      pending.payload[offset:end],
+		Offset:    offset,
+		TotalSize: len(pending.payload),
+		SHA256:    pending.checksum,
+		Done:      end == len(pending.payload),
+	}
+
+	if chunk.Done {
+		delete(c.exports, id)
+	} else {
+		chunk.NextCursor = encodeCursor(id, end)
+		pending.expires = c.now().Add(c.ttl)
+	}
+
+	return chunk, nil
+}
+
+func encodeCursor(id string, offset int) string {
+	return id + ":" + strconv.Itoa(offset)
+}
+
+func decodeCursor(cursor string) (string, int, error) {
+	id, rawOffset, found := strings.Cut(cursor, ":")
+	offset, err := strconv.Atoi(rawOffset)
+	if !found || id == "" || err != nil || offset < 0 {
+		return "", 0, errorf(ErrInvalidArgument, "invalid export cursor %q", cursor)
+	}
+	return id, offset, nil
+}
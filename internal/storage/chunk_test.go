@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressExport_RoundTrip(t *testing.T) {
+	content := []byte(strings.Repeat(`{"thought":"repetitive"}`, 100))
+
+	encoded, err := CompressExport(content)
+	require.NoError(t, err)
+	assert.Less(t, len(encoded), len(content))
+
+	decoded, err := DecompressExport(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, content, decoded)
+}
+
+func TestExportChunker_ReassemblesWholeCharacters(t *testing.T) {
+	chunker := NewExportChunker(time.Minute)
+	payload := strings.Repeat("héllo wörld ", 20)
+
+	cursor := chunker.Begin("", "s1", FormatMarkdown, EncodingIdentity, payload)
+	var rebuilt strings.Builder
+	for chunks := 0; ; chunks++ {
+		require.Less(t, chunks, len(payload), "chunking did not terminate")
+
+		chunk, err := chunker.Next("", cursor, 7)
+		require.NoError(t, err)
+		assert.Equal(t, rebuilt.Len(), chunk.Offset)
+		assert.True(t, len(chunk.Data) <= 7)
+		rebuilt.WriteString(chunk.Data)
+
+		if chunk.Done {
+			assert.Empty(t, chunk.NextCursor)
+			break
+		}
+		cursor = chunk.NextCursor
+	}
+	assert.Equal(t, payload, rebuilt.String())
+
+	// A finished export is released
+	_, err := chunker.Next("", cursor, 7)
+	assert.Error(t, err)
+}
+
+func TestExportChunker_ExpiresUnreadExports(t *testing.T) {
+	chunker := NewExportChunker(time.Minute)
+	now := time.Now()
+	chunker.now = func() time.Time { return now }
+
+	cursor := chunker.Begin("", "s1", FormatJSON, EncodingIdentity, "{}")
+	now = now.Add(2 * time.Minute)
+
+	_, err := chunker.Next("", cursor, 0)
+	assert.Error(t, err)
+
+	_, err = chunker.Next("", "not-a-cursor", 0)
+	assert.Error(t, err)
+}
+
+func TestExportChunker_BindsExportsToTenant(t *testing.T) {
+	chunker := NewExportChunker(time.Minute)
+	cursor := chunker.Begin("acme", "s1", FormatJSON, EncodingIdentity, "{}")
+
+	_, err := chunker.Next("globex", cursor, 0)
+	assert.Error(t, err)
+
+	chunk, err := chunker.Next("acme", cursor, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", chunk.Data)
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors identifying why the store refused an operation. Errors returned by
+// stores wrap one of these where it applies, so callers can tell the cases
+// apart with errors.Is; their messages still name the session or record.
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrRecordNotFound     = errors.New("record not found")
+	ErrSessionExists      = errors.New("session already exists")
+	ErrSessionArchived    = errors.New("session is archived")
+	ErrSessionNotArchived = errors.New("session is not archived")
+	ErrThoughtLimit       = errors.New("thought limit reached")
+	ErrQuotaExceeded      = errors.New("session quota exceeded")
+	ErrInvalidArgument    = errors.New("invalid argument")
+)
+
+// kindError carries a descriptive message while matching the sentinel error
+// it wraps
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (e *kindError) Error() string { return e.message }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// errorf formats an error message that wraps kind without repeating its text
+func errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,413 @@
+package storage
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// DefaultEventBuffer is the number of events a subscription holds before
+// further events are dropped for it
+const DefaultEventBuffer = 256
+
+// Event describes a write to the store. Type is one of the Op constants;
+// Record holds the record as stored after an add or update.
+type Event struct {
+	Seq       uint64          `json:"seq"`
+	Type      string          `json:"type"`
+	SessionID string          `json:"session_id"`
+	RecordID  string          `json:"record_id,omitempty"`
+	Record    json.RawMessage `json:"record,omitempty"`
+	Time      time.Time       `json:"time"`
+}
+
+// EventBus delivers store events to subscribers. Publishing never blocks: a
+// subscriber that falls behind by more than its buffer misses events, which
+// are counted by Subscription.Dropped.
+type EventBus struct {
+	seq         atomic.Uint64
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	mu          sync.RWMutex
+}
+
+// NewEventBus creates an event bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events of one tenant, optionally narrowed to a
+// single session, or of every namespace. Session IDs in its events are those
+// the tenant uses, or the stored IDs for a subscription to every namespace.
+type Subscription struct {
+	bus       *EventBus
+	events    chan Event
+	tenant    *TenantStore
+	sessionID string
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+}
+
+// Subscribe registers a subscription to the events of tenantID, or of the
+// shared namespace when it is empty. A non-empty sessionID limits it to that
+// session. buffer <= 0 selects DefaultEventBuffer. Subscribing to a closed
+// bus returns a subscription whose channel is already closed.
+func (b *EventBus) Subscribe(tenantID, sessionID string, buffer int) *Subscription {
+	return b.subscribe(ForTenant(nil, tenantID), sessionID, buffer)
+}
+
+// SubscribeAll registers a subscription to the events of every namespace,
+// with session IDs as stored
+func (b *EventBus) SubscribeAll(buffer int) *Subscription {
+	return b.subscribe(nil, "", buffer)
+}
+
+// subscribe registers a subscription filtered by tenant, or unfiltered when
+// tenant is nil
+func (b *EventBus) subscribe(tenant *TenantStore, sessionID string, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+
+	sub := &Subscription{
+		bus:       b,
+		events:    make(chan Event, buffer),
+		tenant:    tenant,
+		sessionID: sessionID,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(sub.events)
+		return sub
+	}
+	b.subscribers[sub] = struct{}{}
+
+	return sub
+}
+
+// Publish assigns the event its sequence number and delivers it to every
+// matching subscription
+func (b *EventBus) Publish(event Event) {
+	event.Seq = b.seq.Add(1)
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		sub.deliver(event)
+	}
+}
+
+// hasSubscribers reports whether any subscription would receive an event,
+// so writers can skip encoding records nobody reads
+func (b *EventBus) hasSubscribers() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers) > 0
+}
+
+// Close ends every subscription and rejects new ones
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subscribers {
+		close(sub.events)
+	}
+	b.subscribers = nil
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscription or the bus is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events missed because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.bus.mu.Lock()
+		defer s.bus.mu.Unlock()
+
+		if _, exists := s.bus.subscribers[s]; exists {
+			delete(s.bus.subscribers, s)
+			close(s.events)
+		}
+	})
+}
+
+// deliver queues the event if it belongs to the subscription, dropping it if
+// the buffer is full. Called with the bus read lock held.
+func (s *Subscription) deliver(event Event) {
+	if s.tenant != nil {
+		sessionID, ok := s.tenant.unscope(event.SessionID)
+		if !ok || (s.sessionID != "" && sessionID != s.sessionID) {
+			return
+		}
+		if sessionID != event.SessionID {
+			event.SessionID = sessionID
+			event.Record = withSessionID(event.Record, sessionID)
+		}
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// withSessionID rewrites the session_id field of an encoded record
+func withSessionID(record json.RawMessage, sessionID string) json.RawMessage {
+	if record == nil {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return record
+	}
+	if _, exists := fields["session_id"]; !exists {
+		return record
+	}
+	fields["session_id"], _ = json.Marshal(sessionID)
+
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return record
+	}
+	return rewritten
+}
+
+// EventStore publishes an event to its bus for every successful write to the
+// wrapped store
+type EventStore struct {
+	Store
+	bus *EventBus
+}
+
+// NewEventStore wraps store so that its writes are published to bus
+func NewEventStore(store Store, bus *EventBus) *EventStore {
+	return &EventStore{Store: store, bus: bus}
+}
+
+// Events returns the bus the store publishes to
+func (s *EventStore) Events() *EventBus {
+	return s.bus
+}
+
+// publish sends an event for a completed write, encoding record only when
+// someone is listening
+func (s *EventStore) publish(op, sessionID, recordID string, record interface{}) {
+	event := Event{Type: op, SessionID: sessionID, RecordID: recordID}
+	if record != nil && s.bus.hasSubscribers() {
+		if data, err := json.Marshal(record); err == nil {
+			event.Record = data
+		}
+	}
+	s.bus.Publish(event)
+}
+
+// publishUpdate wraps update so the updated record is captured for the event
+// published once the wrapped store commits it
+func publishUpdate[T any](s *EventStore, op, sessionID, id string, update func(*T) error, apply func(func(*T) error) error) error {
+	var updated T
+	err := apply(func(record *T) error {
+		if err := update(record); err != nil {
+			return err
+		}
+		updated = *record
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(op, sessionID, id, &updated)
+	return nil
+}
+
+// AddThought adds a thought and publishes it
+func (s *EventStore) AddThought(sessionID string, thought *types.ThoughtData) error {
+	if err := s.Store.AddThought(sessionID, thought); err != nil {
+		return err
+	}
+	s.publish(OpAddThought, sessionID, thought.ID, thought)
+	return nil
+}
+
+// AddMentalModel adds a mental model application and publishes it
+func (s *EventStore) AddMentalModel(sessionID string, model *types.MentalModelData) error {
+	if err := s.Store.AddMentalModel(sessionID, model); err != nil {
+		return err
+	}
+	s.publish(OpAddMentalModel, sessionID, model.ID, model)
+	return nil
+}
+
+// AddStochasticAlgorithm adds a stochastic algorithm result and publishes it
+func (s *EventStore) AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error {
+	if err := s.Store.AddStochasticAlgorithm(sessionID, algorithm); err != nil {
+		return err
+	}
+	s.publish(OpAddStochasticAlgorithm, sessionID, algorithm.ID, algorithm)
+	return nil
+}
+
+// AddDecision adds a decision and publishes it
+func (s *EventStore) AddDecision(sessionID string, decision *types.DecisionData) error {
+	if err := s.Store.AddDecision(sessionID, decision); err != nil {
+		return err
+	}
+	s.publish(OpAddDecision, sessionID, decision.ID, decision)
+	return nil
+}
+
+// AddVisualData adds visual data and publishes it
+func (s *EventStore) AddVisualData(sessionID string, visual *types.VisualData) error {
+	if err := s.Store.AddVisualData(sessionID, visual); err != nil {
+		return err
+	}
+	s.publish(OpAddVisualData, sessionID, visual.ID, visual)
+	return nil
+}
+
+// AddCritique adds a critique and publishes it
+func (s *EventStore) AddCritique(sessionID string, critique *types.CritiqueData) error {
+	if err := s.Store.AddCritique(sessionID, critique); err != nil {
+		return err
+	}
+	s.publish(OpAddCritique, sessionID, critique.ID, critique)
+	return nil
+}
+
+// AddScenario adds a scenario analysis and publishes it
+func (s *EventStore) AddScenario(sessionID string, scenario *types.ScenarioData) error {
+	if err := s.Store.AddScenario(sessionID, scenario); err != nil {
+		return err
+	}
+	s.publish(OpAddScenario, sessionID, scenario.ID, scenario)
+	return nil
+}
+
+// AddBatch adds each record of a batch and publishes it
+func (s *EventStore) AddBatch(sessionID string, batch *Batch) (*BatchResult, error) {
+	return addBatch(s, sessionID, batch)
+}
+
+// Transaction applies a transaction and publishes each of its writes once it commits
+func (s *EventStore) Transaction(sessionID string, fn func(tx *Tx) error) error {
+	var staged *Tx
+	err := s.Store.Transaction(sessionID, func(tx *Tx) error {
+		staged = tx
+		return fn(tx)
+	})
+	if err != nil || staged == nil {
+		return err
+	}
+
+	for _, op := range staged.ops {
+		if op.result == nil {
+			continue
+		}
+		id, record := op.result()
+		s.publish(op.op, sessionID, id, record)
+	}
+	return nil
+}
+
+// UpdateThought revises a thought and publishes the result
+func (s *EventStore) UpdateThought(sessionID, id string, update func(*types.ThoughtData) error) error {
+	return publishUpdate(s, OpUpdateThought, sessionID, id, update, func(update func(*types.ThoughtData) error) error {
+		return s.Store.UpdateThought(sessionID, id, update)
+	})
+}
+
+// UpdateMentalModel revises a mental model application and publishes the result
+func (s *EventStore) UpdateMentalModel(sessionID, id string, update func(*types.MentalModelData) error) error {
+	return publishUpdate(s, OpUpdateMentalModel, sessionID, id, update, func(update func(*types.MentalModelData) error) error {
+		return s.Store.UpdateMentalModel(sessionID, id, update)
+	})
+}
+
+// UpdateStochasticAlgorithm revises a stochastic algorithm result and publishes the result
+func (s *EventStore) UpdateStochasticAlgorithm(sessionID, id string, update func(*types.StochasticAlgorithmData) error) error {
+	return publishUpdate(s, OpUpdateStochasticAlgorithm, sessionID, id, update, func(update func(*types.StochasticAlgorithmData) error) error {
+		return s.Store.UpdateStochasticAlgorithm(sessionID, id, update)
+	})
+}
+
+// UpdateDecision revises a decision and publishes the result
+func (s *EventStore) UpdateDecision(sessionID, id string, update func(*types.DecisionData) error) error {
+	return publishUpdate(s, OpUpdateDecision, sessionID, id, update, func(update func(*types.DecisionData) error) error {
+		return s.Store.UpdateDecision(sessionID, id, update)
+	})
+}
+
+// UpdateVisualData revises visual data and publishes the result
+func (s *EventStore) UpdateVisualData(sessionID, id string, update func(*types.VisualData) error) error {
+	return publishUpdate(s, OpUpdateVisualData, sessionID, id, update, func(update func(*types.VisualData) error) error {
+		return s.Store.UpdateVisualData(sessionID, id, update)
+	})
+}
+
+// CreateSession creates a session and publishes it
+func (s *EventStore) CreateSession(sessionID string) (*SessionData, error) {
+	session, err := s.Store.CreateSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(OpCreateSession, sessionID, "", nil)
+	return session, nil
+}
+
+// ClearSession clears a session and publishes it
+func (s *EventStore) ClearSession(sessionID string) error {
+	if err := s.Store.ClearSession(sessionID); err != nil {
+		return err
+	}
+	s.publish(OpClearSession, sessionID, "", nil)
+	return nil
+}
+
+// ArchiveSession archives a session and publishes it
+func (s *EventStore) ArchiveSession(sessionID string) error {
+	if err := s.Store.ArchiveSession(sessionID); err != nil {
+		return err
+	}
+	s.publish(OpArchiveSession, sessionID, "", nil)
+	return nil
+}
+
+// RestoreSession restores an archived session and publishes it
+func (s *EventStore) RestoreSession(sessionID string) error {
+	if err := s.Store.RestoreSession(sessionID); err != nil {
+		return err
+	}
+	s.publish(OpRestoreSession, sessionID, "", nil)
+	return nil
+}
+
+// Close ends all subscriptions and closes the wrapped store
+func (s *EventStore) Close() error {
+	s.bus.Close()
+	return s.Store.Close()
+}
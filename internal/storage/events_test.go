@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receive returns the events queued on a subscription
+func receive(sub *Subscription) []Event {
+	var events []Event
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventStore_PublishesWrites(t *testing.T) {
+	store := NewEventStore(NewMemoryStore(config.DefaultConfig()), NewEventBus())
+	sub := store.Events().Subscribe("", "", 0)
+	defer sub.Close()
+
+	decision := &types.DecisionData{DecisionStatement: "Pick one"}
+	require.NoError(t, store.AddDecision("s1", decision))
+	require.NoError(t, store.UpdateDecision("s1", decision.ID, func(d *types.DecisionData) error {
+		d.Recommendation = "Option A"
+		return nil
+	}))
+	require.NoError(t, store.ClearSession("s1"))
+	// Failed writes are not published
+	assert.Error(t, store.UpdateDecision("s1", decision.ID, func(*types.DecisionData) error { return nil }))
+
+	events := receive(sub)
+	require.Len(t, events, 3)
+	assert.Equal(t, OpAddDecision, events[0].Type)
+	assert.Equal(t, OpUpdateDecision, events[1].Type)
+	assert.Equal(t, OpClearSession, events[2].Type)
+	for i, event := range events {
+		assert.Equal(t, uint64(i+1), event.Seq)
+		assert.Equal(t, "s1", event.SessionID)
+	}
+
+	var updated types.DecisionData
+	require.NoError(t, json.Unmarshal(events[1].Record, &updated))
+	assert.Equal(t, decision.ID, events[1].RecordID)
+	assert.Equal(t, "Option A", updated.Recommendation)
+	assert.Nil(t, events[2].Record)
+}
+
+func TestEventBus_ScopesSubscriptions(t *testing.T) {
+	store := NewEventStore(NewMemoryStore(config.DefaultConfig()), NewEventBus())
+	shared := store.Events().Subscribe("", "", 0)
+	acme := store.Events().Subscribe("acme", "", 0)
+	acmeS2 := store.Events().Subscribe("acme", "s2", 0)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "shared"}))
+	tenant := ForTenant(store, "acme")
+	require.NoError(t, tenant.AddThought("s1", &types.ThoughtData{Thought: "first"}))
+	require.NoError(t, tenant.AddThought("s2", &types.ThoughtData{Thought: "second"}))
+
+	assert.Len(t, receive(shared), 1)
+
+	events := receive(acme)
+	require.Len(t, events, 2)
+	assert.Equal(t, "s1", events[0].SessionID)
+	var thought types.ThoughtData
+	require.NoError(t, json.Unmarshal(events[0].Record, &thought))
+	assert.Equal(t, "s1", thought.SessionID)
+
+	events = receive(acmeS2)
+	require.Len(t, events, 1)
+	assert.Equal(t, "s2", events[0].SessionID)
+}
+
+func TestEventBus_DropsForSlowSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe("", "", 2)
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: OpCreateSession, SessionID: "s1"})
+	}
+
+	assert.Len(t, receive(sub), 2)
+	assert.Equal(t, uint64(3), sub.Dropped())
+}
+
+func TestEventBus_CloseEndsSubscriptions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	store, err := New(cfg)
+	require.NoError(t, err)
+	events, ok := store.(*EventStore)
+	require.True(t, ok)
+
+	sub := events.Events().Subscribe("", "", 0)
+	require.NoError(t, store.Close())
+
+	_, open := <-sub.Events()
+	assert.False(t, open)
+	sub.Close()
+
+	late := events.Events().Subscribe("", "", 0)
+	_, open = <-late.Events()
+	assert.False(t, open)
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// ForkResult describes a session copied by ForkSession
+type ForkResult struct {
+	SourceSessionID string `json:"source_session_id"`
+	// UpToThought is the thought number the fork was cut at, or 0 for a full copy
+	UpToThought int `json:"up_to_thought,omitempty"`
+	*ImportResult
+}
+
+// ForkSession copies the records of sourceID into the new session targetID,
+// leaving the source untouched. Copies receive new IDs and references between
+// them are rewritten, as for ImportSession. With upToThought > 0 the fork
+// holds the session as it stood before the first thought numbered beyond
+// upToThought was recorded: later records of every type are left out.
+func ForkSession(s Store, sourceID, targetID string, upToThought int) (*ForkResult, error) {
+	if targetID == "" {
+		return nil, errorf(ErrInvalidArgument, "target session ID required")
+	}
+	if targetID == sourceID {
+		return nil, errorf(ErrInvalidArgument, "cannot fork session %s into itself", sourceID)
+	}
+	if upToThought < 0 {
+		return nil, errorf(ErrInvalidArgument, "up_to_thought must not be negative")
+	}
+	if _, err := s.GetSession(sourceID); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetSession(targetID); err == nil {
+		return nil, errorf(ErrSessionExists, "session %s already exists", targetID)
+	}
+
+	export, err := s.ExportSession(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decodeExportData(export.Data)
+	if err != nil {
+		return nil, err
+	}
+	if upToThought > 0 {
+		data = cutAtThought(data, upToThought)
+	}
+
+	// Import works on the typed data directly; the export is only a carrier
+	export.Data = data
+	if _, err := s.CreateSession(targetID); err != nil {
+		return nil, err
+	}
+	imported, err := ImportSession(s, export, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork session %s: %w", sourceID, err)
+	}
+
+	return &ForkResult{
+		SourceSessionID: sourceID,
+		UpToThought:     upToThought,
+		ImportResult:    imported,
+	}, nil
+}
+
+// cutAtThought keeps the records created before the first thought numbered
+// beyond upToThought. Critiques only review earlier records, so every record a
+// kept critique references is kept too.
+func cutAtThought(data *exportData, upToThought int) *exportData {
+	var cutoff time.Time
+	for _, thought := range data.Thoughts {
+		if thought.ThoughtNumber > upToThought && (cutoff.IsZero() || thought.CreatedAt.Before(cutoff)) {
+			cutoff = thought.CreatedAt
+		}
+	}
+	if cutoff.IsZero() {
+		return data
+	}
+
+	return &exportData{
+		Thoughts:             createdBefore(data.Thoughts, cutoff, thoughtIdentity),
+		MentalModels:         createdBefore(data.MentalModels, cutoff, mentalModelIdentity),
+		StochasticAlgorithms: createdBefore(data.StochasticAlgorithms, cutoff, algorithmIdentity),
+		Decisions:            createdBefore(data.Decisions, cutoff, decisionIdentity),
+		VisualData:           createdBefore(data.VisualData, cutoff, visualIdentity),
+		Critiques:            createdBefore(data.Critiques, cutoff, critiqueIdentity),
+		Scenarios:            createdBefore(data.Scenarios, cutoff, scenarioIdentity),
+	}
+}
+
+// createdBefore returns the records created before cutoff
+func createdBefore[T any](records []*T, cutoff time.Time, identity identityFunc[T]) []*T {
+	var kept []*T
+	for _, record := range records {
+		if _, _, createdAt := identity(record); createdAt.Before(cutoff) {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
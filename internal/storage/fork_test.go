@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkSession_CopiesRecords(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	thought := &types.ThoughtData{Thought: "first", ThoughtNumber: 1, TotalThoughts: 1}
+	require.NoError(t, store.AddThought("source", thought))
+	require.NoError(t, store.AddCritique("source", &types.CritiqueData{TargetType: "thoughts", TargetIDs: []string{thought.ID}}))
+
+	result, err := ForkSession(store, "source", "fork", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "source", result.SourceSessionID)
+	assert.Equal(t, "fork", result.SessionID)
+	assert.Equal(t, map[string]int{KindThoughts: 1, KindCritiques: 1}, result.Imported)
+
+	forked, err := store.GetThoughts("fork", nil)
+	require.NoError(t, err)
+	require.Len(t, forked, 1)
+	assert.NotEqual(t, thought.ID, forked[0].ID)
+
+	// The critique reviews the copied thought, not the original
+	critiques, err := store.GetCritiques("fork", nil)
+	require.NoError(t, err)
+	require.Len(t, critiques, 1)
+	assert.Equal(t, []string{forked[0].ID}, critiques[0].TargetIDs)
+
+	// The forks are independent
+	require.NoError(t, store.AddThought("fork", &types.ThoughtData{Thought: "alternative", ThoughtNumber: 2, TotalThoughts: 2}))
+	original, err := store.GetThoughts("source", nil)
+	require.NoError(t, err)
+	assert.Len(t, original, 1)
+}
+
+func TestForkSession_UpToThought(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	tick := 0
+	store.SetClock(func() time.Time {
+		tick++
+		return time.Date(2025, 1, 1, 0, 0, tick, 0, time.UTC)
+	})
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, store.AddThought("source", &types.ThoughtData{Thought: "step", ThoughtNumber: i, TotalThoughts: 3}))
+		require.NoError(t, store.AddDecision("source", &types.DecisionData{DecisionStatement: "after step"}))
+	}
+
+	result, err := ForkSession(store, "source", "fork", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.UpToThought)
+	assert.Equal(t, map[string]int{KindThoughts: 2, KindDecisions: 2}, result.Imported)
+}
+
+func TestForkSession_RejectsInvalidTargets(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddThought("source", &types.ThoughtData{Thought: "first"}))
+	require.NoError(t, store.AddThought("taken", &types.ThoughtData{Thought: "other"}))
+
+	_, err := ForkSession(store, "missing", "fork", 0)
+	assert.Error(t, err)
+	_, err = ForkSession(store, "source", "taken", 0)
+	assert.ErrorContains(t, err, "already exists")
+	_, err = ForkSession(store, "source", "source", 0)
+	assert.Error(t, err)
+
+	thoughts, err := store.GetThoughts("taken", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
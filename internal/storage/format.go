@@ -0,0 +1,697 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/internal/flowchart"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Session export formats
+const (
+	FormatJSON       = "json"
+	FormatMarkdown   = "markdown"
+	FormatCSV        = "csv"
+	FormatDOT        = "dot"
+	FormatExcalidraw = "excalidraw"
+	FormatDrawIO     = "drawio"
+	FormatPlantUML   = "plantuml"
+)
+
+// ExportFormats returns the formats accepted by RenderExport
+func ExportFormats() []string {
+	return []string{FormatJSON, FormatMarkdown, FormatCSV, FormatDOT, FormatExcalidraw, FormatDrawIO, FormatPlantUML}
+}
+
+// ExportFile is one file of a rendered session export
+type ExportFile struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+}
+
+// RenderExport encodes a session export in the given format. JSON and
+// Markdown produce a single file; CSV produces one file per store, and DOT,
+// Excalidraw, draw.io and PlantUML one file per diagram.
+func RenderExport(export *types.SessionExport, format string) ([]ExportFile, error) {
+	switch format {
+	case "", FormatJSON:
+		data, err := json.Marshal(export)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode session export: %w", err)
+		}
+		return []ExportFile{{Name: "session.json", ContentType: "application/json", Content: string(data)}}, nil
+	case FormatMarkdown:
+		data, err := decodeExportData(export.Data)
+		if err != nil {
+			return nil, err
+		}
+		return []ExportFile{{Name: "session.md", ContentType: "text/markdown", Content: renderMarkdown(export, data)}}, nil
+	case FormatCSV:
+		data, err := decodeExportData(export.Data)
+		if err != nil {
+			return nil, err
+		}
+		return renderCSV(data)
+	case FormatDOT:
+		data, err := decodeExportData(export.Data)
+		if err != nil {
+			return nil, err
+		}
+		return renderDiagrams(data, "dot", "text/vnd.graphviz", renderDiagram), nil
+	case FormatExcalidraw:
+		data, err := decodeExportData(export.Data)
+		if err != nil {
+			return nil, err
+		}
+		return renderDiagrams(data, "excalidraw", "application/vnd.excalidraw+json", renderExcalidraw), nil
+	case FormatDrawIO:
+		data, err := decodeExportData(export.Data)
+		if err != nil {
+			return nil, err
+		}
+		return renderDiagrams(data, "drawio", "application/vnd.jgraph.mxfile", renderDrawIO), nil
+	case FormatPlantUML:
+		data, err := decodeExportData(export.Data)
+		if err != nil {
+			return nil, err
+		}
+		return renderDiagrams(data, "puml", "text/plain", renderPlantUML), nil
+	default:
+		return nil, errorf(ErrInvalidArgument, "unknown export format %q (expected one of %v)", format, ExportFormats())
+	}
+}
+
+// ============================================================================
+// Markdown
+// ============================================================================
+
+// renderMarkdown writes a readable report of the session's reasoning
+func renderMarkdown(export *types.SessionExport, data *exportData) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Session %s\n\n", export.SessionID)
+	fmt.Fprintf(&b, "Exported %s\n", export.Timestamp.UTC().Format(time.RFC3339))
+
+	if len(data.Thoughts) > 0 {
+		b.WriteString("\n## Thoughts\n\n")
+		for _, thought := range data.Thoughts {
+			fmt.Fprintf(&b, "%d. %s", thought.ThoughtNumber, thought.Thought)
+			if thought.IsRevision && thought.RevisesThought != nil {
+				fmt.Fprintf(&b, " _(revises thought %d)_", *thought.RevisesThought)
+			}
+			if thought.BranchID != "" {
+				fmt.Fprintf(&b, " _(branch %s)_", thought.BranchID)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	var models, debugging []*types.MentalModelData
+	for _, model := range data.MentalModels {
+		if model.IsDebuggingApproach() {
+			debugging = append(debugging, model)
+		} else {
+			models = append(models, model)
+		}
+	}
+
+	if len(models) > 0 {
+		b.WriteString("\n## Mental Models\n")
+		for _, model := range models {
+			fmt.Fprintf(&b, "\n### %s\n\n", model.ModelName)
+			writeField(&b, "Problem", model.Problem)
+			writeList(&b, "Steps", model.Steps)
+			writeField(&b, "Reasoning", model.Reasoning)
+			writeField(&b, "Conclusion", model.Conclusion)
+			if model.Confidence > 0 {
+				writeField(&b, "Confidence", formatFloat(model.Confidence))
+			}
+		}
+	}
+
+	if len(debugging) > 0 {
+		b.WriteString("\n## Debugging\n")
+		for _, approach := range debugging {
+			fmt.Fprintf(&b, "\n### %s\n\n", strings.TrimPrefix(approach.ModelName, types.DebuggingModelPrefix))
+			writeField(&b, "Issue", approach.Problem)
+			writeList(&b, "Steps", approach.Steps)
+			writeField(&b, "Findings", approach.Reasoning)
+			writeField(&b, "Resolution", approach.Conclusion)
+		}
+	}
+
+	if len(data.Decisions) > 0 {
+		b.WriteString("\n## Decisions\n")
+		for _, decision := range data.Decisions {
+			fmt.Fprintf(&b, "\n### %s\n\n", decision.DecisionStatement)
+			writeField(&b, "Analysis", decision.AnalysisType)
+			if len(decision.Options) > 0 {
+				b.WriteString("\n| Option | Description |\n| --- | --- |\n")
+				for _, option := range decision.Options {
+					fmt.Fprintf(&b, "| %s | %s |\n", escapeCell(option.Name), escapeCell(option.Description))
+				}
+				b.WriteString("\n")
+			}
+			writeField(&b, "Recommendation", decision.Recommendation)
+		}
+	}
+
+	if len(data.Scenarios) > 0 {
+		b.WriteString("\n## Scenario Analyses\n")
+		for _, analysis := range data.Scenarios {
+			title := analysis.Title
+			if title == "" {
+				title = "Scenario analysis"
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n", title)
+			b.WriteString("| Scenario | Probability | Description |\n| --- | --- | --- |\n")
+			for _, scenario := range analysis.Scenarios {
+				fmt.Fprintf(&b, "| %s | %s | %s |\n", escapeCell(scenario.Name), formatFloat(scenario.Probability), escapeCell(scenario.Description))
+			}
+			b.WriteString("\n| Option | Class | Expected value | Worst case | Max regret |\n| --- | --- | --- | --- | --- |\n")
+			for _, e := range analysis.Evaluations {
+				fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", escapeCell(e.Option), e.Class, formatFloat(e.ExpectedValue), formatFloat(e.WorstCase), formatFloat(e.MaxRegret))
+			}
+			b.WriteString("\n")
+			writeField(&b, "Recommendation", analysis.Recommendation)
+		}
+	}
+
+	if len(data.StochasticAlgorithms) > 0 {
+		b.WriteString("\n## Stochastic Algorithms\n\n")
+		for _, algorithm := range data.StochasticAlgorithms {
+			fmt.Fprintf(&b, "- **%s**: %s", algorithm.Algorithm, algorithm.Problem)
+			if algorithm.Result != "" {
+				fmt.Fprintf(&b, " → %s", algorithm.Result)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(data.VisualData) > 0 {
+		b.WriteString("\n## Visual Thinking\n\n")
+		for _, visual := range data.VisualData {
+			fmt.Fprintf(&b, "- **%s** %s (%s)", visual.DiagramType, visual.Operation, visual.DiagramID)
+			if visual.Insight != "" {
+				fmt.Fprintf(&b, ": %s", visual.Insight)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(data.Critiques) > 0 {
+		b.WriteString("\n## Critiques\n")
+		for _, critique := range data.Critiques {
+			fmt.Fprintf(&b, "\n### %s critique\n\n", critique.TargetType)
+			writeField(&b, "Summary", critique.Summary)
+			writeList(&b, "Logical gaps", critique.LogicalGaps)
+			writeList(&b, "Missing alternatives", critique.MissingAlternatives)
+		}
+	}
+
+	return b.String()
+}
+
+// writeField writes a labelled paragraph, skipping empty values
+func writeField(b *strings.Builder, label, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "**%s:** %s\n\n", label, value)
+}
+
+// writeList writes a labelled bullet list, skipping empty lists
+func writeList(b *strings.Builder, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "**%s:**\n\n", label)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+	b.WriteString("\n")
+}
+
+// escapeCell keeps a value from breaking a Markdown table row
+func escapeCell(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
+
+// ============================================================================
+// CSV
+// ============================================================================
+
+// renderCSV writes one CSV file per store, with a header row in each
+func renderCSV(data *exportData) ([]ExportFile, error) {
+	tables := []struct {
+		kind   string
+		header []string
+		rows   [][]string
+	}{
+		{KindThoughts, []string{"id", "created_at", "thought_number", "total_thoughts", "thought", "is_revision", "branch_id", "next_thought_needed"}, nil},
+		{KindMentalModels, []string{"id", "created_at", "model_name", "problem", "steps", "reasoning", "conclusion", "confidence"}, nil},
+		{KindStochasticAlgorithms, []string{"id", "created_at", "algorithm", "problem", "parameters", "result", "confidence", "iterations", "converged", "stopping_reason", "value"}, nil},
+		{KindDecisions, []string{"id", "created_at", "decision_statement", "analysis_type", "stage", "options", "recommendation"}, nil},
+		{KindVisualData, []string{"id", "created_at", "diagram_id", "diagram_type", "operation", "iteration", "observation", "insight", "hypothesis"}, nil},
+		{KindCritiques, []string{"id", "created_at", "target_type", "target_ids", "model", "summary", "logical_gaps", "missing_alternatives"}, nil},
+		{KindScenarios, []string{"id", "created_at", "title", "scenarios", "options", "robust_options", "bets", "recommendation"}, nil},
+	}
+
+	for _, r := range data.Thoughts {
+		tables[0].rows = append(tables[0].rows, []string{r.ID, formatTime(r.CreatedAt), strconv.Itoa(r.ThoughtNumber), strconv.Itoa(r.TotalThoughts),
+			r.Thought, strconv.FormatBool(r.IsRevision), r.BranchID, strconv.FormatBool(r.NextThoughtNeeded)})
+	}
+	for _, r := range data.MentalModels {
+		tables[1].rows = append(tables[1].rows, []string{r.ID, formatTime(r.CreatedAt), r.ModelName, r.Problem,
+			strings.Join(r.Steps, "; "), r.Reasoning, r.Conclusion, formatFloat(r.Confidence)})
+	}
+	for _, r := range data.StochasticAlgorithms {
+		parameters, _ := json.Marshal(r.Parameters)
+		value := ""
+		if r.Value != nil {
+			value = formatFloat(*r.Value)
+		}
+		tables[2].rows = append(tables[2].rows, []string{r.ID, formatTime(r.CreatedAt), r.Algorithm, r.Problem, string(parameters),
+			r.Result, formatFloat(r.Confidence), strconv.Itoa(r.Iterations), strconv.FormatBool(r.Converged), r.StoppingReason, value})
+	}
+	for _, r := range data.Decisions {
+		options := make([]string, len(r.Options))
+		for i, option := range r.Options {
+			options[i] = option.Name
+		}
+		tables[3].rows = append(tables[3].rows, []string{r.ID, formatTime(r.CreatedAt), r.DecisionStatement, r.AnalysisType, r.Stage,
+			strings.Join(options, "; "), r.Recommendation})
+	}
+	for _, r := range data.VisualData {
+		tables[4].rows = append(tables[4].rows, []string{r.ID, formatTime(r.CreatedAt), r.DiagramID, r.DiagramType, r.Operation,
+			strconv.Itoa(r.Iteration), r.Observation, r.Insight, r.Hypothesis})
+	}
+	for _, r := range data.Critiques {
+		tables[5].rows = append(tables[5].rows, []string{r.ID, formatTime(r.CreatedAt), r.TargetType, strings.Join(r.TargetIDs, "; "), r.Model,
+			r.Summary, strings.Join(r.LogicalGaps, "; "), strings.Join(r.MissingAlternatives, "; ")})
+	}
+	for _, r := range data.Scenarios {
+		scenarios := make([]string, len(r.Scenarios))
+		for i, scenario := range r.Scenarios {
+			scenarios[i] = fmt.Sprintf("%s (%s)", scenario.Name, formatFloat(scenario.Probability))
+		}
+		tables[6].rows = append(tables[6].rows, []string{r.ID, formatTime(r.CreatedAt), r.Title, strings.Join(scenarios, "; "),
+			strings.Join(r.Options, "; "), strings.Join(r.RobustOptions, "; "), strings.Join(r.Bets, "; "), r.Recommendation})
+	}
+
+	files := make([]ExportFile, 0, len(tables))
+	for _, table := range tables {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write(table.header); err != nil {
+			return nil, fmt.Errorf("failed to write %s CSV: %w", table.kind, err)
+		}
+		if err := w.WriteAll(table.rows); err != nil {
+			return nil, fmt.Errorf("failed to write %s CSV: %w", table.kind, err)
+		}
+		files = append(files, ExportFile{Name: table.kind + ".csv", ContentType: "text/csv", Content: buf.String()})
+	}
+
+	return files, nil
+}
+
+// ============================================================================
+// DOT
+// ============================================================================
+
+// diagram is a diagram as its recorded operations left it
+type diagram struct {
+	id          string
+	diagramType string
+	elements    []types.VisualElement
+}
+
+// replayDiagrams applies the operations of visuals, oldest first, to their
+// diagrams: create replaces a diagram's elements, update adds its elements or
+// replaces those of the same ID, and delete removes its elements or, naming
+// none, the diagram. Diagrams are returned in the order they were begun.
+func replayDiagrams(visuals []*types.VisualData) []*diagram {
+	ordered := append([]*types.VisualData{}, visuals...)
+	sort.SliceStable(ordered, func(a, b int) bool { return ordered[a].CreatedAt.Before(ordered[b].CreatedAt) })
+
+	var diagrams []*diagram
+	byID := make(map[string]*diagram)
+	for _, visual := range ordered {
+		d := byID[visual.DiagramID]
+		if d == nil {
+			d = &diagram{id: visual.DiagramID}
+			byID[visual.DiagramID] = d
+			diagrams = append(diagrams, d)
+		}
+		if visual.DiagramType != "" {
+			d.diagramType = visual.DiagramType
+		}
+		d.elements, _ = ApplyDiagramOperation(d.elements, visual)
+	}
+
+	live := diagrams[:0]
+	for _, d := range diagrams {
+		if len(d.elements) > 0 {
+			live = append(live, d)
+		}
+	}
+	return live
+}
+
+// DiagramChanges lists by ID the elements a diagram operation added,
+// changed and removed
+type DiagramChanges struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// ApplyDiagramOperation applies the operation of visual to the elements of
+// its diagram, returning the elements it leaves and what it changed. Create,
+// like a transform, replaces the elements, update adds its elements or
+// replaces those of the same ID, and delete removes its elements or, naming
+// none, all of them;
+// deleting an element the diagram lacks changes nothing. elements is left
+// untouched.
+func ApplyDiagramOperation(elements []types.VisualElement, visual *types.VisualData) ([]types.VisualElement, DiagramChanges) {
+	changes := DiagramChanges{Added: []string{}, Updated: []string{}, Removed: []string{}}
+	result := append([]types.VisualElement{}, elements...)
+	switch visual.Operation {
+	case "delete":
+		deleted := make(map[string]bool, len(visual.Elements))
+		for _, element := range visual.Elements {
+			deleted[element.ID] = true
+		}
+		kept := result[:0]
+		for _, element := range result {
+			if len(visual.Elements) == 0 || deleted[element.ID] {
+				changes.Removed = append(changes.Removed, element.ID)
+			} else {
+				kept = append(kept, element)
+			}
+		}
+		result = kept
+	case "update":
+		for _, element := range visual.Elements {
+			replaced := false
+			for i := range result {
+				if result[i].ID == element.ID {
+					if !reflect.DeepEqual(result[i], element) {
+						changes.Updated = append(changes.Updated, element.ID)
+					}
+					result[i], replaced = element, true
+					break
+				}
+			}
+			if !replaced {
+				result = append(result, element)
+				changes.Added = append(changes.Added, element.ID)
+			}
+		}
+	default:
+		kept := make(map[string]bool, len(visual.Elements))
+		for _, element := range visual.Elements {
+			kept[element.ID] = true
+		}
+		existed := make(map[string]types.VisualElement, len(result))
+		for _, element := range result {
+			existed[element.ID] = element
+			if !kept[element.ID] {
+				changes.Removed = append(changes.Removed, element.ID)
+			}
+		}
+		for _, element := range visual.Elements {
+			previous, ok := existed[element.ID]
+			switch {
+			case !ok:
+				changes.Added = append(changes.Added, element.ID)
+			case !reflect.DeepEqual(previous, element):
+				changes.Updated = append(changes.Updated, element.ID)
+			}
+		}
+		result = append([]types.VisualElement{}, visual.Elements...)
+	}
+	return result, changes
+}
+
+// DiagramType returns the type of diagram diagramID as the operations of
+// visuals left it, empty when it was never drawn
+func DiagramType(visuals []*types.VisualData, diagramID string) string {
+	for _, d := range replayDiagrams(visuals) {
+		if d.id == diagramID {
+			return d.diagramType
+		}
+	}
+	return ""
+}
+
+// DiagramElements returns the elements of diagram diagramID as the
+// operations of visuals left it, none when it was deleted or never drawn
+func DiagramElements(visuals []*types.VisualData, diagramID string) []types.VisualElement {
+	for _, d := range replayDiagrams(visuals) {
+		if d.id == diagramID {
+			return d.elements
+		}
+	}
+	return nil
+}
+
+// renderDiagrams writes each diagram of the session to a file of its own,
+// named for the diagram with extension ext, with render. In every format,
+// edges are the elements with a source and target; elements containing
+// others are groups; the rest are nodes.
+func renderDiagrams(data *exportData, ext, contentType string, render func(*diagram) string) []ExportFile {
+	diagrams := replayDiagrams(data.VisualData)
+	files := make([]ExportFile, 0, len(diagrams))
+	names := make(map[string]bool, len(diagrams))
+	for _, d := range diagrams {
+		name := dotFileName(d.id)
+		for n := 2; names[name]; n++ {
+			name = fmt.Sprintf("%s-%d", dotFileName(d.id), n)
+		}
+		names[name] = true
+		files = append(files, ExportFile{Name: name + "." + ext, ContentType: contentType, Content: render(d)})
+	}
+	return files
+}
+
+// DiagramDOT renders the elements of a diagram as a Graphviz digraph, as the
+// DOT export does
+func DiagramDOT(diagramID, diagramType string, elements []types.VisualElement) string {
+	return renderDiagram(&diagram{id: diagramID, diagramType: diagramType, elements: elements})
+}
+
+// renderDiagram writes d as a Graphviz digraph, its groups as clusters
+func renderDiagram(d *diagram) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(d.id))
+	if d.diagramType != "" {
+		fmt.Fprintf(&b, "  label=%s;\n", dotQuote(d.diagramType))
+	}
+
+	elements := make(map[string]types.VisualElement, len(d.elements))
+	contained := make(map[string]bool)
+	for _, element := range d.elements {
+		elements[element.ID] = element
+		for _, member := range element.Contains {
+			contained[member] = true
+		}
+	}
+
+	rendered := make(map[string]bool, len(d.elements))
+	var write func(element types.VisualElement, indent string)
+	write = func(element types.VisualElement, indent string) {
+		if rendered[element.ID] {
+			return
+		}
+		rendered[element.ID] = true
+		if len(element.Contains) == 0 {
+			fmt.Fprintf(&b, "%s%s%s;\n", indent, dotQuote(element.ID), dotAttributes(nodeAttributes(d.diagramType, element)))
+			return
+		}
+		fmt.Fprintf(&b, "%ssubgraph %s {\n", indent, dotQuote("cluster_"+element.ID))
+		for _, attribute := range dotAttributeList(nodeAttributes(d.diagramType, element)) {
+			fmt.Fprintf(&b, "%s  %s;\n", indent, attribute)
+		}
+		for _, member := range element.Contains {
+			if m, ok := elements[member]; ok && !isEdge(m) {
+				write(m, indent+"  ")
+			} else if !ok {
+				fmt.Fprintf(&b, "%s  %s;\n", indent, dotQuote(member))
+			}
+		}
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+	for _, element := range d.elements {
+		if !isEdge(element) && !contained[element.ID] {
+			write(element, "  ")
+		}
+	}
+	for _, element := range d.elements {
+		if isEdge(element) {
+			fmt.Fprintf(&b, "  %s -> %s%s;\n", dotQuote(element.Source), dotQuote(element.Target), dotAttributes(edgeAttributes(element)))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// isEdge reports whether element joins two others
+func isEdge(element types.VisualElement) bool {
+	return element.Source != "" && element.Target != ""
+}
+
+// nodeShapes are the shapes of the nodes of decision trees by node type
+var nodeShapes = map[string]string{
+	"decision": "box",
+	"chance":   "ellipse",
+	"terminal": "plaintext",
+}
+
+// nodeAttributes maps a node or cluster's label, type and properties to
+// Graphviz attributes. The type becomes its class; a flowchart node's type or
+// a decision tree node's node type picks its shape; and the properties, which
+// may set Graphviz attributes such as color or shape, are carried over as
+// they are.
+func nodeAttributes(diagramType string, element types.VisualElement) map[string]string {
+	attributes := elementAttributes(element)
+	shapes, shapeOf := nodeShapes, fmt.Sprint(element.Properties["node_type"])
+	if diagramType == flowchart.DiagramType {
+		shapes, shapeOf = flowchart.Shapes, element.Type
+	}
+	if shape, ok := shapes[shapeOf]; ok {
+		attributes["shape"] = shape
+	}
+	if optimal, _ := element.Properties["optimal"].(bool); optimal {
+		attributes["penwidth"] = "2"
+	}
+	attributes = overlayProperties(attributes, element)
+	// A laid out node is pinned where its layout put it; Graphviz's y axis
+	// points up
+	x, isX := element.Properties["x"].(float64)
+	y, isY := element.Properties["y"].(float64)
+	if isX && isY {
+		if y != 0 {
+			y = -y
+		}
+		attributes["pos"] = fmt.Sprintf("%s,%s!", formatFloat(x), formatFloat(y))
+		delete(attributes, "x")
+		delete(attributes, "y")
+	}
+	return attributes
+}
+
+// edgeAttributes maps an edge's label, type, probability and properties to
+// Graphviz attributes: its probability is shown after its label and
+// thickens the edge, and an optimal edge is drawn bold
+func edgeAttributes(element types.VisualElement) map[string]string {
+	attributes := elementAttributes(element)
+	if element.Probability > 0 {
+		probability := formatFloat(element.Probability)
+		attributes["probability"] = probability
+		if element.Label != "" {
+			attributes["label"] = fmt.Sprintf("%s (%s)", element.Label, probability)
+		} else {
+			attributes["label"] = probability
+		}
+		attributes["penwidth"] = formatFloat(1 + 2*element.Probability)
+	}
+	if optimal, _ := element.Properties["optimal"].(bool); optimal {
+		attributes["style"] = "bold"
+	}
+	return overlayProperties(attributes, element)
+}
+
+// elementAttributes returns the label and class of element
+func elementAttributes(element types.VisualElement) map[string]string {
+	attributes := make(map[string]string)
+	if element.Label != "" {
+		attributes["label"] = element.Label
+	}
+	if element.Type != "" {
+		attributes["class"] = element.Type
+	}
+	return attributes
+}
+
+// overlayProperties sets an attribute for each property of element, encoding
+// values other than strings, numbers and booleans as JSON, without
+// replacing its label
+func overlayProperties(attributes map[string]string, element types.VisualElement) map[string]string {
+	for key, value := range element.Properties {
+		if key == "label" && attributes["label"] != "" {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			attributes[key] = v
+		case float64:
+			attributes[key] = formatFloat(v)
+		case int, int64, bool:
+			attributes[key] = fmt.Sprint(v)
+		default:
+			encoded, _ := json.Marshal(v)
+			attributes[key] = string(encoded)
+		}
+	}
+	return attributes
+}
+
+// dotAttributeList returns attributes as key=value pairs, ordered by key
+func dotAttributeList(attributes map[string]string) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	list := make([]string, len(keys))
+	for i, key := range keys {
+		list[i] = dotQuote(key) + "=" + dotQuote(attributes[key])
+	}
+	return list
+}
+
+// dotAttributes returns the attribute list of a node or edge statement
+func dotAttributes(attributes map[string]string) string {
+	if len(attributes) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(dotAttributeList(attributes), ", ") + "]"
+}
+
+// dotQuote quotes s as a DOT ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// dotFileName returns a diagram's ID made safe for a file name
+func dotFileName(id string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, id)
+	if name == "" {
+		name = "diagram"
+	}
+	return name
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
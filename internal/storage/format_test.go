@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFormatFixture(t *testing.T) *types.SessionExport {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "Frame the question", ThoughtNumber: 1, TotalThoughts: 1}))
+	require.NoError(t, store.AddMentalModel("s1", &types.MentalModelData{
+		ModelName:  "first_principles",
+		Problem:    "Slow builds",
+		Steps:      []string{"List assumptions", "Rebuild"},
+		Conclusion: "Cache dependencies",
+	}))
+	require.NoError(t, store.AddMentalModel("s1", &types.MentalModelData{
+		ModelName: types.DebuggingModelPrefix + "bisect",
+		Problem:   "Flaky test",
+		Reasoning: "Fails under load",
+	}))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{
+		DecisionStatement: "Pick a database",
+		Options:           []types.DecisionOption{{Name: "Postgres", Description: "Relational | mature"}, {Name: "Redis"}},
+		Recommendation:    "Postgres",
+	}))
+
+	export, err := store.ExportSession("s1")
+	require.NoError(t, err)
+	return export
+}
+
+func TestRenderExport_Markdown(t *testing.T) {
+	files, err := RenderExport(newFormatFixture(t), FormatMarkdown)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	report := files[0].Content
+	assert.Contains(t, report, "# Session s1")
+	assert.Contains(t, report, "1. Frame the question")
+	assert.Contains(t, report, "**Conclusion:** Cache dependencies")
+	assert.Contains(t, report, "## Debugging\n\n### bisect")
+	assert.Contains(t, report, "**Findings:** Fails under load")
+	assert.Contains(t, report, "| Postgres | Relational \\| mature |")
+	assert.Contains(t, report, "**Recommendation:** Postgres")
+	assert.NotContains(t, report, "## Critiques")
+}
+
+func TestRenderExport_CSV(t *testing.T) {
+	files, err := RenderExport(newFormatFixture(t), FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, files, len(RecordKinds()))
+
+	byName := make(map[string]string)
+	for _, file := range files {
+		byName[file.Name] = file.Content
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(byName["decisions.csv"])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "decision_statement", rows[0][2])
+	assert.Equal(t, "Pick a database", rows[1][2])
+	assert.Equal(t, "Postgres; Redis", rows[1][5])
+
+	// Stores without records still get a header row
+	rows, err = csv.NewReader(strings.NewReader(byName["critiques.csv"])).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+}
+
+func TestRenderExport_DOT(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddVisualData("s1", &types.VisualData{
+		Operation:   "create",
+		DiagramID:   "trace:run 1",
+		DiagramType: "decision-tree",
+		Elements: []types.VisualElement{
+			{ID: "root", Type: "node", Label: "Launch?", Properties: map[string]interface{}{"node_type": "decision", "value": 42.5}},
+			{ID: "market", Type: "node", Label: "Market", Properties: map[string]interface{}{"node_type": "chance"}},
+			{ID: "root->market", Type: "edge", Label: "launch", Source: "root", Target: "market", Probability: 0.25,
+				Properties: map[string]interface{}{"optimal": true}},
+		},
+		CreatedAt: time.Now(),
+	}))
+	require.NoError(t, store.AddVisualData("s1", &types.VisualData{
+		Operation: "update",
+		DiagramID: "trace:run 1",
+		Elements: []types.VisualElement{
+			{ID: "market", Type: "node", Label: `Market "A"`, Properties: map[string]interface{}{"node_type": "chance", "color": "red"}},
+			{ID: "group", Type: "stage", Label: "Stage 1", Contains: []string{"root", "market"}},
+		},
+		CreatedAt: time.Now().Add(time.Second),
+	}))
+	require.NoError(t, store.AddVisualData("s1", &types.VisualData{
+		Operation: "create",
+		DiagramID: "scratch",
+		Elements:  []types.VisualElement{{ID: "a", Type: "concept"}},
+		CreatedAt: time.Now().Add(2 * time.Second),
+	}))
+	require.NoError(t, store.AddVisualData("s1", &types.VisualData{
+		Operation: "delete",
+		DiagramID: "scratch",
+		CreatedAt: time.Now().Add(3 * time.Second),
+	}))
+
+	export, err := store.ExportSession("s1")
+	require.NoError(t, err)
+	files, err := RenderExport(export, FormatDOT)
+	require.NoError(t, err)
+
+	// The deleted diagram is left out
+	require.Len(t, files, 1)
+	assert.Equal(t, "trace_run_1.dot", files[0].Name)
+	assert.Equal(t, "text/vnd.graphviz", files[0].ContentType)
+
+	dot := files[0].Content
+	assert.True(t, strings.HasPrefix(dot, "digraph \"trace:run 1\" {\n  label=\"decision-tree\";\n"))
+	assert.Contains(t, dot, "  subgraph \"cluster_group\" {\n    \"class\"=\"stage\";\n    \"label\"=\"Stage 1\";\n")
+	assert.Contains(t, dot, `    "root" ["class"="node", "label"="Launch?", "node_type"="decision", "shape"="box", "value"="42.5"];`)
+	assert.Contains(t, dot, `    "market" ["class"="node", "color"="red", "label"="Market \"A\"", "node_type"="chance", "shape"="ellipse"];`)
+	assert.Contains(t, dot, `  "root" -> "market" ["class"="edge", "label"="launch (0.25)", "optimal"="true", "penwidth"="1.5", "probability"="0.25", "style"="bold"];`)
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+}
+
+func TestDiagramDOT_PinsLaidOutNodes(t *testing.T) {
+	dot := DiagramDOT("map", "conceptMap", []types.VisualElement{
+		{ID: "a", Type: "concept", Properties: map[string]interface{}{"x": 0.0, "y": 0.0}},
+		{ID: "b", Type: "concept", Properties: map[string]interface{}{"x": 120.0, "y": 80.0}},
+		{ID: "c", Type: "concept", Properties: map[string]interface{}{"x": 10.0}},
+	})
+	assert.Contains(t, dot, `"a" ["class"="concept", "pos"="0,0!"];`)
+	assert.Contains(t, dot, `"b" ["class"="concept", "pos"="120,-80!"];`)
+	assert.Contains(t, dot, `"c" ["class"="concept", "x"="10"];`)
+}
+
+func TestRenderExport_UnknownFormat(t *testing.T) {
+	_, err := RenderExport(newFormatFixture(t), "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// ExportVersion is the format version written by ExportSession. Imports
+// migrate exports of earlier versions to it; see MigrateExport.
+const ExportVersion = "1.2.0"
+
+// ImportResult describes the outcome of a session import
+type ImportResult struct {
+	SessionID string `json:"session_id"`
+	// Imported counts the records restored per store
+	Imported map[string]int `json:"imported"`
+	// IDMap maps record IDs in the export to the IDs assigned on import
+	IDMap map[string]string `json:"id_map"`
+}
+
+// exportData is the typed form of SessionExport.Data
+type exportData struct {
+	Thoughts             []*types.ThoughtData             `json:"thoughts"`
+	MentalModels         []*types.MentalModelData         `json:"mental_models"`
+	StochasticAlgorithms []*types.StochasticAlgorithmData `json:"stochastic_algorithms"`
+	Decisions            []*types.DecisionData            `json:"decisions"`
+	VisualData           []*types.VisualData              `json:"visual_data"`
+	Critiques            []*types.CritiqueData            `json:"critiques"`
+	Scenarios            []*types.ScenarioData            `json:"scenarios"`
+}
+
+// DecodeSessionExport parses a serialized session export
+func DecodeSessionExport(data []byte) (*types.SessionExport, error) {
+	var export types.SessionExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid session export: %w", err)
+	}
+
+	return &export, nil
+}
+
+// ImportSession restores the records of an export into a store. Records are
+// added to targetSessionID, or to the exported session ID when it is empty.
+// Every record receives a new ID so an export can be imported alongside the
+// session it came from; references between records are rewritten to match.
+// Exports of earlier versions are migrated first.
+func ImportSession(s Store, export *types.SessionExport, targetSessionID string) (*ImportResult, error) {
+	export, err := MigrateExport(export)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := targetSessionID
+	if sessionID == "" {
+		sessionID = export.SessionID
+	}
+	if sessionID == "" {
+		return nil, errorf(ErrInvalidArgument, "session ID required: export has none and no target was given")
+	}
+
+	data, err := decodeExportData(export.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the thought limit up front rather than failing part way through
+	stats, err := s.GetSessionStats(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Thoughts) > stats.RemainingThoughts {
+		return nil, errorf(ErrThoughtLimit, "import of %d thoughts exceeds the %d remaining for session %s",
+			len(data.Thoughts), stats.RemainingThoughts, sessionID)
+	}
+
+	result := &ImportResult{
+		SessionID: sessionID,
+		Imported:  make(map[string]int),
+		IDMap:     make(map[string]string),
+	}
+
+	// remap clears a record's ID so the store assigns a fresh one; the
+	// returned function records the mapping once the record is stored
+	remap := func(id *string) func() {
+		oldID := *id
+		*id = ""
+		return func() {
+			if oldID != "" {
+				result.IDMap[oldID] = *id
+			}
+		}
+	}
+
+	for _, thought := range data.Thoughts {
+		done := remap(&thought.ID)
+		if err := s.AddThought(sessionID, thought); err != nil {
+			return result, fmt.Errorf("failed to import thought: %w", err)
+		}
+		done()
+		result.Imported[KindThoughts]++
+	}
+
+	for _, model := range data.MentalModels {
+		done := remap(&model.ID)
+		if err := s.AddMentalModel(sessionID, model); err != nil {
+			return result, fmt.Errorf("failed to import mental model: %w", err)
+		}
+		done()
+		result.Imported[KindMentalModels]++
+	}
+
+	for _, algorithm := range data.StochasticAlgorithms {
+		done := remap(&algorithm.ID)
+		if err := s.AddStochasticAlgorithm(sessionID, algorithm); err != nil {
+			return result, fmt.Errorf("failed to import stochastic algorithm: %w", err)
+		}
+		done()
+		result.Imported[KindStochasticAlgorithms]++
+	}
+
+	for _, decision := range data.Decisions {
+		done := remap(&decision.ID)
+		if err := s.AddDecision(sessionID, decision); err != nil {
+			return result, fmt.Errorf("failed to import decision: %w", err)
+		}
+		done()
+		result.Imported[KindDecisions]++
+	}
+
+	for _, visual := range data.VisualData {
+		done := remap(&visual.ID)
+		if err := s.AddVisualData(sessionID, visual); err != nil {
+			return result, fmt.Errorf("failed to import visual data: %w", err)
+		}
+		done()
+		result.Imported[KindVisualData]++
+	}
+
+	for _, scenario := range data.Scenarios {
+		done := remap(&scenario.ID)
+		if err := s.AddScenario(sessionID, scenario); err != nil {
+			return result, fmt.Errorf("failed to import scenario analysis: %w", err)
+		}
+		done()
+		result.Imported[KindScenarios]++
+	}
+
+	// Critiques reference the records they reviewed, so they are imported last
+	for _, critique := range data.Critiques {
+		for i, targetID := range critique.TargetIDs {
+			if newID, ok := result.IDMap[targetID]; ok {
+				critique.TargetIDs[i] = newID
+			}
+		}
+
+		done := remap(&critique.ID)
+		if err := s.AddCritique(sessionID, critique); err != nil {
+			return result, fmt.Errorf("failed to import critique: %w", err)
+		}
+		done()
+		result.Imported[KindCritiques]++
+	}
+
+	return result, nil
+}
+
+// decodeExportData converts the loosely typed export data into typed records
+func decodeExportData(data interface{}) (*exportData, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session export data: %w", err)
+	}
+
+	var decoded exportData
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid session export data: %w", err)
+	}
+
+	return &decoded, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportSession_RemapsCritiqueTargets(t *testing.T) {
+	source := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, source.AddDecision("s1", &types.DecisionData{ID: "d1", DecisionStatement: "Pick a database"}))
+	require.NoError(t, source.AddCritique("s1", &types.CritiqueData{TargetType: "decisions", TargetIDs: []string{"d1"}}))
+
+	export, err := source.ExportSession("s1")
+	require.NoError(t, err)
+
+	target := NewMemoryStore(config.DefaultConfig())
+	result, err := ImportSession(target, export, "")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", result.SessionID)
+
+	decisions, err := target.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, result.IDMap["d1"], decisions[0].ID)
+	assert.NotEqual(t, "d1", decisions[0].ID)
+
+	critiques, err := target.GetCritiques("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, critiques, 1)
+	assert.Equal(t, []string{decisions[0].ID}, critiques[0].TargetIDs)
+}
+
+func TestImportSession_EnforcesThoughtLimit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxThoughtsPerSession = 1
+
+	export := &types.SessionExport{
+		Version:   ExportVersion,
+		SessionID: "s1",
+		Data: map[string]interface{}{
+			"thoughts": []*types.ThoughtData{{Thought: "one"}, {Thought: "two"}},
+		},
+	}
+
+	store := NewMemoryStore(cfg)
+	_, err := ImportSession(store, export, "")
+	assert.Error(t, err)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+}
+
+func TestImportSession_RestoresScenarioAnalyses(t *testing.T) {
+	source := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, source.AddScenario("s1", &types.ScenarioData{
+		ID:            "a1",
+		Title:         "Expand or hold",
+		Scenarios:     []types.Scenario{{Name: "boom", Probability: 0.4}, {Name: "bust", Probability: 0.6}},
+		Options:       []string{"expand", "hold"},
+		RobustOptions: []string{"hold"},
+	}))
+
+	export, err := source.ExportSession("s1")
+	require.NoError(t, err)
+
+	target := NewMemoryStore(config.DefaultConfig())
+	result, err := ImportSession(target, export, "s2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{KindScenarios: 1}, result.Imported)
+
+	scenarios, err := target.GetScenarios("s2", nil)
+	require.NoError(t, err)
+	require.Len(t, scenarios, 1)
+	assert.Equal(t, result.IDMap["a1"], scenarios[0].ID)
+	assert.Equal(t, "s2", scenarios[0].SessionID)
+	assert.Equal(t, []string{"hold"}, scenarios[0].RobustOptions)
+
+	require.NoError(t, target.ClearSession("s2"))
+	scenarios, err = target.GetScenarios("s2", nil)
+	require.NoError(t, err)
+	assert.Empty(t, scenarios)
+}
@@ -0,0 +1,573 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Journal operations, also the types of published events
+const (
+	OpCreateSession             = "create_session"
+	OpClearSession              = "clear_session"
+	OpArchiveSession            = "archive_session"
+	OpRestoreSession            = "restore_session"
+	OpAddThought                = "add_thought"
+	OpAddMentalModel            = "add_mental_model"
+	OpAddStochasticAlgorithm    = "add_stochastic_algorithm"
+	OpAddDecision               = "add_decision"
+	OpAddVisualData             = "add_visual_data"
+	OpAddCritique               = "add_critique"
+	OpAddScenario               = "add_scenario"
+	OpUpdateThought             = "update_thought"
+	OpUpdateMentalModel         = "update_mental_model"
+	OpUpdateStochasticAlgorithm = "update_stochastic_algorithm"
+	OpUpdateDecision            = "update_decision"
+	OpUpdateVisualData          = "update_visual_data"
+	OpTransaction               = "transaction"
+)
+
+// journalTxWrite is one write of a journaled transaction, which records its
+// writes as the entry's record
+type journalTxWrite struct {
+	Op     string          `json:"op"`
+	Record json.RawMessage `json:"record"`
+}
+
+// JournalEntry is a single operation recorded in the journal
+type JournalEntry struct {
+	Seq       uint64          `json:"seq"`
+	Time      time.Time       `json:"time"`
+	Op        string          `json:"op"`
+	SessionID string          `json:"session_id"`
+	Record    json.RawMessage `json:"record,omitempty"`
+}
+
+// Journal is an append-only log of store operations, one JSON entry per line
+type Journal struct {
+	file *os.File
+	seq  uint64
+	mu   sync.Mutex
+}
+
+// JournalPath returns the journal file location for the configuration
+func JournalPath(cfg *config.Config) string {
+	if cfg.JournalPath != "" {
+		return cfg.JournalPath
+	}
+
+	dir := cfg.PersistencePath
+	if filepath.Ext(dir) != "" {
+		dir = filepath.Dir(dir)
+	}
+	if dir == "" {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "gothink.journal")
+}
+
+// OpenJournal opens (and if necessary creates) the journal at path
+func OpenJournal(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	return &Journal{file: file}, nil
+}
+
+// Replay calls fn for every entry in the journal in order. A partially
+// written final entry, left behind by a crash, is discarded.
+func (j *Journal) Replay(fn func(entry *JournalEntry) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	offset, seq, err := readJournal(j.file, fn)
+	if err != nil {
+		return err
+	}
+	j.seq = seq
+
+	if err := j.file.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+	if _, err := j.file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return nil
+}
+
+// readJournal calls fn for every complete entry read from r and returns the
+// length of the complete entries and the last sequence number
+func readJournal(r io.Reader, fn func(entry *JournalEntry) error) (int64, uint64, error) {
+	reader := bufio.NewReader(r)
+	var offset int64
+	var seq uint64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Anything after the last newline is an incomplete write
+			return offset, seq, nil
+		}
+		if err != nil {
+			return offset, seq, fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return offset, seq, fmt.Errorf("corrupt journal entry at offset %d: %w", offset, err)
+		}
+		offset += int64(len(line))
+		seq = entry.Seq
+
+		if err := fn(&entry); err != nil {
+			return offset, seq, err
+		}
+	}
+}
+
+// Append writes an entry and syncs it to disk
+func (j *Journal) Append(op, sessionID string, record interface{}) error {
+	entry := JournalEntry{
+		Time:      time.Now(),
+		Op:        op,
+		SessionID: sessionID,
+	}
+	if record != nil {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode journal entry: %w", err)
+		}
+		entry.Record = data
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	entry.Seq = j.seq
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.file.Close()
+}
+
+// JournaledStore records every write to a journal before applying it to the
+// wrapped store, and rebuilds the store from the journal when opened.
+type JournaledStore struct {
+	Store
+	journal *Journal
+	logger  *logrus.Logger
+}
+
+// NewJournaledStore replays the journal at path into store and returns a
+// Store that journals all subsequent writes
+func NewJournaledStore(store Store, path string) (*JournaledStore, error) {
+	journal, err := OpenJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &JournaledStore{
+		Store:   store,
+		journal: journal,
+		logger:  logrus.New(),
+	}
+
+	replayed := 0
+	err = journal.Replay(func(entry *JournalEntry) error {
+		replayed++
+		s.replay(entry)
+		return nil
+	})
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"path":    path,
+		"entries": replayed,
+	}).Info("Replayed journal")
+
+	return s, nil
+}
+
+// ReplayJournal applies the journal at path to store without modifying the
+// journal, so it can read a journal that another process is appending to. A
+// missing journal is not an error.
+func ReplayJournal(store Store, path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	replayer := &JournaledStore{Store: store, logger: logrus.New()}
+	_, _, err = readJournal(file, func(entry *JournalEntry) error {
+		replayer.replay(entry)
+		return nil
+	})
+	return err
+}
+
+// replay applies a journal entry during replay, logging entries that fail
+func (s *JournaledStore) replay(entry *JournalEntry) {
+	err := s.apply(entry)
+	if err == nil || errors.Is(err, ErrDuplicateID) {
+		// Duplicates are records a durable backend already holds
+		return
+	}
+
+	// The operation failed the same way when it was first recorded
+	s.logger.WithError(err).WithFields(logrus.Fields{
+		"seq": entry.Seq,
+		"op":  entry.Op,
+	}).Warn("Skipped journal entry during replay")
+}
+
+// apply performs a journaled operation on the wrapped store
+func (s *JournaledStore) apply(entry *JournalEntry) error {
+	decode := func(record interface{}) error {
+		if err := json.Unmarshal(entry.Record, record); err != nil {
+			return fmt.Errorf("failed to decode %s record: %w", entry.Op, err)
+		}
+		return nil
+	}
+
+	switch entry.Op {
+	case OpCreateSession:
+		_, err := s.Store.CreateSession(entry.SessionID)
+		return err
+	case OpClearSession:
+		return s.Store.ClearSession(entry.SessionID)
+	case OpArchiveSession:
+		return s.Store.ArchiveSession(entry.SessionID)
+	case OpRestoreSession:
+		return s.Store.RestoreSession(entry.SessionID)
+	case OpAddThought:
+		var thought types.ThoughtData
+		if err := decode(&thought); err != nil {
+			return err
+		}
+		return s.Store.AddThought(entry.SessionID, &thought)
+	case OpAddMentalModel:
+		var model types.MentalModelData
+		if err := decode(&model); err != nil {
+			return err
+		}
+		return s.Store.AddMentalModel(entry.SessionID, &model)
+	case OpAddStochasticAlgorithm:
+		var algorithm types.StochasticAlgorithmData
+		if err := decode(&algorithm); err != nil {
+			return err
+		}
+		return s.Store.AddStochasticAlgorithm(entry.SessionID, &algorithm)
+	case OpAddDecision:
+		var decision types.DecisionData
+		if err := decode(&decision); err != nil {
+			return err
+		}
+		return s.Store.AddDecision(entry.SessionID, &decision)
+	case OpAddVisualData:
+		var visual types.VisualData
+		if err := decode(&visual); err != nil {
+			return err
+		}
+		return s.Store.AddVisualData(entry.SessionID, &visual)
+	case OpAddCritique:
+		var critique types.CritiqueData
+		if err := decode(&critique); err != nil {
+			return err
+		}
+		return s.Store.AddCritique(entry.SessionID, &critique)
+	case OpAddScenario:
+		var scenario types.ScenarioData
+		if err := decode(&scenario); err != nil {
+			return err
+		}
+		return s.Store.AddScenario(entry.SessionID, &scenario)
+	case OpUpdateThought:
+		var thought types.ThoughtData
+		if err := decode(&thought); err != nil {
+			return err
+		}
+		return s.Store.UpdateThought(entry.SessionID, thought.ID, replaceWith(&thought))
+	case OpUpdateMentalModel:
+		var model types.MentalModelData
+		if err := decode(&model); err != nil {
+			return err
+		}
+		return s.Store.UpdateMentalModel(entry.SessionID, model.ID, replaceWith(&model))
+	case OpUpdateStochasticAlgorithm:
+		var algorithm types.StochasticAlgorithmData
+		if err := decode(&algorithm); err != nil {
+			return err
+		}
+		return s.Store.UpdateStochasticAlgorithm(entry.SessionID, algorithm.ID, replaceWith(&algorithm))
+	case OpUpdateDecision:
+		var decision types.DecisionData
+		if err := decode(&decision); err != nil {
+			return err
+		}
+		return s.Store.UpdateDecision(entry.SessionID, decision.ID, replaceWith(&decision))
+	case OpUpdateVisualData:
+		var visual types.VisualData
+		if err := decode(&visual); err != nil {
+			return err
+		}
+		return s.Store.UpdateVisualData(entry.SessionID, visual.ID, replaceWith(&visual))
+	case OpTransaction:
+		var writes []journalTxWrite
+		if err := decode(&writes); err != nil {
+			return err
+		}
+		// The writes committed together, so they are replayed together
+		return s.Store.Transaction(entry.SessionID, func(tx *Tx) error {
+			for _, write := range writes {
+				write := &JournalEntry{Op: write.Op, SessionID: entry.SessionID, Record: write.Record}
+				tx.ops = append(tx.ops, &txOp{
+					op: write.Op,
+					apply: func(store Store, sessionID string) error {
+						return (&JournaledStore{Store: store}).apply(write)
+					},
+				})
+			}
+			return nil
+		})
+	default:
+		return fmt.Errorf("unknown journal operation %q", entry.Op)
+	}
+}
+
+// prepare assigns the ID and timestamp before a record is journaled so that
+// replay reproduces the same record
+func prepare(id *string, createdAt *time.Time) {
+	if *id == "" {
+		*id = generateID()
+	}
+	if createdAt.IsZero() {
+		*createdAt = time.Now()
+	}
+}
+
+// replaceWith returns an update that overwrites a record with a journaled copy
+func replaceWith[T any](record *T) func(*T) error {
+	return func(current *T) error {
+		*current = *record
+		return nil
+	}
+}
+
+// journalUpdate wraps update so the updated record is journaled before the
+// wrapped store commits it; a failed append aborts the update
+func journalUpdate[T any](s *JournaledStore, op, sessionID, id string, identity identityFunc[T], update func(*T) error) func(*T) error {
+	return func(record *T) error {
+		if err := update(record); err != nil {
+			return err
+		}
+		// Replay locates the record by the journaled ID
+		recordID, _, _ := identity(record)
+		*recordID = id
+		return s.journal.Append(op, sessionID, record)
+	}
+}
+
+// AddThought journals and adds a thought
+func (s *JournaledStore) AddThought(sessionID string, thought *types.ThoughtData) error {
+	prepare(&thought.ID, &thought.CreatedAt)
+	if err := s.journal.Append(OpAddThought, sessionID, thought); err != nil {
+		return err
+	}
+	return s.Store.AddThought(sessionID, thought)
+}
+
+// AddMentalModel journals and adds a mental model application
+func (s *JournaledStore) AddMentalModel(sessionID string, model *types.MentalModelData) error {
+	prepare(&model.ID, &model.CreatedAt)
+	if err := s.journal.Append(OpAddMentalModel, sessionID, model); err != nil {
+		return err
+	}
+	return s.Store.AddMentalModel(sessionID, model)
+}
+
+// AddStochasticAlgorithm journals and adds a stochastic algorithm result
+func (s *JournaledStore) AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error {
+	prepare(&algorithm.ID, &algorithm.CreatedAt)
+	if err := s.journal.Append(OpAddStochasticAlgorithm, sessionID, algorithm); err != nil {
+		return err
+	}
+	return s.Store.AddStochasticAlgorithm(sessionID, algorithm)
+}
+
+// AddDecision journals and adds a decision
+func (s *JournaledStore) AddDecision(sessionID string, decision *types.DecisionData) error {
+	prepare(&decision.ID, &decision.CreatedAt)
+	if err := s.journal.Append(OpAddDecision, sessionID, decision); err != nil {
+		return err
+	}
+	return s.Store.AddDecision(sessionID, decision)
+}
+
+// AddVisualData journals and adds visual data
+func (s *JournaledStore) AddVisualData(sessionID string, visual *types.VisualData) error {
+	prepare(&visual.ID, &visual.CreatedAt)
+	if err := s.journal.Append(OpAddVisualData, sessionID, visual); err != nil {
+		return err
+	}
+	return s.Store.AddVisualData(sessionID, visual)
+}
+
+// AddCritique journals and adds a critique
+func (s *JournaledStore) AddCritique(sessionID string, critique *types.CritiqueData) error {
+	prepare(&critique.ID, &critique.CreatedAt)
+	if err := s.journal.Append(OpAddCritique, sessionID, critique); err != nil {
+		return err
+	}
+	return s.Store.AddCritique(sessionID, critique)
+}
+
+// AddScenario journals and adds a scenario analysis
+func (s *JournaledStore) AddScenario(sessionID string, scenario *types.ScenarioData) error {
+	prepare(&scenario.ID, &scenario.CreatedAt)
+	if err := s.journal.Append(OpAddScenario, sessionID, scenario); err != nil {
+		return err
+	}
+	return s.Store.AddScenario(sessionID, scenario)
+}
+
+// AddBatch journals and adds each record of a batch
+func (s *JournaledStore) AddBatch(sessionID string, batch *Batch) (*BatchResult, error) {
+	return addBatch(s, sessionID, batch)
+}
+
+// UpdateThought journals and revises a thought
+func (s *JournaledStore) UpdateThought(sessionID, id string, update func(*types.ThoughtData) error) error {
+	return s.Store.UpdateThought(sessionID, id, journalUpdate(s, OpUpdateThought, sessionID, id, thoughtIdentity, update))
+}
+
+// UpdateMentalModel journals and revises a mental model application
+func (s *JournaledStore) UpdateMentalModel(sessionID, id string, update func(*types.MentalModelData) error) error {
+	return s.Store.UpdateMentalModel(sessionID, id, journalUpdate(s, OpUpdateMentalModel, sessionID, id, mentalModelIdentity, update))
+}
+
+// UpdateStochasticAlgorithm journals and revises a stochastic algorithm result
+func (s *JournaledStore) UpdateStochasticAlgorithm(sessionID, id string, update func(*types.StochasticAlgorithmData) error) error {
+	return s.Store.UpdateStochasticAlgorithm(sessionID, id, journalUpdate(s, OpUpdateStochasticAlgorithm, sessionID, id, algorithmIdentity, update))
+}
+
+// UpdateDecision journals and revises a decision
+func (s *JournaledStore) UpdateDecision(sessionID, id string, update func(*types.DecisionData) error) error {
+	return s.Store.UpdateDecision(sessionID, id, journalUpdate(s, OpUpdateDecision, sessionID, id, decisionIdentity, update))
+}
+
+// UpdateVisualData journals and revises visual data
+func (s *JournaledStore) UpdateVisualData(sessionID, id string, update func(*types.VisualData) error) error {
+	return s.Store.UpdateVisualData(sessionID, id, journalUpdate(s, OpUpdateVisualData, sessionID, id, visualIdentity, update))
+}
+
+// Transaction journals a transaction's writes as a single entry once they
+// have been applied, before the wrapped store commits them; a failed append
+// rolls the transaction back
+func (s *JournaledStore) Transaction(sessionID string, fn func(tx *Tx) error) error {
+	return s.Store.Transaction(sessionID, func(tx *Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		tx.beforeCommit(func() error {
+			writes := make([]journalTxWrite, 0, tx.Len())
+			for _, op := range tx.ops {
+				_, record := op.result()
+				data, err := json.Marshal(record)
+				if err != nil {
+					return fmt.Errorf("failed to encode journal entry: %w", err)
+				}
+				writes = append(writes, journalTxWrite{Op: op.op, Record: data})
+			}
+			return s.journal.Append(OpTransaction, sessionID, writes)
+		})
+		return nil
+	})
+}
+
+// CreateSession journals and creates a session
+func (s *JournaledStore) CreateSession(sessionID string) (*SessionData, error) {
+	if err := s.journal.Append(OpCreateSession, sessionID, nil); err != nil {
+		return nil, err
+	}
+	return s.Store.CreateSession(sessionID)
+}
+
+// ClearSession journals and clears a session
+func (s *JournaledStore) ClearSession(sessionID string) error {
+	if err := s.journal.Append(OpClearSession, sessionID, nil); err != nil {
+		return err
+	}
+	return s.Store.ClearSession(sessionID)
+}
+
+// ArchiveSession journals and archives a session
+func (s *JournaledStore) ArchiveSession(sessionID string) error {
+	if err := s.journal.Append(OpArchiveSession, sessionID, nil); err != nil {
+		return err
+	}
+	return s.Store.ArchiveSession(sessionID)
+}
+
+// RestoreSession journals and restores an archived session
+func (s *JournaledStore) RestoreSession(sessionID string) error {
+	if err := s.journal.Append(OpRestoreSession, sessionID, nil); err != nil {
+		return err
+	}
+	return s.Store.RestoreSession(sessionID)
+}
+
+// Close closes the wrapped store and the journal
+func (s *JournaledStore) Close() error {
+	storeErr := s.Store.Close()
+	if err := s.journal.Close(); err != nil {
+		return err
+	}
+	return storeErr
+}
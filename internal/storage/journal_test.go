@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournaledStore_ReplaysAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gothink.journal")
+
+	store, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+
+	thought := &types.ThoughtData{Thought: "first"}
+	require.NoError(t, store.AddThought("s1", thought))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "Pick one"}))
+	require.NoError(t, store.Close())
+
+	replayed, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	defer replayed.Close()
+
+	thoughts, err := replayed.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, thought.ID, thoughts[0].ID)
+	assert.True(t, thought.CreatedAt.Equal(thoughts[0].CreatedAt))
+
+	decisions, err := replayed.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 1)
+
+	// New writes continue the sequence after the replayed entries
+	require.NoError(t, replayed.AddThought("s1", &types.ThoughtData{Thought: "second"}))
+	assert.Equal(t, uint64(3), replayed.journal.seq)
+}
+
+func TestJournaledStore_ReplaysUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gothink.journal")
+
+	store, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+
+	model := &types.MentalModelData{ModelName: "debugging_binary_search", Problem: "Flaky test"}
+	require.NoError(t, store.AddMentalModel("s1", model))
+	require.NoError(t, store.UpdateMentalModel("s1", model.ID, func(m *types.MentalModelData) error {
+		m.Reasoning = "Fails under load"
+		return nil
+	}))
+	require.NoError(t, store.Close())
+
+	replayed, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	defer replayed.Close()
+
+	models, err := replayed.GetMentalModels("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, model.ID, models[0].ID)
+	assert.Equal(t, "Fails under load", models[0].Reasoning)
+}
+
+func TestJournal_DiscardsTornFinalEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gothink.journal")
+
+	store, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "kept"}))
+	require.NoError(t, store.Close())
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":2,"op":"add_thought","session_id":"s1","rec`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	replayed, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	require.NoError(t, replayed.AddThought("s1", &types.ThoughtData{Thought: "after crash"}))
+	require.NoError(t, replayed.Close())
+
+	final, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	defer final.Close()
+
+	thoughts, err := final.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 2)
+	assert.Equal(t, "kept", thoughts[0].Thought)
+	assert.Equal(t, "after crash", thoughts[1].Thought)
+}
+
+func TestReplayJournal_LeavesJournalUntouched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gothink.journal")
+
+	store, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "first"}))
+
+	// A write in progress in the other process
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":2,"op":"add_th`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	reader := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, ReplayJournal(reader, path))
+
+	thoughts, err := reader.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
@@ -0,0 +1,679 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// MemoryStore is the default Store implementation backed by in-memory maps
+type MemoryStore struct {
+	config *config.Config
+	logger *logrus.Logger
+
+	// ID and time sources (replaceable for deterministic tests)
+	newID func() string
+	now   func() time.Time
+
+	// In-memory stores (in production, these would be backed by a database)
+	thoughts             map[string]*types.ThoughtData
+	mentalModels         map[string]*types.MentalModelData
+	stochasticAlgorithms map[string]*types.StochasticAlgorithmData
+	decisions            map[string]*types.DecisionData
+	visualData           map[string]*types.VisualData
+	critiques            map[string]*types.CritiqueData
+	scenarios            map[string]*types.ScenarioData
+	sessions             map[string]*SessionData
+
+	// Per-session record IDs in insertion order, guarded by the matching store mutex
+	thoughtsBySession             map[string][]string
+	mentalModelsBySession         map[string][]string
+	stochasticAlgorithmsBySession map[string][]string
+	decisionsBySession            map[string][]string
+	visualDataBySession           map[string][]string
+	critiquesBySession            map[string][]string
+	scenariosBySession            map[string][]string
+
+	// Records and approximate bytes held per session, guarded by sessionsMutex
+	usage map[string]*sessionUsage
+
+	// Mutexes for thread safety
+	thoughtsMutex             sync.RWMutex
+	mentalModelsMutex         sync.RWMutex
+	stochasticAlgorithmsMutex sync.RWMutex
+	decisionsMutex            sync.RWMutex
+	visualDataMutex           sync.RWMutex
+	critiquesMutex            sync.RWMutex
+	scenariosMutex            sync.RWMutex
+	sessionsMutex             sync.RWMutex
+
+	// Eviction counters reported by StorageStats
+	expiredEvictions atomic.Int64
+	quotaEvictions   atomic.Int64
+	hookFailures     atomic.Int64
+
+	// Background tasks (expiry sweeper, snapshots), guarded by backgroundMutex
+	evictionHook    EvictionHook
+	sweeping        bool
+	snapshotPath    string
+	stopBackground  chan struct{}
+	background      sync.WaitGroup
+	backgroundMutex sync.Mutex
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore(cfg *config.Config) *MemoryStore {
+	return &MemoryStore{
+		config:               cfg,
+		logger:               logrus.New(),
+		newID:                generateID,
+		now:                  time.Now,
+		thoughts:             make(map[string]*types.ThoughtData),
+		mentalModels:         make(map[string]*types.MentalModelData),
+		stochasticAlgorithms: make(map[string]*types.StochasticAlgorithmData),
+		decisions:            make(map[string]*types.DecisionData),
+		visualData:           make(map[string]*types.VisualData),
+		critiques:            make(map[string]*types.CritiqueData),
+		scenarios:            make(map[string]*types.ScenarioData),
+		sessions:             make(map[string]*SessionData),
+
+		thoughtsBySession:             make(map[string][]string),
+		mentalModelsBySession:         make(map[string][]string),
+		stochasticAlgorithmsBySession: make(map[string][]string),
+		decisionsBySession:            make(map[string][]string),
+		visualDataBySession:           make(map[string][]string),
+		critiquesBySession:            make(map[string][]string),
+		scenariosBySession:            make(map[string][]string),
+		usage:                         make(map[string]*sessionUsage),
+	}
+}
+
+// SetIDGenerator replaces the function used to generate record IDs
+func (s *MemoryStore) SetIDGenerator(newID func() string) {
+	s.newID = newID
+}
+
+// SetClock replaces the function used to timestamp records and sessions
+func (s *MemoryStore) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// ============================================================================
+// Thought Management
+// ============================================================================
+
+// AddThought adds a new thought to storage
+func (s *MemoryStore) AddThought(sessionID string, thought *types.ThoughtData) error {
+	defer s.enforceGlobalQuota(sessionID)
+	s.thoughtsMutex.Lock()
+	defer s.thoughtsMutex.Unlock()
+
+	// Generate ID if not provided; IDs are never reused, even across sessions
+	if thought.ID == "" {
+		thought.ID = s.newID()
+	}
+	if _, exists := s.thoughts[thought.ID]; exists {
+		return duplicateID(KindThoughts, thought.ID)
+	}
+
+	// Check the thought limit and quotas and count the thought against the session
+	if err := s.admitRecord(sessionID, KindThoughts, thought); err != nil {
+		return err
+	}
+
+	thought.SessionID = sessionID
+	if thought.CreatedAt.IsZero() {
+		thought.CreatedAt = s.now()
+	}
+
+	s.thoughtsBySession[sessionID] = append(s.thoughtsBySession[sessionID], thought.ID)
+	s.thoughts[thought.ID] = thought
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":     sessionID,
+		"thought_id":     thought.ID,
+		"thought_number": thought.ThoughtNumber,
+	}).Debug("Added thought to storage")
+
+	return nil
+}
+
+// GetThoughts retrieves all thoughts for a session
+func (s *MemoryStore) GetThoughts(sessionID string, query *Query) ([]*types.ThoughtData, error) {
+	s.thoughtsMutex.RLock()
+	defer s.thoughtsMutex.RUnlock()
+
+	var sessionThoughts []*types.ThoughtData
+	for _, id := range s.thoughtsBySession[sessionID] {
+		sessionThoughts = append(sessionThoughts, s.thoughts[id])
+	}
+
+	return applyQuery(sessionThoughts, query, thoughtCreatedAt), nil
+}
+
+// ============================================================================
+// Mental Model Management
+// ============================================================================
+
+// AddMentalModel adds a mental model application to storage
+func (s *MemoryStore) AddMentalModel(sessionID string, model *types.MentalModelData) error {
+	defer s.enforceGlobalQuota(sessionID)
+	s.mentalModelsMutex.Lock()
+	defer s.mentalModelsMutex.Unlock()
+
+	if model.ID == "" {
+		model.ID = s.newID()
+	}
+	if _, exists := s.mentalModels[model.ID]; exists {
+		return duplicateID(KindMentalModels, model.ID)
+	}
+
+	if err := s.admitRecord(sessionID, KindMentalModels, model); err != nil {
+		return err
+	}
+
+	model.SessionID = sessionID
+	if model.CreatedAt.IsZero() {
+		model.CreatedAt = s.now()
+	}
+
+	s.mentalModelsBySession[sessionID] = append(s.mentalModelsBySession[sessionID], model.ID)
+	s.mentalModels[model.ID] = model
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"model_id":   model.ID,
+		"model_name": model.ModelName,
+	}).Debug("Added mental model to storage")
+
+	return nil
+}
+
+// GetMentalModels retrieves all mental models for a session
+func (s *MemoryStore) GetMentalModels(sessionID string, query *Query) ([]*types.MentalModelData, error) {
+	s.mentalModelsMutex.RLock()
+	defer s.mentalModelsMutex.RUnlock()
+
+	var sessionModels []*types.MentalModelData
+	for _, id := range s.mentalModelsBySession[sessionID] {
+		sessionModels = append(sessionModels, s.mentalModels[id])
+	}
+
+	return applyQuery(sessionModels, query, mentalModelCreatedAt), nil
+}
+
+// ============================================================================
+// Stochastic Algorithm Management
+// ============================================================================
+
+// AddStochasticAlgorithm adds a stochastic algorithm result to storage
+func (s *MemoryStore) AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error {
+	defer s.enforceGlobalQuota(sessionID)
+	s.stochasticAlgorithmsMutex.Lock()
+	defer s.stochasticAlgorithmsMutex.Unlock()
+
+	if algorithm.ID == "" {
+		algorithm.ID = s.newID()
+	}
+	if _, exists := s.stochasticAlgorithms[algorithm.ID]; exists {
+		return duplicateID(KindStochasticAlgorithms, algorithm.ID)
+	}
+
+	if err := s.admitRecord(sessionID, KindStochasticAlgorithms, algorithm); err != nil {
+		return err
+	}
+
+	algorithm.SessionID = sessionID
+	if algorithm.CreatedAt.IsZero() {
+		algorithm.CreatedAt = s.now()
+	}
+
+	s.stochasticAlgorithmsBySession[sessionID] = append(s.stochasticAlgorithmsBySession[sessionID], algorithm.ID)
+	s.stochasticAlgorithms[algorithm.ID] = algorithm
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":   sessionID,
+		"algorithm_id": algorithm.ID,
+		"algorithm":    algorithm.Algorithm,
+	}).Debug("Added stochastic algorithm to storage")
+
+	return nil
+}
+
+// GetStochasticAlgorithms retrieves all stochastic algorithms for a session
+func (s *MemoryStore) GetStochasticAlgorithms(sessionID string, query *Query) ([]*types.StochasticAlgorithmData, error) {
+	s.stochasticAlgorithmsMutex.RLock()
+	defer s.stochasticAlgorithmsMutex.RUnlock()
+
+	var sessionAlgorithms []*types.StochasticAlgorithmData
+	for _, id := range s.stochasticAlgorithmsBySession[sessionID] {
+		sessionAlgorithms = append(sessionAlgorithms, s.stochasticAlgorithms[id])
+	}
+
+	return applyQuery(sessionAlgorithms, query, algorithmCreatedAt), nil
+}
+
+// ============================================================================
+// Decision Management
+// ============================================================================
+
+// AddDecision adds a decision framework to storage
+func (s *MemoryStore) AddDecision(sessionID string, decision *types.DecisionData) error {
+	defer s.enforceGlobalQuota(sessionID)
+	s.decisionsMutex.Lock()
+	defer s.decisionsMutex.Unlock()
+
+	if decision.ID == "" {
+		decision.ID = s.newID()
+	}
+	if _, exists := s.decisions[decision.ID]; exists {
+		return duplicateID(KindDecisions, decision.ID)
+	}
+
+	if err := s.admitRecord(sessionID, KindDecisions, decision); err != nil {
+		return err
+	}
+
+	decision.SessionID = sessionID
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = s.now()
+	}
+
+	s.decisionsBySession[sessionID] = append(s.decisionsBySession[sessionID], decision.ID)
+	s.decisions[decision.ID] = decision
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":    sessionID,
+		"decision_id":   decision.ID,
+		"analysis_type": decision.AnalysisType,
+	}).Debug("Added decision to storage")
+
+	return nil
+}
+
+// GetDecisions retrieves all decisions for a session
+func (s *MemoryStore) GetDecisions(sessionID string, query *Query) ([]*types.DecisionData, error) {
+	s.decisionsMutex.RLock()
+	defer s.decisionsMutex.RUnlock()
+
+	var sessionDecisions []*types.DecisionData
+	for _, id := range s.decisionsBySession[sessionID] {
+		sessionDecisions = append(sessionDecisions, s.decisions[id])
+	}
+
+	return applyQuery(sessionDecisions, query, decisionCreatedAt), nil
+}
+
+// ============================================================================
+// Visual Data Management
+// ============================================================================
+
+// AddVisualData adds visual data to storage
+func (s *MemoryStore) AddVisualData(sessionID string, visual *types.VisualData) error {
+	defer s.enforceGlobalQuota(sessionID)
+	s.visualDataMutex.Lock()
+	defer s.visualDataMutex.Unlock()
+
+	if visual.ID == "" {
+		visual.ID = s.newID()
+	}
+	if _, exists := s.visualData[visual.ID]; exists {
+		return duplicateID(KindVisualData, visual.ID)
+	}
+
+	if err := s.admitRecord(sessionID, KindVisualData, visual); err != nil {
+		return err
+	}
+
+	visual.SessionID = sessionID
+	if visual.CreatedAt.IsZero() {
+		visual.CreatedAt = s.now()
+	}
+
+	s.visualDataBySession[sessionID] = append(s.visualDataBySession[sessionID], visual.ID)
+	s.visualData[visual.ID] = visual
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":   sessionID,
+		"visual_id":    visual.ID,
+		"diagram_type": visual.DiagramType,
+	}).Debug("Added visual data to storage")
+
+	return nil
+}
+
+// GetVisualData retrieves all visual data for a session
+func (s *MemoryStore) GetVisualData(sessionID string, query *Query) ([]*types.VisualData, error) {
+	s.visualDataMutex.RLock()
+	defer s.visualDataMutex.RUnlock()
+
+	var sessionVisuals []*types.VisualData
+	for _, id := range s.visualDataBySession[sessionID] {
+		sessionVisuals = append(sessionVisuals, s.visualData[id])
+	}
+
+	return applyQuery(sessionVisuals, query, visualCreatedAt), nil
+}
+
+// ============================================================================
+// Critique Management
+// ============================================================================
+
+// AddCritique adds an automated critique to storage
+func (s *MemoryStore) AddCritique(sessionID string, critique *types.CritiqueData) error {
+	defer s.enforceGlobalQuota(sessionID)
+	s.critiquesMutex.Lock()
+	defer s.critiquesMutex.Unlock()
+
+	if critique.ID == "" {
+		critique.ID = s.newID()
+	}
+	if _, exists := s.critiques[critique.ID]; exists {
+		return duplicateID(KindCritiques, critique.ID)
+	}
+
+	if err := s.admitRecord(sessionID, KindCritiques, critique); err != nil {
+		return err
+	}
+
+	critique.SessionID = sessionID
+	if critique.CreatedAt.IsZero() {
+		critique.CreatedAt = s.now()
+	}
+
+	s.critiquesBySession[sessionID] = append(s.critiquesBySession[sessionID], critique.ID)
+	s.critiques[critique.ID] = critique
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":  sessionID,
+		"critique_id": critique.ID,
+		"target_type": critique.TargetType,
+	}).Debug("Added critique to storage")
+
+	return nil
+}
+
+// GetCritiques retrieves all critiques for a session
+func (s *MemoryStore) GetCritiques(sessionID string, query *Query) ([]*types.CritiqueData, error) {
+	s.critiquesMutex.RLock()
+	defer s.critiquesMutex.RUnlock()
+
+	var sessionCritiques []*types.CritiqueData
+	for _, id := range s.critiquesBySession[sessionID] {
+		sessionCritiques = append(sessionCritiques, s.critiques[id])
+	}
+
+	return applyQuery(sessionCritiques, query, critiqueCreatedAt), nil
+}
+
+// ============================================================================
+// Scenario Management
+// ============================================================================
+
+// AddScenario adds a scenario analysis to storage
+func (s *MemoryStore) AddScenario(sessionID string, scenario *types.ScenarioData) error {
+	defer s.enforceGlobalQuota(sessionID)
+	s.scenariosMutex.Lock()
+	defer s.scenariosMutex.Unlock()
+
+	if scenario.ID == "" {
+		scenario.ID = s.newID()
+	}
+	if _, exists := s.scenarios[scenario.ID]; exists {
+		return duplicateID(KindScenarios, scenario.ID)
+	}
+
+	if err := s.admitRecord(sessionID, KindScenarios, scenario); err != nil {
+		return err
+	}
+
+	scenario.SessionID = sessionID
+	if scenario.CreatedAt.IsZero() {
+		scenario.CreatedAt = s.now()
+	}
+
+	s.scenariosBySession[sessionID] = append(s.scenariosBySession[sessionID], scenario.ID)
+	s.scenarios[scenario.ID] = scenario
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":  sessionID,
+		"scenario_id": scenario.ID,
+		"scenarios":   len(scenario.Scenarios),
+	}).Debug("Added scenario analysis to storage")
+
+	return nil
+}
+
+// GetScenarios retrieves all scenario analyses for a session
+func (s *MemoryStore) GetScenarios(sessionID string, query *Query) ([]*types.ScenarioData, error) {
+	s.scenariosMutex.RLock()
+	defer s.scenariosMutex.RUnlock()
+
+	var sessionScenarios []*types.ScenarioData
+	for _, id := range s.scenariosBySession[sessionID] {
+		sessionScenarios = append(sessionScenarios, s.scenarios[id])
+	}
+
+	return applyQuery(sessionScenarios, query, scenarioCreatedAt), nil
+}
+
+// AddBatch adds the records of a batch to a session
+func (s *MemoryStore) AddBatch(sessionID string, batch *Batch) (*BatchResult, error) {
+	return addBatch(s, sessionID, batch)
+}
+
+// ============================================================================
+// Session Management
+// ============================================================================
+
+// GetSession retrieves session data
+func (s *MemoryStore) GetSession(sessionID string) (*SessionData, error) {
+	s.sessionsMutex.RLock()
+	defer s.sessionsMutex.RUnlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil, errorf(ErrSessionNotFound, "session %s not found", sessionID)
+	}
+
+	return session.clone(), nil
+}
+
+// ListSessions returns the IDs of all sessions in order
+func (s *MemoryStore) ListSessions() ([]string, error) {
+	s.sessionsMutex.RLock()
+	defer s.sessionsMutex.RUnlock()
+
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// CreateSession creates a new session
+func (s *MemoryStore) CreateSession(sessionID string) (*SessionData, error) {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session := &SessionData{
+		ID:                sessionID,
+		CreatedAt:         s.now(),
+		LastAccessedAt:    s.now(),
+		ThoughtCount:      0,
+		ToolsUsed:         []string{},
+		TotalOperations:   0,
+		IsActive:          true,
+		RemainingThoughts: s.config.MaxThoughtsPerSession,
+	}
+
+	s.sessions[sessionID] = session
+
+	s.logger.WithField("session_id", sessionID).Debug("Created new session")
+
+	return session, nil
+}
+
+// ClearSession removes a session and all of its records
+func (s *MemoryStore) ClearSession(sessionID string) error {
+	if !s.removeSession(sessionID, nil) {
+		return errorf(ErrSessionNotFound, "session %s not found", sessionID)
+	}
+
+	s.logger.WithField("session_id", sessionID).Debug("Cleared session")
+
+	return nil
+}
+
+// ArchiveSession marks a session archived. Its records stay retrievable but
+// no new records are accepted and it is never expired.
+func (s *MemoryStore) ArchiveSession(sessionID string) error {
+	return s.setArchived(sessionID, true)
+}
+
+// RestoreSession returns an archived session to normal use
+func (s *MemoryStore) RestoreSession(sessionID string) error {
+	return s.setArchived(sessionID, false)
+}
+
+// setArchived changes a session's archive state
+func (s *MemoryStore) setArchived(sessionID string, archived bool) error {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return errorf(ErrSessionNotFound, "session %s not found", sessionID)
+	}
+	if err := archiveTransition(session, archived, s.now()); err != nil {
+		return err
+	}
+	session.LastAccessedAt = s.now()
+
+	return nil
+}
+
+// getSession gets or creates a session
+func (s *MemoryStore) getSession(sessionID string) *SessionData {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		session = &SessionData{
+			ID:                sessionID,
+			CreatedAt:         s.now(),
+			LastAccessedAt:    s.now(),
+			ThoughtCount:      0,
+			ToolsUsed:         []string{},
+			TotalOperations:   0,
+			IsActive:          true,
+			RemainingThoughts: s.config.MaxThoughtsPerSession,
+		}
+		s.sessions[sessionID] = session
+	}
+
+	return session
+}
+
+// touchSession records activity on a session, creating it if needed.
+// Archived sessions are read-only and reject changes.
+func (s *MemoryStore) touchSession(sessionID string) error {
+	session := s.getSession(sessionID)
+
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	if session.Archived {
+		return errorf(ErrSessionArchived, "session %s is archived", sessionID)
+	}
+	session.LastAccessedAt = s.now()
+	session.IsActive = true
+
+	return nil
+}
+
+// GetSessionStats retrieves comprehensive session statistics
+func (s *MemoryStore) GetSessionStats(sessionID string) (*types.SessionStatistics, error) {
+	session := s.getSession(sessionID)
+
+	// Read the counters under the lock; records may be added concurrently
+	s.sessionsMutex.RLock()
+	session = session.clone()
+	s.sessionsMutex.RUnlock()
+
+	stats, err := buildSessionStats(s, s.config, session)
+	if err != nil {
+		return nil, err
+	}
+	stats.StoragePressure = s.storagePressure(sessionID)
+
+	return stats, nil
+}
+
+// ============================================================================
+// Export/Import
+// ============================================================================
+
+// ExportSession exports session data
+func (s *MemoryStore) ExportSession(sessionID string) (*types.SessionExport, error) {
+	return buildSessionExport(s, sessionID)
+}
+
+// Ping always succeeds: the store is held in memory
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close stops background tasks and writes a final snapshot if snapshots are enabled
+func (s *MemoryStore) Close() error {
+	s.backgroundMutex.Lock()
+	stop, path := s.stopBackground, s.snapshotPath
+	s.stopBackground, s.sweeping, s.snapshotPath = nil, false, ""
+	s.backgroundMutex.Unlock()
+
+	if stop != nil {
+		close(stop)
+		s.background.Wait()
+	}
+
+	if path != "" {
+		return s.SaveSnapshot(path)
+	}
+
+	return nil
+}
+
+// runEvery calls fn every interval on a background goroutine until the store
+// is closed; callers must hold backgroundMutex
+func (s *MemoryStore) runEvery(interval time.Duration, fn func()) {
+	if s.stopBackground == nil {
+		s.stopBackground = make(chan struct{})
+	}
+	stop := s.stopBackground
+
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
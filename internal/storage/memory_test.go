@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_RecordsAreSessionScoped(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "first"}))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "second"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "elsewhere"}))
+	require.NoError(t, store.AddDecision("s2", &types.DecisionData{DecisionStatement: "elsewhere"}))
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 2)
+	assert.Equal(t, "first", thoughts[0].Thought)
+	assert.Equal(t, "second", thoughts[1].Thought)
+	assert.Equal(t, "s1", thoughts[0].SessionID)
+
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+
+	stats, err := store.GetSessionStats("s2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.ThoughtCount)
+	assert.Equal(t, 2, stats.TotalOperations)
+	assert.Equal(t, 99, stats.RemainingThoughts)
+}
+
+func TestMemoryStore_ThoughtLimitIsPerSession(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxThoughtsPerSession = 1
+	store := NewMemoryStore(cfg)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "only"}))
+	assert.Error(t, store.AddThought("s1", &types.ThoughtData{Thought: "one too many"}))
+	assert.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "different session"}))
+}
+
+func TestMemoryStore_ClearSession(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "gone"}))
+	require.NoError(t, store.AddCritique("s1", &types.CritiqueData{Summary: "gone"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "kept"}))
+
+	require.NoError(t, store.ClearSession("s1"))
+	assert.Error(t, store.ClearSession("s1"))
+
+	_, err := store.GetSession("s1")
+	assert.Error(t, err)
+	critiques, err := store.GetCritiques("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, critiques)
+
+	// A cleared session starts again from an empty thought budget
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "fresh"}))
+	stats, err := store.GetSessionStats("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.ThoughtCount)
+
+	thoughts, err := store.GetThoughts("s2", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
+
+func TestMemoryStore_ArchiveSession(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "kept"}))
+
+	require.NoError(t, store.ArchiveSession("s1"))
+	assert.Error(t, store.ArchiveSession("s1"))
+	assert.Error(t, store.AddThought("s1", &types.ThoughtData{Thought: "rejected"}))
+	assert.Error(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "rejected"}))
+
+	// Archived records stay retrievable
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+	stats, err := store.GetSessionStats("s1")
+	require.NoError(t, err)
+	assert.True(t, stats.IsArchived)
+	assert.False(t, stats.IsActive)
+
+	require.NoError(t, store.RestoreSession("s1"))
+	assert.Error(t, store.RestoreSession("s1"))
+	assert.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "accepted"}))
+}
+
+func TestMemoryStore_TracksToolOperations(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "one"}))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "two"}))
+	require.NoError(t, store.AddStochasticAlgorithm("s1", &types.StochasticAlgorithmData{Algorithm: "mcts"}))
+	require.NoError(t, store.AddMentalModel("s1", &types.MentalModelData{ModelName: types.DebuggingModelPrefix + "bisect"}))
+
+	// Rejected writes are not counted
+	require.NoError(t, store.ArchiveSession("s1"))
+	assert.Error(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "rejected"}))
+
+	stats, err := store.GetSessionStats("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.TotalOperations)
+	assert.Equal(t, []string{"sequential-thinking", "stochastic-mcts", "debugging-approach"}, stats.ToolsUsed)
+	assert.Equal(t, map[string]int{
+		"sequential-thinking": 2,
+		"stochastic-mcts":     1,
+		"debugging-approach":  1,
+	}, stats.ToolCounts)
+
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, session.TotalOperations)
+}
+
+func TestMemoryStore_RejectsDuplicateIDs(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "first"}))
+	assert.ErrorIs(t, store.AddThought("s2", &types.ThoughtData{ID: "t1", Thought: "copy"}), ErrDuplicateID)
+
+	// A colliding generator is caught too
+	store.SetIDGenerator(func() string { return "m1" })
+	require.NoError(t, store.AddMentalModel("s1", &types.MentalModelData{ModelName: "first_principles"}))
+	assert.ErrorIs(t, store.AddMentalModel("s1", &types.MentalModelData{ModelName: "occams_razor"}), ErrDuplicateID)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "first", thoughts[0].Thought)
+
+	stats, err := store.GetSessionStats("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.StoragePressure.SessionRecords)
+}
+
+func TestMemoryStore_GeneratesUniqueIDsConcurrently(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "parallel"}))
+			}
+		}()
+	}
+	wg.Wait()
+
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 400)
+
+	seen := make(map[string]bool)
+	for _, decision := range decisions {
+		parsed, err := uuid.Parse(decision.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), parsed.Version())
+		assert.False(t, seen[decision.ID])
+		seen[decision.ID] = true
+	}
+}
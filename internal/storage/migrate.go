@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// exportMigration upgrades the data of an export written at one version to
+// the next version
+type exportMigration struct {
+	to      string
+	migrate func(export *types.SessionExport, data map[string]interface{}) error
+}
+
+// exportMigrations maps each earlier export version to the migration that
+// upgrades it. Adding a version means bumping ExportVersion and registering a
+// migration from the previous one here.
+var exportMigrations = map[string]exportMigration{
+	"1.0.0": {to: "1.1.0", migrate: migrateSessionScope},
+	"1.1.0": {to: "1.2.0", migrate: migrateScenarios},
+}
+
+// ExportVersions returns every export version MigrateExport accepts, oldest first
+func ExportVersions() []string {
+	versions := []string{ExportVersion}
+	for version := range exportMigrations {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// MigrateExport returns the export upgraded to ExportVersion by applying the
+// migration of each version in turn; an export at the current version is
+// returned as is. Exports without a version, or with one this server does not
+// know, such as those written by a newer server, are rejected.
+func MigrateExport(export *types.SessionExport) (*types.SessionExport, error) {
+	if export.Version == "" {
+		return nil, errorf(ErrInvalidArgument, "session export has no version")
+	}
+
+	version := strings.TrimPrefix(export.Version, "v")
+	if version == ExportVersion {
+		return export, nil
+	}
+	if _, known := exportMigrations[version]; !known {
+		return nil, errorf(ErrInvalidArgument, "unsupported session export version %s (supported: %s)",
+			export.Version, strings.Join(ExportVersions(), ", "))
+	}
+
+	raw, err := json.Marshal(export.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session export data: %w", err)
+	}
+	data := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("invalid session export data: %w", err)
+	}
+
+	for version != ExportVersion {
+		migration := exportMigrations[version]
+		if err := migration.migrate(export, data); err != nil {
+			return nil, fmt.Errorf("failed to migrate session export from version %s to %s: %w", version, migration.to, err)
+		}
+		version = migration.to
+	}
+
+	migrated := *export
+	migrated.Version = ExportVersion
+	migrated.Data = data
+	migrated.Metadata = make(map[string]interface{}, len(export.Metadata)+1)
+	for key, value := range export.Metadata {
+		migrated.Metadata[key] = value
+	}
+	migrated.Metadata["migrated_from"] = export.Version
+
+	return &migrated, nil
+}
+
+// migrateSessionScope upgrades 1.0.0 exports, which predate critiques and
+// whose records do not name their session
+func migrateSessionScope(export *types.SessionExport, data map[string]interface{}) error {
+	if _, exists := data[KindCritiques]; !exists {
+		data[KindCritiques] = []interface{}{}
+	}
+
+	for _, kind := range []string{KindThoughts, KindMentalModels, KindStochasticAlgorithms, KindDecisions, KindVisualData} {
+		records, _ := data[kind].([]interface{})
+		for _, record := range records {
+			fields, ok := record.(map[string]interface{})
+			if !ok {
+				return errorf(ErrInvalidArgument, "%s record is not an object", kind)
+			}
+			if sessionID, _ := fields["session_id"].(string); sessionID == "" {
+				fields["session_id"] = export.SessionID
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateScenarios upgrades 1.1.0 exports, which predate scenario analyses
+func migrateScenarios(export *types.SessionExport, data map[string]interface{}) error {
+	if _, exists := data[KindScenarios]; !exists {
+		data[KindScenarios] = []interface{}{}
+	}
+
+	return nil
+}
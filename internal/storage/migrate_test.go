@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyExport is a session export as written by version 1.0.0, before
+// records named their session and before critiques existed
+const legacyExport = `{
+	"version": "1.0.0",
+	"timestamp": "2025-01-01T00:00:00Z",
+	"session_id": "legacy",
+	"session_type": "hybrid",
+	"data": {
+		"thoughts": [{"id": "1735689600000000000-0", "thought": "Define the problem", "thought_number": 1, "total_thoughts": 1, "created_at": "2025-01-01T00:00:00Z"}],
+		"mental_models": [],
+		"stochastic_algorithms": [],
+		"decisions": [{"id": "1735689600000000001-1", "decision_statement": "Pick one", "created_at": "2025-01-01T00:00:01Z"}],
+		"visual_data": []
+	},
+	"metadata": {"version": "0.1.0"}
+}`
+
+func TestMigrateExport_UpgradesLegacyExports(t *testing.T) {
+	export, err := DecodeSessionExport([]byte(legacyExport))
+	require.NoError(t, err)
+
+	migrated, err := MigrateExport(export)
+	require.NoError(t, err)
+	assert.Equal(t, ExportVersion, migrated.Version)
+	assert.Equal(t, "1.0.0", migrated.Metadata["migrated_from"])
+	assert.Equal(t, "1.0.0", export.Version, "the original export is left unchanged")
+
+	data, err := decodeExportData(migrated.Data)
+	require.NoError(t, err)
+	require.Len(t, data.Thoughts, 1)
+	assert.Equal(t, "legacy", data.Thoughts[0].SessionID)
+	assert.NotNil(t, data.Critiques)
+
+	store := NewMemoryStore(config.DefaultConfig())
+	result, err := ImportSession(store, export, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{KindThoughts: 1, KindDecisions: 1}, result.Imported)
+}
+
+func TestMigrateExport_CurrentVersionUnchanged(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	export, err := store.ExportSession("s1")
+	require.NoError(t, err)
+
+	migrated, err := MigrateExport(export)
+	require.NoError(t, err)
+	assert.Same(t, export, migrated)
+}
+
+func TestMigrateExport_RejectsUnknownVersions(t *testing.T) {
+	for _, version := range []string{"", "0.9.0", "1.3.0", "2.0.0"} {
+		export, err := DecodeSessionExport([]byte(`{"version": "` + version + `", "session_id": "s1", "data": {}}`))
+		require.NoError(t, err)
+
+		_, err = MigrateExport(export)
+		assert.Error(t, err, version)
+	}
+
+	export, err := DecodeSessionExport([]byte(`{"version": "3.0.0", "session_id": "s1", "data": {}}`))
+	require.NoError(t, err)
+	_, err = MigrateExport(export)
+	assert.ErrorContains(t, err, "unsupported session export version 3.0.0 (supported: 1.0.0, 1.1.0, 1.2.0)")
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rainmana/gothink/internal/flowchart"
+)
+
+// ============================================================================
+// PlantUML
+// ============================================================================
+
+// mindMapDiagramType is the diagram type mind maps are recorded under
+const mindMapDiagramType = "mindMap"
+
+// renderPlantUML writes d as PlantUML: a flowchart as an activity diagram, a
+// mind map as a mind map, and any other diagram, or a flowchart edited out
+// of shape, as the DOT export's digraph, which PlantUML draws with Graphviz
+func renderPlantUML(d *diagram) string {
+	switch d.diagramType {
+	case flowchart.DiagramType:
+		if chart, ok := diagramChart(d); ok {
+			return flowchart.PlantUML(chart)
+		}
+	case mindMapDiagramType:
+		return plantUMLMindMap(d)
+	}
+	return "@startdot\n" + renderDiagram(d) + "@enddot\n"
+}
+
+// diagramChart reads d as a flowchart, its nodes typed by their element
+// type, reporting whether it is a valid one
+func diagramChart(d *diagram) (flowchart.Chart, bool) {
+	var chart flowchart.Chart
+	for _, element := range d.elements {
+		switch {
+		case isEdge(element):
+			chart.Connections = append(chart.Connections, flowchart.Connection{From: element.Source, To: element.Target, Label: element.Label})
+		case len(element.Contains) == 0:
+			chart.Nodes = append(chart.Nodes, flowchart.Node{ID: element.ID, Type: element.Type, Label: element.Label})
+		}
+	}
+	return chart, flowchart.Validate(chart) == nil
+}
+
+// plantUMLMindMap writes d as a PlantUML mind map, a tree: each node hangs
+// from the first node to reach it along the fewest edges, starting from the
+// nodes no edge leads to. Nodes no such node reaches start trees of their
+// own, and several trees hang from a root named for the diagram. Edges the
+// tree does not follow, and groups, are left out.
+func plantUMLMindMap(d *diagram) string {
+	var nodes []string
+	labels := make(map[string]string)
+	for _, element := range d.elements {
+		if !isEdge(element) && len(element.Contains) == 0 {
+			nodes = append(nodes, element.ID)
+			labels[element.ID] = element.Label
+			if element.Label == "" {
+				labels[element.ID] = element.ID
+			}
+		}
+	}
+	targets := make(map[string][]string)
+	led := make(map[string]bool)
+	for _, element := range d.elements {
+		_, from := labels[element.Source]
+		_, to := labels[element.Target]
+		if isEdge(element) && from && to {
+			targets[element.Source] = append(targets[element.Source], element.Target)
+			led[element.Target] = true
+		}
+	}
+
+	children := make(map[string][]string)
+	placed := make(map[string]bool, len(nodes))
+	var roots []string
+	grow := func(root string) {
+		roots = append(roots, root)
+		placed[root] = true
+		for queue := []string{root}; len(queue) > 0; queue = queue[1:] {
+			for _, target := range targets[queue[0]] {
+				if !placed[target] {
+					placed[target] = true
+					children[queue[0]] = append(children[queue[0]], target)
+					queue = append(queue, target)
+				}
+			}
+		}
+	}
+	for _, id := range nodes {
+		if !led[id] {
+			grow(id)
+		}
+	}
+	for _, id := range nodes {
+		if !placed[id] {
+			grow(id)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("@startmindmap\n")
+	var write func(id string, depth int)
+	write = func(id string, depth int) {
+		writeMindMapNode(&b, labels[id], depth)
+		for _, child := range children[id] {
+			write(child, depth+1)
+		}
+	}
+	depth := 1
+	if len(roots) > 1 {
+		writeMindMapNode(&b, d.id, 1)
+		depth = 2
+	}
+	for _, root := range roots {
+		write(root, depth)
+	}
+	b.WriteString("@endmindmap\n")
+	return b.String()
+}
+
+// writeMindMapNode writes a mind map node at depth, a label of several lines
+// in PlantUML's multiline form
+func writeMindMapNode(b *strings.Builder, label string, depth int) {
+	stars := strings.Repeat("*", depth)
+	if strings.Contains(label, "\n") {
+		fmt.Fprintf(b, "%s:%s;\n", stars, label)
+		return
+	}
+	fmt.Fprintf(b, "%s %s\n", stars, label)
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderExport_PlantUML(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	record := func(diagramID, diagramType string, elements ...types.VisualElement) {
+		require.NoError(t, store.AddVisualData("s1", &types.VisualData{
+			Operation: "create", DiagramID: diagramID, DiagramType: diagramType, Elements: elements, CreatedAt: time.Now(),
+		}))
+	}
+	node := func(id, kind, label string) types.VisualElement {
+		return types.VisualElement{ID: id, Type: kind, Label: label}
+	}
+	edge := func(source, target, label string) types.VisualElement {
+		return types.VisualElement{ID: source + "->" + target, Type: "edge", Label: label, Source: source, Target: target}
+	}
+	record("release", "flowchart",
+		node("begin", "start", ""), node("check", "decision", "Tests pass?"), node("ship", "process", "Deploy"), node("done", "end", ""),
+		edge("begin", "check", ""), edge("check", "ship", "yes"), edge("check", "done", "no"), edge("ship", "done", ""))
+	record("ideas", "mindMap",
+		node("cars", "concept", "Cars"), node("roads", "concept", "Roads"), node("tolls", "concept", "Tolls\nand fees"),
+		node("fuel", "concept", "Fuel"), node("tax", "concept", ""), node("bikes", "concept", "Bikes"),
+		edge("cars", "roads", "use"), edge("cars", "fuel", ""), edge("roads", "tolls", ""), edge("fuel", "tax", ""),
+		edge("tolls", "tax", ""), edge("bikes", "roads", ""))
+	record("broken", "flowchart", node("step", "process", "Orphan"))
+	record("tree", "decision-tree", node("root", "node", "Launch?"))
+
+	export, err := store.ExportSession("s1")
+	require.NoError(t, err)
+	files, err := RenderExport(export, FormatPlantUML)
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+	assert.Equal(t, "release.puml", files[0].Name)
+	assert.Equal(t, "text/plain", files[0].ContentType)
+
+	assert.Equal(t, `@startuml
+start
+if (Tests pass?) then (yes)
+  :Deploy;
+else (no)
+endif
+stop
+@enduml
+`, files[0].Content)
+
+	// Each node hangs from the first to reach it, and the two trees from
+	// the diagram
+	assert.Equal(t, `@startmindmap
+* ideas
+** Cars
+*** Roads
+****:Tolls
+and fees;
+*** Fuel
+**** tax
+** Bikes
+@endmindmap
+`, files[1].Content)
+
+	// A flowchart that is not well formed, like any other diagram, is drawn
+	// from its DOT rendering
+	assert.Equal(t, "@startdot\ndigraph \"broken\" {\n  label=\"flowchart\";\n  \"step\" [\"class\"=\"process\", \"label\"=\"Orphan\", \"shape\"=\"box\"];\n}\n@enddot\n", files[2].Content)
+	assert.Contains(t, files[3].Content, "@startdot\ndigraph \"tree\" {\n")
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Sort orders for record queries
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// Query narrows the records returned by the Store getters. A nil query
+// returns every record of the session, oldest first.
+type Query struct {
+	// Limit caps the number of records returned; zero means no limit
+	Limit int `json:"limit,omitempty"`
+	// Offset skips records after filtering and sorting
+	Offset int `json:"offset,omitempty"`
+	// Since and Until bound CreatedAt (inclusive); zero values are open
+	Since time.Time `json:"since,omitempty"`
+	Until time.Time `json:"until,omitempty"`
+	// Order is SortAscending (default) or SortDescending by creation time
+	Order string `json:"order,omitempty"`
+}
+
+// Validate reports whether the query's options are usable
+func (q *Query) Validate() error {
+	if q == nil {
+		return nil
+	}
+	if q.Limit < 0 {
+		return errorf(ErrInvalidArgument, "limit must not be negative")
+	}
+	if q.Offset < 0 {
+		return errorf(ErrInvalidArgument, "offset must not be negative")
+	}
+	if q.Order != "" && q.Order != SortAscending && q.Order != SortDescending {
+		return errorf(ErrInvalidArgument, "order must be %q or %q", SortAscending, SortDescending)
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return errorf(ErrInvalidArgument, "until must not be before since")
+	}
+
+	return nil
+}
+
+// applyQuery filters, sorts and paginates records held in insertion order
+func applyQuery[T any](records []T, query *Query, createdAt func(T) time.Time) []T {
+	if query == nil {
+		return records
+	}
+
+	filtered := make([]T, 0, len(records))
+	for _, record := range records {
+		t := createdAt(record)
+		if !query.Since.IsZero() && t.Before(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && t.After(query.Until) {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+
+	// Stable sort keeps insertion order for records created at the same instant
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if query.Order == SortDescending {
+			return createdAt(filtered[i]).After(createdAt(filtered[j]))
+		}
+		return createdAt(filtered[i]).Before(createdAt(filtered[j]))
+	})
+
+	return pageOf(filtered, query)
+}
+
+// pageOf applies a query's offset and limit
+func pageOf[T any](records []T, query *Query) []T {
+	if query == nil {
+		return records
+	}
+
+	if query.Offset >= len(records) {
+		return records[:0]
+	}
+	records = records[query.Offset:]
+
+	if query.Limit > 0 && query.Limit < len(records) {
+		records = records[:query.Limit]
+	}
+
+	return records
+}
+
+// RecordPage is a page of records of one kind
+type RecordPage struct {
+	SessionID string      `json:"session_id"`
+	Kind      string      `json:"kind"`
+	Records   interface{} `json:"records"`
+	Count     int         `json:"count"`
+	// Total is the number of records matching the time range before pagination
+	Total int `json:"total"`
+}
+
+// QueryRecords returns a page of the session's records of the given kind
+func QueryRecords(s Store, sessionID, kind string, query *Query) (*RecordPage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Fetch the full filtered range first so the page can report the total
+	var unpaged *Query
+	if query != nil {
+		copied := *query
+		copied.Limit, copied.Offset = 0, 0
+		unpaged = &copied
+	}
+
+	page := &RecordPage{SessionID: sessionID, Kind: kind}
+	var err error
+	switch kind {
+	case KindThoughts:
+		var records []*types.ThoughtData
+		records, err = s.GetThoughts(sessionID, unpaged)
+		page.Records, page.Count, page.Total = paginate(records, query)
+	case KindMentalModels:
+		var records []*types.MentalModelData
+		records, err = s.GetMentalModels(sessionID, unpaged)
+		page.Records, page.Count, page.Total = paginate(records, query)
+	case KindStochasticAlgorithms:
+		var records []*types.StochasticAlgorithmData
+		records, err = s.GetStochasticAlgorithms(sessionID, unpaged)
+		page.Records, page.Count, page.Total = paginate(records, query)
+	case KindDecisions:
+		var records []*types.DecisionData
+		records, err = s.GetDecisions(sessionID, unpaged)
+		page.Records, page.Count, page.Total = paginate(records, query)
+	case KindVisualData:
+		var records []*types.VisualData
+		records, err = s.GetVisualData(sessionID, unpaged)
+		page.Records, page.Count, page.Total = paginate(records, query)
+	case KindCritiques:
+		var records []*types.CritiqueData
+		records, err = s.GetCritiques(sessionID, unpaged)
+		page.Records, page.Count, page.Total = paginate(records, query)
+	case KindScenarios:
+		var records []*types.ScenarioData
+		records, err = s.GetScenarios(sessionID, unpaged)
+		page.Records, page.Count, page.Total = paginate(records, query)
+	default:
+		return nil, errorf(ErrInvalidArgument, "unknown record type %q (expected one of %v)", kind, RecordKinds())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// RecordKinds returns the record kinds accepted by QueryRecords
+func RecordKinds() []string {
+	return []string{KindThoughts, KindMentalModels, KindStochasticAlgorithms, KindDecisions, KindVisualData, KindCritiques, KindScenarios}
+}
+
+// paginate applies a query's offset and limit to already filtered records
+func paginate[T any](records []T, query *Query) (interface{}, int, int) {
+	total := len(records)
+	records = pageOf(records, query)
+	if records == nil {
+		records = []T{}
+	}
+
+	return records, len(records), total
+}
+
+// Creation time accessors used with applyQuery
+func thoughtCreatedAt(r *types.ThoughtData) time.Time               { return r.CreatedAt }
+func mentalModelCreatedAt(r *types.MentalModelData) time.Time       { return r.CreatedAt }
+func algorithmCreatedAt(r *types.StochasticAlgorithmData) time.Time { return r.CreatedAt }
+func decisionCreatedAt(r *types.DecisionData) time.Time             { return r.CreatedAt }
+func visualCreatedAt(r *types.VisualData) time.Time                 { return r.CreatedAt }
+func critiqueCreatedAt(r *types.CritiqueData) time.Time             { return r.CreatedAt }
+func scenarioCreatedAt(r *types.ScenarioData) time.Time             { return r.CreatedAt }
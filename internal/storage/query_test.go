@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQueryTestStore(t *testing.T) (*MemoryStore, time.Time) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	store := NewMemoryStore(config.DefaultConfig())
+	store.SetClock(func() time.Time { return now })
+	for i := 1; i <= 5; i++ {
+		now = start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: fmt.Sprintf("t%d", i)}))
+	}
+
+	return store, start
+}
+
+func thoughtIDs(thoughts []*types.ThoughtData) []string {
+	var ids []string
+	for _, thought := range thoughts {
+		ids = append(ids, thought.ID)
+	}
+	return ids
+}
+
+func TestGetThoughts_Query(t *testing.T) {
+	store, start := newQueryTestStore(t)
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []string
+	}{
+		{"nil query", nil, []string{"t1", "t2", "t3", "t4", "t5"}},
+		{"limit and offset", &Query{Limit: 2, Offset: 1}, []string{"t2", "t3"}},
+		{"descending", &Query{Order: SortDescending, Limit: 2}, []string{"t5", "t4"}},
+		{"time range", &Query{Since: start.Add(2 * time.Minute), Until: start.Add(4 * time.Minute)}, []string{"t2", "t3", "t4"}},
+		{"offset past end", &Query{Offset: 10}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thoughts, err := store.GetThoughts("s1", tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, thoughtIDs(thoughts))
+		})
+	}
+}
+
+func TestQueryRecords_ReportsTotal(t *testing.T) {
+	store, _ := newQueryTestStore(t)
+
+	page, err := QueryRecords(store, "s1", KindThoughts, &Query{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Count)
+	assert.Equal(t, 5, page.Total)
+
+	_, err = QueryRecords(store, "s1", "unknown", nil)
+	assert.Error(t, err)
+
+	_, err = QueryRecords(store, "s1", KindThoughts, &Query{Order: "sideways"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Utilization thresholds for the storage pressure levels
+const (
+	elevatedPressure = 0.75
+	highPressure     = 0.9
+)
+
+// sessionUsage is the number of records and approximate bytes held for a session
+type sessionUsage struct {
+	records int
+	bytes   int64
+}
+
+// recordSize approximates the memory held by a record by its JSON encoding
+func recordSize(record interface{}) int64 {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// admitRecord checks that a session accepts a new record of the given kind
+// and counts it against the session's usage and operations: archived sessions
+// are rejected, thoughts are checked against the thought limit and every
+// record against the per-session quotas. The session is created if needed.
+func (s *MemoryStore) admitRecord(sessionID, kind string, record interface{}) error {
+	size := recordSize(record)
+	session := s.getSession(sessionID)
+
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	if session.Archived {
+		return errorf(ErrSessionArchived, "session %s is archived", sessionID)
+	}
+	if kind == KindThoughts && session.ThoughtCount >= s.config.MaxThoughtsPerSession {
+		return errorf(ErrThoughtLimit, "thought limit reached for session %s", sessionID)
+	}
+
+	usage := s.usageOf(sessionID)
+	if limit := s.config.MaxRecordsPerSession; limit > 0 && usage.records+1 > limit {
+		return errorf(ErrQuotaExceeded, "session %s has reached its quota of %d records", sessionID, limit)
+	}
+	if limit := s.config.MaxBytesPerSession; limit > 0 && usage.bytes+size > limit {
+		return errorf(ErrQuotaExceeded, "session %s has reached its quota of %d bytes", sessionID, limit)
+	}
+
+	usage.records++
+	usage.bytes += size
+	if kind == KindThoughts {
+		session.ThoughtCount++
+	}
+	session.recordOperation(toolFor(record))
+	session.LastAccessedAt = s.now()
+	session.IsActive = true
+
+	return nil
+}
+
+// resizeRecord applies the size change of an updated record to its session's
+// usage, rejecting growth beyond the per-session byte quota
+func (s *MemoryStore) resizeRecord(sessionID string, before, after interface{}) error {
+	delta := recordSize(after) - recordSize(before)
+
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	usage := s.usageOf(sessionID)
+	if limit := s.config.MaxBytesPerSession; limit > 0 && delta > 0 && usage.bytes+delta > limit {
+		return errorf(ErrQuotaExceeded, "session %s has reached its quota of %d bytes", sessionID, limit)
+	}
+	usage.bytes += delta
+
+	return nil
+}
+
+// usageOf returns the usage entry for a session; callers must hold sessionsMutex
+func (s *MemoryStore) usageOf(sessionID string) *sessionUsage {
+	usage, exists := s.usage[sessionID]
+	if !exists {
+		usage = &sessionUsage{}
+		s.usage[sessionID] = usage
+	}
+	return usage
+}
+
+// totalUsage sums the usage of every session; callers must hold sessionsMutex
+func (s *MemoryStore) totalUsage() sessionUsage {
+	var total sessionUsage
+	for _, usage := range s.usage {
+		total.records += usage.records
+		total.bytes += usage.bytes
+	}
+	return total
+}
+
+// overGlobalQuota reports whether total usage exceeds a global quota
+func (s *MemoryStore) overGlobalQuota(total sessionUsage) bool {
+	if limit := s.config.MaxTotalRecords; limit > 0 && total.records > limit {
+		return true
+	}
+	if limit := s.config.MaxTotalBytes; limit > 0 && total.bytes > limit {
+		return true
+	}
+	return false
+}
+
+// enforceGlobalQuota evicts least recently used sessions, inactive ones
+// first, until the store is back within its global quotas. The session just
+// written to and archived sessions are never evicted. It must be called
+// without any store mutex held.
+func (s *MemoryStore) enforceGlobalQuota(writing string) []string {
+	type candidate struct {
+		id         string
+		active     bool
+		lastAccess time.Time
+	}
+
+	s.sessionsMutex.RLock()
+	over := s.overGlobalQuota(s.totalUsage())
+	var candidates []candidate
+	if over {
+		for id, session := range s.sessions {
+			if id != writing && !session.Archived {
+				candidates = append(candidates, candidate{id, session.IsActive, session.LastAccessedAt})
+			}
+		}
+	}
+	s.sessionsMutex.RUnlock()
+
+	if !over {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].active != candidates[j].active {
+			return !candidates[i].active
+		}
+		return candidates[i].lastAccess.Before(candidates[j].lastAccess)
+	})
+
+	s.backgroundMutex.Lock()
+	hook := s.evictionHook
+	s.backgroundMutex.Unlock()
+
+	var evicted []string
+	for _, candidate := range candidates {
+		s.sessionsMutex.RLock()
+		over = s.overGlobalQuota(s.totalUsage())
+		s.sessionsMutex.RUnlock()
+		if !over {
+			break
+		}
+
+		notArchived := func(current *SessionData) bool { return !current.Archived }
+		if s.evict(candidate.id, hook, notArchived) {
+			evicted = append(evicted, candidate.id)
+		}
+	}
+
+	s.quotaEvictions.Add(int64(len(evicted)))
+	if len(evicted) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"evicted": len(evicted),
+		}).Info("Evicted least recently used sessions to stay within storage quota")
+	} else {
+		s.logger.Warn("Storage quota exceeded and no session could be evicted")
+	}
+
+	return evicted
+}
+
+// storagePressure reports quota utilization for a session
+func (s *MemoryStore) storagePressure(sessionID string) *types.StoragePressure {
+	s.sessionsMutex.RLock()
+	defer s.sessionsMutex.RUnlock()
+
+	var usage sessionUsage
+	if current, exists := s.usage[sessionID]; exists {
+		usage = *current
+	}
+	total := s.totalUsage()
+
+	utilization := 0.0
+	use := func(used, limit float64) {
+		if limit > 0 && used/limit > utilization {
+			utilization = used / limit
+		}
+	}
+	use(float64(usage.records), float64(s.config.MaxRecordsPerSession))
+	use(float64(usage.bytes), float64(s.config.MaxBytesPerSession))
+	use(float64(total.records), float64(s.config.MaxTotalRecords))
+	use(float64(total.bytes), float64(s.config.MaxTotalBytes))
+
+	level := types.PressureLow
+	switch {
+	case utilization >= highPressure:
+		level = types.PressureHigh
+	case utilization >= elevatedPressure:
+		level = types.PressureElevated
+	}
+
+	archived := false
+	if session, exists := s.sessions[sessionID]; exists {
+		archived = session.Archived
+	}
+	globalQuota := s.config.MaxTotalRecords > 0 || s.config.MaxTotalBytes > 0
+
+	return &types.StoragePressure{
+		Level:          level,
+		Utilization:    utilization,
+		SessionRecords: usage.records,
+		SessionBytes:   usage.bytes,
+		TotalRecords:   total.records,
+		TotalBytes:     total.bytes,
+		Evictable:      globalQuota && !archived,
+	}
+}
+
+// StorageStats reports record counts and sizes for every session along with
+// the eviction counters
+func (s *MemoryStore) StorageStats() (*types.StorageStats, error) {
+	stores := []struct {
+		kind      string
+		mutex     *sync.RWMutex
+		bySession map[string][]string
+	}{
+		{KindThoughts, &s.thoughtsMutex, s.thoughtsBySession},
+		{KindMentalModels, &s.mentalModelsMutex, s.mentalModelsBySession},
+		{KindStochasticAlgorithms, &s.stochasticAlgorithmsMutex, s.stochasticAlgorithmsBySession},
+		{KindDecisions, &s.decisionsMutex, s.decisionsBySession},
+		{KindVisualData, &s.visualDataMutex, s.visualDataBySession},
+		{KindCritiques, &s.critiquesMutex, s.critiquesBySession},
+		{KindScenarios, &s.scenariosMutex, s.scenariosBySession},
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, store := range stores {
+		store.mutex.RLock()
+		for sessionID, ids := range store.bySession {
+			if len(ids) == 0 {
+				continue
+			}
+			if counts[sessionID] == nil {
+				counts[sessionID] = make(map[string]int)
+			}
+			counts[sessionID][store.kind] = len(ids)
+		}
+		store.mutex.RUnlock()
+	}
+
+	s.sessionsMutex.RLock()
+	sizes := make([]types.SessionSize, 0, len(s.sessions))
+	for id, session := range s.sessions {
+		size := types.SessionSize{SessionID: id, Archived: session.Archived, RecordCounts: counts[id]}
+		if size.RecordCounts == nil {
+			size.RecordCounts = make(map[string]int)
+		}
+		for _, count := range size.RecordCounts {
+			size.TotalRecords += count
+		}
+		if usage, exists := s.usage[id]; exists {
+			size.Bytes = usage.bytes
+		}
+		sizes = append(sizes, size)
+	}
+	s.sessionsMutex.RUnlock()
+
+	evictions := &types.EvictionCounters{
+		Expired:      s.expiredEvictions.Load(),
+		Quota:        s.quotaEvictions.Load(),
+		HookFailures: s.hookFailures.Load(),
+	}
+
+	return summarizeStorage("memory", sizes, evictions), nil
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_SessionRecordQuota(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxRecordsPerSession = 2
+	store := NewMemoryStore(cfg)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "one"}))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "two"}))
+	assert.Error(t, store.AddMentalModel("s1", &types.MentalModelData{ModelName: "three"}))
+	assert.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "other session"}))
+
+	// A rejected thought does not count against the thought limit
+	stats, err := store.GetSessionStats("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.ThoughtCount)
+	assert.Equal(t, 2, stats.StoragePressure.SessionRecords)
+	assert.Equal(t, types.PressureHigh, stats.StoragePressure.Level)
+}
+
+func TestMemoryStore_SessionByteQuotaAppliesToUpdates(t *testing.T) {
+	cfg := config.DefaultConfig()
+	thought := &types.ThoughtData{Thought: "short"}
+	cfg.MaxBytesPerSession = recordSize(thought) + 64
+	store := NewMemoryStore(cfg)
+
+	require.NoError(t, store.AddThought("s1", thought))
+	err := store.UpdateThought("s1", thought.ID, func(t *types.ThoughtData) error {
+		t.Thought = string(make([]byte, 256))
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestMemoryStore_GlobalQuotaEvictsLeastRecentlyUsed(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxTotalRecords = 3
+	store := NewMemoryStore(cfg)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.SetClock(func() time.Time { return now })
+	advance := func() { now = now.Add(time.Minute) }
+
+	require.NoError(t, store.AddThought("oldest", &types.ThoughtData{Thought: "a"}))
+	advance()
+	require.NoError(t, store.AddThought("archived", &types.ThoughtData{Thought: "b"}))
+	require.NoError(t, store.ArchiveSession("archived"))
+	advance()
+	require.NoError(t, store.AddThought("recent", &types.ThoughtData{Thought: "c"}))
+	advance()
+
+	var exported []string
+	store.SetEvictionHook(func(export *types.SessionExport) error {
+		exported = append(exported, export.SessionID)
+		return nil
+	})
+
+	require.NoError(t, store.AddThought("writer", &types.ThoughtData{Thought: "d"}))
+
+	assert.Equal(t, []string{"oldest"}, exported)
+	_, err := store.GetSession("oldest")
+	assert.Error(t, err)
+	for _, id := range []string{"archived", "recent", "writer"} {
+		_, err := store.GetSession(id)
+		assert.NoError(t, err, id)
+	}
+
+	stats, err := store.GetSessionStats("writer")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.StoragePressure.TotalRecords)
+	assert.True(t, stats.StoragePressure.Evictable)
+}
+
+func TestMemoryStore_GlobalQuotaPrefersInactiveSessions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxTotalRecords = 2
+	store := NewMemoryStore(cfg)
+
+	require.NoError(t, store.AddThought("older", &types.ThoughtData{Thought: "a"}))
+	require.NoError(t, store.AddThought("idle", &types.ThoughtData{Thought: "b"}))
+	store.sessions["idle"].IsActive = false
+
+	require.NoError(t, store.AddThought("writer", &types.ThoughtData{Thought: "c"}))
+
+	_, err := store.GetSession("idle")
+	assert.Error(t, err)
+	_, err = store.GetSession("older")
+	assert.NoError(t, err)
+}
+
+func TestMemoryStore_StorageStats(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxTotalRecords = 3
+	store := NewMemoryStore(cfg)
+
+	require.NoError(t, store.AddThought("small", &types.ThoughtData{Thought: "a"}))
+	require.NoError(t, store.AddThought("large", &types.ThoughtData{Thought: "a much longer thought"}))
+	require.NoError(t, store.AddDecision("large", &types.DecisionData{DecisionStatement: "Ship it"}))
+	require.NoError(t, store.ArchiveSession("large"))
+
+	stats, err := store.StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, "memory", stats.Backend)
+	assert.Equal(t, 2, stats.Sessions)
+	assert.Equal(t, 1, stats.ArchivedSessions)
+	assert.Equal(t, 2, stats.RecordCounts[KindThoughts])
+	assert.Equal(t, 1, stats.RecordCounts[KindDecisions])
+	assert.Equal(t, 0, stats.RecordCounts[KindCritiques])
+	assert.Equal(t, 3, stats.TotalRecords)
+	require.Len(t, stats.SessionSizes, 2)
+	assert.Equal(t, "large", stats.SessionSizes[0].SessionID)
+	assert.Equal(t, 2, stats.SessionSizes[0].TotalRecords)
+	assert.Equal(t, stats.SessionSizes[0].Bytes+stats.SessionSizes[1].Bytes, stats.EstimatedBytes)
+	assert.Equal(t, int64(0), stats.Evictions.Quota)
+
+	// Exceeding the global quota evicts the unarchived session
+	require.NoError(t, store.AddThought("new", &types.ThoughtData{Thought: "b"}))
+
+	stats, err = store.StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Evictions.Quota)
+	assert.Equal(t, 2, stats.Sessions)
+}
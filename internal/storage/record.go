@@ -0,0 +1,604 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Record kinds used by durable backends. They match the store names reported in session statistics.
+const (
+	KindThoughts             = "thoughts"
+	KindMentalModels         = "mental_models"
+	KindStochasticAlgorithms = "stochastic_algorithms"
+	KindDecisions            = "decisions"
+	KindVisualData           = "visual_data"
+	KindCritiques            = "critiques"
+	KindScenarios            = "scenarios"
+)
+
+// ErrNotFound is returned by record backends when a session or record does not exist
+var ErrNotFound = errors.New("not found")
+
+// RecordBackend persists serialized records and sessions. Durable backends
+// (SQLite, key-value stores, ...) implement this interface and are wrapped in a
+// RecordStore to provide the full Store API.
+type RecordBackend interface {
+	// InsertRecord adds a new record of the given kind, returning
+	// ErrDuplicateID if a record of that kind already has the ID
+	InsertRecord(kind, sessionID, id string, data []byte) error
+	// PutRecord inserts or replaces a record of the given kind
+	PutRecord(kind, sessionID, id string, data []byte) error
+	// ListRecords returns all records of a kind for a session in insertion order
+	ListRecords(kind, sessionID string) ([][]byte, error)
+
+	// PutSession inserts or replaces session metadata
+	PutSession(sessionID string, data []byte) error
+	// GetSession returns session metadata or ErrNotFound
+	GetSession(sessionID string) ([]byte, error)
+	// ListSessions returns the IDs of all sessions with metadata
+	ListSessions() ([]string, error)
+	// DeleteSession atomically removes session metadata and all of the session's records
+	DeleteSession(sessionID string) error
+
+	// ApplyWrites applies writes in order as one atomic unit: if any fails,
+	// such as an insert returning ErrDuplicateID, none take effect
+	ApplyWrites(writes []RecordWrite) error
+
+	// Ping checks that the backend can be reached
+	Ping(ctx context.Context) error
+
+	// Close releases the backend's resources
+	Close() error
+}
+
+// Write types applied by RecordBackend.ApplyWrites
+const (
+	WriteInsertRecord = "insert_record"
+	WritePutRecord    = "put_record"
+	WritePutSession   = "put_session"
+)
+
+// RecordWrite is a single write applied by RecordBackend.ApplyWrites. Kind
+// and ID are unused for session writes.
+type RecordWrite struct {
+	Type      string
+	Kind      string
+	SessionID string
+	ID        string
+	Data      []byte
+}
+
+// RecordStore implements Store on top of a RecordBackend by serializing records as JSON
+type RecordStore struct {
+	config  *config.Config
+	logger  *logrus.Logger
+	backend RecordBackend
+
+	// sessionsMutex serializes session read-modify-write cycles
+	sessionsMutex sync.Mutex
+}
+
+// NewRecordStore creates a Store backed by the given record backend
+func NewRecordStore(cfg *config.Config, backend RecordBackend) *RecordStore {
+	return &RecordStore{
+		config:  cfg,
+		logger:  logrus.New(),
+		backend: backend,
+	}
+}
+
+// ============================================================================
+// Thought Management
+// ============================================================================
+
+// AddThought adds a new thought to storage
+func (s *RecordStore) AddThought(sessionID string, thought *types.ThoughtData) error {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session, err := s.loadSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.Archived {
+		return errorf(ErrSessionArchived, "session %s is archived", sessionID)
+	}
+
+	// Check thought limit
+	if session.ThoughtCount >= s.config.MaxThoughtsPerSession {
+		return errorf(ErrThoughtLimit, "thought limit reached for session %s", sessionID)
+	}
+
+	thought.SessionID = sessionID
+	if thought.ID == "" {
+		thought.ID = generateID()
+	}
+	if thought.CreatedAt.IsZero() {
+		thought.CreatedAt = time.Now()
+	}
+
+	if err := s.insertRecord(KindThoughts, sessionID, thought.ID, thought); err != nil {
+		return err
+	}
+
+	session.ThoughtCount++
+	session.recordOperation(toolFor(thought))
+	return s.saveSession(session)
+}
+
+// GetThoughts retrieves all thoughts for a session
+func (s *RecordStore) GetThoughts(sessionID string, query *Query) ([]*types.ThoughtData, error) {
+	var thoughts []*types.ThoughtData
+	if err := s.listRecords(KindThoughts, sessionID, &thoughts); err != nil {
+		return nil, err
+	}
+	return applyQuery(thoughts, query, thoughtCreatedAt), nil
+}
+
+// ============================================================================
+// Mental Model Management
+// ============================================================================
+
+// AddMentalModel adds a mental model application to storage
+func (s *RecordStore) AddMentalModel(sessionID string, model *types.MentalModelData) error {
+	model.SessionID = sessionID
+	if model.ID == "" {
+		model.ID = generateID()
+	}
+	if model.CreatedAt.IsZero() {
+		model.CreatedAt = time.Now()
+	}
+
+	return s.addRecord(KindMentalModels, sessionID, model.ID, model)
+}
+
+// GetMentalModels retrieves all mental models for a session
+func (s *RecordStore) GetMentalModels(sessionID string, query *Query) ([]*types.MentalModelData, error) {
+	var models []*types.MentalModelData
+	if err := s.listRecords(KindMentalModels, sessionID, &models); err != nil {
+		return nil, err
+	}
+	return applyQuery(models, query, mentalModelCreatedAt), nil
+}
+
+// ============================================================================
+// Stochastic Algorithm Management
+// ============================================================================
+
+// AddStochasticAlgorithm adds a stochastic algorithm result to storage
+func (s *RecordStore) AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error {
+	algorithm.SessionID = sessionID
+	if algorithm.ID == "" {
+		algorithm.ID = generateID()
+	}
+	if algorithm.CreatedAt.IsZero() {
+		algorithm.CreatedAt = time.Now()
+	}
+
+	return s.addRecord(KindStochasticAlgorithms, sessionID, algorithm.ID, algorithm)
+}
+
+// GetStochasticAlgorithms retrieves all stochastic algorithms for a session
+func (s *RecordStore) GetStochasticAlgorithms(sessionID string, query *Query) ([]*types.StochasticAlgorithmData, error) {
+	var algorithms []*types.StochasticAlgorithmData
+	if err := s.listRecords(KindStochasticAlgorithms, sessionID, &algorithms); err != nil {
+		return nil, err
+	}
+	return applyQuery(algorithms, query, algorithmCreatedAt), nil
+}
+
+// ============================================================================
+// Decision Management
+// ============================================================================
+
+// AddDecision adds a decision framework to storage
+func (s *RecordStore) AddDecision(sessionID string, decision *types.DecisionData) error {
+	decision.SessionID = sessionID
+	if decision.ID == "" {
+		decision.ID = generateID()
+	}
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+
+	return s.addRecord(KindDecisions, sessionID, decision.ID, decision)
+}
+
+// GetDecisions retrieves all decisions for a session
+func (s *RecordStore) GetDecisions(sessionID string, query *Query) ([]*types.DecisionData, error) {
+	var decisions []*types.DecisionData
+	if err := s.listRecords(KindDecisions, sessionID, &decisions); err != nil {
+		return nil, err
+	}
+	return applyQuery(decisions, query, decisionCreatedAt), nil
+}
+
+// ============================================================================
+// Visual Data Management
+// ============================================================================
+
+// AddVisualData adds visual data to storage
+func (s *RecordStore) AddVisualData(sessionID string, visual *types.VisualData) error {
+	visual.SessionID = sessionID
+	if visual.ID == "" {
+		visual.ID = generateID()
+	}
+	if visual.CreatedAt.IsZero() {
+		visual.CreatedAt = time.Now()
+	}
+
+	return s.addRecord(KindVisualData, sessionID, visual.ID, visual)
+}
+
+// GetVisualData retrieves all visual data for a session
+func (s *RecordStore) GetVisualData(sessionID string, query *Query) ([]*types.VisualData, error) {
+	var visuals []*types.VisualData
+	if err := s.listRecords(KindVisualData, sessionID, &visuals); err != nil {
+		return nil, err
+	}
+	return applyQuery(visuals, query, visualCreatedAt), nil
+}
+
+// ============================================================================
+// Critique Management
+// ============================================================================
+
+// AddCritique adds an automated critique to storage
+func (s *RecordStore) AddCritique(sessionID string, critique *types.CritiqueData) error {
+	critique.SessionID = sessionID
+	if critique.ID == "" {
+		critique.ID = generateID()
+	}
+	if critique.CreatedAt.IsZero() {
+		critique.CreatedAt = time.Now()
+	}
+
+	return s.addRecord(KindCritiques, sessionID, critique.ID, critique)
+}
+
+// GetCritiques retrieves all critiques for a session
+func (s *RecordStore) GetCritiques(sessionID string, query *Query) ([]*types.CritiqueData, error) {
+	var critiques []*types.CritiqueData
+	if err := s.listRecords(KindCritiques, sessionID, &critiques); err != nil {
+		return nil, err
+	}
+	return applyQuery(critiques, query, critiqueCreatedAt), nil
+}
+
+// ============================================================================
+// Scenario Management
+// ============================================================================
+
+// AddScenario adds a scenario analysis to storage
+func (s *RecordStore) AddScenario(sessionID string, scenario *types.ScenarioData) error {
+	scenario.SessionID = sessionID
+	if scenario.ID == "" {
+		scenario.ID = generateID()
+	}
+	if scenario.CreatedAt.IsZero() {
+		scenario.CreatedAt = time.Now()
+	}
+
+	return s.addRecord(KindScenarios, sessionID, scenario.ID, scenario)
+}
+
+// GetScenarios retrieves all scenario analyses for a session
+func (s *RecordStore) GetScenarios(sessionID string, query *Query) ([]*types.ScenarioData, error) {
+	var scenarios []*types.ScenarioData
+	if err := s.listRecords(KindScenarios, sessionID, &scenarios); err != nil {
+		return nil, err
+	}
+	return applyQuery(scenarios, query, scenarioCreatedAt), nil
+}
+
+// AddBatch adds the records of a batch to a session
+func (s *RecordStore) AddBatch(sessionID string, batch *Batch) (*BatchResult, error) {
+	return addBatch(s, sessionID, batch)
+}
+
+// ============================================================================
+// Session Management
+// ============================================================================
+
+// GetSession retrieves session data
+func (s *RecordStore) GetSession(sessionID string) (*SessionData, error) {
+	data, err := s.backend.GetSession(sessionID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errorf(ErrSessionNotFound, "session %s not found", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session %s: %w", sessionID, err)
+	}
+
+	return decodeSession(sessionID, data)
+}
+
+// ListSessions returns the IDs of all sessions in order
+func (s *RecordStore) ListSessions() ([]string, error) {
+	ids, err := s.backend.ListSessions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// CreateSession creates a new session
+func (s *RecordStore) CreateSession(sessionID string) (*SessionData, error) {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session := s.newSession(sessionID)
+	if err := s.saveSession(session); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("session_id", sessionID).Debug("Created new session")
+
+	return session, nil
+}
+
+// ClearSession removes a session and all of its records
+func (s *RecordStore) ClearSession(sessionID string) error {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	if _, err := s.backend.GetSession(sessionID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return errorf(ErrSessionNotFound, "session %s not found", sessionID)
+		}
+		return fmt.Errorf("failed to load session %s: %w", sessionID, err)
+	}
+
+	if err := s.backend.DeleteSession(sessionID); err != nil {
+		return fmt.Errorf("failed to clear session %s: %w", sessionID, err)
+	}
+
+	s.logger.WithField("session_id", sessionID).Debug("Cleared session")
+
+	return nil
+}
+
+// ArchiveSession marks a session archived. Its records stay retrievable but
+// no new records are accepted.
+func (s *RecordStore) ArchiveSession(sessionID string) error {
+	return s.setArchived(sessionID, true)
+}
+
+// RestoreSession returns an archived session to normal use
+func (s *RecordStore) RestoreSession(sessionID string) error {
+	return s.setArchived(sessionID, false)
+}
+
+// setArchived changes a session's archive state
+func (s *RecordStore) setArchived(sessionID string, archived bool) error {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := archiveTransition(session, archived, time.Now()); err != nil {
+		return err
+	}
+
+	return s.saveSession(session)
+}
+
+// GetSessionStats retrieves comprehensive session statistics
+func (s *RecordStore) GetSessionStats(sessionID string) (*types.SessionStatistics, error) {
+	s.sessionsMutex.Lock()
+	session, err := s.loadSession(sessionID)
+	s.sessionsMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSessionStats(s, s.config, session)
+}
+
+// ExportSession exports session data
+func (s *RecordStore) ExportSession(sessionID string) (*types.SessionExport, error) {
+	return buildSessionExport(s, sessionID)
+}
+
+// StorageStats reports record counts and sizes for every session. It reads
+// every record, so it is meant for occasional capacity checks.
+func (s *RecordStore) StorageStats() (*types.StorageStats, error) {
+	ids, err := s.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make([]types.SessionSize, 0, len(ids))
+	for _, id := range ids {
+		session, err := s.GetSession(id)
+		if err != nil {
+			// The session was removed after it was listed
+			continue
+		}
+
+		size := types.SessionSize{SessionID: id, Archived: session.Archived, RecordCounts: make(map[string]int)}
+		for _, kind := range RecordKinds() {
+			rows, err := s.backend.ListRecords(kind, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s for session %s: %w", kind, id, err)
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			size.RecordCounts[kind] = len(rows)
+			size.TotalRecords += len(rows)
+			for _, row := range rows {
+				size.Bytes += int64(len(row))
+			}
+		}
+		sizes = append(sizes, size)
+	}
+
+	return summarizeStorage(s.config.StorageBackend, sizes, nil), nil
+}
+
+// Ping checks that the underlying backend can be reached
+func (s *RecordStore) Ping(ctx context.Context) error {
+	return s.backend.Ping(ctx)
+}
+
+// Close closes the underlying backend
+func (s *RecordStore) Close() error {
+	return s.backend.Close()
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// newSession returns fresh session metadata
+func (s *RecordStore) newSession(sessionID string) *SessionData {
+	return &SessionData{
+		ID:                sessionID,
+		CreatedAt:         time.Now(),
+		LastAccessedAt:    time.Now(),
+		ThoughtCount:      0,
+		ToolsUsed:         []string{},
+		TotalOperations:   0,
+		IsActive:          true,
+		RemainingThoughts: s.config.MaxThoughtsPerSession,
+	}
+}
+
+// loadSession gets or creates a session; callers must hold sessionsMutex
+func (s *RecordStore) loadSession(sessionID string) (*SessionData, error) {
+	data, err := s.backend.GetSession(sessionID)
+	if errors.Is(err, ErrNotFound) {
+		session := s.newSession(sessionID)
+		if err := s.saveSession(session); err != nil {
+			return nil, err
+		}
+		return session, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session %s: %w", sessionID, err)
+	}
+
+	return decodeSession(sessionID, data)
+}
+
+// decodeSession decodes serialized session metadata
+func decodeSession(sessionID string, data []byte) (*SessionData, error) {
+	var session SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %w", sessionID, err)
+	}
+
+	return &session, nil
+}
+
+// saveSession persists session metadata after touching its access time; callers must hold sessionsMutex
+func (s *RecordStore) saveSession(session *SessionData) error {
+	session.LastAccessedAt = time.Now()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session %s: %w", session.ID, err)
+	}
+
+	if err := s.backend.PutSession(session.ID, data); err != nil {
+		return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+	}
+
+	return nil
+}
+
+// addRecord stores a record and touches its session
+func (s *RecordStore) addRecord(kind, sessionID, id string, record interface{}) error {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session, err := s.loadSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.Archived {
+		return errorf(ErrSessionArchived, "session %s is archived", sessionID)
+	}
+
+	if err := s.insertRecord(kind, sessionID, id, record); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"kind":       kind,
+		"record_id":  id,
+	}).Debug("Added record to storage")
+
+	session.recordOperation(toolFor(record))
+	return s.saveSession(session)
+}
+
+// insertRecord serializes and writes a new record, rejecting IDs already in use
+func (s *RecordStore) insertRecord(kind, sessionID, id string, record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s record %s: %w", kind, id, err)
+	}
+
+	if err := s.backend.InsertRecord(kind, sessionID, id, data); err != nil {
+		if errors.Is(err, ErrDuplicateID) {
+			return duplicateID(kind, id)
+		}
+		return fmt.Errorf("failed to store %s record %s: %w", kind, id, err)
+	}
+
+	return nil
+}
+
+// putRecord serializes and writes a single record
+func (s *RecordStore) putRecord(kind, sessionID, id string, record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s record %s: %w", kind, id, err)
+	}
+
+	if err := s.backend.PutRecord(kind, sessionID, id, data); err != nil {
+		return fmt.Errorf("failed to store %s record %s: %w", kind, id, err)
+	}
+
+	return nil
+}
+
+// listRecords reads and decodes all records of a kind into the slice pointed to by out
+func (s *RecordStore) listRecords(kind, sessionID string, out interface{}) error {
+	rows, err := s.backend.ListRecords(kind, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list %s for session %s: %w", kind, sessionID, err)
+	}
+
+	// Assemble a JSON array so the records decode into the typed slice in one pass
+	buf := []byte{'['}
+	for i, row := range rows {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, row...)
+	}
+	buf = append(buf, ']')
+
+	if err := json.Unmarshal(buf, out); err != nil {
+		return fmt.Errorf("failed to decode %s for session %s: %w", kind, sessionID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,368 @@
+// Package redis provides a Redis storage backend.
+//
+// Importing the package registers the "redis" backend with the storage
+// package. Sessions stored in Redis can be shared by several GoThink replicas
+// and expire automatically once they have been idle for SessionTimeout.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Key layout (all keys start with the configured prefix):
+//
+//	session:<id>             session metadata
+//	record:<id>:<kind>       hash of record ID to record data
+//	order:<id>:<kind>        list of record IDs in insertion order
+//	keys:<id>                set of the session's record keys, used to refresh their TTL together
+//	id:<kind>:<record id>    the session owning a record ID, so IDs are unique across sessions
+
+// putRecordScript stores a record, appends new IDs to the order list and refreshes the TTLs.
+// KEYS: record hash, order list, key set. ARGV: record ID, data, TTL in milliseconds.
+var putRecordScript = goredis.NewScript(`
+if redis.call('HSET', KEYS[1], ARGV[1], ARGV[2]) == 1 then
+	redis.call('RPUSH', KEYS[2], ARGV[1])
+end
+redis.call('SADD', KEYS[3], KEYS[1], KEYS[2])
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+	redis.call('PEXPIRE', KEYS[3], ttl)
+end
+return 1
+`)
+
+// insertRecordScript stores a new record unless its ID is already claimed by
+// any session, then refreshes the TTLs. Returns 0 for a duplicate ID.
+// KEYS: record hash, order list, key set, ID claim. ARGV: record ID, data, TTL in milliseconds, session ID.
+var insertRecordScript = goredis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 or not redis.call('SET', KEYS[4], ARGV[4], 'NX') then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('RPUSH', KEYS[2], ARGV[1])
+redis.call('SADD', KEYS[3], KEYS[1], KEYS[2], KEYS[4])
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	redis.call('PEXPIRE', KEYS[2], ttl)
+	redis.call('PEXPIRE', KEYS[3], ttl)
+	redis.call('PEXPIRE', KEYS[4], ttl)
+end
+return 1
+`)
+
+// putSessionScript stores session metadata and refreshes the TTL of every key in the session.
+// A negative TTL removes expiry from the session's keys.
+// KEYS: session key, key set. ARGV: data, TTL in milliseconds.
+var putSessionScript = goredis.NewScript(`
+redis.call('SET', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+	for _, key in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+		redis.call('PEXPIRE', key, ttl)
+	end
+	redis.call('PEXPIRE', KEYS[2], ttl)
+elseif ttl < 0 then
+	for _, key in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+		redis.call('PERSIST', key)
+	end
+	redis.call('PERSIST', KEYS[2])
+end
+return 1
+`)
+
+// deleteSessionScript removes a session's metadata, record keys and key set.
+// KEYS: session key, key set.
+var deleteSessionScript = goredis.NewScript(`
+for _, key in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+	redis.call('DEL', key)
+end
+redis.call('DEL', KEYS[1], KEYS[2])
+return 1
+`)
+
+// applyWritesScript applies record and session writes in order, checking the
+// IDs of all new records first so that either every write is applied or, for
+// a duplicate ID, none are. Returns 0 for a duplicate ID.
+// KEYS: per write, the keys of insertRecordScript, putRecordScript or putSessionScript.
+// ARGV: record TTL in milliseconds, then per write its type followed by
+// insert_record: record ID, data, session ID; put_record: record ID, data;
+// put_session: data, session TTL in milliseconds.
+var applyWritesScript = goredis.NewScript(`
+local ttl = tonumber(ARGV[1])
+local k, a = 1, 2
+while a <= #ARGV do
+	local op = ARGV[a]
+	if op == 'insert_record' then
+		if redis.call('HEXISTS', KEYS[k], ARGV[a+1]) == 1 or redis.call('EXISTS', KEYS[k+3]) == 1 then
+			return 0
+		end
+		k, a = k + 4, a + 4
+	elseif op == 'put_record' then
+		k, a = k + 3, a + 3
+	else
+		k, a = k + 2, a + 3
+	end
+end
+
+k, a = 1, 2
+while a <= #ARGV do
+	local op = ARGV[a]
+	local keys = {}
+	if op == 'insert_record' then
+		redis.call('HSET', KEYS[k], ARGV[a+1], ARGV[a+2])
+		redis.call('RPUSH', KEYS[k+1], ARGV[a+1])
+		redis.call('SET', KEYS[k+3], ARGV[a+3])
+		redis.call('SADD', KEYS[k+2], KEYS[k], KEYS[k+1], KEYS[k+3])
+		keys = {KEYS[k], KEYS[k+1], KEYS[k+2], KEYS[k+3]}
+		k, a = k + 4, a + 4
+	elseif op == 'put_record' then
+		if redis.call('HSET', KEYS[k], ARGV[a+1], ARGV[a+2]) == 1 then
+			redis.call('RPUSH', KEYS[k+1], ARGV[a+1])
+		end
+		redis.call('SADD', KEYS[k+2], KEYS[k], KEYS[k+1])
+		keys = {KEYS[k], KEYS[k+1], KEYS[k+2]}
+		k, a = k + 3, a + 3
+	else
+		redis.call('SET', KEYS[k], ARGV[a+1])
+		local sessionTTL = tonumber(ARGV[a+2])
+		if sessionTTL > 0 then
+			redis.call('PEXPIRE', KEYS[k], sessionTTL)
+			for _, key in ipairs(redis.call('SMEMBERS', KEYS[k+1])) do
+				redis.call('PEXPIRE', key, sessionTTL)
+			end
+			redis.call('PEXPIRE', KEYS[k+1], sessionTTL)
+		elseif sessionTTL < 0 then
+			for _, key in ipairs(redis.call('SMEMBERS', KEYS[k+1])) do
+				redis.call('PERSIST', key)
+			end
+			redis.call('PERSIST', KEYS[k+1])
+		end
+		k, a = k + 2, a + 3
+	end
+	if ttl > 0 then
+		for _, key in ipairs(keys) do
+			redis.call('PEXPIRE', key, ttl)
+		end
+	end
+end
+return 1
+`)
+
+func init() {
+	storage.Register("redis", func(cfg *config.Config) (storage.Store, error) {
+		backend, err := Open(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return storage.NewRecordStore(cfg, backend), nil
+	})
+}
+
+// Backend stores records and sessions in Redis
+type Backend struct {
+	client *goredis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// Open connects to the Redis server described by the configuration
+func Open(cfg *config.Config) (*Backend, error) {
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     cfg.RedisAddress,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", cfg.RedisAddress, err)
+	}
+
+	return NewBackend(client, cfg.RedisKeyPrefix, cfg.SessionTimeout), nil
+}
+
+// NewBackend wraps an existing Redis client. A zero ttl disables expiry.
+func NewBackend(client *goredis.Client, prefix string, ttl time.Duration) *Backend {
+	return &Backend{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+// InsertRecord adds a new record, returning storage.ErrDuplicateID if a record
+// of the same kind already has the ID in any session
+func (b *Backend) InsertRecord(kind, sessionID, id string, data []byte) error {
+	keys := []string{b.recordKey(sessionID, kind), b.orderKey(sessionID, kind), b.keySetKey(sessionID), b.idKey(kind, id)}
+	inserted, err := insertRecordScript.Run(context.Background(), b.client, keys, id, data, b.ttl.Milliseconds(), sessionID).Int()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return storage.ErrDuplicateID
+	}
+	return nil
+}
+
+// PutRecord inserts or replaces a record
+func (b *Backend) PutRecord(kind, sessionID, id string, data []byte) error {
+	keys := []string{b.recordKey(sessionID, kind), b.orderKey(sessionID, kind), b.keySetKey(sessionID)}
+	return putRecordScript.Run(context.Background(), b.client, keys, id, data, b.ttl.Milliseconds()).Err()
+}
+
+// ListRecords returns all records of a kind for a session in insertion order
+func (b *Backend) ListRecords(kind, sessionID string) ([][]byte, error) {
+	ctx := context.Background()
+
+	ids, err := b.client.LRange(ctx, b.orderKey(sessionID, kind), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	values, err := b.client.HMGet(ctx, b.recordKey(sessionID, kind), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var records [][]byte
+	for _, value := range values {
+		if data, ok := value.(string); ok {
+			records = append(records, []byte(data))
+		}
+	}
+
+	return records, nil
+}
+
+// PutSession inserts or replaces session metadata and refreshes the session
+// TTL. Archived sessions are kept without expiry.
+func (b *Backend) PutSession(sessionID string, data []byte) error {
+	keys := []string{b.sessionKey(sessionID), b.keySetKey(sessionID)}
+	return putSessionScript.Run(context.Background(), b.client, keys, data, b.sessionTTL(data)).Err()
+}
+
+// sessionTTL returns the TTL in milliseconds for session metadata, or -1 for
+// archived sessions, which are kept without expiry
+func (b *Backend) sessionTTL(data []byte) int64 {
+	var session storage.SessionData
+	if err := json.Unmarshal(data, &session); err == nil && session.Archived {
+		return -1
+	}
+	return b.ttl.Milliseconds()
+}
+
+// GetSession returns session metadata or storage.ErrNotFound
+func (b *Backend) GetSession(sessionID string) ([]byte, error) {
+	data, err := b.client.Get(context.Background(), b.sessionKey(sessionID)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, storage.ErrNotFound
+	}
+
+	return data, err
+}
+
+// ListSessions returns the IDs of all unexpired sessions
+func (b *Backend) ListSessions() ([]string, error) {
+	ctx := context.Background()
+	prefix := b.sessionKey("")
+
+	var ids []string
+	iter := b.client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), prefix))
+	}
+
+	return ids, iter.Err()
+}
+
+// DeleteSession removes session metadata and all of the session's records
+func (b *Backend) DeleteSession(sessionID string) error {
+	keys := []string{b.sessionKey(sessionID), b.keySetKey(sessionID)}
+	return deleteSessionScript.Run(context.Background(), b.client, keys).Err()
+}
+
+// ApplyWrites applies writes in order in a single script, which Redis runs
+// without interleaving other commands
+func (b *Backend) ApplyWrites(writes []storage.RecordWrite) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	var keys []string
+	args := []interface{}{b.ttl.Milliseconds()}
+	for _, write := range writes {
+		switch write.Type {
+		case storage.WriteInsertRecord:
+			keys = append(keys, b.recordKey(write.SessionID, write.Kind), b.orderKey(write.SessionID, write.Kind),
+				b.keySetKey(write.SessionID), b.idKey(write.Kind, write.ID))
+			args = append(args, write.Type, write.ID, write.Data, write.SessionID)
+		case storage.WritePutRecord:
+			keys = append(keys, b.recordKey(write.SessionID, write.Kind), b.orderKey(write.SessionID, write.Kind),
+				b.keySetKey(write.SessionID))
+			args = append(args, write.Type, write.ID, write.Data)
+		case storage.WritePutSession:
+			keys = append(keys, b.sessionKey(write.SessionID), b.keySetKey(write.SessionID))
+			args = append(args, write.Type, write.Data, b.sessionTTL(write.Data))
+		default:
+			return fmt.Errorf("unknown write type %q", write.Type)
+		}
+	}
+
+	applied, err := applyWritesScript.Run(context.Background(), b.client, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	if applied == 0 {
+		return storage.ErrDuplicateID
+	}
+	return nil
+}
+
+// Ping checks that the Redis server answers
+func (b *Backend) Ping(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+// Close closes the Redis client
+func (b *Backend) Close() error {
+	return b.client.Close()
+}
+
+func (b *Backend) sessionKey(sessionID string) string {
+	return b.prefix + "session:" + sessionID
+}
+
+func (b *Backend) recordKey(sessionID, kind string) string {
+	return b.prefix + "record:" + sessionID + ":" + kind
+}
+
+func (b *Backend) orderKey(sessionID, kind string) string {
+	return b.prefix + "order:" + sessionID + ":" + kind
+}
+
+func (b *Backend) keySetKey(sessionID string) string {
+	return b.prefix + "keys:" + sessionID
+}
+
+func (b *Backend) idKey(kind, id string) string {
+	return b.prefix + "id:" + kind + ":" + id
+}
@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T, ttl time.Duration) (*storage.RecordStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+
+	cfg := config.DefaultConfig()
+	store := storage.NewRecordStore(cfg, NewBackend(client, "test:", ttl))
+	t.Cleanup(func() { store.Close() })
+
+	return store, server
+}
+
+func TestRecordsSharedAcrossReplicas(t *testing.T) {
+	store, server := newStore(t, time.Hour)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "first", ThoughtNumber: 1}))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "second", ThoughtNumber: 2}))
+
+	// A second replica pointed at the same server sees the same session
+	replica := storage.NewRecordStore(config.DefaultConfig(), NewBackend(goredis.NewClient(&goredis.Options{Addr: server.Addr()}), "test:", time.Hour))
+	defer replica.Close()
+
+	thoughts, err := replica.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 2)
+	assert.Equal(t, "first", thoughts[0].Thought)
+	assert.Equal(t, "second", thoughts[1].Thought)
+
+	session, err := replica.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, session.ThoughtCount)
+}
+
+func TestSessionsExpireAfterTimeout(t *testing.T) {
+	store, server := newStore(t, 30*time.Minute)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "first"}))
+	server.FastForward(20 * time.Minute)
+
+	// Activity on another store refreshes the TTL of every key in the session
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "keep going"}))
+	server.FastForward(20 * time.Minute)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+
+	server.FastForward(31 * time.Minute)
+
+	_, err = store.GetSession("s1")
+	assert.Error(t, err)
+	thoughts, err = store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+}
+
+func TestClearSessionRemovesAllKeys(t *testing.T) {
+	store, server := newStore(t, time.Hour)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "gone"}))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "gone"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "kept"}))
+
+	require.NoError(t, store.ClearSession("s1"))
+
+	for _, key := range server.Keys() {
+		assert.NotContains(t, key, ":s1")
+	}
+
+	thoughts, err := store.GetThoughts("s2", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
+
+func TestArchivedSessionsDoNotExpire(t *testing.T) {
+	store, server := newStore(t, 30*time.Minute)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "kept"}))
+	require.NoError(t, store.ArchiveSession("s1"))
+	server.FastForward(24 * time.Hour)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+
+	// Restoring the session makes it expire again
+	require.NoError(t, store.RestoreSession("s1"))
+	server.FastForward(31 * time.Minute)
+
+	_, err = store.GetSession("s1")
+	assert.Error(t, err)
+}
+
+func TestDuplicateRecordIDsRejectedAcrossSessions(t *testing.T) {
+	store, _ := newStore(t, time.Hour)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "first"}))
+	assert.ErrorIs(t, store.AddThought("s2", &types.ThoughtData{ID: "t1", Thought: "copy"}), storage.ErrDuplicateID)
+
+	require.NoError(t, store.ClearSession("s1"))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{ID: "t1", Thought: "reused"}))
+}
+
+func TestStorageStatsListsSessions(t *testing.T) {
+	store, _ := newStore(t, time.Hour)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "first"}))
+	require.NoError(t, store.AddDecision("s2", &types.DecisionData{DecisionStatement: "Ship it"}))
+
+	stats, err := store.StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Sessions)
+	assert.Equal(t, 2, stats.TotalRecords)
+}
+
+func TestTransactionCommitsOrRollsBack(t *testing.T) {
+	store, _ := newStore(t, time.Hour)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "first"}))
+	require.NoError(t, store.AddDecision("s2", &types.DecisionData{ID: "taken", DecisionStatement: "elsewhere"}))
+
+	stage := func(clash bool) func(tx *storage.Tx) error {
+		return func(tx *storage.Tx) error {
+			tx.UpdateThought("t1", func(thought *types.ThoughtData) error {
+				thought.Thought = "revised"
+				return nil
+			})
+			tx.AddDecision(&types.DecisionData{DecisionStatement: "Pick a database"})
+			tx.AddVisualData(&types.VisualData{DiagramType: "decisionTree"})
+			if clash {
+				tx.AddDecision(&types.DecisionData{ID: "taken", DecisionStatement: "clash"})
+			}
+			return nil
+		}
+	}
+
+	// The clash is only found by the backend, after every write was staged
+	assert.ErrorIs(t, store.Transaction("s1", stage(true)), storage.ErrDuplicateID)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "first", thoughts[0].Thought)
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, session.TotalOperations)
+
+	require.NoError(t, store.Transaction("s1", stage(false)))
+
+	thoughts, err = store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "revised", thoughts[0].Thought)
+	decisions, err = store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 1)
+	visuals, err := store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, visuals, 1)
+	session, err = store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, session.TotalOperations)
+}
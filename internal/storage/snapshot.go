@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// snapshotVersion is the format version of snapshot files
+const snapshotVersion = "1"
+
+// snapshotFile is the on-disk form of a MemoryStore snapshot
+type snapshotFile struct {
+	Version  string             `json:"version"`
+	SavedAt  time.Time          `json:"saved_at"`
+	Sessions []*sessionSnapshot `json:"sessions"`
+}
+
+// sessionSnapshot holds a session and its records in insertion order
+type sessionSnapshot struct {
+	Session *SessionData `json:"session"`
+	exportData
+}
+
+// SnapshotPath returns the snapshot file location for the configuration
+func SnapshotPath(cfg *config.Config) string {
+	if filepath.Ext(cfg.PersistencePath) == "" {
+		return filepath.Join(cfg.PersistencePath, "gothink-snapshot.json")
+	}
+
+	return cfg.PersistencePath
+}
+
+// StartSnapshots loads the snapshot at path, if one exists, and then writes a
+// new snapshot every interval. A final snapshot is written when the store is
+// closed.
+func (s *MemoryStore) StartSnapshots(path string, interval time.Duration) error {
+	if err := s.LoadSnapshot(path); err != nil {
+		return err
+	}
+
+	s.backgroundMutex.Lock()
+	defer s.backgroundMutex.Unlock()
+
+	if s.snapshotPath != "" {
+		return fmt.Errorf("snapshots already enabled at %s", s.snapshotPath)
+	}
+	s.snapshotPath = path
+
+	if interval > 0 {
+		s.runEvery(interval, func() {
+			if err := s.SaveSnapshot(path); err != nil {
+				s.logger.WithError(err).Error("Failed to save snapshot")
+			}
+		})
+	}
+
+	return nil
+}
+
+// SaveSnapshot writes every session and record to path. The file is replaced
+// atomically so a crash mid-write leaves the previous snapshot intact.
+func (s *MemoryStore) SaveSnapshot(path string) error {
+	data, sessions, err := s.encodeSnapshot()
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"path":     path,
+		"sessions": sessions,
+	}).Debug("Saved snapshot")
+
+	return nil
+}
+
+// LoadSnapshot restores the sessions and records saved at path, replacing
+// sessions with the same ID. A missing file is not an error.
+func (s *MemoryStore) LoadSnapshot(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot snapshotFile
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to decode snapshot %s: %w", path, err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %q in %s", snapshot.Version, path)
+	}
+
+	for _, session := range snapshot.Sessions {
+		if session.Session == nil || session.Session.ID == "" {
+			continue
+		}
+		s.restoreSession(session)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"path":     path,
+		"sessions": len(snapshot.Sessions),
+	}).Info("Loaded snapshot")
+
+	return nil
+}
+
+// encodeSnapshot serializes the store's contents under read locks and
+// returns the data along with the number of sessions it contains
+func (s *MemoryStore) encodeSnapshot() ([]byte, int, error) {
+	s.thoughtsMutex.RLock()
+	defer s.thoughtsMutex.RUnlock()
+	s.mentalModelsMutex.RLock()
+	defer s.mentalModelsMutex.RUnlock()
+	s.stochasticAlgorithmsMutex.RLock()
+	defer s.stochasticAlgorithmsMutex.RUnlock()
+	s.decisionsMutex.RLock()
+	defer s.decisionsMutex.RUnlock()
+	s.visualDataMutex.RLock()
+	defer s.visualDataMutex.RUnlock()
+	s.critiquesMutex.RLock()
+	defer s.critiquesMutex.RUnlock()
+	s.scenariosMutex.RLock()
+	defer s.scenariosMutex.RUnlock()
+	s.sessionsMutex.RLock()
+	defer s.sessionsMutex.RUnlock()
+
+	snapshot := &snapshotFile{
+		Version: snapshotVersion,
+		SavedAt: s.now(),
+	}
+
+	for id := range s.sessions {
+		snapshot.Sessions = append(snapshot.Sessions, s.snapshotSession(id))
+	}
+
+	sort.Slice(snapshot.Sessions, func(i, j int) bool {
+		return snapshot.Sessions[i].Session.ID < snapshot.Sessions[j].Session.ID
+	})
+
+	data, err := json.Marshal(snapshot)
+	return data, len(snapshot.Sessions), err
+}
+
+// snapshotSession returns a copy of a session's metadata and its records in
+// insertion order, or nil if the session does not exist; callers must hold
+// every store lock, at least for reading
+func (s *MemoryStore) snapshotSession(sessionID string) *sessionSnapshot {
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil
+	}
+	entry := &sessionSnapshot{Session: session.clone()}
+
+	for _, recordID := range s.thoughtsBySession[sessionID] {
+		entry.Thoughts = append(entry.Thoughts, s.thoughts[recordID])
+	}
+	for _, recordID := range s.mentalModelsBySession[sessionID] {
+		entry.MentalModels = append(entry.MentalModels, s.mentalModels[recordID])
+	}
+	for _, recordID := range s.stochasticAlgorithmsBySession[sessionID] {
+		entry.StochasticAlgorithms = append(entry.StochasticAlgorithms, s.stochasticAlgorithms[recordID])
+	}
+	for _, recordID := range s.decisionsBySession[sessionID] {
+		entry.Decisions = append(entry.Decisions, s.decisions[recordID])
+	}
+	for _, recordID := range s.visualDataBySession[sessionID] {
+		entry.VisualData = append(entry.VisualData, s.visualData[recordID])
+	}
+	for _, recordID := range s.critiquesBySession[sessionID] {
+		entry.Critiques = append(entry.Critiques, s.critiques[recordID])
+	}
+	for _, recordID := range s.scenariosBySession[sessionID] {
+		entry.Scenarios = append(entry.Scenarios, s.scenarios[recordID])
+	}
+
+	return entry
+}
+
+// restoreSession replaces a session and its records with the snapshotted copy
+func (s *MemoryStore) restoreSession(entry *sessionSnapshot) {
+	sessionID := entry.Session.ID
+	s.removeSession(sessionID, nil)
+
+	s.thoughtsMutex.Lock()
+	defer s.thoughtsMutex.Unlock()
+	s.mentalModelsMutex.Lock()
+	defer s.mentalModelsMutex.Unlock()
+	s.stochasticAlgorithmsMutex.Lock()
+	defer s.stochasticAlgorithmsMutex.Unlock()
+	s.decisionsMutex.Lock()
+	defer s.decisionsMutex.Unlock()
+	s.visualDataMutex.Lock()
+	defer s.visualDataMutex.Unlock()
+	s.critiquesMutex.Lock()
+	defer s.critiquesMutex.Unlock()
+	s.scenariosMutex.Lock()
+	defer s.scenariosMutex.Unlock()
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	s.sessions[sessionID] = entry.Session
+	usage := s.usageOf(sessionID)
+	count := func(record interface{}) {
+		usage.records++
+		usage.bytes += recordSize(record)
+	}
+
+	for _, thought := range entry.Thoughts {
+		count(thought)
+		s.thoughts[thought.ID] = thought
+		s.thoughtsBySession[sessionID] = append(s.thoughtsBySession[sessionID], thought.ID)
+	}
+	for _, model := range entry.MentalModels {
+		count(model)
+		s.mentalModels[model.ID] = model
+		s.mentalModelsBySession[sessionID] = append(s.mentalModelsBySession[sessionID], model.ID)
+	}
+	for _, algorithm := range entry.StochasticAlgorithms {
+		count(algorithm)
+		s.stochasticAlgorithms[algorithm.ID] = algorithm
+		s.stochasticAlgorithmsBySession[sessionID] = append(s.stochasticAlgorithmsBySession[sessionID], algorithm.ID)
+	}
+	for _, decision := range entry.Decisions {
+		count(decision)
+		s.decisions[decision.ID] = decision
+		s.decisionsBySession[sessionID] = append(s.decisionsBySession[sessionID], decision.ID)
+	}
+	for _, visual := range entry.VisualData {
+		count(visual)
+		s.visualData[visual.ID] = visual
+		s.visualDataBySession[sessionID] = append(s.visualDataBySession[sessionID], visual.ID)
+	}
+	for _, critique := range entry.Critiques {
+		count(critique)
+		s.critiques[critique.ID] = critique
+		s.critiquesBySession[sessionID] = append(s.critiquesBySession[sessionID], critique.ID)
+	}
+	for _, scenario := range entry.Scenarios {
+		count(scenario)
+		s.scenarios[scenario.ID] = scenario
+		s.scenariosBySession[sessionID] = append(s.scenariosBySession[sessionID], scenario.ID)
+	}
+}
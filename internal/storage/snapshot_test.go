@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gothink-snapshot.json")
+
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.StartSnapshots(path, time.Hour))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "first"}))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t2", Thought: "second"}))
+	require.NoError(t, store.AddDecision("s2", &types.DecisionData{ID: "d1", DecisionStatement: "Pick one"}))
+
+	// Close writes the final snapshot
+	require.NoError(t, store.Close())
+
+	restored := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, restored.StartSnapshots(path, time.Hour))
+	defer restored.Close()
+
+	thoughts, err := restored.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 2)
+	assert.Equal(t, "t1", thoughts[0].ID)
+	assert.Equal(t, "t2", thoughts[1].ID)
+
+	decisions, err := restored.GetDecisions("s2", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "Pick one", decisions[0].DecisionStatement)
+
+	session, err := restored.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, session.ThoughtCount)
+}
+
+func TestLoadSnapshot_RejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": "99", "sessions": []}`), 0o600))
+
+	store := NewMemoryStore(config.DefaultConfig())
+	assert.Error(t, store.LoadSnapshot(path))
+	assert.NoError(t, store.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json")))
+}
@@ -0,0 +1,261 @@
+//go:build cgo
+
+// Package sqlite provides a SQLite-backed storage backend.
+//
+// Importing the package registers the "sqlite" backend with the storage
+// package. When persistence is enabled the database is written to
+// PersistencePath (a directory, in which gothink.db is created, or a file
+// path); otherwise an in-memory database is used. The driver needs cgo, so
+// the package is only built with it.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// schema creates the tables used by the backend
+const schema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id   TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS records (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind       TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	UNIQUE (kind, id)
+);
+
+CREATE INDEX IF NOT EXISTS records_by_session ON records (session_id, kind, seq);
+`
+
+func init() {
+	storage.Register("sqlite", func(cfg *config.Config) (storage.Store, error) {
+		backend, err := Open(DatabasePath(cfg))
+		if err != nil {
+			return nil, err
+		}
+		return storage.NewRecordStore(cfg, backend), nil
+	})
+}
+
+// Backend stores records and sessions in a SQLite database
+type Backend struct {
+	db *sql.DB
+}
+
+// DatabasePath returns the database location for the configuration
+func DatabasePath(cfg *config.Config) string {
+	if !cfg.EnablePersistence || cfg.PersistencePath == "" {
+		return ":memory:"
+	}
+
+	if filepath.Ext(cfg.PersistencePath) == "" {
+		return filepath.Join(cfg.PersistencePath, "gothink.db")
+	}
+
+	return cfg.PersistencePath
+}
+
+// Open opens (and if necessary creates) the SQLite database at path
+func Open(path string) (*Backend, error) {
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite serializes writers; a single connection also keeps in-memory databases shared
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	return &Backend{db: db}, nil
+}
+
+// execer runs statements on the database or inside a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// InsertRecord adds a new record, returning storage.ErrDuplicateID if a record
+// of the same kind already has the ID
+func (b *Backend) InsertRecord(kind, sessionID, id string, data []byte) error {
+	return insertRecord(b.db, kind, sessionID, id, data)
+}
+
+// PutRecord inserts or replaces a record
+func (b *Backend) PutRecord(kind, sessionID, id string, data []byte) error {
+	return putRecord(b.db, kind, sessionID, id, data)
+}
+
+// insertRecord adds a new record unless a record of the same kind has the ID
+func insertRecord(db execer, kind, sessionID, id string, data []byte) error {
+	result, err := db.Exec(`
+		INSERT INTO records (kind, session_id, id, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (kind, id) DO NOTHING`,
+		kind, sessionID, id, data)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return storage.ErrDuplicateID
+	}
+	return nil
+}
+
+// putRecord inserts or replaces a record
+func putRecord(db execer, kind, sessionID, id string, data []byte) error {
+	_, err := db.Exec(`
+		INSERT INTO records (kind, session_id, id, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (kind, id) DO UPDATE SET session_id = excluded.session_id, data = excluded.data`,
+		kind, sessionID, id, data)
+	return err
+}
+
+// ListRecords returns all records of a kind for a session in insertion order
+func (b *Backend) ListRecords(kind, sessionID string) ([][]byte, error) {
+	rows, err := b.db.Query(`SELECT data FROM records WHERE session_id = ? AND kind = ? ORDER BY seq`, sessionID, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records [][]byte
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		records = append(records, data)
+	}
+
+	return records, rows.Err()
+}
+
+// PutSession inserts or replaces session metadata
+func (b *Backend) PutSession(sessionID string, data []byte) error {
+	return putSession(b.db, sessionID, data)
+}
+
+// putSession inserts or replaces session metadata
+func putSession(db execer, sessionID string, data []byte) error {
+	_, err := db.Exec(`
+		INSERT INTO sessions (id, data) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		sessionID, data)
+	return err
+}
+
+// GetSession returns session metadata or storage.ErrNotFound
+func (b *Backend) GetSession(sessionID string) ([]byte, error) {
+	var data []byte
+	err := b.db.QueryRow(`SELECT data FROM sessions WHERE id = ?`, sessionID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+
+	return data, err
+}
+
+// ListSessions returns the IDs of all sessions
+func (b *Backend) ListSessions() ([]string, error) {
+	rows, err := b.db.Query(`SELECT id FROM sessions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// DeleteSession removes session metadata and all of the session's records in one transaction
+func (b *Backend) DeleteSession(sessionID string) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM records WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, sessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ApplyWrites applies writes in order in one transaction
+func (b *Backend) ApplyWrites(writes []storage.RecordWrite) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, write := range writes {
+		var err error
+		switch write.Type {
+		case storage.WriteInsertRecord:
+			err = insertRecord(tx, write.Kind, write.SessionID, write.ID, write.Data)
+		case storage.WritePutRecord:
+			err = putRecord(tx, write.Kind, write.SessionID, write.ID, write.Data)
+		case storage.WritePutSession:
+			err = putSession(tx, write.SessionID, write.Data)
+		default:
+			err = fmt.Errorf("unknown write type %q", write.Type)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Ping checks that the database can be reached
+func (b *Backend) Ping(ctx context.Context) error {
+	return b.db.PingContext(ctx)
+}
+
+// Close closes the database
+func (b *Backend) Close() error {
+	return b.db.Close()
+}
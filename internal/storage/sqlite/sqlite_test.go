@@ -0,0 +1,214 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConfig(t *testing.T) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.StorageBackend = "sqlite"
+	cfg.EnablePersistence = true
+	cfg.PersistencePath = filepath.Join(t.TempDir(), "data")
+	return cfg
+}
+
+func TestDatabasePath(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.Equal(t, ":memory:", DatabasePath(cfg))
+
+	cfg.EnablePersistence = true
+	cfg.PersistencePath = "./data"
+	assert.Equal(t, filepath.Join("data", "gothink.db"), DatabasePath(cfg))
+
+	cfg.PersistencePath = "/var/lib/gothink/sessions.sqlite"
+	assert.Equal(t, "/var/lib/gothink/sessions.sqlite", DatabasePath(cfg))
+}
+
+func TestSessionsSurviveRestart(t *testing.T) {
+	cfg := newConfig(t)
+
+	store, err := storage.New(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "first", ThoughtNumber: 1, TotalThoughts: 2}))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "second", ThoughtNumber: 2, TotalThoughts: 2}))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "Pick a database"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "other session", ThoughtNumber: 1, TotalThoughts: 1}))
+	require.NoError(t, store.Close())
+
+	reopened, err := storage.New(cfg)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	thoughts, err := reopened.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 2)
+	assert.Equal(t, "first", thoughts[0].Thought)
+	assert.Equal(t, "second", thoughts[1].Thought)
+
+	session, err := reopened.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, session.ThoughtCount)
+
+	stats, err := reopened.GetSessionStats("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalOperations)
+	assert.Equal(t, map[string]int{"sequential-thinking": 2, "decision-framework": 1}, stats.ToolCounts)
+	assert.Equal(t, 3, session.TotalOperations)
+}
+
+func TestThoughtLimit(t *testing.T) {
+	cfg := newConfig(t)
+	cfg.MaxThoughtsPerSession = 1
+
+	store, err := storage.New(cfg)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "only"}))
+	assert.Error(t, store.AddThought("s1", &types.ThoughtData{Thought: "one too many"}))
+}
+
+func TestClearSession(t *testing.T) {
+	store, err := storage.New(newConfig(t))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "gone"}))
+	require.NoError(t, store.AddThought("s2", &types.ThoughtData{Thought: "kept"}))
+
+	require.NoError(t, store.ClearSession("s1"))
+	assert.Error(t, store.ClearSession("s1"))
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+
+	thoughts, err = store.GetThoughts("s2", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
+
+func TestUpdateThought(t *testing.T) {
+	store, err := storage.New(newConfig(t))
+	require.NoError(t, err)
+	defer store.Close()
+
+	first := &types.ThoughtData{Thought: "first"}
+	require.NoError(t, store.AddThought("s1", first))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "second"}))
+
+	require.NoError(t, store.UpdateThought("s1", first.ID, func(thought *types.ThoughtData) error {
+		thought.Thought = "revised"
+		return nil
+	}))
+	assert.Error(t, store.UpdateThought("s2", first.ID, func(*types.ThoughtData) error { return nil }))
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 2)
+	assert.Equal(t, first.ID, thoughts[0].ID)
+	assert.Equal(t, "revised", thoughts[0].Thought)
+	assert.Equal(t, "second", thoughts[1].Thought)
+}
+
+func TestDuplicateRecordIDsRejected(t *testing.T) {
+	store, err := storage.New(newConfig(t))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "first"}))
+	assert.ErrorIs(t, store.AddThought("s2", &types.ThoughtData{ID: "t1", Thought: "copy"}), storage.ErrDuplicateID)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "first", thoughts[0].Thought)
+
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, session.ThoughtCount)
+}
+
+func TestStorageStats(t *testing.T) {
+	store, err := storage.New(newConfig(t))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "first"}))
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "second"}))
+	require.NoError(t, store.AddDecision("s2", &types.DecisionData{DecisionStatement: "Ship it"}))
+
+	stats, err := store.StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite", stats.Backend)
+	assert.Equal(t, 2, stats.Sessions)
+	assert.Equal(t, 2, stats.RecordCounts[storage.KindThoughts])
+	assert.Equal(t, 1, stats.RecordCounts[storage.KindDecisions])
+	assert.Equal(t, 3, stats.TotalRecords)
+	assert.Positive(t, stats.EstimatedBytes)
+	assert.Nil(t, stats.Evictions)
+}
+
+func TestTransactionCommitsOrRollsBack(t *testing.T) {
+	store, err := storage.New(newConfig(t))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{ID: "t1", Thought: "first"}))
+	require.NoError(t, store.AddDecision("s2", &types.DecisionData{ID: "taken", DecisionStatement: "elsewhere"}))
+
+	stage := func(clash bool) func(tx *storage.Tx) error {
+		return func(tx *storage.Tx) error {
+			tx.UpdateThought("t1", func(thought *types.ThoughtData) error {
+				thought.Thought = "revised"
+				return nil
+			})
+			tx.AddDecision(&types.DecisionData{DecisionStatement: "Pick a database"})
+			tx.AddVisualData(&types.VisualData{DiagramType: "decisionTree"})
+			if clash {
+				tx.AddDecision(&types.DecisionData{ID: "taken", DecisionStatement: "clash"})
+			}
+			return nil
+		}
+	}
+
+	// The clash is only found by the backend, after every write was staged
+	assert.ErrorIs(t, store.Transaction("s1", stage(true)), storage.ErrDuplicateID)
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "first", thoughts[0].Thought)
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, session.TotalOperations)
+
+	require.NoError(t, store.Transaction("s1", stage(false)))
+
+	thoughts, err = store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "revised", thoughts[0].Thought)
+	decisions, err = store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 1)
+	visuals, err := store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, visuals, 1)
+	session, err = store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, session.TotalOperations)
+}
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// buildSessionStats computes session statistics from the records held by a store
+func buildSessionStats(s Store, cfg *config.Config, session *SessionData) (*types.SessionStatistics, error) {
+	sessionID := session.ID
+
+	thoughts, _ := s.GetThoughts(sessionID, nil)
+	mentalModels, _ := s.GetMentalModels(sessionID, nil)
+	stochasticAlgorithms, _ := s.GetStochasticAlgorithms(sessionID, nil)
+	decisions, _ := s.GetDecisions(sessionID, nil)
+	visualData, _ := s.GetVisualData(sessionID, nil)
+	critiques, _ := s.GetCritiques(sessionID, nil)
+	scenarios, _ := s.GetScenarios(sessionID, nil)
+
+	// Sessions recorded before operations were tracked derive their counts from their records
+	toolsUsed, toolCounts, totalOperations := session.ToolsUsed, session.ToolCounts, session.TotalOperations
+	if len(toolCounts) == 0 {
+		derived := &SessionData{}
+		for _, thought := range thoughts {
+			derived.recordOperation(toolFor(thought))
+		}
+		for _, model := range mentalModels {
+			derived.recordOperation(toolFor(model))
+		}
+		for _, algorithm := range stochasticAlgorithms {
+			derived.recordOperation(toolFor(algorithm))
+		}
+		for _, decision := range decisions {
+			derived.recordOperation(toolFor(decision))
+		}
+		for _, visual := range visualData {
+			derived.recordOperation(toolFor(visual))
+		}
+		for _, critique := range critiques {
+			derived.recordOperation(toolFor(critique))
+		}
+		for _, scenario := range scenarios {
+			derived.recordOperation(toolFor(scenario))
+		}
+		toolsUsed, toolCounts, totalOperations = derived.ToolsUsed, derived.ToolCounts, derived.TotalOperations
+	}
+	if toolsUsed == nil {
+		toolsUsed = []string{}
+	}
+	if toolCounts == nil {
+		toolCounts = map[string]int{}
+	}
+
+	stats := &types.SessionStatistics{
+		SessionID:         sessionID,
+		CreatedAt:         session.CreatedAt,
+		LastAccessedAt:    session.LastAccessedAt,
+		ThoughtCount:      len(thoughts),
+		ToolsUsed:         toolsUsed,
+		ToolCounts:        toolCounts,
+		TotalOperations:   totalOperations,
+		IsActive:          session.IsActive,
+		IsArchived:        session.Archived,
+		RemainingThoughts: cfg.MaxThoughtsPerSession - len(thoughts),
+		Stores: map[string]interface{}{
+			"thoughts":              map[string]int{"count": len(thoughts)},
+			"mental_models":         map[string]int{"count": len(mentalModels)},
+			"stochastic_algorithms": map[string]int{"count": len(stochasticAlgorithms)},
+			"decisions":             map[string]int{"count": len(decisions)},
+			"visual_data":           map[string]int{"count": len(visualData)},
+			"critiques":             map[string]int{"count": len(critiques)},
+			"scenarios":             map[string]int{"count": len(scenarios)},
+		},
+	}
+
+	return stats, nil
+}
+
+// toolFor names the tool that produces a record, as counted in session statistics
+func toolFor(record interface{}) string {
+	switch r := record.(type) {
+	case *types.ThoughtData:
+		return "sequential-thinking"
+	case *types.MentalModelData:
+		if r.IsDebuggingApproach() {
+			return "debugging-approach"
+		}
+		return "mental-model"
+	case *types.StochasticAlgorithmData:
+		return "stochastic-" + r.Algorithm
+	case *types.DecisionData:
+		return "decision-framework"
+	case *types.VisualData:
+		return "visual-" + r.DiagramType
+	case *types.CritiqueData:
+		return "critique-reasoning"
+	case *types.ScenarioData:
+		return "scenario-analysis"
+	default:
+		return "unknown"
+	}
+}
+
+// buildSessionExport assembles the export payload from the records held by a store
+func buildSessionExport(s Store, sessionID string) (*types.SessionExport, error) {
+	thoughts, _ := s.GetThoughts(sessionID, nil)
+	mentalModels, _ := s.GetMentalModels(sessionID, nil)
+	stochasticAlgorithms, _ := s.GetStochasticAlgorithms(sessionID, nil)
+	decisions, _ := s.GetDecisions(sessionID, nil)
+	visualData, _ := s.GetVisualData(sessionID, nil)
+	critiques, _ := s.GetCritiques(sessionID, nil)
+	scenarios, _ := s.GetScenarios(sessionID, nil)
+
+	export := &types.SessionExport{
+		Version:     ExportVersion,
+		Timestamp:   time.Now(),
+		SessionID:   sessionID,
+		SessionType: "hybrid",
+		Data: map[string]interface{}{
+			"thoughts":              thoughts,
+			"mental_models":         mentalModels,
+			"stochastic_algorithms": stochasticAlgorithms,
+			"decisions":             decisions,
+			"visual_data":           visualData,
+			"critiques":             critiques,
+			"scenarios":             scenarios,
+		},
+		Metadata: map[string]interface{}{
+			"exported_at": time.Now(),
+			"version":     "0.1.0",
+		},
+	}
+
+	return export, nil
+}
+
+// summarizeStorage totals per-session sizes into storage statistics, listing
+// sessions largest first
+func summarizeStorage(backend string, sizes []types.SessionSize, evictions *types.EvictionCounters) *types.StorageStats {
+	if sizes == nil {
+		sizes = []types.SessionSize{}
+	}
+
+	stats := &types.StorageStats{
+		Backend:      backend,
+		Sessions:     len(sizes),
+		RecordCounts: make(map[string]int),
+		SessionSizes: sizes,
+		Evictions:    evictions,
+	}
+	for _, kind := range RecordKinds() {
+		stats.RecordCounts[kind] = 0
+	}
+
+	for _, size := range sizes {
+		if size.Archived {
+			stats.ArchivedSessions++
+		}
+		for kind, count := range size.RecordCounts {
+			stats.RecordCounts[kind] += count
+		}
+		stats.TotalRecords += size.TotalRecords
+		stats.EstimatedBytes += size.Bytes
+	}
+
+	sort.SliceStable(stats.SessionSizes, func(i, j int) bool {
+		if stats.SessionSizes[i].Bytes != stats.SessionSizes[j].Bytes {
+			return stats.SessionSizes[i].Bytes > stats.SessionSizes[j].Bytes
+		}
+		return stats.SessionSizes[i].SessionID < stats.SessionSizes[j].SessionID
+	})
+
+	return stats
+}
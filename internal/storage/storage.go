@@ -1,442 +1,249 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/google/uuid"
 	"github.com/rainmana/gothink/internal/config"
 	"github.com/rainmana/gothink/internal/types"
 )
 
-// Storage manages all data storage for the GoThink server
-type Storage struct {
-	config *config.Config
-	logger *logrus.Logger
-
-	// In-memory stores (in production, these would be backed by a database)
-	thoughts             map[string]*types.ThoughtData
-	mentalModels         map[string]*types.MentalModelData
-	stochasticAlgorithms map[string]*types.StochasticAlgorithmData
-	decisions            map[string]*types.DecisionData
-	visualData           map[string]*types.VisualData
-	sessions             map[string]*SessionData
-
-	// Mutexes for thread safety
-	thoughtsMutex             sync.RWMutex
-	mentalModelsMutex         sync.RWMutex
-	stochasticAlgorithmsMutex sync.RWMutex
-	decisionsMutex            sync.RWMutex
-	visualDataMutex           sync.RWMutex
-	sessionsMutex             sync.RWMutex
-}
-
-// SessionData represents session-specific data
-type SessionData struct {
-	ID                string    `json:"id"`
-	CreatedAt         time.Time `json:"created_at"`
-	LastAccessedAt    time.Time `json:"last_accessed_at"`
-	ThoughtCount      int       `json:"thought_count"`
-	ToolsUsed         []string  `json:"tools_used"`
-	TotalOperations   int       `json:"total_operations"`
-	IsActive          bool      `json:"is_active"`
-	RemainingThoughts int       `json:"remaining_thoughts"`
-}
-
-// New creates a new storage instance
-func New(cfg *config.Config) (*Storage, error) {
-
-	return &Storage{
-		config:               cfg,
-		logger:               logrus.New(),
-		thoughts:             make(map[string]*types.ThoughtData),
-		mentalModels:         make(map[string]*types.MentalModelData),
-		stochasticAlgorithms: make(map[string]*types.StochasticAlgorithmData),
-		decisions:            make(map[string]*types.DecisionData),
-		visualData:           make(map[string]*types.VisualData),
-		sessions:             make(map[string]*SessionData),
-	}, nil
-}
-
-// ============================================================================
-// Thought Management
-// ============================================================================
-
-// AddThought adds a new thought to storage
-func (s *Storage) AddThought(sessionID string, thought *types.ThoughtData) error {
-	s.thoughtsMutex.Lock()
-	defer s.thoughtsMutex.Unlock()
-
-	// Check thought limit
-	session := s.getSession(sessionID)
-	if session.ThoughtCount >= s.config.MaxThoughtsPerSession {
-		return fmt.Errorf("thought limit reached for session %s", sessionID)
-	}
-
-	// Generate ID if not provided
-	if thought.ID == "" {
-		thought.ID = generateID()
-	}
-	thought.CreatedAt = time.Now()
-
-	s.thoughts[thought.ID] = thought
-
-	// Update session
-	session.ThoughtCount++
-	session.LastAccessedAt = time.Now()
-	s.sessions[sessionID] = session
-
-	s.logger.WithFields(logrus.Fields{
-		"session_id":     sessionID,
-		"thought_id":     thought.ID,
-		"thought_number": thought.ThoughtNumber,
-	}).Debug("Added thought to storage")
+// Store is the storage backend used by the GoThink handlers and MCP tools.
+// Getters return a session's records oldest first; a non-nil query filters,
+// sorts and paginates them. Update methods apply a function to a copy of an
+// existing record; its ID, session and creation time cannot be changed.
+type Store interface {
+	// Thoughts
+	AddThought(sessionID string, thought *types.ThoughtData) error
+	GetThoughts(sessionID string, query *Query) ([]*types.ThoughtData, error)
+	UpdateThought(sessionID, id string, update func(*types.ThoughtData) error) error
+
+	// Mental models
+	AddMentalModel(sessionID string, model *types.MentalModelData) error
+	GetMentalModels(sessionID string, query *Query) ([]*types.MentalModelData, error)
+	UpdateMentalModel(sessionID, id string, update func(*types.MentalModelData) error) error
+
+	// Stochastic algorithms
+	AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error
+	GetStochasticAlgorithms(sessionID string, query *Query) ([]*types.StochasticAlgorithmData, error)
+	UpdateStochasticAlgorithm(sessionID, id string, update func(*types.StochasticAlgorithmData) error) error
+
+	// Decisions
+	AddDecision(sessionID string, decision *types.DecisionData) error
+	GetDecisions(sessionID string, query *Query) ([]*types.DecisionData, error)
+	UpdateDecision(sessionID, id string, update func(*types.DecisionData) error) error
+
+	// Visual data
+	AddVisualData(sessionID string, visual *types.VisualData) error
+	GetVisualData(sessionID string, query *Query) ([]*types.VisualData, error)
+	UpdateVisualData(sessionID, id string, update func(*types.VisualData) error) error
+
+	// Critiques
+	AddCritique(sessionID string, critique *types.CritiqueData) error
+	GetCritiques(sessionID string, query *Query) ([]*types.CritiqueData, error)
+
+	// Scenario analyses
+	AddScenario(sessionID string, scenario *types.ScenarioData) error
+	GetScenarios(sessionID string, query *Query) ([]*types.ScenarioData, error)
+
+	// AddBatch adds records of any type to a session in one call
+	AddBatch(sessionID string, batch *Batch) (*BatchResult, error)
+
+	// Transaction calls fn to stage writes to a session and then applies
+	// them atomically: all are committed or none are. An error from fn
+	// abandons the transaction.
+	Transaction(sessionID string, fn func(tx *Tx) error) error
+
+	// Sessions
+	ListSessions() ([]string, error)
+	GetSession(sessionID string) (*SessionData, error)
+	CreateSession(sessionID string) (*SessionData, error)
+	ClearSession(sessionID string) error
+	ArchiveSession(sessionID string) error
+	RestoreSession(sessionID string) error
+	GetSessionStats(sessionID string) (*types.SessionStatistics, error)
+
+	// StorageStats reports record counts and sizes across all sessions
+	StorageStats() (*types.StorageStats, error)
+
+	// Export
+	ExportSession(sessionID string) (*types.SessionExport, error)
+
+	// Ping checks that the backend can be reached
+	Ping(ctx context.Context) error
+
+	// Close releases any resources held by the backend
+	Close() error
+}
+
+// Factory creates a Store from configuration
+type Factory func(cfg *config.Config) (Store, error)
+
+var (
+	backendsMutex sync.RWMutex
+	backends      = make(map[string]Factory)
+)
 
-	return nil
+// cgoBackends are the backends that need cgo, and so are missing from
+// binaries built with CGO_ENABLED=0
+var cgoBackends = map[string]bool{"sqlite": true}
+
+func init() {
+	Register("memory", func(cfg *config.Config) (Store, error) {
+		store := NewMemoryStore(cfg)
+		if cfg.EnablePersistence && cfg.PersistencePath != "" {
+			if err := store.StartSnapshots(SnapshotPath(cfg), cfg.SnapshotInterval); err != nil {
+				return nil, err
+			}
+		}
+		store.StartSweeper(cfg.SessionSweepInterval)
+		return store, nil
+	})
 }
 
-// GetThoughts retrieves all thoughts for a session
-func (s *Storage) GetThoughts(sessionID string) ([]*types.ThoughtData, error) {
-	s.thoughtsMutex.RLock()
-	defer s.thoughtsMutex.RUnlock()
+// Register makes a storage backend available under the given name.
+// It panics if a backend is registered twice under the same name.
+func Register(name string, factory Factory) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
 
-	var sessionThoughts []*types.ThoughtData
-	for _, thought := range s.thoughts {
-		// In a real implementation, you'd filter by session ID
-		sessionThoughts = append(sessionThoughts, thought)
+	if _, exists := backends[name]; exists {
+		panic(fmt.Sprintf("storage: backend %s registered twice", name))
 	}
-
-	return sessionThoughts, nil
+	backends[name] = factory
 }
 
-// ============================================================================
-// Mental Model Management
-// ============================================================================
-
-// AddMentalModel adds a mental model application to storage
-func (s *Storage) AddMentalModel(sessionID string, model *types.MentalModelData) error {
-	s.mentalModelsMutex.Lock()
-	defer s.mentalModelsMutex.Unlock()
+// Backends returns the names of all registered storage backends
+func Backends() []string {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
 
-	if model.ID == "" {
-		model.ID = generateID()
+	var names []string
+	for name := range backends {
+		names = append(names, name)
 	}
-	model.CreatedAt = time.Now()
-
-	s.mentalModels[model.ID] = model
-
-	// Update session
-	session := s.getSession(sessionID)
-	session.LastAccessedAt = time.Now()
-	s.sessions[sessionID] = session
+	sort.Strings(names)
 
-	s.logger.WithFields(logrus.Fields{
-		"session_id": sessionID,
-		"model_id":   model.ID,
-		"model_name": model.ModelName,
-	}).Debug("Added mental model to storage")
-
-	return nil
+	return names
 }
 
-// GetMentalModels retrieves all mental models for a session
-func (s *Storage) GetMentalModels(sessionID string) ([]*types.MentalModelData, error) {
-	s.mentalModelsMutex.RLock()
-	defer s.mentalModelsMutex.RUnlock()
-
-	var sessionModels []*types.MentalModelData
-	for _, model := range s.mentalModels {
-		sessionModels = append(sessionModels, model)
+// New creates the storage backend selected by the configuration. The returned
+// store is an *EventStore publishing every write.
+func New(cfg *config.Config) (Store, error) {
+	name := cfg.StorageBackend
+	if name == "" {
+		name = "memory"
 	}
 
-	return sessionModels, nil
-}
-
-// ============================================================================
-// Stochastic Algorithm Management
-// ============================================================================
-
-// AddStochasticAlgorithm adds a stochastic algorithm result to storage
-func (s *Storage) AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error {
-	s.stochasticAlgorithmsMutex.Lock()
-	defer s.stochasticAlgorithmsMutex.Unlock()
+	backendsMutex.RLock()
+	factory, exists := backends[name]
+	backendsMutex.RUnlock()
 
-	if algorithm.ID == "" {
-		algorithm.ID = generateID()
+	if !exists && cgoBackends[name] {
+		return nil, fmt.Errorf("%s backend not compiled in: it needs a build with CGO_ENABLED=1 (available: %v)", name, Backends())
 	}
-	algorithm.CreatedAt = time.Now()
-
-	s.stochasticAlgorithms[algorithm.ID] = algorithm
-
-	// Update session
-	session := s.getSession(sessionID)
-	session.LastAccessedAt = time.Now()
-	s.sessions[sessionID] = session
-
-	s.logger.WithFields(logrus.Fields{
-		"session_id":   sessionID,
-		"algorithm_id": algorithm.ID,
-		"algorithm":    algorithm.Algorithm,
-	}).Debug("Added stochastic algorithm to storage")
-
-	return nil
-}
-
-// GetStochasticAlgorithms retrieves all stochastic algorithms for a session
-func (s *Storage) GetStochasticAlgorithms(sessionID string) ([]*types.StochasticAlgorithmData, error) {
-	s.stochasticAlgorithmsMutex.RLock()
-	defer s.stochasticAlgorithmsMutex.RUnlock()
-
-	var sessionAlgorithms []*types.StochasticAlgorithmData
-	for _, algorithm := range s.stochasticAlgorithms {
-		sessionAlgorithms = append(sessionAlgorithms, algorithm)
+	if !exists {
+		return nil, fmt.Errorf("unknown storage backend %q (available: %v)", name, Backends())
 	}
 
-	return sessionAlgorithms, nil
-}
-
-// ============================================================================
-// Decision Management
-// ============================================================================
-
-// AddDecision adds a decision framework to storage
-func (s *Storage) AddDecision(sessionID string, decision *types.DecisionData) error {
-	s.decisionsMutex.Lock()
-	defer s.decisionsMutex.Unlock()
-
-	if decision.ID == "" {
-		decision.ID = generateID()
+	store, err := factory(cfg)
+	if err != nil {
+		return nil, err
 	}
-	decision.CreatedAt = time.Now()
-
-	s.decisions[decision.ID] = decision
 
-	// Update session
-	session := s.getSession(sessionID)
-	session.LastAccessedAt = time.Now()
-	s.sessions[sessionID] = session
-
-	s.logger.WithFields(logrus.Fields{
-		"session_id":    sessionID,
-		"decision_id":   decision.ID,
-		"analysis_type": decision.AnalysisType,
-	}).Debug("Added decision to storage")
-
-	return nil
-}
-
-// GetDecisions retrieves all decisions for a session
-func (s *Storage) GetDecisions(sessionID string) ([]*types.DecisionData, error) {
-	s.decisionsMutex.RLock()
-	defer s.decisionsMutex.RUnlock()
-
-	var sessionDecisions []*types.DecisionData
-	for _, decision := range s.decisions {
-		sessionDecisions = append(sessionDecisions, decision)
+	if cfg.EnableJournal {
+		journaled, err := NewJournaledStore(store, JournalPath(cfg))
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		store = journaled
 	}
 
-	return sessionDecisions, nil
+	return NewEventStore(store, NewEventBus()), nil
 }
 
-// ============================================================================
-// Visual Data Management
-// ============================================================================
-
-// AddVisualData adds visual data to storage
-func (s *Storage) AddVisualData(sessionID string, visual *types.VisualData) error {
-	s.visualDataMutex.Lock()
-	defer s.visualDataMutex.Unlock()
-
-	if visual.ID == "" {
-		visual.ID = generateID()
-	}
-	visual.CreatedAt = time.Now()
-
-	s.visualData[visual.ID] = visual
-
-	// Update session
-	session := s.getSession(sessionID)
-	session.LastAccessedAt = time.Now()
-	s.sessions[sessionID] = session
-
-	s.logger.WithFields(logrus.Fields{
-		"session_id":   sessionID,
-		"visual_id":    visual.ID,
-		"diagram_type": visual.DiagramType,
-	}).Debug("Added visual data to storage")
-
-	return nil
+// SessionData represents session-specific data
+type SessionData struct {
+	ID                string    `json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	LastAccessedAt    time.Time `json:"last_accessed_at"`
+	ThoughtCount      int       `json:"thought_count"`
+	ToolsUsed         []string  `json:"tools_used"`
+	TotalOperations   int       `json:"total_operations"`
+	IsActive          bool      `json:"is_active"`
+	RemainingThoughts int       `json:"remaining_thoughts"`
+	// ToolCounts is the number of records each tool has added to the session
+	ToolCounts map[string]int `json:"tool_counts,omitempty"`
+	// Archived sessions are read-only and exempt from expiry
+	Archived   bool       `json:"archived,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
-// GetVisualData retrieves all visual data for a session
-func (s *Storage) GetVisualData(sessionID string) ([]*types.VisualData, error) {
-	s.visualDataMutex.RLock()
-	defer s.visualDataMutex.RUnlock()
-
-	var sessionVisuals []*types.VisualData
-	for _, visual := range s.visualData {
-		sessionVisuals = append(sessionVisuals, visual)
+// recordOperation counts a record added by tool against the session
+func (session *SessionData) recordOperation(tool string) {
+	if session.ToolCounts == nil {
+		session.ToolCounts = make(map[string]int)
 	}
-
-	return sessionVisuals, nil
-}
-
-// ============================================================================
-// Session Management
-// ============================================================================
-
-// GetSession retrieves session data
-func (s *Storage) GetSession(sessionID string) (*SessionData, error) {
-	s.sessionsMutex.RLock()
-	defer s.sessionsMutex.RUnlock()
-
-	session, exists := s.sessions[sessionID]
-	if !exists {
-		return nil, fmt.Errorf("session %s not found", sessionID)
+	if session.ToolCounts[tool] == 0 {
+		session.ToolsUsed = append(session.ToolsUsed, tool)
 	}
-
-	return session, nil
+	session.ToolCounts[tool]++
+	session.TotalOperations++
 }
 
-// CreateSession creates a new session
-func (s *Storage) CreateSession(sessionID string) (*SessionData, error) {
-	s.sessionsMutex.Lock()
-	defer s.sessionsMutex.Unlock()
-
-	session := &SessionData{
-		ID:                sessionID,
-		CreatedAt:         time.Now(),
-		LastAccessedAt:    time.Now(),
-		ThoughtCount:      0,
-		ToolsUsed:         []string{},
-		TotalOperations:   0,
-		IsActive:          true,
-		RemainingThoughts: s.config.MaxThoughtsPerSession,
-	}
-
-	s.sessions[sessionID] = session
-
-	s.logger.WithField("session_id", sessionID).Debug("Created new session")
-
-	return session, nil
-}
-
-// getSession gets or creates a session
-func (s *Storage) getSession(sessionID string) *SessionData {
-	s.sessionsMutex.Lock()
-	defer s.sessionsMutex.Unlock()
-
-	session, exists := s.sessions[sessionID]
-	if !exists {
-		session = &SessionData{
-			ID:                sessionID,
-			CreatedAt:         time.Now(),
-			LastAccessedAt:    time.Now(),
-			ThoughtCount:      0,
-			ToolsUsed:         []string{},
-			TotalOperations:   0,
-			IsActive:          true,
-			RemainingThoughts: s.config.MaxThoughtsPerSession,
+// clone returns a copy of the session that shares no mutable state
+func (session *SessionData) clone() *SessionData {
+	copied := *session
+	copied.ToolsUsed = append([]string{}, session.ToolsUsed...)
+	if session.ToolCounts != nil {
+		copied.ToolCounts = make(map[string]int, len(session.ToolCounts))
+		for tool, count := range session.ToolCounts {
+			copied.ToolCounts[tool] = count
 		}
-		s.sessions[sessionID] = session
 	}
-
-	return session
+	return &copied
 }
 
-// GetSessionStats retrieves comprehensive session statistics
-func (s *Storage) GetSessionStats(sessionID string) (*types.SessionStatistics, error) {
-	session := s.getSession(sessionID)
-
-	thoughts, _ := s.GetThoughts(sessionID)
-	mentalModels, _ := s.GetMentalModels(sessionID)
-	stochasticAlgorithms, _ := s.GetStochasticAlgorithms(sessionID)
-	decisions, _ := s.GetDecisions(sessionID)
-	visualData, _ := s.GetVisualData(sessionID)
-
-	// Collect tools used
-	toolsUsed := make(map[string]bool)
-	if len(thoughts) > 0 {
-		toolsUsed["sequential-thinking"] = true
-	}
-	if len(mentalModels) > 0 {
-		toolsUsed["mental-model"] = true
-	}
-	for _, algorithm := range stochasticAlgorithms {
-		toolsUsed["stochastic-"+algorithm.Algorithm] = true
-	}
-	if len(decisions) > 0 {
-		toolsUsed["decision-framework"] = true
-	}
-	for _, visual := range visualData {
-		toolsUsed["visual-"+visual.DiagramType] = true
-	}
-
-	var toolsList []string
-	for tool := range toolsUsed {
-		toolsList = append(toolsList, tool)
+// archiveTransition moves a session into or out of the archive
+func archiveTransition(session *SessionData, archived bool, now time.Time) error {
+	if session.Archived == archived {
+		if archived {
+			return errorf(ErrSessionArchived, "session %s is already archived", session.ID)
+		}
+		return errorf(ErrSessionNotArchived, "session %s is not archived", session.ID)
 	}
 
-	stats := &types.SessionStatistics{
-		SessionID:         sessionID,
-		CreatedAt:         session.CreatedAt,
-		LastAccessedAt:    session.LastAccessedAt,
-		ThoughtCount:      len(thoughts),
-		ToolsUsed:         toolsList,
-		TotalOperations:   len(thoughts) + len(mentalModels) + len(stochasticAlgorithms) + len(decisions) + len(visualData),
-		IsActive:          session.IsActive,
-		RemainingThoughts: s.config.MaxThoughtsPerSession - len(thoughts),
-		Stores: map[string]interface{}{
-			"thoughts":              map[string]int{"count": len(thoughts)},
-			"mental_models":         map[string]int{"count": len(mentalModels)},
-			"stochastic_algorithms": map[string]int{"count": len(stochasticAlgorithms)},
-			"decisions":             map[string]int{"count": len(decisions)},
-			"visual_data":           map[string]int{"count": len(visualData)},
-		},
+	session.Archived = archived
+	session.IsActive = !archived
+	if archived {
+		session.ArchivedAt = &now
+	} else {
+		session.ArchivedAt = nil
 	}
 
-	return stats, nil
+	return nil
 }
 
 // ============================================================================
-// Export/Import
+// Utility Functions
 // ============================================================================
 
-// ExportSession exports session data
-func (s *Storage) ExportSession(sessionID string) (*types.SessionExport, error) {
-	thoughts, _ := s.GetThoughts(sessionID)
-	mentalModels, _ := s.GetMentalModels(sessionID)
-	stochasticAlgorithms, _ := s.GetStochasticAlgorithms(sessionID)
-	decisions, _ := s.GetDecisions(sessionID)
-	visualData, _ := s.GetVisualData(sessionID)
-
-	export := &types.SessionExport{
-		Version:     "1.0.0",
-		Timestamp:   time.Now(),
-		SessionID:   sessionID,
-		SessionType: "hybrid",
-		Data: map[string]interface{}{
-			"thoughts":              thoughts,
-			"mental_models":         mentalModels,
-			"stochastic_algorithms": stochasticAlgorithms,
-			"decisions":             decisions,
-			"visual_data":           visualData,
-		},
-		Metadata: map[string]interface{}{
-			"exported_at": time.Now(),
-			"version":     "0.1.0",
-		},
-	}
+// ErrDuplicateID is returned when a record is added with an ID already in use
+var ErrDuplicateID = errors.New("duplicate record ID")
 
-	return export, nil
+// generateID generates a UUIDv7: unique without coordination, and ordered by
+// creation time so IDs sort the same way as the records they name
+func generateID() string {
+	return uuid.Must(uuid.NewV7()).String()
 }
 
-// ============================================================================
-// Utility Functions
-// ============================================================================
-
-// generateID generates a unique ID
-func generateID() string {
-	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), time.Now().Nanosecond())
+// duplicateID reports a record ID that is already in use
+func duplicateID(kind, id string) error {
+	return fmt.Errorf("%s record %s already exists: %w", kind, id, ErrDuplicateID)
 }
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_ReportsBackendsNotCompiledIn(t *testing.T) {
+	// The sqlite package is not imported here, as in builds without cgo
+	cfg := config.DefaultConfig()
+	cfg.StorageBackend = "sqlite"
+	_, err := New(cfg)
+	assert.ErrorContains(t, err, "sqlite backend not compiled in: it needs a build with CGO_ENABLED=1")
+
+	cfg.StorageBackend = "postgres"
+	_, err = New(cfg)
+	assert.ErrorContains(t, err, `unknown storage backend "postgres"`)
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// EvictionHook is called with a session's export before the session is
+// evicted. Returning an error keeps the session so eviction is retried on the
+// next sweep.
+type EvictionHook func(export *types.SessionExport) error
+
+// SetEvictionHook sets the hook called before expired sessions are evicted
+func (s *MemoryStore) SetEvictionHook(hook EvictionHook) {
+	s.backgroundMutex.Lock()
+	defer s.backgroundMutex.Unlock()
+
+	s.evictionHook = hook
+}
+
+// StartSweeper starts a background goroutine that calls SweepExpired every
+// interval until the store is closed. It does nothing if the sweeper is
+// already running or session expiry is disabled.
+func (s *MemoryStore) StartSweeper(interval time.Duration) {
+	s.backgroundMutex.Lock()
+	defer s.backgroundMutex.Unlock()
+
+	if s.sweeping || s.config.SessionTimeout <= 0 || interval <= 0 {
+		return
+	}
+
+	s.sweeping = true
+	s.runEvery(interval, func() { s.SweepExpired() })
+}
+
+// SweepExpired marks sessions idle for longer than SessionTimeout inactive and
+// evicts sessions idle for longer than SessionTimeout plus SessionGracePeriod.
+// It returns the IDs of the evicted sessions.
+func (s *MemoryStore) SweepExpired() []string {
+	if s.config.SessionTimeout <= 0 {
+		return nil
+	}
+
+	now := s.now()
+	inactiveAfter := s.config.SessionTimeout
+	evictAfter := s.config.SessionTimeout + s.config.SessionGracePeriod
+
+	// Mark idle sessions inactive and collect the ones due for eviction
+	var candidates []string
+	s.sessionsMutex.Lock()
+	for id, session := range s.sessions {
+		if session.Archived {
+			continue
+		}
+		idle := now.Sub(session.LastAccessedAt)
+		if idle >= inactiveAfter && session.IsActive {
+			session.IsActive = false
+			s.logger.WithField("session_id", id).Debug("Session marked inactive")
+		}
+		if idle >= evictAfter {
+			candidates = append(candidates, id)
+		}
+	}
+	s.sessionsMutex.Unlock()
+
+	s.backgroundMutex.Lock()
+	hook := s.evictionHook
+	s.backgroundMutex.Unlock()
+
+	// The session may have been used while the hook ran
+	stillExpired := func(session *SessionData) bool {
+		return !session.Archived && now.Sub(session.LastAccessedAt) >= evictAfter
+	}
+
+	var evicted []string
+	for _, id := range candidates {
+		if s.evict(id, hook, stillExpired) {
+			evicted = append(evicted, id)
+		}
+	}
+
+	s.expiredEvictions.Add(int64(len(evicted)))
+	if len(evicted) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"evicted": len(evicted),
+		}).Info("Evicted expired sessions")
+	}
+
+	return evicted
+}
+
+// evict passes a session's export to hook, if set, and then removes the
+// session if check still approves it. A failing hook keeps the session.
+func (s *MemoryStore) evict(sessionID string, hook EvictionHook, check func(*SessionData) bool) bool {
+	if hook != nil {
+		export, err := s.ExportSession(sessionID)
+		if err == nil {
+			err = hook(export)
+		}
+		if err != nil {
+			s.hookFailures.Add(1)
+			s.logger.WithError(err).WithField("session_id", sessionID).Warn("Eviction hook failed, keeping session")
+			return false
+		}
+	}
+
+	return s.removeSession(sessionID, check)
+}
+
+// removeSession deletes a session and all of its records if check approves
+// the session's current state. Store mutexes are taken before sessionsMutex,
+// matching the order used when records are added.
+func (s *MemoryStore) removeSession(sessionID string, check func(*SessionData) bool) bool {
+	s.thoughtsMutex.Lock()
+	defer s.thoughtsMutex.Unlock()
+	s.mentalModelsMutex.Lock()
+	defer s.mentalModelsMutex.Unlock()
+	s.stochasticAlgorithmsMutex.Lock()
+	defer s.stochasticAlgorithmsMutex.Unlock()
+	s.decisionsMutex.Lock()
+	defer s.decisionsMutex.Unlock()
+	s.visualDataMutex.Lock()
+	defer s.visualDataMutex.Unlock()
+	s.critiquesMutex.Lock()
+	defer s.critiquesMutex.Unlock()
+	s.scenariosMutex.Lock()
+	defer s.scenariosMutex.Unlock()
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	if exists && check != nil && !check(session) {
+		return false
+	}
+	delete(s.sessions, sessionID)
+	delete(s.usage, sessionID)
+
+	for _, id := range s.thoughtsBySession[sessionID] {
+		delete(s.thoughts, id)
+	}
+	delete(s.thoughtsBySession, sessionID)
+
+	for _, id := range s.mentalModelsBySession[sessionID] {
+		delete(s.mentalModels, id)
+	}
+	delete(s.mentalModelsBySession, sessionID)
+
+	for _, id := range s.stochasticAlgorithmsBySession[sessionID] {
+		delete(s.stochasticAlgorithms, id)
+	}
+	delete(s.stochasticAlgorithmsBySession, sessionID)
+
+	for _, id := range s.decisionsBySession[sessionID] {
+		delete(s.decisions, id)
+	}
+	delete(s.decisionsBySession, sessionID)
+
+	for _, id := range s.visualDataBySession[sessionID] {
+		delete(s.visualData, id)
+	}
+	delete(s.visualDataBySession, sessionID)
+
+	for _, id := range s.critiquesBySession[sessionID] {
+		delete(s.critiques, id)
+	}
+	delete(s.critiquesBySession, sessionID)
+
+	for _, id := range s.scenariosBySession[sessionID] {
+		delete(s.scenarios, id)
+	}
+	delete(s.scenariosBySession, sessionID)
+
+	return exists
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSweepTestStore(t *testing.T) (*MemoryStore, *time.Time) {
+	cfg := config.DefaultConfig()
+	cfg.SessionTimeout = 10 * time.Minute
+	cfg.SessionGracePeriod = 5 * time.Minute
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(cfg)
+	store.SetClock(func() time.Time { return now })
+
+	require.NoError(t, store.AddThought("s1", &types.ThoughtData{Thought: "idle"}))
+	return store, &now
+}
+
+func TestSweepExpired_MarksInactiveThenEvicts(t *testing.T) {
+	store, now := newSweepTestStore(t)
+
+	*now = now.Add(11 * time.Minute)
+	assert.Empty(t, store.SweepExpired())
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.False(t, session.IsActive)
+
+	var exported []string
+	store.SetEvictionHook(func(export *types.SessionExport) error {
+		exported = append(exported, export.SessionID)
+		return nil
+	})
+
+	*now = now.Add(5 * time.Minute)
+	assert.Equal(t, []string{"s1"}, store.SweepExpired())
+	assert.Equal(t, []string{"s1"}, exported)
+
+	_, err = store.GetSession("s1")
+	assert.Error(t, err)
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+}
+
+func TestSweepExpired_ActivityReactivatesSession(t *testing.T) {
+	store, now := newSweepTestStore(t)
+
+	*now = now.Add(11 * time.Minute)
+	store.SweepExpired()
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{DecisionStatement: "resume"}))
+
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.True(t, session.IsActive)
+
+	*now = now.Add(14 * time.Minute)
+	assert.Empty(t, store.SweepExpired())
+}
+
+func TestSweepExpired_HookFailureKeepsSession(t *testing.T) {
+	store, now := newSweepTestStore(t)
+	store.SetEvictionHook(func(export *types.SessionExport) error {
+		return errors.New("disk full")
+	})
+
+	*now = now.Add(time.Hour)
+	assert.Empty(t, store.SweepExpired())
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
+
+func TestSweepExpired_SkipsArchivedSessions(t *testing.T) {
+	store, now := newSweepTestStore(t)
+	require.NoError(t, store.ArchiveSession("s1"))
+
+	*now = now.Add(24 * time.Hour)
+	assert.Empty(t, store.SweepExpired())
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, thoughts, 1)
+}
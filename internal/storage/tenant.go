@@ -0,0 +1,447 @@
+package storage
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// tenantPrefix marks the session IDs of tenant namespaces. Session IDs in the
+// shared namespace may not use it, so no client can address another tenant's
+// sessions directly.
+const tenantPrefix = "tenant:"
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// ValidateTenantID reports whether id is a usable tenant ID. The empty ID
+// selects the shared namespace.
+func ValidateTenantID(id string) error {
+	if id != "" && !tenantIDPattern.MatchString(id) {
+		return errorf(ErrInvalidArgument, "invalid tenant ID %q: use 1-64 letters, digits, '.', '_' or '-'", id)
+	}
+	return nil
+}
+
+// TenantSessionID returns the ID under which a tenant's session is stored
+func TenantSessionID(tenantID, sessionID string) string {
+	if tenantID == "" {
+		return sessionID
+	}
+	return tenantPrefix + tenantID + "/" + sessionID
+}
+
+// SplitTenantSessionID splits a stored session ID into its tenant and the
+// session ID the tenant uses. The tenant is empty for the shared namespace.
+func SplitTenantSessionID(storedID string) (string, string) {
+	scoped, ok := strings.CutPrefix(storedID, tenantPrefix)
+	if !ok {
+		return "", storedID
+	}
+	tenantID, sessionID, _ := strings.Cut(scoped, "/")
+	return tenantID, sessionID
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context carrying the tenant of a request
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant carried by ctx, or "" for the shared namespace
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+// TenantStore scopes a Store to one tenant. Session IDs are mapped into the
+// tenant's namespace on the way in and back out of it on the way out, so
+// sessions, stats, search and exports never cross tenants.
+type TenantStore struct {
+	store    Store
+	tenantID string
+}
+
+// ForTenant returns store scoped to tenantID, or to the shared namespace when
+// tenantID is empty. The tenant ID must have passed ValidateTenantID.
+func ForTenant(store Store, tenantID string) *TenantStore {
+	return &TenantStore{store: store, tenantID: tenantID}
+}
+
+// scope maps a session ID into the tenant's namespace
+func (s *TenantStore) scope(sessionID string) (string, error) {
+	if strings.HasPrefix(sessionID, tenantPrefix) {
+		return "", errorf(ErrInvalidArgument, "session IDs may not start with %q", tenantPrefix)
+	}
+	return TenantSessionID(s.tenantID, sessionID), nil
+}
+
+// unscope maps a stored session ID back out of the tenant's namespace,
+// reporting false for sessions of other namespaces
+func (s *TenantStore) unscope(storedID string) (string, bool) {
+	if s.tenantID == "" {
+		return storedID, !strings.HasPrefix(storedID, tenantPrefix)
+	}
+	namespace := TenantSessionID(s.tenantID, "")
+	if !strings.HasPrefix(storedID, namespace) {
+		return "", false
+	}
+	return strings.TrimPrefix(storedID, namespace), true
+}
+
+// addScoped adds a copy of record under the scoped session ID and reports the
+// assigned ID and creation time back on record, leaving the stored copy intact
+func addScoped[T any](s *TenantStore, sessionID string, record *T, identity identityFunc[T], add func(string, *T) error) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+
+	copied := *record
+	if err := add(scoped, &copied); err != nil {
+		return err
+	}
+
+	id, _, createdAt := identity(&copied)
+	recordID, recordSession, recordCreatedAt := identity(record)
+	*recordID, *recordSession, *recordCreatedAt = *id, sessionID, *createdAt
+
+	return nil
+}
+
+// getScoped returns copies of the session's records carrying the caller's session ID
+func getScoped[T any](s *TenantStore, sessionID string, query *Query, identity identityFunc[T], get func(string, *Query) ([]*T, error)) ([]*T, error) {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := get(scoped, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return unscopeRecords(records, identity, sessionID), nil
+}
+
+// unscopeRecords copies records, replacing their session ID
+func unscopeRecords[T any](records []*T, identity identityFunc[T], sessionID string) []*T {
+	copies := make([]*T, len(records))
+	for i, record := range records {
+		copied := *record
+		_, recordSession, _ := identity(&copied)
+		*recordSession = sessionID
+		copies[i] = &copied
+	}
+	return copies
+}
+
+func critiqueIdentity(r *types.CritiqueData) (*string, *string, *time.Time) {
+	return &r.ID, &r.SessionID, &r.CreatedAt
+}
+
+func scenarioIdentity(r *types.ScenarioData) (*string, *string, *time.Time) {
+	return &r.ID, &r.SessionID, &r.CreatedAt
+}
+
+// AddThought adds a thought to the tenant's session
+func (s *TenantStore) AddThought(sessionID string, thought *types.ThoughtData) error {
+	return addScoped(s, sessionID, thought, thoughtIdentity, s.store.AddThought)
+}
+
+// GetThoughts retrieves the thoughts of the tenant's session
+func (s *TenantStore) GetThoughts(sessionID string, query *Query) ([]*types.ThoughtData, error) {
+	return getScoped(s, sessionID, query, thoughtIdentity, s.store.GetThoughts)
+}
+
+// UpdateThought revises a thought of the tenant's session
+func (s *TenantStore) UpdateThought(sessionID, id string, update func(*types.ThoughtData) error) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateThought(scoped, id, update)
+}
+
+// AddMentalModel adds a mental model application to the tenant's session
+func (s *TenantStore) AddMentalModel(sessionID string, model *types.MentalModelData) error {
+	return addScoped(s, sessionID, model, mentalModelIdentity, s.store.AddMentalModel)
+}
+
+// GetMentalModels retrieves the mental models of the tenant's session
+func (s *TenantStore) GetMentalModels(sessionID string, query *Query) ([]*types.MentalModelData, error) {
+	return getScoped(s, sessionID, query, mentalModelIdentity, s.store.GetMentalModels)
+}
+
+// UpdateMentalModel revises a mental model application of the tenant's session
+func (s *TenantStore) UpdateMentalModel(sessionID, id string, update func(*types.MentalModelData) error) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateMentalModel(scoped, id, update)
+}
+
+// AddStochasticAlgorithm adds a stochastic algorithm result to the tenant's session
+func (s *TenantStore) AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error {
+	return addScoped(s, sessionID, algorithm, algorithmIdentity, s.store.AddStochasticAlgorithm)
+}
+
+// GetStochasticAlgorithms retrieves the stochastic algorithm results of the tenant's session
+func (s *TenantStore) GetStochasticAlgorithms(sessionID string, query *Query) ([]*types.StochasticAlgorithmData, error) {
+	return getScoped(s, sessionID, query, algorithmIdentity, s.store.GetStochasticAlgorithms)
+}
+
+// UpdateStochasticAlgorithm revises a stochastic algorithm result of the tenant's session
+func (s *TenantStore) UpdateStochasticAlgorithm(sessionID, id string, update func(*types.StochasticAlgorithmData) error) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateStochasticAlgorithm(scoped, id, update)
+}
+
+// AddDecision adds a decision to the tenant's session
+func (s *TenantStore) AddDecision(sessionID string, decision *types.DecisionData) error {
+	return addScoped(s, sessionID, decision, decisionIdentity, s.store.AddDecision)
+}
+
+// GetDecisions retrieves the decisions of the tenant's session
+func (s *TenantStore) GetDecisions(sessionID string, query *Query) ([]*types.DecisionData, error) {
+	return getScoped(s, sessionID, query, decisionIdentity, s.store.GetDecisions)
+}
+
+// UpdateDecision revises a decision of the tenant's session
+func (s *TenantStore) UpdateDecision(sessionID, id string, update func(*types.DecisionData) error) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateDecision(scoped, id, update)
+}
+
+// AddVisualData adds visual data to the tenant's session
+func (s *TenantStore) AddVisualData(sessionID string, visual *types.VisualData) error {
+	return addScoped(s, sessionID, visual, visualIdentity, s.store.AddVisualData)
+}
+
+// GetVisualData retrieves the visual data of the tenant's session
+func (s *TenantStore) GetVisualData(sessionID string, query *Query) ([]*types.VisualData, error) {
+	return getScoped(s, sessionID, query, visualIdentity, s.store.GetVisualData)
+}
+
+// UpdateVisualData revises visual data of the tenant's session
+func (s *TenantStore) UpdateVisualData(sessionID, id string, update func(*types.VisualData) error) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.UpdateVisualData(scoped, id, update)
+}
+
+// AddCritique adds a critique to the tenant's session
+func (s *TenantStore) AddCritique(sessionID string, critique *types.CritiqueData) error {
+	return addScoped(s, sessionID, critique, critiqueIdentity, s.store.AddCritique)
+}
+
+// GetCritiques retrieves the critiques of the tenant's session
+func (s *TenantStore) GetCritiques(sessionID string, query *Query) ([]*types.CritiqueData, error) {
+	return getScoped(s, sessionID, query, critiqueIdentity, s.store.GetCritiques)
+}
+
+// AddScenario adds a scenario analysis to the tenant's session
+func (s *TenantStore) AddScenario(sessionID string, scenario *types.ScenarioData) error {
+	return addScoped(s, sessionID, scenario, scenarioIdentity, s.store.AddScenario)
+}
+
+// GetScenarios retrieves the scenario analyses of the tenant's session
+func (s *TenantStore) GetScenarios(sessionID string, query *Query) ([]*types.ScenarioData, error) {
+	return getScoped(s, sessionID, query, scenarioIdentity, s.store.GetScenarios)
+}
+
+// AddBatch adds the records of a batch to the tenant's session
+func (s *TenantStore) AddBatch(sessionID string, batch *Batch) (*BatchResult, error) {
+	return addBatch(s, sessionID, batch)
+}
+
+// Transaction applies the writes staged by fn to the tenant's session atomically
+func (s *TenantStore) Transaction(sessionID string, fn func(tx *Tx) error) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+
+	var staged *Tx
+	err = s.store.Transaction(scoped, func(tx *Tx) error {
+		staged = tx
+		return fn(tx)
+	})
+	if err != nil || staged == nil {
+		return err
+	}
+
+	// The wrapped store reported the scoped session ID
+	staged.report(sessionID)
+	return nil
+}
+
+// ListSessions returns the IDs of the tenant's sessions
+func (s *TenantStore) ListSessions() ([]string, error) {
+	ids, err := s.store.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var own []string
+	for _, id := range ids {
+		if sessionID, ok := s.unscope(id); ok {
+			own = append(own, sessionID)
+		}
+	}
+	return own, nil
+}
+
+// GetSession retrieves the tenant's session
+func (s *TenantStore) GetSession(sessionID string) (*SessionData, error) {
+	return s.sessionCall(sessionID, s.store.GetSession)
+}
+
+// CreateSession creates a session in the tenant's namespace
+func (s *TenantStore) CreateSession(sessionID string) (*SessionData, error) {
+	return s.sessionCall(sessionID, s.store.CreateSession)
+}
+
+// sessionCall runs a session lookup in the tenant's namespace and returns a
+// copy of the session carrying the caller's session ID
+func (s *TenantStore) sessionCall(sessionID string, call func(string) (*SessionData, error)) (*SessionData, error) {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := call(scoped)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := session.clone()
+	copied.ID = sessionID
+	return copied, nil
+}
+
+// ClearSession removes the tenant's session and all of its records
+func (s *TenantStore) ClearSession(sessionID string) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.ClearSession(scoped)
+}
+
+// ArchiveSession archives the tenant's session
+func (s *TenantStore) ArchiveSession(sessionID string) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.ArchiveSession(scoped)
+}
+
+// RestoreSession restores the tenant's archived session
+func (s *TenantStore) RestoreSession(sessionID string) error {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.store.RestoreSession(scoped)
+}
+
+// GetSessionStats retrieves statistics for the tenant's session
+func (s *TenantStore) GetSessionStats(sessionID string) (*types.SessionStatistics, error) {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.store.GetSessionStats(scoped)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.SessionID = sessionID
+	return stats, nil
+}
+
+// StorageStats reports storage held by the tenant's sessions only. Eviction
+// counters cover the whole store, so only the shared namespace sees them.
+func (s *TenantStore) StorageStats() (*types.StorageStats, error) {
+	stats, err := s.store.StorageStats()
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes []types.SessionSize
+	for _, size := range stats.SessionSizes {
+		if sessionID, ok := s.unscope(size.SessionID); ok {
+			size.SessionID = sessionID
+			sizes = append(sizes, size)
+		}
+	}
+
+	evictions := stats.Evictions
+	if s.tenantID != "" {
+		evictions = nil
+	}
+
+	return summarizeStorage(stats.Backend, sizes, evictions), nil
+}
+
+// ExportSession exports the tenant's session with its records' session IDs
+// mapped back out of the tenant namespace
+func (s *TenantStore) ExportSession(sessionID string) (*types.SessionExport, error) {
+	scoped, err := s.scope(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := s.store.ExportSession(scoped)
+	if err != nil {
+		return nil, err
+	}
+	if scoped == sessionID {
+		return export, nil
+	}
+
+	data, err := decodeExportData(export.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *export
+	copied.SessionID = sessionID
+	copied.Data = map[string]interface{}{
+		KindThoughts:             unscopeRecords(data.Thoughts, thoughtIdentity, sessionID),
+		KindMentalModels:         unscopeRecords(data.MentalModels, mentalModelIdentity, sessionID),
+		KindStochasticAlgorithms: unscopeRecords(data.StochasticAlgorithms, algorithmIdentity, sessionID),
+		KindDecisions:            unscopeRecords(data.Decisions, decisionIdentity, sessionID),
+		KindVisualData:           unscopeRecords(data.VisualData, visualIdentity, sessionID),
+		KindCritiques:            unscopeRecords(data.Critiques, critiqueIdentity, sessionID),
+		KindScenarios:            unscopeRecords(data.Scenarios, scenarioIdentity, sessionID),
+	}
+
+	return &copied, nil
+}
+
+// Ping checks the wrapped store
+func (s *TenantStore) Ping(ctx context.Context) error {
+	return s.store.Ping(ctx)
+}
+
+// Close does nothing: the wrapped store is shared between tenants and is
+// closed by its owner
+func (s *TenantStore) Close() error {
+	return nil
+}
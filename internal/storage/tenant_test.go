@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantStore_IsolatesSessions(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	acme := ForTenant(store, "acme")
+	globex := ForTenant(store, "globex")
+
+	thought := &types.ThoughtData{Thought: "Acme only", ThoughtNumber: 1, TotalThoughts: 1}
+	require.NoError(t, acme.AddThought("s1", thought))
+	assert.Equal(t, "s1", thought.SessionID)
+	assert.NotEmpty(t, thought.ID)
+
+	thoughts, err := acme.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "s1", thoughts[0].SessionID)
+
+	thoughts, err = globex.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+
+	thoughts, err = store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+
+	// The stored record keeps its scoped session ID
+	thoughts, err = store.GetThoughts(TenantSessionID("acme", "s1"), nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, TenantSessionID("acme", "s1"), thoughts[0].SessionID)
+
+	stats, err := acme.GetSessionStats("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", stats.SessionID)
+	assert.Equal(t, 1, stats.ThoughtCount)
+
+	export, err := acme.ExportSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", export.SessionID)
+	data, err := decodeExportData(export.Data)
+	require.NoError(t, err)
+	require.Len(t, data.Thoughts, 1)
+	assert.Equal(t, "s1", data.Thoughts[0].SessionID)
+
+	err = globex.UpdateThought("s1", thought.ID, func(*types.ThoughtData) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestTenantStore_RejectsReservedSessionIDs(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, ForTenant(store, "acme").AddThought("s1", &types.ThoughtData{Thought: "secret"}))
+
+	for _, tenantID := range []string{"", "globex"} {
+		_, err := ForTenant(store, tenantID).GetThoughts(TenantSessionID("acme", "s1"), nil)
+		assert.Error(t, err, "tenant %q", tenantID)
+	}
+}
+
+func TestValidateTenantID(t *testing.T) {
+	assert.NoError(t, ValidateTenantID(""))
+	assert.NoError(t, ValidateTenantID("acme-prod.eu_1"))
+	assert.Error(t, ValidateTenantID("acme/other"))
+	assert.Error(t, ValidateTenantID("tenant:acme"))
+
+	ctx := WithTenant(context.Background(), "acme")
+	assert.Equal(t, "acme", TenantFromContext(ctx))
+	assert.Empty(t, TenantFromContext(context.Background()))
+}
+
+func TestTenantStore_StorageStatsCoverOnlyTheTenant(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, ForTenant(store, "acme").AddThought("s1", &types.ThoughtData{Thought: "acme"}))
+	require.NoError(t, ForTenant(store, "globex").AddThought("s1", &types.ThoughtData{Thought: "globex"}))
+	require.NoError(t, store.AddThought("shared", &types.ThoughtData{Thought: "shared"}))
+
+	stats, err := ForTenant(store, "acme").StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Sessions)
+	assert.Equal(t, 1, stats.TotalRecords)
+	require.Len(t, stats.SessionSizes, 1)
+	assert.Equal(t, "s1", stats.SessionSizes[0].SessionID)
+	assert.Nil(t, stats.Evictions)
+
+	stats, err = ForTenant(store, "").StorageStats()
+	require.NoError(t, err)
+	require.Len(t, stats.SessionSizes, 1)
+	assert.Equal(t, "shared", stats.SessionSizes[0].SessionID)
+	assert.NotNil(t, stats.Evictions)
+}
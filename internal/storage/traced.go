@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/rainmana/gothink/internal/telemetry"
+	"github.com/rainmana/gothink/internal/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedStore records an OpenTelemetry span for every call to the wrapped
+// store, as a child of the span in the context it was created with, and
+// refuses calls with the context's error once that context is done, so a
+// cancelled request stops touching storage. Store methods take no context,
+// so a TracedStore is created per request.
+type TracedStore struct {
+	Store
+	ctx context.Context
+}
+
+// WithTracing wraps store so its calls are traced under the span of ctx and
+// stop once ctx is done
+func WithTracing(ctx context.Context, store Store) *TracedStore {
+	return &TracedStore{Store: store, ctx: ctx}
+}
+
+// traced runs fn within a span named after the storage operation, unless the
+// request's context is already done
+func traced[T any](s *TracedStore, op, sessionID string, fn func() (T, error)) (T, error) {
+	if err := s.ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	var attrs []trace.SpanStartOption
+	if sessionID != "" {
+		attrs = append(attrs, trace.WithAttributes(telemetry.AttrSessionID.String(sessionID)))
+	}
+	_, span := telemetry.Tracer().Start(s.ctx, "storage."+op, attrs...)
+
+	result, err := fn()
+	telemetry.End(span, err)
+	return result, err
+}
+
+// tracedErr runs fn, which only returns an error, within a span
+func tracedErr(s *TracedStore, op, sessionID string, fn func() error) error {
+	_, err := traced(s, op, sessionID, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// AddThought traces the addition of a thought
+func (s *TracedStore) AddThought(sessionID string, thought *types.ThoughtData) error {
+	return tracedErr(s, "AddThought", sessionID, func() error { return s.Store.AddThought(sessionID, thought) })
+}
+
+// GetThoughts traces a thought query
+func (s *TracedStore) GetThoughts(sessionID string, query *Query) ([]*types.ThoughtData, error) {
+	return traced(s, "GetThoughts", sessionID, func() ([]*types.ThoughtData, error) { return s.Store.GetThoughts(sessionID, query) })
+}
+
+// UpdateThought traces the revision of a thought
+func (s *TracedStore) UpdateThought(sessionID, id string, update func(*types.ThoughtData) error) error {
+	return tracedErr(s, "UpdateThought", sessionID, func() error { return s.Store.UpdateThought(sessionID, id, update) })
+}
+
+// AddMentalModel traces the addition of a mental model application
+func (s *TracedStore) AddMentalModel(sessionID string, model *types.MentalModelData) error {
+	return tracedErr(s, "AddMentalModel", sessionID, func() error { return s.Store.AddMentalModel(sessionID, model) })
+}
+
+// GetMentalModels traces a mental model query
+func (s *TracedStore) GetMentalModels(sessionID string, query *Query) ([]*types.MentalModelData, error) {
+	return traced(s, "GetMentalModels", sessionID, func() ([]*types.MentalModelData, error) { return s.Store.GetMentalModels(sessionID, query) })
+}
+
+// UpdateMentalModel traces the revision of a mental model application
+func (s *TracedStore) UpdateMentalModel(sessionID, id string, update func(*types.MentalModelData) error) error {
+	return tracedErr(s, "UpdateMentalModel", sessionID, func() error { return s.Store.UpdateMentalModel(sessionID, id, update) })
+}
+
+// AddStochasticAlgorithm traces the addition of a stochastic algorithm result
+func (s *TracedStore) AddStochasticAlgorithm(sessionID string, algorithm *types.StochasticAlgorithmData) error {
+	return tracedErr(s, "AddStochasticAlgorithm", sessionID, func() error { return s.Store.AddStochasticAlgorithm(sessionID, algorithm) })
+}
+
+// GetStochasticAlgorithms traces a stochastic algorithm query
+func (s *TracedStore) GetStochasticAlgorithms(sessionID string, query *Query) ([]*types.StochasticAlgorithmData, error) {
+	return traced(s, "GetStochasticAlgorithms", sessionID, func() ([]*types.StochasticAlgorithmData, error) {
+		return s.Store.GetStochasticAlgorithms(sessionID, query)
+	})
+}
+
+// UpdateStochasticAlgorithm traces the revision of a stochastic algorithm result
+func (s *TracedStore) UpdateStochasticAlgorithm(sessionID, id string, update func(*types.StochasticAlgorithmData) error) error {
+	return tracedErr(s, "UpdateStochasticAlgorithm", sessionID, func() error { return s.Store.UpdateStochasticAlgorithm(sessionID, id, update) })
+}
+
+// AddDecision traces the addition of a decision
+func (s *TracedStore) AddDecision(sessionID string, decision *types.DecisionData) error {
+	return tracedErr(s, "AddDecision", sessionID, func() error { return s.Store.AddDecision(sessionID, decision) })
+}
+
+// GetDecisions traces a decision query
+func (s *TracedStore) GetDecisions(sessionID string, query *Query) ([]*types.DecisionData, error) {
+	return traced(s, "GetDecisions", sessionID, func() ([]*types.DecisionData, error) { return s.Store.GetDecisions(sessionID, query) })
+}
+
+// UpdateDecision traces the revision of a decision
+func (s *TracedStore) UpdateDecision(sessionID, id string, update func(*types.DecisionData) error) error {
+	return tracedErr(s, "UpdateDecision", sessionID, func() error { return s.Store.UpdateDecision(sessionID, id, update) })
+}
+
+// AddVisualData traces the addition of visual data
+func (s *TracedStore) AddVisualData(sessionID string, visual *types.VisualData) error {
+	return tracedErr(s, "AddVisualData", sessionID, func() error { return s.Store.AddVisualData(sessionID, visual) })
+}
+
+// GetVisualData traces a visual data query
+func (s *TracedStore) GetVisualData(sessionID string, query *Query) ([]*types.VisualData, error) {
+	return traced(s, "GetVisualData", sessionID, func() ([]*types.VisualData, error) { return s.Store.GetVisualData(sessionID, query) })
+}
+
+// UpdateVisualData traces the revision of visual data
+func (s *TracedStore) UpdateVisualData(sessionID, id string, update func(*types.VisualData) error) error {
+	return tracedErr(s, "UpdateVisualData", sessionID, func() error { return s.Store.UpdateVisualData(sessionID, id, update) })
+}
+
+// AddCritique traces the addition of a critique
+func (s *TracedStore) AddCritique(sessionID string, critique *types.CritiqueData) error {
+	return tracedErr(s, "AddCritique", sessionID, func() error { return s.Store.AddCritique(sessionID, critique) })
+}
+
+// GetCritiques traces a critique query
+func (s *TracedStore) GetCritiques(sessionID string, query *Query) ([]*types.CritiqueData, error) {
+	return traced(s, "GetCritiques", sessionID, func() ([]*types.CritiqueData, error) { return s.Store.GetCritiques(sessionID, query) })
+}
+
+// AddScenario traces the addition of a scenario analysis
+func (s *TracedStore) AddScenario(sessionID string, scenario *types.ScenarioData) error {
+	return tracedErr(s, "AddScenario", sessionID, func() error { return s.Store.AddScenario(sessionID, scenario) })
+}
+
+// GetScenarios traces a scenario analysis query
+func (s *TracedStore) GetScenarios(sessionID string, query *Query) ([]*types.ScenarioData, error) {
+	return traced(s, "GetScenarios", sessionID, func() ([]*types.ScenarioData, error) { return s.Store.GetScenarios(sessionID, query) })
+}
+
+// AddBatch traces the addition of a batch of records
+func (s *TracedStore) AddBatch(sessionID string, batch *Batch) (*BatchResult, error) {
+	return traced(s, "AddBatch", sessionID, func() (*BatchResult, error) { return s.Store.AddBatch(sessionID, batch) })
+}
+
+// Transaction traces a transaction as a whole
+func (s *TracedStore) Transaction(sessionID string, fn func(tx *Tx) error) error {
+	return tracedErr(s, "Transaction", sessionID, func() error { return s.Store.Transaction(sessionID, fn) })
+}
+
+// ListSessions traces the listing of sessions
+func (s *TracedStore) ListSessions() ([]string, error) {
+	return traced(s, "ListSessions", "", s.Store.ListSessions)
+}
+
+// GetSession traces a session lookup
+func (s *TracedStore) GetSession(sessionID string) (*SessionData, error) {
+	return traced(s, "GetSession", sessionID, func() (*SessionData, error) { return s.Store.GetSession(sessionID) })
+}
+
+// CreateSession traces the creation of a session
+func (s *TracedStore) CreateSession(sessionID string) (*SessionData, error) {
+	return traced(s, "CreateSession", sessionID, func() (*SessionData, error) { return s.Store.CreateSession(sessionID) })
+}
+
+// ClearSession traces the deletion of a session
+func (s *TracedStore) ClearSession(sessionID string) error {
+	return tracedErr(s, "ClearSession", sessionID, func() error { return s.Store.ClearSession(sessionID) })
+}
+
+// ArchiveSession traces the archiving of a session
+func (s *TracedStore) ArchiveSession(sessionID string) error {
+	return tracedErr(s, "ArchiveSession", sessionID, func() error { return s.Store.ArchiveSession(sessionID) })
+}
+
+// RestoreSession traces the return of an archived session to normal use
+func (s *TracedStore) RestoreSession(sessionID string) error {
+	return tracedErr(s, "RestoreSession", sessionID, func() error { return s.Store.RestoreSession(sessionID) })
+}
+
+// GetSessionStats traces a session statistics query
+func (s *TracedStore) GetSessionStats(sessionID string) (*types.SessionStatistics, error) {
+	return traced(s, "GetSessionStats", sessionID, func() (*types.SessionStatistics, error) { return s.Store.GetSessionStats(sessionID) })
+}
+
+// StorageStats traces a storage statistics query
+func (s *TracedStore) StorageStats() (*types.StorageStats, error) {
+	return traced(s, "StorageStats", "", s.Store.StorageStats)
+}
+
+// ExportSession traces a session export
+func (s *TracedStore) ExportSession(sessionID string) (*types.SessionExport, error) {
+	return traced(s, "ExportSession", sessionID, func() (*types.SessionExport, error) { return s.Store.ExportSession(sessionID) })
+}
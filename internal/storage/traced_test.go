@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracedStore_StopsOnceContextIsDone(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	traced := WithTracing(ctx, store)
+
+	require.NoError(t, traced.AddThought("s1", &types.ThoughtData{Thought: "Before", ThoughtNumber: 1, TotalThoughts: 2}))
+
+	cancel()
+	assert.ErrorIs(t, traced.AddThought("s1", &types.ThoughtData{Thought: "After", ThoughtNumber: 2, TotalThoughts: 2}), context.Canceled)
+	_, err := traced.GetThoughts("s1", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Nothing was written after the cancellation
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.Equal(t, "Before", thoughts[0].Thought)
+}
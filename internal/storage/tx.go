@@ -0,0 +1,485 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// Tx stages writes to one session for Store.Transaction. Nothing is written
+// while the transaction function runs; once it returns nil the staged writes
+// are applied in order and either all of them are committed or, if any is
+// rejected, none are. Staged records are copied, and the records passed in
+// receive their assigned ID, session and creation time only on commit.
+type Tx struct {
+	ops      []*txOp
+	onCommit []func() error
+	err      error
+}
+
+// txOp is a single staged write
+type txOp struct {
+	op    string
+	kind  string
+	added bool
+	// apply performs the write on a store
+	apply func(s Store, sessionID string) error
+	// result returns the record ID and the record as written, once applied
+	result func() (string, interface{})
+	// report copies an added record's identity back to the caller's record
+	report func(sessionID string)
+}
+
+// Len returns the number of staged writes
+func (tx *Tx) Len() int {
+	return len(tx.ops)
+}
+
+// AddThought stages a thought to be added
+func (tx *Tx) AddThought(thought *types.ThoughtData) {
+	stageAdd(tx, OpAddThought, KindThoughts, thought, thoughtIdentity, Store.AddThought)
+}
+
+// AddMentalModel stages a mental model application to be added
+func (tx *Tx) AddMentalModel(model *types.MentalModelData) {
+	stageAdd(tx, OpAddMentalModel, KindMentalModels, model, mentalModelIdentity, Store.AddMentalModel)
+}
+
+// AddStochasticAlgorithm stages a stochastic algorithm result to be added
+func (tx *Tx) AddStochasticAlgorithm(algorithm *types.StochasticAlgorithmData) {
+	stageAdd(tx, OpAddStochasticAlgorithm, KindStochasticAlgorithms, algorithm, algorithmIdentity, Store.AddStochasticAlgorithm)
+}
+
+// AddDecision stages a decision to be added
+func (tx *Tx) AddDecision(decision *types.DecisionData) {
+	stageAdd(tx, OpAddDecision, KindDecisions, decision, decisionIdentity, Store.AddDecision)
+}
+
+// AddVisualData stages visual data to be added
+func (tx *Tx) AddVisualData(visual *types.VisualData) {
+	stageAdd(tx, OpAddVisualData, KindVisualData, visual, visualIdentity, Store.AddVisualData)
+}
+
+// AddCritique stages a critique to be added
+func (tx *Tx) AddCritique(critique *types.CritiqueData) {
+	stageAdd(tx, OpAddCritique, KindCritiques, critique, critiqueIdentity, Store.AddCritique)
+}
+
+// AddScenario stages a scenario analysis to be added
+func (tx *Tx) AddScenario(scenario *types.ScenarioData) {
+	stageAdd(tx, OpAddScenario, KindScenarios, scenario, scenarioIdentity, Store.AddScenario)
+}
+
+// UpdateThought stages a revision of a thought
+func (tx *Tx) UpdateThought(id string, update func(*types.ThoughtData) error) {
+	stageUpdate(tx, OpUpdateThought, KindThoughts, id, update, Store.UpdateThought)
+}
+
+// UpdateMentalModel stages a revision of a mental model application
+func (tx *Tx) UpdateMentalModel(id string, update func(*types.MentalModelData) error) {
+	stageUpdate(tx, OpUpdateMentalModel, KindMentalModels, id, update, Store.UpdateMentalModel)
+}
+
+// UpdateStochasticAlgorithm stages a revision of a stochastic algorithm result
+func (tx *Tx) UpdateStochasticAlgorithm(id string, update func(*types.StochasticAlgorithmData) error) {
+	stageUpdate(tx, OpUpdateStochasticAlgorithm, KindStochasticAlgorithms, id, update, Store.UpdateStochasticAlgorithm)
+}
+
+// UpdateDecision stages a revision of a decision
+func (tx *Tx) UpdateDecision(id string, update func(*types.DecisionData) error) {
+	stageUpdate(tx, OpUpdateDecision, KindDecisions, id, update, Store.UpdateDecision)
+}
+
+// UpdateVisualData stages a revision of visual data
+func (tx *Tx) UpdateVisualData(id string, update func(*types.VisualData) error) {
+	stageUpdate(tx, OpUpdateVisualData, KindVisualData, id, update, Store.UpdateVisualData)
+}
+
+// stageAdd stages a copy of record to be added through add
+func stageAdd[T any](tx *Tx, op, kind string, record *T, identity identityFunc[T], add func(Store, string, *T) error) {
+	if record == nil {
+		tx.fail(errorf(ErrInvalidArgument, "transaction contains an empty %s record", kind))
+		return
+	}
+
+	copied := *record
+	tx.ops = append(tx.ops, &txOp{
+		op:    op,
+		kind:  kind,
+		added: true,
+		apply: func(s Store, sessionID string) error {
+			return add(s, sessionID, &copied)
+		},
+		result: func() (string, interface{}) {
+			id, _, _ := identity(&copied)
+			return *id, &copied
+		},
+		report: func(sessionID string) {
+			id, _, createdAt := identity(&copied)
+			recordID, recordSession, recordCreatedAt := identity(record)
+			*recordID, *recordSession, *recordCreatedAt = *id, sessionID, *createdAt
+		},
+	})
+}
+
+// stageUpdate stages update to be applied to record id through apply,
+// capturing the updated record
+func stageUpdate[T any](tx *Tx, op, kind, id string, update func(*T) error, apply func(Store, string, string, func(*T) error) error) {
+	if update == nil {
+		tx.fail(errorf(ErrInvalidArgument, "transaction contains an empty update of %s record %s", kind, id))
+		return
+	}
+
+	var updated *T
+	tx.ops = append(tx.ops, &txOp{
+		op:   op,
+		kind: kind,
+		apply: func(s Store, sessionID string) error {
+			return apply(s, sessionID, id, func(record *T) error {
+				if err := update(record); err != nil {
+					return err
+				}
+				// The store restores the record's identity on this copy
+				updated = record
+				return nil
+			})
+		},
+		result: func() (string, interface{}) {
+			return id, updated
+		},
+	})
+}
+
+// fail records the first error found while staging; the transaction is
+// rejected before anything is applied
+func (tx *Tx) fail(err error) {
+	if tx.err == nil {
+		tx.err = err
+	}
+}
+
+// beforeCommit registers fn to run once every write has been applied, just
+// before they are committed. An error from fn rolls the transaction back.
+func (tx *Tx) beforeCommit(fn func() error) {
+	tx.onCommit = append(tx.onCommit, fn)
+}
+
+// stageTx runs fn on a new transaction and returns the staged writes
+func stageTx(fn func(*Tx) error) (*Tx, error) {
+	tx := &Tx{}
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+	if tx.err != nil {
+		return nil, tx.err
+	}
+	return tx, nil
+}
+
+// apply performs the staged writes on s in order
+func (tx *Tx) apply(s Store, sessionID string) error {
+	for i, op := range tx.ops {
+		if err := op.apply(s, sessionID); err != nil {
+			return fmt.Errorf("transaction rolled back at write %d (%s): %w", i+1, op.op, err)
+		}
+	}
+	return nil
+}
+
+// commit runs the hooks registered with beforeCommit
+func (tx *Tx) commit() error {
+	for _, fn := range tx.onCommit {
+		if err := fn(); err != nil {
+			return fmt.Errorf("transaction rolled back: %w", err)
+		}
+	}
+	return nil
+}
+
+// report copies the identity of every added record back to the caller's
+// records, giving them sessionID
+func (tx *Tx) report(sessionID string) {
+	for _, op := range tx.ops {
+		if op.report != nil {
+			op.report(sessionID)
+		}
+	}
+}
+
+// ============================================================================
+// MemoryStore transactions
+// ============================================================================
+
+// Transaction applies the writes staged by fn to a session atomically. While
+// every store lock is held they are applied to a private copy of the session,
+// which replaces the session only if all of them succeed.
+func (s *MemoryStore) Transaction(sessionID string, fn func(*Tx) error) error {
+	tx, err := stageTx(fn)
+	if err != nil || tx.Len() == 0 {
+		return err
+	}
+
+	defer s.enforceGlobalQuota(sessionID)
+	s.thoughtsMutex.Lock()
+	defer s.thoughtsMutex.Unlock()
+	s.mentalModelsMutex.Lock()
+	defer s.mentalModelsMutex.Unlock()
+	s.stochasticAlgorithmsMutex.Lock()
+	defer s.stochasticAlgorithmsMutex.Unlock()
+	s.decisionsMutex.Lock()
+	defer s.decisionsMutex.Unlock()
+	s.visualDataMutex.Lock()
+	defer s.visualDataMutex.Unlock()
+	s.critiquesMutex.Lock()
+	defer s.critiquesMutex.Unlock()
+	s.scenariosMutex.Lock()
+	defer s.scenariosMutex.Unlock()
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	scratch := s.scratchCopy(sessionID)
+	if err := tx.apply(scratch, sessionID); err != nil {
+		return err
+	}
+
+	// The copy only knows this session's IDs; IDs are unique across sessions
+	for _, op := range tx.ops {
+		if !op.added {
+			continue
+		}
+		if id, _ := op.result(); s.holdsID(op.kind, id) {
+			return fmt.Errorf("transaction rolled back: %w", duplicateID(op.kind, id))
+		}
+	}
+
+	if err := tx.commit(); err != nil {
+		return err
+	}
+	s.adoptSession(scratch, sessionID)
+	tx.report(sessionID)
+
+	return nil
+}
+
+// scratchCopy returns a private store holding a copy of a session, to which
+// writes can be applied and then adopted or abandoned; callers must hold
+// every store lock
+func (s *MemoryStore) scratchCopy(sessionID string) *MemoryStore {
+	cfg := *s.config
+	// Global quotas cover every session, so they are enforced once adopted
+	cfg.MaxTotalRecords, cfg.MaxTotalBytes = 0, 0
+
+	scratch := NewMemoryStore(&cfg)
+	scratch.logger, scratch.newID, scratch.now = s.logger, s.newID, s.now
+	if entry := s.snapshotSession(sessionID); entry != nil {
+		scratch.restoreSession(entry)
+	}
+
+	return scratch
+}
+
+// holdsID reports whether a record of the kind has the ID; callers must hold
+// the kind's mutex
+func (s *MemoryStore) holdsID(kind, id string) bool {
+	var exists bool
+	switch kind {
+	case KindThoughts:
+		_, exists = s.thoughts[id]
+	case KindMentalModels:
+		_, exists = s.mentalModels[id]
+	case KindStochasticAlgorithms:
+		_, exists = s.stochasticAlgorithms[id]
+	case KindDecisions:
+		_, exists = s.decisions[id]
+	case KindVisualData:
+		_, exists = s.visualData[id]
+	case KindCritiques:
+		_, exists = s.critiques[id]
+	case KindScenarios:
+		_, exists = s.scenarios[id]
+	}
+	return exists
+}
+
+// adoptSession replaces a session, its records and usage with those held by
+// scratch; callers must hold every store lock
+func (s *MemoryStore) adoptSession(scratch *MemoryStore, sessionID string) {
+	s.sessions[sessionID] = scratch.sessions[sessionID]
+	s.usage[sessionID] = scratch.usage[sessionID]
+
+	adoptRecords(s.thoughts, s.thoughtsBySession, scratch.thoughts, scratch.thoughtsBySession, sessionID)
+	adoptRecords(s.mentalModels, s.mentalModelsBySession, scratch.mentalModels, scratch.mentalModelsBySession, sessionID)
+	adoptRecords(s.stochasticAlgorithms, s.stochasticAlgorithmsBySession, scratch.stochasticAlgorithms, scratch.stochasticAlgorithmsBySession, sessionID)
+	adoptRecords(s.decisions, s.decisionsBySession, scratch.decisions, scratch.decisionsBySession, sessionID)
+	adoptRecords(s.visualData, s.visualDataBySession, scratch.visualData, scratch.visualDataBySession, sessionID)
+	adoptRecords(s.critiques, s.critiquesBySession, scratch.critiques, scratch.critiquesBySession, sessionID)
+	adoptRecords(s.scenarios, s.scenariosBySession, scratch.scenarios, scratch.scenariosBySession, sessionID)
+}
+
+// adoptRecords replaces the records a session holds in one MemoryStore map
+// with those of another
+func adoptRecords[T any](records map[string]*T, bySession map[string][]string, from map[string]*T, fromBySession map[string][]string, sessionID string) {
+	for _, id := range bySession[sessionID] {
+		delete(records, id)
+	}
+
+	ids := fromBySession[sessionID]
+	if len(ids) == 0 {
+		delete(bySession, sessionID)
+		return
+	}
+	for _, id := range ids {
+		records[id] = from[id]
+	}
+	bySession[sessionID] = ids
+}
+
+// ============================================================================
+// RecordStore transactions
+// ============================================================================
+
+// Transaction applies the writes staged by fn to a session atomically. They
+// are applied to an overlay of the backend that collects the resulting
+// backend writes, which the backend then commits as one unit.
+func (s *RecordStore) Transaction(sessionID string, fn func(*Tx) error) error {
+	tx, err := stageTx(fn)
+	if err != nil || tx.Len() == 0 {
+		return err
+	}
+
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	staged := &stagedBackend{RecordBackend: s.backend}
+	view := &RecordStore{config: s.config, logger: s.logger, backend: staged}
+	if err := tx.apply(view, sessionID); err != nil {
+		return err
+	}
+	if err := tx.commit(); err != nil {
+		return err
+	}
+
+	if err := s.backend.ApplyWrites(staged.writes); err != nil {
+		return fmt.Errorf("transaction rolled back: %w", err)
+	}
+	tx.report(sessionID)
+
+	return nil
+}
+
+// stagedBackend collects the writes made through it instead of applying
+// them, answering reads from the wrapped backend with those writes applied
+type stagedBackend struct {
+	RecordBackend
+	writes []RecordWrite
+}
+
+// InsertRecord stages a new record, rejecting IDs already staged. IDs held
+// by the wrapped backend are rejected when the writes are applied.
+func (b *stagedBackend) InsertRecord(kind, sessionID, id string, data []byte) error {
+	for _, write := range b.writes {
+		if write.Type == WriteInsertRecord && write.Kind == kind && write.ID == id {
+			return ErrDuplicateID
+		}
+	}
+	b.writes = append(b.writes, RecordWrite{Type: WriteInsertRecord, Kind: kind, SessionID: sessionID, ID: id, Data: data})
+	return nil
+}
+
+// PutRecord stages a record to be inserted or replaced
+func (b *stagedBackend) PutRecord(kind, sessionID, id string, data []byte) error {
+	b.writes = append(b.writes, RecordWrite{Type: WritePutRecord, Kind: kind, SessionID: sessionID, ID: id, Data: data})
+	return nil
+}
+
+// ListRecords returns the wrapped backend's records with staged records
+// replaced or appended
+func (b *stagedBackend) ListRecords(kind, sessionID string) ([][]byte, error) {
+	rows, err := b.RecordBackend.ListRecords(kind, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, write := range b.writes {
+		if write.Type == WritePutSession || write.Kind != kind || write.SessionID != sessionID {
+			continue
+		}
+		if ids == nil {
+			ids = make([]string, len(rows))
+			for i, row := range rows {
+				var record struct {
+					ID string `json:"id"`
+				}
+				if err := json.Unmarshal(row, &record); err != nil {
+					return nil, err
+				}
+				ids[i] = record.ID
+			}
+		}
+
+		replaced := false
+		for i, id := range ids {
+			if id == write.ID {
+				rows[i], replaced = write.Data, true
+				break
+			}
+		}
+		if !replaced {
+			rows = append(rows, write.Data)
+			ids = append(ids, write.ID)
+		}
+	}
+
+	return rows, nil
+}
+
+// PutSession stages session metadata
+func (b *stagedBackend) PutSession(sessionID string, data []byte) error {
+	b.writes = append(b.writes, RecordWrite{Type: WritePutSession, SessionID: sessionID, Data: data})
+	return nil
+}
+
+// GetSession returns the latest staged session metadata, falling back to the
+// wrapped backend
+func (b *stagedBackend) GetSession(sessionID string) ([]byte, error) {
+	for i := len(b.writes) - 1; i >= 0; i-- {
+		if write := b.writes[i]; write.Type == WritePutSession && write.SessionID == sessionID {
+			return write.Data, nil
+		}
+	}
+	return b.RecordBackend.GetSession(sessionID)
+}
+
+// ListSessions returns the wrapped backend's sessions and any staged ones
+func (b *stagedBackend) ListSessions() ([]string, error) {
+	ids, err := b.RecordBackend.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, write := range b.writes {
+		if write.Type == WritePutSession && !seen[write.SessionID] {
+			seen[write.SessionID] = true
+			ids = append(ids, write.SessionID)
+		}
+	}
+
+	return ids, nil
+}
+
+// DeleteSession is not available inside a transaction
+func (b *stagedBackend) DeleteSession(sessionID string) error {
+	return errors.New("sessions cannot be deleted inside a transaction")
+}
+
+// ApplyWrites is not available inside a transaction
+func (b *stagedBackend) ApplyWrites(writes []RecordWrite) error {
+	return errors.New("transactions cannot be nested")
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_CommitsAllWrites(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{ID: "d0", DecisionStatement: "earlier"}))
+
+	decision := &types.DecisionData{DecisionStatement: "Pick a database"}
+	visual := &types.VisualData{DiagramType: "decisionTree"}
+	err := store.Transaction("s1", func(tx *Tx) error {
+		tx.AddDecision(decision)
+		tx.AddVisualData(visual)
+		tx.UpdateDecision("d0", func(d *types.DecisionData) error {
+			d.Recommendation = "superseded"
+			return nil
+		})
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The caller's records receive their identity on commit
+	assert.NotEmpty(t, decision.ID)
+	assert.Equal(t, "s1", visual.SessionID)
+	assert.False(t, visual.CreatedAt.IsZero())
+
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, "superseded", decisions[0].Recommendation)
+	assert.Equal(t, decision.ID, decisions[1].ID)
+
+	visuals, err := store.GetVisualData("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, visuals, 1)
+
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, session.TotalOperations)
+}
+
+func TestTransaction_RollsBackOnFailure(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	require.NoError(t, store.AddThought("other", &types.ThoughtData{ID: "taken", Thought: "elsewhere"}))
+	require.NoError(t, store.AddDecision("s1", &types.DecisionData{ID: "d0", DecisionStatement: "earlier"}))
+
+	decision := &types.DecisionData{DecisionStatement: "Pick a database"}
+	err := store.Transaction("s1", func(tx *Tx) error {
+		tx.AddDecision(decision)
+		tx.UpdateDecision("d0", func(d *types.DecisionData) error {
+			d.Recommendation = "changed"
+			return nil
+		})
+		tx.AddThought(&types.ThoughtData{ID: "taken", Thought: "clash"})
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrDuplicateID)
+	assert.Empty(t, decision.ID)
+
+	err = store.Transaction("s1", func(tx *Tx) error {
+		tx.AddDecision(&types.DecisionData{DecisionStatement: "never"})
+		tx.UpdateVisualData("missing", func(*types.VisualData) error { return nil })
+		return nil
+	})
+	assert.ErrorContains(t, err, "transaction rolled back at write 2")
+
+	err = store.Transaction("s1", func(tx *Tx) error {
+		tx.AddDecision(&types.DecisionData{DecisionStatement: "never"})
+		return errors.New("changed my mind")
+	})
+	assert.EqualError(t, err, "changed my mind")
+
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Empty(t, decisions[0].Recommendation)
+
+	session, err := store.GetSession("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, session.TotalOperations)
+	stats, err := store.StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalRecords)
+}
+
+func TestTransaction_RespectsSessionLimits(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxThoughtsPerSession = 1
+	store := NewMemoryStore(cfg)
+
+	err := store.Transaction("s1", func(tx *Tx) error {
+		tx.AddThought(&types.ThoughtData{Thought: "first"})
+		tx.AddThought(&types.ThoughtData{Thought: "second"})
+		return nil
+	})
+	assert.ErrorContains(t, err, "thought limit reached")
+
+	thoughts, err := store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, thoughts)
+}
+
+func TestTransaction_JournaledAndReplayedTogether(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gothink.journal")
+
+	store, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	tenant := ForTenant(store, "acme")
+
+	decision := &types.DecisionData{DecisionStatement: "Pick a database"}
+	err = tenant.Transaction("s1", func(tx *Tx) error {
+		tx.AddDecision(decision)
+		tx.AddVisualData(&types.VisualData{DiagramType: "decisionTree"})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "s1", decision.SessionID)
+	require.NoError(t, store.Close())
+
+	replayed, err := NewJournaledStore(NewMemoryStore(config.DefaultConfig()), path)
+	require.NoError(t, err)
+	defer replayed.Close()
+
+	decisions, err := ForTenant(replayed, "acme").GetDecisions("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, decision.ID, decisions[0].ID)
+	visuals, err := ForTenant(replayed, "acme").GetVisualData("s1", nil)
+	require.NoError(t, err)
+	assert.Len(t, visuals, 1)
+}
+
+func TestTransaction_PublishesOnCommit(t *testing.T) {
+	bus := NewEventBus()
+	store := NewEventStore(NewMemoryStore(config.DefaultConfig()), bus)
+	sub := bus.Subscribe("", "s1", 0)
+	defer sub.Close()
+
+	err := store.Transaction("s1", func(tx *Tx) error {
+		tx.AddDecision(&types.DecisionData{DecisionStatement: "never"})
+		tx.UpdateDecision("missing", func(*types.DecisionData) error { return nil })
+		return nil
+	})
+	require.Error(t, err)
+
+	decision := &types.DecisionData{DecisionStatement: "Pick a database"}
+	err = store.Transaction("s1", func(tx *Tx) error {
+		tx.AddDecision(decision)
+		tx.AddVisualData(&types.VisualData{DiagramType: "decisionTree"})
+		return nil
+	})
+	require.NoError(t, err)
+
+	event := <-sub.Events()
+	assert.Equal(t, OpAddDecision, event.Type)
+	assert.Equal(t, decision.ID, event.RecordID)
+	event = <-sub.Events()
+	assert.Equal(t, OpAddVisualData, event.Type)
+	assert.Len(t, sub.Events(), 0)
+}
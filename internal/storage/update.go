@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// identityFunc exposes the fields of a record that updates may not change:
+// its ID, session ID and creation time
+type identityFunc[T any] func(record *T) (*string, *string, *time.Time)
+
+func thoughtIdentity(r *types.ThoughtData) (*string, *string, *time.Time) {
+	return &r.ID, &r.SessionID, &r.CreatedAt
+}
+
+func mentalModelIdentity(r *types.MentalModelData) (*string, *string, *time.Time) {
+	return &r.ID, &r.SessionID, &r.CreatedAt
+}
+
+func algorithmIdentity(r *types.StochasticAlgorithmData) (*string, *string, *time.Time) {
+	return &r.ID, &r.SessionID, &r.CreatedAt
+}
+
+func decisionIdentity(r *types.DecisionData) (*string, *string, *time.Time) {
+	return &r.ID, &r.SessionID, &r.CreatedAt
+}
+
+func visualIdentity(r *types.VisualData) (*string, *string, *time.Time) {
+	return &r.ID, &r.SessionID, &r.CreatedAt
+}
+
+// applyUpdate runs update on a copy of current and returns the copy with its
+// identity restored. Working on a copy keeps records already handed out to
+// readers unchanged.
+func applyUpdate[T any](current *T, identity identityFunc[T], update func(*T) error) (*T, error) {
+	updated := *current
+	if err := update(&updated); err != nil {
+		return nil, err
+	}
+
+	id, sessionID, createdAt := identity(current)
+	newID, newSessionID, newCreatedAt := identity(&updated)
+	*newID, *newSessionID, *newCreatedAt = *id, *sessionID, *createdAt
+
+	return &updated, nil
+}
+
+// updateInMap replaces a record held in a MemoryStore map; callers must hold
+// the map's mutex
+func updateInMap[T any](s *MemoryStore, records map[string]*T, kind, sessionID, id string, identity identityFunc[T], update func(*T) error) error {
+	current, exists := records[id]
+	if exists {
+		_, owner, _ := identity(current)
+		exists = *owner == sessionID
+	}
+	if !exists {
+		return errorf(ErrRecordNotFound, "%s record %s not found in session %s", kind, id, sessionID)
+	}
+	if err := s.touchSession(sessionID); err != nil {
+		return err
+	}
+
+	updated, err := applyUpdate(current, identity, update)
+	if err != nil {
+		return err
+	}
+	if err := s.resizeRecord(sessionID, current, updated); err != nil {
+		return err
+	}
+	records[id] = updated
+
+	return nil
+}
+
+// updateStored replaces a record held by a RecordStore backend
+func updateStored[T any](s *RecordStore, kind, sessionID, id string, identity identityFunc[T], update func(*T) error) error {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.Archived {
+		return errorf(ErrSessionArchived, "session %s is archived", sessionID)
+	}
+
+	var records []*T
+	if err := s.listRecords(kind, sessionID, &records); err != nil {
+		return err
+	}
+
+	var current *T
+	for _, record := range records {
+		if recordID, _, _ := identity(record); *recordID == id {
+			current = record
+			break
+		}
+	}
+	if current == nil {
+		return errorf(ErrRecordNotFound, "%s record %s not found in session %s", kind, id, sessionID)
+	}
+
+	updated, err := applyUpdate(current, identity, update)
+	if err != nil {
+		return err
+	}
+
+	if err := s.putRecord(kind, sessionID, id, updated); err != nil {
+		return err
+	}
+
+	return s.saveSession(session)
+}
+
+// ============================================================================
+// MemoryStore updates
+// ============================================================================
+
+// UpdateThought revises a stored thought
+func (s *MemoryStore) UpdateThought(sessionID, id string, update func(*types.ThoughtData) error) error {
+	s.thoughtsMutex.Lock()
+	defer s.thoughtsMutex.Unlock()
+
+	return updateInMap(s, s.thoughts, KindThoughts, sessionID, id, thoughtIdentity, update)
+}
+
+// UpdateMentalModel revises a stored mental model application
+func (s *MemoryStore) UpdateMentalModel(sessionID, id string, update func(*types.MentalModelData) error) error {
+	s.mentalModelsMutex.Lock()
+	defer s.mentalModelsMutex.Unlock()
+
+	return updateInMap(s, s.mentalModels, KindMentalModels, sessionID, id, mentalModelIdentity, update)
+}
+
+// UpdateStochasticAlgorithm revises a stored stochastic algorithm result
+func (s *MemoryStore) UpdateStochasticAlgorithm(sessionID, id string, update func(*types.StochasticAlgorithmData) error) error {
+	s.stochasticAlgorithmsMutex.Lock()
+	defer s.stochasticAlgorithmsMutex.Unlock()
+
+	return updateInMap(s, s.stochasticAlgorithms, KindStochasticAlgorithms, sessionID, id, algorithmIdentity, update)
+}
+
+// UpdateDecision revises a stored decision
+func (s *MemoryStore) UpdateDecision(sessionID, id string, update func(*types.DecisionData) error) error {
+	s.decisionsMutex.Lock()
+	defer s.decisionsMutex.Unlock()
+
+	return updateInMap(s, s.decisions, KindDecisions, sessionID, id, decisionIdentity, update)
+}
+
+// UpdateVisualData revises stored visual data
+func (s *MemoryStore) UpdateVisualData(sessionID, id string, update func(*types.VisualData) error) error {
+	s.visualDataMutex.Lock()
+	defer s.visualDataMutex.Unlock()
+
+	return updateInMap(s, s.visualData, KindVisualData, sessionID, id, visualIdentity, update)
+}
+
+// ============================================================================
+// RecordStore updates
+// ============================================================================
+
+// UpdateThought revises a stored thought
+func (s *RecordStore) UpdateThought(sessionID, id string, update func(*types.ThoughtData) error) error {
+	return updateStored(s, KindThoughts, sessionID, id, thoughtIdentity, update)
+}
+
+// UpdateMentalModel revises a stored mental model application
+func (s *RecordStore) UpdateMentalModel(sessionID, id string, update func(*types.MentalModelData) error) error {
+	return updateStored(s, KindMentalModels, sessionID, id, mentalModelIdentity, update)
+}
+
+// UpdateStochasticAlgorithm revises a stored stochastic algorithm result
+func (s *RecordStore) UpdateStochasticAlgorithm(sessionID, id string, update func(*types.StochasticAlgorithmData) error) error {
+	return updateStored(s, KindStochasticAlgorithms, sessionID, id, algorithmIdentity, update)
+}
+
+// UpdateDecision revises a stored decision
+func (s *RecordStore) UpdateDecision(sessionID, id string, update func(*types.DecisionData) error) error {
+	return updateStored(s, KindDecisions, sessionID, id, decisionIdentity, update)
+}
+
+// UpdateVisualData revises stored visual data
+func (s *RecordStore) UpdateVisualData(sessionID, id string, update func(*types.VisualData) error) error {
+	return updateStored(s, KindVisualData, sessionID, id, visualIdentity, update)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_UpdateMentalModel(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	model := &types.MentalModelData{ModelName: "first_principles", Problem: "Slow builds"}
+	require.NoError(t, store.AddMentalModel("s1", model))
+
+	before, err := store.GetMentalModels("s1", nil)
+	require.NoError(t, err)
+
+	err = store.UpdateMentalModel("s1", model.ID, func(m *types.MentalModelData) error {
+		m.Conclusion = "Cache dependencies"
+		m.ID = "renamed"
+		return nil
+	})
+	require.NoError(t, err)
+
+	models, err := store.GetMentalModels("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, model.ID, models[0].ID)
+	assert.Equal(t, "Cache dependencies", models[0].Conclusion)
+	assert.True(t, model.CreatedAt.Equal(models[0].CreatedAt))
+
+	// Records returned before the update are left untouched
+	assert.Empty(t, before[0].Conclusion)
+}
+
+func TestMemoryStore_UpdateRejectsUnknownAndForeignRecords(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	thought := &types.ThoughtData{Thought: "first"}
+	require.NoError(t, store.AddThought("s1", thought))
+
+	noop := func(*types.ThoughtData) error { return nil }
+	assert.Error(t, store.UpdateThought("s1", "missing", noop))
+	assert.Error(t, store.UpdateThought("s2", thought.ID, noop))
+
+	_, err := store.GetSession("s2")
+	assert.Error(t, err, "a failed update must not create the session")
+}
+
+func TestMemoryStore_UpdateErrorKeepsRecord(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	decision := &types.DecisionData{DecisionStatement: "Pick one"}
+	require.NoError(t, store.AddDecision("s1", decision))
+
+	err := store.UpdateDecision("s1", decision.ID, func(d *types.DecisionData) error {
+		d.DecisionStatement = "changed"
+		return fmt.Errorf("rejected")
+	})
+	assert.EqualError(t, err, "rejected")
+
+	decisions, err := store.GetDecisions("s1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Pick one", decisions[0].DecisionStatement)
+}
+
+func TestMemoryStore_UpdateRejectsArchivedSession(t *testing.T) {
+	store := NewMemoryStore(config.DefaultConfig())
+	thought := &types.ThoughtData{Thought: "first"}
+	require.NoError(t, store.AddThought("s1", thought))
+	require.NoError(t, store.ArchiveSession("s1"))
+
+	err := store.UpdateThought("s1", thought.ID, func(t *types.ThoughtData) error {
+		t.Thought = "revised"
+		return nil
+	})
+	assert.Error(t, err)
+}
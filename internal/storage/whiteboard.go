@@ -0,0 +1,612 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rainmana/gothink/internal/layout"
+	"github.com/rainmana/gothink/internal/types"
+)
+
+// ============================================================================
+// Whiteboards: Excalidraw and draw.io
+// ============================================================================
+
+// Sizes on a whiteboard. Stored positions are node centers in layout units,
+// spread by whiteboardScale so that nodes sized for their labels fit between
+// them.
+const (
+	whiteboardScale        = 1.5
+	whiteboardNodeWidth    = 120
+	whiteboardNodeHeight   = 60
+	whiteboardGroupPadding = 20
+	whiteboardGroupHeader  = 30
+	whiteboardFontSize     = 16
+	whiteboardArrowGap     = 4
+)
+
+// box is the rectangle a node or group takes on a whiteboard, from its top
+// left corner
+type box struct {
+	x, y, width, height float64
+}
+
+func (b box) center() (float64, float64) {
+	return b.x + b.width/2, b.y + b.height/2
+}
+
+// board is a diagram placed on a whiteboard: its groups, outermost first so
+// that they are drawn beneath what they contain, its nodes and its edges,
+// each node and group with its box and attributes as the DOT export maps
+// them
+type board struct {
+	groups     []types.VisualElement
+	nodes      []types.VisualElement
+	edges      []types.VisualElement
+	boxes      map[string]box
+	attributes map[string]map[string]string
+}
+
+// placeBoard places the elements of d on a whiteboard. Nodes sit at their
+// stored x and y; nodes without them are laid out by force around those
+// with them. Groups enclose the boxes of their members, and groups without
+// placed members, like edges not joining two placed nodes, are left out.
+func placeBoard(d *diagram) *board {
+	b := &board{boxes: make(map[string]box), attributes: make(map[string]map[string]string)}
+	g := layout.Graph{Fixed: make(map[string]layout.Point)}
+	groups := make(map[string]types.VisualElement)
+	for _, element := range d.elements {
+		switch {
+		case isEdge(element):
+			g.Edges = append(g.Edges, [2]string{element.Source, element.Target})
+		case len(element.Contains) > 0:
+			groups[element.ID] = element
+		default:
+			b.nodes = append(b.nodes, element)
+			g.Nodes = append(g.Nodes, element.ID)
+			x, isX := numericProperty(element, "x")
+			y, isY := numericProperty(element, "y")
+			if isX && isY {
+				g.Fixed[element.ID] = layout.Point{X: x, Y: y}
+			}
+		}
+	}
+
+	positions := g.Fixed
+	if len(g.Fixed) < len(g.Nodes) {
+		positions = layout.ForceDirected(g, layout.Options{})
+	}
+	for _, node := range b.nodes {
+		p := positions[node.ID]
+		b.boxes[node.ID] = box{
+			x:      p.X*whiteboardScale - whiteboardNodeWidth/2,
+			y:      p.Y*whiteboardScale - whiteboardNodeHeight/2,
+			width:  whiteboardNodeWidth,
+			height: whiteboardNodeHeight,
+		}
+		b.attributes[node.ID] = nodeAttributes(d.diagramType, node)
+	}
+
+	// A group's box is found from its members', so every group is visited
+	// once its members are
+	visiting := make(map[string]bool)
+	var enclose func(id string) (box, bool)
+	enclose = func(id string) (box, bool) {
+		if placed, ok := b.boxes[id]; ok {
+			return placed, true
+		}
+		group, ok := groups[id]
+		if !ok || visiting[id] {
+			return box{}, false
+		}
+		visiting[id] = true
+		minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+		for _, member := range group.Contains {
+			if m, ok := enclose(member); ok {
+				minX, minY = math.Min(minX, m.x), math.Min(minY, m.y)
+				maxX, maxY = math.Max(maxX, m.x+m.width), math.Max(maxY, m.y+m.height)
+			}
+		}
+		if math.IsInf(minX, 1) {
+			return box{}, false
+		}
+		enclosing := box{
+			x:      minX - whiteboardGroupPadding,
+			y:      minY - whiteboardGroupPadding - whiteboardGroupHeader,
+			width:  maxX - minX + 2*whiteboardGroupPadding,
+			height: maxY - minY + 2*whiteboardGroupPadding + whiteboardGroupHeader,
+		}
+		b.boxes[id] = enclosing
+		b.attributes[id] = nodeAttributes(d.diagramType, group)
+		b.groups = append(b.groups, group)
+		return enclosing, true
+	}
+	for _, element := range d.elements {
+		if len(element.Contains) > 0 {
+			enclose(element.ID)
+		}
+	}
+	sort.SliceStable(b.groups, func(i, j int) bool {
+		a, c := b.boxes[b.groups[i].ID], b.boxes[b.groups[j].ID]
+		return a.width*a.height > c.width*c.height
+	})
+
+	for _, element := range d.elements {
+		if !isEdge(element) {
+			continue
+		}
+		_, from := b.boxes[element.Source]
+		_, to := b.boxes[element.Target]
+		if from && to {
+			b.edges = append(b.edges, element)
+			b.attributes[element.ID] = edgeAttributes(element)
+		}
+	}
+	return b
+}
+
+// label returns the label of element on a whiteboard, its ID when it has
+// none
+func (b *board) label(element types.VisualElement) string {
+	if label := b.attributes[element.ID]["label"]; label != "" {
+		return label
+	}
+	if isEdge(element) {
+		return ""
+	}
+	return element.ID
+}
+
+// strokeWidth returns the pen width of element, 1 unless its penwidth or a
+// bold style thickens it
+func (b *board) strokeWidth(element types.VisualElement) float64 {
+	attributes := b.attributes[element.ID]
+	width := 1.0
+	if penwidth, err := strconv.ParseFloat(attributes["penwidth"], 64); err == nil && penwidth > 0 {
+		width = penwidth
+	}
+	if attributes["style"] == "bold" {
+		width = math.Max(width, 3)
+	}
+	return width
+}
+
+// endpoints returns where edge leaves its source's box and enters its
+// target's, along the line between their centers
+func (b *board) endpoints(edge types.VisualElement) (x1, y1, x2, y2 float64) {
+	source, target := b.boxes[edge.Source], b.boxes[edge.Target]
+	sx, sy := source.center()
+	tx, ty := target.center()
+	x1, y1 = clipToBox(source, tx-sx, ty-sy)
+	x2, y2 = clipToBox(target, sx-tx, sy-ty)
+	return x1, y1, x2, y2
+}
+
+// clipToBox returns the point, whiteboardArrowGap outside b, where a line
+// from its center in direction (dx, dy) leaves it
+func clipToBox(b box, dx, dy float64) (float64, float64) {
+	cx, cy := b.center()
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return cx, cy
+	}
+	t := math.Inf(1)
+	if dx != 0 {
+		t = math.Min(t, b.width/2/math.Abs(dx))
+	}
+	if dy != 0 {
+		t = math.Min(t, b.height/2/math.Abs(dy))
+	}
+	gap := whiteboardArrowGap / length
+	return cx + dx*(t+gap), cy + dy*(t+gap)
+}
+
+// elementData returns the type, probability and properties of element,
+// other than its position, as whiteboard attributes, encoded as the DOT
+// export encodes them
+func elementData(element types.VisualElement) map[string]string {
+	data := overlayProperties(make(map[string]string), element)
+	delete(data, "x")
+	delete(data, "y")
+	if element.Type != "" {
+		data["type"] = element.Type
+	}
+	if element.Probability > 0 {
+		data["probability"] = formatFloat(element.Probability)
+	}
+	return data
+}
+
+// whiteboardSeed derives a stable random seed from id, so that an export
+// draws the same every time
+func whiteboardSeed(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() & math.MaxInt32)
+}
+
+// ----------------------------------------------------------------------------
+// Excalidraw
+// ----------------------------------------------------------------------------
+
+// excalidrawShapes are the Excalidraw shapes of Graphviz shapes; any other
+// is a rectangle
+var excalidrawShapes = map[string]string{
+	"ellipse": "ellipse",
+	"oval":    "ellipse",
+	"circle":  "ellipse",
+	"diamond": "diamond",
+}
+
+// excalidrawScene is an Excalidraw file
+type excalidrawScene struct {
+	Type     string                 `json:"type"`
+	Version  int                    `json:"version"`
+	Source   string                 `json:"source"`
+	Elements []*excalidrawElement   `json:"elements"`
+	AppState map[string]interface{} `json:"appState"`
+	Files    map[string]interface{} `json:"files"`
+}
+
+// excalidrawElement is a shape, text or arrow of an Excalidraw scene
+type excalidrawElement struct {
+	ID              string               `json:"id"`
+	Type            string               `json:"type"`
+	X               float64              `json:"x"`
+	Y               float64              `json:"y"`
+	Width           float64              `json:"width"`
+	Height          float64              `json:"height"`
+	Angle           float64              `json:"angle"`
+	StrokeColor     string               `json:"strokeColor"`
+	BackgroundColor string               `json:"backgroundColor"`
+	FillStyle       string               `json:"fillStyle"`
+	StrokeWidth     float64              `json:"strokeWidth"`
+	StrokeStyle     string               `json:"strokeStyle"`
+	Roughness       int                  `json:"roughness"`
+	Opacity         int                  `json:"opacity"`
+	GroupIDs        []string             `json:"groupIds"`
+	FrameID         *string              `json:"frameId"`
+	Roundness       *excalidrawRoundness `json:"roundness"`
+	Seed            int                  `json:"seed"`
+	Version         int                  `json:"version"`
+	VersionNonce    int                  `json:"versionNonce"`
+	IsDeleted       bool                 `json:"isDeleted"`
+	BoundElements   []excalidrawBound    `json:"boundElements"`
+	Updated         int64                `json:"updated"`
+	Link            *string              `json:"link"`
+	Locked          bool                 `json:"locked"`
+	CustomData      map[string]string    `json:"customData,omitempty"`
+	Text            string               `json:"text,omitempty"`
+	OriginalText    string               `json:"originalText,omitempty"`
+	FontSize        float64              `json:"fontSize,omitempty"`
+	FontFamily      int                  `json:"fontFamily,omitempty"`
+	TextAlign       string               `json:"textAlign,omitempty"`
+	VerticalAlign   string               `json:"verticalAlign,omitempty"`
+	ContainerID     *string              `json:"containerId,omitempty"`
+	LineHeight      float64              `json:"lineHeight,omitempty"`
+	Points          [][2]float64         `json:"points,omitempty"`
+	StartBinding    *excalidrawBinding   `json:"startBinding,omitempty"`
+	EndBinding      *excalidrawBinding   `json:"endBinding,omitempty"`
+	EndArrowhead    string               `json:"endArrowhead,omitempty"`
+}
+
+type excalidrawRoundness struct {
+	Type int `json:"type"`
+}
+
+// excalidrawBound names an element bound to a shape: its label or an arrow
+type excalidrawBound struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// excalidrawBinding attaches an end of an arrow to a shape
+type excalidrawBinding struct {
+	ElementID string  `json:"elementId"`
+	Focus     float64 `json:"focus"`
+	Gap       float64 `json:"gap"`
+}
+
+// newExcalidrawElement returns an element of kind with Excalidraw's
+// defaults, its seeds derived from id
+func newExcalidrawElement(id, kind string, b box) *excalidrawElement {
+	return &excalidrawElement{
+		ID:              id,
+		Type:            kind,
+		X:               b.x,
+		Y:               b.y,
+		Width:           b.width,
+		Height:          b.height,
+		StrokeColor:     "#1e1e1e",
+		BackgroundColor: "transparent",
+		FillStyle:       "solid",
+		StrokeWidth:     1,
+		StrokeStyle:     "solid",
+		Roughness:       1,
+		Opacity:         100,
+		GroupIDs:        []string{},
+		Seed:            whiteboardSeed(id),
+		Version:         1,
+		VersionNonce:    whiteboardSeed(id + "#nonce"),
+		Updated:         1,
+	}
+}
+
+// excalidrawText returns the text element labeling container, centered in
+// b
+func excalidrawText(container, text string, b box) *excalidrawElement {
+	lines := strings.Split(text, "\n")
+	longest := 0
+	for _, line := range lines {
+		longest = max(longest, len([]rune(line)))
+	}
+	width := math.Round(math.Min(b.width, float64(longest)*whiteboardFontSize*0.6))
+	height := float64(len(lines)) * whiteboardFontSize * 1.25
+	cx, cy := b.center()
+	element := newExcalidrawElement(container+":label", "text", box{x: cx - width/2, y: cy - height/2, width: width, height: height})
+	element.Text, element.OriginalText = text, text
+	element.FontSize, element.FontFamily, element.LineHeight = whiteboardFontSize, 1, 1.25
+	element.TextAlign, element.VerticalAlign = "center", "middle"
+	element.ContainerID = &container
+	return element
+}
+
+// renderExcalidraw writes d as an Excalidraw scene. Nodes become rectangles,
+// ellipses or diamonds by their Graphviz shape, labeled with bound text;
+// groups become dashed rectangles around their members, labeled at the top;
+// and edges become arrows bound to the nodes they join. Every element keeps
+// its type and properties as custom data.
+func renderExcalidraw(d *diagram) string {
+	b := placeBoard(d)
+	scene := excalidrawScene{
+		Type:     "excalidraw",
+		Version:  2,
+		Source:   "https://github.com/rainmana/gothink",
+		Elements: []*excalidrawElement{},
+		AppState: map[string]interface{}{"viewBackgroundColor": "#ffffff", "gridSize": nil},
+		Files:    map[string]interface{}{},
+	}
+	shapes := make(map[string]*excalidrawElement)
+
+	for _, group := range b.groups {
+		frame := b.boxes[group.ID]
+		shape := newExcalidrawElement(group.ID, "rectangle", frame)
+		shape.StrokeStyle = "dashed"
+		shape.Roundness = &excalidrawRoundness{Type: 3}
+		shape.CustomData = elementData(group)
+		styleExcalidraw(shape, b.attributes[group.ID])
+		scene.Elements = append(scene.Elements, shape)
+		if label := b.label(group); label != "" {
+			header := box{x: frame.x + whiteboardGroupPadding, y: frame.y + whiteboardGroupPadding/2, width: frame.width - 2*whiteboardGroupPadding, height: whiteboardGroupHeader}
+			text := excalidrawText(group.ID, label, header)
+			text.X, text.ContainerID, text.TextAlign = header.x, nil, "left"
+			scene.Elements = append(scene.Elements, text)
+		}
+	}
+
+	for _, node := range b.nodes {
+		attributes := b.attributes[node.ID]
+		kind, ok := excalidrawShapes[attributes["shape"]]
+		if !ok {
+			kind = "rectangle"
+		}
+		shape := newExcalidrawElement(node.ID, kind, b.boxes[node.ID])
+		if kind == "rectangle" {
+			shape.Roundness = &excalidrawRoundness{Type: 3}
+		}
+		if attributes["shape"] == "plaintext" || attributes["shape"] == "plain" || attributes["shape"] == "none" {
+			shape.StrokeColor = "transparent"
+		}
+		shape.StrokeWidth = b.strokeWidth(node)
+		shape.CustomData = elementData(node)
+		styleExcalidraw(shape, attributes)
+		text := excalidrawText(node.ID, b.label(node), b.boxes[node.ID])
+		shape.BoundElements = append(shape.BoundElements, excalidrawBound{ID: text.ID, Type: "text"})
+		shapes[node.ID] = shape
+		scene.Elements = append(scene.Elements, shape, text)
+	}
+
+	for _, edge := range b.edges {
+		x1, y1, x2, y2 := b.endpoints(edge)
+		arrow := newExcalidrawElement(edge.ID, "arrow", box{x: x1, y: y1, width: math.Abs(x2 - x1), height: math.Abs(y2 - y1)})
+		arrow.Points = [][2]float64{{0, 0}, {x2 - x1, y2 - y1}}
+		arrow.Roundness = &excalidrawRoundness{Type: 2}
+		arrow.StartBinding = &excalidrawBinding{ElementID: edge.Source, Gap: whiteboardArrowGap}
+		arrow.EndBinding = &excalidrawBinding{ElementID: edge.Target, Gap: whiteboardArrowGap}
+		arrow.EndArrowhead = "arrow"
+		arrow.StrokeWidth = b.strokeWidth(edge)
+		arrow.CustomData = elementData(edge)
+		styleExcalidraw(arrow, b.attributes[edge.ID])
+		shapes[edge.Source].BoundElements = append(shapes[edge.Source].BoundElements, excalidrawBound{ID: edge.ID, Type: "arrow"})
+		if edge.Target != edge.Source {
+			shapes[edge.Target].BoundElements = append(shapes[edge.Target].BoundElements, excalidrawBound{ID: edge.ID, Type: "arrow"})
+		}
+		scene.Elements = append(scene.Elements, arrow)
+		if label := b.label(edge); label != "" {
+			text := excalidrawText(edge.ID, label, box{x: (x1+x2)/2 - whiteboardNodeWidth/2, y: (y1 + y2) / 2, width: whiteboardNodeWidth})
+			arrow.BoundElements = append(arrow.BoundElements, excalidrawBound{ID: text.ID, Type: "text"})
+			scene.Elements = append(scene.Elements, text)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(scene)
+	return buf.String()
+}
+
+// styleExcalidraw colors element by the color and fillcolor of attributes
+func styleExcalidraw(element *excalidrawElement, attributes map[string]string) {
+	if color := attributes["color"]; color != "" {
+		element.StrokeColor = color
+	}
+	if fill := attributes["fillcolor"]; fill != "" {
+		element.BackgroundColor = fill
+	}
+}
+
+// ----------------------------------------------------------------------------
+// draw.io
+// ----------------------------------------------------------------------------
+
+// drawioShapes are the draw.io styles of Graphviz shapes; any other is a
+// rounded rectangle
+var drawioShapes = map[string]string{
+	"ellipse":       "ellipse;",
+	"oval":          "ellipse;",
+	"circle":        "ellipse;",
+	"diamond":       "rhombus;",
+	"parallelogram": "shape=parallelogram;perimeter=parallelogramPerimeter;",
+	"plaintext":     "text;",
+	"plain":         "text;",
+	"none":          "text;",
+}
+
+// IDs of the root and layer cells every draw.io diagram holds
+const (
+	drawioRootID  = "gothink:root"
+	drawioLayerID = "gothink:layer"
+)
+
+// drawioFile is a draw.io file holding one diagram
+type drawioFile struct {
+	XMLName xml.Name      `xml:"mxfile"`
+	Host    string        `xml:"host,attr"`
+	Diagram drawioDiagram `xml:"diagram"`
+}
+
+type drawioDiagram struct {
+	ID    string      `xml:"id,attr"`
+	Name  string      `xml:"name,attr"`
+	Model drawioModel `xml:"mxGraphModel"`
+}
+
+type drawioModel struct {
+	Grid     int        `xml:"grid,attr"`
+	GridSize int        `xml:"gridSize,attr"`
+	Root     drawioRoot `xml:"root"`
+}
+
+type drawioRoot struct {
+	Cells   []drawioCell   `xml:"mxCell"`
+	Objects []drawioObject `xml:"object"`
+}
+
+// drawioObject is a cell carrying data: its label and the attributes draw.io
+// shows under Edit Data
+type drawioObject struct {
+	ID    string     `xml:"id,attr"`
+	Label string     `xml:"label,attr"`
+	Data  []xml.Attr `xml:",any,attr"`
+	Cell  drawioCell `xml:"mxCell"`
+}
+
+type drawioCell struct {
+	ID       string          `xml:"id,attr,omitempty"`
+	Style    string          `xml:"style,attr,omitempty"`
+	Vertex   string          `xml:"vertex,attr,omitempty"`
+	Edge     string          `xml:"edge,attr,omitempty"`
+	Parent   string          `xml:"parent,attr,omitempty"`
+	Source   string          `xml:"source,attr,omitempty"`
+	Target   string          `xml:"target,attr,omitempty"`
+	Geometry *drawioGeometry `xml:"mxGeometry"`
+}
+
+type drawioGeometry struct {
+	X        float64 `xml:"x,attr,omitempty"`
+	Y        float64 `xml:"y,attr,omitempty"`
+	Width    float64 `xml:"width,attr,omitempty"`
+	Height   float64 `xml:"height,attr,omitempty"`
+	Relative string  `xml:"relative,attr,omitempty"`
+	As       string  `xml:"as,attr"`
+}
+
+// xmlName matches the property keys that are valid XML attribute names
+var xmlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// renderDrawIO writes d as a draw.io diagram. Nodes become rounded
+// rectangles, ellipses, rhombi, parallelograms or bare text by their
+// Graphviz shape; groups become dashed containers drawn behind their
+// members, labeled at the top; and edges become arrows between the nodes
+// they join. Every element keeps its type and properties as data.
+func renderDrawIO(d *diagram) string {
+	b := placeBoard(d)
+	root := drawioRoot{Cells: []drawioCell{{ID: drawioRootID}, {ID: drawioLayerID, Parent: drawioRootID}}}
+	object := func(element types.VisualElement, style string, cell drawioCell) {
+		data := elementData(element)
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			if xmlName.MatchString(key) && key != "id" && key != "label" && key != "placeholders" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		attrs := make([]xml.Attr, len(keys))
+		for i, key := range keys {
+			attrs[i] = xml.Attr{Name: xml.Name{Local: key}, Value: data[key]}
+		}
+		attributes := b.attributes[element.ID]
+		if color := attributes["color"]; color != "" {
+			style += "strokeColor=" + color + ";"
+		}
+		if fill := attributes["fillcolor"]; fill != "" {
+			style += "fillColor=" + fill + ";"
+		}
+		if width := b.strokeWidth(element); width != 1 {
+			style += "strokeWidth=" + formatFloat(width) + ";"
+		}
+		cell.Style, cell.Parent = style, drawioLayerID
+		root.Objects = append(root.Objects, drawioObject{ID: element.ID, Label: b.label(element), Data: attrs, Cell: cell})
+	}
+	geometry := func(id string) *drawioGeometry {
+		placed := b.boxes[id]
+		return &drawioGeometry{X: placed.x, Y: placed.y, Width: placed.width, Height: placed.height, As: "geometry"}
+	}
+
+	for _, group := range b.groups {
+		object(group, "rounded=1;dashed=1;fillColor=none;verticalAlign=top;align=left;spacingLeft=8;",
+			drawioCell{Vertex: "1", Geometry: geometry(group.ID)})
+	}
+	for _, node := range b.nodes {
+		style, ok := drawioShapes[b.attributes[node.ID]["shape"]]
+		if !ok {
+			style = "rounded=1;"
+		}
+		object(node, style, drawioCell{Vertex: "1", Geometry: geometry(node.ID)})
+	}
+	for _, edge := range b.edges {
+		object(edge, "endArrow=classic;", drawioCell{
+			Edge:     "1",
+			Source:   edge.Source,
+			Target:   edge.Target,
+			Geometry: &drawioGeometry{Relative: "1", As: "geometry"},
+		})
+	}
+
+	file := drawioFile{
+		Host:    "gothink",
+		Diagram: drawioDiagram{ID: d.id, Name: d.id, Model: drawioModel{Grid: 1, GridSize: 10, Root: root}},
+	}
+	data, _ := xml.MarshalIndent(file, "", "  ")
+	return xml.Header + string(data) + "\n"
+}
+
+// numericProperty returns the numeric property key of element
+func numericProperty(element types.VisualElement, key string) (float64, bool) {
+	switch v := element.Properties[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWhiteboardFixture exports a session holding a laid out flowchart with
+// a stage grouping two of its nodes, and a concept map never laid out
+func newWhiteboardFixture(t *testing.T) *types.SessionExport {
+	store := NewMemoryStore(config.DefaultConfig())
+	position := func(x, y float64, properties map[string]interface{}) map[string]interface{} {
+		properties["x"], properties["y"] = x, y
+		return properties
+	}
+	require.NoError(t, store.AddVisualData("s1", &types.VisualData{
+		Operation:   "create",
+		DiagramID:   "release",
+		DiagramType: "flowchart",
+		Elements: []types.VisualElement{
+			{ID: "begin", Type: "start", Label: "Begin", Properties: position(0, 0, map[string]interface{}{})},
+			{ID: "check", Type: "decision", Label: "Tests pass?", Properties: position(0, 100, map[string]interface{}{"color": "#e03131"})},
+			{ID: "ship", Type: "process", Label: "Deploy", Properties: position(0, 200, map[string]interface{}{"owner": "ops", "bad key": "dropped"})},
+			{ID: "begin->check", Type: "edge", Source: "begin", Target: "check"},
+			{ID: "check->ship", Type: "edge", Label: "yes", Source: "check", Target: "ship", Probability: 0.5},
+			{ID: "stage", Type: "stage", Label: "Verify", Contains: []string{"check", "ship"}},
+		},
+		CreatedAt: time.Now(),
+	}))
+	require.NoError(t, store.AddVisualData("s1", &types.VisualData{
+		Operation: "create",
+		DiagramID: "ideas",
+		Elements: []types.VisualElement{
+			{ID: "a", Type: "concept", Label: "Cars"},
+			{ID: "b", Type: "concept", Label: "Roads"},
+			{ID: "a->b", Type: "edge", Source: "a", Target: "b"},
+		},
+		CreatedAt: time.Now().Add(time.Second),
+	}))
+
+	export, err := store.ExportSession("s1")
+	require.NoError(t, err)
+	return export
+}
+
+func TestRenderExport_Excalidraw(t *testing.T) {
+	files, err := RenderExport(newWhiteboardFixture(t), FormatExcalidraw)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "release.excalidraw", files[0].Name)
+	assert.Equal(t, "application/vnd.excalidraw+json", files[0].ContentType)
+
+	var scene struct {
+		Type     string                   `json:"type"`
+		Elements []map[string]interface{} `json:"elements"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(files[0].Content), &scene))
+	assert.Equal(t, "excalidraw", scene.Type)
+	elements := make(map[string]map[string]interface{})
+	var order []string
+	for _, element := range scene.Elements {
+		elements[element["id"].(string)] = element
+		order = append(order, element["id"].(string))
+	}
+	// The stage is drawn first, beneath its members
+	assert.Equal(t, []string{"stage", "stage:label", "begin", "begin:label", "check", "check:label", "ship", "ship:label",
+		"begin->check", "check->ship", "check->ship:label"}, order)
+
+	assert.Equal(t, "ellipse", elements["begin"]["type"])
+	check := elements["check"]
+	assert.Equal(t, "diamond", check["type"])
+	// Positions are spread by half again and mark node centers
+	assert.Equal(t, []interface{}{-60.0, 120.0, 120.0, 60.0}, []interface{}{check["x"], check["y"], check["width"], check["height"]})
+	assert.Equal(t, "#e03131", check["strokeColor"])
+	assert.Equal(t, map[string]interface{}{"color": "#e03131", "type": "decision"}, check["customData"])
+	assert.Equal(t, "Tests pass?", elements["check:label"]["text"])
+	assert.Equal(t, "check", elements["check:label"]["containerId"])
+
+	stage := elements["stage"]
+	assert.Equal(t, "dashed", stage["strokeStyle"])
+	assert.Equal(t, []interface{}{-80.0, 70.0, 160.0, 280.0}, []interface{}{stage["x"], stage["y"], stage["width"], stage["height"]})
+	assert.Equal(t, "Verify", elements["stage:label"]["text"])
+
+	arrow := elements["check->ship"]
+	assert.Equal(t, "arrow", arrow["type"])
+	assert.Equal(t, "check", arrow["startBinding"].(map[string]interface{})["elementId"])
+	assert.Equal(t, "ship", arrow["endBinding"].(map[string]interface{})["elementId"])
+	assert.Equal(t, []interface{}{[]interface{}{0.0, 0.0}, []interface{}{0.0, 82.0}}, arrow["points"])
+	assert.Equal(t, 2.0, arrow["strokeWidth"])
+	assert.Equal(t, "yes (0.5)", elements["check->ship:label"]["text"])
+	assert.Contains(t, check["boundElements"], map[string]interface{}{"id": "check->ship", "type": "arrow"})
+
+	// The concept map was never laid out, so it is laid out on export
+	require.NoError(t, json.Unmarshal([]byte(files[1].Content), &scene))
+	require.Len(t, scene.Elements, 5)
+	a, b := scene.Elements[0], scene.Elements[2]
+	assert.NotEqual(t, a["x"], b["x"])
+}
+
+func TestRenderExport_DrawIO(t *testing.T) {
+	files, err := RenderExport(newWhiteboardFixture(t), FormatDrawIO)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "release.drawio", files[0].Name)
+	assert.Equal(t, "application/vnd.jgraph.mxfile", files[0].ContentType)
+
+	var file drawioFile
+	require.NoError(t, xml.Unmarshal([]byte(files[0].Content), &file))
+	assert.Equal(t, "release", file.Diagram.Name)
+	root := file.Diagram.Model.Root
+	require.Len(t, root.Cells, 2)
+	assert.Equal(t, drawioLayerID, root.Cells[1].ID)
+
+	objects := make(map[string]drawioObject)
+	var order []string
+	for _, object := range root.Objects {
+		objects[object.ID] = object
+		order = append(order, object.ID)
+		assert.Equal(t, drawioLayerID, object.Cell.Parent)
+	}
+	assert.Equal(t, []string{"stage", "begin", "check", "ship", "begin->check", "check->ship"}, order)
+
+	data := func(object drawioObject) map[string]string {
+		values := make(map[string]string)
+		for _, attr := range object.Data {
+			values[attr.Name.Local] = attr.Value
+		}
+		return values
+	}
+	check := objects["check"]
+	assert.Equal(t, "Tests pass?", check.Label)
+	assert.Equal(t, "rhombus;strokeColor=#e03131;", check.Cell.Style)
+	assert.Equal(t, "1", check.Cell.Vertex)
+	assert.Equal(t, drawioGeometry{X: -60, Y: 120, Width: 120, Height: 60, As: "geometry"}, *check.Cell.Geometry)
+	// Properties that are not XML names are dropped
+	assert.Equal(t, map[string]string{"owner": "ops", "type": "process"}, data(objects["ship"]))
+	assert.Equal(t, "rounded=1;", objects["ship"].Cell.Style)
+	assert.Contains(t, objects["stage"].Cell.Style, "dashed=1;")
+
+	edge := objects["check->ship"]
+	assert.Equal(t, "yes (0.5)", edge.Label)
+	assert.Equal(t, map[string]string{"probability": "0.5", "type": "edge"}, data(edge))
+	assert.Equal(t, drawioCell{Style: "endArrow=classic;strokeWidth=2;", Edge: "1", Parent: drawioLayerID, Source: "check", Target: "ship",
+		Geometry: &drawioGeometry{Relative: "1", As: "geometry"}}, edge.Cell)
+}
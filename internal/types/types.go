@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // ============================================================================
 // Core Thinking Types
@@ -9,6 +13,7 @@ import "time"
 // ThoughtData represents a single thought in a sequential thinking process
 type ThoughtData struct {
 	ID                string    `json:"id"`
+	SessionID         string    `json:"session_id,omitempty"`
 	Thought           string    `json:"thought"`
 	ThoughtNumber     int       `json:"thought_number"`
 	TotalThoughts     int       `json:"total_thoughts"`
@@ -24,6 +29,7 @@ type ThoughtData struct {
 // MentalModelData represents the application of a mental model to a problem
 type MentalModelData struct {
 	ID         string    `json:"id"`
+	SessionID  string    `json:"session_id,omitempty"`
 	ModelName  string    `json:"model_name"`
 	Problem    string    `json:"problem"`
 	Steps      []string  `json:"steps"`
@@ -33,6 +39,16 @@ type MentalModelData struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// DebuggingModelPrefix marks mental model records that hold a debugging
+// approach: Problem is the issue, Reasoning the findings and Conclusion the
+// resolution
+const DebuggingModelPrefix = "debugging_"
+
+// IsDebuggingApproach reports whether the record holds a debugging approach
+func (m *MentalModelData) IsDebuggingApproach() bool {
+	return strings.HasPrefix(m.ModelName, DebuggingModelPrefix)
+}
+
 // ============================================================================
 // Stochastic Algorithm Types
 // ============================================================================
@@ -40,6 +56,7 @@ type MentalModelData struct {
 // StochasticAlgorithmData represents the application of a stochastic algorithm
 type StochasticAlgorithmData struct {
 	ID         string                 `json:"id"`
+	SessionID  string                 `json:"session_id,omitempty"`
 	Algorithm  string                 `json:"algorithm"`
 	Problem    string                 `json:"problem"`
 	Parameters map[string]interface{} `json:"parameters"`
@@ -47,7 +64,26 @@ type StochasticAlgorithmData struct {
 	Confidence float64                `json:"confidence,omitempty"`
 	Iterations int                    `json:"iterations,omitempty"`
 	Converged  bool                   `json:"converged,omitempty"`
-	CreatedAt  time.Time              `json:"created_at"`
+	// ConfidenceMethod is how Confidence was computed from the run, when the
+	// run computes one
+	ConfidenceMethod string `json:"confidence_method,omitempty"`
+	// StoppingReason is the reason the run stopped, and Value its headline
+	// value, such as the best objective value found, when it has one
+	StoppingReason string   `json:"stopping_reason,omitempty"`
+	Value          *float64 `json:"value,omitempty"`
+	// Rerun repeats the run, for runs of registered algorithms
+	Rerun *Rerun `json:"rerun,omitempty"`
+	// Payload keeps, as a JSON object, the fields of the run's typed data
+	// beyond these, such as an MDP's policy or a bandit's arm statistics
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Rerun is what repeats a stochastic algorithm run: the registered algorithm
+// that ran it and its request, with the defaults and seed it ran with
+type Rerun struct {
+	Algorithm string                 `json:"algorithm"`
+	Request   map[string]interface{} `json:"request"`
 }
 
 // MDPData represents Markov Decision Process specific data
@@ -56,28 +92,103 @@ type MDPData struct {
 	Policy        map[string]string             `json:"policy,omitempty"`
 	ValueFunction map[string]float64            `json:"value_function,omitempty"`
 	QValues       map[string]map[string]float64 `json:"q_values,omitempty"`
+	Convergence   *Convergence                  `json:"convergence,omitempty"`
+}
+
+// Convergence reports how a stochastic algorithm run ended: whether it
+// converged, the reason it stopped, its iterations and the residual of each
+// iteration or checkpoint, the last being Residual
+type Convergence struct {
+	Method         string    `json:"method,omitempty"`
+	Iterations     int       `json:"iterations"`
+	Converged      bool      `json:"converged"`
+	StoppingReason string    `json:"stopping_reason"`
+	Tolerance      float64   `json:"tolerance,omitempty"`
+	Residual       float64   `json:"residual"`
+	Residuals      []float64 `json:"residuals"`
+	RHat           float64   `json:"r_hat,omitempty"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+}
+
+// QLearningData represents a tabular Q-learning, SARSA or expected SARSA
+// run: the learned policy, its values and the learning curve, one entry per
+// episode
+type QLearningData struct {
+	StochasticAlgorithmData
+	Policy        map[string]string             `json:"policy,omitempty"`
+	PolicyGrid    []string                      `json:"policy_grid,omitempty"`
+	ValueFunction map[string]float64            `json:"value_function,omitempty"`
+	QValues       map[string]map[string]float64 `json:"q_values,omitempty"`
+	LearningCurve []LearningEpisode             `json:"learning_curve,omitempty"`
+	Convergence   *Convergence                  `json:"convergence,omitempty"`
+}
+
+// LearningEpisode reports the reward collected in one episode of learning,
+// its steps, the exploration rate it ran with and the largest change of a
+// Q-value over it
+type LearningEpisode struct {
+	Episode  int     `json:"episode"`
+	Reward   float64 `json:"reward"`
+	Steps    int     `json:"steps"`
+	Epsilon  float64 `json:"epsilon"`
+	Residual float64 `json:"residual"`
 }
 
 // MCTSData represents Monte Carlo Tree Search specific data
 type MCTSData struct {
 	StochasticAlgorithmData
-	BestAction string                 `json:"best_action,omitempty"`
-	TreeStats  map[string]interface{} `json:"tree_stats,omitempty"`
+	BestAction         string                 `json:"best_action,omitempty"`
+	ActionStats        []MCTSActionStats      `json:"action_stats,omitempty"`
+	PrincipalVariation []string               `json:"principal_variation,omitempty"`
+	TreeStats          map[string]interface{} `json:"tree_stats,omitempty"`
+	Convergence        *Convergence           `json:"convergence,omitempty"`
+}
+
+// MCTSActionStats are the visits of one move from the root of a search and
+// the mean reward of the playouts through it
+type MCTSActionStats struct {
+	Move   string  `json:"move"`
+	Visits int     `json:"visits"`
+	Q      float64 `json:"q"`
 }
 
 // BanditData represents Multi-Armed Bandit specific data
 type BanditData struct {
 	StochasticAlgorithmData
-	ArmStats    []ArmStatistics `json:"arm_stats,omitempty"`
-	SelectedArm int             `json:"selected_arm,omitempty"`
+	ArmStats     []ArmStatistics `json:"arm_stats,omitempty"`
+	SelectedArm  int             `json:"selected_arm,omitempty"`
+	OptimalArm   int             `json:"optimal_arm,omitempty"`
+	Regret       float64         `json:"regret,omitempty"`
+	RegretCurve  []RegretPoint   `json:"regret_curve,omitempty"`
+	ChangePoints []ChangePoint   `json:"change_points,omitempty"`
+	Convergence  *Convergence    `json:"convergence,omitempty"`
 }
 
 // ArmStatistics represents statistics for a bandit arm
 type ArmStatistics struct {
-	Arm           int     `json:"arm"`
-	Pulls         int     `json:"pulls"`
-	Rewards       float64 `json:"rewards"`
-	AverageReward float64 `json:"average_reward"`
+	Arm            int     `json:"arm"`
+	Name           string  `json:"name,omitempty"`
+	Pulls          int     `json:"pulls"`
+	Rewards        float64 `json:"rewards"`
+	AverageReward  float64 `json:"average_reward"`
+	ExpectedReward float64 `json:"expected_reward"`
+}
+
+// ChangePoint represents a change of a bandit arm's mean detected at Step,
+// after the arm had paid out Before on average since its previous one
+type ChangePoint struct {
+	Arm       int     `json:"arm"`
+	Name      string  `json:"name,omitempty"`
+	Step      int     `json:"step"`
+	Direction string  `json:"direction"`
+	Before    float64 `json:"before"`
+}
+
+// RegretPoint represents the cumulative regret of a bandit run after Step
+// pulls
+type RegretPoint struct {
+	Step   int     `json:"step"`
+	Regret float64 `json:"regret"`
 }
 
 // BayesianOptimizationData represents Bayesian Optimization specific data
@@ -86,22 +197,243 @@ type BayesianOptimizationData struct {
 	OptimizationHistory []OptimizationStep `json:"optimization_history,omitempty"`
 	BestParameters      map[string]float64 `json:"best_parameters,omitempty"`
 	BestValue           float64            `json:"best_value,omitempty"`
+	BestPosterior       GPPosterior        `json:"best_posterior"`
+	NextParameters      map[string]float64 `json:"next_parameters,omitempty"`
+	Convergence         *Convergence       `json:"convergence,omitempty"`
 }
 
 // OptimizationStep represents a step in Bayesian optimization
 type OptimizationStep struct {
-	Iteration  int                `json:"iteration"`
-	Parameters map[string]float64 `json:"parameters"`
-	Value      float64            `json:"value"`
+	Iteration   int                `json:"iteration"`
+	Parameters  map[string]float64 `json:"parameters"`
+	Value       float64            `json:"value"`
+	Random      bool               `json:"random,omitempty"`
+	Acquisition float64            `json:"acquisition,omitempty"`
+	Predicted   GPPosterior        `json:"predicted"`
+}
+
+// GPPosterior represents the posterior mean and variance of a Gaussian
+// process at a point
+type GPPosterior struct {
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
 }
 
 // HMMData represents Hidden Markov Model specific data
 type HMMData struct {
 	StochasticAlgorithmData
-	StateSequence           []int       `json:"state_sequence,omitempty"`
-	TransitionProbabilities [][]float64 `json:"transition_probabilities,omitempty"`
-	EmissionProbabilities   [][]float64 `json:"emission_probabilities,omitempty"`
-	InitialProbabilities    []float64   `json:"initial_probabilities,omitempty"`
+	StateSequence           []int        `json:"state_sequence,omitempty"`
+	TransitionProbabilities [][]float64  `json:"transition_probabilities,omitempty"`
+	EmissionProbabilities   [][]float64  `json:"emission_probabilities,omitempty"`
+	InitialProbabilities    []float64    `json:"initial_probabilities,omitempty"`
+	StatePath               []string     `json:"state_path,omitempty"`
+	LogLikelihood           float64      `json:"log_likelihood,omitempty"`
+	Convergence             *Convergence `json:"convergence,omitempty"`
+}
+
+// AnnealingData represents a simulated annealing run: the best point found,
+// where the search ended and its trajectory
+type AnnealingData struct {
+	StochasticAlgorithmData
+	BestPoint   map[string]float64 `json:"best_point,omitempty"`
+	BestValue   float64            `json:"best_value,omitempty"`
+	FinalPoint  map[string]float64 `json:"final_point,omitempty"`
+	FinalValue  float64            `json:"final_value,omitempty"`
+	Trajectory  []AnnealingStep    `json:"trajectory,omitempty"`
+	Convergence *Convergence       `json:"convergence,omitempty"`
+}
+
+// AnnealingStep represents the state of an annealing run after an iteration
+type AnnealingStep struct {
+	Iteration      int                `json:"iteration"`
+	Temperature    float64            `json:"temperature"`
+	Point          map[string]float64 `json:"point"`
+	Value          float64            `json:"value"`
+	BestValue      float64            `json:"best_value"`
+	AcceptanceRate float64            `json:"acceptance_rate"`
+}
+
+// MonteCarloData represents a Monte Carlo simulation: the distribution of
+// its output over the trials run
+type MonteCarloData struct {
+	StochasticAlgorithmData
+	Mean        float64                `json:"mean,omitempty"`
+	StdDev      float64                `json:"std_dev,omitempty"`
+	Percentiles []MonteCarloPercentile `json:"percentiles,omitempty"`
+	Histogram   []HistogramBucket      `json:"histogram,omitempty"`
+	Exceedances []Exceedance           `json:"exceedances,omitempty"`
+	Convergence *Convergence           `json:"convergence,omitempty"`
+}
+
+// MonteCarloPercentile represents a percentile of a simulated output
+type MonteCarloPercentile struct {
+	Percentile float64 `json:"percentile"`
+	Value      float64 `json:"value"`
+}
+
+// HistogramBucket represents the count of simulated outputs from Low up to
+// High
+type HistogramBucket struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Count int     `json:"count"`
+}
+
+// Exceedance represents the probability of a simulated output exceeding a
+// threshold
+type Exceedance struct {
+	Threshold   float64 `json:"threshold"`
+	Probability float64 `json:"probability"`
+}
+
+// ParticleFilterData represents a particle filter run: the filtered
+// estimates of the latent state at each step
+type ParticleFilterData struct {
+	StochasticAlgorithmData
+	Steps         []FilterStep `json:"steps,omitempty"`
+	LogLikelihood float64      `json:"log_likelihood,omitempty"`
+}
+
+// FilterStep represents a particle filter's belief after an observation
+type FilterStep struct {
+	Step                int                      `json:"step"`
+	Observation         float64                  `json:"observation"`
+	Predicted           float64                  `json:"predicted"`
+	Estimates           map[string]StateEstimate `json:"estimates"`
+	EffectiveSampleSize float64                  `json:"effective_sample_size"`
+	Resampled           bool                     `json:"resampled"`
+}
+
+// StateEstimate represents the filtered mean, spread and uncertainty band of
+// a state variable
+type StateEstimate struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Lower  float64 `json:"lower"`
+	Upper  float64 `json:"upper"`
+}
+
+// BootstrapData represents a bootstrap analysis: a statistic of a dataset and
+// its confidence intervals
+type BootstrapData struct {
+	StochasticAlgorithmData
+	Estimate           float64            `json:"estimate"`
+	StdError           float64            `json:"std_error,omitempty"`
+	Bias               float64            `json:"bias,omitempty"`
+	PercentileInterval ConfidenceInterval `json:"percentile_interval"`
+	BasicInterval      ConfidenceInterval `json:"basic_interval"`
+	NormalInterval     ConfidenceInterval `json:"normal_interval"`
+}
+
+// ConfidenceInterval represents the bounds of a confidence interval
+type ConfidenceInterval struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// QueueingData represents a queueing simulation: the utilization, waits and
+// line lengths of a simulated queue
+type QueueingData struct {
+	StochasticAlgorithmData
+	Servers         int          `json:"servers"`
+	Stable          bool         `json:"stable"`
+	Utilization     float64      `json:"utilization"`
+	MeanWait        float64      `json:"mean_wait"`
+	MeanQueueLength float64      `json:"mean_queue_length"`
+	QueueLengths    []QueueShare `json:"queue_lengths,omitempty"`
+	Convergence     *Convergence `json:"convergence,omitempty"`
+}
+
+// QueueShare represents the share of the time a queue had a length
+type QueueShare struct {
+	Length      int     `json:"length"`
+	Probability float64 `json:"probability"`
+}
+
+// ABTestData represents an A/B test analysis: each variant's conversion
+// rate and how it compares with the control
+type ABTestData struct {
+	StochasticAlgorithmData
+	Control  string          `json:"control"`
+	Best     string          `json:"best"`
+	Variants []ABTestVariant `json:"variants,omitempty"`
+}
+
+// ABTestVariant represents the analysis of a variant of an A/B test
+type ABTestVariant struct {
+	Name              string   `json:"name"`
+	Visitors          int      `json:"visitors"`
+	Conversions       int      `json:"conversions"`
+	Rate              float64  `json:"rate"`
+	PosteriorMean     float64  `json:"posterior_mean"`
+	ProbabilityBest   float64  `json:"probability_best"`
+	ExpectedLoss      float64  `json:"expected_loss"`
+	PValue            *float64 `json:"p_value,omitempty"`
+	ProbabilityToBeat *float64 `json:"probability_to_beat,omitempty"`
+}
+
+// ParameterSweepData represents a parameter sweep: the result of each
+// configuration of an algorithm's hyperparameters
+type ParameterSweepData struct {
+	StochasticAlgorithmData
+	Results []SweepResult `json:"results,omitempty"`
+	Best    *SweepResult  `json:"best,omitempty"`
+}
+
+// SensitivityData represents a sensitivity analysis: how far a recorded
+// run's value moves as each of its parameters is perturbed
+type SensitivityData struct {
+	StochasticAlgorithmData
+	Sensitivities []ParameterSensitivity `json:"sensitivities,omitempty"`
+}
+
+// ParameterSensitivity represents the runs of a perturbed parameter and the
+// range of values they reached
+type ParameterSensitivity struct {
+	Name    string        `json:"name"`
+	Base    interface{}   `json:"base"`
+	Results []SweepResult `json:"results"`
+	Low     *float64      `json:"low,omitempty"`
+	High    *float64      `json:"high,omitempty"`
+	Swing   float64       `json:"swing"`
+}
+
+// DominanceData represents a comparison of two distributions of outcomes by
+// stochastic dominance and by their tails
+type DominanceData struct {
+	StochasticAlgorithmData
+	A                  DominanceSummary `json:"a"`
+	B                  DominanceSummary `json:"b"`
+	FirstOrder         string           `json:"first_order,omitempty"`
+	SecondOrder        string           `json:"second_order,omitempty"`
+	Leader             string           `json:"leader,omitempty"`
+	Epsilon            float64          `json:"epsilon"`
+	KolmogorovSmirnov  float64          `json:"kolmogorov_smirnov"`
+	ProbabilityABetter float64          `json:"probability_a_better"`
+	DecisionID         string           `json:"decision_id,omitempty"`
+}
+
+// DominanceSummary represents a compared distribution: where it came from,
+// its mean and spread, and its tail
+type DominanceSummary struct {
+	Name        string  `json:"name"`
+	AlgorithmID string  `json:"algorithm_id,omitempty"`
+	Samples     int     `json:"samples"`
+	Mean        float64 `json:"mean"`
+	StdDev      float64 `json:"std_dev"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	VaR         float64 `json:"var"`
+	CVaR        float64 `json:"cvar"`
+}
+
+// SweepResult represents the outcome of a configuration of a sweep
+type SweepResult struct {
+	Configuration map[string]interface{} `json:"configuration"`
+	Value         *float64               `json:"value,omitempty"`
+	Converged     bool                   `json:"converged"`
+	Iterations    int                    `json:"iterations"`
+	Error         string                 `json:"error,omitempty"`
 }
 
 // ============================================================================
@@ -110,12 +442,35 @@ type HMMData struct {
 
 // DecisionOption represents an option in a decision
 type DecisionOption struct {
-	ID                   string  `json:"id,omitempty"`
-	Name                 string  `json:"name"`
-	Description          string  `json:"description"`
-	ExpectedValue        float64 `json:"expected_value,omitempty"`
-	RiskLevel            string  `json:"risk_level,omitempty"`
-	ProbabilityOfSuccess float64 `json:"probability_of_success,omitempty"`
+	ID                   string               `json:"id,omitempty"`
+	Name                 string               `json:"name"`
+	Description          string               `json:"description"`
+	ExpectedValue        *float64             `json:"expected_value,omitempty"`
+	RiskLevel            string               `json:"risk_level,omitempty"`
+	ProbabilityOfSuccess *float64             `json:"probability_of_success,omitempty"`
+	Outcome              *OutcomeDistribution `json:"outcome,omitempty"`
+	CashFlows            []CashFlow           `json:"cash_flows,omitempty"`
+}
+
+// CashFlow represents an option's cost or benefit, or both, in a period
+type CashFlow struct {
+	Period  int     `json:"period"`
+	Cost    float64 `json:"cost,omitempty"`
+	Benefit float64 `json:"benefit,omitempty"`
+}
+
+// OutcomeDistribution represents the distribution of an option's outcome
+type OutcomeDistribution struct {
+	Distribution  string    `json:"distribution"`
+	Mean          float64   `json:"mean,omitempty"`
+	StdDev        float64   `json:"std_dev,omitempty"`
+	Min           float64   `json:"min,omitempty"`
+	Mode          float64   `json:"mode,omitempty"`
+	Max           float64   `json:"max,omitempty"`
+	Alpha         float64   `json:"alpha,omitempty"`
+	Beta          float64   `json:"beta,omitempty"`
+	Values        []float64 `json:"values,omitempty"`
+	Probabilities []float64 `json:"probabilities,omitempty"`
 }
 
 // DecisionCriterion represents a criterion for evaluating options
@@ -125,11 +480,353 @@ type DecisionCriterion struct {
 	Description      string  `json:"description"`
 	Weight           float64 `json:"weight"`
 	EvaluationMethod string  `json:"evaluation_method"`
+	Direction        string  `json:"direction,omitempty"`
+}
+
+// DecisionScore represents how an option scores on a criterion
+type DecisionScore struct {
+	Option    string  `json:"option"`
+	Criterion string  `json:"criterion"`
+	Score     float64 `json:"score"`
+	// Rating is the qualitative rating the score was recorded from, if any
+	Rating    string `json:"rating,omitempty"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// RankedOption represents an option's place in a decision's ranking: its
+// combined score and what each criterion contributed to it
+type RankedOption struct {
+	Rank              int                `json:"rank"`
+	Option            string             `json:"option"`
+	Score             float64            `json:"score"`
+	Contributions     map[string]float64 `json:"contributions"`
+	IdealDistance     float64            `json:"ideal_distance,omitempty"`
+	AntiIdealDistance float64            `json:"anti_ideal_distance,omitempty"`
+}
+
+// DecisionRisk represents a risk in a decision's register, threatening one
+// option or, naming none, every option
+type DecisionRisk struct {
+	Name        string           `json:"name"`
+	Option      string           `json:"option,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Probability float64          `json:"probability"`
+	Impact      float64          `json:"impact"`
+	Mitigations []RiskMitigation `json:"mitigations,omitempty"`
+}
+
+// RiskMitigation represents a measure cutting a risk's probability and
+// impact by fractions, at a cost
+type RiskMitigation struct {
+	Name                 string  `json:"name"`
+	ProbabilityReduction float64 `json:"probability_reduction,omitempty"`
+	ImpactReduction      float64 `json:"impact_reduction,omitempty"`
+	Cost                 float64 `json:"cost,omitempty"`
+}
+
+// RiskCell represents a risk's place on a probability-impact matrix
+type RiskCell struct {
+	ProbabilityLevel int    `json:"probability_level"`
+	ImpactLevel      int    `json:"impact_level"`
+	Severity         int    `json:"severity"`
+	Rating           string `json:"rating"`
+}
+
+// AssessedRisk represents a risk's assessment before and after mitigation
+type AssessedRisk struct {
+	Name                string   `json:"name"`
+	Option              string   `json:"option,omitempty"`
+	Inherent            RiskCell `json:"inherent"`
+	Residual            RiskCell `json:"residual"`
+	ResidualProbability float64  `json:"residual_probability"`
+	ResidualImpact      float64  `json:"residual_impact"`
+	ExpectedLoss        float64  `json:"expected_loss"`
+	MitigationCost      float64  `json:"mitigation_cost"`
+}
+
+// OptionRisk represents the risk an option runs and its value adjusted for it
+type OptionRisk struct {
+	Option            string  `json:"option"`
+	Risks             int     `json:"risks"`
+	ExpectedValue     float64 `json:"expected_value"`
+	ExpectedLoss      float64 `json:"expected_loss"`
+	MitigationCost    float64 `json:"mitigation_cost"`
+	RiskAdjustedValue float64 `json:"risk_adjusted_value"`
+	Rating            string  `json:"rating"`
+}
+
+// RiskAssessment represents the assessment of a decision's risk register:
+// probability-impact matrices counting the risks by probability level, the
+// rows, and impact level, the columns, and the options by risk-adjusted value
+type RiskAssessment struct {
+	Risks          []AssessedRisk `json:"risks"`
+	InherentMatrix [][]int        `json:"inherent_matrix"`
+	ResidualMatrix [][]int        `json:"residual_matrix"`
+	Options        []OptionRisk   `json:"options"`
+	Recommendation string         `json:"recommendation"`
+}
+
+// DecisionStakeholder represents a stakeholder's influence over a decision,
+// interest in it and support for its options
+type DecisionStakeholder struct {
+	Name        string                  `json:"name"`
+	Role        string                  `json:"role,omitempty"`
+	Influence   float64                 `json:"influence"`
+	Interest    float64                 `json:"interest"`
+	Preferences []StakeholderPreference `json:"preferences,omitempty"`
+}
+
+// StakeholderPreference represents a stakeholder's support for an option
+type StakeholderPreference struct {
+	Option  string  `json:"option"`
+	Support float64 `json:"support"`
+}
+
+// PositionedStakeholder represents a stakeholder's place on the
+// influence/interest grid and its weight
+type PositionedStakeholder struct {
+	Name      string  `json:"name"`
+	Influence float64 `json:"influence"`
+	Interest  float64 `json:"interest"`
+	Quadrant  string  `json:"quadrant"`
+	Weight    float64 `json:"weight"`
+}
+
+// StakeholderGrid represents the stakeholders of each quadrant of the
+// influence/interest grid
+type StakeholderGrid struct {
+	ManageClosely []string `json:"manage_closely"`
+	KeepSatisfied []string `json:"keep_satisfied"`
+	KeepInformed  []string `json:"keep_informed"`
+	Monitor       []string `json:"monitor"`
+}
+
+// StakeholderScore represents an option's stakeholder-weighted score
+type StakeholderScore struct {
+	Rank       int      `json:"rank"`
+	Option     string   `json:"option"`
+	Score      float64  `json:"score"`
+	Supporters []string `json:"supporters,omitempty"`
+	Opponents  []string `json:"opponents,omitempty"`
+}
+
+// StakeholderAnalysis represents the analysis of a decision's stakeholders
+type StakeholderAnalysis struct {
+	Stakeholders   []PositionedStakeholder `json:"stakeholders"`
+	Grid           StakeholderGrid         `json:"grid"`
+	Options        []StakeholderScore      `json:"options"`
+	Recommendation string                  `json:"recommendation"`
+}
+
+// DecisionEvaluation represents an evaluator's scores of a decision's options
+type DecisionEvaluation struct {
+	Evaluator string           `json:"evaluator"`
+	Scores    []EvaluatorScore `json:"scores"`
+}
+
+// EvaluatorScore represents an evaluator's score of an option
+type EvaluatorScore struct {
+	Option string  `json:"option"`
+	Score  float64 `json:"score"`
+}
+
+// GroupStanding represents an option's place in a group ranking
+type GroupStanding struct {
+	Rank   int     `json:"rank"`
+	Option string  `json:"option"`
+	Score  float64 `json:"score"`
+}
+
+// GroupOption represents an option's standing under every aggregation method
+// and the spread of its ranks and scores
+type GroupOption struct {
+	Option        string  `json:"option"`
+	BordaPoints   float64 `json:"borda_points"`
+	CopelandScore int     `json:"copeland_score"`
+	RangeScore    float64 `json:"range_score"`
+	MeanRank      float64 `json:"mean_rank"`
+	RankSpread    float64 `json:"rank_spread"`
+	ScoreSpread   float64 `json:"score_spread"`
+}
+
+// EvaluatorConflict represents the correlation of two evaluators' rankings
+type EvaluatorConflict struct {
+	EvaluatorA  string  `json:"evaluator_a"`
+	EvaluatorB  string  `json:"evaluator_b"`
+	Correlation float64 `json:"correlation"`
+}
+
+// GroupAggregation represents the aggregation of a decision's evaluations
+type GroupAggregation struct {
+	Method          string              `json:"method"`
+	Evaluators      int                 `json:"evaluators"`
+	Ranking         []GroupStanding     `json:"ranking"`
+	Options         []GroupOption       `json:"options"`
+	Pairwise        [][]int             `json:"pairwise"`
+	CondorcetWinner string              `json:"condorcet_winner,omitempty"`
+	Disagreement    float64             `json:"disagreement"`
+	Conflicts       []EvaluatorConflict `json:"conflicts,omitempty"`
+	Recommendation  string              `json:"recommendation"`
+}
+
+// OptionCostBenefit represents an option's appraisal by its discounted costs
+// and benefits; undefined measures are nil
+type OptionCostBenefit struct {
+	Rank                    int      `json:"rank"`
+	Option                  string   `json:"option"`
+	NPV                     float64  `json:"npv"`
+	PresentBenefits         float64  `json:"present_benefits"`
+	PresentCosts            float64  `json:"present_costs"`
+	BenefitCostRatio        *float64 `json:"benefit_cost_ratio,omitempty"`
+	IRR                     *float64 `json:"irr,omitempty"`
+	PaybackPeriod           *float64 `json:"payback_period,omitempty"`
+	DiscountedPaybackPeriod *float64 `json:"discounted_payback_period,omitempty"`
+}
+
+// CostBenefitAnalysis represents the appraisal of a decision's options
+type CostBenefitAnalysis struct {
+	DiscountRate   float64             `json:"discount_rate"`
+	Options        []OptionCostBenefit `json:"options"`
+	Recommendation string              `json:"recommendation"`
+}
+
+// MustObjective represents a mandatory objective of a Kepner-Tregoe analysis
+type MustObjective struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// WantObjective represents a desirable objective of a Kepner-Tregoe
+// analysis, weighted from 1 to 10
+type WantObjective struct {
+	Name        string `json:"name"`
+	Weight      int    `json:"weight"`
+	Description string `json:"description,omitempty"`
+}
+
+// MustResult represents whether an option meets a MUST objective
+type MustResult struct {
+	Must     string `json:"must"`
+	Met      bool   `json:"met"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// WantScore represents an option's score, from 0 to 10, against a WANT
+// objective
+type WantScore struct {
+	Want      string  `json:"want"`
+	Score     float64 `json:"score"`
+	Rationale string  `json:"rationale,omitempty"`
+}
+
+// AdverseConsequence represents a risk of an option, its probability and
+// seriousness rated from 1 to 10
+type AdverseConsequence struct {
+	Description string `json:"description"`
+	Probability int    `json:"probability"`
+	Seriousness int    `json:"seriousness"`
+	Threat      int    `json:"threat"`
+}
+
+// KepnerTregoeOption represents an option's Kepner-Tregoe assessment
+type KepnerTregoeOption struct {
+	Rank                int                  `json:"rank,omitempty"`
+	Option              string               `json:"option"`
+	Qualified           bool                 `json:"qualified"`
+	Musts               []MustResult         `json:"musts,omitempty"`
+	FailedMusts         []string             `json:"failed_musts,omitempty"`
+	Wants               []WantScore          `json:"wants,omitempty"`
+	WeightedScore       float64              `json:"weighted_score"`
+	Share               float64              `json:"share"`
+	AdverseConsequences []AdverseConsequence `json:"adverse_consequences,omitempty"`
+	Threat              int                  `json:"threat"`
+	SeriousRisks        []string             `json:"serious_risks,omitempty"`
+}
+
+// KepnerTregoeAnalysis represents a Kepner-Tregoe analysis of a decision's
+// options
+type KepnerTregoeAnalysis struct {
+	Musts          []MustObjective      `json:"musts,omitempty"`
+	Wants          []WantObjective      `json:"wants"`
+	Options        []KepnerTregoeOption `json:"options"`
+	BalancedChoice string               `json:"balanced_choice,omitempty"`
+	Recommendation string               `json:"recommendation"`
+}
+
+// HybridOption represents an option's estimate by a stochastic algorithm
+type HybridOption struct {
+	Rank     int     `json:"rank"`
+	Option   string  `json:"option"`
+	Estimate float64 `json:"estimate"`
+	Pulls    int     `json:"pulls,omitempty"`
+}
+
+// HybridDecision represents a decision's options ranked by a stochastic
+// algorithm run on them, recorded under AlgorithmID
+type HybridDecision struct {
+	Algorithm      string         `json:"algorithm"`
+	AlgorithmID    string         `json:"algorithm_id"`
+	Options        []HybridOption `json:"options"`
+	Recommendation string         `json:"recommendation"`
+}
+
+// SimulatedOption represents an option's outcome simulated over many futures
+type SimulatedOption struct {
+	Option          string  `json:"option"`
+	ProbabilityBest float64 `json:"probability_best"`
+	ExpectedValue   float64 `json:"expected_value"`
+	StdDev          float64 `json:"std_dev"`
+	P5              float64 `json:"p5"`
+	P50             float64 `json:"p50"`
+	P95             float64 `json:"p95"`
+	VaR             float64 `json:"var"`
+	CVaR            float64 `json:"cvar"`
+}
+
+// DecisionSimulation represents a simulation of a decision's options
+type DecisionSimulation struct {
+	Futures        int               `json:"futures"`
+	Objective      string            `json:"objective"`
+	Alpha          float64           `json:"alpha"`
+	Seed           int64             `json:"seed"`
+	Options        []SimulatedOption `json:"options"`
+	Recommendation string            `json:"recommendation"`
+}
+
+// DecisionOutcome represents what came of a decision: the option taken,
+// whether it succeeded and the value it realized, with what was forecast for
+// it when the outcome was recorded
+type DecisionOutcome struct {
+	ChosenOption        string    `json:"chosen_option"`
+	Succeeded           *bool     `json:"succeeded,omitempty"`
+	RealizedValue       *float64  `json:"realized_value,omitempty"`
+	ForecastProbability *float64  `json:"forecast_probability,omitempty"`
+	ForecastValue       *float64  `json:"forecast_value,omitempty"`
+	Notes               string    `json:"notes,omitempty"`
+	RecordedAt          time.Time `json:"recorded_at"`
+}
+
+// DecisionIteration represents an earlier iteration of a decision, as it
+// stood before it was revised
+type DecisionIteration struct {
+	Iteration         int                 `json:"iteration"`
+	DecisionStatement string              `json:"decision_statement"`
+	AnalysisType      string              `json:"analysis_type"`
+	Stage             string              `json:"stage"`
+	Options           []DecisionOption    `json:"options"`
+	Criteria          []DecisionCriterion `json:"criteria,omitempty"`
+	Scores            []DecisionScore     `json:"scores,omitempty"`
+	ScoringMethod     string              `json:"scoring_method,omitempty"`
+	Normalization     string              `json:"normalization,omitempty"`
+	Ranking           []RankedOption      `json:"ranking,omitempty"`
+	Recommendation    string              `json:"recommendation,omitempty"`
+	RecordedAt        time.Time           `json:"recorded_at"`
 }
 
 // DecisionData represents a complete decision framework
 type DecisionData struct {
 	ID                string              `json:"id"`
+	SessionID         string              `json:"session_id,omitempty"`
 	DecisionStatement string              `json:"decision_statement"`
 	Options           []DecisionOption    `json:"options"`
 	Criteria          []DecisionCriterion `json:"criteria,omitempty"`
@@ -140,9 +837,30 @@ type DecisionData struct {
 	AnalysisType      string              `json:"analysis_type"`
 	Stage             string              `json:"stage"`
 	Recommendation    string              `json:"recommendation,omitempty"`
-	Iteration         int                 `json:"iteration"`
-	NextStageNeeded   bool                `json:"next_stage_needed"`
-	CreatedAt         time.Time           `json:"created_at"`
+	Scores            []DecisionScore     `json:"scores,omitempty"`
+	ScoringMethod     string              `json:"scoring_method,omitempty"`
+	Normalization     string              `json:"normalization,omitempty"`
+	Ranking           []RankedOption      `json:"ranking,omitempty"`
+	Risks             []DecisionRisk      `json:"risks,omitempty"`
+	RiskAssessment    *RiskAssessment     `json:"risk_assessment,omitempty"`
+	Simulation        *DecisionSimulation `json:"simulation,omitempty"`
+	// StakeholderProfiles model the stakeholders named in Stakeholders
+	StakeholderProfiles []DecisionStakeholder `json:"stakeholder_profiles,omitempty"`
+	StakeholderAnalysis *StakeholderAnalysis  `json:"stakeholder_analysis,omitempty"`
+	Evaluations         []DecisionEvaluation  `json:"evaluations,omitempty"`
+	GroupAggregation    *GroupAggregation     `json:"group_aggregation,omitempty"`
+	CostBenefit         *CostBenefitAnalysis  `json:"cost_benefit,omitempty"`
+	KepnerTregoe        *KepnerTregoeAnalysis `json:"kepner_tregoe,omitempty"`
+	Hybrid              *HybridDecision       `json:"hybrid,omitempty"`
+	Outcome             *DecisionOutcome      `json:"outcome,omitempty"`
+	DiagramID           string                `json:"diagram_id,omitempty"`
+	Iteration           int                   `json:"iteration"`
+	// History holds the earlier iterations of the decision, oldest first
+	History         []DecisionIteration `json:"history,omitempty"`
+	NextStageNeeded bool                `json:"next_stage_needed"`
+	CreatedAt       time.Time           `json:"created_at"`
+	// RevisedAt is when the current iteration was recorded, if not the first
+	RevisedAt *time.Time `json:"revised_at,omitempty"`
 }
 
 // ============================================================================
@@ -164,11 +882,13 @@ type VisualElement struct {
 // VisualData represents a visual reasoning operation
 type VisualData struct {
 	ID                  string          `json:"id"`
+	SessionID           string          `json:"session_id,omitempty"`
 	Operation           string          `json:"operation"`
 	Elements            []VisualElement `json:"elements,omitempty"`
 	TransformationType  string          `json:"transformation_type,omitempty"`
 	DiagramID           string          `json:"diagram_id"`
 	DiagramType         string          `json:"diagram_type"`
+	DecisionID          string          `json:"decision_id,omitempty"`
 	Iteration           int             `json:"iteration"`
 	Observation         string          `json:"observation,omitempty"`
 	Insight             string          `json:"insight,omitempty"`
@@ -177,6 +897,72 @@ type VisualData struct {
 	CreatedAt           time.Time       `json:"created_at"`
 }
 
+// ============================================================================
+// Critique Types
+// ============================================================================
+
+// CritiqueData represents an automated review of session reasoning
+type CritiqueData struct {
+	ID                  string    `json:"id"`
+	SessionID           string    `json:"session_id,omitempty"`
+	TargetType          string    `json:"target_type"`
+	TargetIDs           []string  `json:"target_ids"`
+	Model               string    `json:"model"`
+	Summary             string    `json:"summary"`
+	LogicalGaps         []string  `json:"logical_gaps,omitempty"`
+	MissingAlternatives []string  `json:"missing_alternatives,omitempty"`
+	RawCritique         string    `json:"raw_critique,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// ============================================================================
+// Scenario Types
+// ============================================================================
+
+// Scenario represents a named future with its probability
+type Scenario struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Probability float64 `json:"probability"`
+}
+
+// ScenarioOutcome represents the outcome of an option in a scenario
+type ScenarioOutcome struct {
+	Option   string  `json:"option"`
+	Scenario string  `json:"scenario"`
+	Value    float64 `json:"value"`
+}
+
+// ScenarioEvaluation represents an option's evaluation across scenarios
+type ScenarioEvaluation struct {
+	Rank           int      `json:"rank"`
+	Option         string   `json:"option"`
+	Class          string   `json:"class"`
+	ExpectedValue  float64  `json:"expected_value"`
+	WorstCase      float64  `json:"worst_case"`
+	BestCase       float64  `json:"best_case"`
+	MaxRegret      float64  `json:"max_regret"`
+	ExpectedRegret float64  `json:"expected_regret"`
+	BestIn         []string `json:"best_in,omitempty"`
+	WeakestIn      string   `json:"weakest_in,omitempty"`
+}
+
+// ScenarioData represents a scenario analysis of a set of options
+type ScenarioData struct {
+	ID             string               `json:"id"`
+	SessionID      string               `json:"session_id,omitempty"`
+	Title          string               `json:"title,omitempty"`
+	Scenarios      []Scenario           `json:"scenarios"`
+	Options        []string             `json:"options"`
+	Outcomes       []ScenarioOutcome    `json:"outcomes"`
+	Tolerance      float64              `json:"tolerance"`
+	Evaluations    []ScenarioEvaluation `json:"evaluations"`
+	RobustOptions  []string             `json:"robust_options,omitempty"`
+	Bets           []string             `json:"bets,omitempty"`
+	Recommendation string               `json:"recommendation"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
+
 // ============================================================================
 // Session Management Types
 // ============================================================================
@@ -212,10 +998,70 @@ type SessionStatistics struct {
 	LastAccessedAt    time.Time              `json:"last_accessed_at"`
 	ThoughtCount      int                    `json:"thought_count"`
 	ToolsUsed         []string               `json:"tools_used"`
+	ToolCounts        map[string]int         `json:"tool_counts"`
 	TotalOperations   int                    `json:"total_operations"`
 	IsActive          bool                   `json:"is_active"`
+	IsArchived        bool                   `json:"is_archived"`
 	RemainingThoughts int                    `json:"remaining_thoughts"`
 	Stores            map[string]interface{} `json:"stores"`
+	// StoragePressure is reported by backends that enforce memory quotas
+	StoragePressure *StoragePressure `json:"storage_pressure,omitempty"`
+}
+
+// Storage pressure levels
+const (
+	PressureLow      = "low"
+	PressureElevated = "elevated"
+	PressureHigh     = "high"
+)
+
+// StoragePressure describes how close a session and the store are to their
+// quotas, so clients know when data may be evicted or writes rejected
+type StoragePressure struct {
+	Level string `json:"level"`
+	// Utilization is the highest fraction of any configured quota in use
+	Utilization    float64 `json:"utilization"`
+	SessionRecords int     `json:"session_records"`
+	SessionBytes   int64   `json:"session_bytes"`
+	TotalRecords   int     `json:"total_records"`
+	TotalBytes     int64   `json:"total_bytes"`
+	// Evictable is true when the session would be evicted to relieve a global quota
+	Evictable bool `json:"evictable"`
+}
+
+// StorageStats describes the contents of a store for capacity planning
+type StorageStats struct {
+	Backend          string `json:"backend"`
+	Sessions         int    `json:"sessions"`
+	ArchivedSessions int    `json:"archived_sessions"`
+	// RecordCounts is the number of records in each store, keyed by record type
+	RecordCounts map[string]int `json:"record_counts"`
+	TotalRecords int            `json:"total_records"`
+	// EstimatedBytes approximates the memory held by records by their encoded size
+	EstimatedBytes int64 `json:"estimated_bytes"`
+	// SessionSizes lists sessions largest first
+	SessionSizes []SessionSize `json:"session_sizes"`
+	// Evictions is reported by backends that evict sessions themselves
+	Evictions *EvictionCounters `json:"evictions,omitempty"`
+}
+
+// SessionSize is the storage held by one session
+type SessionSize struct {
+	SessionID    string         `json:"session_id"`
+	Archived     bool           `json:"archived"`
+	RecordCounts map[string]int `json:"record_counts"`
+	TotalRecords int            `json:"total_records"`
+	Bytes        int64          `json:"bytes"`
+}
+
+// EvictionCounters counts sessions evicted since the store was opened
+type EvictionCounters struct {
+	// Expired sessions were evicted after their idle timeout and grace period
+	Expired int64 `json:"expired"`
+	// Quota sessions were evicted to bring the store within its global quotas
+	Quota int64 `json:"quota"`
+	// HookFailures counts evictions skipped because the eviction hook failed
+	HookFailures int64 `json:"hook_failures"`
 }
 
 // ============================================================================
@@ -297,3 +1143,44 @@ var MentalModels = map[string]MentalModel{
 		Category: "holistic",
 	},
 }
+
+// DebuggingApproach represents a systematic debugging approach
+type DebuggingApproach struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Steps       []string `json:"steps"`
+}
+
+// Available debugging approaches
+var DebuggingApproaches = map[string]DebuggingApproach{
+	"binary_search": {
+		Name:        "Binary Search",
+		Description: "Narrow down the source of a problem by repeatedly halving the search space",
+		Steps: []string{
+			"Find a known good state and a known bad state",
+			"Test the midpoint between them",
+			"Keep the half that still shows the problem",
+			"Repeat until the change responsible is isolated",
+		},
+	},
+	"reverse_engineering": {
+		Name:        "Reverse Engineering",
+		Description: "Work backwards from the observed behavior to the code that produces it",
+		Steps: []string{
+			"Describe the observed behavior precisely",
+			"Trace the output back to where it is produced",
+			"Reconstruct the inputs and state that lead there",
+			"Compare the reconstruction with the intended behavior",
+		},
+	},
+	"root_cause_analysis": {
+		Name:        "Root Cause Analysis",
+		Description: "Look past the symptoms to the underlying cause of a problem",
+		Steps: []string{
+			"Describe the symptoms and when they occur",
+			"Ask why each symptom happens until a root cause emerges",
+			"Verify the cause explains every symptom",
+			"Address the cause rather than the symptoms",
+		},
+	},
+}
@@ -0,0 +1,255 @@
+// Package voting aggregates the evaluations of a decision's options by a
+// group of evaluators. Each evaluator scores every option, higher being
+// better, and so ranks the options, tied scores sharing their average rank.
+// The ballots are aggregated by Borda count, each ballot giving an option a
+// point for every option it beats and half a point for every tie; by
+// Condorcet's pairwise majorities, whose winner beats every other option and
+// which rank the options by their Copeland score, majorities won less
+// majorities lost; and by range voting, the mean score. How much the
+// evaluators disagree is one less Kendall's coefficient of concordance W of
+// their rankings, corrected for ties, and every pair of evaluators is
+// compared by the Spearman correlation of their rankings.
+package voting
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Aggregation methods
+const (
+	Borda     = "borda"
+	Condorcet = "condorcet"
+	Range     = "range"
+)
+
+// Ballot is an evaluator's scores of the options, in their order
+type Ballot struct {
+	Evaluator string
+	Scores    []float64
+}
+
+// Option is an option's standing under every method and how the evaluators
+// differ over it
+type Option struct {
+	Name string
+	// Borda is the option's Borda count over all ballots
+	Borda float64
+	// Copeland is the number of options a majority prefers the option to,
+	// less the number preferred to it by a majority
+	Copeland int
+	// Range is the option's mean score
+	Range float64
+	// MeanRank is the option's mean rank, 1 being best, and RankSpread and
+	// ScoreSpread the standard deviations of its ranks and scores
+	MeanRank    float64
+	RankSpread  float64
+	ScoreSpread float64
+}
+
+// Conflict compares the rankings of two evaluators: a correlation of 1 is
+// full agreement, -1 opposite rankings
+type Conflict struct {
+	A, B        string
+	Correlation float64
+}
+
+// Result is the aggregation of the ballots
+type Result struct {
+	// Options holds the options in their given order
+	Options []Option
+	// Pairwise counts, for each option, the ballots preferring it to each
+	// other option
+	Pairwise [][]int
+	// CondorcetWinner is the option preferred to every other by a majority,
+	// empty when there is none
+	CondorcetWinner string
+	// Disagreement is one less Kendall's W, 0 when the evaluators rank the
+	// options alike and 1 when their rankings cancel out
+	Disagreement float64
+	// Conflicts holds every pair of evaluators, least correlated first
+	Conflicts []Conflict
+}
+
+// Standing is an option's place under a method
+type Standing struct {
+	// Rank is shared by tied options
+	Rank   int
+	Option string
+	Score  float64
+}
+
+// Aggregate aggregates ballots on options
+func Aggregate(options []string, ballots []Ballot) (*Result, error) {
+	n, m := len(options), len(ballots)
+	switch {
+	case n < 2:
+		return nil, errors.New("there must be at least two options")
+	case m == 0:
+		return nil, errors.New("there are no evaluations")
+	}
+	names := make(map[string]bool, n)
+	for _, option := range options {
+		if names[option] {
+			return nil, fmt.Errorf("option %s is listed twice", option)
+		}
+		names[option] = true
+	}
+	evaluators := make(map[string]bool, m)
+	for k, b := range ballots {
+		switch {
+		case b.Evaluator == "":
+			return nil, fmt.Errorf("evaluation %d has no evaluator", k+1)
+		case evaluators[b.Evaluator]:
+			return nil, fmt.Errorf("evaluator %s is listed twice", b.Evaluator)
+		case len(b.Scores) != n:
+			return nil, fmt.Errorf("evaluator %s scores %d options, not %d", b.Evaluator, len(b.Scores), n)
+		}
+		for i, score := range b.Scores {
+			if math.IsNaN(score) || math.IsInf(score, 0) {
+				return nil, fmt.Errorf("evaluator %s gives %s a non-finite score", b.Evaluator, options[i])
+			}
+		}
+		evaluators[b.Evaluator] = true
+	}
+
+	result := &Result{Options: make([]Option, n), Pairwise: make([][]int, n)}
+	for i := range options {
+		result.Options[i].Name = options[i]
+		result.Pairwise[i] = make([]int, n)
+	}
+	ranks := make([][]float64, m)
+	ties := 0.0
+	for k, b := range ballots {
+		var t float64
+		ranks[k], t = rank(b.Scores)
+		ties += t
+		for i := range options {
+			o := &result.Options[i]
+			o.Borda += float64(n) - ranks[k][i]
+			o.Range += b.Scores[i] / float64(m)
+			o.MeanRank += ranks[k][i] / float64(m)
+			for j := range options {
+				if b.Scores[i] > b.Scores[j] {
+					result.Pairwise[i][j]++
+				}
+			}
+		}
+	}
+
+	for i := range options {
+		o := &result.Options[i]
+		for k, b := range ballots {
+			o.RankSpread += (ranks[k][i] - o.MeanRank) * (ranks[k][i] - o.MeanRank) / float64(m)
+			o.ScoreSpread += (b.Scores[i] - o.Range) * (b.Scores[i] - o.Range) / float64(m)
+		}
+		o.RankSpread, o.ScoreSpread = math.Sqrt(o.RankSpread), math.Sqrt(o.ScoreSpread)
+		wins := 0
+		for j := range options {
+			switch {
+			case result.Pairwise[i][j] > result.Pairwise[j][i]:
+				o.Copeland++
+				wins++
+			case result.Pairwise[i][j] < result.Pairwise[j][i]:
+				o.Copeland--
+			}
+		}
+		if wins == n-1 {
+			result.CondorcetWinner = o.Name
+		}
+	}
+
+	// Kendall's W: the spread of the options' rank sums against its most
+	// when every evaluator ranks alike
+	s := 0.0
+	for i := range options {
+		sum := result.Options[i].MeanRank * float64(m)
+		s += (sum - float64(m*(n+1))/2) * (sum - float64(m*(n+1))/2)
+	}
+	most := float64(m*m)*(float64(n*n*n)-float64(n)) - float64(m)*ties
+	if most > 0 {
+		result.Disagreement = math.Max(0, 1-12*s/most)
+	}
+
+	for a := 0; a < m; a++ {
+		for b := a + 1; b < m; b++ {
+			result.Conflicts = append(result.Conflicts, Conflict{A: ballots[a].Evaluator, B: ballots[b].Evaluator, Correlation: correlation(ranks[a], ranks[b])})
+		}
+	}
+	sort.SliceStable(result.Conflicts, func(a, b int) bool { return result.Conflicts[a].Correlation < result.Conflicts[b].Correlation })
+	return result, nil
+}
+
+// Ranking returns the options best first by method
+func (r *Result) Ranking(method string) ([]Standing, error) {
+	standings := make([]Standing, len(r.Options))
+	for i, o := range r.Options {
+		standings[i].Option = o.Name
+		switch method {
+		case Borda:
+			standings[i].Score = o.Borda
+		case Condorcet:
+			standings[i].Score = float64(o.Copeland)
+		case Range:
+			standings[i].Score = o.Range
+		default:
+			return nil, fmt.Errorf("unknown aggregation method %q", method)
+		}
+	}
+	sort.SliceStable(standings, func(a, b int) bool { return standings[a].Score > standings[b].Score })
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && math.Abs(standings[i].Score-standings[i-1].Score) < 1e-12 {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings, nil
+}
+
+// rank returns the ranks scores give their options, 1 for the highest and
+// tied scores sharing their average rank, and the tie correction of Kendall's
+// W, the sum of t³ - t over groups of t tied scores
+func rank(scores []float64) ([]float64, float64) {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	ranks := make([]float64, len(scores))
+	ties := 0.0
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && scores[order[end]] == scores[order[start]] {
+			end++
+		}
+		for _, i := range order[start:end] {
+			ranks[i] = float64(start+end+1) / 2
+		}
+		t := float64(end - start)
+		ties += t*t*t - t
+		start = end
+	}
+	return ranks, ties
+}
+
+// correlation returns the Pearson correlation of x and y, 0 when either is
+// constant
+func correlation(x, y []float64) float64 {
+	mx, my := 0.0, 0.0
+	for i := range x {
+		mx += x[i] / float64(len(x))
+		my += y[i] / float64(len(y))
+	}
+	sxy, sxx, syy := 0.0, 0.0, 0.0
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
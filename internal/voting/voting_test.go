@@ -0,0 +1,117 @@
+package voting
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate_CountsBordaCondorcetAndRange(t *testing.T) {
+	result, err := Aggregate([]string{"a", "b", "c"}, []Ballot{
+		{Evaluator: "ann", Scores: []float64{3, 2, 1}},
+		{Evaluator: "bob", Scores: []float64{3, 2, 1}},
+		{Evaluator: "cyd", Scores: []float64{1, 2, 3}},
+	})
+	require.NoError(t, err)
+
+	a, b, c := result.Options[0], result.Options[1], result.Options[2]
+	assert.Equal(t, 4.0, a.Borda)
+	assert.Equal(t, 3.0, b.Borda)
+	assert.Equal(t, 2.0, c.Borda)
+	assert.Equal(t, 2, a.Copeland)
+	assert.Equal(t, 0, b.Copeland)
+	assert.Equal(t, -2, c.Copeland)
+	assert.InDelta(t, 7.0/3, a.Range, 1e-12)
+	assert.InDelta(t, 5.0/3, a.MeanRank, 1e-12)
+	assert.Equal(t, 0.0, b.RankSpread)
+	assert.InDelta(t, math.Sqrt(8.0/9), a.RankSpread, 1e-12)
+	assert.Equal(t, "a", result.CondorcetWinner)
+	assert.Equal(t, [][]int{{0, 2, 2}, {1, 0, 2}, {1, 1, 0}}, result.Pairwise)
+
+	// Rank sums of 5, 6 and 7 make W 12·2 / (9·24)
+	assert.InDelta(t, 1-1.0/9, result.Disagreement, 1e-12)
+	require.Len(t, result.Conflicts, 3)
+	assert.Equal(t, Conflict{A: "ann", B: "cyd", Correlation: -1}, result.Conflicts[0])
+	assert.Equal(t, Conflict{A: "ann", B: "bob", Correlation: 1}, result.Conflicts[2])
+}
+
+func TestAggregate_MethodsCanDisagree(t *testing.T) {
+	// Three evaluators rank a > b > c and two b > c > a: b gathers the most
+	// Borda points, but a majority prefers a to either
+	ballots := []Ballot{
+		{Evaluator: "1", Scores: []float64{9, 5, 1}},
+		{Evaluator: "2", Scores: []float64{9, 5, 1}},
+		{Evaluator: "3", Scores: []float64{9, 5, 1}},
+		{Evaluator: "4", Scores: []float64{0, 10, 8}},
+		{Evaluator: "5", Scores: []float64{0, 10, 8}},
+	}
+	result, err := Aggregate([]string{"a", "b", "c"}, ballots)
+	require.NoError(t, err)
+
+	borda, err := result.Ranking(Borda)
+	require.NoError(t, err)
+	assert.Equal(t, Standing{Rank: 1, Option: "b", Score: 7}, borda[0])
+	condorcet, err := result.Ranking(Condorcet)
+	require.NoError(t, err)
+	assert.Equal(t, Standing{Rank: 1, Option: "a", Score: 2}, condorcet[0])
+	assert.Equal(t, "a", result.CondorcetWinner)
+	byRange, err := result.Ranking(Range)
+	require.NoError(t, err)
+	assert.Equal(t, "b", byRange[0].Option)
+	assert.InDelta(t, 7, byRange[0].Score, 1e-12)
+
+	_, err = result.Ranking("plurality")
+	assert.Error(t, err)
+}
+
+func TestAggregate_SharesTiesAndFindsCycles(t *testing.T) {
+	// A cycle: no option beats both others, and the rankings cancel out
+	result, err := Aggregate([]string{"a", "b", "c"}, []Ballot{
+		{Evaluator: "ann", Scores: []float64{3, 2, 1}},
+		{Evaluator: "bob", Scores: []float64{1, 3, 2}},
+		{Evaluator: "cyd", Scores: []float64{2, 1, 3}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.CondorcetWinner)
+	assert.InDelta(t, 1, result.Disagreement, 1e-12)
+	standings, err := result.Ranking(Borda)
+	require.NoError(t, err)
+	for _, s := range standings {
+		assert.Equal(t, 1, s.Rank)
+		assert.Equal(t, 3.0, s.Score)
+	}
+
+	// Tied scores share their average rank; indifferent evaluators agree
+	result, err = Aggregate([]string{"a", "b", "c"}, []Ballot{
+		{Evaluator: "ann", Scores: []float64{5, 5, 1}},
+		{Evaluator: "bob", Scores: []float64{2, 2, 2}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.5+1, result.Options[0].Borda)
+	assert.Equal(t, 0.0, result.Conflicts[0].Correlation)
+	result, err = Aggregate([]string{"a", "b"}, []Ballot{{Evaluator: "ann", Scores: []float64{1, 1}}})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, result.Disagreement)
+	assert.Empty(t, result.Conflicts)
+}
+
+func TestAggregate_RejectsInvalidBallots(t *testing.T) {
+	options := []string{"a", "b"}
+	for name, c := range map[string]struct {
+		options []string
+		ballots []Ballot
+	}{
+		"one option":       {[]string{"a"}, []Ballot{{Evaluator: "e", Scores: []float64{1}}}},
+		"duplicate option": {[]string{"a", "a"}, []Ballot{{Evaluator: "e", Scores: []float64{1, 2}}}},
+		"no ballots":       {options, nil},
+		"no evaluator":     {options, []Ballot{{Scores: []float64{1, 2}}}},
+		"twice":            {options, []Ballot{{Evaluator: "e", Scores: []float64{1, 2}}, {Evaluator: "e", Scores: []float64{2, 1}}}},
+		"short":            {options, []Ballot{{Evaluator: "e", Scores: []float64{1}}}},
+		"NaN":              {options, []Ballot{{Evaluator: "e", Scores: []float64{1, math.NaN()}}}},
+	} {
+		_, err := Aggregate(c.options, c.ballots)
+		assert.Error(t, err, name)
+	}
+}
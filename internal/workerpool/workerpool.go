@@ -0,0 +1,163 @@
+// Package workerpool bounds the number of expensive calls running at once.
+// Calls beyond the pool's workers wait in a bounded queue, calls beyond the
+// queue are rejected, and every call is given a timeout so a burst of heavy
+// work cannot starve the rest of the process.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/rainmana/gothink/internal/telemetry"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	// ErrQueueFull is returned when every worker is busy and the queue is full
+	ErrQueueFull = errors.New("worker pool queue is full")
+	// ErrTimeout is returned when a call does not finish within the timeout
+	ErrTimeout = errors.New("call timed out")
+)
+
+// Pool runs calls on a bounded number of workers
+type Pool struct {
+	// admitted holds a slot for every running or queued call, workers one
+	// for every running call
+	admitted chan struct{}
+	workers  chan struct{}
+	timeout  time.Duration
+
+	queued atomic.Int64
+	active atomic.Int64
+
+	rejected metric.Int64Counter
+	timeouts metric.Int64Counter
+}
+
+// New returns a pool running up to workers calls at once, with up to
+// queueSize more waiting for a worker. A positive timeout bounds each call,
+// including its time in the queue. New returns nil, a pool that runs every
+// call directly, when workers is not positive.
+func New(workers, queueSize int, timeout time.Duration) *Pool {
+	if workers <= 0 {
+		return nil
+	}
+
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	p := &Pool{
+		admitted: make(chan struct{}, workers+queueSize),
+		workers:  make(chan struct{}, workers),
+		timeout:  timeout,
+	}
+	p.instrument()
+	return p
+}
+
+// instrument reports the queue depth and the busy workers as gauges, and
+// counts rejected and timed out calls
+func (p *Pool) instrument() {
+	meter := telemetry.Meter()
+
+	queueDepth, _ := meter.Int64ObservableGauge("gothink.workerpool.queue_depth",
+		metric.WithDescription("Calls waiting for a worker"))
+	active, _ := meter.Int64ObservableGauge("gothink.workerpool.active",
+		metric.WithDescription("Calls running on a worker"))
+	meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(queueDepth, p.queued.Load())
+		o.ObserveInt64(active, p.active.Load())
+		return nil
+	}, queueDepth, active)
+
+	p.rejected, _ = meter.Int64Counter("gothink.workerpool.rejected",
+		metric.WithDescription("Calls rejected because the queue was full"))
+	p.timeouts, _ = meter.Int64Counter("gothink.workerpool.timeouts",
+		metric.WithDescription("Calls that did not finish within the timeout"))
+}
+
+// QueueDepth returns the number of calls waiting for a worker
+func (p *Pool) QueueDepth() int {
+	if p == nil {
+		return 0
+	}
+	return int(p.queued.Load())
+}
+
+// Active returns the number of calls running on a worker
+func (p *Pool) Active() int {
+	if p == nil {
+		return 0
+	}
+	return int(p.active.Load())
+}
+
+// Run calls fn on a worker and waits for it to return. It returns
+// ErrQueueFull without calling fn when the queue is full, ErrTimeout when the
+// call does not finish within the pool's timeout, and the context's error
+// when ctx is done first. fn is given a context carrying the timeout; a call
+// that outlives it keeps its worker until it returns.
+func (p *Pool) Run(ctx context.Context, fn func(context.Context)) error {
+	if p == nil {
+		fn(ctx)
+		return nil
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, p.timeout, ErrTimeout)
+		defer cancel()
+	}
+	if ctx.Err() != nil {
+		return p.contextError(ctx)
+	}
+
+	select {
+	case p.admitted <- struct{}{}:
+	default:
+		p.rejected.Add(ctx, 1)
+		return ErrQueueFull
+	}
+
+	// Wait in the queue for a worker
+	p.queued.Add(1)
+	select {
+	case p.workers <- struct{}{}:
+		p.queued.Add(-1)
+	case <-ctx.Done():
+		p.queued.Add(-1)
+		<-p.admitted
+		return p.contextError(ctx)
+	}
+
+	p.active.Add(1)
+	done := make(chan struct{})
+	go func() {
+		defer func() {
+			p.active.Add(-1)
+			<-p.workers
+			<-p.admitted
+			close(done)
+		}()
+		fn(ctx)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return p.contextError(ctx)
+	}
+}
+
+// contextError returns ErrTimeout when the pool's timeout expired, and the
+// error of ctx otherwise
+func (p *Pool) contextError(ctx context.Context) error {
+	if context.Cause(ctx) == ErrTimeout {
+		p.timeouts.Add(context.Background(), 1)
+		return ErrTimeout
+	}
+	return ctx.Err()
+}
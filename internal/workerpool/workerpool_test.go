@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_QueuesRejectsAndTimesOut(t *testing.T) {
+	pool := New(1, 1, time.Second)
+	release := make(chan struct{})
+	running := make(chan struct{})
+
+	// The first call takes the only worker
+	first := make(chan error)
+	go func() {
+		first <- pool.Run(context.Background(), func(context.Context) {
+			close(running)
+			<-release
+		})
+	}()
+	<-running
+
+	// The second waits in the queue
+	second := make(chan error)
+	go func() {
+		second <- pool.Run(context.Background(), func(context.Context) {})
+	}()
+	require.Eventually(t, func() bool { return pool.QueueDepth() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, pool.Active())
+
+	// The third finds the queue full
+	called := false
+	assert.ErrorIs(t, pool.Run(context.Background(), func(context.Context) { called = true }), ErrQueueFull)
+	assert.False(t, called)
+
+	close(release)
+	assert.NoError(t, <-first)
+	assert.NoError(t, <-second)
+	assert.Equal(t, 0, pool.QueueDepth())
+
+	// A call outliving the timeout fails, and one cancelled by its caller
+	// reports the cancellation
+	pool = New(1, 0, 20*time.Millisecond)
+	assert.ErrorIs(t, pool.Run(context.Background(), func(ctx context.Context) { <-ctx.Done() }), ErrTimeout)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, pool.Run(ctx, func(ctx context.Context) { <-ctx.Done() }), context.Canceled)
+
+	// Without workers calls run directly
+	called = false
+	assert.NoError(t, New(0, 0, 0).Run(context.Background(), func(context.Context) { called = true }))
+	assert.True(t, called)
+}
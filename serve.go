@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/audit"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/grpcserver"
+	"github.com/rainmana/gothink/internal/httpserver"
+	"github.com/rainmana/gothink/internal/intelligence"
+	"github.com/rainmana/gothink/internal/mcpserver"
+	"github.com/rainmana/gothink/internal/models"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/rainmana/gothink/internal/telemetry"
+	"github.com/sirupsen/logrus"
+)
+
+// shutdownTimeout bounds how long in-flight HTTP requests and gRPC calls may
+// take to finish once the servers are stopping
+const shutdownTimeout = 10 * time.Second
+
+// runServe runs the MCP server over stdio (mcp, the default), the HTTP API
+// (http), or both over one storage instance, along with the gRPC API when its
+// port is configured, until stdin closes or the process is interrupted
+func runServe(args []string) error {
+	mode := "mcp"
+	if len(args) > 0 {
+		mode = args[0]
+	}
+	if len(args) > 1 || (mode != "mcp" && mode != "http" && mode != "both") {
+		return fmt.Errorf("usage: gothink serve [mcp|http|both]")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Export traces when an OTLP endpoint is configured
+	shutdownTracing, err := telemetry.Setup(ctx, "gothink")
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}()
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+		}
+	}()
+
+	// Logs go to stderr: stdout carries the MCP protocol
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+
+	auditLog, err := audit.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := auditLog.Close(); err != nil {
+			log.Printf("Failed to close audit log: %v", err)
+		}
+	}()
+
+	// The MCP tools and the HTTP readiness check share the intelligence data
+	intelligenceService := intelligence.NewIntelligenceService("") // No API key for now
+
+	// The MCP server is served over stdio, runs the HTTP API's batches and,
+	// when enabled, is served over WebSocket by the HTTP API; tool calls are
+	// audited either way
+	mcpServer := mcpserver.New(cfg, store, models.NewLoader(logger), intelligenceService, mcpserver.WithAuditLog(auditLog))
+
+	var servers []func(context.Context) error
+	if mode != "http" {
+		servers = append(servers, func(ctx context.Context) error {
+			return serveMCP(ctx, mcpServer)
+		})
+	}
+	if mode != "mcp" {
+		servers = append(servers, func(ctx context.Context) error {
+			return serveHTTP(ctx, cfg, store, logger, intelligenceService, mcpServer)
+		})
+	}
+	if cfg.GRPCPort != "" {
+		servers = append(servers, func(ctx context.Context) error {
+			return serveGRPC(ctx, cfg, store, logger)
+		})
+	}
+	return runServers(ctx, servers...)
+}
+
+// runServers runs the servers until ctx ends or one of them stops, since
+// there is nothing left to serve alongside a stopped server, and returns the
+// first error
+func runServers(ctx context.Context, servers ...func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(servers))
+	for _, serve := range servers {
+		go func() {
+			errs <- serve(ctx)
+			cancel()
+		}()
+	}
+
+	var first error
+	for range servers {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// serveMCP serves s over stdio until stdin closes or ctx ends
+func serveMCP(ctx context.Context, s *server.MCPServer) error {
+	if err := mcpserver.ServeStdio(ctx, s, os.Stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("MCP server error: %w", err)
+	}
+	return nil
+}
+
+// serveHTTP serves the HTTP API on the configured host and port until ctx
+// ends, then lets in-flight requests finish. The readiness check covers the
+// freshness of intelligenceService's data, and mcpServer, when set, runs
+// batches and is served to WebSocket clients when they are enabled.
+func serveHTTP(ctx context.Context, cfg *config.Config, store storage.Store, logger *logrus.Logger, intelligenceService *intelligence.IntelligenceService, mcpServer *server.MCPServer) error {
+	opts := []httpserver.Option{httpserver.WithIntelligence(intelligenceService)}
+	if mcpServer != nil {
+		opts = append(opts, httpserver.WithMCP(ctx, mcpServer))
+	}
+	srv := &http.Server{
+		Addr:         net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:      httpserver.NewRouter(cfg, store, logger, opts...),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Infof("HTTP API listening on %s", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("HTTP server error: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop HTTP server: %w", err)
+	}
+	return nil
+}
+
+// serveGRPC serves the gRPC API on the configured host and gRPC port until
+// ctx ends, then lets in-flight calls finish
+func serveGRPC(ctx context.Context, cfg *config.Config, store storage.Store, logger *logrus.Logger) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.GRPCPort))
+	if err != nil {
+		return fmt.Errorf("gRPC server error: %w", err)
+	}
+	srv := grpcserver.New(cfg, store, logger)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Infof("gRPC API listening on %s", listener.Addr())
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("gRPC server error: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Streams such as WatchEvents run until the client leaves, so they are
+	// cut off once the timeout passes
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		srv.Stop()
+	}
+	return nil
+}
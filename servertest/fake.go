@@ -0,0 +1,72 @@
+package servertest
+
+import (
+	"context"
+
+	"github.com/rainmana/gothink/internal/models"
+)
+
+// FakeIntelligence serves fixed intelligence data in place of the NVD, MITRE and OWASP downloaders
+type FakeIntelligence struct {
+	CVEs       []models.CVE
+	Techniques []models.AttackTechnique
+	Procedures []models.OWASPProcedure
+
+	// Err, when set, is returned by every download
+	Err error
+}
+
+// NewFakeIntelligence returns a fake populated with a small, stable data set
+func NewFakeIntelligence() *FakeIntelligence {
+	return &FakeIntelligence{
+		CVEs: []models.CVE{
+			{
+				ID:          "CVE-2021-44228",
+				Description: "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP endpoints",
+				Severity:    "CRITICAL",
+				CVSSScore:   10.0,
+				Published:   Epoch,
+				Modified:    Epoch,
+				Products:    []string{"log4j"},
+				Vendors:     []string{"apache"},
+			},
+		},
+		Techniques: []models.AttackTechnique{
+			{
+				ID:          "T1059",
+				Name:        "Command and Scripting Interpreter",
+				Description: "Adversaries may abuse command and script interpreters to execute commands",
+				Tactics:     []string{"execution"},
+				Platforms:   []string{"Linux", "Windows", "macOS"},
+				Created:     Epoch,
+				Modified:    Epoch,
+			},
+		},
+		Procedures: []models.OWASPProcedure{
+			{
+				ID:          "WSTG-INPV-05",
+				Category:    "Input Validation",
+				Title:       "Testing for SQL Injection",
+				Description: "Test whether user input can alter SQL queries",
+				Tools:       []string{"sqlmap"},
+				Created:     Epoch,
+				Modified:    Epoch,
+			},
+		},
+	}
+}
+
+// DownloadAllCVEs returns the fake CVEs
+func (f *FakeIntelligence) DownloadAllCVEs(ctx context.Context) ([]models.CVE, error) {
+	return f.CVEs, f.Err
+}
+
+// DownloadTechniques returns the fake ATT&CK techniques
+func (f *FakeIntelligence) DownloadTechniques(ctx context.Context) ([]models.AttackTechnique, error) {
+	return f.Techniques, f.Err
+}
+
+// DownloadProcedures returns the fake OWASP procedures
+func (f *FakeIntelligence) DownloadProcedures(ctx context.Context) ([]models.OWASPProcedure, error) {
+	return f.Procedures, f.Err
+}
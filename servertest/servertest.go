@@ -0,0 +1,361 @@
+// Package servertest runs the full GoThink MCP server in-process for end-to-end tests.
+//
+// A test server uses deterministic in-memory storage and fake intelligence
+// sources, and exposes helpers to invoke tools through a real MCP client and
+// assert on the stored state:
+//
+//	srv := servertest.New(t)
+//	result := srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+//		"session_id":          "s1",
+//		"thought":             "Define the problem",
+//		"thought_number":      1,
+//		"total_thoughts":      3,
+//		"next_thought_needed": true,
+//	})
+//	srv.AssertRecordCount("s1", "thoughts", 1)
+package servertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rainmana/gothink/internal/audit"
+	"github.com/rainmana/gothink/internal/config"
+	"github.com/rainmana/gothink/internal/intelligence"
+	"github.com/rainmana/gothink/internal/mcpserver"
+	"github.com/rainmana/gothink/internal/models"
+	"github.com/rainmana/gothink/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Epoch is the fixed time at which the deterministic test clock starts
+var Epoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Server is an in-process GoThink MCP server connected to an MCP client
+type Server struct {
+	t testing.TB
+
+	Config *config.Config
+	Store  *storage.MemoryStore
+	// Events publishes every write made through the server
+	Events *storage.EventBus
+	MCP    *server.MCPServer
+	Client *client.Client
+
+	Intelligence *FakeIntelligence
+
+	auditLog io.Writer
+}
+
+// Option customizes a test server before it starts
+type Option func(*Server)
+
+// WithConfig lets a test adjust the server configuration
+func WithConfig(configure func(cfg *config.Config)) Option {
+	return func(s *Server) {
+		configure(s.Config)
+	}
+}
+
+// WithIntelligence replaces the default fake intelligence data
+func WithIntelligence(fake *FakeIntelligence) Option {
+	return func(s *Server) {
+		s.Intelligence = fake
+	}
+}
+
+// WithAuditLog records the server's tool calls in w, redacting the arguments
+// the configuration asks to redact
+func WithAuditLog(w io.Writer) Option {
+	return func(s *Server) {
+		s.auditLog = w
+	}
+}
+
+// New starts a test server and initializes its client. The server is closed
+// automatically when the test finishes.
+func New(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:            t,
+		Config:       config.DefaultConfig(),
+		Intelligence: NewFakeIntelligence(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Deterministic storage: sequential IDs and a clock that ticks one second per call
+	s.Store = storage.NewMemoryStore(s.Config)
+	var idCounter, clockCounter int64
+	s.Store.SetIDGenerator(func() string {
+		return fmt.Sprintf("test-%d", atomic.AddInt64(&idCounter, 1))
+	})
+	s.Store.SetClock(func() time.Time {
+		return Epoch.Add(time.Duration(atomic.AddInt64(&clockCounter, 1)) * time.Second)
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	intelligenceService := intelligence.NewIntelligenceServiceWithSources(s.Intelligence, s.Intelligence, s.Intelligence)
+	s.Events = storage.NewEventBus()
+	t.Cleanup(s.Events.Close)
+	var serverOpts []mcpserver.Option
+	if s.auditLog != nil {
+		serverOpts = append(serverOpts, mcpserver.WithAuditLog(audit.New(s.auditLog, audit.RedactedArguments(s.Config))))
+	}
+	s.MCP = mcpserver.New(s.Config, storage.NewEventStore(s.Store, s.Events), models.NewLoader(logger), intelligenceService, serverOpts...)
+
+	mcpClient, err := client.NewInProcessClient(s.MCP)
+	if err != nil {
+		t.Fatalf("servertest: failed to create client: %v", err)
+	}
+	s.Client = mcpClient
+	t.Cleanup(func() {
+		s.Client.Close()
+	})
+
+	ctx := context.Background()
+	if err := s.Client.Start(ctx); err != nil {
+		t.Fatalf("servertest: failed to start client: %v", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "servertest", Version: "1.0.0"}
+	if _, err := s.Client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("servertest: failed to initialize client: %v", err)
+	}
+
+	return s
+}
+
+// CallTool invokes a tool and returns the raw result. Protocol-level errors fail the test.
+func (s *Server) CallTool(name string, args map[string]interface{}) *mcp.CallToolResult {
+	s.t.Helper()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+
+	result, err := s.Client.CallTool(context.Background(), request)
+	if err != nil {
+		s.t.Fatalf("servertest: calling tool %s failed: %v", name, err)
+	}
+
+	return result
+}
+
+// CallToolJSON invokes a tool that is expected to succeed and decodes its JSON text result
+func (s *Server) CallToolJSON(name string, args map[string]interface{}) map[string]interface{} {
+	s.t.Helper()
+
+	result := s.CallTool(name, args)
+	text := ResultText(result)
+	if result.IsError {
+		s.t.Fatalf("servertest: tool %s returned an error: %s", name, text)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		s.t.Fatalf("servertest: tool %s returned invalid JSON: %v\n%s", name, err, text)
+	}
+
+	return decoded
+}
+
+// CallToolError invokes a tool that is expected to fail and returns its error text
+func (s *Server) CallToolError(name string, args map[string]interface{}) string {
+	s.t.Helper()
+
+	result := s.CallTool(name, args)
+	text := ResultText(result)
+	if !result.IsError {
+		s.t.Fatalf("servertest: expected tool %s to fail, got: %s", name, text)
+	}
+
+	return text
+}
+
+// CallToolErrorCode invokes a tool that is expected to fail and returns the
+// machine-readable code of its error
+func (s *Server) CallToolErrorCode(name string, args map[string]interface{}) string {
+	s.t.Helper()
+
+	text := s.CallToolError(name, args)
+	var decoded struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil || decoded.Error.Code == "" {
+		s.t.Fatalf("servertest: tool %s returned an error without a code: %s", name, text)
+	}
+
+	return decoded.Error.Code
+}
+
+// ReadResource reads a resource that is expected to exist and returns its text
+func (s *Server) ReadResource(uri string) string {
+	s.t.Helper()
+
+	text, err := s.readResource(uri)
+	if err != nil {
+		s.t.Fatalf("servertest: reading resource %s failed: %v", uri, err)
+	}
+
+	return text
+}
+
+// ReadResourceJSON reads a resource that is expected to exist and decodes its JSON text
+func (s *Server) ReadResourceJSON(uri string) map[string]interface{} {
+	s.t.Helper()
+
+	text := s.ReadResource(uri)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		s.t.Fatalf("servertest: resource %s is invalid JSON: %v\n%s", uri, err, text)
+	}
+
+	return decoded
+}
+
+// ReadResourceError reads a resource that is expected to fail and returns the error
+func (s *Server) ReadResourceError(uri string) error {
+	s.t.Helper()
+
+	text, err := s.readResource(uri)
+	if err == nil {
+		s.t.Fatalf("servertest: expected resource %s to fail, got: %s", uri, text)
+	}
+
+	return err
+}
+
+// readResource reads a resource and concatenates its text contents
+func (s *Server) readResource(uri string) (string, error) {
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+
+	result, err := s.Client.ReadResource(context.Background(), request)
+	if err != nil {
+		return "", err
+	}
+
+	var text string
+	for _, content := range result.Contents {
+		if textContent, ok := content.(mcp.TextResourceContents); ok {
+			text += textContent.Text
+		}
+	}
+	return text, nil
+}
+
+// GetPrompt renders a prompt that is expected to exist and returns the text of its messages
+func (s *Server) GetPrompt(name string, args map[string]string) string {
+	s.t.Helper()
+
+	text, err := s.getPrompt(name, args)
+	if err != nil {
+		s.t.Fatalf("servertest: getting prompt %s failed: %v", name, err)
+	}
+
+	return text
+}
+
+// GetPromptError renders a prompt that is expected to fail and returns the error
+func (s *Server) GetPromptError(name string, args map[string]string) error {
+	s.t.Helper()
+
+	text, err := s.getPrompt(name, args)
+	if err == nil {
+		s.t.Fatalf("servertest: expected prompt %s to fail, got: %s", name, text)
+	}
+
+	return err
+}
+
+// getPrompt renders a prompt and concatenates the text of its messages
+func (s *Server) getPrompt(name string, args map[string]string) (string, error) {
+	request := mcp.GetPromptRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+
+	result, err := s.Client.GetPrompt(context.Background(), request)
+	if err != nil {
+		return "", err
+	}
+
+	var text string
+	for _, message := range result.Messages {
+		if textContent, ok := message.Content.(mcp.TextContent); ok {
+			text += textContent.Text
+		}
+	}
+	return text, nil
+}
+
+// ToolNames lists the names of all tools registered on the server
+func (s *Server) ToolNames() []string {
+	s.t.Helper()
+
+	result, err := s.Client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		s.t.Fatalf("servertest: failed to list tools: %v", err)
+	}
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+
+	return names
+}
+
+// RecordCount returns the number of records in the named store (e.g. "thoughts",
+// "decisions") as reported by the session statistics
+func (s *Server) RecordCount(sessionID, store string) int {
+	s.t.Helper()
+
+	stats, err := s.Store.GetSessionStats(sessionID)
+	if err != nil {
+		s.t.Fatalf("servertest: failed to get session stats: %v", err)
+	}
+
+	counts, ok := stats.Stores[store].(map[string]int)
+	if !ok {
+		s.t.Fatalf("servertest: unknown store %q", store)
+	}
+
+	return counts["count"]
+}
+
+// AssertRecordCount fails the test if the named store does not hold the expected number of records
+func (s *Server) AssertRecordCount(sessionID, store string, expected int) {
+	s.t.Helper()
+
+	if actual := s.RecordCount(sessionID, store); actual != expected {
+		s.t.Errorf("servertest: expected %d %s in session %s, got %d", expected, store, sessionID, actual)
+	}
+}
+
+// ResultText concatenates the text content of a tool result
+func ResultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if textContent, ok := mcp.AsTextContent(content); ok {
+			text += textContent.Text
+		}
+	}
+	return text
+}
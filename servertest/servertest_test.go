@@ -0,0 +1,64 @@
+package servertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_RegistersTools(t *testing.T) {
+	srv := New(t)
+
+	names := srv.ToolNames()
+	assert.Contains(t, names, "sequential_thinking")
+	assert.Contains(t, names, "session_export")
+	assert.Contains(t, names, "query_nvd")
+	assert.NotContains(t, names, "critique_reasoning")
+}
+
+func TestCallToolJSON_StoresThoughtDeterministically(t *testing.T) {
+	srv := New(t)
+
+	result := srv.CallToolJSON("sequential_thinking", map[string]interface{}{
+		"session_id":          "s1",
+		"thought":             "Define the problem",
+		"thought_number":      1,
+		"total_thoughts":      3,
+		"next_thought_needed": true,
+	})
+
+	assert.Equal(t, "success", result["status"])
+	assert.Equal(t, "test-1", result["thought_id"])
+	srv.AssertRecordCount("s1", "thoughts", 1)
+
+	thoughts, err := srv.Store.GetThoughts("s1", nil)
+	require.NoError(t, err)
+	require.Len(t, thoughts, 1)
+	assert.WithinDuration(t, Epoch, thoughts[0].CreatedAt, time.Minute)
+}
+
+func TestCallToolError_UnknownMentalModel(t *testing.T) {
+	srv := New(t)
+
+	text := srv.CallToolError("mental_model", map[string]interface{}{
+		"session_id": "s1",
+		"model_name": "does_not_exist",
+		"problem":    "Anything",
+	})
+
+	assert.Contains(t, text, "not found")
+	srv.AssertRecordCount("s1", "mental_models", 0)
+}
+
+func TestFakeIntelligence_RefreshAndQuery(t *testing.T) {
+	srv := New(t)
+
+	srv.CallToolJSON("refresh_intelligence", map[string]interface{}{})
+	result := srv.CallToolJSON("query_attack", map[string]interface{}{
+		"query": "T1059",
+	})
+
+	assert.Equal(t, float64(1), result["total"])
+}
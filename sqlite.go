@@ -0,0 +1,7 @@
+//go:build cgo
+
+package main
+
+// The sqlite backend needs cgo; without it storage.New reports the backend
+// as not compiled in
+import _ "github.com/rainmana/gothink/internal/storage/sqlite"